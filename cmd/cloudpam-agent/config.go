@@ -28,8 +28,19 @@ type AWSOrg struct {
 	ExcludeAccounts []string `yaml:"exclude_accounts"`
 }
 
+// AzureOrg holds Azure subscription fan-out configuration. With no
+// management group set, every subscription the service principal can read is
+// discovered.
+type AzureOrg struct {
+	Enabled              bool     `yaml:"enabled"`
+	ManagementGroup      string   `yaml:"management_group"`
+	Regions              []string `yaml:"regions"`
+	ExcludeSubscriptions []string `yaml:"exclude_subscriptions"`
+}
+
 // Config holds the agent configuration.
 type Config struct {
+	Provider          string        `yaml:"provider"`
 	ServerURL         string        `yaml:"server_url"`
 	APIKey            string        `yaml:"api_key"`
 	AgentName         string        `yaml:"agent_name"`
@@ -45,6 +56,12 @@ type Config struct {
 	BootstrapToken    string        `yaml:"bootstrap_token"`
 	AWSOrg            AWSOrg        `yaml:"aws_org"`
 
+	// Azure credentials come from the standard AZURE_TENANT_ID,
+	// AZURE_CLIENT_ID and AZURE_CLIENT_SECRET variables.
+	AzureSubscriptionID string   `yaml:"azure_subscription_id"`
+	AzureRegions        []string `yaml:"azure_regions"`
+	AzureOrg            AzureOrg `yaml:"azure_org"`
+
 	// Bootstrapped is set to true when config was populated from a bootstrap token.
 	Bootstrapped bool `yaml:"-"`
 }
@@ -73,6 +90,9 @@ func LoadConfig(path string) (*Config, error) {
 	}
 
 	// Override with environment variables
+	if v := os.Getenv("CLOUDPAM_PROVIDER"); v != "" {
+		cfg.Provider = v
+	}
 	if v := os.Getenv("CLOUDPAM_SERVER_URL"); v != "" {
 		cfg.ServerURL = v
 	}
@@ -129,6 +149,26 @@ func LoadConfig(path string) (*Config, error) {
 		cfg.AWSOrg.ExcludeAccounts = strings.Split(v, ",")
 	}
 
+	// Azure env vars
+	if v := os.Getenv("CLOUDPAM_AZURE_SUBSCRIPTION_ID"); v != "" {
+		cfg.AzureSubscriptionID = v
+	}
+	if v := os.Getenv("CLOUDPAM_AZURE_REGIONS"); v != "" {
+		cfg.AzureRegions = strings.Split(v, ",")
+	}
+	if v := os.Getenv("CLOUDPAM_AZURE_ORG_ENABLED"); v == "true" || v == "1" {
+		cfg.AzureOrg.Enabled = true
+	}
+	if v := os.Getenv("CLOUDPAM_AZURE_ORG_MANAGEMENT_GROUP"); v != "" {
+		cfg.AzureOrg.ManagementGroup = v
+	}
+	if v := os.Getenv("CLOUDPAM_AZURE_ORG_REGIONS"); v != "" {
+		cfg.AzureOrg.Regions = strings.Split(v, ",")
+	}
+	if v := os.Getenv("CLOUDPAM_AZURE_ORG_EXCLUDE_SUBSCRIPTIONS"); v != "" {
+		cfg.AzureOrg.ExcludeSubscriptions = strings.Split(v, ",")
+	}
+
 	// Validate required fields
 	if err := cfg.Validate(); err != nil {
 		return nil, err
@@ -220,6 +260,27 @@ func (c *Config) Validate() error {
 	if c.AccountID < 1 {
 		return errors.New("account_id must be a positive integer (set CLOUDPAM_ACCOUNT_ID or yaml)")
 	}
+	if c.Provider == "" {
+		c.Provider = "aws"
+		if c.AzureOrg.Enabled {
+			c.Provider = "azure"
+		}
+	}
+	switch c.Provider {
+	case "aws":
+		if c.AzureOrg.Enabled {
+			return errors.New("azure_org requires provider azure")
+		}
+	case "azure":
+		if c.AWSOrg.Enabled {
+			return errors.New("aws_org requires provider aws")
+		}
+		if !c.AzureOrg.Enabled && c.AzureSubscriptionID == "" {
+			return errors.New("azure_subscription_id is required unless azure_org is enabled (set CLOUDPAM_AZURE_SUBSCRIPTION_ID or yaml)")
+		}
+	default:
+		return fmt.Errorf("unsupported provider %q (must be aws or azure)", c.Provider)
+	}
 	if c.AWSOrg.Enabled {
 		if c.AWSOrg.RoleName == "" {
 			c.AWSOrg.RoleName = "CloudPAMDiscoveryRole"
@@ -31,6 +31,13 @@ var covAgentEnvKeys = []string{
 	"CLOUDPAM_AWS_ORG_EXTERNAL_ID",
 	"CLOUDPAM_AWS_ORG_REGIONS",
 	"CLOUDPAM_AWS_ORG_EXCLUDE_ACCOUNTS",
+	"CLOUDPAM_PROVIDER",
+	"CLOUDPAM_AZURE_SUBSCRIPTION_ID",
+	"CLOUDPAM_AZURE_REGIONS",
+	"CLOUDPAM_AZURE_ORG_ENABLED",
+	"CLOUDPAM_AZURE_ORG_MANAGEMENT_GROUP",
+	"CLOUDPAM_AZURE_ORG_REGIONS",
+	"CLOUDPAM_AZURE_ORG_EXCLUDE_SUBSCRIPTIONS",
 }
 
 func covIsolateAgentEnv(t *testing.T) {
@@ -361,6 +368,10 @@ func TestCovValidateRejectsIncompleteConfigs(t *testing.T) {
 		{"negative account id", func(c *Config) { c.AccountID = -5 }, "account_id must be a positive integer"},
 		{"sync interval too small", func(c *Config) { c.SyncInterval = 59 * time.Second }, "sync_interval must be at least 1 minute"},
 		{"heartbeat interval too small", func(c *Config) { c.HeartbeatInterval = 9 * time.Second }, "heartbeat_interval must be at least 10 seconds"},
+		{"unknown provider", func(c *Config) { c.Provider = "gcp" }, "unsupported provider"},
+		{"azure without subscription", func(c *Config) { c.Provider = "azure" }, "azure_subscription_id is required"},
+		{"aws org on azure", func(c *Config) { c.Provider = "azure"; c.AWSOrg.Enabled = true }, "aws_org requires provider aws"},
+		{"azure org on aws", func(c *Config) { c.Provider = "aws"; c.AzureOrg.Enabled = true }, "azure_org requires provider azure"},
 	}
 
 	for _, tc := range tests {
@@ -426,6 +437,31 @@ func TestCovValidateDefaultsOrgRoleNameOnlyWhenOrgEnabled(t *testing.T) {
 	}
 }
 
+func TestCovLoadConfigAzureFromEnv(t *testing.T) {
+	covIsolateAgentEnv(t)
+	t.Setenv("CLOUDPAM_SERVER_URL", "https://pam.example.com")
+	t.Setenv("CLOUDPAM_API_KEY", "cpk")
+	t.Setenv("CLOUDPAM_AGENT_NAME", "azure-agent")
+	t.Setenv("CLOUDPAM_ACCOUNT_ID", "1")
+	t.Setenv("CLOUDPAM_AZURE_ORG_ENABLED", "1")
+	t.Setenv("CLOUDPAM_AZURE_ORG_MANAGEMENT_GROUP", "platform")
+	t.Setenv("CLOUDPAM_AZURE_ORG_REGIONS", "eastus,westeurope")
+	t.Setenv("CLOUDPAM_AZURE_ORG_EXCLUDE_SUBSCRIPTIONS", "sub-x")
+
+	cfg, err := LoadConfig("")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	// Enabling azure_org selects the azure provider when none is set.
+	if cfg.Provider != "azure" {
+		t.Errorf("Provider = %q, want azure", cfg.Provider)
+	}
+	want := AzureOrg{Enabled: true, ManagementGroup: "platform", Regions: []string{"eastus", "westeurope"}, ExcludeSubscriptions: []string{"sub-x"}}
+	if !reflect.DeepEqual(cfg.AzureOrg, want) {
+		t.Errorf("AzureOrg = %+v, want %+v", cfg.AzureOrg, want)
+	}
+}
+
 func TestCovValidateAppliesBootstrapToken(t *testing.T) {
 	token := covBootstrapToken(t, domain.AgentProvisionBundle{
 		AgentName: "bootstrapped-agent",
@@ -3,9 +3,11 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -13,6 +15,7 @@ import (
 
 	"cloudpam/internal/discovery"
 	"cloudpam/internal/discovery/aws"
+	"cloudpam/internal/discovery/azure"
 	"cloudpam/internal/domain"
 )
 
@@ -36,6 +39,7 @@ func main() {
 
 	logger.Info("cloudpam-agent starting",
 		"version", version,
+		"provider", cfg.Provider,
 		"server_url", cfg.ServerURL,
 		"account_id", cfg.AccountID,
 		"agent_name", cfg.AgentName,
@@ -74,8 +78,11 @@ func main() {
 		)
 	}
 
-	// Create AWS collector
-	collector := aws.New()
+	// Create the provider's collector
+	var collector discovery.Collector = aws.New()
+	if cfg.Provider == "azure" {
+		collector = azure.New()
+	}
 
 	// Setup signal handling
 	ctx, cancel := context.WithCancel(context.Background())
@@ -109,9 +116,17 @@ func runScheduler(ctx context.Context, cfg *Config, collector discovery.Collecto
 
 	// Choose sync function based on mode
 	syncFn := func(syncJobID *uuid.UUID) { runSync(ctx, cfg, collector, pusher, syncJobID, logger) }
-	if cfg.AWSOrg.Enabled {
+	switch {
+	case cfg.AWSOrg.Enabled:
 		logger.Info("org mode enabled", "role_name", cfg.AWSOrg.RoleName, "regions", cfg.AWSOrg.Regions)
 		syncFn = func(syncJobID *uuid.UUID) { runOrgSync(ctx, cfg, pusher, syncJobID, logger) }
+	case cfg.AzureOrg.Enabled:
+		lister, ok := collector.(subscriptionLister)
+		if !ok {
+			return fmt.Errorf("azure_org requires the azure collector, got %s", collector.Provider())
+		}
+		logger.Info("azure org mode enabled", "management_group", cfg.AzureOrg.ManagementGroup, "regions", cfg.AzureOrg.Regions)
+		syncFn = func(syncJobID *uuid.UUID) { runAzureOrgSync(ctx, cfg, lister, pusher, syncJobID, logger) }
 	}
 
 	// Initial sync and heartbeat. Org-mode agents still need to appear as live
@@ -229,15 +244,98 @@ func runOrgSync(ctx context.Context, cfg *Config, pusher *Pusher, syncJobID *uui
 	logger.Info("org sync completed", "accounts", len(ingestAccounts))
 }
 
+// subscriptionLister is an Azure collector that can enumerate the
+// subscriptions to fan out over.
+type subscriptionLister interface {
+	discovery.Collector
+	ListSubscriptions(ctx context.Context, managementGroup string) ([]azure.Subscription, error)
+}
+
+func runAzureOrgSync(ctx context.Context, cfg *Config, collector subscriptionLister, pusher *Pusher, syncJobID *uuid.UUID, logger *slog.Logger) {
+	logger.Info("starting azure org discovery sync", "management_group", cfg.AzureOrg.ManagementGroup)
+
+	subs, err := collector.ListSubscriptions(ctx, cfg.AzureOrg.ManagementGroup)
+	if err != nil {
+		logger.Error("failed to list subscriptions", "error", err)
+		return
+	}
+
+	excludeSet := make(map[string]bool, len(cfg.AzureOrg.ExcludeSubscriptions))
+	for _, id := range cfg.AzureOrg.ExcludeSubscriptions {
+		excludeSet[strings.ToLower(id)] = true
+	}
+
+	regions := cfg.AzureOrg.Regions
+	if len(regions) == 0 {
+		regions = cfg.AzureRegions
+	}
+
+	var ingestAccounts []domain.OrgAccountIngest
+
+	for _, sub := range subs {
+		if excludeSet[strings.ToLower(sub.ID)] {
+			logger.Info("excluding subscription", "subscription_id", sub.ID, "name", sub.Name)
+			continue
+		}
+
+		logger.Info("discovering subscription", "subscription_id", sub.ID, "name", sub.Name)
+
+		// One service principal reads every subscription it has been granted,
+		// so there is no per-subscription credential exchange as with AWS.
+		account := domain.Account{
+			Provider:   "azure",
+			ExternalID: sub.ID,
+			Regions:    regions,
+		}
+
+		resources, err := collector.Discover(ctx, account)
+		if err != nil {
+			logger.Error("discovery failed for subscription", "subscription_id", sub.ID, "error", err)
+			continue
+		}
+
+		logger.Info("discovered resources for subscription", "subscription_id", sub.ID, "count", len(resources))
+
+		ingestAccounts = append(ingestAccounts, domain.OrgAccountIngest{
+			AWSAccountID: sub.ID,
+			AccountName:  sub.Name,
+			Provider:     "azure",
+			Regions:      regions,
+			Resources:    resources,
+		})
+	}
+
+	if len(ingestAccounts) == 0 {
+		logger.Info("no subscriptions to ingest")
+		return
+	}
+
+	req := domain.BulkIngestRequest{
+		Accounts:  ingestAccounts,
+		SyncJobID: syncJobID,
+	}
+
+	if err := pusher.PushOrgResources(ctx, req, cfg.MaxRetries, cfg.RetryBackoff); err != nil {
+		logger.Error("push org resources failed", "error", err)
+		return
+	}
+
+	logger.Info("azure org sync completed", "subscriptions", len(ingestAccounts))
+}
+
 func runSync(ctx context.Context, cfg *Config, collector discovery.Collector, pusher *Pusher, syncJobID *uuid.UUID, logger *slog.Logger) {
 	logger.Info("starting discovery sync", "account_id", cfg.AccountID)
 
-	// Create mock account with AWS regions
+	// Create mock account with the provider's regions
 	account := domain.Account{
 		ID:       cfg.AccountID,
-		Provider: "aws",
+		Provider: collector.Provider(),
 		Regions:  cfg.AWSRegions,
 	}
+	if account.Provider == "azure" {
+		account.ExternalID = cfg.AzureSubscriptionID
+		account.Regions = cfg.AzureRegions
+	}
 
 	// Discover resources
 	resources, err := collector.Discover(ctx, account)
@@ -12,6 +12,7 @@ import (
 
 	"github.com/google/uuid"
 
+	"cloudpam/internal/discovery/azure"
 	"cloudpam/internal/domain"
 )
 
@@ -440,3 +441,75 @@ func TestCovRunSchedulerContinuesAfterHeartbeatFailure(t *testing.T) {
 		t.Fatalf("heartbeats = %d, want at least 2", heartbeats)
 	}
 }
+
+// covStubSubscriptionLister is a stand-in for the Azure collector in org mode.
+type covStubSubscriptionLister struct {
+	covStubCollector
+	subs            []azure.Subscription
+	managementGroup string
+}
+
+func (c *covStubSubscriptionLister) Provider() string { return "azure" }
+
+func (c *covStubSubscriptionLister) ListSubscriptions(_ context.Context, managementGroup string) ([]azure.Subscription, error) {
+	c.managementGroup = managementGroup
+	return c.subs, nil
+}
+
+func TestCovRunAzureOrgSyncFansOutOverSubscriptions(t *testing.T) {
+	var got domain.BulkIngestRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/discovery/ingest/org" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_ = json.NewEncoder(w).Encode(domain.BulkIngestResponse{AccountsProcessed: len(got.Accounts)})
+	}))
+	defer srv.Close()
+
+	collector := &covStubSubscriptionLister{
+		covStubCollector: covStubCollector{resources: []domain.DiscoveredResource{{ID: uuid.New(), ResourceID: "/subscriptions/a/vnet"}}},
+		subs: []azure.Subscription{
+			{ID: "sub-a", Name: "Prod", State: "Enabled"},
+			{ID: "SUB-B", Name: "Sandbox", State: "Enabled"},
+		},
+	}
+	pusher := NewPusher(srv.URL, "cpk", uuid.New(), 5*time.Second, covQuietLogger())
+	cfg := &Config{
+		Provider:     "azure",
+		AzureRegions: []string{"eastus"},
+		AzureOrg:     AzureOrg{Enabled: true, ManagementGroup: "platform", ExcludeSubscriptions: []string{"sub-b"}},
+	}
+	runAzureOrgSync(context.Background(), cfg, collector, pusher, nil, covQuietLogger())
+
+	if collector.managementGroup != "platform" {
+		t.Errorf("management group = %q, want platform", collector.managementGroup)
+	}
+	if collector.callCount() != 1 {
+		t.Fatalf("collector calls = %d, want 1 (excluded subscriptions are skipped case-insensitively)", collector.callCount())
+	}
+	acct := collector.lastAccount()
+	if acct.Provider != "azure" || acct.ExternalID != "sub-a" || len(acct.Regions) != 1 || acct.Regions[0] != "eastus" {
+		t.Errorf("discovered account = %+v", acct)
+	}
+	if len(got.Accounts) != 1 {
+		t.Fatalf("ingested accounts = %+v", got.Accounts)
+	}
+	if a := got.Accounts[0]; a.AWSAccountID != "sub-a" || a.Provider != "azure" || a.AccountName != "Prod" || len(a.Resources) != 1 {
+		t.Errorf("ingested account = %+v", a)
+	}
+}
+
+func TestCovRunSyncUsesAzureSubscription(t *testing.T) {
+	_, url := covNewIngestServer(t)
+	collector := &covStubSubscriptionLister{}
+	pusher := NewPusher(url, "cpk", uuid.New(), 5*time.Second, covQuietLogger())
+
+	cfg := &Config{AccountID: 9, AzureSubscriptionID: "sub-a", AzureRegions: []string{"westeurope"}, AWSRegions: []string{"us-east-1"}}
+	runSync(context.Background(), cfg, collector, pusher, nil, covQuietLogger())
+
+	acct := collector.lastAccount()
+	if acct.Provider != "azure" || acct.ExternalID != "sub-a" || len(acct.Regions) != 1 || acct.Regions[0] != "westeurope" {
+		t.Errorf("discovered account = %+v", acct)
+	}
+}
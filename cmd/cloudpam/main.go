@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto/ed25519"
 	"crypto/rand"
 	"encoding/hex"
 	"flag"
@@ -22,10 +23,15 @@ import (
 	"cloudpam/internal/auth"
 	"cloudpam/internal/discovery"
 	awscollector "cloudpam/internal/discovery/aws"
+	azurecollector "cloudpam/internal/discovery/azure"
 	gcpcollector "cloudpam/internal/discovery/gcp"
+	"cloudpam/internal/domain"
+	"cloudpam/internal/notify"
 	"cloudpam/internal/observability"
 	"cloudpam/internal/planning"
 	"cloudpam/internal/planning/llm"
+	"cloudpam/internal/storage"
+	"cloudpam/internal/webhook"
 
 	"github.com/google/uuid"
 )
@@ -175,7 +181,15 @@ func main() {
 	}
 
 	mux := http.NewServeMux()
+
+	// Webhook dispatcher. Pool lifecycle events are taken from the audit
+	// stream, so the dispatcher is attached as an audit sink.
+	webhookStore := selectWebhookStore(logger, store)
+	webhookDispatcher := webhook.NewDispatcher(webhookStore, webhook.Config{Logger: logger.Slog()})
 	auditLogger := configureAuditSyslogForwarding(logger, selectAuditLogger(logger), version)
+	auditLogger = audit.NewForwardingAuditLogger(auditLogger, []audit.Sink{webhookDispatcher.AuditSink()}, func(ctx context.Context, event *audit.AuditEvent, err error) {
+		logger.WarnContext(ctx, "webhook publish from audit event failed", "error", err)
+	})
 	keyStore := selectKeyStore(logger)
 	userStore := selectUserStore(logger)
 	roleStore := selectRoleStore(logger, userStore)
@@ -209,38 +223,85 @@ func main() {
 	syncService := discovery.NewSyncService(discoveryStore)
 	syncService.RegisterCollector(awscollector.New())
 	syncService.RegisterCollector(gcpcollector.New())
+	syncService.RegisterCollector(azurecollector.New())
+	ipAddressStore := selectIPAddressStore(logger, store)
+	syncService.SetIPAddressReconciler(discovery.NewIPAddressReconciler(discoveryStore, ipAddressStore))
 	discoverySrv := api.NewDiscoveryServer(srv, discoveryStore, syncService, keyStore)
 	discoverySrv.SetNetworkStore(networkStore)
-	logger.Info("discovery subsystem initialized", "collectors", "aws,gcp")
+	logger.Info("discovery subsystem initialized", "collectors", "aws,gcp,azure")
 
 	// Initialize analysis subsystem
 	analysisService := planning.NewAnalysisService(store)
+	complianceRuleStore := selectComplianceRuleStore(logger, store)
+	analysisService.SetComplianceRules(complianceRuleStore)
+	srv.SetPoolAdmission(analysisService)
 	analysisSrv := api.NewAnalysisServer(srv, analysisService)
+	analysisSrv.SetComplianceRuleStore(complianceRuleStore)
 	logger.Info("analysis subsystem initialized")
 
+	// Initialize utilization history subsystem
+	utilizationStore := selectUtilizationStore(logger, store)
+	utilizationService := planning.NewUtilizationService(store, utilizationStore)
+	utilizationSrv := api.NewUtilizationServer(srv, utilizationService)
+	logger.Info("utilization history subsystem initialized")
+
 	// Initialize recommendation subsystem
 	recStore := selectRecommendationStore(logger, store)
 	recService := planning.NewRecommendationService(analysisService, recStore, store)
+	recService.SetPublisher(webhookDispatcher)
+	recService.SetUtilization(utilizationService)
+	recService.SetDiscoveryStore(discoveryStore)
 	recSrv := api.NewRecommendationServer(srv, recService, recStore)
 	logger.Info("recommendation subsystem initialized")
 
 	// Initialize AI planning subsystem
 	llmCfg := llm.ConfigFromEnv()
-	llmProvider := llm.NewOpenAIProvider(llmCfg)
+	llmProvider, err := llm.NewProvider(llmCfg)
+	if err != nil {
+		logger.Error("invalid CLOUDPAM_LLM_PROVIDER", "error", err)
+		os.Exit(1)
+	}
 	if llmProvider.Available() {
-		logger.Info("ai planning enabled", "model", llmCfg.Model, "endpoint", llmCfg.Endpoint)
+		logger.Info("ai planning enabled", "provider", llmProvider.Name(), "model", llmCfg.Model, "endpoint", llmCfg.Endpoint)
 	} else {
 		logger.Info("ai planning disabled (set CLOUDPAM_LLM_API_KEY or CLOUDPAM_LLM_ENDPOINT to enable)")
 	}
 	convStore := selectConversationStore(logger, store)
 	aiService := planning.NewAIPlanningService(analysisService, convStore, store, llmProvider)
+	aiService.SetDiscoveryStore(discoveryStore)
 	aiSrv := api.NewAIPlanningServer(srv, aiService, convStore)
 	logger.Info("ai planning subsystem initialized")
 
+	// Initialize change proposals (reviewed schema and AI plan applies)
+	proposalStore := selectChangeProposalStore(logger, store)
+	srv.SetChangeProposalStore(proposalStore)
+	proposalSrv := api.NewChangeProposalServer(srv, proposalStore)
+
+	// Pool change requests (pool mutations held back by approval policies)
+	poolChangeStore := selectPoolChangeRequestStore(logger, store)
+	srv.SetPoolChangeRequestStore(poolChangeStore)
+	recService.SetPoolChangeRequestStore(poolChangeStore)
+	poolChangeSrv := api.NewPoolChangeRequestServer(srv, poolChangeStore)
+
+	// CIDR reservations (soft holds that expire)
+	reservationStore := selectReservationStore(logger, store)
+	srv.SetReservationStore(reservationStore)
+	analysisService.SetReservationStore(reservationStore)
+	reservationSrv := api.NewReservationServer(srv, reservationStore)
+	reservationSrv.SetPublisher(webhookDispatcher)
+
 	// Initialize drift detection subsystem
 	driftStore := selectDriftStore(logger, store)
 	driftDetector := discovery.NewDriftDetector(store, discoveryStore, driftStore)
+	driftDetector.SetPublisher(webhookDispatcher)
 	driftSrv := api.NewDriftServer(srv, driftDetector, driftStore)
+
+	// Scheduled discovery reuses the manual sync path, so accounts with a
+	// healthy agent get an agent job and the rest sync locally.
+	scheduleStore := selectDiscoveryScheduleStore(logger, store)
+	discoverySrv.SetScheduleStore(scheduleStore)
+	discoveryScheduler := discovery.NewScheduler(scheduleStore, store, discoveryStore, discoverySrv, driftDetector,
+		discoverySchedulerConfig(logger))
 	logger.Info("drift detection subsystem initialized")
 
 	// Initialize settings subsystem
@@ -249,6 +310,14 @@ func main() {
 	srv.SetSettingsStore(settingsStore)
 	logger.Info("settings subsystem initialized")
 
+	// Capacity alerts: rules and channels live in the settings store.
+	alertStore := selectAlertStore(logger, store)
+	alertService := planning.NewAlertService(store, settingsStore, alertStore, notify.NewRegistry(notify.Options{}))
+	alertService.SetUtilization(utilizationService)
+	alertService.SetLogger(logger.Slog())
+	alertSrv := api.NewAlertServer(srv, alertService, alertStore)
+	logger.Info("capacity alert subsystem initialized")
+
 	networkSrv := api.NewNetworkServer(srv, store, discoveryStore, driftStore)
 	networkSrv.SetNetworkStore(networkStore)
 	networkSrv.SetSettingsStore(settingsStore)
@@ -261,6 +330,14 @@ func main() {
 	oidcSrv := api.NewOIDCServer(srv, oidcStore, sessionStore, userStore, settingsStore, oidcEncKey, oidcCallbackURL)
 	logger.Info("oidc subsystem initialized")
 
+	// IP address subsystem
+	ipAddressSrv := api.NewIPAddressServer(srv, ipAddressStore)
+	logger.Info("ip address subsystem initialized")
+
+	// Webhook subsystem
+	webhookSrv := api.NewWebhookServer(srv, webhookStore, webhookDispatcher)
+	logger.Info("webhook subsystem initialized")
+
 	// Update subsystem
 	updateSrv := api.NewUpdateServer(srv)
 	logger.Info("update subsystem initialized")
@@ -269,6 +346,10 @@ func main() {
 	srv.RegisterProtectedRoutes(keyStore, sessionStore, userStore, logger.Slog())
 	authSrv := api.NewAuthServerWithStores(srv, keyStore, sessionStore, userStore, auditLogger)
 	authSrv.SetSettingsStore(settingsStore)
+	checkpointKey := auditSigningKey(logger)
+	if checkpointKey != nil {
+		authSrv.SetAuditCheckpointKey(checkpointKey.Public().(ed25519.PublicKey))
+	}
 	authSrv.RegisterProtectedAuthRoutes(logger.Slog())
 	userSrv := api.NewUserServer(srv, keyStore, userStore, sessionStore, auditLogger)
 	userSrv.SetRoleStore(roleStore)
@@ -283,13 +364,23 @@ func main() {
 	discoverySrv.RegisterProtectedDiscoveryRoutes(dualMW, logger.Slog())
 	networkSrv.RegisterProtectedNetworkRoutes(dualMW, logger.Slog())
 	analysisSrv.RegisterProtectedAnalysisRoutes(dualMW, logger.Slog())
+	utilizationSrv.RegisterProtectedUtilizationRoutes(dualMW, logger.Slog())
+	alertSrv.RegisterProtectedAlertRoutes(dualMW, logger.Slog())
 	recSrv.RegisterProtectedRecommendationRoutes(dualMW, logger.Slog())
 	driftSrv.RegisterProtectedDriftRoutes(dualMW, logger.Slog())
 	aiSrv.RegisterProtectedAIPlanningRoutes(dualMW, logger.Slog())
+	proposalSrv.RegisterProtectedChangeProposalRoutes(dualMW, logger.Slog())
+	poolChangeSrv.RegisterProtectedPoolChangeRequestRoutes(dualMW, logger.Slog())
+	reservationSrv.RegisterProtectedReservationRoutes(dualMW, logger.Slog())
 	settingsSrv.RegisterProtectedSettingsRoutes(dualMW, logger.Slog())
+	oidcSrv.SetRoleStore(roleStore)
 	oidcSrv.RegisterOIDCRoutes(logger.Slog())
 	oidcSrv.RegisterOIDCAdminRoutes(dualMW, logger.Slog())
 	updateSrv.RegisterProtectedUpdateRoutes(dualMW, logger.Slog())
+	webhookSrv.RegisterProtectedWebhookRoutes(dualMW, logger.Slog())
+	ipAddressSrv.RegisterProtectedIPAddressRoutes(dualMW, logger.Slog())
+	scimSrv := api.NewSCIMServer(srv, userStore, roleStore, sessionStore, keyStore)
+	scimSrv.RegisterProtectedSCIMRoutes(logger.Slog())
 	userSrv.SetSettingsStore(settingsStore)
 
 	if len(existingUsers) == 0 {
@@ -312,6 +403,183 @@ func main() {
 		}
 	}()
 
+	// Webhook delivery workers and retry poller, stopped on shutdown.
+	webhookCtx, stopWebhooks := context.WithCancel(context.Background())
+	defer stopWebhooks()
+	webhooksDone := make(chan struct{})
+	go func() {
+		webhookDispatcher.Run(webhookCtx)
+		close(webhooksDone)
+	}()
+
+	// Publish agent.offline when an approved agent stops sending heartbeats.
+	go func() {
+		monitor := discovery.NewAgentMonitor(discoveryStore, webhookDispatcher)
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			if err := monitor.Check(webhookCtx, time.Now().UTC()); err != nil && webhookCtx.Err() == nil {
+				logger.Warn("agent monitor check failed", "error", err)
+			}
+			select {
+			case <-webhookCtx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	// Periodic pool utilization snapshots for history and forecasts,
+	// stopped on shutdown.
+	snapshotsDone := make(chan struct{})
+	go func() {
+		defer close(snapshotsDone)
+		interval := utilizationSnapshotInterval(logger)
+		if interval == 0 {
+			logger.Info("utilization snapshots disabled")
+			return
+		}
+		logger.Info("utilization snapshots enabled", "interval", interval.String())
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if n, err := utilizationService.RecordSnapshots(webhookCtx, time.Now()); err != nil && webhookCtx.Err() == nil {
+				logger.Warn("utilization snapshot failed", "recorded", n, "error", err)
+			}
+			select {
+			case <-webhookCtx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	// Periodic capacity alert evaluation, stopped on shutdown.
+	alertsDone := make(chan struct{})
+	go func() {
+		defer close(alertsDone)
+		interval := alertEvaluationInterval(logger)
+		if interval == 0 {
+			logger.Info("capacity alert evaluation disabled")
+			return
+		}
+		logger.Info("capacity alert evaluation enabled", "interval", interval.String())
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if _, err := alertService.Evaluate(webhookCtx); err != nil && webhookCtx.Err() == nil {
+				logger.Warn("capacity alert evaluation failed", "error", err)
+			}
+			select {
+			case <-webhookCtx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	// Periodic release of expired reservations, stopped on shutdown.
+	reservationsDone := make(chan struct{})
+	go func() {
+		defer close(reservationsDone)
+		interval := reservationExpiryInterval(logger)
+		if interval == 0 {
+			logger.Info("reservation expiry disabled")
+			return
+		}
+		logger.Info("reservation expiry enabled", "interval", interval.String())
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			expired, err := reservationSrv.ExpireReservations(webhookCtx, time.Now().UTC())
+			if err != nil && webhookCtx.Err() == nil {
+				logger.Warn("reservation expiry failed", "error", err)
+			}
+			for _, res := range expired {
+				logger.Info("reservation expired", "reservation_id", res.ID, "cidr", res.CIDR, "owner", res.Owner)
+			}
+			select {
+			case <-webhookCtx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	// Periodic audit log retention, stopped on shutdown. The policy is read
+	// from the settings store on every run, so changes apply without a
+	// restart.
+	retentionDone := make(chan struct{})
+	go func() {
+		defer close(retentionDone)
+		enforcer, ok := audit.AsRetentionEnforcer(auditLogger)
+		if !ok {
+			logger.Info("audit retention not supported by the audit backend")
+			return
+		}
+		interval := auditRetentionInterval(logger)
+		if interval == 0 {
+			logger.Info("audit retention disabled")
+			return
+		}
+		logger.Info("audit retention enabled", "interval", interval.String())
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := enforceAuditRetention(webhookCtx, logger, enforcer, settingsStore); err != nil && webhookCtx.Err() == nil {
+				logger.Warn("audit retention failed", "error", err)
+			}
+			select {
+			case <-webhookCtx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	// Periodic signed checkpoints of the audit hash chain, stopped on
+	// shutdown. They only run when a signing key is configured.
+	checkpointsDone := make(chan struct{})
+	go func() {
+		defer close(checkpointsDone)
+		if checkpointKey == nil {
+			return
+		}
+		verifier, ok := audit.AsChainVerifier(auditLogger)
+		if !ok {
+			logger.Info("audit checkpoints not supported by the audit backend")
+			return
+		}
+		interval := auditCheckpointInterval(logger)
+		if interval == 0 {
+			logger.Info("audit checkpoints disabled")
+			return
+		}
+		logger.Info("audit checkpoints enabled", "interval", interval.String(), "key_id", audit.KeyID(checkpointKey.Public().(ed25519.PublicKey)))
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-webhookCtx.Done():
+				return
+			case <-ticker.C:
+			}
+			cp, err := verifier.Checkpoint(webhookCtx, checkpointKey)
+			if err != nil && webhookCtx.Err() == nil {
+				logger.Warn("audit checkpoint failed", "error", err)
+			} else if cp != nil {
+				logger.Info("audit checkpoint signed", "seq", cp.Seq)
+			}
+		}
+	}()
+
+	// Scheduled discovery sync and drift detection, stopped on shutdown.
+	schedulerDone := make(chan struct{})
+	go func() {
+		discoveryScheduler.Run(webhookCtx)
+		close(schedulerDone)
+	}()
+
 	// Apply middleware stack (metrics, request ID, tracing, structured logging, rate limiting).
 	// Order: metrics (outermost) -> requestID -> tracing -> logging -> rateLimiting (innermost before handler)
 	// Tracing sits after requestID so the span can carry the request ID, and
@@ -367,6 +635,15 @@ func main() {
 	} else {
 		logger.Info("server stopped gracefully")
 	}
+	// In-flight deliveries abort and stay pending for the next start.
+	stopWebhooks()
+	<-webhooksDone
+	<-schedulerDone
+	<-snapshotsDone
+	<-alertsDone
+	<-reservationsDone
+	<-retentionDone
+	<-checkpointsDone
 
 	// Close database connection
 	if err := store.Close(); err != nil {
@@ -397,6 +674,150 @@ func main() {
 	logger.Info("shutdown complete")
 }
 
+// discoverySchedulerConfig reads CLOUDPAM_DISCOVERY_SCHEDULER_CONCURRENCY and
+// CLOUDPAM_DISCOVERY_SCHEDULER_JITTER. A jitter of 0 disables it.
+func discoverySchedulerConfig(logger observability.Logger) discovery.SchedulerConfig {
+	cfg := discovery.SchedulerConfig{Logger: logger.Slog()}
+	if v := strings.TrimSpace(os.Getenv("CLOUDPAM_DISCOVERY_SCHEDULER_CONCURRENCY")); v != "" {
+		if parsed, err := strconv.Atoi(v); err != nil || parsed <= 0 {
+			logger.Warn("invalid CLOUDPAM_DISCOVERY_SCHEDULER_CONCURRENCY; using default", "value", v)
+		} else {
+			cfg.Concurrency = parsed
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv("CLOUDPAM_DISCOVERY_SCHEDULER_JITTER")); v != "" {
+		if parsed, err := time.ParseDuration(v); err != nil || parsed < 0 {
+			logger.Warn("invalid CLOUDPAM_DISCOVERY_SCHEDULER_JITTER; using default", "value", v)
+		} else if parsed == 0 {
+			cfg.Jitter = -1
+		} else {
+			cfg.Jitter = parsed
+		}
+	}
+	return cfg
+}
+
+// utilizationSnapshotInterval reads CLOUDPAM_UTILIZATION_SNAPSHOT_INTERVAL,
+// defaulting to 1h. 0 disables snapshots; shorter intervals than a minute
+// are rejected.
+func utilizationSnapshotInterval(logger observability.Logger) time.Duration {
+	v := strings.TrimSpace(os.Getenv("CLOUDPAM_UTILIZATION_SNAPSHOT_INTERVAL"))
+	if v == "" {
+		return time.Hour
+	}
+	parsed, err := time.ParseDuration(v)
+	if err != nil || parsed < 0 || (parsed > 0 && parsed < time.Minute) {
+		logger.Warn("invalid CLOUDPAM_UTILIZATION_SNAPSHOT_INTERVAL; using default", "value", v)
+		return time.Hour
+	}
+	return parsed
+}
+
+// alertEvaluationInterval reads CLOUDPAM_ALERT_EVALUATION_INTERVAL,
+// defaulting to 5m. 0 disables evaluation; shorter intervals than a minute
+// are rejected.
+func alertEvaluationInterval(logger observability.Logger) time.Duration {
+	v := strings.TrimSpace(os.Getenv("CLOUDPAM_ALERT_EVALUATION_INTERVAL"))
+	if v == "" {
+		return 5 * time.Minute
+	}
+	parsed, err := time.ParseDuration(v)
+	if err != nil || parsed < 0 || (parsed > 0 && parsed < time.Minute) {
+		logger.Warn("invalid CLOUDPAM_ALERT_EVALUATION_INTERVAL; using default", "value", v)
+		return 5 * time.Minute
+	}
+	return parsed
+}
+
+// reservationExpiryInterval reads CLOUDPAM_RESERVATION_EXPIRY_INTERVAL,
+// defaulting to 1m. 0 disables the job; shorter intervals than a minute
+// are rejected. Expired reservations stop holding their block at once; the
+// job only records and announces the expiry.
+func reservationExpiryInterval(logger observability.Logger) time.Duration {
+	v := strings.TrimSpace(os.Getenv("CLOUDPAM_RESERVATION_EXPIRY_INTERVAL"))
+	if v == "" {
+		return time.Minute
+	}
+	parsed, err := time.ParseDuration(v)
+	if err != nil || parsed < 0 || (parsed > 0 && parsed < time.Minute) {
+		logger.Warn("invalid CLOUDPAM_RESERVATION_EXPIRY_INTERVAL; using default", "value", v)
+		return time.Minute
+	}
+	return parsed
+}
+
+// auditRetentionInterval reads CLOUDPAM_AUDIT_RETENTION_INTERVAL,
+// defaulting to 1h. 0 disables enforcement; shorter intervals than a minute
+// are rejected.
+func auditRetentionInterval(logger observability.Logger) time.Duration {
+	v := strings.TrimSpace(os.Getenv("CLOUDPAM_AUDIT_RETENTION_INTERVAL"))
+	if v == "" {
+		return time.Hour
+	}
+	parsed, err := time.ParseDuration(v)
+	if err != nil || parsed < 0 || (parsed > 0 && parsed < time.Minute) {
+		logger.Warn("invalid CLOUDPAM_AUDIT_RETENTION_INTERVAL; using default", "value", v)
+		return time.Hour
+	}
+	return parsed
+}
+
+// auditSigningKey reads CLOUDPAM_AUDIT_SIGNING_KEY, the Ed25519 seed that
+// signs audit chain checkpoints. It returns nil when the key is unset and
+// exits on a malformed key rather than running without checkpoints.
+func auditSigningKey(logger observability.Logger) ed25519.PrivateKey {
+	v := strings.TrimSpace(os.Getenv("CLOUDPAM_AUDIT_SIGNING_KEY"))
+	if v == "" {
+		return nil
+	}
+	key, err := audit.ParseSigningKey(v)
+	if err != nil {
+		logger.Error("invalid CLOUDPAM_AUDIT_SIGNING_KEY", "error", err)
+		os.Exit(1)
+	}
+	return key
+}
+
+// auditCheckpointInterval reads CLOUDPAM_AUDIT_CHECKPOINT_INTERVAL,
+// defaulting to 1h. 0 disables checkpoints; shorter intervals than a minute
+// are rejected.
+func auditCheckpointInterval(logger observability.Logger) time.Duration {
+	v := strings.TrimSpace(os.Getenv("CLOUDPAM_AUDIT_CHECKPOINT_INTERVAL"))
+	if v == "" {
+		return time.Hour
+	}
+	parsed, err := time.ParseDuration(v)
+	if err != nil || parsed < 0 || (parsed > 0 && parsed < time.Minute) {
+		logger.Warn("invalid CLOUDPAM_AUDIT_CHECKPOINT_INTERVAL; using default", "value", v)
+		return time.Hour
+	}
+	return parsed
+}
+
+// enforceAuditRetention applies the stored retention settings once.
+func enforceAuditRetention(ctx context.Context, logger observability.Logger, enforcer audit.RetentionEnforcer, settings storage.SettingsStore) error {
+	cfg, err := settings.GetAuditRetentionSettings(ctx)
+	if err != nil {
+		return fmt.Errorf("load audit retention settings: %w", err)
+	}
+	deleted, err := enforcer.EnforcePolicy(ctx, auditRetentionPolicy(cfg))
+	if deleted > 0 {
+		logger.Info("audit retention deleted events", "deleted", deleted)
+	}
+	return err
+}
+
+// auditRetentionPolicy converts the day-based settings to a policy.
+func auditRetentionPolicy(cfg *domain.AuditRetentionSettings) audit.AuditRetentionPolicy {
+	const day = 24 * time.Hour
+	return audit.AuditRetentionPolicy{
+		MaxAge:           time.Duration(cfg.MaxAgeDays) * day,
+		MaxEvents:        cfg.MaxEvents,
+		RetainSuccessful: cfg.SuccessfulMaxAgeDays == 0,
+		SuccessfulMaxAge: time.Duration(cfg.SuccessfulMaxAgeDays) * day,
+	}
+}
+
 func envOr(k, def string) string {
 	if v := os.Getenv(k); v != "" {
 		return v
@@ -13,7 +13,9 @@ import (
 
 	"cloudpam/internal/audit"
 	"cloudpam/internal/auth"
+	"cloudpam/internal/domain"
 	"cloudpam/internal/observability"
+	"cloudpam/internal/storage"
 )
 
 // bufferedLogger returns a logger writing JSON records into buf so tests can
@@ -55,6 +57,123 @@ func TestEnvOrFallback(t *testing.T) {
 	}
 }
 
+func TestUtilizationSnapshotInterval(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", time.Hour},
+		{"15m", 15 * time.Minute},
+		{"0", 0},
+		{"10s", time.Hour},
+		{"-1h", time.Hour},
+		{"hourly", time.Hour},
+	}
+	for _, tc := range tests {
+		t.Setenv("CLOUDPAM_UTILIZATION_SNAPSHOT_INTERVAL", tc.value)
+		if got := utilizationSnapshotInterval(discardLogger()); got != tc.want {
+			t.Errorf("utilizationSnapshotInterval(%q) = %s, want %s", tc.value, got, tc.want)
+		}
+	}
+}
+
+func TestAlertEvaluationInterval(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 5 * time.Minute},
+		{"1m", time.Minute},
+		{"0", 0},
+		{"30s", 5 * time.Minute},
+		{"-5m", 5 * time.Minute},
+		{"often", 5 * time.Minute},
+	}
+	for _, tc := range tests {
+		t.Setenv("CLOUDPAM_ALERT_EVALUATION_INTERVAL", tc.value)
+		if got := alertEvaluationInterval(discardLogger()); got != tc.want {
+			t.Errorf("alertEvaluationInterval(%q) = %s, want %s", tc.value, got, tc.want)
+		}
+	}
+}
+
+func TestReservationExpiryInterval(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", time.Minute},
+		{"15m", 15 * time.Minute},
+		{"0", 0},
+		{"10s", time.Minute},
+		{"soon", time.Minute},
+	}
+	for _, tc := range tests {
+		t.Setenv("CLOUDPAM_RESERVATION_EXPIRY_INTERVAL", tc.value)
+		if got := reservationExpiryInterval(discardLogger()); got != tc.want {
+			t.Errorf("reservationExpiryInterval(%q) = %s, want %s", tc.value, got, tc.want)
+		}
+	}
+}
+
+func TestAuditRetentionInterval(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", time.Hour},
+		{"6h", 6 * time.Hour},
+		{"0", 0},
+		{"5s", time.Hour},
+		{"daily", time.Hour},
+	}
+	for _, tc := range tests {
+		t.Setenv("CLOUDPAM_AUDIT_RETENTION_INTERVAL", tc.value)
+		if got := auditRetentionInterval(discardLogger()); got != tc.want {
+			t.Errorf("auditRetentionInterval(%q) = %s, want %s", tc.value, got, tc.want)
+		}
+	}
+}
+
+func TestAuditSigningKey(t *testing.T) {
+	t.Setenv("CLOUDPAM_AUDIT_SIGNING_KEY", "")
+	if key := auditSigningKey(discardLogger()); key != nil {
+		t.Fatalf("auditSigningKey() with no key = %x, want nil", key)
+	}
+
+	seed := bytes.Repeat([]byte{7}, 32)
+	t.Setenv("CLOUDPAM_AUDIT_SIGNING_KEY", hex.EncodeToString(seed))
+	key := auditSigningKey(discardLogger())
+	if key == nil || !bytes.Equal(key.Seed(), seed) {
+		t.Fatalf("auditSigningKey() = %x, want the key for seed %x", key, seed)
+	}
+}
+
+func TestEnforceAuditRetentionUsesStoredSettings(t *testing.T) {
+	ctx := context.Background()
+	logger := audit.NewMemoryAuditLogger()
+	now := time.Now().UTC()
+	for _, e := range []*audit.AuditEvent{
+		{ID: "old", Timestamp: now.Add(-40 * 24 * time.Hour), StatusCode: 500},
+		{ID: "ok", Timestamp: now.Add(-10 * 24 * time.Hour), StatusCode: 200},
+		{ID: "failed", Timestamp: now.Add(-10 * 24 * time.Hour), StatusCode: 500},
+	} {
+		_ = logger.Log(ctx, e)
+	}
+	settings := storage.NewMemorySettingsStore()
+	if err := settings.UpdateAuditRetentionSettings(ctx, &domain.AuditRetentionSettings{MaxAgeDays: 30, SuccessfulMaxAgeDays: 7}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := enforceAuditRetention(ctx, discardLogger(), logger, settings); err != nil {
+		t.Fatalf("enforceAuditRetention: %v", err)
+	}
+	events, _, _ := logger.List(ctx, audit.ListOptions{})
+	if len(events) != 1 || events[0].ID != "failed" {
+		t.Errorf("remaining events = %+v", events)
+	}
+}
+
 // TestConfigureAuditSyslogForwardingDeliversCEF asserts the wiring in
 // configureAuditSyslogForwarding actually reaches the syslog endpoint and that
 // the device version carried in the CEF header is the cleaned app version.
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"cloudpam/internal/observability"
+	"cloudpam/internal/storage"
+)
+
+func selectAlertStore(logger observability.Logger, mainStore storage.Store) storage.AlertStore {
+	if as, ok := mainStore.(storage.AlertStore); ok {
+		return as
+	}
+	if _, ok := mainStore.(*storage.MemoryStore); !ok {
+		logger.Warn("main store does not implement AlertStore; using in-memory fallback")
+	}
+	return storage.NewMemoryAlertStore()
+}
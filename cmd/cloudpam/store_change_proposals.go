@@ -0,0 +1,16 @@
+package main
+
+import (
+	"cloudpam/internal/observability"
+	"cloudpam/internal/storage"
+)
+
+func selectChangeProposalStore(logger observability.Logger, mainStore storage.Store) storage.ChangeProposalStore {
+	if cs, ok := mainStore.(storage.ChangeProposalStore); ok {
+		return cs
+	}
+	if _, ok := mainStore.(*storage.MemoryStore); !ok {
+		logger.Warn("main store does not implement ChangeProposalStore; using in-memory fallback")
+	}
+	return storage.NewMemoryChangeProposalStore()
+}
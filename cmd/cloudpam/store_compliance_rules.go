@@ -0,0 +1,16 @@
+package main
+
+import (
+	"cloudpam/internal/observability"
+	"cloudpam/internal/storage"
+)
+
+func selectComplianceRuleStore(logger observability.Logger, mainStore storage.Store) storage.ComplianceRuleStore {
+	if cs, ok := mainStore.(storage.ComplianceRuleStore); ok {
+		return cs
+	}
+	if _, ok := mainStore.(*storage.MemoryStore); !ok {
+		logger.Warn("main store does not implement ComplianceRuleStore; using in-memory fallback")
+	}
+	return storage.NewMemoryComplianceRuleStore()
+}
@@ -162,6 +162,24 @@ func TestDefaultDerivedStoresAreUsable(t *testing.T) {
 	if got := selectOIDCProviderStore(logger, main); got == nil {
 		t.Error("selectOIDCProviderStore returned nil")
 	}
+	if got := selectUtilizationStore(logger, main); got == nil {
+		t.Error("selectUtilizationStore returned nil")
+	}
+	if got := selectAlertStore(logger, main); got == nil {
+		t.Error("selectAlertStore returned nil")
+	}
+	if got := selectComplianceRuleStore(logger, main); got == nil {
+		t.Error("selectComplianceRuleStore returned nil")
+	}
+	if got := selectChangeProposalStore(logger, main); got == nil {
+		t.Error("selectChangeProposalStore returned nil")
+	}
+	if got := selectPoolChangeRequestStore(logger, main); got == nil {
+		t.Error("selectPoolChangeRequestStore returned nil")
+	}
+	if got := selectReservationStore(logger, main); got == nil {
+		t.Error("selectReservationStore returned nil")
+	}
 }
 
 // TestMigrationStatusUnavailableInMemoryBuild asserts the no-tag binary reports
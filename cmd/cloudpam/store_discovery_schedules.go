@@ -0,0 +1,16 @@
+package main
+
+import (
+	"cloudpam/internal/observability"
+	"cloudpam/internal/storage"
+)
+
+func selectDiscoveryScheduleStore(logger observability.Logger, mainStore storage.Store) storage.DiscoveryScheduleStore {
+	if ss, ok := mainStore.(storage.DiscoveryScheduleStore); ok {
+		return ss
+	}
+	if _, ok := mainStore.(*storage.MemoryStore); !ok {
+		logger.Warn("main store does not implement DiscoveryScheduleStore; using in-memory fallback")
+	}
+	return storage.NewMemoryDiscoveryScheduleStore()
+}
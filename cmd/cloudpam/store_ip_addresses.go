@@ -0,0 +1,19 @@
+package main
+
+import (
+	"cloudpam/internal/observability"
+	"cloudpam/internal/storage"
+)
+
+func selectIPAddressStore(logger observability.Logger, mainStore storage.Store) storage.IPAddressStore {
+	if is, ok := mainStore.(storage.IPAddressStore); ok {
+		return is
+	}
+	// The in-memory store attaches to the main store so pool stats count
+	// its records.
+	if ms, ok := mainStore.(*storage.MemoryStore); ok {
+		return storage.NewMemoryIPAddressStore(ms)
+	}
+	logger.Warn("main store does not implement IPAddressStore; using in-memory fallback")
+	return storage.NewMemoryIPAddressStore(storage.NewMemoryStore())
+}
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"cloudpam/internal/observability"
+	"cloudpam/internal/storage"
+)
+
+func selectPoolChangeRequestStore(logger observability.Logger, mainStore storage.Store) storage.PoolChangeRequestStore {
+	if cs, ok := mainStore.(storage.PoolChangeRequestStore); ok {
+		return cs
+	}
+	if _, ok := mainStore.(*storage.MemoryStore); !ok {
+		logger.Warn("main store does not implement PoolChangeRequestStore; using in-memory fallback")
+	}
+	return storage.NewMemoryPoolChangeRequestStore()
+}
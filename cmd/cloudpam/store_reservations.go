@@ -0,0 +1,16 @@
+package main
+
+import (
+	"cloudpam/internal/observability"
+	"cloudpam/internal/storage"
+)
+
+func selectReservationStore(logger observability.Logger, mainStore storage.Store) storage.ReservationStore {
+	if rs, ok := mainStore.(storage.ReservationStore); ok {
+		return rs
+	}
+	if _, ok := mainStore.(*storage.MemoryStore); !ok {
+		logger.Warn("main store does not implement ReservationStore; using in-memory fallback")
+	}
+	return storage.NewMemoryReservationStore()
+}
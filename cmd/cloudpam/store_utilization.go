@@ -0,0 +1,16 @@
+package main
+
+import (
+	"cloudpam/internal/observability"
+	"cloudpam/internal/storage"
+)
+
+func selectUtilizationStore(logger observability.Logger, mainStore storage.Store) storage.UtilizationStore {
+	if us, ok := mainStore.(storage.UtilizationStore); ok {
+		return us
+	}
+	if _, ok := mainStore.(*storage.MemoryStore); !ok {
+		logger.Warn("main store does not implement UtilizationStore; using in-memory fallback")
+	}
+	return storage.NewMemoryUtilizationStore()
+}
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"cloudpam/internal/observability"
+	"cloudpam/internal/storage"
+)
+
+func selectWebhookStore(logger observability.Logger, mainStore storage.Store) storage.WebhookStore {
+	if ws, ok := mainStore.(storage.WebhookStore); ok {
+		return ws
+	}
+	logger.Warn("main store does not implement WebhookStore; using in-memory fallback")
+	return storage.NewMemoryWebhookStore()
+}
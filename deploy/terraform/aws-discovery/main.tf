@@ -75,6 +75,7 @@ data "aws_iam_policy_document" "discovery" {
       "ec2:DescribeVpcs",
       "ec2:DescribeSubnets",
       "ec2:DescribeAddresses",
+      "ec2:DescribeNetworkInterfaces",
     ]
 
     resources = ["*"]
@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -11,6 +12,7 @@ import (
 	"cloudpam/internal/audit"
 	"cloudpam/internal/auth"
 	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
 	"cloudpam/internal/validation"
 )
 
@@ -74,6 +76,7 @@ func (s *Server) protectedAccountsSubroutesHandler(logger *slog.Logger) http.Han
 				s.writeErr(ctx, w, http.StatusNotFound, "not found", "")
 				return
 			}
+			w.Header().Set("ETag", etag(a.Version))
 			writeJSON(w, http.StatusOK, a)
 
 		case http.MethodPatch:
@@ -93,7 +96,15 @@ func (s *Server) protectedAccountsSubroutesHandler(logger *slog.Logger) http.Han
 					return
 				}
 			}
+			if in.Version, err = expectedAccountVersion(ctx, s.store, r, id); err != nil {
+				s.writeStoreErr(ctx, w, err)
+				return
+			}
 			a, ok, err := s.store.UpdateAccount(ctx, id, in)
+			if errors.Is(err, storage.ErrPreconditionFailed) {
+				s.writeStoreErr(ctx, w, err)
+				return
+			}
 			if err != nil {
 				s.writeErr(ctx, w, http.StatusBadRequest, err.Error(), "")
 				return
@@ -102,6 +113,7 @@ func (s *Server) protectedAccountsSubroutesHandler(logger *slog.Logger) http.Han
 				s.writeErr(ctx, w, http.StatusNotFound, "not found", "")
 				return
 			}
+			w.Header().Set("ETag", etag(a.Version))
 			writeJSON(w, http.StatusOK, a)
 
 		case http.MethodDelete:
@@ -109,15 +121,9 @@ func (s *Server) protectedAccountsSubroutesHandler(logger *slog.Logger) http.Han
 				writeJSON(w, http.StatusForbidden, apiError{Error: "forbidden"})
 				return
 			}
-			var ok bool
-			force := strings.ToLower(r.URL.Query().Get("force"))
-			if force == "1" || force == "true" || force == "yes" {
-				ok, err = s.store.DeleteAccountCascade(ctx, id)
-			} else {
-				ok, err = s.store.DeleteAccount(ctx, id)
-			}
+			ok, err := s.deleteAccount(ctx, r, id, isTruthy(r.URL.Query().Get("force")))
 			if err != nil {
-				s.writeErr(ctx, w, http.StatusConflict, err.Error(), "")
+				s.writeDeleteErr(ctx, w, err)
 				return
 			}
 			if !ok {
@@ -169,6 +175,7 @@ func (s *Server) handleAccountsSubroutes(w http.ResponseWriter, r *http.Request)
 			s.writeErr(r.Context(), w, http.StatusNotFound, "not found", "")
 			return
 		}
+		w.Header().Set("ETag", etag(a.Version))
 		writeJSON(w, http.StatusOK, a)
 	case http.MethodPatch:
 		var in domain.Account
@@ -184,7 +191,15 @@ func (s *Server) handleAccountsSubroutes(w http.ResponseWriter, r *http.Request)
 				return
 			}
 		}
+		if in.Version, err = expectedAccountVersion(r.Context(), s.store, r, id); err != nil {
+			s.writeStoreErr(r.Context(), w, err)
+			return
+		}
 		a, ok, err := s.store.UpdateAccount(r.Context(), id, in)
+		if errors.Is(err, storage.ErrPreconditionFailed) {
+			s.writeStoreErr(r.Context(), w, err)
+			return
+		}
 		if err != nil {
 			s.writeErr(r.Context(), w, http.StatusBadRequest, err.Error(), "")
 			return
@@ -194,19 +209,14 @@ func (s *Server) handleAccountsSubroutes(w http.ResponseWriter, r *http.Request)
 			return
 		}
 		s.logAudit(r.Context(), audit.ActionUpdate, audit.ResourceAccount, fmt.Sprintf("%d", a.ID), a.Name, http.StatusOK)
+		w.Header().Set("ETag", etag(a.Version))
 		writeJSON(w, http.StatusOK, a)
 	case http.MethodDelete:
 		// Get account info before delete for audit logging
 		acct, acctFound, _ := s.store.GetAccount(r.Context(), id)
-		var ok bool
-		force := strings.ToLower(r.URL.Query().Get("force"))
-		if force == "1" || force == "true" || force == "yes" {
-			ok, err = s.store.DeleteAccountCascade(r.Context(), id)
-		} else {
-			ok, err = s.store.DeleteAccount(r.Context(), id)
-		}
+		ok, err := s.deleteAccount(r.Context(), r, id, isTruthy(r.URL.Query().Get("force")))
 		if err != nil {
-			s.writeErr(r.Context(), w, http.StatusConflict, err.Error(), "")
+			s.writeDeleteErr(r.Context(), w, err)
 			return
 		}
 		if !ok {
@@ -226,16 +236,17 @@ func (s *Server) handleAccountsSubroutes(w http.ResponseWriter, r *http.Request)
 
 func (s *Server) listAccounts(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	accs, err := s.store.ListAccounts(ctx)
+	opts, pg, err := parseAccountQuery(r.URL.Query())
 	if err != nil {
-		s.writeErr(r.Context(), w, http.StatusInternalServerError, "internal error", err.Error())
+		s.writeErr(ctx, w, http.StatusBadRequest, err.Error(), "")
 		return
 	}
-	if accs == nil {
-		accs = []domain.Account{}
+	accs, total, err := s.queryAccounts(ctx, opts)
+	if err != nil {
+		s.writeStoreErr(ctx, w, err)
+		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(accs)
+	writeList(w, accs, total, pg)
 }
 
 func (s *Server) createAccount(w http.ResponseWriter, r *http.Request) {
@@ -12,7 +12,6 @@ import (
 	"cloudpam/internal/domain"
 	"cloudpam/internal/planning"
 	"cloudpam/internal/storage"
-	"cloudpam/internal/validation"
 )
 
 // AIPlanningServer handles AI planning API endpoints.
@@ -206,7 +205,8 @@ func (a *AIPlanningServer) handleDeleteSession(w http.ResponseWriter, r *http.Re
 	writeJSON(w, http.StatusOK, map[string]string{"status": "deleted"})
 }
 
-// handleApplyPlan applies a generated plan from a conversation.
+// handleApplyPlan applies a generated plan from a conversation, or with
+// dry_run or propose, reports or proposes the change without creating it.
 // POST /api/v1/ai/sessions/{id}/apply-plan
 func (a *AIPlanningServer) handleApplyPlan(w http.ResponseWriter, r *http.Request, sessionID string) {
 	ctx := r.Context()
@@ -222,112 +222,64 @@ func (a *AIPlanningServer) handleApplyPlan(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// Validate all entries
-	refSet := make(map[string]bool)
-	for i, p := range req.Plan.Pools {
-		if p.Ref == "" {
-			a.srv.writeErr(ctx, w, http.StatusBadRequest, fmt.Sprintf("pool %d: ref is required", i), "")
-			return
-		}
-		if refSet[p.Ref] {
-			a.srv.writeErr(ctx, w, http.StatusBadRequest, fmt.Sprintf("pool %d: duplicate ref %q", i, p.Ref), "")
-			return
-		}
-		refSet[p.Ref] = true
-
-		if err := validation.ValidateName(p.Name); err != nil {
-			a.srv.writeErr(ctx, w, http.StatusBadRequest, fmt.Sprintf("pool %d (%s): %v", i, p.Ref, err), "")
-			return
-		}
-		if err := validation.ValidateCIDRWithOptions(p.CIDR, validation.CIDROptions{
-			MinPrefix: 8,
-			MaxPrefix: 30,
-		}); err != nil {
-			a.srv.writeErr(ctx, w, http.StatusBadRequest, fmt.Sprintf("pool %d (%s): %v", i, p.Ref, err), "")
-			return
-		}
-		if p.ParentRef != "" {
-			found := false
-			for _, prev := range req.Plan.Pools[:i] {
-				if prev.Ref == p.ParentRef {
-					found = true
-					break
-				}
-			}
-			if !found {
-				a.srv.writeErr(ctx, w, http.StatusBadRequest,
-					fmt.Sprintf("pool %d (%s): parent_ref %q not found in preceding entries", i, p.Ref, p.ParentRef), "")
-				return
-			}
-		}
+	if msg := validatePoolTree(req.Plan.Pools); msg != "" {
+		a.srv.writeErr(ctx, w, http.StatusBadRequest, msg, "")
+		return
 	}
-
-	// Create pools in topological order
-	refToID := make(map[string]int64)
-	var created, skipped int
-	var errs []string
-	var rootPoolID int64
-
-	tags := map[string]string{
-		"ai_planner": "true",
-		"session_id": sessionID,
+	tree := domain.PoolTree{
+		Pools:  req.Plan.Pools,
+		Status: domain.PoolStatusPlanned,
+		Tags: map[string]string{
+			"ai_planner": "true",
+			"session_id": sessionID,
+		},
+		Description: "Created by AI Planner",
 	}
 
-	for _, p := range req.Plan.Pools {
-		poolType := domain.PoolType(p.Type)
-		if poolType == "" {
-			poolType = domain.PoolTypeSubnet
-		}
-		if !domain.IsValidPoolType(poolType) {
-			errs = append(errs, fmt.Sprintf("pool %q: invalid type %q, using subnet", p.Ref, p.Type))
-			poolType = domain.PoolTypeSubnet
-		}
-
-		cp := domain.CreatePool{
-			Name:        p.Name,
-			CIDR:        p.CIDR,
-			Type:        poolType,
-			Status:      domain.PoolStatusPlanned,
-			Source:      domain.PoolSourceManual,
-			Description: "Created by AI Planner",
-			Tags:        tags,
-		}
-
-		if p.ParentRef != "" {
-			parentID, ok := refToID[p.ParentRef]
-			if !ok {
-				errs = append(errs, fmt.Sprintf("pool %q: parent_ref %q not yet created", p.Ref, p.ParentRef))
-				skipped++
-				continue
-			}
-			cp.ParentID = &parentID
+	switch {
+	case req.Propose:
+		title := req.Title
+		if title == "" {
+			title = req.Plan.Name
 		}
-
-		pool, err := a.srv.store.CreatePool(ctx, cp)
+		a.srv.proposePoolTree(w, r, domain.ChangeProposal{
+			Title:     title,
+			Source:    domain.ChangeProposalSourceAIPlan,
+			SessionID: sessionID,
+			Tree:      tree,
+		})
+		return
+	case req.DryRun:
+		cs, err := a.srv.planPoolTree(ctx, a.srv.store, tree)
 		if err != nil {
-			errs = append(errs, fmt.Sprintf("pool %q: %v", p.Ref, err))
-			skipped++
-			continue
-		}
-
-		refToID[p.Ref] = pool.ID
-		if p.ParentRef == "" {
-			rootPoolID = pool.ID
+			a.srv.writeErr(ctx, w, http.StatusInternalServerError, "failed to check existing pools", err.Error())
+			return
 		}
-		created++
-
-		a.srv.logAudit(ctx, "create", "pool", fmt.Sprintf("%d", pool.ID), pool.Name, http.StatusCreated)
+		writeJSON(w, http.StatusOK, cs)
+		return
 	}
 
-	resp := map[string]any{
-		"created":      created,
-		"skipped":      skipped,
-		"errors":       errs,
-		"root_pool_id": rootPoolID,
-		"pool_map":     refToID,
+	// Create pools in topological order, all in one transaction so a failed
+	// apply never leaves half a hierarchy behind.
+	var res poolTreeResult
+	err := a.srv.withTx(ctx, func(st storage.Store) error {
+		var err error
+		res, err = a.srv.createPoolTree(ctx, st, tree)
+		return err
+	})
+	if err != nil {
+		a.srv.writeStoreErr(ctx, w, err)
+		return
 	}
-	if errs == nil {
-		resp["errors"] = []string{}
+	for _, pool := range res.Created {
+		a.srv.logAudit(ctx, "create", "pool", fmt.Sprintf("%d", pool.ID), pool.Name, http.StatusCreated)
 	}
-	writeJSON(w, http.StatusOK, resp)
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"created":      len(res.Created),
+		"skipped":      0,
+		"errors":       res.Warnings,
+		"root_pool_id": res.RootPoolID,
+		"pool_map":     res.PoolMap,
+	})
 }
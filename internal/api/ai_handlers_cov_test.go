@@ -393,7 +393,8 @@ func TestAIApplyPlanCreatesHierarchyCov(t *testing.T) {
 }
 
 // failingPoolStoreCov rejects CreatePool for pools whose name matches failName,
-// simulating a storage-layer failure mid-plan.
+// simulating a storage-layer failure mid-plan. Transactions it hands out fail
+// the same way.
 type failingPoolStoreCov struct {
 	*storage.MemoryStore
 	failName string
@@ -406,7 +407,25 @@ func (s *failingPoolStoreCov) CreatePool(ctx context.Context, in domain.CreatePo
 	return s.MemoryStore.CreatePool(ctx, in)
 }
 
-func TestAIApplyPlanSkipsFailedAndOrphanedPoolsCov(t *testing.T) {
+func (s *failingPoolStoreCov) WithTx(ctx context.Context, fn func(tx storage.Transaction) error) error {
+	return s.MemoryStore.WithTx(ctx, func(tx storage.Transaction) error {
+		return fn(&failingPoolTxCov{Transaction: tx, failName: s.failName})
+	})
+}
+
+type failingPoolTxCov struct {
+	storage.Transaction
+	failName string
+}
+
+func (t *failingPoolTxCov) CreatePool(ctx context.Context, in domain.CreatePool) (domain.Pool, error) {
+	if in.Name == t.failName {
+		return domain.Pool{}, errors.New("simulated storage failure")
+	}
+	return t.Transaction.CreatePool(ctx, in)
+}
+
+func TestAIApplyPlanRollsBackOnFailureCov(t *testing.T) {
 	base := storage.NewMemoryStore()
 	st := &failingPoolStoreCov{MemoryStore: base, failName: "Doomed"}
 	mux := http.NewServeMux()
@@ -421,40 +440,17 @@ func TestAIApplyPlanSkipsFailedAndOrphanedPoolsCov(t *testing.T) {
 		{"ref":"bad","name":"Doomed","cidr":"10.0.0.0/16","type":"supernet"},
 		{"ref":"orphan","name":"Orphan","cidr":"10.0.1.0/24","type":"subnet","parent_ref":"bad"}
 	]}}`
-	rr := assertStatusCov(t, doReqCov(t, mux, http.MethodPost, "/api/v1/ai/sessions/s/apply-plan", body), http.StatusOK)
-
-	var resp struct {
-		Created    int              `json:"created"`
-		Skipped    int              `json:"skipped"`
-		Errors     []string         `json:"errors"`
-		RootPoolID int64            `json:"root_pool_id"`
-		PoolMap    map[string]int64 `json:"pool_map"`
-	}
-	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
-		t.Fatalf("unmarshal: %v", err)
-	}
-	if resp.Created != 1 || resp.Skipped != 2 {
-		t.Fatalf("created/skipped = %d/%d, want 1/2", resp.Created, resp.Skipped)
-	}
-	joined := strings.Join(resp.Errors, "|")
-	if !strings.Contains(joined, "simulated storage failure") {
-		t.Fatalf("expected the storage failure to be reported, got %#v", resp.Errors)
-	}
-	if !strings.Contains(joined, "not yet created") {
-		t.Fatalf("expected the orphaned child to be reported, got %#v", resp.Errors)
-	}
-	if _, ok := resp.PoolMap["bad"]; ok {
-		t.Fatal("failed pool must not appear in pool_map")
-	}
-	if _, ok := resp.PoolMap["orphan"]; ok {
-		t.Fatal("orphaned pool must not appear in pool_map")
+	rr := assertStatusCov(t, doReqCov(t, mux, http.MethodPost, "/api/v1/ai/sessions/s/apply-plan", body), http.StatusInternalServerError)
+	if e := decodeErrCov(t, rr); !strings.Contains(e.Detail, "simulated storage failure") || !strings.Contains(e.Detail, `pool "bad"`) {
+		t.Fatalf("expected the failing pool to be reported, got %+v", e)
 	}
+
 	pools, err := base.ListPools(t.Context())
 	if err != nil {
 		t.Fatalf("list pools: %v", err)
 	}
-	if len(pools) != 1 || pools[0].Name != "Fresh" {
-		t.Fatalf("expected only the healthy pool to be created, got %+v", pools)
+	if len(pools) != 0 {
+		t.Fatalf("a failed apply must not leave pools behind, got %+v", pools)
 	}
 }
 
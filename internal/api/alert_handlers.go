@@ -0,0 +1,232 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"cloudpam/internal/audit"
+	"cloudpam/internal/auth"
+	"cloudpam/internal/domain"
+	"cloudpam/internal/planning"
+	"cloudpam/internal/storage"
+)
+
+// AlertServer serves capacity alerts and the alert rule and notification
+// channel settings.
+type AlertServer struct {
+	srv        *Server
+	alerts     *planning.AlertService
+	alertStore storage.AlertStore
+}
+
+// NewAlertServer creates a new AlertServer.
+func NewAlertServer(srv *Server, alerts *planning.AlertService, alertStore storage.AlertStore) *AlertServer {
+	return &AlertServer{srv: srv, alerts: alerts, alertStore: alertStore}
+}
+
+// RegisterProtectedAlertRoutes registers alert routes with RBAC. Alerts are
+// pool data; their rules and channels are settings.
+func (as *AlertServer) RegisterProtectedAlertRoutes(dualMW Middleware, logger *slog.Logger) {
+	listMW := RequirePermissionMiddleware(auth.ResourcePools, auth.ActionList, logger)
+	readMW := RequirePermissionMiddleware(auth.ResourcePools, auth.ActionRead, logger)
+	updateMW := RequirePermissionMiddleware(auth.ResourcePools, auth.ActionUpdate, logger)
+	settingsRead := RequirePermissionMiddleware(auth.ResourceSettings, auth.ActionRead, logger)
+	settingsWrite := RequirePermissionMiddleware(auth.ResourceSettings, auth.ActionWrite, logger)
+
+	as.srv.handleOpenAPIRoute("GET /api/v1/alerts", dualMW(listMW(http.HandlerFunc(as.handleList))))
+	as.srv.handleOpenAPIRoute("POST /api/v1/alerts/evaluate", dualMW(updateMW(http.HandlerFunc(as.handleEvaluate))))
+	as.srv.handleOpenAPIRoute("GET /api/v1/alerts/{id}", dualMW(readMW(http.HandlerFunc(as.handleGet))))
+	as.srv.handleOpenAPIRoute("POST /api/v1/alerts/{id}/acknowledge", dualMW(updateMW(http.HandlerFunc(as.handleAcknowledge))))
+	as.srv.handleOpenAPIRoute("POST /api/v1/alerts/{id}/resolve", dualMW(updateMW(http.HandlerFunc(as.handleResolve))))
+	as.srv.handleOpenAPIRoute("GET /api/v1/settings/alerts", dualMW(settingsRead(http.HandlerFunc(as.handleGetSettings))))
+	as.srv.handleOpenAPIRoute("PATCH /api/v1/settings/alerts", dualMW(settingsWrite(http.HandlerFunc(as.handleUpdateSettings))))
+	as.srv.handleOpenAPIRoute("POST /api/v1/settings/alerts/channels/{id}/test", dualMW(settingsWrite(http.HandlerFunc(as.handleTestChannel))))
+}
+
+// RegisterAlertRoutesNoAuth registers alert routes without auth middleware (for tests).
+func (as *AlertServer) RegisterAlertRoutesNoAuth() {
+	as.srv.handleOpenAPIRouteFunc("GET /api/v1/alerts", as.handleList)
+	as.srv.handleOpenAPIRouteFunc("POST /api/v1/alerts/evaluate", as.handleEvaluate)
+	as.srv.handleOpenAPIRouteFunc("GET /api/v1/alerts/{id}", as.handleGet)
+	as.srv.handleOpenAPIRouteFunc("POST /api/v1/alerts/{id}/acknowledge", as.handleAcknowledge)
+	as.srv.handleOpenAPIRouteFunc("POST /api/v1/alerts/{id}/resolve", as.handleResolve)
+	as.srv.handleOpenAPIRouteFunc("GET /api/v1/settings/alerts", as.handleGetSettings)
+	as.srv.handleOpenAPIRouteFunc("PATCH /api/v1/settings/alerts", as.handleUpdateSettings)
+	as.srv.handleOpenAPIRouteFunc("POST /api/v1/settings/alerts/channels/{id}/test", as.handleTestChannel)
+}
+
+// handleList lists alerts, most recently opened first. status accepts
+// open, acknowledged, resolved, or active (open or acknowledged).
+// GET /api/v1/alerts
+func (as *AlertServer) handleList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	q := r.URL.Query()
+	filters := domain.AlertFilters{
+		Status:   q.Get("status"),
+		Severity: q.Get("severity"),
+		Kind:     q.Get("kind"),
+	}
+	if filters.Status != "" && !domain.IsValidAlertStatusFilter(filters.Status) {
+		as.srv.writeErr(ctx, w, http.StatusBadRequest, "invalid status", "use open, acknowledged, resolved, or active")
+		return
+	}
+	switch domain.AlertSeverity(filters.Severity) {
+	case "", domain.AlertSeverityWarning, domain.AlertSeverityCritical:
+	default:
+		as.srv.writeErr(ctx, w, http.StatusBadRequest, "invalid severity", "use warning or critical")
+		return
+	}
+	switch domain.AlertKind(filters.Kind) {
+	case "", domain.AlertKindUtilization, domain.AlertKindExhaustion:
+	default:
+		as.srv.writeErr(ctx, w, http.StatusBadRequest, "invalid kind", "use utilization or exhaustion")
+		return
+	}
+	if v := q.Get("pool_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			as.srv.writeErr(ctx, w, http.StatusBadRequest, "invalid pool_id", err.Error())
+			return
+		}
+		filters.PoolID = id
+	}
+	if v := q.Get("page"); v != "" {
+		if p, err := strconv.Atoi(v); err == nil {
+			filters.Page = p
+		}
+	}
+	if v := q.Get("page_size"); v != "" {
+		if ps, err := strconv.Atoi(v); err == nil {
+			filters.PageSize = ps
+		}
+	}
+
+	items, total, err := as.alertStore.ListAlerts(ctx, filters)
+	if err != nil {
+		as.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+	page := filters.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := filters.PageSize
+	if pageSize < 1 {
+		pageSize = 50
+	}
+	writeJSON(w, http.StatusOK, domain.AlertListResponse{Items: items, Total: total, Page: page, PageSize: pageSize})
+}
+
+// handleGet returns one alert.
+// GET /api/v1/alerts/{id}
+func (as *AlertServer) handleGet(w http.ResponseWriter, r *http.Request) {
+	a, err := as.alertStore.GetAlert(r.Context(), r.PathValue("id"))
+	if err != nil {
+		as.srv.writeStoreErr(r.Context(), w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, a)
+}
+
+// handleAcknowledge acknowledges an open alert.
+// POST /api/v1/alerts/{id}/acknowledge
+func (as *AlertServer) handleAcknowledge(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	a, err := as.alerts.Acknowledge(ctx, r.PathValue("id"), alertActor(r))
+	if err != nil {
+		as.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+	as.srv.logAudit(ctx, audit.ActionUpdate, audit.ResourceAlert, a.ID, a.PoolName, http.StatusOK)
+	writeJSON(w, http.StatusOK, a)
+}
+
+// handleResolve resolves an active alert. If the pool still breaches the
+// rule, the next evaluation opens a new alert.
+// POST /api/v1/alerts/{id}/resolve
+func (as *AlertServer) handleResolve(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	a, err := as.alerts.Resolve(ctx, r.PathValue("id"), alertActor(r))
+	if err != nil {
+		as.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+	as.srv.logAudit(ctx, audit.ActionUpdate, audit.ResourceAlert, a.ID, a.PoolName, http.StatusOK)
+	writeJSON(w, http.StatusOK, a)
+}
+
+// handleEvaluate runs the alert rules now instead of waiting for the
+// background evaluation. Pools that could not be evaluated are listed in
+// the response's errors.
+// POST /api/v1/alerts/evaluate
+func (as *AlertServer) handleEvaluate(w http.ResponseWriter, r *http.Request) {
+	resp, err := as.alerts.Evaluate(r.Context())
+	if resp == nil {
+		as.srv.writeStoreErr(r.Context(), w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleGetSettings returns the alert rules and notification channels.
+// GET /api/v1/settings/alerts
+func (as *AlertServer) handleGetSettings(w http.ResponseWriter, r *http.Request) {
+	settings, err := as.alerts.Settings(r.Context())
+	if err != nil {
+		as.srv.writeErr(r.Context(), w, http.StatusInternalServerError, "failed to load alert settings", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, settings)
+}
+
+// handleUpdateSettings replaces the alert rules and notification channels.
+// PATCH /api/v1/settings/alerts
+func (as *AlertServer) handleUpdateSettings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var input domain.AlertSettings
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		as.srv.writeErr(ctx, w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+	settings, err := as.alerts.UpdateSettings(ctx, &input)
+	if errors.Is(err, storage.ErrValidation) {
+		as.srv.writeErr(ctx, w, http.StatusBadRequest, "invalid alert settings", err.Error())
+		return
+	}
+	if err != nil {
+		as.srv.writeErr(ctx, w, http.StatusInternalServerError, "failed to save alert settings", err.Error())
+		return
+	}
+	as.srv.logAudit(ctx, "update", "settings", "alerting", "alert_settings", http.StatusOK)
+	writeJSON(w, http.StatusOK, settings)
+}
+
+// handleTestChannel sends a sample notification through a channel and
+// reports whether it was accepted.
+// POST /api/v1/settings/alerts/channels/{id}/test
+func (as *AlertServer) handleTestChannel(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	err := as.alerts.TestChannel(ctx, r.PathValue("id"))
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusNoContent)
+	case errors.Is(err, storage.ErrNotFound), errors.Is(err, storage.ErrValidation):
+		as.srv.writeStoreErr(ctx, w, err)
+	default:
+		as.srv.writeErr(ctx, w, http.StatusBadGateway, "notification failed", err.Error())
+	}
+}
+
+// alertActor names the user or API key acting on an alert.
+func alertActor(r *http.Request) string {
+	if user := auth.UserFromContext(r.Context()); user != nil {
+		return user.Username
+	}
+	if key := auth.APIKeyFromContext(r.Context()); key != nil {
+		return "apikey:" + key.Name
+	}
+	return "anonymous"
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/notify"
+	"cloudpam/internal/planning"
+	"cloudpam/internal/storage"
+)
+
+func setupAlertTestEnv(t *testing.T) (*http.ServeMux, *storage.MemoryStore) {
+	t.Helper()
+	st := storage.NewMemoryStore()
+	mux := http.NewServeMux()
+	srv := NewServer(mux, st, nil, nil, nil)
+	srv.registerUnprotectedTestRoutes()
+	alertStore := storage.NewMemoryAlertStore()
+	svc := planning.NewAlertService(st, storage.NewMemorySettingsStore(), alertStore, notify.NewRegistry(notify.Options{}))
+	NewAlertServer(srv, svc, alertStore).RegisterAlertRoutesNoAuth()
+	return mux, st
+}
+
+func TestAlertHandlers_Lifecycle(t *testing.T) {
+	mux, st := setupAlertTestEnv(t)
+	ctx := context.Background()
+	var received []notify.Notification
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n notify.Notification
+		if err := json.NewDecoder(r.Body).Decode(&n); err != nil {
+			t.Error(err)
+		}
+		received = append(received, n)
+	}))
+	defer hook.Close()
+
+	rr := doJSON(t, mux, http.MethodGet, "/api/v1/settings/alerts", "", http.StatusOK)
+	var settings domain.AlertSettings
+	if err := json.Unmarshal(rr.Body.Bytes(), &settings); err != nil || len(settings.Rules) != 1 {
+		t.Fatalf("default settings = %s", rr.Body.String())
+	}
+	body := fmt.Sprintf(`{"rules":[{"id":"default","enabled":true,"warning_percent":80,"critical_percent":90}],
+		"channels":[{"id":"ops","name":"Ops","type":"webhook","enabled":true,"url":%q}]}`, hook.URL)
+	doJSON(t, mux, http.MethodPatch, "/api/v1/settings/alerts", body, http.StatusOK)
+	doJSON(t, mux, http.MethodPost, "/api/v1/settings/alerts/channels/ops/test", "", http.StatusNoContent)
+	if len(received) != 1 || received[0].Event != notify.EventTest {
+		t.Fatalf("test notification = %+v", received)
+	}
+
+	pool, err := st.CreatePool(ctx, domain.CreatePool{Name: "prod", CIDR: "10.0.0.0/24"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := st.CreatePool(ctx, domain.CreatePool{Name: "full", CIDR: "10.0.0.0/24", ParentID: &pool.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	rr = doJSON(t, mux, http.MethodPost, "/api/v1/alerts/evaluate", "", http.StatusOK)
+	var eval domain.AlertEvaluationResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &eval); err != nil || eval.Opened != 1 {
+		t.Fatalf("evaluate = %s", rr.Body.String())
+	}
+	if len(received) != 2 || received[1].Event != notify.EventAlertOpened || received[1].Alert.PoolID != pool.ID {
+		t.Fatalf("notifications = %+v", received)
+	}
+
+	rr = doJSON(t, mux, http.MethodGet, "/api/v1/alerts?status=active&severity=critical", "", http.StatusOK)
+	var list domain.AlertListResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &list); err != nil || list.Total != 1 || list.PageSize != 50 {
+		t.Fatalf("list = %s", rr.Body.String())
+	}
+	id := list.Items[0].ID
+	doJSON(t, mux, http.MethodGet, "/api/v1/alerts/"+id, "", http.StatusOK)
+
+	rr = doJSON(t, mux, http.MethodPost, "/api/v1/alerts/"+id+"/acknowledge", "", http.StatusOK)
+	var alert domain.Alert
+	if err := json.Unmarshal(rr.Body.Bytes(), &alert); err != nil || alert.Status != domain.AlertStatusAcknowledged || alert.AcknowledgedBy != "anonymous" {
+		t.Fatalf("acknowledge = %s", rr.Body.String())
+	}
+	doJSON(t, mux, http.MethodPost, "/api/v1/alerts/"+id+"/acknowledge", "", http.StatusConflict)
+
+	rr = doJSON(t, mux, http.MethodPost, "/api/v1/alerts/"+id+"/resolve", "", http.StatusOK)
+	if err := json.Unmarshal(rr.Body.Bytes(), &alert); err != nil || alert.Status != domain.AlertStatusResolved {
+		t.Fatalf("resolve = %s", rr.Body.String())
+	}
+	doJSON(t, mux, http.MethodPost, "/api/v1/alerts/"+id+"/resolve", "", http.StatusConflict)
+	if last := received[len(received)-1]; last.Event != notify.EventAlertResolved {
+		t.Fatalf("last notification = %+v", last)
+	}
+}
+
+func TestAlertHandlers_Validation(t *testing.T) {
+	mux, _ := setupAlertTestEnv(t)
+
+	doJSON(t, mux, http.MethodGet, "/api/v1/alerts?status=closed", "", http.StatusBadRequest)
+	doJSON(t, mux, http.MethodGet, "/api/v1/alerts?severity=info", "", http.StatusBadRequest)
+	doJSON(t, mux, http.MethodGet, "/api/v1/alerts?kind=latency", "", http.StatusBadRequest)
+	doJSON(t, mux, http.MethodGet, "/api/v1/alerts?pool_id=x", "", http.StatusBadRequest)
+	doJSON(t, mux, http.MethodGet, "/api/v1/alerts/missing", "", http.StatusNotFound)
+	doJSON(t, mux, http.MethodPost, "/api/v1/alerts/missing/acknowledge", "", http.StatusNotFound)
+
+	doJSON(t, mux, http.MethodPatch, "/api/v1/settings/alerts", `{`, http.StatusBadRequest)
+	doJSON(t, mux, http.MethodPatch, "/api/v1/settings/alerts", `{"rules":[{"id":"r","warning_percent":120}]}`, http.StatusBadRequest)
+	doJSON(t, mux, http.MethodPatch, "/api/v1/settings/alerts", `{"channels":[{"id":"c","type":"slack","url":"mailto:x"}]}`, http.StatusBadRequest)
+	doJSON(t, mux, http.MethodPost, "/api/v1/settings/alerts/channels/missing/test", "", http.StatusNotFound)
+
+	// An unreachable endpoint surfaces as a gateway error.
+	doJSON(t, mux, http.MethodPatch, "/api/v1/settings/alerts", `{"channels":[{"id":"c","type":"webhook","url":"http://127.0.0.1:1/hook"}]}`, http.StatusOK)
+	doJSON(t, mux, http.MethodPost, "/api/v1/settings/alerts/channels/c/test", "", http.StatusBadGateway)
+}
@@ -7,12 +7,14 @@ import (
 
 	"cloudpam/internal/auth"
 	"cloudpam/internal/planning"
+	"cloudpam/internal/storage"
 )
 
 // AnalysisServer handles analysis API endpoints.
 type AnalysisServer struct {
 	srv      *Server
 	analysis *planning.AnalysisService
+	rules    storage.ComplianceRuleStore
 }
 
 // NewAnalysisServer creates a new AnalysisServer.
@@ -20,12 +22,25 @@ func NewAnalysisServer(srv *Server, analysis *planning.AnalysisService) *Analysi
 	return &AnalysisServer{srv: srv, analysis: analysis}
 }
 
+// SetComplianceRuleStore enables the /api/v1/analysis/rules endpoints. Call
+// it before registering routes.
+func (a *AnalysisServer) SetComplianceRuleStore(rules storage.ComplianceRuleStore) {
+	a.rules = rules
+}
+
 // RegisterAnalysisRoutes registers analysis routes without RBAC.
 func (a *AnalysisServer) RegisterAnalysisRoutes() {
 	a.srv.handleOpenAPIRouteFunc("/api/v1/analysis", a.handleAnalysis)
 	a.srv.handleOpenAPIRouteFunc("/api/v1/analysis/gaps", a.handleGaps)
 	a.srv.handleOpenAPIRouteFunc("/api/v1/analysis/fragmentation", a.handleFragmentation)
 	a.srv.handleOpenAPIRouteFunc("/api/v1/analysis/compliance", a.handleCompliance)
+	if a.rules != nil {
+		a.srv.handleOpenAPIRouteFunc("GET /api/v1/analysis/rules", a.handleListRules)
+		a.srv.handleOpenAPIRouteFunc("POST /api/v1/analysis/rules", a.handleCreateRule)
+		a.srv.handleOpenAPIRouteFunc("GET /api/v1/analysis/rules/{id}", a.handleGetRule)
+		a.srv.handleOpenAPIRouteFunc("PUT /api/v1/analysis/rules/{id}", a.handleUpdateRule)
+		a.srv.handleOpenAPIRouteFunc("DELETE /api/v1/analysis/rules/{id}", a.handleDeleteRule)
+	}
 }
 
 // RegisterProtectedAnalysisRoutes registers analysis routes with RBAC.
@@ -36,6 +51,16 @@ func (a *AnalysisServer) RegisterProtectedAnalysisRoutes(dualMW Middleware, logg
 	a.srv.handleOpenAPIRoute("/api/v1/analysis/gaps", dualMW(readMW(http.HandlerFunc(a.handleGaps))))
 	a.srv.handleOpenAPIRoute("/api/v1/analysis/fragmentation", dualMW(readMW(http.HandlerFunc(a.handleFragmentation))))
 	a.srv.handleOpenAPIRoute("/api/v1/analysis/compliance", dualMW(readMW(http.HandlerFunc(a.handleCompliance))))
+
+	if a.rules != nil {
+		rulesReadMW := RequirePermissionMiddleware(auth.ResourceSettings, auth.ActionRead, logger)
+		rulesWriteMW := RequirePermissionMiddleware(auth.ResourceSettings, auth.ActionWrite, logger)
+		a.srv.handleOpenAPIRoute("GET /api/v1/analysis/rules", dualMW(rulesReadMW(http.HandlerFunc(a.handleListRules))))
+		a.srv.handleOpenAPIRoute("POST /api/v1/analysis/rules", dualMW(rulesWriteMW(http.HandlerFunc(a.handleCreateRule))))
+		a.srv.handleOpenAPIRoute("GET /api/v1/analysis/rules/{id}", dualMW(rulesReadMW(http.HandlerFunc(a.handleGetRule))))
+		a.srv.handleOpenAPIRoute("PUT /api/v1/analysis/rules/{id}", dualMW(rulesWriteMW(http.HandlerFunc(a.handleUpdateRule))))
+		a.srv.handleOpenAPIRoute("DELETE /api/v1/analysis/rules/{id}", dualMW(rulesWriteMW(http.HandlerFunc(a.handleDeleteRule))))
+	}
 }
 
 // handleAnalysis runs a full analysis report.
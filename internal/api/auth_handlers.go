@@ -2,7 +2,9 @@ package api
 
 import (
 	"context"
+	"crypto/ed25519"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -23,6 +25,8 @@ type AuthServer struct {
 	userStore     auth.UserStore
 	auditLogger   audit.AuditLogger
 	settingsStore storage.SettingsStore
+	// checkpointKey verifies the signatures of audit chain checkpoints.
+	checkpointKey ed25519.PublicKey
 }
 
 // NewAuthServer creates a new AuthServer with auth and audit capabilities.
@@ -50,6 +54,12 @@ func (as *AuthServer) SetSettingsStore(store storage.SettingsStore) {
 	as.settingsStore = store
 }
 
+// SetAuditCheckpointKey sets the public key that audit chain verification
+// checks checkpoint signatures with.
+func (as *AuthServer) SetAuditCheckpointKey(key ed25519.PublicKey) {
+	as.checkpointKey = key
+}
+
 // RegisterAuthRoutes registers the auth API endpoints without RBAC.
 // For backward compatibility. Use RegisterProtectedAuthRoutes for RBAC enforcement.
 // Note: Audit endpoint is registered by Server.RegisterRoutes() for unprotected access.
@@ -62,7 +72,7 @@ func (as *AuthServer) RegisterAuthRoutes() {
 // RegisterProtectedAuthRoutes registers the auth and audit API endpoints with RBAC.
 // Routes require authentication and appropriate permissions:
 // - /api/v1/auth/keys: requires apikeys:* permissions
-// - /api/v1/audit: requires audit:read permission
+// - /api/v1/audit, /api/v1/audit/stats, /api/v1/audit/verify, /api/v1/audit/export: require audit:read permission
 func (as *AuthServer) RegisterProtectedAuthRoutes(logger *slog.Logger) {
 	if logger == nil {
 		logger = slog.Default()
@@ -84,6 +94,9 @@ func (as *AuthServer) RegisterProtectedAuthRoutes(logger *slog.Logger) {
 	// Audit endpoints - requires audit:read permission
 	auditReadMW := RequirePermissionMiddleware(auth.ResourceAudit, auth.ActionRead, logger)
 	as.handleOpenAPIRoute("/api/v1/audit", authMW(auditReadMW(http.HandlerFunc(as.handleAuditList))))
+	as.handleOpenAPIRoute("GET /api/v1/audit/stats", authMW(auditReadMW(http.HandlerFunc(as.handleAuditStats))))
+	as.handleOpenAPIRoute("GET /api/v1/audit/verify", authMW(auditReadMW(http.HandlerFunc(as.handleAuditVerify))))
+	as.handleOpenAPIRoute("GET /api/v1/audit/export", authMW(auditReadMW(http.HandlerFunc(as.handleAuditExport))))
 }
 
 // protectedAPIKeysHandler returns a handler for /api/v1/auth/keys with RBAC.
@@ -642,6 +655,171 @@ func (as *AuthServer) handleAuditList(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, response)
 }
 
+// handleAuditStats handles GET /api/v1/audit/stats.
+func (as *AuthServer) handleAuditStats(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	enforcer, ok := audit.AsRetentionEnforcer(as.auditLogger)
+	if !ok {
+		as.writeErr(ctx, w, http.StatusNotImplemented, "audit stats not supported", "the audit backend does not report statistics")
+		return
+	}
+	stats, err := enforcer.GetStats(ctx)
+	if err != nil {
+		as.writeErr(ctx, w, http.StatusInternalServerError, "failed to load audit stats", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// handleAuditVerify handles GET /api/v1/audit/verify. It walks the hash
+// chain between the optional since and until bounds and reports the first
+// broken link.
+func (as *AuthServer) handleAuditVerify(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	verifier, ok := audit.AsChainVerifier(as.auditLogger)
+	if !ok {
+		as.writeErr(ctx, w, http.StatusNotImplemented, "audit verification not supported", "the audit backend does not hash-chain events")
+		return
+	}
+	opts := audit.VerifyOptions{PublicKey: as.checkpointKey}
+	if opts.Since, opts.Until, ok = as.parseAuditRange(w, r); !ok {
+		return
+	}
+	result, err := verifier.VerifyChain(ctx, opts)
+	if err != nil {
+		as.writeErr(ctx, w, http.StatusInternalServerError, "failed to verify audit chain", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// parseAuditRange reads the optional since and until query parameters as
+// RFC 3339 timestamps. It writes a 400 and returns false when either is
+// malformed or the range is inverted.
+func (as *AuthServer) parseAuditRange(w http.ResponseWriter, r *http.Request) (since, until *time.Time, ok bool) {
+	q := r.URL.Query()
+	for _, bound := range []struct {
+		name string
+		dst  **time.Time
+	}{{"since", &since}, {"until", &until}} {
+		v := q.Get(bound.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			as.writeErr(r.Context(), w, http.StatusBadRequest, "invalid "+bound.name, "must be an RFC 3339 timestamp")
+			return nil, nil, false
+		}
+		*bound.dst = &t
+	}
+	if since != nil && until != nil && until.Before(*since) {
+		as.writeErr(r.Context(), w, http.StatusBadRequest, "invalid range", "until must not be before since")
+		return nil, nil, false
+	}
+	return since, until, true
+}
+
+// handleAuditExport handles GET /api/v1/audit/export. It streams every
+// event matching the filters, oldest first, as JSONL, CSV or CEF. Errors
+// after the first event has been written can only end the stream early, so
+// they are logged instead.
+func (as *AuthServer) handleAuditExport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	streamer, ok := audit.AsEventStreamer(as.auditLogger)
+	if !ok {
+		as.writeErr(ctx, w, http.StatusNotImplemented, "audit export not supported", "the audit backend cannot stream events")
+		return
+	}
+	q := r.URL.Query()
+	format := audit.ExportJSONL
+	if v := q.Get("format"); v != "" {
+		f, err := audit.ParseExportFormat(v)
+		if err != nil {
+			as.writeErr(ctx, w, http.StatusBadRequest, "invalid format", err.Error())
+			return
+		}
+		format = f
+	}
+	opts := audit.ListOptions{
+		Actor:        q.Get("actor"),
+		Action:       q.Get("action"),
+		ResourceType: q.Get("resource_type"),
+		ResourceID:   q.Get("resource_id"),
+	}
+	if opts.Since, opts.Until, ok = as.parseAuditRange(w, r); !ok {
+		return
+	}
+
+	// Record the export before it runs, so that the trail shows who pulled
+	// the events even when the download is cut short.
+	as.logAuditExport(ctx, format, opts)
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="cloudpam-audit-%s.%s"`, time.Now().UTC().Format("20060102T150405Z"), format))
+	w.Header().Set("Cache-Control", "no-store")
+	out := audit.NewExportWriter(w, format, audit.CEFFormatter{DeviceVersion: strings.TrimPrefix(strings.TrimSpace(as.appVersion), "v")})
+	rc := http.NewResponseController(w)
+	var n int64
+	err := streamer.Stream(ctx, opts, func(e *audit.AuditEvent) error {
+		if err := out.Write(e); err != nil {
+			return err
+		}
+		// Push each batch to the client rather than buffering the export.
+		if n++; n%exportFlushEvery == 0 {
+			if err := out.Flush(); err != nil {
+				return err
+			}
+			if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		err = out.Flush()
+	}
+	if err != nil {
+		as.logger.WarnContext(ctx, "audit export ended early", appendRequestID(ctx, []any{"format", string(format), "events", n, "error", err})...)
+	}
+}
+
+// exportFlushEvery is the number of events written between flushes of an
+// audit export.
+const exportFlushEvery = 500
+
+// logAuditExport records that the audit log was exported with opts.
+func (as *AuthServer) logAuditExport(ctx context.Context, format audit.ExportFormat, opts audit.ListOptions) {
+	if as.auditLogger == nil {
+		return
+	}
+	actor, actorType := auditActorFromContext(ctx)
+	filters := map[string]any{"format": string(format)}
+	for k, v := range map[string]string{
+		"actor": opts.Actor, "action": opts.Action, "resource_type": opts.ResourceType, "resource_id": opts.ResourceID,
+	} {
+		if v != "" {
+			filters[k] = v
+		}
+	}
+	if opts.Since != nil {
+		filters["since"] = opts.Since.UTC().Format(time.RFC3339)
+	}
+	if opts.Until != nil {
+		filters["until"] = opts.Until.UTC().Format(time.RFC3339)
+	}
+	_ = as.auditLogger.Log(ctx, &audit.AuditEvent{
+		Actor:        actor,
+		ActorType:    actorType,
+		Action:       audit.ActionRead,
+		ResourceType: audit.ResourceAuditLog,
+		ResourceID:   "export",
+		Changes:      &audit.Changes{After: filters},
+		RequestID:    RequestIDFromContext(ctx),
+		StatusCode:   http.StatusOK,
+	})
+}
+
 // parseInt parses a string to int, returning error if invalid.
 func parseInt(s string) (int, error) {
 	var v int
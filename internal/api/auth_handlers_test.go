@@ -592,3 +592,106 @@ func TestAudit_MethodNotAllowed(t *testing.T) {
 		t.Errorf("Expected 405, got %d", rr.Code)
 	}
 }
+
+func TestAudit_Stats(t *testing.T) {
+	as, _, auditLogger := setupAuthTestServer()
+	ctx := context.Background()
+	for _, e := range []*audit.AuditEvent{
+		{Actor: "alice", Action: audit.ActionCreate, ResourceType: audit.ResourcePool, StatusCode: 201},
+		{Actor: "alice", Action: audit.ActionDelete, ResourceType: audit.ResourcePool, StatusCode: 204},
+		{Actor: "bob", Action: audit.ActionCreate, ResourceType: audit.ResourceAccount, StatusCode: 409},
+	} {
+		if err := auditLogger.Log(ctx, e); err != nil {
+			t.Fatalf("Log: %v", err)
+		}
+	}
+
+	rr := doAuthJSON(t, as.mux, stdhttp.MethodGet, "/api/v1/audit/stats", "", stdhttp.StatusOK)
+	var stats audit.AuditStats
+	if err := json.Unmarshal(rr.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if stats.TotalEvents != 3 || stats.SuccessCount != 2 || stats.FailureCount != 1 {
+		t.Errorf("counts = %+v", stats)
+	}
+	if stats.EventsByAction["create"] != 2 || stats.EventsByResource["pool"] != 2 || stats.EventsByActor["alice"] != 2 {
+		t.Errorf("breakdowns = %+v", stats)
+	}
+	if stats.OldestEvent == nil || stats.NewestEvent == nil {
+		t.Errorf("missing event range: %+v", stats)
+	}
+}
+
+func TestAudit_Verify(t *testing.T) {
+	as, _, auditLogger := setupAuthTestServer()
+	ctx := context.Background()
+	for _, action := range []string{audit.ActionCreate, audit.ActionUpdate, audit.ActionDelete} {
+		if err := auditLogger.Log(ctx, &audit.AuditEvent{Actor: "alice", Action: action, ResourceType: audit.ResourcePool, StatusCode: 200}); err != nil {
+			t.Fatalf("Log: %v", err)
+		}
+	}
+
+	rr := doAuthJSON(t, as.mux, stdhttp.MethodGet, "/api/v1/audit/verify", "", stdhttp.StatusOK)
+	var res audit.VerifyResult
+	if err := json.Unmarshal(rr.Body.Bytes(), &res); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !res.Valid || res.EventsChecked != 3 || res.FirstSeq != 1 || res.LastSeq != 3 {
+		t.Errorf("result = %+v", res)
+	}
+
+	doAuthJSON(t, as.mux, stdhttp.MethodGet, "/api/v1/audit/verify?since=yesterday", "", stdhttp.StatusBadRequest)
+	doAuthJSON(t, as.mux, stdhttp.MethodGet, "/api/v1/audit/verify?since=2026-02-01T00:00:00Z&until=2026-01-01T00:00:00Z", "", stdhttp.StatusBadRequest)
+}
+
+func TestAudit_Export(t *testing.T) {
+	as, _, auditLogger := setupAuthTestServer()
+	ctx := context.Background()
+	for _, e := range []*audit.AuditEvent{
+		{Actor: "alice", Action: audit.ActionCreate, ResourceType: audit.ResourcePool, ResourceID: "1", StatusCode: 201},
+		{Actor: "bob", Action: audit.ActionDelete, ResourceType: audit.ResourceAccount, ResourceID: "2", StatusCode: 204},
+		{Actor: "alice", Action: audit.ActionUpdate, ResourceType: audit.ResourcePool, ResourceID: "1", StatusCode: 200},
+	} {
+		if err := auditLogger.Log(ctx, e); err != nil {
+			t.Fatalf("Log: %v", err)
+		}
+	}
+
+	rr := doAuthJSON(t, as.mux, stdhttp.MethodGet, "/api/v1/audit/export?resource_type=pool", "", stdhttp.StatusOK)
+	if ct := rr.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if cd := rr.Header().Get("Content-Disposition"); !strings.HasPrefix(cd, `attachment; filename="cloudpam-audit-`) || !strings.HasSuffix(cd, `.jsonl"`) {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+	lines := strings.Split(strings.TrimSpace(rr.Body.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("jsonl lines = %q", lines)
+	}
+	var first audit.AuditEvent
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil || first.Action != audit.ActionCreate {
+		t.Errorf("first event = %+v, %v; want the oldest pool event", first, err)
+	}
+
+	rr = doAuthJSON(t, as.mux, stdhttp.MethodGet, "/api/v1/audit/export?format=csv&actor=bob", "", stdhttp.StatusOK)
+	if rows := strings.Split(strings.TrimSpace(rr.Body.String()), "\n"); len(rows) != 2 || !strings.HasPrefix(rows[0], "seq,id,timestamp") {
+		t.Errorf("csv = %q", rows)
+	}
+
+	rr = doAuthJSON(t, as.mux, stdhttp.MethodGet, "/api/v1/audit/export?format=cef&action=update", "", stdhttp.StatusOK)
+	if body := rr.Body.String(); !strings.HasPrefix(body, "CEF:0|BadgerOps|CloudPAM|") || strings.Count(body, "\n") != 1 {
+		t.Errorf("cef = %q", body)
+	}
+
+	doAuthJSON(t, as.mux, stdhttp.MethodGet, "/api/v1/audit/export?format=pdf", "", stdhttp.StatusBadRequest)
+	doAuthJSON(t, as.mux, stdhttp.MethodGet, "/api/v1/audit/export?until=tomorrow", "", stdhttp.StatusBadRequest)
+
+	// Each export is itself audited.
+	events, _, err := auditLogger.List(ctx, audit.ListOptions{ResourceType: audit.ResourceAuditLog})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(events) != 3 || events[0].Changes == nil || events[0].Changes.After["format"] != "cef" || events[0].Changes.After["action"] != "update" {
+		t.Errorf("export events = %+v", events)
+	}
+}
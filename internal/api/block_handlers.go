@@ -260,7 +260,7 @@ func (s *Server) blocksForPool(w http.ResponseWriter, r *http.Request, id int64)
 		return
 	}
 	npl, err := strconv.Atoi(nplStr)
-	if err != nil || npl <= 0 || npl > 32 {
+	if err != nil || npl <= 0 || npl > 128 {
 		s.writeErr(r.Context(), w, http.StatusBadRequest, "invalid new_prefix_len", "")
 		return
 	}
@@ -287,14 +287,14 @@ func (s *Server) blocksForPool(w http.ResponseWriter, r *http.Request, id int64)
 		}
 		page = p
 	}
-	// Compute blocks (IPv4 only), returning a page window if requested.
+	// Compute blocks, returning a page window if requested.
 	offset := 0
 	limit := 0
 	if pageSize > 0 {
 		limit = pageSize
 		offset = (page - 1) * pageSize
 	}
-	blocks, hosts, total, err := computeSubnetsIPv4Window(pool.CIDR, npl, offset, limit)
+	blocks, hosts, total, err := computeSubnetsWindow(pool.CIDR, npl, offset, limit)
 	if err != nil {
 		s.writeErr(r.Context(), w, http.StatusBadRequest, err.Error(), "")
 		return
@@ -323,7 +323,7 @@ func (s *Server) blocksForPool(w http.ResponseWriter, r *http.Request, id int64)
 	for _, p := range all {
 		if p.ParentID != nil && *p.ParentID == pool.ID {
 			used[p.CIDR] = usedInfo{id: p.ID, name: p.Name, accountID: p.AccountID}
-			if pf, err := netip.ParsePrefix(p.CIDR); err == nil && pf.Addr().Is4() {
+			if pf, err := netip.ParsePrefix(p.CIDR); err == nil {
 				children = append(children, childPrefix{id: p.ID, name: p.Name, pfx: pf, cidr: p.CIDR, accountID: p.AccountID})
 			}
 		}
@@ -350,12 +350,12 @@ func (s *Server) blocksForPool(w http.ResponseWriter, r *http.Request, id int64)
 		} else {
 			// mark as unavailable if overlaps any existing direct child with a different CIDR
 			bp, err := netip.ParsePrefix(b)
-			if err == nil && bp.Addr().Is4() {
+			if err == nil {
 				for _, ch := range children {
 					if ch.cidr == b {
 						continue
 					}
-					if prefixesOverlapIPv4(ch.pfx, bp) {
+					if prefixesOverlap(ch.pfx, bp) {
 						bi.ExistsElsewhere = true
 						bi.ExistsElsewhereID = ch.id
 						bi.ExistsElsewhereName = ch.name
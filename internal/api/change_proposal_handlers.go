@@ -0,0 +1,201 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"cloudpam/internal/audit"
+	"cloudpam/internal/auth"
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+// ChangeProposalServer handles review of change proposals: pool trees from
+// the schema planner or an AI plan that were stored with propose instead
+// of being applied.
+type ChangeProposalServer struct {
+	srv       *Server
+	proposals storage.ChangeProposalStore
+}
+
+// NewChangeProposalServer creates a new ChangeProposalServer.
+func NewChangeProposalServer(srv *Server, proposals storage.ChangeProposalStore) *ChangeProposalServer {
+	return &ChangeProposalServer{srv: srv, proposals: proposals}
+}
+
+// RegisterProtectedChangeProposalRoutes registers change proposal routes
+// with RBAC. Approving creates pools, so reviewing needs pools:create.
+func (cs *ChangeProposalServer) RegisterProtectedChangeProposalRoutes(dualMW Middleware, logger *slog.Logger) {
+	readMW := RequirePermissionMiddleware(auth.ResourcePools, auth.ActionRead, logger)
+	createMW := RequirePermissionMiddleware(auth.ResourcePools, auth.ActionCreate, logger)
+
+	cs.srv.handleOpenAPIRoute("GET /api/v1/change-proposals", dualMW(readMW(http.HandlerFunc(cs.handleList))))
+	cs.srv.handleOpenAPIRoute("GET /api/v1/change-proposals/{id}", dualMW(readMW(http.HandlerFunc(cs.handleGet))))
+	cs.srv.handleOpenAPIRoute("POST /api/v1/change-proposals/{id}/approve", dualMW(createMW(http.HandlerFunc(cs.handleApprove))))
+	cs.srv.handleOpenAPIRoute("POST /api/v1/change-proposals/{id}/reject", dualMW(createMW(http.HandlerFunc(cs.handleReject))))
+}
+
+// RegisterChangeProposalRoutesNoAuth registers change proposal routes
+// without auth middleware (for tests).
+func (cs *ChangeProposalServer) RegisterChangeProposalRoutesNoAuth() {
+	cs.srv.handleOpenAPIRouteFunc("GET /api/v1/change-proposals", cs.handleList)
+	cs.srv.handleOpenAPIRouteFunc("GET /api/v1/change-proposals/{id}", cs.handleGet)
+	cs.srv.handleOpenAPIRouteFunc("POST /api/v1/change-proposals/{id}/approve", cs.handleApprove)
+	cs.srv.handleOpenAPIRouteFunc("POST /api/v1/change-proposals/{id}/reject", cs.handleReject)
+}
+
+// handleList lists proposals, newest first, optionally by status.
+// GET /api/v1/change-proposals
+func (cs *ChangeProposalServer) handleList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	status := domain.ChangeProposalStatus(r.URL.Query().Get("status"))
+	if status != "" && !domain.IsValidChangeProposalStatus(status) {
+		cs.srv.writeErr(ctx, w, http.StatusBadRequest, "invalid status", "use pending, applied, or rejected")
+		return
+	}
+	items, err := cs.proposals.ListChangeProposals(ctx, status)
+	if err != nil {
+		cs.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, domain.ChangeProposalListResponse{Items: items})
+}
+
+// handleGet returns a single proposal with its change set.
+// GET /api/v1/change-proposals/{id}
+func (cs *ChangeProposalServer) handleGet(w http.ResponseWriter, r *http.Request) {
+	p, err := cs.proposals.GetChangeProposal(r.Context(), r.PathValue("id"))
+	if err != nil {
+		cs.srv.writeStoreErr(r.Context(), w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+// reviewRequest is the optional body of approve and reject, for change
+// proposals and pool change requests alike.
+type reviewRequest struct {
+	Comment string `json:"comment"`
+}
+
+// decodeReview reads the optional review body. An empty body is allowed.
+func (s *Server) decodeReview(w http.ResponseWriter, r *http.Request) (reviewRequest, bool) {
+	var req reviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		s.writeErr(r.Context(), w, http.StatusBadRequest, "invalid request body", err.Error())
+		return req, false
+	}
+	return req, true
+}
+
+// loadPending returns the proposal in the path if it is still pending,
+// writing the error response otherwise.
+func (cs *ChangeProposalServer) loadPending(w http.ResponseWriter, r *http.Request) (*domain.ChangeProposal, bool) {
+	p, err := cs.proposals.GetChangeProposal(r.Context(), r.PathValue("id"))
+	if err != nil {
+		cs.srv.writeStoreErr(r.Context(), w, err)
+		return nil, false
+	}
+	if p.Status != domain.ChangeProposalPending {
+		cs.srv.writeErr(r.Context(), w, http.StatusConflict, fmt.Sprintf("change proposal is already %s", p.Status), "")
+		return nil, false
+	}
+	return p, true
+}
+
+// handleApprove applies a pending proposal. The approver must be someone
+// other than the author, counting the author's own API keys, and must act
+// with a different role that grants at least the author's permissions. The
+// tree is
+// re-checked against the pools that exist now, so an approval can still
+// fail with a conflict; the proposal then stays pending.
+// POST /api/v1/change-proposals/{id}/approve
+func (cs *ChangeProposalServer) handleApprove(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req, ok := cs.srv.decodeReview(w, r)
+	if !ok {
+		return
+	}
+	p, ok := cs.loadPending(w, r)
+	if !ok {
+		return
+	}
+
+	actor, role := reviewActor(ctx)
+	actorID := reviewActorID(ctx)
+	if actorID == p.ProposedByID {
+		cs.srv.writeErr(ctx, w, http.StatusForbidden, "a change proposal cannot be approved by its author", "")
+		return
+	}
+	if auth.Role(role) == auth.Role(p.ProposedRole) {
+		cs.srv.writeErr(ctx, w, http.StatusForbidden, "approver must hold a different role than the proposer",
+			fmt.Sprintf("both act as %q", role))
+		return
+	}
+	if !roleCovers(ctx, auth.Role(role), auth.Role(p.ProposedRole)) {
+		cs.srv.writeErr(ctx, w, http.StatusForbidden, "approver's role must grant at least the proposer's permissions",
+			fmt.Sprintf("%q does not cover %q", role, p.ProposedRole))
+		return
+	}
+
+	now := time.Now().UTC()
+	p.Status = domain.ChangeProposalApplied
+	p.ReviewedBy, p.ReviewedRole = actor, role
+	p.ReviewedByID = actorID
+	p.ReviewComment = req.Comment
+	p.ReviewedAt = &now
+
+	var res poolTreeResult
+	err := cs.srv.withTx(ctx, func(st storage.Store) error {
+		var err error
+		res, err = cs.srv.createPoolTree(ctx, st, p.Tree)
+		if err != nil {
+			return err
+		}
+		p.PoolMap = res.PoolMap
+		return txStore(st, cs.proposals).ReviewChangeProposal(ctx, *p)
+	})
+	if err != nil {
+		cs.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+	for _, pool := range res.Created {
+		cs.srv.logAudit(ctx, audit.ActionCreate, audit.ResourcePool, fmt.Sprintf("%d", pool.ID), pool.Name, http.StatusCreated)
+	}
+	cs.srv.logAuditWithChanges(ctx, audit.ActionApprove, audit.ResourceChangeProposal, p.ID, p.Title,
+		&audit.Changes{After: map[string]any{"pool_map": p.PoolMap, "proposed_by": p.ProposedBy}}, http.StatusOK)
+	writeJSON(w, http.StatusOK, p)
+}
+
+// handleReject closes a pending proposal without applying it. Authors may
+// reject, that is withdraw, their own proposals.
+// POST /api/v1/change-proposals/{id}/reject
+func (cs *ChangeProposalServer) handleReject(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	req, ok := cs.srv.decodeReview(w, r)
+	if !ok {
+		return
+	}
+	p, ok := cs.loadPending(w, r)
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	p.Status = domain.ChangeProposalRejected
+	p.ReviewedBy, p.ReviewedRole = reviewActor(ctx)
+	p.ReviewedByID = reviewActorID(ctx)
+	p.ReviewComment = req.Comment
+	p.ReviewedAt = &now
+	if err := cs.proposals.ReviewChangeProposal(ctx, *p); err != nil {
+		cs.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+	cs.srv.logAudit(ctx, audit.ActionReject, audit.ResourceChangeProposal, p.ID, p.Title, http.StatusOK)
+	writeJSON(w, http.StatusOK, p)
+}
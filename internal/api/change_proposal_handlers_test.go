@@ -0,0 +1,266 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	stdhttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cloudpam/internal/auth"
+	"cloudpam/internal/domain"
+	"cloudpam/internal/observability"
+	"cloudpam/internal/planning"
+	"cloudpam/internal/storage"
+)
+
+func setupChangeProposalServer(t *testing.T) (*stdhttp.ServeMux, *storage.MemoryStore) {
+	t.Helper()
+	st := storage.NewMemoryStore()
+	mux := stdhttp.NewServeMux()
+	logger := observability.NewLogger(observability.Config{Level: "info", Format: "json", Output: io.Discard})
+	srv := NewServer(mux, st, logger, nil, nil)
+	srv.registerUnprotectedTestRoutes()
+
+	analysisSvc := planning.NewAnalysisService(st)
+	rules := storage.NewMemoryComplianceRuleStore()
+	if err := rules.CreateComplianceRule(t.Context(), domain.ComplianceRule{
+		ID: "TAGS-001", Name: "Owner tag", Enabled: true, Severity: "warning",
+		Check: domain.ComplianceCheck{Type: domain.ComplianceCheckRequiredTags, Tags: []string{"owner"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	analysisSvc.SetComplianceRules(rules)
+	srv.SetPoolAdmission(analysisSvc)
+
+	proposals := storage.NewMemoryChangeProposalStore()
+	srv.SetChangeProposalStore(proposals)
+	NewChangeProposalServer(srv, proposals).RegisterChangeProposalRoutesNoAuth()
+
+	convStore := storage.NewMemoryConversationStore(st)
+	aiSvc := planning.NewAIPlanningService(analysisSvc, convStore, st, nil)
+	NewAIPlanningServer(srv, aiSvc, convStore).RegisterAIPlanningRoutes()
+	return mux, st
+}
+
+// doJSONAs is doJSON for a request made by the named user acting with role.
+func doJSONAs(t *testing.T, mux *stdhttp.ServeMux, username string, role auth.Role, method, path, body string, code int) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	ctx := auth.ContextWithUser(req.Context(), &auth.User{ID: username, Username: username, Role: role})
+	req = req.WithContext(auth.ContextWithRole(ctx, role))
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	if rr.Code != code {
+		t.Fatalf("%s %s as %s: expected code %d, got %d: %s", method, path, username, code, rr.Code, rr.Body.String())
+	}
+	return rr
+}
+
+// doJSONAsKey is doJSON for a request made with key.
+func doJSONAsKey(t *testing.T, mux *stdhttp.ServeMux, key *auth.APIKey, method, path, body string, code int) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(auth.ContextWithAPIKey(req.Context(), key))
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	if rr.Code != code {
+		t.Fatalf("%s %s with key %s: expected code %d, got %d: %s", method, path, key.Name, code, rr.Code, rr.Body.String())
+	}
+	return rr
+}
+
+func TestSchemaApply_DryRun(t *testing.T) {
+	mux, st := setupChangeProposalServer(t)
+	doJSON(t, mux, stdhttp.MethodPost, "/api/v1/pools", `{"name":"existing","cidr":"10.0.0.0/16","tags":{"owner":"netops"}}`, stdhttp.StatusCreated)
+
+	body := `{"dry_run":true,"status":"planned","tags":{"owner":"netops"},"pools":[
+		{"ref":"root","name":"Root","cidr":"10.0.0.0/8","type":"supernet"},
+		{"ref":"r0","name":"us-east-1","cidr":"10.0.0.0/12","type":"region","parent_ref":"root"},
+		{"ref":"lab","name":"Lab","cidr":"192.168.0.0/16","type":"bogus"},
+		{"ref":"lab-a","name":"Lab A","cidr":"192.168.1.0/24","parent_ref":"lab"}
+	]}`
+	rr := doJSON(t, mux, stdhttp.MethodPost, "/api/v1/schema/apply", body, stdhttp.StatusOK)
+	var cs domain.ChangeSet
+	if err := json.Unmarshal(rr.Body.Bytes(), &cs); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if cs.Applicable || len(cs.Skip) != 2 || len(cs.Create) != 2 {
+		t.Fatalf("change set = %s", rr.Body.String())
+	}
+	if s := cs.Skip[0]; s.Ref != "root" || s.Reason != domain.SkipReasonConflict || s.ExistingPoolID == 0 {
+		t.Fatalf("skip[0] = %+v, want a conflict with the existing pool", s)
+	}
+	if s := cs.Skip[1]; s.Ref != "r0" || s.Reason != domain.SkipReasonParentSkipped {
+		t.Fatalf("skip[1] = %+v, want parent_skipped", s)
+	}
+	if c := cs.Create[1]; c.Ref != "lab-a" || c.ParentName != "Lab" || c.Depth != 2 || c.Type != domain.PoolTypeSubnet {
+		t.Fatalf("create[1] = %+v", c)
+	}
+	if len(cs.Warnings) != 1 || !strings.Contains(cs.Warnings[0], "invalid type") || len(cs.Violations) != 0 {
+		t.Fatalf("warnings/violations = %v / %+v", cs.Warnings, cs.Violations)
+	}
+
+	// Without the owner tag every planned pool violates the rule.
+	body = strings.Replace(body, `"tags":{"owner":"netops"},`, "", 1)
+	rr = doJSON(t, mux, stdhttp.MethodPost, "/api/v1/schema/apply", body, stdhttp.StatusOK)
+	if err := json.Unmarshal(rr.Body.Bytes(), &cs); err != nil {
+		t.Fatal(err)
+	}
+	if len(cs.Violations) != 2 || cs.Violations[0].Ref != "lab" || cs.Violations[0].RuleID != "TAGS-001" {
+		t.Fatalf("violations = %+v", cs.Violations)
+	}
+
+	if pools, _ := st.ListPools(t.Context()); len(pools) != 1 {
+		t.Fatalf("dry run created pools: %+v", pools)
+	}
+}
+
+func TestChangeProposal_ApproveRequiresSecondReviewer(t *testing.T) {
+	mux, st := setupChangeProposalServer(t)
+
+	body := `{"propose":true,"title":"Prod layout","status":"planned","pools":[
+		{"ref":"root","name":"Root","cidr":"10.0.0.0/8","type":"supernet"},
+		{"ref":"r0","name":"us-east-1","cidr":"10.0.0.0/12","type":"region","parent_ref":"root"}
+	]}`
+	rr := doJSONAs(t, mux, "alice", auth.RoleOperator, stdhttp.MethodPost, "/api/v1/schema/apply", body, stdhttp.StatusCreated)
+	var p domain.ChangeProposal
+	if err := json.Unmarshal(rr.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.ID == "" || p.Status != domain.ChangeProposalPending || p.Title != "Prod layout" || p.Source != domain.ChangeProposalSourceSchema ||
+		p.ProposedBy != "alice" || p.ProposedByID != "user:alice" || p.ProposedRole != "operator" || len(p.ChangeSet.Create) != 2 || !p.ChangeSet.Applicable {
+		t.Fatalf("proposal = %s", rr.Body.String())
+	}
+	if pools, _ := st.ListPools(t.Context()); len(pools) != 0 {
+		t.Fatalf("proposing created pools: %+v", pools)
+	}
+
+	rr = doJSON(t, mux, stdhttp.MethodGet, "/api/v1/change-proposals?status=pending", "", stdhttp.StatusOK)
+	var list domain.ChangeProposalListResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &list); err != nil || len(list.Items) != 1 {
+		t.Fatalf("pending list = %s", rr.Body.String())
+	}
+	doJSON(t, mux, stdhttp.MethodGet, "/api/v1/change-proposals?status=bogus", "", stdhttp.StatusBadRequest)
+	doJSON(t, mux, stdhttp.MethodGet, "/api/v1/change-proposals/missing", "", stdhttp.StatusNotFound)
+
+	approve := "/api/v1/change-proposals/" + p.ID + "/approve"
+	doJSONAs(t, mux, "alice", auth.RoleAdmin, stdhttp.MethodPost, approve, "", stdhttp.StatusForbidden)
+	// A second person still needs a different role, and one that covers the author's.
+	doJSONAs(t, mux, "bob", auth.RoleOperator, stdhttp.MethodPost, approve, "", stdhttp.StatusForbidden)
+	doJSONAs(t, mux, "bob", auth.RoleViewer, stdhttp.MethodPost, approve, "", stdhttp.StatusForbidden)
+
+	rr = doJSONAs(t, mux, "bob", auth.RoleAdmin, stdhttp.MethodPost, approve, `{"comment":"lgtm"}`, stdhttp.StatusOK)
+	if err := json.Unmarshal(rr.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Status != domain.ChangeProposalApplied || p.ReviewedBy != "bob" || p.ReviewedByID != "user:bob" || p.ReviewedRole != "admin" ||
+		p.ReviewComment != "lgtm" || p.ReviewedAt == nil || len(p.PoolMap) != 2 {
+		t.Fatalf("approved proposal = %s", rr.Body.String())
+	}
+	pool, found, err := st.GetPool(t.Context(), p.PoolMap["r0"])
+	if err != nil || !found || pool.ParentID == nil || *pool.ParentID != p.PoolMap["root"] || pool.Status != domain.PoolStatusPlanned {
+		t.Fatalf("created pool = %+v, %v", pool, err)
+	}
+
+	doJSONAs(t, mux, "carol", auth.RoleAdmin, stdhttp.MethodPost, approve, "", stdhttp.StatusConflict)
+	doJSONAs(t, mux, "carol", auth.RoleAdmin, stdhttp.MethodPost, "/api/v1/change-proposals/"+p.ID+"/reject", "", stdhttp.StatusConflict)
+}
+
+func TestChangeProposal_AuthorCannotApproveWithOwnKey(t *testing.T) {
+	mux, _ := setupChangeProposalServer(t)
+
+	body := `{"propose":true,"pools":[{"ref":"root","name":"Root","cidr":"10.0.0.0/16"}]}`
+	rr := doJSONAs(t, mux, "alice", auth.RoleOperator, stdhttp.MethodPost, "/api/v1/schema/apply", body, stdhttp.StatusCreated)
+	var p domain.ChangeProposal
+	if err := json.Unmarshal(rr.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	approve := "/api/v1/change-proposals/" + p.ID + "/approve"
+
+	// A personal key acts with a different role, but it is still alice.
+	owner := "alice"
+	personal := &auth.APIKey{ID: "key-1", Name: "alice-ci", Scopes: []string{"*"}, OwnerID: &owner}
+	doJSONAsKey(t, mux, personal, stdhttp.MethodPost, approve, "", stdhttp.StatusForbidden)
+
+	// So is a key with a different name; only an ownerless key stands apart.
+	renamed := &auth.APIKey{ID: "key-2", Name: "bob-lookalike", Scopes: []string{"*"}, OwnerID: &owner}
+	doJSONAsKey(t, mux, renamed, stdhttp.MethodPost, approve, "", stdhttp.StatusForbidden)
+
+	bot := &auth.APIKey{ID: "key-3", Name: "release-bot", Scopes: []string{"*"}}
+	rr = doJSONAsKey(t, mux, bot, stdhttp.MethodPost, approve, "", stdhttp.StatusOK)
+	if err := json.Unmarshal(rr.Body.Bytes(), &p); err != nil || p.ReviewedByID != "apikey:key-3" {
+		t.Fatalf("approved proposal = %s", rr.Body.String())
+	}
+}
+
+func TestChangeProposal_ApproveConflictKeepsPending(t *testing.T) {
+	mux, st := setupChangeProposalServer(t)
+
+	body := `{"propose":true,"pools":[{"ref":"root","name":"Root","cidr":"10.0.0.0/16"}]}`
+	rr := doJSONAs(t, mux, "alice", auth.RoleOperator, stdhttp.MethodPost, "/api/v1/schema/apply", body, stdhttp.StatusCreated)
+	var p domain.ChangeProposal
+	if err := json.Unmarshal(rr.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Title != "1 pools from schema" {
+		t.Fatalf("default title = %q", p.Title)
+	}
+
+	// A pool created after the proposal blocks its approval.
+	doJSON(t, mux, stdhttp.MethodPost, "/api/v1/pools", `{"name":"later","cidr":"10.0.0.0/24"}`, stdhttp.StatusCreated)
+	doJSONAs(t, mux, "bob", auth.RoleAdmin, stdhttp.MethodPost, "/api/v1/change-proposals/"+p.ID+"/approve", "", stdhttp.StatusConflict)
+
+	rr = doJSON(t, mux, stdhttp.MethodGet, "/api/v1/change-proposals/"+p.ID, "", stdhttp.StatusOK)
+	if err := json.Unmarshal(rr.Body.Bytes(), &p); err != nil || p.Status != domain.ChangeProposalPending {
+		t.Fatalf("proposal after failed approval = %s", rr.Body.String())
+	}
+	if pools, _ := st.ListPools(t.Context()); len(pools) != 1 {
+		t.Fatalf("failed approval left pools behind: %+v", pools)
+	}
+}
+
+func TestAIApplyPlan_DryRunAndProposeReject(t *testing.T) {
+	mux, st := setupChangeProposalServer(t)
+
+	plan := `"plan":{"name":"Prod VPCs","pools":[
+		{"ref":"root","name":"AI Root","cidr":"10.0.0.0/16","type":"supernet"},
+		{"ref":"child","name":"AI Child","cidr":"10.1.0.0/24","parent_ref":"root"}
+	]}`
+	rr := doJSON(t, mux, stdhttp.MethodPost, "/api/v1/ai/sessions/sess-1/apply-plan", `{"dry_run":true,`+plan+`}`, stdhttp.StatusOK)
+	var cs domain.ChangeSet
+	if err := json.Unmarshal(rr.Body.Bytes(), &cs); err != nil {
+		t.Fatal(err)
+	}
+	if !cs.Applicable || len(cs.Create) != 2 || len(cs.Warnings) != 1 || !strings.Contains(cs.Warnings[0], `"child"`) {
+		t.Fatalf("change set = %s", rr.Body.String())
+	}
+
+	rr = doJSONAs(t, mux, "alice", auth.RoleOperator, stdhttp.MethodPost, "/api/v1/ai/sessions/sess-1/apply-plan", `{"propose":true,`+plan+`}`, stdhttp.StatusCreated)
+	var p domain.ChangeProposal
+	if err := json.Unmarshal(rr.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Title != "Prod VPCs" || p.Source != domain.ChangeProposalSourceAIPlan || p.SessionID != "sess-1" || p.Tree.Tags["session_id"] != "sess-1" {
+		t.Fatalf("proposal = %s", rr.Body.String())
+	}
+
+	// Authors may withdraw their own proposals.
+	rr = doJSONAs(t, mux, "alice", auth.RoleOperator, stdhttp.MethodPost, "/api/v1/change-proposals/"+p.ID+"/reject", `{"comment":"wrong region"}`, stdhttp.StatusOK)
+	if err := json.Unmarshal(rr.Body.Bytes(), &p); err != nil || p.Status != domain.ChangeProposalRejected || p.ReviewComment != "wrong region" {
+		t.Fatalf("rejected proposal = %s", rr.Body.String())
+	}
+	doJSONAs(t, mux, "bob", auth.RoleAdmin, stdhttp.MethodPost, "/api/v1/change-proposals/"+p.ID+"/approve", "", stdhttp.StatusConflict)
+	if pools, _ := st.ListPools(t.Context()); len(pools) != 0 {
+		t.Fatalf("rejected proposal created pools: %+v", pools)
+	}
+}
+
+func TestSchemaApply_ProposeWithoutStore(t *testing.T) {
+	srv, _ := setupTestServer()
+	body := `{"propose":true,"pools":[{"ref":"root","name":"Root","cidr":"10.0.0.0/16"}]}`
+	doJSON(t, srv.mux, stdhttp.MethodPost, "/api/v1/schema/apply", body, stdhttp.StatusServiceUnavailable)
+}
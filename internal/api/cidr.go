@@ -1,10 +1,14 @@
 package api
 
 import (
-	"encoding/binary"
+	"context"
 	"fmt"
-	"net"
+	"math"
 	"net/netip"
+
+	"cloudpam/internal/cidr"
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
 )
 
 func validateChildCIDR(parentCIDR, childCIDR string) error {
@@ -16,37 +20,68 @@ func validateChildCIDR(parentCIDR, childCIDR string) error {
 	if err != nil {
 		return fmt.Errorf("invalid child: %w", err)
 	}
-	if !pp.Addr().Is4() || !cp.Addr().Is4() {
-		return fmt.Errorf("only ipv4 supported")
+	if pp.Addr().Is4() != cp.Addr().Is4() {
+		return fmt.Errorf("child must be the same address family as parent")
 	}
 	if cp.Bits() <= pp.Bits() {
 		return fmt.Errorf("child prefix len must be greater than parent")
 	}
 	// Check both start and end addresses within parent.
-	cstart := cp.Masked().Addr()
-	cend, err := lastAddr(cp)
-	if err != nil {
-		return err
-	}
-	if !pp.Contains(cstart) || !pp.Contains(cend) {
+	if !cidr.PrefixContains(pp, cp) {
 		return fmt.Errorf("child not within parent")
 	}
 	return nil
 }
 
-// prefixesOverlapIPv4 returns true if two IPv4 prefixes overlap in address space.
-func prefixesOverlapIPv4(a, b netip.Prefix) bool {
-	if !a.Addr().Is4() || !b.Addr().Is4() {
-		return false
-	}
-	aStart := ipv4ToUint32(a.Masked().Addr())
-	aEnd, _ := lastAddr(a)
-	bStart := ipv4ToUint32(b.Masked().Addr())
-	bEnd, _ := lastAddr(b)
-	aEndU := ipv4ToUint32(aEnd)
-	bEndU := ipv4ToUint32(bEnd)
-	// Overlap if intervals intersect
-	return aEndU >= bStart && bEndU >= aStart
+// prefixesOverlap returns true if two prefixes overlap in address space.
+// Prefixes of different address families never overlap.
+func prefixesOverlap(a, b netip.Prefix) bool {
+	return cidr.PrefixesOverlap(a.Masked(), b.Masked())
+}
+
+// findOverlappingPools returns the live pools whose CIDR overlaps prefix.
+// scope follows storage.CIDROperations.FindOverlapping: nil searches every
+// pool, a pointer to 0 only top-level pools, and any other value the children
+// of that pool. Stores without CIDROperations fall back to scanning ListPools.
+func findOverlappingPools(ctx context.Context, st storage.Store, prefix string, scope *int64) ([]domain.Pool, error) {
+	if ops, ok := st.(storage.CIDROperations); ok {
+		found, err := ops.FindOverlapping(ctx, prefix, scope)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]domain.Pool, 0, len(found))
+		for _, p := range found {
+			out = append(out, *p)
+		}
+		return out, nil
+	}
+
+	pfx, err := storage.ParseCIDRArg(prefix)
+	if err != nil {
+		return nil, err
+	}
+	all, err := st.ListPools(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var out []domain.Pool
+	for _, p := range all {
+		switch {
+		case scope == nil:
+		case *scope == 0:
+			if p.ParentID != nil {
+				continue
+			}
+		default:
+			if p.ParentID == nil || *p.ParentID != *scope {
+				continue
+			}
+		}
+		if old, err := netip.ParsePrefix(p.CIDR); err == nil && prefixesOverlap(old, pfx) {
+			out = append(out, p)
+		}
+	}
+	return out, nil
 }
 
 // MaxSubnetExpansion bounds how many candidate blocks a single request may
@@ -55,21 +90,26 @@ func prefixesOverlapIPv4(a, b netip.Prefix) bool {
 // still see how many blocks exist and page through them.
 const MaxSubnetExpansion = 65536
 
-func computeSubnetsIPv4Window(parentCIDR string, newPrefixLen int, offset, limit int) ([]string, uint64, int, error) {
+// computeSubnetsWindow splits parentCIDR into /newPrefixLen blocks and returns
+// the requested window of them, the usable hosts per block and the total
+// number of blocks. For IPv6 parents the total saturates at math.MaxInt.
+func computeSubnetsWindow(parentCIDR string, newPrefixLen int, offset, limit int) ([]string, uint64, int, error) {
 	pp, err := netip.ParsePrefix(parentCIDR)
 	if err != nil {
 		return nil, 0, 0, fmt.Errorf("invalid parent cidr: %w", err)
 	}
-	if !pp.Addr().Is4() {
-		return nil, 0, 0, fmt.Errorf("only ipv4 supported")
+	pp = pp.Masked()
+	bitLen := pp.Addr().BitLen()
+	if newPrefixLen < pp.Bits() || newPrefixLen > bitLen {
+		return nil, 0, 0, fmt.Errorf("new_prefix_len must be between %d and %d", pp.Bits(), bitLen)
 	}
-	if newPrefixLen < pp.Bits() || newPrefixLen > 32 {
-		return nil, 0, 0, fmt.Errorf("new_prefix_len must be between %d and 32", pp.Bits())
+	// number of blocks = 2^(new - old), saturated to fit an int
+	count := math.MaxInt
+	if diff := newPrefixLen - pp.Bits(); diff < 63 {
+		count = 1 << diff
 	}
-	// number of blocks = 2^(new - old)
-	count := 1 << (newPrefixLen - pp.Bits())
-	base := ipv4ToUint32(pp.Masked().Addr())
-	step := uint32(1) << uint32(32-newPrefixLen)
+	base := cidr.AddrToUint128(pp.Addr())
+	stepBits := uint(bitLen - newPrefixLen)
 	start := 0
 	end := count
 	if limit > 0 {
@@ -84,7 +124,7 @@ func computeSubnetsIPv4Window(parentCIDR string, newPrefixLen int, offset, limit
 		}
 		start = offset
 		end = offset + limit
-		if end > count {
+		if end > count || end < start {
 			end = count
 		}
 	} else if count > MaxSubnetExpansion {
@@ -97,33 +137,14 @@ func computeSubnetsIPv4Window(parentCIDR string, newPrefixLen int, offset, limit
 	}
 	res := make([]string, 0, end-start)
 	for i := start; i < end; i++ {
-		addr := uint32ToIPv4(base + uint32(i)*step)
+		addr := cidr.Uint128ToAddr(base.Add(cidr.Uint128From64(uint64(i)).Lsh(stepBits)), pp.Addr().Is4())
 		res = append(res, netip.PrefixFrom(addr, newPrefixLen).String())
 	}
 	hosts := usableHostsIPv4(newPrefixLen)
-	return res, hosts, count, nil
-}
-
-func lastAddr(p netip.Prefix) (netip.Addr, error) {
-	if !p.Addr().Is4() {
-		return netip.Addr{}, fmt.Errorf("only ipv4 supported")
+	if !pp.Addr().Is4() {
+		hosts = usableHostsIPv6(newPrefixLen)
 	}
-	base := ipv4ToUint32(p.Masked().Addr())
-	size := uint32(1) << uint32(32-p.Bits())
-	return uint32ToIPv4(base + size - 1), nil
-}
-
-func ipv4ToUint32(a netip.Addr) uint32 {
-	b := a.As4()
-	return binary.BigEndian.Uint32(b[:])
-}
-
-func uint32ToIPv4(u uint32) netip.Addr {
-	var b [4]byte
-	binary.BigEndian.PutUint32(b[:], u)
-	ip := net.IPv4(b[0], b[1], b[2], b[3])
-	addr, _ := netip.ParseAddr(ip.String())
-	return addr
+	return res, hosts, count, nil
 }
 
 func usableHostsIPv4(prefixLen int) uint64 {
@@ -138,3 +159,13 @@ func usableHostsIPv4(prefixLen int) uint64 {
 	}
 	return total
 }
+
+// usableHostsIPv6 returns the address count of an IPv6 prefix. IPv6 has no
+// broadcast address, so nothing is excluded; wide prefixes saturate at
+// math.MaxUint64.
+func usableHostsIPv6(prefixLen int) uint64 {
+	if prefixLen < 0 || prefixLen > 128 {
+		return 0
+	}
+	return cidr.AddressCount(netip.PrefixFrom(netip.IPv6Unspecified(), prefixLen)).Uint64()
+}
@@ -11,7 +11,7 @@ import (
 // expansion would allocate millions of strings per request.
 func TestComputeSubnetsRejectsUnboundedExpansion(t *testing.T) {
 	// 10.0.0.0/8 into /30s is 2^22 = 4,194,304 blocks.
-	_, _, _, err := computeSubnetsIPv4Window("10.0.0.0/8", 30, 0, 0)
+	_, _, _, err := computeSubnetsWindow("10.0.0.0/8", 30, 0, 0)
 	if err == nil {
 		t.Fatal("expected an error for an unpaginated expansion above the limit")
 	}
@@ -24,7 +24,7 @@ func TestComputeSubnetsRejectsUnboundedExpansion(t *testing.T) {
 // "page_size=all" behaviour working for reasonably sized pools.
 func TestComputeSubnetsAllowsUnboundedExpansionAtOrBelowLimit(t *testing.T) {
 	// 10.0.0.0/16 into /32s is exactly 65536 blocks, right at the limit.
-	blocks, _, total, err := computeSubnetsIPv4Window("10.0.0.0/16", 32, 0, 0)
+	blocks, _, total, err := computeSubnetsWindow("10.0.0.0/16", 32, 0, 0)
 	if err != nil {
 		t.Fatalf("expansion at the limit should be allowed: %v", err)
 	}
@@ -36,7 +36,7 @@ func TestComputeSubnetsAllowsUnboundedExpansionAtOrBelowLimit(t *testing.T) {
 // TestComputeSubnetsClampsOversizedPageSize checks an explicit page_size cannot
 // be used to bypass the cap.
 func TestComputeSubnetsClampsOversizedPageSize(t *testing.T) {
-	blocks, _, total, err := computeSubnetsIPv4Window("10.0.0.0/8", 30, 0, 1_000_000)
+	blocks, _, total, err := computeSubnetsWindow("10.0.0.0/8", 30, 0, 1_000_000)
 	if err != nil {
 		t.Fatalf("paginated request should succeed: %v", err)
 	}
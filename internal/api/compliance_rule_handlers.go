@@ -0,0 +1,115 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"cloudpam/internal/audit"
+	"cloudpam/internal/domain"
+)
+
+// handleListRules returns the user-defined compliance rules.
+// GET /api/v1/analysis/rules
+func (a *AnalysisServer) handleListRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := a.rules.ListComplianceRules(r.Context())
+	if err != nil {
+		a.srv.writeStoreErr(r.Context(), w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, domain.ComplianceRuleListResponse{Items: rules})
+}
+
+// handleCreateRule adds a compliance rule. Rules are enabled unless
+// "enabled" is false, and default to warning severity.
+// POST /api/v1/analysis/rules
+func (a *AnalysisServer) handleCreateRule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	rule, ok := a.decodeRule(w, r)
+	if !ok {
+		return
+	}
+	now := time.Now().UTC()
+	rule.CreatedAt, rule.UpdatedAt = now, now
+	if err := a.rules.CreateComplianceRule(ctx, rule); err != nil {
+		a.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+	a.srv.logAudit(ctx, audit.ActionCreate, audit.ResourceComplianceRule, rule.ID, rule.Name, http.StatusCreated)
+	writeJSON(w, http.StatusCreated, rule)
+}
+
+// handleGetRule returns a single compliance rule.
+// GET /api/v1/analysis/rules/{id}
+func (a *AnalysisServer) handleGetRule(w http.ResponseWriter, r *http.Request) {
+	rule, err := a.rules.GetComplianceRule(r.Context(), r.PathValue("id"))
+	if err != nil {
+		a.srv.writeStoreErr(r.Context(), w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, rule)
+}
+
+// handleUpdateRule replaces a compliance rule. The ID comes from the path.
+// PUT /api/v1/analysis/rules/{id}
+func (a *AnalysisServer) handleUpdateRule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	existing, err := a.rules.GetComplianceRule(ctx, r.PathValue("id"))
+	if err != nil {
+		a.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+	rule, ok := a.decodeRule(w, r)
+	if !ok {
+		return
+	}
+	if rule.ID != existing.ID {
+		a.srv.writeErr(ctx, w, http.StatusBadRequest, "id does not match the path", "")
+		return
+	}
+	rule.CreatedAt = existing.CreatedAt
+	rule.UpdatedAt = time.Now().UTC()
+	if err := a.rules.UpdateComplianceRule(ctx, rule); err != nil {
+		a.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+	a.srv.logAudit(ctx, audit.ActionUpdate, audit.ResourceComplianceRule, rule.ID, rule.Name, http.StatusOK)
+	writeJSON(w, http.StatusOK, rule)
+}
+
+// handleDeleteRule removes a compliance rule.
+// DELETE /api/v1/analysis/rules/{id}
+func (a *AnalysisServer) handleDeleteRule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := r.PathValue("id")
+	if err := a.rules.DeleteComplianceRule(ctx, id); err != nil {
+		a.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+	a.srv.logAudit(ctx, audit.ActionDelete, audit.ResourceComplianceRule, id, "", http.StatusNoContent)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeRule reads and validates a rule document. On PUT a missing id is
+// taken from the path.
+func (a *AnalysisServer) decodeRule(w http.ResponseWriter, r *http.Request) (domain.ComplianceRule, bool) {
+	rule := domain.ComplianceRule{Enabled: true}
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		a.srv.writeErr(r.Context(), w, http.StatusBadRequest, "invalid request body", err.Error())
+		return rule, false
+	}
+	rule.ID = strings.TrimSpace(rule.ID)
+	if rule.ID == "" {
+		rule.ID = r.PathValue("id")
+	}
+	rule.Name = strings.TrimSpace(rule.Name)
+	if rule.Severity == "" {
+		rule.Severity = "warning"
+	}
+	if msg := domain.ValidateComplianceRule(&rule); msg != "" {
+		a.srv.writeErr(r.Context(), w, http.StatusBadRequest, "invalid compliance rule", msg)
+		return rule, false
+	}
+	return rule, true
+}
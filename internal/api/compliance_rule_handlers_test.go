@@ -0,0 +1,160 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	stdhttp "net/http"
+	"testing"
+	"time"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/observability"
+	"cloudpam/internal/planning"
+	"cloudpam/internal/storage"
+)
+
+func setupComplianceRuleServer() *stdhttp.ServeMux {
+	st := storage.NewMemoryStore()
+	mux := stdhttp.NewServeMux()
+	logger := observability.NewLogger(observability.Config{Level: "info", Format: "json", Output: io.Discard})
+	srv := NewServer(mux, st, logger, nil, nil)
+	srv.registerUnprotectedTestRoutes()
+	analysisSvc := planning.NewAnalysisService(st)
+	rules := storage.NewMemoryComplianceRuleStore()
+	analysisSvc.SetComplianceRules(rules)
+	srv.SetPoolAdmission(analysisSvc)
+	analysisSrv := NewAnalysisServer(srv, analysisSvc)
+	analysisSrv.SetComplianceRuleStore(rules)
+	analysisSrv.RegisterAnalysisRoutes()
+	return mux
+}
+
+func TestComplianceRuleHandlers_CRUD(t *testing.T) {
+	mux := setupComplianceRuleServer()
+
+	body := `{"id":"TAGS-001","name":"Ownership tags","severity":"error",
+		"check":{"type":"required_tags","tags":["owner","cost-center"]},"remediation":"Tag the pool"}`
+	rr := doJSON(t, mux, stdhttp.MethodPost, "/api/v1/analysis/rules", body, stdhttp.StatusCreated)
+	var rule domain.ComplianceRule
+	if err := json.Unmarshal(rr.Body.Bytes(), &rule); err != nil {
+		t.Fatal(err)
+	}
+	if !rule.Enabled || rule.Enforce || rule.CreatedAt.IsZero() {
+		t.Fatalf("created rule = %s", rr.Body.String())
+	}
+	doJSON(t, mux, stdhttp.MethodPost, "/api/v1/analysis/rules", body, stdhttp.StatusConflict)
+	doJSON(t, mux, stdhttp.MethodPost, "/api/v1/analysis/rules", `{"id":"NAME-001","name":"x","check":{"type":"max_depth","max_depth":2}}`, stdhttp.StatusBadRequest)
+	doJSON(t, mux, stdhttp.MethodPost, "/api/v1/analysis/rules", `{"id":"BAD","name":"x","check":{"type":"name_pattern","pattern":"("}}`, stdhttp.StatusBadRequest)
+
+	doJSON(t, mux, stdhttp.MethodPost, "/api/v1/pools", `{"name":"root","cidr":"10.0.0.0/16"}`, stdhttp.StatusCreated)
+	rr = doJSON(t, mux, stdhttp.MethodPost, "/api/v1/analysis/compliance", `{}`, stdhttp.StatusOK)
+	if !containsRule(t, rr.Body.Bytes(), "TAGS-001") {
+		t.Fatal("untagged pool did not violate TAGS-001")
+	}
+
+	// Enforced: untagged pools are rejected, tagged ones admitted.
+	doJSON(t, mux, stdhttp.MethodPut, "/api/v1/analysis/rules/TAGS-001",
+		`{"name":"Ownership tags","severity":"error","enforce":true,"check":{"type":"required_tags","tags":["owner"]}}`, stdhttp.StatusOK)
+	rr = doJSON(t, mux, stdhttp.MethodPost, "/api/v1/pools", `{"name":"lab","cidr":"192.168.0.0/24"}`, stdhttp.StatusBadRequest)
+	var rejection struct {
+		Error      string                         `json:"error"`
+		Violations []planning.ComplianceViolation `json:"violations"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &rejection); err != nil {
+		t.Fatal(err)
+	}
+	if len(rejection.Violations) != 1 || rejection.Violations[0].RuleID != "TAGS-001" || rejection.Violations[0].Message != "missing required tags: owner" {
+		t.Fatalf("rejection = %s", rr.Body.String())
+	}
+	doJSON(t, mux, stdhttp.MethodPost, "/api/v1/pools", `{"name":"lab","cidr":"192.168.0.0/24","tags":{"owner":"netops"}}`, stdhttp.StatusCreated)
+
+	doJSON(t, mux, stdhttp.MethodPut, "/api/v1/analysis/rules/TAGS-001",
+		`{"id":"OTHER","name":"x","check":{"type":"max_depth","max_depth":2}}`, stdhttp.StatusBadRequest)
+	doJSON(t, mux, stdhttp.MethodPut, "/api/v1/analysis/rules/missing",
+		`{"name":"x","check":{"type":"max_depth","max_depth":2}}`, stdhttp.StatusNotFound)
+
+	rr = doJSON(t, mux, stdhttp.MethodGet, "/api/v1/analysis/rules", "", stdhttp.StatusOK)
+	var list domain.ComplianceRuleListResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &list); err != nil || len(list.Items) != 1 || !list.Items[0].Enforce {
+		t.Fatalf("list = %s", rr.Body.String())
+	}
+	doJSON(t, mux, stdhttp.MethodGet, "/api/v1/analysis/rules/TAGS-001", "", stdhttp.StatusOK)
+	doJSON(t, mux, stdhttp.MethodDelete, "/api/v1/analysis/rules/TAGS-001", "", stdhttp.StatusNoContent)
+	doJSON(t, mux, stdhttp.MethodGet, "/api/v1/analysis/rules/TAGS-001", "", stdhttp.StatusNotFound)
+	doJSON(t, mux, stdhttp.MethodPost, "/api/v1/pools", `{"name":"lab2","cidr":"192.168.1.0/24"}`, stdhttp.StatusCreated)
+}
+
+func containsRule(t *testing.T, body []byte, ruleID string) bool {
+	t.Helper()
+	var report planning.ComplianceReport
+	if err := json.Unmarshal(body, &report); err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range report.Violations {
+		if v.RuleID == ruleID {
+			return true
+		}
+	}
+	return false
+}
+
+func TestComplianceRuleHandlers_EnforcedOnAllocations(t *testing.T) {
+	st := storage.NewMemoryStore()
+	mux := stdhttp.NewServeMux()
+	logger := observability.NewLogger(observability.Config{Level: "info", Format: "json", Output: io.Discard})
+	srv := NewServer(mux, st, logger, nil, nil)
+	srv.registerUnprotectedTestRoutes()
+	analysisSvc := planning.NewAnalysisService(st)
+	rules := storage.NewMemoryComplianceRuleStore()
+	analysisSvc.SetComplianceRules(rules)
+	srv.SetPoolAdmission(analysisSvc)
+	analysisSrv := NewAnalysisServer(srv, analysisSvc)
+	analysisSrv.SetComplianceRuleStore(rules)
+	analysisSrv.RegisterAnalysisRoutes()
+	recStore := storage.NewMemoryRecommendationStore(st)
+	recSvc := planning.NewRecommendationService(analysisSvc, recStore, st)
+	NewRecommendationServer(srv, recSvc, recStore).RegisterRecommendationRoutes()
+
+	doJSON(t, mux, stdhttp.MethodPost, "/api/v1/pools", `{"name":"root","cidr":"10.0.0.0/16"}`, stdhttp.StatusCreated)
+	doJSON(t, mux, stdhttp.MethodPost, "/api/v1/analysis/rules",
+		`{"id":"NO-FIRST","name":"Keep the first /24 free","severity":"error","enforce":true,
+		"check":{"type":"forbidden_ranges","ranges":["10.0.0.0/24"]}}`, stdhttp.StatusCreated)
+
+	// First fit lands on the forbidden block; best fit would too.
+	rr := doJSON(t, mux, stdhttp.MethodPost, "/api/v1/pools/1/allocate", `{"name":"app","prefix_length":24}`, stdhttp.StatusBadRequest)
+	var rejection struct {
+		Violations []planning.ComplianceViolation `json:"violations"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &rejection); err != nil {
+		t.Fatal(err)
+	}
+	if len(rejection.Violations) != 1 || rejection.Violations[0].RuleID != "NO-FIRST" || rejection.Violations[0].CIDR != "10.0.0.0/24" {
+		t.Fatalf("allocate rejection = %s", rr.Body.String())
+	}
+	if pools, _ := st.ListPools(context.Background()); len(pools) != 1 {
+		t.Fatalf("rejected allocation created a pool: %+v", pools)
+	}
+	doJSON(t, mux, stdhttp.MethodPost, "/api/v1/pools/1/allocate", `{"name":"app","prefix_length":23}`, stdhttp.StatusBadRequest)
+
+	now := time.Now().UTC()
+	parentID := int64(1)
+	for _, rec := range []domain.Recommendation{
+		{ID: "alloc-bad", PoolID: parentID, Type: domain.RecommendationTypeAllocation, Status: domain.RecommendationStatusPending,
+			SuggestedCIDR: "10.0.0.0/24", CreatedAt: now, UpdatedAt: now},
+		{ID: "alloc-ok", PoolID: parentID, Type: domain.RecommendationTypeAllocation, Status: domain.RecommendationStatusPending,
+			SuggestedCIDR: "10.0.1.0/24", CreatedAt: now, UpdatedAt: now},
+	} {
+		if err := recStore.CreateRecommendation(context.Background(), rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rr = doJSON(t, mux, stdhttp.MethodPost, "/api/v1/recommendations/alloc-bad/apply", `{}`, stdhttp.StatusBadRequest)
+	if err := json.Unmarshal(rr.Body.Bytes(), &rejection); err != nil || len(rejection.Violations) != 1 {
+		t.Fatalf("recommendation rejection = %s", rr.Body.String())
+	}
+	if rec, _ := recStore.GetRecommendation(context.Background(), "alloc-bad"); rec.Status != domain.RecommendationStatusPending {
+		t.Errorf("rejected recommendation status = %s", rec.Status)
+	}
+	doJSON(t, mux, stdhttp.MethodPost, "/api/v1/recommendations/alloc-ok/apply", `{}`, stdhttp.StatusOK)
+}
@@ -132,7 +132,7 @@ func TestCreatePoolValidationCov(t *testing.T) {
 		{"malformed json", `{`},
 		{"blank name", `{"name":"   ","cidr":"10.0.0.0/16"}`},
 		{"invalid cidr", `{"name":"p","cidr":"not-a-cidr"}`},
-		{"ipv6 cidr", `{"name":"p","cidr":"2001:db8::/32"}`},
+		{"ipv6 link-local cidr", `{"name":"p","cidr":"fe80::/64"}`},
 		{"host bits set", `{"name":"p","cidr":"10.0.0.1/16"}`},
 		{"invalid type", `{"name":"p","cidr":"10.0.0.0/16","type":"wormhole"}`},
 		{"invalid status", `{"name":"p","cidr":"10.0.0.0/16","status":"pending-ish"}`},
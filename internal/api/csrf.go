@@ -46,8 +46,9 @@ func CSRFMiddleware() Middleware {
 				return
 			}
 
-			// Skip CSRF for login, setup, and OIDC endpoints (OIDC uses state parameter for security)
-			if r.URL.Path == "/api/v1/auth/login" || r.URL.Path == "/api/v1/auth/setup" || strings.HasPrefix(r.URL.Path, "/api/v1/auth/oidc/") {
+			// Skip CSRF for login, setup, and OIDC endpoints (OIDC uses state parameter for security).
+			// The MFA login step is bound to the mfa_token in its body, not to a cookie.
+			if r.URL.Path == "/api/v1/auth/login" || strings.HasPrefix(r.URL.Path, "/api/v1/auth/login/") || r.URL.Path == "/api/v1/auth/setup" || strings.HasPrefix(r.URL.Path, "/api/v1/auth/oidc/") {
 				next.ServeHTTP(w, r)
 				return
 			}
@@ -28,6 +28,7 @@ type DiscoveryServer struct {
 	syncService  *discovery.SyncService
 	keyStore     auth.KeyStore
 	networkStore storage.NetworkStore
+	schedules    storage.DiscoveryScheduleStore
 }
 
 // NewDiscoveryServer creates a new DiscoveryServer.
@@ -41,6 +42,12 @@ func (d *DiscoveryServer) SetNetworkStore(networkStore storage.NetworkStore) {
 	d.networkStore = networkStore
 }
 
+// SetScheduleStore attaches per-account discovery schedule storage. Without
+// it the schedule endpoints report that scheduling is unavailable.
+func (d *DiscoveryServer) SetScheduleStore(schedules storage.DiscoveryScheduleStore) {
+	d.schedules = schedules
+}
+
 // RegisterDiscoveryRoutes registers discovery routes without RBAC.
 func (d *DiscoveryServer) RegisterDiscoveryRoutes() {
 	d.srv.handleOpenAPIRouteFunc("/api/v1/discovery/resources", d.handleResources)
@@ -53,12 +60,18 @@ func (d *DiscoveryServer) RegisterDiscoveryRoutes() {
 	d.srv.handleOpenAPIRouteFunc("/api/v1/discovery/ingest/org", d.handleOrgIngest)
 	d.srv.handleOpenAPIRouteFunc("/api/v1/discovery/agents", d.handleListAgents)
 	d.srv.handleOpenAPIRouteFunc("/api/v1/discovery/agents/", d.handleAgentsSubroutes)
+	d.srv.handleOpenAPIRouteFunc("GET /api/v1/discovery/schedules", d.handleListSchedules)
+	d.srv.handleOpenAPIRouteFunc("GET /api/v1/discovery/schedules/{id}", d.handleGetSchedule)
+	d.srv.handleOpenAPIRouteFunc("PUT /api/v1/discovery/schedules/{id}", d.handlePutSchedule)
+	d.srv.handleOpenAPIRouteFunc("DELETE /api/v1/discovery/schedules/{id}", d.handleDeleteSchedule)
 }
 
 // RegisterProtectedDiscoveryRoutes registers discovery routes with RBAC.
 func (d *DiscoveryServer) RegisterProtectedDiscoveryRoutes(dualMW Middleware, logger *slog.Logger) {
 	readMW := RequirePermissionMiddleware(auth.ResourceDiscovery, auth.ActionRead, logger)
 	createMW := RequirePermissionMiddleware(auth.ResourceDiscovery, auth.ActionCreate, logger)
+	updateMW := RequirePermissionMiddleware(auth.ResourceDiscovery, auth.ActionUpdate, logger)
+	deleteMW := RequirePermissionMiddleware(auth.ResourceDiscovery, auth.ActionDelete, logger)
 
 	d.srv.handleOpenAPIRoute("/api/v1/discovery/resources", dualMW(readMW(http.HandlerFunc(d.handleResources))))
 	d.srv.handleOpenAPIRoute("/api/v1/discovery/resources/", dualMW(d.protectedResourcesSubroutes(logger)))
@@ -72,6 +85,11 @@ func (d *DiscoveryServer) RegisterProtectedDiscoveryRoutes(dualMW Middleware, lo
 	d.srv.handleOpenAPIRoute("/api/v1/discovery/ingest/org", dualMW(createMW(http.HandlerFunc(d.handleOrgIngest))))
 	d.srv.handleOpenAPIRoute("/api/v1/discovery/agents", dualMW(readMW(http.HandlerFunc(d.handleListAgents))))
 	d.srv.handleOpenAPIRoute("/api/v1/discovery/agents/", dualMW(d.protectedAgentsSubroutes(logger)))
+
+	d.srv.handleOpenAPIRoute("GET /api/v1/discovery/schedules", dualMW(readMW(http.HandlerFunc(d.handleListSchedules))))
+	d.srv.handleOpenAPIRoute("GET /api/v1/discovery/schedules/{id}", dualMW(readMW(http.HandlerFunc(d.handleGetSchedule))))
+	d.srv.handleOpenAPIRoute("PUT /api/v1/discovery/schedules/{id}", dualMW(updateMW(http.HandlerFunc(d.handlePutSchedule))))
+	d.srv.handleOpenAPIRoute("DELETE /api/v1/discovery/schedules/{id}", dualMW(deleteMW(http.HandlerFunc(d.handleDeleteSchedule))))
 }
 
 // protectedAgentsSubroutes returns a handler for /api/v1/discovery/agents/ with RBAC.
@@ -354,6 +372,10 @@ func (d *DiscoveryServer) handleImportApply(w http.ResponseWriter, r *http.Reque
 			d.srv.writeErr(r.Context(), w, http.StatusBadRequest, err.Error(), "")
 			return
 		}
+		if errors.Is(err, storage.ErrValidation) || errors.Is(err, storage.ErrConflict) {
+			d.srv.writeStoreErr(r.Context(), w, err)
+			return
+		}
 		d.srv.writeErr(r.Context(), w, http.StatusInternalServerError, "apply import failed", err.Error())
 		return
 	}
@@ -382,6 +404,9 @@ func isDiscoveryImportClientError(err error) bool {
 	return errors.As(err, &target)
 }
 
+// applyDiscoveryImport imports the selected resources in one transaction:
+// either every pool and link it writes lands, or none do. Items the preview
+// does not mark importable are skipped rather than failing the import.
 func (d *DiscoveryServer) applyDiscoveryImport(ctx context.Context, req domain.DiscoveryImportApplyRequest, opts discoveryImportApplyOptions) (domain.DiscoveryImportApplyResponse, error) {
 	previewReq := domain.DiscoveryImportPreviewRequest(req)
 	preview, err := d.previewDiscoveryImport(ctx, previewReq)
@@ -389,11 +414,31 @@ func (d *DiscoveryServer) applyDiscoveryImport(ctx context.Context, req domain.D
 		return domain.DiscoveryImportApplyResponse{}, err
 	}
 
+	var resp domain.DiscoveryImportApplyResponse
+	var rels []domain.CreateNetworkRelationship
+	err = d.srv.withTx(ctx, func(st storage.Store) error {
+		var err error
+		resp, rels, err = d.applyDiscoveryImportIn(ctx, st, req, preview, opts)
+		return err
+	})
+	if err != nil {
+		return domain.DiscoveryImportApplyResponse{}, err
+	}
+	d.persistDiscoveryRelationships(ctx, rels)
+	return resp, nil
+}
+
+// applyDiscoveryImportIn performs the writes of an import through st, which
+// is normally a transaction. Network relationships are returned rather than
+// written so the caller can record them once the transaction commits.
+func (d *DiscoveryServer) applyDiscoveryImportIn(ctx context.Context, st storage.Store, req domain.DiscoveryImportApplyRequest, preview domain.DiscoveryImportPreviewResponse, opts discoveryImportApplyOptions) (domain.DiscoveryImportApplyResponse, []domain.CreateNetworkRelationship, error) {
+	ds := txStore(st, d.store)
 	resp := domain.DiscoveryImportApplyResponse{
 		Preview: preview,
 		Summary: discoveryImportApplyPreviewSummary(preview),
 		Errors:  []string{},
 	}
+	var rels []domain.CreateNetworkRelationship
 	items := append([]domain.DiscoveryImportPreviewItem{}, preview.Items...)
 	sort.SliceStable(items, func(i, j int) bool {
 		if items[i].ResourceType == items[j].ResourceType {
@@ -414,21 +459,19 @@ func (d *DiscoveryServer) applyDiscoveryImport(ctx context.Context, req domain.D
 			resp.Skipped++
 			continue
 		}
-		res, err := d.store.GetDiscoveredResource(ctx, item.ResourceID)
+		res, err := ds.GetDiscoveredResource(ctx, item.ResourceID)
 		if err != nil {
-			resp.Errors = append(resp.Errors, fmt.Sprintf("%s: load resource: %v", item.ResourceID, err))
-			resp.Skipped++
-			continue
+			return resp, nil, fmt.Errorf("%s: load resource: %w", item.ResourceID, err)
+		}
+		if rel, ok := discoveryImportCandidate(item, *res); ok {
+			rels = append(rels, rel)
 		}
-		d.persistDiscoveryImportCandidate(ctx, item, *res)
 
 		if item.ProposedPoolID != nil && (item.ProposedAction == "link_pool" || opts.AllowBlocked) {
-			if err := d.store.LinkResourceToPool(ctx, item.ResourceID, *item.ProposedPoolID); err != nil {
-				resp.Errors = append(resp.Errors, fmt.Sprintf("%s: link pool: %v", res.ResourceID, err))
-				resp.Skipped++
-				continue
+			if err := ds.LinkResourceToPool(ctx, item.ResourceID, *item.ProposedPoolID); err != nil {
+				return resp, nil, fmt.Errorf("%s: link pool: %w", res.ResourceID, err)
 			}
-			d.persistDiscoveryRelationship(ctx, domain.CreateNetworkRelationship{
+			rels = append(rels, domain.CreateNetworkRelationship{
 				Type:            domain.NetworkRelationshipImportedAs,
 				SourceKind:      "discovered",
 				SourceID:        item.ResourceID.String(),
@@ -457,32 +500,21 @@ func (d *DiscoveryServer) applyDiscoveryImport(ctx context.Context, req domain.D
 			continue
 		}
 		if parentID != nil {
-			if err := d.validateImportParentPool(ctx, req.AccountID, *parentID); err != nil {
+			if err := validateImportParentPool(ctx, st, req.AccountID, *parentID); err != nil {
 				resp.Errors = append(resp.Errors, fmt.Sprintf("%s: %v", res.ResourceID, err))
 				resp.Skipped++
 				continue
 			}
 		}
-		pool, err := d.createDiscoveredPool(ctx, req.AccountID, *res, parentID)
+		pool, err := createDiscoveredPool(ctx, st, req.AccountID, *res, parentID)
 		if err != nil {
-			resp.Errors = append(resp.Errors, fmt.Sprintf("%s: create pool: %v", res.ResourceID, err))
-			resp.Skipped++
-			continue
+			return resp, nil, fmt.Errorf("%s: create pool: %w", res.ResourceID, err)
 		}
-		resp.CreatedPoolIDs = append(resp.CreatedPoolIDs, pool.ID)
-		if err := d.store.LinkResourceToPool(ctx, item.ResourceID, pool.ID); err != nil {
-			if ok, cleanupErr := d.srv.store.DeletePool(ctx, pool.ID); cleanupErr != nil {
-				resp.Errors = append(resp.Errors, fmt.Sprintf("%s: cleanup created pool %d: %v", res.ResourceID, pool.ID, cleanupErr))
-			} else if !ok {
-				resp.Errors = append(resp.Errors, fmt.Sprintf("%s: cleanup created pool %d: not found", res.ResourceID, pool.ID))
-			} else {
-				resp.CreatedPoolIDs = resp.CreatedPoolIDs[:len(resp.CreatedPoolIDs)-1]
-			}
-			resp.Errors = append(resp.Errors, fmt.Sprintf("%s: link new pool: %v", res.ResourceID, err))
-			resp.Skipped++
-			continue
+		if err := ds.LinkResourceToPool(ctx, item.ResourceID, pool.ID); err != nil {
+			return resp, nil, fmt.Errorf("%s: link new pool: %w", res.ResourceID, err)
 		}
-		d.persistDiscoveryRelationship(ctx, domain.CreateNetworkRelationship{
+		resp.CreatedPoolIDs = append(resp.CreatedPoolIDs, pool.ID)
+		rels = append(rels, domain.CreateNetworkRelationship{
 			Type:            domain.NetworkRelationshipImportedAs,
 			SourceKind:      "discovered",
 			SourceID:        item.ResourceID.String(),
@@ -494,7 +526,7 @@ func (d *DiscoveryServer) applyDiscoveryImport(ctx context.Context, req domain.D
 			ResolutionState: string(domain.DriftStatusResolved),
 		})
 		if parentID != nil {
-			d.persistDiscoveryRelationship(ctx, domain.CreateNetworkRelationship{
+			rels = append(rels, domain.CreateNetworkRelationship{
 				Type:            domain.NetworkRelationshipContains,
 				SourceKind:      "pool",
 				SourceID:        fmt.Sprintf("%d", *parentID),
@@ -519,18 +551,20 @@ func (d *DiscoveryServer) applyDiscoveryImport(ctx context.Context, req domain.D
 	resp.Summary.AffectedResourceIDs = resp.LinkedResourceIDs
 	resp.Summary.CreatedPoolIDs = resp.CreatedPoolIDs
 
-	return resp, nil
+	return resp, rels, nil
 }
 
-func (d *DiscoveryServer) persistDiscoveryImportCandidate(ctx context.Context, item domain.DiscoveryImportPreviewItem, res domain.DiscoveredResource) {
+// discoveryImportCandidate describes the pool an import item was proposed
+// for, if any.
+func discoveryImportCandidate(item domain.DiscoveryImportPreviewItem, res domain.DiscoveredResource) (domain.CreateNetworkRelationship, bool) {
 	targetPoolID := item.ProposedPoolID
 	if targetPoolID == nil {
 		targetPoolID = item.ProposedParentPoolID
 	}
 	if targetPoolID == nil {
-		return
+		return domain.CreateNetworkRelationship{}, false
 	}
-	d.persistDiscoveryRelationship(ctx, domain.CreateNetworkRelationship{
+	return domain.CreateNetworkRelationship{
 		Type:            domain.NetworkRelationshipCandidateImport,
 		SourceKind:      "discovered",
 		SourceID:        item.ResourceID.String(),
@@ -540,7 +574,13 @@ func (d *DiscoveryServer) persistDiscoveryImportCandidate(ctx context.Context, i
 		Reason:          "discovery import candidate",
 		Evidence:        discoveryRelationshipEvidence(res, "proposed_action="+item.ProposedAction, "candidate_pool_id="+fmt.Sprintf("%d", *targetPoolID), strings.Join(item.Evidence, "; ")),
 		ResolutionState: "open",
-	})
+	}, true
+}
+
+func (d *DiscoveryServer) persistDiscoveryRelationships(ctx context.Context, rels []domain.CreateNetworkRelationship) {
+	for _, rel := range rels {
+		d.persistDiscoveryRelationship(ctx, rel)
+	}
 }
 
 func (d *DiscoveryServer) persistDiscoveryRelationship(ctx context.Context, in domain.CreateNetworkRelationship) {
@@ -868,8 +908,8 @@ func (d *DiscoveryServer) classifyPoolRelationships(item *domain.DiscoveryImport
 	}
 }
 
-func (d *DiscoveryServer) validateImportParentPool(ctx context.Context, accountID int64, parentID int64) error {
-	parent, found, err := d.srv.store.GetPool(ctx, parentID)
+func validateImportParentPool(ctx context.Context, st storage.Store, accountID int64, parentID int64) error {
+	parent, found, err := st.GetPool(ctx, parentID)
 	if err != nil {
 		return err
 	}
@@ -939,7 +979,7 @@ func (d *DiscoveryServer) listAllDiscoveredResources(ctx context.Context, accoun
 	return out, nil
 }
 
-func (d *DiscoveryServer) createDiscoveredPool(ctx context.Context, accountID int64, res domain.DiscoveredResource, parentID *int64) (domain.Pool, error) {
+func createDiscoveredPool(ctx context.Context, st storage.Store, accountID int64, res domain.DiscoveredResource, parentID *int64) (domain.Pool, error) {
 	poolType := domain.PoolTypeSubnet
 	if res.ResourceType == domain.ResourceTypeVPC {
 		poolType = domain.PoolTypeVPC
@@ -948,7 +988,7 @@ func (d *DiscoveryServer) createDiscoveredPool(ctx context.Context, accountID in
 	if name == "" {
 		name = res.ResourceID
 	}
-	return d.srv.store.CreatePool(ctx, domain.CreatePool{
+	return st.CreatePool(ctx, domain.CreatePool{
 		Name:      name,
 		CIDR:      res.CIDR,
 		ParentID:  parentID,
@@ -1273,17 +1313,7 @@ func (d *DiscoveryServer) triggerSync(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if agent := d.selectConnectedAgent(r.Context(), body.AccountID); agent != nil {
-		job, err := d.createAgentSyncJob(r.Context(), account.ID, agent.ID)
-		if err != nil {
-			d.srv.writeErr(r.Context(), w, http.StatusInternalServerError, "create agent sync job failed", err.Error())
-			return
-		}
-		writeJSON(w, http.StatusOK, job)
-		return
-	}
-
-	job, err := d.syncService.Sync(r.Context(), account)
+	job, err := d.StartAccountSync(r.Context(), account)
 	if err != nil {
 		// Job was still created even on failure — return it
 		if job != nil {
@@ -1297,6 +1327,23 @@ func (d *DiscoveryServer) triggerSync(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, job)
 }
 
+// StartAccountSync queues a sync job for a healthy agent if one is connected
+// and otherwise runs the local collector. A queued agent job is returned with
+// status pending; a failed local sync returns both the job and the error.
+func (d *DiscoveryServer) StartAccountSync(ctx context.Context, account domain.Account) (*domain.SyncJob, error) {
+	if agent := d.selectConnectedAgent(ctx, account.ID); agent != nil {
+		job, err := d.createAgentSyncJob(ctx, account.ID, agent.ID)
+		if err != nil {
+			return nil, fmt.Errorf("create agent sync job: %w", err)
+		}
+		return &job, nil
+	}
+	if d.syncService == nil {
+		return nil, errors.New("sync service not available")
+	}
+	return d.syncService.Sync(ctx, account)
+}
+
 func (d *DiscoveryServer) createAgentSyncJob(ctx context.Context, accountID int64, agentID uuid.UUID) (domain.SyncJob, error) {
 	now := time.Now().UTC()
 	return d.store.CreateSyncJob(ctx, domain.SyncJob{
@@ -1696,13 +1743,7 @@ func (d *DiscoveryServer) handleGetAgent(w http.ResponseWriter, r *http.Request)
 
 // computeAgentStatus determines agent health based on last_seen_at.
 func computeAgentStatus(lastSeen time.Time, now time.Time) domain.AgentStatus {
-	elapsed := now.Sub(lastSeen)
-	if elapsed < 5*time.Minute {
-		return domain.AgentStatusHealthy
-	} else if elapsed < 15*time.Minute {
-		return domain.AgentStatusStale
-	}
-	return domain.AgentStatusOffline
+	return discovery.AgentStatusAt(lastSeen, now)
 }
 
 // getAPIKeyIDFromContext extracts the API key ID from the context.
@@ -1771,12 +1812,13 @@ func (d *DiscoveryServer) handleOrgIngest(w http.ResponseWriter, r *http.Request
 			continue
 		}
 
-		// Build key: "aws:<account_id>"
-		key := "aws:" + orgAcct.AWSAccountID
+		// Build key: "<provider>:<account_id>", e.g. "aws:123456789012" or
+		// "azure:<subscription_id>".
 		provider := orgAcct.Provider
 		if provider == "" {
 			provider = "aws"
 		}
+		key := provider + ":" + orgAcct.AWSAccountID
 
 		// Look up or auto-create the CloudPAM account
 		account, err := d.srv.store.GetAccountByKey(r.Context(), key)
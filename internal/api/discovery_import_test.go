@@ -861,7 +861,7 @@ func TestDiscoveryResourcesSearchAcrossPages(t *testing.T) {
 	}
 }
 
-func TestDiscoveryImportApplyDeletesCreatedPoolWhenLinkFails(t *testing.T) {
+func TestDiscoveryImportApplyRollsBackWhenLinkFails(t *testing.T) {
 	discSrv, st, ds, _ := setupDiscoveryTestServer()
 	account, err := st.CreateAccount(t.Context(), domain.CreateAccount{Key: "aws:123456789012", Name: "prod", Provider: "aws"})
 	if err != nil {
@@ -886,23 +886,13 @@ func TestDiscoveryImportApplyDeletesCreatedPoolWhenLinkFails(t *testing.T) {
 	discSrv.store = &failLinkDiscoveryStore{DiscoveryStore: ds, failID: vpcID}
 
 	body := fmt.Sprintf("{\"account_id\":%d,\"resource_ids\":[\"%s\"]}", account.ID, vpcID)
-	rr := doJSON(t, discSrv.srv.mux, http.MethodPost, "/api/v1/discovery/import/apply", body, http.StatusOK)
-	var applied domain.DiscoveryImportApplyResponse
-	if err := json.Unmarshal(rr.Body.Bytes(), &applied); err != nil {
-		t.Fatalf("unmarshal apply: %v", err)
-	}
-	if applied.PoolsCreated != 0 || applied.ResourcesLinked != 0 || applied.Skipped != 1 {
-		t.Fatalf("failed link should skip without completed imports: %+v", applied)
-	}
-	if len(applied.CreatedPoolIDs) != 0 {
-		t.Fatalf("created pool id should be removed after cleanup succeeds: %+v", applied.CreatedPoolIDs)
-	}
+	doJSON(t, discSrv.srv.mux, http.MethodPost, "/api/v1/discovery/import/apply", body, http.StatusInternalServerError)
 	pools, err := st.ListPools(t.Context())
 	if err != nil {
 		t.Fatalf("list pools: %v", err)
 	}
 	if len(pools) != 0 {
-		t.Fatalf("link failure should clean up created pool, got %+v", pools)
+		t.Fatalf("link failure should roll back the created pool, got %+v", pools)
 	}
 	res, err := ds.GetDiscoveredResource(t.Context(), vpcID)
 	if err != nil {
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloudpam/internal/audit"
+	"cloudpam/internal/discovery"
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+// handleListSchedules returns every account's discovery schedule with its
+// next and last run.
+// GET /api/v1/discovery/schedules
+func (d *DiscoveryServer) handleListSchedules(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !d.requireSchedules(w, r) {
+		return
+	}
+	schedules, err := d.schedules.ListDiscoverySchedules(ctx)
+	if err != nil {
+		d.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+	accounts, err := d.srv.store.ListAccounts(ctx)
+	if err != nil {
+		d.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+	byID := make(map[int64]domain.Account, len(accounts))
+	for _, a := range accounts {
+		byID[a.ID] = a
+	}
+
+	items := make([]domain.DiscoveryScheduleView, 0, len(schedules))
+	for _, sc := range schedules {
+		account, ok := byID[sc.AccountID]
+		if !ok {
+			// Soft-deleted account; the schedule no longer runs.
+			continue
+		}
+		items = append(items, scheduleView(sc, account))
+	}
+	writeJSON(w, http.StatusOK, domain.DiscoveryScheduleListResponse{Items: items})
+}
+
+// handleGetSchedule returns one account's discovery schedule.
+// GET /api/v1/discovery/schedules/{id}
+func (d *DiscoveryServer) handleGetSchedule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !d.requireSchedules(w, r) {
+		return
+	}
+	account, ok := d.scheduleAccount(w, r)
+	if !ok {
+		return
+	}
+	sc, err := d.schedules.GetDiscoverySchedule(ctx, account.ID)
+	if err != nil {
+		d.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, scheduleView(*sc, account))
+}
+
+// handlePutSchedule creates or replaces an account's discovery schedule and
+// computes its next run. Last-run fields survive reconfiguration.
+// PUT /api/v1/discovery/schedules/{id}
+func (d *DiscoveryServer) handlePutSchedule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !d.requireSchedules(w, r) {
+		return
+	}
+	account, ok := d.scheduleAccount(w, r)
+	if !ok {
+		return
+	}
+
+	var input domain.UpsertDiscoveryScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		d.srv.writeErr(ctx, w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	now := time.Now().UTC()
+	sc := domain.DiscoverySchedule{
+		AccountID:   account.ID,
+		Enabled:     input.Enabled == nil || *input.Enabled,
+		Interval:    strings.TrimSpace(input.Interval),
+		Cron:        strings.TrimSpace(input.Cron),
+		DetectDrift: input.DetectDrift == nil || *input.DetectDrift,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := discovery.ValidateSchedule(sc); err != nil {
+		d.srv.writeErr(ctx, w, http.StatusBadRequest, "invalid schedule", err.Error())
+		return
+	}
+	if sc.Enabled {
+		next, err := discovery.NextScheduledRun(sc, now)
+		if err != nil {
+			d.srv.writeErr(ctx, w, http.StatusBadRequest, "invalid schedule", err.Error())
+			return
+		}
+		sc.NextRunAt = &next
+	}
+
+	action := audit.ActionUpdate
+	if _, err := d.schedules.GetDiscoverySchedule(ctx, account.ID); errors.Is(err, storage.ErrNotFound) {
+		action = audit.ActionCreate
+	} else if err != nil {
+		d.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+	if err := d.schedules.UpsertDiscoverySchedule(ctx, sc); err != nil {
+		d.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+	saved, err := d.schedules.GetDiscoverySchedule(ctx, account.ID)
+	if err != nil {
+		d.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+
+	d.srv.logAudit(ctx, action, audit.ResourceDiscoverySchedule, strconv.FormatInt(account.ID, 10), account.Name, http.StatusOK)
+	writeJSON(w, http.StatusOK, scheduleView(*saved, account))
+}
+
+// handleDeleteSchedule stops scheduled discovery for an account.
+// DELETE /api/v1/discovery/schedules/{id}
+func (d *DiscoveryServer) handleDeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !d.requireSchedules(w, r) {
+		return
+	}
+	account, ok := d.scheduleAccount(w, r)
+	if !ok {
+		return
+	}
+	if err := d.schedules.DeleteDiscoverySchedule(ctx, account.ID); err != nil {
+		d.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+	d.srv.logAudit(ctx, audit.ActionDelete, audit.ResourceDiscoverySchedule, strconv.FormatInt(account.ID, 10), account.Name, http.StatusNoContent)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (d *DiscoveryServer) requireSchedules(w http.ResponseWriter, r *http.Request) bool {
+	if d.schedules == nil {
+		d.srv.writeErr(r.Context(), w, http.StatusServiceUnavailable, "discovery scheduling not available", "")
+		return false
+	}
+	return true
+}
+
+// scheduleAccount resolves the {id} path value to a live account.
+func (d *DiscoveryServer) scheduleAccount(w http.ResponseWriter, r *http.Request) (domain.Account, bool) {
+	ctx := r.Context()
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id < 1 {
+		d.srv.writeErr(ctx, w, http.StatusBadRequest, "invalid account id", "")
+		return domain.Account{}, false
+	}
+	account, found, err := d.srv.store.GetAccount(ctx, id)
+	if err != nil {
+		d.srv.writeErr(ctx, w, http.StatusInternalServerError, "account lookup failed", err.Error())
+		return domain.Account{}, false
+	}
+	if !found {
+		d.srv.writeErr(ctx, w, http.StatusNotFound, "account not found", "")
+		return domain.Account{}, false
+	}
+	return account, true
+}
+
+func scheduleView(sc domain.DiscoverySchedule, account domain.Account) domain.DiscoveryScheduleView {
+	return domain.DiscoveryScheduleView{
+		DiscoverySchedule: sc,
+		AccountKey:        account.Key,
+		AccountName:       account.Name,
+		Provider:          account.Provider,
+	}
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+func TestDiscoveryScheduleHandlers_CRUD(t *testing.T) {
+	discSrv, st, _, _ := setupDiscoveryTestServer()
+	schedules := storage.NewMemoryDiscoveryScheduleStore()
+	discSrv.SetScheduleStore(schedules)
+	mux := discSrv.srv.mux
+	ctx := context.Background()
+
+	acct, err := st.CreateAccount(ctx, domain.CreateAccount{Key: "aws:111", Name: "Prod", Provider: "aws"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := "/api/v1/discovery/schedules/" + strconv.FormatInt(acct.ID, 10)
+
+	doJSON(t, mux, http.MethodGet, path, "", http.StatusNotFound)
+	doJSON(t, mux, http.MethodPut, "/api/v1/discovery/schedules/999", `{"interval":"6h"}`, http.StatusNotFound)
+	doJSON(t, mux, http.MethodPut, "/api/v1/discovery/schedules/abc", `{"interval":"6h"}`, http.StatusBadRequest)
+	doJSON(t, mux, http.MethodPut, path, `{}`, http.StatusBadRequest)
+	doJSON(t, mux, http.MethodPut, path, `{"interval":"1m"}`, http.StatusBadRequest)
+	doJSON(t, mux, http.MethodPut, path, `{"interval":"6h","cron":"@daily"}`, http.StatusBadRequest)
+	doJSON(t, mux, http.MethodPut, path, `{"cron":"61 * * * *"}`, http.StatusBadRequest)
+
+	before := time.Now().UTC()
+	rr := doJSON(t, mux, http.MethodPut, path, `{"interval":"6h"}`, http.StatusOK)
+	var view domain.DiscoveryScheduleView
+	if err := json.Unmarshal(rr.Body.Bytes(), &view); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !view.Enabled || !view.DetectDrift || view.AccountKey != "aws:111" || view.NextRunAt == nil {
+		t.Fatalf("PUT response = %s", rr.Body.String())
+	}
+	if view.NextRunAt.Before(before.Add(6*time.Hour - time.Second)) {
+		t.Fatalf("next_run_at = %s, want about 6h from now", view.NextRunAt)
+	}
+
+	// A disabled schedule has no next run; the last run survives reconfiguration.
+	jobID := uuid.New()
+	if err := schedules.RecordDiscoveryScheduleRun(ctx, acct.ID, domain.DiscoveryScheduleRun{
+		At: before, Status: domain.ScheduleRunCompleted, SyncJobID: &jobID,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	rr = doJSON(t, mux, http.MethodPut, path, `{"cron":"0 3 * * *","enabled":false,"detect_drift":false}`, http.StatusOK)
+	view = domain.DiscoveryScheduleView{}
+	_ = json.Unmarshal(rr.Body.Bytes(), &view)
+	if view.Enabled || view.DetectDrift || view.NextRunAt != nil || view.Interval != "" || view.Cron != "0 3 * * *" {
+		t.Fatalf("PUT disabled response = %s", rr.Body.String())
+	}
+	if view.LastStatus != domain.ScheduleRunCompleted || view.LastSyncJobID == nil {
+		t.Fatalf("last run lost: %s", rr.Body.String())
+	}
+
+	rr = doJSON(t, mux, http.MethodGet, "/api/v1/discovery/schedules", "", http.StatusOK)
+	var list domain.DiscoveryScheduleListResponse
+	_ = json.Unmarshal(rr.Body.Bytes(), &list)
+	if len(list.Items) != 1 || list.Items[0].AccountName != "Prod" {
+		t.Fatalf("list = %s", rr.Body.String())
+	}
+
+	doJSON(t, mux, http.MethodDelete, path, "", http.StatusNoContent)
+	doJSON(t, mux, http.MethodDelete, path, "", http.StatusNotFound)
+}
+
+func TestDiscoveryScheduleHandlers_Unavailable(t *testing.T) {
+	discSrv, _, _, _ := setupDiscoveryTestServer()
+	doJSON(t, discSrv.srv.mux, http.MethodGet, "/api/v1/discovery/schedules", "", http.StatusServiceUnavailable)
+}
+
+func TestStartAccountSync_QueuesForHealthyAgent(t *testing.T) {
+	discSrv, st, ds, _ := setupDiscoveryTestServer()
+	ctx := context.Background()
+	acct, err := st.CreateAccount(ctx, domain.CreateAccount{Key: "aws:111", Name: "Prod", Provider: "aws"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	agentID := uuid.New()
+	if err := ds.UpsertAgent(ctx, domain.DiscoveryAgent{
+		ID: agentID, Name: "agent", AccountID: acct.ID, ApprovalStatus: domain.AgentApprovalApproved,
+		LastSeenAt: time.Now().UTC(), CreatedAt: time.Now().UTC(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	job, err := discSrv.StartAccountSync(ctx, acct)
+	if err != nil {
+		t.Fatalf("StartAccountSync: %v", err)
+	}
+	if job.Status != domain.SyncJobStatusPending || job.Source != "agent" || job.AgentID == nil || *job.AgentID != agentID {
+		t.Fatalf("job = %+v, want pending agent job", job)
+	}
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"cloudpam/internal/storage"
+)
+
+// Pools and accounts carry a version that increases on every write. It is
+// served as a strong ETag on GET and PATCH, and PATCH and DELETE honour
+// If-Match against it with 412 Precondition Failed on a mismatch.
+
+// etag formats a row version as a strong entity tag.
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// ifMatchAllows reports whether the request's If-Match header admits
+// version. A missing header and "*" admit any version. Weak tags never
+// match, since If-Match uses strong comparison.
+func ifMatchAllows(r *http.Request, version int64) bool {
+	h := strings.TrimSpace(r.Header.Get("If-Match"))
+	if h == "" || h == "*" {
+		return true
+	}
+	want := etag(version)
+	for _, tag := range strings.Split(h, ",") {
+		if strings.TrimSpace(tag) == want {
+			return true
+		}
+	}
+	return false
+}
+
+func hasIfMatch(r *http.Request) bool {
+	return strings.TrimSpace(r.Header.Get("If-Match")) != ""
+}
+
+// expectedPoolVersion resolves If-Match for a pool write. It returns nil when
+// the request is unconditional, or the current version when the header
+// admits it; the store re-checks that version as it writes, so a concurrent
+// change still fails. A missing pool yields ErrNotFound and a stale tag
+// ErrPreconditionFailed.
+func expectedPoolVersion(ctx context.Context, st storage.Store, r *http.Request, id int64) (*int64, error) {
+	if !hasIfMatch(r) {
+		return nil, nil
+	}
+	p, ok, err := st.GetPool(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("pool %d: %w", id, storage.ErrNotFound)
+	}
+	if !ifMatchAllows(r, p.Version) {
+		return nil, fmt.Errorf("pool %d is at version %d: %w", id, p.Version, storage.ErrPreconditionFailed)
+	}
+	return &p.Version, nil
+}
+
+// expectedAccountVersion is expectedPoolVersion for accounts. It returns 0
+// for an unconditional request, matching UpdateAccount's convention.
+func expectedAccountVersion(ctx context.Context, st storage.Store, r *http.Request, id int64) (int64, error) {
+	if !hasIfMatch(r) {
+		return 0, nil
+	}
+	a, ok, err := st.GetAccount(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, fmt.Errorf("account %d: %w", id, storage.ErrNotFound)
+	}
+	if !ifMatchAllows(r, a.Version) {
+		return 0, fmt.Errorf("account %d is at version %d: %w", id, a.Version, storage.ErrPreconditionFailed)
+	}
+	return a.Version, nil
+}
+
+// deletePool deletes a pool, optionally with its subtree. With If-Match the
+// version check and the delete run in one transaction.
+func (s *Server) deletePool(ctx context.Context, r *http.Request, id int64, cascade bool) (bool, error) {
+	del := func(st storage.Store) (bool, error) {
+		if cascade {
+			return st.DeletePoolCascade(ctx, id)
+		}
+		return st.DeletePool(ctx, id)
+	}
+	if !hasIfMatch(r) {
+		return del(s.store)
+	}
+	var ok bool
+	err := s.withTx(ctx, func(st storage.Store) error {
+		if _, err := expectedPoolVersion(ctx, st, r, id); err != nil {
+			return err
+		}
+		var err error
+		ok, err = del(st)
+		return err
+	})
+	return ok, err
+}
+
+// deleteAccount is deletePool for accounts.
+func (s *Server) deleteAccount(ctx context.Context, r *http.Request, id int64, cascade bool) (bool, error) {
+	del := func(st storage.Store) (bool, error) {
+		if cascade {
+			return st.DeleteAccountCascade(ctx, id)
+		}
+		return st.DeleteAccount(ctx, id)
+	}
+	if !hasIfMatch(r) {
+		return del(s.store)
+	}
+	var ok bool
+	err := s.withTx(ctx, func(st storage.Store) error {
+		if _, err := expectedAccountVersion(ctx, st, r, id); err != nil {
+			return err
+		}
+		var err error
+		ok, err = del(st)
+		return err
+	})
+	return ok, err
+}
+
+// writeDeleteErr maps a delete failure. Missing rows and stale If-Match tags
+// get their own statuses; anything else keeps the historical 409.
+func (s *Server) writeDeleteErr(ctx context.Context, w http.ResponseWriter, err error) {
+	if errors.Is(err, storage.ErrNotFound) || errors.Is(err, storage.ErrPreconditionFailed) {
+		s.writeStoreErr(ctx, w, err)
+		return
+	}
+	s.writeErr(ctx, w, http.StatusConflict, err.Error(), "")
+}
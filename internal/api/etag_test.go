@@ -0,0 +1,75 @@
+package api
+
+import (
+	"context"
+	stdhttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cloudpam/internal/domain"
+)
+
+// doIfMatch is doJSON with an If-Match header.
+func doIfMatch(t *testing.T, mux *stdhttp.ServeMux, method, path, body, ifMatch string, code int) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("If-Match", ifMatch)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	if rr.Code != code {
+		t.Fatalf("%s %s (If-Match %s): expected code %d, got %d: %s", method, path, ifMatch, code, rr.Code, rr.Body.String())
+	}
+	return rr
+}
+
+func TestPoolETag(t *testing.T) {
+	srv, st := setupTestServer()
+	p, _ := st.CreatePool(context.Background(), domain.CreatePool{Name: "p", CIDR: "10.0.0.0/16"})
+	path := "/api/v1/pools/" + itoa(p.ID)
+
+	rr := doJSON(t, srv.mux, stdhttp.MethodGet, path, "", stdhttp.StatusOK)
+	if got := rr.Header().Get("ETag"); got != `"1"` {
+		t.Fatalf("GET ETag = %q, want \"1\"", got)
+	}
+
+	rr = doIfMatch(t, srv.mux, stdhttp.MethodPatch, path, `{"name":"a"}`, `"1"`, stdhttp.StatusOK)
+	if got := rr.Header().Get("ETag"); got != `"2"` {
+		t.Errorf("PATCH ETag = %q, want \"2\"", got)
+	}
+	doIfMatch(t, srv.mux, stdhttp.MethodPatch, path, `{"name":"b"}`, `"1"`, stdhttp.StatusPreconditionFailed)
+	doIfMatch(t, srv.mux, stdhttp.MethodPatch, path, `{"name":"b"}`, `W/"2"`, stdhttp.StatusPreconditionFailed)
+	doIfMatch(t, srv.mux, stdhttp.MethodPatch, path, `{"name":"b"}`, `"7", "2"`, stdhttp.StatusOK)
+	doIfMatch(t, srv.mux, stdhttp.MethodPatch, path, `{"name":"c"}`, `*`, stdhttp.StatusOK)
+	if cur, _, _ := st.GetPool(context.Background(), p.ID); cur.Name != "c" || cur.Version != 4 {
+		t.Fatalf("pool = %q v%d, want c v4", cur.Name, cur.Version)
+	}
+
+	doIfMatch(t, srv.mux, stdhttp.MethodPatch, "/api/v1/pools/9999", `{"name":"x"}`, `"1"`, stdhttp.StatusNotFound)
+	doIfMatch(t, srv.mux, stdhttp.MethodDelete, path, "", `"3"`, stdhttp.StatusPreconditionFailed)
+	doIfMatch(t, srv.mux, stdhttp.MethodDelete, path, "", `"4"`, stdhttp.StatusNoContent)
+	doJSON(t, srv.mux, stdhttp.MethodGet, path, "", stdhttp.StatusNotFound)
+}
+
+func TestAccountETag(t *testing.T) {
+	srv, st := setupTestServer()
+	a, _ := st.CreateAccount(context.Background(), domain.CreateAccount{Key: "aws:1", Name: "a"})
+	path := "/api/v1/accounts/" + itoa(a.ID)
+
+	rr := doJSON(t, srv.mux, stdhttp.MethodGet, path, "", stdhttp.StatusOK)
+	if got := rr.Header().Get("ETag"); got != `"1"` {
+		t.Fatalf("GET ETag = %q, want \"1\"", got)
+	}
+	rr = doIfMatch(t, srv.mux, stdhttp.MethodPatch, path, `{"name":"b"}`, `"1"`, stdhttp.StatusOK)
+	if got := rr.Header().Get("ETag"); got != `"2"` {
+		t.Errorf("PATCH ETag = %q, want \"2\"", got)
+	}
+	doIfMatch(t, srv.mux, stdhttp.MethodPatch, path, `{"name":"c"}`, `"1"`, stdhttp.StatusPreconditionFailed)
+	// A version in the body is not a precondition; only If-Match is.
+	doJSON(t, srv.mux, stdhttp.MethodPatch, path, `{"name":"c","version":1}`, stdhttp.StatusOK)
+	doIfMatch(t, srv.mux, stdhttp.MethodDelete, path, "", `"2"`, stdhttp.StatusPreconditionFailed)
+	doIfMatch(t, srv.mux, stdhttp.MethodDelete, path, "", `"3"`, stdhttp.StatusNoContent)
+}
@@ -0,0 +1,406 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"cloudpam/internal/audit"
+	"cloudpam/internal/auth"
+	"cloudpam/internal/domain"
+	"cloudpam/internal/planning"
+	"cloudpam/internal/storage"
+)
+
+// IPAddressServer handles the host address records kept inside subnet pools.
+type IPAddressServer struct {
+	srv   *Server
+	store storage.IPAddressStore
+}
+
+// NewIPAddressServer creates a new IPAddressServer.
+func NewIPAddressServer(srv *Server, store storage.IPAddressStore) *IPAddressServer {
+	return &IPAddressServer{srv: srv, store: store}
+}
+
+// RegisterProtectedIPAddressRoutes registers IP address routes with RBAC.
+// Address records are part of their pool, so they use pool permissions.
+func (is *IPAddressServer) RegisterProtectedIPAddressRoutes(dualMW Middleware, logger *slog.Logger) {
+	listMW := RequirePermissionMiddleware(auth.ResourcePools, auth.ActionList, logger)
+	readMW := RequirePermissionMiddleware(auth.ResourcePools, auth.ActionRead, logger)
+	createMW := RequirePermissionMiddleware(auth.ResourcePools, auth.ActionCreate, logger)
+	updateMW := RequirePermissionMiddleware(auth.ResourcePools, auth.ActionUpdate, logger)
+	deleteMW := RequirePermissionMiddleware(auth.ResourcePools, auth.ActionDelete, logger)
+
+	is.srv.handleOpenAPIRoute("GET /api/v1/pools/{id}/addresses", dualMW(listMW(http.HandlerFunc(is.handleListPoolAddresses))))
+	is.srv.handleOpenAPIRoute("POST /api/v1/pools/{id}/addresses", dualMW(createMW(http.HandlerFunc(is.handleCreate))))
+	is.srv.handleOpenAPIRoute("POST /api/v1/pools/{id}/addresses/allocate", dualMW(createMW(http.HandlerFunc(is.handleAllocate))))
+	is.srv.handleOpenAPIRoute("GET /api/v1/ip-addresses", dualMW(listMW(http.HandlerFunc(is.handleList))))
+	is.srv.handleOpenAPIRoute("GET /api/v1/ip-addresses/{id}", dualMW(readMW(http.HandlerFunc(is.handleGet))))
+	is.srv.handleOpenAPIRoute("PATCH /api/v1/ip-addresses/{id}", dualMW(updateMW(http.HandlerFunc(is.handleUpdate))))
+	is.srv.handleOpenAPIRoute("DELETE /api/v1/ip-addresses/{id}", dualMW(deleteMW(http.HandlerFunc(is.handleDelete))))
+}
+
+// RegisterIPAddressRoutesNoAuth registers IP address routes without auth middleware (for tests).
+func (is *IPAddressServer) RegisterIPAddressRoutesNoAuth() {
+	is.srv.handleOpenAPIRouteFunc("GET /api/v1/pools/{id}/addresses", is.handleListPoolAddresses)
+	is.srv.handleOpenAPIRouteFunc("POST /api/v1/pools/{id}/addresses", is.handleCreate)
+	is.srv.handleOpenAPIRouteFunc("POST /api/v1/pools/{id}/addresses/allocate", is.handleAllocate)
+	is.srv.handleOpenAPIRouteFunc("GET /api/v1/ip-addresses", is.handleList)
+	is.srv.handleOpenAPIRouteFunc("GET /api/v1/ip-addresses/{id}", is.handleGet)
+	is.srv.handleOpenAPIRouteFunc("PATCH /api/v1/ip-addresses/{id}", is.handleUpdate)
+	is.srv.handleOpenAPIRouteFunc("DELETE /api/v1/ip-addresses/{id}", is.handleDelete)
+}
+
+// handleListPoolAddresses returns the address records of a pool.
+// GET /api/v1/pools/{id}/addresses
+func (is *IPAddressServer) handleListPoolAddresses(w http.ResponseWriter, r *http.Request) {
+	pool, ok := is.loadPool(w, r)
+	if !ok {
+		return
+	}
+	is.list(w, r, pool.ID)
+}
+
+// handleList returns address records across pools, optionally filtered by
+// pool_id.
+// GET /api/v1/ip-addresses
+func (is *IPAddressServer) handleList(w http.ResponseWriter, r *http.Request) {
+	poolID, err := parseIDParam(r.URL.Query(), "pool_id")
+	if err != nil {
+		is.srv.writeErr(r.Context(), w, http.StatusBadRequest, "invalid pool_id", err.Error())
+		return
+	}
+	var id int64
+	if poolID != nil {
+		id = *poolID
+	}
+	is.list(w, r, id)
+}
+
+func (is *IPAddressServer) list(w http.ResponseWriter, r *http.Request, poolID int64) {
+	ctx := r.Context()
+	q := r.URL.Query()
+	filters := domain.IPAddressFilters{
+		PoolID: poolID,
+		Status: q.Get("status"),
+		Source: q.Get("source"),
+		Query:  strings.TrimSpace(q.Get("q")),
+	}
+	if filters.Status != "" && !domain.IsValidIPAddressStatus(domain.IPAddressStatus(filters.Status)) {
+		is.srv.writeErr(ctx, w, http.StatusBadRequest, "invalid status", fmt.Sprintf("valid statuses: %v", domain.ValidIPAddressStatuses))
+		return
+	}
+	if pageStr := q.Get("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil {
+			filters.Page = p
+		}
+	}
+	if psStr := q.Get("page_size"); psStr != "" {
+		if ps, err := strconv.Atoi(psStr); err == nil {
+			filters.PageSize = ps
+		}
+	}
+
+	items, total, err := is.store.ListIPAddresses(ctx, filters)
+	if err != nil {
+		is.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+
+	page := filters.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := filters.PageSize
+	if pageSize < 1 {
+		pageSize = 50
+	}
+
+	writeJSON(w, http.StatusOK, domain.IPAddressListResponse{
+		Items:    items,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	})
+}
+
+// handleCreate records a specific address in a subnet pool.
+// POST /api/v1/pools/{id}/addresses
+func (is *IPAddressServer) handleCreate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	pool, ok := is.loadPool(w, r)
+	if !ok {
+		return
+	}
+
+	var in domain.CreateIPAddress
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		is.srv.writeErr(ctx, w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+	prefix, err := subnetPrefix(pool)
+	if err != nil {
+		is.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+	addr, err := netip.ParseAddr(strings.TrimSpace(in.Address))
+	if err != nil {
+		is.srv.writeErr(ctx, w, http.StatusBadRequest, "invalid address", err.Error())
+		return
+	}
+	addr = addr.Unmap()
+	if !prefix.Contains(addr) {
+		is.srv.writeErr(ctx, w, http.StatusBadRequest, "address outside pool", fmt.Sprintf("%s is not in %s", addr, prefix))
+		return
+	}
+	a, err := newIPAddressRecord(addr.String(), in.Hostname, in.MACAddress, in.Owner, in.Status, in.Description)
+	if err != nil {
+		is.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+	a.PoolID = pool.ID
+
+	if err := is.store.CreateIPAddress(ctx, a); err != nil {
+		is.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+
+	is.srv.logAuditWithChanges(ctx, audit.ActionCreate, audit.ResourceIPAddress, a.ID, a.Address,
+		&audit.Changes{After: map[string]any{"pool_id": a.PoolID, "address": a.Address, "status": string(a.Status)}}, http.StatusCreated)
+	writeJSON(w, http.StatusCreated, a)
+}
+
+// handleAllocate records the lowest free address of a subnet pool. Addresses
+// inside child pools or already recorded are skipped, as are the IPv4
+// network and broadcast addresses.
+// POST /api/v1/pools/{id}/addresses/allocate
+func (is *IPAddressServer) handleAllocate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	poolID, ok := is.poolIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	var in domain.AllocateIPAddress
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		is.srv.writeErr(ctx, w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+	template, err := newIPAddressRecord("", in.Hostname, in.MACAddress, in.Owner, in.Status, in.Description)
+	if err != nil {
+		is.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+
+	a, err := is.store.AllocateIPAddress(ctx, poolID, func(pool domain.Pool, children []domain.Pool, existing []domain.IPAddress) (domain.IPAddress, error) {
+		prefix, err := subnetPrefix(pool)
+		if err != nil {
+			return domain.IPAddress{}, err
+		}
+		occupied := make([]netip.Prefix, 0, len(children))
+		for _, c := range children {
+			if cp, err := netip.ParsePrefix(c.CIDR); err == nil {
+				occupied = append(occupied, cp)
+			}
+		}
+		used := make([]netip.Addr, 0, len(existing))
+		for _, e := range existing {
+			if ea, err := netip.ParseAddr(e.Address); err == nil {
+				used = append(used, ea)
+			}
+		}
+		addr, err := planning.NextAvailableAddress(prefix, occupied, used)
+		if err != nil {
+			return domain.IPAddress{}, err
+		}
+		out := template
+		out.Address = addr.String()
+		return out, nil
+	})
+	if err != nil {
+		if errors.Is(err, planning.ErrPoolExhausted) {
+			is.srv.writeErr(ctx, w, http.StatusConflict, "pool exhausted", err.Error())
+			return
+		}
+		is.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+
+	is.srv.logAuditWithChanges(ctx, audit.ActionAllocate, audit.ResourceIPAddress, a.ID, a.Address,
+		&audit.Changes{After: map[string]any{"pool_id": a.PoolID, "address": a.Address, "status": string(a.Status)}}, http.StatusCreated)
+	writeJSON(w, http.StatusCreated, a)
+}
+
+// handleGet returns a single address record.
+// GET /api/v1/ip-addresses/{id}
+func (is *IPAddressServer) handleGet(w http.ResponseWriter, r *http.Request) {
+	a, err := is.store.GetIPAddress(r.Context(), r.PathValue("id"))
+	if err != nil {
+		is.srv.writeStoreErr(r.Context(), w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, a)
+}
+
+// handleUpdate partially updates an address record. The address and pool
+// cannot be changed; delete and re-create the record instead.
+// PATCH /api/v1/ip-addresses/{id}
+func (is *IPAddressServer) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	a, err := is.store.GetIPAddress(ctx, r.PathValue("id"))
+	if err != nil {
+		is.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+
+	var in domain.UpdateIPAddress
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		is.srv.writeErr(ctx, w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+	before := map[string]any{"status": string(a.Status), "owner": a.Owner, "hostname": a.Hostname}
+	if in.Hostname != nil {
+		a.Hostname = strings.TrimSpace(*in.Hostname)
+	}
+	if in.MACAddress != nil {
+		mac, err := normalizeMAC(*in.MACAddress)
+		if err != nil {
+			is.srv.writeStoreErr(ctx, w, err)
+			return
+		}
+		a.MACAddress = mac
+	}
+	if in.Owner != nil {
+		a.Owner = strings.TrimSpace(*in.Owner)
+	}
+	if in.Status != nil {
+		if !domain.IsValidIPAddressStatus(*in.Status) {
+			is.srv.writeErr(ctx, w, http.StatusBadRequest, "invalid status", fmt.Sprintf("valid statuses: %v", domain.ValidIPAddressStatuses))
+			return
+		}
+		a.Status = *in.Status
+	}
+	if in.Description != nil {
+		a.Description = *in.Description
+	}
+
+	a.UpdatedAt = time.Now().UTC()
+	if err := is.store.UpdateIPAddress(ctx, *a); err != nil {
+		is.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+
+	is.srv.logAuditWithChanges(ctx, audit.ActionUpdate, audit.ResourceIPAddress, a.ID, a.Address,
+		&audit.Changes{Before: before, After: map[string]any{"status": string(a.Status), "owner": a.Owner, "hostname": a.Hostname}}, http.StatusOK)
+	writeJSON(w, http.StatusOK, a)
+}
+
+// handleDelete releases an address record.
+// DELETE /api/v1/ip-addresses/{id}
+func (is *IPAddressServer) handleDelete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := r.PathValue("id")
+
+	a, err := is.store.GetIPAddress(ctx, id)
+	if err != nil {
+		is.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+	if err := is.store.DeleteIPAddress(ctx, id); err != nil {
+		is.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+
+	is.srv.logAudit(ctx, audit.ActionDelete, audit.ResourceIPAddress, id, a.Address, http.StatusNoContent)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (is *IPAddressServer) poolIDParam(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		is.srv.writeErr(r.Context(), w, http.StatusBadRequest, "invalid id", "")
+		return 0, false
+	}
+	return id, true
+}
+
+func (is *IPAddressServer) loadPool(w http.ResponseWriter, r *http.Request) (domain.Pool, bool) {
+	id, ok := is.poolIDParam(w, r)
+	if !ok {
+		return domain.Pool{}, false
+	}
+	pool, found, err := is.srv.store.GetPool(r.Context(), id)
+	if err != nil {
+		is.srv.writeStoreErr(r.Context(), w, err)
+		return domain.Pool{}, false
+	}
+	if !found {
+		is.srv.writeErr(r.Context(), w, http.StatusNotFound, "pool not found", "")
+		return domain.Pool{}, false
+	}
+	return pool, true
+}
+
+// subnetPrefix returns the CIDR of a subnet pool. Address records are only
+// kept in subnet pools.
+func subnetPrefix(pool domain.Pool) (netip.Prefix, error) {
+	if pool.Type != domain.PoolTypeSubnet {
+		return netip.Prefix{}, fmt.Errorf("pool %d is a %s pool; addresses can only be recorded in subnet pools: %w", pool.ID, pool.Type, storage.ErrValidation)
+	}
+	prefix, err := netip.ParsePrefix(pool.CIDR)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid pool cidr %q: %w", pool.CIDR, err)
+	}
+	return prefix.Masked(), nil
+}
+
+// newIPAddressRecord validates the caller-supplied fields of a manual record.
+func newIPAddressRecord(address, hostname, mac, owner string, status domain.IPAddressStatus, description string) (domain.IPAddress, error) {
+	if status == "" {
+		status = domain.IPAddressStatusAssigned
+	}
+	if !domain.IsValidIPAddressStatus(status) {
+		return domain.IPAddress{}, fmt.Errorf("invalid status %q, valid statuses: %v: %w", status, domain.ValidIPAddressStatuses, storage.ErrValidation)
+	}
+	mac, err := normalizeMAC(mac)
+	if err != nil {
+		return domain.IPAddress{}, err
+	}
+	now := time.Now().UTC()
+	return domain.IPAddress{
+		ID:          uuid.NewString(),
+		Address:     address,
+		Hostname:    strings.TrimSpace(hostname),
+		MACAddress:  mac,
+		Owner:       strings.TrimSpace(owner),
+		Status:      status,
+		Source:      domain.PoolSourceManual,
+		Description: description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
+
+// normalizeMAC returns mac in lower-case colon form, or "" for an empty value.
+func normalizeMAC(mac string) (string, error) {
+	mac = strings.TrimSpace(mac)
+	if mac == "" {
+		return "", nil
+	}
+	hw, err := net.ParseMAC(mac)
+	if err != nil {
+		return "", fmt.Errorf("invalid mac_address %q: %w", mac, storage.ErrValidation)
+	}
+	return hw.String(), nil
+}
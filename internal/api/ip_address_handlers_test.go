@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"cloudpam/internal/audit"
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+func setupIPAddressTestEnv(t *testing.T) (*http.ServeMux, *storage.MemoryStore, *audit.MemoryAuditLogger) {
+	t.Helper()
+	st := storage.NewMemoryStore()
+	auditLogger := audit.NewMemoryAuditLogger()
+	mux := http.NewServeMux()
+	srv := NewServer(mux, st, nil, nil, auditLogger)
+	srv.registerUnprotectedTestRoutes()
+	NewIPAddressServer(srv, storage.NewMemoryIPAddressStore(st)).RegisterIPAddressRoutesNoAuth()
+	return mux, st, auditLogger
+}
+
+func TestIPAddressHandlers_CRUD(t *testing.T) {
+	mux, st, auditLogger := setupIPAddressTestEnv(t)
+	pool, err := st.CreatePool(context.Background(), domain.CreatePool{Name: "apps", CIDR: "10.0.0.0/24", Type: domain.PoolTypeSubnet})
+	if err != nil {
+		t.Fatalf("create pool: %v", err)
+	}
+	poolPath := fmt.Sprintf("/api/v1/pools/%d/addresses", pool.ID)
+
+	rr := doJSON(t, mux, http.MethodPost, poolPath,
+		`{"address":"10.0.0.20","hostname":"db-1","mac_address":"0E-00-00-00-00-01","owner":"data"}`, http.StatusCreated)
+	var created domain.IPAddress
+	if err := json.Unmarshal(rr.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode create: %v", err)
+	}
+	if created.PoolID != pool.ID || created.Status != domain.IPAddressStatusAssigned ||
+		created.Source != domain.PoolSourceManual || created.MACAddress != "0e:00:00:00:00:01" {
+		t.Fatalf("create response = %s", rr.Body.String())
+	}
+
+	doJSON(t, mux, http.MethodPost, poolPath, `{"address":"10.0.0.20"}`, http.StatusConflict)
+	doJSON(t, mux, http.MethodPost, poolPath, `{"address":"10.1.0.20"}`, http.StatusBadRequest)
+	doJSON(t, mux, http.MethodPost, poolPath, `{"address":"10.0.0.21","status":"leased"}`, http.StatusBadRequest)
+	doJSON(t, mux, http.MethodPost, poolPath, `{"address":"10.0.0.21","mac_address":"nope"}`, http.StatusBadRequest)
+	doJSON(t, mux, http.MethodPost, "/api/v1/pools/999/addresses", `{"address":"10.0.0.21"}`, http.StatusNotFound)
+
+	// The pool's own routes still answer next to the address routes.
+	rr = doJSON(t, mux, http.MethodGet, fmt.Sprintf("/api/v1/pools/%d/stats", pool.ID), "", http.StatusOK)
+	var stats domain.PoolStats
+	if err := json.Unmarshal(rr.Body.Bytes(), &stats); err != nil || stats.UsedIPs != 1 {
+		t.Fatalf("stats = %s", rr.Body.String())
+	}
+
+	path := "/api/v1/ip-addresses/" + created.ID
+	rr = doJSON(t, mux, http.MethodPatch, path, `{"status":"reserved","owner":"platform"}`, http.StatusOK)
+	var updated domain.IPAddress
+	_ = json.Unmarshal(rr.Body.Bytes(), &updated)
+	if updated.Status != domain.IPAddressStatusReserved || updated.Owner != "platform" || updated.Hostname != "db-1" {
+		t.Fatalf("update response = %s", rr.Body.String())
+	}
+	doJSON(t, mux, http.MethodPatch, path, `{"status":"leased"}`, http.StatusBadRequest)
+
+	rr = doJSON(t, mux, http.MethodGet, fmt.Sprintf("/api/v1/ip-addresses?pool_id=%d&status=reserved&q=plat", pool.ID), "", http.StatusOK)
+	var list domain.IPAddressListResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &list); err != nil || list.Total != 1 || list.Items[0].ID != created.ID {
+		t.Fatalf("list response = %s", rr.Body.String())
+	}
+	doJSON(t, mux, http.MethodGet, "/api/v1/ip-addresses?pool_id=abc", "", http.StatusBadRequest)
+
+	doJSON(t, mux, http.MethodDelete, path, "", http.StatusNoContent)
+	doJSON(t, mux, http.MethodGet, path, "", http.StatusNotFound)
+
+	events, _, _ := auditLogger.List(context.Background(), audit.ListOptions{ResourceType: audit.ResourceIPAddress})
+	if len(events) != 3 {
+		t.Fatalf("expected 3 ip address audit events (create, update, delete), got %d", len(events))
+	}
+}
+
+func TestIPAddressHandlers_Allocate(t *testing.T) {
+	mux, st, _ := setupIPAddressTestEnv(t)
+	ctx := context.Background()
+	pool, err := st.CreatePool(ctx, domain.CreatePool{Name: "tiny", CIDR: "10.0.0.0/29", Type: domain.PoolTypeSubnet})
+	if err != nil {
+		t.Fatalf("create pool: %v", err)
+	}
+	if _, err := st.CreatePool(ctx, domain.CreatePool{Name: "vip", CIDR: "10.0.0.4/30", ParentID: &pool.ID}); err != nil {
+		t.Fatalf("create child: %v", err)
+	}
+	allocPath := fmt.Sprintf("/api/v1/pools/%d/addresses/allocate", pool.ID)
+
+	// .0 is the network address and .4-.7 belong to the child, so only
+	// .1-.3 are free.
+	var got []string
+	for i := 0; i < 3; i++ {
+		rr := doJSON(t, mux, http.MethodPost, allocPath, `{"hostname":"web","status":"dhcp"}`, http.StatusCreated)
+		var a domain.IPAddress
+		if err := json.Unmarshal(rr.Body.Bytes(), &a); err != nil {
+			t.Fatalf("decode allocate: %v", err)
+		}
+		if a.Status != domain.IPAddressStatusDHCP {
+			t.Fatalf("allocate response = %s", rr.Body.String())
+		}
+		got = append(got, a.Address)
+	}
+	if fmt.Sprint(got) != "[10.0.0.1 10.0.0.2 10.0.0.3]" {
+		t.Fatalf("allocated %v", got)
+	}
+	doJSON(t, mux, http.MethodPost, allocPath, `{}`, http.StatusConflict)
+
+	block, err := st.CreatePool(ctx, domain.CreatePool{Name: "region", CIDR: "10.8.0.0/16", Type: domain.PoolTypeRegion})
+	if err != nil {
+		t.Fatalf("create region pool: %v", err)
+	}
+	doJSON(t, mux, http.MethodPost, fmt.Sprintf("/api/v1/pools/%d/addresses/allocate", block.ID), `{}`, http.StatusBadRequest)
+	doJSON(t, mux, http.MethodPost, "/api/v1/pools/999/addresses/allocate", `{}`, http.StatusNotFound)
+}
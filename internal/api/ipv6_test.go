@@ -0,0 +1,79 @@
+package api
+
+import (
+	"encoding/json"
+	stdhttp "net/http"
+	"testing"
+
+	"cloudpam/internal/domain"
+)
+
+func TestIPv6PoolLifecycle(t *testing.T) {
+	srv, _ := setupTestServer()
+
+	rr := doJSON(t, srv.mux, stdhttp.MethodPost, "/api/v1/pools", `{"name":"v6-root","cidr":"2001:db8::/48"}`, stdhttp.StatusCreated)
+	var parent domain.Pool
+	if err := json.Unmarshal(rr.Body.Bytes(), &parent); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	children := "/api/v1/pools"
+	doJSON(t, srv.mux, stdhttp.MethodPost, children,
+		`{"name":"v6-a","cidr":"2001:db8::/64","parent_id":`+itoa(parent.ID)+`}`, stdhttp.StatusCreated)
+
+	// An IPv4 child under an IPv6 parent is rejected.
+	doJSON(t, srv.mux, stdhttp.MethodPost, children,
+		`{"name":"v4","cidr":"10.0.0.0/24","parent_id":`+itoa(parent.ID)+`}`, stdhttp.StatusBadRequest)
+	// Overlap detection works on IPv6 siblings.
+	doJSON(t, srv.mux, stdhttp.MethodPost, children,
+		`{"name":"v6-dup","cidr":"2001:db8::/63","parent_id":`+itoa(parent.ID)+`}`, stdhttp.StatusBadRequest)
+	// IPv4 and IPv6 top-level pools never overlap each other.
+	doJSON(t, srv.mux, stdhttp.MethodPost, children, `{"name":"v4-root","cidr":"10.0.0.0/8"}`, stdhttp.StatusCreated)
+
+	rr = doJSON(t, srv.mux, stdhttp.MethodPost, "/api/v1/pools/"+itoa(parent.ID)+"/allocate", `{"name":"v6-b","prefix_length":64}`, stdhttp.StatusCreated)
+	var alloc domain.Pool
+	if err := json.Unmarshal(rr.Body.Bytes(), &alloc); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if alloc.CIDR != "2001:db8:0:1::/64" {
+		t.Errorf("allocated %s, want 2001:db8:0:1::/64", alloc.CIDR)
+	}
+	// /80 is past the default IPv6 maximum.
+	doJSON(t, srv.mux, stdhttp.MethodPost, "/api/v1/pools/"+itoa(parent.ID)+"/allocate", `{"name":"v6-c","prefix_length":80}`, stdhttp.StatusBadRequest)
+
+	rr = doJSON(t, srv.mux, stdhttp.MethodGet, "/api/v1/pools/"+itoa(parent.ID)+"/blocks?new_prefix_len=64&page_size=4", "", stdhttp.StatusOK)
+	var blocks struct {
+		Items []blockInfo `json:"items"`
+		Total int         `json:"total"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &blocks); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if blocks.Total != 65536 || len(blocks.Items) != 4 {
+		t.Fatalf("total = %d, items = %d, want 65536 and 4", blocks.Total, len(blocks.Items))
+	}
+	if !blocks.Items[0].Used || !blocks.Items[1].Used || blocks.Items[2].Used {
+		t.Errorf("used flags = %v %v %v, want the first two /64s used", blocks.Items[0].Used, blocks.Items[1].Used, blocks.Items[2].Used)
+	}
+	if blocks.Items[2].CIDR != "2001:db8:0:2::/64" {
+		t.Errorf("third block = %s", blocks.Items[2].CIDR)
+	}
+}
+
+func TestComputeSubnetsWindow_IPv6(t *testing.T) {
+	blocks, hosts, total, err := computeSubnetsWindow("2001:db8::/32", 64, 1, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if total != 1<<32 {
+		t.Errorf("total = %d, want %d", total, 1<<32)
+	}
+	if hosts != ^uint64(0) {
+		t.Errorf("hosts = %d, want saturated uint64 for a /64", hosts)
+	}
+	if len(blocks) != 2 || blocks[0] != "2001:db8:0:1::/64" || blocks[1] != "2001:db8:0:2::/64" {
+		t.Errorf("blocks = %v", blocks)
+	}
+	if _, _, _, err := computeSubnetsWindow("2001:db8::/32", 129, 0, 1); err == nil {
+		t.Error("expected an error for /129")
+	}
+}
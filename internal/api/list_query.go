@@ -0,0 +1,249 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+const (
+	defaultListPageSize = 50
+	maxListPageSize     = 500
+)
+
+// listPage is the page window requested on a list endpoint. When neither page
+// nor page_size is given the endpoint returns every match as a bare array, as
+// it did before filtering was added.
+type listPage struct {
+	Paged    bool
+	Page     int
+	PageSize int
+}
+
+func (p listPage) limitOffset() (int, int) {
+	if !p.Paged {
+		return 0, 0
+	}
+	return p.PageSize, (p.Page - 1) * p.PageSize
+}
+
+// listEnvelope is the paged response body of the pool and account lists.
+type listEnvelope[T any] struct {
+	Items    []T   `json:"items"`
+	Total    int64 `json:"total"`
+	Page     int   `json:"page"`
+	PageSize int   `json:"page_size"`
+}
+
+// writeList writes items as a bare array or, when a page was requested, as a
+// listEnvelope. X-Total-Count always carries the number of matches.
+func writeList[T any](w http.ResponseWriter, items []T, total int64, pg listPage) {
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+	if !pg.Paged {
+		writeJSON(w, http.StatusOK, items)
+		return
+	}
+	writeJSON(w, http.StatusOK, listEnvelope[T]{Items: items, Total: total, Page: pg.Page, PageSize: pg.PageSize})
+}
+
+// parseListPage reads page and page_size. page_size defaults to 50 and is
+// capped at 500.
+func parseListPage(q url.Values) (listPage, error) {
+	pg := listPage{Page: 1, PageSize: defaultListPageSize}
+	if v := q.Get("page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return pg, fmt.Errorf("invalid page")
+		}
+		pg.Page, pg.Paged = n, true
+	}
+	if v := q.Get("page_size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxListPageSize {
+			return pg, fmt.Errorf("invalid page_size: must be between 1 and %d", maxListPageSize)
+		}
+		pg.PageSize, pg.Paged = n, true
+	}
+	return pg, nil
+}
+
+// parseIDParam reads a non-negative id filter, where 0 selects rows with no
+// reference (root pools, unassigned pools).
+func parseIDParam(q url.Values, name string) (*int64, error) {
+	v := q.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid %s", name)
+	}
+	return &n, nil
+}
+
+// parseTimeParam reads an RFC 3339 timestamp or Unix seconds.
+func parseTimeParam(q url.Values, name string) (*int64, error) {
+	v := q.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+	if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return &n, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: want RFC 3339 or Unix seconds", name)
+	}
+	n := t.Unix()
+	return &n, nil
+}
+
+// parseOrder reads order (asc or desc).
+func parseOrder(q url.Values) (bool, error) {
+	switch strings.ToLower(q.Get("order")) {
+	case "", "asc":
+		return false, nil
+	case "desc":
+		return true, nil
+	}
+	return false, fmt.Errorf("invalid order: must be asc or desc")
+}
+
+func isTruthy(v string) bool {
+	switch strings.ToLower(v) {
+	case "true", "1", "yes":
+		return true
+	}
+	return false
+}
+
+// parsePoolQuery maps the /api/v1/pools query string onto PoolQueryOptions.
+func parsePoolQuery(q url.Values) (storage.PoolQueryOptions, listPage, error) {
+	var opts storage.PoolQueryOptions
+	pg, err := parseListPage(q)
+	if err != nil {
+		return opts, pg, err
+	}
+	if opts.ParentID, err = parseIDParam(q, "parent_id"); err != nil {
+		return opts, pg, err
+	}
+	if opts.AccountID, err = parseIDParam(q, "account_id"); err != nil {
+		return opts, pg, err
+	}
+	if opts.CreatedAfter, err = parseTimeParam(q, "created_after"); err != nil {
+		return opts, pg, err
+	}
+	if opts.CreatedBefore, err = parseTimeParam(q, "created_before"); err != nil {
+		return opts, pg, err
+	}
+	if opts.OrderDesc, err = parseOrder(q); err != nil {
+		return opts, pg, err
+	}
+	opts.CIDRPrefix = strings.TrimSpace(q.Get("cidr_prefix"))
+	opts.CIDRContains = strings.TrimSpace(q.Get("cidr_contains"))
+	opts.CIDRWithin = strings.TrimSpace(q.Get("cidr_within"))
+	opts.NameContains = strings.TrimSpace(q.Get("name"))
+	opts.IncludeChildren = isTruthy(q.Get("include_children"))
+	opts.OrderBy = q.Get("order_by")
+	opts.Limit, opts.Offset = pg.limitOffset()
+	return opts, pg, nil
+}
+
+// parseAccountQuery maps the /api/v1/accounts query string onto
+// AccountQueryOptions.
+func parseAccountQuery(q url.Values) (storage.AccountQueryOptions, listPage, error) {
+	var opts storage.AccountQueryOptions
+	pg, err := parseListPage(q)
+	if err != nil {
+		return opts, pg, err
+	}
+	if opts.CreatedAfter, err = parseTimeParam(q, "created_after"); err != nil {
+		return opts, pg, err
+	}
+	if opts.CreatedBefore, err = parseTimeParam(q, "created_before"); err != nil {
+		return opts, pg, err
+	}
+	if opts.OrderDesc, err = parseOrder(q); err != nil {
+		return opts, pg, err
+	}
+	opts.Key = strings.TrimSpace(q.Get("key"))
+	opts.KeyPrefix = strings.TrimSpace(q.Get("key_prefix"))
+	opts.Provider = q.Get("provider")
+	opts.Platform = q.Get("platform")
+	opts.Tier = q.Get("tier")
+	opts.Environment = q.Get("environment")
+	opts.Region = q.Get("region")
+	opts.NameContains = strings.TrimSpace(q.Get("name"))
+	opts.OrderBy = q.Get("order_by")
+	opts.Limit, opts.Offset = pg.limitOffset()
+	return opts, pg, nil
+}
+
+// queryPools runs opts through the store's Queryable implementation, or
+// filters ListPools in memory for stores without one. It returns the page and
+// the total number of matches.
+func (s *Server) queryPools(ctx context.Context, opts storage.PoolQueryOptions) ([]domain.Pool, int64, error) {
+	var (
+		page  []*domain.Pool
+		total int64
+		err   error
+	)
+	if qs, ok := s.store.(storage.Queryable); ok {
+		if total, err = qs.CountPools(ctx, opts); err != nil {
+			return nil, 0, err
+		}
+		if page, err = qs.QueryPools(ctx, opts); err != nil {
+			return nil, 0, err
+		}
+	} else {
+		all, err := s.store.ListPools(ctx)
+		if err != nil {
+			return nil, 0, err
+		}
+		if page, total, err = storage.FilterPools(all, opts); err != nil {
+			return nil, 0, err
+		}
+	}
+	out := make([]domain.Pool, len(page))
+	for i, p := range page {
+		out[i] = *p
+	}
+	return out, total, nil
+}
+
+// queryAccounts is queryPools for accounts.
+func (s *Server) queryAccounts(ctx context.Context, opts storage.AccountQueryOptions) ([]domain.Account, int64, error) {
+	var (
+		page  []*domain.Account
+		total int64
+		err   error
+	)
+	if qs, ok := s.store.(storage.Queryable); ok {
+		if total, err = qs.CountAccounts(ctx, opts); err != nil {
+			return nil, 0, err
+		}
+		if page, err = qs.QueryAccounts(ctx, opts); err != nil {
+			return nil, 0, err
+		}
+	} else {
+		all, err := s.store.ListAccounts(ctx)
+		if err != nil {
+			return nil, 0, err
+		}
+		if page, total, err = storage.FilterAccounts(all, opts); err != nil {
+			return nil, 0, err
+		}
+	}
+	out := make([]domain.Account, len(page))
+	for i, a := range page {
+		out[i] = *a
+	}
+	return out, total, nil
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	stdhttp "net/http"
+	"testing"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+func seedListQuery(t *testing.T, st *storage.MemoryStore) (domain.Account, domain.Pool) {
+	t.Helper()
+	ctx := context.Background()
+	prod, _ := st.CreateAccount(ctx, domain.CreateAccount{Key: "aws:111", Name: "Prod", Provider: "aws", Environment: "prod"})
+	_, _ = st.CreateAccount(ctx, domain.CreateAccount{Key: "aws:222", Name: "Dev", Provider: "aws", Environment: "dev"})
+	_, _ = st.CreateAccount(ctx, domain.CreateAccount{Key: "gcp:333", Name: "GCP", Provider: "gcp", Environment: "prod"})
+	root, _ := st.CreatePool(ctx, domain.CreatePool{Name: "root", CIDR: "10.0.0.0/8"})
+	mid, _ := st.CreatePool(ctx, domain.CreatePool{Name: "mid", CIDR: "10.1.0.0/16", ParentID: &root.ID, AccountID: &prod.ID})
+	_, _ = st.CreatePool(ctx, domain.CreatePool{Name: "leaf", CIDR: "10.1.2.0/24", ParentID: &mid.ID})
+	_, _ = st.CreatePool(ctx, domain.CreatePool{Name: "other", CIDR: "172.16.0.0/12"})
+	return prod, root
+}
+
+func TestListPools_Filters(t *testing.T) {
+	srv, st := setupTestServer()
+	_, root := seedListQuery(t, st)
+
+	names := func(path string) []string {
+		rr := doJSON(t, srv.mux, stdhttp.MethodGet, path, "", stdhttp.StatusOK)
+		var pools []domain.Pool
+		if err := json.Unmarshal(rr.Body.Bytes(), &pools); err != nil {
+			t.Fatalf("%s: decode: %v", path, err)
+		}
+		out := make([]string, len(pools))
+		for i, p := range pools {
+			out[i] = p.Name
+		}
+		return out
+	}
+	equal := func(got []string, want ...string) bool {
+		if len(got) != len(want) {
+			return false
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	if got := names("/api/v1/pools"); !equal(got, "root", "mid", "leaf", "other") {
+		t.Errorf("unfiltered = %v", got)
+	}
+	if got := names("/api/v1/pools?parent_id=0"); !equal(got, "root", "other") {
+		t.Errorf("parent_id=0 = %v", got)
+	}
+	if got := names("/api/v1/pools?include_children=true&parent_id=" + itoa(root.ID)); !equal(got, "mid", "leaf") {
+		t.Errorf("descendants = %v", got)
+	}
+	if got := names("/api/v1/pools?cidr_contains=10.1.2.9&order=desc"); !equal(got, "leaf", "mid", "root") {
+		t.Errorf("cidr_contains = %v", got)
+	}
+	if got := names("/api/v1/pools?name=ROO&created_after=2000-01-01T00:00:00Z"); !equal(got, "root") {
+		t.Errorf("name = %v", got)
+	}
+
+	rr := doJSON(t, srv.mux, stdhttp.MethodGet, "/api/v1/pools?cidr_within=10.0.0.0/8", "", stdhttp.StatusOK)
+	if got := rr.Header().Get("X-Total-Count"); got != "3" {
+		t.Errorf("X-Total-Count = %q, want 3", got)
+	}
+
+	for _, bad := range []string{
+		"/api/v1/pools?parent_id=x",
+		"/api/v1/pools?cidr_within=nope",
+		"/api/v1/pools?order_by=size",
+		"/api/v1/pools?order=sideways",
+		"/api/v1/pools?page_size=501",
+		"/api/v1/pools?created_before=yesterday",
+	} {
+		doJSON(t, srv.mux, stdhttp.MethodGet, bad, "", stdhttp.StatusBadRequest)
+	}
+}
+
+func TestListPools_Paged(t *testing.T) {
+	srv, st := setupTestServer()
+	seedListQuery(t, st)
+
+	rr := doJSON(t, srv.mux, stdhttp.MethodGet, "/api/v1/pools?order_by=name&page=2&page_size=3&include_stats=true", "", stdhttp.StatusOK)
+	var env struct {
+		Items []struct {
+			domain.Pool
+			Stats domain.PoolStats `json:"stats"`
+		} `json:"items"`
+		Total    int64 `json:"total"`
+		Page     int   `json:"page"`
+		PageSize int   `json:"page_size"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &env); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if env.Total != 4 || env.Page != 2 || env.PageSize != 3 {
+		t.Fatalf("envelope = total %d page %d size %d", env.Total, env.Page, env.PageSize)
+	}
+	if len(env.Items) != 1 || env.Items[0].Name != "root" || env.Items[0].Stats.TotalIPs == 0 {
+		t.Errorf("items = %+v", env.Items)
+	}
+}
+
+func TestListAccounts_Filters(t *testing.T) {
+	srv, st := setupTestServer()
+	prod, _ := seedListQuery(t, st)
+
+	rr := doJSON(t, srv.mux, stdhttp.MethodGet, "/api/v1/accounts?environment=prod&key_prefix=aws:", "", stdhttp.StatusOK)
+	var accs []domain.Account
+	if err := json.Unmarshal(rr.Body.Bytes(), &accs); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(accs) != 1 || accs[0].ID != prod.ID {
+		t.Errorf("accounts = %+v", accs)
+	}
+
+	rr = doJSON(t, srv.mux, stdhttp.MethodGet, "/api/v1/accounts?order_by=key&order=desc&page_size=2", "", stdhttp.StatusOK)
+	var env struct {
+		Items []domain.Account `json:"items"`
+		Total int64            `json:"total"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &env); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if env.Total != 3 || len(env.Items) != 2 || env.Items[0].Key != "gcp:333" {
+		t.Errorf("paged accounts = %+v", env)
+	}
+	if rr.Header().Get("X-Total-Count") != "3" {
+		t.Errorf("X-Total-Count = %q", rr.Header().Get("X-Total-Count"))
+	}
+	doJSON(t, srv.mux, stdhttp.MethodGet, "/api/v1/accounts?order_by=cidr", "", stdhttp.StatusBadRequest)
+}
@@ -0,0 +1,432 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"cloudpam/internal/audit"
+	"cloudpam/internal/auth"
+)
+
+const (
+	// mfaChallengeTTL bounds the time between a correct password and the
+	// second login step.
+	mfaChallengeTTL = 5 * time.Minute
+
+	// mfaChallengeMaxAttempts is the number of wrong codes a challenge
+	// accepts before the user has to start again with their password.
+	mfaChallengeMaxAttempts = 5
+
+	// mfaIssuer names CloudPAM in authenticator apps.
+	mfaIssuer = "CloudPAM"
+)
+
+// mfaChallenge is a login that passed the password check and is waiting
+// for a TOTP or recovery code.
+type mfaChallenge struct {
+	userID    string
+	enroll    bool // policy requires MFA and the user has not yet enrolled
+	expiresAt time.Time
+	attempts  int
+}
+
+// mfaChallengeStore holds pending MFA challenges in memory, keyed by an
+// opaque token handed to the client in place of a session.
+type mfaChallengeStore struct {
+	mu         sync.Mutex
+	challenges map[string]*mfaChallenge
+}
+
+func newMFAChallengeStore() *mfaChallengeStore {
+	return &mfaChallengeStore{challenges: make(map[string]*mfaChallenge)}
+}
+
+// create starts a challenge for userID and returns its token.
+func (s *mfaChallengeStore) create(userID string, enroll bool) (string, *mfaChallenge, error) {
+	token, err := auth.GenerateSessionID()
+	if err != nil {
+		return "", nil, err
+	}
+	now := time.Now()
+	c := &mfaChallenge{userID: userID, enroll: enroll, expiresAt: now.Add(mfaChallengeTTL)}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for t, other := range s.challenges {
+		if now.After(other.expiresAt) {
+			delete(s.challenges, t)
+		}
+	}
+	s.challenges[token] = c
+	dup := *c
+	return token, &dup, nil
+}
+
+// get returns a copy of the unexpired challenge for token, or nil.
+func (s *mfaChallengeStore) get(token string) *mfaChallenge {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.challenges[token]
+	if !ok {
+		return nil
+	}
+	if time.Now().After(c.expiresAt) {
+		delete(s.challenges, token)
+		return nil
+	}
+	dup := *c
+	return &dup
+}
+
+// fail records a wrong code and drops the challenge once it has used up
+// its attempts.
+func (s *mfaChallengeStore) fail(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.challenges[token]; ok {
+		c.attempts++
+		if c.attempts >= mfaChallengeMaxAttempts {
+			delete(s.challenges, token)
+		}
+	}
+}
+
+func (s *mfaChallengeStore) delete(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.challenges, token)
+}
+
+// mfaEnrollmentResponse carries a new TOTP secret. The recovery codes are
+// shown once and only their hashes are kept.
+type mfaEnrollmentResponse struct {
+	Secret        string   `json:"secret"`
+	OTPAuthURI    string   `json:"otpauth_uri"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// startMFAChallenge answers a correct password with a challenge token
+// instead of a session.
+func (us *UserServer) startMFAChallenge(w http.ResponseWriter, r *http.Request, user *auth.User) {
+	ctx := r.Context()
+	token, c, err := us.mfaChallenges.create(user.ID, !user.MFAEnabled)
+	if err != nil {
+		us.writeErr(ctx, w, http.StatusInternalServerError, "failed to start mfa challenge", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, struct {
+		MFARequired           bool      `json:"mfa_required"`
+		MFAEnrollmentRequired bool      `json:"mfa_enrollment_required"`
+		MFAToken              string    `json:"mfa_token"`
+		ExpiresAt             time.Time `json:"expires_at"`
+	}{
+		MFARequired:           true,
+		MFAEnrollmentRequired: c.enroll,
+		MFAToken:              token,
+		ExpiresAt:             c.expiresAt.UTC(),
+	})
+}
+
+// handleLoginMFA completes a login with a TOTP code or, for enrolled users,
+// a recovery code. A challenge that requires enrolment is completed by the
+// first valid code for the secret issued by handleLoginMFAEnroll.
+// POST /api/v1/auth/login/mfa
+func (us *UserServer) handleLoginMFA(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var input struct {
+		MFAToken     string `json:"mfa_token"`
+		Code         string `json:"code"`
+		RecoveryCode string `json:"recovery_code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		us.writeErr(ctx, w, http.StatusBadRequest, "invalid json", "")
+		return
+	}
+	input.Code = strings.TrimSpace(input.Code)
+	input.RecoveryCode = strings.TrimSpace(input.RecoveryCode)
+	if input.Code == "" && input.RecoveryCode == "" {
+		us.writeErr(ctx, w, http.StatusBadRequest, "code or recovery_code is required", "")
+		return
+	}
+
+	c := us.mfaChallenges.get(input.MFAToken)
+	if c == nil {
+		us.writeErr(ctx, w, http.StatusUnauthorized, "invalid or expired mfa token", "sign in again")
+		return
+	}
+	user, ok := us.challengeUser(w, r, input.MFAToken, c)
+	if !ok {
+		return
+	}
+	if !c.enroll && !user.MFAEnabled {
+		// MFA was reset after the password step.
+		us.mfaChallenges.delete(input.MFAToken)
+		us.writeErr(ctx, w, http.StatusUnauthorized, "invalid or expired mfa token", "sign in again")
+		return
+	}
+	if c.enroll && user.MFASecret == "" {
+		us.writeErr(ctx, w, http.StatusConflict, "mfa enrollment not started", "call /api/v1/auth/login/mfa/enroll first")
+		return
+	}
+	if c.enroll && !user.MFAEnabled && input.RecoveryCode != "" {
+		us.writeErr(ctx, w, http.StatusBadRequest, "recovery codes cannot complete enrollment", "enter a code from the authenticator app")
+		return
+	}
+
+	now := time.Now().UTC()
+	valid, recovered := false, false
+	if input.Code != "" {
+		var step int64
+		if step, valid = auth.ValidateTOTP(user.MFASecret, input.Code, now, user.MFALastStep); valid {
+			user.MFALastStep = step
+		}
+	} else if i := auth.MatchRecoveryCode(user.MFARecoveryCodes, input.RecoveryCode); i >= 0 {
+		user.MFARecoveryCodes = append(user.MFARecoveryCodes[:i], user.MFARecoveryCodes[i+1:]...)
+		valid, recovered = true, true
+	}
+
+	settings := us.securitySettings(ctx)
+	if !valid {
+		us.mfaChallenges.fail(input.MFAToken)
+		locked, err := us.recordFailedLogin(ctx, user, settings)
+		if err != nil {
+			us.writeErr(ctx, w, http.StatusInternalServerError, "failed to record login failure", err.Error())
+			return
+		}
+		if locked {
+			us.mfaChallenges.delete(input.MFAToken)
+			us.writeErr(ctx, w, http.StatusLocked, "account locked", "too many failed login attempts")
+			return
+		}
+		us.writeErr(ctx, w, http.StatusUnauthorized, "invalid mfa code", "")
+		return
+	}
+	us.mfaChallenges.delete(input.MFAToken)
+
+	enrolled := c.enroll && !user.MFAEnabled
+	if enrolled {
+		user.MFAEnabled = true
+		user.MFAEnrolledAt = &now
+	}
+	user.UpdatedAt = now
+	if err := us.userStore.Update(ctx, user); err != nil {
+		us.writeErr(ctx, w, http.StatusInternalServerError, "failed to update user", err.Error())
+		return
+	}
+	if enrolled {
+		us.logAuditEventAs(ctx, user.Username, audit.ActorTypeUser, audit.ActionMFAEnrolled, audit.ResourceUser, user.ID, user.Username, http.StatusOK)
+	}
+	if recovered {
+		us.logAuditEventAs(ctx, user.Username, audit.ActorTypeUser, audit.ActionMFARecoveryCodeUsed, audit.ResourceUser, user.ID, user.Username, http.StatusOK)
+	}
+
+	us.finishLogin(w, r, user, settings)
+}
+
+// handleLoginMFAEnroll issues a TOTP secret to a user whose role requires
+// MFA but who has not enrolled yet, during login.
+// POST /api/v1/auth/login/mfa/enroll
+func (us *UserServer) handleLoginMFAEnroll(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var input struct {
+		MFAToken string `json:"mfa_token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		us.writeErr(ctx, w, http.StatusBadRequest, "invalid json", "")
+		return
+	}
+
+	c := us.mfaChallenges.get(input.MFAToken)
+	if c == nil {
+		us.writeErr(ctx, w, http.StatusUnauthorized, "invalid or expired mfa token", "sign in again")
+		return
+	}
+	if !c.enroll {
+		us.writeErr(ctx, w, http.StatusConflict, "mfa already enabled", "")
+		return
+	}
+	user, ok := us.challengeUser(w, r, input.MFAToken, c)
+	if !ok {
+		return
+	}
+	if user.MFAEnabled {
+		us.writeErr(ctx, w, http.StatusConflict, "mfa already enabled", "")
+		return
+	}
+	us.beginMFAEnrollment(w, r, user)
+}
+
+// challengeUser loads the user behind a challenge, dropping the challenge
+// if the account has since been disabled or locked.
+func (us *UserServer) challengeUser(w http.ResponseWriter, r *http.Request, token string, c *mfaChallenge) (*auth.User, bool) {
+	ctx := r.Context()
+	user, err := us.userStore.GetByID(ctx, c.userID)
+	if err != nil {
+		us.writeErr(ctx, w, http.StatusInternalServerError, "failed to get user", err.Error())
+		return nil, false
+	}
+	if user == nil || !user.IsActive {
+		us.mfaChallenges.delete(token)
+		us.writeErr(ctx, w, http.StatusUnauthorized, "invalid or expired mfa token", "sign in again")
+		return nil, false
+	}
+	if user.LockoutUntil != nil && time.Now().UTC().Before(*user.LockoutUntil) {
+		us.mfaChallenges.delete(token)
+		us.writeErr(ctx, w, http.StatusLocked, "account locked", "try again after "+user.LockoutUntil.Format(time.RFC3339))
+		return nil, false
+	}
+	return user, true
+}
+
+// handleMFAEnroll starts TOTP enrolment for the signed-in user. The secret
+// takes effect once confirmed through handleMFAVerify.
+// POST /api/v1/auth/mfa/enroll
+func (us *UserServer) handleMFAEnroll(w http.ResponseWriter, r *http.Request) {
+	user, ok := us.mfaSelf(w, r)
+	if !ok {
+		return
+	}
+	if user.MFAEnabled {
+		us.writeErr(r.Context(), w, http.StatusConflict, "mfa already enabled", "ask an administrator to reset it first")
+		return
+	}
+	us.beginMFAEnrollment(w, r, user)
+}
+
+// handleMFAVerify confirms the signed-in user's pending TOTP enrolment.
+// POST /api/v1/auth/mfa/verify
+func (us *UserServer) handleMFAVerify(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := us.mfaSelf(w, r)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Code string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		us.writeErr(ctx, w, http.StatusBadRequest, "invalid json", "")
+		return
+	}
+	if strings.TrimSpace(input.Code) == "" {
+		us.writeErr(ctx, w, http.StatusBadRequest, "code is required", "")
+		return
+	}
+	if user.MFAEnabled {
+		us.writeErr(ctx, w, http.StatusConflict, "mfa already enabled", "")
+		return
+	}
+	if user.MFASecret == "" {
+		us.writeErr(ctx, w, http.StatusConflict, "mfa enrollment not started", "call /api/v1/auth/mfa/enroll first")
+		return
+	}
+
+	now := time.Now().UTC()
+	step, valid := auth.ValidateTOTP(user.MFASecret, input.Code, now, user.MFALastStep)
+	if !valid {
+		us.writeErr(ctx, w, http.StatusUnauthorized, "invalid mfa code", "")
+		return
+	}
+	user.MFAEnabled = true
+	user.MFAEnrolledAt = &now
+	user.MFALastStep = step
+	user.UpdatedAt = now
+	if err := us.userStore.Update(ctx, user); err != nil {
+		us.writeErr(ctx, w, http.StatusInternalServerError, "failed to update user", err.Error())
+		return
+	}
+
+	us.logAuditEvent(ctx, audit.ActionMFAEnrolled, audit.ResourceUser, user.ID, user.Username, http.StatusOK)
+	writeJSON(w, http.StatusOK, user)
+}
+
+// mfaSelf returns the signed-in local user, freshly loaded so that the
+// MFA fields are current.
+func (us *UserServer) mfaSelf(w http.ResponseWriter, r *http.Request) (*auth.User, bool) {
+	ctx := r.Context()
+	current := auth.UserFromContext(ctx)
+	if current == nil {
+		us.writeErr(ctx, w, http.StatusUnauthorized, "not authenticated", "mfa enrollment requires a user session")
+		return nil, false
+	}
+	user, err := us.userStore.GetByID(ctx, current.ID)
+	if err != nil {
+		us.writeErr(ctx, w, http.StatusInternalServerError, "failed to get user", err.Error())
+		return nil, false
+	}
+	if user == nil {
+		us.writeErr(ctx, w, http.StatusNotFound, "user not found", "")
+		return nil, false
+	}
+	if user.AuthProvider != "" && user.AuthProvider != "local" {
+		us.writeErr(ctx, w, http.StatusBadRequest, "mfa applies to local accounts only", "configure MFA at your identity provider")
+		return nil, false
+	}
+	return user, true
+}
+
+// beginMFAEnrollment stores a new pending secret and recovery codes for
+// user, replacing any earlier unconfirmed ones, and returns them.
+func (us *UserServer) beginMFAEnrollment(w http.ResponseWriter, r *http.Request, user *auth.User) {
+	ctx := r.Context()
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		us.writeErr(ctx, w, http.StatusInternalServerError, "failed to generate mfa secret", err.Error())
+		return
+	}
+	codes, hashes, err := auth.GenerateRecoveryCodes(auth.RecoveryCodeCount)
+	if err != nil {
+		us.writeErr(ctx, w, http.StatusInternalServerError, "failed to generate recovery codes", err.Error())
+		return
+	}
+
+	user.MFASecret = secret
+	user.MFARecoveryCodes = hashes
+	user.MFALastStep = 0
+	user.UpdatedAt = time.Now().UTC()
+	if err := us.userStore.Update(ctx, user); err != nil {
+		us.writeErr(ctx, w, http.StatusInternalServerError, "failed to update user", err.Error())
+		return
+	}
+
+	// The user may not have a session yet when enrolling during login.
+	us.logAuditEventAs(ctx, user.Username, audit.ActorTypeUser, audit.ActionMFAEnrollmentStarted, audit.ResourceUser, user.ID, user.Username, http.StatusOK)
+	writeJSON(w, http.StatusOK, mfaEnrollmentResponse{
+		Secret:        secret,
+		OTPAuthURI:    auth.TOTPURI(mfaIssuer, user.Username, secret),
+		RecoveryCodes: codes,
+	})
+}
+
+// handleResetMFA removes a user's MFA enrolment so that they can enrol
+// again, for example after losing their device and recovery codes.
+// POST /api/v1/auth/users/{id}/mfa/reset
+func (us *UserServer) handleResetMFA(w http.ResponseWriter, r *http.Request, id string) {
+	ctx := r.Context()
+
+	user, err := us.userStore.GetByID(ctx, id)
+	if err != nil {
+		us.writeErr(ctx, w, http.StatusInternalServerError, "failed to get user", err.Error())
+		return
+	}
+	if user == nil {
+		us.writeErr(ctx, w, http.StatusNotFound, "user not found", "")
+		return
+	}
+
+	user.ClearMFA()
+	user.UpdatedAt = time.Now().UTC()
+	if err := us.userStore.Update(ctx, user); err != nil {
+		us.writeErr(ctx, w, http.StatusInternalServerError, "failed to reset mfa", err.Error())
+		return
+	}
+
+	us.logAuditEvent(ctx, audit.ActionMFAReset, audit.ResourceUser, user.ID, user.Username, http.StatusOK)
+	writeJSON(w, http.StatusOK, user)
+}
@@ -0,0 +1,251 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	stdhttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"cloudpam/internal/audit"
+	"cloudpam/internal/auth"
+	"cloudpam/internal/storage"
+)
+
+func createMFATestUser(t *testing.T, userStore auth.UserStore, id, username string, role auth.Role) *auth.User {
+	t.Helper()
+	hash, _ := auth.HashPassword("TestPass123!")
+	user := &auth.User{
+		ID:           id,
+		Username:     username,
+		Role:         role,
+		PasswordHash: hash,
+		IsActive:     true,
+		CreatedAt:    time.Now().UTC(),
+		UpdatedAt:    time.Now().UTC(),
+	}
+	if err := userStore.Create(context.Background(), user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return user
+}
+
+func postMFAJSON(us *UserServer, path, body string, as *auth.User) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(stdhttp.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if as != nil {
+		req = req.WithContext(auth.ContextWithRole(auth.ContextWithUser(req.Context(), as), as.Role))
+	}
+	rr := httptest.NewRecorder()
+	us.mux.ServeHTTP(rr, req)
+	return rr
+}
+
+type mfaLoginResult struct {
+	MFARequired           bool       `json:"mfa_required"`
+	MFAEnrollmentRequired bool       `json:"mfa_enrollment_required"`
+	MFAToken              string     `json:"mfa_token"`
+	User                  *auth.User `json:"user"`
+}
+
+func loginForMFA(t *testing.T, us *UserServer, username string) mfaLoginResult {
+	t.Helper()
+	rr := postMFAJSON(us, "/api/v1/auth/login", `{"username":"`+username+`","password":"TestPass123!"}`, nil)
+	if rr.Code != stdhttp.StatusOK {
+		t.Fatalf("login: expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var res mfaLoginResult
+	if err := json.Unmarshal(rr.Body.Bytes(), &res); err != nil {
+		t.Fatalf("unmarshal login: %v", err)
+	}
+	if res.MFARequired && len(rr.Result().Cookies()) != 0 {
+		t.Fatalf("MFA challenge set cookies: %v", rr.Result().Cookies())
+	}
+	return res
+}
+
+func TestMFA_EnrollAndLogin(t *testing.T) {
+	us, _, userStore, auditLogger := setupUserTestServerWithAudit()
+	ctx := context.Background()
+	user := createMFATestUser(t, userStore, "user-mfa", "mfauser", auth.RoleViewer)
+
+	rr := postMFAJSON(us, "/api/v1/auth/mfa/enroll", "", user)
+	if rr.Code != stdhttp.StatusOK {
+		t.Fatalf("enroll: expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var enrollment mfaEnrollmentResponse
+	_ = json.Unmarshal(rr.Body.Bytes(), &enrollment)
+	if enrollment.Secret == "" || len(enrollment.RecoveryCodes) != auth.RecoveryCodeCount ||
+		!strings.HasPrefix(enrollment.OTPAuthURI, "otpauth://totp/CloudPAM:mfauser?") {
+		t.Fatalf("enrollment = %+v", enrollment)
+	}
+
+	// Until verified, login still needs only the password.
+	if res := loginForMFA(t, us, "mfauser"); res.MFARequired {
+		t.Fatalf("unconfirmed enrollment required MFA at login")
+	}
+
+	step := auth.TOTPStep(time.Now())
+	code, _ := auth.TOTPCode(enrollment.Secret, step)
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+	if rr := postMFAJSON(us, "/api/v1/auth/mfa/verify", `{"code":"`+wrong+`"}`, user); rr.Code != stdhttp.StatusUnauthorized {
+		t.Fatalf("verify wrong code: expected 401, got %d", rr.Code)
+	}
+	rr = postMFAJSON(us, "/api/v1/auth/mfa/verify", `{"code":"`+code+`"}`, user)
+	if rr.Code != stdhttp.StatusOK {
+		t.Fatalf("verify: expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if got, _ := userStore.GetByID(ctx, user.ID); !got.MFAEnabled || got.MFAEnrolledAt == nil {
+		t.Fatalf("user after verify = %+v", got)
+	}
+	if rr := postMFAJSON(us, "/api/v1/auth/mfa/enroll", "", user); rr.Code != stdhttp.StatusConflict {
+		t.Fatalf("re-enroll: expected 409, got %d", rr.Code)
+	}
+
+	// The password step now yields a challenge instead of a session.
+	res := loginForMFA(t, us, "mfauser")
+	if !res.MFARequired || res.MFAEnrollmentRequired || res.MFAToken == "" || res.User != nil {
+		t.Fatalf("login = %+v", res)
+	}
+
+	// The code used to verify cannot be replayed; the next step's can.
+	rr = postMFAJSON(us, "/api/v1/auth/login/mfa", `{"mfa_token":"`+res.MFAToken+`","code":"`+code+`"}`, nil)
+	if rr.Code != stdhttp.StatusUnauthorized {
+		t.Fatalf("replayed code: expected 401, got %d: %s", rr.Code, rr.Body.String())
+	}
+	next, _ := auth.TOTPCode(enrollment.Secret, step+1)
+	rr = postMFAJSON(us, "/api/v1/auth/login/mfa", `{"mfa_token":"`+res.MFAToken+`","code":"`+next+`"}`, nil)
+	if rr.Code != stdhttp.StatusOK {
+		t.Fatalf("login mfa: expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if len(rr.Result().Cookies()) == 0 {
+		t.Fatal("expected a session cookie after the MFA step")
+	}
+	if rr := postMFAJSON(us, "/api/v1/auth/login/mfa", `{"mfa_token":"`+res.MFAToken+`","code":"`+next+`"}`, nil); rr.Code != stdhttp.StatusUnauthorized {
+		t.Fatalf("reused token: expected 401, got %d", rr.Code)
+	}
+
+	// A recovery code works once.
+	res = loginForMFA(t, us, "mfauser")
+	body := `{"mfa_token":"` + res.MFAToken + `","recovery_code":"` + enrollment.RecoveryCodes[0] + `"}`
+	if rr := postMFAJSON(us, "/api/v1/auth/login/mfa", body, nil); rr.Code != stdhttp.StatusOK {
+		t.Fatalf("recovery code: expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	res = loginForMFA(t, us, "mfauser")
+	body = `{"mfa_token":"` + res.MFAToken + `","recovery_code":"` + enrollment.RecoveryCodes[0] + `"}`
+	if rr := postMFAJSON(us, "/api/v1/auth/login/mfa", body, nil); rr.Code != stdhttp.StatusUnauthorized {
+		t.Fatalf("reused recovery code: expected 401, got %d", rr.Code)
+	}
+	if got, _ := userStore.GetByID(ctx, user.ID); len(got.MFARecoveryCodes) != auth.RecoveryCodeCount-1 {
+		t.Errorf("recovery codes left = %d, want %d", len(got.MFARecoveryCodes), auth.RecoveryCodeCount-1)
+	}
+
+	events, _, _ := auditLogger.List(ctx, audit.ListOptions{Limit: 50})
+	if countAuditActions(events, audit.ActionMFAEnrollmentStarted) != 1 || countAuditActions(events, audit.ActionMFAEnrolled) != 1 ||
+		countAuditActions(events, audit.ActionMFARecoveryCodeUsed) != 1 {
+		t.Fatalf("expected mfa_enrollment_started, mfa_enrolled and mfa_recovery_code_used audit events, got %#v", events)
+	}
+	if countAuditActions(events, audit.ActionLoginFailed) != 2 {
+		t.Errorf("expected 2 login_failed events for the rejected codes, got %d", countAuditActions(events, audit.ActionLoginFailed))
+	}
+}
+
+func TestMFA_RequiredRoleForcesEnrollment(t *testing.T) {
+	us, _, userStore := setupUserTestServer()
+	ctx := context.Background()
+	user := createMFATestUser(t, userStore, "user-mfa-required", "operator1", auth.RoleOperator)
+	createMFATestUser(t, userStore, "user-mfa-exempt", "viewer1", auth.RoleViewer)
+
+	settingsStore := storage.NewMemorySettingsStore()
+	settings, _ := settingsStore.GetSecuritySettings(ctx)
+	settings.MFARequiredRoles = []string{"operator"}
+	_ = settingsStore.UpdateSecuritySettings(ctx, settings)
+	us.SetSettingsStore(settingsStore)
+
+	if res := loginForMFA(t, us, "viewer1"); res.MFARequired {
+		t.Fatalf("viewer was asked for MFA: %+v", res)
+	}
+
+	res := loginForMFA(t, us, "operator1")
+	if !res.MFARequired || !res.MFAEnrollmentRequired {
+		t.Fatalf("login = %+v, want enrollment required", res)
+	}
+	rr := postMFAJSON(us, "/api/v1/auth/login/mfa", `{"mfa_token":"`+res.MFAToken+`","code":"123456"}`, nil)
+	if rr.Code != stdhttp.StatusConflict {
+		t.Fatalf("code before enrollment: expected 409, got %d: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = postMFAJSON(us, "/api/v1/auth/login/mfa/enroll", `{"mfa_token":"`+res.MFAToken+`"}`, nil)
+	if rr.Code != stdhttp.StatusOK {
+		t.Fatalf("login enroll: expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var enrollment mfaEnrollmentResponse
+	_ = json.Unmarshal(rr.Body.Bytes(), &enrollment)
+	code, _ := auth.TOTPCode(enrollment.Secret, auth.TOTPStep(time.Now()))
+	rr = postMFAJSON(us, "/api/v1/auth/login/mfa", `{"mfa_token":"`+res.MFAToken+`","code":"`+code+`"}`, nil)
+	if rr.Code != stdhttp.StatusOK {
+		t.Fatalf("login mfa: expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if got, _ := userStore.GetByID(ctx, user.ID); !got.MFAEnabled {
+		t.Fatal("expected MFA enabled after the first code")
+	}
+
+	// With everyone required, the viewer is challenged too.
+	settings.MFARequired = true
+	_ = settingsStore.UpdateSecuritySettings(ctx, settings)
+	if res := loginForMFA(t, us, "viewer1"); !res.MFAEnrollmentRequired {
+		t.Fatalf("viewer login = %+v, want enrollment required", res)
+	}
+}
+
+func TestMFA_AdminReset(t *testing.T) {
+	us, _, userStore, auditLogger := setupUserTestServerWithAudit()
+	ctx := context.Background()
+	user := createMFATestUser(t, userStore, "user-mfa-reset", "resetuser", auth.RoleViewer)
+	now := time.Now().UTC()
+	user.MFAEnabled = true
+	user.MFAEnrolledAt = &now
+	user.MFASecret, _ = auth.GenerateTOTPSecret()
+	user.MFARecoveryCodes = []string{"x"}
+	_ = userStore.Update(ctx, user)
+
+	res := loginForMFA(t, us, "resetuser")
+	if !res.MFARequired {
+		t.Fatalf("login = %+v, want MFA challenge", res)
+	}
+
+	admin := &auth.User{ID: "admin", Username: "admin", Role: auth.RoleAdmin, IsActive: true}
+	rr := postMFAJSON(us, "/api/v1/auth/users/"+user.ID+"/mfa/reset", "", admin)
+	if rr.Code != stdhttp.StatusOK {
+		t.Fatalf("reset: expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	got, _ := userStore.GetByID(ctx, user.ID)
+	if got.MFAEnabled || got.MFASecret != "" || got.MFARecoveryCodes != nil || got.MFAEnrolledAt != nil {
+		t.Fatalf("user after reset = %+v", got)
+	}
+
+	// The challenge issued before the reset no longer completes.
+	code, _ := auth.TOTPCode(user.MFASecret, auth.TOTPStep(time.Now()))
+	rr = postMFAJSON(us, "/api/v1/auth/login/mfa", `{"mfa_token":"`+res.MFAToken+`","code":"`+code+`"}`, nil)
+	if rr.Code != stdhttp.StatusUnauthorized {
+		t.Fatalf("stale challenge: expected 401, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if res := loginForMFA(t, us, "resetuser"); res.MFARequired {
+		t.Fatalf("login after reset = %+v, want a session", res)
+	}
+
+	events, _, _ := auditLogger.List(ctx, audit.ListOptions{Limit: 10})
+	if countAuditActions(events, audit.ActionMFAReset) != 1 {
+		t.Fatalf("expected 1 mfa_reset audit event, got %#v", events)
+	}
+	for _, e := range events {
+		if e.Action == audit.ActionMFAReset && (e.Actor != "admin" || e.ResourceID != user.ID) {
+			t.Errorf("mfa_reset event = %+v", e)
+		}
+	}
+}
@@ -499,6 +499,10 @@ func (ns *NetworkServer) handleNetworkConflictLinkAction(w http.ResponseWriter,
 	})
 }
 
+// errNetworkImportIncomplete aborts a conflict import transaction when the
+// import skipped any of the selected resources.
+var errNetworkImportIncomplete = errors.New("import did not complete for all selected resources")
+
 func (ns *NetworkServer) handleNetworkConflictImportAction(w http.ResponseWriter, r *http.Request, conflictID string) {
 	if r.Method != http.MethodPost {
 		w.Header().Set("Allow", http.MethodPost)
@@ -554,16 +558,14 @@ func (ns *NetworkServer) handleNetworkConflictImportAction(w http.ResponseWriter
 		PoolID:      req.PoolID,
 	}
 	discoveryServer := &DiscoveryServer{srv: ns.srv, store: ns.discStore}
-	if !req.Override {
-		preview, err := discoveryServer.previewDiscoveryImport(r.Context(), domain.DiscoveryImportPreviewRequest(importReq))
-		if err != nil {
-			ns.srv.writeErr(r.Context(), w, http.StatusInternalServerError, "preview import failed", err.Error())
-			return
-		}
-		if preview.Importable != len(req.ResourceIDs) {
-			ns.srv.writeErr(r.Context(), w, http.StatusBadRequest, "selected resources are not all importable; set override to import conflict rows", "")
-			return
-		}
+	preview, err := discoveryServer.previewDiscoveryImport(r.Context(), domain.DiscoveryImportPreviewRequest(importReq))
+	if err != nil {
+		ns.srv.writeErr(r.Context(), w, http.StatusInternalServerError, "preview import failed", err.Error())
+		return
+	}
+	if !req.Override && preview.Importable != len(req.ResourceIDs) {
+		ns.srv.writeErr(r.Context(), w, http.StatusBadRequest, "selected resources are not all importable; set override to import conflict rows", "")
+		return
 	}
 	actionDetails := map[string]string{
 		"network_conflict_action": "import",
@@ -576,42 +578,51 @@ func (ns *NetworkServer) handleNetworkConflictImportAction(w http.ResponseWriter
 		ns.srv.writeErr(r.Context(), w, http.StatusInternalServerError, "prepare conflict action failed", err.Error())
 		return
 	}
-	importResp, err := discoveryServer.applyDiscoveryImport(r.Context(), importReq, discoveryImportApplyOptions{AllowBlocked: req.Override})
-	if err != nil {
-		ns.srv.writeErr(r.Context(), w, http.StatusInternalServerError, "apply import failed", err.Error())
-		return
-	}
-	if importResp.PoolsCreated+importResp.ResourcesLinked == 0 || importResp.Skipped > 0 || len(importResp.Errors) > 0 {
-		rollbackErr := ns.rollbackNetworkConflictImport(r.Context(), importResp)
+
+	// The import and the resolution record commit together: an import that
+	// skips any selected resource, or a resolution that cannot be recorded,
+	// rolls back every pool and link the import wrote.
+	var importResp domain.DiscoveryImportApplyResponse
+	var importRels []domain.CreateNetworkRelationship
+	var resolveErr error
+	err = ns.srv.withTx(r.Context(), func(st storage.Store) error {
+		var err error
+		importResp, importRels, err = discoveryServer.applyDiscoveryImportIn(r.Context(), st, importReq, preview, discoveryImportApplyOptions{AllowBlocked: req.Override})
+		if err != nil {
+			return err
+		}
+		if importResp.PoolsCreated+importResp.ResourcesLinked == 0 || importResp.Skipped > 0 || len(importResp.Errors) > 0 {
+			return errNetworkImportIncomplete
+		}
+		actionDetails["pools_created"] = fmt.Sprintf("%d", importResp.PoolsCreated)
+		actionDetails["resources_linked"] = fmt.Sprintf("%d", importResp.ResourcesLinked)
+		actionDetails["skipped"] = fmt.Sprintf("%d", importResp.Skipped)
+		actionDetails["created_pool_ids"] = joinInt64s(importResp.CreatedPoolIDs)
+		actionDetails["linked_resource_ids"] = joinUUIDs(importResp.LinkedResourceIDs)
+		resolveReq := domain.ResolveNetworkConflictRequest{
+			Decision: "import",
+			Reason:   networkActionReason("import", req.Reason, actionDetails),
+		}
+		resolveErr = persistNetworkConflictResolution(r.Context(), txStore(st, ns.driftStore), *conflict, resolveReq, actionDetails)
+		return resolveErr
+	})
+	switch {
+	case errors.Is(err, errNetworkImportIncomplete):
 		detail := strings.Join(importResp.Errors, "; ")
 		if detail == "" && importResp.Skipped > 0 {
 			detail = fmt.Sprintf("%d selected resources were skipped", importResp.Skipped)
 		}
-		if rollbackErr != nil {
-			ns.srv.writeErr(r.Context(), w, http.StatusInternalServerError, "import was incomplete and rollback failed", detail+"; rollback: "+rollbackErr.Error())
-			return
-		}
 		ns.srv.writeErr(r.Context(), w, http.StatusBadRequest, "import did not complete for all selected resources", detail)
 		return
-	}
-	actionDetails["pools_created"] = fmt.Sprintf("%d", importResp.PoolsCreated)
-	actionDetails["resources_linked"] = fmt.Sprintf("%d", importResp.ResourcesLinked)
-	actionDetails["skipped"] = fmt.Sprintf("%d", importResp.Skipped)
-	actionDetails["created_pool_ids"] = joinInt64s(importResp.CreatedPoolIDs)
-	actionDetails["linked_resource_ids"] = joinUUIDs(importResp.LinkedResourceIDs)
-	resolveReq := domain.ResolveNetworkConflictRequest{
-		Decision: "import",
-		Reason:   networkActionReason("import", req.Reason, actionDetails),
-	}
-	if err := ns.persistNetworkConflictActionResolution(r.Context(), *conflict, resolveReq, actionDetails); err != nil {
-		rollbackErr := ns.rollbackNetworkConflictImport(r.Context(), importResp)
-		if rollbackErr != nil {
-			ns.srv.writeErr(r.Context(), w, http.StatusInternalServerError, "record conflict action failed and rollback failed", err.Error()+"; rollback: "+rollbackErr.Error())
-			return
-		}
-		ns.srv.writeErr(r.Context(), w, http.StatusInternalServerError, "record conflict action failed", err.Error())
+	case resolveErr != nil:
+		ns.srv.writeErr(r.Context(), w, http.StatusInternalServerError, "record conflict action failed", resolveErr.Error())
+		return
+	case err != nil:
+		ns.srv.writeErr(r.Context(), w, http.StatusInternalServerError, "apply import failed", err.Error())
 		return
 	}
+	discoveryServer.persistDiscoveryRelationships(r.Context(), importRels)
+
 	var relationshipInputs []domain.CreateNetworkRelationship
 	for _, resourceID := range importResp.LinkedResourceIDs {
 		for _, poolID := range importResp.CreatedPoolIDs {
@@ -1227,6 +1238,10 @@ func (ns *NetworkServer) networkNodeFromResource(res domain.DiscoveredResource,
 			node.IPAddress = strings.TrimSuffix(res.CIDR, "/32")
 		}
 	}
+	if res.ResourceType == domain.ResourceTypeNetworkInterface {
+		primary, _, _ := strings.Cut(res.Metadata[domain.NICMetaAddresses], ",")
+		node.IPAddress = primary
+	}
 	return node
 }
 
@@ -1519,17 +1534,23 @@ func (ns *NetworkServer) persistNetworkConflictResolution(ctx context.Context, c
 }
 
 func (ns *NetworkServer) persistNetworkConflictActionResolution(ctx context.Context, conflict domain.NetworkConflict, req domain.ResolveNetworkConflictRequest, details map[string]string) error {
-	if ns.driftStore == nil {
+	return persistNetworkConflictResolution(ctx, ns.driftStore, conflict, req, details)
+}
+
+// persistNetworkConflictResolution records the decision on the conflict's
+// drift item through driftStore, which may be scoped to a transaction.
+func persistNetworkConflictResolution(ctx context.Context, driftStore storage.DriftStore, conflict domain.NetworkConflict, req domain.ResolveNetworkConflictRequest, details map[string]string) error {
+	if driftStore == nil {
 		return fmt.Errorf("drift store is not available")
 	}
 	status := networkDecisionStatus(req.Decision)
 	reason := networkResolutionReason(req.Decision, req.Reason)
 	if len(details) > 0 {
-		if err := ns.driftStore.UpdateDriftDetails(ctx, conflict.ID, details); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		if err := driftStore.UpdateDriftDetails(ctx, conflict.ID, details); err != nil && !errors.Is(err, storage.ErrNotFound) {
 			return err
 		}
 	}
-	if err := ns.driftStore.UpdateDriftStatus(ctx, conflict.ID, status, reason); err == nil {
+	if err := driftStore.UpdateDriftStatus(ctx, conflict.ID, status, reason); err == nil {
 		return nil
 	} else if !errors.Is(err, storage.ErrNotFound) {
 		return err
@@ -1561,10 +1582,10 @@ func (ns *NetworkServer) persistNetworkConflictActionResolution(ctx context.Cont
 	if len(conflict.PoolIDs) > 0 {
 		item.PoolID = &conflict.PoolIDs[0]
 	}
-	if err := ns.driftStore.CreateDriftItem(ctx, item); err != nil {
+	if err := driftStore.CreateDriftItem(ctx, item); err != nil {
 		return err
 	}
-	return ns.driftStore.UpdateDriftStatus(ctx, conflict.ID, status, reason)
+	return driftStore.UpdateDriftStatus(ctx, conflict.ID, status, reason)
 }
 
 func (ns *NetworkServer) logNetworkConflictAudit(ctx context.Context, action string, conflict domain.NetworkConflict, after map[string]any) {
@@ -1628,26 +1649,6 @@ func (ns *NetworkServer) rollbackNetworkConflictLink(ctx context.Context, discov
 	return ns.discStore.LinkResourceToPool(ctx, discoveredID, *previousPoolID)
 }
 
-func (ns *NetworkServer) rollbackNetworkConflictImport(ctx context.Context, resp domain.DiscoveryImportApplyResponse) error {
-	var errs []string
-	for _, resourceID := range resp.LinkedResourceIDs {
-		if err := ns.discStore.UnlinkResource(ctx, resourceID); err != nil {
-			errs = append(errs, fmt.Sprintf("unlink %s: %v", resourceID, err))
-		}
-	}
-	for i := len(resp.CreatedPoolIDs) - 1; i >= 0; i-- {
-		if ok, err := ns.store.DeletePool(ctx, resp.CreatedPoolIDs[i]); err != nil {
-			errs = append(errs, fmt.Sprintf("delete pool %d: %v", resp.CreatedPoolIDs[i], err))
-		} else if !ok {
-			errs = append(errs, fmt.Sprintf("delete pool %d: not found", resp.CreatedPoolIDs[i]))
-		}
-	}
-	if len(errs) > 0 {
-		return errors.New(strings.Join(errs, "; "))
-	}
-	return nil
-}
-
 func (ns *NetworkServer) findNetworkConflict(ctx context.Context, conflictID string) (*domain.NetworkConflict, error) {
 	view, err := ns.buildNetworkView(ctx, networkViewFilters{})
 	if err != nil {
@@ -32,6 +32,7 @@ type OIDCServer struct {
 	sessionStore  auth.SessionStore
 	userStore     auth.UserStore
 	settingsStore storage.SettingsStore
+	roleStore     auth.RoleStore
 	encryptionKey []byte
 	callbackURL   string
 	providerCache sync.Map // id -> *oidc.Provider
@@ -58,6 +59,11 @@ func NewOIDCServer(
 	}
 }
 
+// SetRoleStore lets role rules name custom roles from the role store.
+func (os *OIDCServer) SetRoleStore(roleStore auth.RoleStore) {
+	os.roleStore = roleStore
+}
+
 // RegisterOIDCRoutes registers the OIDC public routes (no auth required).
 func (os *OIDCServer) RegisterOIDCRoutes(_ *slog.Logger) {
 	os.handleOpenAPIRouteFunc("/api/v1/auth/oidc/login", os.handleOIDCLogin)
@@ -121,15 +127,16 @@ func (os *OIDCServer) handleAdminCreateProvider(w http.ResponseWriter, r *http.R
 	ctx := r.Context()
 
 	var input struct {
-		Name          string            `json:"name"`
-		IssuerURL     string            `json:"issuer_url"`
-		ClientID      string            `json:"client_id"`
-		ClientSecret  string            `json:"client_secret"`
-		Scopes        string            `json:"scopes"`
-		RoleMapping   map[string]string `json:"role_mapping"`
-		DefaultRole   string            `json:"default_role"`
-		AutoProvision bool              `json:"auto_provision"`
-		Enabled       bool              `json:"enabled"`
+		Name          string                `json:"name"`
+		IssuerURL     string                `json:"issuer_url"`
+		ClientID      string                `json:"client_id"`
+		ClientSecret  string                `json:"client_secret"`
+		Scopes        string                `json:"scopes"`
+		RoleMapping   map[string]string     `json:"role_mapping"`
+		RoleRules     []domain.OIDCRoleRule `json:"role_rules"`
+		DefaultRole   string                `json:"default_role"`
+		AutoProvision bool                  `json:"auto_provision"`
+		Enabled       bool                  `json:"enabled"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
 		os.writeErr(ctx, w, http.StatusBadRequest, "invalid request body", err.Error())
@@ -153,6 +160,10 @@ func (os *OIDCServer) handleAdminCreateProvider(w http.ResponseWriter, r *http.R
 		os.writeErr(ctx, w, http.StatusBadRequest, "client_secret is required", "")
 		return
 	}
+	if err := os.validateRoleRules(ctx, input.RoleRules); err != nil {
+		os.writeErr(ctx, w, http.StatusBadRequest, "invalid role_rules", err.Error())
+		return
+	}
 
 	// Encrypt client secret.
 	encSecret, err := oidc.Encrypt(input.ClientSecret, os.encryptionKey)
@@ -170,6 +181,7 @@ func (os *OIDCServer) handleAdminCreateProvider(w http.ResponseWriter, r *http.R
 		ClientSecretEncrypted: encSecret,
 		Scopes:                input.Scopes,
 		RoleMapping:           input.RoleMapping,
+		RoleRules:             input.RoleRules,
 		DefaultRole:           input.DefaultRole,
 		AutoProvision:         input.AutoProvision,
 		Enabled:               input.Enabled,
@@ -279,6 +291,18 @@ func (os *OIDCServer) handleAdminUpdateProvider(w http.ResponseWriter, r *http.R
 			provider.RoleMapping = roleMapping
 		}
 	}
+	if v, ok := input["role_rules"]; ok {
+		var roleRules []domain.OIDCRoleRule
+		if err := json.Unmarshal(v, &roleRules); err != nil {
+			os.writeErr(ctx, w, http.StatusBadRequest, "invalid role_rules", err.Error())
+			return
+		}
+		if err := os.validateRoleRules(ctx, roleRules); err != nil {
+			os.writeErr(ctx, w, http.StatusBadRequest, "invalid role_rules", err.Error())
+			return
+		}
+		provider.RoleRules = roleRules
+	}
 	if v, ok := input["default_role"]; ok {
 		var defaultRole string
 		if err := json.Unmarshal(v, &defaultRole); err == nil {
@@ -561,11 +585,12 @@ func (os *OIDCServer) handleOIDCCallback(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Exchange code for claims.
-	claims, err := prov.Exchange(ctx, code)
+	tokens, err := prov.ExchangeTokens(ctx, code)
 	if err != nil {
 		os.writeErr(ctx, w, http.StatusUnauthorized, "token exchange failed", err.Error())
 		return
 	}
+	claims := tokens.Claims
 
 	// Look up user by OIDC identity.
 	user, err := os.userStore.GetByOIDCIdentity(ctx, claims.Issuer, claims.Subject)
@@ -574,6 +599,23 @@ func (os *OIDCServer) handleOIDCCallback(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Users provisioned over SCIM have no OIDC identity until their first
+	// login; link them by username or email.
+	if user == nil {
+		user, err = os.linkSCIMUser(ctx, claims)
+		if err != nil {
+			os.writeErr(ctx, w, http.StatusInternalServerError, "failed to look up user", err.Error())
+			return
+		}
+	}
+
+	// Map claims onto a role. The roles of SCIM users follow their SCIM
+	// groups instead.
+	var decision oidc.RoleDecision
+	if user == nil || user.AuthProvider != scimAuthProvider {
+		decision = os.resolveRole(ctx, provCfg, claims)
+	}
+
 	// JIT provisioning if user not found and auto_provision is enabled.
 	if user == nil {
 		if !provCfg.AutoProvision {
@@ -581,7 +623,7 @@ func (os *OIDCServer) handleOIDCCallback(w http.ResponseWriter, r *http.Request)
 			return
 		}
 
-		role := oidc.MapRole(*claims, provCfg.RoleMapping, auth.ParseRole(provCfg.DefaultRole))
+		role := decision.Role
 		if role == auth.RoleNone {
 			role = auth.RoleViewer
 		}
@@ -631,19 +673,14 @@ func (os *OIDCServer) handleOIDCCallback(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Update role from current claims (role sync on each login).
-	newRole := oidc.MapRole(*claims, provCfg.RoleMapping, auth.ParseRole(provCfg.DefaultRole))
-	if newRole != auth.RoleNone && newRole != user.Role {
-		user.Role = newRole
-		user.UpdatedAt = time.Now().UTC()
-		_ = os.userStore.Update(ctx, user)
+	// Update role from current claims (role sync on each login). Sessions
+	// holding the old role are ended.
+	if os.syncRole(ctx, user, decision) {
+		_ = os.sessionStore.DeleteByUserID(ctx, user.ID)
 	}
 
 	// Create session.
-	session, err := auth.NewSession(user.ID, user.Role, auth.DefaultSessionDuration, map[string]string{
-		"auth_provider": "oidc",
-		"oidc_issuer":   claims.Issuer,
-	})
+	session, err := os.newOIDCSession(user, claims.Issuer, tokens.RefreshToken)
 	if err != nil {
 		os.writeErr(ctx, w, http.StatusInternalServerError, "failed to create session", err.Error())
 		return
@@ -657,20 +694,7 @@ func (os *OIDCServer) handleOIDCCallback(w http.ResponseWriter, r *http.Request)
 	now := time.Now().UTC()
 	_ = os.userStore.UpdateLastLogin(ctx, user.ID, now)
 
-	// Set session cookie.
-	sameSite := http.SameSiteLaxMode
-	if isSecure {
-		sameSite = http.SameSiteStrictMode
-	}
-	http.SetCookie(w, &http.Cookie{
-		Name:     "session",
-		Value:    session.ID,
-		Path:     "/",
-		HttpOnly: true,
-		Secure:   isSecure,
-		SameSite: sameSite,
-		MaxAge:   int(auth.DefaultSessionDuration.Seconds()),
-	})
+	setOIDCSessionCookie(w, r, session)
 
 	os.logOIDCAudit(ctx, audit.ActionLogin, audit.ResourceSession, session.ID, user.Username, http.StatusOK)
 
@@ -717,10 +741,14 @@ func (os *OIDCServer) handleOIDCRefresh(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if user.AuthProvider != "oidc" || user.OIDCIssuer == "" {
+	if (user.AuthProvider != "oidc" && user.AuthProvider != scimAuthProvider) || user.OIDCIssuer == "" {
 		os.writeErr(ctx, w, http.StatusBadRequest, "not an oidc session", "")
 		return
 	}
+	if !user.IsActive {
+		os.writeErr(ctx, w, http.StatusUnauthorized, "account disabled", "")
+		return
+	}
 
 	// Find the provider by issuer.
 	provCfg, err := os.oidcStore.GetProviderByIssuer(ctx, user.OIDCIssuer)
@@ -729,11 +757,199 @@ func (os *OIDCServer) handleOIDCRefresh(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Return redirect URL for frontend iframe refresh.
+	// With a refresh token the session is renewed here, re-evaluating the
+	// user's role against fresh claims.
+	if enc := session.Metadata[oidcRefreshTokenKey]; enc != "" && provCfg.Enabled {
+		if renewed, ok := os.refreshSession(ctx, provCfg, session, user, enc); ok {
+			setOIDCSessionCookie(w, r, renewed)
+			expiresAt := renewed.ExpiresAt
+			writeJSON(w, http.StatusOK, oidcRefreshResponse{
+				Refreshed:        true,
+				Role:             string(renewed.Role),
+				SessionExpiresAt: &expiresAt,
+			})
+			return
+		}
+	}
+
+	// Otherwise return a redirect URL for frontend iframe refresh.
 	redirectURL := fmt.Sprintf("/api/v1/auth/oidc/login?provider_id=%s&prompt=none", provCfg.ID)
-	writeJSON(w, http.StatusOK, struct {
-		RedirectURL string `json:"redirect_url"`
-	}{RedirectURL: redirectURL})
+	writeJSON(w, http.StatusOK, oidcRefreshResponse{RedirectURL: redirectURL})
+}
+
+// oidcRefreshTokenKey is the session metadata key holding the encrypted
+// refresh token.
+const oidcRefreshTokenKey = "oidc_refresh_token"
+
+type oidcRefreshResponse struct {
+	RedirectURL      string     `json:"redirect_url,omitempty"`
+	Refreshed        bool       `json:"refreshed,omitempty"`
+	Role             string     `json:"role,omitempty"`
+	SessionExpiresAt *time.Time `json:"session_expires_at,omitempty"`
+}
+
+// refreshSession redeems the session's refresh token, syncs the user's role
+// from the new claims and replaces the session. It reports false when the
+// session cannot be renewed this way, so the caller falls back to an
+// interactive refresh.
+func (os *OIDCServer) refreshSession(ctx context.Context, provCfg *domain.OIDCProvider, session *auth.Session, user *auth.User, encRefreshToken string) (*auth.Session, bool) {
+	refreshToken, err := oidc.Decrypt(encRefreshToken, os.encryptionKey)
+	if err != nil {
+		return nil, false
+	}
+	clientSecret, err := oidc.Decrypt(provCfg.ClientSecretEncrypted, os.encryptionKey)
+	if err != nil {
+		return nil, false
+	}
+	prov, err := os.getOrCreateProvider(ctx, provCfg, clientSecret)
+	if err != nil {
+		return nil, false
+	}
+	tokens, err := prov.Refresh(ctx, refreshToken)
+	if err != nil {
+		os.logger.WarnContext(ctx, "oidc token refresh failed", "user", user.Username, "issuer", provCfg.IssuerURL, "error", err)
+		return nil, false
+	}
+	if tokens.Claims.Subject != user.OIDCSubject {
+		os.logger.WarnContext(ctx, "oidc token refresh returned a different subject", "user", user.Username, "issuer", provCfg.IssuerURL)
+		return nil, false
+	}
+	if tokens.RefreshToken == "" {
+		tokens.RefreshToken = refreshToken
+	}
+
+	var decision oidc.RoleDecision
+	if user.AuthProvider != scimAuthProvider {
+		decision = os.resolveRole(ctx, provCfg, tokens.Claims)
+	}
+	if os.syncRole(ctx, user, decision) {
+		_ = os.sessionStore.DeleteByUserID(ctx, user.ID)
+	} else {
+		_ = os.sessionStore.Delete(ctx, session.ID)
+	}
+
+	renewed, err := os.newOIDCSession(user, tokens.Claims.Issuer, tokens.RefreshToken)
+	if err != nil {
+		return nil, false
+	}
+	if err := os.sessionStore.Create(ctx, renewed); err != nil {
+		return nil, false
+	}
+	return renewed, true
+}
+
+// newOIDCSession creates a session for an OIDC sign-in, keeping the
+// refresh token, if any, encrypted in its metadata.
+func (os *OIDCServer) newOIDCSession(user *auth.User, issuer, refreshToken string) (*auth.Session, error) {
+	metadata := map[string]string{
+		"auth_provider": "oidc",
+		"oidc_issuer":   issuer,
+	}
+	if refreshToken != "" {
+		enc, err := oidc.Encrypt(refreshToken, os.encryptionKey)
+		if err != nil {
+			return nil, err
+		}
+		metadata[oidcRefreshTokenKey] = enc
+	}
+	return auth.NewSession(user.ID, user.Role, auth.DefaultSessionDuration, metadata)
+}
+
+func setOIDCSessionCookie(w http.ResponseWriter, r *http.Request, session *auth.Session) {
+	isSecure := r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https"
+	sameSite := http.SameSiteLaxMode
+	if isSecure {
+		sameSite = http.SameSiteStrictMode
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     "session",
+		Value:    session.ID,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   isSecure,
+		SameSite: sameSite,
+		MaxAge:   int(auth.DefaultSessionDuration.Seconds()),
+	})
+}
+
+// resolveRole maps claims onto a role using the provider's role rules and
+// group mapping, and logs the decision with the claims behind it.
+func (os *OIDCServer) resolveRole(ctx context.Context, provCfg *domain.OIDCProvider, claims *oidc.Claims) oidc.RoleDecision {
+	defaultRole := auth.NormalizeRoleName(provCfg.DefaultRole)
+	if !os.roleExists(ctx, defaultRole) {
+		defaultRole = auth.RoleNone
+	}
+	d := oidc.ResolveRole(*claims, provCfg.RoleRules, provCfg.RoleMapping, defaultRole, func(role auth.Role) bool {
+		return os.roleExists(ctx, role)
+	})
+
+	args := []any{
+		"provider", provCfg.Name,
+		"subject", claims.Subject,
+		"email", claims.Email,
+		"role", string(d.Role),
+		"source", d.Source,
+	}
+	if d.Rule != nil {
+		args = append(args, "rule_priority", d.Rule.Priority, "rule_value", d.Rule.Value)
+	}
+	if d.Claim != "" {
+		args = append(args, "claim", d.Claim, "claim_values", d.Values)
+	}
+	os.logger.InfoContext(ctx, "oidc role mapping", args...)
+	return d
+}
+
+// syncRole gives the user the role of a mapping decision and reports
+// whether it changed. SCIM users are never changed.
+func (os *OIDCServer) syncRole(ctx context.Context, user *auth.User, d oidc.RoleDecision) bool {
+	if user.AuthProvider == scimAuthProvider {
+		return false
+	}
+	if d.Role == auth.RoleNone || d.Role == user.Role {
+		return false
+	}
+
+	changes := &audit.Changes{
+		Before: map[string]any{"role": string(user.Role)},
+		After:  map[string]any{"role": string(d.Role), "source": d.Source},
+	}
+	if d.Claim != "" {
+		changes.After["claim"] = d.Claim
+		changes.After["claim_values"] = d.Values
+	}
+	user.Role = d.Role
+	user.UpdatedAt = time.Now().UTC()
+	if err := os.userStore.Update(ctx, user); err != nil {
+		return false
+	}
+	os.logOIDCAuditWithChanges(ctx, "oidc_role_sync", audit.ResourceUser, user.ID, user.Username, changes, http.StatusOK)
+	return true
+}
+
+func (os *OIDCServer) roleExists(ctx context.Context, role auth.Role) bool {
+	if role == auth.RoleNone {
+		return false
+	}
+	if os.roleStore != nil {
+		def, err := os.roleStore.GetRole(ctx, role)
+		return err == nil && def != nil
+	}
+	return auth.RoleExists(ctx, role)
+}
+
+// validateRoleRules checks that every rule is complete and names an
+// existing role.
+func (os *OIDCServer) validateRoleRules(ctx context.Context, rules []domain.OIDCRoleRule) error {
+	for i, rule := range rules {
+		if strings.TrimSpace(rule.Claim) == "" || rule.Value == "" {
+			return fmt.Errorf("rule %d: claim and value are required", i)
+		}
+		if !os.roleExists(ctx, auth.NormalizeRoleName(rule.Role)) {
+			return fmt.Errorf("rule %d: unknown role %q", i, rule.Role)
+		}
+	}
+	return nil
 }
 
 // handleListPublicProviders returns enabled OIDC providers (id and name only).
@@ -797,8 +1013,37 @@ func (os *OIDCServer) getOrCreateProvider(ctx context.Context, provCfg *domain.O
 	return prov, nil
 }
 
+// linkSCIMUser finds a SCIM-provisioned user whose username is the
+// email in claims and who has no OIDC identity yet, and links them to
+// the identity in claims. It returns nil when there is no such user.
+func (os *OIDCServer) linkSCIMUser(ctx context.Context, claims *oidc.Claims) (*auth.User, error) {
+	if claims.Email == "" {
+		return nil, nil
+	}
+	user, err := os.userStore.GetByUsername(ctx, claims.Email)
+	if err != nil || user == nil {
+		return nil, err
+	}
+	if user.AuthProvider != scimAuthProvider || user.OIDCSubject != "" {
+		return nil, nil
+	}
+	user.OIDCSubject = claims.Subject
+	user.OIDCIssuer = claims.Issuer
+	user.UpdatedAt = time.Now().UTC()
+	if err := os.userStore.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	os.logOIDCAudit(ctx, "oidc_link", audit.ResourceUser, user.ID, user.Username, http.StatusOK)
+	return user, nil
+}
+
 // logOIDCAudit logs an audit event for OIDC operations.
 func (os *OIDCServer) logOIDCAudit(ctx context.Context, action, resourceType, resourceID, resourceName string, statusCode int) {
+	os.logOIDCAuditWithChanges(ctx, action, resourceType, resourceID, resourceName, nil, statusCode)
+}
+
+// logOIDCAuditWithChanges logs an OIDC audit event with before/after state.
+func (os *OIDCServer) logOIDCAuditWithChanges(ctx context.Context, action, resourceType, resourceID, resourceName string, changes *audit.Changes, statusCode int) {
 	if os.auditLogger == nil {
 		return
 	}
@@ -810,6 +1055,7 @@ func (os *OIDCServer) logOIDCAudit(ctx context.Context, action, resourceType, re
 		ResourceType: resourceType,
 		ResourceID:   resourceID,
 		ResourceName: resourceName,
+		Changes:      changes,
 		StatusCode:   statusCode,
 	}
 
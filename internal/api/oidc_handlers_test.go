@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -29,6 +30,17 @@ type testOIDCEnv struct {
 	privKey       *rsa.PrivateKey
 	providerID    string
 	encryptionKey []byte
+
+	mu            sync.Mutex
+	groups        []string // groups claim issued by the mock IdP
+	refreshGrants int      // refresh_token grants the mock IdP has served
+}
+
+// setGroups changes the groups claim of tokens issued from now on.
+func (env *testOIDCEnv) setGroups(groups ...string) {
+	env.mu.Lock()
+	defer env.mu.Unlock()
+	env.groups = groups
 }
 
 // setupOIDCTestEnv creates a test environment with a mock OIDC IdP server,
@@ -42,6 +54,7 @@ func setupOIDCTestEnv(t *testing.T) *testOIDCEnv {
 	}
 
 	var idpSrv *httptest.Server
+	env := &testOIDCEnv{groups: []string{"cloudpam-admins", "developers"}}
 
 	idpMux := http.NewServeMux()
 	idpMux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
@@ -91,11 +104,16 @@ func setupOIDCTestEnv(t *testing.T) *testOIDCEnv {
 			Expiry:    jwt.NewNumericDate(now.Add(time.Hour)),
 			NotBefore: jwt.NewNumericDate(now.Add(-time.Minute)),
 		}
+		env.mu.Lock()
+		if r.FormValue("grant_type") == "refresh_token" {
+			env.refreshGrants++
+		}
 		extraClaims := map[string]interface{}{
 			"email":  "alice@example.com",
 			"name":   "Alice",
-			"groups": []string{"cloudpam-admins", "developers"},
+			"groups": env.groups,
 		}
+		env.mu.Unlock()
 
 		rawJWT, err := jwt.Signed(signer).Claims(claims).Claims(extraClaims).Serialize()
 		if err != nil {
@@ -104,10 +122,11 @@ func setupOIDCTestEnv(t *testing.T) *testOIDCEnv {
 		}
 
 		tokenResponse := map[string]interface{}{
-			"access_token": "mock-access-token",
-			"token_type":   "Bearer",
-			"id_token":     rawJWT,
-			"expires_in":   3600,
+			"access_token":  "mock-access-token",
+			"token_type":    "Bearer",
+			"id_token":      rawJWT,
+			"refresh_token": "mock-refresh-token",
+			"expires_in":    3600,
 		}
 		w.Header().Set("Content-Type", "application/json")
 		_ = json.NewEncoder(w).Encode(tokenResponse)
@@ -160,13 +179,12 @@ func setupOIDCTestEnv(t *testing.T) *testOIDCEnv {
 	oidcSrv := NewOIDCServer(srv, oidcStore, sessionStore, userStore, settingsStore, encKey, "http://localhost:8080/api/v1/auth/oidc/callback")
 	oidcSrv.RegisterOIDCRoutes(nil)
 
-	return &testOIDCEnv{
-		oidcServer:    oidcSrv,
-		oidcSrv:       idpSrv,
-		privKey:       privKey,
-		providerID:    providerID,
-		encryptionKey: encKey,
-	}
+	env.oidcServer = oidcSrv
+	env.oidcSrv = idpSrv
+	env.privKey = privKey
+	env.providerID = providerID
+	env.encryptionKey = encKey
+	return env
 }
 
 func TestOIDCLogin_RedirectsToIdP(t *testing.T) {
@@ -427,6 +445,63 @@ func TestOIDCCallback_ExistingUser_SessionCreated(t *testing.T) {
 	}
 }
 
+func TestOIDCCallback_LinksSCIMUser(t *testing.T) {
+	env := setupOIDCTestEnv(t)
+
+	// A user provisioned by SCIM has no OIDC identity until first sign-in.
+	scimUser := &auth.User{
+		ID:           "scim-user-id",
+		Username:     "alice@example.com",
+		Email:        "alice@example.com",
+		Role:         auth.RoleOperator,
+		IsActive:     true,
+		CreatedAt:    time.Now().UTC(),
+		UpdatedAt:    time.Now().UTC(),
+		AuthProvider: scimAuthProvider,
+	}
+	if err := env.oidcServer.userStore.Create(context.Background(), scimUser); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	loginReq := httptest.NewRequest(http.MethodGet, "/api/v1/auth/oidc/login?provider_id="+env.providerID, nil)
+	loginRec := httptest.NewRecorder()
+	env.oidcServer.mux.ServeHTTP(loginRec, loginReq)
+
+	var stateCookie *http.Cookie
+	for _, c := range loginRec.Result().Cookies() {
+		if c.Name == "oidc_state" {
+			stateCookie = c
+			break
+		}
+	}
+	if stateCookie == nil {
+		t.Fatal("missing oidc_state cookie")
+	}
+
+	callbackURL := fmt.Sprintf("/api/v1/auth/oidc/callback?code=mock-auth-code&state=%s", stateCookie.Value)
+	callbackReq := httptest.NewRequest(http.MethodGet, callbackURL, nil)
+	callbackReq.AddCookie(stateCookie)
+	callbackRec := httptest.NewRecorder()
+	env.oidcServer.mux.ServeHTTP(callbackRec, callbackReq)
+
+	if callbackRec.Code != http.StatusFound {
+		t.Fatalf("expected 302, got %d: %s", callbackRec.Code, callbackRec.Body.String())
+	}
+
+	users, _ := env.oidcServer.userStore.List(context.Background())
+	if len(users) != 1 {
+		t.Fatalf("expected the SCIM user to be linked, not a new user; got %d users", len(users))
+	}
+	linked, _ := env.oidcServer.userStore.GetByID(context.Background(), scimUser.ID)
+	if linked.OIDCSubject != "user-123" || linked.OIDCIssuer != env.oidcSrv.URL {
+		t.Errorf("identity = %q/%q, want user-123/%s", linked.OIDCSubject, linked.OIDCIssuer, env.oidcSrv.URL)
+	}
+	// SCIM owns the role; group claims must not override it.
+	if linked.Role != auth.RoleOperator {
+		t.Errorf("expected role to stay operator, got %s", linked.Role)
+	}
+}
+
 func TestOIDCCallback_InvalidState(t *testing.T) {
 	env := setupOIDCTestEnv(t)
 
@@ -618,3 +693,159 @@ func TestOIDCCallback_AutoProvisionDisabled(t *testing.T) {
 		t.Errorf("expected 'user not provisioned', got %q", resp.Error)
 	}
 }
+
+// loginViaOIDC runs the login and callback flow against the mock IdP and
+// returns the session cookie.
+func loginViaOIDC(t *testing.T, env *testOIDCEnv) *http.Cookie {
+	t.Helper()
+	loginRec := httptest.NewRecorder()
+	env.oidcServer.mux.ServeHTTP(loginRec, httptest.NewRequest(http.MethodGet, "/api/v1/auth/oidc/login?provider_id="+env.providerID, nil))
+	var stateCookie *http.Cookie
+	for _, c := range loginRec.Result().Cookies() {
+		if c.Name == "oidc_state" {
+			stateCookie = c
+		}
+	}
+	if stateCookie == nil {
+		t.Fatal("missing oidc_state cookie")
+	}
+
+	callbackReq := httptest.NewRequest(http.MethodGet, "/api/v1/auth/oidc/callback?code=mock-auth-code&state="+stateCookie.Value, nil)
+	callbackReq.AddCookie(stateCookie)
+	callbackRec := httptest.NewRecorder()
+	env.oidcServer.mux.ServeHTTP(callbackRec, callbackReq)
+	if callbackRec.Code != http.StatusFound {
+		t.Fatalf("callback: expected 302, got %d: %s", callbackRec.Code, callbackRec.Body.String())
+	}
+	for _, c := range callbackRec.Result().Cookies() {
+		if c.Name == "session" {
+			return c
+		}
+	}
+	t.Fatal("expected session cookie")
+	return nil
+}
+
+func TestOIDCCallback_RoleRulesMapCustomRole(t *testing.T) {
+	env := setupOIDCTestEnv(t)
+	ctx := context.Background()
+
+	roleStore := auth.NewMemoryRoleStore(env.oidcServer.userStore)
+	if err := roleStore.CreateRole(ctx, &auth.RoleDefinition{Name: "network-engineer", Permissions: []auth.Permission{{Resource: auth.ResourcePools, Action: auth.ActionRead}}}); err != nil {
+		t.Fatalf("create role: %v", err)
+	}
+	env.oidcServer.SetRoleStore(roleStore)
+
+	provider, _ := env.oidcServer.oidcStore.GetProvider(ctx, env.providerID)
+	provider.RoleRules = []domain.OIDCRoleRule{
+		{Claim: "email", Value: "alice@example.com", Role: "auditor", Priority: 20},
+		{Claim: "groups", Value: "developers", Role: "network-engineer", Priority: 10},
+	}
+	if err := env.oidcServer.oidcStore.UpdateProvider(ctx, provider); err != nil {
+		t.Fatalf("update provider: %v", err)
+	}
+
+	cookie := loginViaOIDC(t, env)
+	session, _ := env.oidcServer.sessionStore.Get(ctx, cookie.Value)
+	if session == nil || session.Role != "network-engineer" {
+		t.Fatalf("session = %+v, want role network-engineer from the first rule", session)
+	}
+}
+
+func TestOIDCRefresh_ResyncsRoleWithRefreshToken(t *testing.T) {
+	env := setupOIDCTestEnv(t)
+	ctx := context.Background()
+
+	cookie := loginViaOIDC(t, env)
+	session, _ := env.oidcServer.sessionStore.Get(ctx, cookie.Value)
+	if session.Role != auth.RoleAdmin {
+		t.Fatalf("initial role = %s, want admin", session.Role)
+	}
+	if session.Metadata[oidcRefreshTokenKey] == "" || session.Metadata[oidcRefreshTokenKey] == "mock-refresh-token" {
+		t.Fatalf("refresh token not stored encrypted: %q", session.Metadata[oidcRefreshTokenKey])
+	}
+
+	// The user leaves the admin group at the IdP.
+	env.setGroups("developers")
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/oidc/refresh", nil)
+	req.AddCookie(cookie)
+	rec := httptest.NewRecorder()
+	env.oidcServer.mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("refresh: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp oidcRefreshResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !resp.Refreshed || resp.Role != string(auth.RoleViewer) || resp.RedirectURL != "" {
+		t.Fatalf("response = %+v, want refreshed as viewer", resp)
+	}
+	env.mu.Lock()
+	grants := env.refreshGrants
+	env.mu.Unlock()
+	if grants != 1 {
+		t.Errorf("refresh grants = %d, want 1", grants)
+	}
+
+	if old, _ := env.oidcServer.sessionStore.Get(ctx, cookie.Value); old != nil {
+		t.Error("old session should be ended after a downgrade")
+	}
+	var renewed *http.Cookie
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == "session" {
+			renewed = c
+		}
+	}
+	if renewed == nil {
+		t.Fatal("expected a new session cookie")
+	}
+	if s, _ := env.oidcServer.sessionStore.Get(ctx, renewed.Value); s == nil || s.Role != auth.RoleViewer {
+		t.Errorf("renewed session = %+v, want viewer", s)
+	}
+	users, _ := env.oidcServer.userStore.List(ctx)
+	if len(users) != 1 || users[0].Role != auth.RoleViewer {
+		t.Errorf("user role not downgraded: %+v", users)
+	}
+}
+
+func TestOIDCRefresh_WithoutRefreshTokenReturnsRedirect(t *testing.T) {
+	env := setupOIDCTestEnv(t)
+	ctx := context.Background()
+
+	user := &auth.User{
+		ID: "u1", Username: "alice@example.com", Role: auth.RoleViewer, IsActive: true,
+		AuthProvider: "oidc", OIDCSubject: "user-123", OIDCIssuer: env.oidcSrv.URL,
+	}
+	_ = env.oidcServer.userStore.Create(ctx, user)
+	session, _ := auth.NewSession(user.ID, user.Role, time.Hour, nil)
+	_ = env.oidcServer.sessionStore.Create(ctx, session)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/oidc/refresh", nil)
+	req.AddCookie(&http.Cookie{Name: "session", Value: session.ID})
+	rec := httptest.NewRecorder()
+	env.oidcServer.mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("refresh: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp oidcRefreshResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+	if resp.Refreshed || !strings.Contains(resp.RedirectURL, "prompt=none") {
+		t.Errorf("response = %+v, want a silent login redirect", resp)
+	}
+}
+
+func TestAdminCreateProvider_RejectsUnknownRuleRole(t *testing.T) {
+	env := setupOIDCTestEnv(t)
+	env.oidcServer.RegisterOIDCAdminRoutesNoAuth()
+
+	body := `{"name":"Okta","issuer_url":"https://okta.example.com","client_id":"c","client_secret":"s",
+		"role_rules":[{"claim":"groups","value":"netops","role":"no-such-role","priority":1}]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/settings/oidc/providers", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	env.oidcServer.mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/google/uuid"
 
+	"cloudpam/internal/audit"
 	"cloudpam/internal/auth"
 	"cloudpam/internal/domain"
 	"cloudpam/internal/planning"
@@ -67,10 +68,29 @@ type openAPILoginRequest struct {
 	Password string `json:"password"`
 }
 
+// openAPILoginResponse documents both login outcomes: a session, or for
+// users who need a second factor, an MFA challenge token.
 type openAPILoginResponse struct {
-	User        *auth.User `json:"user"`
-	ExpiresAt   time.Time  `json:"expires_at"`
-	Permissions []string   `json:"permissions"`
+	User                  *auth.User `json:"user,omitempty"`
+	ExpiresAt             time.Time  `json:"expires_at"`
+	Permissions           []string   `json:"permissions,omitempty"`
+	MFARequired           bool       `json:"mfa_required,omitempty"`
+	MFAEnrollmentRequired bool       `json:"mfa_enrollment_required,omitempty"`
+	MFAToken              string     `json:"mfa_token,omitempty"`
+}
+
+type openAPILoginMFARequest struct {
+	MFAToken     string `json:"mfa_token"`
+	Code         string `json:"code,omitempty"`
+	RecoveryCode string `json:"recovery_code,omitempty"`
+}
+
+type openAPIMFATokenRequest struct {
+	MFAToken string `json:"mfa_token"`
+}
+
+type openAPIMFAVerifyRequest struct {
+	Code string `json:"code"`
 }
 
 type openAPIMeResponse struct {
@@ -185,6 +205,71 @@ type openAPISyncRequest struct {
 	AllAgents bool   `json:"all_agents,omitempty"`
 }
 
+type openAPISCIMUserListResponse struct {
+	Schemas      []string   `json:"schemas"`
+	TotalResults int        `json:"totalResults"`
+	StartIndex   int        `json:"startIndex"`
+	ItemsPerPage int        `json:"itemsPerPage"`
+	Resources    []scimUser `json:"Resources"`
+}
+
+type openAPISCIMGroupListResponse struct {
+	Schemas      []string    `json:"schemas"`
+	TotalResults int         `json:"totalResults"`
+	StartIndex   int         `json:"startIndex"`
+	ItemsPerPage int         `json:"itemsPerPage"`
+	Resources    []scimGroup `json:"Resources"`
+}
+
+type openAPIWebhookListResponse struct {
+	Items []domain.Webhook `json:"items"`
+}
+
+type openAPIWebhookCreateRequest struct {
+	Name    string                    `json:"name"`
+	URL     string                    `json:"url"`
+	Secret  string                    `json:"secret,omitempty"`
+	Events  []domain.WebhookEventType `json:"events,omitempty"`
+	Enabled *bool                     `json:"enabled,omitempty"`
+}
+
+// openAPIWebhookSecretResponse spells out webhookSecretResponse: embedding
+// the Webhook component would collapse to a $ref and hide the secret field.
+type openAPIWebhookSecretResponse struct {
+	ID        string                    `json:"id"`
+	Name      string                    `json:"name"`
+	URL       string                    `json:"url"`
+	Events    []domain.WebhookEventType `json:"events"`
+	Enabled   bool                      `json:"enabled"`
+	CreatedAt time.Time                 `json:"created_at"`
+	UpdatedAt time.Time                 `json:"updated_at"`
+	Secret    string                    `json:"secret,omitempty"`
+}
+
+// openAPIDiscoverySchedule spells out DiscoveryScheduleView, which embeds
+// DiscoverySchedule and would otherwise collapse to a $ref.
+type openAPIDiscoverySchedule struct {
+	AccountID     int64      `json:"account_id"`
+	AccountKey    string     `json:"account_key"`
+	AccountName   string     `json:"account_name"`
+	Provider      string     `json:"provider,omitempty"`
+	Enabled       bool       `json:"enabled"`
+	Interval      string     `json:"interval,omitempty"`
+	Cron          string     `json:"cron,omitempty"`
+	DetectDrift   bool       `json:"detect_drift"`
+	NextRunAt     *time.Time `json:"next_run_at,omitempty"`
+	LastRunAt     *time.Time `json:"last_run_at,omitempty"`
+	LastStatus    string     `json:"last_status,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+	LastSyncJobID *uuid.UUID `json:"last_sync_job_id,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+type openAPIDiscoveryScheduleListResponse struct {
+	Items []openAPIDiscoverySchedule `json:"items"`
+}
+
 type openAPIOIDCProvidersResponse struct {
 	Providers []domain.OIDCProvider `json:"providers"`
 }
@@ -205,7 +290,10 @@ type openAPIOIDCProviderTestResponse struct {
 }
 
 type openAPIOIDCRefreshResponse struct {
-	RedirectURL string `json:"redirect_url"`
+	RedirectURL      string     `json:"redirect_url,omitempty"`
+	Refreshed        bool       `json:"refreshed,omitempty"`
+	Role             string     `json:"role,omitempty"`
+	SessionExpiresAt *time.Time `json:"session_expires_at,omitempty"`
 }
 
 type openAPIConversationListResponse struct {
@@ -490,6 +578,7 @@ func openAPIComponentTypes() []openAPIComponentType {
 		{"PoolWithStats", reflect.TypeOf(domain.PoolWithStats{})},
 		{"CreatePool", reflect.TypeOf(domain.CreatePool{})},
 		{"UpdatePool", reflect.TypeOf(domain.UpdatePool{})},
+		{"AllocatePool", reflect.TypeOf(domain.AllocatePool{})},
 		{"Account", reflect.TypeOf(domain.Account{})},
 		{"CreateAccount", reflect.TypeOf(domain.CreateAccount{})},
 		{"UpdateAccount", reflect.TypeOf(domain.CreateAccount{})},
@@ -514,6 +603,9 @@ func openAPIComponentTypes() []openAPIComponentType {
 		{"BulkIngestResponse", reflect.TypeOf(domain.BulkIngestResponse{})},
 		{"DiscoveryAgent", reflect.TypeOf(domain.DiscoveryAgent{})},
 		{"DiscoveryAgentsResponse", reflect.TypeOf(domain.DiscoveryAgentsResponse{})},
+		{"DiscoverySchedule", reflect.TypeOf(openAPIDiscoverySchedule{})},
+		{"DiscoveryScheduleListResponse", reflect.TypeOf(openAPIDiscoveryScheduleListResponse{})},
+		{"UpsertDiscoveryScheduleRequest", reflect.TypeOf(domain.UpsertDiscoveryScheduleRequest{})},
 		{"AgentProvisionRequest", reflect.TypeOf(domain.AgentProvisionRequest{})},
 		{"AgentProvisionResponse", reflect.TypeOf(domain.AgentProvisionResponse{})},
 		{"AgentRegisterRequest", reflect.TypeOf(domain.AgentRegisterRequest{})},
@@ -543,6 +635,10 @@ func openAPIComponentTypes() []openAPIComponentType {
 		{"NetworkSchemaPolicy", reflect.TypeOf(domain.NetworkSchemaPolicy{})},
 		{"LoginRequest", reflect.TypeOf(openAPILoginRequest{})},
 		{"LoginResponse", reflect.TypeOf(openAPILoginResponse{})},
+		{"LoginMFARequest", reflect.TypeOf(openAPILoginMFARequest{})},
+		{"MFATokenRequest", reflect.TypeOf(openAPIMFATokenRequest{})},
+		{"MFAVerifyRequest", reflect.TypeOf(openAPIMFAVerifyRequest{})},
+		{"MFAEnrollmentResponse", reflect.TypeOf(mfaEnrollmentResponse{})},
 		{"MeResponse", reflect.TypeOf(openAPIMeResponse{})},
 		{"User", reflect.TypeOf(auth.User{})},
 		{"UserListResponse", reflect.TypeOf(openAPIUserListResponse{})},
@@ -576,6 +672,45 @@ func openAPIComponentTypes() []openAPIComponentType {
 		{"UpgradeRequestResponse", reflect.TypeOf(openAPIUpgradeRequestResponse{})},
 		{"UpgradeStatusResponse", reflect.TypeOf(openAPIUpgradeStatusResponse{})},
 		{"UpgradeStatusAckResponse", reflect.TypeOf(openAPIUpgradeStatusAckResponse{})},
+		{"Webhook", reflect.TypeOf(domain.Webhook{})},
+		{"WebhookListResponse", reflect.TypeOf(openAPIWebhookListResponse{})},
+		{"WebhookCreateRequest", reflect.TypeOf(openAPIWebhookCreateRequest{})},
+		{"WebhookSecretResponse", reflect.TypeOf(openAPIWebhookSecretResponse{})},
+		{"WebhookDelivery", reflect.TypeOf(domain.WebhookDelivery{})},
+		{"WebhookDeliveryListResponse", reflect.TypeOf(domain.WebhookDeliveryListResponse{})},
+		{"SCIMUser", reflect.TypeOf(scimUser{})},
+		{"SCIMUserListResponse", reflect.TypeOf(openAPISCIMUserListResponse{})},
+		{"SCIMGroup", reflect.TypeOf(scimGroup{})},
+		{"SCIMGroupListResponse", reflect.TypeOf(openAPISCIMGroupListResponse{})},
+		{"SCIMPatchRequest", reflect.TypeOf(scimPatchRequest{})},
+		{"IPAddress", reflect.TypeOf(domain.IPAddress{})},
+		{"IPAddressListResponse", reflect.TypeOf(domain.IPAddressListResponse{})},
+		{"CreateIPAddress", reflect.TypeOf(domain.CreateIPAddress{})},
+		{"AllocateIPAddress", reflect.TypeOf(domain.AllocateIPAddress{})},
+		{"UpdateIPAddress", reflect.TypeOf(domain.UpdateIPAddress{})},
+		{"UtilizationHistory", reflect.TypeOf(planning.UtilizationHistory{})},
+		{"UtilizationForecast", reflect.TypeOf(planning.UtilizationForecast{})},
+		{"UtilizationForecastReport", reflect.TypeOf(planning.UtilizationForecastReport{})},
+		{"Alert", reflect.TypeOf(domain.Alert{})},
+		{"AlertListResponse", reflect.TypeOf(domain.AlertListResponse{})},
+		{"AlertEvaluationResponse", reflect.TypeOf(domain.AlertEvaluationResponse{})},
+		{"AlertSettings", reflect.TypeOf(domain.AlertSettings{})},
+		{"ComplianceRule", reflect.TypeOf(domain.ComplianceRule{})},
+		{"ComplianceRuleListResponse", reflect.TypeOf(domain.ComplianceRuleListResponse{})},
+		{"ChangeSet", reflect.TypeOf(domain.ChangeSet{})},
+		{"ChangeProposal", reflect.TypeOf(domain.ChangeProposal{})},
+		{"ChangeProposalListResponse", reflect.TypeOf(domain.ChangeProposalListResponse{})},
+		{"ChangeProposalReview", reflect.TypeOf(reviewRequest{})},
+		{"AuditRetentionSettings", reflect.TypeOf(domain.AuditRetentionSettings{})},
+		{"ApprovalSettings", reflect.TypeOf(domain.ApprovalSettings{})},
+		{"PoolChangeRequest", reflect.TypeOf(domain.PoolChangeRequest{})},
+		{"PoolChangeRequestListResponse", reflect.TypeOf(domain.PoolChangeRequestListResponse{})},
+		{"Reservation", reflect.TypeOf(domain.Reservation{})},
+		{"ReservationListResponse", reflect.TypeOf(domain.ReservationListResponse{})},
+		{"CreateReservation", reflect.TypeOf(domain.CreateReservation{})},
+		{"UpdateReservation", reflect.TypeOf(domain.UpdateReservation{})},
+		{"AuditStats", reflect.TypeOf(audit.AuditStats{})},
+		{"AuditVerifyResult", reflect.TypeOf(audit.VerifyResult{})},
 	}
 	sort.Slice(types, func(i, j int) bool { return types[i].name < types[j].name })
 	return types
@@ -750,6 +885,7 @@ func openAPIRoutesForPattern(pattern string) []openAPIRoute {
 			{http.MethodDelete, "/api/v1/pools/{poolId}"},
 			{http.MethodGet, "/api/v1/pools/{poolId}/blocks"},
 			{http.MethodGet, "/api/v1/pools/{poolId}/stats"},
+			{http.MethodPost, "/api/v1/pools/{poolId}/allocate"},
 		})
 	case "/api/v1/accounts":
 		return routesForMethodsPath([]string{http.MethodGet, http.MethodPost}, "/api/v1/accounts")
@@ -891,6 +1027,7 @@ func openAPIRoutesForPattern(pattern string) []openAPIRoute {
 			{http.MethodPatch, "/api/v1/auth/users/{userId}/password"},
 			{http.MethodPost, "/api/v1/auth/users/{userId}/revoke-sessions"},
 			{http.MethodPost, "/api/v1/auth/users/{userId}/unlock"},
+			{http.MethodPost, "/api/v1/auth/users/{userId}/mfa/reset"},
 		})
 	case "/api/v1/auth/keys":
 		return routesForMethodsPath([]string{http.MethodGet, http.MethodPost}, "/api/v1/auth/keys")
@@ -970,6 +1107,54 @@ func splitMethodPattern(pattern string) (string, string, bool) {
 		path = "/api/v1/settings/oidc/providers/{providerId}"
 	case "/api/v1/settings/oidc/providers/{id}/test":
 		path = "/api/v1/settings/oidc/providers/{providerId}/test"
+	case "/api/v1/pools/{id}/addresses":
+		path = "/api/v1/pools/{poolId}/addresses"
+	case "/api/v1/pools/{id}/addresses/allocate":
+		path = "/api/v1/pools/{poolId}/addresses/allocate"
+	case "/api/v1/ip-addresses/{id}":
+		path = "/api/v1/ip-addresses/{ipAddressId}"
+	case "/api/v1/pools/{id}/utilization/history":
+		path = "/api/v1/pools/{poolId}/utilization/history"
+	case "/api/v1/pools/{id}/utilization/forecast":
+		path = "/api/v1/pools/{poolId}/utilization/forecast"
+	case "/api/v1/discovery/schedules/{id}":
+		path = "/api/v1/discovery/schedules/{accountId}"
+	case "/api/v1/webhooks/{id}":
+		path = "/api/v1/webhooks/{webhookId}"
+	case "/api/v1/webhooks/{id}/deliveries":
+		path = "/api/v1/webhooks/{webhookId}/deliveries"
+	case "/api/v1/webhooks/{id}/test":
+		path = "/api/v1/webhooks/{webhookId}/test"
+	case "/scim/v2/Users/{id}":
+		path = "/scim/v2/Users/{userId}"
+	case "/scim/v2/Groups/{id}":
+		path = "/scim/v2/Groups/{groupId}"
+	case "/api/v1/alerts/{id}":
+		path = "/api/v1/alerts/{alertId}"
+	case "/api/v1/alerts/{id}/acknowledge":
+		path = "/api/v1/alerts/{alertId}/acknowledge"
+	case "/api/v1/alerts/{id}/resolve":
+		path = "/api/v1/alerts/{alertId}/resolve"
+	case "/api/v1/settings/alerts/channels/{id}/test":
+		path = "/api/v1/settings/alerts/channels/{channelId}/test"
+	case "/api/v1/analysis/rules/{id}":
+		path = "/api/v1/analysis/rules/{ruleId}"
+	case "/api/v1/change-proposals/{id}":
+		path = "/api/v1/change-proposals/{proposalId}"
+	case "/api/v1/change-proposals/{id}/approve":
+		path = "/api/v1/change-proposals/{proposalId}/approve"
+	case "/api/v1/change-proposals/{id}/reject":
+		path = "/api/v1/change-proposals/{proposalId}/reject"
+	case "/api/v1/change-requests/{id}":
+		path = "/api/v1/change-requests/{changeRequestId}"
+	case "/api/v1/change-requests/{id}/approve":
+		path = "/api/v1/change-requests/{changeRequestId}/approve"
+	case "/api/v1/change-requests/{id}/reject":
+		path = "/api/v1/change-requests/{changeRequestId}/reject"
+	case "/api/v1/reservations/{id}":
+		path = "/api/v1/reservations/{reservationId}"
+	case "/api/v1/reservations/{id}/release":
+		path = "/api/v1/reservations/{reservationId}/release"
 	}
 	switch parts[0] {
 	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
@@ -1032,25 +1217,26 @@ func openAPIOperationCatalog() map[string]openAPIRoute {
 		{Method: "GET", Path: "/api/v1/system/info", Summary: "Get system metadata", Tag: "System", ResponseSchema: "SystemInfoResponse"},
 		{Method: "GET", Path: "/api/v1/system/changelog", Summary: "Get changelog markdown", Tag: "System", ResponseSchema: "String", ResponseContentType: "text/markdown"},
 		{Method: "POST", Path: "/api/v1/auth/setup", Summary: "Create first admin account", Tag: "Auth", Security: false, RequestSchema: "SetupRequest", SuccessStatus: "201", ResponseSchema: "SetupResponse", ResponseDescription: "Initial admin account created"},
-		{Method: "GET", Path: "/api/v1/pools", Summary: "List pools", Tag: "Pools", ResponseSchema: "Object", Parameters: []openAPIParameter{queryParam("include_stats", "Include utilization statistics", "boolean")}},
-		{Method: "POST", Path: "/api/v1/pools", Summary: "Create pool", Tag: "Pools", RequestSchema: "CreatePool", SuccessStatus: "201", ResponseSchema: "Pool", ResponseDescription: "Pool created"},
+		{Method: "GET", Path: "/api/v1/pools", Summary: "List pools", Tag: "Pools", ResponseSchema: "Object", Parameters: poolListQueryParams()},
+		{Method: "POST", Path: "/api/v1/pools", Summary: "Create pool", Description: "The CIDR must not overlap a sibling pool, a block held by a pending change request, or an active reservation. When an approval policy matches, the change is stored as a pending PoolChangeRequest and the response is 202 with that request.", Tag: "Pools", RequestSchema: "CreatePool", SuccessStatus: "201", ResponseSchema: "Pool", ResponseDescription: "Pool created"},
 		{Method: "GET", Path: "/api/v1/pools/hierarchy", Summary: "Get pool hierarchy", Tag: "Pools", ResponseSchema: "Object", Parameters: []openAPIParameter{queryParam("root_id", "Optional root pool ID", "integer")}},
-		{Method: "GET", Path: "/api/v1/pools/{poolId}", Summary: "Get pool", Tag: "Pools", ResponseSchema: "Pool"},
-		{Method: "PATCH", Path: "/api/v1/pools/{poolId}", Summary: "Update pool metadata", Tag: "Pools", RequestSchema: "UpdatePool", ResponseSchema: "Pool"},
-		{Method: "DELETE", Path: "/api/v1/pools/{poolId}", Summary: "Delete pool", Tag: "Pools", ResponseDescription: "Pool deleted", Parameters: []openAPIParameter{queryParam("force", "Force recursive delete where supported", "boolean")}},
+		{Method: "GET", Path: "/api/v1/pools/{poolId}", Summary: "Get pool", Description: "The ETag header carries the pool version for use with If-Match.", Tag: "Pools", ResponseSchema: "Pool"},
+		{Method: "PATCH", Path: "/api/v1/pools/{poolId}", Summary: "Update pool metadata", Description: "When an approval policy matches, the change is stored as a pending PoolChangeRequest and the response is 202 with that request.", Tag: "Pools", RequestSchema: "UpdatePool", ResponseSchema: "Pool", Parameters: []openAPIParameter{ifMatchParam()}},
+		{Method: "DELETE", Path: "/api/v1/pools/{poolId}", Summary: "Delete pool", Description: "When an approval policy matches, the change is stored as a pending PoolChangeRequest and the response is 202 with that request.", Tag: "Pools", ResponseDescription: "Pool deleted", Parameters: []openAPIParameter{queryParam("force", "Force recursive delete where supported", "boolean"), ifMatchParam()}},
 		{Method: "GET", Path: "/api/v1/pools/{poolId}/blocks", Summary: "Enumerate candidate blocks", Tag: "Blocks", ResponseSchema: "Object", Parameters: []openAPIParameter{queryParam("new_prefix_len", "Requested block prefix length", "integer"), queryParam("page", "Page number", "integer"), queryParam("page_size", "Page size, or \"all\". Omitting it (or passing \"all\") expands the whole pool, which is rejected with 400 above 65536 blocks; paginate instead.", "integer")}},
 		{Method: "GET", Path: "/api/v1/pools/{poolId}/stats", Summary: "Get pool utilization statistics", Tag: "Pools", ResponseSchema: "PoolStats"},
-		{Method: "GET", Path: "/api/v1/accounts", Summary: "List accounts", Tag: "Accounts", ResponseSchema: "Object"},
+		{Method: "POST", Path: "/api/v1/pools/{poolId}/allocate", Summary: "Allocate the next free child pool", Description: "Blocks held by pending change requests or active reservations are skipped. When an approval policy matches, the change is stored as a pending PoolChangeRequest and the response is 202 with that request.", Tag: "Pools", RequestSchema: "AllocatePool", SuccessStatus: "201", ResponseSchema: "Pool", ResponseDescription: "Child pool allocated"},
+		{Method: "GET", Path: "/api/v1/accounts", Summary: "List accounts", Tag: "Accounts", ResponseSchema: "Object", Parameters: accountListQueryParams()},
 		{Method: "POST", Path: "/api/v1/accounts", Summary: "Create account", Tag: "Accounts", RequestSchema: "CreateAccount", SuccessStatus: "201", ResponseSchema: "Account", ResponseDescription: "Account created"},
-		{Method: "GET", Path: "/api/v1/accounts/{accountId}", Summary: "Get account", Tag: "Accounts", ResponseSchema: "Account"},
-		{Method: "PATCH", Path: "/api/v1/accounts/{accountId}", Summary: "Update account", Tag: "Accounts", RequestSchema: "UpdateAccount", ResponseSchema: "Account"},
-		{Method: "DELETE", Path: "/api/v1/accounts/{accountId}", Summary: "Delete account", Tag: "Accounts", ResponseDescription: "Account deleted"},
+		{Method: "GET", Path: "/api/v1/accounts/{accountId}", Summary: "Get account", Description: "The ETag header carries the account version for use with If-Match.", Tag: "Accounts", ResponseSchema: "Account"},
+		{Method: "PATCH", Path: "/api/v1/accounts/{accountId}", Summary: "Update account", Tag: "Accounts", RequestSchema: "UpdateAccount", ResponseSchema: "Account", Parameters: []openAPIParameter{ifMatchParam()}},
+		{Method: "DELETE", Path: "/api/v1/accounts/{accountId}", Summary: "Delete account", Tag: "Accounts", ResponseDescription: "Account deleted", Parameters: []openAPIParameter{queryParam("force", "Also delete the account's pools", "boolean"), ifMatchParam()}},
 		{Method: "GET", Path: "/api/v1/blocks", Summary: "List assigned blocks", Tag: "Blocks", ResponseSchema: "Object", Parameters: []openAPIParameter{queryParam("q", "Search query", "string"), queryParam("pool_id", "Pool ID", "integer"), queryParam("account_id", "Account ID", "integer"), queryParam("page", "Page number", "integer"), queryParam("page_size", "Page size", "integer")}},
 		{Method: "GET", Path: "/api/v1/export", Summary: "Export pools and accounts as CSV ZIP", Tag: "Export", ResponseSchema: "String", ResponseContentType: "application/zip"},
 		{Method: "POST", Path: "/api/v1/import/accounts", Summary: "Import accounts from CSV", Tag: "Import", RequestSchema: "Object", ResponseSchema: "ImportResponse"},
 		{Method: "POST", Path: "/api/v1/import/pools", Summary: "Import pools from CSV", Tag: "Import", RequestSchema: "Object", ResponseSchema: "ImportResponse"},
-		{Method: "POST", Path: "/api/v1/schema/check", Summary: "Check schema conflicts", Tag: "Schema", RequestSchema: "SchemaPlanRequest", ResponseSchema: "Object"},
-		{Method: "POST", Path: "/api/v1/schema/apply", Summary: "Apply schema plan", Tag: "Schema", RequestSchema: "SchemaPlanRequest", ResponseSchema: "Object"},
+		{Method: "POST", Path: "/api/v1/schema/check", Summary: "Check schema conflicts", Description: "Conflicts with active reservations carry a reservation_id.", Tag: "Schema", RequestSchema: "SchemaPlanRequest", ResponseSchema: "Object"},
+		{Method: "POST", Path: "/api/v1/schema/apply", Summary: "Apply schema plan", Description: "With dry_run the response is the ChangeSet the apply would make; with propose the plan is stored as a pending ChangeProposal (201).", Tag: "Schema", RequestSchema: "SchemaPlanRequest", ResponseSchema: "Object"},
 		{Method: "GET", Path: "/api/v1/search", Summary: "Search pools and accounts", Tag: "Search", ResponseSchema: "SearchResponse", Parameters: []openAPIParameter{queryParam("q", "Search query", "string"), queryParam("type", "Optional result type", "string")}},
 		{Method: "GET", Path: "/api/v1/discovery/resources", Summary: "List discovered resources", Tag: "Discovery", ResponseSchema: "DiscoveryResourcesResponse", Parameters: discoveryResourceQueryParams()},
 		{Method: "GET", Path: "/api/v1/discovery/resources/{resourceId}", Summary: "Get discovered resource", Tag: "Discovery", ResponseSchema: "DiscoveredResource"},
@@ -1072,6 +1258,10 @@ func openAPIOperationCatalog() map[string]openAPIRoute {
 		{Method: "POST", Path: "/api/v1/discovery/agents/heartbeat", Summary: "Record discovery agent heartbeat", Tag: "Discovery", RequestSchema: "AgentHeartbeatRequest", ResponseSchema: "AgentHeartbeatResponse"},
 		{Method: "POST", Path: "/api/v1/discovery/agents/{agentId}/approve", Summary: "Approve discovery agent", Tag: "Discovery", ResponseSchema: "AgentRegisterResponse"},
 		{Method: "POST", Path: "/api/v1/discovery/agents/{agentId}/reject", Summary: "Reject discovery agent", Tag: "Discovery", ResponseSchema: "AgentRegisterResponse"},
+		{Method: "GET", Path: "/api/v1/discovery/schedules", Summary: "List discovery schedules with next and last runs", Tag: "Discovery", ResponseSchema: "DiscoveryScheduleListResponse"},
+		{Method: "GET", Path: "/api/v1/discovery/schedules/{accountId}", Summary: "Get an account's discovery schedule", Tag: "Discovery", ResponseSchema: "DiscoverySchedule"},
+		{Method: "PUT", Path: "/api/v1/discovery/schedules/{accountId}", Summary: "Create or replace an account's discovery schedule", Tag: "Discovery", RequestSchema: "UpsertDiscoveryScheduleRequest", ResponseSchema: "DiscoverySchedule"},
+		{Method: "DELETE", Path: "/api/v1/discovery/schedules/{accountId}", Summary: "Delete an account's discovery schedule", Tag: "Discovery", SuccessStatus: "204", ResponseDescription: "Schedule deleted"},
 		{Method: "GET", Path: "/api/v1/network/flat", Summary: "Get flat network view", Tag: "Network", ResponseSchema: "Object", Parameters: networkViewQueryParams()},
 		{Method: "GET", Path: "/api/v1/network/hierarchy", Summary: "Get hierarchical network view", Tag: "Network", ResponseSchema: "Object", Parameters: networkViewQueryParams()},
 		{Method: "GET", Path: "/api/v1/network/merged", Summary: "Get merged network view", Tag: "Network", ResponseSchema: "Object", Parameters: networkViewQueryParams()},
@@ -1092,6 +1282,11 @@ func openAPIOperationCatalog() map[string]openAPIRoute {
 		{Method: "POST", Path: "/api/v1/analysis/gaps", Summary: "Run gap analysis", Tag: "Analysis", RequestSchema: "GapAnalysisRequest", ResponseSchema: "Object"},
 		{Method: "POST", Path: "/api/v1/analysis/fragmentation", Summary: "Run fragmentation analysis", Tag: "Analysis", RequestSchema: "AnalysisRequest", ResponseSchema: "Object"},
 		{Method: "POST", Path: "/api/v1/analysis/compliance", Summary: "Run compliance checks", Tag: "Analysis", RequestSchema: "AnalysisRequest", ResponseSchema: "Object"},
+		{Method: "GET", Path: "/api/v1/analysis/rules", Summary: "List custom compliance rules", Tag: "Analysis", ResponseSchema: "ComplianceRuleListResponse"},
+		{Method: "POST", Path: "/api/v1/analysis/rules", Summary: "Create custom compliance rule", Tag: "Analysis", RequestSchema: "ComplianceRule", SuccessStatus: "201", ResponseSchema: "ComplianceRule"},
+		{Method: "GET", Path: "/api/v1/analysis/rules/{ruleId}", Summary: "Get custom compliance rule", Tag: "Analysis", ResponseSchema: "ComplianceRule"},
+		{Method: "PUT", Path: "/api/v1/analysis/rules/{ruleId}", Summary: "Replace custom compliance rule", Tag: "Analysis", RequestSchema: "ComplianceRule", ResponseSchema: "ComplianceRule"},
+		{Method: "DELETE", Path: "/api/v1/analysis/rules/{ruleId}", Summary: "Delete custom compliance rule", Tag: "Analysis", SuccessStatus: "204", ResponseDescription: "Compliance rule deleted"},
 		{Method: "POST", Path: "/api/v1/recommendations/generate", Summary: "Generate recommendations", Tag: "Recommendations", RequestSchema: "GenerateRecommendationsRequest", ResponseSchema: "GenerateRecommendationsResponse"},
 		{Method: "GET", Path: "/api/v1/recommendations", Summary: "List recommendations", Tag: "Recommendations", ResponseSchema: "RecommendationsListResponse", Parameters: paginatedFilterParams("pool_id", "type", "status", "priority")},
 		{Method: "GET", Path: "/api/v1/recommendations/{recommendationId}", Summary: "Get recommendation", Tag: "Recommendations", ResponseSchema: "Recommendation"},
@@ -1106,8 +1301,16 @@ func openAPIOperationCatalog() map[string]openAPIRoute {
 		{Method: "PATCH", Path: "/api/v1/settings/security", Summary: "Update security settings", Tag: "Settings", RequestSchema: "SecuritySettings", ResponseSchema: "SecuritySettings"},
 		{Method: "GET", Path: "/api/v1/settings/network-schema-policy", Summary: "Get network schema policy", Tag: "Settings", ResponseSchema: "NetworkSchemaPolicy"},
 		{Method: "PATCH", Path: "/api/v1/settings/network-schema-policy", Summary: "Update network schema policy", Tag: "Settings", RequestSchema: "NetworkSchemaPolicy", ResponseSchema: "NetworkSchemaPolicy"},
+		{Method: "GET", Path: "/api/v1/settings/audit-retention", Summary: "Get audit log retention policy", Tag: "Settings", ResponseSchema: "AuditRetentionSettings"},
+		{Method: "PATCH", Path: "/api/v1/settings/audit-retention", Summary: "Replace audit log retention policy", Tag: "Settings", RequestSchema: "AuditRetentionSettings", ResponseSchema: "AuditRetentionSettings"},
+		{Method: "GET", Path: "/api/v1/settings/approvals", Summary: "Get pool change approval policies", Tag: "Change Requests", ResponseSchema: "ApprovalSettings"},
+		{Method: "PATCH", Path: "/api/v1/settings/approvals", Summary: "Replace pool change approval policies", Tag: "Change Requests", RequestSchema: "ApprovalSettings", ResponseSchema: "ApprovalSettings"},
 		{Method: "GET", Path: "/api/v1/auth/login", Summary: "Login", Tag: "Auth", Security: false, RequestSchema: "LoginRequest", ResponseSchema: "LoginResponse"},
-		{Method: "POST", Path: "/api/v1/auth/login", Summary: "Login", Tag: "Auth", Security: false, RequestSchema: "LoginRequest", ResponseSchema: "LoginResponse"},
+		{Method: "POST", Path: "/api/v1/auth/login", Summary: "Login", Description: "Users enrolled in MFA, or whose role requires it, receive an mfa_token to complete the login at /api/v1/auth/login/mfa instead of a session.", Tag: "Auth", Security: false, RequestSchema: "LoginRequest", ResponseSchema: "LoginResponse"},
+		{Method: "POST", Path: "/api/v1/auth/login/mfa", Summary: "Complete login with an MFA code", Description: "Accepts a TOTP code or a single-use recovery code and creates the session.", Tag: "Auth", Security: false, RequestSchema: "LoginMFARequest", ResponseSchema: "LoginResponse"},
+		{Method: "POST", Path: "/api/v1/auth/login/mfa/enroll", Summary: "Enroll in MFA during login", Description: "Issues a TOTP secret to a user who must enroll before signing in. The login completes with the first valid code.", Tag: "Auth", Security: false, RequestSchema: "MFATokenRequest", ResponseSchema: "MFAEnrollmentResponse"},
+		{Method: "POST", Path: "/api/v1/auth/mfa/enroll", Summary: "Start MFA enrollment", Description: "Issues a TOTP secret and recovery codes to the signed-in user. MFA is enabled once a code is verified.", Tag: "Auth", ResponseSchema: "MFAEnrollmentResponse"},
+		{Method: "POST", Path: "/api/v1/auth/mfa/verify", Summary: "Confirm MFA enrollment", Tag: "Auth", RequestSchema: "MFAVerifyRequest", ResponseSchema: "User"},
 		{Method: "POST", Path: "/api/v1/auth/logout", Summary: "Logout", Tag: "Auth", ResponseDescription: "Session cleared"},
 		{Method: "GET", Path: "/api/v1/auth/me", Summary: "Get current identity", Tag: "Auth", ResponseSchema: "MeResponse"},
 		{Method: "GET", Path: "/api/v1/auth/users", Summary: "List users", Tag: "Auth", ResponseSchema: "UserListResponse"},
@@ -1118,6 +1321,7 @@ func openAPIOperationCatalog() map[string]openAPIRoute {
 		{Method: "PATCH", Path: "/api/v1/auth/users/{userId}/password", Summary: "Change user password", Tag: "Auth", RequestSchema: "ChangePasswordRequest", ResponseDescription: "Password changed"},
 		{Method: "POST", Path: "/api/v1/auth/users/{userId}/revoke-sessions", Summary: "Revoke user sessions", Tag: "Auth", ResponseSchema: "StatusResponse"},
 		{Method: "POST", Path: "/api/v1/auth/users/{userId}/unlock", Summary: "Unlock user", Tag: "Auth", ResponseSchema: "User"},
+		{Method: "POST", Path: "/api/v1/auth/users/{userId}/mfa/reset", Summary: "Reset user MFA", Tag: "Auth", ResponseSchema: "User"},
 		{Method: "GET", Path: "/api/v1/auth/keys", Summary: "List API keys", Tag: "Auth", ResponseSchema: "APIKeysResponse"},
 		{Method: "POST", Path: "/api/v1/auth/keys", Summary: "Create API key", Tag: "Auth", RequestSchema: "APIKeyCreateRequest", SuccessStatus: "201", ResponseSchema: "APIKeyCreateResponse"},
 		{Method: "DELETE", Path: "/api/v1/auth/keys/{keyId}", Summary: "Revoke API key", Tag: "Auth", ResponseDescription: "API key revoked"},
@@ -1128,9 +1332,12 @@ func openAPIOperationCatalog() map[string]openAPIRoute {
 		{Method: "PATCH", Path: "/api/v1/auth/roles/{roleName}", Summary: "Update RBAC role", Tag: "Auth", RequestSchema: "RoleRequest", ResponseSchema: "Role"},
 		{Method: "DELETE", Path: "/api/v1/auth/roles/{roleName}", Summary: "Delete RBAC role", Tag: "Auth", ResponseDescription: "Role deleted"},
 		{Method: "GET", Path: "/api/v1/audit", Summary: "Query audit log", Tag: "Audit", ResponseSchema: "AuditListResponse", Parameters: []openAPIParameter{queryParam("limit", "Maximum events", "integer"), queryParam("offset", "Offset", "integer"), queryParam("actor", "Actor filter", "string"), queryParam("action", "Action filter", "string"), queryParam("resource_type", "Resource type filter", "string")}},
+		{Method: "GET", Path: "/api/v1/audit/stats", Summary: "Audit log statistics", Tag: "Audit", ResponseSchema: "AuditStats"},
+		{Method: "GET", Path: "/api/v1/audit/export", Summary: "Export audit log", Description: "Streams every matching event, oldest first, as JSON Lines, CSV or CEF.", Tag: "Audit", ResponseSchema: "String", ResponseContentType: "application/x-ndjson", Parameters: []openAPIParameter{queryParam("format", "jsonl (default), csv or cef", "string"), queryParam("since", "Start of range (RFC 3339)", "string"), queryParam("until", "End of range (RFC 3339)", "string"), queryParam("actor", "Actor filter", "string"), queryParam("action", "Action filter", "string"), queryParam("resource_type", "Resource type filter", "string"), queryParam("resource_id", "Resource ID filter", "string")}},
+		{Method: "GET", Path: "/api/v1/audit/verify", Summary: "Verify the audit hash chain", Tag: "Audit", ResponseSchema: "AuditVerifyResult", Parameters: []openAPIParameter{queryParam("since", "Start of range (RFC 3339)", "string"), queryParam("until", "End of range (RFC 3339)", "string")}},
 		{Method: "GET", Path: "/api/v1/auth/oidc/login", Summary: "Start OIDC login", Tag: "OIDC", Security: false, ResponseDescription: "Redirect to OIDC provider", Parameters: []openAPIParameter{queryParam("provider_id", "OIDC provider ID", "string"), queryParam("prompt", "Optional OIDC prompt", "string")}},
 		{Method: "GET", Path: "/api/v1/auth/oidc/callback", Summary: "Handle OIDC callback", Tag: "OIDC", Security: false, ResponseDescription: "Redirect to frontend or iframe HTML", Parameters: []openAPIParameter{queryParam("code", "Authorization code", "string"), queryParam("state", "OIDC state", "string")}},
-		{Method: "POST", Path: "/api/v1/auth/oidc/refresh", Summary: "Get OIDC silent refresh URL", Tag: "OIDC", Security: false, ResponseSchema: "OIDCRefreshResponse"},
+		{Method: "POST", Path: "/api/v1/auth/oidc/refresh", Summary: "Refresh an OIDC session or get its silent refresh URL", Tag: "OIDC", Security: false, ResponseSchema: "OIDCRefreshResponse"},
 		{Method: "GET", Path: "/api/v1/auth/oidc/providers", Summary: "List enabled OIDC providers", Tag: "OIDC", Security: false, ResponseSchema: "PublicOIDCProvidersResponse"},
 		{Method: "GET", Path: "/api/v1/settings/oidc/providers", Summary: "List OIDC providers", Tag: "OIDC", ResponseSchema: "OIDCProvidersResponse"},
 		{Method: "POST", Path: "/api/v1/settings/oidc/providers", Summary: "Create OIDC provider", Tag: "OIDC", RequestSchema: "OIDCProvider", SuccessStatus: "201", ResponseSchema: "OIDCProvider"},
@@ -1138,12 +1345,81 @@ func openAPIOperationCatalog() map[string]openAPIRoute {
 		{Method: "PATCH", Path: "/api/v1/settings/oidc/providers/{providerId}", Summary: "Update OIDC provider", Tag: "OIDC", RequestSchema: "Object", ResponseSchema: "OIDCProvider"},
 		{Method: "DELETE", Path: "/api/v1/settings/oidc/providers/{providerId}", Summary: "Delete OIDC provider", Tag: "OIDC", ResponseDescription: "OIDC provider deleted"},
 		{Method: "POST", Path: "/api/v1/settings/oidc/providers/{providerId}/test", Summary: "Test OIDC provider discovery", Tag: "OIDC", ResponseSchema: "OIDCProviderTestResponse"},
+		{Method: "GET", Path: "/api/v1/webhooks", Summary: "List webhooks", Tag: "Webhooks", ResponseSchema: "WebhookListResponse"},
+		{Method: "POST", Path: "/api/v1/webhooks", Summary: "Create webhook", Tag: "Webhooks", RequestSchema: "WebhookCreateRequest", SuccessStatus: "201", ResponseSchema: "WebhookSecretResponse"},
+		{Method: "GET", Path: "/api/v1/webhooks/{webhookId}", Summary: "Get webhook", Tag: "Webhooks", ResponseSchema: "Webhook"},
+		{Method: "PATCH", Path: "/api/v1/webhooks/{webhookId}", Summary: "Update webhook or rotate its secret", Tag: "Webhooks", RequestSchema: "Object", ResponseSchema: "WebhookSecretResponse"},
+		{Method: "DELETE", Path: "/api/v1/webhooks/{webhookId}", Summary: "Delete webhook", Tag: "Webhooks", SuccessStatus: "204", ResponseDescription: "Webhook deleted"},
+		{Method: "GET", Path: "/api/v1/webhooks/{webhookId}/deliveries", Summary: "List webhook deliveries", Tag: "Webhooks", ResponseSchema: "WebhookDeliveryListResponse", Parameters: []openAPIParameter{queryParam("status", "Delivery status: pending, succeeded or failed", "string"), queryParam("event_type", "Event type filter", "string"), queryParam("page", "Page number", "integer"), queryParam("page_size", "Page size", "integer")}},
+		{Method: "POST", Path: "/api/v1/webhooks/{webhookId}/test", Summary: "Send a test ping to a webhook", Tag: "Webhooks", SuccessStatus: "202", ResponseSchema: "WebhookDelivery"},
+		{Method: "GET", Path: "/api/v1/pools/{poolId}/addresses", Summary: "List address records of a pool", Tag: "Pools", ResponseSchema: "IPAddressListResponse", Parameters: ipAddressListParams()},
+		{Method: "POST", Path: "/api/v1/pools/{poolId}/addresses", Summary: "Record an address in a subnet pool", Tag: "Pools", RequestSchema: "CreateIPAddress", SuccessStatus: "201", ResponseSchema: "IPAddress"},
+		{Method: "POST", Path: "/api/v1/pools/{poolId}/addresses/allocate", Summary: "Allocate the next free address in a subnet pool", Tag: "Pools", RequestSchema: "AllocateIPAddress", SuccessStatus: "201", ResponseSchema: "IPAddress", ResponseDescription: "Address allocated"},
+		{Method: "GET", Path: "/scim/v2/ServiceProviderConfig", Summary: "Get SCIM service provider configuration", Tag: "SCIM", ResponseSchema: "Object", ResponseContentType: scimContentType},
+		{Method: "GET", Path: "/scim/v2/Users", Summary: "List SCIM users", Description: "Filters support eq, ne, co, sw, ew and pr on userName, displayName, emails and active, joined with and.", Tag: "SCIM", ResponseSchema: "SCIMUserListResponse", ResponseContentType: scimContentType, Parameters: scimListParams()},
+		{Method: "POST", Path: "/scim/v2/Users", Summary: "Provision SCIM user", Tag: "SCIM", RequestSchema: "SCIMUser", SuccessStatus: "201", ResponseSchema: "SCIMUser", ResponseContentType: scimContentType},
+		{Method: "GET", Path: "/scim/v2/Users/{userId}", Summary: "Get SCIM user", Tag: "SCIM", ResponseSchema: "SCIMUser", ResponseContentType: scimContentType},
+		{Method: "PUT", Path: "/scim/v2/Users/{userId}", Summary: "Replace SCIM user", Tag: "SCIM", RequestSchema: "SCIMUser", ResponseSchema: "SCIMUser", ResponseContentType: scimContentType},
+		{Method: "PATCH", Path: "/scim/v2/Users/{userId}", Summary: "Patch SCIM user", Description: "Setting active to false disables the user and revokes their sessions and API keys.", Tag: "SCIM", RequestSchema: "SCIMPatchRequest", ResponseSchema: "SCIMUser", ResponseContentType: scimContentType},
+		{Method: "DELETE", Path: "/scim/v2/Users/{userId}", Summary: "Deprovision SCIM user", Description: "Deactivates the user and revokes their sessions and API keys.", Tag: "SCIM", SuccessStatus: "204", ResponseDescription: "User deactivated"},
+		{Method: "GET", Path: "/scim/v2/Groups", Summary: "List SCIM groups", Description: "Groups are roles; members are the users holding the role.", Tag: "SCIM", ResponseSchema: "SCIMGroupListResponse", ResponseContentType: scimContentType, Parameters: append(scimListParams(), queryParam("excludedAttributes", "Set to members to omit members", "string"))},
+		{Method: "POST", Path: "/scim/v2/Groups", Summary: "Create SCIM group", Description: "Creates a custom role with no permissions.", Tag: "SCIM", RequestSchema: "SCIMGroup", SuccessStatus: "201", ResponseSchema: "SCIMGroup", ResponseContentType: scimContentType},
+		{Method: "GET", Path: "/scim/v2/Groups/{groupId}", Summary: "Get SCIM group", Tag: "SCIM", ResponseSchema: "SCIMGroup", ResponseContentType: scimContentType},
+		{Method: "PUT", Path: "/scim/v2/Groups/{groupId}", Summary: "Replace SCIM group members", Tag: "SCIM", RequestSchema: "SCIMGroup", ResponseSchema: "SCIMGroup", ResponseContentType: scimContentType},
+		{Method: "PATCH", Path: "/scim/v2/Groups/{groupId}", Summary: "Patch SCIM group members", Tag: "SCIM", RequestSchema: "SCIMPatchRequest", ResponseSchema: "SCIMGroup", ResponseContentType: scimContentType},
+		{Method: "DELETE", Path: "/scim/v2/Groups/{groupId}", Summary: "Delete SCIM group", Description: "Deletes the custom role. Built-in roles and roles with active members cannot be deleted.", Tag: "SCIM", SuccessStatus: "204", ResponseDescription: "Group deleted"},
+		{Method: "GET", Path: "/api/v1/ip-addresses", Summary: "List address records", Tag: "IP Addresses", ResponseSchema: "IPAddressListResponse", Parameters: append([]openAPIParameter{queryParam("pool_id", "Pool filter", "integer")}, ipAddressListParams()...)},
+		{Method: "GET", Path: "/api/v1/ip-addresses/{ipAddressId}", Summary: "Get address record", Tag: "IP Addresses", ResponseSchema: "IPAddress"},
+		{Method: "PATCH", Path: "/api/v1/ip-addresses/{ipAddressId}", Summary: "Update address record", Tag: "IP Addresses", RequestSchema: "UpdateIPAddress", ResponseSchema: "IPAddress"},
+		{Method: "DELETE", Path: "/api/v1/ip-addresses/{ipAddressId}", Summary: "Release address record", Tag: "IP Addresses", SuccessStatus: "204", ResponseDescription: "Address record deleted"},
+		{Method: "GET", Path: "/api/v1/pools/{poolId}/utilization/history", Summary: "Get pool utilization history", Tag: "Pools", ResponseSchema: "UtilizationHistory", Parameters: []openAPIParameter{
+			queryParam("from", "Start of the range (RFC3339); defaults to seven days before to", "string"),
+			queryParam("to", "End of the range (RFC3339); defaults to now", "string"),
+			queryParam("bucket", "Downsampling bucket such as 1h or 24h; auto when omitted", "string"),
+		}},
+		{Method: "GET", Path: "/api/v1/pools/{poolId}/utilization/forecast", Summary: "Forecast pool address exhaustion", Tag: "Pools", ResponseSchema: "UtilizationForecast", Parameters: []openAPIParameter{queryParam("days", "Days of history to fit (1-730, default 90)", "integer")}},
+		{Method: "GET", Path: "/api/v1/utilization/forecast", Summary: "Forecast address exhaustion for all pools", Tag: "Pools", ResponseSchema: "UtilizationForecastReport", Parameters: []openAPIParameter{queryParam("days", "Days of history to fit (1-730, default 90)", "integer")}},
+		{Method: "GET", Path: "/api/v1/alerts", Summary: "List capacity alerts", Tag: "Alerts", ResponseSchema: "AlertListResponse", Parameters: []openAPIParameter{
+			queryParam("status", "Alert status: open, acknowledged, resolved, or active", "string"),
+			queryParam("severity", "Severity: warning or critical", "string"),
+			queryParam("kind", "Kind: utilization or exhaustion", "string"),
+			queryParam("pool_id", "Pool filter", "integer"),
+			queryParam("page", "Page number", "integer"),
+			queryParam("page_size", "Page size", "integer"),
+		}},
+		{Method: "POST", Path: "/api/v1/alerts/evaluate", Summary: "Evaluate alert rules now", Tag: "Alerts", ResponseSchema: "AlertEvaluationResponse"},
+		{Method: "GET", Path: "/api/v1/alerts/{alertId}", Summary: "Get capacity alert", Tag: "Alerts", ResponseSchema: "Alert"},
+		{Method: "POST", Path: "/api/v1/alerts/{alertId}/acknowledge", Summary: "Acknowledge an open alert", Tag: "Alerts", ResponseSchema: "Alert"},
+		{Method: "POST", Path: "/api/v1/alerts/{alertId}/resolve", Summary: "Resolve an active alert", Tag: "Alerts", ResponseSchema: "Alert"},
+		{Method: "GET", Path: "/api/v1/settings/alerts", Summary: "Get alert rules and notification channels", Tag: "Alerts", ResponseSchema: "AlertSettings"},
+		{Method: "PATCH", Path: "/api/v1/settings/alerts", Summary: "Replace alert rules and notification channels", Tag: "Alerts", RequestSchema: "AlertSettings", ResponseSchema: "AlertSettings"},
+		{Method: "POST", Path: "/api/v1/settings/alerts/channels/{channelId}/test", Summary: "Send a test notification through a channel", Tag: "Alerts", SuccessStatus: "204", ResponseDescription: "Notification accepted by the channel"},
+		{Method: "GET", Path: "/api/v1/change-proposals", Summary: "List change proposals", Tag: "Change Proposals", ResponseSchema: "ChangeProposalListResponse", Parameters: []openAPIParameter{
+			queryParam("status", "Status: pending, applied, or rejected", "string"),
+		}},
+		{Method: "GET", Path: "/api/v1/change-proposals/{proposalId}", Summary: "Get change proposal", Tag: "Change Proposals", ResponseSchema: "ChangeProposal"},
+		{Method: "POST", Path: "/api/v1/change-proposals/{proposalId}/approve", Summary: "Approve and apply a pending change proposal", Description: "The approver must be neither the author nor acting with the author's role.", Tag: "Change Proposals", RequestSchema: "ChangeProposalReview", ResponseSchema: "ChangeProposal"},
+		{Method: "POST", Path: "/api/v1/change-proposals/{proposalId}/reject", Summary: "Reject a pending change proposal", Tag: "Change Proposals", RequestSchema: "ChangeProposalReview", ResponseSchema: "ChangeProposal"},
+		{Method: "GET", Path: "/api/v1/change-requests", Summary: "List pool change requests", Tag: "Change Requests", ResponseSchema: "PoolChangeRequestListResponse", Parameters: []openAPIParameter{
+			queryParam("status", "Status: pending, applied, or rejected", "string"),
+		}},
+		{Method: "GET", Path: "/api/v1/change-requests/{changeRequestId}", Summary: "Get pool change request", Tag: "Change Requests", ResponseSchema: "PoolChangeRequest"},
+		{Method: "POST", Path: "/api/v1/change-requests/{changeRequestId}/approve", Summary: "Approve and apply a pending pool change request", Description: "The approver must not be the requester. Fails with 412 if the pool changed since the request was made.", Tag: "Change Requests", RequestSchema: "ChangeProposalReview", ResponseSchema: "PoolChangeRequest"},
+		{Method: "POST", Path: "/api/v1/change-requests/{changeRequestId}/reject", Summary: "Reject a pending pool change request", Tag: "Change Requests", RequestSchema: "ChangeProposalReview", ResponseSchema: "PoolChangeRequest"},
+		{Method: "GET", Path: "/api/v1/reservations", Summary: "List CIDR reservations", Tag: "Reservations", ResponseSchema: "ReservationListResponse", Parameters: []openAPIParameter{
+			queryParam("status", "Status: active, released, or expired", "string"),
+			queryParam("parent_id", "Only reservations in this parent pool", "integer"),
+		}},
+		{Method: "POST", Path: "/api/v1/reservations", Summary: "Reserve a CIDR block", Description: "Holds a block under a parent pool until expires_at without creating a pool. The block must not overlap a sibling pool, a pending change request, or another active reservation (409). Owner defaults to the caller.", Tag: "Reservations", RequestSchema: "CreateReservation", SuccessStatus: "201", ResponseSchema: "Reservation", ResponseDescription: "Reservation created"},
+		{Method: "GET", Path: "/api/v1/reservations/{reservationId}", Summary: "Get CIDR reservation", Tag: "Reservations", ResponseSchema: "Reservation"},
+		{Method: "PATCH", Path: "/api/v1/reservations/{reservationId}", Summary: "Update or extend an active reservation", Description: "Fails with 409 once the reservation has been released or has expired.", Tag: "Reservations", RequestSchema: "UpdateReservation", ResponseSchema: "Reservation"},
+		{Method: "POST", Path: "/api/v1/reservations/{reservationId}/release", Summary: "Release an active reservation", Tag: "Reservations", ResponseSchema: "Reservation"},
 		{Method: "POST", Path: "/api/v1/ai/chat", Summary: "Stream AI planning chat", Tag: "AI", RequestSchema: "ChatRequest", ResponseSchema: "String", ResponseContentType: "text/event-stream"},
 		{Method: "GET", Path: "/api/v1/ai/sessions", Summary: "List AI planning sessions", Tag: "AI", ResponseSchema: "ConversationListResponse"},
 		{Method: "POST", Path: "/api/v1/ai/sessions", Summary: "Create AI planning session", Tag: "AI", RequestSchema: "CreateConversationRequest", SuccessStatus: "201", ResponseSchema: "Conversation"},
 		{Method: "GET", Path: "/api/v1/ai/sessions/{sessionId}", Summary: "Get AI planning session", Tag: "AI", ResponseSchema: "ConversationWithMessages"},
 		{Method: "DELETE", Path: "/api/v1/ai/sessions/{sessionId}", Summary: "Delete AI planning session", Tag: "AI", SuccessStatus: "200", ResponseSchema: "StatusResponse"},
-		{Method: "POST", Path: "/api/v1/ai/sessions/{sessionId}/apply-plan", Summary: "Apply generated AI plan", Tag: "AI", RequestSchema: "ApplyPlanRequest", ResponseSchema: "ApplyPlanResponse"},
+		{Method: "POST", Path: "/api/v1/ai/sessions/{sessionId}/apply-plan", Summary: "Apply generated AI plan", Description: "With dry_run the response is the ChangeSet the apply would make; with propose the plan is stored as a pending ChangeProposal (201).", Tag: "AI", RequestSchema: "ApplyPlanRequest", ResponseSchema: "ApplyPlanResponse"},
 		{Method: "GET", Path: "/api/v1/updates", Summary: "Check for updates", Tag: "Updates", ResponseSchema: "UpdateCheckResponse", Parameters: []openAPIParameter{queryParam("force", "Force refresh release metadata", "boolean")}},
 		{Method: "POST", Path: "/api/v1/updates/upgrade", Summary: "Request in-app upgrade", Tag: "Updates", SuccessStatus: "202", ResponseSchema: "UpgradeRequestResponse"},
 		{Method: "GET", Path: "/api/v1/updates/status", Summary: "Get upgrade status", Tag: "Updates", ResponseSchema: "UpgradeStatusResponse"},
@@ -1166,6 +1442,31 @@ func queryParam(name, description, typ string) openAPIParameter {
 	return openAPIParameter{Name: name, In: "query", Description: description, Type: typ}
 }
 
+// ipAddressListParams documents the filters shared by the address record lists.
+func ipAddressListParams() []openAPIParameter {
+	return []openAPIParameter{
+		queryParam("status", "Address status: reserved, assigned or dhcp", "string"),
+		queryParam("source", "Record source: manual or discovered", "string"),
+		queryParam("q", "Substring of the address, hostname, MAC or owner", "string"),
+		queryParam("page", "Page number", "integer"),
+		queryParam("page_size", "Page size", "integer"),
+	}
+}
+
+func scimListParams() []openAPIParameter {
+	return []openAPIParameter{
+		queryParam("filter", "SCIM filter, such as userName eq \"alice@example.com\"", "string"),
+		queryParam("startIndex", "1-based index of the first result", "integer"),
+		queryParam("count", "Page size (default 100, maximum 500)", "integer"),
+	}
+}
+
+// ifMatchParam documents the optimistic concurrency header on pool and
+// account writes.
+func ifMatchParam() openAPIParameter {
+	return openAPIParameter{Name: "If-Match", In: "header", Description: "ETag from a previous GET. A stale tag fails with 412 instead of overwriting a concurrent change.", Type: "string"}
+}
+
 func pathParameters(path string) []openAPIParameter {
 	var params []openAPIParameter
 	for _, part := range strings.Split(path, "/") {
@@ -1196,6 +1497,47 @@ func discoveryResourceQueryParams() []openAPIParameter {
 	}
 }
 
+// listPageQueryParams are shared by the pool and account lists. Without page
+// or page_size they return a bare array; with either, an
+// {items,total,page,page_size} envelope. Both set X-Total-Count.
+func listPageQueryParams() []openAPIParameter {
+	return []openAPIParameter{
+		queryParam("created_after", "Created at or after (RFC 3339 or Unix seconds)", "string"),
+		queryParam("created_before", "Created before (RFC 3339 or Unix seconds)", "string"),
+		queryParam("order", "Sort direction: asc (default) or desc", "string"),
+		queryParam("page", "Page number; returns a paged envelope", "integer"),
+		queryParam("page_size", "Page size (default 50, max 500); returns a paged envelope", "integer"),
+	}
+}
+
+func poolListQueryParams() []openAPIParameter {
+	return append([]openAPIParameter{
+		queryParam("include_stats", "Include utilization statistics", "boolean"),
+		queryParam("parent_id", "Parent pool ID; 0 selects top-level pools", "integer"),
+		queryParam("include_children", "With parent_id, include all descendants rather than direct children", "boolean"),
+		queryParam("account_id", "Account ID; 0 selects unassigned pools", "integer"),
+		queryParam("cidr_prefix", "CIDR text prefix, e.g. 10.1.", "string"),
+		queryParam("cidr_contains", "Pools containing this address or prefix", "string"),
+		queryParam("cidr_within", "Pools within this prefix", "string"),
+		queryParam("name", "Case-insensitive name substring", "string"),
+		queryParam("order_by", "Sort field: id (default), name, cidr, created_at", "string"),
+	}, listPageQueryParams()...)
+}
+
+func accountListQueryParams() []openAPIParameter {
+	return append([]openAPIParameter{
+		queryParam("key", "Exact account key", "string"),
+		queryParam("key_prefix", "Account key prefix, e.g. aws:", "string"),
+		queryParam("provider", "Cloud provider", "string"),
+		queryParam("platform", "Platform", "string"),
+		queryParam("tier", "Tier", "string"),
+		queryParam("environment", "Environment", "string"),
+		queryParam("region", "Region the account operates in", "string"),
+		queryParam("name", "Case-insensitive name substring", "string"),
+		queryParam("order_by", "Sort field: id (default), key, name, created_at", "string"),
+	}, listPageQueryParams()...)
+}
+
 func networkViewQueryParams() []openAPIParameter {
 	return []openAPIParameter{
 		queryParam("account_id", "Account ID", "integer"),
@@ -1255,7 +1597,9 @@ func paginatedFilterParams(names ...string) []openAPIParameter {
 
 func tagForPath(path string) string {
 	switch {
-	case strings.Contains(path, "/pools"):
+	case strings.HasPrefix(path, "/scim/"):
+		return "SCIM"
+	case strings.Contains(path, "/pools"), strings.Contains(path, "/utilization"):
 		return "Pools"
 	case strings.Contains(path, "/accounts"):
 		return "Accounts"
@@ -1265,6 +1609,14 @@ func tagForPath(path string) string {
 		return "OIDC"
 	case strings.Contains(path, "/auth"):
 		return "Auth"
+	case strings.Contains(path, "/alerts"):
+		return "Alerts"
+	case strings.Contains(path, "/change-proposals"):
+		return "Change Proposals"
+	case strings.Contains(path, "/change-requests"), strings.Contains(path, "/settings/approvals"):
+		return "Change Requests"
+	case strings.Contains(path, "/reservations"):
+		return "Reservations"
 	case strings.Contains(path, "/settings"):
 		return "Settings"
 	case strings.Contains(path, "/analysis"):
@@ -1275,6 +1627,10 @@ func tagForPath(path string) string {
 		return "AI"
 	case strings.Contains(path, "/updates"):
 		return "Updates"
+	case strings.Contains(path, "/webhooks"):
+		return "Webhooks"
+	case strings.Contains(path, "/ip-addresses"):
+		return "IP Addresses"
 	default:
 		return "System"
 	}
@@ -1282,7 +1638,7 @@ func tagForPath(path string) string {
 
 func isPublicOpenAPIPath(path string) bool {
 	switch path {
-	case "/openapi", "/openapi.yaml", "/healthz", "/readyz", "/metrics", "/api/v1/test-sentry", "/api/v1/auth/setup", "/api/v1/auth/login", "/api/v1/auth/oidc/login", "/api/v1/auth/oidc/callback", "/api/v1/auth/oidc/refresh", "/api/v1/auth/oidc/providers":
+	case "/openapi", "/openapi.yaml", "/healthz", "/readyz", "/metrics", "/api/v1/test-sentry", "/api/v1/auth/setup", "/api/v1/auth/login", "/api/v1/auth/login/mfa", "/api/v1/auth/login/mfa/enroll", "/api/v1/auth/oidc/login", "/api/v1/auth/oidc/callback", "/api/v1/auth/oidc/refresh", "/api/v1/auth/oidc/providers":
 		return true
 	default:
 		return false
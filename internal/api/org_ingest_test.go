@@ -191,3 +191,19 @@ func TestOrgIngestRejectsInvalidAgentID(t *testing.T) {
 	body := `{"agent_id":"not-a-uuid","accounts":[{"aws_account_id":"123456789012","account_name":"prod","provider":"aws"}]}`
 	doJSON(t, discSrv.srv.mux, http.MethodPost, "/api/v1/discovery/ingest/org", body, http.StatusBadRequest)
 }
+
+func TestOrgIngestKeysAccountsByProvider(t *testing.T) {
+	discSrv, st, _, _ := setupDiscoveryTestServer()
+
+	sub := "11111111-1111-1111-1111-111111111111"
+	body := `{"accounts":[{"aws_account_id":"` + sub + `","account_name":"Prod","provider":"azure","regions":["eastus"],"resources":[]}]}`
+	doJSON(t, discSrv.srv.mux, http.MethodPost, "/api/v1/discovery/ingest/org", body, http.StatusOK)
+
+	account, err := st.GetAccountByKey(t.Context(), "azure:"+sub)
+	if err != nil {
+		t.Fatalf("get account: %v", err)
+	}
+	if account.Provider != "azure" || account.ExternalID != sub || account.Name != "Prod" {
+		t.Fatalf("account = %+v", account)
+	}
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	stdhttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"cloudpam/internal/audit"
+	"cloudpam/internal/domain"
+)
+
+func TestAllocatePool_FirstFit(t *testing.T) {
+	srv, st := setupTestServer()
+	parent, _ := st.CreatePool(t.Context(), domain.CreatePool{Name: "vpc", CIDR: "10.0.0.0/22"})
+	_, _ = st.CreatePool(t.Context(), domain.CreatePool{Name: "taken", CIDR: "10.0.0.0/24", ParentID: &parent.ID})
+	acct, _ := st.CreateAccount(t.Context(), domain.CreateAccount{Key: "aws:123456789012", Name: "prod"})
+
+	body := fmt.Sprintf(`{"name":"app","prefix_length":24,"type":"subnet","account_id":%d,"tags":{"tier":"app"}}`, acct.ID)
+	rr := doJSON(t, srv.mux, stdhttp.MethodPost, "/api/v1/pools/"+itoa(parent.ID)+"/allocate", body, stdhttp.StatusCreated)
+
+	var p domain.Pool
+	if err := json.Unmarshal(rr.Body.Bytes(), &p); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if p.CIDR != "10.0.1.0/24" {
+		t.Fatalf("expected 10.0.1.0/24, got %s", p.CIDR)
+	}
+	if p.ParentID == nil || *p.ParentID != parent.ID {
+		t.Fatalf("expected parent %d, got %v", parent.ID, p.ParentID)
+	}
+	if p.AccountID == nil || *p.AccountID != acct.ID || p.Tags["tier"] != "app" || p.Type != domain.PoolTypeSubnet {
+		t.Fatalf("request fields not carried through: %+v", p)
+	}
+}
+
+func TestAllocatePool_BestFit(t *testing.T) {
+	srv, st := setupTestServer()
+	parent, _ := st.CreatePool(t.Context(), domain.CreatePool{Name: "vpc", CIDR: "10.0.0.0/22"})
+	_, _ = st.CreatePool(t.Context(), domain.CreatePool{Name: "taken", CIDR: "10.0.2.0/25", ParentID: &parent.ID})
+
+	rr := doJSON(t, srv.mux, stdhttp.MethodPost, "/api/v1/pools/"+itoa(parent.ID)+"/allocate",
+		`{"name":"small","prefix_length":26,"strategy":"best_fit"}`, stdhttp.StatusCreated)
+	var p domain.Pool
+	_ = json.Unmarshal(rr.Body.Bytes(), &p)
+	if p.CIDR != "10.0.2.128/26" {
+		t.Fatalf("expected best fit 10.0.2.128/26, got %s", p.CIDR)
+	}
+}
+
+func TestAllocatePool_AuditsAsAllocate(t *testing.T) {
+	srv, st := setupTestServer()
+	auditLogger := audit.NewMemoryAuditLogger()
+	srv.auditLogger = auditLogger
+	parent, _ := st.CreatePool(t.Context(), domain.CreatePool{Name: "vpc", CIDR: "10.0.0.0/22"})
+
+	doJSON(t, srv.mux, stdhttp.MethodPost, "/api/v1/pools/"+itoa(parent.ID)+"/allocate",
+		`{"name":"app","prefix_length":24}`, stdhttp.StatusCreated)
+
+	events, _, _ := auditLogger.List(t.Context(), audit.ListOptions{ResourceType: audit.ResourcePool})
+	if len(events) != 1 || events[0].Action != audit.ActionAllocate {
+		t.Fatalf("expected one allocate audit event, got %+v", events)
+	}
+	if events[0].Changes == nil || events[0].Changes.After["cidr"] != "10.0.0.0/24" {
+		t.Fatalf("allocate audit event missing cidr: %+v", events[0].Changes)
+	}
+}
+
+func TestAllocatePool_ExhaustedReturns409(t *testing.T) {
+	srv, st := setupTestServer()
+	parent, _ := st.CreatePool(t.Context(), domain.CreatePool{Name: "tiny", CIDR: "10.0.0.0/23"})
+	path := "/api/v1/pools/" + itoa(parent.ID) + "/allocate"
+
+	doJSON(t, srv.mux, stdhttp.MethodPost, path, `{"name":"a","prefix_length":24}`, stdhttp.StatusCreated)
+	doJSON(t, srv.mux, stdhttp.MethodPost, path, `{"name":"b","prefix_length":24}`, stdhttp.StatusCreated)
+	rr := doJSON(t, srv.mux, stdhttp.MethodPost, path, `{"name":"c","prefix_length":24}`, stdhttp.StatusConflict)
+
+	var e apiError
+	_ = json.Unmarshal(rr.Body.Bytes(), &e)
+	if e.Error != "pool exhausted" {
+		t.Fatalf("expected pool exhausted error, got %+v", e)
+	}
+}
+
+func TestAllocatePool_Concurrent(t *testing.T) {
+	srv, st := setupTestServer()
+	parent, _ := st.CreatePool(t.Context(), domain.CreatePool{Name: "vpc", CIDR: "10.0.0.0/20"})
+	path := "/api/v1/pools/" + itoa(parent.ID) + "/allocate"
+
+	// 16 /24s fit; the remaining requests must get 409, never a duplicate.
+	const n = 20
+	var wg sync.WaitGroup
+	codes := make(chan int, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req := httptest.NewRequest(stdhttp.MethodPost, path, strings.NewReader(fmt.Sprintf(`{"name":"s%d","prefix_length":24}`, i)))
+			rr := httptest.NewRecorder()
+			srv.mux.ServeHTTP(rr, req)
+			codes <- rr.Code
+		}(i)
+	}
+	wg.Wait()
+	close(codes)
+
+	created, conflicts := 0, 0
+	for c := range codes {
+		switch c {
+		case stdhttp.StatusCreated:
+			created++
+		case stdhttp.StatusConflict:
+			conflicts++
+		default:
+			t.Fatalf("unexpected status %d", c)
+		}
+	}
+	if created != 16 || conflicts != 4 {
+		t.Fatalf("expected 16 created and 4 conflicts, got %d and %d", created, conflicts)
+	}
+	children, _ := st.GetPoolChildren(t.Context(), parent.ID)
+	seen := make(map[string]bool)
+	for _, c := range children {
+		if seen[c.CIDR] {
+			t.Fatalf("duplicate allocation %s", c.CIDR)
+		}
+		seen[c.CIDR] = true
+	}
+}
+
+func TestAllocatePool_Validation(t *testing.T) {
+	srv, st := setupTestServer()
+	parent, _ := st.CreatePool(t.Context(), domain.CreatePool{Name: "vpc", CIDR: "10.0.0.0/16"})
+	path := "/api/v1/pools/" + itoa(parent.ID) + "/allocate"
+
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"invalid json", `{`, stdhttp.StatusBadRequest},
+		{"missing name", `{"prefix_length":24}`, stdhttp.StatusBadRequest},
+		{"missing prefix length", `{"name":"x"}`, stdhttp.StatusBadRequest},
+		{"prefix not longer than parent", `{"name":"x","prefix_length":16}`, stdhttp.StatusBadRequest},
+		{"prefix too long", `{"name":"x","prefix_length":31}`, stdhttp.StatusBadRequest},
+		{"unknown strategy", `{"name":"x","prefix_length":24,"strategy":"worst_fit"}`, stdhttp.StatusBadRequest},
+		{"invalid type", `{"name":"x","prefix_length":24,"type":"galaxy"}`, stdhttp.StatusBadRequest},
+		{"invalid status", `{"name":"x","prefix_length":24,"status":"gone"}`, stdhttp.StatusBadRequest},
+		{"unknown account", `{"name":"x","prefix_length":24,"account_id":999}`, stdhttp.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doJSON(t, srv.mux, stdhttp.MethodPost, path, tt.body, tt.code)
+		})
+	}
+
+	doJSON(t, srv.mux, stdhttp.MethodPost, "/api/v1/pools/999/allocate", `{"name":"x","prefix_length":24}`, stdhttp.StatusNotFound)
+	rr := doJSON(t, srv.mux, stdhttp.MethodGet, path, "", stdhttp.StatusMethodNotAllowed)
+	if rr.Header().Get("Allow") != stdhttp.MethodPost {
+		t.Fatalf("expected Allow: POST, got %q", rr.Header().Get("Allow"))
+	}
+}
+
+func TestProtectedPoolSubroutes_Allocate(t *testing.T) {
+	mux, st, key := setupProtectedTestServer(t)
+	parent, _ := st.CreatePool(t.Context(), domain.CreatePool{Name: "vpc", CIDR: "10.0.0.0/16"})
+
+	rr := doProtected(t, mux, stdhttp.MethodPost, "/api/v1/pools/"+itoa(parent.ID)+"/allocate", `{"name":"a","prefix_length":24}`, key)
+	if rr.Code != stdhttp.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestProtectedPoolSubroutes_ViewerCannotAllocate(t *testing.T) {
+	mux, st, key := setupViewerProtectedTestServer(t)
+	parent, _ := st.CreatePool(t.Context(), domain.CreatePool{Name: "vpc", CIDR: "10.0.0.0/16"})
+
+	rr := doProtected(t, mux, stdhttp.MethodPost, "/api/v1/pools/"+itoa(parent.ID)+"/allocate", `{"name":"a","prefix_length":24}`, key)
+	if rr.Code != stdhttp.StatusForbidden {
+		t.Fatalf("expected 403, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
@@ -0,0 +1,579 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"cloudpam/internal/audit"
+	"cloudpam/internal/auth"
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+// PoolChangeRequestServer handles review of pool change requests: creates,
+// updates and deletes held back by an approval policy.
+type PoolChangeRequestServer struct {
+	srv      *Server
+	requests storage.PoolChangeRequestStore
+}
+
+// NewPoolChangeRequestServer creates a new PoolChangeRequestServer.
+func NewPoolChangeRequestServer(srv *Server, requests storage.PoolChangeRequestStore) *PoolChangeRequestServer {
+	return &PoolChangeRequestServer{srv: srv, requests: requests}
+}
+
+// RegisterProtectedPoolChangeRequestRoutes registers change request routes
+// with RBAC.
+func (cs *PoolChangeRequestServer) RegisterProtectedPoolChangeRequestRoutes(dualMW Middleware, logger *slog.Logger) {
+	listMW := RequirePermissionMiddleware(auth.ResourceChangeRequests, auth.ActionList, logger)
+	readMW := RequirePermissionMiddleware(auth.ResourceChangeRequests, auth.ActionRead, logger)
+	approveMW := RequirePermissionMiddleware(auth.ResourceChangeRequests, auth.ActionApprove, logger)
+
+	cs.srv.handleOpenAPIRoute("GET /api/v1/change-requests", dualMW(listMW(http.HandlerFunc(cs.handleList))))
+	cs.srv.handleOpenAPIRoute("GET /api/v1/change-requests/{id}", dualMW(readMW(http.HandlerFunc(cs.handleGet))))
+	cs.srv.handleOpenAPIRoute("POST /api/v1/change-requests/{id}/approve", dualMW(approveMW(http.HandlerFunc(cs.handleApprove))))
+	cs.srv.handleOpenAPIRoute("POST /api/v1/change-requests/{id}/reject", dualMW(approveMW(http.HandlerFunc(cs.handleReject))))
+}
+
+// RegisterPoolChangeRequestRoutesNoAuth registers change request routes
+// without auth middleware (for tests).
+func (cs *PoolChangeRequestServer) RegisterPoolChangeRequestRoutesNoAuth() {
+	cs.srv.handleOpenAPIRouteFunc("GET /api/v1/change-requests", cs.handleList)
+	cs.srv.handleOpenAPIRouteFunc("GET /api/v1/change-requests/{id}", cs.handleGet)
+	cs.srv.handleOpenAPIRouteFunc("POST /api/v1/change-requests/{id}/approve", cs.handleApprove)
+	cs.srv.handleOpenAPIRouteFunc("POST /api/v1/change-requests/{id}/reject", cs.handleReject)
+}
+
+// handleList lists change requests, newest first, optionally by status.
+// GET /api/v1/change-requests
+func (cs *PoolChangeRequestServer) handleList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	status := domain.PoolChangeRequestStatus(r.URL.Query().Get("status"))
+	if status != "" && !domain.IsValidPoolChangeRequestStatus(status) {
+		cs.srv.writeErr(ctx, w, http.StatusBadRequest, "invalid status", "use pending, applied, or rejected")
+		return
+	}
+	items, err := cs.requests.ListPoolChangeRequests(ctx, status)
+	if err != nil {
+		cs.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, domain.PoolChangeRequestListResponse{Items: items})
+}
+
+// handleGet returns a single change request.
+// GET /api/v1/change-requests/{id}
+func (cs *PoolChangeRequestServer) handleGet(w http.ResponseWriter, r *http.Request) {
+	req, err := cs.requests.GetPoolChangeRequest(r.Context(), r.PathValue("id"))
+	if err != nil {
+		cs.srv.writeStoreErr(r.Context(), w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, req)
+}
+
+// loadPending returns the change request in the path if it is still
+// pending, writing the error response otherwise.
+func (cs *PoolChangeRequestServer) loadPending(w http.ResponseWriter, r *http.Request) (*domain.PoolChangeRequest, bool) {
+	req, err := cs.requests.GetPoolChangeRequest(r.Context(), r.PathValue("id"))
+	if err != nil {
+		cs.srv.writeStoreErr(r.Context(), w, err)
+		return nil, false
+	}
+	if req.Status != domain.PoolChangeRequestPending {
+		cs.srv.writeErr(r.Context(), w, http.StatusConflict, fmt.Sprintf("change request is already %s", req.Status), "")
+		return nil, false
+	}
+	return req, true
+}
+
+// handleApprove applies a pending change request. The approver must be
+// someone other than the requester, counting the requester's own API keys. Updates and deletes only apply if the
+// pool is still at the version they were requested against, and a create
+// is re-checked against its siblings, so an approval can fail with 412 or
+// 409; the request then stays pending.
+// POST /api/v1/change-requests/{id}/approve
+func (cs *PoolChangeRequestServer) handleApprove(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	body, ok := cs.srv.decodeReview(w, r)
+	if !ok {
+		return
+	}
+	req, ok := cs.loadPending(w, r)
+	if !ok {
+		return
+	}
+	actor, role := reviewActor(ctx)
+	actorID := reviewActorID(ctx)
+	if actorID == req.RequestedByID {
+		cs.srv.writeErr(ctx, w, http.StatusForbidden, "a change request cannot be approved by its requester", "")
+		return
+	}
+
+	now := time.Now().UTC()
+	req.Status = domain.PoolChangeRequestApplied
+	req.ReviewedBy, req.ReviewedRole = actor, role
+	req.ReviewedByID = actorID
+	req.ReviewComment = body.Comment
+	req.ReviewedAt = &now
+
+	var pool domain.Pool
+	err := cs.srv.withTx(ctx, func(st storage.Store) error {
+		var err error
+		pool, err = cs.srv.applyPoolChange(ctx, st, req)
+		if err != nil {
+			return err
+		}
+		if req.Operation == domain.PoolChangeCreate {
+			req.PoolID = &pool.ID
+		}
+		return txStore(st, cs.requests).ReviewPoolChangeRequest(ctx, *req)
+	})
+	if err != nil {
+		cs.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+
+	poolID := fmt.Sprintf("%d", pool.ID)
+	switch req.Operation {
+	case domain.PoolChangeCreate:
+		cs.srv.logAudit(ctx, audit.ActionCreate, audit.ResourcePool, poolID, pool.Name, http.StatusCreated)
+	case domain.PoolChangeUpdate:
+		cs.srv.logAudit(ctx, audit.ActionUpdate, audit.ResourcePool, poolID, pool.Name, http.StatusOK)
+	case domain.PoolChangeDelete:
+		cs.srv.logAudit(ctx, audit.ActionDelete, audit.ResourcePool, poolID, pool.Name, http.StatusNoContent)
+	}
+	cs.srv.logAuditWithChanges(ctx, audit.ActionApprove, audit.ResourcePoolChangeRequest, req.ID, req.PoolName,
+		&audit.Changes{After: map[string]any{
+			"operation":    string(req.Operation),
+			"pool_id":      pool.ID,
+			"requested_by": req.RequestedBy,
+			"policy_ids":   req.PolicyIDs,
+		}}, http.StatusOK)
+	writeJSON(w, http.StatusOK, req)
+}
+
+// handleReject closes a pending change request without applying it.
+// Requesters may reject, that is withdraw, their own requests.
+// POST /api/v1/change-requests/{id}/reject
+func (cs *PoolChangeRequestServer) handleReject(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	body, ok := cs.srv.decodeReview(w, r)
+	if !ok {
+		return
+	}
+	req, ok := cs.loadPending(w, r)
+	if !ok {
+		return
+	}
+
+	now := time.Now().UTC()
+	req.Status = domain.PoolChangeRequestRejected
+	req.ReviewedBy, req.ReviewedRole = reviewActor(ctx)
+	req.ReviewedByID = reviewActorID(ctx)
+	req.ReviewComment = body.Comment
+	req.ReviewedAt = &now
+	if err := cs.requests.ReviewPoolChangeRequest(ctx, *req); err != nil {
+		cs.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+	cs.srv.logAudit(ctx, audit.ActionReject, audit.ResourcePoolChangeRequest, req.ID, req.PoolName, http.StatusOK)
+	writeJSON(w, http.StatusOK, req)
+}
+
+// applyPoolChange performs the mutation a change request holds back and
+// returns the pool it created, updated or deleted.
+func (s *Server) applyPoolChange(ctx context.Context, st storage.Store, req *domain.PoolChangeRequest) (domain.Pool, error) {
+	switch req.Operation {
+	case domain.PoolChangeCreate:
+		if req.Create == nil {
+			return domain.Pool{}, fmt.Errorf("change request %s has no pool: %w", req.ID, storage.ErrValidation)
+		}
+		scope := req.Create.ParentID
+		if scope == nil {
+			scope = new(int64)
+		}
+		overlapping, err := findOverlappingPools(ctx, st, req.Create.CIDR, scope)
+		if err != nil {
+			return domain.Pool{}, err
+		}
+		if len(overlapping) > 0 {
+			p := overlapping[0]
+			return domain.Pool{}, fmt.Errorf("%s overlaps pool #%d (%s): %w", req.Create.CIDR, p.ID, p.CIDR, storage.ErrConflict)
+		}
+		return st.CreatePool(ctx, *req.Create)
+
+	case domain.PoolChangeUpdate:
+		if req.PoolID != nil && req.Resize != "" {
+			return s.resizePool(ctx, st, req)
+		}
+		if req.Update == nil || req.PoolID == nil {
+			return domain.Pool{}, fmt.Errorf("change request %s has no update: %w", req.ID, storage.ErrValidation)
+		}
+		update := *req.Update
+		update.IfVersion = &req.PoolVersion
+		p, ok, err := st.UpdatePool(ctx, *req.PoolID, update)
+		if err != nil {
+			return domain.Pool{}, err
+		}
+		if !ok {
+			return domain.Pool{}, fmt.Errorf("pool %d: %w", *req.PoolID, storage.ErrNotFound)
+		}
+		return p, nil
+
+	case domain.PoolChangeDelete:
+		if req.PoolID == nil {
+			return domain.Pool{}, fmt.Errorf("change request %s has no pool: %w", req.ID, storage.ErrValidation)
+		}
+		p, ok, err := st.GetPool(ctx, *req.PoolID)
+		if err != nil {
+			return domain.Pool{}, err
+		}
+		if !ok {
+			return domain.Pool{}, fmt.Errorf("pool %d: %w", *req.PoolID, storage.ErrNotFound)
+		}
+		if p.Version != req.PoolVersion {
+			return domain.Pool{}, fmt.Errorf("pool %d is at version %d: %w", p.ID, p.Version, storage.ErrPreconditionFailed)
+		}
+		if req.Cascade {
+			ok, err = st.DeletePoolCascade(ctx, p.ID)
+		} else {
+			ok, err = st.DeletePool(ctx, p.ID)
+		}
+		if err != nil {
+			// Deletes keep the historical 409 for a pool that still has children.
+			return domain.Pool{}, fmt.Errorf("%v: %w", err, storage.ErrConflict)
+		}
+		if !ok {
+			return domain.Pool{}, fmt.Errorf("pool %d: %w", p.ID, storage.ErrNotFound)
+		}
+		return p, nil
+	}
+	return domain.Pool{}, fmt.Errorf("unknown operation %q: %w", req.Operation, storage.ErrValidation)
+}
+
+// resizePool moves the pool of a resize request to its new CIDR, provided
+// the pool is still at the version the resize was requested against. The
+// new block must stay clear of held blocks like any other resize.
+func (s *Server) resizePool(ctx context.Context, st storage.Store, req *domain.PoolChangeRequest) (domain.Pool, error) {
+	restructurer, ok := st.(storage.PoolRestructurer)
+	if !ok {
+		return domain.Pool{}, fmt.Errorf("store cannot resize pools: %w", storage.ErrValidation)
+	}
+	p, ok, err := st.GetPool(ctx, *req.PoolID)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+	if !ok {
+		return domain.Pool{}, fmt.Errorf("pool %d: %w", *req.PoolID, storage.ErrNotFound)
+	}
+	if p.Version != req.PoolVersion {
+		return domain.Pool{}, fmt.Errorf("pool %d is at version %d: %w", p.ID, p.Version, storage.ErrPreconditionFailed)
+	}
+	held, err := s.heldPrefixes(ctx, p.ParentID)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+	return restructurer.ResizePool(ctx, p.ID, req.Resize, held)
+}
+
+// approvalPolicies returns the enabled approval policies. It returns none
+// when change requests are not configured.
+func (s *Server) approvalPolicies(ctx context.Context) ([]domain.ApprovalPolicy, error) {
+	if s.poolChanges == nil || s.settingsStore == nil {
+		return nil, nil
+	}
+	settings, err := s.settingsStore.GetApprovalSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var out []domain.ApprovalPolicy
+	for _, p := range settings.Policies {
+		if p.Enabled {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+// matchPolicies returns the IDs of the policies that cover op on any of
+// pools.
+func (s *Server) matchPolicies(ctx context.Context, policies []domain.ApprovalPolicy, op domain.PoolChangeOperation, pools ...domain.Pool) ([]string, error) {
+	if len(policies) == 0 {
+		return nil, nil
+	}
+	all, err := s.store.ListPools(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]domain.Pool, len(all))
+	for _, p := range all {
+		byID[p.ID] = p
+	}
+	lineages := make([][]int64, len(pools))
+	for i, p := range pools {
+		lineages[i] = poolLineage(byID, p)
+	}
+	var ids []string
+	for _, policy := range policies {
+		for i, p := range pools {
+			if policy.Matches(op, p, lineages[i]) {
+				ids = append(ids, policy.ID)
+				break
+			}
+		}
+	}
+	return ids, nil
+}
+
+// poolLineage returns the ID of p, if it exists yet, followed by the IDs of
+// its ancestors.
+func poolLineage(byID map[int64]domain.Pool, p domain.Pool) []int64 {
+	var lineage []int64
+	if p.ID != 0 {
+		lineage = append(lineage, p.ID)
+	}
+	for parent := p.ParentID; parent != nil && len(lineage) <= len(byID); {
+		lineage = append(lineage, *parent)
+		next, ok := byID[*parent]
+		if !ok {
+			break
+		}
+		parent = next.ParentID
+	}
+	return lineage
+}
+
+// poolSubtree returns the pools beneath id, which a cascading delete removes
+// along with it.
+func poolSubtree(ctx context.Context, st storage.Store, id int64) ([]domain.Pool, error) {
+	all, err := st.ListPools(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]domain.Pool, len(all))
+	for _, p := range all {
+		byID[p.ID] = p
+	}
+	var out []domain.Pool
+	for _, p := range all {
+		lineage := poolLineage(byID, p)
+		if len(lineage) > 1 && slices.Contains(lineage[1:], id) {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+// pendingHold returns the pending change request holding a block that
+// overlaps prefix under parentID, if any.
+func (s *Server) pendingHold(ctx context.Context, prefix string, parentID *int64) (*domain.PoolChangeRequest, error) {
+	if s.poolChanges == nil {
+		return nil, nil
+	}
+	held, err := s.poolChanges.ListPoolChangeRequests(ctx, domain.PoolChangeRequestPending)
+	if err != nil {
+		return nil, err
+	}
+	return storage.OverlappingHold(held, prefix, parentID), nil
+}
+
+// heldPrefixes returns the blocks pending change requests and active
+// reservations hold under parentID, so the allocator and resizes skip them.
+func (s *Server) heldPrefixes(ctx context.Context, parentID *int64) ([]netip.Prefix, error) {
+	var requests []domain.PoolChangeRequest
+	if s.poolChanges != nil {
+		var err error
+		requests, err = s.poolChanges.ListPoolChangeRequests(ctx, domain.PoolChangeRequestPending)
+		if err != nil {
+			return nil, err
+		}
+	}
+	reservations, err := activeReservations(ctx, s.reservations)
+	if err != nil {
+		return nil, err
+	}
+	return storage.HeldPrefixes(requests, reservations, parentID, time.Now()), nil
+}
+
+// queuePoolChange stores req as a pending change request and writes the
+// 202 response.
+func (s *Server) queuePoolChange(w http.ResponseWriter, r *http.Request, req domain.PoolChangeRequest) {
+	ctx := r.Context()
+	req.ID = uuid.NewString()
+	req.Status = domain.PoolChangeRequestPending
+	req.RequestedBy, req.RequestedRole = reviewActor(ctx)
+	req.RequestedByID = reviewActorID(ctx)
+	req.CreatedAt = time.Now().UTC()
+	if err := s.poolChanges.CreatePoolChangeRequest(ctx, req); err != nil {
+		s.writeStoreErr(ctx, w, err)
+		return
+	}
+	s.logger.InfoContext(ctx, "pools:change request created", appendRequestID(ctx, []any{
+		"change_request_id", req.ID,
+		"operation", req.Operation,
+		"pool_id", valueOrNil(req.PoolID),
+		"policies", strings.Join(req.PolicyIDs, ","),
+	})...)
+	s.logAuditWithChanges(ctx, audit.ActionCreate, audit.ResourcePoolChangeRequest, req.ID, req.PoolName,
+		&audit.Changes{After: map[string]any{
+			"operation":  string(req.Operation),
+			"pool_id":    valueOrNil(req.PoolID),
+			"cidr":       req.CIDR,
+			"policy_ids": req.PolicyIDs,
+		}}, http.StatusAccepted)
+	w.Header().Set("Location", "/api/v1/change-requests/"+req.ID)
+	writeJSON(w, http.StatusAccepted, req)
+}
+
+// proposePoolUpdate queues update as a change request when a policy covers
+// the pool as it is or as it would be. It reports whether it wrote the
+// response; a missing pool is left to the normal 404.
+func (s *Server) proposePoolUpdate(w http.ResponseWriter, r *http.Request, id int64, update domain.UpdatePool) bool {
+	ctx := r.Context()
+	policies, err := s.approvalPolicies(ctx)
+	if err == nil && len(policies) == 0 {
+		return false
+	}
+	var current domain.Pool
+	var found bool
+	if err == nil {
+		current, found, err = s.store.GetPool(ctx, id)
+	}
+	if err != nil {
+		s.writeErr(ctx, w, http.StatusInternalServerError, "internal error", err.Error())
+		return true
+	}
+	if !found {
+		return false
+	}
+	policyIDs, err := s.matchPolicies(ctx, policies, domain.PoolChangeUpdate, current, updatedPool(current, update))
+	if err != nil {
+		s.writeErr(ctx, w, http.StatusInternalServerError, "internal error", err.Error())
+		return true
+	}
+	if len(policyIDs) == 0 {
+		return false
+	}
+	version := current.Version
+	if update.IfVersion != nil {
+		version = *update.IfVersion
+	}
+	s.queuePoolChange(w, r, domain.PoolChangeRequest{
+		Operation:   domain.PoolChangeUpdate,
+		PoolID:      &id,
+		PoolVersion: version,
+		PoolName:    current.Name,
+		ParentID:    current.ParentID,
+		Update:      &update,
+		PolicyIDs:   policyIDs,
+	})
+	return true
+}
+
+// updatedPool returns p with update applied.
+func updatedPool(p domain.Pool, update domain.UpdatePool) domain.Pool {
+	if update.Name != nil {
+		p.Name = *update.Name
+	}
+	p.AccountID = update.AccountID
+	if update.Type != nil {
+		p.Type = *update.Type
+	}
+	if update.Status != nil {
+		p.Status = *update.Status
+	}
+	if update.Description != nil {
+		p.Description = *update.Description
+	}
+	if update.Tags != nil {
+		p.Tags = *update.Tags
+	}
+	return p
+}
+
+// proposePoolDelete queues the delete of id as a change request when a
+// policy covers the pool or, for a cascade, any pool beneath it. It reports
+// whether it wrote the response; a missing pool is left to the normal 404.
+func (s *Server) proposePoolDelete(w http.ResponseWriter, r *http.Request, id int64, cascade bool) bool {
+	ctx := r.Context()
+	policies, err := s.approvalPolicies(ctx)
+	if err == nil && len(policies) == 0 {
+		return false
+	}
+	var current domain.Pool
+	var found bool
+	if err == nil {
+		current, found, err = s.store.GetPool(ctx, id)
+	}
+	if err != nil {
+		s.writeErr(ctx, w, http.StatusInternalServerError, "internal error", err.Error())
+		return true
+	}
+	if !found {
+		return false
+	}
+	if !ifMatchAllows(r, current.Version) {
+		s.writeStoreErr(ctx, w, fmt.Errorf("pool %d is at version %d: %w", id, current.Version, storage.ErrPreconditionFailed))
+		return true
+	}
+	covered := []domain.Pool{current}
+	if cascade {
+		subtree, err := poolSubtree(ctx, s.store, id)
+		if err != nil {
+			s.writeErr(ctx, w, http.StatusInternalServerError, "internal error", err.Error())
+			return true
+		}
+		covered = append(covered, subtree...)
+	}
+	policyIDs, err := s.matchPolicies(ctx, policies, domain.PoolChangeDelete, covered...)
+	if err != nil {
+		s.writeErr(ctx, w, http.StatusInternalServerError, "internal error", err.Error())
+		return true
+	}
+	if len(policyIDs) == 0 {
+		return false
+	}
+	s.queuePoolChange(w, r, domain.PoolChangeRequest{
+		Operation:   domain.PoolChangeDelete,
+		PoolID:      &id,
+		PoolVersion: current.Version,
+		PoolName:    current.Name,
+		CIDR:        current.CIDR,
+		ParentID:    current.ParentID,
+		Cascade:     cascade,
+		PolicyIDs:   policyIDs,
+	})
+	return true
+}
+
+// pickHeldAllocation runs an allocator pick against the parent's current
+// children without creating the pool, for an allocation that a change
+// request will hold.
+func (s *Server) pickHeldAllocation(ctx context.Context, parentID int64, pick storage.AllocateFunc) (domain.CreatePool, error) {
+	parent, ok, err := s.store.GetPool(ctx, parentID)
+	if err != nil {
+		return domain.CreatePool{}, err
+	}
+	if !ok {
+		return domain.CreatePool{}, fmt.Errorf("pool %d: %w", parentID, storage.ErrNotFound)
+	}
+	children, err := findOverlappingPools(ctx, s.store, parent.CIDR, &parentID)
+	if err != nil {
+		return domain.CreatePool{}, err
+	}
+	create, err := pick(parent, children)
+	if err != nil {
+		return domain.CreatePool{}, err
+	}
+	create.ParentID = &parentID
+	return create, nil
+}
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	stdhttp "net/http"
+	"strings"
+	"testing"
+
+	"cloudpam/internal/auth"
+	"cloudpam/internal/domain"
+	"cloudpam/internal/observability"
+	"cloudpam/internal/storage"
+)
+
+func setupPoolChangeRequestServer(t *testing.T) (*stdhttp.ServeMux, *storage.MemoryStore) {
+	t.Helper()
+	st := storage.NewMemoryStore()
+	mux := stdhttp.NewServeMux()
+	logger := observability.NewLogger(observability.Config{Level: "info", Format: "json", Output: io.Discard})
+	srv := NewServer(mux, st, logger, nil, nil)
+	srv.registerUnprotectedTestRoutes()
+
+	settings := storage.NewMemorySettingsStore()
+	srv.SetSettingsStore(settings)
+	NewSettingsServer(srv, settings).RegisterSettingsRoutes()
+
+	requests := storage.NewMemoryPoolChangeRequestStore()
+	srv.SetPoolChangeRequestStore(requests)
+	NewPoolChangeRequestServer(srv, requests).RegisterPoolChangeRequestRoutesNoAuth()
+	return mux, st
+}
+
+func decodeChangeRequest(t *testing.T, body []byte) domain.PoolChangeRequest {
+	t.Helper()
+	var cr domain.PoolChangeRequest
+	if err := json.Unmarshal(body, &cr); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return cr
+}
+
+func TestPoolChangeRequest_CreateHoldsCIDRUntilApproved(t *testing.T) {
+	mux, st := setupPoolChangeRequestServer(t)
+	doJSON(t, mux, stdhttp.MethodPatch, "/api/v1/settings/approvals",
+		`{"policies":[{"id":"prod-vpcs","name":"Production VPCs","enabled":true,"pool_types":["vpc"],"tags":{"env":"prod"}}]}`,
+		stdhttp.StatusOK)
+	doJSON(t, mux, stdhttp.MethodPost, "/api/v1/pools", `{"name":"root","cidr":"10.0.0.0/8","type":"supernet"}`, stdhttp.StatusCreated)
+
+	rr := doJSONAs(t, mux, "alice", auth.RoleOperator, stdhttp.MethodPost, "/api/v1/pools",
+		`{"name":"prod-vpc","cidr":"10.0.0.0/16","parent_id":1,"type":"vpc","tags":{"env":"prod"}}`, stdhttp.StatusAccepted)
+	cr := decodeChangeRequest(t, rr.Body.Bytes())
+	if cr.Status != domain.PoolChangeRequestPending || cr.Operation != domain.PoolChangeCreate || cr.RequestedBy != "alice" ||
+		cr.CIDR != "10.0.0.0/16" || len(cr.PolicyIDs) != 1 || cr.PolicyIDs[0] != "prod-vpcs" {
+		t.Fatalf("change request = %+v", cr)
+	}
+	if loc := rr.Header().Get("Location"); loc != "/api/v1/change-requests/"+cr.ID {
+		t.Fatalf("Location = %q", loc)
+	}
+	if pools, _ := st.ListPools(t.Context()); len(pools) != 1 {
+		t.Fatalf("pending create made a pool: %+v", pools)
+	}
+
+	// The held block is taken for direct creates and skipped by the allocator.
+	rr = doJSON(t, mux, stdhttp.MethodPost, "/api/v1/pools", `{"name":"dev","cidr":"10.0.0.0/20","parent_id":1}`, stdhttp.StatusBadRequest)
+	if !strings.Contains(rr.Body.String(), cr.ID) {
+		t.Fatalf("overlap error does not name the hold: %s", rr.Body.String())
+	}
+	rr = doJSON(t, mux, stdhttp.MethodPost, "/api/v1/pools/1/allocate", `{"name":"dev","prefix_length":16}`, stdhttp.StatusCreated)
+	var allocated domain.Pool
+	if err := json.Unmarshal(rr.Body.Bytes(), &allocated); err != nil {
+		t.Fatal(err)
+	}
+	if allocated.CIDR != "10.1.0.0/16" {
+		t.Fatalf("allocated %s, want the block after the hold", allocated.CIDR)
+	}
+
+	path := "/api/v1/change-requests/" + cr.ID
+	doJSONAs(t, mux, "alice", auth.RoleOperator, stdhttp.MethodPost, path+"/approve", "", stdhttp.StatusForbidden)
+	rr = doJSONAs(t, mux, "bob", auth.RoleOperator, stdhttp.MethodPost, path+"/approve", `{"comment":"ok"}`, stdhttp.StatusOK)
+	cr = decodeChangeRequest(t, rr.Body.Bytes())
+	if cr.Status != domain.PoolChangeRequestApplied || cr.ReviewedBy != "bob" || cr.ReviewComment != "ok" || cr.PoolID == nil {
+		t.Fatalf("approved request = %+v", cr)
+	}
+	p, ok, err := st.GetPool(t.Context(), *cr.PoolID)
+	if err != nil || !ok || p.CIDR != "10.0.0.0/16" || p.Type != domain.PoolTypeVPC {
+		t.Fatalf("created pool = %+v, %v, %v", p, ok, err)
+	}
+	doJSONAs(t, mux, "carol", auth.RoleAdmin, stdhttp.MethodPost, path+"/approve", "", stdhttp.StatusConflict)
+
+	rr = doJSON(t, mux, stdhttp.MethodGet, "/api/v1/change-requests?status=applied", "", stdhttp.StatusOK)
+	var list domain.PoolChangeRequestListResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &list); err != nil || len(list.Items) != 1 {
+		t.Fatalf("list = %s, %v", rr.Body.String(), err)
+	}
+	doJSON(t, mux, stdhttp.MethodGet, "/api/v1/change-requests?status=bogus", "", stdhttp.StatusBadRequest)
+}
+
+func TestPoolChangeRequest_RequesterCannotApproveWithOwnKey(t *testing.T) {
+	mux, _ := setupPoolChangeRequestServer(t)
+	doJSON(t, mux, stdhttp.MethodPatch, "/api/v1/settings/approvals",
+		`{"policies":[{"id":"all-creates","name":"All creates","enabled":true,"operations":["create"]}]}`,
+		stdhttp.StatusOK)
+
+	rr := doJSONAs(t, mux, "alice", auth.RoleAdmin, stdhttp.MethodPost, "/api/v1/pools",
+		`{"name":"root","cidr":"10.0.0.0/8"}`, stdhttp.StatusAccepted)
+	cr := decodeChangeRequest(t, rr.Body.Bytes())
+	if cr.RequestedByID != "user:alice" {
+		t.Fatalf("requested_by_id = %q", cr.RequestedByID)
+	}
+	approve := "/api/v1/change-requests/" + cr.ID + "/approve"
+
+	// alice's own key is alice, whatever it is named.
+	owner := "alice"
+	for _, name := range []string{"alice-ci", "bob"} {
+		key := &auth.APIKey{ID: "key-" + name, Name: name, Scopes: []string{"*"}, OwnerID: &owner}
+		doJSONAsKey(t, mux, key, stdhttp.MethodPost, approve, "", stdhttp.StatusForbidden)
+	}
+
+	bobOwner := "bob"
+	bobKey := &auth.APIKey{ID: "key-b", Name: "alice-ci", Scopes: []string{"*"}, OwnerID: &bobOwner}
+	rr = doJSONAsKey(t, mux, bobKey, stdhttp.MethodPost, approve, "", stdhttp.StatusOK)
+	if cr = decodeChangeRequest(t, rr.Body.Bytes()); cr.ReviewedBy != "apikey:alice-ci" || cr.ReviewedByID != "user:bob" {
+		t.Fatalf("approved request = %+v", cr)
+	}
+}
+
+func TestPoolChangeRequest_UpdateAndDelete(t *testing.T) {
+	mux, st := setupPoolChangeRequestServer(t)
+	doJSON(t, mux, stdhttp.MethodPost, "/api/v1/pools", `{"name":"root","cidr":"10.0.0.0/8","type":"supernet"}`, stdhttp.StatusCreated)
+	doJSON(t, mux, stdhttp.MethodPost, "/api/v1/pools", `{"name":"vpc","cidr":"10.0.0.0/16","parent_id":1,"type":"vpc"}`, stdhttp.StatusCreated)
+	doJSON(t, mux, stdhttp.MethodPost, "/api/v1/pools", `{"name":"lab","cidr":"192.168.0.0/16"}`, stdhttp.StatusCreated)
+	doJSON(t, mux, stdhttp.MethodPatch, "/api/v1/settings/approvals",
+		`{"policies":[{"id":"root-tree","name":"Root subtree","enabled":true,"operations":["update","delete"],"subtree_pool_id":1}]}`,
+		stdhttp.StatusOK)
+
+	// Pools outside the subtree change at once.
+	doJSON(t, mux, stdhttp.MethodPatch, "/api/v1/pools/3", `{"name":"lab-2"}`, stdhttp.StatusOK)
+
+	rr := doJSONAs(t, mux, "alice", auth.RoleOperator, stdhttp.MethodPatch, "/api/v1/pools/2", `{"status":"deprecated"}`, stdhttp.StatusAccepted)
+	update := decodeChangeRequest(t, rr.Body.Bytes())
+	if update.Operation != domain.PoolChangeUpdate || *update.PoolID != 2 || update.PoolVersion != 1 {
+		t.Fatalf("update request = %+v", update)
+	}
+	doJSONAs(t, mux, "bob", auth.RoleAdmin, stdhttp.MethodPost, "/api/v1/change-requests/"+update.ID+"/approve", "", stdhttp.StatusOK)
+	if p, _, _ := st.GetPool(t.Context(), 2); p.Status != domain.PoolStatusDeprecated || p.Version != 2 {
+		t.Fatalf("updated pool = %+v", p)
+	}
+
+	// An update requested against an older version fails on approval.
+	rr = doJSONAs(t, mux, "alice", auth.RoleOperator, stdhttp.MethodPatch, "/api/v1/pools/2", `{"name":"vpc-a"}`, stdhttp.StatusAccepted)
+	stale := decodeChangeRequest(t, rr.Body.Bytes())
+	name := "vpc-b"
+	if _, _, err := st.UpdatePool(t.Context(), 2, domain.UpdatePool{Name: &name}); err != nil {
+		t.Fatal(err)
+	}
+	doJSONAs(t, mux, "bob", auth.RoleAdmin, stdhttp.MethodPost, "/api/v1/change-requests/"+stale.ID+"/approve", "", stdhttp.StatusPreconditionFailed)
+
+	// Requesters may withdraw their own delete.
+	rr = doJSONAs(t, mux, "alice", auth.RoleOperator, stdhttp.MethodDelete, "/api/v1/pools/1?force=true", "", stdhttp.StatusAccepted)
+	del := decodeChangeRequest(t, rr.Body.Bytes())
+	if del.Operation != domain.PoolChangeDelete || !del.Cascade {
+		t.Fatalf("delete request = %+v", del)
+	}
+	doJSONAs(t, mux, "alice", auth.RoleOperator, stdhttp.MethodPost, "/api/v1/change-requests/"+del.ID+"/reject", `{"comment":"wrong pool"}`, stdhttp.StatusOK)
+	if _, ok, _ := st.GetPool(t.Context(), 1); !ok {
+		t.Fatal("rejected delete removed the pool")
+	}
+
+	rr = doJSON(t, mux, stdhttp.MethodGet, "/api/v1/change-requests?status=pending", "", stdhttp.StatusOK)
+	var list domain.PoolChangeRequestListResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &list); err != nil || len(list.Items) != 1 || list.Items[0].ID != stale.ID {
+		t.Fatalf("pending = %s, %v", rr.Body.String(), err)
+	}
+	doJSON(t, mux, stdhttp.MethodGet, fmt.Sprintf("/api/v1/change-requests/%s", del.ID), "", stdhttp.StatusOK)
+}
+
+func TestApprovalSettings_Validation(t *testing.T) {
+	mux, _ := setupPoolChangeRequestServer(t)
+	rr := doJSON(t, mux, stdhttp.MethodGet, "/api/v1/settings/approvals", "", stdhttp.StatusOK)
+	if strings.TrimSpace(rr.Body.String()) != `{"policies":[]}` {
+		t.Fatalf("default approvals = %s", rr.Body.String())
+	}
+	doJSON(t, mux, stdhttp.MethodPatch, "/api/v1/settings/approvals",
+		`{"policies":[{"id":"a","name":"A","operations":["move"]}]}`, stdhttp.StatusBadRequest)
+}
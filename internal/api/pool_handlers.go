@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,10 +10,12 @@ import (
 	"net/netip"
 	"strconv"
 	"strings"
+	"time"
 
 	"cloudpam/internal/audit"
 	"cloudpam/internal/auth"
 	"cloudpam/internal/domain"
+	"cloudpam/internal/planning"
 	"cloudpam/internal/storage"
 	"cloudpam/internal/validation"
 )
@@ -95,6 +98,22 @@ func (s *Server) protectedPoolsSubroutesHandler(logger *slog.Logger) http.Handle
 			return
 		}
 
+		// Handle /pools/{id}/allocate
+		if len(parts) >= 2 && parts[1] == "allocate" {
+			if r.Method != http.MethodPost {
+				w.Header().Set("Allow", http.MethodPost)
+				s.writeErr(ctx, w, http.StatusMethodNotAllowed, "method not allowed", "")
+				return
+			}
+			// Requires pools:create
+			if !auth.HasPermissionContext(ctx, role, auth.ResourcePools, auth.ActionCreate) {
+				writeJSON(w, http.StatusForbidden, apiError{Error: "forbidden"})
+				return
+			}
+			s.allocatePool(w, r, id64)
+			return
+		}
+
 		// Handle /pools/{id}/stats
 		if len(parts) >= 2 && parts[1] == "stats" {
 			if r.Method != http.MethodGet {
@@ -126,6 +145,7 @@ func (s *Server) protectedPoolsSubroutesHandler(logger *slog.Logger) http.Handle
 				s.writeErr(ctx, w, http.StatusNotFound, "not found", "")
 				return
 			}
+			w.Header().Set("ETag", etag(p.Version))
 			writeJSON(w, http.StatusOK, p)
 
 		case http.MethodPatch:
@@ -140,15 +160,12 @@ func (s *Server) protectedPoolsSubroutesHandler(logger *slog.Logger) http.Handle
 				writeJSON(w, http.StatusForbidden, apiError{Error: "forbidden"})
 				return
 			}
-			var ok bool
-			force := strings.ToLower(r.URL.Query().Get("force"))
-			if force == "1" || force == "true" || force == "yes" {
-				ok, err = s.store.DeletePoolCascade(ctx, id64)
-			} else {
-				ok, err = s.store.DeletePool(ctx, id64)
+			if s.proposePoolDelete(w, r, id64, isTruthy(r.URL.Query().Get("force"))) {
+				return
 			}
+			ok, err := s.deletePool(ctx, r, id64, isTruthy(r.URL.Query().Get("force")))
 			if err != nil {
-				s.writeErr(ctx, w, http.StatusConflict, err.Error(), "")
+				s.writeDeleteErr(ctx, w, err)
 				return
 			}
 			if !ok {
@@ -178,6 +195,7 @@ func (s *Server) handlePools(w http.ResponseWriter, r *http.Request) {
 // /api/v1/pools/{id}/blocks?new_prefix_len=24
 // /api/v1/pools/hierarchy - returns pool hierarchy tree
 // /api/v1/pools/{id}/stats - returns pool statistics
+// /api/v1/pools/{id}/allocate - carves the next free child out of a pool
 func (s *Server) handlePoolsSubroutes(w http.ResponseWriter, r *http.Request) {
 	path := strings.TrimPrefix(r.URL.Path, "/api/v1/pools/")
 	parts := strings.Split(strings.Trim(path, "/"), "/")
@@ -209,6 +227,16 @@ func (s *Server) handlePoolsSubroutes(w http.ResponseWriter, r *http.Request) {
 		s.blocksForPool(w, r, id64)
 		return
 	}
+	// Handle /api/v1/pools/{id}/allocate
+	if len(parts) >= 2 && parts[1] == "allocate" {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			s.writeErr(r.Context(), w, http.StatusMethodNotAllowed, "method not allowed", "")
+			return
+		}
+		s.allocatePool(w, r, id64)
+		return
+	}
 	// Handle /api/v1/pools/{id}/stats
 	if len(parts) >= 2 && parts[1] == "stats" {
 		if r.Method != http.MethodGet {
@@ -230,21 +258,19 @@ func (s *Server) handlePoolsSubroutes(w http.ResponseWriter, r *http.Request) {
 			s.writeErr(r.Context(), w, http.StatusNotFound, "not found", "")
 			return
 		}
+		w.Header().Set("ETag", etag(p.Version))
 		writeJSON(w, http.StatusOK, p)
 	case http.MethodPatch:
 		s.updatePool(w, r, id64)
 	case http.MethodDelete:
+		if s.proposePoolDelete(w, r, id64, isTruthy(r.URL.Query().Get("force"))) {
+			return
+		}
 		// Get pool info before delete for audit logging
 		pool, poolFound, _ := s.store.GetPool(r.Context(), id64)
-		var ok bool
-		force := strings.ToLower(r.URL.Query().Get("force"))
-		if force == "1" || force == "true" || force == "yes" {
-			ok, err = s.store.DeletePoolCascade(r.Context(), id64)
-		} else {
-			ok, err = s.store.DeletePool(r.Context(), id64)
-		}
+		ok, err := s.deletePool(r.Context(), r, id64, isTruthy(r.URL.Query().Get("force")))
 		if err != nil {
-			s.writeErr(r.Context(), w, http.StatusConflict, err.Error(), "")
+			s.writeDeleteErr(r.Context(), w, err)
 			return
 		}
 		if !ok {
@@ -375,6 +401,12 @@ func (s *Server) updatePool(w http.ResponseWriter, r *http.Request, id int64) {
 		return
 	}
 
+	ifVersion, err := expectedPoolVersion(ctx, s.store, r, id)
+	if err != nil {
+		s.writeStoreErr(ctx, w, err)
+		return
+	}
+
 	update := domain.UpdatePool{
 		Name:        payload.Name,
 		AccountID:   accountID,
@@ -382,9 +414,17 @@ func (s *Server) updatePool(w http.ResponseWriter, r *http.Request, id int64) {
 		Status:      payload.Status,
 		Description: payload.Description,
 		Tags:        payload.Tags,
+		IfVersion:   ifVersion,
+	}
+	if s.proposePoolUpdate(w, r, id, update) {
+		return
 	}
 
 	p, ok, err := s.store.UpdatePool(ctx, id, update)
+	if errors.Is(err, storage.ErrPreconditionFailed) {
+		s.writeStoreErr(ctx, w, err)
+		return
+	}
 	if err != nil {
 		s.writeErr(ctx, w, http.StatusBadRequest, err.Error(), "")
 		return
@@ -394,22 +434,26 @@ func (s *Server) updatePool(w http.ResponseWriter, r *http.Request, id int64) {
 		return
 	}
 	s.logAudit(ctx, audit.ActionUpdate, audit.ResourcePool, fmt.Sprintf("%d", p.ID), p.Name, http.StatusOK)
+	w.Header().Set("ETag", etag(p.Version))
 	writeJSON(w, http.StatusOK, p)
 }
 
 func (s *Server) listPools(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	opts, pg, err := parsePoolQuery(r.URL.Query())
+	if err != nil {
+		s.writeErr(ctx, w, http.StatusBadRequest, err.Error(), "")
+		return
+	}
+	pools, total, err := s.queryPools(ctx, opts)
+	if err != nil {
+		s.writeStoreErr(ctx, w, err)
+		return
+	}
 
 	// Check for include_stats query param
-	includeStats := strings.ToLower(r.URL.Query().Get("include_stats"))
-	if includeStats == "true" || includeStats == "1" || includeStats == "yes" {
-		// Return all pools with stats (flat list, not hierarchy)
-		pools, err := s.store.ListPools(ctx)
-		if err != nil {
-			s.writeErr(ctx, w, http.StatusInternalServerError, "internal error", err.Error())
-			return
-		}
-
+	if isTruthy(r.URL.Query().Get("include_stats")) {
+		// Return the matching pools with stats (flat list, not hierarchy)
 		type poolWithStats struct {
 			domain.Pool
 			Stats domain.PoolStats `json:"stats"`
@@ -424,19 +468,10 @@ func (s *Server) listPools(w http.ResponseWriter, r *http.Request) {
 			}
 			result = append(result, poolWithStats{Pool: p, Stats: *stats})
 		}
-		writeJSON(w, http.StatusOK, result)
+		writeList(w, result, total, pg)
 		return
 	}
-
-	pools, err := s.store.ListPools(ctx)
-	if err != nil {
-		s.writeErr(ctx, w, http.StatusInternalServerError, "internal error", err.Error())
-		return
-	}
-	if pools == nil {
-		pools = []domain.Pool{}
-	}
-	writeJSON(w, http.StatusOK, pools)
+	writeList(w, pools, total, pg)
 }
 
 func (s *Server) createPool(w http.ResponseWriter, r *http.Request) {
@@ -461,7 +496,7 @@ func (s *Server) createPool(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate CIDR format, IPv4, reserved ranges, and prefix bounds
+	// Validate CIDR format, reserved ranges, and prefix bounds
 	if err := validation.ValidateCIDR(in.CIDR); err != nil {
 		logger.WarnContext(ctx, "pools:create invalid cidr", appendRequestID(ctx, []any{
 			"cidr", in.CIDR,
@@ -498,7 +533,7 @@ func (s *Server) createPool(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// If ParentID provided, ensure child CIDR is subset of parent CIDR (IPv4 only for now).
+	// If ParentID provided, ensure child CIDR is subset of parent CIDR.
 	if in.ParentID != nil {
 		parent, ok, err := s.store.GetPool(ctx, *in.ParentID)
 		if err != nil {
@@ -527,45 +562,98 @@ func (s *Server) createPool(w http.ResponseWriter, r *http.Request) {
 	// Overlap protection: disallow any overlapping CIDRs within the same parent scope
 	// (i.e., among pools sharing the same parent_id, or among top-level pools).
 	{
-		pfxNew, _ := netip.ParsePrefix(in.CIDR)
-		if !pfxNew.Addr().Is4() {
-			s.writeErr(r.Context(), w, http.StatusBadRequest, "only ipv4 supported for now", "")
-			return
+		scope := in.ParentID
+		if scope == nil {
+			scope = new(int64) // top-level pools
 		}
-		all, err := s.store.ListPools(ctx)
+		overlapping, err := findOverlappingPools(ctx, s.store, in.CIDR, scope)
 		if err != nil {
 			s.writeErr(r.Context(), w, http.StatusInternalServerError, "internal error", err.Error())
 			return
 		}
-		for _, p := range all {
-			if in.ParentID == nil {
-				if p.ParentID != nil {
-					continue
-				}
-			} else {
-				if p.ParentID == nil || *p.ParentID != *in.ParentID {
-					continue
-				}
-			}
+		for _, p := range overlapping {
 			// Skip comparing with an exact duplicate; DB uniqueness should also catch
 			if strings.EqualFold(strings.TrimSpace(p.CIDR), in.CIDR) {
 				continue
 			}
-			old, err := netip.ParsePrefix(p.CIDR)
-			if err != nil || !old.Addr().Is4() {
-				continue
-			}
-			if prefixesOverlapIPv4(old, pfxNew) {
-				logger.WarnContext(ctx, "pools:create cidr overlap", appendRequestID(ctx, []any{
-					"candidate_cidr", in.CIDR,
-					"existing_pool_id", p.ID,
-					"existing_cidr", p.CIDR,
-				})...)
-				s.writeErr(r.Context(), w, http.StatusBadRequest, "cidr overlaps with existing block", fmt.Sprintf("conflicts with pool #%d (%s)", p.ID, p.CIDR))
-				return
-			}
+			logger.WarnContext(ctx, "pools:create cidr overlap", appendRequestID(ctx, []any{
+				"candidate_cidr", in.CIDR,
+				"existing_pool_id", p.ID,
+				"existing_cidr", p.CIDR,
+			})...)
+			s.writeErr(r.Context(), w, http.StatusBadRequest, "cidr overlaps with existing block", fmt.Sprintf("conflicts with pool #%d (%s)", p.ID, p.CIDR))
+			return
+		}
+		// Pending change requests hold their blocks as if already created.
+		hold, err := s.pendingHold(ctx, in.CIDR, in.ParentID)
+		if err != nil {
+			s.writeErr(r.Context(), w, http.StatusInternalServerError, "internal error", err.Error())
+			return
+		}
+		if hold != nil {
+			logger.WarnContext(ctx, "pools:create cidr held", appendRequestID(ctx, []any{
+				"candidate_cidr", in.CIDR,
+				"change_request_id", hold.ID,
+				"held_cidr", hold.CIDR,
+			})...)
+			s.writeErr(r.Context(), w, http.StatusBadRequest, "cidr overlaps with existing block", fmt.Sprintf("held by change request %s (%s)", hold.ID, hold.CIDR))
+			return
+		}
+		// So do active reservations, until they are released or expire.
+		reserved, err := s.reservedHold(ctx, in.CIDR, in.ParentID)
+		if err != nil {
+			s.writeErr(r.Context(), w, http.StatusInternalServerError, "internal error", err.Error())
+			return
+		}
+		if reserved != nil {
+			logger.WarnContext(ctx, "pools:create cidr reserved", appendRequestID(ctx, []any{
+				"candidate_cidr", in.CIDR,
+				"reservation_id", reserved.ID,
+				"reserved_cidr", reserved.CIDR,
+			})...)
+			s.writeErr(r.Context(), w, http.StatusBadRequest, "cidr overlaps with existing block",
+				fmt.Sprintf("reserved for %s by reservation %s (%s) until %s", reserved.Owner, reserved.ID, reserved.CIDR, reserved.ExpiresAt.Format(time.RFC3339)))
+			return
 		}
 	}
+	// Compliance rules marked enforce reject the pool outright.
+	if s.admission != nil {
+		violations, err := s.admission.CheckPoolAdmission(ctx, in)
+		if err != nil {
+			s.writeErr(r.Context(), w, http.StatusInternalServerError, "internal error", err.Error())
+			return
+		}
+		if len(violations) > 0 {
+			s.writeComplianceRejection(ctx, w, "pools:create", in.CIDR, violations)
+			return
+		}
+	}
+	// Approval policies turn the create into a change request that holds
+	// the block until it is decided.
+	policies, err := s.approvalPolicies(ctx)
+	if err != nil {
+		s.writeErr(r.Context(), w, http.StatusInternalServerError, "internal error", err.Error())
+		return
+	}
+	policyIDs, err := s.matchPolicies(ctx, policies, domain.PoolChangeCreate, domain.Pool{
+		Name: in.Name, CIDR: in.CIDR, ParentID: in.ParentID, AccountID: in.AccountID,
+		Type: in.Type, Status: in.Status, Tags: in.Tags,
+	})
+	if err != nil {
+		s.writeErr(r.Context(), w, http.StatusInternalServerError, "internal error", err.Error())
+		return
+	}
+	if len(policyIDs) > 0 {
+		s.queuePoolChange(w, r, domain.PoolChangeRequest{
+			Operation: domain.PoolChangeCreate,
+			PoolName:  in.Name,
+			CIDR:      in.CIDR,
+			ParentID:  in.ParentID,
+			Create:    &in,
+			PolicyIDs: policyIDs,
+		})
+		return
+	}
 	p, err := s.store.CreatePool(ctx, in)
 	if err != nil {
 		logger.WarnContext(ctx, "pools:create storage error", appendRequestID(ctx, []any{
@@ -588,3 +676,228 @@ func (s *Server) createPool(w http.ResponseWriter, r *http.Request) {
 	s.logAudit(ctx, audit.ActionCreate, audit.ResourcePool, fmt.Sprintf("%d", p.ID), p.Name, http.StatusCreated)
 	writeJSON(w, http.StatusCreated, p)
 }
+
+// complianceRejection is the 400 body for a pool rejected by enforced
+// compliance rules.
+type complianceRejection struct {
+	apiError
+	Violations []planning.ComplianceViolation `json:"violations"`
+}
+
+// writeComplianceRejection logs the rules that rejected a pool at cidr and
+// writes the 400 response.
+func (s *Server) writeComplianceRejection(ctx context.Context, w http.ResponseWriter, op, cidr string, violations []planning.ComplianceViolation) {
+	ids := make([]string, len(violations))
+	for i, v := range violations {
+		ids[i] = v.RuleID
+	}
+	s.logger.WarnContext(ctx, op+" compliance violation", appendRequestID(ctx, []any{
+		"cidr", cidr,
+		"rules", strings.Join(ids, ","),
+	})...)
+	writeJSON(w, http.StatusBadRequest, complianceRejection{
+		apiError:   apiError{Error: "pool violates compliance rules", Detail: violations[0].RuleID + ": " + violations[0].Message},
+		Violations: violations,
+	})
+}
+
+// errAllocationMoved aborts an allocation whose block changed after it
+// passed admission, so allocateAdmitted can pick and check again.
+var errAllocationMoved = errors.New("allocated block changed during admission")
+
+// allocateAdmitted allocates a child of parentID whose block has passed the
+// admission checks. Admission reads the store, so it cannot run inside the
+// allocator's transaction: the block is picked and checked first, and the
+// allocation only goes ahead if the allocator picks the same block. If a
+// concurrent write moved it, the pick is checked again.
+func (s *Server) allocateAdmitted(ctx context.Context, allocator storage.PoolAllocator, parentID int64, pick storage.AllocateFunc) (domain.Pool, []planning.ComplianceViolation, error) {
+	if s.admission == nil {
+		p, err := allocator.AllocatePool(ctx, parentID, pick)
+		return p, nil, err
+	}
+	for attempt := 1; ; attempt++ {
+		create, err := s.pickHeldAllocation(ctx, parentID, pick)
+		if err != nil {
+			return domain.Pool{}, nil, err
+		}
+		violations, err := s.admission.CheckPoolAdmission(ctx, create)
+		if err != nil || len(violations) > 0 {
+			return domain.Pool{}, violations, err
+		}
+		p, err := allocator.AllocatePool(ctx, parentID, func(parent domain.Pool, children []domain.Pool) (domain.CreatePool, error) {
+			in, err := pick(parent, children)
+			if err == nil && in.CIDR != create.CIDR {
+				return domain.CreatePool{}, errAllocationMoved
+			}
+			return in, err
+		})
+		if !errors.Is(err, errAllocationMoved) {
+			return p, nil, err
+		}
+		if attempt == 3 {
+			return domain.Pool{}, nil, fmt.Errorf("%v: %w", err, storage.ErrConflict)
+		}
+	}
+}
+
+// allocatePool handles POST /api/v1/pools/{id}/allocate. It picks a free block
+// of the requested prefix length inside the parent and creates the child pool
+// in one store transaction, so concurrent callers never receive the same CIDR.
+func (s *Server) allocatePool(w http.ResponseWriter, r *http.Request, parentID int64) {
+	ctx := r.Context()
+	logger := s.logger
+	var in domain.AllocatePool
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		s.writeErr(ctx, w, http.StatusBadRequest, "invalid json", "")
+		return
+	}
+	in.Name = strings.TrimSpace(in.Name)
+
+	if err := validation.ValidateName(in.Name); err != nil {
+		s.writeErr(ctx, w, http.StatusBadRequest, err.Error(), "")
+		return
+	}
+	// The family-specific bounds are checked against the parent below.
+	if in.PrefixLength <= 0 || in.PrefixLength > 128 {
+		s.writeErr(ctx, w, http.StatusBadRequest, "invalid prefix_length", "must be between 1 and 128")
+		return
+	}
+	strategy := planning.StrategyFirstFit
+	if in.Strategy != "" {
+		strategy = planning.AllocationStrategy(in.Strategy)
+		if !planning.IsValidAllocationStrategy(strategy) {
+			s.writeErr(ctx, w, http.StatusBadRequest, "invalid strategy", fmt.Sprintf("valid strategies: %v", planning.ValidAllocationStrategies))
+			return
+		}
+	}
+	if in.Type != "" && !domain.IsValidPoolType(in.Type) {
+		s.writeErr(ctx, w, http.StatusBadRequest, "invalid pool type", fmt.Sprintf("valid types: %v", domain.ValidPoolTypes))
+		return
+	}
+	if in.Status != "" && !domain.IsValidPoolStatus(in.Status) {
+		s.writeErr(ctx, w, http.StatusBadRequest, "invalid pool status", fmt.Sprintf("valid statuses: %v", domain.ValidPoolStatuses))
+		return
+	}
+
+	if in.AccountID != nil {
+		if _, found, err := s.store.GetAccount(ctx, *in.AccountID); err != nil {
+			s.writeErr(ctx, w, http.StatusInternalServerError, "internal error", err.Error())
+			return
+		} else if !found {
+			s.writeErr(ctx, w, http.StatusBadRequest, "account not found", "")
+			return
+		}
+	}
+
+	allocator, ok := s.store.(storage.PoolAllocator)
+	if !ok {
+		s.writeErr(ctx, w, http.StatusNotImplemented, "allocation not supported by this store", "")
+		return
+	}
+	held, err := s.heldPrefixes(ctx, &parentID)
+	if err != nil {
+		s.writeErr(ctx, w, http.StatusInternalServerError, "internal error", err.Error())
+		return
+	}
+	policies, err := s.approvalPolicies(ctx)
+	if err != nil {
+		s.writeErr(ctx, w, http.StatusInternalServerError, "internal error", err.Error())
+		return
+	}
+	policyIDs, err := s.matchPolicies(ctx, policies, domain.PoolChangeCreate, domain.Pool{
+		Name: in.Name, ParentID: &parentID, AccountID: in.AccountID, Type: in.Type, Status: in.Status, Tags: in.Tags,
+	})
+	if err != nil {
+		s.writeErr(ctx, w, http.StatusInternalServerError, "internal error", err.Error())
+		return
+	}
+
+	pick := func(parent domain.Pool, children []domain.Pool) (domain.CreatePool, error) {
+		parentPfx, err := netip.ParsePrefix(parent.CIDR)
+		if err != nil {
+			return domain.CreatePool{}, fmt.Errorf("invalid parent cidr %q: %w", parent.CIDR, err)
+		}
+		if in.PrefixLength <= parentPfx.Bits() {
+			return domain.CreatePool{}, fmt.Errorf("prefix_length /%d must be longer than parent /%d: %w", in.PrefixLength, parentPfx.Bits(), storage.ErrValidation)
+		}
+		if minLen, maxLen := validation.PrefixBounds(parentPfx.Addr().Is4(), validation.CIDROptions{}); in.PrefixLength < minLen || in.PrefixLength > maxLen {
+			return domain.CreatePool{}, fmt.Errorf("prefix_length must be between %d and %d: %w", minLen, maxLen, storage.ErrValidation)
+		}
+		occupied := make([]netip.Prefix, 0, len(children)+len(held))
+		for _, c := range children {
+			if cp, err := netip.ParsePrefix(c.CIDR); err == nil {
+				occupied = append(occupied, cp)
+			}
+		}
+		occupied = append(occupied, held...)
+		pfx, err := planning.NextAvailablePrefix(parentPfx, occupied, in.PrefixLength, strategy)
+		if err != nil {
+			return domain.CreatePool{}, err
+		}
+		return domain.CreatePool{
+			Name:        in.Name,
+			CIDR:        pfx.String(),
+			AccountID:   in.AccountID,
+			Type:        in.Type,
+			Status:      in.Status,
+			Description: in.Description,
+			Tags:        in.Tags,
+		}, nil
+	}
+
+	var p domain.Pool
+	if len(policyIDs) > 0 {
+		// The block is picked now and held by the change request.
+		var create domain.CreatePool
+		var violations []planning.ComplianceViolation
+		if create, err = s.pickHeldAllocation(ctx, parentID, pick); err == nil && s.admission != nil {
+			violations, err = s.admission.CheckPoolAdmission(ctx, create)
+		}
+		if len(violations) > 0 {
+			s.writeComplianceRejection(ctx, w, "pools:allocate", create.CIDR, violations)
+			return
+		}
+		if err == nil {
+			s.queuePoolChange(w, r, domain.PoolChangeRequest{
+				Operation: domain.PoolChangeCreate,
+				PoolName:  create.Name,
+				CIDR:      create.CIDR,
+				ParentID:  create.ParentID,
+				Create:    &create,
+				PolicyIDs: policyIDs,
+			})
+			return
+		}
+	} else {
+		var violations []planning.ComplianceViolation
+		p, violations, err = s.allocateAdmitted(ctx, allocator, parentID, pick)
+		if len(violations) > 0 {
+			s.writeComplianceRejection(ctx, w, "pools:allocate", violations[0].CIDR, violations)
+			return
+		}
+	}
+	if err != nil {
+		logger.WarnContext(ctx, "pools:allocate failed", appendRequestID(ctx, []any{
+			"parent_id", parentID,
+			"prefix_length", in.PrefixLength,
+			"strategy", strategy,
+			"error", err.Error(),
+		})...)
+		if errors.Is(err, planning.ErrPoolExhausted) {
+			s.writeErr(ctx, w, http.StatusConflict, "pool exhausted", err.Error())
+			return
+		}
+		s.writeStoreErr(ctx, w, err)
+		return
+	}
+	logger.InfoContext(ctx, "pools:allocate success", appendRequestID(ctx, []any{
+		"id", p.ID,
+		"name", p.Name,
+		"cidr", p.CIDR,
+		"parent_id", parentID,
+		"strategy", strategy,
+	})...)
+	s.logAuditWithChanges(ctx, audit.ActionAllocate, audit.ResourcePool, fmt.Sprintf("%d", p.ID), p.Name,
+		&audit.Changes{After: map[string]any{"cidr": p.CIDR, "parent_id": parentID, "strategy": string(strategy)}}, http.StatusCreated)
+	writeJSON(w, http.StatusCreated, p)
+}
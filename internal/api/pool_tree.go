@@ -0,0 +1,342 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"cloudpam/internal/audit"
+	"cloudpam/internal/auth"
+	"cloudpam/internal/domain"
+	"cloudpam/internal/planning"
+	"cloudpam/internal/storage"
+	"cloudpam/internal/validation"
+)
+
+// Pool trees are what the schema planner and AI plans apply: a
+// topologically ordered list of pools created in one transaction. The
+// helpers here validate a tree, dry-run it into a change set, create it,
+// and store it as a change proposal for review.
+
+// validatePoolTree checks refs, names, CIDRs and parent order, and returns
+// a message for a 400 response, or "" when the tree is well formed.
+func validatePoolTree(pools []domain.PoolSpec) string {
+	refSet := make(map[string]bool)
+	for i, p := range pools {
+		if p.Ref == "" {
+			return fmt.Sprintf("pool %d: ref is required", i)
+		}
+		if refSet[p.Ref] {
+			return fmt.Sprintf("pool %d: duplicate ref %q", i, p.Ref)
+		}
+
+		if err := validation.ValidateName(p.Name); err != nil {
+			return fmt.Sprintf("pool %d (%s): %v", i, p.Ref, err)
+		}
+		if err := validation.ValidateCIDRWithOptions(p.CIDR, validation.CIDROptions{
+			MinPrefix: 8,
+			MaxPrefix: 30,
+		}); err != nil {
+			return fmt.Sprintf("pool %d (%s): %v", i, p.Ref, err)
+		}
+		// parent_ref must reference an earlier entry in the array (topological order)
+		if p.ParentRef != "" && !refSet[p.ParentRef] {
+			return fmt.Sprintf("pool %d (%s): parent_ref %q not found in preceding entries", i, p.Ref, p.ParentRef)
+		}
+		refSet[p.Ref] = true
+	}
+	return ""
+}
+
+// resolvePoolType returns the type to create a tree pool with. Unknown
+// types fall back to subnet with a warning rather than failing the tree.
+func resolvePoolType(p domain.PoolSpec) (domain.PoolType, string) {
+	poolType := domain.PoolType(p.Type)
+	if poolType == "" {
+		return domain.PoolTypeSubnet, ""
+	}
+	if !domain.IsValidPoolType(poolType) {
+		return domain.PoolTypeSubnet, fmt.Sprintf("pool %q: invalid type %q, using subnet", p.Ref, p.Type)
+	}
+	return poolType, ""
+}
+
+// poolTreeConflict is returned when a tree that checks conflicts overlaps
+// an existing pool or an active reservation.
+type poolTreeConflict struct {
+	planned     domain.PoolSpec
+	existing    domain.Pool
+	reservation *domain.Reservation
+}
+
+func (e *poolTreeConflict) Error() string {
+	if e.reservation != nil {
+		return fmt.Sprintf("pool %q (%s) overlaps reservation %s (%s) for %s",
+			e.planned.Name, e.planned.CIDR, e.reservation.ID, e.reservation.CIDR, e.reservation.Owner)
+	}
+	return fmt.Sprintf("pool %q (%s) overlaps with existing pool %q (%s)",
+		e.planned.Name, e.planned.CIDR, e.existing.Name, e.existing.CIDR)
+}
+
+func (e *poolTreeConflict) Unwrap() error { return storage.ErrConflict }
+
+// planPoolTree dry-runs a validated tree against st and returns what
+// applying it would do, without writing anything.
+func (s *Server) planPoolTree(ctx context.Context, st storage.Store, tree domain.PoolTree) (domain.ChangeSet, error) {
+	cs := domain.ChangeSet{
+		Create:     []domain.PlannedPool{},
+		Skip:       []domain.SkippedPool{},
+		Violations: []domain.PlannedViolation{},
+		Warnings:   []string{},
+	}
+	planned := make(map[string]domain.PlannedPool)
+	skipped := make(map[string]bool)
+	for _, p := range tree.Pools {
+		poolType, warning := resolvePoolType(p)
+		if warning != "" {
+			cs.Warnings = append(cs.Warnings, warning)
+		}
+		pool := domain.PlannedPool{
+			Ref:       p.Ref,
+			Name:      p.Name,
+			CIDR:      p.CIDR,
+			Type:      poolType,
+			Status:    tree.Status,
+			ParentRef: p.ParentRef,
+			Depth:     1,
+		}
+		skip := func(reason, detail string, existingID int64) {
+			skipped[p.Ref] = true
+			cs.Skip = append(cs.Skip, domain.SkippedPool{
+				Ref: p.Ref, Name: p.Name, CIDR: p.CIDR, Reason: reason, Detail: detail, ExistingPoolID: existingID,
+			})
+		}
+
+		if p.ParentRef != "" {
+			if skipped[p.ParentRef] {
+				skip(domain.SkipReasonParentSkipped, fmt.Sprintf("parent %q is skipped", p.ParentRef), 0)
+				continue
+			}
+			parent := planned[p.ParentRef]
+			pool.ParentName, pool.ParentCIDR, pool.Depth = parent.Name, parent.CIDR, parent.Depth+1
+			if err := validateChildCIDR(parent.CIDR, p.CIDR); err != nil {
+				cs.Warnings = append(cs.Warnings, fmt.Sprintf("pool %q: %v (%s in %s)", p.Ref, err, p.CIDR, parent.CIDR))
+			}
+		}
+
+		overlapping, err := findOverlappingPools(ctx, st, p.CIDR, nil)
+		if err != nil {
+			return cs, err
+		}
+		if len(overlapping) > 0 {
+			ex := overlapping[0]
+			detail := fmt.Sprintf("overlaps existing pool %q (%s)", ex.Name, ex.CIDR)
+			if tree.CheckConflicts {
+				skip(domain.SkipReasonConflict, detail, ex.ID)
+				continue
+			}
+			cs.Warnings = append(cs.Warnings, fmt.Sprintf("pool %q %s", p.Ref, detail))
+		}
+		reserved, err := overlappingReservations(ctx, s.reservations, p.CIDR)
+		if err != nil {
+			return cs, err
+		}
+		if len(reserved) > 0 {
+			res := reserved[0]
+			detail := fmt.Sprintf("overlaps reservation %s (%s) for %s", res.ID, res.CIDR, res.Owner)
+			if tree.CheckConflicts {
+				skip(domain.SkipReasonConflict, detail, 0)
+				continue
+			}
+			cs.Warnings = append(cs.Warnings, fmt.Sprintf("pool %q %s", p.Ref, detail))
+		}
+
+		// Pools under the same parent in one tree should not overlap either.
+		pfx, _ := netip.ParsePrefix(p.CIDR) // validated
+		for _, sibling := range cs.Create {
+			if sibling.ParentRef != p.ParentRef {
+				continue
+			}
+			if sp, err := netip.ParsePrefix(sibling.CIDR); err == nil && prefixesOverlap(pfx, sp) {
+				cs.Warnings = append(cs.Warnings, fmt.Sprintf("pool %q (%s) overlaps pool %q (%s) in the same tree",
+					p.Ref, p.CIDR, sibling.Ref, sibling.CIDR))
+			}
+		}
+
+		planned[p.Ref] = pool
+		cs.Create = append(cs.Create, pool)
+	}
+	cs.Applicable = len(cs.Skip) == 0
+
+	if s.admission != nil && len(cs.Create) > 0 {
+		// Pool IDs are positions in cs.Create, offset by one so none is zero.
+		pools := make([]planning.PlannedPool, len(cs.Create))
+		for i, p := range cs.Create {
+			pools[i] = planning.PlannedPool{
+				Pool: domain.Pool{
+					ID: int64(i + 1), Name: p.Name, CIDR: p.CIDR, Type: p.Type, Status: p.Status, Tags: tree.Tags,
+				},
+				Depth: p.Depth,
+			}
+		}
+		violations, err := s.admission.CheckPlannedPools(ctx, pools)
+		if err != nil {
+			return cs, err
+		}
+		for _, v := range violations {
+			cs.Violations = append(cs.Violations, domain.PlannedViolation{
+				Ref:         cs.Create[v.PoolID-1].Ref,
+				PoolName:    v.PoolName,
+				CIDR:        v.CIDR,
+				RuleID:      v.RuleID,
+				Severity:    v.Severity,
+				Message:     v.Message,
+				Remediation: v.Remediation,
+			})
+		}
+	}
+	return cs, nil
+}
+
+// poolTreeResult is the outcome of creating a pool tree.
+type poolTreeResult struct {
+	Created    []domain.Pool
+	PoolMap    map[string]int64
+	RootPoolID int64
+	Warnings   []string
+}
+
+// createPoolTree creates a validated tree on st, which should be a
+// transaction so a failed pool leaves nothing behind. Trees that check
+// conflicts fail with a *poolTreeConflict before anything is created.
+func (s *Server) createPoolTree(ctx context.Context, st storage.Store, tree domain.PoolTree) (poolTreeResult, error) {
+	res := poolTreeResult{PoolMap: make(map[string]int64), Warnings: []string{}}
+	if tree.CheckConflicts {
+		reservations := txStore(st, s.reservations)
+		for _, p := range tree.Pools {
+			overlapping, err := findOverlappingPools(ctx, st, p.CIDR, nil)
+			if err != nil {
+				return res, fmt.Errorf("check existing pools: %w", err)
+			}
+			if len(overlapping) > 0 {
+				return res, &poolTreeConflict{planned: p, existing: overlapping[0]}
+			}
+			reserved, err := overlappingReservations(ctx, reservations, p.CIDR)
+			if err != nil {
+				return res, fmt.Errorf("check reservations: %w", err)
+			}
+			if len(reserved) > 0 {
+				return res, &poolTreeConflict{planned: p, reservation: &reserved[0]}
+			}
+		}
+	}
+
+	for _, p := range tree.Pools {
+		poolType, warning := resolvePoolType(p)
+		if warning != "" {
+			res.Warnings = append(res.Warnings, warning)
+		}
+		cp := domain.CreatePool{
+			Name:        p.Name,
+			CIDR:        p.CIDR,
+			Type:        poolType,
+			Status:      tree.Status,
+			Source:      domain.PoolSourceManual,
+			Description: tree.Description,
+			Tags:        tree.Tags,
+		}
+		if p.ParentRef != "" {
+			parentID := res.PoolMap[p.ParentRef]
+			cp.ParentID = &parentID
+		}
+
+		pool, err := st.CreatePool(ctx, cp)
+		if err != nil {
+			return res, fmt.Errorf("pool %q: %w", p.Ref, err)
+		}
+		res.PoolMap[p.Ref] = pool.ID
+		if p.ParentRef == "" {
+			res.RootPoolID = pool.ID
+		}
+		res.Created = append(res.Created, pool)
+	}
+	return res, nil
+}
+
+// reviewActor names the user or API key acting on a change proposal, and
+// the role they act with.
+func reviewActor(ctx context.Context) (string, string) {
+	actor := "anonymous"
+	if user := auth.UserFromContext(ctx); user != nil {
+		actor = user.Username
+	} else if key := auth.APIKeyFromContext(ctx); key != nil {
+		actor = "apikey:" + key.Name
+	}
+	return actor, string(auth.GetEffectiveRole(ctx))
+}
+
+// reviewActorID returns a stable ID for the principal behind ctx, used to
+// stop authors from approving their own changes. An API key resolves to the
+// user who owns it, so a session and that user's keys share one ID; keys
+// without an owner stand for themselves.
+func reviewActorID(ctx context.Context) string {
+	if user := auth.UserFromContext(ctx); user != nil {
+		return "user:" + user.ID
+	}
+	if key := auth.APIKeyFromContext(ctx); key != nil {
+		if key.OwnerID != nil && *key.OwnerID != "" {
+			return "user:" + *key.OwnerID
+		}
+		return "apikey:" + key.ID
+	}
+	return "anonymous"
+}
+
+// roleCovers reports whether role grants every permission base does.
+func roleCovers(ctx context.Context, role, base auth.Role) bool {
+	if role == base {
+		return true
+	}
+	for _, perm := range auth.GetRolePermissionsContext(ctx, base) {
+		if !auth.HasRolePermissionContext(ctx, role, perm.Resource, perm.Action) {
+			return false
+		}
+	}
+	return true
+}
+
+// proposePoolTree dry-runs a validated tree and stores it as a pending
+// change proposal, writing the 201 response.
+func (s *Server) proposePoolTree(w http.ResponseWriter, r *http.Request, p domain.ChangeProposal) {
+	ctx := r.Context()
+	if s.proposals == nil {
+		s.writeErr(ctx, w, http.StatusServiceUnavailable, "change proposals not available", "")
+		return
+	}
+	cs, err := s.planPoolTree(ctx, s.store, p.Tree)
+	if err != nil {
+		s.writeErr(ctx, w, http.StatusInternalServerError, "failed to check existing pools", err.Error())
+		return
+	}
+	p.ID = uuid.NewString()
+	p.Title = strings.TrimSpace(p.Title)
+	if p.Title == "" {
+		p.Title = fmt.Sprintf("%d pools from %s", len(p.Tree.Pools), strings.ReplaceAll(string(p.Source), "_", " "))
+	}
+	p.Status = domain.ChangeProposalPending
+	p.ChangeSet = cs
+	p.ProposedBy, p.ProposedRole = reviewActor(ctx)
+	p.ProposedByID = reviewActorID(ctx)
+	p.CreatedAt = time.Now().UTC()
+	if err := s.proposals.CreateChangeProposal(ctx, p); err != nil {
+		s.writeStoreErr(ctx, w, err)
+		return
+	}
+	s.logAudit(ctx, audit.ActionCreate, audit.ResourceChangeProposal, p.ID, p.Title, http.StatusCreated)
+	writeJSON(w, http.StatusCreated, p)
+}
@@ -2,11 +2,13 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
 
+	"cloudpam/internal/audit"
 	"cloudpam/internal/auth"
 	"cloudpam/internal/domain"
 	"cloudpam/internal/planning"
@@ -174,14 +176,95 @@ func (rs *RecommendationServer) handleApply(w http.ResponseWriter, r *http.Reque
 		req = domain.ApplyRecommendationRequest{}
 	}
 
-	rec, err := rs.recSvc.Apply(r.Context(), id, req)
+	ctx := r.Context()
+	if rs.proposeApply(w, r, id) {
+		return
+	}
+	rec, err := rs.recSvc.Apply(ctx, id, req)
+	var rejected *planning.AdmissionError
+	if errors.As(err, &rejected) {
+		rs.srv.writeComplianceRejection(ctx, w, "recommendations:apply", rejected.Violations[0].CIDR, rejected.Violations)
+		return
+	}
 	if err != nil {
-		rs.srv.writeStoreErr(r.Context(), w, err)
+		rs.srv.writeStoreErr(ctx, w, err)
 		return
 	}
+	// Each pool change is audited as a pool event, so it also reaches pool
+	// webhooks, followed by the recommendation itself.
+	for _, c := range rec.Changes {
+		rs.srv.logAuditWithChanges(ctx, c.Action, audit.ResourcePool, strconv.FormatInt(c.PoolID, 10), c.Name,
+			&audit.Changes{Before: c.Before, After: c.After}, http.StatusOK)
+	}
+	rs.srv.logAuditWithChanges(ctx, audit.ActionApply, audit.ResourceRecommendation, rec.ID, rec.Title,
+		&audit.Changes{After: map[string]any{"type": string(rec.Type), "pool_id": rec.PoolID, "applied_pool_id": rec.AppliedPoolID}}, http.StatusOK)
 	writeJSON(w, http.StatusOK, rec)
 }
 
+// proposeApply queues a reclaim or resize as a pool change request when an
+// approval policy covers the pool, as DELETE and PATCH /api/v1/pools do.
+// The recommendation stays pending. It reports whether it wrote the
+// response; anything else is left to Apply.
+func (rs *RecommendationServer) proposeApply(w http.ResponseWriter, r *http.Request, id string) bool {
+	ctx := r.Context()
+	policies, err := rs.srv.approvalPolicies(ctx)
+	if err != nil {
+		rs.srv.writeErr(ctx, w, http.StatusInternalServerError, "internal error", err.Error())
+		return true
+	}
+	if len(policies) == 0 {
+		return false
+	}
+	rec, err := rs.recStore.GetRecommendation(ctx, id)
+	if err != nil {
+		rs.srv.writeStoreErr(ctx, w, err)
+		return true
+	}
+	var op domain.PoolChangeOperation
+	switch {
+	case rec.Status != domain.RecommendationStatusPending:
+		return false
+	case rec.Type == domain.RecommendationTypeReclaim:
+		op = domain.PoolChangeDelete
+	case rec.Type == domain.RecommendationTypeResize:
+		op = domain.PoolChangeUpdate
+	default:
+		return false
+	}
+	pool, found, err := rs.srv.store.GetPool(ctx, rec.PoolID)
+	if err != nil {
+		rs.srv.writeErr(ctx, w, http.StatusInternalServerError, "internal error", err.Error())
+		return true
+	}
+	if !found || (op == domain.PoolChangeUpdate && pool.CIDR != rec.Metadata["current_cidr"]) {
+		// Apply reports the missing or since-moved pool.
+		return false
+	}
+	policyIDs, err := rs.srv.matchPolicies(ctx, policies, op, pool)
+	if err != nil {
+		rs.srv.writeErr(ctx, w, http.StatusInternalServerError, "internal error", err.Error())
+		return true
+	}
+	if len(policyIDs) == 0 {
+		return false
+	}
+	change := domain.PoolChangeRequest{
+		Operation:   op,
+		PoolID:      &pool.ID,
+		PoolVersion: pool.Version,
+		PoolName:    pool.Name,
+		ParentID:    pool.ParentID,
+		PolicyIDs:   policyIDs,
+	}
+	if op == domain.PoolChangeDelete {
+		change.CIDR = pool.CIDR
+	} else {
+		change.Resize = rec.SuggestedCIDR
+	}
+	rs.srv.queuePoolChange(w, r, change)
+	return true
+}
+
 // handleDismiss dismisses a recommendation.
 // POST /api/v1/recommendations/{id}/dismiss
 func (rs *RecommendationServer) handleDismiss(w http.ResponseWriter, r *http.Request, id string) {
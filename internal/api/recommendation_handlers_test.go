@@ -7,7 +7,10 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"cloudpam/internal/audit"
+	"cloudpam/internal/auth"
 	"cloudpam/internal/domain"
 	"cloudpam/internal/planning"
 	"cloudpam/internal/storage"
@@ -297,3 +300,131 @@ func TestRecommendationHandler_InvalidBody(t *testing.T) {
 		t.Errorf("expected 400, got %d", rr.Code)
 	}
 }
+
+func TestRecommendationHandler_ApplyReclaimAudited(t *testing.T) {
+	st := storage.NewMemoryStore()
+	auditLogger := audit.NewMemoryAuditLogger()
+	mux := stdhttp.NewServeMux()
+	srv := NewServer(mux, st, nil, nil, auditLogger)
+	srv.registerUnprotectedTestRoutes()
+	recStore := storage.NewMemoryRecommendationStore(st)
+	recSvc := planning.NewRecommendationService(planning.NewAnalysisService(st), recStore, st)
+	NewRecommendationServer(srv, recSvc, recStore).RegisterRecommendationRoutes()
+
+	pool, _ := st.CreatePool(context.Background(), domain.CreatePool{
+		Name: "Old", CIDR: "10.9.0.0/24", Type: domain.PoolTypeSubnet, Status: domain.PoolStatusDeprecated,
+	})
+
+	genReq := httptest.NewRequest(stdhttp.MethodPost, "/api/v1/recommendations/generate",
+		strings.NewReader(`{"pool_ids":[`+int64Str(pool.ID)+`]}`))
+	genReq.Header.Set("Content-Type", "application/json")
+	genRR := httptest.NewRecorder()
+	mux.ServeHTTP(genRR, genReq)
+	var genResp domain.GenerateRecommendationsResponse
+	if err := json.NewDecoder(genRR.Body).Decode(&genResp); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	var reclaimID string
+	for _, r := range genResp.Items {
+		if r.Type == domain.RecommendationTypeReclaim {
+			reclaimID = r.ID
+		}
+	}
+	if reclaimID == "" {
+		t.Fatalf("no reclaim recommendation in %+v", genResp.Items)
+	}
+
+	req := httptest.NewRequest(stdhttp.MethodPost, "/api/v1/recommendations/"+reclaimID+"/apply", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	if rr.Code != stdhttp.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var rec domain.Recommendation
+	if err := json.NewDecoder(rr.Body).Decode(&rec); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if len(rec.Changes) != 1 || rec.Changes[0].Action != "delete" || rec.Changes[0].PoolID != pool.ID {
+		t.Errorf("changes = %+v", rec.Changes)
+	}
+	if _, ok, _ := st.GetPool(context.Background(), pool.ID); ok {
+		t.Error("reclaimed pool still exists")
+	}
+
+	ctx := context.Background()
+	poolEvents, _, _ := auditLogger.List(ctx, audit.ListOptions{ResourceType: audit.ResourcePool})
+	if len(poolEvents) != 1 || poolEvents[0].Action != audit.ActionDelete || poolEvents[0].ResourceID != int64Str(pool.ID) {
+		t.Errorf("pool audit events = %+v", poolEvents)
+	}
+	recEvents, _, _ := auditLogger.List(ctx, audit.ListOptions{ResourceType: audit.ResourceRecommendation})
+	if len(recEvents) != 1 || recEvents[0].Action != audit.ActionApply || recEvents[0].ResourceID != reclaimID {
+		t.Errorf("recommendation audit events = %+v", recEvents)
+	}
+}
+
+func TestRecommendationHandler_ApplyQueuedByApprovalPolicy(t *testing.T) {
+	st := storage.NewMemoryStore()
+	mux := stdhttp.NewServeMux()
+	srv := NewServerWithSlog(mux, st, nil)
+	srv.registerUnprotectedTestRoutes()
+	settings := storage.NewMemorySettingsStore()
+	srv.SetSettingsStore(settings)
+	NewSettingsServer(srv, settings).RegisterSettingsRoutes()
+	requests := storage.NewMemoryPoolChangeRequestStore()
+	srv.SetPoolChangeRequestStore(requests)
+	NewPoolChangeRequestServer(srv, requests).RegisterPoolChangeRequestRoutesNoAuth()
+	recStore := storage.NewMemoryRecommendationStore(st)
+	recSvc := planning.NewRecommendationService(planning.NewAnalysisService(st), recStore, st)
+	NewRecommendationServer(srv, recSvc, recStore).RegisterRecommendationRoutes()
+
+	doJSON(t, mux, stdhttp.MethodPatch, "/api/v1/settings/approvals",
+		`{"policies":[{"id":"prod","name":"Production","enabled":true,"tags":{"env":"prod"}}]}`, stdhttp.StatusOK)
+	ctx := context.Background()
+	prod := map[string]string{"env": "prod"}
+	root, _ := st.CreatePool(ctx, domain.CreatePool{Name: "root", CIDR: "10.0.0.0/16", Type: domain.PoolTypeSupernet})
+	old, _ := st.CreatePool(ctx, domain.CreatePool{Name: "old", CIDR: "10.0.9.0/24", ParentID: &root.ID, Tags: prod})
+	busy, _ := st.CreatePool(ctx, domain.CreatePool{Name: "busy", CIDR: "10.0.0.0/24", ParentID: &root.ID, Tags: prod})
+	now := time.Now().UTC()
+	for _, rec := range []domain.Recommendation{
+		{ID: "reclaim-1", PoolID: old.ID, Type: domain.RecommendationTypeReclaim, Status: domain.RecommendationStatusPending,
+			CreatedAt: now, UpdatedAt: now},
+		{ID: "resize-1", PoolID: busy.ID, Type: domain.RecommendationTypeResize, Status: domain.RecommendationStatusPending,
+			SuggestedCIDR: "10.0.0.0/23", Metadata: map[string]string{"current_cidr": "10.0.0.0/24"}, CreatedAt: now, UpdatedAt: now},
+	} {
+		if err := recStore.CreateRecommendation(ctx, rec); err != nil {
+			t.Fatalf("CreateRecommendation: %v", err)
+		}
+	}
+
+	rr := doJSONAs(t, mux, "alice", auth.RoleOperator, stdhttp.MethodPost, "/api/v1/recommendations/reclaim-1/apply", `{}`, stdhttp.StatusAccepted)
+	cr := decodeChangeRequest(t, rr.Body.Bytes())
+	if cr.Operation != domain.PoolChangeDelete || cr.PoolID == nil || *cr.PoolID != old.ID || cr.PolicyIDs[0] != "prod" {
+		t.Fatalf("reclaim change request = %+v", cr)
+	}
+	if _, ok, _ := st.GetPool(ctx, old.ID); !ok {
+		t.Fatal("reclaimed pool deleted before approval")
+	}
+	if rec, _ := recStore.GetRecommendation(ctx, "reclaim-1"); rec.Status != domain.RecommendationStatusPending {
+		t.Errorf("reclaim recommendation status = %s, want pending", rec.Status)
+	}
+
+	rr = doJSONAs(t, mux, "alice", auth.RoleOperator, stdhttp.MethodPost, "/api/v1/recommendations/resize-1/apply", `{}`, stdhttp.StatusAccepted)
+	cr = decodeChangeRequest(t, rr.Body.Bytes())
+	if cr.Operation != domain.PoolChangeUpdate || cr.Resize != "10.0.0.0/23" {
+		t.Fatalf("resize change request = %+v", cr)
+	}
+	if p, _, _ := st.GetPool(ctx, busy.ID); p.CIDR != "10.0.0.0/24" {
+		t.Fatalf("pool resized before approval: %s", p.CIDR)
+	}
+	// A pending create holding part of the new block stops the resize.
+	rr = doJSONAs(t, mux, "carol", auth.RoleOperator, stdhttp.MethodPost, "/api/v1/pools",
+		`{"name":"next","cidr":"10.0.1.0/24","parent_id":`+int64Str(root.ID)+`,"tags":{"env":"prod"}}`, stdhttp.StatusAccepted)
+	hold := decodeChangeRequest(t, rr.Body.Bytes())
+	doJSONAs(t, mux, "bob", auth.RoleAdmin, stdhttp.MethodPost, "/api/v1/change-requests/"+cr.ID+"/approve", `{}`, stdhttp.StatusConflict)
+	doJSONAs(t, mux, "carol", auth.RoleOperator, stdhttp.MethodPost, "/api/v1/change-requests/"+hold.ID+"/reject", `{}`, stdhttp.StatusOK)
+	doJSONAs(t, mux, "bob", auth.RoleAdmin, stdhttp.MethodPost, "/api/v1/change-requests/"+cr.ID+"/approve", `{}`, stdhttp.StatusOK)
+	if p, _, _ := st.GetPool(ctx, busy.ID); p.CIDR != "10.0.0.0/23" {
+		t.Errorf("approved resize left pool at %s", p.CIDR)
+	}
+}
@@ -0,0 +1,415 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"cloudpam/internal/audit"
+	"cloudpam/internal/auth"
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+	"cloudpam/internal/validation"
+	"cloudpam/internal/webhook"
+)
+
+// Length limits for reservation text fields.
+const (
+	maxReservationOwnerLen  = 255
+	maxReservationReasonLen = 1024
+)
+
+// ReservationServer handles CIDR reservations: soft holds on a block under
+// a parent pool that keep it free for an owner until released or expired.
+type ReservationServer struct {
+	srv          *Server
+	reservations storage.ReservationStore
+	publisher    webhook.Publisher
+}
+
+// NewReservationServer creates a new ReservationServer.
+func NewReservationServer(srv *Server, reservations storage.ReservationStore) *ReservationServer {
+	return &ReservationServer{srv: srv, reservations: reservations}
+}
+
+// SetPublisher publishes a reservation.expired webhook event for every
+// reservation the expiry job releases.
+func (rs *ReservationServer) SetPublisher(p webhook.Publisher) {
+	rs.publisher = p
+}
+
+// RegisterProtectedReservationRoutes registers reservation routes with
+// RBAC. Reservations are part of the address plan, so they use the pool
+// permissions.
+func (rs *ReservationServer) RegisterProtectedReservationRoutes(dualMW Middleware, logger *slog.Logger) {
+	listMW := RequirePermissionMiddleware(auth.ResourcePools, auth.ActionList, logger)
+	readMW := RequirePermissionMiddleware(auth.ResourcePools, auth.ActionRead, logger)
+	createMW := RequirePermissionMiddleware(auth.ResourcePools, auth.ActionCreate, logger)
+	updateMW := RequirePermissionMiddleware(auth.ResourcePools, auth.ActionUpdate, logger)
+
+	rs.srv.handleOpenAPIRoute("GET /api/v1/reservations", dualMW(listMW(http.HandlerFunc(rs.handleList))))
+	rs.srv.handleOpenAPIRoute("POST /api/v1/reservations", dualMW(createMW(http.HandlerFunc(rs.handleCreate))))
+	rs.srv.handleOpenAPIRoute("GET /api/v1/reservations/{id}", dualMW(readMW(http.HandlerFunc(rs.handleGet))))
+	rs.srv.handleOpenAPIRoute("PATCH /api/v1/reservations/{id}", dualMW(updateMW(http.HandlerFunc(rs.handleUpdate))))
+	rs.srv.handleOpenAPIRoute("POST /api/v1/reservations/{id}/release", dualMW(updateMW(http.HandlerFunc(rs.handleRelease))))
+}
+
+// RegisterReservationRoutesNoAuth registers reservation routes without auth
+// middleware (for tests).
+func (rs *ReservationServer) RegisterReservationRoutesNoAuth() {
+	rs.srv.handleOpenAPIRouteFunc("GET /api/v1/reservations", rs.handleList)
+	rs.srv.handleOpenAPIRouteFunc("POST /api/v1/reservations", rs.handleCreate)
+	rs.srv.handleOpenAPIRouteFunc("GET /api/v1/reservations/{id}", rs.handleGet)
+	rs.srv.handleOpenAPIRouteFunc("PATCH /api/v1/reservations/{id}", rs.handleUpdate)
+	rs.srv.handleOpenAPIRouteFunc("POST /api/v1/reservations/{id}/release", rs.handleRelease)
+}
+
+// handleList lists reservations, newest first, optionally by status and
+// parent pool.
+// GET /api/v1/reservations
+func (rs *ReservationServer) handleList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	q := r.URL.Query()
+	status := domain.ReservationStatus(q.Get("status"))
+	if status != "" && !domain.IsValidReservationStatus(status) {
+		rs.srv.writeErr(ctx, w, http.StatusBadRequest, "invalid status", "use active, released, or expired")
+		return
+	}
+	var parentID int64
+	if v := q.Get("parent_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id < 1 {
+			rs.srv.writeErr(ctx, w, http.StatusBadRequest, "invalid parent_id", "")
+			return
+		}
+		parentID = id
+	}
+	items, err := rs.reservations.ListReservations(ctx, status)
+	if err != nil {
+		rs.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+	if parentID != 0 {
+		filtered := items[:0]
+		for _, res := range items {
+			if res.ParentID != nil && *res.ParentID == parentID {
+				filtered = append(filtered, res)
+			}
+		}
+		items = filtered
+	}
+	writeJSON(w, http.StatusOK, domain.ReservationListResponse{Items: items})
+}
+
+// handleCreate reserves a block. The block must fit in the parent pool and
+// must not overlap a sibling pool, a block held by a pending change
+// request, or another active reservation.
+// POST /api/v1/reservations
+func (rs *ReservationServer) handleCreate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var in domain.CreateReservation
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		rs.srv.writeErr(ctx, w, http.StatusBadRequest, "invalid json", err.Error())
+		return
+	}
+	in.CIDR = strings.TrimSpace(in.CIDR)
+	in.Owner = strings.TrimSpace(in.Owner)
+	in.Reason = strings.TrimSpace(in.Reason)
+	actor, _ := reviewActor(ctx)
+	if in.Owner == "" {
+		in.Owner = actor
+	}
+	now := time.Now().UTC()
+	if msg := validateReservationFields(in.Owner, in.Reason, in.ExpiresAt, now); msg != "" {
+		rs.srv.writeErr(ctx, w, http.StatusBadRequest, msg, "")
+		return
+	}
+	if err := validation.ValidateCIDR(in.CIDR); err != nil {
+		rs.srv.writeErr(ctx, w, http.StatusBadRequest, err.Error(), "")
+		return
+	}
+
+	if in.ParentID != nil {
+		parent, ok, err := rs.srv.store.GetPool(ctx, *in.ParentID)
+		if err != nil {
+			rs.srv.writeErr(ctx, w, http.StatusInternalServerError, "internal error", err.Error())
+			return
+		}
+		if !ok {
+			rs.srv.writeErr(ctx, w, http.StatusBadRequest, "parent not found", "")
+			return
+		}
+		if err := validateChildCIDR(parent.CIDR, in.CIDR); err != nil {
+			rs.srv.writeErr(ctx, w, http.StatusBadRequest, "invalid reservation cidr", err.Error())
+			return
+		}
+	}
+
+	// Reservations keep free space free: pools and pending change requests
+	// already in the block conflict.
+	scope := in.ParentID
+	if scope == nil {
+		scope = new(int64)
+	}
+	overlapping, err := findOverlappingPools(ctx, rs.srv.store, in.CIDR, scope)
+	if err != nil {
+		rs.srv.writeErr(ctx, w, http.StatusInternalServerError, "internal error", err.Error())
+		return
+	}
+	if len(overlapping) > 0 {
+		p := overlapping[0]
+		rs.srv.writeErr(ctx, w, http.StatusConflict, "cidr overlaps with existing block", fmt.Sprintf("conflicts with pool #%d (%s)", p.ID, p.CIDR))
+		return
+	}
+	hold, err := rs.srv.pendingHold(ctx, in.CIDR, in.ParentID)
+	if err != nil {
+		rs.srv.writeErr(ctx, w, http.StatusInternalServerError, "internal error", err.Error())
+		return
+	}
+	if hold != nil {
+		rs.srv.writeErr(ctx, w, http.StatusConflict, "cidr overlaps with existing block", fmt.Sprintf("held by change request %s (%s)", hold.ID, hold.CIDR))
+		return
+	}
+
+	res := domain.Reservation{
+		ID:        uuid.NewString(),
+		CIDR:      in.CIDR,
+		ParentID:  in.ParentID,
+		Owner:     in.Owner,
+		Reason:    in.Reason,
+		Status:    domain.ReservationActive,
+		ExpiresAt: in.ExpiresAt.UTC(),
+		CreatedBy: actor,
+		CreatedAt: now,
+	}
+	if err := rs.reservations.CreateReservation(ctx, res); err != nil {
+		rs.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+	rs.srv.logger.InfoContext(ctx, "reservations:create success", appendRequestID(ctx, []any{
+		"reservation_id", res.ID,
+		"cidr", res.CIDR,
+		"parent_id", valueOrNil(res.ParentID),
+		"owner", res.Owner,
+		"expires_at", res.ExpiresAt.Format(time.RFC3339),
+	})...)
+	rs.srv.logAuditWithChanges(ctx, audit.ActionCreate, audit.ResourceReservation, res.ID, res.CIDR,
+		&audit.Changes{After: reservationAuditFields(res)}, http.StatusCreated)
+	w.Header().Set("Location", "/api/v1/reservations/"+res.ID)
+	writeJSON(w, http.StatusCreated, res)
+}
+
+// handleGet returns a single reservation.
+// GET /api/v1/reservations/{id}
+func (rs *ReservationServer) handleGet(w http.ResponseWriter, r *http.Request) {
+	res, err := rs.reservations.GetReservation(r.Context(), r.PathValue("id"))
+	if err != nil {
+		rs.srv.writeStoreErr(r.Context(), w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// loadHolding returns the reservation in the path if it still holds its
+// block, writing the error response otherwise.
+func (rs *ReservationServer) loadHolding(w http.ResponseWriter, r *http.Request, now time.Time) (*domain.Reservation, bool) {
+	res, err := rs.reservations.GetReservation(r.Context(), r.PathValue("id"))
+	if err != nil {
+		rs.srv.writeStoreErr(r.Context(), w, err)
+		return nil, false
+	}
+	if res.Status != domain.ReservationActive {
+		rs.srv.writeErr(r.Context(), w, http.StatusConflict, fmt.Sprintf("reservation is already %s", res.Status), "")
+		return nil, false
+	}
+	if !res.HoldsAt(now) {
+		rs.srv.writeErr(r.Context(), w, http.StatusConflict, "reservation has expired", "")
+		return nil, false
+	}
+	return res, true
+}
+
+// handleUpdate changes the owner, reason or expiry of an active
+// reservation, for example to extend it.
+// PATCH /api/v1/reservations/{id}
+func (rs *ReservationServer) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var in domain.UpdateReservation
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		rs.srv.writeErr(ctx, w, http.StatusBadRequest, "invalid json", err.Error())
+		return
+	}
+	now := time.Now().UTC()
+	res, ok := rs.loadHolding(w, r, now)
+	if !ok {
+		return
+	}
+	before := reservationAuditFields(*res)
+	if in.Owner != nil {
+		res.Owner = strings.TrimSpace(*in.Owner)
+	}
+	if in.Reason != nil {
+		res.Reason = strings.TrimSpace(*in.Reason)
+	}
+	if in.ExpiresAt != nil {
+		res.ExpiresAt = in.ExpiresAt.UTC()
+	}
+	if msg := validateReservationFields(res.Owner, res.Reason, res.ExpiresAt, now); msg != "" {
+		rs.srv.writeErr(ctx, w, http.StatusBadRequest, msg, "")
+		return
+	}
+	if err := rs.reservations.UpdateReservation(ctx, *res); err != nil {
+		rs.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+	rs.srv.logAuditWithChanges(ctx, audit.ActionUpdate, audit.ResourceReservation, res.ID, res.CIDR,
+		&audit.Changes{Before: before, After: reservationAuditFields(*res)}, http.StatusOK)
+	writeJSON(w, http.StatusOK, res)
+}
+
+// handleRelease ends an active reservation before its expiry, freeing the
+// block.
+// POST /api/v1/reservations/{id}/release
+func (rs *ReservationServer) handleRelease(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	now := time.Now().UTC()
+	res, err := rs.reservations.GetReservation(ctx, r.PathValue("id"))
+	if err != nil {
+		rs.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+	res.Status = domain.ReservationReleased
+	res.ReleasedBy, _ = reviewActor(ctx)
+	res.ReleasedAt = &now
+	if err := rs.reservations.ReleaseReservation(ctx, *res); err != nil {
+		rs.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+	rs.srv.logAudit(ctx, audit.ActionRelease, audit.ResourceReservation, res.ID, res.CIDR, http.StatusOK)
+	writeJSON(w, http.StatusOK, res)
+}
+
+// ExpireReservations releases the reservations past their expiry at now.
+// Each one gets an audit event and, with a publisher set, a
+// reservation.expired webhook event. It returns the expired reservations.
+func (rs *ReservationServer) ExpireReservations(ctx context.Context, now time.Time) ([]domain.Reservation, error) {
+	expired, err := rs.reservations.ExpireReservations(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	for _, res := range expired {
+		if rs.srv.auditLogger != nil {
+			_ = rs.srv.auditLogger.Log(ctx, &audit.AuditEvent{
+				Timestamp:    now.UTC(),
+				Actor:        storage.ReservationSystemActor,
+				ActorType:    audit.ActorTypeSystem,
+				Action:       audit.ActionExpire,
+				ResourceType: audit.ResourceReservation,
+				ResourceID:   res.ID,
+				ResourceName: res.CIDR,
+				Changes:      &audit.Changes{Before: reservationAuditFields(res)},
+				StatusCode:   http.StatusOK,
+			})
+		}
+		if rs.publisher != nil {
+			rs.publisher.Publish(ctx, domain.WebhookEventReservationExpired, webhook.ReservationExpiredData{
+				ReservationID: res.ID,
+				CIDR:          res.CIDR,
+				ParentID:      res.ParentID,
+				Owner:         res.Owner,
+				Reason:        res.Reason,
+				ExpiresAt:     res.ExpiresAt,
+			})
+		}
+	}
+	return expired, nil
+}
+
+// validateReservationFields checks the owner, reason and expiry of a
+// reservation. It returns "" when they are valid.
+func validateReservationFields(owner, reason string, expiresAt, now time.Time) string {
+	switch {
+	case owner == "":
+		return "owner is required"
+	case len(owner) > maxReservationOwnerLen:
+		return fmt.Sprintf("owner must be at most %d characters", maxReservationOwnerLen)
+	case len(reason) > maxReservationReasonLen:
+		return fmt.Sprintf("reason must be at most %d characters", maxReservationReasonLen)
+	case expiresAt.IsZero():
+		return "expires_at is required"
+	case !expiresAt.After(now):
+		return "expires_at must be in the future"
+	}
+	return ""
+}
+
+// reservationAuditFields are the reservation fields recorded in audit
+// changes.
+func reservationAuditFields(res domain.Reservation) map[string]any {
+	return map[string]any{
+		"cidr":       res.CIDR,
+		"parent_id":  valueOrNil(res.ParentID),
+		"owner":      res.Owner,
+		"reason":     res.Reason,
+		"expires_at": res.ExpiresAt.Format(time.RFC3339),
+	}
+}
+
+// activeReservations returns the reservations in rs holding their block
+// now. It returns none when rs is nil.
+func activeReservations(ctx context.Context, rs storage.ReservationStore) ([]domain.Reservation, error) {
+	if rs == nil {
+		return nil, nil
+	}
+	active, err := rs.ListReservations(ctx, domain.ReservationActive)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	out := active[:0]
+	for _, res := range active {
+		if res.HoldsAt(now) {
+			out = append(out, res)
+		}
+	}
+	return out, nil
+}
+
+// reservedHold returns the active reservation holding a block that
+// overlaps prefix under parentID, if any.
+func (s *Server) reservedHold(ctx context.Context, prefix string, parentID *int64) (*domain.Reservation, error) {
+	active, err := activeReservations(ctx, s.reservations)
+	if err != nil {
+		return nil, err
+	}
+	return storage.OverlappingReservation(active, prefix, parentID, time.Now()), nil
+}
+
+// overlappingReservations returns the active reservations in rs, anywhere
+// in the tree, whose block overlaps prefix. The schema conflict checks use
+// it.
+func overlappingReservations(ctx context.Context, rs storage.ReservationStore, prefix string) ([]domain.Reservation, error) {
+	pfx, err := netip.ParsePrefix(prefix)
+	if err != nil {
+		return nil, err
+	}
+	active, err := activeReservations(ctx, rs)
+	if err != nil {
+		return nil, err
+	}
+	var out []domain.Reservation
+	for _, res := range active {
+		if rp, err := netip.ParsePrefix(res.CIDR); err == nil && prefixesOverlap(pfx, rp) {
+			out = append(out, res)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,176 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	stdhttp "net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"cloudpam/internal/auth"
+	"cloudpam/internal/domain"
+	"cloudpam/internal/observability"
+	"cloudpam/internal/storage"
+	"cloudpam/internal/webhook"
+)
+
+type recordingReservationPublisher struct {
+	expired []webhook.ReservationExpiredData
+}
+
+func (p *recordingReservationPublisher) Publish(_ context.Context, t domain.WebhookEventType, data any) {
+	if t == domain.WebhookEventReservationExpired {
+		p.expired = append(p.expired, data.(webhook.ReservationExpiredData))
+	}
+}
+
+func setupReservationServer(t *testing.T) (*stdhttp.ServeMux, *ReservationServer, *storage.MemoryReservationStore) {
+	t.Helper()
+	st := storage.NewMemoryStore()
+	mux := stdhttp.NewServeMux()
+	logger := observability.NewLogger(observability.Config{Level: "info", Format: "json", Output: io.Discard})
+	srv := NewServer(mux, st, logger, nil, nil)
+	srv.registerUnprotectedTestRoutes()
+
+	reservations := storage.NewMemoryReservationStore()
+	srv.SetReservationStore(reservations)
+	rs := NewReservationServer(srv, reservations)
+	rs.RegisterReservationRoutesNoAuth()
+	return mux, rs, reservations
+}
+
+func decodeReservation(t *testing.T, body []byte) domain.Reservation {
+	t.Helper()
+	var res domain.Reservation
+	if err := json.Unmarshal(body, &res); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return res
+}
+
+func reservationBody(cidr string, expiresAt time.Time) string {
+	return `{"cidr":"` + cidr + `","parent_id":1,"reason":"prod rollout","expires_at":"` + expiresAt.UTC().Format(time.RFC3339) + `"}`
+}
+
+func TestReservation_HoldsBlockUntilReleased(t *testing.T) {
+	mux, _, _ := setupReservationServer(t)
+	doJSON(t, mux, stdhttp.MethodPost, "/api/v1/pools", `{"name":"root","cidr":"10.0.0.0/8","type":"supernet"}`, stdhttp.StatusCreated)
+	expires := time.Now().Add(24 * time.Hour)
+
+	rr := doJSONAs(t, mux, "alice", auth.RoleOperator, stdhttp.MethodPost, "/api/v1/reservations",
+		reservationBody("10.0.0.0/16", expires), stdhttp.StatusCreated)
+	res := decodeReservation(t, rr.Body.Bytes())
+	if res.Status != domain.ReservationActive || res.Owner != "alice" || res.CreatedBy != "alice" || *res.ParentID != 1 {
+		t.Fatalf("reservation = %+v", res)
+	}
+	if loc := rr.Header().Get("Location"); loc != "/api/v1/reservations/"+res.ID {
+		t.Fatalf("Location = %q", loc)
+	}
+
+	// Overlapping reservations, blocks outside the parent and past expiries
+	// are rejected.
+	doJSON(t, mux, stdhttp.MethodPost, "/api/v1/reservations", reservationBody("10.0.128.0/17", expires), stdhttp.StatusConflict)
+	doJSON(t, mux, stdhttp.MethodPost, "/api/v1/reservations", reservationBody("192.168.0.0/16", expires), stdhttp.StatusBadRequest)
+	doJSON(t, mux, stdhttp.MethodPost, "/api/v1/reservations", reservationBody("10.5.0.0/16", time.Now().Add(-time.Minute)), stdhttp.StatusBadRequest)
+
+	// The reserved block is taken for direct creates and skipped by the
+	// allocator.
+	rr = doJSON(t, mux, stdhttp.MethodPost, "/api/v1/pools", `{"name":"dev","cidr":"10.0.0.0/20","parent_id":1}`, stdhttp.StatusBadRequest)
+	if !strings.Contains(rr.Body.String(), res.ID) {
+		t.Fatalf("overlap error does not name the reservation: %s", rr.Body.String())
+	}
+	rr = doJSON(t, mux, stdhttp.MethodPost, "/api/v1/pools/1/allocate", `{"name":"dev","prefix_length":16}`, stdhttp.StatusCreated)
+	var allocated domain.Pool
+	if err := json.Unmarshal(rr.Body.Bytes(), &allocated); err != nil {
+		t.Fatal(err)
+	}
+	if allocated.CIDR != "10.1.0.0/16" {
+		t.Fatalf("allocated %s, want the block after the reservation", allocated.CIDR)
+	}
+	// A reservation cannot cover a pool that already exists.
+	doJSON(t, mux, stdhttp.MethodPost, "/api/v1/reservations", reservationBody("10.1.0.0/24", expires), stdhttp.StatusConflict)
+
+	// Schema checks report the reservation as a conflict.
+	rr = doJSON(t, mux, stdhttp.MethodPost, "/api/v1/schema/check", `{"pools":[{"name":"planned","cidr":"10.0.4.0/24"}]}`, stdhttp.StatusOK)
+	var check schemaCheckResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &check); err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, c := range check.Conflicts {
+		if c.ReservationID == res.ID {
+			found = true
+			if c.ExistingCIDR != "10.0.0.0/16" || c.OverlapType != "contained_by" || c.ExistingPoolID != 1 {
+				t.Fatalf("reservation conflict = %+v", c)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("schema check missed the reservation: %+v", check.Conflicts)
+	}
+
+	path := "/api/v1/reservations/" + res.ID
+	later := expires.Add(48 * time.Hour).UTC().Truncate(time.Second)
+	rr = doJSON(t, mux, stdhttp.MethodPatch, path, `{"owner":"bob","expires_at":"`+later.Format(time.RFC3339)+`"}`, stdhttp.StatusOK)
+	if updated := decodeReservation(t, rr.Body.Bytes()); updated.Owner != "bob" || !updated.ExpiresAt.Equal(later) {
+		t.Fatalf("updated = %+v", updated)
+	}
+	doJSON(t, mux, stdhttp.MethodPatch, path, `{"owner":""}`, stdhttp.StatusBadRequest)
+
+	rr = doJSONAs(t, mux, "bob", auth.RoleOperator, stdhttp.MethodPost, path+"/release", "", stdhttp.StatusOK)
+	if released := decodeReservation(t, rr.Body.Bytes()); released.Status != domain.ReservationReleased || released.ReleasedBy != "bob" {
+		t.Fatalf("released = %+v", released)
+	}
+	doJSON(t, mux, stdhttp.MethodPost, path+"/release", "", stdhttp.StatusConflict)
+	doJSON(t, mux, stdhttp.MethodPatch, path, `{"reason":"again"}`, stdhttp.StatusConflict)
+
+	// Released, the block is free again.
+	doJSON(t, mux, stdhttp.MethodPost, "/api/v1/pools", `{"name":"dev","cidr":"10.0.0.0/20","parent_id":1}`, stdhttp.StatusCreated)
+
+	rr = doJSON(t, mux, stdhttp.MethodGet, "/api/v1/reservations?status=released&parent_id=1", "", stdhttp.StatusOK)
+	var list domain.ReservationListResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Items) != 1 || list.Items[0].ID != res.ID {
+		t.Fatalf("list = %+v", list.Items)
+	}
+	doJSON(t, mux, stdhttp.MethodGet, "/api/v1/reservations?status=bogus", "", stdhttp.StatusBadRequest)
+	doJSON(t, mux, stdhttp.MethodGet, "/api/v1/reservations/missing", "", stdhttp.StatusNotFound)
+}
+
+func TestReservation_ExpireNotifiesAndFreesBlock(t *testing.T) {
+	mux, rs, reservations := setupReservationServer(t)
+	doJSON(t, mux, stdhttp.MethodPost, "/api/v1/pools", `{"name":"root","cidr":"10.0.0.0/8","type":"supernet"}`, stdhttp.StatusCreated)
+	pub := &recordingReservationPublisher{}
+	rs.SetPublisher(pub)
+
+	now := time.Now().UTC()
+	parent := int64(1)
+	held := domain.Reservation{
+		ID: "held", CIDR: "10.0.0.0/16", ParentID: &parent, Owner: "alice", Reason: "migration",
+		Status: domain.ReservationActive, ExpiresAt: now.Add(time.Hour), CreatedBy: "alice", CreatedAt: now.Add(-time.Hour),
+	}
+	if err := reservations.CreateReservation(t.Context(), held); err != nil {
+		t.Fatal(err)
+	}
+	doJSON(t, mux, stdhttp.MethodPost, "/api/v1/pools", `{"name":"dev","cidr":"10.0.0.0/20","parent_id":1}`, stdhttp.StatusBadRequest)
+
+	expired, err := rs.ExpireReservations(t.Context(), now.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("ExpireReservations: %v", err)
+	}
+	if len(expired) != 1 || expired[0].Status != domain.ReservationExpired || expired[0].ReleasedBy != storage.ReservationSystemActor {
+		t.Fatalf("expired = %+v", expired)
+	}
+	if len(pub.expired) != 1 || pub.expired[0].ReservationID != "held" || pub.expired[0].Owner != "alice" {
+		t.Fatalf("published = %+v", pub.expired)
+	}
+	if again, _ := rs.ExpireReservations(t.Context(), now.Add(2*time.Hour)); len(again) != 0 {
+		t.Fatalf("expired twice: %+v", again)
+	}
+	doJSON(t, mux, stdhttp.MethodPost, "/api/v1/reservations/held/release", "", stdhttp.StatusConflict)
+	doJSON(t, mux, stdhttp.MethodPost, "/api/v1/pools", `{"name":"dev","cidr":"10.0.0.0/20","parent_id":1}`, stdhttp.StatusCreated)
+}
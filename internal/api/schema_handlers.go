@@ -2,11 +2,13 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/netip"
 
 	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
 	"cloudpam/internal/validation"
 )
 
@@ -24,6 +26,9 @@ type schemaCheckRequest struct {
 	Pools []schemaPoolEntry `json:"pools"`
 }
 
+// schemaConflict is a planned pool that overlaps an existing pool or, when
+// ReservationID is set, an active reservation. For a reservation the
+// existing pool fields describe the reserved block and its parent.
 type schemaConflict struct {
 	PlannedCIDR      string `json:"planned_cidr"`
 	PlannedName      string `json:"planned_name"`
@@ -31,6 +36,7 @@ type schemaConflict struct {
 	ExistingPoolName string `json:"existing_pool_name"`
 	ExistingCIDR     string `json:"existing_cidr"`
 	OverlapType      string `json:"overlap_type"`
+	ReservationID    string `json:"reservation_id,omitempty"`
 }
 
 type schemaCheckResponse struct {
@@ -44,10 +50,17 @@ type schemaApplyRequest struct {
 	Status        string            `json:"status"`
 	Tags          map[string]string `json:"tags"`
 	SkipConflicts bool              `json:"skip_conflicts"`
+	// DryRun returns the change set instead of creating pools. Propose
+	// stores it as a change proposal for a second user to approve.
+	DryRun  bool   `json:"dry_run"`
+	Propose bool   `json:"propose"`
+	Title   string `json:"title"` // proposal title
 }
 
 type schemaApplyResponse struct {
-	Created    int              `json:"created"`
+	Created int `json:"created"`
+	// Skipped is always zero now that apply is all-or-nothing; it is kept so
+	// existing clients keep decoding the response.
 	Skipped    int              `json:"skipped"`
 	Errors     []string         `json:"errors"`
 	RootPoolID int64            `json:"root_pool_id"`
@@ -83,40 +96,54 @@ func (s *Server) handleSchemaCheck(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Get all existing pools
-	existing, err := s.store.ListPools(ctx)
-	if err != nil {
-		s.writeErr(ctx, w, http.StatusInternalServerError, "failed to list existing pools", err.Error())
-		return
-	}
-
 	var conflicts []schemaConflict
 	for _, proposed := range req.Pools {
 		pp, err := netip.ParsePrefix(proposed.CIDR)
 		if err != nil {
 			continue // already validated above
 		}
-		for _, ex := range existing {
+		overlapping, err := findOverlappingPools(ctx, s.store, proposed.CIDR, nil)
+		if err != nil {
+			s.writeErr(ctx, w, http.StatusInternalServerError, "failed to check existing pools", err.Error())
+			return
+		}
+		for _, ex := range overlapping {
 			ep, err := netip.ParsePrefix(ex.CIDR)
 			if err != nil {
 				continue
 			}
-			if prefixesOverlapIPv4(pp, ep) {
-				overlapType := "overlap"
-				if pp.Bits() <= ep.Bits() && pp.Contains(ep.Addr()) {
-					overlapType = "contains"
-				} else if ep.Bits() <= pp.Bits() && ep.Contains(pp.Addr()) {
-					overlapType = "contained_by"
-				}
-				conflicts = append(conflicts, schemaConflict{
-					PlannedCIDR:      proposed.CIDR,
-					PlannedName:      proposed.Name,
-					ExistingPoolID:   ex.ID,
-					ExistingPoolName: ex.Name,
-					ExistingCIDR:     ex.CIDR,
-					OverlapType:      overlapType,
-				})
+			conflicts = append(conflicts, schemaConflict{
+				PlannedCIDR:      proposed.CIDR,
+				PlannedName:      proposed.Name,
+				ExistingPoolID:   ex.ID,
+				ExistingPoolName: ex.Name,
+				ExistingCIDR:     ex.CIDR,
+				OverlapType:      overlapType(pp, ep),
+			})
+		}
+		reserved, err := overlappingReservations(ctx, s.reservations, proposed.CIDR)
+		if err != nil {
+			s.writeErr(ctx, w, http.StatusInternalServerError, "failed to check reservations", err.Error())
+			return
+		}
+		for _, res := range reserved {
+			rp, err := netip.ParsePrefix(res.CIDR)
+			if err != nil {
+				continue
 			}
+			var parentID int64
+			if res.ParentID != nil {
+				parentID = *res.ParentID
+			}
+			conflicts = append(conflicts, schemaConflict{
+				PlannedCIDR:      proposed.CIDR,
+				PlannedName:      proposed.Name,
+				ExistingPoolID:   parentID,
+				ExistingPoolName: "reserved for " + res.Owner,
+				ExistingCIDR:     res.CIDR,
+				OverlapType:      overlapType(pp, rp),
+				ReservationID:    res.ID,
+			})
 		}
 	}
 
@@ -131,7 +158,20 @@ func (s *Server) handleSchemaCheck(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, resp)
 }
 
-// POST /api/v1/schema/apply — bulk-create pools from a schema tree.
+// overlapType describes how a planned prefix relates to an existing one it
+// overlaps.
+func overlapType(planned, existing netip.Prefix) string {
+	switch {
+	case planned.Bits() <= existing.Bits() && planned.Contains(existing.Addr()):
+		return "contains"
+	case existing.Bits() <= planned.Bits() && existing.Contains(planned.Addr()):
+		return "contained_by"
+	}
+	return "overlap"
+}
+
+// POST /api/v1/schema/apply — bulk-create pools from a schema tree, or with
+// dry_run or propose, report or propose the change without creating it.
 func (s *Server) handleSchemaApply(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		s.writeErr(r.Context(), w, http.StatusMethodNotAllowed, "method not allowed", "")
@@ -165,135 +205,66 @@ func (s *Server) handleSchemaApply(w http.ResponseWriter, r *http.Request) {
 	tags["schema_planner"] = "true"
 
 	// Validate all entries upfront
-	refSet := make(map[string]bool)
+	pools := make([]domain.PoolSpec, len(req.Pools))
 	for i, p := range req.Pools {
-		if p.Ref == "" {
-			s.writeErr(ctx, w, http.StatusBadRequest, fmt.Sprintf("pool %d: ref is required", i), "")
-			return
-		}
-		if refSet[p.Ref] {
-			s.writeErr(ctx, w, http.StatusBadRequest, fmt.Sprintf("pool %d: duplicate ref %q", i, p.Ref), "")
-			return
-		}
-		refSet[p.Ref] = true
-
-		if err := validation.ValidateName(p.Name); err != nil {
-			s.writeErr(ctx, w, http.StatusBadRequest, fmt.Sprintf("pool %d (%s): %v", i, p.Ref, err), "")
-			return
-		}
-		if err := validation.ValidateCIDRWithOptions(p.CIDR, validation.CIDROptions{
-			MinPrefix: 8,
-			MaxPrefix: 30,
-		}); err != nil {
-			s.writeErr(ctx, w, http.StatusBadRequest, fmt.Sprintf("pool %d (%s): %v", i, p.Ref, err), "")
-			return
-		}
-		if p.ParentRef != "" && !refSet[p.ParentRef] {
-			// parent_ref must reference an earlier entry in the array (topological order)
-			found := false
-			for _, prev := range req.Pools[:i] {
-				if prev.Ref == p.ParentRef {
-					found = true
-					break
-				}
-			}
-			if !found {
-				s.writeErr(ctx, w, http.StatusBadRequest,
-					fmt.Sprintf("pool %d (%s): parent_ref %q not found in preceding entries", i, p.Ref, p.ParentRef), "")
-				return
-			}
-		}
+		pools[i] = domain.PoolSpec(p)
+	}
+	if msg := validatePoolTree(pools); msg != "" {
+		s.writeErr(ctx, w, http.StatusBadRequest, msg, "")
+		return
+	}
+	tree := domain.PoolTree{
+		Pools:          pools,
+		Status:         status,
+		Tags:           tags,
+		Description:    "Created by Schema Planner",
+		CheckConflicts: !req.SkipConflicts,
 	}
 
-	// If skip_conflicts is false, run conflict check first
-	if !req.SkipConflicts {
-		existing, err := s.store.ListPools(ctx)
+	switch {
+	case req.Propose:
+		s.proposePoolTree(w, r, domain.ChangeProposal{
+			Title:  req.Title,
+			Source: domain.ChangeProposalSourceSchema,
+			Tree:   tree,
+		})
+		return
+	case req.DryRun:
+		cs, err := s.planPoolTree(ctx, s.store, tree)
 		if err != nil {
-			s.writeErr(ctx, w, http.StatusInternalServerError, "failed to list existing pools", err.Error())
+			s.writeErr(ctx, w, http.StatusInternalServerError, "failed to check existing pools", err.Error())
 			return
 		}
-		for _, proposed := range req.Pools {
-			pp, err := netip.ParsePrefix(proposed.CIDR)
-			if err != nil {
-				continue
-			}
-			for _, ex := range existing {
-				ep, err := netip.ParsePrefix(ex.CIDR)
-				if err != nil {
-					continue
-				}
-				if prefixesOverlapIPv4(pp, ep) {
-					s.writeErr(ctx, w, http.StatusConflict,
-						fmt.Sprintf("pool %q (%s) overlaps with existing pool %q (%s)", proposed.Name, proposed.CIDR, ex.Name, ex.CIDR),
-						"set skip_conflicts to true to bypass this check")
-					return
-				}
-			}
-		}
+		writeJSON(w, http.StatusOK, cs)
+		return
 	}
 
-	// Create pools in order (the request must be topologically sorted)
-	refToID := make(map[string]int64)
-	var created, skipped int
-	var errs []string
-	var rootPoolID int64
-
-	for _, p := range req.Pools {
-		poolType := domain.PoolType(p.Type)
-		if poolType == "" {
-			poolType = domain.PoolTypeSubnet
-		}
-		if !domain.IsValidPoolType(poolType) {
-			errs = append(errs, fmt.Sprintf("pool %q: invalid type %q, using subnet", p.Ref, p.Type))
-			poolType = domain.PoolTypeSubnet
-		}
-
-		cp := domain.CreatePool{
-			Name:        p.Name,
-			CIDR:        p.CIDR,
-			Type:        poolType,
-			Status:      status,
-			Source:      domain.PoolSourceManual,
-			Description: "Created by Schema Planner",
-			Tags:        tags,
-		}
-
-		// Resolve parent ref
-		if p.ParentRef != "" {
-			parentID, ok := refToID[p.ParentRef]
-			if !ok {
-				errs = append(errs, fmt.Sprintf("pool %q: parent_ref %q not yet created", p.Ref, p.ParentRef))
-				skipped++
-				continue
-			}
-			cp.ParentID = &parentID
-		}
-
-		pool, err := s.store.CreatePool(ctx, cp)
-		if err != nil {
-			errs = append(errs, fmt.Sprintf("pool %q: %v", p.Ref, err))
-			skipped++
-			continue
-		}
-
-		refToID[p.Ref] = pool.ID
-		if p.ParentRef == "" {
-			rootPoolID = pool.ID
-		}
-		created++
-
-		s.logAudit(ctx, "create", "pool", fmt.Sprintf("%d", pool.ID), pool.Name, http.StatusCreated)
+	// Create pools in order (the request must be topologically sorted). The
+	// whole tree is created in one transaction: if any pool fails, none are
+	// kept. If skip_conflicts is false, overlaps are checked first.
+	var res poolTreeResult
+	err := s.withTx(ctx, func(st storage.Store) error {
+		var err error
+		res, err = s.createPoolTree(ctx, st, tree)
+		return err
+	})
+	var conflict *poolTreeConflict
+	if errors.As(err, &conflict) {
+		s.writeErr(ctx, w, http.StatusConflict, conflict.Error(), "set skip_conflicts to true to bypass this check")
+		return
 	}
-
-	resp := schemaApplyResponse{
-		Created:    created,
-		Skipped:    skipped,
-		Errors:     errs,
-		RootPoolID: rootPoolID,
-		PoolMap:    refToID,
+	if err != nil {
+		s.writeStoreErr(ctx, w, err)
+		return
 	}
-	if resp.Errors == nil {
-		resp.Errors = []string{}
+	for _, pool := range res.Created {
+		s.logAudit(ctx, "create", "pool", fmt.Sprintf("%d", pool.ID), pool.Name, http.StatusCreated)
 	}
-	writeJSON(w, http.StatusOK, resp)
+
+	writeJSON(w, http.StatusOK, schemaApplyResponse{
+		Created:    len(res.Created),
+		Errors:     res.Warnings,
+		RootPoolID: res.RootPoolID,
+		PoolMap:    res.PoolMap,
+	})
 }
@@ -2,8 +2,12 @@ package api
 
 import (
 	"encoding/json"
+	"io"
 	stdhttp "net/http"
 	"testing"
+
+	"cloudpam/internal/observability"
+	"cloudpam/internal/storage"
 )
 
 func TestSchemaCheck_NoConflicts(t *testing.T) {
@@ -197,3 +201,29 @@ func TestSchemaApply_MethodNotAllowed(t *testing.T) {
 	srv, _ := setupTestServer()
 	doJSON(t, srv.mux, stdhttp.MethodGet, "/api/v1/schema/apply", "", stdhttp.StatusMethodNotAllowed)
 }
+
+func TestSchemaApply_RollsBackOnFailure(t *testing.T) {
+	base := storage.NewMemoryStore()
+	st := &failingPoolStoreCov{MemoryStore: base, failName: "Doomed"}
+	logger := observability.NewLogger(observability.Config{Level: "info", Format: "json", Output: io.Discard})
+	srv := NewServer(stdhttp.NewServeMux(), st, logger, nil, nil)
+	srv.registerUnprotectedTestRoutes()
+
+	body := `{
+		"pools":[
+			{"ref":"root","name":"Root","cidr":"10.0.0.0/8","type":"supernet","parent_ref":""},
+			{"ref":"r0","name":"us-east-1","cidr":"10.0.0.0/12","type":"region","parent_ref":"root"},
+			{"ref":"r1","name":"Doomed","cidr":"10.16.0.0/12","type":"region","parent_ref":"root"}
+		],
+		"skip_conflicts":true
+	}`
+	doJSON(t, srv.mux, stdhttp.MethodPost, "/api/v1/schema/apply", body, stdhttp.StatusInternalServerError)
+
+	pools, err := base.ListPools(t.Context())
+	if err != nil {
+		t.Fatalf("list pools: %v", err)
+	}
+	if len(pools) != 0 {
+		t.Fatalf("failed apply should leave no pools behind, got %d", len(pools))
+	}
+}
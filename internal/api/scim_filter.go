@@ -0,0 +1,160 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+)
+
+// scimComparison is one attribute expression of a SCIM filter, such as
+// userName eq "alice" or emails pr.
+type scimComparison struct {
+	attr  string // lower-cased attribute path
+	op    string // eq, ne, co, sw, ew, pr
+	value string // lower-cased comparison value; empty for pr
+}
+
+// scimFilter is a parsed SCIM filter (RFC 7644 §3.4.2.2). Only
+// comparisons joined by "and" are supported, which covers what identity
+// providers send when matching users and groups; "or", "not" and
+// grouping are rejected.
+type scimFilter []scimComparison
+
+// parseSCIMFilter parses a filter expression. attrs lists the attribute
+// paths (lower case) the resource supports. An empty filter matches
+// everything.
+func parseSCIMFilter(s string, attrs map[string]bool) (scimFilter, error) {
+	tokens, err := scimFilterTokens(s)
+	if err != nil {
+		return nil, err
+	}
+	var f scimFilter
+	for i := 0; i < len(tokens); {
+		if len(f) > 0 {
+			if !strings.EqualFold(tokens[i], "and") {
+				return nil, fmt.Errorf("unsupported operator %q; only \"and\" may join expressions", tokens[i])
+			}
+			i++
+			if i == len(tokens) {
+				return nil, fmt.Errorf("expression expected after \"and\"")
+			}
+		}
+		attr := strings.ToLower(tokens[i])
+		if !attrs[attr] {
+			return nil, fmt.Errorf("unsupported attribute %q", tokens[i])
+		}
+		if i+1 == len(tokens) {
+			return nil, fmt.Errorf("operator expected after %q", tokens[i])
+		}
+		op := strings.ToLower(tokens[i+1])
+		switch op {
+		case "pr":
+			f = append(f, scimComparison{attr: attr, op: op})
+			i += 2
+			continue
+		case "eq", "ne", "co", "sw", "ew":
+		default:
+			return nil, fmt.Errorf("unsupported operator %q", tokens[i+1])
+		}
+		if i+2 == len(tokens) {
+			return nil, fmt.Errorf("value expected after %q", tokens[i+1])
+		}
+		value := tokens[i+2]
+		if strings.HasPrefix(value, `"`) {
+			value = strings.TrimSuffix(strings.TrimPrefix(value, `"`), `"`)
+		}
+		f = append(f, scimComparison{attr: attr, op: op, value: strings.ToLower(value)})
+		i += 3
+	}
+	return f, nil
+}
+
+// scimFilterTokens splits a filter on whitespace, keeping quoted strings
+// (with their quotes) as single tokens.
+func scimFilterTokens(s string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(' || c == ')' || c == '[' || c == ']':
+			return nil, fmt.Errorf("grouping is not supported")
+		case c == '"':
+			var b strings.Builder
+			b.WriteByte('"')
+			j := i + 1
+			for ; j < len(s) && s[j] != '"'; j++ {
+				if s[j] == '\\' && j+1 < len(s) {
+					j++
+				}
+				b.WriteByte(s[j])
+			}
+			if j == len(s) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			b.WriteByte('"')
+			tokens = append(tokens, b.String())
+			i = j + 1
+		default:
+			j := i
+			for j < len(s) && s[j] != ' ' && s[j] != '\t' && s[j] != '(' && s[j] != ')' {
+				j++
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+// matches reports whether a resource matches the filter. values returns
+// the values of an attribute path, so that multi-valued attributes such
+// as emails match when any value does. Comparisons ignore case.
+func (f scimFilter) matches(values func(attr string) []string) bool {
+	for _, c := range f {
+		if !c.matches(values(c.attr)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c scimComparison) matches(values []string) bool {
+	if c.op == "pr" {
+		for _, v := range values {
+			if v != "" {
+				return true
+			}
+		}
+		return false
+	}
+	if c.op == "ne" {
+		for _, v := range values {
+			if strings.EqualFold(v, c.value) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, v := range values {
+		v = strings.ToLower(v)
+		switch c.op {
+		case "eq":
+			if v == c.value {
+				return true
+			}
+		case "co":
+			if strings.Contains(v, c.value) {
+				return true
+			}
+		case "sw":
+			if strings.HasPrefix(v, c.value) {
+				return true
+			}
+		case "ew":
+			if strings.HasSuffix(v, c.value) {
+				return true
+			}
+		}
+	}
+	return false
+}
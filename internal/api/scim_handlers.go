@@ -0,0 +1,1154 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloudpam/internal/audit"
+	"cloudpam/internal/auth"
+
+	"github.com/google/uuid"
+)
+
+// SCIM 2.0 (RFC 7643, RFC 7644) schema URNs.
+const (
+	scimSchemaUser           = "urn:ietf:params:scim:schemas:core:2.0:User"
+	scimSchemaGroup          = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	scimSchemaSPConfig       = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	scimSchemaListResponse   = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	scimSchemaError          = "urn:ietf:params:scim:api:messages:2.0:Error"
+	scimContentType          = "application/scim+json"
+	scimDefaultCount         = 100
+	scimMaxCount             = 500
+	scimAuthProvider         = "scim"
+	scimGroupRoleDescription = "Provisioned by SCIM"
+)
+
+// SCIMServer serves a SCIM 2.0 provisioning API over users and roles.
+// Users map onto auth.User; groups map onto custom roles. Built-in roles
+// are not groups, so a provisioning key cannot hand out admin, and only
+// users that came from the identity provider are visible. A user holds
+// exactly one role, so adding a user to a group moves them out of their
+// previous one, and removing them returns them to viewer.
+type SCIMServer struct {
+	srv          *Server
+	userStore    auth.UserStore
+	roleStore    auth.RoleStore
+	sessionStore auth.SessionStore
+	keyStore     auth.KeyStore
+}
+
+// NewSCIMServer creates a new SCIMServer.
+func NewSCIMServer(srv *Server, userStore auth.UserStore, roleStore auth.RoleStore, sessionStore auth.SessionStore, keyStore auth.KeyStore) *SCIMServer {
+	return &SCIMServer{srv: srv, userStore: userStore, roleStore: roleStore, sessionStore: sessionStore, keyStore: keyStore}
+}
+
+// RegisterProtectedSCIMRoutes registers the SCIM routes. They accept only
+// API keys with the scim:provision scope, which grants nothing outside
+// /scim/v2, so sessions and ordinary keys are refused.
+func (ss *SCIMServer) RegisterProtectedSCIMRoutes(logger *slog.Logger) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	keyMW := AuthMiddleware(ss.keyStore, true, logger)
+	scopeMW := RequireScopeMiddleware(auth.SCIMScope, logger)
+	for pattern, h := range ss.routes() {
+		ss.srv.handleOpenAPIRoute(pattern, keyMW(scopeMW(http.HandlerFunc(h))))
+	}
+}
+
+// RegisterSCIMRoutesNoAuth registers SCIM routes without auth middleware (for tests).
+func (ss *SCIMServer) RegisterSCIMRoutesNoAuth() {
+	for pattern, h := range ss.routes() {
+		ss.srv.handleOpenAPIRouteFunc(pattern, h)
+	}
+}
+
+func (ss *SCIMServer) routes() map[string]http.HandlerFunc {
+	return map[string]http.HandlerFunc{
+		"GET /scim/v2/ServiceProviderConfig": ss.handleServiceProviderConfig,
+		"GET /scim/v2/Users":                 ss.handleListUsers,
+		"POST /scim/v2/Users":                ss.handleCreateUser,
+		"GET /scim/v2/Users/{id}":            ss.handleGetUser,
+		"PUT /scim/v2/Users/{id}":            ss.handleReplaceUser,
+		"PATCH /scim/v2/Users/{id}":          ss.handlePatchUser,
+		"DELETE /scim/v2/Users/{id}":         ss.handleDeleteUser,
+		"GET /scim/v2/Groups":                ss.handleListGroups,
+		"POST /scim/v2/Groups":               ss.handleCreateGroup,
+		"GET /scim/v2/Groups/{id}":           ss.handleGetGroup,
+		"PUT /scim/v2/Groups/{id}":           ss.handleReplaceGroup,
+		"PATCH /scim/v2/Groups/{id}":         ss.handlePatchGroup,
+		"DELETE /scim/v2/Groups/{id}":        ss.handleDeleteGroup,
+	}
+}
+
+// scimMeta is the meta attribute common to SCIM resources.
+type scimMeta struct {
+	ResourceType string     `json:"resourceType"`
+	Created      *time.Time `json:"created,omitempty"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
+	Location     string     `json:"location,omitempty"`
+}
+
+// scimName is the name attribute of a SCIM user. Only formatted is stored,
+// as the display name.
+type scimName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// scimValue is an entry of a multi-valued attribute such as emails,
+// members or groups.
+type scimValue struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// scimUser is the SCIM representation of a user.
+type scimUser struct {
+	Schemas     []string    `json:"schemas"`
+	ID          string      `json:"id,omitempty"`
+	UserName    string      `json:"userName"`
+	Name        *scimName   `json:"name,omitempty"`
+	DisplayName string      `json:"displayName,omitempty"`
+	Emails      []scimValue `json:"emails,omitempty"`
+	Active      *bool       `json:"active,omitempty"`
+	Groups      []scimValue `json:"groups,omitempty"`
+	Meta        *scimMeta   `json:"meta,omitempty"`
+}
+
+// scimGroup is the SCIM representation of a role and its members.
+type scimGroup struct {
+	Schemas     []string    `json:"schemas"`
+	ID          string      `json:"id,omitempty"`
+	DisplayName string      `json:"displayName"`
+	Members     []scimValue `json:"members,omitempty"`
+	Meta        *scimMeta   `json:"meta,omitempty"`
+}
+
+// scimListResponse is a page of SCIM resources.
+type scimListResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    any      `json:"Resources"`
+}
+
+// scimPatchRequest is a SCIM PATCH body.
+type scimPatchRequest struct {
+	Schemas    []string             `json:"schemas"`
+	Operations []scimPatchOperation `json:"Operations"`
+}
+
+// scimPatchOperation is one operation of a SCIM PATCH. Op is matched
+// without regard to case, as some identity providers send "Replace".
+type scimPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// scimError is the SCIM error response body.
+type scimError struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+// errSCIMBadRequest marks request errors raised while applying a PATCH
+// or PUT, which are reported as 400 invalidValue.
+var errSCIMBadRequest = errors.New("invalid value")
+
+var (
+	scimUserFilterAttrs  = map[string]bool{"id": true, "username": true, "displayname": true, "name.formatted": true, "emails": true, "emails.value": true, "active": true}
+	scimGroupFilterAttrs = map[string]bool{"id": true, "displayname": true, "members": true, "members.value": true}
+
+	// scimMemberPath matches the member filter in paths such as
+	// members[value eq "2819c223-7f76-453a-919d-413861904646"].
+	scimMemberPath = regexp.MustCompile(`(?i)^members\[value eq "([^"]+)"\]$`)
+)
+
+func writeSCIM(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", scimContentType)
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func (ss *SCIMServer) writeSCIMError(ctx context.Context, w http.ResponseWriter, code int, scimType, detail string) {
+	fields := appendRequestID(ctx, []any{"status", code, "scim_type", scimType, "detail", detail})
+	if code >= 500 {
+		ss.srv.logger.ErrorContext(ctx, "scim request failed", fields...)
+	} else {
+		ss.srv.logger.WarnContext(ctx, "scim request failed", fields...)
+	}
+	writeSCIM(w, code, scimError{
+		Schemas:  []string{scimSchemaError},
+		Status:   strconv.Itoa(code),
+		ScimType: scimType,
+		Detail:   detail,
+	})
+}
+
+// handleServiceProviderConfig describes the supported SCIM features.
+// GET /scim/v2/ServiceProviderConfig
+func (ss *SCIMServer) handleServiceProviderConfig(w http.ResponseWriter, r *http.Request) {
+	supported := func(ok bool) map[string]bool { return map[string]bool{"supported": ok} }
+	writeSCIM(w, http.StatusOK, map[string]any{
+		"schemas":        []string{scimSchemaSPConfig},
+		"patch":          supported(true),
+		"bulk":           map[string]any{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]any{"supported": true, "maxResults": scimMaxCount},
+		"changePassword": supported(false),
+		"sort":           supported(false),
+		"etag":           supported(false),
+		"authenticationSchemes": []map[string]string{{
+			"type":        "oauthbearertoken",
+			"name":        "API key",
+			"description": "A CloudPAM API key with the " + auth.SCIMScope + " scope, sent as a bearer token.",
+		}},
+	})
+}
+
+// handleListUsers lists users, optionally filtered.
+// GET /scim/v2/Users
+func (ss *SCIMServer) handleListUsers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	filter, err := parseSCIMFilter(r.URL.Query().Get("filter"), scimUserFilterAttrs)
+	if err != nil {
+		ss.writeSCIMError(ctx, w, http.StatusBadRequest, "invalidFilter", err.Error())
+		return
+	}
+	startIndex, count := scimPage(r)
+
+	users, err := ss.userStore.List(ctx)
+	if err != nil {
+		ss.writeSCIMError(ctx, w, http.StatusInternalServerError, "", err.Error())
+		return
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Username < users[j].Username })
+
+	matched := make([]scimUser, 0, len(users))
+	for _, u := range users {
+		if scimManaged(u) && filter.matches(scimUserValues(u)) {
+			matched = append(matched, toSCIMUser(u))
+		}
+	}
+	page := scimPageOf(matched, startIndex, count)
+	writeSCIM(w, http.StatusOK, scimListResponse{
+		Schemas:      []string{scimSchemaListResponse},
+		TotalResults: len(matched),
+		StartIndex:   startIndex,
+		ItemsPerPage: len(page),
+		Resources:    page,
+	})
+}
+
+// handleCreateUser provisions a user. Provisioned users sign in through
+// SSO and are linked to their identity provider account on first login.
+// POST /scim/v2/Users
+func (ss *SCIMServer) handleCreateUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var in scimUser
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		ss.writeSCIMError(ctx, w, http.StatusBadRequest, "invalidSyntax", "invalid json")
+		return
+	}
+	username := strings.TrimSpace(in.UserName)
+	if username == "" {
+		ss.writeSCIMError(ctx, w, http.StatusBadRequest, "invalidValue", "userName is required")
+		return
+	}
+	existing, err := ss.userStore.GetByUsername(ctx, username)
+	if err != nil {
+		ss.writeSCIMError(ctx, w, http.StatusInternalServerError, "", err.Error())
+		return
+	}
+	if existing != nil {
+		ss.writeSCIMError(ctx, w, http.StatusConflict, "uniqueness", "userName is already in use")
+		return
+	}
+
+	now := time.Now().UTC()
+	user := &auth.User{
+		ID:           uuid.New().String(),
+		Username:     username,
+		Email:        scimPrimaryEmail(in.Emails),
+		DisplayName:  scimDisplayName(in),
+		Role:         auth.RoleViewer,
+		IsActive:     in.Active == nil || *in.Active,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		AuthProvider: scimAuthProvider,
+	}
+	if err := ss.userStore.Create(ctx, user); err != nil {
+		if errors.Is(err, auth.ErrUserExists) {
+			ss.writeSCIMError(ctx, w, http.StatusConflict, "uniqueness", "userName is already in use")
+			return
+		}
+		ss.writeSCIMError(ctx, w, http.StatusInternalServerError, "", err.Error())
+		return
+	}
+	ss.srv.logAudit(ctx, audit.ActionCreate, audit.ResourceUser, user.ID, user.Username, http.StatusCreated)
+
+	out := toSCIMUser(user)
+	w.Header().Set("Location", out.Meta.Location)
+	writeSCIM(w, http.StatusCreated, out)
+}
+
+// handleGetUser returns one user.
+// GET /scim/v2/Users/{id}
+func (ss *SCIMServer) handleGetUser(w http.ResponseWriter, r *http.Request) {
+	user, ok := ss.loadUser(w, r)
+	if !ok {
+		return
+	}
+	writeSCIM(w, http.StatusOK, toSCIMUser(user))
+}
+
+// handleReplaceUser replaces a user's attributes. Omitting active leaves
+// it unchanged.
+// PUT /scim/v2/Users/{id}
+func (ss *SCIMServer) handleReplaceUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := ss.loadUser(w, r)
+	if !ok {
+		return
+	}
+	var in scimUser
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		ss.writeSCIMError(ctx, w, http.StatusBadRequest, "invalidSyntax", "invalid json")
+		return
+	}
+	if strings.TrimSpace(in.UserName) == "" {
+		ss.writeSCIMError(ctx, w, http.StatusBadRequest, "invalidValue", "userName is required")
+		return
+	}
+
+	before := *user
+	user.Username = strings.TrimSpace(in.UserName)
+	user.DisplayName = scimDisplayName(in)
+	user.Email = scimPrimaryEmail(in.Emails)
+	if in.Active != nil {
+		user.IsActive = *in.Active
+	}
+	ss.saveUser(w, r, &before, user)
+}
+
+// handlePatchUser applies SCIM PATCH operations to a user. Setting active
+// to false disables the user and revokes their sessions and API keys.
+// Attributes CloudPAM does not store are ignored.
+// PATCH /scim/v2/Users/{id}
+func (ss *SCIMServer) handlePatchUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := ss.loadUser(w, r)
+	if !ok {
+		return
+	}
+	ops, ok := ss.decodePatch(w, r)
+	if !ok {
+		return
+	}
+
+	before := *user
+	for _, op := range ops {
+		if err := applySCIMUserOp(user, op); err != nil {
+			ss.writeSCIMError(ctx, w, http.StatusBadRequest, "invalidValue", err.Error())
+			return
+		}
+	}
+	ss.saveUser(w, r, &before, user)
+}
+
+// handleDeleteUser deactivates a user and revokes their sessions and API
+// keys. The account is kept, as with DELETE /api/v1/auth/users/{id}, so
+// that audit history still resolves.
+// DELETE /scim/v2/Users/{id}
+func (ss *SCIMServer) handleDeleteUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := ss.loadUser(w, r)
+	if !ok {
+		return
+	}
+	user.IsActive = false
+	user.UpdatedAt = time.Now().UTC()
+	if err := ss.userStore.Update(ctx, user); err != nil {
+		ss.writeSCIMError(ctx, w, http.StatusInternalServerError, "", err.Error())
+		return
+	}
+	if err := ss.revokeAccess(ctx, user.ID); err != nil {
+		ss.writeSCIMError(ctx, w, http.StatusInternalServerError, "", err.Error())
+		return
+	}
+	ss.srv.logAudit(ctx, audit.ActionDelete, audit.ResourceUser, user.ID, user.Username, http.StatusNoContent)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// loadUser resolves the path's user. Local accounts are reported as not
+// found, so SCIM cannot rename, deactivate or delete them.
+func (ss *SCIMServer) loadUser(w http.ResponseWriter, r *http.Request) (*auth.User, bool) {
+	ctx := r.Context()
+	user, err := ss.userStore.GetByID(ctx, r.PathValue("id"))
+	if err != nil {
+		ss.writeSCIMError(ctx, w, http.StatusInternalServerError, "", err.Error())
+		return nil, false
+	}
+	if user == nil || !scimManaged(user) {
+		ss.writeSCIMError(ctx, w, http.StatusNotFound, "", "user not found")
+		return nil, false
+	}
+	return user, true
+}
+
+// saveUser stores a modified user, checking that a new userName is free
+// and revoking access if the user was deactivated.
+func (ss *SCIMServer) saveUser(w http.ResponseWriter, r *http.Request, before, user *auth.User) {
+	ctx := r.Context()
+	if user.Username == "" {
+		ss.writeSCIMError(ctx, w, http.StatusBadRequest, "invalidValue", "userName is required")
+		return
+	}
+	if user.Username != before.Username {
+		existing, err := ss.userStore.GetByUsername(ctx, user.Username)
+		if err != nil {
+			ss.writeSCIMError(ctx, w, http.StatusInternalServerError, "", err.Error())
+			return
+		}
+		if existing != nil && existing.ID != user.ID {
+			ss.writeSCIMError(ctx, w, http.StatusConflict, "uniqueness", "userName is already in use")
+			return
+		}
+	}
+
+	user.UpdatedAt = time.Now().UTC()
+	if err := ss.userStore.Update(ctx, user); err != nil {
+		ss.writeSCIMError(ctx, w, http.StatusInternalServerError, "", err.Error())
+		return
+	}
+	if before.IsActive && !user.IsActive {
+		if err := ss.revokeAccess(ctx, user.ID); err != nil {
+			ss.writeSCIMError(ctx, w, http.StatusInternalServerError, "", err.Error())
+			return
+		}
+	}
+
+	var changes *audit.Changes
+	if before.IsActive != user.IsActive {
+		changes = &audit.Changes{
+			Before: map[string]any{"is_active": before.IsActive},
+			After:  map[string]any{"is_active": user.IsActive},
+		}
+	}
+	ss.srv.logAuditWithChanges(ctx, audit.ActionUpdate, audit.ResourceUser, user.ID, user.Username, changes, http.StatusOK)
+	writeSCIM(w, http.StatusOK, toSCIMUser(user))
+}
+
+// revokeAccess ends a deactivated user's sessions and revokes the API keys
+// they own. Reactivating the user does not restore the keys.
+func (ss *SCIMServer) revokeAccess(ctx context.Context, userID string) error {
+	if ss.sessionStore != nil {
+		if err := ss.sessionStore.DeleteByUserID(ctx, userID); err != nil {
+			return err
+		}
+	}
+	if ss.keyStore == nil {
+		return nil
+	}
+	keys, err := ss.keyStore.ListByOwner(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if key.Revoked {
+			continue
+		}
+		if err := ss.keyStore.Revoke(ctx, key.ID); err != nil {
+			return err
+		}
+		ss.srv.logAudit(ctx, audit.ActionDelete, audit.ResourceAPIKey, key.ID, key.Name, http.StatusOK)
+	}
+	return nil
+}
+
+// applySCIMUserOp applies one PATCH operation to a user. An operation
+// without a path carries an object of attribute values.
+func applySCIMUserOp(u *auth.User, op scimPatchOperation) error {
+	kind := strings.ToLower(op.Op)
+	if op.Path == "" {
+		if kind == "remove" {
+			return errors.New("remove requires a path")
+		}
+		var attrs map[string]json.RawMessage
+		if err := json.Unmarshal(op.Value, &attrs); err != nil {
+			return errors.New("value must be an object when path is omitted")
+		}
+		for path, value := range attrs {
+			if err := setSCIMUserAttr(u, kind, path, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return setSCIMUserAttr(u, kind, op.Path, op.Value)
+}
+
+func setSCIMUserAttr(u *auth.User, kind, path string, value json.RawMessage) error {
+	switch kind {
+	case "add", "replace", "remove":
+	default:
+		return errors.New("unsupported op " + kind)
+	}
+	remove := kind == "remove"
+	path = strings.ToLower(path)
+	switch {
+	case path == "active":
+		if remove {
+			return errors.New("active cannot be removed")
+		}
+		active, err := scimBool(value)
+		if err != nil {
+			return err
+		}
+		u.IsActive = active
+	case path == "username":
+		if remove {
+			return errors.New("userName cannot be removed")
+		}
+		s, err := scimString(value)
+		if err != nil {
+			return err
+		}
+		u.Username = strings.TrimSpace(s)
+	case path == "displayname" || path == "name.formatted":
+		if remove {
+			u.DisplayName = ""
+			return nil
+		}
+		s, err := scimString(value)
+		if err != nil {
+			return err
+		}
+		u.DisplayName = strings.TrimSpace(s)
+	case path == "name":
+		if remove {
+			u.DisplayName = ""
+			return nil
+		}
+		var name scimName
+		if err := json.Unmarshal(value, &name); err != nil {
+			return errors.New("name must be an object")
+		}
+		if name.Formatted != "" {
+			u.DisplayName = strings.TrimSpace(name.Formatted)
+		}
+	case path == "emails":
+		if remove {
+			u.Email = ""
+			return nil
+		}
+		var emails []scimValue
+		if err := json.Unmarshal(value, &emails); err != nil {
+			return errors.New("emails must be a list")
+		}
+		u.Email = scimPrimaryEmail(emails)
+	case strings.HasPrefix(path, "emails[") || path == "emails.value":
+		// emails[type eq "work"].value, as sent by Microsoft Entra ID.
+		if remove {
+			u.Email = ""
+			return nil
+		}
+		s, err := scimString(value)
+		if err != nil {
+			return err
+		}
+		u.Email = strings.TrimSpace(s)
+	}
+	return nil
+}
+
+// handleListGroups lists custom roles as groups. Filters on displayName are
+// compared against the role name the display name would produce.
+// GET /scim/v2/Groups
+func (ss *SCIMServer) handleListGroups(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	q := r.URL.Query()
+	filter, err := parseSCIMFilter(q.Get("filter"), scimGroupFilterAttrs)
+	if err != nil {
+		ss.writeSCIMError(ctx, w, http.StatusBadRequest, "invalidFilter", err.Error())
+		return
+	}
+	for i := range filter {
+		if filter[i].attr == "displayname" {
+			filter[i].value = string(scimRoleName(filter[i].value))
+		}
+	}
+	withMembers := !strings.Contains(strings.ToLower(q.Get("excludedAttributes")), "members")
+	startIndex, count := scimPage(r)
+
+	roles, err := ss.roleStore.ListRoles(ctx)
+	if err != nil {
+		ss.writeSCIMError(ctx, w, http.StatusInternalServerError, "", err.Error())
+		return
+	}
+	members, err := ss.membersByRole(ctx)
+	if err != nil {
+		ss.writeSCIMError(ctx, w, http.StatusInternalServerError, "", err.Error())
+		return
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+
+	matched := make([]scimGroup, 0, len(roles))
+	for _, role := range roles {
+		if role.IsBuiltin || auth.IsBuiltinRole(role.Name) {
+			continue
+		}
+		g := toSCIMGroup(role, members[role.Name])
+		if !filter.matches(scimGroupValues(g)) {
+			continue
+		}
+		if !withMembers {
+			g.Members = nil
+		}
+		matched = append(matched, g)
+	}
+	page := scimPageOf(matched, startIndex, count)
+	writeSCIM(w, http.StatusOK, scimListResponse{
+		Schemas:      []string{scimSchemaListResponse},
+		TotalResults: len(matched),
+		StartIndex:   startIndex,
+		ItemsPerPage: len(page),
+		Resources:    page,
+	})
+}
+
+// handleCreateGroup creates a custom role with no permissions, named after
+// the group's display name (lower case, spaces as dashes), and assigns it
+// to the listed members. An administrator grants its permissions.
+// POST /scim/v2/Groups
+func (ss *SCIMServer) handleCreateGroup(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var in scimGroup
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		ss.writeSCIMError(ctx, w, http.StatusBadRequest, "invalidSyntax", "invalid json")
+		return
+	}
+	name := scimRoleName(in.DisplayName)
+	if _, err := ss.roleStore.GetRole(ctx, name); err == nil {
+		ss.writeSCIMError(ctx, w, http.StatusConflict, "uniqueness", "a role named "+string(name)+" already exists")
+		return
+	}
+	role := &auth.RoleDefinition{Name: name, Description: scimGroupRoleDescription, Permissions: []auth.Permission{}}
+	if err := ss.roleStore.CreateRole(ctx, role); err != nil {
+		switch {
+		case errors.Is(err, auth.ErrRoleExists):
+			ss.writeSCIMError(ctx, w, http.StatusConflict, "uniqueness", "a role named "+string(name)+" already exists")
+		case errors.Is(err, auth.ErrInvalidRole), errors.Is(err, auth.ErrBuiltinRole):
+			ss.writeSCIMError(ctx, w, http.StatusBadRequest, "invalidValue", "displayName does not make a valid role name")
+		default:
+			ss.writeSCIMError(ctx, w, http.StatusInternalServerError, "", err.Error())
+		}
+		return
+	}
+	ss.srv.logAudit(ctx, audit.ActionCreate, "role", string(role.Name), string(role.Name), http.StatusCreated)
+
+	if err := ss.addMembers(ctx, role.Name, scimValueIDs(in.Members)); err != nil {
+		ss.writeGroupMemberError(ctx, w, err)
+		return
+	}
+	g, err := ss.group(ctx, role.Name)
+	if err != nil {
+		ss.writeSCIMError(ctx, w, http.StatusInternalServerError, "", err.Error())
+		return
+	}
+	w.Header().Set("Location", g.Meta.Location)
+	writeSCIM(w, http.StatusCreated, g)
+}
+
+// handleGetGroup returns one role as a group.
+// GET /scim/v2/Groups/{id}
+func (ss *SCIMServer) handleGetGroup(w http.ResponseWriter, r *http.Request) {
+	name, ok := ss.loadGroup(w, r)
+	if !ok {
+		return
+	}
+	ss.writeGroup(w, r, name)
+}
+
+// handleReplaceGroup sets a group's members to exactly those listed.
+// PUT /scim/v2/Groups/{id}
+func (ss *SCIMServer) handleReplaceGroup(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	name, ok := ss.loadGroup(w, r)
+	if !ok {
+		return
+	}
+	var in scimGroup
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		ss.writeSCIMError(ctx, w, http.StatusBadRequest, "invalidSyntax", "invalid json")
+		return
+	}
+	if in.DisplayName != "" && scimRoleName(in.DisplayName) != name {
+		ss.writeSCIMError(ctx, w, http.StatusBadRequest, "mutability", "groups cannot be renamed")
+		return
+	}
+	if err := ss.replaceMembers(ctx, name, scimValueIDs(in.Members)); err != nil {
+		ss.writeGroupMemberError(ctx, w, err)
+		return
+	}
+	ss.writeGroup(w, r, name)
+}
+
+// handlePatchGroup adds, removes or replaces group members.
+// PATCH /scim/v2/Groups/{id}
+func (ss *SCIMServer) handlePatchGroup(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	name, ok := ss.loadGroup(w, r)
+	if !ok {
+		return
+	}
+	ops, ok := ss.decodePatch(w, r)
+	if !ok {
+		return
+	}
+	for _, op := range ops {
+		if err := ss.applyGroupOp(ctx, name, op); err != nil {
+			ss.writeGroupMemberError(ctx, w, err)
+			return
+		}
+	}
+	ss.writeGroup(w, r, name)
+}
+
+// handleDeleteGroup deletes a custom role. Roles that still have active
+// members cannot be deleted.
+// DELETE /scim/v2/Groups/{id}
+func (ss *SCIMServer) handleDeleteGroup(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	name, ok := ss.loadGroup(w, r)
+	if !ok {
+		return
+	}
+	if err := ss.roleStore.DeleteRole(ctx, name); err != nil {
+		switch {
+		case errors.Is(err, auth.ErrRoleInUse):
+			ss.writeSCIMError(ctx, w, http.StatusConflict, "", "role is assigned to active users")
+		case errors.Is(err, auth.ErrRoleNotFound):
+			ss.writeSCIMError(ctx, w, http.StatusNotFound, "", "group not found")
+		default:
+			ss.writeSCIMError(ctx, w, http.StatusInternalServerError, "", err.Error())
+		}
+		return
+	}
+	ss.srv.logAudit(ctx, audit.ActionDelete, "role", string(name), string(name), http.StatusNoContent)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// loadGroup resolves the path's group to a custom role. Built-in roles are
+// reported as not found.
+func (ss *SCIMServer) loadGroup(w http.ResponseWriter, r *http.Request) (auth.Role, bool) {
+	ctx := r.Context()
+	name := auth.NormalizeRoleName(r.PathValue("id"))
+	if auth.IsBuiltinRole(name) {
+		ss.writeSCIMError(ctx, w, http.StatusNotFound, "", "group not found")
+		return auth.RoleNone, false
+	}
+	if _, err := ss.roleStore.GetRole(ctx, name); err != nil {
+		if errors.Is(err, auth.ErrRoleNotFound) {
+			ss.writeSCIMError(ctx, w, http.StatusNotFound, "", "group not found")
+		} else {
+			ss.writeSCIMError(ctx, w, http.StatusInternalServerError, "", err.Error())
+		}
+		return auth.RoleNone, false
+	}
+	return name, true
+}
+
+func (ss *SCIMServer) writeGroup(w http.ResponseWriter, r *http.Request, name auth.Role) {
+	g, err := ss.group(r.Context(), name)
+	if err != nil {
+		ss.writeSCIMError(r.Context(), w, http.StatusInternalServerError, "", err.Error())
+		return
+	}
+	writeSCIM(w, http.StatusOK, g)
+}
+
+func (ss *SCIMServer) group(ctx context.Context, name auth.Role) (scimGroup, error) {
+	role, err := ss.roleStore.GetRole(ctx, name)
+	if err != nil {
+		return scimGroup{}, err
+	}
+	members, err := ss.membersByRole(ctx)
+	if err != nil {
+		return scimGroup{}, err
+	}
+	return toSCIMGroup(role, members[name]), nil
+}
+
+// membersByRole groups the users SCIM manages by role, ordered by
+// username.
+func (ss *SCIMServer) membersByRole(ctx context.Context) (map[auth.Role][]*auth.User, error) {
+	users, err := ss.userStore.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Username < users[j].Username })
+	out := make(map[auth.Role][]*auth.User)
+	for _, u := range users {
+		if scimManaged(u) {
+			out[u.Role] = append(out[u.Role], u)
+		}
+	}
+	return out, nil
+}
+
+// errSCIMMemberNotFound is returned when a member value is not a user ID.
+var errSCIMMemberNotFound = errors.New("member not found")
+
+func (ss *SCIMServer) writeGroupMemberError(ctx context.Context, w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errSCIMMemberNotFound), errors.Is(err, errSCIMBadRequest):
+		ss.writeSCIMError(ctx, w, http.StatusBadRequest, "invalidValue", err.Error())
+	default:
+		ss.writeSCIMError(ctx, w, http.StatusInternalServerError, "", err.Error())
+	}
+}
+
+func (ss *SCIMServer) applyGroupOp(ctx context.Context, name auth.Role, op scimPatchOperation) error {
+	kind := strings.ToLower(op.Op)
+	path := strings.ToLower(op.Path)
+
+	if path == "" {
+		if kind == "remove" {
+			return scimBadRequest("remove requires a path")
+		}
+		var attrs map[string]json.RawMessage
+		if err := json.Unmarshal(op.Value, &attrs); err != nil {
+			return scimBadRequest("value must be an object when path is omitted")
+		}
+		for attr, value := range attrs {
+			if err := ss.applyGroupOp(ctx, name, scimPatchOperation{Op: kind, Path: attr, Value: value}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if m := scimMemberPath.FindStringSubmatch(op.Path); m != nil {
+		if kind != "remove" {
+			return scimBadRequest("only remove is supported on a member filter")
+		}
+		return ss.removeMembers(ctx, name, []string{m[1]})
+	}
+
+	switch path {
+	case "displayname":
+		if kind == "remove" {
+			return scimBadRequest("displayName cannot be removed")
+		}
+		s, err := scimString(op.Value)
+		if err != nil {
+			return err
+		}
+		if scimRoleName(s) != name {
+			return scimBadRequest("groups cannot be renamed")
+		}
+		return nil
+	case "members":
+		var values []scimValue
+		if len(op.Value) > 0 {
+			if err := json.Unmarshal(op.Value, &values); err != nil {
+				return scimBadRequest("members must be a list")
+			}
+		}
+		ids := scimValueIDs(values)
+		switch kind {
+		case "add":
+			return ss.addMembers(ctx, name, ids)
+		case "replace":
+			return ss.replaceMembers(ctx, name, ids)
+		case "remove":
+			if len(op.Value) == 0 {
+				return ss.replaceMembers(ctx, name, nil)
+			}
+			return ss.removeMembers(ctx, name, ids)
+		}
+		return scimBadRequest("unsupported op " + kind)
+	default:
+		return nil
+	}
+}
+
+func scimBadRequest(msg string) error {
+	return fmt.Errorf("%w: %s", errSCIMBadRequest, msg)
+}
+
+// addMembers gives the listed users the role.
+func (ss *SCIMServer) addMembers(ctx context.Context, name auth.Role, ids []string) error {
+	for _, id := range ids {
+		if err := ss.setUserRole(ctx, id, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeMembers returns the listed users who hold the role to viewer.
+// Users holding a different role are left alone.
+func (ss *SCIMServer) removeMembers(ctx context.Context, name auth.Role, ids []string) error {
+	for _, id := range ids {
+		user, err := ss.userStore.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if user == nil || !scimManaged(user) || user.Role != name {
+			continue
+		}
+		if err := ss.setUserRole(ctx, id, auth.RoleViewer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replaceMembers makes the listed users the role's only members.
+func (ss *SCIMServer) replaceMembers(ctx context.Context, name auth.Role, ids []string) error {
+	keep := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		keep[id] = true
+	}
+	members, err := ss.membersByRole(ctx)
+	if err != nil {
+		return err
+	}
+	var drop []string
+	for _, u := range members[name] {
+		if !keep[u.ID] {
+			drop = append(drop, u.ID)
+		}
+	}
+	if err := ss.addMembers(ctx, name, ids); err != nil {
+		return err
+	}
+	return ss.removeMembers(ctx, name, drop)
+}
+
+func (ss *SCIMServer) setUserRole(ctx context.Context, id string, role auth.Role) error {
+	user, err := ss.userStore.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if user == nil || !scimManaged(user) {
+		return fmt.Errorf("%w: %s", errSCIMMemberNotFound, id)
+	}
+	if user.Role == role {
+		return nil
+	}
+	changes := &audit.Changes{
+		Before: map[string]any{"role": string(user.Role)},
+		After:  map[string]any{"role": string(role)},
+	}
+	user.Role = role
+	user.UpdatedAt = time.Now().UTC()
+	if err := ss.userStore.Update(ctx, user); err != nil {
+		return err
+	}
+	ss.srv.logAuditWithChanges(ctx, audit.ActionUpdate, audit.ResourceUser, user.ID, user.Username, changes, http.StatusOK)
+	return nil
+}
+
+// scimManaged reports whether SCIM may see and change u: accounts it
+// provisioned, and accounts created by OIDC sign-in.
+func scimManaged(u *auth.User) bool {
+	return u.AuthProvider == scimAuthProvider || u.AuthProvider == "oidc"
+}
+
+func (ss *SCIMServer) decodePatch(w http.ResponseWriter, r *http.Request) ([]scimPatchOperation, bool) {
+	ctx := r.Context()
+	var in scimPatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		ss.writeSCIMError(ctx, w, http.StatusBadRequest, "invalidSyntax", "invalid json")
+		return nil, false
+	}
+	if len(in.Operations) == 0 {
+		ss.writeSCIMError(ctx, w, http.StatusBadRequest, "invalidSyntax", "Operations is required")
+		return nil, false
+	}
+	return in.Operations, true
+}
+
+func toSCIMUser(u *auth.User) scimUser {
+	active := u.IsActive
+	created, modified := u.CreatedAt, u.UpdatedAt
+	out := scimUser{
+		Schemas:     []string{scimSchemaUser},
+		ID:          u.ID,
+		UserName:    u.Username,
+		DisplayName: u.DisplayName,
+		Active:      &active,
+		Groups:      []scimValue{{Value: string(u.Role), Display: string(u.Role)}},
+		Meta: &scimMeta{
+			ResourceType: "User",
+			Created:      &created,
+			LastModified: &modified,
+			Location:     "/scim/v2/Users/" + u.ID,
+		},
+	}
+	if u.DisplayName != "" {
+		out.Name = &scimName{Formatted: u.DisplayName}
+	}
+	if u.Email != "" {
+		out.Emails = []scimValue{{Value: u.Email, Type: "work", Primary: true}}
+	}
+	return out
+}
+
+func toSCIMGroup(role *auth.RoleDefinition, members []*auth.User) scimGroup {
+	g := scimGroup{
+		Schemas:     []string{scimSchemaGroup},
+		ID:          string(role.Name),
+		DisplayName: string(role.Name),
+		Members:     make([]scimValue, 0, len(members)),
+		Meta: &scimMeta{
+			ResourceType: "Group",
+			Location:     "/scim/v2/Groups/" + string(role.Name),
+		},
+	}
+	if !role.CreatedAt.IsZero() {
+		created, modified := role.CreatedAt, role.UpdatedAt
+		g.Meta.Created, g.Meta.LastModified = &created, &modified
+	}
+	for _, u := range members {
+		g.Members = append(g.Members, scimValue{Value: u.ID, Display: u.Username})
+	}
+	return g
+}
+
+func scimUserValues(u *auth.User) func(string) []string {
+	return func(attr string) []string {
+		switch attr {
+		case "id":
+			return []string{u.ID}
+		case "username":
+			return []string{u.Username}
+		case "displayname", "name.formatted":
+			return []string{u.DisplayName}
+		case "emails", "emails.value":
+			return []string{u.Email}
+		case "active":
+			return []string{strconv.FormatBool(u.IsActive)}
+		}
+		return nil
+	}
+}
+
+func scimGroupValues(g scimGroup) func(string) []string {
+	return func(attr string) []string {
+		switch attr {
+		case "id", "displayname":
+			return []string{g.ID}
+		case "members", "members.value":
+			return scimValueIDs(g.Members)
+		}
+		return nil
+	}
+}
+
+// scimRoleName turns a group display name into a role name:
+// "Network Engineers" becomes "network-engineers".
+func scimRoleName(displayName string) auth.Role {
+	return auth.NormalizeRoleName(strings.Join(strings.Fields(displayName), "-"))
+}
+
+func scimValueIDs(values []scimValue) []string {
+	ids := make([]string, 0, len(values))
+	for _, v := range values {
+		if v.Value != "" {
+			ids = append(ids, v.Value)
+		}
+	}
+	return ids
+}
+
+// scimPrimaryEmail returns the primary email, or the first one.
+func scimPrimaryEmail(emails []scimValue) string {
+	for _, e := range emails {
+		if e.Primary {
+			return strings.TrimSpace(e.Value)
+		}
+	}
+	if len(emails) > 0 {
+		return strings.TrimSpace(emails[0].Value)
+	}
+	return ""
+}
+
+// scimDisplayName prefers displayName, then name.formatted, then the
+// given and family names.
+func scimDisplayName(u scimUser) string {
+	if s := strings.TrimSpace(u.DisplayName); s != "" {
+		return s
+	}
+	if u.Name == nil {
+		return ""
+	}
+	if s := strings.TrimSpace(u.Name.Formatted); s != "" {
+		return s
+	}
+	return strings.TrimSpace(u.Name.GivenName + " " + u.Name.FamilyName)
+}
+
+// scimBool accepts a JSON boolean or the strings "True" and "False",
+// which Microsoft Entra ID sends in PATCH operations.
+func scimBool(raw json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(raw, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b, nil
+		}
+	}
+	return false, scimBadRequest("expected a boolean")
+}
+
+func scimString(raw json.RawMessage) (string, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return "", scimBadRequest("expected a string")
+	}
+	return s, nil
+}
+
+// scimPage reads the 1-based startIndex and count query parameters.
+func scimPage(r *http.Request) (startIndex, count int) {
+	q := r.URL.Query()
+	startIndex, count = 1, scimDefaultCount
+	if n, err := strconv.Atoi(q.Get("startIndex")); err == nil && n > 1 {
+		startIndex = n
+	}
+	if n, err := strconv.Atoi(q.Get("count")); err == nil && n >= 0 {
+		count = min(n, scimMaxCount)
+	}
+	return startIndex, count
+}
+
+func scimPageOf[T any](items []T, startIndex, count int) []T {
+	from := min(startIndex-1, len(items))
+	to := min(from+count, len(items))
+	return items[from:to]
+}
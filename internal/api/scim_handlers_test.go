@@ -0,0 +1,322 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"cloudpam/internal/auth"
+	"cloudpam/internal/storage"
+)
+
+type scimTestEnv struct {
+	srv          *Server
+	scim         *SCIMServer
+	userStore    *auth.MemoryUserStore
+	roleStore    *auth.MemoryRoleStore
+	sessionStore *auth.MemorySessionStore
+	keyStore     *auth.MemoryKeyStore
+}
+
+func setupSCIMTestEnv(t *testing.T, protected bool) *scimTestEnv {
+	t.Helper()
+	st := storage.NewMemoryStore()
+	srv := NewServer(http.NewServeMux(), st, nil, nil, nil)
+	userStore := auth.NewMemoryUserStore()
+	env := &scimTestEnv{
+		srv:          srv,
+		userStore:    userStore,
+		roleStore:    auth.NewMemoryRoleStore(userStore),
+		sessionStore: auth.NewMemorySessionStore(),
+		keyStore:     auth.NewMemoryKeyStore(),
+	}
+	env.scim = NewSCIMServer(srv, env.userStore, env.roleStore, env.sessionStore, env.keyStore)
+	if protected {
+		env.scim.RegisterProtectedSCIMRoutes(nil)
+	} else {
+		env.scim.RegisterSCIMRoutesNoAuth()
+	}
+	return env
+}
+
+func (env *scimTestEnv) do(t *testing.T, method, path, body string, code int) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", scimContentType)
+	}
+	rr := httptest.NewRecorder()
+	env.srv.mux.ServeHTTP(rr, req)
+	if rr.Code != code {
+		t.Fatalf("%s %s: expected code %d, got %d: %s", method, path, code, rr.Code, rr.Body.String())
+	}
+	return rr
+}
+
+func decodeSCIM[T any](t *testing.T, rr *httptest.ResponseRecorder) T {
+	t.Helper()
+	var v T
+	if err := json.Unmarshal(rr.Body.Bytes(), &v); err != nil {
+		t.Fatalf("unmarshal: %v: %s", err, rr.Body.String())
+	}
+	return v
+}
+
+// createSCIMTestUser stores a user as if SCIM had provisioned it.
+func createSCIMTestUser(t *testing.T, userStore auth.UserStore, id, username string, role auth.Role) *auth.User {
+	t.Helper()
+	user := &auth.User{
+		ID: id, Username: username, Role: role, IsActive: true, AuthProvider: scimAuthProvider,
+		CreatedAt: time.Now().UTC(), UpdatedAt: time.Now().UTC(),
+	}
+	if err := userStore.Create(context.Background(), user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return user
+}
+
+func TestSCIM_UserLifecycle(t *testing.T) {
+	env := setupSCIMTestEnv(t, false)
+	ctx := context.Background()
+
+	body := `{"schemas":["` + scimSchemaUser + `"],"userName":"alice@example.com","externalId":"00u1",
+		"name":{"givenName":"Alice","familyName":"Smith"},
+		"emails":[{"value":"alice@example.com","type":"work","primary":true}],"active":true}`
+	rr := env.do(t, http.MethodPost, "/scim/v2/Users", body, http.StatusCreated)
+	if ct := rr.Header().Get("Content-Type"); ct != scimContentType {
+		t.Errorf("content type = %q", ct)
+	}
+	created := decodeSCIM[scimUser](t, rr)
+	if created.ID == "" || created.UserName != "alice@example.com" || created.Meta.Location != "/scim/v2/Users/"+created.ID {
+		t.Fatalf("created = %+v", created)
+	}
+	user, err := env.userStore.GetByID(ctx, created.ID)
+	if err != nil || user == nil {
+		t.Fatalf("stored user: %v", err)
+	}
+	if user.Role != auth.RoleViewer || user.AuthProvider != scimAuthProvider || user.DisplayName != "Alice Smith" {
+		t.Errorf("stored user = %+v", user)
+	}
+
+	env.do(t, http.MethodPost, "/scim/v2/Users", body, http.StatusConflict)
+
+	list := decodeSCIM[scimListResponse](t, env.do(t, http.MethodGet, `/scim/v2/Users?filter=userName+eq+%22ALICE@example.com%22`, "", http.StatusOK))
+	if list.TotalResults != 1 {
+		t.Errorf("filtered totalResults = %d, want 1", list.TotalResults)
+	}
+	list = decodeSCIM[scimListResponse](t, env.do(t, http.MethodGet, `/scim/v2/Users?filter=userName+eq+%22bob%22`, "", http.StatusOK))
+	if list.TotalResults != 0 {
+		t.Errorf("unmatched totalResults = %d, want 0", list.TotalResults)
+	}
+	env.do(t, http.MethodGet, `/scim/v2/Users?filter=userName+eq+%22a%22+or+userName+eq+%22b%22`, "", http.StatusBadRequest)
+
+	// Deactivation ends sessions and revokes the user's keys.
+	session, _ := auth.NewSession(user.ID, user.Role, time.Hour, nil)
+	_ = env.sessionStore.Create(ctx, session)
+	_, key, _ := auth.GenerateAPIKey(auth.GenerateAPIKeyOptions{Name: "alice-key", Scopes: []string{"pools:read"}})
+	key.OwnerID = &user.ID
+	_ = env.keyStore.Create(ctx, key)
+
+	patch := `{"schemas":["urn:ietf:params:scim:api:messages:2.0:PatchOp"],"Operations":[{"op":"Replace","path":"active","value":"False"}]}`
+	patched := decodeSCIM[scimUser](t, env.do(t, http.MethodPatch, "/scim/v2/Users/"+user.ID, patch, http.StatusOK))
+	if patched.Active == nil || *patched.Active {
+		t.Errorf("active = %v, want false", patched.Active)
+	}
+	if n := env.sessionStore.CountByUser(user.ID); n != 0 {
+		t.Errorf("sessions after deactivation = %d, want 0", n)
+	}
+	if k, _ := env.keyStore.GetByID(ctx, key.ID); k == nil || !k.Revoked {
+		t.Error("owned API key was not revoked")
+	}
+
+	env.do(t, http.MethodDelete, "/scim/v2/Users/"+user.ID, "", http.StatusNoContent)
+	if u, _ := env.userStore.GetByID(ctx, user.ID); u == nil || u.IsActive {
+		t.Errorf("deleted user = %+v, want kept and inactive", u)
+	}
+	env.do(t, http.MethodGet, "/scim/v2/Users/missing", "", http.StatusNotFound)
+}
+
+func TestSCIM_GroupMembership(t *testing.T) {
+	env := setupSCIMTestEnv(t, false)
+	ctx := context.Background()
+	user := createSCIMTestUser(t, env.userStore, "u1", "bob", auth.RoleViewer)
+
+	rr := env.do(t, http.MethodPost, "/scim/v2/Groups", `{"displayName":"Network Engineers"}`, http.StatusCreated)
+	group := decodeSCIM[scimGroup](t, rr)
+	if group.ID != "network-engineers" {
+		t.Fatalf("group id = %q, want network-engineers", group.ID)
+	}
+	if role, _ := env.roleStore.GetRole(ctx, "network-engineers"); role == nil || role.IsBuiltin {
+		t.Fatalf("role = %+v, want custom role", role)
+	}
+	env.do(t, http.MethodPost, "/scim/v2/Groups", `{"displayName":"network engineers"}`, http.StatusConflict)
+
+	list := decodeSCIM[scimListResponse](t, env.do(t, http.MethodGet, `/scim/v2/Groups?filter=displayName+eq+%22Network+Engineers%22`, "", http.StatusOK))
+	if list.TotalResults != 1 {
+		t.Errorf("filtered totalResults = %d, want 1", list.TotalResults)
+	}
+
+	add := `{"Operations":[{"op":"add","path":"members","value":[{"value":"u1"}]}]}`
+	group = decodeSCIM[scimGroup](t, env.do(t, http.MethodPatch, "/scim/v2/Groups/network-engineers", add, http.StatusOK))
+	if len(group.Members) != 1 || group.Members[0].Value != "u1" {
+		t.Errorf("members = %+v", group.Members)
+	}
+	if u, _ := env.userStore.GetByID(ctx, user.ID); u.Role != "network-engineers" {
+		t.Errorf("role after add = %q", u.Role)
+	}
+
+	// The role is in use, so the group cannot be deleted yet.
+	env.do(t, http.MethodDelete, "/scim/v2/Groups/network-engineers", "", http.StatusConflict)
+
+	remove := `{"Operations":[{"op":"remove","path":"members[value eq \"u1\"]"}]}`
+	env.do(t, http.MethodPatch, "/scim/v2/Groups/network-engineers", remove, http.StatusOK)
+	if u, _ := env.userStore.GetByID(ctx, user.ID); u.Role != auth.RoleViewer {
+		t.Errorf("role after remove = %q, want viewer", u.Role)
+	}
+
+	unknown := `{"Operations":[{"op":"add","path":"members","value":[{"value":"nope"}]}]}`
+	env.do(t, http.MethodPatch, "/scim/v2/Groups/network-engineers", unknown, http.StatusBadRequest)
+	env.do(t, http.MethodPut, "/scim/v2/Groups/network-engineers", `{"displayName":"Other"}`, http.StatusBadRequest)
+
+	env.do(t, http.MethodDelete, "/scim/v2/Groups/admin", "", http.StatusNotFound)
+	env.do(t, http.MethodDelete, "/scim/v2/Groups/network-engineers", "", http.StatusNoContent)
+	env.do(t, http.MethodGet, "/scim/v2/Groups/network-engineers", "", http.StatusNotFound)
+}
+
+func TestSCIM_BuiltinRolesAreNotGroups(t *testing.T) {
+	env := setupSCIMTestEnv(t, false)
+	ctx := context.Background()
+	createSCIMTestUser(t, env.userStore, "u1", "bob", auth.RoleViewer)
+
+	list := decodeSCIM[scimListResponse](t, env.do(t, http.MethodGet, "/scim/v2/Groups", "", http.StatusOK))
+	if list.TotalResults != 0 {
+		t.Fatalf("groups = %+v, want no built-in roles", list.Resources)
+	}
+
+	add := `{"Operations":[{"op":"add","path":"members","value":[{"value":"u1"}]}]}`
+	env.do(t, http.MethodPatch, "/scim/v2/Groups/admin", add, http.StatusNotFound)
+	env.do(t, http.MethodPut, "/scim/v2/Groups/Admin", `{"members":[{"value":"u1"}]}`, http.StatusNotFound)
+	env.do(t, http.MethodGet, "/scim/v2/Groups/operator", "", http.StatusNotFound)
+	if u, _ := env.userStore.GetByID(ctx, "u1"); u.Role != auth.RoleViewer {
+		t.Fatalf("role = %q, want viewer", u.Role)
+	}
+}
+
+func TestSCIM_LocalUsersAreOutOfReach(t *testing.T) {
+	env := setupSCIMTestEnv(t, false)
+	ctx := context.Background()
+	admin := createMFATestUser(t, env.userStore, "local-admin", "admin", auth.RoleAdmin)
+	createSCIMTestUser(t, env.userStore, "u1", "bob", auth.RoleViewer)
+	session, _ := auth.NewSession(admin.ID, admin.Role, time.Hour, nil)
+	_ = env.sessionStore.Create(ctx, session)
+
+	list := decodeSCIM[scimListResponse](t, env.do(t, http.MethodGet, "/scim/v2/Users", "", http.StatusOK))
+	if list.TotalResults != 1 {
+		t.Fatalf("users totalResults = %d, want only the provisioned user", list.TotalResults)
+	}
+
+	deactivate := `{"Operations":[{"op":"replace","path":"active","value":false}]}`
+	env.do(t, http.MethodPatch, "/scim/v2/Users/local-admin", deactivate, http.StatusNotFound)
+	env.do(t, http.MethodPut, "/scim/v2/Users/local-admin", `{"userName":"taken-over","active":false}`, http.StatusNotFound)
+	env.do(t, http.MethodDelete, "/scim/v2/Users/local-admin", "", http.StatusNotFound)
+	env.do(t, http.MethodGet, "/scim/v2/Users/local-admin", "", http.StatusNotFound)
+
+	// Nor can a group pull the local admin in and demote them.
+	env.do(t, http.MethodPost, "/scim/v2/Groups", `{"displayName":"Readers"}`, http.StatusCreated)
+	add := `{"Operations":[{"op":"add","path":"members","value":[{"value":"local-admin"}]}]}`
+	env.do(t, http.MethodPatch, "/scim/v2/Groups/readers", add, http.StatusBadRequest)
+
+	u, _ := env.userStore.GetByID(ctx, admin.ID)
+	if u == nil || !u.IsActive || u.Username != "admin" || u.Role != auth.RoleAdmin {
+		t.Fatalf("local admin = %+v, want unchanged", u)
+	}
+	if n := env.sessionStore.CountByUser(admin.ID); n != 1 {
+		t.Fatalf("local admin sessions = %d, want 1", n)
+	}
+}
+
+func TestSCIM_RequiresProvisioningScope(t *testing.T) {
+	env := setupSCIMTestEnv(t, true)
+	ctx := context.Background()
+
+	call := func(key string) int {
+		req := httptest.NewRequest(http.MethodGet, "/scim/v2/Users", nil)
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		rr := httptest.NewRecorder()
+		env.srv.mux.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	adminKey, key, _ := auth.GenerateAPIKey(auth.GenerateAPIKeyOptions{Name: "admin", Scopes: []string{"*"}})
+	_ = env.keyStore.Create(ctx, key)
+	scimKey, key, _ := auth.GenerateAPIKey(auth.GenerateAPIKeyOptions{Name: "okta", Scopes: []string{auth.SCIMScope}})
+	_ = env.keyStore.Create(ctx, key)
+	poolsKey, key, _ := auth.GenerateAPIKey(auth.GenerateAPIKeyOptions{Name: "pools", Scopes: []string{"pools:write"}})
+	_ = env.keyStore.Create(ctx, key)
+
+	if code := call(""); code != http.StatusUnauthorized {
+		t.Errorf("no key: got %d, want 401", code)
+	}
+	if code := call(poolsKey); code != http.StatusForbidden {
+		t.Errorf("pools key: got %d, want 403", code)
+	}
+	if code := call(scimKey); code != http.StatusOK {
+		t.Errorf("scim key: got %d, want 200", code)
+	}
+	if code := call(adminKey); code != http.StatusOK {
+		t.Errorf("wildcard key: got %d, want 200", code)
+	}
+}
+
+func TestParseSCIMFilter(t *testing.T) {
+	attrs := map[string]bool{"username": true, "emails.value": true, "active": true}
+	values := func(attr string) []string {
+		switch attr {
+		case "username":
+			return []string{"Alice@Example.com"}
+		case "emails.value":
+			return []string{"alice@example.com", "a.smith@corp.example"}
+		}
+		return nil
+	}
+	tests := []struct {
+		filter  string
+		want    bool
+		wantErr bool
+	}{
+		{filter: "", want: true},
+		{filter: `userName eq "alice@example.com"`, want: true},
+		{filter: `userName ne "alice@example.com"`, want: false},
+		{filter: `userName sw "alice" and emails.value ew "corp.example"`, want: true},
+		{filter: `emails.value co "smith"`, want: true},
+		{filter: `userName pr`, want: true},
+		{filter: `active pr`, want: false},
+		{filter: `userName eq "a" or userName eq "b"`, wantErr: true},
+		{filter: `(userName eq "a")`, wantErr: true},
+		{filter: `title eq "x"`, wantErr: true},
+		{filter: `userName gt "x"`, wantErr: true},
+		{filter: `userName eq "x`, wantErr: true},
+	}
+	for _, tt := range tests {
+		f, err := parseSCIMFilter(tt.filter, attrs)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%q: expected error", tt.filter)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tt.filter, err)
+			continue
+		}
+		if got := f.matches(values); got != tt.want {
+			t.Errorf("%q: matches = %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+}
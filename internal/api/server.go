@@ -12,7 +12,9 @@ import (
 
 	"cloudpam/internal/audit"
 	"cloudpam/internal/auth"
+	"cloudpam/internal/domain"
 	"cloudpam/internal/observability"
+	"cloudpam/internal/planning"
 	"cloudpam/internal/storage"
 )
 
@@ -39,6 +41,10 @@ type Server struct {
 	userStore        auth.UserStore
 	roleStore        auth.RoleStore
 	settingsStore    storage.SettingsStore
+	admission        PoolAdmissionChecker
+	proposals        storage.ChangeProposalStore
+	poolChanges      storage.PoolChangeRequestStore
+	reservations     storage.ReservationStore
 	appVersion       string
 	openAPIRoutes    []openAPIRoute
 	openAPIRouteKeys map[string]bool
@@ -67,6 +73,29 @@ func (s *Server) SetRoleStore(rs auth.RoleStore) { s.roleStore = rs }
 // SetSettingsStore sets the settings store for runtime configuration lookups.
 func (s *Server) SetSettingsStore(ss storage.SettingsStore) { s.settingsStore = ss }
 
+// PoolAdmissionChecker vets a pool before it is created and returns the
+// compliance violations that should block it. CheckPlannedPools reports
+// every violation, enforced or not, for pool tree dry runs.
+type PoolAdmissionChecker interface {
+	CheckPoolAdmission(ctx context.Context, in domain.CreatePool) ([]planning.ComplianceViolation, error)
+	CheckPlannedPools(ctx context.Context, pools []planning.PlannedPool) ([]planning.ComplianceViolation, error)
+}
+
+// SetPoolAdmission enables admission checks on pool creation.
+func (s *Server) SetPoolAdmission(c PoolAdmissionChecker) { s.admission = c }
+
+// SetChangeProposalStore lets schema and AI plan applies be stored as
+// change proposals for review.
+func (s *Server) SetChangeProposalStore(cs storage.ChangeProposalStore) { s.proposals = cs }
+
+// SetPoolChangeRequestStore enables approval policies: pool mutations they
+// match are stored as change requests instead of being applied.
+func (s *Server) SetPoolChangeRequestStore(cs storage.PoolChangeRequestStore) { s.poolChanges = cs }
+
+// SetReservationStore makes creates, the allocator and schema conflict
+// checks treat active reservations as occupied.
+func (s *Server) SetReservationStore(rs storage.ReservationStore) { s.reservations = rs }
+
 // SetNeedsSetup marks the server as requiring first-boot admin setup.
 func (s *Server) SetNeedsSetup(v bool) { s.needsSetup = v }
 
@@ -121,11 +150,43 @@ func (s *Server) writeStoreErr(ctx context.Context, w http.ResponseWriter, err e
 		s.writeErr(ctx, w, http.StatusConflict, err.Error(), "")
 	case errors.Is(err, storage.ErrValidation):
 		s.writeErr(ctx, w, http.StatusBadRequest, err.Error(), "")
+	case errors.Is(err, storage.ErrPreconditionFailed):
+		s.writeErr(ctx, w, http.StatusPreconditionFailed, err.Error(), "")
 	default:
 		s.writeErr(ctx, w, http.StatusInternalServerError, "internal error", err.Error())
 	}
 }
 
+// withTx runs fn inside a store transaction so multi-step writes land
+// all-or-nothing. Stores without transaction support run fn directly.
+func (s *Server) withTx(ctx context.Context, fn func(st storage.Store) error) error {
+	ts, ok := s.store.(storage.TransactionalStore)
+	if !ok {
+		return fn(s.store)
+	}
+	return ts.WithTx(ctx, func(tx storage.Transaction) error { return fn(tx) })
+}
+
+// txStore returns the transaction's own view of a companion store (the SQL
+// transactions also implement DiscoveryStore, DriftStore and so on, and the
+// memory transaction binds its companion stores), or fallback when the
+// transaction does not provide one. A nil fallback means the feature is
+// disabled and stays nil.
+func txStore[T any](st storage.Store, fallback T) T {
+	if any(fallback) == nil {
+		return fallback
+	}
+	if v, ok := st.(T); ok {
+		return v
+	}
+	if b, ok := st.(storage.TxBinder); ok {
+		if v, ok := b.Bind(fallback).(T); ok {
+			return v
+		}
+	}
+	return fallback
+}
+
 // logAudit logs an audit event for CRUD operations.
 func (s *Server) logAudit(ctx context.Context, action, resourceType, resourceID, resourceName string, statusCode int) {
 	s.logAuditWithChanges(ctx, action, resourceType, resourceID, resourceName, nil, statusCode)
@@ -5,7 +5,7 @@ import (
 )
 
 func TestComputeSubnetsIPv4Window_Basics(t *testing.T) {
-	blocks, hosts, total, err := computeSubnetsIPv4Window("10.0.0.0/16", 24, 0, 0)
+	blocks, hosts, total, err := computeSubnetsWindow("10.0.0.0/16", 24, 0, 0)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -28,7 +28,7 @@ func TestComputeSubnetsIPv4Window_Basics(t *testing.T) {
 }
 
 func TestComputeSubnetsIPv4Window_Paged(t *testing.T) {
-	blocks, hosts, total, err := computeSubnetsIPv4Window("192.168.0.0/16", 20, 4, 3)
+	blocks, hosts, total, err := computeSubnetsWindow("192.168.0.0/16", 20, 4, 3)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
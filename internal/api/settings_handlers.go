@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"strings"
 
+	"cloudpam/internal/audit"
 	"cloudpam/internal/auth"
 	"cloudpam/internal/domain"
 	"cloudpam/internal/storage"
@@ -35,6 +36,14 @@ func (ss *SettingsServer) RegisterProtectedSettingsRoutes(dualMW func(http.Handl
 		dualMW(adminRead(http.HandlerFunc(ss.handleGetNetworkSchemaPolicy))))
 	ss.handleOpenAPIRoute("PATCH /api/v1/settings/network-schema-policy",
 		dualMW(adminWrite(http.HandlerFunc(ss.handleUpdateNetworkSchemaPolicy))))
+	ss.handleOpenAPIRoute("GET /api/v1/settings/audit-retention",
+		dualMW(adminRead(http.HandlerFunc(ss.handleGetAuditRetention))))
+	ss.handleOpenAPIRoute("PATCH /api/v1/settings/audit-retention",
+		dualMW(adminWrite(http.HandlerFunc(ss.handleUpdateAuditRetention))))
+	ss.handleOpenAPIRoute("GET /api/v1/settings/approvals",
+		dualMW(adminRead(http.HandlerFunc(ss.handleGetApprovalSettings))))
+	ss.handleOpenAPIRoute("PATCH /api/v1/settings/approvals",
+		dualMW(adminWrite(http.HandlerFunc(ss.handleUpdateApprovalSettings))))
 }
 
 // RegisterSettingsRoutes registers settings endpoints without RBAC (for tests).
@@ -43,6 +52,10 @@ func (ss *SettingsServer) RegisterSettingsRoutes() {
 	ss.handleOpenAPIRouteFunc("PATCH /api/v1/settings/security", ss.handleUpdateSecuritySettings)
 	ss.handleOpenAPIRouteFunc("GET /api/v1/settings/network-schema-policy", ss.handleGetNetworkSchemaPolicy)
 	ss.handleOpenAPIRouteFunc("PATCH /api/v1/settings/network-schema-policy", ss.handleUpdateNetworkSchemaPolicy)
+	ss.handleOpenAPIRouteFunc("GET /api/v1/settings/audit-retention", ss.handleGetAuditRetention)
+	ss.handleOpenAPIRouteFunc("PATCH /api/v1/settings/audit-retention", ss.handleUpdateAuditRetention)
+	ss.handleOpenAPIRouteFunc("GET /api/v1/settings/approvals", ss.handleGetApprovalSettings)
+	ss.handleOpenAPIRouteFunc("PATCH /api/v1/settings/approvals", ss.handleUpdateApprovalSettings)
 }
 
 func (ss *SettingsServer) handleGetSecuritySettings(w http.ResponseWriter, r *http.Request) {
@@ -113,6 +126,14 @@ func (ss *SettingsServer) handleUpdateSecuritySettings(w http.ResponseWriter, r
 		ss.writeErr(r.Context(), w, http.StatusBadRequest, "invalid api_key_allowed_scopes_by_role", "scope policy contains an invalid or elevated scope")
 		return
 	}
+	for i, role := range input.MFARequiredRoles {
+		name := auth.NormalizeRoleName(role)
+		if name == auth.RoleNone {
+			ss.writeErr(r.Context(), w, http.StatusBadRequest, "invalid mfa_required_roles", "role names must not be empty")
+			return
+		}
+		input.MFARequiredRoles[i] = string(name)
+	}
 
 	input = *domain.NormalizeSecuritySettings(&input)
 	if err := ss.settingsStore.UpdateSecuritySettings(r.Context(), &input); err != nil {
@@ -154,6 +175,93 @@ func (ss *SettingsServer) handleUpdateNetworkSchemaPolicy(w http.ResponseWriter,
 	writeJSON(w, http.StatusOK, policy)
 }
 
+func (ss *SettingsServer) handleGetAuditRetention(w http.ResponseWriter, r *http.Request) {
+	settings, err := ss.settingsStore.GetAuditRetentionSettings(r.Context())
+	if err != nil {
+		ss.writeErr(r.Context(), w, http.StatusInternalServerError, "failed to load audit retention settings", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, settings)
+}
+
+func (ss *SettingsServer) handleUpdateAuditRetention(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var input domain.AuditRetentionSettings
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		ss.writeErr(ctx, w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+	if denied := domain.ValidateAuditRetentionSettings(&input); denied != "" {
+		ss.writeErr(ctx, w, http.StatusBadRequest, "invalid audit retention settings", denied)
+		return
+	}
+	before, err := ss.settingsStore.GetAuditRetentionSettings(ctx)
+	if err != nil {
+		ss.writeErr(ctx, w, http.StatusInternalServerError, "failed to load audit retention settings", err.Error())
+		return
+	}
+	if err := ss.settingsStore.UpdateAuditRetentionSettings(ctx, &input); err != nil {
+		ss.writeErr(ctx, w, http.StatusInternalServerError, "failed to save audit retention settings", err.Error())
+		return
+	}
+
+	// Shortening retention destroys evidence, so the old policy is kept in
+	// the audit trail alongside the new one.
+	ss.logAuditWithChanges(ctx, "update", "settings", "audit_retention", "audit_retention", &audit.Changes{
+		Before: auditRetentionFields(before),
+		After:  auditRetentionFields(&input),
+	}, http.StatusOK)
+	writeJSON(w, http.StatusOK, input)
+}
+
+func auditRetentionFields(s *domain.AuditRetentionSettings) map[string]any {
+	return map[string]any{
+		"max_age_days":            s.MaxAgeDays,
+		"max_events":              s.MaxEvents,
+		"successful_max_age_days": s.SuccessfulMaxAgeDays,
+	}
+}
+
+func (ss *SettingsServer) handleGetApprovalSettings(w http.ResponseWriter, r *http.Request) {
+	settings, err := ss.settingsStore.GetApprovalSettings(r.Context())
+	if err != nil {
+		ss.writeErr(r.Context(), w, http.StatusInternalServerError, "failed to load approval settings", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, settings)
+}
+
+func (ss *SettingsServer) handleUpdateApprovalSettings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var input domain.ApprovalSettings
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		ss.writeErr(ctx, w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+	if denied := domain.ValidateApprovalSettings(&input); denied != "" {
+		ss.writeErr(ctx, w, http.StatusBadRequest, "invalid approval settings", denied)
+		return
+	}
+	before, err := ss.settingsStore.GetApprovalSettings(ctx)
+	if err != nil {
+		ss.writeErr(ctx, w, http.StatusInternalServerError, "failed to load approval settings", err.Error())
+		return
+	}
+	settings := domain.NormalizeApprovalSettings(&input)
+	if err := ss.settingsStore.UpdateApprovalSettings(ctx, settings); err != nil {
+		ss.writeErr(ctx, w, http.StatusInternalServerError, "failed to save approval settings", err.Error())
+		return
+	}
+
+	// Dropping a policy lets changes skip review, so the old policies are
+	// kept in the audit trail alongside the new ones.
+	ss.logAuditWithChanges(ctx, "update", "settings", "approvals", "approvals", &audit.Changes{
+		Before: map[string]any{"policies": before.Policies},
+		After:  map[string]any{"policies": settings.Policies},
+	}, http.StatusOK)
+	writeJSON(w, http.StatusOK, settings)
+}
+
 func validateAPIKeyScopePolicy(policy map[string][]string) string {
 	for role, scopes := range policy {
 		var authRole auth.Role
@@ -1,12 +1,14 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	stdhttp "net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
+	"cloudpam/internal/audit"
 	"cloudpam/internal/domain"
 	"cloudpam/internal/storage"
 )
@@ -286,3 +288,56 @@ func TestSettingsHandler_UpdateInvalidBounds(t *testing.T) {
 		})
 	}
 }
+
+func TestSettingsHandler_AuditRetention(t *testing.T) {
+	st := storage.NewMemoryStore()
+	mux := stdhttp.NewServeMux()
+	auditLogger := audit.NewMemoryAuditLogger()
+	srv := NewServer(mux, st, nil, nil, auditLogger)
+	NewSettingsServer(srv, storage.NewMemorySettingsStore()).RegisterSettingsRoutes()
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(stdhttp.MethodGet, "/api/v1/settings/audit-retention", nil))
+	var got domain.AuditRetentionSettings
+	if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if got != domain.DefaultAuditRetentionSettings() {
+		t.Errorf("defaults = %+v", got)
+	}
+
+	for _, body := range []string{
+		`{"max_age_days":-1}`,
+		`{"max_age_days":5000}`,
+		`{"max_events":-10}`,
+		`{"max_age_days":30,"successful_max_age_days":30}`,
+	} {
+		req := httptest.NewRequest(stdhttp.MethodPatch, "/api/v1/settings/audit-retention", strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		if rr.Code != stdhttp.StatusBadRequest {
+			t.Errorf("PATCH %s: expected 400, got %d", body, rr.Code)
+		}
+	}
+
+	req := httptest.NewRequest(stdhttp.MethodPatch, "/api/v1/settings/audit-retention",
+		strings.NewReader(`{"max_age_days":365,"max_events":0,"successful_max_age_days":30}`))
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+	if rr.Code != stdhttp.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(stdhttp.MethodGet, "/api/v1/settings/audit-retention", nil))
+	if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+		t.Fatalf("decode error: %v", err)
+	}
+	if want := (domain.AuditRetentionSettings{MaxAgeDays: 365, SuccessfulMaxAgeDays: 30}); got != want {
+		t.Errorf("stored = %+v, want %+v", got, want)
+	}
+
+	events, _, _ := auditLogger.List(context.Background(), audit.ListOptions{ResourceType: "settings"})
+	if len(events) != 1 || events[0].Changes == nil || events[0].Changes.Before["max_age_days"] != 90 {
+		t.Errorf("audit events = %+v", events)
+	}
+}
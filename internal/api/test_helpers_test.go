@@ -33,4 +33,7 @@ func (s *Server) registerUnprotectedTestRoutes() {
 func (as *AuthServer) registerUnprotectedAuthTestRoutes() {
 	as.handleOpenAPIRouteFunc("/api/v1/auth/keys", as.handleAPIKeys)
 	as.handleOpenAPIRouteFunc("/api/v1/auth/keys/", as.handleAPIKeyByID)
+	as.handleOpenAPIRouteFunc("GET /api/v1/audit/stats", as.handleAuditStats)
+	as.handleOpenAPIRouteFunc("GET /api/v1/audit/verify", as.handleAuditVerify)
+	as.handleOpenAPIRouteFunc("GET /api/v1/audit/export", as.handleAuditExport)
 }
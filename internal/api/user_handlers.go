@@ -26,16 +26,18 @@ type UserServer struct {
 	sessionStore  auth.SessionStore
 	auditLogger   audit.AuditLogger
 	settingsStore storage.SettingsStore
+	mfaChallenges *mfaChallengeStore
 }
 
 // NewUserServer creates a new UserServer.
 func NewUserServer(s *Server, keyStore auth.KeyStore, userStore auth.UserStore, sessionStore auth.SessionStore, auditLogger audit.AuditLogger) *UserServer {
 	return &UserServer{
-		Server:       s,
-		keyStore:     keyStore,
-		userStore:    userStore,
-		sessionStore: sessionStore,
-		auditLogger:  auditLogger,
+		Server:        s,
+		keyStore:      keyStore,
+		userStore:     userStore,
+		sessionStore:  sessionStore,
+		auditLogger:   auditLogger,
+		mfaChallenges: newMFAChallengeStore(),
 	}
 }
 
@@ -67,6 +69,10 @@ func WithLoginRateLimit(mw func(http.Handler) http.Handler) UserRouteOption {
 // RegisterUserRoutes registers user auth routes without RBAC (development mode).
 func (us *UserServer) RegisterUserRoutes() {
 	us.handleOpenAPIRouteFunc("/api/v1/auth/login", us.handleLogin)
+	us.handleOpenAPIRouteFunc("POST /api/v1/auth/login/mfa", us.handleLoginMFA)
+	us.handleOpenAPIRouteFunc("POST /api/v1/auth/login/mfa/enroll", us.handleLoginMFAEnroll)
+	us.handleOpenAPIRouteFunc("POST /api/v1/auth/mfa/enroll", us.handleMFAEnroll)
+	us.handleOpenAPIRouteFunc("POST /api/v1/auth/mfa/verify", us.handleMFAVerify)
 	us.handleOpenAPIRouteFunc("/api/v1/auth/logout", us.handleLogout)
 	us.handleOpenAPIRouteFunc("/api/v1/auth/me", us.handleMe)
 	us.handleOpenAPIRouteFunc("/api/v1/auth/users", us.handleUsers)
@@ -91,9 +97,24 @@ func (us *UserServer) RegisterProtectedUserRoutes(logger *slog.Logger, opts ...U
 	}
 	us.handleOpenAPIRoute("/api/v1/auth/login", loginHandler)
 
+	// The second login step and login-time enrolment authenticate with the
+	// MFA token from the password step, so they share the login rate limit.
+	loginMFAHandler := http.Handler(http.HandlerFunc(us.handleLoginMFA))
+	loginMFAEnrollHandler := http.Handler(http.HandlerFunc(us.handleLoginMFAEnroll))
+	if cfg.loginRateLimit != nil {
+		loginMFAHandler = cfg.loginRateLimit(loginMFAHandler)
+		loginMFAEnrollHandler = cfg.loginRateLimit(loginMFAEnrollHandler)
+	}
+	us.handleOpenAPIRoute("POST /api/v1/auth/login/mfa", loginMFAHandler)
+	us.handleOpenAPIRoute("POST /api/v1/auth/login/mfa/enroll", loginMFAEnrollHandler)
+
 	// Dual auth middleware (session or API key).
 	dualMW := DualAuthMiddleware(us.keyStore, us.sessionStore, us.userStore, true, logger)
 
+	// Self-service MFA enrolment.
+	us.handleOpenAPIRoute("POST /api/v1/auth/mfa/enroll", dualMW(http.HandlerFunc(us.handleMFAEnroll)))
+	us.handleOpenAPIRoute("POST /api/v1/auth/mfa/verify", dualMW(http.HandlerFunc(us.handleMFAVerify)))
+
 	// Logout and me require authentication.
 	us.handleOpenAPIRoute("/api/v1/auth/logout", dualMW(http.HandlerFunc(us.handleLogout)))
 	us.handleOpenAPIRoute("/api/v1/auth/me", dualMW(http.HandlerFunc(us.handleMe)))
@@ -162,6 +183,19 @@ func (us *UserServer) RegisterProtectedUserRoutes(logger *slog.Logger, opts ...U
 			return
 		}
 
+		// Check for /mfa/reset sub-route.
+		if len(parts) == 2 && strings.TrimSuffix(parts[1], "/") == "mfa/reset" {
+			if r.Method == http.MethodPost {
+				usersUpdateMW(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					us.handleResetMFA(w, r, id)
+				})).ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set("Allow", http.MethodPost)
+			us.writeErr(r.Context(), w, http.StatusMethodNotAllowed, "method not allowed", "")
+			return
+		}
+
 		switch r.Method {
 		case http.MethodGet:
 			usersReadMW(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -265,6 +299,22 @@ func (us *UserServer) handleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Users enrolled in MFA, or whose role requires it, must pass a second
+	// step before a session is issued.
+	if user.MFAEnabled || settings.RequiresMFA(string(user.Role)) {
+		us.startMFAChallenge(w, r, user)
+		return
+	}
+
+	us.finishLogin(w, r, user, settings)
+}
+
+// finishLogin creates a session for an authenticated user, sets the session
+// cookie and writes the login response.
+func (us *UserServer) finishLogin(w http.ResponseWriter, r *http.Request, user *auth.User, settings domain.SecuritySettings) {
+	ctx := r.Context()
+	now := time.Now().UTC()
+
 	// Create session.
 	sessionDuration := time.Duration(settings.SessionDurationHours) * time.Hour
 	session, err := auth.NewSession(user.ID, user.Role, sessionDuration, nil)
@@ -472,6 +522,16 @@ func (us *UserServer) handleUserByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if len(parts) == 2 && strings.TrimSuffix(parts[1], "/") == "mfa/reset" {
+		if r.Method == http.MethodPost {
+			us.handleResetMFA(w, r, id)
+			return
+		}
+		w.Header().Set("Allow", http.MethodPost)
+		us.writeErr(r.Context(), w, http.StatusMethodNotAllowed, "method not allowed", "")
+		return
+	}
+
 	switch r.Method {
 	case http.MethodGet:
 		us.getUser(w, r, id)
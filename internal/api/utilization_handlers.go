@@ -0,0 +1,157 @@
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"cloudpam/internal/auth"
+	"cloudpam/internal/planning"
+)
+
+const (
+	defaultHistoryWindow    = 7 * 24 * time.Hour
+	minHistoryBucket        = time.Minute
+	defaultForecastLookback = 90
+	maxForecastLookback     = 730
+)
+
+// UtilizationServer serves pool utilization history and exhaustion
+// forecasts built from periodic snapshots.
+type UtilizationServer struct {
+	srv         *Server
+	utilization *planning.UtilizationService
+}
+
+// NewUtilizationServer creates a new UtilizationServer.
+func NewUtilizationServer(srv *Server, utilization *planning.UtilizationService) *UtilizationServer {
+	return &UtilizationServer{srv: srv, utilization: utilization}
+}
+
+// RegisterProtectedUtilizationRoutes registers utilization routes with RBAC.
+func (us *UtilizationServer) RegisterProtectedUtilizationRoutes(dualMW Middleware, logger *slog.Logger) {
+	listMW := RequirePermissionMiddleware(auth.ResourcePools, auth.ActionList, logger)
+	readMW := RequirePermissionMiddleware(auth.ResourcePools, auth.ActionRead, logger)
+
+	us.srv.handleOpenAPIRoute("GET /api/v1/pools/{id}/utilization/history", dualMW(readMW(http.HandlerFunc(us.handleHistory))))
+	us.srv.handleOpenAPIRoute("GET /api/v1/pools/{id}/utilization/forecast", dualMW(readMW(http.HandlerFunc(us.handleForecast))))
+	us.srv.handleOpenAPIRoute("GET /api/v1/utilization/forecast", dualMW(listMW(http.HandlerFunc(us.handleForecastAll))))
+}
+
+// RegisterUtilizationRoutesNoAuth registers utilization routes without auth middleware (for tests).
+func (us *UtilizationServer) RegisterUtilizationRoutesNoAuth() {
+	us.srv.handleOpenAPIRouteFunc("GET /api/v1/pools/{id}/utilization/history", us.handleHistory)
+	us.srv.handleOpenAPIRouteFunc("GET /api/v1/pools/{id}/utilization/forecast", us.handleForecast)
+	us.srv.handleOpenAPIRouteFunc("GET /api/v1/utilization/forecast", us.handleForecastAll)
+}
+
+// handleHistory returns a pool's utilization snapshots over a time range.
+// from and to are RFC3339 and default to the last seven days; bucket is a
+// duration such as "1h" and is chosen automatically when omitted.
+// GET /api/v1/pools/{id}/utilization/history
+func (us *UtilizationServer) handleHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, ok := us.poolID(w, r)
+	if !ok {
+		return
+	}
+	q := r.URL.Query()
+	req := planning.UtilizationHistoryRequest{To: time.Now().UTC()}
+	if v := q.Get("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			us.srv.writeErr(ctx, w, http.StatusBadRequest, "invalid to", "use RFC3339")
+			return
+		}
+		req.To = t.UTC()
+	}
+	req.From = req.To.Add(-defaultHistoryWindow)
+	if v := q.Get("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			us.srv.writeErr(ctx, w, http.StatusBadRequest, "invalid from", "use RFC3339")
+			return
+		}
+		req.From = t.UTC()
+	}
+	if !req.From.Before(req.To) {
+		us.srv.writeErr(ctx, w, http.StatusBadRequest, "from must be before to", "")
+		return
+	}
+	if v := q.Get("bucket"); v != "" && v != "auto" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < minHistoryBucket {
+			us.srv.writeErr(ctx, w, http.StatusBadRequest, "invalid bucket", fmt.Sprintf("use a duration of at least %s, or auto", minHistoryBucket))
+			return
+		}
+		req.Bucket = d
+	}
+
+	history, err := us.utilization.History(ctx, id, req)
+	if err != nil {
+		us.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, history)
+}
+
+// handleForecast predicts when a pool runs out of addresses from the last
+// days (default 90) of snapshots.
+// GET /api/v1/pools/{id}/utilization/forecast
+func (us *UtilizationServer) handleForecast(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, ok := us.poolID(w, r)
+	if !ok {
+		return
+	}
+	days, ok := us.lookbackDays(w, r)
+	if !ok {
+		return
+	}
+	forecast, err := us.utilization.Forecast(ctx, id, time.Duration(days)*24*time.Hour)
+	if err != nil {
+		us.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, forecast)
+}
+
+// handleForecastAll forecasts every pool, soonest exhaustion first.
+// GET /api/v1/utilization/forecast
+func (us *UtilizationServer) handleForecastAll(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	days, ok := us.lookbackDays(w, r)
+	if !ok {
+		return
+	}
+	report, err := us.utilization.ForecastAll(ctx, time.Duration(days)*24*time.Hour)
+	if err != nil {
+		us.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+func (us *UtilizationServer) poolID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id < 1 {
+		us.srv.writeErr(r.Context(), w, http.StatusBadRequest, "invalid id", "")
+		return 0, false
+	}
+	return id, true
+}
+
+func (us *UtilizationServer) lookbackDays(w http.ResponseWriter, r *http.Request) (int, bool) {
+	v := r.URL.Query().Get("days")
+	if v == "" {
+		return defaultForecastLookback, true
+	}
+	days, err := strconv.Atoi(v)
+	if err != nil || days < 1 || days > maxForecastLookback {
+		us.srv.writeErr(r.Context(), w, http.StatusBadRequest, "invalid days", fmt.Sprintf("use 1-%d", maxForecastLookback))
+		return 0, false
+	}
+	return days, true
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/planning"
+	"cloudpam/internal/storage"
+)
+
+func setupUtilizationTestEnv(t *testing.T) (*http.ServeMux, *storage.MemoryStore, *planning.UtilizationService) {
+	t.Helper()
+	st := storage.NewMemoryStore()
+	mux := http.NewServeMux()
+	srv := NewServer(mux, st, nil, nil, nil)
+	srv.registerUnprotectedTestRoutes()
+	svc := planning.NewUtilizationService(st, storage.NewMemoryUtilizationStore())
+	NewUtilizationServer(srv, svc).RegisterUtilizationRoutesNoAuth()
+	return mux, st, svc
+}
+
+func TestUtilizationHandlers_HistoryAndForecast(t *testing.T) {
+	mux, st, svc := setupUtilizationTestEnv(t)
+	ctx := context.Background()
+	pool, err := st.CreatePool(ctx, domain.CreatePool{Name: "prod", CIDR: "10.0.0.0/24"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A child /26 is carved out after each daily snapshot but the last.
+	start := time.Now().UTC().Add(-3 * 24 * time.Hour).Truncate(time.Hour)
+	for i := 0; i < 3; i++ {
+		if _, err := svc.RecordSnapshots(ctx, start.Add(time.Duration(i)*24*time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+		cidr := fmt.Sprintf("10.0.0.%d/26", i*64)
+		if _, err := st.CreatePool(ctx, domain.CreatePool{Name: cidr, CIDR: cidr, ParentID: &pool.ID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	path := fmt.Sprintf("/api/v1/pools/%d/utilization/history", pool.ID)
+	rr := doJSON(t, mux, http.MethodGet, path, "", http.StatusOK)
+	var history planning.UtilizationHistory
+	if err := json.Unmarshal(rr.Body.Bytes(), &history); err != nil {
+		t.Fatal(err)
+	}
+	if history.PoolID != pool.ID || len(history.Points) != 3 || history.Points[2].UsedIPs != 128 {
+		t.Fatalf("history = %s", rr.Body.String())
+	}
+
+	rr = doJSON(t, mux, http.MethodGet, path+"?bucket=168h&from="+start.Add(-time.Hour).Format(time.RFC3339), "", http.StatusOK)
+	history = planning.UtilizationHistory{}
+	if err := json.Unmarshal(rr.Body.Bytes(), &history); err != nil {
+		t.Fatal(err)
+	}
+	if history.BucketSeconds != 7*24*3600 {
+		t.Errorf("bucket = %d, want one week", history.BucketSeconds)
+	}
+
+	rr = doJSON(t, mux, http.MethodGet, fmt.Sprintf("/api/v1/pools/%d/utilization/forecast?days=30", pool.ID), "", http.StatusOK)
+	var forecast planning.UtilizationForecast
+	if err := json.Unmarshal(rr.Body.Bytes(), &forecast); err != nil {
+		t.Fatal(err)
+	}
+	// 64 addresses a day leaves the 128 free ones gone in about two days.
+	if forecast.Method != planning.ForecastLinear || forecast.GrowthPerDay != 64 || forecast.ExhaustionAt == nil {
+		t.Fatalf("forecast = %s", rr.Body.String())
+	}
+
+	rr = doJSON(t, mux, http.MethodGet, "/api/v1/utilization/forecast", "", http.StatusOK)
+	var report planning.UtilizationForecastReport
+	if err := json.Unmarshal(rr.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.LookbackDays != 90 || len(report.Items) == 0 || report.Items[0].PoolID != pool.ID {
+		t.Fatalf("report = %s", rr.Body.String())
+	}
+}
+
+func TestUtilizationHandlers_Validation(t *testing.T) {
+	mux, st, _ := setupUtilizationTestEnv(t)
+	pool, err := st.CreatePool(context.Background(), domain.CreatePool{Name: "prod", CIDR: "10.0.0.0/24"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	history := fmt.Sprintf("/api/v1/pools/%d/utilization/history", pool.ID)
+	forecast := fmt.Sprintf("/api/v1/pools/%d/utilization/forecast", pool.ID)
+
+	doJSON(t, mux, http.MethodGet, history+"?from=yesterday", "", http.StatusBadRequest)
+	doJSON(t, mux, http.MethodGet, history+"?to=2026-01-01", "", http.StatusBadRequest)
+	doJSON(t, mux, http.MethodGet, history+"?from=2026-02-01T00:00:00Z&to=2026-01-01T00:00:00Z", "", http.StatusBadRequest)
+	doJSON(t, mux, http.MethodGet, history+"?bucket=10s", "", http.StatusBadRequest)
+	doJSON(t, mux, http.MethodGet, history+"?bucket=auto", "", http.StatusOK)
+	doJSON(t, mux, http.MethodGet, forecast+"?days=0", "", http.StatusBadRequest)
+	doJSON(t, mux, http.MethodGet, forecast+"?days=1000", "", http.StatusBadRequest)
+	doJSON(t, mux, http.MethodGet, "/api/v1/utilization/forecast?days=x", "", http.StatusBadRequest)
+	doJSON(t, mux, http.MethodGet, "/api/v1/pools/999/utilization/history", "", http.StatusNotFound)
+	doJSON(t, mux, http.MethodGet, "/api/v1/pools/999/utilization/forecast", "", http.StatusNotFound)
+	doJSON(t, mux, http.MethodGet, "/api/v1/pools/abc/utilization/forecast", "", http.StatusBadRequest)
+
+	// No snapshots yet: the forecast explains itself instead of failing.
+	rr := doJSON(t, mux, http.MethodGet, forecast, "", http.StatusOK)
+	var f planning.UtilizationForecast
+	if err := json.Unmarshal(rr.Body.Bytes(), &f); err != nil || f.Reason == "" || f.ExhaustionAt != nil {
+		t.Fatalf("forecast = %s", rr.Body.String())
+	}
+}
@@ -0,0 +1,394 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"cloudpam/internal/audit"
+	"cloudpam/internal/auth"
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+	"cloudpam/internal/webhook"
+)
+
+// WebhookServer handles webhook subscription and delivery log endpoints.
+type WebhookServer struct {
+	srv        *Server
+	store      storage.WebhookStore
+	dispatcher *webhook.Dispatcher
+}
+
+// NewWebhookServer creates a new WebhookServer. dispatcher may be nil, in
+// which case the test endpoint reports that delivery is not configured.
+func NewWebhookServer(srv *Server, store storage.WebhookStore, dispatcher *webhook.Dispatcher) *WebhookServer {
+	return &WebhookServer{srv: srv, store: store, dispatcher: dispatcher}
+}
+
+// RegisterProtectedWebhookRoutes registers webhook routes with RBAC.
+func (ws *WebhookServer) RegisterProtectedWebhookRoutes(dualMW Middleware, logger *slog.Logger) {
+	listMW := RequirePermissionMiddleware(auth.ResourceWebhooks, auth.ActionList, logger)
+	readMW := RequirePermissionMiddleware(auth.ResourceWebhooks, auth.ActionRead, logger)
+	createMW := RequirePermissionMiddleware(auth.ResourceWebhooks, auth.ActionCreate, logger)
+	updateMW := RequirePermissionMiddleware(auth.ResourceWebhooks, auth.ActionUpdate, logger)
+	deleteMW := RequirePermissionMiddleware(auth.ResourceWebhooks, auth.ActionDelete, logger)
+
+	ws.srv.handleOpenAPIRoute("GET /api/v1/webhooks", dualMW(listMW(http.HandlerFunc(ws.handleList))))
+	ws.srv.handleOpenAPIRoute("POST /api/v1/webhooks", dualMW(createMW(http.HandlerFunc(ws.handleCreate))))
+	ws.srv.handleOpenAPIRoute("GET /api/v1/webhooks/{id}", dualMW(readMW(http.HandlerFunc(ws.handleGet))))
+	ws.srv.handleOpenAPIRoute("PATCH /api/v1/webhooks/{id}", dualMW(updateMW(http.HandlerFunc(ws.handleUpdate))))
+	ws.srv.handleOpenAPIRoute("DELETE /api/v1/webhooks/{id}", dualMW(deleteMW(http.HandlerFunc(ws.handleDelete))))
+	ws.srv.handleOpenAPIRoute("GET /api/v1/webhooks/{id}/deliveries", dualMW(readMW(http.HandlerFunc(ws.handleListDeliveries))))
+	ws.srv.handleOpenAPIRoute("POST /api/v1/webhooks/{id}/test", dualMW(updateMW(http.HandlerFunc(ws.handleTest))))
+}
+
+// RegisterWebhookRoutesNoAuth registers webhook routes without auth middleware (for tests).
+func (ws *WebhookServer) RegisterWebhookRoutesNoAuth() {
+	ws.srv.handleOpenAPIRouteFunc("GET /api/v1/webhooks", ws.handleList)
+	ws.srv.handleOpenAPIRouteFunc("POST /api/v1/webhooks", ws.handleCreate)
+	ws.srv.handleOpenAPIRouteFunc("GET /api/v1/webhooks/{id}", ws.handleGet)
+	ws.srv.handleOpenAPIRouteFunc("PATCH /api/v1/webhooks/{id}", ws.handleUpdate)
+	ws.srv.handleOpenAPIRouteFunc("DELETE /api/v1/webhooks/{id}", ws.handleDelete)
+	ws.srv.handleOpenAPIRouteFunc("GET /api/v1/webhooks/{id}/deliveries", ws.handleListDeliveries)
+	ws.srv.handleOpenAPIRouteFunc("POST /api/v1/webhooks/{id}/test", ws.handleTest)
+}
+
+// webhookListResponse is the body of GET /api/v1/webhooks.
+type webhookListResponse struct {
+	Items []domain.Webhook `json:"items"`
+}
+
+// webhookSecretResponse is a webhook plus its signing secret. The secret is
+// only returned when it is first set: on create and on rotation.
+type webhookSecretResponse struct {
+	domain.Webhook
+	Secret string `json:"secret,omitempty"`
+}
+
+// handleList returns all webhooks. Secrets are never included.
+// GET /api/v1/webhooks
+func (ws *WebhookServer) handleList(w http.ResponseWriter, r *http.Request) {
+	hooks, err := ws.store.ListWebhooks(r.Context())
+	if err != nil {
+		ws.srv.writeStoreErr(r.Context(), w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, webhookListResponse{Items: hooks})
+}
+
+// handleCreate registers a webhook. A signing secret is generated unless one
+// is supplied, and is returned once in the response.
+// POST /api/v1/webhooks
+func (ws *WebhookServer) handleCreate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var input struct {
+		Name    string                    `json:"name"`
+		URL     string                    `json:"url"`
+		Secret  string                    `json:"secret"`
+		Events  []domain.WebhookEventType `json:"events"`
+		Enabled *bool                     `json:"enabled"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		ws.srv.writeErr(ctx, w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		ws.srv.writeErr(ctx, w, http.StatusBadRequest, "name is required", "")
+		return
+	}
+	if err := validateWebhookURL(input.URL); err != nil {
+		ws.srv.writeErr(ctx, w, http.StatusBadRequest, "invalid url", err.Error())
+		return
+	}
+	if err := validateWebhookEvents(input.Events); err != nil {
+		ws.srv.writeErr(ctx, w, http.StatusBadRequest, "invalid events", err.Error())
+		return
+	}
+
+	secret := input.Secret
+	if secret == "" {
+		var err error
+		if secret, err = webhook.GenerateSecret(); err != nil {
+			ws.srv.writeErr(ctx, w, http.StatusInternalServerError, "failed to generate secret", err.Error())
+			return
+		}
+	}
+	events := input.Events
+	if events == nil {
+		events = []domain.WebhookEventType{}
+	}
+
+	now := time.Now().UTC()
+	hook := domain.Webhook{
+		ID:        uuid.New().String(),
+		Name:      input.Name,
+		URL:       input.URL,
+		Secret:    secret,
+		Events:    events,
+		Enabled:   input.Enabled == nil || *input.Enabled,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := ws.store.CreateWebhook(ctx, hook); err != nil {
+		ws.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+
+	ws.srv.logAudit(ctx, audit.ActionCreate, audit.ResourceWebhook, hook.ID, hook.Name, http.StatusCreated)
+	writeJSON(w, http.StatusCreated, webhookSecretResponse{Webhook: hook, Secret: secret})
+}
+
+// handleGet returns a single webhook.
+// GET /api/v1/webhooks/{id}
+func (ws *WebhookServer) handleGet(w http.ResponseWriter, r *http.Request) {
+	hook, err := ws.store.GetWebhook(r.Context(), r.PathValue("id"))
+	if err != nil {
+		ws.srv.writeStoreErr(r.Context(), w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, hook)
+}
+
+// handleUpdate partially updates a webhook. Setting "rotate_secret" to true
+// generates a new secret; "secret" sets one explicitly. Either way the new
+// secret is returned once in the response.
+// PATCH /api/v1/webhooks/{id}
+func (ws *WebhookServer) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	hook, err := ws.store.GetWebhook(ctx, r.PathValue("id"))
+	if err != nil {
+		ws.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+
+	var input map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		ws.srv.writeErr(ctx, w, http.StatusBadRequest, "invalid request body", err.Error())
+		return
+	}
+
+	if v, ok := input["name"]; ok {
+		var name string
+		if err := json.Unmarshal(v, &name); err != nil || strings.TrimSpace(name) == "" {
+			ws.srv.writeErr(ctx, w, http.StatusBadRequest, "name must be a non-empty string", "")
+			return
+		}
+		hook.Name = strings.TrimSpace(name)
+	}
+	if v, ok := input["url"]; ok {
+		var u string
+		if err := json.Unmarshal(v, &u); err != nil {
+			ws.srv.writeErr(ctx, w, http.StatusBadRequest, "url must be a string", "")
+			return
+		}
+		if err := validateWebhookURL(u); err != nil {
+			ws.srv.writeErr(ctx, w, http.StatusBadRequest, "invalid url", err.Error())
+			return
+		}
+		hook.URL = u
+	}
+	if v, ok := input["events"]; ok {
+		var events []domain.WebhookEventType
+		if err := json.Unmarshal(v, &events); err != nil {
+			ws.srv.writeErr(ctx, w, http.StatusBadRequest, "events must be an array of event types", "")
+			return
+		}
+		if err := validateWebhookEvents(events); err != nil {
+			ws.srv.writeErr(ctx, w, http.StatusBadRequest, "invalid events", err.Error())
+			return
+		}
+		if events == nil {
+			events = []domain.WebhookEventType{}
+		}
+		hook.Events = events
+	}
+	if v, ok := input["enabled"]; ok {
+		var enabled bool
+		if err := json.Unmarshal(v, &enabled); err != nil {
+			ws.srv.writeErr(ctx, w, http.StatusBadRequest, "enabled must be a boolean", "")
+			return
+		}
+		hook.Enabled = enabled
+	}
+
+	var newSecret string
+	if v, ok := input["secret"]; ok {
+		if err := json.Unmarshal(v, &newSecret); err != nil || newSecret == "" {
+			ws.srv.writeErr(ctx, w, http.StatusBadRequest, "secret must be a non-empty string", "")
+			return
+		}
+	}
+	if v, ok := input["rotate_secret"]; ok && newSecret == "" {
+		var rotate bool
+		if err := json.Unmarshal(v, &rotate); err != nil {
+			ws.srv.writeErr(ctx, w, http.StatusBadRequest, "rotate_secret must be a boolean", "")
+			return
+		}
+		if rotate {
+			if newSecret, err = webhook.GenerateSecret(); err != nil {
+				ws.srv.writeErr(ctx, w, http.StatusInternalServerError, "failed to generate secret", err.Error())
+				return
+			}
+		}
+	}
+	if newSecret != "" {
+		hook.Secret = newSecret
+	}
+
+	hook.UpdatedAt = time.Now().UTC()
+	if err := ws.store.UpdateWebhook(ctx, *hook); err != nil {
+		ws.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+
+	ws.srv.logAudit(ctx, audit.ActionUpdate, audit.ResourceWebhook, hook.ID, hook.Name, http.StatusOK)
+	writeJSON(w, http.StatusOK, webhookSecretResponse{Webhook: *hook, Secret: newSecret})
+}
+
+// handleDelete removes a webhook and its delivery log.
+// DELETE /api/v1/webhooks/{id}
+func (ws *WebhookServer) handleDelete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := r.PathValue("id")
+
+	hook, err := ws.store.GetWebhook(ctx, id)
+	if err != nil {
+		ws.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+	if err := ws.store.DeleteWebhook(ctx, id); err != nil {
+		ws.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+
+	ws.srv.logAudit(ctx, audit.ActionDelete, audit.ResourceWebhook, id, hook.Name, http.StatusNoContent)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleListDeliveries returns a webhook's delivery log, newest first.
+// GET /api/v1/webhooks/{id}/deliveries
+func (ws *WebhookServer) handleListDeliveries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := r.PathValue("id")
+
+	if _, err := ws.store.GetWebhook(ctx, id); err != nil {
+		ws.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+
+	q := r.URL.Query()
+	filters := domain.WebhookDeliveryFilters{
+		WebhookID: id,
+		Status:    q.Get("status"),
+		EventType: q.Get("event_type"),
+	}
+	switch domain.WebhookDeliveryStatus(filters.Status) {
+	case "", domain.WebhookDeliveryPending, domain.WebhookDeliverySucceeded, domain.WebhookDeliveryFailed:
+	default:
+		ws.srv.writeErr(ctx, w, http.StatusBadRequest, "invalid status", "must be pending, succeeded or failed")
+		return
+	}
+	if pageStr := q.Get("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil {
+			filters.Page = p
+		}
+	}
+	if psStr := q.Get("page_size"); psStr != "" {
+		if ps, err := strconv.Atoi(psStr); err == nil {
+			filters.PageSize = ps
+		}
+	}
+
+	items, total, err := ws.store.ListWebhookDeliveries(ctx, filters)
+	if err != nil {
+		ws.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+
+	page := filters.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := filters.PageSize
+	if pageSize < 1 {
+		pageSize = 50
+	}
+
+	writeJSON(w, http.StatusOK, domain.WebhookDeliveryListResponse{
+		Items:    items,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	})
+}
+
+// handleTest queues a ping event for the webhook and returns the pending
+// delivery. Poll the delivery log for the outcome.
+// POST /api/v1/webhooks/{id}/test
+func (ws *WebhookServer) handleTest(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if ws.dispatcher == nil {
+		ws.srv.writeErr(ctx, w, http.StatusServiceUnavailable, "webhook delivery is not configured", "")
+		return
+	}
+	hook, err := ws.store.GetWebhook(ctx, r.PathValue("id"))
+	if err != nil {
+		ws.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+	if !hook.Enabled {
+		ws.srv.writeErr(ctx, w, http.StatusConflict, "webhook is disabled", "enable it before sending a test event")
+		return
+	}
+
+	delivery, err := ws.dispatcher.Send(ctx, *hook, domain.WebhookEventPing, webhook.PingData{
+		WebhookID: hook.ID,
+		Message:   "Test event from CloudPAM",
+	})
+	if err != nil {
+		ws.srv.writeStoreErr(ctx, w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, delivery)
+}
+
+// validateWebhookURL requires an absolute http or https URL.
+func validateWebhookURL(raw string) error {
+	if raw == "" {
+		return fmt.Errorf("url is required")
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("scheme must be http or https")
+	}
+	if u.Host == "" {
+		return fmt.Errorf("host is required")
+	}
+	return nil
+}
+
+// validateWebhookEvents rejects unknown event types. An empty list
+// subscribes to every event.
+func validateWebhookEvents(events []domain.WebhookEventType) error {
+	for _, e := range events {
+		if !domain.IsValidWebhookEventType(e) {
+			return fmt.Errorf("unknown event type %q", e)
+		}
+	}
+	return nil
+}
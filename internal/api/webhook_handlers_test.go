@@ -0,0 +1,133 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"cloudpam/internal/audit"
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+	"cloudpam/internal/webhook"
+)
+
+func setupWebhookTestEnv(t *testing.T) (*http.ServeMux, *storage.MemoryWebhookStore, *audit.MemoryAuditLogger) {
+	t.Helper()
+	store := storage.NewMemoryWebhookStore()
+	auditLogger := audit.NewMemoryAuditLogger()
+	mux := http.NewServeMux()
+	srv := NewServer(mux, storage.NewMemoryStore(), nil, nil, auditLogger)
+	ws := NewWebhookServer(srv, store, webhook.NewDispatcher(store, webhook.Config{}))
+	ws.RegisterWebhookRoutesNoAuth()
+	return mux, store, auditLogger
+}
+
+func TestWebhookHandlers_CRUD(t *testing.T) {
+	mux, store, auditLogger := setupWebhookTestEnv(t)
+
+	rr := doJSON(t, mux, http.MethodPost, "/api/v1/webhooks",
+		`{"name":"ops","url":"https://hooks.example.com/ipam","events":["pool.created","drift.detected"]}`, http.StatusCreated)
+	var created struct {
+		domain.Webhook
+		Secret string `json:"secret"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &created); err != nil {
+		t.Fatalf("decode create: %v", err)
+	}
+	if created.ID == "" || !created.Enabled || len(created.Events) != 2 || created.Secret == "" {
+		t.Fatalf("create response = %s", rr.Body.String())
+	}
+	stored, _ := store.GetWebhook(context.Background(), created.ID)
+	if stored.Secret != created.Secret {
+		t.Fatal("returned secret does not match the stored one")
+	}
+
+	// The secret is write-only after creation.
+	path := "/api/v1/webhooks/" + created.ID
+	for _, body := range []string{
+		doJSON(t, mux, http.MethodGet, path, "", http.StatusOK).Body.String(),
+		doJSON(t, mux, http.MethodGet, "/api/v1/webhooks", "", http.StatusOK).Body.String(),
+		doJSON(t, mux, http.MethodPatch, path, `{"enabled":false}`, http.StatusOK).Body.String(),
+	} {
+		var m map[string]any
+		_ = json.Unmarshal([]byte(body), &m)
+		if _, ok := m["secret"]; ok {
+			t.Fatalf("secret leaked in %s", body)
+		}
+	}
+	if h, _ := store.GetWebhook(context.Background(), created.ID); h.Enabled {
+		t.Fatal("PATCH enabled=false not applied")
+	}
+
+	rr = doJSON(t, mux, http.MethodPatch, path, `{"rotate_secret":true,"events":[]}`, http.StatusOK)
+	var rotated struct {
+		Secret string                    `json:"secret"`
+		Events []domain.WebhookEventType `json:"events"`
+	}
+	_ = json.Unmarshal(rr.Body.Bytes(), &rotated)
+	if rotated.Secret == "" || rotated.Secret == created.Secret || len(rotated.Events) != 0 {
+		t.Fatalf("rotate response = %s", rr.Body.String())
+	}
+
+	doJSON(t, mux, http.MethodDelete, path, "", http.StatusNoContent)
+	doJSON(t, mux, http.MethodGet, path, "", http.StatusNotFound)
+	doJSON(t, mux, http.MethodDelete, path, "", http.StatusNotFound)
+
+	events, _, _ := auditLogger.List(context.Background(), audit.ListOptions{ResourceType: audit.ResourceWebhook})
+	if len(events) != 4 {
+		t.Fatalf("expected 4 webhook audit events (create, 2x update, delete), got %d", len(events))
+	}
+}
+
+func TestWebhookHandlers_Validation(t *testing.T) {
+	mux, _, _ := setupWebhookTestEnv(t)
+
+	for _, body := range []string{
+		`{"url":"https://example.com"}`,
+		`{"name":"x","url":"ftp://example.com"}`,
+		`{"name":"x","url":"/relative"}`,
+		`{"name":"x","url":"https://example.com","events":["pool.exploded"]}`,
+		`{"name":"x","url":"https://example.com","events":["ping"]}`,
+		`not json`,
+	} {
+		doJSON(t, mux, http.MethodPost, "/api/v1/webhooks", body, http.StatusBadRequest)
+	}
+
+	rr := doJSON(t, mux, http.MethodPost, "/api/v1/webhooks", `{"name":"x","url":"https://example.com"}`, http.StatusCreated)
+	var hook domain.Webhook
+	_ = json.Unmarshal(rr.Body.Bytes(), &hook)
+	path := "/api/v1/webhooks/" + hook.ID
+	doJSON(t, mux, http.MethodPatch, path, `{"url":"gopher://example.com"}`, http.StatusBadRequest)
+	doJSON(t, mux, http.MethodPatch, path, `{"name":""}`, http.StatusBadRequest)
+	doJSON(t, mux, http.MethodPatch, path, `{"enabled":"yes"}`, http.StatusBadRequest)
+	doJSON(t, mux, http.MethodGet, path+"/deliveries?status=bogus", "", http.StatusBadRequest)
+	doJSON(t, mux, http.MethodPatch, "/api/v1/webhooks/missing", `{"enabled":true}`, http.StatusNotFound)
+}
+
+func TestWebhookHandlers_TestAndDeliveries(t *testing.T) {
+	mux, _, _ := setupWebhookTestEnv(t)
+
+	rr := doJSON(t, mux, http.MethodPost, "/api/v1/webhooks", `{"name":"x","url":"https://example.com/hook"}`, http.StatusCreated)
+	var hook domain.Webhook
+	_ = json.Unmarshal(rr.Body.Bytes(), &hook)
+	path := "/api/v1/webhooks/" + hook.ID
+
+	rr = doJSON(t, mux, http.MethodPost, path+"/test", "", http.StatusAccepted)
+	var delivery domain.WebhookDelivery
+	_ = json.Unmarshal(rr.Body.Bytes(), &delivery)
+	if delivery.EventType != domain.WebhookEventPing || delivery.Status != domain.WebhookDeliveryPending || delivery.WebhookID != hook.ID {
+		t.Fatalf("test delivery = %s", rr.Body.String())
+	}
+
+	rr = doJSON(t, mux, http.MethodGet, path+"/deliveries?status=pending", "", http.StatusOK)
+	var list domain.WebhookDeliveryListResponse
+	_ = json.Unmarshal(rr.Body.Bytes(), &list)
+	if list.Total != 1 || len(list.Items) != 1 || list.Items[0].ID != delivery.ID || list.PageSize != 50 {
+		t.Fatalf("deliveries = %s", rr.Body.String())
+	}
+	doJSON(t, mux, http.MethodGet, "/api/v1/webhooks/missing/deliveries", "", http.StatusNotFound)
+
+	doJSON(t, mux, http.MethodPatch, path, `{"enabled":false}`, http.StatusOK)
+	doJSON(t, mux, http.MethodPost, path+"/test", "", http.StatusConflict)
+}
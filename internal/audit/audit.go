@@ -21,6 +21,12 @@ type AuditEvent struct {
 	RequestID    string    `json:"request_id,omitempty"`
 	IPAddress    string    `json:"ip_address,omitempty"`
 	StatusCode   int       `json:"status_code"`
+
+	// Seq, PrevHash and Hash place the event in the tamper-evident hash
+	// chain; loggers that chain events assign them in Log.
+	Seq      int64  `json:"seq,omitempty"`
+	PrevHash string `json:"prev_hash,omitempty"`
+	Hash     string `json:"hash,omitempty"`
 }
 
 // Changes captures the before and after state for update operations.
@@ -58,20 +64,36 @@ type AuditLogger interface {
 
 // Valid actions for audit events.
 const (
-	ActionCreate = "create"
-	ActionUpdate = "update"
-	ActionDelete = "delete"
-	ActionRead   = "read" // Used only for sensitive operations like key listing
+	ActionCreate   = "create"
+	ActionUpdate   = "update"
+	ActionDelete   = "delete"
+	ActionRead     = "read"     // Used only for sensitive operations like key listing
+	ActionAllocate = "allocate" // A child pool carved from a parent by the allocator
+	ActionApply    = "apply"    // A recommendation applied to the pools it concerns
+	ActionApprove  = "approve"  // A change proposal or pool change request approved and applied
+	ActionReject   = "reject"   // A change proposal or pool change request rejected
+	ActionRelease  = "release"  // A reservation released before its expiry
+	ActionExpire   = "expire"   // A reservation released by the expiry job
 )
 
 // Valid resource types for audit events.
 const (
-	ResourcePool            = "pool"
-	ResourceAccount         = "account"
-	ResourceAPIKey          = "api_key"
-	ResourceUser            = "user"
-	ResourceSession         = "session"
-	ResourceNetworkConflict = "network_conflict"
+	ResourcePool              = "pool"
+	ResourceAccount           = "account"
+	ResourceAPIKey            = "api_key"
+	ResourceUser              = "user"
+	ResourceSession           = "session"
+	ResourceNetworkConflict   = "network_conflict"
+	ResourceWebhook           = "webhook"
+	ResourceIPAddress         = "ip_address"
+	ResourceDiscoverySchedule = "discovery_schedule"
+	ResourceAlert             = "alert"
+	ResourceComplianceRule    = "compliance_rule"
+	ResourceRecommendation    = "recommendation"
+	ResourceAuditLog          = "audit_log"
+	ResourceChangeProposal    = "change_proposal"
+	ResourcePoolChangeRequest = "pool_change_request"
+	ResourceReservation       = "reservation"
 )
 
 // Valid actor types.
@@ -79,6 +101,7 @@ const (
 	ActorTypeAPIKey    = "api_key"
 	ActorTypeAnonymous = "anonymous"
 	ActorTypeUser      = "user"
+	ActorTypeSystem    = "system" // Background jobs such as reservation expiry
 )
 
 // Additional action constants for auth events.
@@ -89,4 +112,9 @@ const (
 	ActionAccountLocked     = "account_locked"
 	ActionAccountUnlocked   = "account_unlocked"
 	ActionAPIKeyRotationDue = "api_key_rotation_due"
+
+	ActionMFAEnrollmentStarted = "mfa_enrollment_started"
+	ActionMFAEnrolled          = "mfa_enrolled"
+	ActionMFAReset             = "mfa_reset"
+	ActionMFARecoveryCodeUsed  = "mfa_recovery_code_used"
 )
@@ -74,6 +74,17 @@ func (f CEFFormatter) Format(event *AuditEvent) string {
 	if event.StatusCode != 0 {
 		extensions = append(extensions, "cn1Label=http_status", "cn1="+strconv.Itoa(event.StatusCode))
 	}
+	// The chain position and hash let a SIEM copy be cross-checked against
+	// the hash chain in the audit store.
+	if event.Seq != 0 {
+		extensions = append(extensions, "cn2Label=chain_seq", "cn2="+strconv.FormatInt(event.Seq, 10))
+	}
+	if event.Hash != "" {
+		extensions = append(extensions, "cs5Label=chain_hash", "cs5="+cefEscapeExtension(event.Hash))
+	}
+	if event.PrevHash != "" {
+		extensions = append(extensions, "cs6Label=chain_prev_hash", "cs6="+cefEscapeExtension(event.PrevHash))
+	}
 
 	return fmt.Sprintf("CEF:0|%s|%s|%s|%s|%s|%d|%s",
 		cefEscapeHeader(cefVendor),
@@ -75,6 +75,18 @@ func TestCovCEFFormatterMarksFailureOutcome(t *testing.T) {
 	}
 }
 
+func TestCovCEFFormatterIncludesChainHash(t *testing.T) {
+	got := (CEFFormatter{}).Format(&AuditEvent{Action: ActionCreate, Seq: 42, PrevHash: "aa11", Hash: "bb22"})
+	for _, want := range []string{"cn2Label=chain_seq cn2=42", "cs5Label=chain_hash cs5=bb22", "cs6Label=chain_prev_hash cs6=aa11"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("Format() = %q, want %q", got, want)
+		}
+	}
+	if got := (CEFFormatter{}).Format(&AuditEvent{Action: ActionCreate}); strings.Contains(got, "chain_") {
+		t.Fatalf("Format() = %q, want no chain extensions for an unchained event", got)
+	}
+}
+
 func TestCovOutcome(t *testing.T) {
 	tests := []struct {
 		status int
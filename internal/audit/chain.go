@@ -0,0 +1,375 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Hash chaining makes the persisted audit trail tamper-evident. Every event
+// carries Seq, its position in the trail, and Hash, the SHA-256 of its
+// canonical serialization, which includes PrevHash, the hash of the event
+// before it. Editing or deleting an event breaks the link to its successor.
+// Signed checkpoints pin the chain head, so rewriting every later hash or
+// truncating the tail is caught as well.
+//
+// Retention deletes events but keeps their links (seq, prev_hash, hash) for
+// as long as a retained event follows them, so a pruned event never reads
+// as a gap.
+
+// Reasons reported in BrokenLink.
+const (
+	BrokenHashMismatch       = "hash_mismatch"                // the event no longer matches its hash
+	BrokenPrevHashMismatch   = "prev_hash_mismatch"           // the event does not chain to its predecessor
+	BrokenMissingEvent       = "missing_event"                // an event was removed without leaving a link
+	BrokenCheckpointMismatch = "checkpoint_mismatch"          // a checkpoint signed a different hash
+	BrokenCheckpointSig      = "checkpoint_signature_invalid" // a checkpoint signature does not verify
+)
+
+// ChainVerifier is implemented by audit loggers that hash-chain the events
+// they persist.
+type ChainVerifier interface {
+	// VerifyChain walks the chain across the range in opts and reports the
+	// first broken link.
+	VerifyChain(ctx context.Context, opts VerifyOptions) (*VerifyResult, error)
+
+	// Checkpoint signs the current chain head with key and stores the
+	// checkpoint. It returns nil when the chain is empty or its head is
+	// already checkpointed.
+	Checkpoint(ctx context.Context, key ed25519.PrivateKey) (*Checkpoint, error)
+}
+
+// AsChainVerifier returns the ChainVerifier behind l, looking through any
+// ForwardingAuditLogger wrappers to the logger that persists events.
+func AsChainVerifier(l AuditLogger) (ChainVerifier, bool) {
+	return unwrapLogger[ChainVerifier](l)
+}
+
+// VerifyOptions selects the part of the chain VerifyChain walks. The walk
+// runs from the first event at or after Since to the last event at or
+// before Until; nil bounds extend to the ends of the chain.
+type VerifyOptions struct {
+	Since *time.Time
+	Until *time.Time
+	// PublicKey checks checkpoint signatures. Checkpoints signed by another
+	// key, or all of them when PublicKey is nil, are compared by hash only.
+	PublicKey ed25519.PublicKey
+}
+
+// VerifyResult reports the outcome of a chain walk.
+type VerifyResult struct {
+	Valid                 bool        `json:"valid"`
+	FirstSeq              int64       `json:"first_seq,omitempty"`
+	LastSeq               int64       `json:"last_seq,omitempty"`
+	EventsChecked         int64       `json:"events_checked"`
+	PrunedLinks           int64       `json:"pruned_links"`
+	CheckpointsChecked    int64       `json:"checkpoints_checked"`
+	CheckpointsUnverified int64       `json:"checkpoints_unverified"`
+	Broken                *BrokenLink `json:"broken,omitempty"`
+}
+
+// BrokenLink describes the first point at which the chain fails to verify.
+type BrokenLink struct {
+	Seq       int64      `json:"seq"`
+	EventID   string     `json:"event_id,omitempty"`
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+	Reason    string     `json:"reason"`
+	Detail    string     `json:"detail"`
+}
+
+// Checkpoint is a signed statement of the chain head at a point in time.
+type Checkpoint struct {
+	Seq       int64     `json:"seq"`
+	Hash      string    `json:"hash"`
+	CreatedAt time.Time `json:"created_at"`
+	KeyID     string    `json:"key_id"`
+	Signature string    `json:"signature"` // base64 Ed25519 signature
+}
+
+// ParseSigningKey decodes a checkpoint signing key: a 32-byte Ed25519 seed
+// in hex or standard base64.
+func ParseSigningKey(s string) (ed25519.PrivateKey, error) {
+	s = strings.TrimSpace(s)
+	seed, err := hex.DecodeString(s)
+	if err != nil {
+		seed, err = base64.StdEncoding.DecodeString(s)
+	}
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("signing key must be a %d-byte Ed25519 seed in hex or base64", ed25519.SeedSize)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// KeyID returns a short fingerprint of a checkpoint public key.
+func KeyID(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:8])
+}
+
+// signCheckpoint signs the chain head seq, hash with key.
+func signCheckpoint(seq int64, hash string, key ed25519.PrivateKey) *Checkpoint {
+	// Stored timestamps keep microseconds, so sign what will be read back.
+	at := time.Now().UTC().Truncate(time.Microsecond)
+	sig := ed25519.Sign(key, checkpointMessage(seq, hash, at))
+	return &Checkpoint{
+		Seq:       seq,
+		Hash:      hash,
+		CreatedAt: at,
+		KeyID:     KeyID(key.Public().(ed25519.PublicKey)),
+		Signature: base64.StdEncoding.EncodeToString(sig),
+	}
+}
+
+func checkpointMessage(seq int64, hash string, at time.Time) []byte {
+	return []byte(fmt.Sprintf("cloudpam-audit-checkpoint\n%d\n%s\n%s", seq, hash, at.UTC().Format(time.RFC3339Nano)))
+}
+
+// canonicalEvent fixes the field order and encoding that chainHash hashes.
+type canonicalEvent struct {
+	Seq          int64           `json:"seq"`
+	PrevHash     string          `json:"prev_hash"`
+	ID           string          `json:"id"`
+	Timestamp    string          `json:"timestamp"`
+	Actor        string          `json:"actor"`
+	ActorType    string          `json:"actor_type"`
+	Action       string          `json:"action"`
+	ResourceType string          `json:"resource_type"`
+	ResourceID   string          `json:"resource_id"`
+	ResourceName string          `json:"resource_name"`
+	Changes      json.RawMessage `json:"changes"`
+	RequestID    string          `json:"request_id"`
+	IPAddress    string          `json:"ip_address"`
+	StatusCode   int             `json:"status_code"`
+}
+
+// chainHash returns the hex SHA-256 of e's canonical serialization at seq,
+// chained to prevHash. changes is the JSON stored for e.Changes, or nil.
+func chainHash(seq int64, prevHash string, e *AuditEvent, changes []byte) (string, error) {
+	canonical, err := canonicalJSON(changes)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(canonicalEvent{
+		Seq:          seq,
+		PrevHash:     prevHash,
+		ID:           e.ID,
+		Timestamp:    e.Timestamp.UTC().Format(time.RFC3339Nano),
+		Actor:        e.Actor,
+		ActorType:    e.ActorType,
+		Action:       e.Action,
+		ResourceType: e.ResourceType,
+		ResourceID:   e.ResourceID,
+		ResourceName: e.ResourceName,
+		Changes:      canonical,
+		RequestID:    e.RequestID,
+		IPAddress:    e.IPAddress,
+		StatusCode:   e.StatusCode,
+	})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// changesJSON returns the JSON stored for c, or nil when there is none.
+// Changes that cannot be encoded are dropped, as the loggers always have.
+func changesJSON(c *Changes) []byte {
+	if c == nil {
+		return nil
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// canonicalJSON re-encodes a JSON document with sorted keys and numbers as
+// written, so that a document reformatted by the database (JSONB reorders
+// keys and adds whitespace) hashes the same as the one that was stored.
+func canonicalJSON(doc []byte) (json.RawMessage, error) {
+	if len(bytes.TrimSpace(doc)) == 0 {
+		return json.RawMessage("null"), nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("canonicalize changes: %w", err)
+	}
+	return json.Marshal(v)
+}
+
+// chainBatchSize bounds the chain positions read at once while verifying or
+// backfilling the chain.
+const chainBatchSize = 1000
+
+// chainRecord is one position in the chain as read back for verification.
+type chainRecord struct {
+	Seq       int64
+	PrevHash  string
+	Hash      string
+	Timestamp time.Time
+	// Event is nil for the link left behind by a pruned event.
+	Event *AuditEvent
+	// Changes is the JSON stored for Event.Changes.
+	Changes []byte
+}
+
+// chainWalker checks records fed to it in ascending seq order.
+type chainWalker struct {
+	from        int64 // records before from only anchor the walk
+	key         ed25519.PublicKey
+	keyID       string
+	checkpoints map[int64]Checkpoint
+	havePrev    bool
+	prevSeq     int64
+	prevHash    string
+	res         VerifyResult
+}
+
+// newChainWalker starts a walk at seq from. checkpoints are those at or
+// after from that the walk is expected to reach.
+func newChainWalker(from int64, key ed25519.PublicKey, checkpoints []Checkpoint) *chainWalker {
+	w := &chainWalker{from: from, key: key, checkpoints: make(map[int64]Checkpoint, len(checkpoints))}
+	if key != nil {
+		w.keyID = KeyID(key)
+	}
+	for _, cp := range checkpoints {
+		w.checkpoints[cp.Seq] = cp
+	}
+	return w
+}
+
+// step checks r against its predecessor and its own hash. It returns false
+// once the chain is broken.
+func (w *chainWalker) step(r chainRecord) bool {
+	if r.Seq < w.from {
+		w.havePrev, w.prevSeq, w.prevHash = true, r.Seq, r.Hash
+		return true
+	}
+	switch {
+	case w.havePrev && r.Seq != w.prevSeq+1:
+		return w.fail(chainRecord{Seq: w.prevSeq + 1}, BrokenMissingEvent,
+			fmt.Sprintf("events %d to %d are missing", w.prevSeq+1, r.Seq-1))
+	case w.havePrev && r.PrevHash != w.prevHash:
+		return w.fail(r, BrokenPrevHashMismatch, fmt.Sprintf("prev_hash does not match the hash of event %d", w.prevSeq))
+	case !w.havePrev && r.Seq == 1 && r.PrevHash != "":
+		return w.fail(r, BrokenPrevHashMismatch, "the first event does not start the chain")
+	}
+	if r.Event != nil {
+		h, err := chainHash(r.Seq, r.PrevHash, r.Event, r.Changes)
+		if err != nil || h != r.Hash {
+			return w.fail(r, BrokenHashMismatch, "the event does not match its stored hash")
+		}
+		w.res.EventsChecked++
+	} else {
+		w.res.PrunedLinks++
+	}
+	if cp, ok := w.checkpoints[r.Seq]; ok {
+		delete(w.checkpoints, r.Seq)
+		if !w.checkCheckpoint(r, cp) {
+			return false
+		}
+	}
+	if w.res.FirstSeq == 0 {
+		w.res.FirstSeq = r.Seq
+	}
+	w.res.LastSeq = r.Seq
+	w.havePrev, w.prevSeq, w.prevHash = true, r.Seq, r.Hash
+	return true
+}
+
+func (w *chainWalker) checkCheckpoint(r chainRecord, cp Checkpoint) bool {
+	w.res.CheckpointsChecked++
+	if cp.Hash != r.Hash {
+		return w.fail(r, BrokenCheckpointMismatch,
+			fmt.Sprintf("checkpoint of %s signed a different hash", cp.CreatedAt.UTC().Format(time.RFC3339)))
+	}
+	if w.key == nil || cp.KeyID != w.keyID {
+		w.res.CheckpointsUnverified++
+		return true
+	}
+	sig, err := base64.StdEncoding.DecodeString(cp.Signature)
+	if err != nil || !ed25519.Verify(w.key, checkpointMessage(cp.Seq, cp.Hash, cp.CreatedAt), sig) {
+		return w.fail(r, BrokenCheckpointSig,
+			fmt.Sprintf("checkpoint of %s has an invalid signature", cp.CreatedAt.UTC().Format(time.RFC3339)))
+	}
+	return true
+}
+
+func (w *chainWalker) fail(r chainRecord, reason, detail string) bool {
+	b := &BrokenLink{Seq: r.Seq, Reason: reason, Detail: detail}
+	if r.Event != nil {
+		b.EventID = r.Event.ID
+	}
+	if !r.Timestamp.IsZero() {
+		ts := r.Timestamp
+		b.Timestamp = &ts
+	}
+	w.res.Broken = b
+	return false
+}
+
+// finish completes the walk. A checkpoint the walk never reached signed an
+// event that is gone without a link, so the tail was truncated.
+func (w *chainWalker) finish() *VerifyResult {
+	if w.res.Broken == nil && len(w.checkpoints) > 0 {
+		seqs := make([]int64, 0, len(w.checkpoints))
+		for seq := range w.checkpoints {
+			seqs = append(seqs, seq)
+		}
+		sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+		w.fail(chainRecord{Seq: seqs[0]}, BrokenMissingEvent, "a checkpoint was signed for an event that no longer exists")
+	}
+	w.res.Valid = w.res.Broken == nil
+	return &w.res
+}
+
+// verifyRecords walks records, sorted by seq, across the range in opts.
+func verifyRecords(records []chainRecord, checkpoints []Checkpoint, opts VerifyOptions) *VerifyResult {
+	var from, to int64
+	for _, r := range records {
+		if from == 0 && (opts.Since == nil || !r.Timestamp.Before(*opts.Since)) {
+			from = r.Seq
+		}
+		if opts.Until == nil || !r.Timestamp.After(*opts.Until) {
+			to = r.Seq
+		}
+	}
+	if from == 0 || to < from {
+		return &VerifyResult{Valid: true}
+	}
+	w := newChainWalker(from, opts.PublicKey, checkpointsInRange(checkpoints, from, to, opts.Until != nil))
+	for _, r := range records {
+		if r.Seq < from-1 {
+			continue
+		}
+		if r.Seq > to || !w.step(r) {
+			break
+		}
+	}
+	return w.finish()
+}
+
+// checkpointsInRange returns the checkpoints a walk from seq from is
+// expected to reach. Without an upper time bound the walk runs to the head,
+// so every later checkpoint must be reached too.
+func checkpointsInRange(checkpoints []Checkpoint, from, to int64, bounded bool) []Checkpoint {
+	var in []Checkpoint
+	for _, cp := range checkpoints {
+		if cp.Seq >= from && (!bounded || cp.Seq <= to) {
+			in = append(in, cp)
+		}
+	}
+	return in
+}
@@ -0,0 +1,197 @@
+package audit
+
+import (
+	"context"
+	"crypto/ed25519"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testSigningKey(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	key, err := ParseSigningKey(strings.Repeat("07", ed25519.SeedSize))
+	if err != nil {
+		t.Fatalf("ParseSigningKey: %v", err)
+	}
+	return key
+}
+
+func TestMemoryAuditLogger_ChainLinksEvents(t *testing.T) {
+	ctx := context.Background()
+	l := NewMemoryAuditLogger()
+	seedRetentionEvents(t, l, time.Now().UTC())
+
+	events, _, err := l.List(ctx, ListOptions{Limit: 10})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	// Newest first: each event chains to the one listed after it.
+	for i, e := range events {
+		if want := int64(len(events) - i); e.Seq != want {
+			t.Errorf("event %s seq = %d, want %d", e.ID, e.Seq, want)
+		}
+		if i+1 < len(events) && e.PrevHash != events[i+1].Hash {
+			t.Errorf("event %s prev_hash does not match its predecessor", e.ID)
+		}
+	}
+
+	res, err := l.VerifyChain(ctx, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("VerifyChain: %v", err)
+	}
+	if !res.Valid || res.EventsChecked != 4 || res.FirstSeq != 1 || res.LastSeq != 4 {
+		t.Errorf("VerifyChain = %+v", res)
+	}
+}
+
+func TestMemoryAuditLogger_VerifyDetectsTampering(t *testing.T) {
+	ctx := context.Background()
+	l := NewMemoryAuditLogger()
+	seedRetentionEvents(t, l, time.Now().UTC())
+
+	// Events are newest first, so index 2 is seq 2.
+	l.events[2].Actor = "mallory"
+
+	res, err := l.VerifyChain(ctx, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("VerifyChain: %v", err)
+	}
+	if res.Valid || res.Broken == nil || res.Broken.Seq != 2 || res.Broken.Reason != BrokenHashMismatch {
+		t.Fatalf("VerifyChain = %+v, broken = %+v", res, res.Broken)
+	}
+}
+
+func TestMemoryAuditLogger_VerifyDetectsRemovedEvent(t *testing.T) {
+	ctx := context.Background()
+	l := NewMemoryAuditLogger()
+	seedRetentionEvents(t, l, time.Now().UTC())
+
+	l.events = append(l.events[:1], l.events[2:]...) // drop seq 3
+
+	res, err := l.VerifyChain(ctx, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("VerifyChain: %v", err)
+	}
+	if res.Valid || res.Broken.Seq != 3 || res.Broken.Reason != BrokenMissingEvent {
+		t.Fatalf("broken = %+v", res.Broken)
+	}
+}
+
+func TestMemoryAuditLogger_VerifySurvivesRetention(t *testing.T) {
+	ctx := context.Background()
+	l := NewMemoryAuditLogger()
+	seedRetentionEvents(t, l, time.Now().UTC())
+
+	if _, err := l.EnforcePolicy(ctx, AuditRetentionPolicy{MaxEvents: 2, RetainSuccessful: true}); err != nil {
+		t.Fatalf("EnforcePolicy: %v", err)
+	}
+	res, err := l.VerifyChain(ctx, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("VerifyChain: %v", err)
+	}
+	if !res.Valid || res.EventsChecked != 2 || res.PrunedLinks != 0 {
+		t.Errorf("VerifyChain = %+v", res)
+	}
+
+	// Logging continues from the pruned head.
+	if err := l.Log(ctx, &AuditEvent{Action: ActionCreate, ResourceType: ResourcePool}); err != nil {
+		t.Fatalf("Log: %v", err)
+	}
+	if res, _ := l.VerifyChain(ctx, VerifyOptions{}); !res.Valid || res.LastSeq != 5 {
+		t.Errorf("VerifyChain after Log = %+v", res)
+	}
+}
+
+func TestMemoryAuditLogger_VerifyTimeRange(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	l := NewMemoryAuditLogger()
+	seedRetentionEvents(t, l, now)
+
+	since := now.Add(-30 * 24 * time.Hour)
+	until := now.Add(-10 * 24 * time.Hour)
+	res, err := l.VerifyChain(ctx, VerifyOptions{Since: &since, Until: &until})
+	if err != nil {
+		t.Fatalf("VerifyChain: %v", err)
+	}
+	if !res.Valid || res.FirstSeq != 2 || res.LastSeq != 3 || res.EventsChecked != 2 {
+		t.Errorf("VerifyChain = %+v", res)
+	}
+}
+
+func TestMemoryAuditLogger_Checkpoints(t *testing.T) {
+	ctx := context.Background()
+	key := testSigningKey(t)
+	l := NewMemoryAuditLogger()
+	seedRetentionEvents(t, l, time.Now().UTC())
+
+	cp, err := l.Checkpoint(ctx, key)
+	if err != nil || cp == nil || cp.Seq != 4 {
+		t.Fatalf("Checkpoint = %+v, %v", cp, err)
+	}
+	if again, err := l.Checkpoint(ctx, key); err != nil || again != nil {
+		t.Errorf("Checkpoint of an unchanged head = %+v, %v; want nil", again, err)
+	}
+
+	pub := key.Public().(ed25519.PublicKey)
+	res, err := l.VerifyChain(ctx, VerifyOptions{PublicKey: pub})
+	if err != nil {
+		t.Fatalf("VerifyChain: %v", err)
+	}
+	if !res.Valid || res.CheckpointsChecked != 1 || res.CheckpointsUnverified != 0 {
+		t.Errorf("VerifyChain = %+v", res)
+	}
+
+	// Truncating the checkpointed tail is caught even though the remaining
+	// events still chain.
+	l.events = l.events[1:]
+	l.seq, l.head = 3, l.events[0].Hash
+	res, _ = l.VerifyChain(ctx, VerifyOptions{PublicKey: pub})
+	if res.Valid || res.Broken.Seq != 4 || res.Broken.Reason != BrokenMissingEvent {
+		t.Errorf("broken = %+v", res.Broken)
+	}
+
+	// A forged signature fails verification.
+	l = NewMemoryAuditLogger()
+	seedRetentionEvents(t, l, time.Now().UTC())
+	if _, err := l.Checkpoint(ctx, key); err != nil {
+		t.Fatalf("Checkpoint: %v", err)
+	}
+	l.checkpoints[0].Signature = "AAAA"
+	res, _ = l.VerifyChain(ctx, VerifyOptions{PublicKey: pub})
+	if res.Valid || res.Broken.Reason != BrokenCheckpointSig {
+		t.Errorf("broken = %+v", res.Broken)
+	}
+	if res, _ := l.VerifyChain(ctx, VerifyOptions{}); !res.Valid || res.CheckpointsUnverified != 1 {
+		t.Errorf("VerifyChain without key = %+v", res)
+	}
+}
+
+func TestCanonicalJSONIgnoresKeyOrderAndWhitespace(t *testing.T) {
+	a, err := canonicalJSON([]byte(`{"after":{"name":"x","cidr":"10.0.0.0/8"}}`))
+	if err != nil {
+		t.Fatalf("canonicalJSON: %v", err)
+	}
+	b, err := canonicalJSON([]byte(`{"after": {"cidr": "10.0.0.0/8", "name": "x"}}`))
+	if err != nil {
+		t.Fatalf("canonicalJSON: %v", err)
+	}
+	if string(a) != string(b) {
+		t.Errorf("canonicalJSON differs: %s vs %s", a, b)
+	}
+}
+
+func TestParseSigningKey(t *testing.T) {
+	if _, err := ParseSigningKey("not-a-key"); err == nil {
+		t.Error("ParseSigningKey accepted a malformed key")
+	}
+	hexKey := testSigningKey(t)
+	b64Key, err := ParseSigningKey("BwcHBwcHBwcHBwcHBwcHBwcHBwcHBwcHBwcHBwcHBwc=")
+	if err != nil {
+		t.Fatalf("ParseSigningKey base64: %v", err)
+	}
+	if !hexKey.Equal(b64Key) {
+		t.Error("hex and base64 encodings of the same seed differ")
+	}
+}
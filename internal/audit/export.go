@@ -0,0 +1,153 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// EventStreamer is implemented by audit loggers that can hand out a large
+// range of events without holding all of them in memory.
+type EventStreamer interface {
+	// Stream calls fn for each event matching the filters in opts, oldest
+	// first in chain order. Limit and Offset are ignored. Events are read in
+	// batches, so fn may see events logged after Stream began. Stream stops
+	// at the first error fn returns and returns it.
+	Stream(ctx context.Context, opts ListOptions, fn func(*AuditEvent) error) error
+}
+
+// AsEventStreamer returns the EventStreamer behind l, looking through any
+// ForwardingAuditLogger wrappers to the logger that persists events.
+func AsEventStreamer(l AuditLogger) (EventStreamer, bool) {
+	return unwrapLogger[EventStreamer](l)
+}
+
+// streamBatchSize bounds the events read at once while streaming.
+const streamBatchSize = 1000
+
+// ExportFormat selects the encoding of an audit export.
+type ExportFormat string
+
+// Supported export formats.
+const (
+	ExportJSONL ExportFormat = "jsonl" // one JSON event per line
+	ExportCSV   ExportFormat = "csv"   // a header row, then one row per event
+	ExportCEF   ExportFormat = "cef"   // one CEF event per line, as forwarded to syslog
+)
+
+// ParseExportFormat validates an export format name.
+func ParseExportFormat(s string) (ExportFormat, error) {
+	switch f := ExportFormat(s); f {
+	case ExportJSONL, ExportCSV, ExportCEF:
+		return f, nil
+	}
+	return "", fmt.Errorf("format must be jsonl, csv or cef")
+}
+
+// ContentType returns the MIME type of an export in format f.
+func (f ExportFormat) ContentType() string {
+	switch f {
+	case ExportJSONL:
+		return "application/x-ndjson"
+	case ExportCSV:
+		return "text/csv; charset=utf-8"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// exportCSVHeader lists the columns of a CSV export.
+var exportCSVHeader = []string{
+	"seq", "id", "timestamp", "actor", "actor_type", "action", "resource_type", "resource_id",
+	"resource_name", "status_code", "request_id", "ip_address", "changes", "prev_hash", "hash",
+}
+
+// ExportWriter encodes audit events in an export format. Output is
+// buffered; call Flush to push it to the underlying writer.
+type ExportWriter struct {
+	format ExportFormat
+	buf    *bufio.Writer
+	csv    *csv.Writer
+	cef    CEFFormatter
+	// header records that the CSV header row has been written.
+	header bool
+}
+
+// NewExportWriter returns a writer of format f to w. cef formats events
+// for ExportCEF.
+func NewExportWriter(w io.Writer, f ExportFormat, cef CEFFormatter) *ExportWriter {
+	x := &ExportWriter{format: f, buf: bufio.NewWriter(w), cef: cef}
+	if f == ExportCSV {
+		x.csv = csv.NewWriter(x.buf)
+	}
+	return x
+}
+
+// Write encodes one event.
+func (x *ExportWriter) Write(e *AuditEvent) error {
+	switch x.format {
+	case ExportJSONL:
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		if _, err := x.buf.Write(data); err != nil {
+			return err
+		}
+		return x.buf.WriteByte('\n')
+	case ExportCSV:
+		if err := x.writeHeader(); err != nil {
+			return err
+		}
+		return x.csv.Write([]string{
+			strconv.FormatInt(e.Seq, 10),
+			e.ID,
+			e.Timestamp.UTC().Format(time.RFC3339Nano),
+			e.Actor,
+			e.ActorType,
+			e.Action,
+			e.ResourceType,
+			e.ResourceID,
+			e.ResourceName,
+			strconv.Itoa(e.StatusCode),
+			e.RequestID,
+			e.IPAddress,
+			string(changesJSON(e.Changes)),
+			e.PrevHash,
+			e.Hash,
+		})
+	default:
+		if _, err := x.buf.WriteString(x.cef.Format(e)); err != nil {
+			return err
+		}
+		return x.buf.WriteByte('\n')
+	}
+}
+
+// Flush writes any buffered output. A CSV export with no events still
+// gets its header row.
+func (x *ExportWriter) Flush() error {
+	if x.csv != nil {
+		if err := x.writeHeader(); err != nil {
+			return err
+		}
+		x.csv.Flush()
+		if err := x.csv.Error(); err != nil {
+			return err
+		}
+	}
+	return x.buf.Flush()
+}
+
+func (x *ExportWriter) writeHeader() error {
+	if x.header {
+		return nil
+	}
+	x.header = true
+	return x.csv.Write(exportCSVHeader)
+}
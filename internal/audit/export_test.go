@@ -0,0 +1,128 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func streamIDs(t *testing.T, s EventStreamer, opts ListOptions) []string {
+	t.Helper()
+	var ids []string
+	if err := s.Stream(context.Background(), opts, func(e *AuditEvent) error {
+		ids = append(ids, e.ID)
+		return nil
+	}); err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	return ids
+}
+
+func TestMemoryAuditLogger_Stream(t *testing.T) {
+	now := time.Now().UTC()
+	l := NewMemoryAuditLogger()
+	seedRetentionEvents(t, l, now)
+
+	if got := strings.Join(streamIDs(t, l, ListOptions{Limit: 1}), ","); got != "old-ok,mid-ok,mid-fail,new-ok" {
+		t.Errorf("all events = %s, want oldest first and Limit ignored", got)
+	}
+	since := now.Add(-30 * 24 * time.Hour)
+	if got := strings.Join(streamIDs(t, l, ListOptions{Actor: "alice", Since: &since}), ","); got != "mid-ok,new-ok" {
+		t.Errorf("filtered events = %s", got)
+	}
+
+	stop := errors.New("stop")
+	var seen int
+	err := l.Stream(context.Background(), ListOptions{}, func(*AuditEvent) error {
+		seen++
+		return stop
+	})
+	if !errors.Is(err, stop) || seen != 1 {
+		t.Errorf("Stream = %v after %d events, want the callback error after 1", err, seen)
+	}
+}
+
+func TestParseExportFormat(t *testing.T) {
+	for _, f := range []string{"jsonl", "csv", "cef"} {
+		if got, err := ParseExportFormat(f); err != nil || string(got) != f {
+			t.Errorf("ParseExportFormat(%q) = %q, %v", f, got, err)
+		}
+	}
+	if _, err := ParseExportFormat("pdf"); err == nil {
+		t.Error("ParseExportFormat accepted pdf")
+	}
+}
+
+func exportEvents() []*AuditEvent {
+	ts := time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC)
+	return []*AuditEvent{
+		{ID: "e1", Timestamp: ts, Actor: "alice", ActorType: ActorTypeUser, Action: ActionCreate, ResourceType: ResourcePool, ResourceID: "7",
+			ResourceName: "prod, east", StatusCode: 201, Seq: 1, Hash: "h1"},
+		{ID: "e2", Timestamp: ts.Add(time.Minute), Actor: "bob", Action: ActionUpdate, ResourceType: ResourcePool, ResourceID: "7",
+			Changes: &Changes{After: map[string]any{"name": "prod"}}, StatusCode: 200, Seq: 2, PrevHash: "h1", Hash: "h2"},
+	}
+}
+
+func writeExport(t *testing.T, f ExportFormat, events []*AuditEvent) string {
+	t.Helper()
+	var buf bytes.Buffer
+	x := NewExportWriter(&buf, f, CEFFormatter{DeviceVersion: "1.2.3"})
+	for _, e := range events {
+		if err := x.Write(e); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := x.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	return buf.String()
+}
+
+func TestExportWriterJSONL(t *testing.T) {
+	lines := strings.Split(strings.TrimSuffix(writeExport(t, ExportJSONL, exportEvents()), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("lines = %q", lines)
+	}
+	var e AuditEvent
+	if err := json.Unmarshal([]byte(lines[1]), &e); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if e.ID != "e2" || e.Changes == nil || e.Changes.After["name"] != "prod" || e.PrevHash != "h1" {
+		t.Errorf("event = %+v", e)
+	}
+}
+
+func TestExportWriterCSV(t *testing.T) {
+	rows, err := csv.NewReader(strings.NewReader(writeExport(t, ExportCSV, exportEvents()))).ReadAll()
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	if len(rows) != 3 || strings.Join(rows[0], ",") != strings.Join(exportCSVHeader, ",") {
+		t.Fatalf("rows = %q", rows)
+	}
+	if rows[1][0] != "1" || rows[1][8] != "prod, east" || rows[1][9] != "201" {
+		t.Errorf("row 1 = %q", rows[1])
+	}
+	if rows[2][12] != `{"after":{"name":"prod"}}` || rows[2][13] != "h1" {
+		t.Errorf("row 2 = %q", rows[2])
+	}
+
+	if got := writeExport(t, ExportCSV, nil); got != strings.Join(exportCSVHeader, ",")+"\n" {
+		t.Errorf("empty export = %q, want the header only", got)
+	}
+}
+
+func TestExportWriterCEF(t *testing.T) {
+	lines := strings.Split(strings.TrimSuffix(writeExport(t, ExportCEF, exportEvents()), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("lines = %q", lines)
+	}
+	if !strings.HasPrefix(lines[0], "CEF:0|BadgerOps|CloudPAM|1.2.3|create|") || !strings.Contains(lines[1], "cs5=h2") {
+		t.Errorf("lines = %q", lines)
+	}
+}
@@ -2,6 +2,8 @@ package audit
 
 import (
 	"context"
+	"crypto/ed25519"
+	"sort"
 	"sync"
 	"time"
 
@@ -18,6 +20,13 @@ type MemoryAuditLogger struct {
 	mu        sync.RWMutex
 	events    []*AuditEvent
 	maxEvents int
+
+	// Hash chain state: the head's seq and hash, the links of pruned events
+	// that retained events still follow, by seq, and the checkpoints.
+	seq         int64
+	head        string
+	links       []chainRecord
+	checkpoints []Checkpoint
 }
 
 // MemoryAuditLoggerOption configures a MemoryAuditLogger.
@@ -61,6 +70,14 @@ func (m *MemoryAuditLogger) Log(ctx context.Context, event *AuditEvent) error {
 		event.Timestamp = time.Now().UTC()
 	}
 
+	hash, err := chainHash(m.seq+1, m.head, event, changesJSON(event.Changes))
+	if err != nil {
+		return err
+	}
+	m.seq++
+	event.Seq, event.PrevHash, event.Hash = m.seq, m.head, hash
+	m.head = hash
+
 	// Create a copy to prevent external modification
 	eventCopy := *event
 	if event.Changes != nil {
@@ -76,6 +93,7 @@ func (m *MemoryAuditLogger) Log(ctx context.Context, event *AuditEvent) error {
 	// Trim to maxEvents
 	if len(m.events) > m.maxEvents {
 		m.events = m.events[:m.maxEvents]
+		m.pruneLinks()
 	}
 
 	return nil
@@ -144,6 +162,143 @@ func (m *MemoryAuditLogger) GetByResource(ctx context.Context, resourceType, res
 	return result, nil
 }
 
+// Stream calls fn for each event matching opts, oldest first. The matching
+// events are copied before fn runs, so fn may take its time without
+// blocking writers.
+func (m *MemoryAuditLogger) Stream(ctx context.Context, opts ListOptions, fn func(*AuditEvent) error) error {
+	m.mu.RLock()
+	var matched []*AuditEvent
+	for i := len(m.events) - 1; i >= 0; i-- {
+		if matchesFilters(m.events[i], opts) {
+			matched = append(matched, copyEvent(m.events[i]))
+		}
+	}
+	m.mu.RUnlock()
+
+	for _, e := range matched {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnforcePolicy deletes the events that policy no longer retains.
+func (m *MemoryAuditLogger) EnforcePolicy(ctx context.Context, policy AuditRetentionPolicy) (int64, error) {
+	all, successful := retentionCutoffs(policy, time.Now().UTC())
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	kept := make([]*AuditEvent, 0, len(m.events))
+	var removed []*AuditEvent
+	for _, e := range m.events {
+		if expired(e, all, successful) {
+			removed = append(removed, e)
+		} else {
+			kept = append(kept, e)
+		}
+	}
+	// Events are newest first, so trimming the tail drops the oldest.
+	if policy.MaxEvents > 0 && int64(len(kept)) > policy.MaxEvents {
+		removed = append(removed, kept[policy.MaxEvents:]...)
+		kept = kept[:policy.MaxEvents]
+	}
+	for _, e := range removed {
+		m.links = append(m.links, chainRecord{Seq: e.Seq, PrevHash: e.PrevHash, Hash: e.Hash, Timestamp: e.Timestamp})
+	}
+	sort.Slice(m.links, func(i, j int) bool { return m.links[i].Seq < m.links[j].Seq })
+	m.events = kept
+	m.pruneLinks()
+	return int64(len(removed)), nil
+}
+
+// GetStats returns statistics about the stored events.
+func (m *MemoryAuditLogger) GetStats(ctx context.Context) (*AuditStats, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stats := &AuditStats{
+		TotalEvents:      int64(len(m.events)),
+		EventsByAction:   map[string]int64{},
+		EventsByResource: map[string]int64{},
+		EventsByActor:    map[string]int64{},
+	}
+	for _, e := range m.events {
+		if stats.OldestEvent == nil || e.Timestamp.Before(*stats.OldestEvent) {
+			ts := e.Timestamp
+			stats.OldestEvent = &ts
+		}
+		if stats.NewestEvent == nil || e.Timestamp.After(*stats.NewestEvent) {
+			ts := e.Timestamp
+			stats.NewestEvent = &ts
+		}
+		stats.EventsByAction[e.Action]++
+		stats.EventsByResource[e.ResourceType]++
+		stats.EventsByActor[e.Actor]++
+		if e.StatusCode >= 400 {
+			stats.FailureCount++
+		} else {
+			stats.SuccessCount++
+		}
+	}
+	stats.EventsByActor = topCounts(stats.EventsByActor, StatsTopActors)
+	return stats, nil
+}
+
+// pruneLinks drops the links and checkpoints that precede every retained
+// event, keeping the newest link when no event is left so that the chain
+// head survives. The caller must hold m.mu.
+func (m *MemoryAuditLogger) pruneLinks() {
+	var bound int64
+	switch {
+	case len(m.events) > 0:
+		bound = m.events[len(m.events)-1].Seq
+	case len(m.links) > 0:
+		bound = m.links[len(m.links)-1].Seq
+	default:
+		return
+	}
+	i := sort.Search(len(m.links), func(i int) bool { return m.links[i].Seq >= bound })
+	m.links = m.links[i:]
+	i = sort.Search(len(m.checkpoints), func(i int) bool { return m.checkpoints[i].Seq >= bound })
+	m.checkpoints = m.checkpoints[i:]
+}
+
+// VerifyChain walks the hash chain across the range in opts.
+func (m *MemoryAuditLogger) VerifyChain(ctx context.Context, opts VerifyOptions) (*VerifyResult, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	records := make([]chainRecord, 0, len(m.events)+len(m.links))
+	for i := len(m.events) - 1; i >= 0; i-- {
+		e := m.events[i]
+		records = append(records, chainRecord{
+			Seq: e.Seq, PrevHash: e.PrevHash, Hash: e.Hash, Timestamp: e.Timestamp,
+			Event: e, Changes: changesJSON(e.Changes),
+		})
+	}
+	records = append(records, m.links...)
+	sort.Slice(records, func(i, j int) bool { return records[i].Seq < records[j].Seq })
+	return verifyRecords(records, m.checkpoints, opts), nil
+}
+
+// Checkpoint signs the current chain head with key.
+func (m *MemoryAuditLogger) Checkpoint(ctx context.Context, key ed25519.PrivateKey) (*Checkpoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.seq == 0 || (len(m.checkpoints) > 0 && m.checkpoints[len(m.checkpoints)-1].Seq == m.seq) {
+		return nil, nil
+	}
+	cp := signCheckpoint(m.seq, m.head, key)
+	m.checkpoints = append(m.checkpoints, *cp)
+	return cp, nil
+}
+
 // matchesFilters checks if an event matches the provided filter options.
 func matchesFilters(e *AuditEvent, opts ListOptions) bool {
 	if opts.Actor != "" && e.Actor != opts.Actor {
@@ -4,7 +4,11 @@ package audit
 
 import (
 	"context"
+	"crypto/ed25519"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -20,8 +24,26 @@ type PostgresAuditLogger struct {
 	pool    *pgxpool.Pool
 	ownPool bool // true if we created the pool (and should close it)
 	orgID   string
+	// chained records that events predating the hash chain have been
+	// backfilled.
+	chained atomic.Bool
 }
 
+// postgresEventColumns lists the audit_events columns read by
+// scanPostgresRecords.
+const postgresEventColumns = `id, timestamp, actor_id, actor_type, action, resource_type, resource_id,
+	resource_name, changes, request_id, actor_ip, status_code, seq, prev_hash, hash`
+
+// postgresLinkColumns reads an audit_chain_links row in the shape of
+// postgresEventColumns.
+const postgresLinkColumns = `NULL, timestamp, NULL, NULL, NULL, NULL, NULL,
+	NULL, NULL, NULL, NULL, NULL, seq, prev_hash, hash`
+
+// postgresChainPositions lists every position in the organization's chain,
+// $1: chained events and the links of pruned ones.
+const postgresChainPositions = `(SELECT seq, timestamp FROM audit_events WHERE organization_id = $1 AND seq IS NOT NULL
+	UNION ALL SELECT seq, timestamp FROM audit_chain_links WHERE organization_id = $1) c`
+
 // NewPostgresAuditLogger creates a new PostgreSQL-backed audit logger with its own connection pool.
 func NewPostgresAuditLogger(connStr string) (*PostgresAuditLogger, error) {
 	pool, err := pgxpool.New(context.Background(), connStr)
@@ -44,7 +66,8 @@ func (s *PostgresAuditLogger) Close() error {
 	return nil
 }
 
-// Log records an audit event to the database.
+// Log records an audit event to the database, chained to the current head
+// of the organization's hash chain.
 func (s *PostgresAuditLogger) Log(ctx context.Context, event *AuditEvent) error {
 	if event == nil {
 		return nil
@@ -56,34 +79,94 @@ func (s *PostgresAuditLogger) Log(ctx context.Context, event *AuditEvent) error
 	if event.Timestamp.IsZero() {
 		event.Timestamp = time.Now().UTC()
 	}
+	// TIMESTAMPTZ keeps microseconds; hash the timestamp that is read back.
+	event.Timestamp = event.Timestamp.Truncate(time.Microsecond)
+	changes := changesJSON(event.Changes)
 
-	var changesJSON []byte
-	if event.Changes != nil {
-		var err error
-		changesJSON, err = json.Marshal(event.Changes)
-		if err != nil {
-			changesJSON = nil
-		}
+	tx, err := s.lockChain(ctx)
+	if err != nil {
+		return err
 	}
+	defer func() { _ = tx.Rollback(ctx) }()
 
-	_, err := s.pool.Exec(ctx, `
+	if err := s.backfillChain(ctx, tx); err != nil {
+		return err
+	}
+	seq, prev, err := s.chainHead(ctx, tx)
+	if err != nil {
+		return err
+	}
+	hash, err := chainHash(seq+1, prev, event, changes)
+	if err != nil {
+		return err
+	}
+	event.Seq, event.PrevHash, event.Hash = seq+1, prev, hash
+
+	if _, err := tx.Exec(ctx, `
 		INSERT INTO audit_events (id, organization_id, timestamp, actor_id, actor_type, action,
-			resource_type, resource_id, resource_name, changes, request_id, actor_ip, status_code)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10::jsonb, $11, $12, $13)`,
+			resource_type, resource_id, resource_name, changes, request_id, actor_ip, status_code,
+			seq, prev_hash, hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10::jsonb, $11, $12, $13, $14, $15, $16)`,
 		event.ID, s.orgID, event.Timestamp,
 		nullStr(event.Actor), event.ActorType, event.Action,
 		event.ResourceType, event.ResourceID,
 		nullStr(event.ResourceName),
-		nullBytes(changesJSON),
+		nullBytes(changes),
 		nullStr(event.RequestID),
 		nullStr(event.IPAddress),
 		event.StatusCode,
-	)
-	return err
+		event.Seq, event.PrevHash, event.Hash,
+	); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	s.chained.Store(true)
+	return nil
 }
 
 // List retrieves audit events with optional filtering.
 func (s *PostgresAuditLogger) List(ctx context.Context, opts ListOptions) ([]*AuditEvent, int, error) {
+	where, args := s.listWhere(opts)
+	argIdx := len(args) + 1
+
+	// Count total
+	var total int
+	countQuery := "SELECT COUNT(*) FROM audit_events WHERE " + where
+	if err := s.pool.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	// Apply pagination
+	if opts.Limit <= 0 {
+		opts.Limit = 50
+	}
+	if opts.Limit > 1000 {
+		opts.Limit = 1000
+	}
+
+	query := "SELECT " + postgresEventColumns + " FROM audit_events WHERE " + where +
+		" ORDER BY timestamp DESC LIMIT $" + itoa(argIdx) + " OFFSET $" + itoa(argIdx+1)
+	args = append(args, opts.Limit, opts.Offset)
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	events, err := scanAuditEvents(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return events, total, nil
+}
+
+// listWhere builds the WHERE clause for the organization and the filters
+// in opts. Its placeholders run from $1 to $len(args).
+func (s *PostgresAuditLogger) listWhere(opts ListOptions) (string, []any) {
 	where := "organization_id = $1"
 	args := []any{s.orgID}
 	argIdx := 2
@@ -116,47 +199,48 @@ func (s *PostgresAuditLogger) List(ctx context.Context, opts ListOptions) ([]*Au
 	if opts.Until != nil {
 		where += " AND timestamp <= $" + itoa(argIdx)
 		args = append(args, *opts.Until)
-		argIdx++
-	}
-
-	// Count total
-	var total int
-	countQuery := "SELECT COUNT(*) FROM audit_events WHERE " + where
-	if err := s.pool.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
-		return nil, 0, err
-	}
-
-	// Apply pagination
-	if opts.Limit <= 0 {
-		opts.Limit = 50
-	}
-	if opts.Limit > 1000 {
-		opts.Limit = 1000
 	}
+	return where, args
+}
 
-	query := "SELECT id, timestamp, actor_id, actor_type, action, resource_type, resource_id, resource_name, changes, request_id, actor_ip, status_code FROM audit_events WHERE " + where +
-		" ORDER BY timestamp DESC LIMIT $" + itoa(argIdx) + " OFFSET $" + itoa(argIdx+1)
-	args = append(args, opts.Limit, opts.Offset)
-
-	rows, err := s.pool.Query(ctx, query, args...)
-	if err != nil {
-		return nil, 0, err
+// Stream calls fn for each event matching opts, oldest first. Events are
+// read streamBatchSize at a time by seq, so no query is held open while fn
+// runs.
+func (s *PostgresAuditLogger) Stream(ctx context.Context, opts ListOptions, fn func(*AuditEvent) error) error {
+	if err := s.ensureChained(ctx); err != nil {
+		return err
 	}
-	defer rows.Close()
-
-	events, err := scanAuditEvents(rows)
-	if err != nil {
-		return nil, 0, err
+	where, args := s.listWhere(opts)
+	n := len(args)
+	query := "SELECT " + postgresEventColumns + " FROM audit_events WHERE " + where +
+		" AND seq > $" + itoa(n+1) + " ORDER BY seq LIMIT $" + itoa(n+2)
+	var after int64
+	for {
+		rows, err := s.pool.Query(ctx, query, append(args, after, streamBatchSize)...)
+		if err != nil {
+			return err
+		}
+		batch, err := scanPostgresRecords(rows)
+		rows.Close()
+		if err != nil {
+			return err
+		}
+		for _, r := range batch {
+			if err := fn(r.Event); err != nil {
+				return err
+			}
+		}
+		if len(batch) < streamBatchSize {
+			return nil
+		}
+		after = batch[len(batch)-1].Seq
 	}
-
-	return events, total, nil
 }
 
 // GetByResource retrieves audit events for a specific resource.
 func (s *PostgresAuditLogger) GetByResource(ctx context.Context, resourceType, resourceID string) ([]*AuditEvent, error) {
 	rows, err := s.pool.Query(ctx, `
-		SELECT id, timestamp, actor_id, actor_type, action, resource_type, resource_id,
-			resource_name, changes, request_id, actor_ip, status_code
+		SELECT `+postgresEventColumns+`
 		FROM audit_events
 		WHERE organization_id = $1 AND resource_type = $2 AND resource_id = $3
 		ORDER BY timestamp DESC
@@ -170,47 +254,83 @@ func (s *PostgresAuditLogger) GetByResource(ctx context.Context, resourceType, r
 }
 
 func scanAuditEvents(rows pgx.Rows) ([]*AuditEvent, error) {
+	records, err := scanPostgresRecords(rows)
+	if err != nil {
+		return nil, err
+	}
 	var events []*AuditEvent
+	for _, r := range records {
+		events = append(events, r.Event)
+	}
+	return events, nil
+}
+
+// scanPostgresRecords scans rows selecting postgresEventColumns, or
+// postgresLinkColumns for pruned links.
+func scanPostgresRecords(rows pgx.Rows) ([]chainRecord, error) {
+	var records []chainRecord
 	for rows.Next() {
-		var e AuditEvent
-		var actor, resourceName, changesStr, requestID, ipAddress *string
+		var r chainRecord
+		var id, actor, actorType, action, resourceType, resourceID, resourceName, changesStr, requestID, ipAddress, prevHash, hash *string
 		var statusCode *int
+		var seq *int64
 
 		if err := rows.Scan(
-			&e.ID, &e.Timestamp, &actor, &e.ActorType,
-			&e.Action, &e.ResourceType, &e.ResourceID,
+			&id, &r.Timestamp, &actor, &actorType,
+			&action, &resourceType, &resourceID,
 			&resourceName, &changesStr, &requestID, &ipAddress, &statusCode,
+			&seq, &prevHash, &hash,
 		); err != nil {
 			return nil, err
 		}
+		r.Seq, r.PrevHash, r.Hash = derefInt64(seq), derefStr(prevHash), derefStr(hash)
 
-		if actor != nil {
-			e.Actor = *actor
-		}
-		if resourceName != nil {
-			e.ResourceName = *resourceName
-		}
-		if requestID != nil {
-			e.RequestID = *requestID
-		}
-		if ipAddress != nil {
-			e.IPAddress = *ipAddress
-		}
-		if statusCode != nil {
-			e.StatusCode = *statusCode
-		}
-
-		if changesStr != nil && *changesStr != "" {
-			var changes Changes
-			if err := json.Unmarshal([]byte(*changesStr), &changes); err == nil {
-				e.Changes = &changes
+		if id != nil {
+			e := &AuditEvent{
+				ID:           *id,
+				Timestamp:    r.Timestamp,
+				Actor:        derefStr(actor),
+				ActorType:    derefStr(actorType),
+				Action:       derefStr(action),
+				ResourceType: derefStr(resourceType),
+				ResourceID:   derefStr(resourceID),
+				ResourceName: derefStr(resourceName),
+				RequestID:    derefStr(requestID),
+				IPAddress:    derefStr(ipAddress),
+				Seq:          r.Seq,
+				PrevHash:     r.PrevHash,
+				Hash:         r.Hash,
 			}
+			if statusCode != nil {
+				e.StatusCode = *statusCode
+			}
+			if changesStr != nil && *changesStr != "" {
+				r.Changes = []byte(*changesStr)
+				var changes Changes
+				if err := json.Unmarshal(r.Changes, &changes); err == nil {
+					e.Changes = &changes
+				}
+			}
+			r.Event = e
 		}
+		records = append(records, r)
+	}
 
-		events = append(events, &e)
+	return records, rows.Err()
+}
+
+func derefStr(s *string) string {
+	if s == nil {
+		return ""
 	}
+	return *s
+}
 
-	return events, rows.Err()
+func derefInt64(i *int64) int64 {
+	if i == nil {
+		return 0
+	}
+	return *i
 }
 
 func nullStr(s string) *string {
@@ -239,3 +359,360 @@ func itoa(i int) string {
 	}
 	return digits
 }
+
+// EnforcePolicy deletes the events that policy no longer retains, leaving
+// the link of each chained event behind while a retained event follows it.
+func (s *PostgresAuditLogger) EnforcePolicy(ctx context.Context, policy AuditRetentionPolicy) (int64, error) {
+	deleted, err := s.enforcePolicy(ctx, policy)
+	if deleted > 0 && err == nil {
+		err = s.pruneLinks(ctx)
+	}
+	return deleted, err
+}
+
+func (s *PostgresAuditLogger) enforcePolicy(ctx context.Context, policy AuditRetentionPolicy) (int64, error) {
+	all, successful := retentionCutoffs(policy, time.Now().UTC())
+	var deleted int64
+	if !all.IsZero() {
+		n, err := s.deleteEvents(ctx, `timestamp < $2`, all)
+		deleted += n
+		if err != nil {
+			return deleted, err
+		}
+	}
+	if !successful.IsZero() {
+		n, err := s.deleteEvents(ctx, `status_code < 400 AND timestamp < $2`, successful)
+		deleted += n
+		if err != nil {
+			return deleted, err
+		}
+	}
+	if policy.MaxEvents > 0 {
+		n, err := s.deleteEvents(ctx, `id IN (
+				SELECT id FROM audit_events
+				WHERE organization_id = $1
+				ORDER BY timestamp DESC, id DESC
+				OFFSET $2)`, policy.MaxEvents)
+		deleted += n
+		if err != nil {
+			return deleted, err
+		}
+	}
+	return deleted, nil
+}
+
+// deleteEvents deletes the organization's events matching where, whose
+// arguments start at $2, and moves the chain link of each into
+// audit_chain_links.
+func (s *PostgresAuditLogger) deleteEvents(ctx context.Context, where string, args ...any) (int64, error) {
+	var n int64
+	err := s.pool.QueryRow(ctx, `
+		WITH gone AS (
+			DELETE FROM audit_events
+			WHERE organization_id = $1 AND `+where+`
+			RETURNING seq, prev_hash, hash, timestamp
+		), linked AS (
+			INSERT INTO audit_chain_links (organization_id, seq, prev_hash, hash, timestamp)
+			SELECT $1, seq, prev_hash, hash, timestamp FROM gone WHERE seq IS NOT NULL
+		)
+		SELECT COUNT(*) FROM gone`, append([]any{s.orgID}, args...)...).Scan(&n)
+	return n, err
+}
+
+// pruneLinks drops the links and checkpoints that precede every retained
+// event, keeping the newest link when no event is left so that the chain
+// head survives.
+func (s *PostgresAuditLogger) pruneLinks(ctx context.Context) error {
+	var bound *int64
+	if err := s.pool.QueryRow(ctx, `
+		SELECT COALESCE(
+			(SELECT MIN(seq) FROM audit_events WHERE organization_id = $1),
+			(SELECT MAX(seq) FROM audit_chain_links WHERE organization_id = $1))`, s.orgID).Scan(&bound); err != nil {
+		return err
+	}
+	if bound == nil {
+		return nil
+	}
+	if _, err := s.pool.Exec(ctx, `DELETE FROM audit_chain_links WHERE organization_id = $1 AND seq < $2`, s.orgID, *bound); err != nil {
+		return err
+	}
+	_, err := s.pool.Exec(ctx, `DELETE FROM audit_checkpoints WHERE organization_id = $1 AND seq < $2`, s.orgID, *bound)
+	return err
+}
+
+// GetStats returns statistics about the organization's events.
+func (s *PostgresAuditLogger) GetStats(ctx context.Context) (*AuditStats, error) {
+	stats := &AuditStats{}
+	if err := s.pool.QueryRow(ctx, `
+		SELECT COUNT(*),
+			COUNT(*) FILTER (WHERE status_code < 400),
+			COUNT(*) FILTER (WHERE status_code >= 400),
+			MIN(timestamp), MAX(timestamp)
+		FROM audit_events
+		WHERE organization_id = $1`, s.orgID).Scan(
+		&stats.TotalEvents, &stats.SuccessCount, &stats.FailureCount, &stats.OldestEvent, &stats.NewestEvent,
+	); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if stats.EventsByAction, err = s.countBy(ctx, `
+		SELECT action, COUNT(*) FROM audit_events
+		WHERE organization_id = $1 GROUP BY action`); err != nil {
+		return nil, err
+	}
+	if stats.EventsByResource, err = s.countBy(ctx, `
+		SELECT resource_type, COUNT(*) FROM audit_events
+		WHERE organization_id = $1 GROUP BY resource_type`); err != nil {
+		return nil, err
+	}
+	if stats.EventsByActor, err = s.countBy(ctx, `
+		SELECT COALESCE(actor_id, ''), COUNT(*) AS n FROM audit_events
+		WHERE organization_id = $1 GROUP BY 1 ORDER BY n DESC, 1 LIMIT $2`, StatsTopActors); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// countBy runs a two-column key, count query scoped to the organization,
+// which is always the first argument.
+func (s *PostgresAuditLogger) countBy(ctx context.Context, query string, args ...any) (map[string]int64, error) {
+	rows, err := s.pool.Query(ctx, query, append([]any{s.orgID}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	counts := map[string]int64{}
+	for rows.Next() {
+		var key string
+		var n int64
+		if err := rows.Scan(&key, &n); err != nil {
+			return nil, err
+		}
+		counts[key] = n
+	}
+	return counts, rows.Err()
+}
+
+// lockChain begins a transaction holding the organization's chain lock, so
+// that concurrent writers, across replicas too, chain to the head they read.
+func (s *PostgresAuditLogger) lockChain(ctx context.Context) (pgx.Tx, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('cloudpam_audit_chain'), hashtext($1))`, s.orgID); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, err
+	}
+	return tx, nil
+}
+
+// chainHead returns the seq and hash of the newest position in the chain,
+// or zero values for an empty chain.
+func (s *PostgresAuditLogger) chainHead(ctx context.Context, q pgxQuerier) (int64, string, error) {
+	var seq int64
+	var hash string
+	err := q.QueryRow(ctx, `
+		SELECT seq, hash FROM audit_events WHERE organization_id = $1 AND seq IS NOT NULL
+		UNION ALL
+		SELECT seq, hash FROM audit_chain_links WHERE organization_id = $1
+		ORDER BY seq DESC LIMIT 1`, s.orgID).Scan(&seq, &hash)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, "", nil
+	}
+	return seq, hash, err
+}
+
+// pgxQuerier is satisfied by *pgxpool.Pool and pgx.Tx.
+type pgxQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// backfillChain chains the events recorded before the hash chain existed,
+// oldest first. They precede every chained event, so this must run before
+// the first event is chained, inside a transaction from lockChain.
+func (s *PostgresAuditLogger) backfillChain(ctx context.Context, tx pgx.Tx) error {
+	if s.chained.Load() {
+		return nil
+	}
+	seq, prev, err := s.chainHead(ctx, tx)
+	if err != nil {
+		return err
+	}
+	for {
+		rows, err := tx.Query(ctx, `
+			SELECT `+postgresEventColumns+` FROM audit_events
+			WHERE organization_id = $1 AND seq IS NULL
+			ORDER BY timestamp, id LIMIT $2`, s.orgID, chainBatchSize)
+		if err != nil {
+			return err
+		}
+		batch, err := scanPostgresRecords(rows)
+		rows.Close()
+		if err != nil || len(batch) == 0 {
+			return err
+		}
+		for _, r := range batch {
+			seq++
+			hash, err := chainHash(seq, prev, r.Event, r.Changes)
+			if err != nil {
+				return fmt.Errorf("chain audit event %s: %w", r.Event.ID, err)
+			}
+			if _, err := tx.Exec(ctx, `UPDATE audit_events SET seq = $1, prev_hash = $2, hash = $3 WHERE id = $4`, seq, prev, hash, r.Event.ID); err != nil {
+				return err
+			}
+			prev = hash
+		}
+	}
+}
+
+// ensureChained backfills the chain outside of Log.
+func (s *PostgresAuditLogger) ensureChained(ctx context.Context) error {
+	if s.chained.Load() {
+		return nil
+	}
+	tx, err := s.lockChain(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	if err := s.backfillChain(ctx, tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	s.chained.Store(true)
+	return nil
+}
+
+// VerifyChain walks the organization's hash chain across the range in opts.
+func (s *PostgresAuditLogger) VerifyChain(ctx context.Context, opts VerifyOptions) (*VerifyResult, error) {
+	if err := s.ensureChained(ctx); err != nil {
+		return nil, err
+	}
+	from, err := s.chainSeq(ctx, "MIN", ">=", opts.Since)
+	if err != nil {
+		return nil, err
+	}
+	var to int64
+	if opts.Until != nil {
+		if to, err = s.chainSeq(ctx, "MAX", "<=", opts.Until); err != nil {
+			return nil, err
+		}
+	}
+	if from == 0 || (opts.Until != nil && to < from) {
+		return &VerifyResult{Valid: true}, nil
+	}
+	// Read the checkpoints before an open-ended walk fixes its end, so that
+	// every checkpoint is at or before the head the walk reaches.
+	checkpoints, err := s.loadCheckpoints(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Until == nil {
+		if to, err = s.chainSeq(ctx, "MAX", "", nil); err != nil {
+			return nil, err
+		}
+	}
+
+	w := newChainWalker(from, opts.PublicKey, checkpoints)
+	after := from - 2 // include the predecessor that anchors the walk
+	for {
+		rows, err := s.pool.Query(ctx, `
+			SELECT `+postgresEventColumns+` FROM audit_events
+			WHERE organization_id = $1 AND seq > $2 AND seq <= $3
+			UNION ALL
+			SELECT `+postgresLinkColumns+` FROM audit_chain_links
+			WHERE organization_id = $1 AND seq > $2 AND seq <= $3
+			ORDER BY seq LIMIT $4`, s.orgID, after, to, chainBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		batch, err := scanPostgresRecords(rows)
+		rows.Close()
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range batch {
+			if !w.step(r) {
+				return w.finish(), nil
+			}
+		}
+		if len(batch) < chainBatchSize {
+			return w.finish(), nil
+		}
+		after = batch[len(batch)-1].Seq
+	}
+}
+
+// chainSeq aggregates the seqs of the chain positions whose timestamp
+// compares to bound with op; a nil bound aggregates over the whole chain.
+// It returns 0 for an empty chain.
+func (s *PostgresAuditLogger) chainSeq(ctx context.Context, agg, op string, bound *time.Time) (int64, error) {
+	query := `SELECT COALESCE(` + agg + `(seq), 0) FROM ` + postgresChainPositions
+	args := []any{s.orgID}
+	if bound != nil {
+		query += ` WHERE timestamp ` + op + ` $2`
+		args = append(args, *bound)
+	}
+	var seq int64
+	err := s.pool.QueryRow(ctx, query, args...).Scan(&seq)
+	return seq, err
+}
+
+// loadCheckpoints returns the checkpoints from seq from on, up to seq to
+// unless to is 0.
+func (s *PostgresAuditLogger) loadCheckpoints(ctx context.Context, from, to int64) ([]Checkpoint, error) {
+	query := `SELECT seq, hash, created_at, key_id, signature FROM audit_checkpoints
+		WHERE organization_id = $1 AND seq >= $2`
+	args := []any{s.orgID, from}
+	if to > 0 {
+		query += ` AND seq <= $3`
+		args = append(args, to)
+	}
+	rows, err := s.pool.Query(ctx, query+` ORDER BY seq`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var checkpoints []Checkpoint
+	for rows.Next() {
+		var cp Checkpoint
+		if err := rows.Scan(&cp.Seq, &cp.Hash, &cp.CreatedAt, &cp.KeyID, &cp.Signature); err != nil {
+			return nil, err
+		}
+		checkpoints = append(checkpoints, cp)
+	}
+	return checkpoints, rows.Err()
+}
+
+// Checkpoint signs the current head of the organization's chain with key.
+func (s *PostgresAuditLogger) Checkpoint(ctx context.Context, key ed25519.PrivateKey) (*Checkpoint, error) {
+	if err := s.ensureChained(ctx); err != nil {
+		return nil, err
+	}
+	seq, hash, err := s.chainHead(ctx, s.pool)
+	if err != nil || seq == 0 {
+		return nil, err
+	}
+	var last *int64
+	if err := s.pool.QueryRow(ctx, `SELECT MAX(seq) FROM audit_checkpoints WHERE organization_id = $1`, s.orgID).Scan(&last); err != nil {
+		return nil, err
+	}
+	if last != nil && *last == seq {
+		return nil, nil
+	}
+	cp := signCheckpoint(seq, hash, key)
+	// Replicas may race to checkpoint the same head; the first one wins.
+	tag, err := s.pool.Exec(ctx, `
+		INSERT INTO audit_checkpoints (organization_id, seq, hash, created_at, key_id, signature)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (organization_id, seq) DO NOTHING`,
+		s.orgID, cp.Seq, cp.Hash, cp.CreatedAt, cp.KeyID, cp.Signature)
+	if err != nil || tag.RowsAffected() == 0 {
+		return nil, err
+	}
+	return cp, nil
+}
@@ -0,0 +1,71 @@
+package audit
+
+import (
+	"sort"
+	"time"
+)
+
+// AsRetentionEnforcer returns the RetentionEnforcer behind l, looking through
+// any ForwardingAuditLogger wrappers to the logger that persists events.
+func AsRetentionEnforcer(l AuditLogger) (RetentionEnforcer, bool) {
+	return unwrapLogger[RetentionEnforcer](l)
+}
+
+// unwrapLogger returns l, or the logger a chain of ForwardingAuditLogger
+// wrappers around l forwards to, as a T.
+func unwrapLogger[T any](l AuditLogger) (T, bool) {
+	for l != nil {
+		if t, ok := l.(T); ok {
+			return t, true
+		}
+		f, ok := l.(*ForwardingAuditLogger)
+		if !ok {
+			break
+		}
+		l = f.primary
+	}
+	var zero T
+	return zero, false
+}
+
+// retentionCutoffs returns the times before which every event, and every
+// successful event, is deleted under policy. A zero time means no cutoff.
+func retentionCutoffs(policy AuditRetentionPolicy, now time.Time) (all, successful time.Time) {
+	if policy.MaxAge > 0 {
+		all = now.Add(-policy.MaxAge)
+	}
+	if !policy.RetainSuccessful && policy.SuccessfulMaxAge > 0 {
+		successful = now.Add(-policy.SuccessfulMaxAge)
+	}
+	return all, successful
+}
+
+// expired reports whether e falls before the cutoffs from retentionCutoffs.
+func expired(e *AuditEvent, all, successful time.Time) bool {
+	if !all.IsZero() && e.Timestamp.Before(all) {
+		return true
+	}
+	return !successful.IsZero() && e.StatusCode < 400 && e.Timestamp.Before(successful)
+}
+
+// topCounts keeps the n largest entries of counts, breaking ties by key.
+func topCounts(counts map[string]int64, n int) map[string]int64 {
+	if len(counts) <= n {
+		return counts
+	}
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	top := make(map[string]int64, n)
+	for _, k := range keys[:n] {
+		top[k] = counts[k]
+	}
+	return top
+}
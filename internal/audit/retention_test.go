@@ -0,0 +1,118 @@
+package audit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func seedRetentionEvents(t *testing.T, l AuditLogger, now time.Time) {
+	t.Helper()
+	events := []*AuditEvent{
+		{ID: "old-ok", Timestamp: now.Add(-100 * 24 * time.Hour), Actor: "alice", Action: ActionCreate, ResourceType: ResourcePool, StatusCode: 201},
+		{ID: "mid-ok", Timestamp: now.Add(-20 * 24 * time.Hour), Actor: "alice", Action: ActionUpdate, ResourceType: ResourcePool, StatusCode: 200},
+		{ID: "mid-fail", Timestamp: now.Add(-20 * 24 * time.Hour), Actor: "bob", Action: ActionDelete, ResourceType: ResourceAccount, StatusCode: 403},
+		{ID: "new-ok", Timestamp: now.Add(-time.Hour), Actor: "alice", Action: ActionCreate, ResourceType: ResourceAccount, StatusCode: 201},
+	}
+	for _, e := range events {
+		if err := l.Log(context.Background(), e); err != nil {
+			t.Fatalf("Log %s: %v", e.ID, err)
+		}
+	}
+}
+
+func remainingIDs(t *testing.T, l AuditLogger) map[string]bool {
+	t.Helper()
+	events, _, err := l.List(context.Background(), ListOptions{Limit: 100})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	ids := map[string]bool{}
+	for _, e := range events {
+		ids[e.ID] = true
+	}
+	return ids
+}
+
+func TestMemoryAuditLogger_EnforcePolicy(t *testing.T) {
+	now := time.Now().UTC()
+	day := 24 * time.Hour
+	tests := []struct {
+		name   string
+		policy AuditRetentionPolicy
+		keep   []string
+	}{
+		{"max age", AuditRetentionPolicy{MaxAge: 90 * day, RetainSuccessful: true}, []string{"mid-ok", "mid-fail", "new-ok"}},
+		{"successful max age", AuditRetentionPolicy{MaxAge: 90 * day, SuccessfulMaxAge: 7 * day}, []string{"mid-fail", "new-ok"}},
+		{"max events", AuditRetentionPolicy{MaxEvents: 2, RetainSuccessful: true}, []string{"mid-fail", "new-ok"}},
+		{"no limits", AuditRetentionPolicy{RetainSuccessful: true}, []string{"old-ok", "mid-ok", "mid-fail", "new-ok"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			l := NewMemoryAuditLogger()
+			seedRetentionEvents(t, l, now)
+			deleted, err := l.EnforcePolicy(context.Background(), tc.policy)
+			if err != nil {
+				t.Fatalf("EnforcePolicy: %v", err)
+			}
+			if want := int64(4 - len(tc.keep)); deleted != want {
+				t.Errorf("deleted = %d, want %d", deleted, want)
+			}
+			ids := remainingIDs(t, l)
+			if len(ids) != len(tc.keep) {
+				t.Errorf("remaining = %v, want %v", ids, tc.keep)
+			}
+			for _, id := range tc.keep {
+				if !ids[id] {
+					t.Errorf("event %s was deleted", id)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryAuditLogger_GetStats(t *testing.T) {
+	now := time.Now().UTC()
+	l := NewMemoryAuditLogger()
+	seedRetentionEvents(t, l, now)
+
+	stats, err := l.GetStats(context.Background())
+	if err != nil {
+		t.Fatalf("GetStats: %v", err)
+	}
+	if stats.TotalEvents != 4 || stats.SuccessCount != 3 || stats.FailureCount != 1 {
+		t.Errorf("counts = %+v", stats)
+	}
+	if stats.EventsByAction[ActionCreate] != 2 || stats.EventsByResource[ResourceAccount] != 2 || stats.EventsByActor["alice"] != 3 {
+		t.Errorf("breakdowns = %v %v %v", stats.EventsByAction, stats.EventsByResource, stats.EventsByActor)
+	}
+	if stats.OldestEvent == nil || !stats.OldestEvent.Equal(now.Add(-100*24*time.Hour)) {
+		t.Errorf("oldest = %v", stats.OldestEvent)
+	}
+	if stats.NewestEvent == nil || !stats.NewestEvent.Equal(now.Add(-time.Hour)) {
+		t.Errorf("newest = %v", stats.NewestEvent)
+	}
+
+	empty, _ := NewMemoryAuditLogger().GetStats(context.Background())
+	if empty.TotalEvents != 0 || empty.OldestEvent != nil {
+		t.Errorf("empty stats = %+v", empty)
+	}
+}
+
+func TestTopCounts(t *testing.T) {
+	got := topCounts(map[string]int64{"a": 1, "b": 3, "c": 3, "d": 2}, 2)
+	if len(got) != 2 || got["b"] != 3 || got["c"] != 3 {
+		t.Errorf("topCounts = %v", got)
+	}
+}
+
+func TestAsRetentionEnforcer(t *testing.T) {
+	mem := NewMemoryAuditLogger()
+	wrapped := NewForwardingAuditLogger(NewForwardingAuditLogger(mem, nil, nil), nil, nil)
+	if r, ok := AsRetentionEnforcer(wrapped); !ok || r != RetentionEnforcer(mem) {
+		t.Errorf("AsRetentionEnforcer(wrapped) = %v, %v", r, ok)
+	}
+	if _, ok := AsRetentionEnforcer(NewForwardingAuditLogger(nil, nil, nil)); ok {
+		t.Error("AsRetentionEnforcer found an enforcer behind a nil primary")
+	}
+}
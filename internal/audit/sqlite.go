@@ -4,8 +4,11 @@ package audit
 
 import (
 	"context"
+	"crypto/ed25519"
 	"database/sql"
 	"encoding/json"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,6 +21,31 @@ type SQLiteAuditLogger struct {
 	// ownDB is true when this logger opened db itself and is therefore
 	// responsible for closing it.
 	ownDB bool
+
+	// chainMu serializes writers of the hash chain, so that every event is
+	// chained to the head it read. chained records that events predating
+	// the chain have been backfilled.
+	chainMu sync.Mutex
+	chained bool
+}
+
+// sqliteEventColumns lists the audit_logs columns read by scanSQLiteRecord.
+const sqliteEventColumns = `id, timestamp, actor, actor_type, action, resource_type, resource_id, resource_name, changes, request_id, ip_address, status_code, seq, prev_hash, hash`
+
+// sqliteLinkColumns reads an audit_chain_links row in the shape of
+// sqliteEventColumns.
+const sqliteLinkColumns = `NULL, timestamp, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, seq, prev_hash, hash`
+
+// sqliteChainPositions lists every position in the chain: chained events
+// and the links of pruned ones.
+const sqliteChainPositions = `(SELECT seq, timestamp FROM audit_logs WHERE seq IS NOT NULL
+	UNION ALL SELECT seq, timestamp FROM audit_chain_links)`
+
+// sqliteQuerier is satisfied by *sql.DB and *sql.Tx.
+type sqliteQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
 }
 
 // NewSQLiteAuditLogger creates a new SQLite-backed audit logger.
@@ -49,7 +77,8 @@ func (s *SQLiteAuditLogger) Close() error {
 	return s.db.Close()
 }
 
-// Log records an audit event to the database.
+// Log records an audit event to the database, chained to the current head
+// of the hash chain.
 func (s *SQLiteAuditLogger) Log(ctx context.Context, event *AuditEvent) error {
 	if event == nil {
 		return nil
@@ -62,19 +91,33 @@ func (s *SQLiteAuditLogger) Log(ctx context.Context, event *AuditEvent) error {
 	if event.Timestamp.IsZero() {
 		event.Timestamp = time.Now().UTC()
 	}
+	changes := changesJSON(event.Changes)
 
-	// Serialize changes to JSON
-	var changesJSON sql.NullString
-	if event.Changes != nil {
-		data, err := json.Marshal(event.Changes)
-		if err == nil {
-			changesJSON = sql.NullString{String: string(data), Valid: true}
-		}
+	s.chainMu.Lock()
+	defer s.chainMu.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := s.backfillChain(ctx, tx); err != nil {
+		return err
+	}
+	seq, prev, err := sqliteChainHead(ctx, tx)
+	if err != nil {
+		return err
 	}
+	hash, err := chainHash(seq+1, prev, event, changes)
+	if err != nil {
+		return err
+	}
+	event.Seq, event.PrevHash, event.Hash = seq+1, prev, hash
 
-	_, err := s.db.ExecContext(ctx, `
-		INSERT INTO audit_logs (id, timestamp, actor, actor_type, action, resource_type, resource_id, resource_name, changes, request_id, ip_address, status_code)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO audit_logs (id, timestamp, actor, actor_type, action, resource_type, resource_id, resource_name, changes, request_id, ip_address, status_code, seq, prev_hash, hash)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		event.ID,
 		event.Timestamp.Format(time.RFC3339Nano),
@@ -84,17 +127,58 @@ func (s *SQLiteAuditLogger) Log(ctx context.Context, event *AuditEvent) error {
 		event.ResourceType,
 		event.ResourceID,
 		sql.NullString{String: event.ResourceName, Valid: event.ResourceName != ""},
-		changesJSON,
+		sql.NullString{String: string(changes), Valid: changes != nil},
 		sql.NullString{String: event.RequestID, Valid: event.RequestID != ""},
 		sql.NullString{String: event.IPAddress, Valid: event.IPAddress != ""},
 		event.StatusCode,
-	)
-	return err
+		event.Seq,
+		event.PrevHash,
+		event.Hash,
+	); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	s.chained = true
+	return nil
 }
 
 // List retrieves audit events with optional filtering.
 func (s *SQLiteAuditLogger) List(ctx context.Context, opts ListOptions) ([]*AuditEvent, int, error) {
-	// Build WHERE clause
+	where, args := sqliteListWhere(opts)
+
+	// Count total
+	var total int
+	countQuery := "SELECT COUNT(*) FROM audit_logs WHERE " + where
+	if err := s.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	// Apply pagination
+	if opts.Limit <= 0 {
+		opts.Limit = 50
+	}
+	if opts.Limit > 1000 {
+		opts.Limit = 1000
+	}
+
+	query := "SELECT " + sqliteEventColumns + " FROM audit_logs WHERE " + where + " ORDER BY timestamp DESC LIMIT ? OFFSET ?"
+	args = append(args, opts.Limit, opts.Offset)
+
+	records, err := querySQLiteRecords(ctx, s.db, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	var events []*AuditEvent
+	for _, r := range records {
+		events = append(events, r.Event)
+	}
+	return events, total, nil
+}
+
+// sqliteListWhere builds the WHERE clause for the filters in opts.
+func sqliteListWhere(opts ListOptions) (string, []any) {
 	where := "1=1"
 	args := []any{}
 
@@ -122,57 +206,86 @@ func (s *SQLiteAuditLogger) List(ctx context.Context, opts ListOptions) ([]*Audi
 		where += " AND timestamp <= ?"
 		args = append(args, opts.Until.Format(time.RFC3339Nano))
 	}
+	return where, args
+}
 
-	// Count total
-	var total int
-	countQuery := "SELECT COUNT(*) FROM audit_logs WHERE " + where
-	if err := s.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
-		return nil, 0, err
-	}
-
-	// Apply pagination
-	if opts.Limit <= 0 {
-		opts.Limit = 50
+// Stream calls fn for each event matching opts, oldest first. Events are
+// read streamBatchSize at a time by seq, so no read is held open while fn
+// runs.
+func (s *SQLiteAuditLogger) Stream(ctx context.Context, opts ListOptions, fn func(*AuditEvent) error) error {
+	if err := s.ensureChained(ctx); err != nil {
+		return err
 	}
-	if opts.Limit > 1000 {
-		opts.Limit = 1000
+	where, args := sqliteListWhere(opts)
+	query := "SELECT " + sqliteEventColumns + " FROM audit_logs WHERE " + where + " AND seq > ? ORDER BY seq LIMIT ?"
+	var after int64
+	for {
+		batch, err := querySQLiteRecords(ctx, s.db, query, append(args, after, streamBatchSize)...)
+		if err != nil {
+			return err
+		}
+		for _, r := range batch {
+			if err := fn(r.Event); err != nil {
+				return err
+			}
+		}
+		if len(batch) < streamBatchSize {
+			return nil
+		}
+		after = batch[len(batch)-1].Seq
 	}
+}
 
-	query := "SELECT id, timestamp, actor, actor_type, action, resource_type, resource_id, resource_name, changes, request_id, ip_address, status_code FROM audit_logs WHERE " + where + " ORDER BY timestamp DESC LIMIT ? OFFSET ?"
-	args = append(args, opts.Limit, opts.Offset)
-
-	rows, err := s.db.QueryContext(ctx, query, args...)
+// querySQLiteRecords runs a query selecting sqliteEventColumns, or
+// sqliteLinkColumns for pruned links.
+func querySQLiteRecords(ctx context.Context, q sqliteQuerier, query string, args ...any) ([]chainRecord, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
 	}
 	defer rows.Close()
 
-	var events []*AuditEvent
+	var records []chainRecord
 	for rows.Next() {
-		var e AuditEvent
+		var r chainRecord
 		var timestamp string
-		var resourceName, changesJSON, requestID, ipAddress sql.NullString
+		var id, actor, actorType, action, resourceType, resourceID, resourceName, changesJSON, requestID, ipAddress, prevHash, hash sql.NullString
+		var statusCode, seq sql.NullInt64
 
-		if err := rows.Scan(&e.ID, &timestamp, &e.Actor, &e.ActorType, &e.Action, &e.ResourceType, &e.ResourceID, &resourceName, &changesJSON, &requestID, &ipAddress, &e.StatusCode); err != nil {
-			return nil, 0, err
+		if err := rows.Scan(&id, &timestamp, &actor, &actorType, &action, &resourceType, &resourceID, &resourceName, &changesJSON, &requestID, &ipAddress, &statusCode, &seq, &prevHash, &hash); err != nil {
+			return nil, err
 		}
-
-		e.Timestamp, _ = time.Parse(time.RFC3339Nano, timestamp)
-		e.ResourceName = resourceName.String
-		e.RequestID = requestID.String
-		e.IPAddress = ipAddress.String
-
-		if changesJSON.Valid && changesJSON.String != "" {
-			var changes Changes
-			if err := json.Unmarshal([]byte(changesJSON.String), &changes); err == nil {
-				e.Changes = &changes
+		r.Timestamp, _ = time.Parse(time.RFC3339Nano, timestamp)
+		r.Seq, r.PrevHash, r.Hash = seq.Int64, prevHash.String, hash.String
+		if id.Valid {
+			e := &AuditEvent{
+				ID:           id.String,
+				Timestamp:    r.Timestamp,
+				Actor:        actor.String,
+				ActorType:    actorType.String,
+				Action:       action.String,
+				ResourceType: resourceType.String,
+				ResourceID:   resourceID.String,
+				ResourceName: resourceName.String,
+				RequestID:    requestID.String,
+				IPAddress:    ipAddress.String,
+				StatusCode:   int(statusCode.Int64),
+				Seq:          r.Seq,
+				PrevHash:     r.PrevHash,
+				Hash:         r.Hash,
+			}
+			if changesJSON.Valid && changesJSON.String != "" {
+				r.Changes = []byte(changesJSON.String)
+				var changes Changes
+				if err := json.Unmarshal(r.Changes, &changes); err == nil {
+					e.Changes = &changes
+				}
 			}
+			r.Event = e
 		}
-
-		events = append(events, &e)
+		records = append(records, r)
 	}
-
-	return events, total, rows.Err()
+	return records, rows.Err()
 }
 
 // GetByResource retrieves audit events for a specific resource.
@@ -190,3 +303,367 @@ func (s *SQLiteAuditLogger) GetByResource(ctx context.Context, resourceType, res
 	}
 	return events, nil
 }
+
+// retentionBatchSize bounds each DELETE issued by EnforcePolicy, so a large
+// backlog is removed without holding the write lock for the whole sweep.
+const retentionBatchSize = 5000
+
+// EnforcePolicy deletes the events that policy no longer retains, leaving
+// the link of each chained event behind while a retained event follows it.
+func (s *SQLiteAuditLogger) EnforcePolicy(ctx context.Context, policy AuditRetentionPolicy) (int64, error) {
+	deleted, err := s.enforcePolicy(ctx, policy)
+	if deleted > 0 && err == nil {
+		err = s.pruneLinks(ctx)
+	}
+	return deleted, err
+}
+
+func (s *SQLiteAuditLogger) enforcePolicy(ctx context.Context, policy AuditRetentionPolicy) (int64, error) {
+	all, successful := retentionCutoffs(policy, time.Now().UTC())
+	var deleted int64
+	if !all.IsZero() {
+		n, err := s.deleteBatches(ctx, `timestamp < ?`, all.Format(time.RFC3339Nano))
+		deleted += n
+		if err != nil {
+			return deleted, err
+		}
+	}
+	if !successful.IsZero() {
+		n, err := s.deleteBatches(ctx, `status_code < 400 AND timestamp < ?`, successful.Format(time.RFC3339Nano))
+		deleted += n
+		if err != nil {
+			return deleted, err
+		}
+	}
+	if policy.MaxEvents > 0 {
+		// The first event past the newest MaxEvents, and everything older,
+		// goes. id breaks timestamp ties so the count is exact.
+		var ts, id string
+		err := s.db.QueryRowContext(ctx, `SELECT timestamp, id FROM audit_logs ORDER BY timestamp DESC, id DESC LIMIT 1 OFFSET ?`, policy.MaxEvents).Scan(&ts, &id)
+		if err == sql.ErrNoRows {
+			return deleted, nil
+		}
+		if err != nil {
+			return deleted, err
+		}
+		n, err := s.deleteBatches(ctx, `(timestamp, id) <= (?, ?)`, ts, id)
+		deleted += n
+		if err != nil {
+			return deleted, err
+		}
+	}
+	return deleted, nil
+}
+
+// deleteBatches deletes the events matching where, retentionBatchSize rows
+// at a time.
+func (s *SQLiteAuditLogger) deleteBatches(ctx context.Context, where string, args ...any) (int64, error) {
+	batch := `SELECT id FROM audit_logs WHERE ` + where + ` LIMIT ?`
+	args = append(args, retentionBatchSize)
+	var deleted int64
+	for {
+		n, err := s.deleteBatch(ctx, batch, args)
+		deleted += n
+		if err != nil || n < retentionBatchSize {
+			return deleted, err
+		}
+	}
+}
+
+// deleteBatch deletes the events selected by batch, moving the chain link
+// of each into audit_chain_links. Both statements select the same rows:
+// nothing else writes audit_logs inside the transaction.
+func (s *SQLiteAuditLogger) deleteBatch(ctx context.Context, batch string, args []any) (int64, error) {
+	s.chainMu.Lock()
+	defer s.chainMu.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO audit_chain_links (seq, prev_hash, hash, timestamp)
+		SELECT seq, prev_hash, hash, timestamp FROM audit_logs
+		WHERE seq IS NOT NULL AND id IN (`+batch+`)`, args...); err != nil {
+		return 0, err
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM audit_logs WHERE id IN (`+batch+`)`, args...)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return n, tx.Commit()
+}
+
+// pruneLinks drops the links and checkpoints that precede every retained
+// event, keeping the newest link when no event is left so that the chain
+// head survives.
+func (s *SQLiteAuditLogger) pruneLinks(ctx context.Context) error {
+	var bound sql.NullInt64
+	if err := s.db.QueryRowContext(ctx, `
+		SELECT COALESCE((SELECT MIN(seq) FROM audit_logs), (SELECT MAX(seq) FROM audit_chain_links))`).Scan(&bound); err != nil {
+		return err
+	}
+	if !bound.Valid {
+		return nil
+	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM audit_chain_links WHERE seq < ?`, bound.Int64); err != nil {
+		return err
+	}
+	_, err := s.db.ExecContext(ctx, `DELETE FROM audit_checkpoints WHERE seq < ?`, bound.Int64)
+	return err
+}
+
+// GetStats returns statistics about the stored events.
+func (s *SQLiteAuditLogger) GetStats(ctx context.Context) (*AuditStats, error) {
+	stats := &AuditStats{}
+	var oldest, newest sql.NullString
+	if err := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*),
+			COALESCE(SUM(CASE WHEN status_code < 400 THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN status_code >= 400 THEN 1 ELSE 0 END), 0),
+			MIN(timestamp), MAX(timestamp)
+		FROM audit_logs`).Scan(&stats.TotalEvents, &stats.SuccessCount, &stats.FailureCount, &oldest, &newest); err != nil {
+		return nil, err
+	}
+	stats.OldestEvent = parseNullTimestamp(oldest)
+	stats.NewestEvent = parseNullTimestamp(newest)
+
+	var err error
+	if stats.EventsByAction, err = s.countBy(ctx, `SELECT action, COUNT(*) FROM audit_logs GROUP BY action`); err != nil {
+		return nil, err
+	}
+	if stats.EventsByResource, err = s.countBy(ctx, `SELECT resource_type, COUNT(*) FROM audit_logs GROUP BY resource_type`); err != nil {
+		return nil, err
+	}
+	if stats.EventsByActor, err = s.countBy(ctx, `SELECT actor, COUNT(*) AS n FROM audit_logs GROUP BY actor ORDER BY n DESC, actor LIMIT ?`, StatsTopActors); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// countBy runs a two-column key, count query.
+func (s *SQLiteAuditLogger) countBy(ctx context.Context, query string, args ...any) (map[string]int64, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	counts := map[string]int64{}
+	for rows.Next() {
+		var key sql.NullString
+		var n int64
+		if err := rows.Scan(&key, &n); err != nil {
+			return nil, err
+		}
+		counts[key.String] = n
+	}
+	return counts, rows.Err()
+}
+
+// parseNullTimestamp parses a stored timestamp, returning nil when it is
+// NULL or malformed.
+func parseNullTimestamp(v sql.NullString) *time.Time {
+	if !v.Valid {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, v.String)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+// sqliteChainHead returns the seq and hash of the newest position in the
+// chain, or zero values for an empty chain.
+func sqliteChainHead(ctx context.Context, q sqliteQuerier) (int64, string, error) {
+	var seq int64
+	var hash string
+	err := q.QueryRowContext(ctx, `
+		SELECT seq, hash FROM audit_logs WHERE seq IS NOT NULL
+		UNION ALL
+		SELECT seq, hash FROM audit_chain_links
+		ORDER BY seq DESC LIMIT 1`).Scan(&seq, &hash)
+	if err == sql.ErrNoRows {
+		return 0, "", nil
+	}
+	return seq, hash, err
+}
+
+// backfillChain chains the events recorded before the hash chain existed,
+// oldest first. They precede every chained event, so this must run before
+// the first event is chained. The caller must hold s.chainMu.
+func (s *SQLiteAuditLogger) backfillChain(ctx context.Context, tx *sql.Tx) error {
+	if s.chained {
+		return nil
+	}
+	seq, prev, err := sqliteChainHead(ctx, tx)
+	if err != nil {
+		return err
+	}
+	for {
+		batch, err := querySQLiteRecords(ctx, tx, `SELECT `+sqliteEventColumns+` FROM audit_logs WHERE seq IS NULL ORDER BY timestamp, id LIMIT ?`, chainBatchSize)
+		if err != nil || len(batch) == 0 {
+			return err
+		}
+		for _, r := range batch {
+			seq++
+			hash, err := chainHash(seq, prev, r.Event, r.Changes)
+			if err != nil {
+				return fmt.Errorf("chain audit event %s: %w", r.Event.ID, err)
+			}
+			if _, err := tx.ExecContext(ctx, `UPDATE audit_logs SET seq = ?, prev_hash = ?, hash = ? WHERE id = ?`, seq, prev, hash, r.Event.ID); err != nil {
+				return err
+			}
+			prev = hash
+		}
+	}
+}
+
+// ensureChained backfills the chain outside of Log.
+func (s *SQLiteAuditLogger) ensureChained(ctx context.Context) error {
+	s.chainMu.Lock()
+	defer s.chainMu.Unlock()
+	if s.chained {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	if err := s.backfillChain(ctx, tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	s.chained = true
+	return nil
+}
+
+// VerifyChain walks the hash chain across the range in opts.
+func (s *SQLiteAuditLogger) VerifyChain(ctx context.Context, opts VerifyOptions) (*VerifyResult, error) {
+	if err := s.ensureChained(ctx); err != nil {
+		return nil, err
+	}
+	from, err := s.chainSeq(ctx, "MIN", ">=", opts.Since)
+	if err != nil {
+		return nil, err
+	}
+	var to int64
+	if opts.Until != nil {
+		if to, err = s.chainSeq(ctx, "MAX", "<=", opts.Until); err != nil {
+			return nil, err
+		}
+	}
+	if from == 0 || (opts.Until != nil && to < from) {
+		return &VerifyResult{Valid: true}, nil
+	}
+	// Read the checkpoints before an open-ended walk fixes its end, so that
+	// every checkpoint is at or before the head the walk reaches.
+	checkpoints, err := s.loadCheckpoints(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Until == nil {
+		if to, err = s.chainSeq(ctx, "MAX", "", nil); err != nil {
+			return nil, err
+		}
+	}
+
+	w := newChainWalker(from, opts.PublicKey, checkpoints)
+	after := from - 2 // include the predecessor that anchors the walk
+	for {
+		batch, err := querySQLiteRecords(ctx, s.db, `
+			SELECT `+sqliteEventColumns+` FROM audit_logs WHERE seq > ? AND seq <= ?
+			UNION ALL
+			SELECT `+sqliteLinkColumns+` FROM audit_chain_links WHERE seq > ? AND seq <= ?
+			ORDER BY seq LIMIT ?`, after, to, after, to, chainBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range batch {
+			if !w.step(r) {
+				return w.finish(), nil
+			}
+		}
+		if len(batch) < chainBatchSize {
+			return w.finish(), nil
+		}
+		after = batch[len(batch)-1].Seq
+	}
+}
+
+// chainSeq aggregates the seqs of the chain positions whose timestamp
+// compares to bound with op; a nil bound aggregates over the whole chain.
+// It returns 0 for an empty chain.
+func (s *SQLiteAuditLogger) chainSeq(ctx context.Context, agg, op string, bound *time.Time) (int64, error) {
+	query := `SELECT COALESCE(` + agg + `(seq), 0) FROM ` + sqliteChainPositions
+	var args []any
+	if bound != nil {
+		query += ` WHERE timestamp ` + op + ` ?`
+		args = append(args, bound.UTC().Format(time.RFC3339Nano))
+	}
+	var seq int64
+	err := s.db.QueryRowContext(ctx, query, args...).Scan(&seq)
+	return seq, err
+}
+
+// loadCheckpoints returns the checkpoints from seq from on, up to seq to
+// unless to is 0.
+func (s *SQLiteAuditLogger) loadCheckpoints(ctx context.Context, from, to int64) ([]Checkpoint, error) {
+	query := `SELECT seq, hash, created_at, key_id, signature FROM audit_checkpoints WHERE seq >= ?`
+	args := []any{from}
+	if to > 0 {
+		query += ` AND seq <= ?`
+		args = append(args, to)
+	}
+	rows, err := s.db.QueryContext(ctx, query+` ORDER BY seq`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var checkpoints []Checkpoint
+	for rows.Next() {
+		var cp Checkpoint
+		var createdAt string
+		if err := rows.Scan(&cp.Seq, &cp.Hash, &createdAt, &cp.KeyID, &cp.Signature); err != nil {
+			return nil, err
+		}
+		cp.CreatedAt, _ = time.Parse(time.RFC3339Nano, createdAt)
+		checkpoints = append(checkpoints, cp)
+	}
+	return checkpoints, rows.Err()
+}
+
+// Checkpoint signs the current chain head with key.
+func (s *SQLiteAuditLogger) Checkpoint(ctx context.Context, key ed25519.PrivateKey) (*Checkpoint, error) {
+	if err := s.ensureChained(ctx); err != nil {
+		return nil, err
+	}
+	seq, hash, err := sqliteChainHead(ctx, s.db)
+	if err != nil || seq == 0 {
+		return nil, err
+	}
+	var last sql.NullInt64
+	if err := s.db.QueryRowContext(ctx, `SELECT MAX(seq) FROM audit_checkpoints`).Scan(&last); err != nil {
+		return nil, err
+	}
+	if last.Int64 == seq {
+		return nil, nil
+	}
+	cp := signCheckpoint(seq, hash, key)
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO audit_checkpoints (seq, hash, created_at, key_id, signature)
+		VALUES (?, ?, ?, ?, ?)`,
+		cp.Seq, cp.Hash, cp.CreatedAt.Format(time.RFC3339Nano), cp.KeyID, cp.Signature); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
@@ -4,6 +4,7 @@ package audit
 
 import (
 	"context"
+	"crypto/ed25519"
 	"database/sql"
 	"path/filepath"
 	"testing"
@@ -34,10 +35,30 @@ func newAuditTestDB(t *testing.T) *sql.DB {
 		changes TEXT,
 		request_id TEXT,
 		ip_address TEXT,
-		status_code INTEGER
+		status_code INTEGER,
+		seq INTEGER UNIQUE,
+		prev_hash TEXT,
+		hash TEXT
 	)`); err != nil {
 		t.Fatalf("create audit_logs: %v", err)
 	}
+	if _, err := db.Exec(`CREATE TABLE audit_chain_links (
+		seq INTEGER PRIMARY KEY,
+		prev_hash TEXT NOT NULL,
+		hash TEXT NOT NULL,
+		timestamp TEXT NOT NULL
+	)`); err != nil {
+		t.Fatalf("create audit_chain_links: %v", err)
+	}
+	if _, err := db.Exec(`CREATE TABLE audit_checkpoints (
+		seq INTEGER PRIMARY KEY,
+		hash TEXT NOT NULL,
+		created_at TEXT NOT NULL,
+		key_id TEXT NOT NULL,
+		signature TEXT NOT NULL
+	)`); err != nil {
+		t.Fatalf("create audit_checkpoints: %v", err)
+	}
 	return db
 }
 
@@ -169,3 +190,131 @@ func TestSQLiteListFiltersByResourceID(t *testing.T) {
 		}
 	}
 }
+
+func TestSQLiteAuditLoggerRetentionAndStats(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	day := 24 * time.Hour
+	logger := NewSQLiteAuditLoggerFromDB(newAuditTestDB(t))
+	seedRetentionEvents(t, logger, now)
+
+	stats, err := logger.GetStats(ctx)
+	if err != nil {
+		t.Fatalf("GetStats: %v", err)
+	}
+	if stats.TotalEvents != 4 || stats.SuccessCount != 3 || stats.FailureCount != 1 ||
+		stats.EventsByActor["alice"] != 3 || stats.EventsByAction[ActionDelete] != 1 || stats.EventsByResource[ResourcePool] != 2 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if stats.OldestEvent == nil || !stats.OldestEvent.Equal(now.Add(-100*day)) {
+		t.Errorf("oldest = %v", stats.OldestEvent)
+	}
+
+	deleted, err := logger.EnforcePolicy(ctx, AuditRetentionPolicy{MaxAge: 90 * day, SuccessfulMaxAge: 7 * day})
+	if err != nil || deleted != 2 {
+		t.Fatalf("EnforcePolicy = %d, %v; want 2 deleted", deleted, err)
+	}
+	deleted, err = logger.EnforcePolicy(ctx, AuditRetentionPolicy{MaxEvents: 1, RetainSuccessful: true})
+	if err != nil || deleted != 1 {
+		t.Fatalf("EnforcePolicy max events = %d, %v; want 1 deleted", deleted, err)
+	}
+	if ids := remainingIDs(t, logger); len(ids) != 1 || !ids["new-ok"] {
+		t.Errorf("remaining = %v, want only new-ok", ids)
+	}
+}
+
+func TestSQLiteAuditLoggerHashChain(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	db := newAuditTestDB(t)
+
+	// An event recorded before the chain existed is chained on first use.
+	if _, err := db.Exec(`INSERT INTO audit_logs (id, timestamp, actor, actor_type, action, resource_type, resource_id, status_code)
+		VALUES ('legacy', ?, 'root', 'user', 'create', 'pool', '1', 201)`, now.Add(-200*24*time.Hour).Format(time.RFC3339Nano)); err != nil {
+		t.Fatalf("insert legacy event: %v", err)
+	}
+	logger := NewSQLiteAuditLoggerFromDB(db)
+	seedRetentionEvents(t, logger, now)
+
+	res, err := logger.VerifyChain(ctx, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("VerifyChain: %v", err)
+	}
+	if !res.Valid || res.EventsChecked != 5 || res.LastSeq != 5 {
+		t.Fatalf("VerifyChain = %+v", res)
+	}
+
+	key := testSigningKey(t)
+	if cp, err := logger.Checkpoint(ctx, key); err != nil || cp == nil || cp.Seq != 5 {
+		t.Fatalf("Checkpoint = %+v, %v", cp, err)
+	}
+
+	// Retention leaves links behind, so the chain still verifies.
+	if _, err := logger.EnforcePolicy(ctx, AuditRetentionPolicy{MaxAge: 90 * 24 * time.Hour, RetainSuccessful: true}); err != nil {
+		t.Fatalf("EnforcePolicy: %v", err)
+	}
+	pub := key.Public().(ed25519.PublicKey)
+	res, err = logger.VerifyChain(ctx, VerifyOptions{PublicKey: pub})
+	if err != nil {
+		t.Fatalf("VerifyChain after retention: %v", err)
+	}
+	if !res.Valid || res.EventsChecked != 3 || res.CheckpointsChecked != 1 || res.CheckpointsUnverified != 0 {
+		t.Fatalf("VerifyChain after retention = %+v", res)
+	}
+
+	if _, err := db.Exec(`UPDATE audit_logs SET actor = 'mallory' WHERE id = 'mid-fail'`); err != nil {
+		t.Fatalf("tamper: %v", err)
+	}
+	res, err = logger.VerifyChain(ctx, VerifyOptions{})
+	if err != nil {
+		t.Fatalf("VerifyChain after tampering: %v", err)
+	}
+	if res.Valid || res.Broken.EventID != "mid-fail" || res.Broken.Reason != BrokenHashMismatch {
+		t.Errorf("broken = %+v", res.Broken)
+	}
+}
+
+func TestSQLiteAuditLoggerStream(t *testing.T) {
+	ctx := context.Background()
+	logger := NewSQLiteAuditLoggerFromDB(newAuditTestDB(t))
+	// More than one batch, so the seq keyset carries over between reads.
+	for i := 0; i < streamBatchSize+5; i++ {
+		action := ActionUpdate
+		if i%2 == 0 {
+			action = ActionCreate
+		}
+		if err := logger.Log(ctx, &AuditEvent{Actor: "alice", Action: action, ResourceType: ResourcePool, StatusCode: 200}); err != nil {
+			t.Fatalf("Log: %v", err)
+		}
+	}
+
+	var seqs []int64
+	if err := logger.Stream(ctx, ListOptions{}, func(e *AuditEvent) error {
+		seqs = append(seqs, e.Seq)
+		return nil
+	}); err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	if len(seqs) != streamBatchSize+5 {
+		t.Fatalf("streamed %d events, want %d", len(seqs), streamBatchSize+5)
+	}
+	for i, seq := range seqs {
+		if seq != int64(i+1) {
+			t.Fatalf("event %d has seq %d, want %d", i, seq, i+1)
+		}
+	}
+
+	var creates int
+	if err := logger.Stream(ctx, ListOptions{Action: ActionCreate}, func(e *AuditEvent) error {
+		if e.Action != ActionCreate {
+			t.Errorf("event %d has action %s", e.Seq, e.Action)
+		}
+		creates++
+		return nil
+	}); err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	if want := (streamBatchSize + 6) / 2; creates != want {
+		t.Errorf("streamed %d create events, want %d", creates, want)
+	}
+}
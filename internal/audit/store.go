@@ -163,7 +163,8 @@ func (o QueryOptions) WithOrdering(orderBy string, desc bool) QueryOptions {
 	return o
 }
 
-// AuditRetentionPolicy defines retention settings for audit logs.
+// AuditRetentionPolicy defines retention settings for audit logs. A zero
+// limit is not enforced.
 type AuditRetentionPolicy struct {
 	// MaxAge is the maximum age of audit events to retain.
 	// Events older than this will be deleted.
@@ -178,7 +179,7 @@ type AuditRetentionPolicy struct {
 	RetainSuccessful bool
 
 	// SuccessfulMaxAge is the retention period for successful operations
-	// when RetainSuccessful is true.
+	// (status code below 400) when RetainSuccessful is false.
 	SuccessfulMaxAge time.Duration
 }
 
@@ -203,31 +204,34 @@ type RetentionEnforcer interface {
 	GetStats(ctx context.Context) (*AuditStats, error)
 }
 
+// StatsTopActors is how many actors AuditStats.EventsByActor lists.
+const StatsTopActors = 20
+
 // AuditStats contains statistics about the audit log.
 type AuditStats struct {
 	// TotalEvents is the total number of audit events.
-	TotalEvents int64
+	TotalEvents int64 `json:"total_events"`
 
 	// EventsByAction breaks down events by action type.
-	EventsByAction map[string]int64
+	EventsByAction map[string]int64 `json:"events_by_action"`
 
 	// EventsByResource breaks down events by resource type.
-	EventsByResource map[string]int64
+	EventsByResource map[string]int64 `json:"events_by_resource"`
 
 	// EventsByActor breaks down events by actor (top actors).
-	EventsByActor map[string]int64
+	EventsByActor map[string]int64 `json:"events_by_actor"`
 
 	// OldestEvent is the timestamp of the oldest event.
-	OldestEvent *time.Time
+	OldestEvent *time.Time `json:"oldest_event,omitempty"`
 
 	// NewestEvent is the timestamp of the newest event.
-	NewestEvent *time.Time
+	NewestEvent *time.Time `json:"newest_event,omitempty"`
 
 	// SuccessCount is the number of successful operations.
-	SuccessCount int64
+	SuccessCount int64 `json:"success_count"`
 
 	// FailureCount is the number of failed operations.
-	FailureCount int64
+	FailureCount int64 `json:"failure_count"`
 }
 
 // ExtendedAuditStore combines AuditStore with retention management.
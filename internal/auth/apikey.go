@@ -51,10 +51,15 @@ var (
 	ErrInsufficientScopes = errors.New("insufficient scopes")
 )
 
+// SCIMScope grants access to the SCIM provisioning API and nothing else.
+const SCIMScope = "scim:provision"
+
 // ValidAPIKeyScopes is the set of scopes accepted by the API key issuer.
 var ValidAPIKeyScopes = []string{
 	"pools:read",
 	"pools:write",
+	"change_requests:read",
+	"change_requests:approve",
 	"accounts:read",
 	"accounts:write",
 	"audit:read",
@@ -62,6 +67,7 @@ var ValidAPIKeyScopes = []string{
 	"keys:write",
 	"discovery:read",
 	"discovery:write",
+	SCIMScope,
 	"*",
 }
 
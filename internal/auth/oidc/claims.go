@@ -1,6 +1,13 @@
 package oidc
 
-import "cloudpam/internal/auth"
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"cloudpam/internal/auth"
+	"cloudpam/internal/domain"
+)
 
 // Claims represents extracted OIDC ID token claims.
 type Claims struct {
@@ -9,6 +16,73 @@ type Claims struct {
 	Name    string   `json:"name"`
 	Groups  []string `json:"groups"`
 	Issuer  string   `json:"iss"`
+
+	// Raw holds every claim in the ID token, for role rules that match on
+	// claims other than the ones above.
+	Raw map[string]any `json:"-"`
+}
+
+// Values returns the values of the claim at a dot-separated path. A string
+// claim yields one value and an array yields one per element; numbers and
+// booleans are formatted. Claim names that themselves contain dots, such as
+// "https://example.com/roles", are matched whole before the path is split.
+func (c Claims) Values(path string) []string {
+	if c.Raw == nil {
+		switch path {
+		case "sub":
+			return nonEmpty(c.Subject)
+		case "email":
+			return nonEmpty(c.Email)
+		case "name":
+			return nonEmpty(c.Name)
+		case "groups":
+			return c.Groups
+		case "iss":
+			return nonEmpty(c.Issuer)
+		}
+		return nil
+	}
+	return claimStrings(lookupClaim(c.Raw, path))
+}
+
+func nonEmpty(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return []string{s}
+}
+
+func lookupClaim(m map[string]any, path string) any {
+	if v, ok := m[path]; ok {
+		return v
+	}
+	for i := strings.LastIndex(path, "."); i > 0; i = strings.LastIndex(path[:i], ".") {
+		if child, ok := m[path[:i]].(map[string]any); ok {
+			if v := lookupClaim(child, path[i+1:]); v != nil {
+				return v
+			}
+		}
+	}
+	return nil
+}
+
+func claimStrings(v any) []string {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{v}
+	case []any:
+		var out []string
+		for _, e := range v {
+			out = append(out, claimStrings(e)...)
+		}
+		return out
+	case map[string]any:
+		return nil
+	default:
+		return []string{fmt.Sprint(v)}
+	}
 }
 
 // MapRole evaluates group-to-role mapping rules against claims.
@@ -29,3 +103,48 @@ func MapRole(claims Claims, mapping map[string]string, defaultRole auth.Role) au
 	}
 	return bestRole
 }
+
+// Role decision sources.
+const (
+	RoleSourceRule        = "role_rule"
+	RoleSourceRoleMapping = "role_mapping"
+	RoleSourceDefault     = "default_role"
+)
+
+// RoleDecision records which role a user was given and why.
+type RoleDecision struct {
+	Role   auth.Role
+	Source string // RoleSourceRule, RoleSourceRoleMapping or RoleSourceDefault
+
+	// Rule, Claim and Values describe the matching role rule, when Source
+	// is RoleSourceRule, or the groups claim for RoleSourceRoleMapping.
+	Rule   *domain.OIDCRoleRule
+	Claim  string
+	Values []string
+}
+
+// ResolveRole picks a role for claims. Role rules are tried first in
+// priority order, skipping rules whose role does not exist (roleExists
+// reports that, so rules may name custom roles). Then the legacy
+// group-to-built-in-role mapping applies, and finally defaultRole.
+func ResolveRole(claims Claims, rules []domain.OIDCRoleRule, mapping map[string]string, defaultRole auth.Role, roleExists func(auth.Role) bool) RoleDecision {
+	sorted := append([]domain.OIDCRoleRule(nil), rules...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Priority < sorted[j].Priority })
+	for i := range sorted {
+		rule := sorted[i]
+		role := auth.NormalizeRoleName(rule.Role)
+		if role == auth.RoleNone || (roleExists != nil && !roleExists(role)) {
+			continue
+		}
+		values := claims.Values(rule.Claim)
+		for _, v := range values {
+			if v == rule.Value {
+				return RoleDecision{Role: role, Source: RoleSourceRule, Rule: &rule, Claim: rule.Claim, Values: values}
+			}
+		}
+	}
+	if role := MapRole(claims, mapping, auth.RoleNone); role != auth.RoleNone {
+		return RoleDecision{Role: role, Source: RoleSourceRoleMapping, Claim: "groups", Values: claims.Groups}
+	}
+	return RoleDecision{Role: defaultRole, Source: RoleSourceDefault}
+}
@@ -1,9 +1,11 @@
 package oidc
 
 import (
+	"strings"
 	"testing"
 
 	"cloudpam/internal/auth"
+	"cloudpam/internal/domain"
 )
 
 func TestMapRole_GroupMatch(t *testing.T) {
@@ -86,3 +88,81 @@ func TestMapRole_NilMapping(t *testing.T) {
 		t.Errorf("expected viewer (default), got %q", role)
 	}
 }
+
+func TestClaimsValues(t *testing.T) {
+	claims := Claims{
+		Groups: []string{"admins"},
+		Raw: map[string]any{
+			"groups":                     []any{"admins", "dev"},
+			"department":                 "IT",
+			"level":                      float64(3),
+			"realm_access":               map[string]any{"roles": []any{"netops", "offline"}},
+			"https://example.com/claims": map[string]any{"team": "core"},
+			"https://example.com/roles":  []any{"pam-editor"},
+		},
+	}
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"groups", "admins,dev"},
+		{"department", "IT"},
+		{"level", "3"},
+		{"realm_access.roles", "netops,offline"},
+		{"https://example.com/roles", "pam-editor"},
+		{"https://example.com/claims.team", "core"},
+		{"realm_access", ""},
+		{"missing.path", ""},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(claims.Values(tt.path), ","); got != tt.want {
+			t.Errorf("Values(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+
+	// Without raw claims only the standard fields are available.
+	bare := Claims{Email: "a@example.com", Groups: []string{"g"}}
+	if got := bare.Values("groups"); len(got) != 1 || got[0] != "g" {
+		t.Errorf("bare groups = %v", got)
+	}
+	if got := bare.Values("department"); got != nil {
+		t.Errorf("bare department = %v, want nil", got)
+	}
+}
+
+func TestResolveRole_PriorityAndCustomRoles(t *testing.T) {
+	claims := Claims{
+		Groups: []string{"cloudpam-admins", "netops"},
+		Raw: map[string]any{
+			"groups":     []any{"cloudpam-admins", "netops"},
+			"department": "IT",
+		},
+	}
+	rules := []domain.OIDCRoleRule{
+		{Claim: "department", Value: "IT", Role: "auditor", Priority: 20},
+		{Claim: "groups", Value: "netops", Role: "network-engineer", Priority: 10},
+		{Claim: "groups", Value: "netops", Role: "deleted-role", Priority: 1},
+	}
+	exists := func(r auth.Role) bool { return auth.IsBuiltinRole(r) || r == "network-engineer" }
+
+	d := ResolveRole(claims, rules, map[string]string{"cloudpam-admins": "admin"}, auth.RoleViewer, exists)
+	if d.Role != "network-engineer" || d.Source != RoleSourceRule || d.Rule == nil || d.Rule.Priority != 10 {
+		t.Fatalf("decision = %+v, want network-engineer from the priority 10 rule", d)
+	}
+	if d.Claim != "groups" || strings.Join(d.Values, ",") != "cloudpam-admins,netops" {
+		t.Errorf("claim = %q %v", d.Claim, d.Values)
+	}
+
+	// Rules win over the group mapping; with no rule match the mapping applies.
+	claims.Raw["groups"] = []any{"cloudpam-admins"}
+	claims.Raw["department"] = "Sales"
+	d = ResolveRole(claims, rules, map[string]string{"cloudpam-admins": "admin"}, auth.RoleViewer, exists)
+	if d.Role != auth.RoleAdmin || d.Source != RoleSourceRoleMapping {
+		t.Errorf("decision = %+v, want admin from role_mapping", d)
+	}
+
+	d = ResolveRole(Claims{}, rules, nil, auth.RoleViewer, exists)
+	if d.Role != auth.RoleViewer || d.Source != RoleSourceDefault {
+		t.Errorf("decision = %+v, want default viewer", d)
+	}
+}
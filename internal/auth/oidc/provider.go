@@ -98,14 +98,44 @@ func (p *Provider) AuthCodeURL(state string, opts ...oauth2.AuthCodeOption) stri
 	return p.oauth2Config.AuthCodeURL(state, opts...)
 }
 
+// Tokens is the verified result of a code exchange or token refresh.
+type Tokens struct {
+	Claims *Claims
+	// RefreshToken is empty unless the IdP issued one, which usually
+	// requires the offline_access scope.
+	RefreshToken string
+}
+
 // Exchange exchanges an authorization code for tokens, verifies the ID token,
 // and extracts claims.
 func (p *Provider) Exchange(ctx context.Context, code string) (*Claims, error) {
+	tokens, err := p.ExchangeTokens(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	return tokens.Claims, nil
+}
+
+// ExchangeTokens is Exchange, also returning the refresh token.
+func (p *Provider) ExchangeTokens(ctx context.Context, code string) (*Tokens, error) {
 	token, err := p.oauth2Config.Exchange(ctx, code)
 	if err != nil {
 		return nil, fmt.Errorf("token exchange: %w", err)
 	}
+	return p.verifyTokens(ctx, token)
+}
 
+// Refresh redeems a refresh token for a new ID token, so that claims can be
+// re-evaluated without sending the user back to the IdP.
+func (p *Provider) Refresh(ctx context.Context, refreshToken string) (*Tokens, error) {
+	token, err := p.oauth2Config.TokenSource(ctx, &oauth2.Token{RefreshToken: refreshToken}).Token()
+	if err != nil {
+		return nil, fmt.Errorf("token refresh: %w", err)
+	}
+	return p.verifyTokens(ctx, token)
+}
+
+func (p *Provider) verifyTokens(ctx context.Context, token *oauth2.Token) (*Tokens, error) {
 	rawIDToken, ok := token.Extra("id_token").(string)
 	if !ok {
 		return nil, fmt.Errorf("no id_token in response")
@@ -120,7 +150,10 @@ func (p *Provider) Exchange(ctx context.Context, code string) (*Claims, error) {
 	if err := idToken.Claims(&claims); err != nil {
 		return nil, fmt.Errorf("extract claims: %w", err)
 	}
+	if err := idToken.Claims(&claims.Raw); err != nil {
+		return nil, fmt.Errorf("extract claims: %w", err)
+	}
 	claims.Issuer = idToken.Issuer
 
-	return &claims, nil
+	return &Tokens{Claims: &claims, RefreshToken: token.RefreshToken}, nil
 }
@@ -36,6 +36,9 @@ const (
 	ResourceUsers     = "users"
 	ResourceDiscovery = "discovery"
 	ResourceSettings  = "settings"
+	ResourceWebhooks  = "webhooks"
+
+	ResourceChangeRequests = "change_requests"
 )
 
 // Action constants for permission checks.
@@ -46,6 +49,9 @@ const (
 	ActionDelete = "delete"
 	ActionList   = "list"
 	ActionWrite  = "write"
+
+	// ActionApprove decides pending pool change requests.
+	ActionApprove = "approve"
 )
 
 // Permission represents an action on a resource.
@@ -67,6 +73,9 @@ func PermissionCatalog() []PermissionDefinition {
 		{ID: "pools:update", Resource: ResourcePools, Action: ActionUpdate, Name: "Update pools", Description: "Edit pool metadata, hierarchy, and assignment.", Category: "IPAM"},
 		{ID: "pools:delete", Resource: ResourcePools, Action: ActionDelete, Name: "Delete pools", Description: "Delete pools and planned allocations.", Category: "IPAM"},
 		{ID: "pools:list", Resource: ResourcePools, Action: ActionList, Name: "List pools", Description: "Browse pool lists and tree views.", Category: "IPAM"},
+		{ID: "change_requests:read", Resource: ResourceChangeRequests, Action: ActionRead, Name: "Read change requests", Description: "View pool change requests awaiting approval.", Category: "IPAM"},
+		{ID: "change_requests:list", Resource: ResourceChangeRequests, Action: ActionList, Name: "List change requests", Description: "Browse pool change requests.", Category: "IPAM"},
+		{ID: "change_requests:approve", Resource: ResourceChangeRequests, Action: ActionApprove, Name: "Approve change requests", Description: "Approve or reject pool changes requested by someone else.", Category: "IPAM"},
 		{ID: "accounts:create", Resource: ResourceAccounts, Action: ActionCreate, Name: "Create accounts", Description: "Create cloud account records.", Category: "Accounts"},
 		{ID: "accounts:read", Resource: ResourceAccounts, Action: ActionRead, Name: "Read accounts", Description: "View account details and account-linked resources.", Category: "Accounts"},
 		{ID: "accounts:update", Resource: ResourceAccounts, Action: ActionUpdate, Name: "Update accounts", Description: "Edit account metadata.", Category: "Accounts"},
@@ -91,6 +100,11 @@ func PermissionCatalog() []PermissionDefinition {
 		{ID: "discovery:list", Resource: ResourceDiscovery, Action: ActionList, Name: "List discovery", Description: "Browse discovery resources, jobs, agents, drift, and recommendations.", Category: "Discovery"},
 		{ID: "settings:read", Resource: ResourceSettings, Action: ActionRead, Name: "Read settings", Description: "View security, OIDC, update, and system configuration.", Category: "Settings"},
 		{ID: "settings:write", Resource: ResourceSettings, Action: ActionWrite, Name: "Write settings", Description: "Change security, OIDC, update, and system configuration.", Category: "Settings"},
+		{ID: "webhooks:create", Resource: ResourceWebhooks, Action: ActionCreate, Name: "Create webhooks", Description: "Register outbound webhook endpoints.", Category: "Integrations"},
+		{ID: "webhooks:read", Resource: ResourceWebhooks, Action: ActionRead, Name: "Read webhooks", Description: "View webhook configuration and delivery logs.", Category: "Integrations"},
+		{ID: "webhooks:update", Resource: ResourceWebhooks, Action: ActionUpdate, Name: "Update webhooks", Description: "Edit webhook endpoints, event filters, secrets, and send test events.", Category: "Integrations"},
+		{ID: "webhooks:delete", Resource: ResourceWebhooks, Action: ActionDelete, Name: "Delete webhooks", Description: "Remove webhook endpoints and their delivery logs.", Category: "Integrations"},
+		{ID: "webhooks:list", Resource: ResourceWebhooks, Action: ActionList, Name: "List webhooks", Description: "Browse webhook endpoints.", Category: "Integrations"},
 	}
 	result := make([]PermissionDefinition, len(defs))
 	copy(result, defs)
@@ -140,6 +154,9 @@ var RolePermissions = map[Role][]Permission{
 		{ResourcePools, ActionUpdate},
 		{ResourcePools, ActionDelete},
 		{ResourcePools, ActionList},
+		{ResourceChangeRequests, ActionRead},
+		{ResourceChangeRequests, ActionList},
+		{ResourceChangeRequests, ActionApprove},
 		{ResourceAccounts, ActionCreate},
 		{ResourceAccounts, ActionRead},
 		{ResourceAccounts, ActionUpdate},
@@ -164,6 +181,11 @@ var RolePermissions = map[Role][]Permission{
 		{ResourceDiscovery, ActionList},
 		{ResourceSettings, ActionRead},
 		{ResourceSettings, ActionWrite},
+		{ResourceWebhooks, ActionCreate},
+		{ResourceWebhooks, ActionRead},
+		{ResourceWebhooks, ActionUpdate},
+		{ResourceWebhooks, ActionDelete},
+		{ResourceWebhooks, ActionList},
 	},
 	RoleOperator: {
 		// Read/write access to pools, accounts, and discovery
@@ -172,6 +194,8 @@ var RolePermissions = map[Role][]Permission{
 		{ResourcePools, ActionUpdate},
 		{ResourcePools, ActionDelete},
 		{ResourcePools, ActionList},
+		{ResourceChangeRequests, ActionRead},
+		{ResourceChangeRequests, ActionList},
 		{ResourceAccounts, ActionCreate},
 		{ResourceAccounts, ActionRead},
 		{ResourceAccounts, ActionUpdate},
@@ -186,15 +210,19 @@ var RolePermissions = map[Role][]Permission{
 		// Read-only access to pools, accounts, and discovery
 		{ResourcePools, ActionRead},
 		{ResourcePools, ActionList},
+		{ResourceChangeRequests, ActionRead},
+		{ResourceChangeRequests, ActionList},
 		{ResourceAccounts, ActionRead},
 		{ResourceAccounts, ActionList},
 		{ResourceDiscovery, ActionRead},
 		{ResourceDiscovery, ActionList},
 	},
 	RoleAuditor: {
-		// Access to audit logs only
+		// Access to audit logs and the change requests they refer to
 		{ResourceAudit, ActionRead},
 		{ResourceAudit, ActionList},
+		{ResourceChangeRequests, ActionRead},
+		{ResourceChangeRequests, ActionList},
 	},
 }
 
@@ -385,6 +413,14 @@ func RoleExists(ctx context.Context, role Role) bool {
 
 func ScopeAllowedByRolePermissions(ctx context.Context, role Role, scope string) bool {
 	scope = strings.TrimSpace(scope)
+	if scope == SCIMScope {
+		// A SCIM key creates, updates and deactivates users and manages
+		// roles, so only callers who can do all of that may issue one.
+		return HasPermissionContext(ctx, role, ResourceUsers, ActionCreate) &&
+			HasPermissionContext(ctx, role, ResourceUsers, ActionUpdate) &&
+			HasPermissionContext(ctx, role, ResourceUsers, ActionDelete) &&
+			HasPermissionContext(ctx, role, ResourceSettings, ActionWrite)
+	}
 	if scope == "*" {
 		for _, def := range PermissionCatalog() {
 			if !HasPermissionContext(ctx, role, def.Resource, def.Action) {
@@ -37,7 +37,7 @@ func TestPermissionCatalogIsWellFormed(t *testing.T) {
 		}
 	}
 
-	for _, id := range []string{"pools:read", "accounts:delete", "apikeys:list", "audit:read", "users:update", "discovery:create", "settings:write"} {
+	for _, id := range []string{"pools:read", "accounts:delete", "apikeys:list", "audit:read", "users:update", "discovery:create", "settings:write", "webhooks:list"} {
 		if !seen[id] {
 			t.Errorf("catalog is missing %q", id)
 		}
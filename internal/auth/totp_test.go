@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"encoding/base32"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 test key from RFC 6238 appendix B.
+var rfc6238Secret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPCode_RFC6238Vectors(t *testing.T) {
+	// The RFC lists 8-digit codes; a 6-digit code is their last six digits.
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, tt := range tests {
+		got, err := TOTPCode(rfc6238Secret, TOTPStep(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("TOTPCode(%d): %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("TOTPCode(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := TOTPStep(now)
+	code, _ := TOTPCode(rfc6238Secret, step)
+
+	got, ok := ValidateTOTP(rfc6238Secret, code, now, 0)
+	if !ok || got != step {
+		t.Fatalf("ValidateTOTP = %d, %v, want %d, true", got, ok, step)
+	}
+	if _, ok := ValidateTOTP(rfc6238Secret, code, now, step); ok {
+		t.Error("ValidateTOTP accepted a replayed code")
+	}
+
+	prev, _ := TOTPCode(rfc6238Secret, step-1)
+	if got, ok := ValidateTOTP(rfc6238Secret, prev, now, 0); !ok || got != step-1 {
+		t.Errorf("previous step = %d, %v, want accepted for clock drift", got, ok)
+	}
+	old, _ := TOTPCode(rfc6238Secret, step-2)
+	if _, ok := ValidateTOTP(rfc6238Secret, old, now, 0); ok {
+		t.Error("ValidateTOTP accepted a code two steps old")
+	}
+	if _, ok := ValidateTOTP(rfc6238Secret, "12345", now, 0); ok {
+		t.Error("ValidateTOTP accepted a short code")
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret: %v", err)
+	}
+	if len(secret) != 32 {
+		t.Errorf("secret length = %d, want 32 base32 characters", len(secret))
+	}
+	code, err := TOTPCode(secret, TOTPStep(time.Now()))
+	if err != nil || len(code) != TOTPDigits {
+		t.Errorf("TOTPCode = %q, %v", code, err)
+	}
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri := TOTPURI("CloudPAM", "alice@example.com", "ABC")
+	u, err := url.Parse(uri)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/CloudPAM:alice@example.com" {
+		t.Errorf("uri = %s", uri)
+	}
+	q := u.Query()
+	if q.Get("secret") != "ABC" || q.Get("issuer") != "CloudPAM" || q.Get("digits") != "6" || q.Get("period") != "30" {
+		t.Errorf("query = %v", q)
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, hashes, err := GenerateRecoveryCodes(RecoveryCodeCount)
+	if err != nil {
+		t.Fatalf("GenerateRecoveryCodes: %v", err)
+	}
+	if len(codes) != RecoveryCodeCount || len(hashes) != RecoveryCodeCount {
+		t.Fatalf("got %d codes and %d hashes", len(codes), len(hashes))
+	}
+	for i, code := range codes {
+		if hashes[i] == code {
+			t.Fatalf("hash %d is the plaintext code", i)
+		}
+	}
+
+	if got := MatchRecoveryCode(hashes, codes[3]); got != 3 {
+		t.Errorf("match = %d, want 3", got)
+	}
+	sloppy := strings.ToUpper(strings.ReplaceAll(codes[5], "-", " "))
+	if got := MatchRecoveryCode(hashes, sloppy); got != 5 {
+		t.Errorf("match %q = %d, want 5", sloppy, got)
+	}
+	if got := MatchRecoveryCode(hashes, "nope-nope"); got != -1 {
+		t.Errorf("match unknown = %d, want -1", got)
+	}
+
+	if got := splitRecoveryCodes(joinRecoveryCodes(hashes)); strings.Join(got, ",") != strings.Join(hashes, ",") {
+		t.Errorf("round trip = %v", got)
+	}
+	if got := splitRecoveryCodes(""); got != nil {
+		t.Errorf("split empty = %v, want nil", got)
+	}
+}
@@ -32,6 +32,23 @@ type User struct {
 	AuthProvider        string     `json:"auth_provider,omitempty"` // "local" or "oidc"
 	OIDCSubject         string     `json:"oidc_subject,omitempty"`  // IdP "sub" claim
 	OIDCIssuer          string     `json:"oidc_issuer,omitempty"`   // IdP issuer URL
+
+	// MFAEnabled is set once the user has confirmed TOTP enrolment with a
+	// valid code; until then MFASecret holds the pending secret.
+	MFAEnabled       bool       `json:"mfa_enabled"`
+	MFAEnrolledAt    *time.Time `json:"mfa_enrolled_at,omitempty"`
+	MFASecret        string     `json:"-"` // base32 TOTP secret, never serialized
+	MFARecoveryCodes []string   `json:"-"` // SHA-256 hashes of unused recovery codes
+	MFALastStep      int64      `json:"-"` // last accepted TOTP step, to reject replays
+}
+
+// ClearMFA removes the user's TOTP enrolment and recovery codes.
+func (u *User) ClearMFA() {
+	u.MFAEnabled = false
+	u.MFAEnrolledAt = nil
+	u.MFASecret = ""
+	u.MFARecoveryCodes = nil
+	u.MFALastStep = 0
 }
 
 // copyUser creates a deep copy of a User.
@@ -52,6 +69,16 @@ func copyUser(u *User) *User {
 		AuthProvider:        u.AuthProvider,
 		OIDCSubject:         u.OIDCSubject,
 		OIDCIssuer:          u.OIDCIssuer,
+		MFAEnabled:          u.MFAEnabled,
+		MFASecret:           u.MFASecret,
+		MFALastStep:         u.MFALastStep,
+	}
+	if u.MFAEnrolledAt != nil {
+		t := *u.MFAEnrolledAt
+		cpy.MFAEnrolledAt = &t
+	}
+	if u.MFARecoveryCodes != nil {
+		cpy.MFARecoveryCodes = append([]string(nil), u.MFARecoveryCodes...)
 	}
 	if u.PasswordHash != nil {
 		cpy.PasswordHash = make([]byte, len(u.PasswordHash))
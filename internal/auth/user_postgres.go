@@ -43,12 +43,13 @@ func (s *PostgresUserStore) Create(ctx context.Context, user *User) error {
 		return ErrUserNotFound
 	}
 	_, err := s.pool.Exec(ctx, `
-		INSERT INTO users (id, username, email, display_name, role, password_hash, is_active, created_at, updated_at, last_failed_login_at, failed_login_attempts, locked_at, lockout_until, auth_provider, oidc_subject, oidc_issuer)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`,
+		INSERT INTO users (id, username, email, display_name, role, password_hash, is_active, created_at, updated_at, last_failed_login_at, failed_login_attempts, locked_at, lockout_until, auth_provider, oidc_subject, oidc_issuer, mfa_enabled, mfa_enrolled_at, mfa_secret, mfa_recovery_codes, mfa_last_step)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)`,
 		user.ID, user.Username, user.Email, user.DisplayName, string(user.Role),
 		user.PasswordHash, user.IsActive, user.CreatedAt, user.UpdatedAt,
 		user.LastFailedLoginAt, user.FailedLoginAttempts, user.LockedAt, user.LockoutUntil,
 		user.AuthProvider, user.OIDCSubject, user.OIDCIssuer,
+		user.MFAEnabled, user.MFAEnrolledAt, user.MFASecret, joinRecoveryCodes(user.MFARecoveryCodes), user.MFALastStep,
 	)
 	if err != nil {
 		if isUniqueViolation(err) {
@@ -61,19 +62,19 @@ func (s *PostgresUserStore) Create(ctx context.Context, user *User) error {
 
 func (s *PostgresUserStore) GetByID(ctx context.Context, id string) (*User, error) {
 	return s.scanUser(s.pool.QueryRow(ctx, `
-		SELECT id, username, email, display_name, role, password_hash, is_active, created_at, updated_at, last_login_at, last_failed_login_at, failed_login_attempts, locked_at, lockout_until, auth_provider, oidc_subject, oidc_issuer
+		SELECT id, username, email, display_name, role, password_hash, is_active, created_at, updated_at, last_login_at, last_failed_login_at, failed_login_attempts, locked_at, lockout_until, auth_provider, oidc_subject, oidc_issuer, mfa_enabled, mfa_enrolled_at, mfa_secret, mfa_recovery_codes, mfa_last_step
 		FROM users WHERE id = $1`, id))
 }
 
 func (s *PostgresUserStore) GetByUsername(ctx context.Context, username string) (*User, error) {
 	return s.scanUser(s.pool.QueryRow(ctx, `
-		SELECT id, username, email, display_name, role, password_hash, is_active, created_at, updated_at, last_login_at, last_failed_login_at, failed_login_attempts, locked_at, lockout_until, auth_provider, oidc_subject, oidc_issuer
+		SELECT id, username, email, display_name, role, password_hash, is_active, created_at, updated_at, last_login_at, last_failed_login_at, failed_login_attempts, locked_at, lockout_until, auth_provider, oidc_subject, oidc_issuer, mfa_enabled, mfa_enrolled_at, mfa_secret, mfa_recovery_codes, mfa_last_step
 		FROM users WHERE username = $1`, username))
 }
 
 func (s *PostgresUserStore) List(ctx context.Context) ([]*User, error) {
 	rows, err := s.pool.Query(ctx, `
-		SELECT id, username, email, display_name, role, is_active, created_at, updated_at, last_login_at, last_failed_login_at, failed_login_attempts, locked_at, lockout_until, auth_provider, oidc_subject, oidc_issuer
+		SELECT id, username, email, display_name, role, is_active, created_at, updated_at, last_login_at, last_failed_login_at, failed_login_attempts, locked_at, lockout_until, auth_provider, oidc_subject, oidc_issuer, mfa_enabled, mfa_enrolled_at
 		FROM users ORDER BY created_at DESC`)
 	if err != nil {
 		return nil, err
@@ -87,7 +88,7 @@ func (s *PostgresUserStore) List(ctx context.Context) ([]*User, error) {
 		var lastLoginAt *time.Time
 		var lastFailedLoginAt, lockedAt, lockoutUntil *time.Time
 		var authProvider, oidcSubject, oidcIssuer *string
-		if err := rows.Scan(&u.ID, &u.Username, &u.Email, &u.DisplayName, &role, &u.IsActive, &u.CreatedAt, &u.UpdatedAt, &lastLoginAt, &lastFailedLoginAt, &u.FailedLoginAttempts, &lockedAt, &lockoutUntil, &authProvider, &oidcSubject, &oidcIssuer); err != nil {
+		if err := rows.Scan(&u.ID, &u.Username, &u.Email, &u.DisplayName, &role, &u.IsActive, &u.CreatedAt, &u.UpdatedAt, &lastLoginAt, &lastFailedLoginAt, &u.FailedLoginAttempts, &lockedAt, &lockoutUntil, &authProvider, &oidcSubject, &oidcIssuer, &u.MFAEnabled, &u.MFAEnrolledAt); err != nil {
 			return nil, err
 		}
 		u.Role = Role(role)
@@ -116,11 +117,15 @@ func (s *PostgresUserStore) Update(ctx context.Context, user *User) error {
 	tag, err := s.pool.Exec(ctx, `
 		UPDATE users SET username = $2, email = $3, display_name = $4, role = $5,
 			password_hash = $6, is_active = $7, updated_at = $8,
-			last_failed_login_at = $9, failed_login_attempts = $10, locked_at = $11, lockout_until = $12
+			last_failed_login_at = $9, failed_login_attempts = $10, locked_at = $11, lockout_until = $12,
+			mfa_enabled = $13, mfa_enrolled_at = $14, mfa_secret = $15, mfa_recovery_codes = $16, mfa_last_step = $17,
+			auth_provider = $18, oidc_subject = $19, oidc_issuer = $20
 		WHERE id = $1`,
 		user.ID, user.Username, user.Email, user.DisplayName, string(user.Role),
 		user.PasswordHash, user.IsActive, user.UpdatedAt,
 		user.LastFailedLoginAt, user.FailedLoginAttempts, user.LockedAt, user.LockoutUntil,
+		user.MFAEnabled, user.MFAEnrolledAt, user.MFASecret, joinRecoveryCodes(user.MFARecoveryCodes), user.MFALastStep,
+		user.AuthProvider, user.OIDCSubject, user.OIDCIssuer,
 	)
 	if err != nil {
 		if isUniqueViolation(err) {
@@ -158,7 +163,7 @@ func (s *PostgresUserStore) UpdateLastLogin(ctx context.Context, id string, t ti
 
 func (s *PostgresUserStore) GetByOIDCIdentity(ctx context.Context, issuer, subject string) (*User, error) {
 	return s.scanUser(s.pool.QueryRow(ctx, `
-		SELECT id, username, email, display_name, role, password_hash, is_active, created_at, updated_at, last_login_at, last_failed_login_at, failed_login_attempts, locked_at, lockout_until, auth_provider, oidc_subject, oidc_issuer
+		SELECT id, username, email, display_name, role, password_hash, is_active, created_at, updated_at, last_login_at, last_failed_login_at, failed_login_attempts, locked_at, lockout_until, auth_provider, oidc_subject, oidc_issuer, mfa_enabled, mfa_enrolled_at, mfa_secret, mfa_recovery_codes, mfa_last_step
 		FROM users WHERE oidc_issuer = $1 AND oidc_subject = $2`, issuer, subject))
 }
 
@@ -168,11 +173,13 @@ func (s *PostgresUserStore) scanUser(row pgx.Row) (*User, error) {
 	var lastLoginAt *time.Time
 	var lastFailedLoginAt, lockedAt, lockoutUntil *time.Time
 	var authProvider, oidcSubject, oidcIssuer *string
+	var mfaSecret, mfaRecoveryCodes *string
 
 	err := row.Scan(&u.ID, &u.Username, &u.Email, &u.DisplayName, &role,
 		&u.PasswordHash, &u.IsActive, &u.CreatedAt, &u.UpdatedAt, &lastLoginAt,
 		&lastFailedLoginAt, &u.FailedLoginAttempts, &lockedAt, &lockoutUntil,
-		&authProvider, &oidcSubject, &oidcIssuer)
+		&authProvider, &oidcSubject, &oidcIssuer,
+		&u.MFAEnabled, &u.MFAEnrolledAt, &mfaSecret, &mfaRecoveryCodes, &u.MFALastStep)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, nil
@@ -194,5 +201,11 @@ func (s *PostgresUserStore) scanUser(row pgx.Row) (*User, error) {
 	if oidcIssuer != nil {
 		u.OIDCIssuer = *oidcIssuer
 	}
+	if mfaSecret != nil {
+		u.MFASecret = *mfaSecret
+	}
+	if mfaRecoveryCodes != nil {
+		u.MFARecoveryCodes = splitRecoveryCodes(*mfaRecoveryCodes)
+	}
 	return &u, nil
 }
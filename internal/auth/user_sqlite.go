@@ -41,8 +41,8 @@ func (s *SQLiteUserStore) Create(ctx context.Context, user *User) error {
 		return ErrUserNotFound
 	}
 	_, err := s.db.ExecContext(ctx, `
-		INSERT INTO users (id, username, email, display_name, role, password_hash, is_active, created_at, updated_at, last_failed_login_at, failed_login_attempts, locked_at, lockout_until, auth_provider, oidc_subject, oidc_issuer)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO users (id, username, email, display_name, role, password_hash, is_active, created_at, updated_at, last_failed_login_at, failed_login_attempts, locked_at, lockout_until, auth_provider, oidc_subject, oidc_issuer, mfa_enabled, mfa_enrolled_at, mfa_secret, mfa_recovery_codes, mfa_last_step)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`,
 		user.ID, user.Username, user.Email, user.DisplayName, string(user.Role),
 		user.PasswordHash, boolToInt(user.IsActive),
@@ -50,6 +50,8 @@ func (s *SQLiteUserStore) Create(ctx context.Context, user *User) error {
 		formatOptionalTime(user.LastFailedLoginAt), user.FailedLoginAttempts,
 		formatOptionalTime(user.LockedAt), formatOptionalTime(user.LockoutUntil),
 		user.AuthProvider, user.OIDCSubject, user.OIDCIssuer,
+		boolToInt(user.MFAEnabled), formatOptionalTime(user.MFAEnrolledAt),
+		user.MFASecret, joinRecoveryCodes(user.MFARecoveryCodes), user.MFALastStep,
 	)
 	if err != nil {
 		if isUniqueViolation(err) {
@@ -62,21 +64,21 @@ func (s *SQLiteUserStore) Create(ctx context.Context, user *User) error {
 
 func (s *SQLiteUserStore) GetByID(ctx context.Context, id string) (*User, error) {
 	return s.scanUser(s.db.QueryRowContext(ctx, `
-		SELECT id, username, email, display_name, role, password_hash, is_active, created_at, updated_at, last_login_at, last_failed_login_at, failed_login_attempts, locked_at, lockout_until, auth_provider, oidc_subject, oidc_issuer
+		SELECT id, username, email, display_name, role, password_hash, is_active, created_at, updated_at, last_login_at, last_failed_login_at, failed_login_attempts, locked_at, lockout_until, auth_provider, oidc_subject, oidc_issuer, mfa_enabled, mfa_enrolled_at, mfa_secret, mfa_recovery_codes, mfa_last_step
 		FROM users WHERE id = ?
 	`, id))
 }
 
 func (s *SQLiteUserStore) GetByUsername(ctx context.Context, username string) (*User, error) {
 	return s.scanUser(s.db.QueryRowContext(ctx, `
-		SELECT id, username, email, display_name, role, password_hash, is_active, created_at, updated_at, last_login_at, last_failed_login_at, failed_login_attempts, locked_at, lockout_until, auth_provider, oidc_subject, oidc_issuer
+		SELECT id, username, email, display_name, role, password_hash, is_active, created_at, updated_at, last_login_at, last_failed_login_at, failed_login_attempts, locked_at, lockout_until, auth_provider, oidc_subject, oidc_issuer, mfa_enabled, mfa_enrolled_at, mfa_secret, mfa_recovery_codes, mfa_last_step
 		FROM users WHERE username = ?
 	`, username))
 }
 
 func (s *SQLiteUserStore) List(ctx context.Context) ([]*User, error) {
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, username, email, display_name, role, is_active, created_at, updated_at, last_login_at, last_failed_login_at, failed_login_attempts, locked_at, lockout_until, auth_provider, oidc_subject, oidc_issuer
+		SELECT id, username, email, display_name, role, is_active, created_at, updated_at, last_login_at, last_failed_login_at, failed_login_attempts, locked_at, lockout_until, auth_provider, oidc_subject, oidc_issuer, mfa_enabled, mfa_enrolled_at
 		FROM users ORDER BY created_at DESC
 	`)
 	if err != nil {
@@ -99,7 +101,9 @@ func (s *SQLiteUserStore) List(ctx context.Context) ([]*User, error) {
 		var authProvider sql.NullString
 		var oidcSubject sql.NullString
 		var oidcIssuer sql.NullString
-		if err := rows.Scan(&u.ID, &u.Username, &u.Email, &u.DisplayName, &role, &isActive, &createdAt, &updatedAt, &lastLoginAt, &lastFailedLoginAt, &u.FailedLoginAttempts, &lockedAt, &lockoutUntil, &authProvider, &oidcSubject, &oidcIssuer); err != nil {
+		var mfaEnabled int
+		var mfaEnrolledAt sql.NullString
+		if err := rows.Scan(&u.ID, &u.Username, &u.Email, &u.DisplayName, &role, &isActive, &createdAt, &updatedAt, &lastLoginAt, &lastFailedLoginAt, &u.FailedLoginAttempts, &lockedAt, &lockoutUntil, &authProvider, &oidcSubject, &oidcIssuer, &mfaEnabled, &mfaEnrolledAt); err != nil {
 			return nil, fmt.Errorf("scan user: %w", err)
 		}
 		u.Role = Role(role)
@@ -131,6 +135,11 @@ func (s *SQLiteUserStore) List(ctx context.Context) ([]*User, error) {
 		if oidcIssuer.Valid {
 			u.OIDCIssuer = oidcIssuer.String
 		}
+		u.MFAEnabled = mfaEnabled != 0
+		if mfaEnrolledAt.Valid {
+			t, _ := time.Parse(time.RFC3339Nano, mfaEnrolledAt.String)
+			u.MFAEnrolledAt = &t
+		}
 		// Never include password hash in list results
 		users = append(users, &u)
 	}
@@ -144,7 +153,9 @@ func (s *SQLiteUserStore) Update(ctx context.Context, user *User) error {
 	res, err := s.db.ExecContext(ctx, `
 		UPDATE users SET username = ?, email = ?, display_name = ?, role = ?,
 			password_hash = ?, is_active = ?, updated_at = ?,
-			last_failed_login_at = ?, failed_login_attempts = ?, locked_at = ?, lockout_until = ?
+			last_failed_login_at = ?, failed_login_attempts = ?, locked_at = ?, lockout_until = ?,
+			auth_provider = ?, oidc_subject = ?, oidc_issuer = ?,
+			mfa_enabled = ?, mfa_enrolled_at = ?, mfa_secret = ?, mfa_recovery_codes = ?, mfa_last_step = ?
 		WHERE id = ?
 	`,
 		user.Username, user.Email, user.DisplayName, string(user.Role),
@@ -152,6 +163,9 @@ func (s *SQLiteUserStore) Update(ctx context.Context, user *User) error {
 		user.UpdatedAt.Format(time.RFC3339Nano),
 		formatOptionalTime(user.LastFailedLoginAt), user.FailedLoginAttempts,
 		formatOptionalTime(user.LockedAt), formatOptionalTime(user.LockoutUntil),
+		user.AuthProvider, user.OIDCSubject, user.OIDCIssuer,
+		boolToInt(user.MFAEnabled), formatOptionalTime(user.MFAEnrolledAt),
+		user.MFASecret, joinRecoveryCodes(user.MFARecoveryCodes), user.MFALastStep,
 		user.ID,
 	)
 	if err != nil {
@@ -194,7 +208,7 @@ func (s *SQLiteUserStore) UpdateLastLogin(ctx context.Context, id string, t time
 
 func (s *SQLiteUserStore) GetByOIDCIdentity(ctx context.Context, issuer, subject string) (*User, error) {
 	return s.scanUser(s.db.QueryRowContext(ctx, `
-		SELECT id, username, email, display_name, role, password_hash, is_active, created_at, updated_at, last_login_at, last_failed_login_at, failed_login_attempts, locked_at, lockout_until, auth_provider, oidc_subject, oidc_issuer
+		SELECT id, username, email, display_name, role, password_hash, is_active, created_at, updated_at, last_login_at, last_failed_login_at, failed_login_attempts, locked_at, lockout_until, auth_provider, oidc_subject, oidc_issuer, mfa_enabled, mfa_enrolled_at, mfa_secret, mfa_recovery_codes, mfa_last_step
 		FROM users WHERE oidc_issuer = ? AND oidc_subject = ?
 	`, issuer, subject))
 }
@@ -212,12 +226,17 @@ func (s *SQLiteUserStore) scanUser(row *sql.Row) (*User, error) {
 		authProvider         sql.NullString
 		oidcSubject          sql.NullString
 		oidcIssuer           sql.NullString
+		mfaEnabled           int
+		mfaEnrolledAt        sql.NullString
+		mfaSecret            sql.NullString
+		mfaRecoveryCodes     sql.NullString
 	)
 
 	err := row.Scan(&u.ID, &u.Username, &u.Email, &u.DisplayName, &role,
 		&u.PasswordHash, &isActive, &createdAt, &updatedAt, &lastLoginAt,
 		&lastFailedLoginAt, &u.FailedLoginAttempts, &lockedAt, &lockoutUntil,
-		&authProvider, &oidcSubject, &oidcIssuer)
+		&authProvider, &oidcSubject, &oidcIssuer,
+		&mfaEnabled, &mfaEnrolledAt, &mfaSecret, &mfaRecoveryCodes, &u.MFALastStep)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -254,6 +273,13 @@ func (s *SQLiteUserStore) scanUser(row *sql.Row) (*User, error) {
 	if oidcIssuer.Valid {
 		u.OIDCIssuer = oidcIssuer.String
 	}
+	u.MFAEnabled = mfaEnabled != 0
+	if mfaEnrolledAt.Valid {
+		t, _ := time.Parse(time.RFC3339Nano, mfaEnrolledAt.String)
+		u.MFAEnrolledAt = &t
+	}
+	u.MFASecret = mfaSecret.String
+	u.MFARecoveryCodes = splitRecoveryCodes(mfaRecoveryCodes.String)
 	return &u, nil
 }
 
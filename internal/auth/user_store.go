@@ -113,6 +113,8 @@ func (s *MemoryUserStore) List(_ context.Context) ([]*User, error) {
 	for _, u := range s.users {
 		cpy := copyUser(u)
 		cpy.PasswordHash = nil // never expose hashes in list
+		cpy.MFASecret = ""
+		cpy.MFARecoveryCodes = nil
 		result = append(result, cpy)
 	}
 	return result, nil
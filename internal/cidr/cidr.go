@@ -8,12 +8,13 @@ import (
 )
 
 // PrefixContains reports whether outer fully contains inner.
-// Both prefixes must be valid IPv4 prefixes; returns false otherwise.
+// Both prefixes must be valid and of the same address family; returns false
+// otherwise.
 func PrefixContains(outer, inner netip.Prefix) bool {
 	if !outer.IsValid() || !inner.IsValid() {
 		return false
 	}
-	if !outer.Addr().Is4() || !inner.Addr().Is4() {
+	if outer.Addr().Is4() != inner.Addr().Is4() {
 		return false
 	}
 	// inner must have equal or longer prefix length
@@ -21,7 +22,16 @@ func PrefixContains(outer, inner netip.Prefix) bool {
 		return false
 	}
 	// both first and last address of inner must be within outer
-	return outer.Contains(inner.Masked().Addr()) && outer.Contains(lastAddr(inner))
+	return outer.Contains(inner.Masked().Addr()) && outer.Contains(LastAddr(inner))
+}
+
+// PrefixesOverlap reports whether a and b share any addresses. Prefixes of
+// different address families never overlap.
+func PrefixesOverlap(a, b netip.Prefix) bool {
+	if !a.IsValid() || !b.IsValid() || a.Addr().Is4() != b.Addr().Is4() {
+		return false
+	}
+	return a.Overlaps(b)
 }
 
 // PrefixContainsAddr reports whether prefix contains addr.
@@ -32,36 +42,30 @@ func PrefixContainsAddr(prefix netip.Prefix, addr netip.Addr) bool {
 	return prefix.Contains(addr)
 }
 
-// ParseCIDROrIP parses a string as either a CIDR prefix ("10.0.0.0/8")
-// or a bare IP address ("10.1.2.5" → 10.1.2.5/32).
+// ParseCIDROrIP parses a string as either a CIDR prefix ("10.0.0.0/8",
+// "2001:db8::/32") or a bare IP address ("10.1.2.5" → 10.1.2.5/32,
+// "2001:db8::1" → 2001:db8::1/128).
 func ParseCIDROrIP(s string) (netip.Prefix, error) {
 	// Try CIDR first
 	if p, err := netip.ParsePrefix(s); err == nil {
-		if !p.Addr().Is4() {
-			return netip.Prefix{}, fmt.Errorf("only IPv4 supported: %s", s)
+		if p.Addr().Is4In6() {
+			return netip.Prefix{}, fmt.Errorf("IPv4-mapped IPv6 not supported: %s", s)
 		}
 		return p.Masked(), nil
 	}
 	// Try bare IP
 	if a, err := netip.ParseAddr(s); err == nil {
-		if !a.Is4() {
-			return netip.Prefix{}, fmt.Errorf("only IPv4 supported: %s", s)
+		if a.Zone() != "" || a.Is4In6() {
+			return netip.Prefix{}, fmt.Errorf("invalid CIDR or IP: %q", s)
 		}
-		return netip.PrefixFrom(a, 32), nil
+		return netip.PrefixFrom(a, a.BitLen()), nil
 	}
 	return netip.Prefix{}, fmt.Errorf("invalid CIDR or IP: %q", s)
 }
 
-// lastAddr returns the last (broadcast) address in a prefix.
-func lastAddr(p netip.Prefix) netip.Addr {
-	a4 := p.Masked().Addr().As4()
-	bits := p.Bits()
-	// set all host bits to 1
-	hostBits := 32 - bits
-	for i := 0; i < hostBits; i++ {
-		byteIdx := 3 - i/8
-		bitIdx := i % 8
-		a4[byteIdx] |= 1 << bitIdx
-	}
-	return netip.AddrFrom4(a4)
+// LastAddr returns the last address in a prefix (the broadcast address for
+// IPv4).
+func LastAddr(p netip.Prefix) netip.Addr {
+	_, last := PrefixRange(p)
+	return Uint128ToAddr(last, p.Addr().Is4())
 }
@@ -20,6 +20,10 @@ func TestPrefixContains(t *testing.T) {
 		{"child wider than parent", "10.0.0.0/16", "10.0.0.0/8", false},
 		{"single host in parent", "10.0.0.0/24", "10.0.0.5/32", true},
 		{"single host outside parent", "10.0.0.0/24", "10.0.1.5/32", false},
+		{"ipv6 parent contains child", "2001:db8::/32", "2001:db8:1::/48", true},
+		{"ipv6 child exceeds parent", "2001:db8::/48", "2001:db8:1::/48", false},
+		{"ipv6 host in parent", "2001:db8::/64", "2001:db8::5/128", true},
+		{"mixed families", "::/0", "10.0.0.0/8", false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -89,8 +93,11 @@ func TestParseCIDROrIP(t *testing.T) {
 		{"bare ip loopback", "127.0.0.1", "127.0.0.1/32", false},
 		{"invalid input", "notanip", "", true},
 		{"empty string", "", "", true},
-		{"ipv6 cidr rejected", "::1/128", "", true},
-		{"ipv6 addr rejected", "::1", "", true},
+		{"ipv6 cidr prefix", "2001:db8::/32", "2001:db8::/32", false},
+		{"ipv6 cidr with host bits", "2001:db8::1/64", "2001:db8::/64", false},
+		{"bare ipv6 becomes /128", "2001:db8::1", "2001:db8::1/128", false},
+		{"ipv6 addr with zone rejected", "fe80::1%eth0", "", true},
+		{"ipv4-mapped ipv6 rejected", "::ffff:10.0.0.1", "", true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -121,13 +128,37 @@ func TestLastAddr(t *testing.T) {
 		{"10.0.0.0/8", "10.255.255.255"},
 		{"192.168.1.0/32", "192.168.1.0"},
 		{"0.0.0.0/0", "255.255.255.255"},
+		{"2001:db8::/64", "2001:db8::ffff:ffff:ffff:ffff"},
+		{"2001:db8::1/128", "2001:db8::1"},
+		{"::/0", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.prefix, func(t *testing.T) {
 			p := netip.MustParsePrefix(tt.prefix)
-			got := lastAddr(p)
+			got := LastAddr(p)
 			if got.String() != tt.want {
-				t.Errorf("lastAddr(%s) = %s, want %s", tt.prefix, got, tt.want)
+				t.Errorf("LastAddr(%s) = %s, want %s", tt.prefix, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrefixesOverlap(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"10.0.0.0/8", "10.1.0.0/16", true},
+		{"10.0.0.0/16", "10.1.0.0/16", false},
+		{"2001:db8::/32", "2001:db8:ff::/48", true},
+		{"2001:db8::/48", "2001:db8:1::/48", false},
+		{"::/0", "10.0.0.0/8", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			a, b := netip.MustParsePrefix(tt.a), netip.MustParsePrefix(tt.b)
+			if got := PrefixesOverlap(a, b); got != tt.want {
+				t.Errorf("PrefixesOverlap(%s, %s) = %v, want %v", tt.a, tt.b, got, tt.want)
 			}
 		})
 	}
@@ -0,0 +1,91 @@
+package cidr
+
+import (
+	"net/netip"
+	"sort"
+)
+
+// RangeToPrefixes decomposes an inclusive range [first, last] into the
+// minimal set of CIDR-aligned prefixes that exactly cover it. is4 selects
+// whether the range is in IPv4 (low 32 bits) or IPv6 address space.
+func RangeToPrefixes(first, last Uint128, is4 bool) []netip.Prefix {
+	bitLen := 128
+	if is4 {
+		bitLen = 32
+	}
+	var result []netip.Prefix
+	for first.Cmp(last) <= 0 {
+		// Largest power-of-two block starting at 'first' that fits alignment.
+		// Alignment: the number of trailing zeros in first determines max block size.
+		maxBits := first.TrailingZeros()
+		if maxBits > bitLen {
+			maxBits = bitLen
+		}
+		// Don't exceed remaining range. A remaining count of zero means the
+		// range wrapped, i.e. it spans the whole 128-bit space.
+		remaining := last.Sub(first).AddOne()
+		fitBits := 128
+		if !remaining.IsZero() {
+			fitBits = 127 - remaining.LeadingZeros() // floor(log2(remaining))
+		}
+		if fitBits > maxBits {
+			fitBits = maxBits
+		}
+		result = append(result, netip.PrefixFrom(Uint128ToAddr(first, is4), bitLen-fitBits))
+		next := first.Add(Uint128From64(1).Lsh(uint(fitBits)))
+		if next.Cmp(first) <= 0 || fitBits >= bitLen { // overflow
+			break
+		}
+		first = next
+	}
+	return result
+}
+
+// FreeBlocks returns the maximal aligned prefixes inside parent that are not
+// covered by any prefix in used. Prefixes of the other address family or
+// outside parent are ignored; a used prefix that covers parent leaves no
+// free space.
+func FreeBlocks(parent netip.Prefix, used []netip.Prefix) []netip.Prefix {
+	if !parent.IsValid() {
+		return nil
+	}
+	parent = parent.Masked()
+	is4 := parent.Addr().Is4()
+	first, last := PrefixRange(parent)
+
+	type span struct{ first, last Uint128 }
+	spans := make([]span, 0, len(used))
+	for _, u := range used {
+		if !PrefixesOverlap(parent, u) {
+			continue
+		}
+		// Prefixes either nest or are disjoint, so clamping to the parent
+		// only matters when u covers all of it.
+		s, e := PrefixRange(u)
+		if s.Cmp(first) < 0 {
+			s = first
+		}
+		if e.Cmp(last) > 0 {
+			e = last
+		}
+		spans = append(spans, span{s, e})
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].first.Cmp(spans[j].first) < 0 })
+
+	var free []netip.Prefix
+	cursor := first
+	for _, sp := range spans {
+		if sp.first.Cmp(cursor) > 0 {
+			free = append(free, RangeToPrefixes(cursor, sp.first.SubOne(), is4)...)
+		}
+		if sp.last.Cmp(last) >= 0 {
+			// Covered through the end of the parent; stopping here also
+			// avoids wrapping past the top of the address space.
+			return free
+		}
+		if next := sp.last.AddOne(); next.Cmp(cursor) > 0 {
+			cursor = next
+		}
+	}
+	return append(free, RangeToPrefixes(cursor, last, is4)...)
+}
@@ -0,0 +1,151 @@
+package cidr
+
+import (
+	"net/netip"
+	"sort"
+)
+
+// IndexEntry is a prefix stored in an Index together with the ID of the
+// record it belongs to.
+type IndexEntry struct {
+	Prefix netip.Prefix
+	ID     int64
+}
+
+// Index is an immutable interval tree over prefixes. It answers overlap and
+// containment queries in O(log n + k) and is safe for concurrent readers.
+// IPv4 and IPv6 prefixes are kept in separate trees, so queries never match
+// across families.
+type Index struct {
+	v4, v6 indexTree
+}
+
+// NewIndex builds an Index from entries. Invalid prefixes are dropped;
+// host bits are masked off.
+func NewIndex(entries []IndexEntry) *Index {
+	var v4, v6 []indexNode
+	for _, e := range entries {
+		if !e.Prefix.IsValid() {
+			continue
+		}
+		e.Prefix = e.Prefix.Masked()
+		first, last := PrefixRange(e.Prefix)
+		n := indexNode{first: first, last: last, entry: e}
+		if e.Prefix.Addr().Is4() {
+			v4 = append(v4, n)
+		} else {
+			v6 = append(v6, n)
+		}
+	}
+	return &Index{v4: newIndexTree(v4), v6: newIndexTree(v6)}
+}
+
+// Len returns the number of prefixes in the index.
+func (x *Index) Len() int {
+	return len(x.v4.nodes) + len(x.v6.nodes)
+}
+
+// Overlapping returns the entries whose prefix shares any address with p,
+// ordered by address and then prefix length.
+func (x *Index) Overlapping(p netip.Prefix) []IndexEntry {
+	return x.query(p, func(n *indexNode, first, last Uint128) bool { return true })
+}
+
+// Containing returns the entries whose prefix contains p (including p
+// itself), outermost first.
+func (x *Index) Containing(p netip.Prefix) []IndexEntry {
+	return x.query(p, func(n *indexNode, first, last Uint128) bool {
+		return n.first.Cmp(first) <= 0 && n.last.Cmp(last) >= 0
+	})
+}
+
+// ContainedBy returns the entries whose prefix lies within p (including p
+// itself), ordered by address and then prefix length.
+func (x *Index) ContainedBy(p netip.Prefix) []IndexEntry {
+	return x.query(p, func(n *indexNode, first, last Uint128) bool {
+		return n.first.Cmp(first) >= 0 && n.last.Cmp(last) <= 0
+	})
+}
+
+func (x *Index) query(p netip.Prefix, keep func(n *indexNode, first, last Uint128) bool) []IndexEntry {
+	if !p.IsValid() {
+		return nil
+	}
+	tree := &x.v6
+	if p.Addr().Is4() {
+		tree = &x.v4
+	}
+	first, last := PrefixRange(p)
+	var out []IndexEntry
+	tree.overlapping(0, len(tree.nodes), first, last, func(n *indexNode) {
+		if keep(n, first, last) {
+			out = append(out, n.entry)
+		}
+	})
+	return out
+}
+
+type indexNode struct {
+	first, last Uint128
+	entry       IndexEntry
+}
+
+// indexTree is an augmented interval tree laid out implicitly over a slice
+// sorted by start address: the root of nodes[lo:hi] is its midpoint, and
+// maxLast[i] holds the largest end address in the subtree rooted at i.
+type indexTree struct {
+	nodes   []indexNode
+	maxLast []Uint128
+}
+
+func newIndexTree(nodes []indexNode) indexTree {
+	sort.Slice(nodes, func(i, j int) bool {
+		if c := nodes[i].first.Cmp(nodes[j].first); c != 0 {
+			return c < 0
+		}
+		if b1, b2 := nodes[i].entry.Prefix.Bits(), nodes[j].entry.Prefix.Bits(); b1 != b2 {
+			return b1 < b2
+		}
+		return nodes[i].entry.ID < nodes[j].entry.ID
+	})
+	t := indexTree{nodes: nodes, maxLast: make([]Uint128, len(nodes))}
+	t.build(0, len(nodes))
+	return t
+}
+
+func (t *indexTree) build(lo, hi int) (Uint128, bool) {
+	if lo >= hi {
+		return Uint128{}, false
+	}
+	mid := int(uint(lo+hi) >> 1)
+	m := t.nodes[mid].last
+	if l, ok := t.build(lo, mid); ok && l.Cmp(m) > 0 {
+		m = l
+	}
+	if r, ok := t.build(mid+1, hi); ok && r.Cmp(m) > 0 {
+		m = r
+	}
+	t.maxLast[mid] = m
+	return m, true
+}
+
+// overlapping calls fn, in order, for every node in nodes[lo:hi] whose range
+// intersects [first, last].
+func (t *indexTree) overlapping(lo, hi int, first, last Uint128, fn func(*indexNode)) {
+	if lo >= hi {
+		return
+	}
+	mid := int(uint(lo+hi) >> 1)
+	if t.maxLast[mid].Cmp(first) < 0 {
+		return // every range in this subtree ends before the query starts
+	}
+	t.overlapping(lo, mid, first, last, fn)
+	n := &t.nodes[mid]
+	if n.first.Cmp(last) > 0 {
+		return // this node and everything to its right start after the query
+	}
+	if n.last.Cmp(first) >= 0 {
+		fn(n)
+	}
+	t.overlapping(mid+1, hi, first, last, fn)
+}
@@ -0,0 +1,130 @@
+package cidr
+
+import (
+	"fmt"
+	"math/rand"
+	"net/netip"
+	"reflect"
+	"testing"
+)
+
+func TestIndexQueries(t *testing.T) {
+	idx := NewIndex([]IndexEntry{
+		{netip.MustParsePrefix("10.0.0.0/8"), 1},
+		{netip.MustParsePrefix("10.1.0.0/16"), 2},
+		{netip.MustParsePrefix("10.1.2.0/24"), 3},
+		{netip.MustParsePrefix("10.2.0.0/16"), 4},
+		{netip.MustParsePrefix("192.168.0.0/16"), 5},
+		{netip.MustParsePrefix("2001:db8::/32"), 6},
+		{netip.MustParsePrefix("2001:db8:1::/48"), 7},
+	})
+	ids := func(es []IndexEntry) []int64 {
+		out := []int64{}
+		for _, e := range es {
+			out = append(out, e.ID)
+		}
+		return out
+	}
+	tests := []struct {
+		name string
+		got  []IndexEntry
+		want []int64
+	}{
+		{"overlapping parent", idx.Overlapping(netip.MustParsePrefix("10.1.0.0/16")), []int64{1, 2, 3}},
+		{"overlapping disjoint", idx.Overlapping(netip.MustParsePrefix("172.16.0.0/12")), []int64{}},
+		{"containing host", idx.Containing(netip.MustParsePrefix("10.1.2.5/32")), []int64{1, 2, 3}},
+		{"containing sibling", idx.Containing(netip.MustParsePrefix("10.3.0.0/16")), []int64{1}},
+		{"contained by /8", idx.ContainedBy(netip.MustParsePrefix("10.0.0.0/8")), []int64{1, 2, 3, 4}},
+		{"contained by /15", idx.ContainedBy(netip.MustParsePrefix("10.0.0.0/15")), []int64{2, 3}},
+		{"ipv6 containing", idx.Containing(netip.MustParsePrefix("2001:db8:1:2::/64")), []int64{6, 7}},
+		{"families never mix", idx.Overlapping(netip.MustParsePrefix("::/0")), []int64{6, 7}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ids(tt.got); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if idx.Len() != 7 {
+		t.Errorf("Len() = %d, want 7", idx.Len())
+	}
+}
+
+// TestIndexMatchesLinearScan cross-checks the tree against a brute-force
+// scan over random prefixes.
+func TestIndexMatchesLinearScan(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	randPrefix := func() netip.Prefix {
+		a := netip.AddrFrom4([4]byte{10, byte(rng.Intn(4)), byte(rng.Intn(256)), 0})
+		return netip.PrefixFrom(a, 12+rng.Intn(13)).Masked()
+	}
+	var entries []IndexEntry
+	for i := 0; i < 500; i++ {
+		entries = append(entries, IndexEntry{Prefix: randPrefix(), ID: int64(i)})
+	}
+	idx := NewIndex(entries)
+	for i := 0; i < 200; i++ {
+		q := randPrefix()
+		var overlap, containing, within []int64
+		for _, e := range entries {
+			if PrefixesOverlap(e.Prefix, q) {
+				overlap = append(overlap, e.ID)
+			}
+			if PrefixContains(e.Prefix, q) {
+				containing = append(containing, e.ID)
+			}
+			if PrefixContains(q, e.Prefix) {
+				within = append(within, e.ID)
+			}
+		}
+		check := func(name string, got []IndexEntry, want []int64) {
+			seen := map[int64]bool{}
+			for _, e := range got {
+				seen[e.ID] = true
+			}
+			if len(got) != len(want) {
+				t.Fatalf("%s(%s): got %d entries, want %d", name, q, len(got), len(want))
+			}
+			for _, id := range want {
+				if !seen[id] {
+					t.Fatalf("%s(%s): missing id %d", name, q, id)
+				}
+			}
+		}
+		check("Overlapping", idx.Overlapping(q), overlap)
+		check("Containing", idx.Containing(q), containing)
+		check("ContainedBy", idx.ContainedBy(q), within)
+	}
+}
+
+func TestFreeBlocks(t *testing.T) {
+	tests := []struct {
+		parent string
+		used   []string
+		want   []string
+	}{
+		{"10.0.0.0/24", nil, []string{"10.0.0.0/24"}},
+		{"10.0.0.0/24", []string{"10.0.0.0/26"}, []string{"10.0.0.64/26", "10.0.0.128/25"}},
+		{"10.0.0.0/24", []string{"10.0.0.64/26", "10.0.0.64/27"}, []string{"10.0.0.0/26", "10.0.0.128/25"}},
+		{"10.0.0.0/24", []string{"10.0.0.0/16"}, nil},
+		{"10.0.0.0/24", []string{"10.0.1.0/24", "2001:db8::/32"}, []string{"10.0.0.0/24"}},
+		{"255.255.255.0/24", []string{"255.255.255.128/25"}, []string{"255.255.255.0/25"}},
+		{"2001:db8::/47", []string{"2001:db8::/48"}, []string{"2001:db8:1::/48"}},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.parent, tt.used), func(t *testing.T) {
+			var used []netip.Prefix
+			for _, u := range tt.used {
+				used = append(used, netip.MustParsePrefix(u))
+			}
+			var got []string
+			for _, p := range FreeBlocks(netip.MustParsePrefix(tt.parent), used) {
+				got = append(got, p.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FreeBlocks = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package cidr
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+	"net/netip"
+)
+
+// Uint128 is an unsigned 128-bit integer used for address arithmetic that
+// has to cover the whole IPv6 space. IPv4 addresses occupy the low 32 bits.
+type Uint128 struct {
+	Hi, Lo uint64
+}
+
+// Uint128From64 returns v as a Uint128.
+func Uint128From64(v uint64) Uint128 {
+	return Uint128{Lo: v}
+}
+
+// IsZero reports whether u is zero.
+func (u Uint128) IsZero() bool {
+	return u.Hi == 0 && u.Lo == 0
+}
+
+// Cmp returns -1, 0 or +1 depending on whether u is less than, equal to or
+// greater than v.
+func (u Uint128) Cmp(v Uint128) int {
+	switch {
+	case u.Hi < v.Hi:
+		return -1
+	case u.Hi > v.Hi:
+		return 1
+	case u.Lo < v.Lo:
+		return -1
+	case u.Lo > v.Lo:
+		return 1
+	}
+	return 0
+}
+
+// Add returns u+v, wrapping on overflow.
+func (u Uint128) Add(v Uint128) Uint128 {
+	lo, carry := bits.Add64(u.Lo, v.Lo, 0)
+	hi, _ := bits.Add64(u.Hi, v.Hi, carry)
+	return Uint128{Hi: hi, Lo: lo}
+}
+
+// Sub returns u-v, wrapping on underflow.
+func (u Uint128) Sub(v Uint128) Uint128 {
+	lo, borrow := bits.Sub64(u.Lo, v.Lo, 0)
+	hi, _ := bits.Sub64(u.Hi, v.Hi, borrow)
+	return Uint128{Hi: hi, Lo: lo}
+}
+
+// AddOne returns u+1, wrapping on overflow.
+func (u Uint128) AddOne() Uint128 {
+	return u.Add(Uint128{Lo: 1})
+}
+
+// SubOne returns u-1, wrapping on underflow.
+func (u Uint128) SubOne() Uint128 {
+	return u.Sub(Uint128{Lo: 1})
+}
+
+// Lsh returns u<<n. Shifts of 128 or more yield zero.
+func (u Uint128) Lsh(n uint) Uint128 {
+	switch {
+	case n >= 128:
+		return Uint128{}
+	case n >= 64:
+		return Uint128{Hi: u.Lo << (n - 64)}
+	case n == 0:
+		return u
+	}
+	return Uint128{Hi: u.Hi<<n | u.Lo>>(64-n), Lo: u.Lo << n}
+}
+
+// TrailingZeros returns the number of trailing zero bits in u; 128 for zero.
+func (u Uint128) TrailingZeros() int {
+	if u.Lo != 0 {
+		return bits.TrailingZeros64(u.Lo)
+	}
+	return 64 + bits.TrailingZeros64(u.Hi)
+}
+
+// LeadingZeros returns the number of leading zero bits in u; 128 for zero.
+func (u Uint128) LeadingZeros() int {
+	if u.Hi != 0 {
+		return bits.LeadingZeros64(u.Hi)
+	}
+	return 64 + bits.LeadingZeros64(u.Lo)
+}
+
+// Uint64 returns u as a uint64, saturating at math.MaxUint64.
+func (u Uint128) Uint64() uint64 {
+	if u.Hi != 0 {
+		return math.MaxUint64
+	}
+	return u.Lo
+}
+
+// Int64 returns u as an int64, saturating at math.MaxInt64.
+func (u Uint128) Int64() int64 {
+	if u.Hi != 0 || u.Lo > math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return int64(u.Lo)
+}
+
+// Float64 returns the nearest float64 to u.
+func (u Uint128) Float64() float64 {
+	return float64(u.Hi)*(1<<64) + float64(u.Lo)
+}
+
+// AddrToUint128 converts an address to its integer value. IPv4 (and
+// IPv4-mapped IPv6) addresses map into the low 32 bits.
+func AddrToUint128(a netip.Addr) Uint128 {
+	if a.Is4() || a.Is4In6() {
+		b := a.Unmap().As4()
+		return Uint128{Lo: uint64(binary.BigEndian.Uint32(b[:]))}
+	}
+	b := a.As16()
+	return Uint128{Hi: binary.BigEndian.Uint64(b[:8]), Lo: binary.BigEndian.Uint64(b[8:])}
+}
+
+// Uint128ToAddr converts an integer back into an address of the requested
+// family. For IPv4 only the low 32 bits are used.
+func Uint128ToAddr(u Uint128, is4 bool) netip.Addr {
+	if is4 {
+		var b [4]byte
+		binary.BigEndian.PutUint32(b[:], uint32(u.Lo))
+		return netip.AddrFrom4(b)
+	}
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], u.Hi)
+	binary.BigEndian.PutUint64(b[8:], u.Lo)
+	return netip.AddrFrom16(b)
+}
+
+// PrefixRange returns the first and last address of p as integers.
+func PrefixRange(p netip.Prefix) (first, last Uint128) {
+	p = p.Masked()
+	first = AddrToUint128(p.Addr())
+	hostBits := uint(p.Addr().BitLen() - p.Bits())
+	return first, first.Add(Uint128{Lo: 1}.Lsh(hostBits).SubOne())
+}
+
+// AddressCount returns the number of addresses in p. The full IPv6 space
+// (::/0) does not fit and saturates at the maximum Uint128 value.
+func AddressCount(p netip.Prefix) Uint128 {
+	hostBits := uint(p.Addr().BitLen() - p.Bits())
+	if hostBits >= 128 {
+		return Uint128{Hi: math.MaxUint64, Lo: math.MaxUint64}
+	}
+	return Uint128{Lo: 1}.Lsh(hostBits)
+}
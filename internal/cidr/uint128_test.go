@@ -0,0 +1,71 @@
+package cidr
+
+import (
+	"math"
+	"net/netip"
+	"testing"
+)
+
+func TestUint128Arithmetic(t *testing.T) {
+	maxLo := Uint128{Lo: math.MaxUint64}
+	if got := maxLo.AddOne(); got != (Uint128{Hi: 1}) {
+		t.Errorf("carry: got %+v", got)
+	}
+	if got := (Uint128{Hi: 1}).SubOne(); got != maxLo {
+		t.Errorf("borrow: got %+v", got)
+	}
+	if got := (Uint128{}).SubOne(); got != (Uint128{Hi: math.MaxUint64, Lo: math.MaxUint64}) {
+		t.Errorf("underflow should wrap: got %+v", got)
+	}
+	if got := (Uint128{Lo: 1}).Lsh(64); got != (Uint128{Hi: 1}) {
+		t.Errorf("Lsh(64): got %+v", got)
+	}
+	if got := (Uint128{Lo: 3}).Lsh(63); got != (Uint128{Hi: 1, Lo: 1 << 63}) {
+		t.Errorf("Lsh(63): got %+v", got)
+	}
+	if got := (Uint128{Lo: 1}).Lsh(128); !got.IsZero() {
+		t.Errorf("Lsh(128): got %+v", got)
+	}
+	if (Uint128{Hi: 1}).Cmp(maxLo) != 1 || maxLo.Cmp(Uint128{Hi: 1}) != -1 || maxLo.Cmp(maxLo) != 0 {
+		t.Error("Cmp ordering is wrong")
+	}
+	if tz := (Uint128{Hi: 1}).TrailingZeros(); tz != 64 {
+		t.Errorf("TrailingZeros = %d, want 64", tz)
+	}
+	if lz := (Uint128{Lo: 1}).LeadingZeros(); lz != 127 {
+		t.Errorf("LeadingZeros = %d, want 127", lz)
+	}
+	if v := (Uint128{Hi: 1}).Int64(); v != math.MaxInt64 {
+		t.Errorf("Int64 should saturate, got %d", v)
+	}
+	if v := (Uint128{Hi: 1}).Uint64(); v != math.MaxUint64 {
+		t.Errorf("Uint64 should saturate, got %d", v)
+	}
+}
+
+func TestAddrUint128RoundTrip(t *testing.T) {
+	for _, s := range []string{"0.0.0.0", "10.1.2.3", "255.255.255.255", "::", "2001:db8::1", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"} {
+		a := netip.MustParseAddr(s)
+		if got := Uint128ToAddr(AddrToUint128(a), a.Is4()); got != a {
+			t.Errorf("round trip %s = %s", s, got)
+		}
+	}
+}
+
+func TestAddressCount(t *testing.T) {
+	tests := []struct {
+		prefix string
+		want   Uint128
+	}{
+		{"10.0.0.0/24", Uint128{Lo: 256}},
+		{"0.0.0.0/0", Uint128{Lo: 1 << 32}},
+		{"2001:db8::/64", Uint128{Lo: 1}.Lsh(64)},
+		{"2001:db8::/128", Uint128{Lo: 1}},
+		{"::/0", Uint128{Hi: math.MaxUint64, Lo: math.MaxUint64}},
+	}
+	for _, tt := range tests {
+		if got := AddressCount(netip.MustParsePrefix(tt.prefix)); got != tt.want {
+			t.Errorf("AddressCount(%s) = %+v, want %+v", tt.prefix, got, tt.want)
+		}
+	}
+}
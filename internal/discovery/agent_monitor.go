@@ -0,0 +1,76 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+	"cloudpam/internal/webhook"
+)
+
+// Agents are stale after 5 minutes without a heartbeat and offline after 15.
+const (
+	AgentStaleAfter   = 5 * time.Minute
+	AgentOfflineAfter = 15 * time.Minute
+)
+
+// AgentStatusAt derives an agent's health from its last heartbeat.
+func AgentStatusAt(lastSeen, now time.Time) domain.AgentStatus {
+	elapsed := now.Sub(lastSeen)
+	if elapsed < AgentStaleAfter {
+		return domain.AgentStatusHealthy
+	} else if elapsed < AgentOfflineAfter {
+		return domain.AgentStatusStale
+	}
+	return domain.AgentStatusOffline
+}
+
+// AgentMonitor publishes agent.offline when an approved agent crosses the
+// offline threshold. Agent status is computed at read time, so the monitor
+// remembers which agents it has already reported and only fires on the
+// transition; an agent that reconnects and drops again fires again.
+type AgentMonitor struct {
+	store     storage.DiscoveryStore
+	publisher webhook.Publisher
+	offline   map[uuid.UUID]bool
+	primed    bool
+}
+
+// NewAgentMonitor creates an AgentMonitor.
+func NewAgentMonitor(store storage.DiscoveryStore, publisher webhook.Publisher) *AgentMonitor {
+	return &AgentMonitor{store: store, publisher: publisher, offline: make(map[uuid.UUID]bool)}
+}
+
+// Check compares every agent's status at now with the previous check. The
+// first check only records state, so agents already offline at startup are
+// not reported. Check is not safe for concurrent use.
+func (m *AgentMonitor) Check(ctx context.Context, now time.Time) error {
+	agents, err := m.store.ListAgents(ctx, 0)
+	if err != nil {
+		return fmt.Errorf("list agents: %w", err)
+	}
+	offline := make(map[uuid.UUID]bool, len(agents))
+	for _, a := range agents {
+		if a.ApprovalStatus != domain.AgentApprovalApproved || AgentStatusAt(a.LastSeenAt, now) != domain.AgentStatusOffline {
+			continue
+		}
+		offline[a.ID] = true
+		if m.primed && !m.offline[a.ID] && m.publisher != nil {
+			m.publisher.Publish(ctx, domain.WebhookEventAgentOffline, webhook.AgentOfflineData{
+				AgentID:    a.ID,
+				Name:       a.Name,
+				AccountID:  a.AccountID,
+				Hostname:   a.Hostname,
+				Version:    a.Version,
+				LastSeenAt: a.LastSeenAt,
+			})
+		}
+	}
+	m.offline = offline
+	m.primed = true
+	return nil
+}
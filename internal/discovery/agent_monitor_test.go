@@ -0,0 +1,92 @@
+package discovery
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+	"cloudpam/internal/webhook"
+)
+
+type recordingPublisher struct {
+	offline []webhook.AgentOfflineData
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, t domain.WebhookEventType, data any) {
+	if t == domain.WebhookEventAgentOffline {
+		p.offline = append(p.offline, data.(webhook.AgentOfflineData))
+	}
+}
+
+func TestAgentStatusAt(t *testing.T) {
+	now := time.Now()
+	cases := []struct {
+		ago  time.Duration
+		want domain.AgentStatus
+	}{
+		{time.Minute, domain.AgentStatusHealthy},
+		{AgentStaleAfter, domain.AgentStatusStale},
+		{AgentOfflineAfter - time.Second, domain.AgentStatusStale},
+		{AgentOfflineAfter, domain.AgentStatusOffline},
+	}
+	for _, tc := range cases {
+		if got := AgentStatusAt(now.Add(-tc.ago), now); got != tc.want {
+			t.Errorf("AgentStatusAt(-%v) = %s, want %s", tc.ago, got, tc.want)
+		}
+	}
+}
+
+func TestAgentMonitor_PublishesOnTransition(t *testing.T) {
+	ctx := context.Background()
+	ds := storage.NewMemoryDiscoveryStore(storage.NewMemoryStore())
+	pub := &recordingPublisher{}
+	mon := NewAgentMonitor(ds, pub)
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	upsert := func(id uuid.UUID, name string, approval domain.AgentApprovalStatus, lastSeen time.Time) {
+		t.Helper()
+		if err := ds.UpsertAgent(ctx, domain.DiscoveryAgent{
+			ID: id, Name: name, AccountID: 1, ApprovalStatus: approval, LastSeenAt: lastSeen, CreatedAt: start,
+		}); err != nil {
+			t.Fatalf("UpsertAgent(%s): %v", name, err)
+		}
+	}
+	alreadyDown, live, pending := uuid.New(), uuid.New(), uuid.New()
+	upsert(alreadyDown, "already-down", domain.AgentApprovalApproved, start.Add(-time.Hour))
+	upsert(live, "live", domain.AgentApprovalApproved, start)
+	upsert(pending, "pending", domain.AgentApprovalPending, start)
+
+	// The first check only primes state.
+	if err := mon.Check(ctx, start); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if len(pub.offline) != 0 {
+		t.Fatalf("first check published %v", pub.offline)
+	}
+
+	later := start.Add(AgentOfflineAfter)
+	if err := mon.Check(ctx, later); err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if len(pub.offline) != 1 || pub.offline[0].AgentID != live || pub.offline[0].Name != "live" {
+		t.Fatalf("published %+v, want only the live agent", pub.offline)
+	}
+
+	// Still offline: no repeat.
+	_ = mon.Check(ctx, later.Add(time.Minute))
+	if len(pub.offline) != 1 {
+		t.Fatalf("repeat check published again: %+v", pub.offline)
+	}
+
+	// Reconnect, then drop again.
+	upsert(live, "live", domain.AgentApprovalApproved, later)
+	_ = mon.Check(ctx, later)
+	_ = mon.Check(ctx, later.Add(AgentOfflineAfter))
+	if len(pub.offline) != 2 {
+		t.Fatalf("second outage published %d events, want 2 total", len(pub.offline))
+	}
+}
@@ -1,10 +1,11 @@
-// Package aws provides an AWS VPC/subnet/EIP discovery collector.
+// Package aws provides an AWS VPC/subnet/EIP/ENI discovery collector.
 package aws
 
 import (
 	"context"
 	"errors"
 	"fmt"
+	"net/netip"
 	"strings"
 	"time"
 
@@ -17,6 +18,7 @@ import (
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 
+	"cloudpam/internal/cidr"
 	"cloudpam/internal/domain"
 	"cloudpam/internal/observability"
 )
@@ -47,7 +49,7 @@ func endEC2Span(span trace.Span, resourceCount int, err error) {
 	span.End()
 }
 
-// Collector discovers AWS VPCs, subnets, and Elastic IPs.
+// Collector discovers AWS VPCs, subnets, Elastic IPs, and network interfaces.
 type Collector struct {
 	credsProvider aws.CredentialsProvider
 	loadConfig    func(context.Context, string, aws.CredentialsProvider) (aws.Config, error)
@@ -58,6 +60,7 @@ type ec2API interface {
 	DescribeVpcs(context.Context, *ec2.DescribeVpcsInput, ...func(*ec2.Options)) (*ec2.DescribeVpcsOutput, error)
 	DescribeSubnets(context.Context, *ec2.DescribeSubnetsInput, ...func(*ec2.Options)) (*ec2.DescribeSubnetsOutput, error)
 	DescribeAddresses(context.Context, *ec2.DescribeAddressesInput, ...func(*ec2.Options)) (*ec2.DescribeAddressesOutput, error)
+	DescribeNetworkInterfaces(context.Context, *ec2.DescribeNetworkInterfacesInput, ...func(*ec2.Options)) (*ec2.DescribeNetworkInterfacesOutput, error)
 }
 
 // New creates a new AWS collector using the default credential chain.
@@ -74,7 +77,7 @@ func NewWithCredentials(cp aws.CredentialsProvider) *Collector {
 // Provider returns "aws".
 func (c *Collector) Provider() string { return "aws" }
 
-// Discover discovers VPCs, subnets, and Elastic IPs for the given account.
+// Discover discovers VPCs, subnets, Elastic IPs, and network interfaces for the given account.
 // Authentication uses the default AWS credential chain (env vars, instance profile, etc.).
 // The account's Regions field determines which regions to query. If empty, uses default config region.
 func (c *Collector) Discover(ctx context.Context, account domain.Account) (resources []domain.DiscoveredResource, err error) {
@@ -128,6 +131,7 @@ func (c *Collector) Discover(ctx context.Context, account domain.Account) (resou
 			errs = append(errs, fmt.Errorf("discover subnets in region %s: %w", displayRegion(actualRegion), err))
 			continue
 		}
+		linkIPv6Subnets(vpcs, subnets)
 		allResources = append(allResources, subnets...)
 
 		// Discover Elastic IPs
@@ -137,6 +141,14 @@ func (c *Collector) Discover(ctx context.Context, account domain.Account) (resou
 			continue
 		}
 		allResources = append(allResources, eips...)
+
+		// Discover network interfaces (ENIs)
+		enis, err := c.discoverNetworkInterfaces(ctx, client, account, actualRegion, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("discover network interfaces in region %s: %w", displayRegion(actualRegion), err))
+			continue
+		}
+		allResources = append(allResources, enis...)
 	}
 
 	if len(errs) > 0 {
@@ -229,6 +241,43 @@ func (c *Collector) discoverVPCs(ctx context.Context, client ec2API, account dom
 				DiscoveredAt: now,
 				LastSeenAt:   now,
 			})
+
+			// Each associated IPv6 block is tracked as its own resource so it
+			// can be imported as an IPv6 pool next to the VPC's IPv4 pool.
+			for _, assoc := range vpc.Ipv6CidrBlockAssociationSet {
+				if assoc.Ipv6CidrBlockState != nil && assoc.Ipv6CidrBlockState.State != ec2types.VpcCidrBlockStateCodeAssociated {
+					continue
+				}
+				block := aws.ToString(assoc.Ipv6CidrBlock)
+				if block == "" {
+					continue
+				}
+				v6meta := map[string]string{
+					"address_family": "ipv6",
+					"vpc_id":         aws.ToString(vpc.VpcId),
+					"ip_source":      string(assoc.IpSource),
+				}
+				if assoc.Ipv6Pool != nil {
+					v6meta["ipv6_pool"] = *assoc.Ipv6Pool
+				}
+				if assoc.NetworkBorderGroup != nil {
+					v6meta["network_border_group"] = *assoc.NetworkBorderGroup
+				}
+				resources = append(resources, domain.DiscoveredResource{
+					ID:           uuid.New(),
+					AccountID:    account.ID,
+					Provider:     "aws",
+					Region:       region,
+					ResourceType: domain.ResourceTypeVPC,
+					ResourceID:   ipv6ResourceID(assoc.AssociationId, aws.ToString(vpc.VpcId), block),
+					Name:         name,
+					CIDR:         block,
+					Status:       domain.DiscoveryStatusActive,
+					Metadata:     v6meta,
+					DiscoveredAt: now,
+					LastSeenAt:   now,
+				})
+			}
 		}
 
 		next := nextPageToken(token, out.NextToken)
@@ -262,6 +311,9 @@ func (c *Collector) discoverSubnets(ctx context.Context, client ec2API, account
 			if subnet.AvailableIpAddressCount != nil {
 				meta["available_ips"] = fmt.Sprintf("%d", *subnet.AvailableIpAddressCount)
 			}
+			if subnet.Ipv6Native != nil && *subnet.Ipv6Native {
+				meta["ipv6_native"] = "true"
+			}
 
 			resources = append(resources, domain.DiscoveredResource{
 				ID:               uuid.New(),
@@ -278,6 +330,39 @@ func (c *Collector) discoverSubnets(ctx context.Context, client ec2API, account
 				DiscoveredAt:     now,
 				LastSeenAt:       now,
 			})
+
+			for _, assoc := range subnet.Ipv6CidrBlockAssociationSet {
+				if assoc.Ipv6CidrBlockState != nil && assoc.Ipv6CidrBlockState.State != ec2types.SubnetCidrBlockStateCodeAssociated {
+					continue
+				}
+				block := aws.ToString(assoc.Ipv6CidrBlock)
+				if block == "" {
+					continue
+				}
+				// The parent is re-pointed at the VPC's matching IPv6 block by
+				// linkIPv6Subnets once both resource sets are known.
+				parent := vpcID
+				resources = append(resources, domain.DiscoveredResource{
+					ID:               uuid.New(),
+					AccountID:        account.ID,
+					Provider:         "aws",
+					Region:           region,
+					ResourceType:     domain.ResourceTypeSubnet,
+					ResourceID:       ipv6ResourceID(assoc.AssociationId, aws.ToString(subnet.SubnetId), block),
+					Name:             name,
+					CIDR:             block,
+					ParentResourceID: &parent,
+					Status:           domain.DiscoveryStatusActive,
+					Metadata: map[string]string{
+						"address_family":    "ipv6",
+						"subnet_id":         aws.ToString(subnet.SubnetId),
+						"availability_zone": az,
+						"ip_source":         string(assoc.IpSource),
+					},
+					DiscoveredAt: now,
+					LastSeenAt:   now,
+				})
+			}
 		}
 
 		next := nextPageToken(token, out.NextToken)
@@ -339,6 +424,132 @@ func (c *Collector) discoverElasticIPs(ctx context.Context, client ec2API, accou
 	return resources, nil
 }
 
+// discoverNetworkInterfaces lists ENIs with their private IPv4 and IPv6
+// addresses. Each ENI becomes one network_interface resource whose CIDR is the
+// primary private address and whose parent is its subnet.
+func (c *Collector) discoverNetworkInterfaces(ctx context.Context, client ec2API, account domain.Account, region string, now time.Time) (resources []domain.DiscoveredResource, err error) {
+	ctx, span := startEC2Span(ctx, "DescribeNetworkInterfaces", region)
+	defer func() { endEC2Span(span, len(resources), err) }()
+
+	var token *string
+	for {
+		out, err := client.DescribeNetworkInterfaces(ctx, &ec2.DescribeNetworkInterfacesInput{NextToken: token})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, eni := range out.NetworkInterfaces {
+			primary := aws.ToString(eni.PrivateIpAddress)
+			addrs := []string{}
+			if primary != "" {
+				addrs = append(addrs, primary)
+			}
+			for _, ip := range eni.PrivateIpAddresses {
+				if a := aws.ToString(ip.PrivateIpAddress); a != "" && a != primary {
+					addrs = append(addrs, a)
+				}
+			}
+			for _, ip := range eni.Ipv6Addresses {
+				if a := aws.ToString(ip.Ipv6Address); a != "" {
+					addrs = append(addrs, a)
+				}
+			}
+			if len(addrs) == 0 {
+				continue
+			}
+			hostCIDR := ""
+			if a, err := netip.ParseAddr(addrs[0]); err == nil {
+				hostCIDR = netip.PrefixFrom(a, a.BitLen()).String()
+			}
+
+			subnetID := aws.ToString(eni.SubnetId)
+			meta := map[string]string{
+				domain.NICMetaAddresses: strings.Join(addrs, ","),
+				"subnet_id":             subnetID,
+				"vpc_id":                aws.ToString(eni.VpcId),
+				"interface_type":        string(eni.InterfaceType),
+				"status":                string(eni.Status),
+			}
+			if mac := aws.ToString(eni.MacAddress); mac != "" {
+				meta[domain.NICMetaMACAddress] = mac
+			}
+			if dns := aws.ToString(eni.PrivateDnsName); dns != "" {
+				meta[domain.NICMetaHostname] = dns
+			}
+			if eni.Attachment != nil && eni.Attachment.InstanceId != nil {
+				meta["instance_id"] = *eni.Attachment.InstanceId
+			}
+			if desc := aws.ToString(eni.Description); desc != "" {
+				meta["description"] = desc
+			}
+			var parent *string
+			if subnetID != "" {
+				parent = &subnetID
+			}
+
+			resources = append(resources, domain.DiscoveredResource{
+				ID:               uuid.New(),
+				AccountID:        account.ID,
+				Provider:         "aws",
+				Region:           region,
+				ResourceType:     domain.ResourceTypeNetworkInterface,
+				ResourceID:       aws.ToString(eni.NetworkInterfaceId),
+				Name:             extractTagName(eni.TagSet),
+				CIDR:             hostCIDR,
+				ParentResourceID: parent,
+				Status:           domain.DiscoveryStatusActive,
+				Metadata:         meta,
+				DiscoveredAt:     now,
+				LastSeenAt:       now,
+			})
+		}
+
+		next := nextPageToken(token, out.NextToken)
+		if next == "" {
+			break
+		}
+		token = aws.String(next)
+	}
+	return resources, nil
+}
+
+// ipv6ResourceID identifies an IPv6 CIDR association. AWS association IDs are
+// stable for the life of the block; the owner/CIDR pair is only a fallback.
+func ipv6ResourceID(associationID *string, ownerID, block string) string {
+	if id := aws.ToString(associationID); id != "" {
+		return id
+	}
+	return ownerID + "/" + block
+}
+
+// linkIPv6Subnets points each IPv6 subnet block at the IPv6 block of its VPC
+// that contains it. Blocks with no matching VPC block become top-level so
+// they are never nested under the VPC's IPv4 range.
+func linkIPv6Subnets(vpcs, subnets []domain.DiscoveredResource) {
+	for i := range subnets {
+		sub := &subnets[i]
+		if sub.Metadata["address_family"] != "ipv6" || sub.ParentResourceID == nil {
+			continue
+		}
+		vpcID := *sub.ParentResourceID
+		sub.ParentResourceID = nil
+		sp, err := netip.ParsePrefix(sub.CIDR)
+		if err != nil {
+			continue
+		}
+		for _, v := range vpcs {
+			if v.Metadata["address_family"] != "ipv6" || v.Metadata["vpc_id"] != vpcID {
+				continue
+			}
+			if vp, err := netip.ParsePrefix(v.CIDR); err == nil && cidr.PrefixContains(vp, sp) {
+				parent := v.ResourceID
+				sub.ParentResourceID = &parent
+				break
+			}
+		}
+	}
+}
+
 // extractTagName extracts the "Name" tag from a list of EC2 tags.
 func extractTagName(tags []ec2types.Tag) string {
 	for _, tag := range tags {
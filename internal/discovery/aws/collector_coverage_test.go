@@ -316,6 +316,11 @@ func TestCovDiscoverReportsPerCallFailures(t *testing.T) {
 			&fakeEC2{addrErr: errors.New("ec2:DescribeAddresses denied")},
 			"discover Elastic IPs in region us-east-1",
 		},
+		{
+			"network interface failure",
+			&fakeEC2{eniErr: errors.New("ec2:DescribeNetworkInterfaces denied")},
+			"discover network interfaces in region us-east-1",
+		},
 	}
 
 	for _, tc := range tests {
@@ -123,6 +123,134 @@ func TestDiscoverFailsInsteadOfReturningPartialInventoryWhenResourceTypeFails(t
 	}
 }
 
+func TestDiscoverCollectsIPv6Blocks(t *testing.T) {
+	collector := newTestCollector(map[string]ec2API{
+		"us-east-1": &fakeEC2{
+			vpcs: []ec2types.Vpc{{
+				VpcId:     awssdk.String("vpc-1"),
+				CidrBlock: awssdk.String("10.0.0.0/16"),
+				State:     ec2types.VpcStateAvailable,
+				Ipv6CidrBlockAssociationSet: []ec2types.VpcIpv6CidrBlockAssociation{
+					{
+						AssociationId:      awssdk.String("vpc-cidr-assoc-1"),
+						Ipv6CidrBlock:      awssdk.String("2600:1f18:abcd:1200::/56"),
+						Ipv6CidrBlockState: &ec2types.VpcCidrBlockState{State: ec2types.VpcCidrBlockStateCodeAssociated},
+						IpSource:           ec2types.IpSourceAmazon,
+						NetworkBorderGroup: awssdk.String("us-east-1"),
+					},
+					{
+						AssociationId:      awssdk.String("vpc-cidr-assoc-old"),
+						Ipv6CidrBlock:      awssdk.String("2600:1f18:ffff:ff00::/56"),
+						Ipv6CidrBlockState: &ec2types.VpcCidrBlockState{State: ec2types.VpcCidrBlockStateCodeDisassociated},
+					},
+				},
+			}},
+			subnets: []ec2types.Subnet{{
+				SubnetId:         awssdk.String("subnet-1"),
+				VpcId:            awssdk.String("vpc-1"),
+				CidrBlock:        awssdk.String("10.0.1.0/24"),
+				AvailabilityZone: awssdk.String("us-east-1a"),
+				State:            ec2types.SubnetStateAvailable,
+				Ipv6CidrBlockAssociationSet: []ec2types.SubnetIpv6CidrBlockAssociation{{
+					AssociationId:      awssdk.String("subnet-cidr-assoc-1"),
+					Ipv6CidrBlock:      awssdk.String("2600:1f18:abcd:1201::/64"),
+					Ipv6CidrBlockState: &ec2types.SubnetCidrBlockState{State: ec2types.SubnetCidrBlockStateCodeAssociated},
+				}},
+			}},
+		},
+	})
+
+	resources, err := collector.Discover(context.Background(), domain.Account{ID: 7, Regions: []string{"us-east-1"}})
+	if err != nil {
+		t.Fatalf("Discover() unexpected error: %v", err)
+	}
+	byID := map[string]domain.DiscoveredResource{}
+	for _, r := range resources {
+		byID[r.ResourceID] = r
+	}
+	if len(resources) != 4 {
+		t.Fatalf("len(resources) = %d, want 4 (two VPC blocks, two subnet blocks): %+v", len(resources), resources)
+	}
+	if _, ok := byID["vpc-cidr-assoc-old"]; ok {
+		t.Error("disassociated IPv6 block should be skipped")
+	}
+	vpc6, ok := byID["vpc-cidr-assoc-1"]
+	if !ok {
+		t.Fatal("missing the VPC IPv6 block")
+	}
+	if vpc6.CIDR != "2600:1f18:abcd:1200::/56" || vpc6.ResourceType != domain.ResourceTypeVPC || vpc6.ParentResourceID != nil {
+		t.Errorf("VPC IPv6 block = %+v", vpc6)
+	}
+	if vpc6.Metadata["vpc_id"] != "vpc-1" || vpc6.Metadata["network_border_group"] != "us-east-1" {
+		t.Errorf("VPC IPv6 metadata = %v", vpc6.Metadata)
+	}
+	sub6, ok := byID["subnet-cidr-assoc-1"]
+	if !ok {
+		t.Fatal("missing the subnet IPv6 block")
+	}
+	if sub6.ParentResourceID == nil || *sub6.ParentResourceID != "vpc-cidr-assoc-1" {
+		t.Errorf("subnet IPv6 parent = %v, want vpc-cidr-assoc-1", sub6.ParentResourceID)
+	}
+	if sub4 := byID["subnet-1"]; sub4.ParentResourceID == nil || *sub4.ParentResourceID != "vpc-1" {
+		t.Errorf("IPv4 subnet parent = %v, want vpc-1", sub4.ParentResourceID)
+	}
+}
+
+func TestDiscoverCollectsNetworkInterfaces(t *testing.T) {
+	collector := newTestCollector(map[string]ec2API{
+		"us-east-1": &fakeEC2{
+			enis: []ec2types.NetworkInterface{
+				{
+					NetworkInterfaceId: awssdk.String("eni-1"),
+					SubnetId:           awssdk.String("subnet-1"),
+					VpcId:              awssdk.String("vpc-1"),
+					MacAddress:         awssdk.String("0a:1b:2c:3d:4e:5f"),
+					PrivateDnsName:     awssdk.String("ip-10-0-1-10.ec2.internal"),
+					PrivateIpAddress:   awssdk.String("10.0.1.10"),
+					PrivateIpAddresses: []ec2types.NetworkInterfacePrivateIpAddress{
+						{PrivateIpAddress: awssdk.String("10.0.1.10"), Primary: awssdk.Bool(true)},
+						{PrivateIpAddress: awssdk.String("10.0.1.11")},
+					},
+					Ipv6Addresses: []ec2types.NetworkInterfaceIpv6Address{{Ipv6Address: awssdk.String("2600:1f18:abcd:1201::10")}},
+					Attachment:    &ec2types.NetworkInterfaceAttachment{InstanceId: awssdk.String("i-123")},
+					TagSet:        []ec2types.Tag{{Key: awssdk.String("Name"), Value: awssdk.String("web-1")}},
+				},
+				// An ENI with no private address has nothing to track.
+				{NetworkInterfaceId: awssdk.String("eni-empty")},
+			},
+		},
+	})
+
+	resources, err := collector.Discover(context.Background(), domain.Account{ID: 1, Regions: []string{"us-east-1"}})
+	if err != nil {
+		t.Fatalf("Discover() unexpected error: %v", err)
+	}
+	if len(resources) != 1 {
+		t.Fatalf("len(resources) = %d, want 1", len(resources))
+	}
+	eni := resources[0]
+	if eni.ResourceType != domain.ResourceTypeNetworkInterface || eni.ResourceID != "eni-1" || eni.Name != "web-1" {
+		t.Errorf("resource = %s %s %q, want network_interface eni-1 \"web-1\"", eni.ResourceType, eni.ResourceID, eni.Name)
+	}
+	if eni.CIDR != "10.0.1.10/32" {
+		t.Errorf("CIDR = %q, want primary address 10.0.1.10/32", eni.CIDR)
+	}
+	if eni.ParentResourceID == nil || *eni.ParentResourceID != "subnet-1" {
+		t.Errorf("ParentResourceID = %v, want subnet-1", eni.ParentResourceID)
+	}
+	want := map[string]string{
+		domain.NICMetaAddresses:  "10.0.1.10,10.0.1.11,2600:1f18:abcd:1201::10",
+		domain.NICMetaMACAddress: "0a:1b:2c:3d:4e:5f",
+		domain.NICMetaHostname:   "ip-10-0-1-10.ec2.internal",
+		"instance_id":            "i-123",
+	}
+	for k, v := range want {
+		if eni.Metadata[k] != v {
+			t.Errorf("Metadata[%q] = %q, want %q", k, eni.Metadata[k], v)
+		}
+	}
+}
+
 func newTestCollector(clients map[string]ec2API) *Collector {
 	return &Collector{
 		loadConfig: func(_ context.Context, region string, _ awssdk.CredentialsProvider) (awssdk.Config, error) {
@@ -145,6 +273,8 @@ type fakeEC2 struct {
 	subnetErr error
 	addresses []ec2types.Address
 	addrErr   error
+	enis      []ec2types.NetworkInterface
+	eniErr    error
 }
 
 func (f *fakeEC2) DescribeVpcs(context.Context, *ec2.DescribeVpcsInput, ...func(*ec2.Options)) (*ec2.DescribeVpcsOutput, error) {
@@ -167,3 +297,10 @@ func (f *fakeEC2) DescribeAddresses(context.Context, *ec2.DescribeAddressesInput
 	}
 	return &ec2.DescribeAddressesOutput{Addresses: f.addresses}, nil
 }
+
+func (f *fakeEC2) DescribeNetworkInterfaces(context.Context, *ec2.DescribeNetworkInterfacesInput, ...func(*ec2.Options)) (*ec2.DescribeNetworkInterfacesOutput, error) {
+	if f.eniErr != nil {
+		return nil, f.eniErr
+	}
+	return &ec2.DescribeNetworkInterfacesOutput{NetworkInterfaces: f.enis}, nil
+}
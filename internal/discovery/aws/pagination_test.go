@@ -12,17 +12,20 @@ import (
 	"cloudpam/internal/domain"
 )
 
-// pagedEC2 serves DescribeVpcs and DescribeSubnets one page at a time, keyed by
+// pagedEC2 serves DescribeVpcs, DescribeSubnets and DescribeNetworkInterfaces
+// one page at a time, keyed by
 // the NextToken the collector echoes back. An unknown token is an error so a
 // collector that drops pagination is caught rather than silently truncating.
 type pagedEC2 struct {
 	vpcPages    [][]ec2types.Vpc
 	subnetPages [][]ec2types.Subnet
 	addresses   []ec2types.Address
+	eniPages    [][]ec2types.NetworkInterface
 
 	vpcCalls    int
 	subnetCalls int
 	addrCalls   int
+	eniCalls    int
 }
 
 func pageIndex(token *string) (int, error) {
@@ -72,6 +75,21 @@ func (f *pagedEC2) DescribeAddresses(_ context.Context, _ *ec2.DescribeAddresses
 	return &ec2.DescribeAddressesOutput{Addresses: f.addresses}, nil
 }
 
+func (f *pagedEC2) DescribeNetworkInterfaces(_ context.Context, in *ec2.DescribeNetworkInterfacesInput, _ ...func(*ec2.Options)) (*ec2.DescribeNetworkInterfacesOutput, error) {
+	f.eniCalls++
+	idx, err := pageIndex(in.NextToken)
+	if err != nil {
+		return nil, err
+	}
+	if len(f.eniPages) == 0 {
+		return &ec2.DescribeNetworkInterfacesOutput{}, nil
+	}
+	if idx >= len(f.eniPages) {
+		return nil, fmt.Errorf("network interface page %d out of range", idx)
+	}
+	return &ec2.DescribeNetworkInterfacesOutput{NetworkInterfaces: f.eniPages[idx], NextToken: nextTokenFor(idx, len(f.eniPages))}, nil
+}
+
 func TestDiscoverFollowsPaginationAcrossAllPages(t *testing.T) {
 	client := &pagedEC2{
 		vpcPages: [][]ec2types.Vpc{
@@ -84,6 +102,10 @@ func TestDiscoverFollowsPaginationAcrossAllPages(t *testing.T) {
 			{{SubnetId: awssdk.String("subnet-2"), VpcId: awssdk.String("vpc-2"), CidrBlock: awssdk.String("10.1.1.0/24")}},
 		},
 		addresses: []ec2types.Address{{AllocationId: awssdk.String("eipalloc-1"), PublicIp: awssdk.String("203.0.113.10")}},
+		eniPages: [][]ec2types.NetworkInterface{
+			{{NetworkInterfaceId: awssdk.String("eni-1"), SubnetId: awssdk.String("subnet-1"), PrivateIpAddress: awssdk.String("10.0.1.10")}},
+			{{NetworkInterfaceId: awssdk.String("eni-2"), SubnetId: awssdk.String("subnet-2"), PrivateIpAddress: awssdk.String("10.1.1.10")}},
+		},
 	}
 	collector := newTestCollector(map[string]ec2API{"us-east-1": client})
 
@@ -106,6 +128,9 @@ func TestDiscoverFollowsPaginationAcrossAllPages(t *testing.T) {
 	if got, want := len(byType[domain.ResourceTypeElasticIP]), 1; got != want {
 		t.Errorf("elastic IPs = %v, want %d entries", byType[domain.ResourceTypeElasticIP], want)
 	}
+	if got, want := len(byType[domain.ResourceTypeNetworkInterface]), 2; got != want {
+		t.Errorf("network interfaces = %v, want %d entries", byType[domain.ResourceTypeNetworkInterface], want)
+	}
 
 	if client.vpcCalls != 3 {
 		t.Errorf("DescribeVpcs calls = %d, want 3", client.vpcCalls)
@@ -117,6 +142,9 @@ func TestDiscoverFollowsPaginationAcrossAllPages(t *testing.T) {
 	if client.addrCalls != 1 {
 		t.Errorf("DescribeAddresses calls = %d, want 1", client.addrCalls)
 	}
+	if client.eniCalls != 2 {
+		t.Errorf("DescribeNetworkInterfaces calls = %d, want 2", client.eniCalls)
+	}
 }
 
 // repeatTokenEC2 always returns the same NextToken, mimicking a broken endpoint.
@@ -143,6 +171,10 @@ func (f *repeatTokenEC2) DescribeAddresses(context.Context, *ec2.DescribeAddress
 	return &ec2.DescribeAddressesOutput{}, nil
 }
 
+func (f *repeatTokenEC2) DescribeNetworkInterfaces(context.Context, *ec2.DescribeNetworkInterfacesInput, ...func(*ec2.Options)) (*ec2.DescribeNetworkInterfacesOutput, error) {
+	return &ec2.DescribeNetworkInterfacesOutput{}, nil
+}
+
 func TestDiscoverStopsOnRepeatedPageToken(t *testing.T) {
 	client := &repeatTokenEC2{}
 	collector := newTestCollector(map[string]ec2API{"us-east-1": client})
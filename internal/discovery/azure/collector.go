@@ -0,0 +1,571 @@
+// Package azure provides an Azure virtual network/subnet/public IP discovery
+// collector.
+// It uses the Azure Resource Manager REST API directly to avoid heavy SDK
+// dependencies.
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+
+	"cloudpam/internal/domain"
+)
+
+const (
+	// DefaultResourceManagerEndpoint is the Azure public cloud ARM endpoint.
+	DefaultResourceManagerEndpoint = "https://management.azure.com"
+	// DefaultAuthorityHost is the Microsoft Entra ID endpoint for the Azure
+	// public cloud.
+	DefaultAuthorityHost = "https://login.microsoftonline.com"
+
+	networkAPIVersion = "2023-09-01"
+)
+
+// Config holds the service principal used to call Azure Resource Manager.
+type Config struct {
+	TenantID     string
+	ClientID     string
+	ClientSecret string
+	// AuthorityHost and ResourceManagerEndpoint default to the Azure public
+	// cloud; override both for sovereign clouds.
+	AuthorityHost           string
+	ResourceManagerEndpoint string
+}
+
+// ConfigFromEnv reads the service principal from the standard Azure SDK
+// environment variables (AZURE_TENANT_ID, AZURE_CLIENT_ID,
+// AZURE_CLIENT_SECRET, AZURE_AUTHORITY_HOST) plus
+// AZURE_RESOURCE_MANAGER_ENDPOINT.
+func ConfigFromEnv() Config {
+	return Config{
+		TenantID:                os.Getenv("AZURE_TENANT_ID"),
+		ClientID:                os.Getenv("AZURE_CLIENT_ID"),
+		ClientSecret:            os.Getenv("AZURE_CLIENT_SECRET"),
+		AuthorityHost:           os.Getenv("AZURE_AUTHORITY_HOST"),
+		ResourceManagerEndpoint: os.Getenv("AZURE_RESOURCE_MANAGER_ENDPOINT"),
+	}
+}
+
+// Collector discovers Azure virtual networks, subnets, and public IP addresses.
+type Collector struct {
+	endpoint    string
+	tokenSource oauth2.TokenSource
+	httpClient  *http.Client
+	configErr   error
+}
+
+// New creates a new Azure collector using the service principal from the
+// environment (see ConfigFromEnv).
+func New() *Collector {
+	return NewWithConfig(ConfigFromEnv())
+}
+
+// NewWithConfig creates a new Azure collector authenticating with the given
+// service principal via the OAuth2 client credentials flow.
+// A missing tenant, client ID, or secret is reported when discovery runs.
+func NewWithConfig(cfg Config) *Collector {
+	c := &Collector{endpoint: strings.TrimRight(cfg.ResourceManagerEndpoint, "/")}
+	if c.endpoint == "" {
+		c.endpoint = DefaultResourceManagerEndpoint
+	}
+	if cfg.TenantID == "" || cfg.ClientID == "" || cfg.ClientSecret == "" {
+		c.configErr = errors.New("azure credentials not configured: set AZURE_TENANT_ID, AZURE_CLIENT_ID and AZURE_CLIENT_SECRET")
+		return c
+	}
+	authority := strings.TrimRight(cfg.AuthorityHost, "/")
+	if authority == "" {
+		authority = DefaultAuthorityHost
+	}
+	cc := &clientcredentials.Config{
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		TokenURL:     fmt.Sprintf("%s/%s/oauth2/v2.0/token", authority, cfg.TenantID),
+		Scopes:       []string{c.endpoint + "/.default"},
+	}
+	// The token source caches the token until it expires, so one collector
+	// shares a token across discovery runs and subscriptions.
+	c.tokenSource = cc.TokenSource(context.Background())
+	return c
+}
+
+// NewWithTokenSource creates a new Azure collector with an explicit token source.
+// This allows injecting custom credentials or test mocks.
+func NewWithTokenSource(ts oauth2.TokenSource) *Collector {
+	return &Collector{endpoint: DefaultResourceManagerEndpoint, tokenSource: ts}
+}
+
+// NewWithHTTPClient creates a new Azure collector with a custom HTTP client.
+// This is primarily useful for testing.
+func NewWithHTTPClient(client *http.Client) *Collector {
+	return &Collector{endpoint: DefaultResourceManagerEndpoint, httpClient: client}
+}
+
+// Provider returns "azure".
+func (c *Collector) Provider() string { return "azure" }
+
+// Discover discovers virtual networks, subnets, and public IP addresses for the
+// given account.
+// The account's ExternalID (or Key) is used as the Azure subscription ID.
+// If account.Regions is set, only resources in those locations are returned.
+func (c *Collector) Discover(ctx context.Context, account domain.Account) ([]domain.DiscoveredResource, error) {
+	subscription := SubscriptionID(account)
+	if subscription == "" {
+		return nil, fmt.Errorf("account %d has no external_id or key for Azure subscription", account.ID)
+	}
+
+	client, err := c.getHTTPClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("create http client: %w", err)
+	}
+
+	regionSet := make(map[string]bool, len(account.Regions))
+	for _, r := range account.Regions {
+		regionSet[normalizeLocation(r)] = true
+	}
+
+	var all []domain.DiscoveredResource
+	var errs []error
+	now := time.Now().UTC()
+
+	// Virtual networks carry their subnets inline, so one listing yields both.
+	networks, err := c.discoverVirtualNetworks(ctx, client, account, subscription, regionSet, now)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("discover virtual networks for subscription %s: %w", subscription, err))
+	} else {
+		all = append(all, networks...)
+	}
+
+	addrs, err := c.discoverPublicIPAddresses(ctx, client, account, subscription, regionSet, now)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("discover public ip addresses for subscription %s: %w", subscription, err))
+	} else {
+		all = append(all, addrs...)
+	}
+
+	// Surface endpoint failures so the caller does not treat a partial
+	// discovery as a complete inventory (which would mark resources stale).
+	if len(errs) > 0 {
+		return all, errors.Join(errs...)
+	}
+
+	return all, nil
+}
+
+func (c *Collector) getHTTPClient(ctx context.Context) (*http.Client, error) {
+	if c.httpClient != nil {
+		return c.httpClient, nil
+	}
+	if c.configErr != nil {
+		return nil, c.configErr
+	}
+	if c.tokenSource == nil {
+		return nil, errors.New("azure collector has no credentials")
+	}
+	return oauth2.NewClient(ctx, c.tokenSource), nil
+}
+
+// --- Azure Resource Manager REST API response types ---
+
+type subResource struct {
+	ID string `json:"id"`
+}
+
+type virtualNetworkList struct {
+	Value    []virtualNetwork `json:"value"`
+	NextLink string           `json:"nextLink"`
+}
+
+type virtualNetwork struct {
+	ID         string                   `json:"id"`
+	Name       string                   `json:"name"`
+	Location   string                   `json:"location"`
+	Properties virtualNetworkProperties `json:"properties"`
+}
+
+type virtualNetworkProperties struct {
+	AddressSpace struct {
+		AddressPrefixes []string `json:"addressPrefixes"`
+	} `json:"addressSpace"`
+	Subnets           []subnet `json:"subnets"`
+	ProvisioningState string   `json:"provisioningState"`
+}
+
+type subnet struct {
+	ID         string           `json:"id"`
+	Name       string           `json:"name"`
+	Properties subnetProperties `json:"properties"`
+}
+
+type subnetProperties struct {
+	AddressPrefix        string       `json:"addressPrefix"`
+	AddressPrefixes      []string     `json:"addressPrefixes"`
+	NetworkSecurityGroup *subResource `json:"networkSecurityGroup"`
+	RouteTable           *subResource `json:"routeTable"`
+	NatGateway           *subResource `json:"natGateway"`
+	Delegations          []struct {
+		Properties struct {
+			ServiceName string `json:"serviceName"`
+		} `json:"properties"`
+	} `json:"delegations"`
+	ProvisioningState string `json:"provisioningState"`
+}
+
+type publicIPAddressList struct {
+	Value    []publicIPAddress `json:"value"`
+	NextLink string            `json:"nextLink"`
+}
+
+type publicIPAddress struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Location string   `json:"location"`
+	Zones    []string `json:"zones"`
+	SKU      struct {
+		Name string `json:"name"`
+		Tier string `json:"tier"`
+	} `json:"sku"`
+	Properties struct {
+		IPAddress                string       `json:"ipAddress"`
+		PublicIPAddressVersion   string       `json:"publicIPAddressVersion"`
+		PublicIPAllocationMethod string       `json:"publicIPAllocationMethod"`
+		IPConfiguration          *subResource `json:"ipConfiguration"`
+		PublicIPPrefix           *subResource `json:"publicIPPrefix"`
+		DNSSettings              *struct {
+			FQDN string `json:"fqdn"`
+		} `json:"dnsSettings"`
+	} `json:"properties"`
+}
+
+// --- Discovery functions ---
+
+// discoverVirtualNetworks lists every virtual network in the subscription.
+// A virtual network may hold several address prefixes; the first becomes the
+// vpc resource keyed by the network's ARM ID and every further prefix is
+// tracked as its own vpc resource so it can be imported as a separate pool.
+// Subnets are parented to whichever of those resources contains them.
+func (c *Collector) discoverVirtualNetworks(ctx context.Context, client *http.Client, account domain.Account, subscription string, regionSet map[string]bool, now time.Time) ([]domain.DiscoveredResource, error) {
+	var resources []domain.DiscoveredResource
+
+	url := fmt.Sprintf("%s/subscriptions/%s/providers/Microsoft.Network/virtualNetworks?api-version=%s", c.endpoint, subscription, networkAPIVersion)
+	for url != "" {
+		var result virtualNetworkList
+		if err := doGet(ctx, client, url, &result); err != nil {
+			return nil, err
+		}
+
+		for _, vnet := range result.Value {
+			region := normalizeLocation(vnet.Location)
+			if len(regionSet) > 0 && !regionSet[region] {
+				continue
+			}
+			resourceGroup := ResourceGroup(vnet.ID)
+
+			type block struct {
+				id     string
+				prefix netip.Prefix
+			}
+			var blocks []block
+			for i, raw := range vnet.Properties.AddressSpace.AddressPrefixes {
+				id := vnet.ID
+				if i > 0 {
+					id = addressPrefixResourceID(vnet.ID, raw)
+				}
+				meta := map[string]string{
+					"resource_group": resourceGroup,
+				}
+				if vnet.Properties.ProvisioningState != "" {
+					meta["provisioning_state"] = vnet.Properties.ProvisioningState
+				}
+				if i > 0 {
+					meta["virtual_network_id"] = vnet.ID
+				}
+				if p, err := netip.ParsePrefix(raw); err == nil {
+					blocks = append(blocks, block{id: id, prefix: p})
+					if p.Addr().Is6() {
+						meta["address_family"] = "ipv6"
+					}
+				}
+				resources = append(resources, domain.DiscoveredResource{
+					ID:           uuid.New(),
+					AccountID:    account.ID,
+					Provider:     "azure",
+					Region:       region,
+					ResourceType: domain.ResourceTypeVPC,
+					ResourceID:   id,
+					Name:         vnet.Name,
+					CIDR:         raw,
+					Status:       domain.DiscoveryStatusActive,
+					Metadata:     meta,
+					DiscoveredAt: now,
+					LastSeenAt:   now,
+				})
+			}
+			// A virtual network without an address space is still worth
+			// tracking; its subnets need a parent.
+			if len(vnet.Properties.AddressSpace.AddressPrefixes) == 0 {
+				resources = append(resources, domain.DiscoveredResource{
+					ID:           uuid.New(),
+					AccountID:    account.ID,
+					Provider:     "azure",
+					Region:       region,
+					ResourceType: domain.ResourceTypeVPC,
+					ResourceID:   vnet.ID,
+					Name:         vnet.Name,
+					Status:       domain.DiscoveryStatusActive,
+					Metadata:     map[string]string{"resource_group": resourceGroup},
+					DiscoveredAt: now,
+					LastSeenAt:   now,
+				})
+			}
+
+			parentFor := func(prefix string) string {
+				p, err := netip.ParsePrefix(prefix)
+				if err != nil {
+					return vnet.ID
+				}
+				for _, b := range blocks {
+					if b.prefix.Addr().Is4() == p.Addr().Is4() && b.prefix.Bits() <= p.Bits() && b.prefix.Contains(p.Addr()) {
+						return b.id
+					}
+				}
+				return vnet.ID
+			}
+
+			for _, sn := range vnet.Properties.Subnets {
+				prefixes := sn.Properties.AddressPrefixes
+				if len(prefixes) == 0 && sn.Properties.AddressPrefix != "" {
+					prefixes = []string{sn.Properties.AddressPrefix}
+				}
+				for i, raw := range prefixes {
+					id := sn.ID
+					meta := subnetMetadata(sn, vnet, resourceGroup)
+					if i > 0 {
+						id = addressPrefixResourceID(sn.ID, raw)
+						meta["subnet_id"] = sn.ID
+					}
+					if p, err := netip.ParsePrefix(raw); err == nil && p.Addr().Is6() {
+						meta["address_family"] = "ipv6"
+					}
+					parent := parentFor(raw)
+					resources = append(resources, domain.DiscoveredResource{
+						ID:               uuid.New(),
+						AccountID:        account.ID,
+						Provider:         "azure",
+						Region:           region,
+						ResourceType:     domain.ResourceTypeSubnet,
+						ResourceID:       id,
+						Name:             sn.Name,
+						CIDR:             raw,
+						ParentResourceID: &parent,
+						Status:           domain.DiscoveryStatusActive,
+						Metadata:         meta,
+						DiscoveredAt:     now,
+						LastSeenAt:       now,
+					})
+				}
+			}
+		}
+
+		url = nextLink(url, result.NextLink)
+	}
+
+	return resources, nil
+}
+
+func subnetMetadata(sn subnet, vnet virtualNetwork, resourceGroup string) map[string]string {
+	meta := map[string]string{
+		"resource_group":  resourceGroup,
+		"virtual_network": vnet.Name,
+	}
+	if sn.Properties.ProvisioningState != "" {
+		meta["provisioning_state"] = sn.Properties.ProvisioningState
+	}
+	if sn.Properties.NetworkSecurityGroup != nil {
+		meta["network_security_group"] = LastPathComponent(sn.Properties.NetworkSecurityGroup.ID)
+	}
+	if sn.Properties.RouteTable != nil {
+		meta["route_table"] = LastPathComponent(sn.Properties.RouteTable.ID)
+	}
+	if sn.Properties.NatGateway != nil {
+		meta["nat_gateway"] = LastPathComponent(sn.Properties.NatGateway.ID)
+	}
+	var services []string
+	for _, d := range sn.Properties.Delegations {
+		if d.Properties.ServiceName != "" {
+			services = append(services, d.Properties.ServiceName)
+		}
+	}
+	if len(services) > 0 {
+		meta["delegations"] = strings.Join(services, ",")
+	}
+	return meta
+}
+
+// addressPrefixResourceID derives the resource ID of an additional address
+// prefix on a virtual network or subnet, which ARM reports as a field of the
+// same object.
+func addressPrefixResourceID(id, prefix string) string {
+	return id + "/addressPrefixes/" + prefix
+}
+
+// discoverPublicIPAddresses lists public IP addresses in the subscription.
+// Dynamic addresses that are not currently allocated have no IP and are
+// skipped.
+func (c *Collector) discoverPublicIPAddresses(ctx context.Context, client *http.Client, account domain.Account, subscription string, regionSet map[string]bool, now time.Time) ([]domain.DiscoveredResource, error) {
+	var resources []domain.DiscoveredResource
+
+	url := fmt.Sprintf("%s/subscriptions/%s/providers/Microsoft.Network/publicIPAddresses?api-version=%s", c.endpoint, subscription, networkAPIVersion)
+	for url != "" {
+		var result publicIPAddressList
+		if err := doGet(ctx, client, url, &result); err != nil {
+			return nil, err
+		}
+
+		for _, pip := range result.Value {
+			region := normalizeLocation(pip.Location)
+			if len(regionSet) > 0 && !regionSet[region] {
+				continue
+			}
+			cidr := hostCIDR(pip.Properties.IPAddress)
+			if cidr == "" {
+				continue
+			}
+
+			meta := map[string]string{
+				"resource_group":    ResourceGroup(pip.ID),
+				"allocation_method": pip.Properties.PublicIPAllocationMethod,
+			}
+			if pip.Properties.PublicIPAddressVersion != "" {
+				meta["ip_version"] = pip.Properties.PublicIPAddressVersion
+			}
+			if pip.SKU.Name != "" {
+				meta["sku"] = pip.SKU.Name
+			}
+			if len(pip.Zones) > 0 {
+				meta["zones"] = strings.Join(pip.Zones, ",")
+			}
+			if pip.Properties.IPConfiguration != nil {
+				meta["ip_configuration_id"] = pip.Properties.IPConfiguration.ID
+			}
+			if pip.Properties.PublicIPPrefix != nil {
+				meta["public_ip_prefix"] = LastPathComponent(pip.Properties.PublicIPPrefix.ID)
+			}
+			if pip.Properties.DNSSettings != nil && pip.Properties.DNSSettings.FQDN != "" {
+				meta["fqdn"] = pip.Properties.DNSSettings.FQDN
+			}
+
+			resources = append(resources, domain.DiscoveredResource{
+				ID:           uuid.New(),
+				AccountID:    account.ID,
+				Provider:     "azure",
+				Region:       region,
+				ResourceType: domain.ResourceTypeElasticIP,
+				ResourceID:   pip.ID,
+				Name:         pip.Name,
+				CIDR:         cidr,
+				Status:       domain.DiscoveryStatusActive,
+				Metadata:     meta,
+				DiscoveredAt: now,
+				LastSeenAt:   now,
+			})
+		}
+
+		url = nextLink(url, result.NextLink)
+	}
+
+	return resources, nil
+}
+
+// nextLink returns the URL of the next page, or "" when the listing is
+// exhausted. A link identical to the current one is treated as exhausted so a
+// misbehaving endpoint cannot spin the caller forever.
+func nextLink(current, next string) string {
+	if next == "" || next == current {
+		return ""
+	}
+	return next
+}
+
+// hostCIDR renders a single address as a host prefix, or "" if unparseable.
+func hostCIDR(raw string) string {
+	ip, err := netip.ParseAddr(strings.TrimSpace(raw))
+	if err != nil {
+		return ""
+	}
+	return netip.PrefixFrom(ip, ip.BitLen()).String()
+}
+
+// doGet performs a GET request and decodes the JSON response.
+func doGet(ctx context.Context, client *http.Client, url string, dest any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("http get %s: %w", url, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("http %d from %s: %s", resp.StatusCode, url, body)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(dest); err != nil {
+		return fmt.Errorf("decode response from %s: %w", url, err)
+	}
+	return nil
+}
+
+// SubscriptionID extracts the Azure subscription ID from the account.
+// It uses ExternalID if set, otherwise falls back to Key (stripping "azure:" prefix).
+func SubscriptionID(account domain.Account) string {
+	if account.ExternalID != "" {
+		return account.ExternalID
+	}
+	return strings.TrimPrefix(account.Key, "azure:")
+}
+
+// ResourceGroup extracts the resource group name from an ARM resource ID such
+// as "/subscriptions/s/resourceGroups/rg/providers/...". It returns "" if the
+// ID is not scoped to a resource group.
+func ResourceGroup(id string) string {
+	parts := strings.Split(id, "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if strings.EqualFold(parts[i], "resourceGroups") {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
+
+// LastPathComponent returns the last component of an ARM resource ID.
+// For example, ".../networkSecurityGroups/web-nsg" returns "web-nsg".
+func LastPathComponent(id string) string {
+	if idx := strings.LastIndex(id, "/"); idx >= 0 {
+		return id[idx+1:]
+	}
+	return id
+}
+
+// normalizeLocation folds a location to ARM's canonical form ("East US" and
+// "eastus" both become "eastus") so account regions match either spelling.
+func normalizeLocation(location string) string {
+	return strings.ToLower(strings.ReplaceAll(location, " ", ""))
+}
@@ -0,0 +1,411 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cloudpam/internal/discovery"
+	"cloudpam/internal/domain"
+)
+
+// Verify Collector implements the discovery.Collector interface at compile time.
+var _ discovery.Collector = (*Collector)(nil)
+
+const (
+	testSub     = "11111111-1111-1111-1111-111111111111"
+	testVNetID  = "/subscriptions/" + testSub + "/resourceGroups/net-rg/providers/Microsoft.Network/virtualNetworks/hub"
+	testVNetsAt = "/subscriptions/" + testSub + "/providers/Microsoft.Network/virtualNetworks"
+	testPIPsAt  = "/subscriptions/" + testSub + "/providers/Microsoft.Network/publicIPAddresses"
+)
+
+func TestProvider(t *testing.T) {
+	c := New()
+	if got := c.Provider(); got != "azure" {
+		t.Errorf("Provider() = %q, want %q", got, "azure")
+	}
+}
+
+func TestSubscriptionID(t *testing.T) {
+	tests := []struct {
+		name    string
+		account domain.Account
+		want    string
+	}{
+		{
+			name:    "uses ExternalID when set",
+			account: domain.Account{ExternalID: testSub, Key: "azure:other"},
+			want:    testSub,
+		},
+		{
+			name:    "strips azure: prefix from Key",
+			account: domain.Account{Key: "azure:" + testSub},
+			want:    testSub,
+		},
+		{
+			name:    "empty returns empty",
+			account: domain.Account{},
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SubscriptionID(tt.account); got != tt.want {
+				t.Errorf("SubscriptionID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResourceGroup(t *testing.T) {
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{testVNetID, "net-rg"},
+		{"/subscriptions/s/resourcegroups/Lower/providers/x/y/z", "Lower"},
+		{"/subscriptions/s", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			if got := ResourceGroup(tt.id); got != tt.want {
+				t.Errorf("ResourceGroup(%q) = %q, want %q", tt.id, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiscover_EmptySubscription(t *testing.T) {
+	c := New()
+	_, err := c.Discover(context.Background(), domain.Account{ID: 1})
+	if err == nil {
+		t.Fatal("expected error for empty subscription, got nil")
+	}
+	if got := err.Error(); got != "account 1 has no external_id or key for Azure subscription" {
+		t.Errorf("unexpected error: %s", got)
+	}
+}
+
+func TestDiscover_MissingCredentials(t *testing.T) {
+	c := NewWithConfig(Config{TenantID: "t"})
+	_, err := c.Discover(context.Background(), domain.Account{ID: 1, ExternalID: testSub})
+	if err == nil || !strings.Contains(err.Error(), "AZURE_CLIENT_SECRET") {
+		t.Fatalf("expected credentials error, got %v", err)
+	}
+}
+
+func writeJSON(t *testing.T, w http.ResponseWriter, v any) {
+	t.Helper()
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		t.Errorf("write response: %v", err)
+	}
+}
+
+// fakeARM serves a recorded-shape ARM listing: one dual-stack hub network
+// split over two pages, and public IPs including an unallocated dynamic one.
+func fakeARM(t *testing.T) *http.ServeMux {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc(testVNetsAt, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("api-version") == "" {
+			t.Errorf("missing api-version on %s", r.URL)
+		}
+		if r.URL.Query().Get("$skiptoken") == "" {
+			writeJSON(t, w, map[string]any{
+				"value": []any{map[string]any{
+					"id": testVNetID, "name": "hub", "location": "eastus",
+					"properties": map[string]any{
+						"provisioningState": "Succeeded",
+						"addressSpace":      map[string]any{"addressPrefixes": []string{"10.0.0.0/16", "fd00:db8::/48"}},
+						"subnets": []any{
+							map[string]any{
+								"id": testVNetID + "/subnets/app", "name": "app",
+								"properties": map[string]any{
+									"addressPrefixes":      []string{"10.0.1.0/24", "fd00:db8:0:1::/64"},
+									"networkSecurityGroup": map[string]any{"id": "/subscriptions/s/resourceGroups/net-rg/providers/Microsoft.Network/networkSecurityGroups/app-nsg"},
+									"delegations": []any{
+										map[string]any{"properties": map[string]any{"serviceName": "Microsoft.Web/serverFarms"}},
+									},
+								},
+							},
+							map[string]any{
+								"id": testVNetID + "/subnets/GatewaySubnet", "name": "GatewaySubnet",
+								"properties": map[string]any{"addressPrefix": "10.0.255.0/27"},
+							},
+						},
+					},
+				}},
+				"nextLink": "https://management.azure.com" + testVNetsAt + "?api-version=2023-09-01&$skiptoken=page2",
+			})
+			return
+		}
+		writeJSON(t, w, map[string]any{
+			"value": []any{map[string]any{
+				"id":         "/subscriptions/" + testSub + "/resourceGroups/eu-rg/providers/Microsoft.Network/virtualNetworks/spoke",
+				"name":       "spoke",
+				"location":   "westeurope",
+				"properties": map[string]any{"addressSpace": map[string]any{"addressPrefixes": []string{"10.1.0.0/16"}}},
+			}},
+		})
+	})
+	mux.HandleFunc(testPIPsAt, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, map[string]any{
+			"value": []any{
+				map[string]any{
+					"id":       "/subscriptions/" + testSub + "/resourceGroups/net-rg/providers/Microsoft.Network/publicIPAddresses/gw-pip",
+					"name":     "gw-pip",
+					"location": "eastus",
+					"zones":    []string{"1", "2"},
+					"sku":      map[string]any{"name": "Standard"},
+					"properties": map[string]any{
+						"ipAddress": "20.10.0.4", "publicIPAddressVersion": "IPv4", "publicIPAllocationMethod": "Static",
+						"dnsSettings": map[string]any{"fqdn": "gw.eastus.cloudapp.azure.com"},
+					},
+				},
+				map[string]any{
+					"id":         "/subscriptions/" + testSub + "/resourceGroups/net-rg/providers/Microsoft.Network/publicIPAddresses/idle",
+					"name":       "idle",
+					"location":   "eastus",
+					"properties": map[string]any{"publicIPAllocationMethod": "Dynamic"},
+				},
+			},
+		})
+	})
+	return mux
+}
+
+func newTestCollector(server *httptest.Server) *Collector {
+	return NewWithHTTPClient(&http.Client{
+		Transport: &rewriteTransport{base: server.Client().Transport, baseURL: server.URL},
+	})
+}
+
+func TestDiscover_FakeARM(t *testing.T) {
+	server := httptest.NewServer(fakeARM(t))
+	defer server.Close()
+
+	resources, err := newTestCollector(server).Discover(context.Background(), domain.Account{ID: 7, ExternalID: testSub})
+	if err != nil {
+		t.Fatalf("Discover() error: %v", err)
+	}
+
+	byID := make(map[string]domain.DiscoveredResource, len(resources))
+	for _, r := range resources {
+		if r.Provider != "azure" || r.AccountID != 7 {
+			t.Errorf("resource %s has provider %q account %d", r.ResourceID, r.Provider, r.AccountID)
+		}
+		byID[r.ResourceID] = r
+	}
+	// hub (2 prefixes) + app (2 prefixes) + GatewaySubnet + spoke + gw-pip.
+	if len(resources) != 7 {
+		t.Fatalf("expected 7 resources, got %d: %+v", len(resources), resources)
+	}
+
+	hub := byID[testVNetID]
+	if hub.ResourceType != domain.ResourceTypeVPC || hub.CIDR != "10.0.0.0/16" || hub.Region != "eastus" ||
+		hub.Metadata["resource_group"] != "net-rg" {
+		t.Errorf("hub = %+v", hub)
+	}
+	hubV6 := byID[testVNetID+"/addressPrefixes/fd00:db8::/48"]
+	if hubV6.Metadata["address_family"] != "ipv6" || hubV6.Metadata["virtual_network_id"] != testVNetID {
+		t.Errorf("hub ipv6 prefix = %+v", hubV6)
+	}
+
+	app := byID[testVNetID+"/subnets/app"]
+	if app.ResourceType != domain.ResourceTypeSubnet || app.CIDR != "10.0.1.0/24" ||
+		app.ParentResourceID == nil || *app.ParentResourceID != testVNetID {
+		t.Errorf("app subnet = %+v", app)
+	}
+	if app.Metadata["network_security_group"] != "app-nsg" || app.Metadata["delegations"] != "Microsoft.Web/serverFarms" ||
+		app.Metadata["virtual_network"] != "hub" {
+		t.Errorf("app subnet metadata = %v", app.Metadata)
+	}
+	appV6 := byID[testVNetID+"/subnets/app/addressPrefixes/fd00:db8:0:1::/64"]
+	if appV6.ParentResourceID == nil || *appV6.ParentResourceID != hubV6.ResourceID ||
+		appV6.Metadata["subnet_id"] != app.ResourceID || appV6.Metadata["address_family"] != "ipv6" {
+		t.Errorf("app ipv6 subnet = %+v", appV6)
+	}
+	if gw := byID[testVNetID+"/subnets/GatewaySubnet"]; gw.CIDR != "10.0.255.0/27" {
+		t.Errorf("gateway subnet = %+v", gw)
+	}
+
+	pip := byID["/subscriptions/"+testSub+"/resourceGroups/net-rg/providers/Microsoft.Network/publicIPAddresses/gw-pip"]
+	if pip.ResourceType != domain.ResourceTypeElasticIP || pip.CIDR != "20.10.0.4/32" ||
+		pip.Metadata["zones"] != "1,2" || pip.Metadata["fqdn"] != "gw.eastus.cloudapp.azure.com" {
+		t.Errorf("public ip = %+v", pip)
+	}
+}
+
+func TestDiscover_RegionFilter(t *testing.T) {
+	server := httptest.NewServer(fakeARM(t))
+	defer server.Close()
+
+	// Display names are accepted as well as canonical location names.
+	resources, err := newTestCollector(server).Discover(context.Background(),
+		domain.Account{ID: 7, ExternalID: testSub, Regions: []string{"West Europe"}})
+	if err != nil {
+		t.Fatalf("Discover() error: %v", err)
+	}
+	if len(resources) != 1 || resources[0].Name != "spoke" || resources[0].Region != "westeurope" {
+		t.Fatalf("expected only the spoke network, got %+v", resources)
+	}
+}
+
+func TestDiscover_PropagatesEndpointFailures(t *testing.T) {
+	tests := []struct {
+		name    string
+		failing string
+		wantIn  string
+	}{
+		{name: "virtual networks fail", failing: testVNetsAt, wantIn: "discover virtual networks"},
+		{name: "public ips fail", failing: testPIPsAt, wantIn: "discover public ip addresses"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			for _, path := range []string{testVNetsAt, testPIPsAt} {
+				if path == tt.failing {
+					mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+						http.Error(w, `{"error":{"code":"AuthorizationFailed"}}`, http.StatusForbidden)
+					})
+					continue
+				}
+				mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+					if _, err := w.Write([]byte(`{"value":[]}`)); err != nil {
+						t.Errorf("write response: %v", err)
+					}
+				})
+			}
+
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			_, err := newTestCollector(server).Discover(context.Background(), domain.Account{ID: 1, ExternalID: testSub})
+			if err == nil {
+				t.Fatal("expected discovery error, got nil")
+			}
+			if !strings.Contains(err.Error(), tt.wantIn) {
+				t.Errorf("error %q does not mention %q", err.Error(), tt.wantIn)
+			}
+		})
+	}
+}
+
+func TestNewWithConfig_ClientCredentials(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/tenant-1/oauth2/v2.0/token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("parse token form: %v", err)
+		}
+		if r.PostForm.Get("grant_type") != "client_credentials" || !strings.HasSuffix(r.PostForm.Get("scope"), "/.default") {
+			t.Errorf("token request form = %v", r.PostForm)
+		}
+		writeJSON(t, w, map[string]any{"access_token": "arm-token", "token_type": "Bearer", "expires_in": 3600})
+	})
+	mux.HandleFunc(testPIPsAt, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer arm-token" {
+			t.Errorf("Authorization = %q", got)
+		}
+		writeJSON(t, w, map[string]any{"value": []any{}})
+	})
+	mux.HandleFunc(testVNetsAt, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, map[string]any{"value": []any{}})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c := NewWithConfig(Config{
+		TenantID: "tenant-1", ClientID: "app", ClientSecret: "secret",
+		AuthorityHost: server.URL, ResourceManagerEndpoint: server.URL,
+	})
+	if _, err := c.Discover(context.Background(), domain.Account{ID: 1, ExternalID: testSub}); err != nil {
+		t.Fatalf("Discover() error: %v", err)
+	}
+}
+
+func TestListSubscriptions(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/subscriptions", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, map[string]any{"value": []any{
+			map[string]any{"subscriptionId": "sub-a", "displayName": "Prod", "state": "Enabled", "tenantId": "t"},
+			map[string]any{"subscriptionId": "SUB-B", "displayName": "Dev", "state": "Warned", "tenantId": "t"},
+			map[string]any{"subscriptionId": "sub-c", "displayName": "Old", "state": "Disabled", "tenantId": "t"},
+			map[string]any{"subscriptionId": "sub-d", "displayName": "Sandbox", "state": "Enabled", "tenantId": "t"},
+		}})
+	})
+	mux.HandleFunc("/providers/Microsoft.Management/managementGroups/platform/descendants", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("$skiptoken") == "" {
+			writeJSON(t, w, map[string]any{
+				"value": []any{
+					map[string]any{"name": "networking", "type": "Microsoft.Management/managementGroups"},
+					map[string]any{"name": "sub-a", "type": "Microsoft.Management/managementGroups/subscriptions"},
+				},
+				"nextLink": "https://management.azure.com/providers/Microsoft.Management/managementGroups/platform/descendants?api-version=2020-05-01&$skiptoken=2",
+			})
+			return
+		}
+		writeJSON(t, w, map[string]any{"value": []any{
+			map[string]any{"name": "sub-b", "type": "/subscriptions"},
+			map[string]any{"name": "sub-c", "type": "/subscriptions"},
+		}})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	c := newTestCollector(server)
+
+	all, err := c.ListSubscriptions(context.Background(), "")
+	if err != nil {
+		t.Fatalf("ListSubscriptions() error: %v", err)
+	}
+	if len(all) != 3 || all[0].ID != "sub-a" || all[0].Name != "Prod" || all[1].State != "Warned" {
+		t.Fatalf("all subscriptions = %+v", all)
+	}
+
+	// Nested groups are flattened by the descendants API; disabled sub-c and
+	// sub-d outside the group are dropped.
+	scoped, err := c.ListSubscriptions(context.Background(), "platform")
+	if err != nil {
+		t.Fatalf("ListSubscriptions(platform) error: %v", err)
+	}
+	if len(scoped) != 2 || scoped[0].ID != "sub-a" || scoped[1].ID != "SUB-B" {
+		t.Fatalf("management group subscriptions = %+v", scoped)
+	}
+
+	if _, err := c.ListSubscriptions(context.Background(), "missing"); err == nil {
+		t.Fatal("expected error for unknown management group")
+	}
+}
+
+// rewriteTransport rewrites request URLs to point to a local test server.
+type rewriteTransport struct {
+	base    http.RoundTripper
+	baseURL string
+}
+
+func (t *rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Rewrite the URL to point to the test server
+	newURL := t.baseURL + req.URL.Path
+	if req.URL.RawQuery != "" {
+		newURL += "?" + req.URL.RawQuery
+	}
+
+	newReq, err := http.NewRequestWithContext(req.Context(), req.Method, newURL, req.Body)
+	if err != nil {
+		return nil, err
+	}
+	newReq.Header = req.Header
+
+	transport := t.base
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return transport.RoundTrip(newReq)
+}
@@ -0,0 +1,105 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	subscriptionsAPIVersion    = "2022-12-01"
+	managementGroupsAPIVersion = "2020-05-01"
+)
+
+// Subscription represents an Azure subscription visible to the collector's
+// service principal.
+type Subscription struct {
+	ID       string // e.g. "00000000-0000-0000-0000-000000000000"
+	Name     string
+	State    string // "Enabled", "Warned", "PastDue"
+	TenantID string
+}
+
+type subscriptionList struct {
+	Value []struct {
+		SubscriptionID string `json:"subscriptionId"`
+		DisplayName    string `json:"displayName"`
+		State          string `json:"state"`
+		TenantID       string `json:"tenantId"`
+	} `json:"value"`
+	NextLink string `json:"nextLink"`
+}
+
+type managementGroupDescendantList struct {
+	Value []struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+	} `json:"value"`
+	NextLink string `json:"nextLink"`
+}
+
+// ListSubscriptions enumerates the subscriptions the service principal can
+// read. Disabled and deleted subscriptions are skipped because their
+// resources cannot be listed.
+//
+// If managementGroup is set, only subscriptions anywhere below that management
+// group (including nested groups) are returned, which lets one agent cover a
+// slice of the tenant hierarchy.
+func (c *Collector) ListSubscriptions(ctx context.Context, managementGroup string) ([]Subscription, error) {
+	client, err := c.getHTTPClient(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("create http client: %w", err)
+	}
+
+	var subs []Subscription
+	next := fmt.Sprintf("%s/subscriptions?api-version=%s", c.endpoint, subscriptionsAPIVersion)
+	for next != "" {
+		var result subscriptionList
+		if err := doGet(ctx, client, next, &result); err != nil {
+			return nil, fmt.Errorf("list subscriptions: %w", err)
+		}
+		for _, s := range result.Value {
+			if strings.EqualFold(s.State, "Disabled") || strings.EqualFold(s.State, "Deleted") {
+				continue
+			}
+			subs = append(subs, Subscription{
+				ID:       s.SubscriptionID,
+				Name:     s.DisplayName,
+				State:    s.State,
+				TenantID: s.TenantID,
+			})
+		}
+		next = nextLink(next, result.NextLink)
+	}
+
+	if managementGroup == "" {
+		return subs, nil
+	}
+
+	// The descendants listing carries neither state nor access, so it only
+	// narrows the subscription listing above.
+	inGroup := make(map[string]bool)
+	next = fmt.Sprintf("%s/providers/Microsoft.Management/managementGroups/%s/descendants?api-version=%s",
+		c.endpoint, url.PathEscape(managementGroup), managementGroupsAPIVersion)
+	for next != "" {
+		var result managementGroupDescendantList
+		if err := doGet(ctx, client, next, &result); err != nil {
+			return nil, fmt.Errorf("list descendants of management group %s: %w", managementGroup, err)
+		}
+		for _, d := range result.Value {
+			if strings.HasSuffix(strings.ToLower(d.Type), "/subscriptions") {
+				inGroup[strings.ToLower(d.Name)] = true
+			}
+		}
+		next = nextLink(next, result.NextLink)
+	}
+
+	filtered := subs[:0]
+	for _, s := range subs {
+		if inGroup[strings.ToLower(s.ID)] {
+			filtered = append(filtered, s)
+		}
+	}
+	return filtered, nil
+}
@@ -24,6 +24,7 @@ type Collector interface {
 type SyncService struct {
 	store      storage.DiscoveryStore
 	collectors map[string]Collector
+	ipAddrs    *IPAddressReconciler
 }
 
 // NewSyncService creates a new sync service with the given discovery store.
@@ -39,6 +40,12 @@ func (s *SyncService) RegisterCollector(c Collector) {
 	s.collectors[c.Provider()] = c
 }
 
+// SetIPAddressReconciler makes every processed run also reconcile the address
+// records of linked subnet pools with the account's network interfaces.
+func (s *SyncService) SetIPAddressReconciler(r *IPAddressReconciler) {
+	s.ipAddrs = r
+}
+
 // Sync runs a discovery sync for the given account.
 // It creates a SyncJob, runs the appropriate collector, upserts resources,
 // and marks stale resources.
@@ -96,7 +103,8 @@ func (s *SyncService) Sync(ctx context.Context, account domain.Account) (*domain
 	return &job, nil
 }
 
-// ProcessResources upserts discovered resources and marks stale resources.
+// ProcessResources upserts discovered resources, marks stale resources and,
+// when a reconciler is set, reconciles subnet pool address records.
 // This is shared logic used by both local sync and agent ingest.
 // Returns created, updated, stale counts and any error.
 func (s *SyncService) ProcessResources(
@@ -131,6 +139,12 @@ func (s *SyncService) ProcessResources(
 		err = fmt.Errorf("mark stale: %w", markErr)
 	}
 
+	if s.ipAddrs != nil {
+		if recErr := s.ipAddrs.Reconcile(ctx, accountID); recErr != nil && err == nil {
+			err = fmt.Errorf("reconcile ip addresses: %w", recErr)
+		}
+	}
+
 	return created, updated, staleCount, err
 }
 
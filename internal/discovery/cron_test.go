@@ -0,0 +1,56 @@
+package discovery
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	// Friday 2026-10-16 12:34 UTC.
+	from := time.Date(2026, 10, 16, 12, 34, 20, 0, time.UTC)
+	cases := []struct {
+		expr string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2026, 10, 16, 12, 45, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 10, 16, 13, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)},
+		{"30 2 * * 1-5", time.Date(2026, 10, 19, 2, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{"0 6 1,15 * *", time.Date(2026, 11, 1, 6, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either one matching is enough.
+		{"0 0 1 * 6", time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// A stepped "*" leaves the field unrestricted: only Monday matters.
+		{"0 0 */2 * 1", time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * */2", time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{"5/20 * * * *", time.Date(2026, 10, 16, 12, 45, 0, 0, time.UTC)},
+	}
+	for _, tc := range cases {
+		c, err := ParseCron(tc.expr)
+		if err != nil {
+			t.Fatalf("ParseCron(%q): %v", tc.expr, err)
+		}
+		if got := c.Next(from); !got.Equal(tc.want) {
+			t.Errorf("%q.Next = %s, want %s", tc.expr, got, tc.want)
+		}
+	}
+}
+
+func TestCronNextNeverFires(t *testing.T) {
+	c, err := ParseCron("0 0 30 2 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Next(time.Now()); !got.IsZero() {
+		t.Fatalf("Next = %s, want zero", got)
+	}
+}
+
+func TestParseCronRejectsInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "*/0 * * * *", "5-1 * * * *", "a * * * *", "@reboot"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) succeeded, want error", expr)
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"cloudpam/internal/domain"
 	"cloudpam/internal/storage"
+	"cloudpam/internal/webhook"
 )
 
 // DriftDetector compares discovered cloud resources against managed IPAM pools.
@@ -17,6 +18,7 @@ type DriftDetector struct {
 	store      storage.Store
 	discStore  storage.DiscoveryStore
 	driftStore storage.DriftStore
+	publisher  webhook.Publisher
 }
 
 // NewDriftDetector creates a new DriftDetector.
@@ -24,6 +26,12 @@ func NewDriftDetector(store storage.Store, discStore storage.DiscoveryStore, dri
 	return &DriftDetector{store: store, discStore: discStore, driftStore: driftStore}
 }
 
+// SetPublisher publishes a drift.detected webhook event after every run that
+// finds drift.
+func (d *DriftDetector) SetPublisher(p webhook.Publisher) {
+	d.publisher = p
+}
+
 // Detect runs drift detection across the specified accounts (or all accounts if empty).
 func (d *DriftDetector) Detect(ctx context.Context, req domain.RunDriftDetectionRequest) (*domain.RunDriftDetectionResponse, error) {
 	now := time.Now().UTC()
@@ -77,12 +85,24 @@ func (d *DriftDetector) Detect(ctx context.Context, req domain.RunDriftDetection
 
 		accountPools := poolsByAccount[acct.ID]
 
+		// Network interfaces in an imported subnet are tracked as IP address
+		// records in the subnet's pool rather than linked to a pool of their own.
+		managedSubnets := make(map[string]bool)
+		for _, res := range resources {
+			if res.ResourceType == domain.ResourceTypeSubnet && res.PoolID != nil {
+				managedSubnets[res.ResourceID] = true
+			}
+		}
+
 		// Pass 1: Check each active resource for drift.
 		for i := range resources {
 			res := &resources[i]
 			if res.CIDR == "" {
 				continue
 			}
+			if res.ResourceType == domain.ResourceTypeNetworkInterface && res.ParentResourceID != nil && managedSubnets[*res.ParentResourceID] {
+				continue
+			}
 
 			if res.PoolID == nil {
 				// Unmanaged: cloud resource with no linked pool.
@@ -183,11 +203,15 @@ func (d *DriftDetector) Detect(ctx context.Context, req domain.RunDriftDetection
 
 	summary := buildSummary(allItems, len(targetAccounts), totalResources, totalPools)
 
-	return &domain.RunDriftDetectionResponse{
+	resp := &domain.RunDriftDetectionResponse{
 		Items:   allItems,
 		Total:   len(allItems),
 		Summary: summary,
-	}, nil
+	}
+	if d.publisher != nil && len(allItems) > 0 {
+		d.publisher.Publish(ctx, domain.WebhookEventDriftDetected, webhook.NewDriftDetectedData(resp))
+	}
+	return resp, nil
 }
 
 func (d *DriftDetector) listActiveResources(ctx context.Context, accountID int64) ([]domain.DiscoveredResource, error) {
@@ -287,3 +287,50 @@ func TestCidrsEqual(t *testing.T) {
 		}
 	}
 }
+
+func TestDriftDetector_SkipsInterfacesInManagedSubnets(t *testing.T) {
+	ctx := context.Background()
+	ms := storage.NewMemoryStore()
+	ds := storage.NewMemoryDiscoveryStore(ms)
+	driftStore := storage.NewMemoryDriftStore(ms)
+
+	acct, err := ms.CreateAccount(ctx, domain.CreateAccount{Key: "aws:111", Name: "Test Account", Provider: "aws"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool, err := ms.CreatePool(ctx, domain.CreatePool{Name: "apps", CIDR: "10.0.1.0/24", AccountID: &acct.ID, Type: domain.PoolTypeSubnet})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().UTC()
+	subnetID, strayID := uuid.New(), uuid.New()
+	managed, unmanaged := "subnet-managed", "subnet-unmanaged"
+	for _, res := range []domain.DiscoveredResource{
+		{ID: subnetID, ResourceType: domain.ResourceTypeSubnet, ResourceID: managed, CIDR: "10.0.1.0/24"},
+		{ID: uuid.New(), ResourceType: domain.ResourceTypeNetworkInterface, ResourceID: "eni-1", CIDR: "10.0.1.10/32", ParentResourceID: &managed},
+		{ID: strayID, ResourceType: domain.ResourceTypeNetworkInterface, ResourceID: "eni-2", CIDR: "10.9.0.10/32", ParentResourceID: &unmanaged},
+	} {
+		res.AccountID = acct.ID
+		res.Provider = "aws"
+		res.Region = "us-east-1"
+		res.Status = domain.DiscoveryStatusActive
+		res.DiscoveredAt = now
+		res.LastSeenAt = now
+		if err := ds.UpsertDiscoveredResource(ctx, res); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ds.LinkResourceToPool(ctx, subnetID, pool.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	detector := NewDriftDetector(ms, ds, driftStore)
+	resp, err := detector.Detect(ctx, domain.RunDriftDetectionRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Total != 1 || resp.Items[0].ResourceID == nil || *resp.Items[0].ResourceID != strayID {
+		t.Fatalf("expected only eni-2 to drift, got %+v", resp.Items)
+	}
+}
@@ -1,4 +1,5 @@
-// Package gcp provides a GCP VPC network/subnetwork/address discovery collector.
+// Package gcp provides a GCP VPC network/subnetwork/address/network interface
+// discovery collector.
 // It uses the GCP Compute Engine REST API directly to avoid heavy SDK dependencies.
 package gcp
 
@@ -25,7 +26,8 @@ const (
 	computeScope   = "https://www.googleapis.com/auth/compute.readonly"
 )
 
-// Collector discovers GCP VPC networks, subnetworks, and external addresses.
+// Collector discovers GCP VPC networks, subnetworks, external addresses, and
+// instance network interfaces.
 type Collector struct {
 	tokenSource oauth2.TokenSource
 	httpClient  *http.Client
@@ -51,9 +53,11 @@ func NewWithHTTPClient(client *http.Client) *Collector {
 // Provider returns "gcp".
 func (c *Collector) Provider() string { return "gcp" }
 
-// Discover discovers VPC networks, subnetworks, and external addresses for the given account.
+// Discover discovers VPC networks, subnetworks, external addresses, and instance
+// network interfaces for the given account.
 // The account's ExternalID (or Key) is used as the GCP project ID.
-// If account.Regions is set, only subnetworks and addresses in those regions are returned.
+// If account.Regions is set, only subnetworks, addresses, and interfaces in
+// those regions are returned.
 func (c *Collector) Discover(ctx context.Context, account domain.Account) ([]domain.DiscoveredResource, error) {
 	project := ProjectID(account)
 	if project == "" {
@@ -98,6 +102,14 @@ func (c *Collector) Discover(ctx context.Context, account domain.Account) ([]dom
 		all = append(all, addrs...)
 	}
 
+	// Discover instance network interfaces (zonal, via aggregated list)
+	nics, err := discoverNetworkInterfaces(ctx, client, account, project, regionSet, subnetIDs(subnets), now)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("discover network interfaces for project %s: %w", project, err))
+	} else {
+		all = append(all, nics...)
+	}
+
 	// Surface endpoint failures so the caller does not treat a partial
 	// discovery as a complete inventory (which would mark resources stale).
 	if len(errs) > 0 {
@@ -133,6 +145,7 @@ type network struct {
 	ID                   uint64        `json:"id,string"`
 	Name                 string        `json:"name"`
 	IPv4Range            string        `json:"IPv4Range"`
+	InternalIpv6Range    string        `json:"internalIpv6Range"`
 	AutoCreateSubnetwork bool          `json:"autoCreateSubnetworks"`
 	RoutingConfig        routingConfig `json:"routingConfig"`
 	SelfLink             string        `json:"selfLink"`
@@ -160,6 +173,9 @@ type subnetwork struct {
 	Region                string `json:"region"`
 	Purpose               string `json:"purpose"`
 	StackType             string `json:"stackType"`
+	Ipv6AccessType        string `json:"ipv6AccessType"`
+	Ipv6CidrRange         string `json:"ipv6CidrRange"`
+	ExternalIpv6Prefix    string `json:"externalIpv6Prefix"`
 	PrivateIpGoogleAccess bool   `json:"privateIpGoogleAccess"`
 }
 
@@ -172,6 +188,31 @@ type addressesScopedList struct {
 	Addresses []address `json:"addresses"`
 }
 
+type aggregatedInstanceList struct {
+	Items         map[string]instancesScopedList `json:"items"`
+	NextPageToken string                         `json:"nextPageToken"`
+}
+
+type instancesScopedList struct {
+	Instances []instance `json:"instances"`
+}
+
+type instance struct {
+	ID                uint64             `json:"id,string"`
+	Name              string             `json:"name"`
+	Status            string             `json:"status"`
+	NetworkInterfaces []networkInterface `json:"networkInterfaces"`
+}
+
+type networkInterface struct {
+	Name        string `json:"name"`
+	Network     string `json:"network"`
+	Subnetwork  string `json:"subnetwork"`
+	NetworkIP   string `json:"networkIP"`
+	Ipv6Address string `json:"ipv6Address"`
+	StackType   string `json:"stackType"`
+}
+
 type address struct {
 	ID           uint64 `json:"id,string"`
 	Name         string `json:"name"`
@@ -225,6 +266,28 @@ func discoverNetworks(ctx context.Context, client *http.Client, account domain.A
 				DiscoveredAt: now,
 				LastSeenAt:   now,
 			})
+
+			// Networks with ULA internal IPv6 enabled carry a /48 that their
+			// dual-stack subnets draw from.
+			if net.InternalIpv6Range != "" {
+				resources = append(resources, domain.DiscoveredResource{
+					ID:           uuid.New(),
+					AccountID:    account.ID,
+					Provider:     "gcp",
+					Region:       "global",
+					ResourceType: domain.ResourceTypeVPC,
+					ResourceID:   ipv6ResourceID(fmt.Sprintf("%d", net.ID)),
+					Name:         net.Name,
+					CIDR:         net.InternalIpv6Range,
+					Status:       domain.DiscoveryStatusActive,
+					Metadata: map[string]string{
+						"address_family": "ipv6",
+						"network":        net.Name,
+					},
+					DiscoveredAt: now,
+					LastSeenAt:   now,
+				})
+			}
 		}
 
 		if result.NextPageToken == "" {
@@ -236,6 +299,12 @@ func discoverNetworks(ctx context.Context, client *http.Client, account domain.A
 	return resources, nil
 }
 
+// ipv6ResourceID derives the resource ID of the IPv6 range attached to a
+// network or subnetwork, which GCP reports as a field of the same object.
+func ipv6ResourceID(id string) string {
+	return id + "/ipv6"
+}
+
 func discoverSubnetworks(ctx context.Context, client *http.Client, account domain.Account, project string, regionSet map[string]bool, now time.Time) ([]domain.DiscoveredResource, error) {
 	var resources []domain.DiscoveredResource
 
@@ -288,6 +357,42 @@ func discoverSubnetworks(ctx context.Context, client *http.Client, account domai
 					DiscoveredAt:     now,
 					LastSeenAt:       now,
 				})
+
+				if subnet.Ipv6CidrRange != "" {
+					v6meta := map[string]string{
+						"address_family": "ipv6",
+						"network":        networkName,
+						"subnet_id":      fmt.Sprintf("%d", subnet.ID),
+					}
+					if subnet.Ipv6AccessType != "" {
+						v6meta["ipv6_access_type"] = subnet.Ipv6AccessType
+					}
+					if subnet.ExternalIpv6Prefix != "" {
+						v6meta["external_ipv6_prefix"] = subnet.ExternalIpv6Prefix
+					}
+					// Only internal ranges come from the network's ULA /48;
+					// external ranges are Google-owned and stand alone.
+					var v6Parent *string
+					if subnet.Ipv6AccessType != "EXTERNAL" {
+						ref := ipv6ResourceID(networkName)
+						v6Parent = &ref
+					}
+					resources = append(resources, domain.DiscoveredResource{
+						ID:               uuid.New(),
+						AccountID:        account.ID,
+						Provider:         "gcp",
+						Region:           region,
+						ResourceType:     domain.ResourceTypeSubnet,
+						ResourceID:       ipv6ResourceID(fmt.Sprintf("%d", subnet.ID)),
+						Name:             subnet.Name,
+						CIDR:             subnet.Ipv6CidrRange,
+						ParentResourceID: v6Parent,
+						Status:           domain.DiscoveryStatusActive,
+						Metadata:         v6meta,
+						DiscoveredAt:     now,
+						LastSeenAt:       now,
+					})
+				}
 			}
 		}
 
@@ -369,6 +474,113 @@ func discoverAddresses(ctx context.Context, client *http.Client, account domain.
 	return resources, nil
 }
 
+// discoverNetworkInterfaces lists the network interfaces of every VM instance.
+// Each interface becomes one network_interface resource whose CIDR is its
+// primary internal address and whose parent is the subnetwork's resource ID,
+// looked up in subnets by region and name. Interfaces in subnetworks that were
+// not discovered fall back to the subnetwork name.
+func discoverNetworkInterfaces(ctx context.Context, client *http.Client, account domain.Account, project string, regionSet map[string]bool, subnets map[string]string, now time.Time) ([]domain.DiscoveredResource, error) {
+	var resources []domain.DiscoveredResource
+
+	pageToken := ""
+	for {
+		url := fmt.Sprintf("%s/projects/%s/aggregated/instances", computeBaseURL, project)
+		if pageToken != "" {
+			url += "?pageToken=" + pageToken
+		}
+
+		var result aggregatedInstanceList
+		if err := doGet(ctx, client, url, &result); err != nil {
+			return nil, err
+		}
+
+		for scope, item := range result.Items {
+			region := regionFromZoneScope(scope)
+			if len(regionSet) > 0 && !regionSet[region] {
+				continue
+			}
+
+			for _, inst := range item.Instances {
+				for _, nic := range inst.NetworkInterfaces {
+					var ips []string
+					if nic.NetworkIP != "" {
+						ips = append(ips, nic.NetworkIP)
+					}
+					if nic.Ipv6Address != "" {
+						ips = append(ips, nic.Ipv6Address)
+					}
+					if len(ips) == 0 {
+						continue
+					}
+
+					subnetName := LastPathComponent(nic.Subnetwork)
+					parentRef := subnetName
+					if id, ok := subnets[region+"/"+subnetName]; ok {
+						parentRef = id
+					}
+
+					meta := map[string]string{
+						domain.NICMetaAddresses: strings.Join(ips, ","),
+						domain.NICMetaHostname:  inst.Name,
+						"network":               LastPathComponent(nic.Network),
+						"subnetwork":            subnetName,
+						"instance_id":           fmt.Sprintf("%d", inst.ID),
+						"instance_status":       inst.Status,
+					}
+					if nic.StackType != "" {
+						meta["stack_type"] = nic.StackType
+					}
+
+					resources = append(resources, domain.DiscoveredResource{
+						ID:               uuid.New(),
+						AccountID:        account.ID,
+						Provider:         "gcp",
+						Region:           region,
+						ResourceType:     domain.ResourceTypeNetworkInterface,
+						ResourceID:       fmt.Sprintf("%d/%s", inst.ID, nic.Name),
+						Name:             inst.Name + "/" + nic.Name,
+						CIDR:             hostCIDR(ips[0]),
+						ParentResourceID: &parentRef,
+						Status:           domain.DiscoveryStatusActive,
+						Metadata:         meta,
+						DiscoveredAt:     now,
+						LastSeenAt:       now,
+					})
+				}
+			}
+		}
+
+		if result.NextPageToken == "" {
+			break
+		}
+		pageToken = result.NextPageToken
+	}
+
+	return resources, nil
+}
+
+// subnetIDs maps "region/name" of each discovered IPv4 subnetwork to its
+// resource ID, so network interfaces can reference their subnetwork.
+func subnetIDs(subnets []domain.DiscoveredResource) map[string]string {
+	out := make(map[string]string, len(subnets))
+	for _, s := range subnets {
+		if s.Metadata["address_family"] == "ipv6" {
+			continue
+		}
+		out[s.Region+"/"+s.Name] = s.ResourceID
+	}
+	return out
+}
+
+// hostCIDR renders a single address as a host prefix, or "" if unparseable.
+func hostCIDR(raw string) string {
+	ip, err := netip.ParseAddr(raw)
+	if err != nil {
+		return ""
+	}
+	return netip.PrefixFrom(ip, ip.BitLen()).String()
+}
+
 // addressCIDR renders a GCP address as a CIDR prefix.
 // GCP reports IPv4 addresses as single hosts and IPv6 addresses either as a
 // bare address or with an explicit prefixLength, so the prefix length must be
@@ -445,6 +657,16 @@ func RegionFromScope(scope string) string {
 	return scope
 }
 
+// regionFromZoneScope extracts the region from a zonal aggregated list scope
+// key such as "zones/us-central1-a".
+func regionFromZoneScope(scope string) string {
+	zone := strings.TrimPrefix(scope, "zones/")
+	if idx := strings.LastIndex(zone, "-"); idx > 0 {
+		return zone[:idx]
+	}
+	return zone
+}
+
 // LastPathComponent returns the last component of a resource URL path.
 // For example, ".../projects/my-project/global/networks/default" returns "default".
 func LastPathComponent(url string) string {
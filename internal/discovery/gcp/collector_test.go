@@ -204,6 +204,11 @@ func TestDiscover_MockAPI(t *testing.T) {
 			t.Fatalf("encode address list response: %v", err)
 		}
 	})
+	mux.HandleFunc("/compute/v1/projects/test-project/aggregated/instances", func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewEncoder(w).Encode(aggregatedInstanceList{}); err != nil {
+			t.Fatalf("encode instance list: %v", err)
+		}
+	})
 
 	server := httptest.NewServer(mux)
 	defer server.Close()
@@ -323,6 +328,11 @@ func TestDiscover_RegionFilter(t *testing.T) {
 			t.Fatalf("encode empty address list response: %v", err)
 		}
 	})
+	mux.HandleFunc("/compute/v1/projects/test-project/aggregated/instances", func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewEncoder(w).Encode(aggregatedInstanceList{}); err != nil {
+			t.Fatalf("encode instance list: %v", err)
+		}
+	})
 
 	server := httptest.NewServer(mux)
 	defer server.Close()
@@ -443,6 +453,11 @@ func TestDiscover_IPv6ExternalAddress(t *testing.T) {
 			t.Fatalf("encode address list: %v", err)
 		}
 	})
+	mux.HandleFunc("/compute/v1/projects/test-project/aggregated/instances", func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewEncoder(w).Encode(aggregatedInstanceList{}); err != nil {
+			t.Fatalf("encode instance list: %v", err)
+		}
+	})
 
 	server := httptest.NewServer(mux)
 	defer server.Close()
@@ -463,6 +478,72 @@ func TestDiscover_IPv6ExternalAddress(t *testing.T) {
 	}
 }
 
+func TestDiscover_IPv6Ranges(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/compute/v1/projects/test-project/global/networks", func(w http.ResponseWriter, r *http.Request) {
+		resp := networkList{Items: []network{{ID: 10, Name: "dual", InternalIpv6Range: "fd20:1:2::/48"}}}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Fatalf("encode network list: %v", err)
+		}
+	})
+	mux.HandleFunc("/compute/v1/projects/test-project/aggregated/subnetworks", func(w http.ResponseWriter, r *http.Request) {
+		resp := aggregatedSubnetworkList{Items: map[string]subnetworksScopedList{
+			"regions/us-central1": {Subnetworks: []subnetwork{
+				{ID: 20, Name: "internal", Network: "projects/test-project/global/networks/dual", IpCidrRange: "10.0.0.0/24",
+					StackType: "IPV4_IPV6", Ipv6AccessType: "INTERNAL", Ipv6CidrRange: "fd20:1:2::/64"},
+				{ID: 21, Name: "external", Network: "projects/test-project/global/networks/dual", IpCidrRange: "10.0.1.0/24",
+					StackType: "IPV4_IPV6", Ipv6AccessType: "EXTERNAL", Ipv6CidrRange: "2600:1900:4001:abc::/64", ExternalIpv6Prefix: "2600:1900:4001:abc::/64"},
+			}},
+		}}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Fatalf("encode subnetwork list: %v", err)
+		}
+	})
+	mux.HandleFunc("/compute/v1/projects/test-project/aggregated/addresses", func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewEncoder(w).Encode(aggregatedAddressList{}); err != nil {
+			t.Fatalf("encode address list: %v", err)
+		}
+	})
+	mux.HandleFunc("/compute/v1/projects/test-project/aggregated/instances", func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewEncoder(w).Encode(aggregatedInstanceList{}); err != nil {
+			t.Fatalf("encode instance list: %v", err)
+		}
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	collector := NewWithHTTPClient(&http.Client{
+		Transport: &rewriteTransport{base: server.Client().Transport, baseURL: server.URL},
+	})
+
+	resources, err := collector.Discover(context.Background(), domain.Account{ID: 1, ExternalID: "test-project"})
+	if err != nil {
+		t.Fatalf("Discover() error: %v", err)
+	}
+	byID := map[string]domain.DiscoveredResource{}
+	for _, r := range resources {
+		byID[r.ResourceID] = r
+	}
+	if len(resources) != 6 {
+		t.Fatalf("got %d resources, want 6: %+v", len(resources), resources)
+	}
+	if got := byID["10/ipv6"]; got.CIDR != "fd20:1:2::/48" || got.ResourceType != domain.ResourceTypeVPC {
+		t.Errorf("network IPv6 range = %+v", got)
+	}
+	internal := byID["20/ipv6"]
+	if internal.ParentResourceID == nil || *internal.ParentResourceID != "dual/ipv6" {
+		t.Errorf("internal subnet parent = %v, want dual/ipv6", internal.ParentResourceID)
+	}
+	external := byID["21/ipv6"]
+	if external.ParentResourceID != nil {
+		t.Errorf("external subnet parent = %v, want none", *external.ParentResourceID)
+	}
+	if external.Metadata["ipv6_access_type"] != "EXTERNAL" || external.Metadata["external_ipv6_prefix"] == "" {
+		t.Errorf("external subnet metadata = %v", external.Metadata)
+	}
+}
+
 func TestDiscover_PropagatesEndpointFailures(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -472,6 +553,7 @@ func TestDiscover_PropagatesEndpointFailures(t *testing.T) {
 		{name: "networks fail", failing: "/compute/v1/projects/test-project/global/networks", wantIn: "discover networks"},
 		{name: "subnetworks fail", failing: "/compute/v1/projects/test-project/aggregated/subnetworks", wantIn: "discover subnetworks"},
 		{name: "addresses fail", failing: "/compute/v1/projects/test-project/aggregated/addresses", wantIn: "discover addresses"},
+		{name: "instances fail", failing: "/compute/v1/projects/test-project/aggregated/instances", wantIn: "discover network interfaces"},
 	}
 
 	for _, tt := range tests {
@@ -481,6 +563,7 @@ func TestDiscover_PropagatesEndpointFailures(t *testing.T) {
 				"/compute/v1/projects/test-project/global/networks",
 				"/compute/v1/projects/test-project/aggregated/subnetworks",
 				"/compute/v1/projects/test-project/aggregated/addresses",
+				"/compute/v1/projects/test-project/aggregated/instances",
 			} {
 				if path == tt.failing {
 					mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
@@ -512,3 +595,98 @@ func TestDiscover_PropagatesEndpointFailures(t *testing.T) {
 		})
 	}
 }
+
+func TestDiscover_NetworkInterfaces(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/compute/v1/projects/test-project/global/networks", func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewEncoder(w).Encode(networkList{}); err != nil {
+			t.Fatalf("encode network list: %v", err)
+		}
+	})
+	mux.HandleFunc("/compute/v1/projects/test-project/aggregated/subnetworks", func(w http.ResponseWriter, r *http.Request) {
+		resp := aggregatedSubnetworkList{Items: map[string]subnetworksScopedList{
+			"regions/us-central1": {Subnetworks: []subnetwork{
+				{ID: 30, Name: "apps", Network: "projects/test-project/global/networks/default", IpCidrRange: "10.0.0.0/24"},
+			}},
+		}}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Fatalf("encode subnetwork list: %v", err)
+		}
+	})
+	mux.HandleFunc("/compute/v1/projects/test-project/aggregated/addresses", func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewEncoder(w).Encode(aggregatedAddressList{}); err != nil {
+			t.Fatalf("encode address list: %v", err)
+		}
+	})
+	mux.HandleFunc("/compute/v1/projects/test-project/aggregated/instances", func(w http.ResponseWriter, r *http.Request) {
+		resp := aggregatedInstanceList{Items: map[string]instancesScopedList{
+			"zones/us-central1-a": {Instances: []instance{{
+				ID:     900,
+				Name:   "web-1",
+				Status: "RUNNING",
+				NetworkInterfaces: []networkInterface{{
+					Name:        "nic0",
+					Network:     "projects/test-project/global/networks/default",
+					Subnetwork:  "projects/test-project/regions/us-central1/subnetworks/apps",
+					NetworkIP:   "10.0.0.7",
+					Ipv6Address: "fd20:1:2::7",
+					StackType:   "IPV4_IPV6",
+				}},
+			}}},
+			"zones/europe-west1-b": {Instances: []instance{{
+				ID:   901,
+				Name: "eu-1",
+				NetworkInterfaces: []networkInterface{{
+					Name:       "nic0",
+					Subnetwork: "projects/test-project/regions/europe-west1/subnetworks/eu",
+					NetworkIP:  "10.1.0.7",
+				}},
+			}}},
+		}}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			t.Fatalf("encode instance list: %v", err)
+		}
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	collector := NewWithHTTPClient(&http.Client{
+		Transport: &rewriteTransport{base: server.Client().Transport, baseURL: server.URL},
+	})
+
+	resources, err := collector.Discover(context.Background(), domain.Account{ID: 1, ExternalID: "test-project"})
+	if err != nil {
+		t.Fatalf("Discover() error: %v", err)
+	}
+	byID := map[string]domain.DiscoveredResource{}
+	for _, r := range resources {
+		byID[r.ResourceID] = r
+	}
+
+	nic, ok := byID["900/nic0"]
+	if !ok {
+		t.Fatalf("network interface 900/nic0 not discovered: %+v", resources)
+	}
+	if nic.ResourceType != domain.ResourceTypeNetworkInterface || nic.Region != "us-central1" {
+		t.Errorf("nic = %+v", nic)
+	}
+	if nic.CIDR != "10.0.0.7/32" {
+		t.Errorf("nic CIDR = %q, want 10.0.0.7/32", nic.CIDR)
+	}
+	if nic.ParentResourceID == nil || *nic.ParentResourceID != "30" {
+		t.Errorf("nic parent = %v, want subnetwork ID 30", nic.ParentResourceID)
+	}
+	if got := nic.Metadata[domain.NICMetaAddresses]; got != "10.0.0.7,fd20:1:2::7" {
+		t.Errorf("nic addresses = %q", got)
+	}
+	if got := nic.Metadata[domain.NICMetaHostname]; got != "web-1" {
+		t.Errorf("nic hostname = %q, want web-1", got)
+	}
+
+	// Undiscovered subnetworks fall back to the subnetwork name.
+	eu := byID["901/nic0"]
+	if eu.Region != "europe-west1" || eu.ParentResourceID == nil || *eu.ParentResourceID != "eu" {
+		t.Errorf("eu nic = %+v", eu)
+	}
+}
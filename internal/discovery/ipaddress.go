@@ -0,0 +1,240 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+// IPAddressReconciler keeps the address records of subnet pools in step with
+// the network interfaces discovered in an account. Every address on an active
+// interface is recorded in the pool linked to the interface's subnet;
+// discovered records whose interface went away are removed, and manual
+// records that match a discovered address are linked to the interface.
+type IPAddressReconciler struct {
+	discovery storage.DiscoveryStore
+	addresses storage.IPAddressStore
+}
+
+// NewIPAddressReconciler creates a reconciler over the given stores.
+func NewIPAddressReconciler(discovery storage.DiscoveryStore, addresses storage.IPAddressStore) *IPAddressReconciler {
+	return &IPAddressReconciler{discovery: discovery, addresses: addresses}
+}
+
+// desiredAddress is an address an active interface holds in a linked pool.
+type desiredAddress struct {
+	resourceID uuid.UUID
+	hostname   string
+	mac        string
+}
+
+type poolAddressKey struct {
+	poolID  int64
+	address string
+}
+
+// Reconcile brings the address records of every pool linked to a subnet in
+// the account up to date with the account's network interfaces.
+func (r *IPAddressReconciler) Reconcile(ctx context.Context, accountID int64) error {
+	var subnets, nics []domain.DiscoveredResource
+	const pageSize = 1000
+	for page := 1; ; page++ {
+		resources, total, err := r.discovery.ListDiscoveredResources(ctx, accountID, domain.DiscoveryFilters{
+			Page:     page,
+			PageSize: pageSize,
+		})
+		if err != nil {
+			return fmt.Errorf("list discovered resources: %w", err)
+		}
+		for _, res := range resources {
+			switch {
+			case res.ResourceType == domain.ResourceTypeSubnet && res.PoolID != nil:
+				subnets = append(subnets, res)
+			case res.ResourceType == domain.ResourceTypeNetworkInterface:
+				nics = append(nics, res)
+			}
+		}
+		if len(resources) == 0 || page*pageSize >= total {
+			break
+		}
+	}
+	if len(subnets) == 0 {
+		return nil
+	}
+
+	accountNICs := make(map[uuid.UUID]bool, len(nics))
+	desired := make(map[poolAddressKey]desiredAddress)
+	for _, nic := range nics {
+		accountNICs[nic.ID] = true
+		if nic.Status != domain.DiscoveryStatusActive {
+			continue
+		}
+		parent := ""
+		if nic.ParentResourceID != nil {
+			parent = *nic.ParentResourceID
+		}
+		for _, raw := range strings.Split(nic.Metadata[domain.NICMetaAddresses], ",") {
+			addr, err := netip.ParseAddr(strings.TrimSpace(raw))
+			if err != nil {
+				continue
+			}
+			poolID, ok := subnetPoolFor(subnets, parent, addr)
+			if !ok {
+				continue
+			}
+			desired[poolAddressKey{poolID, addr.String()}] = desiredAddress{
+				resourceID: nic.ID,
+				hostname:   nic.Metadata[domain.NICMetaHostname],
+				mac:        nic.Metadata[domain.NICMetaMACAddress],
+			}
+		}
+	}
+
+	var errs []error
+	now := time.Now().UTC()
+	seenPools := make(map[int64]bool)
+	for _, subnet := range subnets {
+		poolID := *subnet.PoolID
+		if seenPools[poolID] {
+			continue
+		}
+		seenPools[poolID] = true
+
+		existing, err := r.poolRecords(ctx, poolID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("list addresses of pool %d: %w", poolID, err))
+			continue
+		}
+		for _, rec := range existing {
+			key := poolAddressKey{poolID, rec.Address}
+			want, ok := desired[key]
+			if ok {
+				delete(desired, key)
+				if updated, changed := linkIPAddress(rec, want); changed {
+					updated.UpdatedAt = now
+					if err := r.addresses.UpdateIPAddress(ctx, updated); err != nil {
+						errs = append(errs, fmt.Errorf("update address %s: %w", rec.Address, err))
+					}
+				}
+				continue
+			}
+
+			linkedHere := rec.ResourceID != nil && accountNICs[*rec.ResourceID]
+			orphaned := rec.ResourceID == nil && rec.Source == domain.PoolSourceDiscovered
+			if !linkedHere && !orphaned {
+				continue
+			}
+			if rec.Source == domain.PoolSourceDiscovered {
+				if err := r.addresses.DeleteIPAddress(ctx, rec.ID); err != nil && !errors.Is(err, storage.ErrNotFound) {
+					errs = append(errs, fmt.Errorf("delete address %s: %w", rec.Address, err))
+				}
+				continue
+			}
+			rec.ResourceID = nil
+			rec.UpdatedAt = now
+			if err := r.addresses.UpdateIPAddress(ctx, rec); err != nil {
+				errs = append(errs, fmt.Errorf("unlink address %s: %w", rec.Address, err))
+			}
+		}
+	}
+
+	for key, want := range desired {
+		resourceID := want.resourceID
+		err := r.addresses.CreateIPAddress(ctx, domain.IPAddress{
+			ID:         uuid.NewString(),
+			PoolID:     key.poolID,
+			Address:    key.address,
+			Hostname:   want.hostname,
+			MACAddress: want.mac,
+			Status:     domain.IPAddressStatusAssigned,
+			Source:     domain.PoolSourceDiscovered,
+			ResourceID: &resourceID,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		})
+		// A pool deleted since its subnet was linked has nowhere to record
+		// the address.
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			errs = append(errs, fmt.Errorf("record address %s in pool %d: %w", key.address, key.poolID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (r *IPAddressReconciler) poolRecords(ctx context.Context, poolID int64) ([]domain.IPAddress, error) {
+	var out []domain.IPAddress
+	const pageSize = 1000
+	for page := 1; ; page++ {
+		items, total, err := r.addresses.ListIPAddresses(ctx, domain.IPAddressFilters{
+			PoolID:   poolID,
+			Page:     page,
+			PageSize: pageSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, items...)
+		if len(items) == 0 || page*pageSize >= total {
+			return out, nil
+		}
+	}
+}
+
+// subnetPoolFor returns the pool of the linked subnet that holds addr. The
+// interface's own subnet wins (matched by resource ID, or by subnet_id for
+// IPv6 blocks tracked as separate resources); otherwise the most specific
+// linked subnet containing the address is used.
+func subnetPoolFor(subnets []domain.DiscoveredResource, parent string, addr netip.Addr) (int64, bool) {
+	var best *domain.DiscoveredResource
+	bestBits := -1
+	for i := range subnets {
+		s := &subnets[i]
+		prefix, err := netip.ParsePrefix(s.CIDR)
+		if err != nil || !prefix.Contains(addr) {
+			continue
+		}
+		if parent != "" && (s.ResourceID == parent || s.Metadata["subnet_id"] == parent) {
+			return *s.PoolID, true
+		}
+		if prefix.Bits() > bestBits {
+			best, bestBits = s, prefix.Bits()
+		}
+	}
+	if best == nil {
+		return 0, false
+	}
+	return *best.PoolID, true
+}
+
+// linkIPAddress points rec at the interface holding its address. Discovered
+// records take the interface's hostname and MAC; manual records only have
+// blanks filled in.
+func linkIPAddress(rec domain.IPAddress, want desiredAddress) (domain.IPAddress, bool) {
+	changed := false
+	if rec.ResourceID == nil || *rec.ResourceID != want.resourceID {
+		id := want.resourceID
+		rec.ResourceID = &id
+		changed = true
+	}
+	fill := func(field *string, value string) {
+		if value == "" || *field == value {
+			return
+		}
+		if *field == "" || rec.Source == domain.PoolSourceDiscovered {
+			*field = value
+			changed = true
+		}
+	}
+	fill(&rec.Hostname, want.hostname)
+	fill(&rec.MACAddress, want.mac)
+	return rec, changed
+}
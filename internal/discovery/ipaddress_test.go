@@ -0,0 +1,135 @@
+package discovery
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+func TestProcessResourcesReconcilesIPAddresses(t *testing.T) {
+	ctx := context.Background()
+	ms := storage.NewMemoryStore()
+	ds := storage.NewMemoryDiscoveryStore(ms)
+	ips := storage.NewMemoryIPAddressStore(ms)
+	svc := NewSyncService(ds)
+	svc.SetIPAddressReconciler(NewIPAddressReconciler(ds, ips))
+
+	pool, err := ms.CreatePool(ctx, domain.CreatePool{Name: "apps", CIDR: "10.0.1.0/24", Type: domain.PoolTypeSubnet})
+	if err != nil {
+		t.Fatalf("create pool: %v", err)
+	}
+
+	now := time.Now().UTC()
+	subnet := domain.DiscoveredResource{
+		ID: uuid.New(), AccountID: 7, Provider: "aws", Region: "us-east-1",
+		ResourceType: domain.ResourceTypeSubnet, ResourceID: "subnet-1", CIDR: "10.0.1.0/24",
+		Status: domain.DiscoveryStatusActive, DiscoveredAt: now, LastSeenAt: now,
+	}
+	if err := ds.UpsertDiscoveredResource(ctx, subnet); err != nil {
+		t.Fatalf("upsert subnet: %v", err)
+	}
+	if err := ds.LinkResourceToPool(ctx, subnet.ID, pool.ID); err != nil {
+		t.Fatalf("link subnet: %v", err)
+	}
+
+	// A manual record for an address the interface holds gets linked.
+	manualID := uuid.NewString()
+	if err := ips.CreateIPAddress(ctx, domain.IPAddress{
+		ID: manualID, PoolID: pool.ID, Address: "10.0.1.11", Owner: "team-a",
+		Status: domain.IPAddressStatusReserved, Source: domain.PoolSourceManual, CreatedAt: now, UpdatedAt: now,
+	}); err != nil {
+		t.Fatalf("create manual record: %v", err)
+	}
+
+	parent := "subnet-1"
+	eni := func(seen time.Time) domain.DiscoveredResource {
+		return domain.DiscoveredResource{
+			ID: uuid.New(), AccountID: 7, Provider: "aws", Region: "us-east-1",
+			ResourceType: domain.ResourceTypeNetworkInterface, ResourceID: "eni-1", CIDR: "10.0.1.10/32",
+			ParentResourceID: &parent, Status: domain.DiscoveryStatusActive,
+			Metadata: map[string]string{
+				domain.NICMetaAddresses:  "10.0.1.10,10.0.1.11,192.168.0.5",
+				domain.NICMetaHostname:   "ip-10-0-1-10.ec2.internal",
+				domain.NICMetaMACAddress: "0e:00:00:00:00:01",
+			},
+			DiscoveredAt: seen, LastSeenAt: seen,
+		}
+	}
+	resources := []domain.DiscoveredResource{subnet, eni(now)}
+	if _, _, _, err := svc.ProcessResources(ctx, 7, resources, now); err != nil {
+		t.Fatalf("ProcessResources() error: %v", err)
+	}
+
+	items, total, err := ips.ListIPAddresses(ctx, domain.IPAddressFilters{PoolID: pool.ID})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if total != 2 {
+		t.Fatalf("total = %d, want 2 (address outside the subnet is skipped): %+v", total, items)
+	}
+	discovered, manual := items[0], items[1]
+	if discovered.Address != "10.0.1.10" || discovered.Source != domain.PoolSourceDiscovered ||
+		discovered.Status != domain.IPAddressStatusAssigned || discovered.Hostname != "ip-10-0-1-10.ec2.internal" ||
+		discovered.ResourceID == nil {
+		t.Errorf("discovered record = %+v", discovered)
+	}
+	if manual.ID != manualID || manual.ResourceID == nil || manual.Owner != "team-a" ||
+		manual.Status != domain.IPAddressStatusReserved || manual.MACAddress != "0e:00:00:00:00:01" {
+		t.Errorf("manual record = %+v", manual)
+	}
+
+	stats, err := ms.CalculatePoolUtilization(ctx, pool.ID)
+	if err != nil {
+		t.Fatalf("utilization: %v", err)
+	}
+	if stats.UsedIPs != 2 {
+		t.Errorf("UsedIPs = %d, want 2", stats.UsedIPs)
+	}
+
+	// The interface disappears: the discovered record goes away and the
+	// manual record is kept but unlinked.
+	later := now.Add(time.Hour)
+	if _, _, _, err := svc.ProcessResources(ctx, 7, []domain.DiscoveredResource{subnet}, later); err != nil {
+		t.Fatalf("ProcessResources() second run error: %v", err)
+	}
+	items, total, err = ips.ListIPAddresses(ctx, domain.IPAddressFilters{PoolID: pool.ID})
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if total != 1 || items[0].ID != manualID || items[0].ResourceID != nil {
+		t.Errorf("after interface removal got %+v", items)
+	}
+}
+
+func TestSubnetPoolForPrefersInterfaceSubnet(t *testing.T) {
+	poolA, poolB := int64(1), int64(2)
+	subnets := []domain.DiscoveredResource{
+		{ResourceID: "subnet-a", CIDR: "10.0.0.0/24", PoolID: &poolA},
+		{ResourceID: "subnet-b/ipv6", CIDR: "10.0.0.0/24", PoolID: &poolB, Metadata: map[string]string{"subnet_id": "subnet-b"}},
+	}
+	addr := mustAddr(t, "10.0.0.5")
+	if got, ok := subnetPoolFor(subnets, "subnet-b", addr); !ok || got != poolB {
+		t.Errorf("subnetPoolFor(subnet-b) = %d, %v; want %d", got, ok, poolB)
+	}
+	if got, ok := subnetPoolFor(subnets, "subnet-x", addr); !ok || got != poolA {
+		t.Errorf("subnetPoolFor(unknown parent) = %d, %v; want %d", got, ok, poolA)
+	}
+	if _, ok := subnetPoolFor(subnets, "subnet-a", mustAddr(t, "10.9.0.1")); ok {
+		t.Error("subnetPoolFor() matched an address outside every subnet")
+	}
+}
+
+func mustAddr(t *testing.T, s string) netip.Addr {
+	t.Helper()
+	a, err := netip.ParseAddr(s)
+	if err != nil {
+		t.Fatalf("parse %q: %v", s, err)
+	}
+	return a
+}
@@ -0,0 +1,351 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+// MinScheduleInterval is the shortest interval a discovery schedule may use.
+const MinScheduleInterval = 5 * time.Minute
+
+// ValidateSchedule checks that exactly one of Interval or Cron is set and
+// that it parses.
+func ValidateSchedule(sc domain.DiscoverySchedule) error {
+	switch {
+	case sc.Interval != "" && sc.Cron != "":
+		return errors.New("set either interval or cron, not both")
+	case sc.Interval != "":
+		d, err := time.ParseDuration(sc.Interval)
+		if err != nil {
+			return fmt.Errorf("invalid interval: %w", err)
+		}
+		if d < MinScheduleInterval {
+			return fmt.Errorf("interval must be at least %s", MinScheduleInterval)
+		}
+	case sc.Cron != "":
+		if _, err := ParseCron(sc.Cron); err != nil {
+			return fmt.Errorf("invalid cron: %w", err)
+		}
+	default:
+		return errors.New("interval or cron is required")
+	}
+	return nil
+}
+
+// NextScheduledRun returns the first run of sc strictly after after. The
+// schedule must be valid.
+func NextScheduledRun(sc domain.DiscoverySchedule, after time.Time) (time.Time, error) {
+	if sc.Cron != "" {
+		c, err := ParseCron(sc.Cron)
+		if err != nil {
+			return time.Time{}, err
+		}
+		next := c.Next(after)
+		if next.IsZero() {
+			return time.Time{}, fmt.Errorf("cron %q never fires", sc.Cron)
+		}
+		return next, nil
+	}
+	d, err := time.ParseDuration(sc.Interval)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return after.UTC().Add(d).Truncate(time.Second), nil
+}
+
+// SyncRunner starts a discovery sync for an account, either by running the
+// local collector or by queuing a job for a connected agent. A queued agent
+// job is returned with status pending.
+type SyncRunner interface {
+	StartAccountSync(ctx context.Context, account domain.Account) (*domain.SyncJob, error)
+}
+
+// SchedulerConfig tunes the Scheduler. Zero values select the defaults.
+type SchedulerConfig struct {
+	Concurrency   int           // maximum runs in flight; default 4
+	Jitter        time.Duration // random delay added to each computed run; default 1m, negative disables
+	PollInterval  time.Duration // how often due schedules are checked; default 30s
+	QueuedTimeout time.Duration // how long to wait for an agent to finish a queued job; default 1h
+	Logger        *slog.Logger
+}
+
+// Scheduler runs discovery syncs, and drift detection after them, on each
+// account's schedule. Syncs for accounts with a healthy agent are queued for
+// the agent; drift detection for those runs once the agent reports the job
+// complete.
+type Scheduler struct {
+	schedules storage.DiscoveryScheduleStore
+	accounts  storage.Store
+	jobs      storage.DiscoveryStore
+	runner    SyncRunner
+	drift     *DriftDetector
+	cfg       SchedulerConfig
+	logger    *slog.Logger
+	now       func() time.Time
+	jitter    func() time.Duration
+
+	sem     chan struct{}
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	running map[int64]bool
+}
+
+// NewScheduler creates a Scheduler. drift may be nil to skip drift detection.
+func NewScheduler(schedules storage.DiscoveryScheduleStore, accounts storage.Store, jobs storage.DiscoveryStore, runner SyncRunner, drift *DriftDetector, cfg SchedulerConfig) *Scheduler {
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 4
+	}
+	if cfg.Jitter == 0 {
+		cfg.Jitter = time.Minute
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 30 * time.Second
+	}
+	if cfg.QueuedTimeout <= 0 {
+		cfg.QueuedTimeout = time.Hour
+	}
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+	s := &Scheduler{
+		schedules: schedules,
+		accounts:  accounts,
+		jobs:      jobs,
+		runner:    runner,
+		drift:     drift,
+		cfg:       cfg,
+		logger:    logger,
+		now:       func() time.Time { return time.Now().UTC() },
+		sem:       make(chan struct{}, cfg.Concurrency),
+		running:   make(map[int64]bool),
+	}
+	s.jitter = func() time.Duration {
+		if s.cfg.Jitter <= 0 {
+			return 0
+		}
+		return rand.N(s.cfg.Jitter)
+	}
+	return s
+}
+
+// Run checks for due schedules every PollInterval until ctx is done, then
+// waits for in-flight runs to finish.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+	s.Tick(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			s.Wait()
+			return
+		case <-ticker.C:
+			s.Tick(ctx)
+		}
+	}
+}
+
+// Tick follows up on queued agent jobs and starts every due schedule that
+// fits within the concurrency limit. Runs continue in the background; use
+// Wait to block until they finish.
+func (s *Scheduler) Tick(ctx context.Context) {
+	s.followQueued(ctx)
+
+	now := s.now()
+	due, err := s.schedules.ListDueDiscoverySchedules(ctx, now)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.WarnContext(ctx, "discovery scheduler: list due schedules failed", "error", err)
+		}
+		return
+	}
+	for _, sc := range due {
+		if s.isRunning(sc.AccountID) {
+			// Skip this occurrence rather than stacking runs behind a slow one.
+			s.claim(ctx, sc, now)
+			s.logger.InfoContext(ctx, "discovery scheduler: previous run still in progress; skipping", "account_id", sc.AccountID)
+			continue
+		}
+		if !s.acquire() {
+			// Left due, so it starts on a later tick once a slot frees.
+			return
+		}
+		if !s.claim(ctx, sc, now) {
+			<-s.sem
+			continue
+		}
+		s.start(sc.AccountID, func() { s.runSchedule(ctx, sc, now) })
+	}
+}
+
+// Wait blocks until all runs started by Tick have finished.
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+// claim advances the schedule's next run so no other tick or replica fires
+// it, and reports whether this scheduler won.
+func (s *Scheduler) claim(ctx context.Context, sc domain.DiscoverySchedule, now time.Time) bool {
+	next, err := NextScheduledRun(sc, now)
+	if err != nil {
+		s.logger.WarnContext(ctx, "discovery scheduler: invalid schedule", "account_id", sc.AccountID, "error", err)
+		return false
+	}
+	next = next.Add(s.jitter()).Truncate(time.Second)
+	ok, err := s.schedules.ClaimDiscoverySchedule(ctx, sc.AccountID, *sc.NextRunAt, next)
+	if err != nil {
+		s.logger.WarnContext(ctx, "discovery scheduler: claim failed", "account_id", sc.AccountID, "error", err)
+		return false
+	}
+	return ok
+}
+
+func (s *Scheduler) acquire() bool {
+	select {
+	case s.sem <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *Scheduler) isRunning(accountID int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.running[accountID]
+}
+
+// start runs fn in the background holding an already-acquired slot.
+func (s *Scheduler) start(accountID int64, fn func()) {
+	s.mu.Lock()
+	s.running[accountID] = true
+	s.mu.Unlock()
+	s.wg.Add(1)
+	go func() {
+		defer func() {
+			s.mu.Lock()
+			delete(s.running, accountID)
+			s.mu.Unlock()
+			<-s.sem
+			s.wg.Done()
+		}()
+		fn()
+	}()
+}
+
+func (s *Scheduler) runSchedule(ctx context.Context, sc domain.DiscoverySchedule, at time.Time) {
+	run := domain.DiscoveryScheduleRun{At: at}
+	defer func() { s.record(ctx, sc.AccountID, run) }()
+
+	account, found, err := s.accounts.GetAccount(ctx, sc.AccountID)
+	if err != nil || !found {
+		run.Status, run.Error = domain.ScheduleRunFailed, "account not found"
+		if err != nil {
+			run.Error = err.Error()
+		}
+		return
+	}
+
+	job, err := s.runner.StartAccountSync(ctx, account)
+	if job != nil {
+		run.SyncJobID = &job.ID
+	}
+	if err != nil {
+		run.Status, run.Error = domain.ScheduleRunFailed, err.Error()
+		return
+	}
+	switch job.Status {
+	case domain.SyncJobStatusPending, domain.SyncJobStatusRunning:
+		run.Status = domain.ScheduleRunQueued
+		return
+	case domain.SyncJobStatusFailed:
+		run.Status, run.Error = domain.ScheduleRunFailed, job.ErrorMessage
+		return
+	}
+	run.Status = domain.ScheduleRunCompleted
+	if sc.DetectDrift {
+		if err := s.detectDrift(ctx, sc.AccountID); err != nil {
+			run.Status, run.Error = domain.ScheduleRunFailed, err.Error()
+		}
+	}
+}
+
+// followQueued checks on agent jobs queued by earlier runs and finishes those
+// runs once the agent reports back or the wait times out.
+func (s *Scheduler) followQueued(ctx context.Context) {
+	all, err := s.schedules.ListDiscoverySchedules(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.WarnContext(ctx, "discovery scheduler: list schedules failed", "error", err)
+		}
+		return
+	}
+	for _, sc := range all {
+		if sc.LastStatus != domain.ScheduleRunQueued || sc.LastSyncJobID == nil || sc.LastRunAt == nil || s.isRunning(sc.AccountID) {
+			continue
+		}
+		job, err := s.jobs.GetSyncJob(ctx, *sc.LastSyncJobID)
+		if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			s.logger.WarnContext(ctx, "discovery scheduler: get sync job failed", "account_id", sc.AccountID, "error", err)
+			continue
+		}
+		run := domain.DiscoveryScheduleRun{At: *sc.LastRunAt, SyncJobID: sc.LastSyncJobID}
+		switch {
+		case job == nil:
+			run.Status, run.Error = domain.ScheduleRunFailed, "sync job no longer exists"
+		case job.Status == domain.SyncJobStatusFailed:
+			run.Status, run.Error = domain.ScheduleRunFailed, job.ErrorMessage
+		case job.Status == domain.SyncJobStatusCompleted:
+			if !sc.DetectDrift {
+				run.Status = domain.ScheduleRunCompleted
+				break
+			}
+			if !s.acquire() {
+				continue
+			}
+			s.start(sc.AccountID, func() {
+				run.Status = domain.ScheduleRunCompleted
+				if err := s.detectDrift(ctx, sc.AccountID); err != nil {
+					run.Status, run.Error = domain.ScheduleRunFailed, err.Error()
+				}
+				s.record(ctx, sc.AccountID, run)
+			})
+			continue
+		case s.now().Sub(*sc.LastRunAt) > s.cfg.QueuedTimeout:
+			run.Status = domain.ScheduleRunFailed
+			run.Error = fmt.Sprintf("agent did not finish the sync job within %s", s.cfg.QueuedTimeout)
+		default:
+			continue
+		}
+		s.record(ctx, sc.AccountID, run)
+	}
+}
+
+func (s *Scheduler) detectDrift(ctx context.Context, accountID int64) error {
+	if s.drift == nil {
+		return nil
+	}
+	if _, err := s.drift.Detect(ctx, domain.RunDriftDetectionRequest{AccountIDs: []int64{accountID}}); err != nil {
+		return fmt.Errorf("drift detection: %w", err)
+	}
+	return nil
+}
+
+func (s *Scheduler) record(ctx context.Context, accountID int64, run domain.DiscoveryScheduleRun) {
+	if err := s.schedules.RecordDiscoveryScheduleRun(ctx, accountID, run); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		s.logger.WarnContext(ctx, "discovery scheduler: record run failed", "account_id", accountID, "error", err)
+	}
+	if run.Status == domain.ScheduleRunFailed {
+		s.logger.WarnContext(ctx, "scheduled discovery run failed", "account_id", accountID, "error", run.Error)
+	}
+}
@@ -0,0 +1,248 @@
+package discovery
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+type fakeSyncRunner struct {
+	jobs    storage.DiscoveryStore
+	status  domain.SyncJobStatus
+	release chan struct{} // if set, each sync blocks until it is closed
+
+	mu    sync.Mutex
+	calls []int64
+}
+
+func (r *fakeSyncRunner) StartAccountSync(ctx context.Context, account domain.Account) (*domain.SyncJob, error) {
+	r.mu.Lock()
+	r.calls = append(r.calls, account.ID)
+	r.mu.Unlock()
+	if r.release != nil {
+		<-r.release
+	}
+	source := "local"
+	if r.status == domain.SyncJobStatusPending {
+		source = "agent"
+	}
+	job, err := r.jobs.CreateSyncJob(ctx, domain.SyncJob{
+		ID: uuid.New(), AccountID: account.ID, Status: r.status, Source: source, CreatedAt: time.Now().UTC(),
+	})
+	return &job, err
+}
+
+func (r *fakeSyncRunner) callCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.calls)
+}
+
+type schedulerFixture struct {
+	ms        *storage.MemoryStore
+	ds        storage.DiscoveryStore
+	drift     storage.DriftStore
+	schedules *storage.MemoryDiscoveryScheduleStore
+	runner    *fakeSyncRunner
+	sched     *Scheduler
+	now       time.Time
+}
+
+func newSchedulerFixture(t *testing.T, status domain.SyncJobStatus, cfg SchedulerConfig) *schedulerFixture {
+	t.Helper()
+	ms := storage.NewMemoryStore()
+	f := &schedulerFixture{
+		ms:        ms,
+		ds:        storage.NewMemoryDiscoveryStore(ms),
+		drift:     storage.NewMemoryDriftStore(ms),
+		schedules: storage.NewMemoryDiscoveryScheduleStore(),
+		now:       time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC),
+	}
+	f.runner = &fakeSyncRunner{jobs: f.ds, status: status}
+	f.sched = NewScheduler(f.schedules, ms, f.ds, f.runner, NewDriftDetector(ms, f.ds, f.drift), cfg)
+	f.sched.now = func() time.Time { return f.now }
+	f.sched.jitter = func() time.Duration { return 0 }
+	return f
+}
+
+// addAccount creates an account with one unmanaged VPC, so a drift run
+// always records one item, and schedules it hourly with its first run due.
+func (f *schedulerFixture) addAccount(t *testing.T, key string) domain.Account {
+	t.Helper()
+	ctx := context.Background()
+	acct, err := f.ms.CreateAccount(ctx, domain.CreateAccount{Key: key, Name: key, Provider: "aws"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.ds.UpsertDiscoveredResource(ctx, domain.DiscoveredResource{
+		ID: uuid.New(), AccountID: acct.ID, Provider: "aws", Region: "us-east-1",
+		ResourceType: domain.ResourceTypeVPC, ResourceID: "vpc-" + key, CIDR: "10.0.0.0/16",
+		Status: domain.DiscoveryStatusActive, DiscoveredAt: f.now, LastSeenAt: f.now,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	due := f.now.Add(-time.Minute)
+	if err := f.schedules.UpsertDiscoverySchedule(ctx, domain.DiscoverySchedule{
+		AccountID: acct.ID, Enabled: true, Interval: "1h", DetectDrift: true, NextRunAt: &due,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return acct
+}
+
+func (f *schedulerFixture) driftCount(t *testing.T, accountID int64) int {
+	t.Helper()
+	_, total, err := f.drift.ListDriftItems(context.Background(), domain.DriftFilters{AccountID: accountID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return total
+}
+
+func TestValidateSchedule(t *testing.T) {
+	cases := []struct {
+		sc domain.DiscoverySchedule
+		ok bool
+	}{
+		{domain.DiscoverySchedule{Interval: "6h"}, true},
+		{domain.DiscoverySchedule{Cron: "0 */4 * * *"}, true},
+		{domain.DiscoverySchedule{}, false},
+		{domain.DiscoverySchedule{Interval: "6h", Cron: "@daily"}, false},
+		{domain.DiscoverySchedule{Interval: "1m"}, false},
+		{domain.DiscoverySchedule{Interval: "soon"}, false},
+		{domain.DiscoverySchedule{Cron: "every day"}, false},
+	}
+	for _, tc := range cases {
+		if err := ValidateSchedule(tc.sc); (err == nil) != tc.ok {
+			t.Errorf("ValidateSchedule(%+v) = %v, want ok=%v", tc.sc, err, tc.ok)
+		}
+	}
+}
+
+func TestScheduler_LocalSyncRunsDrift(t *testing.T) {
+	ctx := context.Background()
+	f := newSchedulerFixture(t, domain.SyncJobStatusCompleted, SchedulerConfig{})
+	acct := f.addAccount(t, "aws:111")
+
+	f.sched.Tick(ctx)
+	f.sched.Wait()
+
+	if f.runner.callCount() != 1 {
+		t.Fatalf("sync calls = %d, want 1", f.runner.callCount())
+	}
+	if n := f.driftCount(t, acct.ID); n != 1 {
+		t.Fatalf("drift items = %d, want 1", n)
+	}
+	sc, err := f.schedules.GetDiscoverySchedule(ctx, acct.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc.LastStatus != domain.ScheduleRunCompleted || sc.LastSyncJobID == nil || sc.LastRunAt == nil || !sc.LastRunAt.Equal(f.now) {
+		t.Fatalf("last run = %+v", sc)
+	}
+	if want := f.now.Add(time.Hour); sc.NextRunAt == nil || !sc.NextRunAt.Equal(want) {
+		t.Fatalf("next run = %v, want %s", sc.NextRunAt, want)
+	}
+
+	// Not due again until the next hour.
+	f.sched.Tick(ctx)
+	f.sched.Wait()
+	if f.runner.callCount() != 1 {
+		t.Fatalf("sync calls after second tick = %d, want 1", f.runner.callCount())
+	}
+}
+
+func TestScheduler_AgentJobDriftWaitsForCompletion(t *testing.T) {
+	ctx := context.Background()
+	f := newSchedulerFixture(t, domain.SyncJobStatusPending, SchedulerConfig{})
+	acct := f.addAccount(t, "aws:111")
+
+	f.sched.Tick(ctx)
+	f.sched.Wait()
+
+	sc, _ := f.schedules.GetDiscoverySchedule(ctx, acct.ID)
+	if sc.LastStatus != domain.ScheduleRunQueued || sc.LastSyncJobID == nil {
+		t.Fatalf("last run = %+v, want queued", sc)
+	}
+	if n := f.driftCount(t, acct.ID); n != 0 {
+		t.Fatalf("drift ran before the agent finished: %d items", n)
+	}
+
+	// Still pending: nothing changes.
+	f.now = f.now.Add(time.Minute)
+	f.sched.Tick(ctx)
+	f.sched.Wait()
+	if sc, _ := f.schedules.GetDiscoverySchedule(ctx, acct.ID); sc.LastStatus != domain.ScheduleRunQueued {
+		t.Fatalf("last status = %s, want queued", sc.LastStatus)
+	}
+
+	job, err := f.ds.GetSyncJob(ctx, *sc.LastSyncJobID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	job.Status = domain.SyncJobStatusCompleted
+	if err := f.ds.UpdateSyncJob(ctx, *job); err != nil {
+		t.Fatal(err)
+	}
+	f.sched.Tick(ctx)
+	f.sched.Wait()
+
+	sc, _ = f.schedules.GetDiscoverySchedule(ctx, acct.ID)
+	if sc.LastStatus != domain.ScheduleRunCompleted {
+		t.Fatalf("last status = %s, want completed", sc.LastStatus)
+	}
+	if n := f.driftCount(t, acct.ID); n != 1 {
+		t.Fatalf("drift items = %d, want 1", n)
+	}
+}
+
+func TestScheduler_QueuedJobTimesOut(t *testing.T) {
+	ctx := context.Background()
+	f := newSchedulerFixture(t, domain.SyncJobStatusPending, SchedulerConfig{QueuedTimeout: 10 * time.Minute})
+	acct := f.addAccount(t, "aws:111")
+
+	f.sched.Tick(ctx)
+	f.sched.Wait()
+	f.now = f.now.Add(11 * time.Minute)
+	f.sched.Tick(ctx)
+	f.sched.Wait()
+
+	sc, _ := f.schedules.GetDiscoverySchedule(ctx, acct.ID)
+	if sc.LastStatus != domain.ScheduleRunFailed || sc.LastError == "" {
+		t.Fatalf("last run = %+v, want failed with error", sc)
+	}
+}
+
+func TestScheduler_ConcurrencyLimit(t *testing.T) {
+	ctx := context.Background()
+	f := newSchedulerFixture(t, domain.SyncJobStatusCompleted, SchedulerConfig{Concurrency: 1})
+	f.runner.release = make(chan struct{})
+	for _, key := range []string{"aws:1", "aws:2", "aws:3"} {
+		f.addAccount(t, key)
+	}
+
+	f.sched.Tick(ctx)
+	due, err := f.schedules.ListDueDiscoverySchedules(ctx, f.now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(due) != 2 {
+		t.Fatalf("due after first tick = %d, want 2 left waiting for a slot", len(due))
+	}
+	close(f.runner.release)
+	f.sched.Wait()
+
+	f.sched.Tick(ctx)
+	f.sched.Wait()
+	f.sched.Tick(ctx)
+	f.sched.Wait()
+	if f.runner.callCount() != 3 {
+		t.Fatalf("sync calls = %d, want 3", f.runner.callCount())
+	}
+}
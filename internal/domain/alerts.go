@@ -0,0 +1,253 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// AlertKind is the condition an alert reports.
+type AlertKind string
+
+const (
+	// AlertKindUtilization fires when a pool's utilization crosses a rule's
+	// warning or critical percentage.
+	AlertKindUtilization AlertKind = "utilization"
+	// AlertKindExhaustion fires when a pool is forecast to run out of
+	// addresses within a rule's number of days.
+	AlertKindExhaustion AlertKind = "exhaustion"
+)
+
+// AlertSeverity indicates urgency.
+type AlertSeverity string
+
+const (
+	AlertSeverityWarning  AlertSeverity = "warning"
+	AlertSeverityCritical AlertSeverity = "critical"
+)
+
+// AlertStatus tracks the lifecycle of an alert. Open and acknowledged
+// alerts are active; an active alert is resolved automatically once its
+// condition clears.
+type AlertStatus string
+
+const (
+	AlertStatusOpen         AlertStatus = "open"
+	AlertStatusAcknowledged AlertStatus = "acknowledged"
+	AlertStatusResolved     AlertStatus = "resolved"
+)
+
+// AlertStatusActive is a list filter matching open and acknowledged alerts.
+const AlertStatusActive = "active"
+
+// IsValidAlertStatusFilter reports whether s can be used as a status filter.
+func IsValidAlertStatusFilter(s string) bool {
+	switch AlertStatus(s) {
+	case AlertStatusOpen, AlertStatusAcknowledged, AlertStatusResolved, AlertStatusActive:
+		return true
+	}
+	return false
+}
+
+// Alert is a capacity threshold breach for one pool. At most one active
+// alert exists per rule, pool and kind.
+type Alert struct {
+	ID       string        `json:"id"`
+	RuleID   string        `json:"rule_id"`
+	PoolID   int64         `json:"pool_id"`
+	PoolName string        `json:"pool_name"`
+	PoolCIDR string        `json:"pool_cidr"`
+	Kind     AlertKind     `json:"kind"`
+	Severity AlertSeverity `json:"severity"`
+	Status   AlertStatus   `json:"status"`
+	// Value is the utilization percentage for utilization alerts and the
+	// forecast days to exhaustion for exhaustion alerts; Threshold is the
+	// rule setting it was compared against.
+	Value          float64    `json:"value"`
+	Threshold      float64    `json:"threshold"`
+	Message        string     `json:"message"`
+	OpenedAt       time.Time  `json:"opened_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	AcknowledgedBy string     `json:"acknowledged_by,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+	ResolvedBy     string     `json:"resolved_by,omitempty"` // "system" when the condition cleared
+	NotifiedAt     *time.Time `json:"notified_at,omitempty"`
+}
+
+// Active reports whether the alert is open or acknowledged.
+func (a Alert) Active() bool {
+	return a.Status == AlertStatusOpen || a.Status == AlertStatusAcknowledged
+}
+
+// AlertFilters for listing alerts. Status accepts AlertStatusActive.
+type AlertFilters struct {
+	PoolID   int64
+	Status   string
+	Severity string
+	Kind     string
+	Page     int
+	PageSize int
+}
+
+// AlertListResponse is the paginated list response.
+type AlertListResponse struct {
+	Items    []Alert `json:"items"`
+	Total    int     `json:"total"`
+	Page     int     `json:"page"`
+	PageSize int     `json:"page_size"`
+}
+
+// AlertEvaluationResponse summarizes one evaluation of the alert rules.
+// Errors lists pools or alerts that could not be processed; their alerts
+// are left as they were.
+type AlertEvaluationResponse struct {
+	EvaluatedPools int      `json:"evaluated_pools"`
+	Opened         int      `json:"opened"`
+	Escalated      int      `json:"escalated"`
+	Resolved       int      `json:"resolved"`
+	Active         int      `json:"active"`
+	Errors         []string `json:"errors,omitempty"`
+}
+
+// AlertRule sets capacity thresholds for pools. A rule with PoolID applies
+// to that pool; otherwise a rule with PoolType applies to pools of that
+// type; otherwise it applies to every pool. Each pool is evaluated against
+// its most specific enabled rule. Zero thresholds are not checked.
+// Exhaustion alerts are warnings until the forecast falls within the last
+// half of ExhaustionDays, when they become critical.
+type AlertRule struct {
+	ID              string   `json:"id"`
+	Name            string   `json:"name"`
+	Enabled         bool     `json:"enabled"`
+	PoolID          *int64   `json:"pool_id,omitempty"`
+	PoolType        PoolType `json:"pool_type,omitempty"`
+	WarningPercent  float64  `json:"warning_percent,omitempty"`
+	CriticalPercent float64  `json:"critical_percent,omitempty"`
+	ExhaustionDays  int      `json:"exhaustion_days,omitempty"`
+	// Channels lists the notification channel IDs alerts from this rule are
+	// sent to. Empty means every enabled channel.
+	Channels []string `json:"channels,omitempty"`
+}
+
+// NotificationChannelType selects how a channel delivers notifications.
+type NotificationChannelType string
+
+const (
+	// NotificationChannelSMTP emails To through an SMTP relay at SMTPAddr.
+	NotificationChannelSMTP NotificationChannelType = "smtp"
+	// NotificationChannelWebhook POSTs the notification as JSON to URL.
+	NotificationChannelWebhook NotificationChannelType = "webhook"
+	// NotificationChannelSlack POSTs a Slack-compatible message to URL.
+	NotificationChannelSlack NotificationChannelType = "slack"
+)
+
+// NotificationChannel is a destination for alert notifications.
+type NotificationChannel struct {
+	ID       string                  `json:"id"`
+	Name     string                  `json:"name"`
+	Type     NotificationChannelType `json:"type"`
+	Enabled  bool                    `json:"enabled"`
+	URL      string                  `json:"url,omitempty"`
+	SMTPAddr string                  `json:"smtp_addr,omitempty"` // host:port of the relay
+	From     string                  `json:"from,omitempty"`
+	To       []string                `json:"to,omitempty"`
+}
+
+// AlertSettings holds the alert rules and notification channels. It is
+// stored in the settings store.
+type AlertSettings struct {
+	Rules    []AlertRule           `json:"rules"`
+	Channels []NotificationChannel `json:"channels"`
+}
+
+// DefaultAlertSettings returns a single rule warning at 80% and going
+// critical at 90% utilization for every pool, with no channels.
+func DefaultAlertSettings() AlertSettings {
+	return AlertSettings{
+		Rules: []AlertRule{{
+			ID:              "default",
+			Name:            "Pool utilization",
+			Enabled:         true,
+			WarningPercent:  80,
+			CriticalPercent: 90,
+		}},
+		Channels: []NotificationChannel{},
+	}
+}
+
+// NormalizeAlertSettings replaces nil lists with empty ones.
+func NormalizeAlertSettings(settings *AlertSettings) *AlertSettings {
+	if settings == nil {
+		defaults := DefaultAlertSettings()
+		return &defaults
+	}
+	if settings.Rules == nil {
+		settings.Rules = []AlertRule{}
+	}
+	if settings.Channels == nil {
+		settings.Channels = []NotificationChannel{}
+	}
+	return settings
+}
+
+// MaxAlertExhaustionDays bounds AlertRule.ExhaustionDays.
+const MaxAlertExhaustionDays = 3650
+
+// ValidateAlertSettings checks rule thresholds, ID uniqueness and channel
+// references. It returns "" when the settings are valid. Channel delivery
+// settings are checked by the notifier for each channel type.
+func ValidateAlertSettings(settings *AlertSettings) string {
+	if settings == nil {
+		return "settings are required"
+	}
+	channels := make(map[string]bool, len(settings.Channels))
+	for i, ch := range settings.Channels {
+		if ch.ID == "" {
+			return fmt.Sprintf("channels[%d]: id is required", i)
+		}
+		if channels[ch.ID] {
+			return fmt.Sprintf("channels[%d]: duplicate id %q", i, ch.ID)
+		}
+		channels[ch.ID] = true
+		if ch.Type == "" {
+			return fmt.Sprintf("channel %q: type is required", ch.ID)
+		}
+	}
+	rules := make(map[string]bool, len(settings.Rules))
+	for i, rule := range settings.Rules {
+		if rule.ID == "" {
+			return fmt.Sprintf("rules[%d]: id is required", i)
+		}
+		if rules[rule.ID] {
+			return fmt.Sprintf("rules[%d]: duplicate id %q", i, rule.ID)
+		}
+		rules[rule.ID] = true
+		if rule.PoolID != nil && rule.PoolType != "" {
+			return fmt.Sprintf("rule %q: set pool_id or pool_type, not both", rule.ID)
+		}
+		if rule.PoolID != nil && *rule.PoolID < 1 {
+			return fmt.Sprintf("rule %q: invalid pool_id", rule.ID)
+		}
+		if rule.PoolType != "" && !IsValidPoolType(rule.PoolType) {
+			return fmt.Sprintf("rule %q: invalid pool_type %q", rule.ID, rule.PoolType)
+		}
+		if rule.WarningPercent < 0 || rule.WarningPercent > 100 || rule.CriticalPercent < 0 || rule.CriticalPercent > 100 {
+			return fmt.Sprintf("rule %q: thresholds must be between 0 and 100", rule.ID)
+		}
+		if rule.WarningPercent > 0 && rule.CriticalPercent > 0 && rule.WarningPercent >= rule.CriticalPercent {
+			return fmt.Sprintf("rule %q: warning_percent must be below critical_percent", rule.ID)
+		}
+		if rule.ExhaustionDays < 0 || rule.ExhaustionDays > MaxAlertExhaustionDays {
+			return fmt.Sprintf("rule %q: exhaustion_days must be between 0 and %d", rule.ID, MaxAlertExhaustionDays)
+		}
+		if rule.WarningPercent == 0 && rule.CriticalPercent == 0 && rule.ExhaustionDays == 0 {
+			return fmt.Sprintf("rule %q: set at least one threshold", rule.ID)
+		}
+		for _, id := range rule.Channels {
+			if !channels[id] {
+				return fmt.Sprintf("rule %q: unknown channel %q", rule.ID, id)
+			}
+		}
+	}
+	return ""
+}
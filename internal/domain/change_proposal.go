@@ -0,0 +1,131 @@
+package domain
+
+import "time"
+
+// PoolTree is a set of pools created together by the schema planner or from
+// an AI plan. Pools are in topological order: a parent_ref names an earlier
+// entry. The tree is applied all-or-nothing.
+type PoolTree struct {
+	Pools       []PoolSpec        `json:"pools"`
+	Status      PoolStatus        `json:"status"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Description string            `json:"description"`
+	// CheckConflicts refuses the apply when a pool overlaps an existing one.
+	CheckConflicts bool `json:"check_conflicts"`
+}
+
+// ChangeSet is what applying a PoolTree would do, computed without writing
+// anything.
+type ChangeSet struct {
+	Create     []PlannedPool      `json:"create"`
+	Skip       []SkippedPool      `json:"skip"`
+	Violations []PlannedViolation `json:"violations"`
+	// Warnings describe problems that do not stop the apply, such as an
+	// unknown pool type falling back to subnet.
+	Warnings []string `json:"warnings"`
+	// Applicable is false when any pool would be skipped. Apply is
+	// all-or-nothing, so a skipped pool blocks the whole tree.
+	Applicable bool `json:"applicable"`
+}
+
+// PlannedPool is a pool a change set would create, with its parent resolved.
+type PlannedPool struct {
+	Ref        string     `json:"ref"`
+	Name       string     `json:"name"`
+	CIDR       string     `json:"cidr"`
+	Type       PoolType   `json:"type"`
+	Status     PoolStatus `json:"status"`
+	ParentRef  string     `json:"parent_ref,omitempty"`
+	ParentName string     `json:"parent_name,omitempty"`
+	ParentCIDR string     `json:"parent_cidr,omitempty"`
+	Depth      int        `json:"depth"` // top-level pools are at depth 1
+}
+
+// Reasons a pool in a change set is skipped.
+const (
+	SkipReasonConflict      = "conflict"       // overlaps an existing pool
+	SkipReasonParentSkipped = "parent_skipped" // its parent is skipped
+)
+
+// SkippedPool is a pool a change set would not create, and why.
+type SkippedPool struct {
+	Ref    string `json:"ref"`
+	Name   string `json:"name"`
+	CIDR   string `json:"cidr"`
+	Reason string `json:"reason"`
+	Detail string `json:"detail"`
+	// ExistingPoolID is the pool it overlaps, for conflicts.
+	ExistingPoolID int64 `json:"existing_pool_id,omitempty"`
+}
+
+// PlannedViolation is a compliance-rule violation a planned pool would
+// introduce.
+type PlannedViolation struct {
+	Ref         string `json:"ref"`
+	PoolName    string `json:"pool_name"`
+	CIDR        string `json:"cidr"`
+	RuleID      string `json:"rule_id"`
+	Severity    string `json:"severity"`
+	Message     string `json:"message"`
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// ChangeProposalSource says which planner produced a proposal.
+type ChangeProposalSource string
+
+const (
+	ChangeProposalSourceSchema ChangeProposalSource = "schema"
+	ChangeProposalSourceAIPlan ChangeProposalSource = "ai_plan"
+)
+
+// ChangeProposalStatus tracks a proposal through review.
+type ChangeProposalStatus string
+
+const (
+	ChangeProposalPending  ChangeProposalStatus = "pending"
+	ChangeProposalApplied  ChangeProposalStatus = "applied"
+	ChangeProposalRejected ChangeProposalStatus = "rejected"
+)
+
+// IsValidChangeProposalStatus reports whether s is a known status.
+func IsValidChangeProposalStatus(s ChangeProposalStatus) bool {
+	switch s {
+	case ChangeProposalPending, ChangeProposalApplied, ChangeProposalRejected:
+		return true
+	}
+	return false
+}
+
+// ChangeProposal is a stored pool tree awaiting review. It is applied only
+// when approved by someone other than its author who holds a different role
+// granting at least the author's permissions.
+type ChangeProposal struct {
+	ID        string               `json:"id"`
+	Title     string               `json:"title"`
+	Source    ChangeProposalSource `json:"source"`
+	SessionID string               `json:"session_id,omitempty"` // AI planning session, for ai_plan
+	Status    ChangeProposalStatus `json:"status"`
+	Tree      PoolTree             `json:"tree"`
+	// ChangeSet is the dry run taken when the proposal was made. Approval
+	// re-checks the tree against the pools that exist by then.
+	ChangeSet ChangeSet `json:"change_set"`
+
+	// ProposedByID and ReviewedByID name the principal behind the session
+	// or API key, "user:<id>" or "apikey:<id>". The author check compares
+	// these rather than the display names.
+	ProposedBy    string           `json:"proposed_by"`
+	ProposedByID  string           `json:"proposed_by_id"`
+	ProposedRole  string           `json:"proposed_role"`
+	ReviewedBy    string           `json:"reviewed_by,omitempty"`
+	ReviewedByID  string           `json:"reviewed_by_id,omitempty"`
+	ReviewedRole  string           `json:"reviewed_role,omitempty"`
+	ReviewComment string           `json:"review_comment,omitempty"`
+	PoolMap       map[string]int64 `json:"pool_map,omitempty"` // ref to created pool ID, once applied
+	CreatedAt     time.Time        `json:"created_at"`
+	ReviewedAt    *time.Time       `json:"reviewed_at,omitempty"`
+}
+
+// ChangeProposalListResponse is the body of GET /api/v1/change-proposals.
+type ChangeProposalListResponse struct {
+	Items []ChangeProposal `json:"items"`
+}
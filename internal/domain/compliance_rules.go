@@ -0,0 +1,181 @@
+package domain
+
+import (
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ComplianceCheckType selects what a compliance rule checks.
+type ComplianceCheckType string
+
+const (
+	// ComplianceCheckPrefixLength bounds a pool's size. MaxPrefixLength is
+	// the smallest allowed block (a /24 minimum is max_prefix_length 24);
+	// MinPrefixLength is the largest.
+	ComplianceCheckPrefixLength ComplianceCheckType = "prefix_length"
+	// ComplianceCheckRequiredTags requires every tag in Tags to be set to a
+	// non-empty value.
+	ComplianceCheckRequiredTags ComplianceCheckType = "required_tags"
+	// ComplianceCheckForbiddenRanges rejects pools overlapping any of Ranges.
+	ComplianceCheckForbiddenRanges ComplianceCheckType = "forbidden_ranges"
+	// ComplianceCheckAllowedRanges requires pools to fall inside one of Ranges.
+	ComplianceCheckAllowedRanges ComplianceCheckType = "allowed_ranges"
+	// ComplianceCheckMaxDepth limits how deeply pools nest. Top-level pools
+	// are at depth 1.
+	ComplianceCheckMaxDepth ComplianceCheckType = "max_depth"
+	// ComplianceCheckNamePattern requires pool names to match Pattern.
+	ComplianceCheckNamePattern ComplianceCheckType = "name_pattern"
+)
+
+// ValidComplianceCheckTypes lists the supported check types.
+var ValidComplianceCheckTypes = []ComplianceCheckType{
+	ComplianceCheckPrefixLength,
+	ComplianceCheckRequiredTags,
+	ComplianceCheckForbiddenRanges,
+	ComplianceCheckAllowedRanges,
+	ComplianceCheckMaxDepth,
+	ComplianceCheckNamePattern,
+}
+
+// BuiltinComplianceRuleIDs are the IDs of the checks that always run. Custom
+// rules cannot reuse them.
+var BuiltinComplianceRuleIDs = []string{"OVERLAP-001", "RFC1918-001", "EMPTY-001", "NAME-001", "NAME-002"}
+
+// ComplianceRule is a user-defined compliance check. Match narrows the pools
+// it applies to; Check says what those pools must satisfy.
+type ComplianceRule struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Enabled     bool   `json:"enabled"`
+	Severity    string `json:"severity"` // error, warning, info
+	// Enforce rejects pool creation that would violate the rule.
+	Enforce     bool            `json:"enforce"`
+	Match       ComplianceMatch `json:"match"`
+	Check       ComplianceCheck `json:"check"`
+	Message     string          `json:"message,omitempty"` // replaces the generated violation message
+	Remediation string          `json:"remediation,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// ComplianceMatch selects the pools a rule applies to. Empty fields match
+// every pool.
+type ComplianceMatch struct {
+	PoolTypes []PoolType `json:"pool_types,omitempty"`
+	// Tags must all be present with the given values; "*" matches any
+	// non-empty value.
+	Tags map[string]string `json:"tags,omitempty"`
+	// IPVersion is 4 or 6.
+	IPVersion int `json:"ip_version,omitempty"`
+}
+
+// ComplianceCheck holds the parameters for the rule's check Type.
+type ComplianceCheck struct {
+	Type            ComplianceCheckType `json:"type"`
+	MinPrefixLength int                 `json:"min_prefix_length,omitempty"`
+	MaxPrefixLength int                 `json:"max_prefix_length,omitempty"`
+	Tags            []string            `json:"tags,omitempty"`
+	Ranges          []string            `json:"ranges,omitempty"`
+	MaxDepth        int                 `json:"max_depth,omitempty"`
+	Pattern         string              `json:"pattern,omitempty"`
+}
+
+// ComplianceRuleListResponse is the body of GET /api/v1/analysis/rules.
+type ComplianceRuleListResponse struct {
+	Items []ComplianceRule `json:"items"`
+}
+
+var complianceRuleIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,63}$`)
+
+// ValidateComplianceRule checks a rule's ID, severity, match and check
+// parameters. It returns "" when the rule is valid.
+func ValidateComplianceRule(rule *ComplianceRule) string {
+	if rule == nil {
+		return "rule is required"
+	}
+	if !complianceRuleIDPattern.MatchString(rule.ID) {
+		return "id must be 1-64 letters, digits, '.', '_' or '-'"
+	}
+	for _, id := range BuiltinComplianceRuleIDs {
+		if strings.EqualFold(rule.ID, id) {
+			return fmt.Sprintf("id %q is reserved for a built-in check", rule.ID)
+		}
+	}
+	if strings.TrimSpace(rule.Name) == "" {
+		return "name is required"
+	}
+	switch rule.Severity {
+	case "error", "warning", "info":
+	default:
+		return "severity must be error, warning or info"
+	}
+	for _, t := range rule.Match.PoolTypes {
+		if !IsValidPoolType(t) {
+			return fmt.Sprintf("match: invalid pool type %q", t)
+		}
+	}
+	for k := range rule.Match.Tags {
+		if strings.TrimSpace(k) == "" {
+			return "match: tag keys must not be empty"
+		}
+	}
+	if v := rule.Match.IPVersion; v != 0 && v != 4 && v != 6 {
+		return "match: ip_version must be 4 or 6"
+	}
+	return validateComplianceCheck(rule.Check, rule.Match.IPVersion)
+}
+
+func validateComplianceCheck(c ComplianceCheck, ipVersion int) string {
+	maxBits := 128
+	if ipVersion == 4 {
+		maxBits = 32
+	}
+	switch c.Type {
+	case ComplianceCheckPrefixLength:
+		if c.MinPrefixLength == 0 && c.MaxPrefixLength == 0 {
+			return "check: set min_prefix_length, max_prefix_length or both"
+		}
+		if c.MinPrefixLength < 0 || c.MinPrefixLength > maxBits || c.MaxPrefixLength < 0 || c.MaxPrefixLength > maxBits {
+			return fmt.Sprintf("check: prefix lengths must be between 0 and %d", maxBits)
+		}
+		if c.MaxPrefixLength > 0 && c.MinPrefixLength > c.MaxPrefixLength {
+			return "check: min_prefix_length must not exceed max_prefix_length"
+		}
+	case ComplianceCheckRequiredTags:
+		if len(c.Tags) == 0 {
+			return "check: tags is required"
+		}
+		for _, t := range c.Tags {
+			if strings.TrimSpace(t) == "" {
+				return "check: tag names must not be empty"
+			}
+		}
+	case ComplianceCheckForbiddenRanges, ComplianceCheckAllowedRanges:
+		if len(c.Ranges) == 0 {
+			return "check: ranges is required"
+		}
+		for _, r := range c.Ranges {
+			if _, err := netip.ParsePrefix(strings.TrimSpace(r)); err != nil {
+				return fmt.Sprintf("check: invalid range %q", r)
+			}
+		}
+	case ComplianceCheckMaxDepth:
+		if c.MaxDepth < 1 {
+			return "check: max_depth must be at least 1"
+		}
+	case ComplianceCheckNamePattern:
+		if c.Pattern == "" {
+			return "check: pattern is required"
+		}
+		if _, err := regexp.Compile(c.Pattern); err != nil {
+			return fmt.Sprintf("check: invalid pattern: %v", err)
+		}
+	default:
+		return fmt.Sprintf("check: type must be one of %v", ValidComplianceCheckTypes)
+	}
+	return ""
+}
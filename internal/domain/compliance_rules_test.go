@@ -0,0 +1,46 @@
+package domain
+
+import "testing"
+
+func TestValidateComplianceRule(t *testing.T) {
+	valid := func() ComplianceRule {
+		return ComplianceRule{
+			ID: "SIZE-001", Name: "Size", Severity: "warning",
+			Check: ComplianceCheck{Type: ComplianceCheckPrefixLength, MinPrefixLength: 16, MaxPrefixLength: 28},
+		}
+	}
+	r := valid()
+	if msg := ValidateComplianceRule(&r); msg != "" {
+		t.Fatalf("valid rule rejected: %s", msg)
+	}
+	tests := []struct {
+		name   string
+		mutate func(*ComplianceRule)
+	}{
+		{"bad id", func(r *ComplianceRule) { r.ID = "has space" }},
+		{"builtin id", func(r *ComplianceRule) { r.ID = "overlap-001" }},
+		{"no name", func(r *ComplianceRule) { r.Name = " " }},
+		{"bad severity", func(r *ComplianceRule) { r.Severity = "fatal" }},
+		{"bad pool type", func(r *ComplianceRule) { r.Match.PoolTypes = []PoolType{"zone"} }},
+		{"bad ip version", func(r *ComplianceRule) { r.Match.IPVersion = 5 }},
+		{"inverted prefixes", func(r *ComplianceRule) { r.Check.MinPrefixLength = 29 }},
+		{"ipv4 prefix too long", func(r *ComplianceRule) { r.Match.IPVersion = 4; r.Check.MaxPrefixLength = 33 }},
+		{"no prefixes", func(r *ComplianceRule) { r.Check = ComplianceCheck{Type: ComplianceCheckPrefixLength} }},
+		{"no tags", func(r *ComplianceRule) { r.Check = ComplianceCheck{Type: ComplianceCheckRequiredTags} }},
+		{"bad range", func(r *ComplianceRule) {
+			r.Check = ComplianceCheck{Type: ComplianceCheckForbiddenRanges, Ranges: []string{"172.17/16"}}
+		}},
+		{"zero depth", func(r *ComplianceRule) { r.Check = ComplianceCheck{Type: ComplianceCheckMaxDepth} }},
+		{"bad pattern", func(r *ComplianceRule) {
+			r.Check = ComplianceCheck{Type: ComplianceCheckNamePattern, Pattern: "(prod"}
+		}},
+		{"unknown type", func(r *ComplianceRule) { r.Check.Type = "cel" }},
+	}
+	for _, tc := range tests {
+		r := valid()
+		tc.mutate(&r)
+		if msg := ValidateComplianceRule(&r); msg == "" {
+			t.Errorf("%s: expected a validation error", tc.name)
+		}
+	}
+}
@@ -53,4 +53,9 @@ type PoolSpec struct {
 type ApplyPlanRequest struct {
 	Plan          GeneratedPlan `json:"plan"`
 	SkipConflicts bool          `json:"skip_conflicts"`
+	// DryRun returns the change set instead of creating pools. Propose
+	// stores it as a change proposal for a second user to approve.
+	DryRun  bool   `json:"dry_run"`
+	Propose bool   `json:"propose"`
+	Title   string `json:"title,omitempty"` // proposal title; defaults to the plan name
 }
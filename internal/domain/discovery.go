@@ -323,7 +323,9 @@ type AgentRegisterResponse struct {
 	Message        string              `json:"message,omitempty"`
 }
 
-// OrgAccountIngest represents a single AWS account's discovered resources for bulk org ingest.
+// OrgAccountIngest represents a single cloud account's discovered resources for bulk org ingest.
+// AWSAccountID carries the provider's account identifier; for Azure it is the
+// subscription ID. The field keeps its name for wire compatibility.
 type OrgAccountIngest struct {
 	AWSAccountID string               `json:"aws_account_id"`
 	AccountName  string               `json:"account_name"`
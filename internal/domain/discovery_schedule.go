@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ScheduleRunStatus is the outcome of a scheduled discovery run.
+type ScheduleRunStatus string
+
+const (
+	// ScheduleRunQueued means a sync job was queued for a discovery agent and
+	// drift detection waits until the agent reports back.
+	ScheduleRunQueued    ScheduleRunStatus = "queued"
+	ScheduleRunCompleted ScheduleRunStatus = "completed"
+	ScheduleRunFailed    ScheduleRunStatus = "failed"
+)
+
+// DiscoverySchedule configures automatic discovery for one account. Exactly
+// one of Interval (a Go duration such as "6h") or Cron (a five-field UTC cron
+// expression) is set.
+type DiscoverySchedule struct {
+	AccountID   int64  `json:"account_id"`
+	Enabled     bool   `json:"enabled"`
+	Interval    string `json:"interval,omitempty"`
+	Cron        string `json:"cron,omitempty"`
+	DetectDrift bool   `json:"detect_drift"`
+
+	NextRunAt     *time.Time        `json:"next_run_at,omitempty"`
+	LastRunAt     *time.Time        `json:"last_run_at,omitempty"`
+	LastStatus    ScheduleRunStatus `json:"last_status,omitempty"`
+	LastError     string            `json:"last_error,omitempty"`
+	LastSyncJobID *uuid.UUID        `json:"last_sync_job_id,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DiscoveryScheduleRun records the outcome of one scheduled run.
+type DiscoveryScheduleRun struct {
+	At        time.Time
+	Status    ScheduleRunStatus
+	Error     string
+	SyncJobID *uuid.UUID
+}
+
+// UpsertDiscoveryScheduleRequest is the body of PUT
+// /api/v1/discovery/schedules/{accountId}. Omitted booleans default to true.
+type UpsertDiscoveryScheduleRequest struct {
+	Enabled     *bool  `json:"enabled,omitempty"`
+	Interval    string `json:"interval,omitempty"`
+	Cron        string `json:"cron,omitempty"`
+	DetectDrift *bool  `json:"detect_drift,omitempty"`
+}
+
+// DiscoveryScheduleView is a schedule together with its account.
+type DiscoveryScheduleView struct {
+	DiscoverySchedule
+	AccountKey  string `json:"account_key"`
+	AccountName string `json:"account_name"`
+	Provider    string `json:"provider,omitempty"`
+}
+
+// DiscoveryScheduleListResponse is the response of GET /api/v1/discovery/schedules.
+type DiscoveryScheduleListResponse struct {
+	Items []DiscoveryScheduleView `json:"items"`
+}
@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IPAddressStatus describes how an individual address in a subnet is used.
+type IPAddressStatus string
+
+const (
+	IPAddressStatusReserved IPAddressStatus = "reserved"
+	IPAddressStatusAssigned IPAddressStatus = "assigned"
+	IPAddressStatusDHCP     IPAddressStatus = "dhcp"
+)
+
+// ValidIPAddressStatuses contains all valid IP address statuses.
+var ValidIPAddressStatuses = []IPAddressStatus{
+	IPAddressStatusReserved,
+	IPAddressStatusAssigned,
+	IPAddressStatusDHCP,
+}
+
+// IsValidIPAddressStatus checks if an IP address status is valid.
+func IsValidIPAddressStatus(s IPAddressStatus) bool {
+	for _, valid := range ValidIPAddressStatuses {
+		if s == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// Metadata keys set by collectors on network_interface resources. The
+// resource's CIDR is its primary address as a host prefix; the IP address
+// reconciler reads the full address list and host details from these keys.
+const (
+	NICMetaAddresses  = "private_ips" // comma-separated, primary first, IPv6 included
+	NICMetaMACAddress = "mac_address"
+	NICMetaHostname   = "hostname"
+)
+
+// IPAddress tracks a single host address inside a subnet pool. Records count
+// towards the pool's used addresses. Source is "manual" for records created
+// through the API and "discovered" for records created from a cloud network
+// interface; ResourceID links either kind to the discovered interface that
+// holds the address.
+type IPAddress struct {
+	ID          string          `json:"id"`
+	PoolID      int64           `json:"pool_id"`
+	Address     string          `json:"address"`
+	Hostname    string          `json:"hostname,omitempty"`
+	MACAddress  string          `json:"mac_address,omitempty"`
+	Owner       string          `json:"owner,omitempty"`
+	Status      IPAddressStatus `json:"status"`
+	Source      PoolSource      `json:"source"`
+	Description string          `json:"description,omitempty"`
+	ResourceID  *uuid.UUID      `json:"resource_id,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// CreateIPAddress is the input for recording an address in a subnet pool.
+type CreateIPAddress struct {
+	Address     string          `json:"address"`
+	Hostname    string          `json:"hostname,omitempty"`
+	MACAddress  string          `json:"mac_address,omitempty"`
+	Owner       string          `json:"owner,omitempty"`
+	Status      IPAddressStatus `json:"status,omitempty"`
+	Description string          `json:"description,omitempty"`
+}
+
+// AllocateIPAddress is the input for taking the next free address in a
+// subnet pool. The server picks the address.
+type AllocateIPAddress struct {
+	Hostname    string          `json:"hostname,omitempty"`
+	MACAddress  string          `json:"mac_address,omitempty"`
+	Owner       string          `json:"owner,omitempty"`
+	Status      IPAddressStatus `json:"status,omitempty"`
+	Description string          `json:"description,omitempty"`
+}
+
+// UpdateIPAddress is the input for updating an address record. Nil fields
+// are left unchanged; the address itself cannot be changed.
+type UpdateIPAddress struct {
+	Hostname    *string          `json:"hostname,omitempty"`
+	MACAddress  *string          `json:"mac_address,omitempty"`
+	Owner       *string          `json:"owner,omitempty"`
+	Status      *IPAddressStatus `json:"status,omitempty"`
+	Description *string          `json:"description,omitempty"`
+}
+
+// IPAddressFilters for listing address records. A zero PoolID lists records
+// across all pools.
+type IPAddressFilters struct {
+	PoolID   int64
+	Status   string
+	Source   string
+	Query    string // substring of address, hostname, MAC or owner
+	Page     int
+	PageSize int
+}
+
+// IPAddressListResponse is the paginated list of address records.
+type IPAddressListResponse struct {
+	Items    []IPAddress `json:"items"`
+	Total    int         `json:"total"`
+	Page     int         `json:"page"`
+	PageSize int         `json:"page_size"`
+}
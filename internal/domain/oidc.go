@@ -12,9 +12,21 @@ type OIDCProvider struct {
 	ClientSecretMasked    string            `json:"client_secret,omitempty"`
 	Scopes                string            `json:"scopes"`
 	RoleMapping           map[string]string `json:"role_mapping"`
+	RoleRules             []OIDCRoleRule    `json:"role_rules"`
 	DefaultRole           string            `json:"default_role"`
 	AutoProvision         bool              `json:"auto_provision"`
 	Enabled               bool              `json:"enabled"`
 	CreatedAt             time.Time         `json:"created_at"`
 	UpdatedAt             time.Time         `json:"updated_at"`
 }
+
+// OIDCRoleRule assigns a role, built-in or custom, to users whose claim at
+// Claim contains Value. Claim is a dot-separated path into the ID token,
+// such as "groups" or "realm_access.roles". Rules are tried in ascending
+// Priority order and the first match wins.
+type OIDCRoleRule struct {
+	Claim    string `json:"claim"`
+	Value    string `json:"value"`
+	Role     string `json:"role"`
+	Priority int    `json:"priority"`
+}
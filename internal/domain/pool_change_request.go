@@ -0,0 +1,220 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// PoolChangeOperation is the pool mutation a change request holds back.
+type PoolChangeOperation string
+
+const (
+	PoolChangeCreate PoolChangeOperation = "create"
+	PoolChangeUpdate PoolChangeOperation = "update"
+	PoolChangeDelete PoolChangeOperation = "delete"
+)
+
+// ValidPoolChangeOperations lists the operations approval policies can cover.
+var ValidPoolChangeOperations = []PoolChangeOperation{PoolChangeCreate, PoolChangeUpdate, PoolChangeDelete}
+
+// IsValidPoolChangeOperation reports whether op is a known operation.
+func IsValidPoolChangeOperation(op PoolChangeOperation) bool {
+	return slices.Contains(ValidPoolChangeOperations, op)
+}
+
+// ApprovalPolicy makes pool mutations it matches wait for a second person's
+// approval. Empty selectors match every pool; a policy with no selectors at
+// all covers every pool mutation.
+type ApprovalPolicy struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	// Operations limits the policy to some mutations; empty means all.
+	Operations []PoolChangeOperation `json:"operations,omitempty"`
+	PoolTypes  []PoolType            `json:"pool_types,omitempty"`
+	Statuses   []PoolStatus          `json:"statuses,omitempty"`
+	// Tags must all be present with the given values; "*" matches any
+	// non-empty value.
+	Tags map[string]string `json:"tags,omitempty"`
+	// SubtreePoolID matches this pool and every pool beneath it.
+	SubtreePoolID *int64 `json:"subtree_pool_id,omitempty"`
+}
+
+// Matches reports whether the policy covers op on pool. lineage holds the
+// IDs of the pool (when it exists) and all its ancestors.
+func (p ApprovalPolicy) Matches(op PoolChangeOperation, pool Pool, lineage []int64) bool {
+	if !p.Enabled {
+		return false
+	}
+	if len(p.Operations) > 0 && !slices.Contains(p.Operations, op) {
+		return false
+	}
+	poolType := pool.Type
+	if poolType == "" {
+		poolType = PoolTypeSubnet
+	}
+	if len(p.PoolTypes) > 0 && !slices.Contains(p.PoolTypes, poolType) {
+		return false
+	}
+	status := pool.Status
+	if status == "" {
+		status = PoolStatusActive
+	}
+	if len(p.Statuses) > 0 && !slices.Contains(p.Statuses, status) {
+		return false
+	}
+	for k, want := range p.Tags {
+		got := pool.Tags[k]
+		if got == "" || (want != "*" && got != want) {
+			return false
+		}
+	}
+	if p.SubtreePoolID != nil && !slices.Contains(lineage, *p.SubtreePoolID) {
+		return false
+	}
+	return true
+}
+
+// ApprovalSettings holds the approval policies for pool mutations.
+type ApprovalSettings struct {
+	Policies []ApprovalPolicy `json:"policies"`
+}
+
+// DefaultApprovalSettings has no policies, so pool mutations apply at once.
+func DefaultApprovalSettings() ApprovalSettings {
+	return ApprovalSettings{Policies: []ApprovalPolicy{}}
+}
+
+// NormalizeApprovalSettings fills defaults for missing fields.
+func NormalizeApprovalSettings(settings *ApprovalSettings) *ApprovalSettings {
+	if settings == nil {
+		defaults := DefaultApprovalSettings()
+		return &defaults
+	}
+	if settings.Policies == nil {
+		settings.Policies = []ApprovalPolicy{}
+	}
+	return settings
+}
+
+var approvalPolicyIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,63}$`)
+
+// ValidateApprovalSettings checks policy IDs and selectors. It returns ""
+// when the settings are valid.
+func ValidateApprovalSettings(settings *ApprovalSettings) string {
+	if settings == nil {
+		return "settings are required"
+	}
+	ids := make(map[string]bool, len(settings.Policies))
+	for i, p := range settings.Policies {
+		if !approvalPolicyIDPattern.MatchString(p.ID) {
+			return fmt.Sprintf("policies[%d]: id must be 1-64 letters, digits, '.', '_' or '-'", i)
+		}
+		if ids[p.ID] {
+			return fmt.Sprintf("policies[%d]: duplicate id %q", i, p.ID)
+		}
+		ids[p.ID] = true
+		if strings.TrimSpace(p.Name) == "" {
+			return fmt.Sprintf("policy %q: name is required", p.ID)
+		}
+		for _, op := range p.Operations {
+			if !IsValidPoolChangeOperation(op) {
+				return fmt.Sprintf("policy %q: invalid operation %q", p.ID, op)
+			}
+		}
+		for _, t := range p.PoolTypes {
+			if !IsValidPoolType(t) {
+				return fmt.Sprintf("policy %q: invalid pool type %q", p.ID, t)
+			}
+		}
+		for _, s := range p.Statuses {
+			if !IsValidPoolStatus(s) {
+				return fmt.Sprintf("policy %q: invalid status %q", p.ID, s)
+			}
+		}
+		for k := range p.Tags {
+			if strings.TrimSpace(k) == "" {
+				return fmt.Sprintf("policy %q: tag keys must not be empty", p.ID)
+			}
+		}
+		if p.SubtreePoolID != nil && *p.SubtreePoolID < 1 {
+			return fmt.Sprintf("policy %q: invalid subtree_pool_id", p.ID)
+		}
+	}
+	return ""
+}
+
+// PoolChangeRequestStatus tracks a change request through review.
+type PoolChangeRequestStatus string
+
+const (
+	PoolChangeRequestPending  PoolChangeRequestStatus = "pending"
+	PoolChangeRequestApplied  PoolChangeRequestStatus = "applied"
+	PoolChangeRequestRejected PoolChangeRequestStatus = "rejected"
+)
+
+// IsValidPoolChangeRequestStatus reports whether s is a known status.
+func IsValidPoolChangeRequestStatus(s PoolChangeRequestStatus) bool {
+	switch s {
+	case PoolChangeRequestPending, PoolChangeRequestApplied, PoolChangeRequestRejected:
+		return true
+	}
+	return false
+}
+
+// PoolChangeRequest is a pool create, update or delete held back by an
+// approval policy until someone other than the requester approves it.
+//
+// A pending create holds its CIDR: no pool or other request may take an
+// overlapping block under the same parent until it is decided.
+type PoolChangeRequest struct {
+	ID        string                  `json:"id"`
+	Operation PoolChangeOperation     `json:"operation"`
+	Status    PoolChangeRequestStatus `json:"status"`
+	// PoolID is the pool updated or deleted, or for a create, the pool
+	// made once the request is applied.
+	PoolID *int64 `json:"pool_id,omitempty"`
+	// PoolVersion is the version the update or delete was requested
+	// against. Approval fails if the pool has changed since.
+	PoolVersion int64  `json:"pool_version,omitempty"`
+	PoolName    string `json:"pool_name"`
+	// CIDR and ParentID locate the block a create holds.
+	CIDR     string `json:"cidr,omitempty"`
+	ParentID *int64 `json:"parent_id,omitempty"`
+
+	Create *CreatePool `json:"create,omitempty"`
+	Update *UpdatePool `json:"update,omitempty"`
+	// Cascade deletes the pool's subtree too, as with ?force=true.
+	Cascade bool `json:"cascade,omitempty"`
+	// Resize is the CIDR an update moves the pool to. It is set instead of
+	// Update when a policy holds back a resize recommendation.
+	Resize string `json:"resize,omitempty"`
+
+	PolicyIDs []string `json:"policy_ids"`
+
+	// RequestedByID and ReviewedByID name the principal behind the
+	// session or API key, "user:<id>" or "apikey:<id>". The requester
+	// check compares these rather than the display names.
+	RequestedBy   string     `json:"requested_by"`
+	RequestedByID string     `json:"requested_by_id"`
+	RequestedRole string     `json:"requested_role"`
+	ReviewedBy    string     `json:"reviewed_by,omitempty"`
+	ReviewedByID  string     `json:"reviewed_by_id,omitempty"`
+	ReviewedRole  string     `json:"reviewed_role,omitempty"`
+	ReviewComment string     `json:"review_comment,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ReviewedAt    *time.Time `json:"reviewed_at,omitempty"`
+}
+
+// HoldsCIDR reports whether the request keeps its CIDR from other pools.
+func (r PoolChangeRequest) HoldsCIDR() bool {
+	return r.Status == PoolChangeRequestPending && r.Operation == PoolChangeCreate && r.CIDR != ""
+}
+
+// PoolChangeRequestListResponse is the body of GET /api/v1/change-requests.
+type PoolChangeRequestListResponse struct {
+	Items []PoolChangeRequest `json:"items"`
+}
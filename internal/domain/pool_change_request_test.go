@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApprovalPolicyMatches(t *testing.T) {
+	subtree := int64(2)
+	prod := ApprovalPolicy{
+		ID: "prod", Name: "Production VPCs", Enabled: true,
+		Operations: []PoolChangeOperation{PoolChangeCreate, PoolChangeDelete},
+		PoolTypes:  []PoolType{PoolTypeVPC, PoolTypeSupernet},
+		Tags:       map[string]string{"env": "prod", "owner": "*"},
+	}
+	vpc := Pool{Type: PoolTypeVPC, Tags: map[string]string{"env": "prod", "owner": "netops"}}
+
+	cases := []struct {
+		name    string
+		policy  ApprovalPolicy
+		op      PoolChangeOperation
+		pool    Pool
+		lineage []int64
+		want    bool
+	}{
+		{"all selectors match", prod, PoolChangeCreate, vpc, nil, true},
+		{"operation not covered", prod, PoolChangeUpdate, vpc, nil, false},
+		{"wrong type", prod, PoolChangeCreate, Pool{Type: PoolTypeSubnet, Tags: vpc.Tags}, nil, false},
+		{"wildcard tag needs a value", prod, PoolChangeCreate, Pool{Type: PoolTypeVPC, Tags: map[string]string{"env": "prod"}}, nil, false},
+		{"disabled", ApprovalPolicy{ID: "x", Name: "x"}, PoolChangeCreate, vpc, nil, false},
+		{"empty type is subnet", ApprovalPolicy{Enabled: true, PoolTypes: []PoolType{PoolTypeSubnet}}, PoolChangeCreate, Pool{}, nil, true},
+		{"empty status is active", ApprovalPolicy{Enabled: true, Statuses: []PoolStatus{PoolStatusActive}}, PoolChangeUpdate, Pool{}, nil, true},
+		{"inside subtree", ApprovalPolicy{Enabled: true, SubtreePoolID: &subtree}, PoolChangeDelete, Pool{ID: 5}, []int64{5, 2, 1}, true},
+		{"outside subtree", ApprovalPolicy{Enabled: true, SubtreePoolID: &subtree}, PoolChangeDelete, Pool{ID: 6}, []int64{6, 1}, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.policy.Matches(tc.op, tc.pool, tc.lineage); got != tc.want {
+				t.Fatalf("Matches = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidateApprovalSettings(t *testing.T) {
+	zero := int64(0)
+	cases := []struct {
+		name     string
+		policies []ApprovalPolicy
+		want     string
+	}{
+		{"valid", []ApprovalPolicy{{ID: "prod", Name: "Prod", Enabled: true, PoolTypes: []PoolType{PoolTypeVPC}}}, ""},
+		{"bad id", []ApprovalPolicy{{ID: "-x", Name: "x"}}, "id must be"},
+		{"duplicate id", []ApprovalPolicy{{ID: "a", Name: "a"}, {ID: "a", Name: "b"}}, "duplicate id"},
+		{"missing name", []ApprovalPolicy{{ID: "a"}}, "name is required"},
+		{"bad operation", []ApprovalPolicy{{ID: "a", Name: "a", Operations: []PoolChangeOperation{"move"}}}, "invalid operation"},
+		{"bad type", []ApprovalPolicy{{ID: "a", Name: "a", PoolTypes: []PoolType{"zone"}}}, "invalid pool type"},
+		{"bad status", []ApprovalPolicy{{ID: "a", Name: "a", Statuses: []PoolStatus{"gone"}}}, "invalid status"},
+		{"empty tag key", []ApprovalPolicy{{ID: "a", Name: "a", Tags: map[string]string{" ": "x"}}}, "tag keys"},
+		{"bad subtree", []ApprovalPolicy{{ID: "a", Name: "a", SubtreePoolID: &zero}}, "subtree_pool_id"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := ValidateApprovalSettings(&ApprovalSettings{Policies: tc.policies})
+			if tc.want == "" && got != "" || tc.want != "" && !strings.Contains(got, tc.want) {
+				t.Fatalf("ValidateApprovalSettings = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
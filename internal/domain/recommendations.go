@@ -10,10 +10,15 @@ type RecommendationType string
 const (
 	RecommendationTypeAllocation RecommendationType = "allocation"
 	RecommendationTypeCompliance RecommendationType = "compliance"
-	// Future types (stubbed):
-	// RecommendationTypeConsolidation RecommendationType = "consolidation"
-	// RecommendationTypeResize        RecommendationType = "resize"
-	// RecommendationTypeReclaim       RecommendationType = "reclaim"
+	// RecommendationTypeConsolidation suggests nesting adjacent sibling
+	// blocks under one aggregate pool.
+	RecommendationTypeConsolidation RecommendationType = "consolidation"
+	// RecommendationTypeResize suggests shrinking or growing a pool whose
+	// utilization history shows it is chronically over- or under-sized.
+	RecommendationTypeResize RecommendationType = "resize"
+	// RecommendationTypeReclaim suggests deleting a deprecated or empty pool,
+	// or one whose discovered resources are gone.
+	RecommendationTypeReclaim RecommendationType = "reclaim"
 )
 
 // RecommendationStatus tracks the lifecycle of a recommendation.
@@ -51,6 +56,19 @@ type Recommendation struct {
 	AppliedPoolID *int64                 `json:"applied_pool_id,omitempty"`
 	CreatedAt     time.Time              `json:"created_at"`
 	UpdatedAt     time.Time              `json:"updated_at"`
+	// Changes lists the pool changes made by applying the recommendation.
+	// It is only set in the apply response and is not stored.
+	Changes []RecommendationChange `json:"changes,omitempty"`
+}
+
+// RecommendationChange describes one pool created, updated or deleted while
+// applying a recommendation.
+type RecommendationChange struct {
+	Action string         `json:"action"` // create, update or delete
+	PoolID int64          `json:"pool_id"`
+	Name   string         `json:"name"`
+	Before map[string]any `json:"before,omitempty"`
+	After  map[string]any `json:"after,omitempty"`
 }
 
 // GenerateRecommendationsRequest is the input for generating recommendations.
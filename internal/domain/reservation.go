@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// ReservationStatus tracks a CIDR reservation from creation to its end.
+type ReservationStatus string
+
+const (
+	ReservationActive   ReservationStatus = "active"
+	ReservationReleased ReservationStatus = "released"
+	ReservationExpired  ReservationStatus = "expired"
+)
+
+// ValidReservationStatuses lists the reservation statuses.
+var ValidReservationStatuses = []ReservationStatus{ReservationActive, ReservationReleased, ReservationExpired}
+
+// IsValidReservationStatus reports whether s is a known status.
+func IsValidReservationStatus(s ReservationStatus) bool {
+	return slices.Contains(ValidReservationStatuses, s)
+}
+
+// Reservation is a soft hold on a block under a parent pool: the block is
+// kept free for an owner until it is released or expires, without creating
+// a pool. Gap analysis, the allocator and conflict checks treat an active
+// reservation as occupied.
+type Reservation struct {
+	ID   string `json:"id"`
+	CIDR string `json:"cidr"`
+	// ParentID is the pool the block is reserved in; nil means top level.
+	ParentID  *int64            `json:"parent_id,omitempty"`
+	Owner     string            `json:"owner"`
+	Reason    string            `json:"reason,omitempty"`
+	Status    ReservationStatus `json:"status"`
+	ExpiresAt time.Time         `json:"expires_at"`
+	CreatedBy string            `json:"created_by"`
+	CreatedAt time.Time         `json:"created_at"`
+	// ReleasedBy and ReleasedAt are set when the reservation ends; an
+	// expired reservation is released by "system".
+	ReleasedBy string     `json:"released_by,omitempty"`
+	ReleasedAt *time.Time `json:"released_at,omitempty"`
+}
+
+// HoldsAt reports whether the reservation still holds its block at now. A
+// reservation past its expiry stops holding before the expiry job marks it.
+func (r Reservation) HoldsAt(now time.Time) bool {
+	return r.Status == ReservationActive && now.Before(r.ExpiresAt)
+}
+
+// CreateReservation is the body of a new reservation. Owner defaults to the
+// caller.
+type CreateReservation struct {
+	CIDR      string    `json:"cidr"`
+	ParentID  *int64    `json:"parent_id,omitempty"`
+	Owner     string    `json:"owner,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// UpdateReservation changes the owner, reason or expiry of an active
+// reservation. Nil fields are left as they are.
+type UpdateReservation struct {
+	Owner     *string    `json:"owner,omitempty"`
+	Reason    *string    `json:"reason,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// ReservationListResponse is the response of GET /api/v1/reservations.
+type ReservationListResponse struct {
+	Items []Reservation `json:"items"`
+}
@@ -1,5 +1,7 @@
 package domain
 
+import "fmt"
+
 // SecuritySettings holds runtime security configuration.
 type SecuritySettings struct {
 	SessionDurationHours          int                 `json:"session_duration_hours"`
@@ -15,6 +17,23 @@ type SecuritySettings struct {
 	APIKeyMaxLifetimeDays         int                 `json:"api_key_max_lifetime_days"`
 	APIKeyRotationReminderDays    int                 `json:"api_key_rotation_reminder_days"`
 	APIKeyAllowedScopesByRole     map[string][]string `json:"api_key_allowed_scopes_by_role"`
+	// MFARequired makes every local user enrol in TOTP before they can sign
+	// in; MFARequiredRoles does the same for users holding the listed roles.
+	MFARequired      bool     `json:"mfa_required"`
+	MFARequiredRoles []string `json:"mfa_required_roles"`
+}
+
+// RequiresMFA reports whether local users with role must use MFA.
+func (s SecuritySettings) RequiresMFA(role string) bool {
+	if s.MFARequired {
+		return true
+	}
+	for _, r := range s.MFARequiredRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
 }
 
 // DefaultSecuritySettings returns safe defaults.
@@ -33,6 +52,7 @@ func DefaultSecuritySettings() SecuritySettings {
 		APIKeyMaxLifetimeDays:         0,
 		APIKeyRotationReminderDays:    14,
 		APIKeyAllowedScopesByRole:     DefaultAPIKeyAllowedScopesByRole(),
+		MFARequiredRoles:              []string{},
 	}
 }
 
@@ -43,6 +63,7 @@ func DefaultAPIKeyAllowedScopesByRole() map[string][]string {
 	return map[string][]string{
 		"admin": {
 			"pools:read", "pools:write",
+			"change_requests:read", "change_requests:approve",
 			"accounts:read", "accounts:write",
 			"keys:read", "keys:write",
 			"discovery:read", "discovery:write",
@@ -51,16 +72,19 @@ func DefaultAPIKeyAllowedScopesByRole() map[string][]string {
 		},
 		"operator": {
 			"pools:read", "pools:write",
+			"change_requests:read",
 			"accounts:read", "accounts:write",
 			"discovery:read", "discovery:write",
 		},
 		"viewer": {
 			"pools:read",
+			"change_requests:read",
 			"accounts:read",
 			"discovery:read",
 		},
 		"auditor": {
 			"audit:read",
+			"change_requests:read",
 		},
 	}
 }
@@ -78,6 +102,9 @@ func NormalizeSecuritySettings(settings *SecuritySettings) *SecuritySettings {
 	if settings.TrustedProxies == nil {
 		settings.TrustedProxies = []string{}
 	}
+	if settings.MFARequiredRoles == nil {
+		settings.MFARequiredRoles = []string{}
+	}
 	if settings.APIKeyRotationReminderDays < 0 {
 		settings.APIKeyRotationReminderDays = DefaultSecuritySettings().APIKeyRotationReminderDays
 	}
@@ -98,3 +125,43 @@ func NormalizeSecuritySettings(settings *SecuritySettings) *SecuritySettings {
 	}
 	return settings
 }
+
+// AuditRetentionSettings controls how long audit events are kept. A zero
+// value turns that limit off.
+type AuditRetentionSettings struct {
+	// MaxAgeDays deletes events older than this many days.
+	MaxAgeDays int `json:"max_age_days"`
+	// MaxEvents keeps at most this many of the newest events.
+	MaxEvents int64 `json:"max_events"`
+	// SuccessfulMaxAgeDays deletes successful events (status below 400)
+	// sooner than MaxAgeDays, keeping failures for the full period.
+	SuccessfulMaxAgeDays int `json:"successful_max_age_days"`
+}
+
+// MaxAuditRetentionDays bounds the day counts in AuditRetentionSettings.
+const MaxAuditRetentionDays = 3650
+
+// DefaultAuditRetentionSettings keeps events for 90 days, up to 10 million.
+func DefaultAuditRetentionSettings() AuditRetentionSettings {
+	return AuditRetentionSettings{MaxAgeDays: 90, MaxEvents: 10000000}
+}
+
+// ValidateAuditRetentionSettings returns "" when settings are valid.
+func ValidateAuditRetentionSettings(settings *AuditRetentionSettings) string {
+	if settings == nil {
+		return "settings are required"
+	}
+	if settings.MaxAgeDays < 0 || settings.MaxAgeDays > MaxAuditRetentionDays {
+		return fmt.Sprintf("max_age_days must be between 0 and %d", MaxAuditRetentionDays)
+	}
+	if settings.MaxEvents < 0 {
+		return "max_events must not be negative"
+	}
+	if settings.SuccessfulMaxAgeDays < 0 || settings.SuccessfulMaxAgeDays > MaxAuditRetentionDays {
+		return fmt.Sprintf("successful_max_age_days must be between 0 and %d", MaxAuditRetentionDays)
+	}
+	if settings.MaxAgeDays > 0 && settings.SuccessfulMaxAgeDays >= settings.MaxAgeDays {
+		return "successful_max_age_days must be shorter than max_age_days"
+	}
+	return ""
+}
@@ -96,9 +96,12 @@ type Pool struct {
 	Source      PoolSource        `json:"source"`
 	Description string            `json:"description,omitempty"`
 	Tags        map[string]string `json:"tags,omitempty"`
-	CreatedAt   time.Time         `json:"created_at"`
-	UpdatedAt   time.Time         `json:"updated_at"`
-	DeletedAt   *time.Time        `json:"deleted_at,omitempty"`
+	// Version starts at 1 and increases on every write. The API serves it
+	// as the pool's ETag.
+	Version   int64      `json:"version"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 // PoolStats contains computed statistics for a pool.
@@ -131,6 +134,21 @@ type CreatePool struct {
 	Tags        map[string]string `json:"tags,omitempty"`
 }
 
+// AllocatePool is the input for carving the next free child out of a parent
+// pool. Unlike CreatePool the caller names a prefix length rather than a CIDR;
+// the server picks the block. Strategy is "first_fit" (the default) or
+// "best_fit".
+type AllocatePool struct {
+	Name         string            `json:"name"`
+	PrefixLength int               `json:"prefix_length"`
+	Strategy     string            `json:"strategy,omitempty"`
+	AccountID    *int64            `json:"account_id,omitempty"`
+	Type         PoolType          `json:"type,omitempty"`
+	Status       PoolStatus        `json:"status,omitempty"`
+	Description  string            `json:"description,omitempty"`
+	Tags         map[string]string `json:"tags,omitempty"`
+}
+
 // UpdatePool is the input for updating a pool. It is an already-resolved
 // command, not a wire type: the PATCH handler inspects key presence in the raw
 // request body and passes the resolved value in here.
@@ -145,24 +163,32 @@ type UpdatePool struct {
 	Status      *PoolStatus        `json:"status,omitempty"`
 	Description *string            `json:"description,omitempty"`
 	Tags        *map[string]string `json:"tags,omitempty"`
+	// IfVersion, when set, applies the update only if the pool is still at
+	// this version; otherwise the store returns storage.ErrPreconditionFailed.
+	// The API fills it from the If-Match header.
+	IfVersion *int64 `json:"-"`
 }
 
 // Account represents a cloud account or project to which pools can be assigned.
 // It uses a generic shape to support AWS accounts, GCP projects, etc.
 type Account struct {
-	ID          int64      `json:"id"`
-	Key         string     `json:"key"` // unique key like "aws:123456789012" or "gcp:my-project"
-	Name        string     `json:"name"`
-	Provider    string     `json:"provider,omitempty"`
-	ExternalID  string     `json:"external_id,omitempty"`
-	Description string     `json:"description,omitempty"`
-	Platform    string     `json:"platform,omitempty"`
-	Tier        string     `json:"tier,omitempty"`
-	Environment string     `json:"environment,omitempty"`
-	Regions     []string   `json:"regions,omitempty"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
-	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+	ID          int64    `json:"id"`
+	Key         string   `json:"key"` // unique key like "aws:123456789012" or "gcp:my-project"
+	Name        string   `json:"name"`
+	Provider    string   `json:"provider,omitempty"`
+	ExternalID  string   `json:"external_id,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Platform    string   `json:"platform,omitempty"`
+	Tier        string   `json:"tier,omitempty"`
+	Environment string   `json:"environment,omitempty"`
+	Regions     []string `json:"regions,omitempty"`
+	// Version starts at 1 and increases on every write. The API serves it
+	// as the account's ETag. As an UpdateAccount argument, a non-zero
+	// Version is the version the caller expects to replace.
+	Version   int64      `json:"version"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 // CreateAccount is the input for creating an account.
@@ -0,0 +1,124 @@
+package domain
+
+import "time"
+
+// WebhookEventType names an IPAM lifecycle event that can be delivered to a
+// webhook endpoint.
+type WebhookEventType string
+
+const (
+	WebhookEventPoolCreated             WebhookEventType = "pool.created"
+	WebhookEventPoolUpdated             WebhookEventType = "pool.updated"
+	WebhookEventPoolDeleted             WebhookEventType = "pool.deleted"
+	WebhookEventPoolAllocated           WebhookEventType = "pool.allocated"
+	WebhookEventDriftDetected           WebhookEventType = "drift.detected"
+	WebhookEventAgentOffline            WebhookEventType = "agent.offline"
+	WebhookEventRecommendationGenerated WebhookEventType = "recommendation.generated"
+	WebhookEventReservationExpired      WebhookEventType = "reservation.expired"
+
+	// WebhookEventPing is sent only by the test endpoint and cannot be
+	// subscribed to.
+	WebhookEventPing WebhookEventType = "ping"
+)
+
+// WebhookEventTypes lists the event types a webhook can subscribe to.
+func WebhookEventTypes() []WebhookEventType {
+	return []WebhookEventType{
+		WebhookEventPoolCreated,
+		WebhookEventPoolUpdated,
+		WebhookEventPoolDeleted,
+		WebhookEventPoolAllocated,
+		WebhookEventDriftDetected,
+		WebhookEventAgentOffline,
+		WebhookEventRecommendationGenerated,
+		WebhookEventReservationExpired,
+	}
+}
+
+// IsValidWebhookEventType reports whether t can be subscribed to.
+func IsValidWebhookEventType(t WebhookEventType) bool {
+	for _, known := range WebhookEventTypes() {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Webhook is an outbound endpoint that receives signed event deliveries.
+type Webhook struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Secret string `json:"-"` // HMAC signing key; only returned when the webhook is created
+	// Events filters which event types are delivered. Empty means all.
+	Events    []WebhookEventType `json:"events"`
+	Enabled   bool               `json:"enabled"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+// Subscribes reports whether the webhook wants events of type t.
+func (w Webhook) Subscribes(t WebhookEventType) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookEvent is the JSON body POSTed to a webhook endpoint.
+type WebhookEvent struct {
+	ID         string           `json:"id"`
+	Type       WebhookEventType `json:"type"`
+	OccurredAt time.Time        `json:"occurred_at"`
+	Data       any              `json:"data"`
+}
+
+// WebhookDeliveryStatus tracks a delivery through its retries.
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery records one event sent to one webhook. Pending deliveries
+// are retried at NextAttemptAt until they succeed or run out of attempts.
+type WebhookDelivery struct {
+	ID             string                `json:"id"`
+	WebhookID      string                `json:"webhook_id"`
+	EventID        string                `json:"event_id"`
+	EventType      WebhookEventType      `json:"event_type"`
+	Payload        string                `json:"payload"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	ResponseStatus int                   `json:"response_status,omitempty"`
+	LastError      string                `json:"last_error,omitempty"`
+	NextAttemptAt  *time.Time            `json:"next_attempt_at,omitempty"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+}
+
+// WebhookDeliveryFilters for listing deliveries.
+type WebhookDeliveryFilters struct {
+	WebhookID string
+	Status    string
+	EventType string
+	Page      int
+	PageSize  int
+}
+
+// WebhookDeliveryListResponse is the paginated list of deliveries.
+type WebhookDeliveryListResponse struct {
+	Items    []WebhookDelivery `json:"items"`
+	Total    int               `json:"total"`
+	Page     int               `json:"page"`
+	PageSize int               `json:"page_size"`
+}
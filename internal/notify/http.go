@@ -0,0 +1,119 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"cloudpam/internal/domain"
+)
+
+// webhookNotifier POSTs the Notification itself as JSON.
+type webhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func newWebhookNotifier(ch domain.NotificationChannel, client *http.Client) (Notifier, error) {
+	if err := validateHTTPURL(ch.URL); err != nil {
+		return nil, err
+	}
+	return &webhookNotifier{url: ch.URL, client: client}, nil
+}
+
+func (w *webhookNotifier) Notify(ctx context.Context, n Notification) error {
+	return postJSON(ctx, w.client, w.url, n)
+}
+
+// slackNotifier POSTs an incoming-webhook message. Mattermost and Rocket.Chat
+// accept the same format.
+type slackNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func newSlackNotifier(ch domain.NotificationChannel, client *http.Client) (Notifier, error) {
+	if err := validateHTTPURL(ch.URL); err != nil {
+		return nil, err
+	}
+	return &slackNotifier{url: ch.URL, client: client}, nil
+}
+
+type slackMessage struct {
+	Text        string            `json:"text"`
+	Attachments []slackAttachment `json:"attachments"`
+}
+
+type slackAttachment struct {
+	Color    string       `json:"color"`
+	Fallback string       `json:"fallback"`
+	Fields   []slackField `json:"fields"`
+	Ts       int64        `json:"ts"`
+}
+
+type slackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+func (s *slackNotifier) Notify(ctx context.Context, n Notification) error {
+	a := n.Alert
+	color := "warning"
+	switch {
+	case n.Event == EventAlertResolved:
+		color = "good"
+	case a.Severity == domain.AlertSeverityCritical:
+		color = "danger"
+	}
+	return postJSON(ctx, s.client, s.url, slackMessage{
+		Text: n.Subject(),
+		Attachments: []slackAttachment{{
+			Color:    color,
+			Fallback: a.Message,
+			Fields: []slackField{
+				{Title: "Pool", Value: fmt.Sprintf("%s (%s)", a.PoolName, a.PoolCIDR), Short: true},
+				{Title: "Kind", Value: string(a.Kind), Short: true},
+				{Title: "Value", Value: fmt.Sprintf("%g", a.Value), Short: true},
+				{Title: "Threshold", Value: fmt.Sprintf("%g", a.Threshold), Short: true},
+			},
+			Ts: n.SentAt.Unix(),
+		}},
+	})
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, body any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "CloudPAM-Notify/1.0")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func validateHTTPURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("url must be an absolute http or https URL")
+	}
+	return nil
+}
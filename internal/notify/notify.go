@@ -0,0 +1,145 @@
+// Package notify delivers capacity alert notifications to the channels
+// configured in the alert settings.
+//
+// Each channel type is backed by a Factory registered on a Registry. The
+// default registry knows three types: smtp sends a plain-text email through
+// a relay, webhook POSTs the notification as JSON, and slack POSTs a
+// Slack-compatible message to an incoming-webhook URL. Delivery is a single
+// attempt; callers log failures and the next state change notifies again.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"cloudpam/internal/domain"
+)
+
+// Event identifies why a notification was sent.
+type Event string
+
+const (
+	EventAlertOpened    Event = "alert.opened"
+	EventAlertEscalated Event = "alert.escalated"
+	EventAlertResolved  Event = "alert.resolved"
+	EventTest           Event = "alert.test"
+)
+
+// Notification is one alert state change sent to a channel.
+type Notification struct {
+	Event  Event        `json:"event"`
+	Alert  domain.Alert `json:"alert"`
+	SentAt time.Time    `json:"sent_at"`
+}
+
+// Subject is a one-line summary suitable for an email subject or chat
+// message title.
+func (n Notification) Subject() string {
+	var prefix string
+	switch n.Event {
+	case EventAlertResolved:
+		prefix = "RESOLVED"
+	case EventTest:
+		prefix = "TEST"
+	default:
+		prefix = strings.ToUpper(string(n.Alert.Severity))
+	}
+	// Pool names are user input; keep them from breaking header lines.
+	msg := strings.NewReplacer("\r", " ", "\n", " ").Replace(n.Alert.Message)
+	return fmt.Sprintf("[CloudPAM] %s: %s", prefix, msg)
+}
+
+// Notifier delivers notifications to one channel.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// Factory builds a Notifier for a channel, returning an error if the
+// channel's settings are incomplete.
+type Factory func(ch domain.NotificationChannel) (Notifier, error)
+
+// ErrUnknownChannelType is returned for channels whose type has no
+// registered Factory.
+var ErrUnknownChannelType = errors.New("unknown notification channel type")
+
+// Options configures the built-in notifiers. Zero values take the defaults
+// noted on each field.
+type Options struct {
+	// Client sends webhook and Slack requests; default: a client with a
+	// 10s timeout that does not follow redirects.
+	Client *http.Client
+	// SendMail sends email; default smtp.SendMail.
+	SendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// Registry maps channel types to notifier factories.
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[domain.NotificationChannelType]Factory
+}
+
+// NewRegistry returns a registry with the smtp, webhook and slack types
+// registered.
+func NewRegistry(opts Options) *Registry {
+	if opts.Client == nil {
+		opts.Client = &http.Client{
+			Timeout:       10 * time.Second,
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		}
+	}
+	if opts.SendMail == nil {
+		opts.SendMail = smtp.SendMail
+	}
+	r := &Registry{factories: make(map[domain.NotificationChannelType]Factory)}
+	r.Register(domain.NotificationChannelSMTP, func(ch domain.NotificationChannel) (Notifier, error) {
+		return newSMTPNotifier(ch, opts.SendMail)
+	})
+	r.Register(domain.NotificationChannelWebhook, func(ch domain.NotificationChannel) (Notifier, error) {
+		return newWebhookNotifier(ch, opts.Client)
+	})
+	r.Register(domain.NotificationChannelSlack, func(ch domain.NotificationChannel) (Notifier, error) {
+		return newSlackNotifier(ch, opts.Client)
+	})
+	return r
+}
+
+// Register adds or replaces the factory for a channel type.
+func (r *Registry) Register(t domain.NotificationChannelType, f Factory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.factories[t] = f
+}
+
+// Notifier builds the notifier for a channel.
+func (r *Registry) Notifier(ch domain.NotificationChannel) (Notifier, error) {
+	r.mu.RLock()
+	f, ok := r.factories[ch.Type]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("channel %q: %w %q", ch.ID, ErrUnknownChannelType, ch.Type)
+	}
+	n, err := f(ch)
+	if err != nil {
+		return nil, fmt.Errorf("channel %q: %w", ch.ID, err)
+	}
+	return n, nil
+}
+
+// Types returns the registered channel types in name order.
+func (r *Registry) Types() []domain.NotificationChannelType {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]domain.NotificationChannelType, 0, len(r.factories))
+	for t := range r.factories {
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
@@ -0,0 +1,133 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"cloudpam/internal/domain"
+)
+
+func testNotification(event Event) Notification {
+	opened := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	return Notification{
+		Event: event,
+		Alert: domain.Alert{
+			ID: "a1", RuleID: "default", PoolID: 7, PoolName: "prod\r\nBcc: evil@example.com", PoolCIDR: "10.0.0.0/24",
+			Kind: domain.AlertKindUtilization, Severity: domain.AlertSeverityCritical, Status: domain.AlertStatusOpen,
+			Value: 93.75, Threshold: 90, Message: "Pool prod\r\nBcc: evil@example.com is 93.75% utilized",
+			OpenedAt: opened, UpdatedAt: opened,
+		},
+		SentAt: opened,
+	}
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	var gotAddr, gotFrom string
+	var gotTo []string
+	var gotMsg []byte
+	reg := NewRegistry(Options{SendMail: func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		gotAddr, gotFrom, gotTo, gotMsg = addr, from, to, msg
+		return nil
+	}})
+	n, err := reg.Notifier(domain.NotificationChannel{
+		ID: "mail", Type: domain.NotificationChannelSMTP, SMTPAddr: "localhost:25",
+		From: "CloudPAM <cloudpam@example.com>", To: []string{"netops@example.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Notify(context.Background(), testNotification(EventAlertOpened)); err != nil {
+		t.Fatal(err)
+	}
+	if gotAddr != "localhost:25" || gotFrom != "cloudpam@example.com" || len(gotTo) != 1 || gotTo[0] != "netops@example.com" {
+		t.Fatalf("sent via %s from %s to %v", gotAddr, gotFrom, gotTo)
+	}
+	headers, _, _ := strings.Cut(string(gotMsg), "\r\n\r\n")
+	if !strings.Contains(headers, "Subject: [CloudPAM] CRITICAL: Pool prod  Bcc: evil@example.com is 93.75% utilized") {
+		t.Errorf("headers = %q", headers)
+	}
+	if strings.Contains(headers, "\r\nBcc:") {
+		t.Error("pool name injected a header")
+	}
+}
+
+func TestWebhookAndSlackNotifiers(t *testing.T) {
+	var bodies []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("content type = %q", r.Header.Get("Content-Type"))
+		}
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		bodies = append(bodies, body)
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer srv.Close()
+
+	reg := NewRegistry(Options{})
+	ctx := context.Background()
+	hook, err := reg.Notifier(domain.NotificationChannel{ID: "hook", Type: domain.NotificationChannelWebhook, URL: srv.URL + "/hook"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := hook.Notify(ctx, testNotification(EventAlertOpened)); err != nil {
+		t.Fatal(err)
+	}
+	slack, err := reg.Notifier(domain.NotificationChannel{ID: "chat", Type: domain.NotificationChannelSlack, URL: srv.URL + "/slack"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := slack.Notify(ctx, testNotification(EventAlertResolved)); err != nil {
+		t.Fatal(err)
+	}
+	if len(bodies) != 2 {
+		t.Fatalf("got %d requests", len(bodies))
+	}
+	if bodies[0]["event"] != "alert.opened" || bodies[0]["alert"].(map[string]any)["pool_id"] != float64(7) {
+		t.Errorf("webhook body = %v", bodies[0])
+	}
+	attachment := bodies[1]["attachments"].([]any)[0].(map[string]any)
+	if !strings.HasPrefix(bodies[1]["text"].(string), "[CloudPAM] RESOLVED:") || attachment["color"] != "good" {
+		t.Errorf("slack body = %v", bodies[1])
+	}
+
+	failing, _ := reg.Notifier(domain.NotificationChannel{ID: "bad", Type: domain.NotificationChannelWebhook, URL: srv.URL + "/fail"})
+	if err := failing.Notify(ctx, testNotification(EventAlertOpened)); err == nil || !strings.Contains(err.Error(), "502") {
+		t.Errorf("err = %v, want status 502", err)
+	}
+}
+
+func TestRegistryValidatesChannels(t *testing.T) {
+	reg := NewRegistry(Options{})
+	for name, ch := range map[string]domain.NotificationChannel{
+		"unknown type":   {ID: "x", Type: "pager"},
+		"webhook no url": {ID: "x", Type: domain.NotificationChannelWebhook},
+		"slack ftp url":  {ID: "x", Type: domain.NotificationChannelSlack, URL: "ftp://example.com/hook"},
+		"smtp no port":   {ID: "x", Type: domain.NotificationChannelSMTP, SMTPAddr: "localhost", From: "a@example.com", To: []string{"b@example.com"}},
+		"smtp no to":     {ID: "x", Type: domain.NotificationChannelSMTP, SMTPAddr: "localhost:25", From: "a@example.com"},
+		"smtp bad to":    {ID: "x", Type: domain.NotificationChannelSMTP, SMTPAddr: "localhost:25", From: "a@example.com", To: []string{"nobody"}},
+	} {
+		if _, err := reg.Notifier(ch); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	if _, err := reg.Notifier(domain.NotificationChannel{ID: "x", Type: "pager"}); !errors.Is(err, ErrUnknownChannelType) {
+		t.Errorf("err = %v, want ErrUnknownChannelType", err)
+	}
+
+	reg.Register("pager", func(domain.NotificationChannel) (Notifier, error) { return nil, nil })
+	if got := reg.Types(); len(got) != 4 || got[0] != "pager" {
+		t.Errorf("Types() = %v", got)
+	}
+}
@@ -0,0 +1,72 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"cloudpam/internal/domain"
+)
+
+// smtpNotifier emails notifications through a relay. It does not
+// authenticate: it is meant for a local relay that accepts mail from the
+// CloudPAM host. The relay's STARTTLS is used when offered.
+type smtpNotifier struct {
+	addr     string
+	from     string
+	to       []string
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+func newSMTPNotifier(ch domain.NotificationChannel, sendMail func(string, smtp.Auth, string, []string, []byte) error) (Notifier, error) {
+	if _, _, err := net.SplitHostPort(ch.SMTPAddr); err != nil {
+		return nil, errors.New("smtp_addr must be host:port")
+	}
+	from, err := mail.ParseAddress(ch.From)
+	if err != nil {
+		return nil, errors.New("from must be an email address")
+	}
+	if len(ch.To) == 0 {
+		return nil, errors.New("to must list at least one recipient")
+	}
+	to := make([]string, 0, len(ch.To))
+	for _, addr := range ch.To {
+		parsed, err := mail.ParseAddress(addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid recipient %q", addr)
+		}
+		to = append(to, parsed.Address)
+	}
+	return &smtpNotifier{addr: ch.SMTPAddr, from: from.Address, to: to, sendMail: sendMail}, nil
+}
+
+func (s *smtpNotifier) Notify(_ context.Context, n Notification) error {
+	return s.sendMail(s.addr, nil, s.from, s.to, s.message(n))
+}
+
+func (s *smtpNotifier) message(n Notification) []byte {
+	a := n.Alert
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", s.from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(s.to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", n.Subject())
+	fmt.Fprintf(&b, "Date: %s\r\n", n.SentAt.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	fmt.Fprintf(&b, "%s\r\n\r\n", a.Message)
+	fmt.Fprintf(&b, "Pool:      %s (%s)\r\n", a.PoolName, a.PoolCIDR)
+	fmt.Fprintf(&b, "Kind:      %s\r\n", a.Kind)
+	fmt.Fprintf(&b, "Severity:  %s\r\n", a.Severity)
+	fmt.Fprintf(&b, "Status:    %s\r\n", a.Status)
+	fmt.Fprintf(&b, "Value:     %g\r\n", a.Value)
+	fmt.Fprintf(&b, "Threshold: %g\r\n", a.Threshold)
+	fmt.Fprintf(&b, "Opened:    %s\r\n", a.OpenedAt.Format(time.RFC3339))
+	fmt.Fprintf(&b, "Alert ID:  %s\r\n", a.ID)
+	return []byte(b.String())
+}
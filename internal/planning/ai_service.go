@@ -21,6 +21,7 @@ type AIPlanningService struct {
 	convStore storage.ConversationStore
 	mainStore storage.Store
 	provider  llm.Provider
+	discovery storage.DiscoveryStore
 }
 
 // NewAIPlanningService creates a new AI planning service.
@@ -38,6 +39,12 @@ func NewAIPlanningService(
 	}
 }
 
+// SetDiscoveryStore lets the assistant list discovered cloud resources for
+// an account.
+func (s *AIPlanningService) SetDiscoveryStore(d storage.DiscoveryStore) {
+	s.discovery = d
+}
+
 // Available returns true if the LLM provider is configured and ready.
 func (s *AIPlanningService) Available() bool {
 	return s.provider != nil && s.provider.Available()
@@ -76,6 +83,11 @@ func (s *AIPlanningService) DeleteConversation(ctx context.Context, id string) e
 // Chat sends a user message and streams the assistant response. It persists
 // both the user message and the final assistant message. The returned channel
 // receives incremental text deltas; the caller should read until close.
+//
+// The model is offered the planner tools (see plannerTools). When it calls
+// them, the calls are run, their results appended to the conversation and
+// the model is asked again, up to maxToolRounds times; only the text it
+// writes reaches the channel and the stored message.
 func (s *AIPlanningService) Chat(ctx context.Context, sessionID, userMessage string) (<-chan llm.StreamEvent, error) {
 	// Persist user message
 	now := time.Now().UTC()
@@ -113,21 +125,61 @@ func (s *AIPlanningService) Chat(ctx context.Context, sessionID, userMessage str
 	}
 
 	// Start streaming from LLM
-	eventCh, err := s.provider.StreamComplete(ctx, messages, llm.Options{})
+	tools := s.plannerTools()
+	eventCh, err := s.provider.StreamComplete(ctx, messages, llm.Options{Tools: tools})
 	if err != nil {
 		return nil, fmt.Errorf("stream complete: %w", err)
 	}
 
-	// Wrap the channel to capture the full response and persist it
+	// Wrap the channel to run tool calls, capture the full response and
+	// persist it
 	outCh := make(chan llm.StreamEvent, 64)
 	go func() {
 		defer close(outCh)
 		var fullContent strings.Builder
 
-		for evt := range eventCh {
-			fullContent.WriteString(evt.Delta)
-			outCh <- evt
-			if evt.Done {
+		for round := 1; ; round++ {
+			var turn strings.Builder
+			var calls []llm.ToolCall
+			for evt := range eventCh {
+				turn.WriteString(evt.Delta)
+				if evt.Done && len(evt.ToolCalls) > 0 {
+					// The turn continues after the tools run, so the
+					// caller must not see this Done.
+					calls = evt.ToolCalls
+					if evt.Delta != "" {
+						outCh <- llm.StreamEvent{Delta: evt.Delta}
+					}
+					break
+				}
+				outCh <- evt
+				if evt.Done {
+					break
+				}
+			}
+			fullContent.WriteString(turn.String())
+			if len(calls) == 0 {
+				break
+			}
+
+			messages = append(messages, llm.Message{Role: "assistant", Content: turn.String(), ToolCalls: calls})
+			for _, call := range calls {
+				messages = append(messages, llm.Message{
+					Role:       "tool",
+					ToolCallID: call.ID,
+					Content:    s.runTool(ctx, call),
+				})
+			}
+			opts := llm.Options{Tools: tools}
+			if round >= maxToolRounds {
+				opts.Tools = nil // force an answer
+			}
+			eventCh, err = s.provider.StreamComplete(ctx, messages, opts)
+			if err != nil {
+				msg := fmt.Sprintf("\n\nThe assistant could not continue after looking up network data: %v", err)
+				fullContent.WriteString(msg)
+				outCh <- llm.StreamEvent{Delta: msg}
+				outCh <- llm.StreamEvent{Done: true, FinishReason: "error"}
 				break
 			}
 		}
@@ -147,13 +199,20 @@ func (s *AIPlanningService) Chat(ctx context.Context, sessionID, userMessage str
 	return outCh, nil
 }
 
-// buildSystemPrompt generates a system prompt with current network context.
+// promptMaxRootPools caps how many top-level pools the system prompt
+// names; the model can search for the rest.
+const promptMaxRootPools = 20
+
+// buildSystemPrompt generates the system prompt: instructions plus a short
+// summary of the top-level pools. Everything else (children, free space,
+// conflicts, compliance, discovered resources) is left to the planner
+// tools so that the prompt stays small on large inventories.
 func (s *AIPlanningService) buildSystemPrompt(ctx context.Context) (string, error) {
 	var sb strings.Builder
 
 	sb.WriteString(`You are CloudPAM's AI network planning assistant. You help design IP address allocation schemes for cloud and on-premises networks.
 
-You have access to the current network topology and can generate structured plans.
+You can look up the current network with tools: search pools and accounts, get the free space in a pool, check proposed CIDRs for conflicts, run compliance checks and list discovered cloud resources. Look up what you need instead of guessing, and check a plan's CIDRs for conflicts before proposing it.
 
 When the user asks you to create a plan, output it as a JSON code block with the following structure:
 ` + "```json" + `
@@ -173,55 +232,24 @@ CIDRs must be valid IPv4 with prefix length between /8 and /30.
 
 `)
 
-	// Add current pool hierarchy context
 	pools, err := s.mainStore.ListPools(ctx)
 	if err == nil && len(pools) > 0 {
-		sb.WriteString("## Current Network Topology\n\n")
-		for _, p := range pools {
-			parentInfo := ""
-			if p.ParentID != nil {
-				parentInfo = fmt.Sprintf(" (parent: %d)", *p.ParentID)
-			}
-			sb.WriteString(fmt.Sprintf("- Pool %d: %s (%s) [%s]%s\n", p.ID, p.Name, p.CIDR, p.Type, parentInfo))
-		}
-		sb.WriteString("\n")
-	}
-
-	// Add gap analysis for top-level pools
-	if len(pools) > 0 {
-		var topLevelIDs []int64
+		var roots []domain.Pool
 		for _, p := range pools {
 			if p.ParentID == nil {
-				topLevelIDs = append(topLevelIDs, p.ID)
-			}
-		}
-		if len(topLevelIDs) > 0 {
-			sb.WriteString("## Available Address Space\n\n")
-			for _, id := range topLevelIDs {
-				gap, err := s.analysis.AnalyzeGaps(ctx, id)
-				if err == nil {
-					sb.WriteString(fmt.Sprintf("- Pool %d: %d total addresses, %d used, %d available (%.1f%% utilized)\n",
-						id, gap.TotalAddresses, gap.UsedAddresses,
-						gap.TotalAddresses-gap.UsedAddresses,
-						float64(gap.UsedAddresses)/float64(gap.TotalAddresses)*100))
-				}
+				roots = append(roots, p)
 			}
-			sb.WriteString("\n")
-		}
-
-		// Add compliance context
-		allIDs := make([]int64, len(pools))
-		for i, p := range pools {
-			allIDs[i] = p.ID
 		}
-		compliance, err := s.analysis.CheckCompliance(ctx, allIDs, false)
-		if err == nil && compliance != nil && len(compliance.Violations) > 0 {
-			sb.WriteString("## Current Compliance Issues\n\n")
-			for _, v := range compliance.Violations {
-				sb.WriteString(fmt.Sprintf("- [%s] %s: %s\n", v.Severity, v.RuleID, v.Message))
+		sb.WriteString("## Network Summary\n\n")
+		sb.WriteString(fmt.Sprintf("%d pools, %d top-level:\n", len(pools), len(roots)))
+		for i, p := range roots {
+			if i == promptMaxRootPools {
+				sb.WriteString(fmt.Sprintf("- ... and %d more (use search_pools)\n", len(roots)-i))
+				break
 			}
-			sb.WriteString("\n")
+			sb.WriteString(fmt.Sprintf("- Pool %d: %s (%s) [%s]\n", p.ID, p.Name, p.CIDR, p.Type))
 		}
+		sb.WriteString("\n")
 	}
 
 	sb.WriteString("Help the user plan their network infrastructure. Be concise and practical.\n")
@@ -346,16 +346,13 @@ func TestCovAIServiceChatSystemPromptDescribesInstructions(t *testing.T) {
 			t.Errorf("system prompt missing %q:\n%s", want, prompt)
 		}
 	}
-	// With no pools, the context sections must be omitted.
-	if strings.Contains(prompt, "## Current Network Topology") {
-		t.Error("empty store should not produce a topology section")
-	}
-	if strings.Contains(prompt, "## Available Address Space") {
-		t.Error("empty store should not produce an address space section")
+	// With no pools, the summary section must be omitted.
+	if strings.Contains(prompt, "## Network Summary") {
+		t.Error("empty store should not produce a summary section")
 	}
 }
 
-func TestCovAIServiceChatSystemPromptIncludesNetworkContext(t *testing.T) {
+func TestCovAIServiceChatSystemPromptSummarizesTopLevelPools(t *testing.T) {
 	ctx := context.Background()
 	provider := &covFakeProvider{available: true, events: []llm.StreamEvent{{Delta: "ok", Done: true}}}
 	svc, st, _ := covSetupAIService(t, provider)
@@ -367,10 +364,9 @@ func TestCovAIServiceChatSystemPromptIncludesNetworkContext(t *testing.T) {
 		t.Fatalf("CreatePool() error = %v", err)
 	}
 	parentID := parent.ID
-	child, err := st.CreatePool(ctx, domain.CreatePool{
+	if _, err := st.CreatePool(ctx, domain.CreatePool{
 		Name: "Prod", CIDR: "10.0.1.0/24", ParentID: &parentID, Type: domain.PoolTypeSubnet, Description: "prod",
-	})
-	if err != nil {
+	}); err != nil {
 		t.Fatalf("CreatePool() error = %v", err)
 	}
 
@@ -382,28 +378,21 @@ func TestCovAIServiceChatSystemPromptIncludesNetworkContext(t *testing.T) {
 	covDrain(t, ch)
 
 	prompt := provider.messages()[0].Content
-	if !strings.Contains(prompt, "## Current Network Topology") {
-		t.Fatalf("prompt missing topology section:\n%s", prompt)
+	if !strings.Contains(prompt, "## Network Summary") {
+		t.Fatalf("prompt missing summary section:\n%s", prompt)
 	}
-	if !strings.Contains(prompt, "Corp (10.0.0.0/16)") {
-		t.Errorf("prompt missing the parent pool:\n%s", prompt)
-	}
-	if !strings.Contains(prompt, "Prod (10.0.1.0/24)") {
-		t.Errorf("prompt missing the child pool:\n%s", prompt)
-	}
-	if !strings.Contains(prompt, "(parent: ") {
-		t.Errorf("prompt missing parent annotation for the child pool:\n%s", prompt)
+	if !strings.Contains(prompt, "2 pools, 1 top-level") {
+		t.Errorf("prompt missing pool counts:\n%s", prompt)
 	}
-	if !strings.Contains(prompt, "## Available Address Space") {
-		t.Errorf("prompt missing gap analysis section:\n%s", prompt)
+	if !strings.Contains(prompt, "Corp (10.0.0.0/16)") {
+		t.Errorf("prompt missing the top-level pool:\n%s", prompt)
 	}
-	if !strings.Contains(prompt, "% utilized") {
-		t.Errorf("prompt missing utilisation summary:\n%s", prompt)
+	if strings.Contains(prompt, "Prod (10.0.1.0/24)") {
+		t.Errorf("child pools are left to the tools, but the prompt lists one:\n%s", prompt)
 	}
-	_ = child
 }
 
-func TestCovAIServiceChatSystemPromptIncludesComplianceViolations(t *testing.T) {
+func TestCovAIServiceChatLeavesDetailToTools(t *testing.T) {
 	ctx := context.Background()
 	provider := &covFakeProvider{available: true, events: []llm.StreamEvent{{Delta: "ok", Done: true}}}
 	svc, st, _ := covSetupAIService(t, provider)
@@ -423,11 +412,19 @@ func TestCovAIServiceChatSystemPromptIncludesComplianceViolations(t *testing.T)
 	covDrain(t, ch)
 
 	prompt := provider.messages()[0].Content
-	if !strings.Contains(prompt, "## Current Compliance Issues") {
-		t.Fatalf("prompt missing compliance section:\n%s", prompt)
-	}
-	if !strings.Contains(prompt, "RFC1918-001") {
-		t.Errorf("prompt missing the RFC1918 violation:\n%s", prompt)
+	if strings.Contains(prompt, "RFC1918-001") || strings.Contains(prompt, "% utilized") {
+		t.Errorf("prompt should not embed compliance or gap analysis:\n%s", prompt)
+	}
+	provider.mu.Lock()
+	tools := provider.gotOpts.Tools
+	provider.mu.Unlock()
+	var names []string
+	for _, tool := range tools {
+		names = append(names, tool.Name)
+	}
+	want := "search_pools get_pool_gaps check_schema_conflicts check_compliance"
+	if got := strings.Join(names, " "); got != want {
+		t.Errorf("offered tools = %q, want %q (no discovery store configured)", got, want)
 	}
 }
 
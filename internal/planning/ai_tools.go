@@ -0,0 +1,286 @@
+package planning
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/planning/llm"
+)
+
+// Tools the planning assistant can call to look up network state on
+// demand rather than receiving all of it in the system prompt.
+const (
+	toolSearchPools             = "search_pools"
+	toolGetPoolGaps             = "get_pool_gaps"
+	toolCheckSchemaConflicts    = "check_schema_conflicts"
+	toolCheckCompliance         = "check_compliance"
+	toolListDiscoveredResources = "list_discovered_resources"
+)
+
+// Limits on how much a single tool result returns, so that one call cannot
+// rebuild the oversized prompt the tools replace.
+const (
+	toolMaxSearchResults  = 25
+	toolMaxGapBlocks      = 20
+	toolMaxViolations     = 50
+	toolMaxDiscoveredRows = 50
+)
+
+// maxToolRounds bounds how many rounds of tool calls one chat turn may make
+// before the model is asked to answer without tools.
+const maxToolRounds = 5
+
+// plannerTools returns the tools offered to the model. Discovered resources
+// are only offered when a discovery store is configured.
+func (s *AIPlanningService) plannerTools() []llm.Tool {
+	tools := []llm.Tool{
+		{
+			Name:        toolSearchPools,
+			Description: "Search pools by name, CIDR or description, or find pools containing or within a prefix. Set types to [\"account\"] to look up cloud accounts instead.",
+			Parameters: json.RawMessage(`{"type":"object","properties":{
+"query":{"type":"string","description":"Free text matched against name, CIDR, key and description"},
+"cidr_contains":{"type":"string","description":"IP or prefix the pool must contain"},
+"cidr_within":{"type":"string","description":"Prefix the pool must lie within"},
+"types":{"type":"array","items":{"type":"string","enum":["pool","account"]}}}}`),
+		},
+		{
+			Name:        toolGetPoolGaps,
+			Description: "Get utilization and the free CIDR blocks inside a pool.",
+			Parameters: json.RawMessage(`{"type":"object","properties":{
+"pool_id":{"type":"integer"}},"required":["pool_id"]}`),
+		},
+		{
+			Name:        toolCheckSchemaConflicts,
+			Description: "Check proposed CIDRs against existing pools and report every overlap.",
+			Parameters: json.RawMessage(`{"type":"object","properties":{
+"pools":{"type":"array","items":{"type":"object","properties":{
+"name":{"type":"string"},"cidr":{"type":"string"}},"required":["cidr"]}}},"required":["pools"]}`),
+		},
+		{
+			Name:        toolCheckCompliance,
+			Description: "Run compliance checks on pools and their children.",
+			Parameters: json.RawMessage(`{"type":"object","properties":{
+"pool_ids":{"type":"array","items":{"type":"integer"}}},"required":["pool_ids"]}`),
+		},
+	}
+	if s.discovery != nil {
+		tools = append(tools, llm.Tool{
+			Name:        toolListDiscoveredResources,
+			Description: "List cloud resources (VPCs, subnets, ...) discovered in an account.",
+			Parameters: json.RawMessage(`{"type":"object","properties":{
+"account_id":{"type":"integer"},
+"resource_type":{"type":"string","description":"Optional filter such as vpc or subnet"},
+"region":{"type":"string"}},"required":["account_id"]}`),
+		})
+	}
+	return tools
+}
+
+// runTool executes a tool call and returns its JSON result. Failures are
+// reported to the model as {"error": "..."} so it can correct itself
+// rather than ending the turn.
+func (s *AIPlanningService) runTool(ctx context.Context, call llm.ToolCall) string {
+	var (
+		result any
+		err    error
+	)
+	args := []byte(call.Arguments)
+	if len(args) == 0 {
+		args = []byte("{}")
+	}
+	switch call.Name {
+	case toolSearchPools:
+		result, err = s.toolSearchPools(ctx, args)
+	case toolGetPoolGaps:
+		result, err = s.toolGetPoolGaps(ctx, args)
+	case toolCheckSchemaConflicts:
+		result, err = s.toolCheckSchemaConflicts(ctx, args)
+	case toolCheckCompliance:
+		result, err = s.toolCheckCompliance(ctx, args)
+	case toolListDiscoveredResources:
+		result, err = s.toolListDiscoveredResources(ctx, args)
+	default:
+		err = fmt.Errorf("unknown tool %q", call.Name)
+	}
+	if err != nil {
+		result = map[string]string{"error": err.Error()}
+	}
+	out, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Sprintf(`{"error":%q}`, err.Error())
+	}
+	return string(out)
+}
+
+func (s *AIPlanningService) toolSearchPools(ctx context.Context, args []byte) (any, error) {
+	var in struct {
+		Query        string   `json:"query"`
+		CIDRContains string   `json:"cidr_contains"`
+		CIDRWithin   string   `json:"cidr_within"`
+		Types        []string `json:"types"`
+	}
+	if err := json.Unmarshal(args, &in); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+	if len(in.Types) == 0 {
+		in.Types = []string{"pool"}
+	}
+	resp, err := s.mainStore.Search(ctx, domain.SearchRequest{
+		Query:        in.Query,
+		CIDRContains: in.CIDRContains,
+		CIDRWithin:   in.CIDRWithin,
+		Types:        in.Types,
+		PageSize:     toolMaxSearchResults,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"total": resp.Total, "items": resp.Items}, nil
+}
+
+func (s *AIPlanningService) toolGetPoolGaps(ctx context.Context, args []byte) (any, error) {
+	var in struct {
+		PoolID int64 `json:"pool_id"`
+	}
+	if err := json.Unmarshal(args, &in); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+	gap, err := s.analysis.AnalyzeGaps(ctx, in.PoolID)
+	if err != nil {
+		return nil, err
+	}
+	out := *gap
+	if len(out.AvailableBlocks) > toolMaxGapBlocks {
+		out.AvailableBlocks = out.AvailableBlocks[:toolMaxGapBlocks]
+	}
+	return map[string]any{
+		"gaps":                   out,
+		"available_blocks_total": len(gap.AvailableBlocks),
+	}, nil
+}
+
+// schemaToolConflict mirrors the conflict shape of POST /api/v1/schema/check.
+type schemaToolConflict struct {
+	PlannedCIDR      string `json:"planned_cidr"`
+	PlannedName      string `json:"planned_name,omitempty"`
+	ExistingPoolID   int64  `json:"existing_pool_id"`
+	ExistingPoolName string `json:"existing_pool_name"`
+	ExistingCIDR     string `json:"existing_cidr"`
+	OverlapType      string `json:"overlap_type"` // overlap, contains, contained_by
+}
+
+func (s *AIPlanningService) toolCheckSchemaConflicts(ctx context.Context, args []byte) (any, error) {
+	var in struct {
+		Pools []struct {
+			Name string `json:"name"`
+			CIDR string `json:"cidr"`
+		} `json:"pools"`
+	}
+	if err := json.Unmarshal(args, &in); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+	if len(in.Pools) == 0 {
+		return nil, fmt.Errorf("pools is required")
+	}
+	existing, err := s.mainStore.ListPools(ctx)
+	if err != nil {
+		return nil, err
+	}
+	conflicts := []schemaToolConflict{}
+	for _, proposed := range in.Pools {
+		pp, err := netip.ParsePrefix(proposed.CIDR)
+		if err != nil {
+			return nil, fmt.Errorf("pool %q: invalid cidr %q", proposed.Name, proposed.CIDR)
+		}
+		pp = pp.Masked()
+		for _, ex := range existing {
+			ep, err := netip.ParsePrefix(ex.CIDR)
+			if err != nil || !prefixesOverlap(pp, ep) {
+				continue
+			}
+			ep = ep.Masked()
+			overlapType := "overlap"
+			if pp.Bits() <= ep.Bits() && pp.Contains(ep.Addr()) {
+				overlapType = "contains"
+			} else if ep.Bits() <= pp.Bits() && ep.Contains(pp.Addr()) {
+				overlapType = "contained_by"
+			}
+			conflicts = append(conflicts, schemaToolConflict{
+				PlannedCIDR:      proposed.CIDR,
+				PlannedName:      proposed.Name,
+				ExistingPoolID:   ex.ID,
+				ExistingPoolName: ex.Name,
+				ExistingCIDR:     ex.CIDR,
+				OverlapType:      overlapType,
+			})
+		}
+	}
+	return map[string]any{"conflict_count": len(conflicts), "conflicts": conflicts}, nil
+}
+
+func (s *AIPlanningService) toolCheckCompliance(ctx context.Context, args []byte) (any, error) {
+	var in struct {
+		PoolIDs []int64 `json:"pool_ids"`
+	}
+	if err := json.Unmarshal(args, &in); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+	if len(in.PoolIDs) == 0 {
+		return nil, fmt.Errorf("pool_ids is required")
+	}
+	report, err := s.analysis.CheckCompliance(ctx, in.PoolIDs, true)
+	if err != nil {
+		return nil, err
+	}
+	violations := report.Violations
+	if len(violations) > toolMaxViolations {
+		violations = violations[:toolMaxViolations]
+	}
+	return map[string]any{
+		"violation_count": len(report.Violations),
+		"violations":      violations,
+	}, nil
+}
+
+func (s *AIPlanningService) toolListDiscoveredResources(ctx context.Context, args []byte) (any, error) {
+	if s.discovery == nil {
+		return nil, fmt.Errorf("discovery is not configured")
+	}
+	var in struct {
+		AccountID    int64  `json:"account_id"`
+		ResourceType string `json:"resource_type"`
+		Region       string `json:"region"`
+	}
+	if err := json.Unmarshal(args, &in); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %w", err)
+	}
+	items, total, err := s.discovery.ListDiscoveredResources(ctx, in.AccountID, domain.DiscoveryFilters{
+		ResourceType: in.ResourceType,
+		Region:       in.Region,
+		PageSize:     toolMaxDiscoveredRows,
+	})
+	if err != nil {
+		return nil, err
+	}
+	type resource struct {
+		Type             domain.CloudResourceType `json:"resource_type"`
+		ResourceID       string                   `json:"resource_id"`
+		Name             string                   `json:"name,omitempty"`
+		Region           string                   `json:"region"`
+		CIDR             string                   `json:"cidr,omitempty"`
+		PoolID           *int64                   `json:"pool_id,omitempty"`
+		Status           domain.DiscoveryStatus   `json:"status"`
+		ParentResourceID *string                  `json:"parent_resource_id,omitempty"`
+	}
+	out := make([]resource, len(items))
+	for i, r := range items {
+		out[i] = resource{
+			Type: r.ResourceType, ResourceID: r.ResourceID, Name: r.Name, Region: r.Region,
+			CIDR: r.CIDR, PoolID: r.PoolID, Status: r.Status, ParentResourceID: r.ParentResourceID,
+		}
+	}
+	return map[string]any{"total": total, "items": out}, nil
+}
@@ -0,0 +1,210 @@
+package planning
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/planning/llm"
+	"cloudpam/internal/storage"
+)
+
+func TestAIServiceChatRunsToolCalls(t *testing.T) {
+	ctx := context.Background()
+	provider := llm.NewScriptedProvider(
+		llm.Response{ToolCalls: []llm.ToolCall{
+			{ID: "call-1", Name: toolGetPoolGaps, Arguments: `{"pool_id":1}`},
+			{ID: "call-2", Name: toolSearchPools, Arguments: `{"query":"prod"}`},
+		}},
+		llm.Response{Content: "Use 10.0.2.0/24."},
+	)
+	svc, st, _ := covSetupAIService(t, provider)
+	root, _ := st.CreatePool(ctx, domain.CreatePool{Name: "Corp", CIDR: "10.0.0.0/16", Type: domain.PoolTypeSupernet})
+	_, _ = st.CreatePool(ctx, domain.CreatePool{Name: "prod-a", CIDR: "10.0.1.0/24", ParentID: &root.ID, Type: domain.PoolTypeSubnet})
+
+	conv, _ := svc.CreateConversation(ctx, "session")
+	ch, err := svc.Chat(ctx, conv.ID, "where can prod grow?")
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	events := covDrain(t, ch)
+	if len(events) != 2 || events[0].Delta != "Use 10.0.2.0/24." || !events[1].Done {
+		t.Fatalf("events = %+v, want the answer and a single Done", events)
+	}
+
+	calls := provider.Calls()
+	if len(calls) != 2 {
+		t.Fatalf("provider calls = %d, want 2", len(calls))
+	}
+	msgs := calls[1].Messages
+	if len(msgs) != 5 {
+		t.Fatalf("second request messages = %d, want system, user, assistant and two tool results", len(msgs))
+	}
+	if msgs[2].Role != "assistant" || len(msgs[2].ToolCalls) != 2 {
+		t.Errorf("assistant message = %+v, want the tool calls echoed", msgs[2])
+	}
+	if msgs[3].Role != "tool" || msgs[3].ToolCallID != "call-1" || !strings.Contains(msgs[3].Content, `"available_blocks"`) {
+		t.Errorf("gap result = %+v", msgs[3])
+	}
+	if msgs[4].ToolCallID != "call-2" || !strings.Contains(msgs[4].Content, "prod-a") {
+		t.Errorf("search result = %+v", msgs[4])
+	}
+	if len(calls[1].Options.Tools) == 0 {
+		t.Error("tools should stay available after the first round")
+	}
+
+	stored, _ := svc.GetConversation(ctx, conv.ID)
+	if len(stored.Messages) != 2 || stored.Messages[1].Content != "Use 10.0.2.0/24." {
+		t.Fatalf("stored messages = %+v, want only the user message and the answer", stored.Messages)
+	}
+}
+
+func TestAIServiceChatForcesAnswerAfterMaxToolRounds(t *testing.T) {
+	ctx := context.Background()
+	var turns []llm.Response
+	for i := 0; i < maxToolRounds; i++ {
+		turns = append(turns, llm.Response{ToolCalls: []llm.ToolCall{
+			{ID: fmt.Sprintf("call-%d", i), Name: toolSearchPools, Arguments: `{}`},
+		}})
+	}
+	turns = append(turns, llm.Response{Content: "done"})
+	provider := llm.NewScriptedProvider(turns...)
+	svc, _, _ := covSetupAIService(t, provider)
+
+	conv, _ := svc.CreateConversation(ctx, "session")
+	ch, err := svc.Chat(ctx, conv.ID, "loop")
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	covDrain(t, ch)
+
+	calls := provider.Calls()
+	if len(calls) != maxToolRounds+1 {
+		t.Fatalf("provider calls = %d, want %d", len(calls), maxToolRounds+1)
+	}
+	if last := calls[len(calls)-1]; last.Options.Tools != nil {
+		t.Error("the final request must not offer tools")
+	}
+}
+
+func TestAIServiceChatReportsFailureAfterToolRound(t *testing.T) {
+	ctx := context.Background()
+	// The script ends after the tool call, so the follow-up request fails.
+	provider := llm.NewScriptedProvider(llm.Response{
+		Content:   "Checking. ",
+		ToolCalls: []llm.ToolCall{{ID: "c", Name: toolSearchPools, Arguments: `{}`}},
+	})
+	svc, _, _ := covSetupAIService(t, provider)
+
+	conv, _ := svc.CreateConversation(ctx, "session")
+	ch, err := svc.Chat(ctx, conv.ID, "hi")
+	if err != nil {
+		t.Fatalf("Chat() error = %v", err)
+	}
+	events := covDrain(t, ch)
+	last := events[len(events)-1]
+	if !last.Done || last.FinishReason != "error" {
+		t.Fatalf("final event = %+v, want Done with finish reason error", last)
+	}
+
+	stored, _ := svc.GetConversation(ctx, conv.ID)
+	content := stored.Messages[1].Content
+	if !strings.HasPrefix(content, "Checking. ") || !strings.Contains(content, "no turns left") {
+		t.Errorf("assistant content = %q, want the partial text and the failure", content)
+	}
+}
+
+func TestRunTool(t *testing.T) {
+	ctx := context.Background()
+	svc, st, _ := covSetupAIService(t, &covFakeProvider{available: true})
+	root, _ := st.CreatePool(ctx, domain.CreatePool{Name: "Corp", CIDR: "10.0.0.0/16", Type: domain.PoolTypeSupernet})
+	_, _ = st.CreatePool(ctx, domain.CreatePool{Name: "Public", CIDR: "8.8.8.0/24", Type: domain.PoolTypeSubnet})
+
+	run := func(name, args string) map[string]any {
+		t.Helper()
+		var out map[string]any
+		res := svc.runTool(ctx, llm.ToolCall{ID: "x", Name: name, Arguments: args})
+		if err := json.Unmarshal([]byte(res), &out); err != nil {
+			t.Fatalf("%s result is not JSON: %v: %s", name, err, res)
+		}
+		return out
+	}
+
+	conflicts := run(toolCheckSchemaConflicts, `{"pools":[{"name":"a","cidr":"10.0.4.0/24"},{"name":"b","cidr":"10.0.0.0/8"},{"name":"c","cidr":"192.168.0.0/24"}]}`)
+	if conflicts["conflict_count"] != float64(2) {
+		t.Fatalf("conflicts = %v, want 2", conflicts)
+	}
+	items := conflicts["conflicts"].([]any)
+	if items[0].(map[string]any)["overlap_type"] != "contained_by" || items[1].(map[string]any)["overlap_type"] != "contains" {
+		t.Errorf("overlap types = %v", items)
+	}
+
+	compliance := run(toolCheckCompliance, fmt.Sprintf(`{"pool_ids":[%d]}`, root.ID))
+	if _, ok := compliance["violations"]; !ok {
+		t.Errorf("compliance = %v", compliance)
+	}
+
+	for _, tc := range []struct{ name, args, want string }{
+		{toolGetPoolGaps, `{"pool_id":999}`, "not found"},
+		{toolGetPoolGaps, `{"pool_id":"one"}`, "invalid arguments"},
+		{toolCheckSchemaConflicts, `{"pools":[]}`, "pools is required"},
+		{toolCheckSchemaConflicts, `{"pools":[{"cidr":"bogus"}]}`, "invalid cidr"},
+		{toolListDiscoveredResources, `{"account_id":1}`, "discovery is not configured"},
+		{"drop_tables", `{}`, "unknown tool"},
+	} {
+		out := run(tc.name, tc.args)
+		if msg, _ := out["error"].(string); !strings.Contains(msg, tc.want) {
+			t.Errorf("%s(%s) = %v, want error containing %q", tc.name, tc.args, out, tc.want)
+		}
+	}
+}
+
+func TestRunToolListDiscoveredResources(t *testing.T) {
+	ctx := context.Background()
+	svc, st, _ := covSetupAIService(t, &covFakeProvider{available: true})
+	disc := storage.NewMemoryDiscoveryStore(st)
+	svc.SetDiscoveryStore(disc)
+
+	acct, _ := st.CreateAccount(ctx, domain.CreateAccount{Key: "aws:1", Name: "prod"})
+	for i, typ := range []domain.CloudResourceType{domain.ResourceTypeVPC, domain.ResourceTypeSubnet} {
+		if err := disc.UpsertDiscoveredResource(ctx, domain.DiscoveredResource{
+			ID: uuid.New(), AccountID: acct.ID, Provider: "aws", Region: "us-east-1",
+			ResourceType: typ, ResourceID: fmt.Sprintf("res-%d", i), CIDR: fmt.Sprintf("10.%d.0.0/16", i),
+			Status: domain.DiscoveryStatusActive, DiscoveredAt: time.Now(), LastSeenAt: time.Now(),
+		}); err != nil {
+			t.Fatalf("upsert: %v", err)
+		}
+	}
+
+	found := false
+	for _, tool := range svc.plannerTools() {
+		found = found || tool.Name == toolListDiscoveredResources
+	}
+	if !found {
+		t.Fatal("list_discovered_resources should be offered once a discovery store is set")
+	}
+
+	res := svc.runTool(ctx, llm.ToolCall{
+		Name:      toolListDiscoveredResources,
+		Arguments: fmt.Sprintf(`{"account_id":%d,"resource_type":"subnet"}`, acct.ID),
+	})
+	var out struct {
+		Total int `json:"total"`
+		Items []struct {
+			ResourceID string `json:"resource_id"`
+			CIDR       string `json:"cidr"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(res), &out); err != nil {
+		t.Fatalf("unmarshal: %v: %s", err, res)
+	}
+	if out.Total != 1 || len(out.Items) != 1 || out.Items[0].ResourceID != "res-1" {
+		t.Fatalf("result = %s", res)
+	}
+}
@@ -0,0 +1,419 @@
+package planning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/notify"
+	"cloudpam/internal/storage"
+)
+
+// AlertForecastLookback is the snapshot history exhaustion alerts forecast
+// from.
+const AlertForecastLookback = 90 * day
+
+// alertActorSystem resolves alerts whose condition cleared.
+const alertActorSystem = "system"
+
+// AlertService evaluates capacity alert rules against pool statistics and
+// drives the open → acknowledged → resolved lifecycle, notifying channels
+// when an alert opens, escalates or resolves.
+type AlertService struct {
+	store       storage.Store
+	settings    storage.SettingsStore
+	alerts      storage.AlertStore
+	notifiers   *notify.Registry
+	utilization *UtilizationService
+	logger      *slog.Logger
+	now         func() time.Time
+
+	// mu serializes evaluations so overlapping runs cannot open the same
+	// alert twice.
+	mu sync.Mutex
+}
+
+// NewAlertService creates a new AlertService.
+func NewAlertService(store storage.Store, settings storage.SettingsStore, alerts storage.AlertStore, notifiers *notify.Registry) *AlertService {
+	return &AlertService{
+		store:     store,
+		settings:  settings,
+		alerts:    alerts,
+		notifiers: notifiers,
+		logger:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+		now:       func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// SetUtilization enables exhaustion alerts, which need forecasts built from
+// utilization snapshots. Without it rules' exhaustion_days are ignored.
+func (s *AlertService) SetUtilization(u *UtilizationService) {
+	s.utilization = u
+}
+
+// SetLogger sets the logger for notification failures.
+func (s *AlertService) SetLogger(l *slog.Logger) {
+	if l != nil {
+		s.logger = l
+	}
+}
+
+// alertKey identifies the condition an active alert tracks.
+type alertKey struct {
+	ruleID string
+	poolID int64
+	kind   domain.AlertKind
+}
+
+// Evaluate checks every pool against its most specific enabled rule. New
+// breaches open alerts; a warning that becomes critical escalates its alert
+// and re-opens it if it was acknowledged; active alerts whose condition has
+// cleared, or whose rule no longer applies, are resolved. A pool whose
+// statistics cannot be computed keeps its alerts unchanged; such failures
+// are listed in the summary and also returned joined as the error.
+func (s *AlertService) Evaluate(ctx context.Context) (*domain.AlertEvaluationResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	settings, err := s.settings.GetAlertSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pools, err := s.store.ListPools(ctx)
+	if err != nil {
+		return nil, err
+	}
+	active, err := s.alerts.ListActiveAlerts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[alertKey]domain.Alert, len(active))
+	for _, a := range active {
+		existing[alertKey{a.RuleID, a.PoolID, a.Kind}] = a
+	}
+
+	now := s.now()
+	resp := &domain.AlertEvaluationResponse{}
+	seen := make(map[alertKey]bool)
+	skipped := make(map[int64]bool)
+	var errs []error
+	for _, pool := range pools {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		rule := matchAlertRule(settings.Rules, pool)
+		if rule == nil {
+			continue
+		}
+		conditions, err := s.conditions(ctx, *rule, pool)
+		if errors.Is(err, storage.ErrNotFound) {
+			continue // deleted since it was listed
+		}
+		if err != nil {
+			skipped[pool.ID] = true
+			errs = append(errs, fmt.Errorf("pool %d: %w", pool.ID, err))
+			continue
+		}
+		resp.EvaluatedPools++
+		for _, c := range conditions {
+			key := alertKey{rule.ID, pool.ID, c.Kind}
+			seen[key] = true
+			prev, ok := existing[key]
+			if !ok {
+				c.ID = uuid.NewString()
+				c.Status = domain.AlertStatusOpen
+				c.OpenedAt, c.UpdatedAt = now, now
+				if err := s.alerts.CreateAlert(ctx, c); err != nil {
+					errs = append(errs, fmt.Errorf("pool %d: %w", pool.ID, err))
+					continue
+				}
+				resp.Opened++
+				s.notify(ctx, settings, rule, notify.EventAlertOpened, c)
+				continue
+			}
+			escalated := prev.Severity == domain.AlertSeverityWarning && c.Severity == domain.AlertSeverityCritical
+			if !escalated && prev.Severity == c.Severity && prev.Value == c.Value && prev.Threshold == c.Threshold {
+				continue
+			}
+			prev.Severity, prev.Value, prev.Threshold, prev.Message = c.Severity, c.Value, c.Threshold, c.Message
+			prev.UpdatedAt = now
+			if escalated {
+				prev.Status = domain.AlertStatusOpen
+				prev.AcknowledgedAt, prev.AcknowledgedBy = nil, ""
+			}
+			if err := s.alerts.UpdateAlert(ctx, prev); err != nil {
+				errs = append(errs, fmt.Errorf("alert %s: %w", prev.ID, err))
+				continue
+			}
+			if escalated {
+				resp.Escalated++
+				s.notify(ctx, settings, rule, notify.EventAlertEscalated, prev)
+			}
+		}
+	}
+
+	for key, a := range existing {
+		if seen[key] || skipped[a.PoolID] {
+			continue
+		}
+		if err := s.resolve(ctx, settings, a, alertActorSystem, now); err != nil {
+			errs = append(errs, fmt.Errorf("alert %s: %w", a.ID, err))
+			continue
+		}
+		resp.Resolved++
+	}
+
+	remaining, err := s.alerts.ListActiveAlerts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp.Active = len(remaining)
+	for _, err := range errs {
+		resp.Errors = append(resp.Errors, err.Error())
+	}
+	return resp, errors.Join(errs...)
+}
+
+// Acknowledge marks an open alert as acknowledged by actor. The alert stays
+// active and re-opens if it escalates.
+func (s *AlertService) Acknowledge(ctx context.Context, id, actor string) (*domain.Alert, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, err := s.alerts.GetAlert(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if a.Status != domain.AlertStatusOpen {
+		return nil, fmt.Errorf("alert %s is %s, not open: %w", id, a.Status, storage.ErrConflict)
+	}
+	now := s.now()
+	a.Status = domain.AlertStatusAcknowledged
+	a.AcknowledgedAt, a.AcknowledgedBy = &now, actor
+	a.UpdatedAt = now
+	if err := s.alerts.UpdateAlert(ctx, *a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Resolve closes an active alert on behalf of actor. If the condition still
+// holds, the next evaluation opens a new alert.
+func (s *AlertService) Resolve(ctx context.Context, id, actor string) (*domain.Alert, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, err := s.alerts.GetAlert(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !a.Active() {
+		return nil, fmt.Errorf("alert %s is already resolved: %w", id, storage.ErrConflict)
+	}
+	settings, err := s.settings.GetAlertSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.resolve(ctx, settings, *a, actor, s.now()); err != nil {
+		return nil, err
+	}
+	return s.alerts.GetAlert(ctx, id)
+}
+
+// Settings returns the alert rules and channels.
+func (s *AlertService) Settings(ctx context.Context) (*domain.AlertSettings, error) {
+	return s.settings.GetAlertSettings(ctx)
+}
+
+// UpdateSettings validates and saves the alert rules and channels. Invalid
+// settings return an error wrapping storage.ErrValidation.
+func (s *AlertService) UpdateSettings(ctx context.Context, settings *domain.AlertSettings) (*domain.AlertSettings, error) {
+	settings = domain.NormalizeAlertSettings(settings)
+	if msg := domain.ValidateAlertSettings(settings); msg != "" {
+		return nil, fmt.Errorf("%s: %w", msg, storage.ErrValidation)
+	}
+	for _, ch := range settings.Channels {
+		if _, err := s.notifiers.Notifier(ch); err != nil {
+			return nil, fmt.Errorf("%v: %w", err, storage.ErrValidation)
+		}
+	}
+	if err := s.settings.UpdateAlertSettings(ctx, settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// TestChannel sends a sample notification through a configured channel,
+// enabled or not, and returns the delivery error if any.
+func (s *AlertService) TestChannel(ctx context.Context, channelID string) error {
+	settings, err := s.settings.GetAlertSettings(ctx)
+	if err != nil {
+		return err
+	}
+	for _, ch := range settings.Channels {
+		if ch.ID != channelID {
+			continue
+		}
+		n, err := s.notifiers.Notifier(ch)
+		if err != nil {
+			return fmt.Errorf("%v: %w", err, storage.ErrValidation)
+		}
+		now := s.now()
+		return n.Notify(ctx, notify.Notification{
+			Event: notify.EventTest,
+			Alert: domain.Alert{
+				ID: "test", RuleID: "test", PoolName: "example", PoolCIDR: "10.0.0.0/24",
+				Kind: domain.AlertKindUtilization, Severity: domain.AlertSeverityWarning, Status: domain.AlertStatusOpen,
+				Value: 85, Threshold: 80, OpenedAt: now, UpdatedAt: now,
+				Message: fmt.Sprintf("Test notification for channel %s", ch.Name),
+			},
+			SentAt: now,
+		})
+	}
+	return fmt.Errorf("channel %s: %w", channelID, storage.ErrNotFound)
+}
+
+// conditions returns the alerts a pool currently breaches under rule, with
+// only the rule, pool, kind, severity, value, threshold and message set.
+func (s *AlertService) conditions(ctx context.Context, rule domain.AlertRule, pool domain.Pool) ([]domain.Alert, error) {
+	stats, err := s.store.CalculatePoolUtilization(ctx, pool.ID)
+	if err != nil {
+		return nil, err
+	}
+	base := domain.Alert{RuleID: rule.ID, PoolID: pool.ID, PoolName: pool.Name, PoolCIDR: pool.CIDR}
+
+	var out []domain.Alert
+	util := round2(stats.Utilization)
+	switch {
+	case rule.CriticalPercent > 0 && util >= rule.CriticalPercent:
+		out = append(out, utilizationAlert(base, domain.AlertSeverityCritical, util, rule.CriticalPercent))
+	case rule.WarningPercent > 0 && util >= rule.WarningPercent:
+		out = append(out, utilizationAlert(base, domain.AlertSeverityWarning, util, rule.WarningPercent))
+	}
+
+	if rule.ExhaustionDays > 0 && s.utilization != nil {
+		f, err := s.utilization.forecast(ctx, pool, AlertForecastLookback, s.now())
+		if err != nil {
+			return nil, err
+		}
+		limit := float64(rule.ExhaustionDays)
+		if f.DaysToExhaustion != nil && *f.DaysToExhaustion <= limit {
+			days := max(*f.DaysToExhaustion, 0)
+			severity := domain.AlertSeverityWarning
+			if days <= limit/2 {
+				severity = domain.AlertSeverityCritical
+			}
+			a := base
+			a.Kind = domain.AlertKindExhaustion
+			a.Severity = severity
+			a.Value, a.Threshold = days, limit
+			a.Message = fmt.Sprintf("Pool %s (%s) is forecast to run out of addresses in %g days (threshold %d days)",
+				pool.Name, pool.CIDR, days, rule.ExhaustionDays)
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+func utilizationAlert(a domain.Alert, severity domain.AlertSeverity, util, threshold float64) domain.Alert {
+	a.Kind = domain.AlertKindUtilization
+	a.Severity = severity
+	a.Value, a.Threshold = util, threshold
+	a.Message = fmt.Sprintf("Pool %s (%s) is %g%% utilized (%s at %g%%)", a.PoolName, a.PoolCIDR, util, severity, threshold)
+	return a
+}
+
+func (s *AlertService) resolve(ctx context.Context, settings *domain.AlertSettings, a domain.Alert, actor string, now time.Time) error {
+	a.Status = domain.AlertStatusResolved
+	a.ResolvedAt, a.ResolvedBy = &now, actor
+	a.UpdatedAt = now
+	if err := s.alerts.UpdateAlert(ctx, a); err != nil {
+		return err
+	}
+	s.notify(ctx, settings, findAlertRule(settings.Rules, a.RuleID), notify.EventAlertResolved, a)
+	return nil
+}
+
+// notify sends a to the rule's channels, or to every enabled channel when
+// the rule names none or no longer exists. Failures are logged; NotifiedAt
+// is recorded when at least one channel accepted the notification.
+func (s *AlertService) notify(ctx context.Context, settings *domain.AlertSettings, rule *domain.AlertRule, event notify.Event, a domain.Alert) {
+	var wanted map[string]bool
+	if rule != nil && len(rule.Channels) > 0 {
+		wanted = make(map[string]bool, len(rule.Channels))
+		for _, id := range rule.Channels {
+			wanted[id] = true
+		}
+	}
+	now := s.now()
+	n := notify.Notification{Event: event, Alert: a, SentAt: now}
+	delivered := false
+	for _, ch := range settings.Channels {
+		if !ch.Enabled || (wanted != nil && !wanted[ch.ID]) {
+			continue
+		}
+		notifier, err := s.notifiers.Notifier(ch)
+		if err == nil {
+			err = notifier.Notify(ctx, n)
+		}
+		if err != nil {
+			s.logger.WarnContext(ctx, "alert notification failed", "alert_id", a.ID, "channel", ch.ID, "event", event, "error", err)
+			continue
+		}
+		delivered = true
+	}
+	if !delivered {
+		return
+	}
+	a.NotifiedAt = &now
+	if err := s.alerts.UpdateAlert(ctx, a); err != nil {
+		s.logger.WarnContext(ctx, "alert notification: record notified_at failed", "alert_id", a.ID, "error", err)
+	}
+}
+
+// matchAlertRule returns the most specific enabled rule for pool: one
+// naming the pool, then one naming its type, then a rule for all pools.
+// Ties go to the rule listed first.
+func matchAlertRule(rules []domain.AlertRule, pool domain.Pool) *domain.AlertRule {
+	var best *domain.AlertRule
+	bestScore := 0
+	for i := range rules {
+		r := &rules[i]
+		if !r.Enabled {
+			continue
+		}
+		var score int
+		switch {
+		case r.PoolID != nil:
+			if *r.PoolID != pool.ID {
+				continue
+			}
+			score = 3
+		case r.PoolType != "":
+			if r.PoolType != pool.Type {
+				continue
+			}
+			score = 2
+		default:
+			score = 1
+		}
+		if score > bestScore {
+			best, bestScore = r, score
+		}
+	}
+	return best
+}
+
+func findAlertRule(rules []domain.AlertRule, id string) *domain.AlertRule {
+	for i := range rules {
+		if rules[i].ID == id {
+			return &rules[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,295 @@
+package planning
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/notify"
+	"cloudpam/internal/storage"
+)
+
+// captureNotifier records notifications per channel.
+type captureNotifier struct {
+	mu   sync.Mutex
+	sent map[string][]notify.Notification
+}
+
+func (c *captureNotifier) factory(ch domain.NotificationChannel) (notify.Notifier, error) {
+	return notifierFunc(func(_ context.Context, n notify.Notification) error {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.sent[ch.ID] = append(c.sent[ch.ID], n)
+		return nil
+	}), nil
+}
+
+func (c *captureNotifier) events(channel string) []notify.Event {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var out []notify.Event
+	for _, n := range c.sent[channel] {
+		out = append(out, n.Event)
+	}
+	return out
+}
+
+type notifierFunc func(ctx context.Context, n notify.Notification) error
+
+func (f notifierFunc) Notify(ctx context.Context, n notify.Notification) error { return f(ctx, n) }
+
+const captureChannel domain.NotificationChannelType = "capture"
+
+func newAlertTestService(t *testing.T, settings domain.AlertSettings) (*AlertService, *storage.MemoryStore, *captureNotifier) {
+	t.Helper()
+	st := storage.NewMemoryStore()
+	ss := storage.NewMemorySettingsStore()
+	capture := &captureNotifier{sent: map[string][]notify.Notification{}}
+	reg := notify.NewRegistry(notify.Options{})
+	reg.Register(captureChannel, capture.factory)
+	svc := NewAlertService(st, ss, storage.NewMemoryAlertStore(), reg)
+	if _, err := svc.UpdateSettings(context.Background(), &settings); err != nil {
+		t.Fatal(err)
+	}
+	return svc, st, capture
+}
+
+func createTestPool(t *testing.T, st storage.Store, name, cidr string, parentID *int64, typ domain.PoolType) domain.Pool {
+	t.Helper()
+	p, err := st.CreatePool(context.Background(), domain.CreatePool{Name: name, CIDR: cidr, ParentID: parentID, Type: typ})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func evaluate(t *testing.T, svc *AlertService) *domain.AlertEvaluationResponse {
+	t.Helper()
+	resp, err := svc.Evaluate(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestAlertLifecycle(t *testing.T) {
+	ctx := context.Background()
+	settings := domain.DefaultAlertSettings()
+	settings.Channels = []domain.NotificationChannel{{ID: "ops", Name: "Ops", Type: captureChannel, Enabled: true}}
+	svc, st, capture := newAlertTestService(t, settings)
+
+	parent := createTestPool(t, st, "prod", "10.0.0.0/24", nil, "")
+	a := createTestPool(t, st, "a", "10.0.0.0/25", &parent.ID, "")
+	b := createTestPool(t, st, "b", "10.0.0.128/26", &parent.ID, "")
+	createTestPool(t, st, "c", "10.0.0.192/27", &parent.ID, "")
+
+	// 87.5% is above the default 80% warning threshold.
+	resp := evaluate(t, svc)
+	if resp.Opened != 1 || resp.Active != 1 {
+		t.Fatalf("first evaluation = %+v", resp)
+	}
+	list, total, err := svc.alerts.ListAlerts(ctx, domain.AlertFilters{Status: domain.AlertStatusActive})
+	if err != nil || total != 1 {
+		t.Fatalf("ListAlerts = %d, %v", total, err)
+	}
+	alert := list[0]
+	if alert.PoolID != parent.ID || alert.Severity != domain.AlertSeverityWarning || alert.Value != 87.5 || alert.Threshold != 80 || alert.NotifiedAt == nil {
+		t.Fatalf("alert = %+v", alert)
+	}
+
+	// Re-evaluating an unchanged pool neither reopens nor notifies.
+	if resp := evaluate(t, svc); resp.Opened != 0 || resp.Active != 1 {
+		t.Fatalf("unchanged evaluation = %+v", resp)
+	}
+
+	acked, err := svc.Acknowledge(ctx, alert.ID, "alice")
+	if err != nil || acked.Status != domain.AlertStatusAcknowledged || acked.AcknowledgedBy != "alice" {
+		t.Fatalf("Acknowledge = %+v, %v", acked, err)
+	}
+	if _, err := svc.Acknowledge(ctx, alert.ID, "alice"); !errors.Is(err, storage.ErrConflict) {
+		t.Fatalf("second Acknowledge err = %v, want ErrConflict", err)
+	}
+
+	// 93.75% crosses the critical threshold: the alert escalates and reopens.
+	d := createTestPool(t, st, "d", "10.0.0.224/28", &parent.ID, "")
+	if resp := evaluate(t, svc); resp.Escalated != 1 || resp.Opened != 0 {
+		t.Fatalf("escalation evaluation = %+v", resp)
+	}
+	got, _ := svc.alerts.GetAlert(ctx, alert.ID)
+	if got.Severity != domain.AlertSeverityCritical || got.Status != domain.AlertStatusOpen || got.AcknowledgedAt != nil {
+		t.Fatalf("escalated alert = %+v", got)
+	}
+
+	// Freeing space clears the condition and resolves the alert.
+	for _, p := range []domain.Pool{a, b, d} {
+		if _, err := st.DeletePool(ctx, p.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if resp := evaluate(t, svc); resp.Resolved != 1 || resp.Active != 0 {
+		t.Fatalf("resolution evaluation = %+v", resp)
+	}
+	got, _ = svc.alerts.GetAlert(ctx, alert.ID)
+	if got.Status != domain.AlertStatusResolved || got.ResolvedBy != "system" || got.ResolvedAt == nil {
+		t.Fatalf("resolved alert = %+v", got)
+	}
+
+	want := []notify.Event{notify.EventAlertOpened, notify.EventAlertEscalated, notify.EventAlertResolved}
+	if got := capture.events("ops"); len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Fatalf("notifications = %v, want %v", got, want)
+	}
+}
+
+func TestAlertManualResolveReopens(t *testing.T) {
+	ctx := context.Background()
+	svc, st, _ := newAlertTestService(t, domain.DefaultAlertSettings())
+	parent := createTestPool(t, st, "prod", "10.0.0.0/24", nil, "")
+	createTestPool(t, st, "full", "10.0.0.0/24", &parent.ID, "")
+
+	evaluate(t, svc)
+	list, _, _ := svc.alerts.ListAlerts(ctx, domain.AlertFilters{})
+	if len(list) != 1 || list[0].Severity != domain.AlertSeverityCritical || list[0].NotifiedAt != nil {
+		t.Fatalf("alerts = %+v", list)
+	}
+	resolved, err := svc.Resolve(ctx, list[0].ID, "bob")
+	if err != nil || resolved.ResolvedBy != "bob" {
+		t.Fatalf("Resolve = %+v, %v", resolved, err)
+	}
+	if _, err := svc.Resolve(ctx, list[0].ID, "bob"); !errors.Is(err, storage.ErrConflict) {
+		t.Fatalf("second Resolve err = %v, want ErrConflict", err)
+	}
+	if _, err := svc.Acknowledge(ctx, "missing", "bob"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("Acknowledge(missing) err = %v, want ErrNotFound", err)
+	}
+
+	// The pool is still full, so the next evaluation opens a fresh alert.
+	if resp := evaluate(t, svc); resp.Opened != 1 {
+		t.Fatalf("evaluation after manual resolve = %+v", resp)
+	}
+	if _, total, _ := svc.alerts.ListAlerts(ctx, domain.AlertFilters{}); total != 2 {
+		t.Fatalf("total alerts = %d, want 2", total)
+	}
+}
+
+func TestAlertRuleMatchingAndRouting(t *testing.T) {
+	ctx := context.Background()
+	vpcOnly := int64(0)
+	settings := domain.AlertSettings{
+		Rules: []domain.AlertRule{
+			{ID: "all", Enabled: true, WarningPercent: 80, Channels: []string{"mail"}},
+			{ID: "vpcs", Enabled: true, PoolType: domain.PoolTypeVPC, WarningPercent: 20, Channels: []string{"chat"}},
+			{ID: "pinned", Enabled: true, PoolID: &vpcOnly, CriticalPercent: 95},
+			{ID: "off", Enabled: false, WarningPercent: 1},
+		},
+		Channels: []domain.NotificationChannel{
+			{ID: "mail", Type: captureChannel, Enabled: true},
+			{ID: "chat", Type: captureChannel, Enabled: true},
+			{ID: "muted", Type: captureChannel, Enabled: false},
+		},
+	}
+	// The pinned rule needs a real pool ID, so the pools come first.
+	svc, st, capture := newAlertTestService(t, domain.DefaultAlertSettings())
+	vpcA := createTestPool(t, st, "vpc-a", "10.1.0.0/24", nil, domain.PoolTypeVPC)
+	vpcB := createTestPool(t, st, "vpc-b", "10.2.0.0/24", nil, domain.PoolTypeVPC)
+	other := createTestPool(t, st, "other", "10.3.0.0/24", nil, domain.PoolTypeSubnet)
+	for _, p := range []domain.Pool{vpcA, vpcB, other} {
+		createTestPool(t, st, p.Name+"-half", p.CIDR[:len(p.CIDR)-3]+"/25", &p.ID, "")
+	}
+	vpcOnly = vpcB.ID
+	if _, err := svc.UpdateSettings(ctx, &settings); err != nil {
+		t.Fatal(err)
+	}
+
+	// 50% used everywhere: only vpc-a breaches (vpcs rule at 20%); vpc-b's
+	// pinned rule wins over the type rule and other's global rule is at 80%.
+	if resp := evaluate(t, svc); resp.Opened != 1 {
+		t.Fatalf("evaluation = %+v", resp)
+	}
+	list, _, _ := svc.alerts.ListAlerts(ctx, domain.AlertFilters{})
+	if len(list) != 1 || list[0].PoolID != vpcA.ID || list[0].RuleID != "vpcs" {
+		t.Fatalf("alerts = %+v", list)
+	}
+	if got := capture.events("chat"); len(got) != 1 {
+		t.Errorf("chat notifications = %v", got)
+	}
+	if got := capture.events("mail"); len(got) != 0 {
+		t.Errorf("mail notifications = %v", got)
+	}
+	if got := capture.events("muted"); len(got) != 0 {
+		t.Errorf("disabled channel notified: %v", got)
+	}
+
+	// Disabling the type rule hands vpc-a to the global rule, resolving
+	// the alert raised under the old rule.
+	settings.Rules[1].Enabled = false
+	if _, err := svc.UpdateSettings(ctx, &settings); err != nil {
+		t.Fatal(err)
+	}
+	if resp := evaluate(t, svc); resp.Resolved != 1 || resp.Active != 0 {
+		t.Fatalf("evaluation after disabling rule = %+v", resp)
+	}
+}
+
+func TestAlertExhaustionForecast(t *testing.T) {
+	ctx := context.Background()
+	now := forecastOrigin.Add(29 * day)
+	util, st, snaps, pool := newUtilizationTestService(t, now)
+	// 3 addresses a day from empty: 87 used today, 169 free, about 56 days left.
+	seedSnapshots(t, snaps, pool.ID, 30, day, linearUsage(0, 3))
+
+	settings := domain.AlertSettings{Rules: []domain.AlertRule{{ID: "runway", Enabled: true, ExhaustionDays: 60}}}
+	reg := notify.NewRegistry(notify.Options{})
+	svc := NewAlertService(st, storage.NewMemorySettingsStore(), storage.NewMemoryAlertStore(), reg)
+	svc.now = func() time.Time { return now }
+	if _, err := svc.UpdateSettings(ctx, &settings); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without forecasts, exhaustion thresholds are not checked.
+	if resp := evaluate(t, svc); resp.Opened != 0 {
+		t.Fatalf("evaluation without forecasts = %+v", resp)
+	}
+
+	svc.SetUtilization(util)
+	if resp := evaluate(t, svc); resp.Opened != 1 {
+		t.Fatalf("evaluation = %+v", resp)
+	}
+	list, _, _ := svc.alerts.ListAlerts(ctx, domain.AlertFilters{Kind: string(domain.AlertKindExhaustion)})
+	if len(list) != 1 || list[0].Severity != domain.AlertSeverityWarning || list[0].Threshold != 60 || list[0].Value < 55 || list[0].Value > 58 {
+		t.Fatalf("alerts = %+v", list)
+	}
+
+	// Inside the last half of the window the alert escalates.
+	settings.Rules[0].ExhaustionDays = 120
+	if _, err := svc.UpdateSettings(ctx, &settings); err != nil {
+		t.Fatal(err)
+	}
+	if resp := evaluate(t, svc); resp.Escalated != 1 {
+		t.Fatalf("evaluation = %+v", resp)
+	}
+}
+
+func TestAlertUpdateSettingsValidation(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _ := newAlertTestService(t, domain.DefaultAlertSettings())
+	for name, settings := range map[string]domain.AlertSettings{
+		"warning above critical": {Rules: []domain.AlertRule{{ID: "r", WarningPercent: 95, CriticalPercent: 90}}},
+		"no thresholds":          {Rules: []domain.AlertRule{{ID: "r"}}},
+		"unknown channel":        {Rules: []domain.AlertRule{{ID: "r", WarningPercent: 80, Channels: []string{"nope"}}}},
+		"bad pool type":          {Rules: []domain.AlertRule{{ID: "r", WarningPercent: 80, PoolType: "galaxy"}}},
+		"duplicate rule":         {Rules: []domain.AlertRule{{ID: "r", WarningPercent: 80}, {ID: "r", WarningPercent: 70}}},
+		"bad webhook url":        {Channels: []domain.NotificationChannel{{ID: "c", Type: domain.NotificationChannelWebhook, URL: "not a url"}}},
+		"unknown channel type":   {Channels: []domain.NotificationChannel{{ID: "c", Type: "pager"}}},
+	} {
+		if _, err := svc.UpdateSettings(ctx, &settings); !errors.Is(err, storage.ErrValidation) {
+			t.Errorf("%s: err = %v, want ErrValidation", name, err)
+		}
+	}
+
+	if err := svc.TestChannel(ctx, "missing"); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("TestChannel(missing) err = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,123 @@
+package planning
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+
+	"cloudpam/internal/cidr"
+	"cloudpam/internal/storage"
+)
+
+// AllocationStrategy selects which free block a new child pool is carved from.
+type AllocationStrategy string
+
+const (
+	// StrategyFirstFit takes the lowest-addressed free block that fits.
+	StrategyFirstFit AllocationStrategy = "first_fit"
+	// StrategyBestFit takes the smallest free block that fits, leaving larger
+	// runs of free space intact for later wide allocations.
+	StrategyBestFit AllocationStrategy = "best_fit"
+)
+
+// ValidAllocationStrategies contains all valid allocation strategies.
+var ValidAllocationStrategies = []AllocationStrategy{
+	StrategyFirstFit,
+	StrategyBestFit,
+}
+
+// IsValidAllocationStrategy checks if an allocation strategy is valid.
+func IsValidAllocationStrategy(s AllocationStrategy) bool {
+	for _, valid := range ValidAllocationStrategies {
+		if s == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrPoolExhausted indicates that a parent pool has no free block of the
+// requested size left.
+var ErrPoolExhausted = errors.New("pool exhausted")
+
+// NextAvailablePrefix returns a free prefix of the given length inside parent
+// that does not overlap any of the occupied prefixes. Occupied prefixes of the
+// other address family are ignored. It returns an error wrapping ErrPoolExhausted when
+// no aligned block of that size is free.
+func NextAvailablePrefix(parent netip.Prefix, occupied []netip.Prefix, bits int, strategy AllocationStrategy) (netip.Prefix, error) {
+	parent = parent.Masked()
+	is4 := parent.Addr().Is4()
+	if bits <= parent.Bits() || bits > parent.Addr().BitLen() {
+		return netip.Prefix{}, fmt.Errorf("prefix length /%d must be longer than parent /%d and at most /%d: %w",
+			bits, parent.Bits(), parent.Addr().BitLen(), storage.ErrValidation)
+	}
+
+	var children []interval
+	for _, p := range occupied {
+		if !p.IsValid() || p.Addr().Is4() != is4 {
+			continue
+		}
+		children = append(children, prefixToInterval(p.Masked()))
+	}
+
+	// rangeToCIDRs splits each gap into maximal aligned blocks, so any aligned
+	// /bits block inside a gap lies wholly within one of them and starts at
+	// its base address when it is the first such block.
+	parentIv := prefixToInterval(parent)
+	var best netip.Prefix
+	for _, fr := range findFreeRanges(parentIv.start, parentIv.end, children) {
+		for _, block := range rangeToCIDRs(fr.start, fr.end, is4) {
+			if block.Bits() > bits {
+				continue
+			}
+			if strategy != StrategyBestFit {
+				return netip.PrefixFrom(block.Addr(), bits), nil
+			}
+			if !best.IsValid() || block.Bits() > best.Bits() {
+				best = block
+			}
+		}
+	}
+	if !best.IsValid() {
+		return netip.Prefix{}, fmt.Errorf("no free /%d left in %s: %w", bits, parent, ErrPoolExhausted)
+	}
+	return netip.PrefixFrom(best.Addr(), bits), nil
+}
+
+// NextAvailableAddress returns the lowest usable host address in subnet that
+// is not in use and not inside an occupied prefix. IPv4 subnets wider than
+// /31 lose their network and broadcast addresses, and IPv6 subnets wider than
+// /127 their subnet-router anycast address. Entries of the other address
+// family are ignored. It returns an error wrapping ErrPoolExhausted when
+// every usable address is taken.
+func NextAvailableAddress(subnet netip.Prefix, occupied []netip.Prefix, used []netip.Addr) (netip.Addr, error) {
+	subnet = subnet.Masked()
+	is4 := subnet.Addr().Is4()
+	first, last := cidr.PrefixRange(subnet)
+	if subnet.Addr().BitLen()-subnet.Bits() >= 2 {
+		first = first.AddOne()
+		if is4 {
+			last = last.SubOne()
+		}
+	}
+
+	var taken []interval
+	for _, p := range occupied {
+		if !p.IsValid() || p.Addr().Is4() != is4 {
+			continue
+		}
+		taken = append(taken, prefixToInterval(p.Masked()))
+	}
+	for _, a := range used {
+		if !a.IsValid() || a.Unmap().Is4() != is4 {
+			continue
+		}
+		v := cidr.AddrToUint128(a)
+		taken = append(taken, interval{start: v, end: v})
+	}
+
+	if free := findFreeRanges(first, last, taken); len(free) > 0 {
+		return cidr.Uint128ToAddr(free[0].start, is4), nil
+	}
+	return netip.Addr{}, fmt.Errorf("no free address left in %s: %w", subnet, ErrPoolExhausted)
+}
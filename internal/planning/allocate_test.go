@@ -0,0 +1,183 @@
+package planning
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+
+	"cloudpam/internal/storage"
+)
+
+func TestNextAvailablePrefix(t *testing.T) {
+	parent := netip.MustParsePrefix("10.0.0.0/22")
+	tests := []struct {
+		name     string
+		occupied []string
+		bits     int
+		strategy AllocationStrategy
+		want     string
+	}{
+		{
+			name: "empty parent",
+			bits: 24, strategy: StrategyFirstFit,
+			want: "10.0.0.0/24",
+		},
+		{
+			name:     "first fit skips occupied",
+			occupied: []string{"10.0.0.0/24"},
+			bits:     24, strategy: StrategyFirstFit,
+			want: "10.0.1.0/24",
+		},
+		{
+			name:     "first fit respects alignment",
+			occupied: []string{"10.0.0.0/25"},
+			bits:     24, strategy: StrategyFirstFit,
+			want: "10.0.1.0/24",
+		},
+		{
+			name:     "first fit takes lowest hole",
+			occupied: []string{"10.0.0.0/26", "10.0.1.0/24"},
+			bits:     26, strategy: StrategyFirstFit,
+			want: "10.0.0.64/26",
+		},
+		{
+			// Free space: 10.0.0.0/23 and 10.0.2.128/25 (+ 10.0.3.0/24).
+			// Best fit picks the /25 hole for a /26 instead of splitting the /23.
+			name:     "best fit picks smallest hole",
+			occupied: []string{"10.0.2.0/25"},
+			bits:     26, strategy: StrategyBestFit,
+			want: "10.0.2.128/26",
+		},
+		{
+			name:     "ignores non-ipv4",
+			occupied: []string{"2001:db8::/32"},
+			bits:     23, strategy: StrategyFirstFit,
+			want: "10.0.0.0/23",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var occupied []netip.Prefix
+			for _, c := range tt.occupied {
+				occupied = append(occupied, netip.MustParsePrefix(c))
+			}
+			got, err := NextAvailablePrefix(parent, occupied, tt.bits, tt.strategy)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextAvailablePrefix_Exhausted(t *testing.T) {
+	parent := netip.MustParsePrefix("10.0.0.0/24")
+	occupied := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/26"),
+		netip.MustParsePrefix("10.0.0.128/26"),
+	}
+	// Two /26s remain free, but neither is an aligned /25.
+	if _, err := NextAvailablePrefix(parent, occupied, 25, StrategyFirstFit); !errors.Is(err, ErrPoolExhausted) {
+		t.Fatalf("expected ErrPoolExhausted, got %v", err)
+	}
+	if _, err := NextAvailablePrefix(parent, occupied, 26, StrategyBestFit); err != nil {
+		t.Fatalf("expected a free /26, got %v", err)
+	}
+}
+
+func TestNextAvailablePrefix_InvalidLength(t *testing.T) {
+	parent := netip.MustParsePrefix("10.0.0.0/24")
+	for _, bits := range []int{8, 24, 33} {
+		if _, err := NextAvailablePrefix(parent, nil, bits, StrategyFirstFit); !errors.Is(err, storage.ErrValidation) {
+			t.Errorf("bits=%d: expected ErrValidation, got %v", bits, err)
+		}
+	}
+	if _, err := NextAvailablePrefix(netip.MustParsePrefix("2001:db8::/48"), nil, 129, StrategyFirstFit); !errors.Is(err, storage.ErrValidation) {
+		t.Errorf("ipv6 bits=129: expected ErrValidation, got %v", err)
+	}
+}
+
+func TestNextAvailablePrefix_IPv6(t *testing.T) {
+	parent := netip.MustParsePrefix("2001:db8::/48")
+	occupied := []netip.Prefix{
+		netip.MustParsePrefix("2001:db8::/64"),
+		netip.MustParsePrefix("2001:db8:0:2::/64"),
+		// IPv4 children never collide with IPv6 space.
+		netip.MustParsePrefix("0.0.0.0/0"),
+	}
+	got, err := NextAvailablePrefix(parent, occupied, 64, StrategyFirstFit)
+	if err != nil {
+		t.Fatalf("first fit: %v", err)
+	}
+	if got.String() != "2001:db8:0:1::/64" {
+		t.Errorf("first fit = %s, want 2001:db8:0:1::/64", got)
+	}
+	got, err = NextAvailablePrefix(parent, occupied, 64, StrategyBestFit)
+	if err != nil {
+		t.Fatalf("best fit: %v", err)
+	}
+	if got.String() != "2001:db8:0:1::/64" {
+		t.Errorf("best fit = %s, want the lone /64 hole 2001:db8:0:1::/64", got)
+	}
+
+	full := []netip.Prefix{parent}
+	if _, err := NextAvailablePrefix(parent, full, 64, StrategyFirstFit); !errors.Is(err, ErrPoolExhausted) {
+		t.Errorf("expected ErrPoolExhausted, got %v", err)
+	}
+}
+
+func TestIsValidAllocationStrategy(t *testing.T) {
+	if !IsValidAllocationStrategy(StrategyFirstFit) || !IsValidAllocationStrategy(StrategyBestFit) {
+		t.Fatal("expected built-in strategies to be valid")
+	}
+	if IsValidAllocationStrategy("worst_fit") {
+		t.Fatal("expected unknown strategy to be invalid")
+	}
+}
+
+func TestNextAvailableAddress(t *testing.T) {
+	tests := []struct {
+		name     string
+		subnet   string
+		occupied []string
+		used     []string
+		want     string
+	}{
+		{name: "skips network address", subnet: "10.0.0.0/24", want: "10.0.0.1"},
+		{name: "skips used", subnet: "10.0.0.0/24", used: []string{"10.0.0.1", "10.0.0.2", "10.0.0.4"}, want: "10.0.0.3"},
+		{name: "skips child pools", subnet: "10.0.0.0/24", occupied: []string{"10.0.0.0/28"}, want: "10.0.0.16"},
+		{name: "point to point uses both", subnet: "10.0.0.0/31", used: []string{"10.0.0.0"}, want: "10.0.0.1"},
+		{name: "ignores other family", subnet: "10.0.0.0/30", used: []string{"::1"}, want: "10.0.0.1"},
+		{name: "ipv6 skips anycast", subnet: "2001:db8::/64", used: []string{"2001:db8::1"}, want: "2001:db8::2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var occupied []netip.Prefix
+			for _, c := range tt.occupied {
+				occupied = append(occupied, netip.MustParsePrefix(c))
+			}
+			var used []netip.Addr
+			for _, a := range tt.used {
+				used = append(used, netip.MustParseAddr(a))
+			}
+			got, err := NextAvailableAddress(netip.MustParsePrefix(tt.subnet), occupied, used)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextAvailableAddress_Exhausted(t *testing.T) {
+	subnet := netip.MustParsePrefix("10.0.0.0/30")
+	used := []netip.Addr{netip.MustParseAddr("10.0.0.1"), netip.MustParseAddr("10.0.0.2")}
+	// .0 and .3 are the network and broadcast addresses.
+	if _, err := NextAvailableAddress(subnet, nil, used); !errors.Is(err, ErrPoolExhausted) {
+		t.Fatalf("expected ErrPoolExhausted, got %v", err)
+	}
+}
@@ -10,7 +10,9 @@ import (
 
 // AnalysisService provides network analysis capabilities.
 type AnalysisService struct {
-	store storage.Store
+	store        storage.Store
+	rules        storage.ComplianceRuleStore
+	reservations storage.ReservationStore
 }
 
 // NewAnalysisService creates a new AnalysisService.
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"testing"
 
+	"cloudpam/internal/cidr"
 	"cloudpam/internal/domain"
 	"cloudpam/internal/storage"
 )
@@ -207,9 +208,9 @@ func TestCovAnalyzeGapsIgnoresUnparsableChildCIDR(t *testing.T) {
 func TestCovFindFreeRangesMergesAndClamps(t *testing.T) {
 	tests := []struct {
 		name        string
-		start, end  uint32
+		start, end  uint64
 		children    []interval
-		wantStarts  []uint32
+		wantStarts  []uint64
 		wantEndsLen int
 	}{
 		{
@@ -217,54 +218,54 @@ func TestCovFindFreeRangesMergesAndClamps(t *testing.T) {
 			start:       0,
 			end:         255,
 			children:    nil,
-			wantStarts:  []uint32{0},
+			wantStarts:  []uint64{0},
 			wantEndsLen: 1,
 		},
 		{
 			name:        "adjacent children merge into one block",
 			start:       0,
 			end:         255,
-			children:    []interval{{start: 0, end: 63}, {start: 64, end: 127}},
-			wantStarts:  []uint32{128},
+			children:    []interval{iv(0, 63), iv(64, 127)},
+			wantStarts:  []uint64{128},
 			wantEndsLen: 1,
 		},
 		{
 			name:        "overlapping children merge",
 			start:       0,
 			end:         255,
-			children:    []interval{{start: 0, end: 100}, {start: 50, end: 63}},
-			wantStarts:  []uint32{101},
+			children:    []interval{iv(0, 100), iv(50, 63)},
+			wantStarts:  []uint64{101},
 			wantEndsLen: 1,
 		},
 		{
 			name:        "unsorted children are handled",
 			start:       0,
 			end:         255,
-			children:    []interval{{start: 192, end: 255}, {start: 0, end: 63}},
-			wantStarts:  []uint32{64},
+			children:    []interval{iv(192, 255), iv(0, 63)},
+			wantStarts:  []uint64{64},
 			wantEndsLen: 1,
 		},
 		{
 			name:        "children fully outside the parent are skipped",
 			start:       100,
 			end:         200,
-			children:    []interval{{start: 0, end: 50}, {start: 300, end: 400}},
-			wantStarts:  []uint32{100},
+			children:    []interval{iv(0, 50), iv(300, 400)},
+			wantStarts:  []uint64{100},
 			wantEndsLen: 1,
 		},
 		{
 			name:        "children straddling the parent bounds are clamped",
 			start:       100,
 			end:         200,
-			children:    []interval{{start: 50, end: 120}},
-			wantStarts:  []uint32{121},
+			children:    []interval{iv(50, 120)},
+			wantStarts:  []uint64{121},
 			wantEndsLen: 1,
 		},
 		{
 			name:        "child covering the whole parent leaves no gaps",
 			start:       100,
 			end:         200,
-			children:    []interval{{start: 0, end: 500}},
+			children:    []interval{iv(0, 500)},
 			wantStarts:  nil,
 			wantEndsLen: 0,
 		},
@@ -272,25 +273,25 @@ func TestCovFindFreeRangesMergesAndClamps(t *testing.T) {
 			name:        "gaps on both sides",
 			start:       0,
 			end:         255,
-			children:    []interval{{start: 64, end: 127}},
-			wantStarts:  []uint32{0, 128},
+			children:    []interval{iv(64, 127)},
+			wantStarts:  []uint64{0, 128},
 			wantEndsLen: 2,
 		},
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			got := findFreeRanges(tc.start, tc.end, tc.children)
+			got := findFreeRanges(cidr.Uint128From64(tc.start), cidr.Uint128From64(tc.end), tc.children)
 			if len(got) != tc.wantEndsLen {
 				t.Fatalf("gaps = %+v, want %d", got, tc.wantEndsLen)
 			}
 			for i, want := range tc.wantStarts {
-				if got[i].start != want {
+				if got[i].start != cidr.Uint128From64(want) {
 					t.Errorf("gap %d start = %d, want %d", i, got[i].start, want)
 				}
 			}
 			for _, g := range got {
-				if g.start < tc.start || g.end > tc.end {
+				if g.start.Lo < tc.start || g.end.Lo > tc.end {
 					t.Errorf("gap %+v escapes the parent range %d-%d", g, tc.start, tc.end)
 				}
 			}
@@ -299,16 +300,29 @@ func TestCovFindFreeRangesMergesAndClamps(t *testing.T) {
 }
 
 func TestCovFindFreeRangesHandlesFullIPv4Range(t *testing.T) {
-	// A child ending at the maximum uint32 makes the cursor overflow to 0.
-	got := findFreeRanges(0, ^uint32(0), []interval{{start: 1, end: ^uint32(0)}})
+	// A child ending at the maximum uint32 must not push the cursor past the
+	// parent and produce a bogus tail gap.
+	got := findFreeRanges(cidr.Uint128{}, cidr.Uint128From64(1<<32-1), []interval{iv(1, 1<<32-1)})
 	if len(got) != 1 {
 		t.Fatalf("gaps = %+v, want a single leading gap", got)
 	}
-	if got[0].start != 0 || got[0].end != 0 {
+	if !got[0].start.IsZero() || !got[0].end.IsZero() {
 		t.Fatalf("gap = %+v, want just address 0", got[0])
 	}
 }
 
+func TestCovFindFreeRangesHandlesFullIPv6Range(t *testing.T) {
+	// The last address of ::/0 wraps to zero when incremented.
+	top := cidr.Uint128{Hi: ^uint64(0), Lo: ^uint64(0)}
+	got := findFreeRanges(cidr.Uint128{}, top, []interval{{start: cidr.Uint128From64(1), end: top}})
+	if len(got) != 1 {
+		t.Fatalf("gaps = %+v, want a single leading gap", got)
+	}
+	if !got[0].start.IsZero() || !got[0].end.IsZero() {
+		t.Fatalf("gap = %+v, want just address ::", got[0])
+	}
+}
+
 func TestCovCheckNamingFlagsMissingNameAndDescription(t *testing.T) {
 	svc := NewAnalysisService(storage.NewMemoryStore())
 
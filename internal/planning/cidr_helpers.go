@@ -1,77 +1,55 @@
 package planning
 
 import (
-	"encoding/binary"
-	"math/bits"
 	"net/netip"
-)
-
-// ipv4ToUint32 converts an IPv4 address to a uint32 in network byte order.
-func ipv4ToUint32(a netip.Addr) uint32 {
-	b := a.As4()
-	return binary.BigEndian.Uint32(b[:])
-}
 
-// uint32ToIPv4 converts a uint32 to an IPv4 netip.Addr.
-func uint32ToIPv4(u uint32) netip.Addr {
-	var b [4]byte
-	binary.BigEndian.PutUint32(b[:], u)
-	return netip.AddrFrom4(b)
-}
+	"cloudpam/internal/cidr"
+)
 
-// prefixesOverlap reports whether two IPv4 prefixes share any addresses.
+// prefixesOverlap reports whether two prefixes share any addresses.
+// Prefixes of different address families never overlap.
 func prefixesOverlap(a, b netip.Prefix) bool {
-	ai := prefixToInterval(a.Masked())
-	bi := prefixToInterval(b.Masked())
-	return ai.end >= bi.start && bi.end >= ai.start
+	return cidr.PrefixesOverlap(a.Masked(), b.Masked())
 }
 
-// rangeToCIDRs decomposes an inclusive uint32 range [start, end] into the
-// minimal set of CIDR-aligned prefixes that exactly cover the range.
-func rangeToCIDRs(start, end uint32) []netip.Prefix {
-	var result []netip.Prefix
-	for start <= end {
-		// Largest power-of-two block starting at 'start' that fits alignment.
-		// Alignment: the number of trailing zeros in start determines max block size.
-		maxBits := 32
-		if start != 0 {
-			maxBits = bits.TrailingZeros32(start)
-		}
-		// Don't exceed remaining range.
-		remaining := uint64(end) - uint64(start) + 1
-		fitBits := 63 - bits.LeadingZeros64(remaining) // floor(log2(remaining))
-		if fitBits > maxBits {
-			fitBits = maxBits
-		}
-		prefixLen := 32 - fitBits
-		result = append(result, netip.PrefixFrom(uint32ToIPv4(start), prefixLen))
-		blockSize := uint32(1) << fitBits
-		start += blockSize
-		if start == 0 { // overflow
-			break
-		}
-	}
-	return result
+// rangeToCIDRs decomposes an inclusive range [start, end] into the minimal
+// set of CIDR-aligned prefixes that exactly cover the range. is4 selects
+// whether the range is in IPv4 (low 32 bits) or IPv6 address space.
+func rangeToCIDRs(start, end cidr.Uint128, is4 bool) []netip.Prefix {
+	return cidr.RangeToPrefixes(start, end, is4)
 }
 
 // isRFC1918 reports whether the prefix falls entirely within RFC 1918 private space.
+// IPv6 prefixes are never RFC 1918; see isPrivateSpace.
 func isRFC1918(p netip.Prefix) bool {
 	rfc1918 := []netip.Prefix{
 		netip.MustParsePrefix("10.0.0.0/8"),
 		netip.MustParsePrefix("172.16.0.0/12"),
 		netip.MustParsePrefix("192.168.0.0/16"),
 	}
-	pi := prefixToInterval(p.Masked())
 	for _, r := range rfc1918 {
-		ri := prefixToInterval(r)
-		if pi.start >= ri.start && pi.end <= ri.end {
+		if cidr.PrefixContains(r, p.Masked()) {
 			return true
 		}
 	}
 	return false
 }
 
-// prefixAddressCount returns the total number of addresses in a prefix.
+// uniqueLocalIPv6 is the RFC 4193 unique local address block, the IPv6
+// counterpart of RFC 1918 space.
+var uniqueLocalIPv6 = netip.MustParsePrefix("fc00::/7")
+
+// isPrivateSpace reports whether the prefix lies in private address space:
+// RFC 1918 for IPv4, unique local addresses (fc00::/7) for IPv6.
+func isPrivateSpace(p netip.Prefix) bool {
+	if p.Addr().Is4() {
+		return isRFC1918(p)
+	}
+	return cidr.PrefixContains(uniqueLocalIPv6, p.Masked())
+}
+
+// prefixAddressCount returns the total number of addresses in a prefix,
+// saturating at math.MaxUint64 for IPv6 prefixes wider than /64.
 func prefixAddressCount(p netip.Prefix) uint64 {
-	return 1 << (32 - p.Bits())
+	return cidr.AddressCount(p).Uint64()
 }
@@ -9,7 +9,8 @@ import (
 	"cloudpam/internal/storage"
 )
 
-// CheckCompliance validates a set of pools against compliance rules.
+// CheckCompliance validates a set of pools against the built-in checks and
+// any enabled user-defined rules.
 func (s *AnalysisService) CheckCompliance(ctx context.Context, poolIDs []int64, includeChildren bool) (*ComplianceReport, error) {
 	pools, err := s.resolvePools(ctx, poolIDs, includeChildren)
 	if err != nil {
@@ -24,6 +25,9 @@ func (s *AnalysisService) CheckCompliance(ctx context.Context, poolIDs []int64,
 		s.checkEmpty(ctx, pool, report)
 		s.checkNaming(pool, report)
 	}
+	if err := s.checkCustomRules(ctx, pools, report); err != nil {
+		return nil, err
+	}
 
 	report.Passed = report.TotalChecks - report.Failed - report.Warnings
 	return report, nil
@@ -64,11 +68,12 @@ func (s *AnalysisService) checkOverlap(ctx context.Context, pool domain.Pool, al
 }
 
 // checkRFC1918 flags non-RFC1918 space in pools (RFC1918-001).
+// IPv6 pools pass: globally routed IPv6 space is the norm for cloud networks.
 func (s *AnalysisService) checkRFC1918(pool domain.Pool, report *ComplianceReport) {
 	report.TotalChecks++
 
 	p, err := netip.ParsePrefix(pool.CIDR)
-	if err != nil {
+	if err != nil || !p.Addr().Is4() {
 		return
 	}
 	if !isRFC1918(p.Masked()) {
@@ -93,13 +98,7 @@ func (s *AnalysisService) checkEmpty(ctx context.Context, pool domain.Pool, repo
 	if err != nil {
 		return
 	}
-	// Only flag pools that look like parents (supernet/region/environment/vpc types).
-	isParentType := pool.Type == domain.PoolTypeSupernet ||
-		pool.Type == domain.PoolTypeRegion ||
-		pool.Type == domain.PoolTypeEnvironment ||
-		pool.Type == domain.PoolTypeVPC
-
-	if isParentType && len(children) == 0 {
+	if isParentPoolType(pool.Type) && len(children) == 0 {
 		report.Warnings++
 		report.Violations = append(report.Violations, ComplianceViolation{
 			RuleID:      "EMPTY-001",
@@ -113,6 +112,15 @@ func (s *AnalysisService) checkEmpty(ctx context.Context, pool domain.Pool, repo
 	}
 }
 
+// isParentPoolType reports whether pools of type t are meant to hold child
+// pools (supernet/region/environment/vpc types).
+func isParentPoolType(t domain.PoolType) bool {
+	return t == domain.PoolTypeSupernet ||
+		t == domain.PoolTypeRegion ||
+		t == domain.PoolTypeEnvironment ||
+		t == domain.PoolTypeVPC
+}
+
 // checkNaming flags missing pool names and descriptions (NAME-001, NAME-002).
 func (s *AnalysisService) checkNaming(pool domain.Pool, report *ComplianceReport) {
 	report.TotalChecks++
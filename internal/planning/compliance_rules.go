@@ -0,0 +1,350 @@
+package planning
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strings"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+// SetComplianceRules enables user-defined compliance rules. Without a rule
+// store only the built-in checks run.
+func (s *AnalysisService) SetComplianceRules(rules storage.ComplianceRuleStore) {
+	s.rules = rules
+}
+
+// AdmissionError is returned when enforced compliance rules reject a pool
+// that applying a recommendation would create.
+type AdmissionError struct {
+	Violations []ComplianceViolation
+}
+
+func (e *AdmissionError) Error() string {
+	v := e.Violations[0]
+	return fmt.Sprintf("pool violates compliance rules: %s: %s", v.RuleID, v.Message)
+}
+
+// admit runs CheckPoolAdmission on in and returns an *AdmissionError if any
+// enforced rule rejects it.
+func (s *AnalysisService) admit(ctx context.Context, in domain.CreatePool) error {
+	violations, err := s.CheckPoolAdmission(ctx, in)
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return &AdmissionError{Violations: violations}
+	}
+	return nil
+}
+
+// CheckPoolAdmission evaluates a pool about to be created against the
+// enabled rules marked enforce and returns the violations. Callers reject
+// the pool when any are returned.
+func (s *AnalysisService) CheckPoolAdmission(ctx context.Context, in domain.CreatePool) ([]ComplianceViolation, error) {
+	rules, err := s.loadComplianceRules(ctx, true)
+	if err != nil || len(rules) == 0 {
+		return nil, err
+	}
+	pool := domain.Pool{Name: in.Name, CIDR: in.CIDR, ParentID: in.ParentID, Type: in.Type, Tags: in.Tags}
+	if pool.Type == "" {
+		pool.Type = domain.PoolTypeSubnet
+	}
+	depths := newPoolDepths(s.store, nil)
+	depth := func() int {
+		if in.ParentID == nil {
+			return 1
+		}
+		return depths.depth(ctx, *in.ParentID) + 1
+	}
+	var violations []ComplianceViolation
+	for _, rule := range rules {
+		if !rule.matches(pool) {
+			continue
+		}
+		if v, ok := rule.evaluate(pool, depth); ok {
+			violations = append(violations, v)
+		}
+	}
+	return violations, nil
+}
+
+// PlannedPool is a pool that does not exist yet, with the nesting depth it
+// would have once created.
+type PlannedPool struct {
+	Pool  domain.Pool
+	Depth int
+}
+
+// CheckPlannedPools evaluates pools that do not exist yet against every
+// enabled rule, enforced or not, for a dry run. Each Pool.ID is the
+// caller's own key and is copied into the PoolID of its violations.
+func (s *AnalysisService) CheckPlannedPools(ctx context.Context, pools []PlannedPool) ([]ComplianceViolation, error) {
+	rules, err := s.loadComplianceRules(ctx, false)
+	if err != nil || len(rules) == 0 {
+		return nil, err
+	}
+	var violations []ComplianceViolation
+	for _, planned := range pools {
+		pool := planned.Pool
+		if pool.Type == "" {
+			pool.Type = domain.PoolTypeSubnet
+		}
+		depth := func() int { return planned.Depth }
+		for _, rule := range rules {
+			if !rule.matches(pool) {
+				continue
+			}
+			if v, ok := rule.evaluate(pool, depth); ok {
+				violations = append(violations, v)
+			}
+		}
+	}
+	return violations, nil
+}
+
+// checkCustomRules runs the user-defined rules over pools and adds their
+// results to report.
+func (s *AnalysisService) checkCustomRules(ctx context.Context, pools []domain.Pool, report *ComplianceReport) error {
+	rules, err := s.loadComplianceRules(ctx, false)
+	if err != nil || len(rules) == 0 {
+		return err
+	}
+	depths := newPoolDepths(s.store, pools)
+	for _, pool := range pools {
+		depth := func() int { return depths.depth(ctx, pool.ID) }
+		for _, rule := range rules {
+			if !rule.matches(pool) {
+				continue
+			}
+			report.TotalChecks++
+			v, ok := rule.evaluate(pool, depth)
+			if !ok {
+				continue
+			}
+			switch v.Severity {
+			case "error":
+				report.Failed++
+			case "warning":
+				report.Warnings++
+			}
+			report.Violations = append(report.Violations, v)
+		}
+	}
+	return nil
+}
+
+// loadComplianceRules returns the enabled rules, or only the enforced ones,
+// ready to evaluate. Rules that no longer compile are skipped; they were
+// validated when saved.
+func (s *AnalysisService) loadComplianceRules(ctx context.Context, enforcedOnly bool) ([]compiledComplianceRule, error) {
+	if s.rules == nil {
+		return nil, nil
+	}
+	rules, err := s.rules.ListComplianceRules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list compliance rules: %w", err)
+	}
+	out := make([]compiledComplianceRule, 0, len(rules))
+	for _, rule := range rules {
+		if !rule.Enabled || (enforcedOnly && !rule.Enforce) {
+			continue
+		}
+		if c, err := compileComplianceRule(rule); err == nil {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+type compiledComplianceRule struct {
+	domain.ComplianceRule
+	pattern *regexp.Regexp
+	ranges  []netip.Prefix
+}
+
+func compileComplianceRule(rule domain.ComplianceRule) (compiledComplianceRule, error) {
+	c := compiledComplianceRule{ComplianceRule: rule}
+	if rule.Check.Type == domain.ComplianceCheckNamePattern {
+		re, err := regexp.Compile(rule.Check.Pattern)
+		if err != nil {
+			return c, err
+		}
+		c.pattern = re
+	}
+	for _, r := range rule.Check.Ranges {
+		p, err := netip.ParsePrefix(strings.TrimSpace(r))
+		if err != nil {
+			return c, err
+		}
+		c.ranges = append(c.ranges, p.Masked())
+	}
+	return c, nil
+}
+
+// matches reports whether the rule applies to pool.
+func (c compiledComplianceRule) matches(pool domain.Pool) bool {
+	m := c.Match
+	if len(m.PoolTypes) > 0 {
+		found := false
+		for _, t := range m.PoolTypes {
+			if pool.Type == t {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for k, want := range m.Tags {
+		got := pool.Tags[k]
+		if got == "" || (want != "*" && got != want) {
+			return false
+		}
+	}
+	if m.IPVersion != 0 {
+		p, err := netip.ParsePrefix(pool.CIDR)
+		if err != nil || (m.IPVersion == 4) != p.Addr().Is4() {
+			return false
+		}
+	}
+	return true
+}
+
+// evaluate checks a pool the rule applies to and returns the violation, if
+// any. depth is only called by max_depth rules.
+func (c compiledComplianceRule) evaluate(pool domain.Pool, depth func() int) (ComplianceViolation, bool) {
+	msg := c.violation(pool, depth)
+	if msg == "" {
+		return ComplianceViolation{}, false
+	}
+	if c.Message != "" {
+		msg = c.Message
+	}
+	return ComplianceViolation{
+		RuleID:      c.ID,
+		Severity:    c.Severity,
+		PoolID:      pool.ID,
+		PoolName:    pool.Name,
+		CIDR:        pool.CIDR,
+		Message:     msg,
+		Remediation: c.Remediation,
+	}, true
+}
+
+// violation returns a description of how pool breaks the rule's check, or
+// "" when it complies.
+func (c compiledComplianceRule) violation(pool domain.Pool, depth func() int) string {
+	check := c.Check
+	switch check.Type {
+	case domain.ComplianceCheckPrefixLength:
+		p, err := netip.ParsePrefix(pool.CIDR)
+		if err != nil {
+			return ""
+		}
+		bits := p.Bits()
+		if check.MaxPrefixLength > 0 && bits > check.MaxPrefixLength {
+			return fmt.Sprintf("pool is a /%d; blocks must be /%d or larger", bits, check.MaxPrefixLength)
+		}
+		if check.MinPrefixLength > 0 && bits < check.MinPrefixLength {
+			return fmt.Sprintf("pool is a /%d; blocks must be /%d or smaller", bits, check.MinPrefixLength)
+		}
+	case domain.ComplianceCheckRequiredTags:
+		var missing []string
+		for _, tag := range check.Tags {
+			if strings.TrimSpace(pool.Tags[tag]) == "" {
+				missing = append(missing, tag)
+			}
+		}
+		if len(missing) > 0 {
+			return "missing required tags: " + strings.Join(missing, ", ")
+		}
+	case domain.ComplianceCheckForbiddenRanges:
+		p, err := netip.ParsePrefix(pool.CIDR)
+		if err != nil {
+			return ""
+		}
+		for _, r := range c.ranges {
+			if prefixesOverlap(p.Masked(), r) {
+				return fmt.Sprintf("overlaps forbidden range %s", r)
+			}
+		}
+	case domain.ComplianceCheckAllowedRanges:
+		p, err := netip.ParsePrefix(pool.CIDR)
+		if err != nil {
+			return ""
+		}
+		for _, r := range c.ranges {
+			if r.Bits() <= p.Bits() && r.Contains(p.Masked().Addr()) {
+				return ""
+			}
+		}
+		return "outside the allowed ranges " + strings.Join(check.Ranges, ", ")
+	case domain.ComplianceCheckMaxDepth:
+		if d := depth(); d > check.MaxDepth {
+			return fmt.Sprintf("nested %d levels deep; the maximum is %d", d, check.MaxDepth)
+		}
+	case domain.ComplianceCheckNamePattern:
+		if c.pattern != nil && !c.pattern.MatchString(pool.Name) {
+			return fmt.Sprintf("name %q does not match %s", pool.Name, check.Pattern)
+		}
+	}
+	return ""
+}
+
+// poolDepths computes nesting depth (top-level pools are at depth 1),
+// fetching ancestors that are not already known.
+type poolDepths struct {
+	store  storage.Store
+	pools  map[int64]domain.Pool
+	depths map[int64]int
+}
+
+func newPoolDepths(store storage.Store, known []domain.Pool) *poolDepths {
+	d := &poolDepths{store: store, pools: make(map[int64]domain.Pool, len(known)), depths: map[int64]int{}}
+	for _, p := range known {
+		d.pools[p.ID] = p
+	}
+	return d
+}
+
+func (d *poolDepths) depth(ctx context.Context, id int64) int {
+	// Walk up to the root or to an ancestor whose depth is known. seen
+	// guards against malformed hierarchies.
+	var chain []int64
+	seen := map[int64]bool{}
+	base := 0
+	for cur := id; ; {
+		if n, ok := d.depths[cur]; ok {
+			base = n
+			break
+		}
+		if seen[cur] {
+			break
+		}
+		seen[cur] = true
+		chain = append(chain, cur)
+		p, ok := d.pools[cur]
+		if !ok {
+			got, found, err := d.store.GetPool(ctx, cur)
+			if err != nil || !found {
+				break
+			}
+			p = got
+			d.pools[cur] = p
+		}
+		if p.ParentID == nil {
+			break
+		}
+		cur = *p.ParentID
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		base++
+		d.depths[chain[i]] = base
+	}
+	return d.depths[id]
+}
@@ -0,0 +1,200 @@
+package planning
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+func addComplianceRule(t *testing.T, rules *storage.MemoryComplianceRuleStore, rule domain.ComplianceRule) {
+	t.Helper()
+	if msg := domain.ValidateComplianceRule(&rule); msg != "" {
+		t.Fatalf("rule %s: %s", rule.ID, msg)
+	}
+	if err := rules.CreateComplianceRule(context.Background(), rule); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// violationsByRule groups the violations by rule, each ordered by pool ID
+// since the memory store lists pools in no particular order.
+func violationsByRule(report *ComplianceReport) map[string][]ComplianceViolation {
+	out := map[string][]ComplianceViolation{}
+	for _, v := range report.Violations {
+		out[v.RuleID] = append(out[v.RuleID], v)
+	}
+	for _, vs := range out {
+		sort.Slice(vs, func(i, j int) bool { return vs[i].PoolID < vs[j].PoolID })
+	}
+	return out
+}
+
+func TestCheckCompliance_CustomRules(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemoryStore()
+	rules := storage.NewMemoryComplianceRuleStore()
+
+	root, _ := store.CreatePool(ctx, domain.CreatePool{Name: "corp", CIDR: "10.0.0.0/8", Type: domain.PoolTypeSupernet})
+	env, _ := store.CreatePool(ctx, domain.CreatePool{
+		Name: "prod", CIDR: "10.1.0.0/16", ParentID: &root.ID, Type: domain.PoolTypeEnvironment,
+		Tags: map[string]string{"env": "prod", "owner": "netops"},
+	})
+	vpc, _ := store.CreatePool(ctx, domain.CreatePool{
+		Name: "app-vpc", CIDR: "10.1.0.0/20", ParentID: &env.ID, Type: domain.PoolTypeVPC,
+		Tags: map[string]string{"env": "prod", "owner": "app", "cost-center": "42"},
+	})
+	tiny, _ := store.CreatePool(ctx, domain.CreatePool{
+		Name: "tiny", CIDR: "10.1.0.0/28", ParentID: &vpc.ID, Type: domain.PoolTypeSubnet,
+		Tags: map[string]string{"env": "prod"},
+	})
+	docker, _ := store.CreatePool(ctx, domain.CreatePool{Name: "docker", CIDR: "172.17.0.0/16", Type: domain.PoolTypeSubnet})
+
+	addComplianceRule(t, rules, domain.ComplianceRule{
+		ID: "TAGS-001", Name: "Ownership tags", Enabled: true, Severity: "error",
+		Match: domain.ComplianceMatch{Tags: map[string]string{"env": "*"}},
+		Check: domain.ComplianceCheck{Type: domain.ComplianceCheckRequiredTags, Tags: []string{"owner", "cost-center"}},
+	})
+	addComplianceRule(t, rules, domain.ComplianceRule{
+		ID: "SIZE-001", Name: "Subnets /27 or larger", Enabled: true, Severity: "warning",
+		Match: domain.ComplianceMatch{PoolTypes: []domain.PoolType{domain.PoolTypeSubnet}, IPVersion: 4},
+		Check: domain.ComplianceCheck{Type: domain.ComplianceCheckPrefixLength, MaxPrefixLength: 27},
+	})
+	addComplianceRule(t, rules, domain.ComplianceRule{
+		ID: "RANGE-001", Name: "No Docker or CGNAT", Enabled: true, Severity: "error", Remediation: "Renumber the pool",
+		Check: domain.ComplianceCheck{Type: domain.ComplianceCheckForbiddenRanges, Ranges: []string{"100.64.0.0/10", "172.17.0.0/16"}},
+	})
+	addComplianceRule(t, rules, domain.ComplianceRule{
+		ID: "DEPTH-001", Name: "At most three levels", Enabled: true, Severity: "info",
+		Check: domain.ComplianceCheck{Type: domain.ComplianceCheckMaxDepth, MaxDepth: 3},
+	})
+	addComplianceRule(t, rules, domain.ComplianceRule{
+		ID: "NAME-PROD", Name: "Prod naming", Enabled: true, Severity: "warning", Message: "prod pools start with prod-",
+		Match: domain.ComplianceMatch{Tags: map[string]string{"env": "prod"}, PoolTypes: []domain.PoolType{domain.PoolTypeVPC}},
+		Check: domain.ComplianceCheck{Type: domain.ComplianceCheckNamePattern, Pattern: `^prod-`},
+	})
+	addComplianceRule(t, rules, domain.ComplianceRule{
+		ID: "OFF-001", Name: "Disabled", Enabled: false, Severity: "error",
+		Check: domain.ComplianceCheck{Type: domain.ComplianceCheckMaxDepth, MaxDepth: 1},
+	})
+
+	svc := NewAnalysisService(store)
+	base, err := svc.CheckCompliance(ctx, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc.SetComplianceRules(rules)
+	report, err := svc.CheckCompliance(ctx, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := violationsByRule(report)
+
+	if v := got["TAGS-001"]; len(v) != 2 || v[0].PoolID != env.ID || v[0].Message != "missing required tags: cost-center" ||
+		v[1].PoolID != tiny.ID || v[1].Message != "missing required tags: owner, cost-center" {
+		t.Errorf("TAGS-001 = %+v", v)
+	}
+	if v := got["SIZE-001"]; len(v) != 1 || v[0].PoolID != tiny.ID || v[0].Severity != "warning" {
+		t.Errorf("SIZE-001 = %+v", v)
+	}
+	if v := got["RANGE-001"]; len(v) != 1 || v[0].PoolID != docker.ID || v[0].Remediation != "Renumber the pool" {
+		t.Errorf("RANGE-001 = %+v", v)
+	}
+	if v := got["DEPTH-001"]; len(v) != 1 || v[0].PoolID != tiny.ID || v[0].Message != "nested 4 levels deep; the maximum is 3" {
+		t.Errorf("DEPTH-001 = %+v", v)
+	}
+	if v := got["NAME-PROD"]; len(v) != 1 || v[0].PoolID != vpc.ID || v[0].Message != "prod pools start with prod-" {
+		t.Errorf("NAME-PROD = %+v", v)
+	}
+	if len(got["OFF-001"]) != 0 {
+		t.Error("disabled rule produced violations")
+	}
+
+	// TAGS-001 on 3 pools, SIZE-001 on 2, RANGE-001 and DEPTH-001 on 5, NAME-PROD on 1.
+	if report.TotalChecks != base.TotalChecks+16 {
+		t.Errorf("total checks = %d, want %d", report.TotalChecks, base.TotalChecks+16)
+	}
+	if report.Failed != base.Failed+3 || report.Warnings != base.Warnings+2 {
+		t.Errorf("failed/warnings = %d/%d, want %d/%d", report.Failed, report.Warnings, base.Failed+3, base.Warnings+2)
+	}
+}
+
+func TestCheckPoolAdmission(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemoryStore()
+	rules := storage.NewMemoryComplianceRuleStore()
+	root, _ := store.CreatePool(ctx, domain.CreatePool{Name: "corp", CIDR: "10.0.0.0/8", Type: domain.PoolTypeSupernet})
+
+	addComplianceRule(t, rules, domain.ComplianceRule{
+		ID: "ALLOW-001", Name: "Corporate space only", Enabled: true, Severity: "error", Enforce: true,
+		Check: domain.ComplianceCheck{Type: domain.ComplianceCheckAllowedRanges, Ranges: []string{"10.0.0.0/8"}},
+	})
+	addComplianceRule(t, rules, domain.ComplianceRule{
+		ID: "DEPTH-001", Name: "Flat hierarchy", Enabled: true, Severity: "error", Enforce: true,
+		Check: domain.ComplianceCheck{Type: domain.ComplianceCheckMaxDepth, MaxDepth: 1},
+	})
+	// Reported but not enforced.
+	addComplianceRule(t, rules, domain.ComplianceRule{
+		ID: "TAGS-001", Name: "Owner tag", Enabled: true, Severity: "error",
+		Check: domain.ComplianceCheck{Type: domain.ComplianceCheckRequiredTags, Tags: []string{"owner"}},
+	})
+
+	svc := NewAnalysisService(store)
+	if v, err := svc.CheckPoolAdmission(ctx, domain.CreatePool{Name: "x", CIDR: "192.168.0.0/24"}); err != nil || v != nil {
+		t.Fatalf("without rules: %+v, %v", v, err)
+	}
+	svc.SetComplianceRules(rules)
+
+	v, err := svc.CheckPoolAdmission(ctx, domain.CreatePool{Name: "lab", CIDR: "192.168.0.0/24"})
+	if err != nil || len(v) != 1 || v[0].RuleID != "ALLOW-001" {
+		t.Fatalf("outside allowed ranges: %+v, %v", v, err)
+	}
+	v, err = svc.CheckPoolAdmission(ctx, domain.CreatePool{Name: "child", CIDR: "10.1.0.0/16", ParentID: &root.ID})
+	if err != nil || len(v) != 1 || v[0].RuleID != "DEPTH-001" || v[0].Message != "nested 2 levels deep; the maximum is 1" {
+		t.Fatalf("nested child: %+v, %v", v, err)
+	}
+	v, err = svc.CheckPoolAdmission(ctx, domain.CreatePool{Name: "second-root", CIDR: "10.0.0.0/8"})
+	if err != nil || len(v) != 0 {
+		t.Fatalf("compliant pool: %+v, %v", v, err)
+	}
+}
+
+func TestCheckPlannedPools(t *testing.T) {
+	ctx := context.Background()
+	rules := storage.NewMemoryComplianceRuleStore()
+	addComplianceRule(t, rules, domain.ComplianceRule{
+		ID: "DEPTH-001", Name: "Shallow hierarchy", Enabled: true, Severity: "warning",
+		Check: domain.ComplianceCheck{Type: domain.ComplianceCheckMaxDepth, MaxDepth: 2},
+	})
+	addComplianceRule(t, rules, domain.ComplianceRule{
+		ID: "TAGS-001", Name: "Owner tag", Enabled: true, Severity: "error",
+		Match: domain.ComplianceMatch{PoolTypes: []domain.PoolType{domain.PoolTypeSubnet}},
+		Check: domain.ComplianceCheck{Type: domain.ComplianceCheckRequiredTags, Tags: []string{"owner"}},
+	})
+	addComplianceRule(t, rules, domain.ComplianceRule{
+		ID: "OFF-001", Name: "Disabled", Enabled: false, Severity: "error",
+		Check: domain.ComplianceCheck{Type: domain.ComplianceCheckMaxDepth, MaxDepth: 1},
+	})
+
+	svc := NewAnalysisService(storage.NewMemoryStore())
+	svc.SetComplianceRules(rules)
+	v, err := svc.CheckPlannedPools(ctx, []PlannedPool{
+		{Pool: domain.Pool{ID: 1, Name: "root", CIDR: "10.0.0.0/8", Type: domain.PoolTypeSupernet}, Depth: 1},
+		{Pool: domain.Pool{ID: 2, Name: "region", CIDR: "10.0.0.0/12", Type: domain.PoolTypeRegion}, Depth: 2},
+		// Untyped pools are subnets, so the tag rule applies.
+		{Pool: domain.Pool{ID: 3, Name: "app", CIDR: "10.0.0.0/24"}, Depth: 3},
+	})
+	if err != nil {
+		t.Fatalf("CheckPlannedPools: %v", err)
+	}
+	if len(v) != 2 {
+		t.Fatalf("violations = %+v, want the depth and tag violations of pool 3", v)
+	}
+	for _, got := range v {
+		if got.PoolID != 3 || (got.RuleID != "DEPTH-001" && got.RuleID != "TAGS-001") {
+			t.Errorf("unexpected violation %+v", got)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package planning
+
+import (
+	"math"
+	"time"
+
+	"cloudpam/internal/domain"
+)
+
+const (
+	// MinForecastSamples is the fewest snapshots a forecast is fitted to.
+	MinForecastSamples = 3
+	// MinForecastSpan is the shortest history a forecast is fitted to.
+	MinForecastSpan = 24 * time.Hour
+	// MinSeasonalSpan is the shortest history the day-of-week model is
+	// fitted to; two full weeks give every weekday at least two samples.
+	MinSeasonalSpan = 14 * 24 * time.Hour
+	// ForecastHorizon is how far ahead exhaustion is searched for.
+	ForecastHorizon = 5 * 365 * 24 * time.Hour
+	// ProjectionDays is how many daily projection points a forecast returns.
+	ProjectionDays = 90
+)
+
+const day = 24 * time.Hour
+
+// growthModel predicts used IPs at x days after the first sample.
+type growthModel struct {
+	method    string
+	intercept float64
+	slope     float64    // IPs per day
+	offsets   [7]float64 // additive per-weekday adjustment; zero for linear
+	params    int        // fitted parameters besides the intercept
+	r2        float64
+	rmse      float64
+}
+
+func (m growthModel) predict(origin time.Time, x float64) float64 {
+	t := origin.Add(time.Duration(x * float64(day)))
+	return m.intercept + m.slope*x + m.offsets[t.Weekday()]
+}
+
+// adjustedR2 penalises the seasonal model's extra parameters so it is only
+// chosen when the weekly pattern explains enough of the variance.
+func (m growthModel) adjustedR2(n int) float64 {
+	if n-m.params-1 <= 0 {
+		return m.r2
+	}
+	return 1 - (1-m.r2)*float64(n-1)/float64(n-m.params-1)
+}
+
+func (m growthModel) toForecastModel(n int, exhaustion *time.Time) ForecastModel {
+	fm := ForecastModel{
+		Method:           m.method,
+		GrowthPerDay:     round2(m.slope),
+		RSquared:         round4(m.r2),
+		AdjustedRSquared: round4(m.adjustedR2(n)),
+		RMSE:             round2(m.rmse),
+		ExhaustionAt:     exhaustion,
+	}
+	if m.method == ForecastSeasonal {
+		fm.WeekdayOffsets = make([]float64, 7)
+		for i, o := range m.offsets {
+			fm.WeekdayOffsets[i] = round2(o)
+		}
+	}
+	return fm
+}
+
+// sampleSeries converts snapshots into days since the first snapshot and
+// used IPs.
+func sampleSeries(snaps []domain.UtilizationSnapshot) (origin time.Time, xs, ys []float64) {
+	origin = snaps[0].CapturedAt.UTC()
+	xs = make([]float64, len(snaps))
+	ys = make([]float64, len(snaps))
+	for i, s := range snaps {
+		xs[i] = s.CapturedAt.Sub(origin).Hours() / 24
+		ys[i] = float64(s.UsedIPs)
+	}
+	return origin, xs, ys
+}
+
+// fitLinear fits used IPs against time by ordinary least squares.
+func fitLinear(xs, ys []float64) growthModel {
+	n := float64(len(xs))
+	var mx, my float64
+	for i := range xs {
+		mx += xs[i]
+		my += ys[i]
+	}
+	mx /= n
+	my /= n
+	var sxx, sxy float64
+	for i := range xs {
+		sxx += (xs[i] - mx) * (xs[i] - mx)
+		sxy += (xs[i] - mx) * (ys[i] - my)
+	}
+	m := growthModel{method: ForecastLinear, params: 1}
+	if sxx > 0 {
+		m.slope = sxy / sxx
+	}
+	m.intercept = my - m.slope*mx
+	m.r2, m.rmse = goodnessOfFit(ys, func(i int) float64 { return m.intercept + m.slope*xs[i] })
+	return m
+}
+
+// fitSeasonal fits a linear trend plus an additive offset per UTC weekday
+// (classical decomposition): the trend is fitted, the mean residual of each
+// weekday becomes its offset, and the trend is refitted to the
+// deseasonalised series. It reports false when the history is too short to
+// see every weekday twice.
+func fitSeasonal(origin time.Time, xs, ys []float64) (growthModel, bool) {
+	if len(xs) < 14 || time.Duration(xs[len(xs)-1]*float64(day)) < MinSeasonalSpan {
+		return growthModel{}, false
+	}
+	weekday := func(i int) time.Weekday {
+		return origin.Add(time.Duration(xs[i] * float64(day))).Weekday()
+	}
+
+	trend := fitLinear(xs, ys)
+	var sums [7]float64
+	var counts [7]int
+	for i := range xs {
+		wd := weekday(i)
+		sums[wd] += ys[i] - (trend.intercept + trend.slope*xs[i])
+		counts[wd]++
+	}
+	var offsets [7]float64
+	var mean float64
+	for wd := range offsets {
+		if counts[wd] == 0 {
+			return growthModel{}, false
+		}
+		offsets[wd] = sums[wd] / float64(counts[wd])
+		mean += offsets[wd] / 7
+	}
+	for wd := range offsets {
+		offsets[wd] -= mean
+	}
+
+	deseasonalised := make([]float64, len(ys))
+	for i := range ys {
+		deseasonalised[i] = ys[i] - offsets[weekday(i)]
+	}
+	m := fitLinear(xs, deseasonalised)
+	m.method = ForecastSeasonal
+	m.offsets = offsets
+	m.params = 7 // slope plus six independent weekday offsets
+	m.r2, m.rmse = goodnessOfFit(ys, func(i int) float64 {
+		return m.intercept + m.slope*xs[i] + offsets[weekday(i)]
+	})
+	return m, true
+}
+
+// goodnessOfFit returns R² and the root-mean-square error of predictions
+// against ys. A constant series fitted exactly has R² of 1.
+func goodnessOfFit(ys []float64, predict func(i int) float64) (r2, rmse float64) {
+	var my float64
+	for _, y := range ys {
+		my += y
+	}
+	my /= float64(len(ys))
+	var ssRes, ssTot float64
+	for i, y := range ys {
+		d := y - predict(i)
+		ssRes += d * d
+		ssTot += (y - my) * (y - my)
+	}
+	rmse = math.Sqrt(ssRes / float64(len(ys)))
+	switch {
+	case ssTot > 0:
+		r2 = 1 - ssRes/ssTot
+	case ssRes < 1e-9:
+		r2 = 1
+	}
+	return r2, rmse
+}
+
+// exhaustion returns when the model's prediction first reaches capacity at
+// or after now, or nil if usage is not growing or the crossing lies beyond
+// ForecastHorizon.
+func (m growthModel) exhaustion(origin, now time.Time, capacity float64) *time.Time {
+	if m.slope <= 0 {
+		return nil
+	}
+	toX := func(t time.Time) float64 { return t.Sub(origin).Hours() / 24 }
+	toTime := func(x float64) time.Time { return origin.Add(time.Duration(x * float64(day))) }
+	limit := now.Add(ForecastHorizon)
+
+	if m.method != ForecastSeasonal {
+		x := (capacity - m.intercept) / m.slope
+		if x > toX(limit) {
+			return nil
+		}
+		at := now
+		if x > toX(now) {
+			at = toTime(x)
+		}
+		at = at.Truncate(time.Second)
+		return &at
+	}
+
+	// The weekday offset is constant over each UTC day, so within a day
+	// the prediction is linear and the crossing can be solved exactly.
+	start := now
+	for start.Before(limit) {
+		end := start.Truncate(day).Add(day)
+		x := (capacity - m.intercept - m.offsets[start.Weekday()]) / m.slope
+		if x < toX(end) {
+			at := start
+			if x > toX(start) {
+				at = toTime(x)
+			}
+			at = at.Truncate(time.Second)
+			return &at
+		}
+		start = end
+	}
+	return nil
+}
+
+// projection returns ProjectionDays daily predictions starting the day
+// after now, clamped to the pool's capacity.
+func (m growthModel) projection(origin, now time.Time, capacity float64) []ForecastPoint {
+	points := make([]ForecastPoint, 0, ProjectionDays)
+	first := now.Truncate(day).Add(day)
+	for i := 0; i < ProjectionDays; i++ {
+		t := first.Add(time.Duration(i) * day)
+		used := math.Round(m.predict(origin, t.Sub(origin).Hours()/24))
+		used = math.Max(0, math.Min(capacity, used))
+		var pct float64
+		if capacity > 0 {
+			pct = round2(used / capacity * 100)
+		}
+		points = append(points, ForecastPoint{Date: t, UsedIPs: int64(used), Utilization: pct})
+	}
+	return points
+}
+
+// forecastFromSnapshots fits the linear and, with enough history, seasonal
+// models to snaps (oldest first), picks the one with the higher adjusted R²,
+// and predicts exhaustion against the latest snapshot's capacity.
+func forecastFromSnapshots(f *UtilizationForecast, snaps []domain.UtilizationSnapshot, now time.Time) {
+	f.Samples = len(snaps)
+	if len(snaps) == 0 {
+		f.Reason = "no utilization snapshots recorded"
+		return
+	}
+	first, latest := snaps[0], snaps[len(snaps)-1]
+	f.HistoryStart = &first.CapturedAt
+	f.HistoryEnd = &latest.CapturedAt
+	f.TotalIPs, f.UsedIPs, f.Utilization = latest.TotalIPs, latest.UsedIPs, latest.Utilization
+
+	capacity := float64(latest.TotalIPs)
+	if latest.TotalIPs > 0 && latest.UsedIPs >= latest.TotalIPs {
+		at := latest.CapturedAt
+		zero := 0.0
+		f.ExhaustionAt, f.DaysToExhaustion = &at, &zero
+		f.Reason = "pool is already full"
+		return
+	}
+	if len(snaps) < MinForecastSamples || latest.CapturedAt.Sub(first.CapturedAt) < MinForecastSpan {
+		f.Reason = "insufficient history: need at least 3 snapshots spanning a day"
+		return
+	}
+
+	origin, xs, ys := sampleSeries(snaps)
+	models := []growthModel{fitLinear(xs, ys)}
+	if seasonal, ok := fitSeasonal(origin, xs, ys); ok {
+		models = append(models, seasonal)
+	}
+
+	best := 0
+	for i, m := range models {
+		exh := m.exhaustion(origin, now, capacity)
+		f.Models = append(f.Models, m.toForecastModel(len(snaps), exh))
+		if m.adjustedR2(len(snaps)) > models[best].adjustedR2(len(snaps)) {
+			best = i
+		}
+	}
+	chosen := models[best]
+	f.Method = chosen.method
+	f.GrowthPerDay = round2(chosen.slope)
+	f.ExhaustionAt = f.Models[best].ExhaustionAt
+	f.Projection = chosen.projection(origin, now, capacity)
+
+	switch {
+	case f.ExhaustionAt != nil:
+		days := round2(f.ExhaustionAt.Sub(now).Hours() / 24)
+		f.DaysToExhaustion = &days
+	case chosen.slope <= 0:
+		f.Reason = "usage is flat or shrinking"
+	default:
+		f.Reason = "not projected to exhaust within 5 years"
+	}
+}
+
+func round2(v float64) float64 { return math.Round(v*100) / 100 }
+
+func round4(v float64) float64 { return math.Round(v*10000) / 10000 }
@@ -5,12 +5,21 @@ import (
 	"fmt"
 	"net/netip"
 	"sort"
+	"time"
 
+	"cloudpam/internal/cidr"
+	"cloudpam/internal/domain"
 	"cloudpam/internal/storage"
 )
 
+// SetReservationStore makes gap analysis treat active reservations as
+// occupied. Without it only child pools are.
+func (s *AnalysisService) SetReservationStore(reservations storage.ReservationStore) {
+	s.reservations = reservations
+}
+
 // AnalyzeGaps finds unused address space within a pool by comparing
-// its CIDR range against its direct children.
+// its CIDR range against its direct children and active reservations.
 func (s *AnalysisService) AnalyzeGaps(ctx context.Context, poolID int64) (*GapAnalysis, error) {
 	pool, found, err := s.store.GetPool(ctx, poolID)
 	if err != nil {
@@ -40,7 +49,9 @@ func (s *AnalysisService) AnalyzeGaps(ctx context.Context, poolID int64) (*GapAn
 			continue
 		}
 		cp = cp.Masked()
-		childIntervals = append(childIntervals, prefixToInterval(cp))
+		if cp.Addr().Is4() == parent.Addr().Is4() {
+			childIntervals = append(childIntervals, prefixToInterval(cp))
+		}
 
 		var util float64
 		if stats, err := s.store.CalculatePoolUtilization(ctx, child.ID); err == nil && stats != nil {
@@ -54,61 +65,110 @@ func (s *AnalysisService) AnalyzeGaps(ctx context.Context, poolID int64) (*GapAn
 		})
 	}
 
+	reserved, err := s.reservedBlocks(ctx, poolID, parent)
+	if err != nil {
+		return nil, err
+	}
+	var reservedCount cidr.Uint128
+	for _, b := range reserved {
+		rp := netip.MustParsePrefix(b.CIDR)
+		childIntervals = append(childIntervals, prefixToInterval(rp))
+		reservedCount = reservedCount.Add(cidr.AddressCount(rp))
+	}
+
 	parentIv := prefixToInterval(parent)
 	freeRanges := findFreeRanges(parentIv.start, parentIv.end, childIntervals)
 
+	// Address counts are summed in 128-bit space and only narrowed (with
+	// saturation) for the JSON fields, so wide IPv6 pools stay accurate.
 	var available []AvailableBlock
-	var freeAddrs uint64
+	var free cidr.Uint128
 	for _, fr := range freeRanges {
-		cidrs := rangeToCIDRs(fr.start, fr.end)
+		cidrs := rangeToCIDRs(fr.start, fr.end, parent.Addr().Is4())
 		for _, c := range cidrs {
-			count := prefixAddressCount(c)
+			count := cidr.AddressCount(c)
 			available = append(available, AvailableBlock{
 				CIDR:         c.String(),
-				AddressCount: count,
+				AddressCount: count.Uint64(),
 			})
-			freeAddrs += count
+			free = free.Add(count)
 		}
 	}
 
-	totalAddrs := prefixAddressCount(parent)
-	usedAddrs := totalAddrs - freeAddrs
+	total := cidr.AddressCount(parent)
+	used := total.Sub(free)
 	var util float64
-	if totalAddrs > 0 {
-		util = float64(usedAddrs) / float64(totalAddrs) * 100
+	if !total.IsZero() {
+		util = used.Float64() / total.Float64() * 100
 	}
+	totalAddrs, usedAddrs, freeAddrs := total.Uint64(), used.Uint64(), free.Uint64()
 
 	return &GapAnalysis{
-		PoolID:          poolID,
-		PoolName:        pool.Name,
-		ParentCIDR:      pool.CIDR,
-		AllocatedBlocks: allocated,
-		AvailableBlocks: available,
-		TotalAddresses:  totalAddrs,
-		UsedAddresses:   usedAddrs,
-		FreeAddresses:   freeAddrs,
-		Utilization:     util,
+		PoolID:            poolID,
+		PoolName:          pool.Name,
+		ParentCIDR:        pool.CIDR,
+		AllocatedBlocks:   allocated,
+		ReservedBlocks:    reserved,
+		AvailableBlocks:   available,
+		TotalAddresses:    totalAddrs,
+		UsedAddresses:     usedAddrs,
+		ReservedAddresses: reservedCount.Uint64(),
+		FreeAddresses:     freeAddrs,
+		Utilization:       util,
 	}, nil
 }
 
+// reservedBlocks returns the active reservations under poolID in the
+// parent's address family.
+func (s *AnalysisService) reservedBlocks(ctx context.Context, poolID int64, parent netip.Prefix) ([]ReservedBlock, error) {
+	if s.reservations == nil {
+		return nil, nil
+	}
+	active, err := s.reservations.ListReservations(ctx, domain.ReservationActive)
+	if err != nil {
+		return nil, fmt.Errorf("list reservations: %w", err)
+	}
+	now := time.Now()
+	var out []ReservedBlock
+	for _, r := range active {
+		if !r.HoldsAt(now) || r.ParentID == nil || *r.ParentID != poolID {
+			continue
+		}
+		rp, err := netip.ParsePrefix(r.CIDR)
+		if err != nil || rp.Addr().Is4() != parent.Addr().Is4() {
+			continue
+		}
+		rp = rp.Masked()
+		out = append(out, ReservedBlock{
+			ReservationID: r.ID,
+			CIDR:          rp.String(),
+			Owner:         r.Owner,
+			Reason:        r.Reason,
+			ExpiresAt:     r.ExpiresAt,
+			AddressCount:  cidr.AddressCount(rp).Uint64(),
+		})
+	}
+	return out, nil
+}
+
 // findFreeRanges returns the gaps in [parentStart, parentEnd] not covered
 // by any child interval. Children may overlap; they are merged first.
-func findFreeRanges(parentStart, parentEnd uint32, children []interval) []interval {
+func findFreeRanges(parentStart, parentEnd cidr.Uint128, children []interval) []interval {
 	if len(children) == 0 {
 		return []interval{{start: parentStart, end: parentEnd}}
 	}
 
 	// Sort by start address.
 	sort.Slice(children, func(i, j int) bool {
-		return children[i].start < children[j].start
+		return children[i].start.Cmp(children[j].start) < 0
 	})
 
 	// Merge overlapping/adjacent intervals.
 	merged := []interval{children[0]}
 	for _, c := range children[1:] {
 		last := &merged[len(merged)-1]
-		if c.start <= last.end+1 {
-			if c.end > last.end {
+		if c.start.Cmp(last.end) <= 0 || c.start == last.end.AddOne() {
+			if c.end.Cmp(last.end) > 0 {
 				last.end = c.end
 			}
 		} else {
@@ -123,24 +183,28 @@ func findFreeRanges(parentStart, parentEnd uint32, children []interval) []interv
 		// Clamp to parent bounds.
 		mStart := m.start
 		mEnd := m.end
-		if mEnd < parentStart || mStart > parentEnd {
+		if mEnd.Cmp(parentStart) < 0 || mStart.Cmp(parentEnd) > 0 {
 			continue
 		}
-		if mStart < parentStart {
+		if mStart.Cmp(parentStart) < 0 {
 			mStart = parentStart
 		}
-		if mEnd > parentEnd {
-			mEnd = parentEnd
+		if mEnd.Cmp(parentEnd) >= 0 {
+			// Covered through the end of the parent; there is no tail gap.
+			// Returning here also avoids wrapping past the top of the space.
+			if cursor.Cmp(mStart) < 0 {
+				gaps = append(gaps, interval{start: cursor, end: mStart.SubOne()})
+			}
+			return gaps
 		}
-		if cursor < mStart {
-			gaps = append(gaps, interval{start: cursor, end: mStart - 1})
+		if cursor.Cmp(mStart) < 0 {
+			gaps = append(gaps, interval{start: cursor, end: mStart.SubOne()})
 		}
-		cursor = mEnd + 1
-		if cursor == 0 { // uint32 overflow
-			break
+		if next := mEnd.AddOne(); next.Cmp(cursor) > 0 {
+			cursor = next
 		}
 	}
-	if cursor != 0 && cursor <= parentEnd {
+	if cursor.Cmp(parentEnd) <= 0 {
 		gaps = append(gaps, interval{start: cursor, end: parentEnd})
 	}
 
@@ -4,53 +4,90 @@ import (
 	"context"
 	"net/netip"
 	"testing"
+	"time"
 
+	"cloudpam/internal/cidr"
 	"cloudpam/internal/domain"
 	"cloudpam/internal/storage"
 )
 
+// addrInt converts an address literal to its integer value.
+func addrInt(s string) cidr.Uint128 {
+	return cidr.AddrToUint128(netip.MustParseAddr(s))
+}
+
+// iv builds an interval from small integer bounds.
+func iv(start, end uint64) interval {
+	return interval{start: cidr.Uint128From64(start), end: cidr.Uint128From64(end)}
+}
+
 func TestRangeToCIDRs(t *testing.T) {
 	tests := []struct {
 		name  string
-		start uint32
-		end   uint32
+		start cidr.Uint128
+		end   cidr.Uint128
 		want  []string
 	}{
 		{
 			name:  "single /24",
-			start: ipv4ToUint32(netip.MustParseAddr("10.0.0.0")),
-			end:   ipv4ToUint32(netip.MustParseAddr("10.0.0.255")),
+			start: addrInt("10.0.0.0"),
+			end:   addrInt("10.0.0.255"),
 			want:  []string{"10.0.0.0/24"},
 		},
 		{
 			name:  "single /32",
-			start: ipv4ToUint32(netip.MustParseAddr("10.0.0.1")),
-			end:   ipv4ToUint32(netip.MustParseAddr("10.0.0.1")),
+			start: addrInt("10.0.0.1"),
+			end:   addrInt("10.0.0.1"),
 			want:  []string{"10.0.0.1/32"},
 		},
 		{
 			name:  "two /25s make a /24",
-			start: ipv4ToUint32(netip.MustParseAddr("10.0.0.0")),
-			end:   ipv4ToUint32(netip.MustParseAddr("10.0.0.255")),
+			start: addrInt("10.0.0.0"),
+			end:   addrInt("10.0.0.255"),
 			want:  []string{"10.0.0.0/24"},
 		},
 		{
 			name:  "non-aligned range",
-			start: ipv4ToUint32(netip.MustParseAddr("10.0.0.128")),
-			end:   ipv4ToUint32(netip.MustParseAddr("10.0.1.127")),
+			start: addrInt("10.0.0.128"),
+			end:   addrInt("10.0.1.127"),
 			want:  []string{"10.0.0.128/25", "10.0.1.0/25"},
 		},
 		{
 			name:  "small gap: 3 addresses",
-			start: ipv4ToUint32(netip.MustParseAddr("10.0.0.1")),
-			end:   ipv4ToUint32(netip.MustParseAddr("10.0.0.3")),
+			start: addrInt("10.0.0.1"),
+			end:   addrInt("10.0.0.3"),
 			want:  []string{"10.0.0.1/32", "10.0.0.2/31"},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := rangeToCIDRs(tt.start, tt.end)
+			got := rangeToCIDRs(tt.start, tt.end, true)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d CIDRs, want %d: %v", len(got), len(tt.want), got)
+			}
+			for i, g := range got {
+				if g.String() != tt.want[i] {
+					t.Errorf("CIDR[%d] = %s, want %s", i, g.String(), tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRangeToCIDRs_IPv6(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end string
+		want       []string
+	}{
+		{"single /64", "2001:db8::", "2001:db8::ffff:ffff:ffff:ffff", []string{"2001:db8::/64"}},
+		{"crosses 64-bit boundary", "2001:db8:0:0:8000::", "2001:db8:0:1:7fff:ffff:ffff:ffff", []string{"2001:db8:0:0:8000::/65", "2001:db8:0:1::/65"}},
+		{"whole space", "::", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", []string{"::/0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rangeToCIDRs(addrInt(tt.start), addrInt(tt.end), false)
 			if len(got) != len(tt.want) {
 				t.Fatalf("got %d CIDRs, want %d: %v", len(got), len(tt.want), got)
 			}
@@ -64,12 +101,12 @@ func TestRangeToCIDRs(t *testing.T) {
 }
 
 func TestFindFreeRanges(t *testing.T) {
-	parseIP := func(s string) uint32 { return ipv4ToUint32(netip.MustParseAddr(s)) }
+	parseIP := addrInt
 
 	tests := []struct {
 		name     string
-		pStart   uint32
-		pEnd     uint32
+		pStart   cidr.Uint128
+		pEnd     cidr.Uint128
 		children []interval
 		wantGaps int
 	}{
@@ -228,3 +265,47 @@ func TestAnalyzeGaps_NoChildren(t *testing.T) {
 		t.Errorf("available_blocks = %d, want 1 (the whole /24)", len(result.AvailableBlocks))
 	}
 }
+
+func TestAnalyzeGaps_Reservations(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewMemoryStore()
+	parent, _ := store.CreatePool(ctx, domain.CreatePool{
+		Name: "Region",
+		CIDR: "10.0.0.0/22",
+		Type: domain.PoolTypeSupernet,
+	})
+	parentID := parent.ID
+
+	reservations := storage.NewMemoryReservationStore()
+	now := time.Now().UTC()
+	for _, r := range []domain.Reservation{
+		{ID: "held", CIDR: "10.0.2.0/24", ParentID: &parentID, Owner: "netops", Reason: "NET-42",
+			Status: domain.ReservationActive, ExpiresAt: now.Add(time.Hour), CreatedAt: now},
+		// Past its expiry but not yet marked by the expiry job.
+		{ID: "lapsed", CIDR: "10.0.0.0/24", ParentID: &parentID, Owner: "netops",
+			Status: domain.ReservationActive, ExpiresAt: now.Add(-time.Minute), CreatedAt: now.Add(-time.Hour)},
+	} {
+		if err := reservations.CreateReservation(ctx, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	svc := NewAnalysisService(store)
+	svc.SetReservationStore(reservations)
+	result, err := svc.AnalyzeGaps(ctx, parent.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.ReservedBlocks) != 1 || result.ReservedBlocks[0].ReservationID != "held" || result.ReservedBlocks[0].Owner != "netops" {
+		t.Fatalf("reserved_blocks = %+v", result.ReservedBlocks)
+	}
+	if result.UsedAddresses != 256 || result.ReservedAddresses != 256 || result.FreeAddresses != 768 {
+		t.Errorf("used/reserved/free = %d/%d/%d, want 256/256/768", result.UsedAddresses, result.ReservedAddresses, result.FreeAddresses)
+	}
+	held := netip.MustParsePrefix("10.0.2.0/24")
+	for _, b := range result.AvailableBlocks {
+		if cidr.PrefixesOverlap(netip.MustParsePrefix(b.CIDR), held) {
+			t.Errorf("available block %s overlaps the reservation", b.CIDR)
+		}
+	}
+}
@@ -0,0 +1,366 @@
+package llm
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// anthropicVersion is the Messages API version the provider speaks.
+const anthropicVersion = "2023-06-01"
+
+// AnthropicProvider implements the Provider interface using the native
+// Anthropic Messages API.
+type AnthropicProvider struct {
+	cfg    Config
+	client *http.Client
+}
+
+// NewAnthropicProvider creates a provider for the Anthropic Messages API.
+func NewAnthropicProvider(cfg Config) *AnthropicProvider {
+	return &AnthropicProvider{
+		cfg:    cfg,
+		client: &http.Client{},
+	}
+}
+
+func (p *AnthropicProvider) Name() string { return ProviderAnthropic }
+func (p *AnthropicProvider) Available() bool {
+	return strings.TrimSpace(p.cfg.APIKey) != "" || strings.TrimSpace(p.cfg.Endpoint) != ""
+}
+
+func (p *AnthropicProvider) baseURL() string {
+	if endpoint := strings.TrimSpace(p.cfg.Endpoint); endpoint != "" {
+		return strings.TrimRight(endpoint, "/")
+	}
+	return "https://api.anthropic.com/v1"
+}
+
+// anthropicRequest is the request body for the Messages API.
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	System      string             `json:"system,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+	MaxTokens   int64              `json:"max_tokens"`
+	Temperature float64            `json:"temperature"`
+	Stream      bool               `json:"stream"`
+	Tools       []anthropicTool    `json:"tools,omitempty"`
+}
+
+type anthropicMessage struct {
+	Role    string           `json:"role"` // "user" or "assistant"
+	Content []anthropicBlock `json:"content"`
+}
+
+// anthropicBlock is a content block. Which fields are set depends on Type:
+// text, tool_use or tool_result.
+type anthropicBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	ID        string          `json:"id,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Input     json.RawMessage `json:"input,omitempty"`
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	Content   string          `json:"content,omitempty"`
+}
+
+type anthropicTool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"input_schema"`
+}
+
+type anthropicUsage struct {
+	InputTokens  int64 `json:"input_tokens"`
+	OutputTokens int64 `json:"output_tokens"`
+}
+
+// anthropicResponse is the response body for non-streaming requests.
+type anthropicResponse struct {
+	Content    []anthropicBlock `json:"content"`
+	StopReason string           `json:"stop_reason"`
+	Usage      anthropicUsage   `json:"usage"`
+	Error      *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// anthropicStreamEvent is the data of one SSE event. The type field
+// selects which of the other fields are set.
+type anthropicStreamEvent struct {
+	Type         string          `json:"type"`
+	Index        int             `json:"index"`
+	ContentBlock *anthropicBlock `json:"content_block,omitempty"`
+	Delta        struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		PartialJSON string `json:"partial_json"`
+		StopReason  string `json:"stop_reason"`
+	} `json:"delta"`
+	Message *struct {
+		Usage anthropicUsage `json:"usage"`
+	} `json:"message,omitempty"`
+	Usage *anthropicUsage `json:"usage,omitempty"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// anthropicFinishReason maps a Messages API stop reason onto the shared
+// finish reasons.
+func anthropicFinishReason(stop string) string {
+	switch stop {
+	case "end_turn", "stop_sequence":
+		return FinishStop
+	case "max_tokens":
+		return FinishLength
+	case "tool_use":
+		return FinishToolCalls
+	}
+	return stop
+}
+
+// newRequest builds the HTTP request for a Messages API call. System
+// messages are lifted into the top-level system field, tool results become
+// tool_result blocks in a user turn, and consecutive messages with the same
+// role are merged, since the API expects user and assistant to alternate.
+func (p *AnthropicProvider) newRequest(ctx context.Context, messages []Message, opts Options, stream bool) (*http.Request, error) {
+	maxTokens := opts.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = p.cfg.MaxTokens
+	}
+	temp := p.cfg.Temperature
+	if opts.Temperature != nil {
+		temp = *opts.Temperature
+	}
+
+	body := anthropicRequest{
+		Model:       p.cfg.Model,
+		MaxTokens:   maxTokens,
+		Temperature: temp,
+		Stream:      stream,
+	}
+	var system []string
+	for _, m := range messages {
+		var role string
+		var blocks []anthropicBlock
+		switch m.Role {
+		case "system":
+			system = append(system, m.Content)
+			continue
+		case "tool":
+			role = "user"
+			blocks = []anthropicBlock{{Type: "tool_result", ToolUseID: m.ToolCallID, Content: m.Content}}
+		case "assistant":
+			role = "assistant"
+			if m.Content != "" {
+				blocks = append(blocks, anthropicBlock{Type: "text", Text: m.Content})
+			}
+			for _, tc := range m.ToolCalls {
+				input := json.RawMessage(tc.Arguments)
+				if !json.Valid(input) {
+					input = json.RawMessage("{}")
+				}
+				blocks = append(blocks, anthropicBlock{Type: "tool_use", ID: tc.ID, Name: tc.Name, Input: input})
+			}
+		default:
+			role = "user"
+			blocks = []anthropicBlock{{Type: "text", Text: m.Content}}
+		}
+		if n := len(body.Messages); n > 0 && body.Messages[n-1].Role == role {
+			body.Messages[n-1].Content = append(body.Messages[n-1].Content, blocks...)
+			continue
+		}
+		body.Messages = append(body.Messages, anthropicMessage{Role: role, Content: blocks})
+	}
+	body.System = strings.Join(system, "\n\n")
+	for _, t := range opts.Tools {
+		schema := t.Parameters
+		if len(schema) == 0 {
+			schema = json.RawMessage(`{"type":"object"}`)
+		}
+		body.Tools = append(body.Tools, anthropicTool{Name: t.Name, Description: t.Description, InputSchema: schema})
+	}
+
+	bodyJSON, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL()+"/messages", strings.NewReader(string(bodyJSON)))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("anthropic-version", anthropicVersion)
+	if apiKey := strings.TrimSpace(p.cfg.APIKey); apiKey != "" {
+		req.Header.Set("x-api-key", apiKey)
+	}
+	if stream {
+		req.Header.Set("Accept", "text/event-stream")
+	}
+	return req, nil
+}
+
+func (p *AnthropicProvider) Complete(ctx context.Context, messages []Message, opts Options) (out *Response, err error) {
+	ctx, span := startCompletionSpan(ctx, "llm.messages", p.Name(), p.cfg.Model, p.baseURL(), false)
+	defer func() { _ = endSpanWithError(span, err) }()
+
+	req, err := p.newRequest(ctx, messages, opts, false)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("http request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("api error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+
+	var aResp anthropicResponse
+	if err := json.Unmarshal(respBody, &aResp); err != nil {
+		return nil, fmt.Errorf("unmarshal response: %w", err)
+	}
+	if aResp.Error != nil {
+		return nil, fmt.Errorf("api error: %s", aResp.Error.Message)
+	}
+
+	recordUsage(span, aResp.Usage.InputTokens, aResp.Usage.OutputTokens)
+
+	out = &Response{
+		FinishReason: anthropicFinishReason(aResp.StopReason),
+		PromptTokens: aResp.Usage.InputTokens,
+		OutputTokens: aResp.Usage.OutputTokens,
+	}
+	var text strings.Builder
+	for _, b := range aResp.Content {
+		switch b.Type {
+		case "text":
+			text.WriteString(b.Text)
+		case "tool_use":
+			out.ToolCalls = append(out.ToolCalls, ToolCall{ID: b.ID, Name: b.Name, Arguments: string(b.Input)})
+		}
+	}
+	out.Content = text.String()
+	return out, nil
+}
+
+func (p *AnthropicProvider) StreamComplete(ctx context.Context, messages []Message, opts Options) (events <-chan StreamEvent, err error) {
+	ctx, span := startCompletionSpan(ctx, "llm.messages", p.Name(), p.cfg.Model, p.baseURL(), true)
+	// The span stays open for the lifetime of the stream, so it is only ended
+	// here when the request never got that far.
+	streaming := false
+	defer func() {
+		if !streaming {
+			_ = endSpanWithError(span, err)
+		}
+	}()
+
+	req, err := p.newRequest(ctx, messages, opts, true)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("http request: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("api error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+
+	ch := make(chan StreamEvent, 64)
+	streaming = true
+	go func() {
+		defer close(ch)
+		defer func() { _ = resp.Body.Close() }()
+		usage, streamErr := readAnthropicStream(resp.Body, ch)
+		recordUsage(span, usage.InputTokens, usage.OutputTokens)
+		_ = endSpanWithError(span, streamErr)
+	}()
+
+	return ch, nil
+}
+
+// readAnthropicStream forwards text deltas from a Messages API SSE body and
+// ends with a Done event carrying any tool calls. Tool inputs arrive as
+// partial JSON fragments per content block and are joined here. It returns
+// the token usage reported by the stream and any error event the API sent,
+// which also ends the stream with FinishReason "error".
+func readAnthropicStream(r io.Reader, ch chan<- StreamEvent) (anthropicUsage, error) {
+	var usage anthropicUsage
+	var toolCalls []ToolCall
+	toolIndex := map[int]int{} // content block index -> toolCalls index
+	finish := ""
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		var evt anthropicStreamEvent
+		if err := json.Unmarshal([]byte(strings.TrimSpace(strings.TrimPrefix(line, "data:"))), &evt); err != nil {
+			continue
+		}
+		switch evt.Type {
+		case "message_start":
+			if evt.Message != nil {
+				usage.InputTokens = evt.Message.Usage.InputTokens
+			}
+		case "content_block_start":
+			if evt.ContentBlock != nil && evt.ContentBlock.Type == "tool_use" {
+				toolIndex[evt.Index] = len(toolCalls)
+				toolCalls = append(toolCalls, ToolCall{ID: evt.ContentBlock.ID, Name: evt.ContentBlock.Name})
+			}
+		case "content_block_delta":
+			switch evt.Delta.Type {
+			case "text_delta":
+				if evt.Delta.Text != "" {
+					ch <- StreamEvent{Delta: evt.Delta.Text}
+				}
+			case "input_json_delta":
+				if i, ok := toolIndex[evt.Index]; ok {
+					toolCalls[i].Arguments += evt.Delta.PartialJSON
+				}
+			}
+		case "message_delta":
+			if evt.Delta.StopReason != "" {
+				finish = anthropicFinishReason(evt.Delta.StopReason)
+			}
+			if evt.Usage != nil {
+				usage.OutputTokens = evt.Usage.OutputTokens
+			}
+		case "message_stop":
+			ch <- StreamEvent{Done: true, FinishReason: finish, ToolCalls: toolCalls}
+			return usage, nil
+		case "error":
+			msg := "unknown error"
+			if evt.Error != nil {
+				msg = evt.Error.Message
+			}
+			ch <- StreamEvent{Done: true, FinishReason: "error"}
+			return usage, fmt.Errorf("stream error: %s", msg)
+		}
+	}
+	ch <- StreamEvent{Done: true, FinishReason: finish, ToolCalls: toolCalls}
+	return usage, scanner.Err()
+}
@@ -0,0 +1,187 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAnthropicProviderComplete(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if got := r.Header.Get("x-api-key"); got != "test-key" {
+			t.Errorf("unexpected x-api-key: %q", got)
+		}
+		if got := r.Header.Get("anthropic-version"); got != anthropicVersion {
+			t.Errorf("unexpected anthropic-version: %q", got)
+		}
+		if r.Header.Get("Authorization") != "" {
+			t.Error("Authorization header must not be sent")
+		}
+
+		var req anthropicRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if req.System != "Be brief.\n\nUse tools." {
+			t.Errorf("system = %q, want the system messages joined", req.System)
+		}
+		if req.MaxTokens != 1024 || req.Model != "claude-test" {
+			t.Errorf("unexpected model or max tokens: %+v", req)
+		}
+		// user, assistant(text+tool_use), user(tool_result+text)
+		if len(req.Messages) != 3 {
+			t.Fatalf("expected 3 alternating messages, got %+v", req.Messages)
+		}
+		if a := req.Messages[1]; a.Role != "assistant" || len(a.Content) != 2 || a.Content[1].Type != "tool_use" ||
+			string(a.Content[1].Input) != `{"pool_id":1}` {
+			t.Errorf("assistant message = %+v", a)
+		}
+		if u := req.Messages[2]; u.Role != "user" || len(u.Content) != 2 ||
+			u.Content[0].Type != "tool_result" || u.Content[0].ToolUseID != "tu_1" || u.Content[1].Text != "thanks" {
+			t.Errorf("merged user message = %+v", u)
+		}
+		if len(req.Tools) != 1 || req.Tools[0].Name != "get_pool_gaps" || string(req.Tools[0].InputSchema) != `{"type":"object"}` {
+			t.Errorf("tools = %+v", req.Tools)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"content":[{"type":"text","text":"Checking."},
+			{"type":"tool_use","id":"tu_2","name":"get_pool_gaps","input":{"pool_id":2}}],
+			"stop_reason":"tool_use","usage":{"input_tokens":42,"output_tokens":7}}`)
+	}))
+	defer ts.Close()
+
+	provider := NewAnthropicProvider(Config{
+		APIKey: "test-key", Model: "claude-test", Endpoint: ts.URL + "/v1", MaxTokens: 1024,
+	})
+	if !provider.Available() || provider.Name() != "anthropic" {
+		t.Fatalf("provider = %s, available %v", provider.Name(), provider.Available())
+	}
+
+	resp, err := provider.Complete(context.Background(), []Message{
+		{Role: "system", Content: "Be brief."},
+		{Role: "system", Content: "Use tools."},
+		{Role: "user", Content: "gaps?"},
+		{Role: "assistant", Content: "Looking.", ToolCalls: []ToolCall{{ID: "tu_1", Name: "get_pool_gaps", Arguments: `{"pool_id":1}`}}},
+		{Role: "tool", ToolCallID: "tu_1", Content: `{"free":10}`},
+		{Role: "user", Content: "thanks"},
+	}, Options{Tools: []Tool{{Name: "get_pool_gaps", Parameters: json.RawMessage(`{"type":"object"}`)}}})
+	if err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	if resp.Content != "Checking." || resp.FinishReason != FinishToolCalls {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+	if resp.PromptTokens != 42 || resp.OutputTokens != 7 {
+		t.Errorf("tokens = %d/%d, want 42/7", resp.PromptTokens, resp.OutputTokens)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0] != (ToolCall{ID: "tu_2", Name: "get_pool_gaps", Arguments: `{"pool_id":2}`}) {
+		t.Errorf("tool calls = %+v", resp.ToolCalls)
+	}
+}
+
+func TestAnthropicProviderStreamComplete(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req anthropicRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if !req.Stream {
+			t.Error("expected stream=true")
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, evt := range []struct{ name, data string }{
+			{"message_start", `{"type":"message_start","message":{"usage":{"input_tokens":12,"output_tokens":1}}}`},
+			{"content_block_start", `{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`},
+			{"ping", `{"type":"ping"}`},
+			{"content_block_delta", `{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hello"}}`},
+			{"content_block_delta", `{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":" there"}}`},
+			{"content_block_stop", `{"type":"content_block_stop","index":0}`},
+			{"content_block_start", `{"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"tu_1","name":"search_pools","input":{}}}`},
+			{"content_block_delta", `{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"query\":"}}`},
+			{"content_block_delta", `{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"\"prod\"}"}}`},
+			{"content_block_stop", `{"type":"content_block_stop","index":1}`},
+			{"message_delta", `{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":30}}`},
+			{"message_stop", `{"type":"message_stop"}`},
+		} {
+			_, _ = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", evt.name, evt.data)
+		}
+	}))
+	defer ts.Close()
+
+	provider := NewAnthropicProvider(Config{APIKey: "k", Model: "claude-test", Endpoint: ts.URL, MaxTokens: 256})
+	ch, err := provider.StreamComplete(context.Background(), []Message{{Role: "user", Content: "hi"}}, Options{})
+	if err != nil {
+		t.Fatalf("stream complete: %v", err)
+	}
+
+	var content string
+	var final StreamEvent
+	for evt := range ch {
+		content += evt.Delta
+		if evt.Done {
+			final = evt
+			break
+		}
+	}
+	if content != "Hello there" {
+		t.Errorf("content = %q", content)
+	}
+	if final.FinishReason != FinishToolCalls || len(final.ToolCalls) != 1 {
+		t.Fatalf("final event = %+v", final)
+	}
+	if tc := final.ToolCalls[0]; tc.ID != "tu_1" || tc.Name != "search_pools" || tc.Arguments != `{"query":"prod"}` {
+		t.Errorf("tool call = %+v", tc)
+	}
+}
+
+func TestAnthropicProviderStreamErrorEvent(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprint(w, "event: content_block_delta\ndata: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"partial\"}}\n\n")
+		_, _ = fmt.Fprint(w, "event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n")
+	}))
+	defer ts.Close()
+
+	provider := NewAnthropicProvider(Config{APIKey: "k", Endpoint: ts.URL})
+	ch, err := provider.StreamComplete(context.Background(), []Message{{Role: "user", Content: "hi"}}, Options{})
+	if err != nil {
+		t.Fatalf("stream complete: %v", err)
+	}
+	var events []StreamEvent
+	for evt := range ch {
+		events = append(events, evt)
+	}
+	if len(events) != 2 || events[0].Delta != "partial" || !events[1].Done || events[1].FinishReason != "error" {
+		t.Fatalf("events = %+v", events)
+	}
+}
+
+func TestAnthropicProviderAPIError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = fmt.Fprint(w, `{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`)
+	}))
+	defer ts.Close()
+
+	provider := NewAnthropicProvider(Config{APIKey: "bad", Endpoint: ts.URL})
+	if _, err := provider.Complete(context.Background(), []Message{{Role: "user", Content: "hi"}}, Options{}); err == nil {
+		t.Fatal("expected error for 401")
+	}
+	if _, err := provider.StreamComplete(context.Background(), []Message{{Role: "user", Content: "hi"}}, Options{}); err == nil {
+		t.Fatal("expected stream error for 401")
+	}
+}
+
+func TestAnthropicProviderAvailability(t *testing.T) {
+	if NewAnthropicProvider(Config{}).Available() {
+		t.Error("expected unavailable without API key or endpoint")
+	}
+	if got := NewAnthropicProvider(Config{APIKey: "k"}).baseURL(); got != "https://api.anthropic.com/v1" {
+		t.Errorf("default base URL = %q", got)
+	}
+}
@@ -93,3 +93,39 @@ func TestConfigFromEnvNumericFallbacks(t *testing.T) {
 		})
 	}
 }
+
+func TestConfigFromEnvProviderSelection(t *testing.T) {
+	tests := []struct {
+		provider  string
+		model     string
+		wantName  string
+		wantModel string
+	}{
+		{provider: "", wantName: "openai", wantModel: "gpt-4o"},
+		{provider: "Anthropic", wantName: "anthropic", wantModel: "claude-sonnet-4-5"},
+		{provider: " ollama ", wantName: "ollama", wantModel: "llama3.1"},
+		{provider: "ollama", model: "qwen2.5", wantName: "ollama", wantModel: "qwen2.5"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.wantName+"/"+tc.wantModel, func(t *testing.T) {
+			t.Setenv("CLOUDPAM_LLM_PROVIDER", tc.provider)
+			t.Setenv("CLOUDPAM_LLM_MODEL", tc.model)
+
+			cfg := ConfigFromEnv()
+			if cfg.Model != tc.wantModel {
+				t.Errorf("Model = %q, want %q", cfg.Model, tc.wantModel)
+			}
+			p, err := NewProvider(cfg)
+			if err != nil {
+				t.Fatalf("NewProvider: %v", err)
+			}
+			if p.Name() != tc.wantName {
+				t.Errorf("provider = %q, want %q", p.Name(), tc.wantName)
+			}
+		})
+	}
+
+	if _, err := NewProvider(Config{Provider: "bard"}); err == nil {
+		t.Error("expected an error for an unknown provider")
+	}
+}
@@ -0,0 +1,287 @@
+package llm
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// OllamaProvider implements the Provider interface using Ollama's native
+// /api/chat endpoint, which streams newline-delimited JSON and reports
+// token counts that its OpenAI-compatible endpoint omits.
+type OllamaProvider struct {
+	cfg    Config
+	client *http.Client
+}
+
+// NewOllamaProvider creates a provider for an Ollama server.
+func NewOllamaProvider(cfg Config) *OllamaProvider {
+	return &OllamaProvider{
+		cfg:    cfg,
+		client: &http.Client{},
+	}
+}
+
+func (p *OllamaProvider) Name() string { return ProviderOllama }
+
+// Available reports true: Ollama needs no API key, and choosing it is the
+// configuration. An unreachable server surfaces as a request error.
+func (p *OllamaProvider) Available() bool { return true }
+
+func (p *OllamaProvider) baseURL() string {
+	if endpoint := strings.TrimSpace(p.cfg.Endpoint); endpoint != "" {
+		return strings.TrimRight(endpoint, "/")
+	}
+	return "http://localhost:11434"
+}
+
+// ollamaRequest is the request body for /api/chat.
+type ollamaRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+	Tools    []openaiTool    `json:"tools,omitempty"` // same shape as OpenAI's
+	Options  struct {
+		Temperature float64 `json:"temperature"`
+		NumPredict  int64   `json:"num_predict,omitempty"`
+	} `json:"options"`
+}
+
+type ollamaMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	ToolCalls []ollamaToolCall `json:"tool_calls,omitempty"`
+	ToolName  string           `json:"tool_name,omitempty"` // on "tool" messages
+}
+
+// ollamaToolCall is a function call. Unlike OpenAI, the arguments are a
+// JSON object rather than a string, and older servers send no ID.
+type ollamaToolCall struct {
+	ID       string `json:"id,omitempty"`
+	Function struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	} `json:"function"`
+}
+
+// ollamaResponse is a full response, or one line of a streamed one.
+type ollamaResponse struct {
+	Message         ollamaMessage `json:"message"`
+	Done            bool          `json:"done"`
+	DoneReason      string        `json:"done_reason"`
+	PromptEvalCount int64         `json:"prompt_eval_count"`
+	EvalCount       int64         `json:"eval_count"`
+	Error           string        `json:"error,omitempty"`
+}
+
+// newRequest builds the HTTP request for an /api/chat call.
+func (p *OllamaProvider) newRequest(ctx context.Context, messages []Message, opts Options, stream bool) (*http.Request, error) {
+	body := ollamaRequest{
+		Model:  p.cfg.Model,
+		Stream: stream,
+		Tools:  toOpenAITools(opts.Tools),
+	}
+	body.Options.Temperature = p.cfg.Temperature
+	if opts.Temperature != nil {
+		body.Options.Temperature = *opts.Temperature
+	}
+	body.Options.NumPredict = opts.MaxTokens
+	if body.Options.NumPredict == 0 {
+		body.Options.NumPredict = p.cfg.MaxTokens
+	}
+
+	// Tool results are matched to calls by name, so remember which call
+	// each ID belonged to.
+	toolNames := map[string]string{}
+	for _, m := range messages {
+		om := ollamaMessage{Role: m.Role, Content: m.Content}
+		for _, tc := range m.ToolCalls {
+			toolNames[tc.ID] = tc.Name
+			call := ollamaToolCall{ID: tc.ID}
+			call.Function.Name = tc.Name
+			call.Function.Arguments = json.RawMessage(tc.Arguments)
+			if !json.Valid(call.Function.Arguments) {
+				call.Function.Arguments = json.RawMessage("{}")
+			}
+			om.ToolCalls = append(om.ToolCalls, call)
+		}
+		if m.Role == "tool" {
+			om.ToolName = toolNames[m.ToolCallID]
+		}
+		body.Messages = append(body.Messages, om)
+	}
+
+	bodyJSON, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL()+"/api/chat", strings.NewReader(string(bodyJSON)))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey := strings.TrimSpace(p.cfg.APIKey); apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	return req, nil
+}
+
+// fromOllamaToolCalls converts calls, numbering those the server sent
+// without an ID. offset keeps numbers unique across streamed chunks.
+func fromOllamaToolCalls(calls []ollamaToolCall, offset int) []ToolCall {
+	out := make([]ToolCall, 0, len(calls))
+	for i, c := range calls {
+		id := c.ID
+		if id == "" {
+			id = fmt.Sprintf("call_%d", offset+i)
+		}
+		args := string(c.Function.Arguments)
+		if args == "" || args == "null" {
+			args = "{}"
+		}
+		out = append(out, ToolCall{ID: id, Name: c.Function.Name, Arguments: args})
+	}
+	return out
+}
+
+// ollamaFinishReason returns the shared finish reason for a final response.
+func ollamaFinishReason(doneReason string, toolCalls []ToolCall) string {
+	if len(toolCalls) > 0 {
+		return FinishToolCalls
+	}
+	if doneReason == "" {
+		return FinishStop
+	}
+	return doneReason // "stop" and "length" already match
+}
+
+func (p *OllamaProvider) Complete(ctx context.Context, messages []Message, opts Options) (out *Response, err error) {
+	ctx, span := startCompletionSpan(ctx, "llm.chat", p.Name(), p.cfg.Model, p.baseURL(), false)
+	defer func() { _ = endSpanWithError(span, err) }()
+
+	req, err := p.newRequest(ctx, messages, opts, false)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("http request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("api error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+
+	var oResp ollamaResponse
+	if err := json.Unmarshal(respBody, &oResp); err != nil {
+		return nil, fmt.Errorf("unmarshal response: %w", err)
+	}
+	if oResp.Error != "" {
+		return nil, fmt.Errorf("api error: %s", oResp.Error)
+	}
+
+	recordUsage(span, oResp.PromptEvalCount, oResp.EvalCount)
+
+	toolCalls := fromOllamaToolCalls(oResp.Message.ToolCalls, 0)
+	if len(toolCalls) == 0 {
+		toolCalls = nil
+	}
+	return &Response{
+		Content:      oResp.Message.Content,
+		FinishReason: ollamaFinishReason(oResp.DoneReason, toolCalls),
+		PromptTokens: oResp.PromptEvalCount,
+		OutputTokens: oResp.EvalCount,
+		ToolCalls:    toolCalls,
+	}, nil
+}
+
+func (p *OllamaProvider) StreamComplete(ctx context.Context, messages []Message, opts Options) (events <-chan StreamEvent, err error) {
+	ctx, span := startCompletionSpan(ctx, "llm.chat", p.Name(), p.cfg.Model, p.baseURL(), true)
+	// The span stays open for the lifetime of the stream, so it is only ended
+	// here when the request never got that far.
+	streaming := false
+	defer func() {
+		if !streaming {
+			_ = endSpanWithError(span, err)
+		}
+	}()
+
+	req, err := p.newRequest(ctx, messages, opts, true)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("http request: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("api error (status %d): %s", resp.StatusCode, string(respBody))
+	}
+
+	ch := make(chan StreamEvent, 64)
+	streaming = true
+	go func() {
+		defer close(ch)
+		defer func() { _ = resp.Body.Close() }()
+		final, streamErr := readOllamaStream(resp.Body, ch)
+		recordUsage(span, final.PromptEvalCount, final.EvalCount)
+		_ = endSpanWithError(span, streamErr)
+	}()
+
+	return ch, nil
+}
+
+// readOllamaStream forwards content from a newline-delimited JSON body.
+// Tool calls may arrive on any line; they are collected and delivered on
+// the final event. It returns the final line, which carries the token
+// counts, and any error the server reported mid-stream.
+func readOllamaStream(r io.Reader, ch chan<- StreamEvent) (ollamaResponse, error) {
+	var toolCalls []ToolCall
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var chunk ollamaResponse
+		if err := json.Unmarshal([]byte(line), &chunk); err != nil {
+			continue
+		}
+		if chunk.Error != "" {
+			ch <- StreamEvent{Done: true, FinishReason: "error"}
+			return chunk, fmt.Errorf("stream error: %s", chunk.Error)
+		}
+		toolCalls = append(toolCalls, fromOllamaToolCalls(chunk.Message.ToolCalls, len(toolCalls))...)
+		if chunk.Done {
+			ch <- StreamEvent{
+				Delta:        chunk.Message.Content,
+				Done:         true,
+				FinishReason: ollamaFinishReason(chunk.DoneReason, toolCalls),
+				ToolCalls:    toolCalls,
+			}
+			return chunk, nil
+		}
+		if chunk.Message.Content != "" {
+			ch <- StreamEvent{Delta: chunk.Message.Content}
+		}
+	}
+	ch <- StreamEvent{Done: true, FinishReason: ollamaFinishReason("", toolCalls), ToolCalls: toolCalls}
+	return ollamaResponse{}, scanner.Err()
+}
@@ -0,0 +1,161 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOllamaProviderComplete(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "" {
+			t.Error("no Authorization header expected without an API key")
+		}
+
+		var req ollamaRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if req.Stream || req.Model != "llama3.1" {
+			t.Errorf("unexpected request: %+v", req)
+		}
+		if req.Options.Temperature != 0 || req.Options.NumPredict != 300 {
+			t.Errorf("options = %+v, want the temperature override and max tokens", req.Options)
+		}
+		if len(req.Messages) != 3 {
+			t.Fatalf("messages = %+v", req.Messages)
+		}
+		if a := req.Messages[1]; len(a.ToolCalls) != 1 || string(a.ToolCalls[0].Function.Arguments) != `{"pool_id":1}` {
+			t.Errorf("assistant message = %+v, want arguments sent as an object", a)
+		}
+		if tool := req.Messages[2]; tool.Role != "tool" || tool.ToolName != "get_pool_gaps" {
+			t.Errorf("tool message = %+v, want the tool name resolved from the call", tool)
+		}
+		if len(req.Tools) != 1 || req.Tools[0].Function.Name != "get_pool_gaps" {
+			t.Errorf("tools = %+v", req.Tools)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"model":"llama3.1","message":{"role":"assistant","content":"",
+			"tool_calls":[{"function":{"name":"get_pool_gaps","arguments":{"pool_id":2}}}]},
+			"done":true,"done_reason":"stop","prompt_eval_count":55,"eval_count":9}`)
+	}))
+	defer ts.Close()
+
+	provider := NewOllamaProvider(Config{Model: "llama3.1", Endpoint: ts.URL + "/", MaxTokens: 300, Temperature: 0.7})
+	if !provider.Available() || provider.Name() != "ollama" {
+		t.Fatalf("provider = %s, available %v", provider.Name(), provider.Available())
+	}
+	zero := 0.0
+	resp, err := provider.Complete(context.Background(), []Message{
+		{Role: "user", Content: "gaps?"},
+		{Role: "assistant", ToolCalls: []ToolCall{{ID: "c1", Name: "get_pool_gaps", Arguments: `{"pool_id":1}`}}},
+		{Role: "tool", ToolCallID: "c1", Content: `{"free":10}`},
+	}, Options{Temperature: &zero, Tools: []Tool{{Name: "get_pool_gaps", Parameters: json.RawMessage(`{"type":"object"}`)}}})
+	if err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	if resp.PromptTokens != 55 || resp.OutputTokens != 9 {
+		t.Errorf("tokens = %d/%d, want 55/9", resp.PromptTokens, resp.OutputTokens)
+	}
+	if resp.FinishReason != FinishToolCalls || len(resp.ToolCalls) != 1 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if tc := resp.ToolCalls[0]; tc.ID != "call_0" || tc.Name != "get_pool_gaps" || tc.Arguments != `{"pool_id":2}` {
+		t.Errorf("tool call = %+v, want a generated ID and string arguments", tc)
+	}
+}
+
+func TestOllamaProviderStreamComplete(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		for _, line := range []string{
+			`{"message":{"role":"assistant","content":"Hello"},"done":false}`,
+			`{"message":{"role":"assistant","content":" from"},"done":false}`,
+			`{"message":{"role":"assistant","content":" llama"},"done":false}`,
+			`{"message":{"role":"assistant","content":""},"done":true,"done_reason":"length","prompt_eval_count":20,"eval_count":3}`,
+		} {
+			_, _ = fmt.Fprintln(w, line)
+		}
+	}))
+	defer ts.Close()
+
+	provider := NewOllamaProvider(Config{Model: "llama3.1", Endpoint: ts.URL})
+	ch, err := provider.StreamComplete(context.Background(), []Message{{Role: "user", Content: "hi"}}, Options{})
+	if err != nil {
+		t.Fatalf("stream complete: %v", err)
+	}
+	var content string
+	var final StreamEvent
+	for evt := range ch {
+		content += evt.Delta
+		if evt.Done {
+			final = evt
+			break
+		}
+	}
+	if content != "Hello from llama" {
+		t.Errorf("content = %q", content)
+	}
+	if final.FinishReason != FinishLength || final.ToolCalls != nil {
+		t.Errorf("final event = %+v", final)
+	}
+}
+
+func TestOllamaProviderStreamToolCalls(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintln(w, `{"message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"search_pools","arguments":{"query":"prod"}}}]},"done":false}`)
+		_, _ = fmt.Fprintln(w, `{"message":{"role":"assistant","content":"","tool_calls":[{"id":"x","function":{"name":"get_pool_gaps","arguments":{"pool_id":3}}}]},"done":false}`)
+		_, _ = fmt.Fprintln(w, `{"message":{"role":"assistant","content":""},"done":true,"done_reason":"stop"}`)
+	}))
+	defer ts.Close()
+
+	provider := NewOllamaProvider(Config{Endpoint: ts.URL})
+	ch, err := provider.StreamComplete(context.Background(), []Message{{Role: "user", Content: "hi"}}, Options{})
+	if err != nil {
+		t.Fatalf("stream complete: %v", err)
+	}
+	var events []StreamEvent
+	for evt := range ch {
+		events = append(events, evt)
+	}
+	if len(events) != 1 {
+		t.Fatalf("events = %+v, want only the final event", events)
+	}
+	want := []ToolCall{
+		{ID: "call_0", Name: "search_pools", Arguments: `{"query":"prod"}`},
+		{ID: "x", Name: "get_pool_gaps", Arguments: `{"pool_id":3}`},
+	}
+	got := events[0]
+	if got.FinishReason != FinishToolCalls || len(got.ToolCalls) != len(want) {
+		t.Fatalf("final event = %+v", got)
+	}
+	for i := range want {
+		if got.ToolCalls[i] != want[i] {
+			t.Errorf("tool call %d = %+v, want %+v", i, got.ToolCalls[i], want[i])
+		}
+	}
+}
+
+func TestOllamaProviderErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = fmt.Fprint(w, `{"error":"model \"nope\" not found, try pulling it first"}`)
+	}))
+	defer ts.Close()
+
+	provider := NewOllamaProvider(Config{Model: "nope", Endpoint: ts.URL})
+	if _, err := provider.Complete(context.Background(), []Message{{Role: "user", Content: "hi"}}, Options{}); err == nil {
+		t.Fatal("expected error for 404")
+	}
+
+	if got := NewOllamaProvider(Config{}).baseURL(); got != "http://localhost:11434" {
+		t.Errorf("default base URL = %q", got)
+	}
+}
@@ -9,35 +9,11 @@ import (
 	"strings"
 
 	"go.opentelemetry.io/otel/attribute"
-	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
-
-	"cloudpam/internal/observability"
 )
 
-// startCompletionSpan opens a client span around a chat completion call. When
-// tracing is disabled the shared tracer is the OpenTelemetry no-op, so this is
-// an interface call against a network round trip.
 func (p *OpenAIProvider) startCompletionSpan(ctx context.Context, stream bool) (context.Context, trace.Span) {
-	return observability.Tracer().Start(ctx, "llm.chat.completions",
-		trace.WithSpanKind(trace.SpanKindClient),
-		trace.WithAttributes(
-			attribute.String("llm.provider", p.Name()),
-			attribute.String("llm.model", p.cfg.Model),
-			attribute.String("server.address", p.baseURL()),
-			attribute.Bool("llm.stream", stream),
-		),
-	)
-}
-
-// endSpanWithError records err on the span and closes it.
-func endSpanWithError(span trace.Span, err error) error {
-	if err != nil {
-		span.RecordError(err)
-		span.SetStatus(codes.Error, err.Error())
-	}
-	span.End()
-	return err
+	return startCompletionSpan(ctx, "llm.chat.completions", p.Name(), p.cfg.Model, p.baseURL(), stream)
 }
 
 // OpenAIProvider implements the Provider interface using the OpenAI-compatible
@@ -75,11 +51,37 @@ type openaiRequest struct {
 	MaxTokens   int64           `json:"max_tokens,omitempty"`
 	Temperature float64         `json:"temperature"`
 	Stream      bool            `json:"stream"`
+	Tools       []openaiTool    `json:"tools,omitempty"`
 }
 
 type openaiMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role       string           `json:"role"`
+	Content    string           `json:"content"`
+	ToolCalls  []openaiToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string           `json:"tool_call_id,omitempty"`
+}
+
+// openaiTool declares a function the model may call.
+type openaiTool struct {
+	Type     string `json:"type"` // always "function"
+	Function struct {
+		Name        string          `json:"name"`
+		Description string          `json:"description,omitempty"`
+		Parameters  json.RawMessage `json:"parameters,omitempty"`
+	} `json:"function"`
+}
+
+// openaiToolCall is a function call in an assistant message. In stream
+// chunks the call arrives in pieces that share an Index; only the first
+// piece carries the ID and name.
+type openaiToolCall struct {
+	Index    *int   `json:"index,omitempty"`
+	ID       string `json:"id,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Function struct {
+		Name      string `json:"name,omitempty"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
 }
 
 // openaiResponse is the response body for non-streaming completions.
@@ -101,7 +103,8 @@ type openaiResponse struct {
 type openaiStreamChunk struct {
 	Choices []struct {
 		Delta struct {
-			Content string `json:"content"`
+			Content   string           `json:"content"`
+			ToolCalls []openaiToolCall `json:"tool_calls"`
 		} `json:"delta"`
 		FinishReason *string `json:"finish_reason"`
 	} `json:"choices"`
@@ -120,17 +123,13 @@ func (p *OpenAIProvider) Complete(ctx context.Context, messages []Message, opts
 		temp = *opts.Temperature
 	}
 
-	oaiMsgs := make([]openaiMessage, len(messages))
-	for i, m := range messages {
-		oaiMsgs[i] = openaiMessage(m)
-	}
-
 	body := openaiRequest{
 		Model:       p.cfg.Model,
-		Messages:    oaiMsgs,
+		Messages:    toOpenAIMessages(messages),
 		MaxTokens:   maxTokens,
 		Temperature: temp,
 		Stream:      false,
+		Tools:       toOpenAITools(opts.Tools),
 	}
 
 	bodyJSON, err := json.Marshal(body)
@@ -174,19 +173,58 @@ func (p *OpenAIProvider) Complete(ctx context.Context, messages []Message, opts
 		return nil, fmt.Errorf("no choices in response")
 	}
 
-	span.SetAttributes(
-		attribute.Int64("llm.usage.prompt_tokens", oaiResp.Usage.PromptTokens),
-		attribute.Int64("llm.usage.completion_tokens", oaiResp.Usage.CompletionTokens),
-	)
+	recordUsage(span, oaiResp.Usage.PromptTokens, oaiResp.Usage.CompletionTokens)
 
 	return &Response{
 		Content:      oaiResp.Choices[0].Message.Content,
 		FinishReason: oaiResp.Choices[0].FinishReason,
 		PromptTokens: oaiResp.Usage.PromptTokens,
 		OutputTokens: oaiResp.Usage.CompletionTokens,
+		ToolCalls:    fromOpenAIToolCalls(oaiResp.Choices[0].Message.ToolCalls),
 	}, nil
 }
 
+// toOpenAIMessages converts messages to the wire format.
+func toOpenAIMessages(messages []Message) []openaiMessage {
+	out := make([]openaiMessage, len(messages))
+	for i, m := range messages {
+		out[i] = openaiMessage{Role: m.Role, Content: m.Content, ToolCallID: m.ToolCallID}
+		for _, tc := range m.ToolCalls {
+			call := openaiToolCall{ID: tc.ID, Type: "function"}
+			call.Function.Name = tc.Name
+			call.Function.Arguments = tc.Arguments
+			out[i].ToolCalls = append(out[i].ToolCalls, call)
+		}
+	}
+	return out
+}
+
+// toOpenAITools converts tool declarations to the wire format.
+func toOpenAITools(tools []Tool) []openaiTool {
+	if len(tools) == 0 {
+		return nil
+	}
+	out := make([]openaiTool, len(tools))
+	for i, t := range tools {
+		out[i].Type = "function"
+		out[i].Function.Name = t.Name
+		out[i].Function.Description = t.Description
+		out[i].Function.Parameters = t.Parameters
+	}
+	return out
+}
+
+func fromOpenAIToolCalls(calls []openaiToolCall) []ToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+	out := make([]ToolCall, len(calls))
+	for i, c := range calls {
+		out[i] = ToolCall{ID: c.ID, Name: c.Function.Name, Arguments: c.Function.Arguments}
+	}
+	return out
+}
+
 func (p *OpenAIProvider) StreamComplete(ctx context.Context, messages []Message, opts Options) (events <-chan StreamEvent, err error) {
 	ctx, span := p.startCompletionSpan(ctx, true)
 	// The span stays open for the lifetime of the stream, so it is only ended
@@ -207,17 +245,13 @@ func (p *OpenAIProvider) StreamComplete(ctx context.Context, messages []Message,
 		temp = *opts.Temperature
 	}
 
-	oaiMsgs := make([]openaiMessage, len(messages))
-	for i, m := range messages {
-		oaiMsgs[i] = openaiMessage(m)
-	}
-
 	body := openaiRequest{
 		Model:       p.cfg.Model,
-		Messages:    oaiMsgs,
+		Messages:    toOpenAIMessages(messages),
 		MaxTokens:   maxTokens,
 		Temperature: temp,
 		Stream:      true,
+		Tools:       toOpenAITools(opts.Tools),
 	}
 
 	bodyJSON, err := json.Marshal(body)
@@ -259,9 +293,12 @@ func (p *OpenAIProvider) StreamComplete(ctx context.Context, messages []Message,
 	return ch, nil
 }
 
+// readSSEStream forwards content deltas from an SSE body. Tool call
+// fragments are assembled by index and delivered on the final event.
 func (p *OpenAIProvider) readSSEStream(r io.Reader, ch chan<- StreamEvent) {
 	buf := make([]byte, 4096)
 	var lineBuf strings.Builder
+	var toolCalls []ToolCall
 
 	for {
 		n, err := r.Read(buf)
@@ -298,12 +335,33 @@ func (p *OpenAIProvider) readSSEStream(r io.Reader, ch chan<- StreamEvent) {
 					continue
 				}
 
+				for _, frag := range chunk.Choices[0].Delta.ToolCalls {
+					idx := len(toolCalls)
+					if frag.Index != nil {
+						idx = *frag.Index
+					}
+					for len(toolCalls) <= idx {
+						toolCalls = append(toolCalls, ToolCall{})
+					}
+					if frag.ID != "" {
+						toolCalls[idx].ID = frag.ID
+					}
+					if frag.Function.Name != "" {
+						toolCalls[idx].Name = frag.Function.Name
+					}
+					toolCalls[idx].Arguments += frag.Function.Arguments
+				}
+
 				evt := StreamEvent{
 					Delta: chunk.Choices[0].Delta.Content,
 				}
 				if chunk.Choices[0].FinishReason != nil {
 					evt.FinishReason = *chunk.Choices[0].FinishReason
 					evt.Done = true
+					evt.ToolCalls = toolCalls
+				}
+				if evt.Delta == "" && !evt.Done {
+					continue
 				}
 				ch <- evt
 			}
@@ -305,3 +305,107 @@ func TestOpenAIProviderTemperatureOverride(t *testing.T) {
 		t.Errorf("expected configured default temperature 0.7, got %v", got)
 	}
 }
+
+func TestOpenAIProviderCompleteWithTools(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var raw map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		tools, _ := raw["tools"].([]any)
+		if len(tools) != 1 {
+			t.Fatalf("expected 1 tool, got %v", raw["tools"])
+		}
+		fn := tools[0].(map[string]any)["function"].(map[string]any)
+		if tools[0].(map[string]any)["type"] != "function" || fn["name"] != "get_pool_gaps" {
+			t.Errorf("unexpected tool: %v", tools[0])
+		}
+		if params, _ := fn["parameters"].(map[string]any); params["type"] != "object" {
+			t.Errorf("parameters not sent as a schema object: %v", fn["parameters"])
+		}
+
+		msgs := raw["messages"].([]any)
+		assistant := msgs[1].(map[string]any)
+		calls, _ := assistant["tool_calls"].([]any)
+		if len(calls) != 1 || calls[0].(map[string]any)["id"] != "call-0" {
+			t.Errorf("assistant tool calls not sent: %v", assistant)
+		}
+		if tool := msgs[2].(map[string]any); tool["role"] != "tool" || tool["tool_call_id"] != "call-0" {
+			t.Errorf("tool result not linked to its call: %v", tool)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"","tool_calls":[
+			{"id":"call-1","type":"function","function":{"name":"get_pool_gaps","arguments":"{\"pool_id\":7}"}}]},
+			"finish_reason":"tool_calls"}]}`)
+	}))
+	defer ts.Close()
+
+	provider := NewOpenAIProvider(Config{APIKey: "k", Model: "gpt-4o", Endpoint: ts.URL})
+	resp, err := provider.Complete(context.Background(), []Message{
+		{Role: "user", Content: "gaps?"},
+		{Role: "assistant", ToolCalls: []ToolCall{{ID: "call-0", Name: "get_pool_gaps", Arguments: `{"pool_id":1}`}}},
+		{Role: "tool", ToolCallID: "call-0", Content: `{"error":"not found"}`},
+	}, Options{Tools: []Tool{{
+		Name:        "get_pool_gaps",
+		Description: "free space",
+		Parameters:  json.RawMessage(`{"type":"object","properties":{"pool_id":{"type":"integer"}}}`),
+	}}})
+	if err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	if resp.FinishReason != "tool_calls" || len(resp.ToolCalls) != 1 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	if tc := resp.ToolCalls[0]; tc.ID != "call-1" || tc.Name != "get_pool_gaps" || tc.Arguments != `{"pool_id":7}` {
+		t.Errorf("unexpected tool call: %+v", tc)
+	}
+}
+
+func TestOpenAIProviderStreamAssemblesToolCalls(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, data := range []string{
+			`{"choices":[{"delta":{"role":"assistant","content":"Let me check. "},"finish_reason":null}]}`,
+			`{"choices":[{"delta":{"tool_calls":[{"index":0,"id":"a","type":"function","function":{"name":"search_pools","arguments":""}}]},"finish_reason":null}]}`,
+			`{"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"query\":"}}]},"finish_reason":null}]}`,
+			`{"choices":[{"delta":{"tool_calls":[{"index":1,"id":"b","type":"function","function":{"name":"get_pool_gaps","arguments":"{\"pool_id\":2}"}}]},"finish_reason":null}]}`,
+			`{"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"prod\"}"}}]},"finish_reason":null}]}`,
+			`{"choices":[{"delta":{},"finish_reason":"tool_calls"}]}`,
+		} {
+			_, _ = fmt.Fprintf(w, "data: %s\n\n", data)
+		}
+		_, _ = fmt.Fprintf(w, "data: [DONE]\n\n")
+	}))
+	defer ts.Close()
+
+	provider := NewOpenAIProvider(Config{APIKey: "k", Model: "gpt-4o", Endpoint: ts.URL})
+	ch, err := provider.StreamComplete(context.Background(), []Message{{Role: "user", Content: "hi"}}, Options{})
+	if err != nil {
+		t.Fatalf("stream complete: %v", err)
+	}
+
+	var events []StreamEvent
+	for evt := range ch {
+		events = append(events, evt)
+		if evt.Done {
+			break
+		}
+	}
+	if len(events) != 2 || events[0].Delta != "Let me check. " {
+		t.Fatalf("expected one content delta and the final event, got %+v", events)
+	}
+	final := events[1]
+	if final.FinishReason != "tool_calls" || len(final.ToolCalls) != 2 {
+		t.Fatalf("unexpected final event: %+v", final)
+	}
+	want := []ToolCall{
+		{ID: "a", Name: "search_pools", Arguments: `{"query":"prod"}`},
+		{ID: "b", Name: "get_pool_gaps", Arguments: `{"pool_id":2}`},
+	}
+	for i, tc := range final.ToolCalls {
+		if tc != want[i] {
+			t.Errorf("tool call %d = %+v, want %+v", i, tc, want[i])
+		}
+	}
+}
@@ -2,14 +2,38 @@ package llm
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
 )
 
 // Message represents a chat message sent to or received from the LLM.
 type Message struct {
-	Role    string `json:"role"` // "system", "user", "assistant"
+	Role    string `json:"role"` // "system", "user", "assistant", "tool"
 	Content string `json:"content"`
+	// ToolCalls lists the tools an assistant message asked to run.
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+	// ToolCallID links a "tool" message to the call whose result it carries.
+	ToolCallID string `json:"tool_call_id,omitempty"`
+}
+
+// Tool describes a function the model may call instead of answering.
+type Tool struct {
+	Name        string
+	Description string
+	// Parameters is the JSON Schema of the tool's argument object.
+	Parameters json.RawMessage
+}
+
+// ToolCall is a model's request to run a tool. Arguments is the JSON
+// object the model produced, unparsed; it is not guaranteed to match the
+// tool's schema.
+type ToolCall struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
 }
 
 // Options configures a single LLM completion request.
@@ -19,14 +43,27 @@ type Options struct {
 	// Temperature is a pointer so that an explicit 0 (fully deterministic
 	// sampling) is distinguishable from "not specified".
 	Temperature *float64
+	// Tools are offered to the model for this request. When the model
+	// calls one, the completion ends with ToolCalls set and the caller is
+	// expected to reply with a "tool" message per call.
+	Tools []Tool
 }
 
+// Finish reasons reported by every provider. Providers translate their
+// native stop reasons into these; anything else is passed through as is.
+const (
+	FinishStop      = "stop"
+	FinishLength    = "length"
+	FinishToolCalls = "tool_calls"
+)
+
 // Response is the result of a non-streaming completion.
 type Response struct {
 	Content      string
-	FinishReason string
+	FinishReason string // see FinishStop, FinishLength, FinishToolCalls
 	PromptTokens int64
 	OutputTokens int64
+	ToolCalls    []ToolCall
 }
 
 // StreamEvent is a single chunk from a streaming completion.
@@ -34,9 +71,15 @@ type StreamEvent struct {
 	Delta        string // incremental text content
 	Done         bool   // true when the stream is finished
 	FinishReason string
+	ToolCalls    []ToolCall // set on the final event when the model called tools
 }
 
 // Provider abstracts an LLM backend (OpenAI, Ollama, vLLM, etc.).
+//
+// Both completion methods offer opts.Tools to the model. A model that calls
+// tools ends its turn with ToolCalls on the Response (or on the final
+// StreamEvent); the caller runs them, appends the assistant message and one
+// "tool" message per call, and completes again.
 type Provider interface {
 	// Complete sends messages and returns a full response.
 	Complete(ctx context.Context, messages []Message, opts Options) (*Response, error)
@@ -51,8 +94,16 @@ type Provider interface {
 	Available() bool
 }
 
+// Provider names accepted in Config.Provider (CLOUDPAM_LLM_PROVIDER).
+const (
+	ProviderOpenAI    = "openai"
+	ProviderAnthropic = "anthropic"
+	ProviderOllama    = "ollama"
+)
+
 // Config holds LLM provider configuration.
 type Config struct {
+	Provider    string // openai (default), anthropic or ollama
 	APIKey      string
 	Model       string
 	Endpoint    string // base URL override (for Ollama, vLLM, Azure)
@@ -60,8 +111,21 @@ type Config struct {
 	Temperature float64
 }
 
+// defaultModels is the model used per provider when CLOUDPAM_LLM_MODEL is
+// unset.
+var defaultModels = map[string]string{
+	ProviderOpenAI:    "gpt-4o",
+	ProviderAnthropic: "claude-sonnet-4-5",
+	ProviderOllama:    "llama3.1",
+}
+
 // ConfigFromEnv reads LLM configuration from environment variables.
 func ConfigFromEnv() Config {
+	provider := strings.ToLower(strings.TrimSpace(os.Getenv("CLOUDPAM_LLM_PROVIDER")))
+	if provider == "" {
+		provider = ProviderOpenAI
+	}
+
 	maxTokens := int64(4096)
 	if v := os.Getenv("CLOUDPAM_LLM_MAX_TOKENS"); v != "" {
 		if n, err := strconv.ParseInt(v, 10, 64); err == nil && n > 0 {
@@ -78,10 +142,11 @@ func ConfigFromEnv() Config {
 
 	model := os.Getenv("CLOUDPAM_LLM_MODEL")
 	if model == "" {
-		model = "gpt-4o"
+		model = defaultModels[provider]
 	}
 
 	return Config{
+		Provider:    provider,
 		APIKey:      os.Getenv("CLOUDPAM_LLM_API_KEY"),
 		Model:       model,
 		Endpoint:    os.Getenv("CLOUDPAM_LLM_ENDPOINT"),
@@ -89,3 +154,18 @@ func ConfigFromEnv() Config {
 		Temperature: temperature,
 	}
 }
+
+// NewProvider returns the provider selected by cfg.Provider. An empty name
+// selects OpenAI.
+func NewProvider(cfg Config) (Provider, error) {
+	switch cfg.Provider {
+	case "", ProviderOpenAI:
+		return NewOpenAIProvider(cfg), nil
+	case ProviderAnthropic:
+		return NewAnthropicProvider(cfg), nil
+	case ProviderOllama:
+		return NewOllamaProvider(cfg), nil
+	}
+	return nil, fmt.Errorf("unknown LLM provider %q (want %s, %s or %s)",
+		cfg.Provider, ProviderOpenAI, ProviderAnthropic, ProviderOllama)
+}
@@ -0,0 +1,92 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// ScriptedCall records one request made to a ScriptedProvider.
+type ScriptedCall struct {
+	Messages []Message
+	Options  Options
+}
+
+// ScriptedProvider is an in-process Provider that replays a fixed list of
+// turns instead of calling a model. Each Complete or StreamComplete call
+// consumes the next turn, so tests can drive a tool-calling conversation
+// deterministically.
+type ScriptedProvider struct {
+	mu    sync.Mutex
+	turns []Response
+	calls []ScriptedCall
+}
+
+// NewScriptedProvider returns a provider that answers with turns in order.
+func NewScriptedProvider(turns ...Response) *ScriptedProvider {
+	return &ScriptedProvider{turns: turns}
+}
+
+func (p *ScriptedProvider) Name() string    { return "scripted" }
+func (p *ScriptedProvider) Available() bool { return true }
+
+// Calls returns the requests received so far.
+func (p *ScriptedProvider) Calls() []ScriptedCall {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]ScriptedCall(nil), p.calls...)
+}
+
+// next records the request and pops the next turn. A turn that calls a
+// tool the request did not offer is an error, as a real API would reject
+// it.
+func (p *ScriptedProvider) next(messages []Message, opts Options) (*Response, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls = append(p.calls, ScriptedCall{
+		Messages: append([]Message(nil), messages...),
+		Options:  opts,
+	})
+	if len(p.turns) == 0 {
+		return nil, fmt.Errorf("scripted provider: no turns left (call %d)", len(p.calls))
+	}
+	turn := p.turns[0]
+	p.turns = p.turns[1:]
+	for _, tc := range turn.ToolCalls {
+		offered := false
+		for _, t := range opts.Tools {
+			if t.Name == tc.Name {
+				offered = true
+				break
+			}
+		}
+		if !offered {
+			return nil, fmt.Errorf("scripted provider: tool %q was not offered", tc.Name)
+		}
+	}
+	if turn.FinishReason == "" {
+		turn.FinishReason = FinishStop
+		if len(turn.ToolCalls) > 0 {
+			turn.FinishReason = FinishToolCalls
+		}
+	}
+	return &turn, nil
+}
+
+func (p *ScriptedProvider) Complete(_ context.Context, messages []Message, opts Options) (*Response, error) {
+	return p.next(messages, opts)
+}
+
+func (p *ScriptedProvider) StreamComplete(_ context.Context, messages []Message, opts Options) (<-chan StreamEvent, error) {
+	turn, err := p.next(messages, opts)
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan StreamEvent, 2)
+	if turn.Content != "" {
+		ch <- StreamEvent{Delta: turn.Content}
+	}
+	ch <- StreamEvent{Done: true, FinishReason: turn.FinishReason, ToolCalls: turn.ToolCalls}
+	close(ch)
+	return ch, nil
+}
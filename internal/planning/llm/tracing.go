@@ -0,0 +1,44 @@
+package llm
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"cloudpam/internal/observability"
+)
+
+// startCompletionSpan opens a client span around a completion call. When
+// tracing is disabled the shared tracer is the OpenTelemetry no-op, so this is
+// an interface call against a network round trip.
+func startCompletionSpan(ctx context.Context, name, provider, model, server string, stream bool) (context.Context, trace.Span) {
+	return observability.Tracer().Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("llm.provider", provider),
+			attribute.String("llm.model", model),
+			attribute.String("server.address", server),
+			attribute.Bool("llm.stream", stream),
+		),
+	)
+}
+
+// recordUsage sets the token counts of a finished completion on its span.
+func recordUsage(span trace.Span, promptTokens, outputTokens int64) {
+	span.SetAttributes(
+		attribute.Int64("llm.usage.prompt_tokens", promptTokens),
+		attribute.Int64("llm.usage.completion_tokens", outputTokens),
+	)
+}
+
+// endSpanWithError records err on the span and closes it.
+func endSpanWithError(span trace.Span, err error) error {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+	return err
+}
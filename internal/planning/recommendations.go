@@ -8,15 +8,21 @@ import (
 
 	"github.com/google/uuid"
 
+	"cloudpam/internal/cidr"
 	"cloudpam/internal/domain"
 	"cloudpam/internal/storage"
+	"cloudpam/internal/webhook"
 )
 
 // RecommendationService generates, applies, and dismisses recommendations.
 type RecommendationService struct {
-	analysis  *AnalysisService
-	store     storage.RecommendationStore
-	mainStore storage.Store
+	analysis    *AnalysisService
+	store       storage.RecommendationStore
+	mainStore   storage.Store
+	publisher   webhook.Publisher
+	utilization *UtilizationService
+	discovery   storage.DiscoveryStore
+	poolChanges storage.PoolChangeRequestStore
 }
 
 // NewRecommendationService creates a new RecommendationService.
@@ -28,6 +34,12 @@ func NewRecommendationService(analysis *AnalysisService, store storage.Recommend
 	}
 }
 
+// SetPublisher publishes a recommendation.generated webhook event after every
+// Generate call that produces recommendations.
+func (s *RecommendationService) SetPublisher(p webhook.Publisher) {
+	s.publisher = p
+}
+
 // Generate creates recommendations for the given pools by running analysis.
 func (s *RecommendationService) Generate(ctx context.Context, req domain.GenerateRecommendationsRequest) (*domain.GenerateRecommendationsResponse, error) {
 	pools, err := s.analysis.resolvePools(ctx, req.PoolIDs, req.IncludeChildren)
@@ -83,19 +95,40 @@ func (s *RecommendationService) Generate(ctx context.Context, req domain.Generat
 				allRecs = append(allRecs, rec)
 			}
 		}
+
+		// Pool structure → reclaim, resize and consolidation recommendations.
+		structural, err := s.structureRecommendations(ctx, pool, now)
+		if err != nil {
+			return nil, err
+		}
+		for _, rec := range structural {
+			if err := s.store.CreateRecommendation(ctx, rec); err != nil {
+				return nil, fmt.Errorf("create %s rec: %w", rec.Type, err)
+			}
+			allRecs = append(allRecs, rec)
+		}
 	}
 
 	if allRecs == nil {
 		allRecs = []domain.Recommendation{}
 	}
 
-	return &domain.GenerateRecommendationsResponse{
+	resp := &domain.GenerateRecommendationsResponse{
 		Items: allRecs,
 		Total: len(allRecs),
-	}, nil
+	}
+	if s.publisher != nil && len(allRecs) > 0 {
+		s.publisher.Publish(ctx, domain.WebhookEventRecommendationGenerated, webhook.NewRecommendationsGeneratedData(resp))
+	}
+	return resp, nil
 }
 
-// Apply applies a recommendation: for allocation types, creates a new child pool.
+// Apply applies a recommendation: allocations create a child pool, reclaims
+// delete the pool, resizes move it to the suggested CIDR and consolidations
+// nest the paired blocks under a new aggregate pool. The returned
+// recommendation lists the pool changes made. A pool that enforced
+// compliance rules reject is not created and Apply returns an
+// *AdmissionError.
 func (s *RecommendationService) Apply(ctx context.Context, id string, req domain.ApplyRecommendationRequest) (*domain.Recommendation, error) {
 	rec, err := s.store.GetRecommendation(ctx, id)
 	if err != nil {
@@ -106,6 +139,7 @@ func (s *RecommendationService) Apply(ctx context.Context, id string, req domain
 	}
 
 	var appliedPoolID *int64
+	var changes []domain.RecommendationChange
 
 	switch rec.Type {
 	case domain.RecommendationTypeAllocation:
@@ -114,20 +148,50 @@ func (s *RecommendationService) Apply(ctx context.Context, id string, req domain
 			name = fmt.Sprintf("Allocation %s", rec.SuggestedCIDR)
 		}
 		parentID := rec.PoolID
-		newPool, err := s.mainStore.CreatePool(ctx, domain.CreatePool{
+		in := domain.CreatePool{
 			Name:      name,
 			CIDR:      rec.SuggestedCIDR,
 			ParentID:  &parentID,
 			AccountID: req.AccountID,
 			Source:    domain.PoolSourceManual,
-		})
+		}
+		if err := s.analysis.admit(ctx, in); err != nil {
+			return nil, err
+		}
+		newPool, err := s.mainStore.CreatePool(ctx, in)
 		if err != nil {
 			return nil, fmt.Errorf("create pool from recommendation: %w", err)
 		}
 		appliedPoolID = &newPool.ID
+		changes = append(changes, domain.RecommendationChange{
+			Action: "create",
+			PoolID: newPool.ID,
+			Name:   newPool.Name,
+			After:  map[string]any{"cidr": newPool.CIDR, "parent_id": parentID},
+		})
 
 	case domain.RecommendationTypeCompliance:
 		// Compliance fixes are manual; marking as applied acknowledges the issue.
+
+	case domain.RecommendationTypeReclaim:
+		changes, err = s.applyReclaim(ctx, rec)
+		if err != nil {
+			return nil, err
+		}
+
+	case domain.RecommendationTypeResize:
+		changes, err = s.applyResize(ctx, rec)
+		if err != nil {
+			return nil, err
+		}
+
+	case domain.RecommendationTypeConsolidation:
+		agg, aggChanges, err := s.applyConsolidation(ctx, rec, req)
+		if err != nil {
+			return nil, err
+		}
+		appliedPoolID = &agg.ID
+		changes = aggChanges
 	}
 
 	if err := s.store.UpdateRecommendationStatus(ctx, id, domain.RecommendationStatusApplied, "", appliedPoolID); err != nil {
@@ -136,6 +200,7 @@ func (s *RecommendationService) Apply(ctx context.Context, id string, req domain
 
 	rec.Status = domain.RecommendationStatusApplied
 	rec.AppliedPoolID = appliedPoolID
+	rec.Changes = changes
 	return rec, nil
 }
 
@@ -169,9 +234,8 @@ func (s *RecommendationService) scoreAllocation(cidrStr string, pool domain.Pool
 	score := 0
 
 	// Alignment (+30): block starts on its natural boundary.
-	addr := ipv4ToUint32(prefix.Addr())
-	blockSize := uint32(1) << (32 - prefix.Bits())
-	if addr%blockSize == 0 {
+	hostBits := prefix.Addr().BitLen() - prefix.Bits()
+	if cidr.AddrToUint128(prefix.Addr()).TrailingZeros() >= hostBits {
 		score += 30
 	}
 
@@ -204,20 +268,20 @@ func (s *RecommendationService) scoreAllocation(cidrStr string, pool domain.Pool
 		blockInterval := prefixToInterval(prefix)
 		for _, child := range children {
 			cp, err := netip.ParsePrefix(child.CIDR)
-			if err != nil {
+			if err != nil || cp.Addr().Is4() != prefix.Addr().Is4() {
 				continue
 			}
 			ci := prefixToInterval(cp.Masked())
 			// Adjacent if blocks touch.
-			if ci.end+1 == blockInterval.start || blockInterval.end+1 == ci.start {
+			if ci.end.AddOne() == blockInterval.start || blockInterval.end.AddOne() == ci.start {
 				score += 20
 				break
 			}
 		}
 	}
 
-	// RFC1918 (+20): within private address space.
-	if isRFC1918(prefix) {
+	// Private space (+20): RFC1918, or unique local space for IPv6.
+	if isPrivateSpace(prefix) {
 		score += 20
 	}
 
@@ -0,0 +1,524 @@
+package planning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"cloudpam/internal/cidr"
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+// Thresholds for reclaim and resize recommendations.
+const (
+	// ResizeLookback is the utilization history resize recommendations read.
+	ResizeLookback = 30 * day
+	// ResizeMinHistory is how much of that history a pool needs before its
+	// sizing counts as chronic.
+	ResizeMinHistory = 14 * day
+	// ReclaimEmptyAge is how long a parent-type pool must have existed
+	// without children before it is suggested for reclaim.
+	ReclaimEmptyAge = 30 * day
+
+	// resizeShrinkBelow is the peak utilization under which a pool is
+	// oversized; resizeGrowAbove the lowest utilization over which it is
+	// undersized.
+	resizeShrinkBelow = 25.0
+	resizeGrowAbove   = 90.0
+	// resizeTarget is the peak utilization a shrunk pool should run at.
+	resizeTarget = 50.0
+)
+
+// Metadata keys set on reclaim, resize and consolidation recommendations.
+const (
+	recMetaReason      = "reason"
+	recMetaCurrentCIDR = "current_cidr"
+	recMetaDirection   = "direction"
+	recMetaPeak        = "peak_utilization"
+	recMetaLow         = "low_utilization"
+	recMetaPoolIDs     = "pool_ids"
+)
+
+// SetUtilization enables resize recommendations, which need the utilization
+// history recorded by u.
+func (s *RecommendationService) SetUtilization(u *UtilizationService) {
+	s.utilization = u
+}
+
+// SetDiscoveryStore lets reclaim recommendations consider pools whose linked
+// discovered resources are all stale or deleted.
+func (s *RecommendationService) SetDiscoveryStore(d storage.DiscoveryStore) {
+	s.discovery = d
+}
+
+// SetPoolChangeRequestStore keeps resizes out of the blocks pending change
+// requests hold. Active reservations are read from the analysis service.
+func (s *RecommendationService) SetPoolChangeRequestStore(cs storage.PoolChangeRequestStore) {
+	s.poolChanges = cs
+}
+
+// structureRecommendations returns the reclaim, resize and consolidation
+// recommendations for pool.
+func (s *RecommendationService) structureRecommendations(ctx context.Context, pool domain.Pool, now time.Time) ([]domain.Recommendation, error) {
+	children, err := s.mainStore.GetPoolChildren(ctx, pool.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list children of pool %d: %w", pool.ID, err)
+	}
+	var recs []domain.Recommendation
+	reclaim, err := s.reclaimRecommendation(ctx, pool, children, now)
+	if err != nil {
+		return nil, err
+	}
+	if reclaim != nil {
+		recs = append(recs, *reclaim)
+	} else {
+		resize, err := s.resizeRecommendation(ctx, pool, children, now)
+		if err != nil {
+			return nil, err
+		}
+		if resize != nil {
+			recs = append(recs, *resize)
+		}
+	}
+	return append(recs, consolidationRecommendations(pool, children, now)...), nil
+}
+
+// reclaimRecommendation suggests deleting a childless pool that is
+// deprecated, whose linked discovered resources are all stale or deleted,
+// or that is a parent-type pool left empty for ReclaimEmptyAge.
+func (s *RecommendationService) reclaimRecommendation(ctx context.Context, pool domain.Pool, children []domain.Pool, now time.Time) (*domain.Recommendation, error) {
+	if len(children) > 0 {
+		return nil, nil
+	}
+	rec := newRecommendation(pool, domain.RecommendationTypeReclaim, now)
+	rec.Title = fmt.Sprintf("Reclaim %s", pool.CIDR)
+	switch {
+	case pool.Status == domain.PoolStatusDeprecated:
+		rec.Score = 70
+		rec.Metadata = map[string]string{recMetaReason: "deprecated"}
+		rec.Description = fmt.Sprintf("Pool %q is deprecated and has no child pools; deleting it returns %s to the free space.", pool.Name, pool.CIDR)
+	default:
+		gone, err := s.resourcesGone(ctx, pool)
+		if err != nil {
+			return nil, err
+		}
+		if gone > 0 {
+			rec.Score = 60
+			rec.Metadata = map[string]string{recMetaReason: "resources_gone"}
+			rec.Description = fmt.Sprintf("All %d discovered resources linked to pool %q are stale or deleted.", gone, pool.Name)
+			break
+		}
+		if pool.Status != domain.PoolStatusActive || !isParentPoolType(pool.Type) || now.Sub(pool.CreatedAt) < ReclaimEmptyAge {
+			return nil, nil
+		}
+		stats, err := s.mainStore.CalculatePoolUtilization(ctx, pool.ID)
+		if err != nil || stats.UsedIPs > 0 {
+			return nil, nil
+		}
+		rec.Score = 40
+		rec.Metadata = map[string]string{recMetaReason: "empty"}
+		rec.Description = fmt.Sprintf("Pool %q has had no allocations for %d days.", pool.Name, int(now.Sub(pool.CreatedAt)/day))
+	}
+	rec.Priority = priorityFromScore(rec.Score)
+	return &rec, nil
+}
+
+// resourcesGone returns how many discovered resources are linked to pool
+// when none of them is still active, and 0 otherwise.
+func (s *RecommendationService) resourcesGone(ctx context.Context, pool domain.Pool) (int, error) {
+	if s.discovery == nil || pool.AccountID == nil {
+		return 0, nil
+	}
+	const pageSize = 1000
+	linked := true
+	gone := 0
+	for page, seen := 1, 0; ; page++ {
+		resources, total, err := s.discovery.ListDiscoveredResources(ctx, *pool.AccountID, domain.DiscoveryFilters{
+			HasPool:  &linked,
+			Page:     page,
+			PageSize: pageSize,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("list discovered resources: %w", err)
+		}
+		for _, r := range resources {
+			if r.PoolID == nil || *r.PoolID != pool.ID {
+				continue
+			}
+			if r.Status == domain.DiscoveryStatusActive {
+				return 0, nil
+			}
+			gone++
+		}
+		seen += len(resources)
+		if len(resources) == 0 || seen >= total {
+			return gone, nil
+		}
+	}
+}
+
+// resizeRecommendation suggests shrinking a parent pool whose utilization
+// never reached resizeShrinkBelow over the lookback, or growing a pool that
+// never dropped below resizeGrowAbove. Only pools with children are shrunk:
+// the new block is fitted around them.
+func (s *RecommendationService) resizeRecommendation(ctx context.Context, pool domain.Pool, children []domain.Pool, now time.Time) (*domain.Recommendation, error) {
+	if s.utilization == nil {
+		return nil, nil
+	}
+	prefix, err := netip.ParsePrefix(pool.CIDR)
+	if err != nil {
+		return nil, nil
+	}
+	prefix = prefix.Masked()
+	snaps, err := s.utilization.snapshots.ListSnapshots(ctx, pool.ID, now.Add(-ResizeLookback), now)
+	if err != nil {
+		return nil, fmt.Errorf("list snapshots for pool %d: %w", pool.ID, err)
+	}
+	if len(snaps) < 2 || snaps[len(snaps)-1].CapturedAt.Sub(snaps[0].CapturedAt) < ResizeMinHistory {
+		return nil, nil
+	}
+	peak, low := snaps[0].Utilization, snaps[0].Utilization
+	var peakUsed int64
+	for _, snap := range snaps {
+		peak = max(peak, snap.Utilization)
+		low = min(low, snap.Utilization)
+		peakUsed = max(peakUsed, snap.UsedIPs)
+	}
+
+	rec := newRecommendation(pool, domain.RecommendationTypeResize, now)
+	rec.Metadata = map[string]string{
+		recMetaCurrentCIDR: pool.CIDR,
+		recMetaPeak:        strconv.FormatFloat(peak, 'f', 1, 64),
+		recMetaLow:         strconv.FormatFloat(low, 'f', 1, 64),
+	}
+	days := int(snaps[len(snaps)-1].CapturedAt.Sub(snaps[0].CapturedAt) / day)
+	switch {
+	case peak < resizeShrinkBelow && len(children) > 0:
+		next, ok := shrinkTarget(prefix, children, peakUsed)
+		if !ok {
+			return nil, nil
+		}
+		rec.Score = 50
+		rec.SuggestedCIDR = next.String()
+		rec.Metadata[recMetaDirection] = "shrink"
+		rec.Title = fmt.Sprintf("Shrink %s to %s", pool.CIDR, next)
+		rec.Description = fmt.Sprintf("Pool %q peaked at %.1f%% utilization over %d days; %s holds every child pool with room to spare.", pool.Name, peak, days, next)
+	case low >= resizeGrowAbove && prefix.Bits() > 0:
+		next := netip.PrefixFrom(prefix.Addr(), prefix.Bits()-1).Masked()
+		parent, siblings, err := s.poolFamily(ctx, pool)
+		if err != nil {
+			return nil, err
+		}
+		held, err := s.heldPrefixes(ctx, pool.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := storage.CheckResize(pool, next.String(), parent, siblings, children, nil, held); err != nil {
+			return nil, nil // no room to grow into
+		}
+		rec.Score = 75
+		rec.SuggestedCIDR = next.String()
+		rec.Metadata[recMetaDirection] = "grow"
+		rec.Title = fmt.Sprintf("Grow %s to %s", pool.CIDR, next)
+		rec.Description = fmt.Sprintf("Pool %q stayed above %.1f%% utilization for %d days; %s doubles it without overlapping its siblings.", pool.Name, low, days, next)
+	default:
+		return nil, nil
+	}
+	rec.Priority = priorityFromScore(rec.Score)
+	return &rec, nil
+}
+
+// shrinkTarget returns the smallest block inside prefix that still contains
+// every child and keeps peakUsed addresses at or under resizeTarget.
+func shrinkTarget(prefix netip.Prefix, children []domain.Pool, peakUsed int64) (netip.Prefix, bool) {
+	// Keep the block that starts at the lowest child, so children clustered
+	// in either half can stay where they are.
+	anchor := prefix.Addr()
+	childPrefixes := make([]netip.Prefix, 0, len(children))
+	for i, ch := range children {
+		cp, err := netip.ParsePrefix(ch.CIDR)
+		if err != nil {
+			return netip.Prefix{}, false
+		}
+		cp = cp.Masked()
+		if i == 0 || cp.Addr().Less(anchor) {
+			anchor = cp.Addr()
+		}
+		childPrefixes = append(childPrefixes, cp)
+	}
+	best := prefix
+	for bits := prefix.Bits() + 1; bits <= prefix.Addr().BitLen(); bits++ {
+		candidate := netip.PrefixFrom(anchor, bits).Masked()
+		if float64(peakUsed) > cidr.AddressCount(candidate).Float64()*resizeTarget/100 {
+			break
+		}
+		fits := true
+		for _, cp := range childPrefixes {
+			if !cidr.PrefixContains(candidate, cp) {
+				fits = false
+				break
+			}
+		}
+		if !fits {
+			break
+		}
+		best = candidate
+	}
+	return best, best != prefix
+}
+
+// consolidationRecommendations pairs up children of pool that are the two
+// halves of one larger block and share a type, status and account. Each
+// pair can be nested under a single aggregate pool.
+func consolidationRecommendations(pool domain.Pool, children []domain.Pool, now time.Time) []domain.Recommendation {
+	parentPrefix, _ := netip.ParsePrefix(pool.CIDR)
+	type block struct {
+		pool   domain.Pool
+		prefix netip.Prefix
+	}
+	var blocks []block
+	for _, ch := range children {
+		cp, err := netip.ParsePrefix(ch.CIDR)
+		if err != nil || ch.Status == domain.PoolStatusDeprecated || cp.Bits() == 0 {
+			continue
+		}
+		blocks = append(blocks, block{pool: ch, prefix: cp.Masked()})
+	}
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i].prefix.Addr().Less(blocks[j].prefix.Addr()) })
+
+	var recs []domain.Recommendation
+	for i := 0; i+1 < len(blocks); i++ {
+		a, b := blocks[i], blocks[i+1]
+		agg := netip.PrefixFrom(a.prefix.Addr(), a.prefix.Bits()-1).Masked()
+		if a.prefix.Bits() != b.prefix.Bits() || agg.Addr() != a.prefix.Addr() || !agg.Contains(b.prefix.Addr()) || agg == parentPrefix.Masked() {
+			continue
+		}
+		if a.pool.Type != b.pool.Type || a.pool.Status != b.pool.Status || !sameAccount(a.pool.AccountID, b.pool.AccountID) {
+			continue
+		}
+		rec := newRecommendation(pool, domain.RecommendationTypeConsolidation, now)
+		rec.Score = 35
+		rec.Priority = priorityFromScore(rec.Score)
+		rec.SuggestedCIDR = agg.String()
+		rec.Title = fmt.Sprintf("Consolidate %s and %s into %s", a.pool.CIDR, b.pool.CIDR, agg)
+		rec.Description = fmt.Sprintf("Pools %q and %q are adjacent halves of %s and can be summarized under one aggregate pool.", a.pool.Name, b.pool.Name, agg)
+		rec.Metadata = map[string]string{recMetaPoolIDs: fmt.Sprintf("%d,%d", a.pool.ID, b.pool.ID)}
+		recs = append(recs, rec)
+		i++ // each block joins at most one aggregate
+	}
+	return recs
+}
+
+// applyReclaim deletes the recommended pool. The store refuses if it has
+// gained children since.
+func (s *RecommendationService) applyReclaim(ctx context.Context, rec *domain.Recommendation) ([]domain.RecommendationChange, error) {
+	pool, err := s.currentPool(ctx, rec.PoolID)
+	if err != nil {
+		return nil, err
+	}
+	deleted, err := s.mainStore.DeletePool(ctx, pool.ID)
+	if err != nil {
+		return nil, fmt.Errorf("reclaim pool %d: %w", pool.ID, err)
+	}
+	if !deleted {
+		return nil, fmt.Errorf("pool %d: %w", pool.ID, storage.ErrNotFound)
+	}
+	return []domain.RecommendationChange{{
+		Action: "delete",
+		PoolID: pool.ID,
+		Name:   pool.Name,
+		Before: map[string]any{"cidr": pool.CIDR, "status": string(pool.Status)},
+	}}, nil
+}
+
+// applyResize moves the pool to the suggested CIDR, provided it has not
+// been moved since the recommendation was generated.
+func (s *RecommendationService) applyResize(ctx context.Context, rec *domain.Recommendation) ([]domain.RecommendationChange, error) {
+	restructurer, err := s.restructurer()
+	if err != nil {
+		return nil, err
+	}
+	pool, err := s.currentPool(ctx, rec.PoolID)
+	if err != nil {
+		return nil, err
+	}
+	if want := rec.Metadata[recMetaCurrentCIDR]; pool.CIDR != want {
+		return nil, fmt.Errorf("pool %d is now %s, not %s: %w", pool.ID, pool.CIDR, want, storage.ErrConflict)
+	}
+	held, err := s.heldPrefixes(ctx, pool.ParentID)
+	if err != nil {
+		return nil, err
+	}
+	resized, err := restructurer.ResizePool(ctx, pool.ID, rec.SuggestedCIDR, held)
+	if err != nil {
+		return nil, fmt.Errorf("resize pool %d: %w", pool.ID, err)
+	}
+	return []domain.RecommendationChange{{
+		Action: "update",
+		PoolID: resized.ID,
+		Name:   resized.Name,
+		Before: map[string]any{"cidr": pool.CIDR},
+		After:  map[string]any{"cidr": resized.CIDR},
+	}}, nil
+}
+
+// applyConsolidation creates the aggregate pool and moves the paired blocks
+// under it. Their own children, addresses and discovery links are untouched.
+func (s *RecommendationService) applyConsolidation(ctx context.Context, rec *domain.Recommendation, req domain.ApplyRecommendationRequest) (*domain.Pool, []domain.RecommendationChange, error) {
+	restructurer, err := s.restructurer()
+	if err != nil {
+		return nil, nil, err
+	}
+	var members []domain.Pool
+	for _, field := range strings.Split(rec.Metadata[recMetaPoolIDs], ",") {
+		id, err := strconv.ParseInt(strings.TrimSpace(field), 10, 64)
+		if err != nil {
+			return nil, nil, fmt.Errorf("recommendation %s has malformed pool_ids: %w", rec.ID, storage.ErrValidation)
+		}
+		p, err := s.currentPool(ctx, id)
+		if err != nil {
+			return nil, nil, err
+		}
+		members = append(members, p)
+	}
+	if len(members) < 2 {
+		return nil, nil, fmt.Errorf("recommendation %s names fewer than two pools: %w", rec.ID, storage.ErrValidation)
+	}
+	name := req.Name
+	if name == "" {
+		name = fmt.Sprintf("Aggregate %s", rec.SuggestedCIDR)
+	}
+	accountID := req.AccountID
+	if accountID == nil {
+		accountID = members[0].AccountID
+	}
+	ids := make([]int64, len(members))
+	for i, m := range members {
+		ids[i] = m.ID
+	}
+	in := domain.CreatePool{
+		Name:      name,
+		CIDR:      rec.SuggestedCIDR,
+		ParentID:  members[0].ParentID,
+		AccountID: accountID,
+		Type:      members[0].Type,
+		Source:    domain.PoolSourceManual,
+	}
+	if err := s.analysis.admit(ctx, in); err != nil {
+		return nil, nil, err
+	}
+	agg, err := restructurer.AggregatePools(ctx, ids, in)
+	if err != nil {
+		return nil, nil, fmt.Errorf("consolidate pools: %w", err)
+	}
+	changes := []domain.RecommendationChange{{
+		Action: "create",
+		PoolID: agg.ID,
+		Name:   agg.Name,
+		After:  map[string]any{"cidr": agg.CIDR, "parent_id": agg.ParentID},
+	}}
+	for _, m := range members {
+		changes = append(changes, domain.RecommendationChange{
+			Action: "update",
+			PoolID: m.ID,
+			Name:   m.Name,
+			Before: map[string]any{"parent_id": m.ParentID},
+			After:  map[string]any{"parent_id": agg.ID},
+		})
+	}
+	return &agg, changes, nil
+}
+
+func (s *RecommendationService) restructurer() (storage.PoolRestructurer, error) {
+	r, ok := s.mainStore.(storage.PoolRestructurer)
+	if !ok {
+		return nil, errors.New("the configured store cannot resize or aggregate pools")
+	}
+	return r, nil
+}
+
+func (s *RecommendationService) currentPool(ctx context.Context, id int64) (domain.Pool, error) {
+	pool, found, err := s.mainStore.GetPool(ctx, id)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+	if !found {
+		return domain.Pool{}, fmt.Errorf("pool %d: %w", id, storage.ErrNotFound)
+	}
+	return pool, nil
+}
+
+// poolFamily returns pool's parent (nil at the top level) and the live pools
+// sharing it.
+func (s *RecommendationService) poolFamily(ctx context.Context, pool domain.Pool) (*domain.Pool, []domain.Pool, error) {
+	if pool.ParentID == nil {
+		all, err := s.mainStore.ListPools(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		var top []domain.Pool
+		for _, p := range all {
+			if p.ParentID == nil {
+				top = append(top, p)
+			}
+		}
+		return nil, top, nil
+	}
+	parent, err := s.currentPool(ctx, *pool.ParentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	siblings, err := s.mainStore.GetPoolChildren(ctx, parent.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &parent, siblings, nil
+}
+
+// heldPrefixes returns the blocks that active reservations and pending
+// change requests hold under parentID.
+func (s *RecommendationService) heldPrefixes(ctx context.Context, parentID *int64) ([]netip.Prefix, error) {
+	var requests []domain.PoolChangeRequest
+	if s.poolChanges != nil {
+		var err error
+		requests, err = s.poolChanges.ListPoolChangeRequests(ctx, domain.PoolChangeRequestPending)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var reservations []domain.Reservation
+	if s.analysis.reservations != nil {
+		var err error
+		reservations, err = s.analysis.reservations.ListReservations(ctx, domain.ReservationActive)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return storage.HeldPrefixes(requests, reservations, parentID, time.Now()), nil
+}
+
+func newRecommendation(pool domain.Pool, t domain.RecommendationType, now time.Time) domain.Recommendation {
+	return domain.Recommendation{
+		ID:        uuid.New().String(),
+		PoolID:    pool.ID,
+		Type:      t,
+		Status:    domain.RecommendationStatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func sameAccount(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
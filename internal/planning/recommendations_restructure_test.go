@@ -0,0 +1,374 @@
+package planning
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+func findRec(items []domain.Recommendation, t domain.RecommendationType) *domain.Recommendation {
+	for i := range items {
+		if items[i].Type == t {
+			return &items[i]
+		}
+	}
+	return nil
+}
+
+func TestGenerate_ReclaimDeprecatedPool(t *testing.T) {
+	ctx := context.Background()
+	svc, st := setupRecService(t)
+
+	pool, _ := st.CreatePool(ctx, domain.CreatePool{
+		Name: "Old", CIDR: "10.9.0.0/24", Type: domain.PoolTypeSubnet, Status: domain.PoolStatusDeprecated,
+	})
+	resp, err := svc.Generate(ctx, domain.GenerateRecommendationsRequest{PoolIDs: []int64{pool.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := findRec(resp.Items, domain.RecommendationTypeReclaim)
+	if rec == nil {
+		t.Fatalf("expected a reclaim recommendation, got %+v", resp.Items)
+	}
+	if rec.Metadata["reason"] != "deprecated" || rec.Priority != domain.RecommendationPriorityHigh {
+		t.Errorf("reclaim rec = %+v", rec)
+	}
+
+	applied, err := svc.Apply(ctx, rec.ID, domain.ApplyRecommendationRequest{})
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if len(applied.Changes) != 1 || applied.Changes[0].Action != "delete" || applied.Changes[0].PoolID != pool.ID {
+		t.Errorf("changes = %+v", applied.Changes)
+	}
+	if _, found, _ := st.GetPool(ctx, pool.ID); found {
+		t.Error("reclaimed pool still exists")
+	}
+}
+
+func TestGenerate_ReclaimSkipsPoolsWithChildren(t *testing.T) {
+	ctx := context.Background()
+	svc, st := setupRecService(t)
+
+	parent, _ := st.CreatePool(ctx, domain.CreatePool{
+		Name: "Old", CIDR: "10.9.0.0/16", Type: domain.PoolTypeSupernet, Status: domain.PoolStatusDeprecated,
+	})
+	if _, err := st.CreatePool(ctx, domain.CreatePool{Name: "Live", CIDR: "10.9.1.0/24", ParentID: &parent.ID}); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := svc.Generate(ctx, domain.GenerateRecommendationsRequest{PoolIDs: []int64{parent.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec := findRec(resp.Items, domain.RecommendationTypeReclaim); rec != nil {
+		t.Errorf("unexpected reclaim for a pool with children: %+v", rec)
+	}
+}
+
+func TestGenerate_ReclaimWhenResourcesGone(t *testing.T) {
+	ctx := context.Background()
+	svc, st := setupRecService(t)
+	disc := storage.NewMemoryDiscoveryStore(st)
+	svc.SetDiscoveryStore(disc)
+
+	acct, _ := st.CreateAccount(ctx, domain.CreateAccount{Key: "aws:1", Name: "prod"})
+	pool, _ := st.CreatePool(ctx, domain.CreatePool{Name: "vpc-subnet", CIDR: "10.8.0.0/24", AccountID: &acct.ID})
+	res := domain.DiscoveredResource{
+		ID: uuid.New(), AccountID: acct.ID, Provider: "aws", Region: "us-east-1",
+		ResourceType: domain.ResourceTypeSubnet, ResourceID: "subnet-1", CIDR: "10.8.0.0/24",
+		Status: domain.DiscoveryStatusActive, DiscoveredAt: time.Now(), LastSeenAt: time.Now(),
+	}
+	if err := disc.UpsertDiscoveredResource(ctx, res); err != nil {
+		t.Fatal(err)
+	}
+	if err := disc.LinkResourceToPool(ctx, res.ID, pool.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := svc.Generate(ctx, domain.GenerateRecommendationsRequest{PoolIDs: []int64{pool.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec := findRec(resp.Items, domain.RecommendationTypeReclaim); rec != nil {
+		t.Fatalf("unexpected reclaim while the resource is active: %+v", rec)
+	}
+
+	if _, err := disc.MarkStaleResources(ctx, acct.ID, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	resp, err = svc.Generate(ctx, domain.GenerateRecommendationsRequest{PoolIDs: []int64{pool.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := findRec(resp.Items, domain.RecommendationTypeReclaim)
+	if rec == nil || rec.Metadata["reason"] != "resources_gone" {
+		t.Fatalf("expected a resources_gone reclaim, got %+v", resp.Items)
+	}
+}
+
+func setupResize(t *testing.T) (*RecommendationService, *storage.MemoryStore, *storage.MemoryUtilizationStore) {
+	t.Helper()
+	svc, st := setupRecService(t)
+	snaps := storage.NewMemoryUtilizationStore()
+	svc.SetUtilization(NewUtilizationService(st, snaps))
+	return svc, st, snaps
+}
+
+func recordHistory(t *testing.T, snaps *storage.MemoryUtilizationStore, poolID int64, utilization float64, used int64) {
+	t.Helper()
+	now := time.Now().UTC()
+	for _, ago := range []time.Duration{20 * day, 10 * day, time.Hour} {
+		if err := snaps.RecordSnapshot(context.Background(), domain.UtilizationSnapshot{
+			PoolID: poolID, Utilization: utilization, UsedIPs: used, CapturedAt: now.Add(-ago),
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestGenerate_ResizeShrinksOversizedPool(t *testing.T) {
+	ctx := context.Background()
+	svc, st, snaps := setupResize(t)
+
+	parent, _ := st.CreatePool(ctx, domain.CreatePool{Name: "Region", CIDR: "10.0.0.0/16", Type: domain.PoolTypeRegion})
+	if _, err := st.CreatePool(ctx, domain.CreatePool{Name: "App", CIDR: "10.0.4.0/24", ParentID: &parent.ID}); err != nil {
+		t.Fatal(err)
+	}
+	recordHistory(t, snaps, parent.ID, 0.4, 256)
+
+	resp, err := svc.Generate(ctx, domain.GenerateRecommendationsRequest{PoolIDs: []int64{parent.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := findRec(resp.Items, domain.RecommendationTypeResize)
+	if rec == nil {
+		t.Fatalf("expected a resize recommendation, got %+v", resp.Items)
+	}
+	if rec.SuggestedCIDR != "10.0.4.0/23" || rec.Metadata["direction"] != "shrink" {
+		t.Fatalf("resize rec = %+v", rec)
+	}
+
+	applied, err := svc.Apply(ctx, rec.ID, domain.ApplyRecommendationRequest{})
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	got, _, _ := st.GetPool(ctx, parent.ID)
+	if got.CIDR != "10.0.4.0/23" {
+		t.Errorf("pool CIDR = %s, want 10.0.4.0/23", got.CIDR)
+	}
+	c := applied.Changes
+	if len(c) != 1 || c[0].Action != "update" || c[0].Before["cidr"] != "10.0.0.0/16" || c[0].After["cidr"] != "10.0.4.0/23" {
+		t.Errorf("changes = %+v", c)
+	}
+}
+
+func TestGenerate_ResizeGrowsFullPool(t *testing.T) {
+	ctx := context.Background()
+	svc, st, snaps := setupResize(t)
+
+	parent, _ := st.CreatePool(ctx, domain.CreatePool{Name: "Region", CIDR: "10.0.0.0/16", Type: domain.PoolTypeRegion})
+	full, _ := st.CreatePool(ctx, domain.CreatePool{Name: "Full", CIDR: "10.0.0.0/24", ParentID: &parent.ID, Type: domain.PoolTypeVPC})
+	blocked, _ := st.CreatePool(ctx, domain.CreatePool{Name: "Blocked", CIDR: "10.0.2.0/24", ParentID: &parent.ID, Type: domain.PoolTypeVPC})
+	if _, err := st.CreatePool(ctx, domain.CreatePool{Name: "Neighbour", CIDR: "10.0.3.0/24", ParentID: &parent.ID}); err != nil {
+		t.Fatal(err)
+	}
+	recordHistory(t, snaps, full.ID, 95, 243)
+	recordHistory(t, snaps, blocked.ID, 95, 243)
+
+	resp, err := svc.Generate(ctx, domain.GenerateRecommendationsRequest{PoolIDs: []int64{full.ID, blocked.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var grow *domain.Recommendation
+	for i, r := range resp.Items {
+		if r.Type != domain.RecommendationTypeResize {
+			continue
+		}
+		if r.PoolID == blocked.ID {
+			t.Errorf("pool boxed in by a sibling should not grow: %+v", r)
+		}
+		grow = &resp.Items[i]
+	}
+	if grow == nil || grow.SuggestedCIDR != "10.0.0.0/23" || grow.Priority != domain.RecommendationPriorityHigh {
+		t.Fatalf("grow rec = %+v", grow)
+	}
+
+	// A pool moved after the recommendation was generated is not resized.
+	if _, err := st.ResizePool(ctx, full.ID, "10.0.0.0/25", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Apply(ctx, grow.ID, domain.ApplyRecommendationRequest{}); !errors.Is(err, storage.ErrConflict) {
+		t.Fatalf("Apply after the pool moved: expected ErrConflict, got %v", err)
+	}
+}
+
+func TestGenerate_ResizeSkipsHeldBlocks(t *testing.T) {
+	ctx := context.Background()
+	svc, st, snaps := setupResize(t)
+	reservations := storage.NewMemoryReservationStore()
+	svc.analysis.SetReservationStore(reservations)
+	requests := storage.NewMemoryPoolChangeRequestStore()
+	svc.SetPoolChangeRequestStore(requests)
+
+	parent, _ := st.CreatePool(ctx, domain.CreatePool{Name: "Region", CIDR: "10.0.0.0/16", Type: domain.PoolTypeRegion})
+	reserved, _ := st.CreatePool(ctx, domain.CreatePool{Name: "Reserved", CIDR: "10.0.0.0/24", ParentID: &parent.ID})
+	requested, _ := st.CreatePool(ctx, domain.CreatePool{Name: "Requested", CIDR: "10.0.4.0/24", ParentID: &parent.ID})
+	free, _ := st.CreatePool(ctx, domain.CreatePool{Name: "Free", CIDR: "10.0.8.0/24", ParentID: &parent.ID})
+	now := time.Now().UTC()
+	if err := reservations.CreateReservation(ctx, domain.Reservation{
+		ID: "res-1", CIDR: "10.0.1.0/24", ParentID: &parent.ID, Owner: "team-a",
+		Status: domain.ReservationActive, ExpiresAt: now.Add(day), CreatedAt: now,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := requests.CreatePoolChangeRequest(ctx, domain.PoolChangeRequest{
+		ID: "cr-1", Operation: domain.PoolChangeCreate, Status: domain.PoolChangeRequestPending,
+		CIDR: "10.0.5.0/24", ParentID: &parent.ID, CreatedAt: now,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []domain.Pool{reserved, requested, free} {
+		recordHistory(t, snaps, p.ID, 95, 243)
+	}
+
+	resp, err := svc.Generate(ctx, domain.GenerateRecommendationsRequest{PoolIDs: []int64{reserved.ID, requested.ID, free.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var grow *domain.Recommendation
+	for i, r := range resp.Items {
+		if r.Type != domain.RecommendationTypeResize {
+			continue
+		}
+		if r.PoolID != free.ID {
+			t.Errorf("pool next to a held block should not grow: %+v", r)
+		}
+		grow = &resp.Items[i]
+	}
+	if grow == nil || grow.SuggestedCIDR != "10.0.8.0/23" {
+		t.Fatalf("grow rec = %+v", grow)
+	}
+
+	// A block reserved after the recommendation was generated stops it too.
+	if err := reservations.CreateReservation(ctx, domain.Reservation{
+		ID: "res-2", CIDR: "10.0.9.0/25", ParentID: &parent.ID, Owner: "team-b",
+		Status: domain.ReservationActive, ExpiresAt: now.Add(day), CreatedAt: now,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Apply(ctx, grow.ID, domain.ApplyRecommendationRequest{}); !errors.Is(err, storage.ErrConflict) {
+		t.Fatalf("Apply over a reservation: expected ErrConflict, got %v", err)
+	}
+	if got, _, _ := st.GetPool(ctx, free.ID); got.CIDR != "10.0.8.0/24" {
+		t.Errorf("pool CIDR = %s, want 10.0.8.0/24", got.CIDR)
+	}
+}
+
+func TestGenerate_ConsolidationPairsAdjacentSiblings(t *testing.T) {
+	ctx := context.Background()
+	svc, st := setupRecService(t)
+
+	parent, _ := st.CreatePool(ctx, domain.CreatePool{Name: "VPC", CIDR: "10.0.0.0/16", Type: domain.PoolTypeVPC})
+	a, _ := st.CreatePool(ctx, domain.CreatePool{Name: "a", CIDR: "10.0.0.0/24", ParentID: &parent.ID})
+	b, _ := st.CreatePool(ctx, domain.CreatePool{Name: "b", CIDR: "10.0.1.0/24", ParentID: &parent.ID})
+	grandchild, _ := st.CreatePool(ctx, domain.CreatePool{Name: "b-1", CIDR: "10.0.1.0/26", ParentID: &b.ID})
+	// Adjacent but not halves of one block, and a different type.
+	for _, in := range []domain.CreatePool{
+		{Name: "c", CIDR: "10.0.3.0/24", ParentID: &parent.ID},
+		{Name: "d", CIDR: "10.0.4.0/24", ParentID: &parent.ID},
+		{Name: "e", CIDR: "10.0.6.0/24", ParentID: &parent.ID},
+		{Name: "f", CIDR: "10.0.7.0/24", ParentID: &parent.ID, Type: domain.PoolTypeVPC},
+	} {
+		if _, err := st.CreatePool(ctx, in); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resp, err := svc.Generate(ctx, domain.GenerateRecommendationsRequest{PoolIDs: []int64{parent.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var recs []domain.Recommendation
+	for _, r := range resp.Items {
+		if r.Type == domain.RecommendationTypeConsolidation {
+			recs = append(recs, r)
+		}
+	}
+	if len(recs) != 1 || recs[0].SuggestedCIDR != "10.0.0.0/23" {
+		t.Fatalf("consolidation recs = %+v", recs)
+	}
+
+	applied, err := svc.Apply(ctx, recs[0].ID, domain.ApplyRecommendationRequest{Name: "ab"})
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if applied.AppliedPoolID == nil || len(applied.Changes) != 3 {
+		t.Fatalf("applied = %+v", applied)
+	}
+	agg, _, _ := st.GetPool(ctx, *applied.AppliedPoolID)
+	if agg.Name != "ab" || agg.CIDR != "10.0.0.0/23" || agg.ParentID == nil || *agg.ParentID != parent.ID {
+		t.Errorf("aggregate = %+v", agg)
+	}
+	for _, id := range []int64{a.ID, b.ID} {
+		p, _, _ := st.GetPool(ctx, id)
+		if p.ParentID == nil || *p.ParentID != agg.ID {
+			t.Errorf("pool %d parent = %v, want %d", id, p.ParentID, agg.ID)
+		}
+	}
+	if p, _, _ := st.GetPool(ctx, grandchild.ID); p.ParentID == nil || *p.ParentID != b.ID {
+		t.Errorf("grandchild moved: %+v", p)
+	}
+
+	// The blocks now fill their parent, so nothing is suggested for it.
+	resp, err = svc.Generate(ctx, domain.GenerateRecommendationsRequest{PoolIDs: []int64{agg.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec := findRec(resp.Items, domain.RecommendationTypeConsolidation); rec != nil {
+		t.Errorf("unexpected consolidation inside the aggregate: %+v", rec)
+	}
+}
+
+func TestApply_ConsolidationRejectedByEnforcedRule(t *testing.T) {
+	ctx := context.Background()
+	svc, st := setupRecService(t)
+	rules := storage.NewMemoryComplianceRuleStore()
+	svc.analysis.SetComplianceRules(rules)
+	if err := rules.CreateComplianceRule(ctx, domain.ComplianceRule{
+		ID: "AGG-NAME", Name: "Aggregate names", Enabled: true, Severity: "error", Enforce: true,
+		Check: domain.ComplianceCheck{Type: domain.ComplianceCheckNamePattern, Pattern: "^agg-"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	parent, _ := st.CreatePool(ctx, domain.CreatePool{Name: "agg-vpc", CIDR: "10.0.0.0/16", Type: domain.PoolTypeVPC})
+	a, _ := st.CreatePool(ctx, domain.CreatePool{Name: "agg-a", CIDR: "10.0.0.0/24", ParentID: &parent.ID})
+	if _, err := st.CreatePool(ctx, domain.CreatePool{Name: "agg-b", CIDR: "10.0.1.0/24", ParentID: &parent.ID}); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := svc.Generate(ctx, domain.GenerateRecommendationsRequest{PoolIDs: []int64{parent.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := findRec(resp.Items, domain.RecommendationTypeConsolidation)
+	if rec == nil {
+		t.Fatalf("expected a consolidation recommendation, got %+v", resp.Items)
+	}
+
+	var rejected *AdmissionError
+	if _, err := svc.Apply(ctx, rec.ID, domain.ApplyRecommendationRequest{Name: "ab"}); !errors.As(err, &rejected) ||
+		rejected.Violations[0].RuleID != "AGG-NAME" {
+		t.Fatalf("Apply: expected an AdmissionError for AGG-NAME, got %v", err)
+	}
+	if p, _, _ := st.GetPool(ctx, a.ID); p.ParentID == nil || *p.ParentID != parent.ID {
+		t.Errorf("rejected consolidation moved pool a: %+v", p)
+	}
+	if _, err := svc.Apply(ctx, rec.ID, domain.ApplyRecommendationRequest{Name: "agg-ab"}); err != nil {
+		t.Fatalf("Apply with a compliant name: %v", err)
+	}
+}
@@ -4,6 +4,8 @@ package planning
 import (
 	"net/netip"
 	"time"
+
+	"cloudpam/internal/cidr"
 )
 
 // AnalysisRequest specifies which pools to analyze.
@@ -18,16 +20,19 @@ type GapAnalysisRequest struct {
 }
 
 // GapAnalysis describes the used and free address space within a parent pool.
+// Reserved blocks count as used.
 type GapAnalysis struct {
-	PoolID          int64            `json:"pool_id"`
-	PoolName        string           `json:"pool_name"`
-	ParentCIDR      string           `json:"parent_cidr"`
-	AllocatedBlocks []AllocatedBlock `json:"allocated_blocks"`
-	AvailableBlocks []AvailableBlock `json:"available_blocks"`
-	TotalAddresses  uint64           `json:"total_addresses"`
-	UsedAddresses   uint64           `json:"used_addresses"`
-	FreeAddresses   uint64           `json:"free_addresses"`
-	Utilization     float64          `json:"utilization_percent"`
+	PoolID            int64            `json:"pool_id"`
+	PoolName          string           `json:"pool_name"`
+	ParentCIDR        string           `json:"parent_cidr"`
+	AllocatedBlocks   []AllocatedBlock `json:"allocated_blocks"`
+	ReservedBlocks    []ReservedBlock  `json:"reserved_blocks,omitempty"`
+	AvailableBlocks   []AvailableBlock `json:"available_blocks"`
+	TotalAddresses    uint64           `json:"total_addresses"`
+	UsedAddresses     uint64           `json:"used_addresses"`
+	ReservedAddresses uint64           `json:"reserved_addresses,omitempty"`
+	FreeAddresses     uint64           `json:"free_addresses"`
+	Utilization       float64          `json:"utilization_percent"`
 }
 
 // AllocatedBlock represents a child pool's allocation within a parent.
@@ -38,6 +43,16 @@ type AllocatedBlock struct {
 	Utilization float64 `json:"utilization_percent"`
 }
 
+// ReservedBlock represents an active reservation within a parent.
+type ReservedBlock struct {
+	ReservationID string    `json:"reservation_id"`
+	CIDR          string    `json:"cidr"`
+	Owner         string    `json:"owner"`
+	Reason        string    `json:"reason,omitempty"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	AddressCount  uint64    `json:"address_count"`
+}
+
 // AvailableBlock represents an unallocated CIDR range.
 type AvailableBlock struct {
 	CIDR         string `json:"cidr"`
@@ -114,15 +129,103 @@ type AnalysisSummary struct {
 	InfoCount          int     `json:"info_count"`
 }
 
-// interval is an internal type representing a uint32 address range [start, end] inclusive.
+// UtilizationHistoryRequest selects a window of a pool's utilization
+// snapshots. A zero Bucket picks one that keeps the response within
+// MaxHistoryPoints.
+type UtilizationHistoryRequest struct {
+	From   time.Time
+	To     time.Time
+	Bucket time.Duration
+}
+
+// UtilizationHistory is a pool's utilization over a time window.
+type UtilizationHistory struct {
+	PoolID        int64                     `json:"pool_id"`
+	PoolName      string                    `json:"pool_name"`
+	CIDR          string                    `json:"cidr"`
+	From          time.Time                 `json:"from"`
+	To            time.Time                 `json:"to"`
+	BucketSeconds int64                     `json:"bucket_seconds"` // 0 when points are raw snapshots
+	Points        []UtilizationHistoryPoint `json:"points"`
+}
+
+// UtilizationHistoryPoint summarizes the snapshots in one bucket. The IP
+// counts are from the last snapshot in the bucket.
+type UtilizationHistoryPoint struct {
+	Timestamp      time.Time `json:"timestamp"` // bucket start
+	Samples        int       `json:"samples"`
+	AvgUtilization float64   `json:"avg_utilization"`
+	MinUtilization float64   `json:"min_utilization"`
+	MaxUtilization float64   `json:"max_utilization"`
+	TotalIPs       int64     `json:"total_ips"`
+	UsedIPs        int64     `json:"used_ips"`
+	AvailableIPs   int64     `json:"available_ips"`
+}
+
+// Forecast methods.
+const (
+	ForecastLinear   = "linear"
+	ForecastSeasonal = "seasonal"
+)
+
+// ForecastModel is one growth model fitted to a pool's used IPs.
+type ForecastModel struct {
+	Method           string     `json:"method"`
+	GrowthPerDay     float64    `json:"growth_per_day"` // IPs per day from the trend
+	RSquared         float64    `json:"r_squared"`
+	AdjustedRSquared float64    `json:"adjusted_r_squared"`
+	RMSE             float64    `json:"rmse"`
+	WeekdayOffsets   []float64  `json:"weekday_offsets,omitempty"` // seasonal only; Sunday first
+	ExhaustionAt     *time.Time `json:"exhaustion_at,omitempty"`
+}
+
+// ForecastPoint is a projected daily value.
+type ForecastPoint struct {
+	Date        time.Time `json:"date"`
+	UsedIPs     int64     `json:"used_ips"`
+	Utilization float64   `json:"utilization"`
+}
+
+// UtilizationForecast predicts when a pool runs out of addresses. Method
+// names the model the prediction comes from; when no exhaustion date can be
+// given, Reason says why.
+type UtilizationForecast struct {
+	PoolID           int64           `json:"pool_id"`
+	PoolName         string          `json:"pool_name"`
+	CIDR             string          `json:"cidr"`
+	TotalIPs         int64           `json:"total_ips"`
+	UsedIPs          int64           `json:"used_ips"`
+	Utilization      float64         `json:"utilization"`
+	Samples          int             `json:"samples"`
+	HistoryStart     *time.Time      `json:"history_start,omitempty"`
+	HistoryEnd       *time.Time      `json:"history_end,omitempty"`
+	Method           string          `json:"method,omitempty"`
+	GrowthPerDay     float64         `json:"growth_per_day"`
+	ExhaustionAt     *time.Time      `json:"exhaustion_at,omitempty"`
+	DaysToExhaustion *float64        `json:"days_to_exhaustion,omitempty"`
+	Reason           string          `json:"reason,omitempty"`
+	Models           []ForecastModel `json:"models,omitempty"`
+	Projection       []ForecastPoint `json:"projection,omitempty"`
+}
+
+// UtilizationForecastReport is the forecast for every pool, soonest
+// exhaustion first.
+type UtilizationForecastReport struct {
+	GeneratedAt  time.Time             `json:"generated_at"`
+	LookbackDays int                   `json:"lookback_days"`
+	Items        []UtilizationForecast `json:"items"`
+}
+
+// interval is an internal type representing an address range [start, end]
+// inclusive. IPv4 ranges occupy the low 32 bits; callers must not mix
+// address families within one set of intervals.
 type interval struct {
-	start uint32
-	end   uint32
+	start cidr.Uint128
+	end   cidr.Uint128
 }
 
-// prefixToInterval converts a netip.Prefix to a uint32 interval.
+// prefixToInterval converts a netip.Prefix to an interval.
 func prefixToInterval(p netip.Prefix) interval {
-	start := ipv4ToUint32(p.Masked().Addr())
-	size := uint32(1) << (32 - p.Bits())
-	return interval{start: start, end: start + size - 1}
+	start, end := cidr.PrefixRange(p)
+	return interval{start: start, end: end}
 }
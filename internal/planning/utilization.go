@@ -0,0 +1,228 @@
+package planning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+// MaxHistoryPoints bounds the points an automatically bucketed history
+// returns.
+const MaxHistoryPoints = 500
+
+// historyBuckets are the bucket sizes automatic downsampling picks from.
+var historyBuckets = []time.Duration{
+	5 * time.Minute, 15 * time.Minute, 30 * time.Minute,
+	time.Hour, 3 * time.Hour, 6 * time.Hour, 12 * time.Hour,
+	24 * time.Hour, 7 * 24 * time.Hour,
+}
+
+// UtilizationService records periodic pool utilization snapshots and derives
+// history and exhaustion forecasts from them.
+type UtilizationService struct {
+	store     storage.Store
+	snapshots storage.UtilizationStore
+	now       func() time.Time
+}
+
+// NewUtilizationService creates a new UtilizationService.
+func NewUtilizationService(store storage.Store, snapshots storage.UtilizationStore) *UtilizationService {
+	return &UtilizationService{
+		store:     store,
+		snapshots: snapshots,
+		now:       func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// RecordSnapshots captures the current utilization of every pool at at and
+// returns how many snapshots were stored. A failure for one pool does not
+// stop the others; all failures are returned together.
+func (s *UtilizationService) RecordSnapshots(ctx context.Context, at time.Time) (int, error) {
+	pools, err := s.store.ListPools(ctx)
+	if err != nil {
+		return 0, err
+	}
+	at = at.UTC().Truncate(time.Second)
+
+	var recorded int
+	var errs []error
+	for _, p := range pools {
+		if err := ctx.Err(); err != nil {
+			return recorded, err
+		}
+		stats, err := s.store.CalculatePoolUtilization(ctx, p.ID)
+		if errors.Is(err, storage.ErrNotFound) {
+			continue // deleted since it was listed
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("pool %d: %w", p.ID, err))
+			continue
+		}
+		snap := domain.UtilizationSnapshot{
+			PoolID:       p.ID,
+			TotalIPs:     stats.TotalIPs,
+			UsedIPs:      stats.UsedIPs,
+			AvailableIPs: stats.AvailableIPs,
+			Utilization:  stats.Utilization,
+			ChildCount:   stats.ChildCount,
+			CapturedAt:   at,
+		}
+		if err := s.snapshots.RecordSnapshot(ctx, snap); err != nil {
+			errs = append(errs, fmt.Errorf("pool %d: %w", p.ID, err))
+			continue
+		}
+		recorded++
+	}
+	return recorded, errors.Join(errs...)
+}
+
+// History returns a pool's utilization between req.From and req.To, grouped
+// into req.Bucket-sized buckets. With no bucket, raw snapshots are returned
+// unless there are more than MaxHistoryPoints, in which case the smallest
+// bucket that fits is used.
+func (s *UtilizationService) History(ctx context.Context, poolID int64, req UtilizationHistoryRequest) (*UtilizationHistory, error) {
+	pool, err := s.pool(ctx, poolID)
+	if err != nil {
+		return nil, err
+	}
+	snaps, err := s.snapshots.ListSnapshots(ctx, poolID, req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	bucket := req.Bucket
+	if bucket == 0 && len(snaps) > MaxHistoryPoints {
+		bucket = autoBucket(req.To.Sub(req.From))
+	}
+	return &UtilizationHistory{
+		PoolID:        pool.ID,
+		PoolName:      pool.Name,
+		CIDR:          pool.CIDR,
+		From:          req.From,
+		To:            req.To,
+		BucketSeconds: int64(bucket / time.Second),
+		Points:        downsample(snaps, bucket),
+	}, nil
+}
+
+// Forecast fits growth models to the last lookback of a pool's snapshots
+// and predicts when it runs out of addresses.
+func (s *UtilizationService) Forecast(ctx context.Context, poolID int64, lookback time.Duration) (*UtilizationForecast, error) {
+	pool, err := s.pool(ctx, poolID)
+	if err != nil {
+		return nil, err
+	}
+	return s.forecast(ctx, pool, lookback, s.now())
+}
+
+// ForecastAll forecasts every pool. Pools projected to exhaust come first,
+// soonest first, followed by the rest in pool order. Projections are
+// omitted to keep the report small.
+func (s *UtilizationService) ForecastAll(ctx context.Context, lookback time.Duration) (*UtilizationForecastReport, error) {
+	pools, err := s.store.ListPools(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	items := make([]UtilizationForecast, 0, len(pools))
+	for _, p := range pools {
+		f, err := s.forecast(ctx, p, lookback, now)
+		if err != nil {
+			return nil, fmt.Errorf("forecast pool %d: %w", p.ID, err)
+		}
+		f.Projection = nil
+		items = append(items, *f)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i].ExhaustionAt, items[j].ExhaustionAt
+		switch {
+		case a != nil && b != nil:
+			return a.Before(*b)
+		case a != nil || b != nil:
+			return a != nil
+		default:
+			return items[i].PoolID < items[j].PoolID
+		}
+	})
+	return &UtilizationForecastReport{
+		GeneratedAt:  now,
+		LookbackDays: int(lookback / day),
+		Items:        items,
+	}, nil
+}
+
+func (s *UtilizationService) forecast(ctx context.Context, pool domain.Pool, lookback time.Duration, now time.Time) (*UtilizationForecast, error) {
+	snaps, err := s.snapshots.ListSnapshots(ctx, pool.ID, now.Add(-lookback), now)
+	if err != nil {
+		return nil, err
+	}
+	f := &UtilizationForecast{PoolID: pool.ID, PoolName: pool.Name, CIDR: pool.CIDR}
+	forecastFromSnapshots(f, snaps, now)
+	return f, nil
+}
+
+func (s *UtilizationService) pool(ctx context.Context, poolID int64) (domain.Pool, error) {
+	pool, found, err := s.store.GetPool(ctx, poolID)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+	if !found {
+		return domain.Pool{}, fmt.Errorf("pool %d: %w", poolID, storage.ErrNotFound)
+	}
+	return pool, nil
+}
+
+// autoBucket returns the smallest standard bucket that splits span into at
+// most MaxHistoryPoints buckets.
+func autoBucket(span time.Duration) time.Duration {
+	for _, b := range historyBuckets {
+		if span/b < MaxHistoryPoints {
+			return b
+		}
+	}
+	return historyBuckets[len(historyBuckets)-1]
+}
+
+// downsample groups snaps (oldest first) into UTC-aligned buckets. A zero
+// bucket returns one point per snapshot.
+func downsample(snaps []domain.UtilizationSnapshot, bucket time.Duration) []UtilizationHistoryPoint {
+	points := make([]UtilizationHistoryPoint, 0, len(snaps))
+	var sum float64
+	for _, snap := range snaps {
+		ts := snap.CapturedAt.UTC()
+		if bucket > 0 {
+			ts = ts.Truncate(bucket)
+		}
+		if n := len(points); n > 0 && bucket > 0 && points[n-1].Timestamp.Equal(ts) {
+			p := &points[n-1]
+			p.Samples++
+			sum += snap.Utilization
+			p.AvgUtilization = round2(sum / float64(p.Samples))
+			if snap.Utilization < p.MinUtilization {
+				p.MinUtilization = snap.Utilization
+			}
+			if snap.Utilization > p.MaxUtilization {
+				p.MaxUtilization = snap.Utilization
+			}
+			p.TotalIPs, p.UsedIPs, p.AvailableIPs = snap.TotalIPs, snap.UsedIPs, snap.AvailableIPs
+			continue
+		}
+		sum = snap.Utilization
+		points = append(points, UtilizationHistoryPoint{
+			Timestamp:      ts,
+			Samples:        1,
+			AvgUtilization: snap.Utilization,
+			MinUtilization: snap.Utilization,
+			MaxUtilization: snap.Utilization,
+			TotalIPs:       snap.TotalIPs,
+			UsedIPs:        snap.UsedIPs,
+			AvailableIPs:   snap.AvailableIPs,
+		})
+	}
+	return points
+}
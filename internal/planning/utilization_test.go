@@ -0,0 +1,276 @@
+package planning
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+var forecastOrigin = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC) // a Sunday
+
+// newUtilizationTestService returns a service over a memory store holding
+// one /24 pool, with the clock fixed at now.
+func newUtilizationTestService(t *testing.T, now time.Time) (*UtilizationService, *storage.MemoryStore, *storage.MemoryUtilizationStore, domain.Pool) {
+	t.Helper()
+	st := storage.NewMemoryStore()
+	snaps := storage.NewMemoryUtilizationStore()
+	pool, err := st.CreatePool(context.Background(), domain.CreatePool{Name: "prod", CIDR: "10.0.0.0/24"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := NewUtilizationService(st, snaps)
+	svc.now = func() time.Time { return now }
+	return svc, st, snaps, pool
+}
+
+// seedSnapshots records a snapshot every step for n steps from origin, with
+// used IPs from usedAt out of a /24.
+func seedSnapshots(t *testing.T, snaps storage.UtilizationStore, poolID int64, n int, step time.Duration, usedAt func(time.Time) int64) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		at := forecastOrigin.Add(time.Duration(i) * step)
+		used := usedAt(at)
+		err := snaps.RecordSnapshot(context.Background(), domain.UtilizationSnapshot{
+			PoolID:       poolID,
+			TotalIPs:     256,
+			UsedIPs:      used,
+			AvailableIPs: 256 - used,
+			Utilization:  float64(used) / 256 * 100,
+			CapturedAt:   at,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func linearUsage(base, perDay float64) func(time.Time) int64 {
+	return func(at time.Time) int64 {
+		return int64(math.Round(base + perDay*at.Sub(forecastOrigin).Hours()/24))
+	}
+}
+
+func TestRecordSnapshots(t *testing.T) {
+	ctx := context.Background()
+	svc, st, snaps, parent := newUtilizationTestService(t, forecastOrigin)
+	if _, err := st.CreatePool(ctx, domain.CreatePool{Name: "app", CIDR: "10.0.0.0/26", ParentID: &parent.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	at := forecastOrigin.Add(1500 * time.Millisecond)
+	n, err := svc.RecordSnapshots(ctx, at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Fatalf("recorded = %d, want 2", n)
+	}
+	latest, err := snaps.LatestSnapshot(ctx, parent.ID)
+	if err != nil || latest == nil {
+		t.Fatalf("latest = %v, %v", latest, err)
+	}
+	if latest.TotalIPs != 256 || latest.UsedIPs != 64 || latest.ChildCount != 1 {
+		t.Errorf("snapshot = %+v, want 64 of 256 used by 1 child", latest)
+	}
+	if !latest.CapturedAt.Equal(forecastOrigin.Add(time.Second)) {
+		t.Errorf("captured_at = %s, want truncated to the second", latest.CapturedAt)
+	}
+}
+
+func TestHistoryDownsampling(t *testing.T) {
+	ctx := context.Background()
+	svc, _, snaps, pool := newUtilizationTestService(t, forecastOrigin)
+	seedSnapshots(t, snaps, pool.ID, 48, time.Hour, linearUsage(0, 24))
+	from, to := forecastOrigin, forecastOrigin.Add(48*time.Hour)
+
+	raw, err := svc.History(ctx, pool.ID, UtilizationHistoryRequest{From: from, To: to})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(raw.Points) != 48 || raw.BucketSeconds != 0 {
+		t.Fatalf("raw history = %d points, bucket %ds; want 48 raw", len(raw.Points), raw.BucketSeconds)
+	}
+
+	daily, err := svc.History(ctx, pool.ID, UtilizationHistoryRequest{From: from, To: to, Bucket: 24 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(daily.Points) != 2 || daily.BucketSeconds != 86400 {
+		t.Fatalf("daily history = %d points, bucket %ds; want 2 of 86400s", len(daily.Points), daily.BucketSeconds)
+	}
+	first := daily.Points[0]
+	if first.Samples != 24 || first.MinUtilization != 0 || first.UsedIPs != 23 || !first.Timestamp.Equal(forecastOrigin) {
+		t.Errorf("first bucket = %+v", first)
+	}
+	if first.AvgUtilization <= first.MinUtilization || first.AvgUtilization >= first.MaxUtilization {
+		t.Errorf("avg %v not between min %v and max %v", first.AvgUtilization, first.MinUtilization, first.MaxUtilization)
+	}
+
+	if _, err := svc.History(ctx, 999, UtilizationHistoryRequest{From: from, To: to}); err == nil {
+		t.Error("expected not found for unknown pool")
+	}
+}
+
+func TestHistoryAutoBucket(t *testing.T) {
+	svc, _, snaps, pool := newUtilizationTestService(t, forecastOrigin)
+	seedSnapshots(t, snaps, pool.ID, 600, time.Minute, linearUsage(0, 0))
+
+	h, err := svc.History(context.Background(), pool.ID, UtilizationHistoryRequest{
+		From: forecastOrigin,
+		To:   forecastOrigin.Add(10 * time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.BucketSeconds != 300 || len(h.Points) != 120 {
+		t.Errorf("auto history = %d points, bucket %ds; want 120 of 300s", len(h.Points), h.BucketSeconds)
+	}
+}
+
+func TestForecastLinear(t *testing.T) {
+	now := forecastOrigin.Add(29 * 24 * time.Hour)
+	svc, _, snaps, pool := newUtilizationTestService(t, now)
+	seedSnapshots(t, snaps, pool.ID, 30, 24*time.Hour, linearUsage(10, 3))
+
+	f, err := svc.Forecast(context.Background(), pool.ID, 90*24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Method != ForecastLinear {
+		t.Errorf("method = %q, want linear", f.Method)
+	}
+	if f.GrowthPerDay != 3 {
+		t.Errorf("growth = %v, want 3/day", f.GrowthPerDay)
+	}
+	// 10 + 3d reaches 256 on day 82, 53 days after the last snapshot.
+	want := forecastOrigin.Add(82 * 24 * time.Hour)
+	if f.ExhaustionAt == nil || !f.ExhaustionAt.Equal(want) {
+		t.Fatalf("exhaustion = %v, want %s", f.ExhaustionAt, want)
+	}
+	if f.DaysToExhaustion == nil || *f.DaysToExhaustion != 53 {
+		t.Errorf("days to exhaustion = %v, want 53", f.DaysToExhaustion)
+	}
+	if len(f.Projection) != ProjectionDays || f.Projection[0].UsedIPs != 100 {
+		t.Errorf("projection starts %+v, want 100 used the next day", f.Projection[0])
+	}
+	if last := f.Projection[len(f.Projection)-1]; last.UsedIPs != 256 || last.Utilization != 100 {
+		t.Errorf("projection ends %+v, want clamped to capacity", last)
+	}
+	if len(f.Models) != 2 {
+		t.Errorf("models = %d, want linear and seasonal", len(f.Models))
+	}
+}
+
+func TestForecastSeasonal(t *testing.T) {
+	now := forecastOrigin.Add(28 * 24 * time.Hour)
+	svc, _, snaps, pool := newUtilizationTestService(t, now)
+	// Weekday workloads hold 30 more addresses than weekends.
+	trend := linearUsage(40, 1.5)
+	seedSnapshots(t, snaps, pool.ID, 28*4, 6*time.Hour, func(at time.Time) int64 {
+		used := trend(at)
+		if wd := at.Weekday(); wd != time.Saturday && wd != time.Sunday {
+			used += 30
+		}
+		return used
+	})
+
+	f, err := svc.Forecast(context.Background(), pool.ID, 90*24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Method != ForecastSeasonal {
+		t.Fatalf("method = %q, want seasonal; models %+v", f.Method, f.Models)
+	}
+	if math.Abs(f.GrowthPerDay-1.5) > 0.05 {
+		t.Errorf("growth = %v, want about 1.5/day", f.GrowthPerDay)
+	}
+	if f.ExhaustionAt == nil {
+		t.Fatalf("no exhaustion: %s", f.Reason)
+	}
+	// The seasonal model must hit capacity on a weekday, before the plain
+	// trend would.
+	if wd := f.ExhaustionAt.Weekday(); wd == time.Saturday || wd == time.Sunday {
+		t.Errorf("exhaustion on %s, want a weekday", wd)
+	}
+	linear := f.Models[0]
+	if linear.Method != ForecastLinear || linear.AdjustedRSquared >= f.Models[1].AdjustedRSquared {
+		t.Errorf("linear model %+v should fit worse than seasonal %+v", linear, f.Models[1])
+	}
+	if linear.ExhaustionAt == nil || !f.ExhaustionAt.Before(*linear.ExhaustionAt) {
+		t.Errorf("seasonal exhaustion %s should precede linear %v", f.ExhaustionAt, linear.ExhaustionAt)
+	}
+}
+
+func TestForecastReasons(t *testing.T) {
+	tests := []struct {
+		name     string
+		n        int
+		step     time.Duration
+		usedAt   func(time.Time) int64
+		reason   string
+		exhausts bool
+	}{
+		{name: "no history", n: 0, step: time.Hour, usedAt: linearUsage(0, 0), reason: "no utilization snapshots recorded"},
+		{name: "short history", n: 5, step: time.Hour, usedAt: linearUsage(10, 5), reason: "insufficient history: need at least 3 snapshots spanning a day"},
+		{name: "flat", n: 10, step: 24 * time.Hour, usedAt: linearUsage(50, 0), reason: "usage is flat or shrinking"},
+		{name: "shrinking", n: 10, step: 24 * time.Hour, usedAt: linearUsage(200, -3), reason: "usage is flat or shrinking"},
+		{name: "slow growth", n: 30, step: 24 * time.Hour, usedAt: linearUsage(10, 0.1), reason: "not projected to exhaust within 5 years"},
+		{name: "full", n: 3, step: time.Hour, usedAt: linearUsage(256, 0), reason: "pool is already full", exhausts: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := forecastOrigin.Add(time.Duration(tt.n) * tt.step)
+			svc, _, snaps, pool := newUtilizationTestService(t, now)
+			seedSnapshots(t, snaps, pool.ID, tt.n, tt.step, tt.usedAt)
+			f, err := svc.Forecast(context.Background(), pool.ID, 90*24*time.Hour)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if f.Reason != tt.reason {
+				t.Errorf("reason = %q, want %q", f.Reason, tt.reason)
+			}
+			if (f.ExhaustionAt != nil) != tt.exhausts {
+				t.Errorf("exhaustion = %v, want set %v", f.ExhaustionAt, tt.exhausts)
+			}
+		})
+	}
+}
+
+func TestForecastAllOrdersBySoonestExhaustion(t *testing.T) {
+	ctx := context.Background()
+	now := forecastOrigin.Add(10 * 24 * time.Hour)
+	svc, st, snaps, flat := newUtilizationTestService(t, now)
+	slow, err := st.CreatePool(ctx, domain.CreatePool{Name: "slow", CIDR: "10.1.0.0/24"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fast, err := st.CreatePool(ctx, domain.CreatePool{Name: "fast", CIDR: "10.2.0.0/24"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	seedSnapshots(t, snaps, flat.ID, 10, 24*time.Hour, linearUsage(50, 0))
+	seedSnapshots(t, snaps, slow.ID, 10, 24*time.Hour, linearUsage(10, 1))
+	seedSnapshots(t, snaps, fast.ID, 10, 24*time.Hour, linearUsage(10, 10))
+
+	report, err := svc.ForecastAll(ctx, 30*24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.LookbackDays != 30 || len(report.Items) != 3 {
+		t.Fatalf("report = %d items over %d days", len(report.Items), report.LookbackDays)
+	}
+	got := []int64{report.Items[0].PoolID, report.Items[1].PoolID, report.Items[2].PoolID}
+	want := []int64{fast.ID, slow.ID, flat.ID}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("order = %v, want %v", got, want)
+		}
+	}
+	if report.Items[0].Projection != nil {
+		t.Error("fleet report should omit projections")
+	}
+}
@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"context"
+
+	"cloudpam/internal/domain"
+)
+
+// AlertStore persists capacity alerts.
+type AlertStore interface {
+	// CreateAlert stores a new alert. It returns ErrConflict if an active
+	// alert already exists for the same rule, pool and kind.
+	CreateAlert(ctx context.Context, a domain.Alert) error
+
+	// GetAlert returns an alert by ID.
+	GetAlert(ctx context.Context, id string) (*domain.Alert, error)
+
+	// ListAlerts returns paginated alerts matching the filters, most
+	// recently opened first.
+	ListAlerts(ctx context.Context, filters domain.AlertFilters) ([]domain.Alert, int, error)
+
+	// ListActiveAlerts returns every open or acknowledged alert.
+	ListActiveAlerts(ctx context.Context) ([]domain.Alert, error)
+
+	// UpdateAlert replaces an alert's mutable fields: severity, status,
+	// value, threshold, message, and the updated, acknowledged, resolved
+	// and notified fields.
+	UpdateAlert(ctx context.Context, a domain.Alert) error
+}
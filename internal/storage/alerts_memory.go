@@ -0,0 +1,165 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"cloudpam/internal/domain"
+)
+
+// MemoryAlertStore is an in-memory implementation of AlertStore.
+type MemoryAlertStore struct {
+	mu     sync.RWMutex
+	alerts map[string]domain.Alert
+}
+
+// NewMemoryAlertStore creates a new in-memory alert store.
+func NewMemoryAlertStore() *MemoryAlertStore {
+	return &MemoryAlertStore{alerts: make(map[string]domain.Alert)}
+}
+
+var _ AlertStore = (*MemoryAlertStore)(nil)
+
+func (s *MemoryAlertStore) CreateAlert(_ context.Context, a domain.Alert) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.alerts[a.ID]; ok {
+		return ErrConflict
+	}
+	if a.Active() {
+		for _, existing := range s.alerts {
+			if existing.Active() && existing.RuleID == a.RuleID && existing.PoolID == a.PoolID && existing.Kind == a.Kind {
+				return ErrConflict
+			}
+		}
+	}
+	s.alerts[a.ID] = cloneAlert(a)
+	return nil
+}
+
+func (s *MemoryAlertStore) GetAlert(_ context.Context, id string) (*domain.Alert, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	a, ok := s.alerts[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	out := cloneAlert(a)
+	return &out, nil
+}
+
+func (s *MemoryAlertStore) ListAlerts(_ context.Context, filters domain.AlertFilters) ([]domain.Alert, int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var filtered []domain.Alert
+	for _, a := range s.alerts {
+		if filters.PoolID != 0 && a.PoolID != filters.PoolID {
+			continue
+		}
+		switch filters.Status {
+		case "":
+		case domain.AlertStatusActive:
+			if !a.Active() {
+				continue
+			}
+		default:
+			if string(a.Status) != filters.Status {
+				continue
+			}
+		}
+		if filters.Severity != "" && string(a.Severity) != filters.Severity {
+			continue
+		}
+		if filters.Kind != "" && string(a.Kind) != filters.Kind {
+			continue
+		}
+		filtered = append(filtered, a)
+	}
+	sortAlerts(filtered)
+
+	total := len(filtered)
+	page := filters.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := filters.PageSize
+	if pageSize < 1 {
+		pageSize = 50
+	}
+	start := (page - 1) * pageSize
+	if start > total {
+		return []domain.Alert{}, total, nil
+	}
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+	out := make([]domain.Alert, 0, end-start)
+	for _, a := range filtered[start:end] {
+		out = append(out, cloneAlert(a))
+	}
+	return out, total, nil
+}
+
+func (s *MemoryAlertStore) ListActiveAlerts(_ context.Context) ([]domain.Alert, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := []domain.Alert{}
+	for _, a := range s.alerts {
+		if a.Active() {
+			out = append(out, cloneAlert(a))
+		}
+	}
+	sortAlerts(out)
+	return out, nil
+}
+
+func (s *MemoryAlertStore) UpdateAlert(_ context.Context, a domain.Alert) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, ok := s.alerts[a.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	existing.Severity = a.Severity
+	existing.Status = a.Status
+	existing.Value = a.Value
+	existing.Threshold = a.Threshold
+	existing.Message = a.Message
+	existing.UpdatedAt = a.UpdatedAt
+	existing.AcknowledgedAt = a.AcknowledgedAt
+	existing.AcknowledgedBy = a.AcknowledgedBy
+	existing.ResolvedAt = a.ResolvedAt
+	existing.ResolvedBy = a.ResolvedBy
+	existing.NotifiedAt = a.NotifiedAt
+	s.alerts[a.ID] = cloneAlert(existing)
+	return nil
+}
+
+// sortAlerts orders alerts most recently opened first.
+func sortAlerts(alerts []domain.Alert) {
+	sort.Slice(alerts, func(i, j int) bool {
+		if !alerts[i].OpenedAt.Equal(alerts[j].OpenedAt) {
+			return alerts[i].OpenedAt.After(alerts[j].OpenedAt)
+		}
+		return alerts[i].ID > alerts[j].ID
+	})
+}
+
+func cloneAlert(a domain.Alert) domain.Alert {
+	if a.AcknowledgedAt != nil {
+		t := *a.AcknowledgedAt
+		a.AcknowledgedAt = &t
+	}
+	if a.ResolvedAt != nil {
+		t := *a.ResolvedAt
+		a.ResolvedAt = &t
+	}
+	if a.NotifiedAt != nil {
+		t := *a.NotifiedAt
+		a.NotifiedAt = &t
+	}
+	return a
+}
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cloudpam/internal/domain"
+)
+
+func newTestAlert(id string, poolID int64, status domain.AlertStatus, opened time.Time) domain.Alert {
+	return domain.Alert{
+		ID: id, RuleID: "default", PoolID: poolID, PoolName: "prod", PoolCIDR: "10.0.0.0/24",
+		Kind: domain.AlertKindUtilization, Severity: domain.AlertSeverityWarning, Status: status,
+		Value: 85, Threshold: 80, Message: "Pool prod is 85% utilized",
+		OpenedAt: opened, UpdatedAt: opened,
+	}
+}
+
+func TestMemoryAlertStore(t *testing.T) {
+	store := NewMemoryAlertStore()
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	if err := store.CreateAlert(ctx, newTestAlert("a1", 1, domain.AlertStatusOpen, now)); err != nil {
+		t.Fatalf("CreateAlert: %v", err)
+	}
+	// A second active alert for the same rule, pool and kind conflicts.
+	if err := store.CreateAlert(ctx, newTestAlert("a2", 1, domain.AlertStatusOpen, now)); !errors.Is(err, ErrConflict) {
+		t.Fatalf("duplicate CreateAlert err = %v, want ErrConflict", err)
+	}
+	if err := store.CreateAlert(ctx, newTestAlert("a3", 2, domain.AlertStatusOpen, now.Add(time.Minute))); err != nil {
+		t.Fatalf("CreateAlert: %v", err)
+	}
+
+	got, err := store.GetAlert(ctx, "a1")
+	if err != nil {
+		t.Fatalf("GetAlert: %v", err)
+	}
+	acked := now.Add(time.Hour)
+	got.Status = domain.AlertStatusAcknowledged
+	ackedAt := acked
+	got.AcknowledgedAt, got.AcknowledgedBy = &ackedAt, "alice"
+	if err := store.UpdateAlert(ctx, *got); err != nil {
+		t.Fatalf("UpdateAlert: %v", err)
+	}
+	// Mutating the caller's copy must not reach the store.
+	*got.AcknowledgedAt = time.Time{}
+	again, _ := store.GetAlert(ctx, "a1")
+	if again.Status != domain.AlertStatusAcknowledged || !again.AcknowledgedAt.Equal(acked) || again.AcknowledgedBy != "alice" {
+		t.Fatalf("GetAlert after update = %+v", again)
+	}
+
+	resolved := newTestAlert("a3", 2, domain.AlertStatusResolved, now)
+	resolved.ResolvedAt, resolved.ResolvedBy = &acked, "system"
+	if err := store.UpdateAlert(ctx, resolved); err != nil {
+		t.Fatalf("UpdateAlert: %v", err)
+	}
+	// Once resolved, a new alert for the same condition can open.
+	if err := store.CreateAlert(ctx, newTestAlert("a4", 2, domain.AlertStatusOpen, now.Add(2*time.Minute))); err != nil {
+		t.Fatalf("CreateAlert after resolve: %v", err)
+	}
+
+	active, err := store.ListActiveAlerts(ctx)
+	if err != nil || len(active) != 2 || active[0].ID != "a4" || active[1].ID != "a1" {
+		t.Fatalf("ListActiveAlerts = %+v, %v", active, err)
+	}
+	items, total, err := store.ListAlerts(ctx, domain.AlertFilters{PoolID: 2})
+	if err != nil || total != 2 || items[0].ID != "a4" {
+		t.Fatalf("ListAlerts(pool) = %+v, %d, %v", items, total, err)
+	}
+	items, total, _ = store.ListAlerts(ctx, domain.AlertFilters{Status: domain.AlertStatusActive, PageSize: 1, Page: 2})
+	if total != 2 || len(items) != 1 || items[0].ID != "a1" {
+		t.Fatalf("ListAlerts(active, page 2) = %+v, %d", items, total)
+	}
+	if _, total, _ := store.ListAlerts(ctx, domain.AlertFilters{Status: string(domain.AlertStatusResolved)}); total != 1 {
+		t.Fatalf("resolved total = %d, want 1", total)
+	}
+
+	if _, err := store.GetAlert(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetAlert(missing) err = %v, want ErrNotFound", err)
+	}
+	if err := store.UpdateAlert(ctx, newTestAlert("missing", 1, domain.AlertStatusOpen, now)); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("UpdateAlert(missing) err = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,23 @@
+package storage
+
+import (
+	"context"
+
+	"cloudpam/internal/domain"
+)
+
+// AllocateFunc chooses the pool to create given a parent and its current live
+// children. It runs while the store holds the parent's allocation lock, so it
+// must not call back into the store.
+type AllocateFunc func(parent domain.Pool, children []domain.Pool) (domain.CreatePool, error)
+
+// PoolAllocator carves a new child pool out of a parent in one atomic step.
+// Concurrent allocations against the same parent are serialized, so two
+// callers never receive the same block.
+type PoolAllocator interface {
+	// AllocatePool loads the parent and its children, asks pick for the pool
+	// to create and inserts it under parentID. It returns ErrNotFound if the
+	// parent does not exist. Errors from pick are returned unchanged and
+	// nothing is written.
+	AllocatePool(ctx context.Context, parentID int64, pick AllocateFunc) (domain.Pool, error)
+}
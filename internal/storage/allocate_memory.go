@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"cloudpam/internal/domain"
+)
+
+var _ PoolAllocator = (*MemoryStore)(nil)
+
+// AllocatePool picks and inserts a child pool under the store's write lock.
+func (m *MemoryStore) AllocatePool(ctx context.Context, parentID int64, pick AllocateFunc) (domain.Pool, error) {
+	m.lockWrite()
+	defer m.unlockWrite()
+
+	parent, ok := m.pools[parentID]
+	if !ok || parent.DeletedAt != nil {
+		return domain.Pool{}, fmt.Errorf("parent pool not found: %w", ErrNotFound)
+	}
+	var children []domain.Pool
+	for _, p := range m.pools {
+		if p.DeletedAt != nil {
+			continue
+		}
+		if p.ParentID != nil && *p.ParentID == parentID {
+			children = append(children, clonePool(p))
+		}
+	}
+
+	in, err := pick(clonePool(parent), children)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+	in.ParentID = &parentID
+	return m.createPoolLocked(in)
+}
@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"cloudpam/internal/domain"
+)
+
+func TestMemoryStore_AllocatePool(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryStore()
+	parent, err := m.CreatePool(ctx, domain.CreatePool{Name: "root", CIDR: "10.0.0.0/16"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _ := m.CreatePool(ctx, domain.CreatePool{Name: "other", CIDR: "172.16.0.0/16"})
+	existing, _ := m.CreatePool(ctx, domain.CreatePool{Name: "a", CIDR: "10.0.0.0/24", ParentID: &parent.ID})
+
+	var seen []domain.Pool
+	p, err := m.AllocatePool(ctx, parent.ID, func(par domain.Pool, children []domain.Pool) (domain.CreatePool, error) {
+		if par.ID != parent.ID {
+			t.Errorf("pick got parent %d, want %d", par.ID, parent.ID)
+		}
+		seen = children
+		// ParentID from pick is overridden with the allocation parent.
+		return domain.CreatePool{Name: "b", CIDR: "10.0.1.0/24", ParentID: &other.ID}, nil
+	})
+	if err != nil {
+		t.Fatalf("allocate: %v", err)
+	}
+	if len(seen) != 1 || seen[0].ID != existing.ID {
+		t.Fatalf("pick saw children %+v, want only pool %d", seen, existing.ID)
+	}
+	if p.ParentID == nil || *p.ParentID != parent.ID {
+		t.Fatalf("expected parent %d, got %v", parent.ID, p.ParentID)
+	}
+	if p.Type != domain.PoolTypeSubnet || p.Status != domain.PoolStatusActive || p.Source != domain.PoolSourceManual {
+		t.Fatalf("expected defaults to be applied, got %+v", p)
+	}
+}
+
+func TestMemoryStore_AllocatePool_Errors(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryStore()
+
+	_, err := m.AllocatePool(ctx, 99, func(domain.Pool, []domain.Pool) (domain.CreatePool, error) {
+		t.Fatal("pick must not run for a missing parent")
+		return domain.CreatePool{}, nil
+	})
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	parent, _ := m.CreatePool(ctx, domain.CreatePool{Name: "root", CIDR: "10.0.0.0/16"})
+	sentinel := errors.New("no room")
+	_, err = m.AllocatePool(ctx, parent.ID, func(domain.Pool, []domain.Pool) (domain.CreatePool, error) {
+		return domain.CreatePool{}, sentinel
+	})
+	if !errors.Is(err, sentinel) {
+		t.Fatalf("expected pick error to pass through, got %v", err)
+	}
+	pools, _ := m.ListPools(ctx)
+	if len(pools) != 1 {
+		t.Fatalf("expected nothing written on pick error, got %d pools", len(pools))
+	}
+}
+
+func TestMemoryStore_AllocatePool_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryStore()
+	parent, _ := m.CreatePool(ctx, domain.CreatePool{Name: "root", CIDR: "10.0.0.0/16"})
+
+	// Each pick takes the next /24 by counting siblings; without the lock two
+	// goroutines would see the same count and choose the same block.
+	const n = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := m.AllocatePool(ctx, parent.ID, func(_ domain.Pool, children []domain.Pool) (domain.CreatePool, error) {
+				return domain.CreatePool{Name: fmt.Sprintf("s%d", i), CIDR: fmt.Sprintf("10.0.%d.0/24", len(children))}, nil
+			})
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("allocate: %v", err)
+		}
+	}
+
+	children, _ := m.GetPoolChildren(ctx, parent.ID)
+	cidrs := make(map[string]bool)
+	for _, c := range children {
+		if cidrs[c.CIDR] {
+			t.Fatalf("duplicate allocation %s", c.CIDR)
+		}
+		cidrs[c.CIDR] = true
+	}
+	if len(cidrs) != n {
+		t.Fatalf("expected %d distinct children, got %d", n, len(cidrs))
+	}
+}
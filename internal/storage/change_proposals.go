@@ -0,0 +1,27 @@
+package storage
+
+import (
+	"context"
+
+	"cloudpam/internal/domain"
+)
+
+// ChangeProposalStore persists change proposals awaiting review.
+type ChangeProposalStore interface {
+	// CreateChangeProposal stores a new proposal. It returns ErrConflict if
+	// the ID is taken.
+	CreateChangeProposal(ctx context.Context, p domain.ChangeProposal) error
+
+	// GetChangeProposal returns a proposal by ID.
+	GetChangeProposal(ctx context.Context, id string) (*domain.ChangeProposal, error)
+
+	// ListChangeProposals returns proposals with the given status, or all of
+	// them when status is empty, newest first.
+	ListChangeProposals(ctx context.Context, status domain.ChangeProposalStatus) ([]domain.ChangeProposal, error)
+
+	// ReviewChangeProposal records the decision on a pending proposal: its
+	// status, the reviewed fields and the pool map. It returns ErrConflict
+	// if the proposal is no longer pending, so two reviewers cannot both
+	// decide it.
+	ReviewChangeProposal(ctx context.Context, p domain.ChangeProposal) error
+}
@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"cloudpam/internal/domain"
+)
+
+// MemoryChangeProposalStore is an in-memory implementation of ChangeProposalStore.
+type MemoryChangeProposalStore struct {
+	mu        sync.RWMutex
+	proposals map[string]domain.ChangeProposal
+}
+
+// NewMemoryChangeProposalStore creates a new in-memory change proposal store.
+func NewMemoryChangeProposalStore() *MemoryChangeProposalStore {
+	return &MemoryChangeProposalStore{proposals: make(map[string]domain.ChangeProposal)}
+}
+
+func (s *MemoryChangeProposalStore) CreateChangeProposal(_ context.Context, p domain.ChangeProposal) error {
+	if p.ID == "" {
+		return ErrValidation
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.proposals[p.ID]; exists {
+		return ErrConflict
+	}
+	s.proposals[p.ID] = cloneChangeProposal(p)
+	return nil
+}
+
+func (s *MemoryChangeProposalStore) GetChangeProposal(_ context.Context, id string) (*domain.ChangeProposal, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p, ok := s.proposals[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	out := cloneChangeProposal(p)
+	return &out, nil
+}
+
+func (s *MemoryChangeProposalStore) ListChangeProposals(_ context.Context, status domain.ChangeProposalStatus) ([]domain.ChangeProposal, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]domain.ChangeProposal, 0, len(s.proposals))
+	for _, p := range s.proposals {
+		if status != "" && p.Status != status {
+			continue
+		}
+		out = append(out, cloneChangeProposal(p))
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.After(out[j].CreatedAt)
+		}
+		return out[i].ID > out[j].ID
+	})
+	return out, nil
+}
+
+func (s *MemoryChangeProposalStore) ReviewChangeProposal(_ context.Context, p domain.ChangeProposal) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, ok := s.proposals[p.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if existing.Status != domain.ChangeProposalPending {
+		return fmt.Errorf("change proposal %s is %s: %w", p.ID, existing.Status, ErrConflict)
+	}
+	existing.Status = p.Status
+	existing.ReviewedBy = p.ReviewedBy
+	existing.ReviewedByID = p.ReviewedByID
+	existing.ReviewedRole = p.ReviewedRole
+	existing.ReviewComment = p.ReviewComment
+	existing.ReviewedAt = p.ReviewedAt
+	existing.PoolMap = p.PoolMap
+	s.proposals[p.ID] = cloneChangeProposal(existing)
+	return nil
+}
+
+func cloneChangeProposal(p domain.ChangeProposal) domain.ChangeProposal {
+	p.Tree.Pools = cloneSlice(p.Tree.Pools)
+	p.Tree.Tags = cloneStringStringMap(p.Tree.Tags)
+	p.ChangeSet.Create = cloneSlice(p.ChangeSet.Create)
+	p.ChangeSet.Skip = cloneSlice(p.ChangeSet.Skip)
+	p.ChangeSet.Violations = cloneSlice(p.ChangeSet.Violations)
+	p.ChangeSet.Warnings = cloneStringSlice(p.ChangeSet.Warnings)
+	if p.PoolMap != nil {
+		m := make(map[string]int64, len(p.PoolMap))
+		for k, v := range p.PoolMap {
+			m[k] = v
+		}
+		p.PoolMap = m
+	}
+	if p.ReviewedAt != nil {
+		t := *p.ReviewedAt
+		p.ReviewedAt = &t
+	}
+	return p
+}
+
+// cloneSlice copies a slice of plain values, keeping nil and empty apart.
+func cloneSlice[T any](in []T) []T {
+	if in == nil {
+		return nil
+	}
+	out := make([]T, len(in))
+	copy(out, in)
+	return out
+}
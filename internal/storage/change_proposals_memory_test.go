@@ -0,0 +1,75 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cloudpam/internal/domain"
+)
+
+func TestMemoryChangeProposalStore(t *testing.T) {
+	store := NewMemoryChangeProposalStore()
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	p := domain.ChangeProposal{
+		ID: "p1", Title: "2 pools from schema", Source: domain.ChangeProposalSourceSchema,
+		Status: domain.ChangeProposalPending, ProposedBy: "alice", ProposedRole: "operator",
+		Tree: domain.PoolTree{Pools: []domain.PoolSpec{
+			{Ref: "root", Name: "Root", CIDR: "10.0.0.0/16"},
+			{Ref: "a", Name: "A", CIDR: "10.0.0.0/24", ParentRef: "root"},
+		}},
+		ChangeSet: domain.ChangeSet{Create: []domain.PlannedPool{}, Skip: []domain.SkippedPool{}, Applicable: true},
+		CreatedAt: now.Add(-time.Minute),
+	}
+	if err := store.CreateChangeProposal(ctx, p); err != nil {
+		t.Fatalf("CreateChangeProposal: %v", err)
+	}
+	if err := store.CreateChangeProposal(ctx, p); !errors.Is(err, ErrConflict) {
+		t.Fatalf("duplicate CreateChangeProposal: expected ErrConflict, got %v", err)
+	}
+	second := p
+	second.ID, second.CreatedAt = "p2", now
+	if err := store.CreateChangeProposal(ctx, second); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := store.GetChangeProposal(ctx, "p1")
+	if err != nil {
+		t.Fatalf("GetChangeProposal: %v", err)
+	}
+	// Returned values are copies; empty slices stay empty rather than nil.
+	got.Tree.Pools[0].Name = "changed"
+	if again, _ := store.GetChangeProposal(ctx, "p1"); again.Tree.Pools[0].Name != "Root" || again.ChangeSet.Skip == nil {
+		t.Fatalf("stored copy = %+v", again)
+	}
+	if _, err := store.GetChangeProposal(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetChangeProposal missing: expected ErrNotFound, got %v", err)
+	}
+
+	reviewed := now
+	got.Status = domain.ChangeProposalApplied
+	got.ReviewedBy, got.ReviewedRole, got.ReviewedAt = "bob", "admin", &reviewed
+	got.PoolMap = map[string]int64{"root": 1, "a": 2}
+	if err := store.ReviewChangeProposal(ctx, *got); err != nil {
+		t.Fatalf("ReviewChangeProposal: %v", err)
+	}
+	if err := store.ReviewChangeProposal(ctx, *got); !errors.Is(err, ErrConflict) {
+		t.Fatalf("second ReviewChangeProposal: expected ErrConflict, got %v", err)
+	}
+	got.ID = "missing"
+	if err := store.ReviewChangeProposal(ctx, *got); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("ReviewChangeProposal missing: expected ErrNotFound, got %v", err)
+	}
+
+	all, err := store.ListChangeProposals(ctx, "")
+	if err != nil || len(all) != 2 || all[0].ID != "p2" {
+		t.Fatalf("ListChangeProposals = %+v, %v", all, err)
+	}
+	applied, err := store.ListChangeProposals(ctx, domain.ChangeProposalApplied)
+	if err != nil || len(applied) != 1 || applied[0].PoolMap["a"] != 2 || applied[0].ReviewedBy != "bob" {
+		t.Fatalf("ListChangeProposals(applied) = %+v, %v", applied, err)
+	}
+}
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"cloudpam/internal/cidr"
+	"cloudpam/internal/domain"
+)
+
+// ParseCIDRArg parses the prefix or bare address passed to a CIDROperations
+// method. Host bits are masked off; errors wrap ErrValidation.
+func ParseCIDRArg(s string) (netip.Prefix, error) {
+	p, err := cidr.ParseCIDROrIP(strings.TrimSpace(s))
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("%v: %w", err, ErrValidation)
+	}
+	return p, nil
+}
+
+// FreeBlocks implements FindGaps for a parent pool and its live children.
+// The gaps are returned as maximal aligned blocks in address order. A
+// prefixLen of 0 returns every gap; otherwise only blocks that can hold a
+// /prefixLen are returned, and prefixLen must lie between the parent's
+// prefix length and the family's bit length.
+func FreeBlocks(parent domain.Pool, children []domain.Pool, prefixLen int) ([]string, error) {
+	pp, err := netip.ParsePrefix(parent.CIDR)
+	if err != nil {
+		return nil, fmt.Errorf("parse pool cidr %q: %w", parent.CIDR, err)
+	}
+	pp = pp.Masked()
+	if prefixLen != 0 && (prefixLen < pp.Bits() || prefixLen > pp.Addr().BitLen()) {
+		return nil, fmt.Errorf("prefix length /%d must be between /%d and /%d: %w", prefixLen, pp.Bits(), pp.Addr().BitLen(), ErrValidation)
+	}
+	used := make([]netip.Prefix, 0, len(children))
+	for _, c := range children {
+		if cp, err := netip.ParsePrefix(c.CIDR); err == nil {
+			used = append(used, cp.Masked())
+		}
+	}
+	out := []string{}
+	for _, b := range cidr.FreeBlocks(pp, used) {
+		if prefixLen == 0 || b.Bits() <= prefixLen {
+			out = append(out, b.String())
+		}
+	}
+	return out, nil
+}
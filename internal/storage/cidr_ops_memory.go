@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"cloudpam/internal/cidr"
+	"cloudpam/internal/domain"
+)
+
+var _ CIDROperations = (*MemoryStore)(nil)
+
+// cidrIndexLocked returns the cached interval tree over every pool, building
+// it if a write dropped it. Soft-deleted pools stay in the tree and are
+// filtered when results are resolved, so only inserts, resizes and rollbacks
+// need to invalidate it. Callers hold at least the read lock.
+func (m *MemoryStore) cidrIndexLocked() *cidr.Index {
+	if idx := m.cidrIdx.Load(); idx != nil {
+		return idx
+	}
+	entries := make([]cidr.IndexEntry, 0, len(m.pools))
+	for id, p := range m.pools {
+		if pp, err := netip.ParsePrefix(p.CIDR); err == nil {
+			entries = append(entries, cidr.IndexEntry{Prefix: pp, ID: id})
+		}
+	}
+	idx := cidr.NewIndex(entries)
+	m.cidrIdx.Store(idx)
+	return idx
+}
+
+// resolveLocked maps index hits back to live pools, keeping their order.
+func (m *MemoryStore) resolveLocked(hits []cidr.IndexEntry, keep func(domain.Pool) bool) []*domain.Pool {
+	out := []*domain.Pool{}
+	for _, h := range hits {
+		p, ok := m.pools[h.ID]
+		if !ok || p.DeletedAt != nil || (keep != nil && !keep(p)) {
+			continue
+		}
+		cp := clonePool(p)
+		out = append(out, &cp)
+	}
+	return out
+}
+
+// FindOverlapping returns live pools overlapping cidr, optionally limited to
+// one level of the hierarchy.
+func (m *MemoryStore) FindOverlapping(ctx context.Context, cidr string, parentID *int64) ([]*domain.Pool, error) {
+	q, err := ParseCIDRArg(cidr)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var keep func(domain.Pool) bool
+	if parentID != nil {
+		keep = func(p domain.Pool) bool {
+			if *parentID == 0 {
+				return p.ParentID == nil
+			}
+			return p.ParentID != nil && *p.ParentID == *parentID
+		}
+	}
+	return m.resolveLocked(m.cidrIndexLocked().Overlapping(q), keep), nil
+}
+
+// FindContaining returns live pools whose CIDR contains cidr.
+func (m *MemoryStore) FindContaining(ctx context.Context, cidr string) ([]*domain.Pool, error) {
+	q, err := ParseCIDRArg(cidr)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.resolveLocked(m.cidrIndexLocked().Containing(q), nil), nil
+}
+
+// FindContainedBy returns live pools inside cidr.
+func (m *MemoryStore) FindContainedBy(ctx context.Context, cidr string) ([]*domain.Pool, error) {
+	q, err := ParseCIDRArg(cidr)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.resolveLocked(m.cidrIndexLocked().ContainedBy(q), nil), nil
+}
+
+// FindGaps returns the free blocks inside a pool.
+func (m *MemoryStore) FindGaps(ctx context.Context, parentID int64, prefixLen int) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	parent, ok := m.pools[parentID]
+	if !ok || parent.DeletedAt != nil {
+		return nil, fmt.Errorf("parent pool not found: %w", ErrNotFound)
+	}
+	var children []domain.Pool
+	for _, p := range m.pools {
+		if p.DeletedAt == nil && p.ParentID != nil && *p.ParentID == parentID {
+			children = append(children, p)
+		}
+	}
+	return FreeBlocks(parent, children, prefixLen)
+}
@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"cloudpam/internal/domain"
+)
+
+func TestMemoryStore_CIDROperations(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryStore()
+	root, _ := m.CreatePool(ctx, domain.CreatePool{Name: "root", CIDR: "10.0.0.0/16"})
+	a, _ := m.CreatePool(ctx, domain.CreatePool{Name: "a", CIDR: "10.0.0.0/24", ParentID: &root.ID})
+	_, _ = m.CreatePool(ctx, domain.CreatePool{Name: "b", CIDR: "10.0.2.0/23", ParentID: &root.ID})
+	_, _ = m.CreatePool(ctx, domain.CreatePool{Name: "v6", CIDR: "2001:db8::/32"})
+
+	names := func(ps []*domain.Pool) string {
+		var out []string
+		for _, p := range ps {
+			out = append(out, p.Name)
+		}
+		return fmt.Sprint(out)
+	}
+
+	if got, _ := m.FindOverlapping(ctx, "10.0.0.0/22", nil); names(got) != "[root a b]" {
+		t.Errorf("FindOverlapping = %s", names(got))
+	}
+	top := int64(0)
+	if got, _ := m.FindOverlapping(ctx, "10.0.0.0/22", &top); names(got) != "[root]" {
+		t.Errorf("FindOverlapping(top-level) = %s", names(got))
+	}
+	if got, _ := m.FindOverlapping(ctx, "10.0.0.128/25", &root.ID); names(got) != "[a]" {
+		t.Errorf("FindOverlapping(children) = %s", names(got))
+	}
+	if got, _ := m.FindContaining(ctx, "10.0.0.5"); names(got) != "[root a]" {
+		t.Errorf("FindContaining = %s", names(got))
+	}
+	if got, _ := m.FindContainedBy(ctx, "2001:db8::/16"); names(got) != "[v6]" {
+		t.Errorf("FindContainedBy = %s", names(got))
+	}
+	if _, err := m.FindContainedBy(ctx, "10.0.0.0/33"); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected ErrValidation, got %v", err)
+	}
+
+	gaps, err := m.FindGaps(ctx, root.ID, 0)
+	if err != nil {
+		t.Fatalf("FindGaps: %v", err)
+	}
+	if fmt.Sprint(gaps) != "[10.0.1.0/24 10.0.4.0/22 10.0.8.0/21 10.0.16.0/20 10.0.32.0/19 10.0.64.0/18 10.0.128.0/17]" {
+		t.Errorf("FindGaps = %v", gaps)
+	}
+	if _, err := m.FindGaps(ctx, root.ID, 8); !errors.Is(err, ErrValidation) {
+		t.Errorf("prefix shorter than the parent: expected ErrValidation, got %v", err)
+	}
+	if _, err := m.FindGaps(ctx, 999, 0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	// Deletes and rollbacks are reflected without a stale index.
+	if _, err := m.DeletePool(ctx, a.ID); err != nil {
+		t.Fatal(err)
+	}
+	_ = m.WithTx(ctx, func(tx Transaction) error {
+		_, _ = tx.CreatePool(ctx, domain.CreatePool{Name: "tx", CIDR: "10.0.0.0/25", ParentID: &root.ID})
+		if got, _ := m.FindContaining(ctx, "10.0.0.1"); names(got) != "[root tx]" {
+			t.Errorf("FindContaining in tx = %s", names(got))
+		}
+		return errors.New("rollback")
+	})
+	if got, _ := m.FindContaining(ctx, "10.0.0.1"); names(got) != "[root]" {
+		t.Errorf("FindContaining after rollback = %s", names(got))
+	}
+}
@@ -31,6 +31,14 @@ func cloneStringStringMap(in map[string]string) map[string]string {
 	return out
 }
 
+func cloneInt64Ptr(in *int64) *int64 {
+	if in == nil {
+		return nil
+	}
+	v := *in
+	return &v
+}
+
 func clonePool(p domain.Pool) domain.Pool {
 	p.Tags = cloneStringStringMap(p.Tags)
 	return p
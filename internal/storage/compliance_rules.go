@@ -0,0 +1,26 @@
+package storage
+
+import (
+	"context"
+
+	"cloudpam/internal/domain"
+)
+
+// ComplianceRuleStore persists user-defined compliance rules.
+type ComplianceRuleStore interface {
+	// CreateComplianceRule stores a new rule. It returns ErrConflict if the
+	// ID is taken.
+	CreateComplianceRule(ctx context.Context, rule domain.ComplianceRule) error
+
+	// GetComplianceRule returns a rule by ID.
+	GetComplianceRule(ctx context.Context, id string) (*domain.ComplianceRule, error)
+
+	// ListComplianceRules returns all rules ordered by ID.
+	ListComplianceRules(ctx context.Context) ([]domain.ComplianceRule, error)
+
+	// UpdateComplianceRule replaces a rule. CreatedAt is kept.
+	UpdateComplianceRule(ctx context.Context, rule domain.ComplianceRule) error
+
+	// DeleteComplianceRule removes a rule.
+	DeleteComplianceRule(ctx context.Context, id string) error
+}
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"cloudpam/internal/domain"
+)
+
+// MemoryComplianceRuleStore is an in-memory implementation of ComplianceRuleStore.
+type MemoryComplianceRuleStore struct {
+	mu    sync.RWMutex
+	rules map[string]domain.ComplianceRule
+}
+
+// NewMemoryComplianceRuleStore creates a new in-memory compliance rule store.
+func NewMemoryComplianceRuleStore() *MemoryComplianceRuleStore {
+	return &MemoryComplianceRuleStore{rules: make(map[string]domain.ComplianceRule)}
+}
+
+func (s *MemoryComplianceRuleStore) CreateComplianceRule(_ context.Context, rule domain.ComplianceRule) error {
+	if rule.ID == "" {
+		return ErrValidation
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.rules[rule.ID]; exists {
+		return ErrConflict
+	}
+	s.rules[rule.ID] = cloneComplianceRule(rule)
+	return nil
+}
+
+func (s *MemoryComplianceRuleStore) GetComplianceRule(_ context.Context, id string) (*domain.ComplianceRule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rule, ok := s.rules[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	out := cloneComplianceRule(rule)
+	return &out, nil
+}
+
+func (s *MemoryComplianceRuleStore) ListComplianceRules(_ context.Context) ([]domain.ComplianceRule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]domain.ComplianceRule, 0, len(s.rules))
+	for _, rule := range s.rules {
+		out = append(out, cloneComplianceRule(rule))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+func (s *MemoryComplianceRuleStore) UpdateComplianceRule(_ context.Context, rule domain.ComplianceRule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, ok := s.rules[rule.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	rule.CreatedAt = existing.CreatedAt
+	s.rules[rule.ID] = cloneComplianceRule(rule)
+	return nil
+}
+
+func (s *MemoryComplianceRuleStore) DeleteComplianceRule(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.rules[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.rules, id)
+	return nil
+}
+
+func cloneComplianceRule(rule domain.ComplianceRule) domain.ComplianceRule {
+	if rule.Match.PoolTypes != nil {
+		rule.Match.PoolTypes = append([]domain.PoolType(nil), rule.Match.PoolTypes...)
+	}
+	rule.Match.Tags = cloneStringStringMap(rule.Match.Tags)
+	rule.Check.Tags = cloneStringSlice(rule.Check.Tags)
+	rule.Check.Ranges = cloneStringSlice(rule.Check.Ranges)
+	return rule
+}
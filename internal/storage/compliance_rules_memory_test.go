@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cloudpam/internal/domain"
+)
+
+func TestMemoryComplianceRuleStore_CRUD(t *testing.T) {
+	store := NewMemoryComplianceRuleStore()
+	ctx := context.Background()
+	created := time.Now().UTC().Add(-time.Hour)
+
+	rule := domain.ComplianceRule{
+		ID: "TAGS-001", Name: "Ownership tags", Enabled: true, Severity: "error",
+		Match:     domain.ComplianceMatch{Tags: map[string]string{"env": "prod"}},
+		Check:     domain.ComplianceCheck{Type: domain.ComplianceCheckRequiredTags, Tags: []string{"owner", "cost-center"}},
+		CreatedAt: created, UpdatedAt: created,
+	}
+	if err := store.CreateComplianceRule(ctx, rule); err != nil {
+		t.Fatalf("CreateComplianceRule: %v", err)
+	}
+	if err := store.CreateComplianceRule(ctx, rule); !errors.Is(err, ErrConflict) {
+		t.Fatalf("duplicate CreateComplianceRule: expected ErrConflict, got %v", err)
+	}
+	if err := store.CreateComplianceRule(ctx, domain.ComplianceRule{}); !errors.Is(err, ErrValidation) {
+		t.Fatalf("CreateComplianceRule without id: expected ErrValidation, got %v", err)
+	}
+
+	got, err := store.GetComplianceRule(ctx, "TAGS-001")
+	if err != nil {
+		t.Fatalf("GetComplianceRule: %v", err)
+	}
+	// Returned values are copies.
+	got.Check.Tags[0] = "team"
+	got.Match.Tags["env"] = "dev"
+	if again, _ := store.GetComplianceRule(ctx, "TAGS-001"); again.Check.Tags[0] != "owner" || again.Match.Tags["env"] != "prod" {
+		t.Fatal("mutating a returned rule changed the stored copy")
+	}
+
+	if err := store.CreateComplianceRule(ctx, domain.ComplianceRule{ID: "DEPTH-001", Name: "Depth", Severity: "warning"}); err != nil {
+		t.Fatal(err)
+	}
+	list, err := store.ListComplianceRules(ctx)
+	if err != nil || len(list) != 2 || list[0].ID != "DEPTH-001" {
+		t.Fatalf("ListComplianceRules = %+v, %v", list, err)
+	}
+
+	rule.Enforce = true
+	rule.CreatedAt = time.Now().UTC()
+	if err := store.UpdateComplianceRule(ctx, rule); err != nil {
+		t.Fatalf("UpdateComplianceRule: %v", err)
+	}
+	got, _ = store.GetComplianceRule(ctx, "TAGS-001")
+	if !got.Enforce || !got.CreatedAt.Equal(created) {
+		t.Fatalf("after update = %+v", got)
+	}
+	if err := store.UpdateComplianceRule(ctx, domain.ComplianceRule{ID: "missing"}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("UpdateComplianceRule missing: expected ErrNotFound, got %v", err)
+	}
+
+	if err := store.DeleteComplianceRule(ctx, "TAGS-001"); err != nil {
+		t.Fatalf("DeleteComplianceRule: %v", err)
+	}
+	if _, err := store.GetComplianceRule(ctx, "TAGS-001"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetComplianceRule after delete: expected ErrNotFound, got %v", err)
+	}
+	if err := store.DeleteComplianceRule(ctx, "TAGS-001"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("second DeleteComplianceRule: expected ErrNotFound, got %v", err)
+	}
+}
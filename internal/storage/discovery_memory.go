@@ -22,12 +22,14 @@ type MemoryDiscoveryStore struct {
 
 // NewMemoryDiscoveryStore creates a new in-memory discovery store.
 func NewMemoryDiscoveryStore(store *MemoryStore) *MemoryDiscoveryStore {
-	return &MemoryDiscoveryStore{
+	m := &MemoryDiscoveryStore{
 		store:     store,
 		resources: make(map[uuid.UUID]domain.DiscoveredResource),
 		syncJobs:  make(map[uuid.UUID]domain.SyncJob),
 		agents:    make(map[uuid.UUID]domain.DiscoveryAgent),
 	}
+	store.join(m)
+	return m
 }
 
 func (m *MemoryDiscoveryStore) ListDiscoveredResources(_ context.Context, accountID int64, filters domain.DiscoveryFilters) ([]domain.DiscoveredResource, int, error) {
@@ -109,8 +111,8 @@ func (m *MemoryDiscoveryStore) GetDiscoveredResource(_ context.Context, id uuid.
 }
 
 func (m *MemoryDiscoveryStore) UpsertDiscoveredResource(_ context.Context, res domain.DiscoveredResource) error {
-	m.store.mu.Lock()
-	defer m.store.mu.Unlock()
+	m.store.lockWrite()
+	defer m.store.unlockWrite()
 
 	// Check for existing by (account_id, resource_id)
 	for id, existing := range m.resources {
@@ -137,8 +139,8 @@ func (m *MemoryDiscoveryStore) UpsertDiscoveredResource(_ context.Context, res d
 }
 
 func (m *MemoryDiscoveryStore) MarkStaleResources(_ context.Context, accountID int64, before time.Time) (int, error) {
-	m.store.mu.Lock()
-	defer m.store.mu.Unlock()
+	m.store.lockWrite()
+	defer m.store.unlockWrite()
 
 	count := 0
 	for id, r := range m.resources {
@@ -152,8 +154,8 @@ func (m *MemoryDiscoveryStore) MarkStaleResources(_ context.Context, accountID i
 }
 
 func (m *MemoryDiscoveryStore) LinkResourceToPool(_ context.Context, resourceID uuid.UUID, poolID int64) error {
-	m.store.mu.Lock()
-	defer m.store.mu.Unlock()
+	m.store.lockWrite()
+	defer m.store.unlockWrite()
 
 	r, ok := m.resources[resourceID]
 	if !ok {
@@ -165,8 +167,8 @@ func (m *MemoryDiscoveryStore) LinkResourceToPool(_ context.Context, resourceID
 }
 
 func (m *MemoryDiscoveryStore) UnlinkResource(_ context.Context, resourceID uuid.UUID) error {
-	m.store.mu.Lock()
-	defer m.store.mu.Unlock()
+	m.store.lockWrite()
+	defer m.store.unlockWrite()
 
 	r, ok := m.resources[resourceID]
 	if !ok {
@@ -178,8 +180,8 @@ func (m *MemoryDiscoveryStore) UnlinkResource(_ context.Context, resourceID uuid
 }
 
 func (m *MemoryDiscoveryStore) DeleteDiscoveredResource(_ context.Context, id uuid.UUID) error {
-	m.store.mu.Lock()
-	defer m.store.mu.Unlock()
+	m.store.lockWrite()
+	defer m.store.unlockWrite()
 
 	if _, ok := m.resources[id]; !ok {
 		return ErrNotFound
@@ -189,8 +191,8 @@ func (m *MemoryDiscoveryStore) DeleteDiscoveredResource(_ context.Context, id uu
 }
 
 func (m *MemoryDiscoveryStore) CreateSyncJob(_ context.Context, job domain.SyncJob) (domain.SyncJob, error) {
-	m.store.mu.Lock()
-	defer m.store.mu.Unlock()
+	m.store.lockWrite()
+	defer m.store.unlockWrite()
 
 	if job.ID == uuid.Nil {
 		job.ID = uuid.New()
@@ -203,8 +205,8 @@ func (m *MemoryDiscoveryStore) CreateSyncJob(_ context.Context, job domain.SyncJ
 }
 
 func (m *MemoryDiscoveryStore) UpdateSyncJob(_ context.Context, job domain.SyncJob) error {
-	m.store.mu.Lock()
-	defer m.store.mu.Unlock()
+	m.store.lockWrite()
+	defer m.store.unlockWrite()
 
 	if _, ok := m.syncJobs[job.ID]; !ok {
 		return ErrNotFound
@@ -246,8 +248,8 @@ func (m *MemoryDiscoveryStore) ListSyncJobs(_ context.Context, accountID int64,
 }
 
 func (m *MemoryDiscoveryStore) ClaimPendingAgentSync(_ context.Context, agentID uuid.UUID) (*domain.SyncJob, error) {
-	m.store.mu.Lock()
-	defer m.store.mu.Unlock()
+	m.store.lockWrite()
+	defer m.store.unlockWrite()
 
 	var selected *domain.SyncJob
 	for _, j := range m.syncJobs {
@@ -270,8 +272,8 @@ func (m *MemoryDiscoveryStore) ClaimPendingAgentSync(_ context.Context, agentID
 }
 
 func (m *MemoryDiscoveryStore) UpsertAgent(_ context.Context, agent domain.DiscoveryAgent) error {
-	m.store.mu.Lock()
-	defer m.store.mu.Unlock()
+	m.store.lockWrite()
+	defer m.store.unlockWrite()
 
 	if agent.ID == uuid.Nil {
 		agent.ID = uuid.New()
@@ -300,8 +302,8 @@ func (m *MemoryDiscoveryStore) GetAgent(_ context.Context, id uuid.UUID) (*domai
 }
 
 func (m *MemoryDiscoveryStore) DeleteAgent(_ context.Context, id uuid.UUID) error {
-	m.store.mu.Lock()
-	defer m.store.mu.Unlock()
+	m.store.lockWrite()
+	defer m.store.unlockWrite()
 
 	if _, ok := m.agents[id]; !ok {
 		return ErrNotFound
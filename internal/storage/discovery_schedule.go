@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"cloudpam/internal/domain"
+)
+
+// DiscoveryScheduleStore persists per-account discovery schedules.
+type DiscoveryScheduleStore interface {
+	// GetDiscoverySchedule returns the schedule for an account.
+	GetDiscoverySchedule(ctx context.Context, accountID int64) (*domain.DiscoverySchedule, error)
+
+	// ListDiscoverySchedules returns all schedules ordered by account ID.
+	ListDiscoverySchedules(ctx context.Context) ([]domain.DiscoverySchedule, error)
+
+	// UpsertDiscoverySchedule creates or replaces a schedule's configuration
+	// and next run time. The last-run fields are left untouched. SQL stores
+	// return ErrNotFound if the account does not exist.
+	UpsertDiscoverySchedule(ctx context.Context, s domain.DiscoverySchedule) error
+
+	// DeleteDiscoverySchedule removes an account's schedule.
+	DeleteDiscoverySchedule(ctx context.Context, accountID int64) error
+
+	// ListDueDiscoverySchedules returns enabled schedules whose next run is at
+	// or before now, oldest first.
+	ListDueDiscoverySchedules(ctx context.Context, now time.Time) ([]domain.DiscoverySchedule, error)
+
+	// ClaimDiscoverySchedule advances next_run_at from due to next only if it
+	// still equals due, so that two server replicas never fire the same run.
+	// It reports whether the claim succeeded.
+	ClaimDiscoverySchedule(ctx context.Context, accountID int64, due, next time.Time) (bool, error)
+
+	// RecordDiscoveryScheduleRun stores the outcome of a run in the last-run fields.
+	RecordDiscoveryScheduleRun(ctx context.Context, accountID int64, run domain.DiscoveryScheduleRun) error
+}
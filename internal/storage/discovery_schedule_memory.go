@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"cloudpam/internal/domain"
+)
+
+// MemoryDiscoveryScheduleStore is an in-memory implementation of DiscoveryScheduleStore.
+type MemoryDiscoveryScheduleStore struct {
+	mu        sync.RWMutex
+	schedules map[int64]domain.DiscoverySchedule
+}
+
+// NewMemoryDiscoveryScheduleStore creates a new in-memory schedule store.
+func NewMemoryDiscoveryScheduleStore() *MemoryDiscoveryScheduleStore {
+	return &MemoryDiscoveryScheduleStore{schedules: make(map[int64]domain.DiscoverySchedule)}
+}
+
+func (s *MemoryDiscoveryScheduleStore) GetDiscoverySchedule(_ context.Context, accountID int64) (*domain.DiscoverySchedule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	sc, ok := s.schedules[accountID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	out := cloneDiscoverySchedule(sc)
+	return &out, nil
+}
+
+func (s *MemoryDiscoveryScheduleStore) ListDiscoverySchedules(_ context.Context) ([]domain.DiscoverySchedule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]domain.DiscoverySchedule, 0, len(s.schedules))
+	for _, sc := range s.schedules {
+		out = append(out, cloneDiscoverySchedule(sc))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].AccountID < out[j].AccountID })
+	return out, nil
+}
+
+func (s *MemoryDiscoveryScheduleStore) UpsertDiscoverySchedule(_ context.Context, sc domain.DiscoverySchedule) error {
+	if sc.AccountID == 0 {
+		return ErrValidation
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.schedules[sc.AccountID]; ok {
+		sc.CreatedAt = existing.CreatedAt
+		sc.LastRunAt = existing.LastRunAt
+		sc.LastStatus = existing.LastStatus
+		sc.LastError = existing.LastError
+		sc.LastSyncJobID = existing.LastSyncJobID
+	} else {
+		sc.LastRunAt, sc.LastStatus, sc.LastError, sc.LastSyncJobID = nil, "", "", nil
+	}
+	s.schedules[sc.AccountID] = cloneDiscoverySchedule(sc)
+	return nil
+}
+
+func (s *MemoryDiscoveryScheduleStore) DeleteDiscoverySchedule(_ context.Context, accountID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.schedules[accountID]; !ok {
+		return ErrNotFound
+	}
+	delete(s.schedules, accountID)
+	return nil
+}
+
+func (s *MemoryDiscoveryScheduleStore) ListDueDiscoverySchedules(_ context.Context, now time.Time) ([]domain.DiscoverySchedule, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var due []domain.DiscoverySchedule
+	for _, sc := range s.schedules {
+		if !sc.Enabled || sc.NextRunAt == nil || sc.NextRunAt.After(now) {
+			continue
+		}
+		due = append(due, cloneDiscoverySchedule(sc))
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].NextRunAt.Equal(*due[j].NextRunAt) {
+			return due[i].NextRunAt.Before(*due[j].NextRunAt)
+		}
+		return due[i].AccountID < due[j].AccountID
+	})
+	return due, nil
+}
+
+func (s *MemoryDiscoveryScheduleStore) ClaimDiscoverySchedule(_ context.Context, accountID int64, due, next time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sc, ok := s.schedules[accountID]
+	if !ok {
+		return false, ErrNotFound
+	}
+	if !sc.Enabled || sc.NextRunAt == nil || !sc.NextRunAt.Equal(due) {
+		return false, nil
+	}
+	sc.NextRunAt = &next
+	s.schedules[accountID] = sc
+	return true, nil
+}
+
+func (s *MemoryDiscoveryScheduleStore) RecordDiscoveryScheduleRun(_ context.Context, accountID int64, run domain.DiscoveryScheduleRun) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sc, ok := s.schedules[accountID]
+	if !ok {
+		return ErrNotFound
+	}
+	at := run.At
+	sc.LastRunAt = &at
+	sc.LastStatus = run.Status
+	sc.LastError = run.Error
+	sc.LastSyncJobID = run.SyncJobID
+	s.schedules[accountID] = cloneDiscoverySchedule(sc)
+	return nil
+}
+
+func cloneDiscoverySchedule(sc domain.DiscoverySchedule) domain.DiscoverySchedule {
+	if sc.NextRunAt != nil {
+		t := *sc.NextRunAt
+		sc.NextRunAt = &t
+	}
+	if sc.LastRunAt != nil {
+		t := *sc.LastRunAt
+		sc.LastRunAt = &t
+	}
+	if sc.LastSyncJobID != nil {
+		id := *sc.LastSyncJobID
+		sc.LastSyncJobID = &id
+	}
+	return sc
+}
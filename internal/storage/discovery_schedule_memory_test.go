@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"cloudpam/internal/domain"
+)
+
+func TestDiscoveryScheduleMemoryStore_UpsertKeepsLastRun(t *testing.T) {
+	store := NewMemoryDiscoveryScheduleStore()
+	ctx := context.Background()
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	next := now.Add(time.Hour)
+
+	sc := domain.DiscoverySchedule{AccountID: 1, Enabled: true, Interval: "1h", DetectDrift: true, NextRunAt: &next, CreatedAt: now, UpdatedAt: now}
+	if err := store.UpsertDiscoverySchedule(ctx, sc); err != nil {
+		t.Fatalf("UpsertDiscoverySchedule: %v", err)
+	}
+
+	jobID := uuid.New()
+	if err := store.RecordDiscoveryScheduleRun(ctx, 1, domain.DiscoveryScheduleRun{
+		At: now, Status: domain.ScheduleRunCompleted, SyncJobID: &jobID,
+	}); err != nil {
+		t.Fatalf("RecordDiscoveryScheduleRun: %v", err)
+	}
+
+	sc.Interval = "2h"
+	sc.CreatedAt = now.Add(time.Minute)
+	if err := store.UpsertDiscoverySchedule(ctx, sc); err != nil {
+		t.Fatalf("second UpsertDiscoverySchedule: %v", err)
+	}
+	got, err := store.GetDiscoverySchedule(ctx, 1)
+	if err != nil {
+		t.Fatalf("GetDiscoverySchedule: %v", err)
+	}
+	if got.Interval != "2h" || !got.CreatedAt.Equal(now) {
+		t.Fatalf("config not replaced or created_at changed: %+v", got)
+	}
+	if got.LastStatus != domain.ScheduleRunCompleted || got.LastSyncJobID == nil || *got.LastSyncJobID != jobID {
+		t.Fatalf("last run lost on upsert: %+v", got)
+	}
+
+	if err := store.DeleteDiscoverySchedule(ctx, 1); err != nil {
+		t.Fatalf("DeleteDiscoverySchedule: %v", err)
+	}
+	if _, err := store.GetDiscoverySchedule(ctx, 1); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after delete, got %v", err)
+	}
+}
+
+func TestDiscoveryScheduleMemoryStore_DueAndClaim(t *testing.T) {
+	store := NewMemoryDiscoveryScheduleStore()
+	ctx := context.Background()
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Minute), now.Add(time.Minute)
+
+	for _, sc := range []domain.DiscoverySchedule{
+		{AccountID: 1, Enabled: true, Interval: "1h", NextRunAt: &past},
+		{AccountID: 2, Enabled: true, Interval: "1h", NextRunAt: &future},
+		{AccountID: 3, Enabled: false, Interval: "1h", NextRunAt: &past},
+	} {
+		if err := store.UpsertDiscoverySchedule(ctx, sc); err != nil {
+			t.Fatalf("UpsertDiscoverySchedule(%d): %v", sc.AccountID, err)
+		}
+	}
+
+	due, err := store.ListDueDiscoverySchedules(ctx, now)
+	if err != nil {
+		t.Fatalf("ListDueDiscoverySchedules: %v", err)
+	}
+	if len(due) != 1 || due[0].AccountID != 1 {
+		t.Fatalf("due = %+v, want only account 1", due)
+	}
+
+	ok, err := store.ClaimDiscoverySchedule(ctx, 1, past, now.Add(time.Hour))
+	if err != nil || !ok {
+		t.Fatalf("first claim = %v, %v; want true", ok, err)
+	}
+	// A second replica holding the stale due time loses the race.
+	ok, err = store.ClaimDiscoverySchedule(ctx, 1, past, now.Add(time.Hour))
+	if err != nil || ok {
+		t.Fatalf("second claim = %v, %v; want false", ok, err)
+	}
+	if due, _ := store.ListDueDiscoverySchedules(ctx, now); len(due) != 0 {
+		t.Fatalf("claimed schedule still due: %+v", due)
+	}
+}
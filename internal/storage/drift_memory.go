@@ -16,15 +16,17 @@ type MemoryDriftStore struct {
 
 // NewMemoryDriftStore creates a new in-memory drift store.
 func NewMemoryDriftStore(store *MemoryStore) *MemoryDriftStore {
-	return &MemoryDriftStore{
+	m := &MemoryDriftStore{
 		store:  store,
 		drifts: make(map[string]domain.DriftItem),
 	}
+	store.join(m)
+	return m
 }
 
 func (m *MemoryDriftStore) CreateDriftItem(_ context.Context, item domain.DriftItem) error {
-	m.store.mu.Lock()
-	defer m.store.mu.Unlock()
+	m.store.lockWrite()
+	defer m.store.unlockWrite()
 	m.drifts[item.ID] = cloneDriftItem(item)
 	return nil
 }
@@ -89,8 +91,8 @@ func (m *MemoryDriftStore) ListDriftItems(_ context.Context, filters domain.Drif
 }
 
 func (m *MemoryDriftStore) UpdateDriftStatus(_ context.Context, id string, status domain.DriftStatus, ignoreReason string) error {
-	m.store.mu.Lock()
-	defer m.store.mu.Unlock()
+	m.store.lockWrite()
+	defer m.store.unlockWrite()
 
 	d, ok := m.drifts[id]
 	if !ok {
@@ -108,8 +110,8 @@ func (m *MemoryDriftStore) UpdateDriftStatus(_ context.Context, id string, statu
 }
 
 func (m *MemoryDriftStore) UpdateDriftDetails(_ context.Context, id string, details map[string]string) error {
-	m.store.mu.Lock()
-	defer m.store.mu.Unlock()
+	m.store.lockWrite()
+	defer m.store.unlockWrite()
 
 	d, ok := m.drifts[id]
 	if !ok {
@@ -127,8 +129,8 @@ func (m *MemoryDriftStore) UpdateDriftDetails(_ context.Context, id string, deta
 }
 
 func (m *MemoryDriftStore) DeleteOpenForAccount(_ context.Context, accountID int64) error {
-	m.store.mu.Lock()
-	defer m.store.mu.Unlock()
+	m.store.lockWrite()
+	defer m.store.unlockWrite()
 
 	for id, d := range m.drifts {
 		if d.AccountID == accountID && d.Status == domain.DriftStatusOpen {
@@ -20,6 +20,10 @@ var (
 	// (e.g., missing required fields).
 	ErrValidation = errors.New("validation error")
 
+	// ErrPreconditionFailed indicates a conditional write found the row at a
+	// different version than the caller expected.
+	ErrPreconditionFailed = errors.New("precondition failed")
+
 	// ErrDuplicateIssuer indicates that an OIDC provider with the same issuer URL already exists.
 	ErrDuplicateIssuer = errors.New("duplicate issuer URL")
 )
@@ -9,7 +9,7 @@ import (
 )
 
 // TransactionalStore extends Store with transaction support.
-// This interface is intended for PostgreSQL and other transactional databases.
+// The SQLite, PostgreSQL and memory stores all implement it.
 type TransactionalStore interface {
 	Store
 
@@ -39,8 +39,17 @@ type Transaction interface {
 	Rollback() error
 }
 
+// TxBinder is implemented by transactions whose companion stores are separate
+// values, like the in-memory store's. Bind returns store's view inside the
+// transaction, or nil if store does not take part in it.
+type TxBinder interface {
+	Bind(store any) any
+}
+
 // Queryable defines common query patterns with flexible filtering.
 // This interface provides more advanced query capabilities than the basic Store.
+// The SQLite, PostgreSQL and memory stores implement it; results never include
+// soft-deleted rows, and invalid options yield ErrValidation.
 type Queryable interface {
 	// QueryPools returns pools matching the given criteria.
 	QueryPools(ctx context.Context, opts PoolQueryOptions) ([]*domain.Pool, error)
@@ -72,27 +81,29 @@ type PoolQueryOptions struct {
 	// Useful for finding pools in a specific IP range.
 	CIDRPrefix string
 
-	// CIDRContains filters pools whose CIDR contains this address/prefix.
-	// PostgreSQL-specific: uses the >> (contains) operator.
+	// CIDRContains filters pools whose CIDR contains this address/prefix,
+	// including an exact match (PostgreSQL's >>= operator).
 	CIDRContains string
 
-	// CIDRWithin filters pools whose CIDR is contained within this prefix.
-	// PostgreSQL-specific: uses the << (is contained by) operator.
+	// CIDRWithin filters pools whose CIDR is contained within this prefix,
+	// including an exact match (PostgreSQL's <<= operator).
 	CIDRWithin string
 
 	// NameContains filters pools whose name contains this substring (case-insensitive).
 	NameContains string
 
-	// CreatedAfter filters pools created after this time.
+	// CreatedAfter filters pools created at or after this time.
 	CreatedAfter *int64 // Unix timestamp
 
 	// CreatedBefore filters pools created before this time.
 	CreatedBefore *int64 // Unix timestamp
 
-	// IncludeChildren when true, includes all descendant pools.
+	// IncludeChildren when true, widens a non-root ParentID filter from the
+	// direct children to all descendant pools.
 	IncludeChildren bool
 
-	// OrderBy specifies the sort field: "id", "name", "cidr", "created_at"
+	// OrderBy specifies the sort field: "id" (the default), "name", "cidr", "created_at".
+	// Ties are broken by id.
 	OrderBy string
 
 	// OrderDesc when true, sorts in descending order.
@@ -134,13 +145,14 @@ type AccountQueryOptions struct {
 	// NameContains filters accounts whose name contains this substring (case-insensitive).
 	NameContains string
 
-	// CreatedAfter filters accounts created after this time.
+	// CreatedAfter filters accounts created at or after this time.
 	CreatedAfter *int64 // Unix timestamp
 
 	// CreatedBefore filters accounts created before this time.
 	CreatedBefore *int64 // Unix timestamp
 
-	// OrderBy specifies the sort field: "id", "key", "name", "created_at"
+	// OrderBy specifies the sort field: "id" (the default), "key", "name", "created_at".
+	// Ties are broken by id.
 	OrderBy string
 
 	// OrderDesc when true, sorts in descending order.
@@ -154,9 +166,14 @@ type AccountQueryOptions struct {
 }
 
 // CIDROperations defines CIDR-specific query operations.
-// These are primarily used with PostgreSQL's native CIDR type.
+// PostgreSQL answers them with its native cidr operators and GiST index; the
+// SQLite and memory stores use an in-process interval tree (cidr.Index).
+// Only live (not soft-deleted) pools are returned, ordered by address and
+// then prefix length. A malformed cidr argument yields ErrValidation.
 type CIDROperations interface {
 	// FindOverlapping returns pools whose CIDR overlaps with the given prefix.
+	// A nil parentID searches every pool; a pointer to 0 limits the search to
+	// top-level pools, and any other value to the children of that pool.
 	FindOverlapping(ctx context.Context, cidr string, parentID *int64) ([]*domain.Pool, error)
 
 	// FindContaining returns pools whose CIDR contains the given address or prefix.
@@ -165,7 +182,10 @@ type CIDROperations interface {
 	// FindContainedBy returns pools whose CIDR is contained within the given prefix.
 	FindContainedBy(ctx context.Context, cidr string) ([]*domain.Pool, error)
 
-	// FindGaps returns unallocated CIDR ranges within a parent pool.
+	// FindGaps returns unallocated CIDR ranges within a parent pool as
+	// maximal aligned blocks. A prefixLen of 0 returns every gap; otherwise
+	// only blocks large enough to hold a /prefixLen are returned. It returns
+	// ErrNotFound if the parent does not exist.
 	FindGaps(ctx context.Context, parentID int64, prefixLen int) ([]string, error)
 }
 
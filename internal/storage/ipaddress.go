@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"context"
+
+	"cloudpam/internal/domain"
+)
+
+// IPAddressPickFunc chooses the address record to create given a subnet pool,
+// its live child pools and the addresses already recorded in it. It runs
+// while the store holds the pool's allocation lock, so it must not call back
+// into the store.
+type IPAddressPickFunc func(pool domain.Pool, children []domain.Pool, existing []domain.IPAddress) (domain.IPAddress, error)
+
+// IPAddressStore persists individual host addresses recorded inside pools.
+// An address can be recorded at most once per pool.
+type IPAddressStore interface {
+	// CreateIPAddress stores a new record. It returns ErrNotFound if the pool
+	// does not exist and ErrConflict if the address is already recorded in it.
+	CreateIPAddress(ctx context.Context, a domain.IPAddress) error
+
+	// GetIPAddress returns a record by ID.
+	GetIPAddress(ctx context.Context, id string) (*domain.IPAddress, error)
+
+	// ListIPAddresses returns paginated records ordered by pool, then by
+	// address. Records of deleted pools are not listed.
+	ListIPAddresses(ctx context.Context, filters domain.IPAddressFilters) ([]domain.IPAddress, int, error)
+
+	// UpdateIPAddress replaces a record's mutable fields. The pool and
+	// address cannot change.
+	UpdateIPAddress(ctx context.Context, a domain.IPAddress) error
+
+	// DeleteIPAddress removes a record.
+	DeleteIPAddress(ctx context.Context, id string) error
+
+	// AllocateIPAddress loads the pool, its children and its records, asks
+	// pick for the record to create and inserts it. Concurrent allocations
+	// in the same pool are serialized, so two callers never receive the same
+	// address. It returns ErrNotFound if the pool does not exist. Errors from
+	// pick are returned unchanged and nothing is written.
+	AllocateIPAddress(ctx context.Context, poolID int64, pick IPAddressPickFunc) (domain.IPAddress, error)
+}
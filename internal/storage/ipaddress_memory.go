@@ -0,0 +1,217 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"cloudpam/internal/domain"
+)
+
+// MemoryIPAddressStore is an in-memory implementation of IPAddressStore.
+// It shares the MemoryStore's lock so pool stats can count its records.
+type MemoryIPAddressStore struct {
+	store     *MemoryStore
+	addresses map[string]domain.IPAddress
+}
+
+// NewMemoryIPAddressStore creates a new in-memory IP address store attached
+// to store.
+func NewMemoryIPAddressStore(store *MemoryStore) *MemoryIPAddressStore {
+	m := &MemoryIPAddressStore{
+		store:     store,
+		addresses: make(map[string]domain.IPAddress),
+	}
+	store.join(m)
+	store.mu.Lock()
+	store.ipAddresses = m
+	store.mu.Unlock()
+	return m
+}
+
+func (m *MemoryIPAddressStore) CreateIPAddress(_ context.Context, a domain.IPAddress) error {
+	if a.ID == "" {
+		return ErrValidation
+	}
+	m.store.lockWrite()
+	defer m.store.unlockWrite()
+	return m.createLocked(a)
+}
+
+func (m *MemoryIPAddressStore) createLocked(a domain.IPAddress) error {
+	p, ok := m.store.pools[a.PoolID]
+	if !ok || p.DeletedAt != nil {
+		return fmt.Errorf("pool %d: %w", a.PoolID, ErrNotFound)
+	}
+	if _, exists := m.addresses[a.ID]; exists {
+		return ErrConflict
+	}
+	for _, existing := range m.addresses {
+		if existing.PoolID == a.PoolID && existing.Address == a.Address {
+			return fmt.Errorf("address %s already recorded in pool %d: %w", a.Address, a.PoolID, ErrConflict)
+		}
+	}
+	m.addresses[a.ID] = cloneIPAddress(a)
+	return nil
+}
+
+func (m *MemoryIPAddressStore) GetIPAddress(_ context.Context, id string) (*domain.IPAddress, error) {
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+	a, ok := m.addresses[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	out := cloneIPAddress(a)
+	return &out, nil
+}
+
+func (m *MemoryIPAddressStore) ListIPAddresses(_ context.Context, filters domain.IPAddressFilters) ([]domain.IPAddress, int, error) {
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+
+	q := strings.ToLower(filters.Query)
+	var filtered []domain.IPAddress
+	for _, a := range m.addresses {
+		if filters.PoolID != 0 && a.PoolID != filters.PoolID {
+			continue
+		}
+		// Records stay with soft-deleted pools but are no longer listed.
+		if p, ok := m.store.pools[a.PoolID]; !ok || p.DeletedAt != nil {
+			continue
+		}
+		if filters.Status != "" && string(a.Status) != filters.Status {
+			continue
+		}
+		if filters.Source != "" && string(a.Source) != filters.Source {
+			continue
+		}
+		if q != "" &&
+			!strings.Contains(strings.ToLower(a.Address), q) &&
+			!strings.Contains(strings.ToLower(a.Hostname), q) &&
+			!strings.Contains(strings.ToLower(a.MACAddress), q) &&
+			!strings.Contains(strings.ToLower(a.Owner), q) {
+			continue
+		}
+		filtered = append(filtered, a)
+	}
+	sortIPAddresses(filtered)
+
+	total := len(filtered)
+	page := filters.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := filters.PageSize
+	if pageSize < 1 {
+		pageSize = 50
+	}
+	start := (page - 1) * pageSize
+	if start > total {
+		return []domain.IPAddress{}, total, nil
+	}
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+	out := make([]domain.IPAddress, 0, end-start)
+	for _, a := range filtered[start:end] {
+		out = append(out, cloneIPAddress(a))
+	}
+	return out, total, nil
+}
+
+func (m *MemoryIPAddressStore) UpdateIPAddress(_ context.Context, a domain.IPAddress) error {
+	m.store.lockWrite()
+	defer m.store.unlockWrite()
+	existing, ok := m.addresses[a.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	a.PoolID = existing.PoolID
+	a.Address = existing.Address
+	a.CreatedAt = existing.CreatedAt
+	m.addresses[a.ID] = cloneIPAddress(a)
+	return nil
+}
+
+func (m *MemoryIPAddressStore) DeleteIPAddress(_ context.Context, id string) error {
+	m.store.lockWrite()
+	defer m.store.unlockWrite()
+	if _, ok := m.addresses[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.addresses, id)
+	return nil
+}
+
+// AllocateIPAddress picks and inserts a record under the store's write lock.
+func (m *MemoryIPAddressStore) AllocateIPAddress(_ context.Context, poolID int64, pick IPAddressPickFunc) (domain.IPAddress, error) {
+	m.store.lockWrite()
+	defer m.store.unlockWrite()
+
+	pool, ok := m.store.pools[poolID]
+	if !ok || pool.DeletedAt != nil {
+		return domain.IPAddress{}, fmt.Errorf("pool not found: %w", ErrNotFound)
+	}
+	var children []domain.Pool
+	for _, p := range m.store.pools {
+		if p.DeletedAt == nil && p.ParentID != nil && *p.ParentID == poolID {
+			children = append(children, clonePool(p))
+		}
+	}
+	var existing []domain.IPAddress
+	for _, a := range m.addresses {
+		if a.PoolID == poolID {
+			existing = append(existing, cloneIPAddress(a))
+		}
+	}
+	sortIPAddresses(existing)
+
+	a, err := pick(clonePool(pool), children, existing)
+	if err != nil {
+		return domain.IPAddress{}, err
+	}
+	a.PoolID = poolID
+	if err := m.createLocked(a); err != nil {
+		return domain.IPAddress{}, err
+	}
+	return cloneIPAddress(a), nil
+}
+
+// poolAddressesLocked returns the addresses recorded in a pool. The caller
+// must hold the shared lock.
+func (m *MemoryIPAddressStore) poolAddressesLocked(poolID int64) []string {
+	var out []string
+	for _, a := range m.addresses {
+		if a.PoolID == poolID {
+			out = append(out, a.Address)
+		}
+	}
+	return out
+}
+
+// sortIPAddresses orders records by pool, then numerically by address.
+func sortIPAddresses(items []domain.IPAddress) {
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].PoolID != items[j].PoolID {
+			return items[i].PoolID < items[j].PoolID
+		}
+		ai, errI := netip.ParseAddr(items[i].Address)
+		aj, errJ := netip.ParseAddr(items[j].Address)
+		if errI != nil || errJ != nil {
+			return items[i].Address < items[j].Address
+		}
+		return ai.Less(aj)
+	})
+}
+
+func cloneIPAddress(a domain.IPAddress) domain.IPAddress {
+	if a.ResourceID != nil {
+		id := *a.ResourceID
+		a.ResourceID = &id
+	}
+	return a
+}
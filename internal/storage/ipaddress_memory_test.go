@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cloudpam/internal/domain"
+)
+
+func newTestIPAddress(id string, poolID int64, addr string, now time.Time) domain.IPAddress {
+	return domain.IPAddress{
+		ID: id, PoolID: poolID, Address: addr, Status: domain.IPAddressStatusAssigned,
+		Source: domain.PoolSourceManual, CreatedAt: now, UpdatedAt: now,
+	}
+}
+
+func TestIPAddressMemoryStore_CRUD(t *testing.T) {
+	ms := NewMemoryStore()
+	store := NewMemoryIPAddressStore(ms)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	pool, err := ms.CreatePool(ctx, domain.CreatePool{Name: "apps", CIDR: "10.0.0.0/24", Type: domain.PoolTypeSubnet})
+	if err != nil {
+		t.Fatalf("CreatePool: %v", err)
+	}
+
+	if err := store.CreateIPAddress(ctx, newTestIPAddress("a1", pool.ID, "10.0.0.10", now)); err != nil {
+		t.Fatalf("CreateIPAddress: %v", err)
+	}
+	if err := store.CreateIPAddress(ctx, newTestIPAddress("a2", pool.ID, "10.0.0.10", now)); !errors.Is(err, ErrConflict) {
+		t.Fatalf("duplicate address: got %v, want ErrConflict", err)
+	}
+	if err := store.CreateIPAddress(ctx, newTestIPAddress("a3", 999, "10.0.0.11", now)); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("missing pool: got %v, want ErrNotFound", err)
+	}
+	if err := store.CreateIPAddress(ctx, newTestIPAddress("a4", pool.ID, "10.0.0.9", now)); err != nil {
+		t.Fatalf("CreateIPAddress: %v", err)
+	}
+
+	items, total, err := store.ListIPAddresses(ctx, domain.IPAddressFilters{PoolID: pool.ID})
+	if err != nil || total != 2 || items[0].Address != "10.0.0.9" || items[1].Address != "10.0.0.10" {
+		t.Fatalf("ListIPAddresses = %+v, %d, %v", items, total, err)
+	}
+
+	got, err := store.GetIPAddress(ctx, "a1")
+	if err != nil {
+		t.Fatalf("GetIPAddress: %v", err)
+	}
+	got.Hostname = "web-1"
+	got.Address = "10.0.0.99" // immutable
+	if err := store.UpdateIPAddress(ctx, *got); err != nil {
+		t.Fatalf("UpdateIPAddress: %v", err)
+	}
+	items, _, err = store.ListIPAddresses(ctx, domain.IPAddressFilters{Query: "WEB"})
+	if err != nil || len(items) != 1 || items[0].Address != "10.0.0.10" || items[0].Hostname != "web-1" {
+		t.Fatalf("query by hostname = %+v, %v", items, err)
+	}
+
+	if err := store.DeleteIPAddress(ctx, "a1"); err != nil {
+		t.Fatalf("DeleteIPAddress: %v", err)
+	}
+	if _, err := store.GetIPAddress(ctx, "a1"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetIPAddress after delete: got %v, want ErrNotFound", err)
+	}
+}
+
+func TestIPAddressMemoryStore_CountsTowardsPoolStats(t *testing.T) {
+	ms := NewMemoryStore()
+	store := NewMemoryIPAddressStore(ms)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	pool, err := ms.CreatePool(ctx, domain.CreatePool{Name: "apps", CIDR: "10.0.0.0/24", Type: domain.PoolTypeSubnet})
+	if err != nil {
+		t.Fatalf("CreatePool: %v", err)
+	}
+	if _, err := ms.CreatePool(ctx, domain.CreatePool{Name: "lb", CIDR: "10.0.0.128/28", ParentID: &pool.ID}); err != nil {
+		t.Fatalf("CreatePool child: %v", err)
+	}
+	for i, addr := range []string{"10.0.0.10", "10.0.0.11", "10.0.0.130"} {
+		if err := store.CreateIPAddress(ctx, newTestIPAddress(string(rune('a'+i)), pool.ID, addr, now)); err != nil {
+			t.Fatalf("CreateIPAddress %s: %v", addr, err)
+		}
+	}
+
+	stats, err := ms.CalculatePoolUtilization(ctx, pool.ID)
+	if err != nil {
+		t.Fatalf("CalculatePoolUtilization: %v", err)
+	}
+	// 16 from the child plus two hosts; 10.0.0.130 is already inside the child.
+	if stats.UsedIPs != 18 {
+		t.Fatalf("UsedIPs = %d, want 18", stats.UsedIPs)
+	}
+}
+
+func TestIPAddressMemoryStore_Allocate(t *testing.T) {
+	ms := NewMemoryStore()
+	store := NewMemoryIPAddressStore(ms)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	pool, err := ms.CreatePool(ctx, domain.CreatePool{Name: "apps", CIDR: "10.0.0.0/30", Type: domain.PoolTypeSubnet})
+	if err != nil {
+		t.Fatalf("CreatePool: %v", err)
+	}
+	if err := store.CreateIPAddress(ctx, newTestIPAddress("a1", pool.ID, "10.0.0.1", now)); err != nil {
+		t.Fatalf("CreateIPAddress: %v", err)
+	}
+
+	var seen []domain.IPAddress
+	got, err := store.AllocateIPAddress(ctx, pool.ID, func(p domain.Pool, children []domain.Pool, existing []domain.IPAddress) (domain.IPAddress, error) {
+		seen = existing
+		return newTestIPAddress("a2", 0, "10.0.0.2", now), nil
+	})
+	if err != nil {
+		t.Fatalf("AllocateIPAddress: %v", err)
+	}
+	if got.PoolID != pool.ID || len(seen) != 1 || seen[0].Address != "10.0.0.1" {
+		t.Fatalf("AllocateIPAddress = %+v, existing %+v", got, seen)
+	}
+
+	errFull := errors.New("full")
+	if _, err := store.AllocateIPAddress(ctx, pool.ID, func(domain.Pool, []domain.Pool, []domain.IPAddress) (domain.IPAddress, error) {
+		return domain.IPAddress{}, errFull
+	}); !errors.Is(err, errFull) {
+		t.Fatalf("pick error: got %v, want %v", err, errFull)
+	}
+	if _, err := store.AllocateIPAddress(ctx, 999, nil); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("missing pool: got %v, want ErrNotFound", err)
+	}
+}
@@ -17,19 +17,23 @@ type MemoryNetworkStore struct {
 	store         *MemoryStore
 	objects       map[int64]domain.NetworkObject
 	relationships map[string]domain.NetworkRelationship
-	nextObjectID  int64
+	// nextObjectID is shared with transaction views of the store.
+	nextObjectID *int64
 }
 
 func NewMemoryNetworkStore(store *MemoryStore) *MemoryNetworkStore {
 	if store == nil {
 		store = NewMemoryStore()
 	}
-	return &MemoryNetworkStore{
+	next := int64(1)
+	m := &MemoryNetworkStore{
 		store:         store,
 		objects:       make(map[int64]domain.NetworkObject),
 		relationships: make(map[string]domain.NetworkRelationship),
-		nextObjectID:  1,
+		nextObjectID:  &next,
 	}
+	store.join(m)
+	return m
 }
 
 func (m *MemoryNetworkStore) ListNetworkObjects(_ context.Context, filters domain.NetworkObjectFilters) ([]domain.NetworkObject, error) {
@@ -103,12 +107,12 @@ func (m *MemoryNetworkStore) CreateNetworkObject(_ context.Context, in domain.Cr
 		state = domain.NetworkObjectStateManaged
 	}
 
-	m.store.mu.Lock()
-	defer m.store.mu.Unlock()
+	m.store.lockWrite()
+	defer m.store.unlockWrite()
 
 	now := time.Now().UTC()
 	obj := domain.NetworkObject{
-		ID:                 m.nextObjectID,
+		ID:                 *m.nextObjectID,
 		ObjectType:         objectType,
 		Provider:           in.Provider,
 		AccountID:          in.AccountID,
@@ -125,14 +129,14 @@ func (m *MemoryNetworkStore) CreateNetworkObject(_ context.Context, in domain.Cr
 		CreatedAt:          now,
 		UpdatedAt:          now,
 	}
-	m.nextObjectID++
+	*m.nextObjectID++
 	m.objects[obj.ID] = obj
 	return cloneNetworkObject(obj), nil
 }
 
 func (m *MemoryNetworkStore) UpdateNetworkObject(_ context.Context, id int64, update domain.UpdateNetworkObject) (domain.NetworkObject, bool, error) {
-	m.store.mu.Lock()
-	defer m.store.mu.Unlock()
+	m.store.lockWrite()
+	defer m.store.unlockWrite()
 
 	obj, ok := m.objects[id]
 	if !ok {
@@ -243,8 +247,8 @@ func (m *MemoryNetworkStore) UpsertNetworkRelationship(_ context.Context, in dom
 		state = "open"
 	}
 
-	m.store.mu.Lock()
-	defer m.store.mu.Unlock()
+	m.store.lockWrite()
+	defer m.store.unlockWrite()
 
 	now := time.Now().UTC()
 	rel := domain.NetworkRelationship{
@@ -275,8 +279,8 @@ func (m *MemoryNetworkStore) UpdateNetworkRelationshipState(_ context.Context, i
 	if strings.TrimSpace(state) == "" {
 		return domain.NetworkRelationship{}, false, fmt.Errorf("resolution_state is required: %w", ErrValidation)
 	}
-	m.store.mu.Lock()
-	defer m.store.mu.Unlock()
+	m.store.lockWrite()
+	defer m.store.unlockWrite()
 
 	rel, ok := m.relationships[id]
 	if !ok {
@@ -140,5 +140,8 @@ func copyOIDCProvider(p *domain.OIDCProvider) *domain.OIDCProvider {
 			cpy.RoleMapping[k] = v
 		}
 	}
+	if p.RoleRules != nil {
+		cpy.RoleRules = append([]domain.OIDCRoleRule(nil), p.RoleRules...)
+	}
 	return &cpy
 }
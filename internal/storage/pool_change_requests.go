@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"context"
+	"net/netip"
+
+	"cloudpam/internal/cidr"
+	"cloudpam/internal/domain"
+)
+
+// PoolChangeRequestStore persists pool mutations waiting for approval.
+type PoolChangeRequestStore interface {
+	// CreatePoolChangeRequest stores a new request. A create that holds a
+	// CIDR fails with ErrConflict when another pending create under the
+	// same parent already holds an overlapping block.
+	CreatePoolChangeRequest(ctx context.Context, r domain.PoolChangeRequest) error
+
+	// GetPoolChangeRequest returns a request by ID.
+	GetPoolChangeRequest(ctx context.Context, id string) (*domain.PoolChangeRequest, error)
+
+	// ListPoolChangeRequests returns requests with the given status, or all
+	// of them when status is empty, newest first.
+	ListPoolChangeRequests(ctx context.Context, status domain.PoolChangeRequestStatus) ([]domain.PoolChangeRequest, error)
+
+	// ReviewPoolChangeRequest records the decision on a pending request:
+	// its status, the reviewed fields and the pool ID. It returns
+	// ErrConflict if the request is no longer pending.
+	ReviewPoolChangeRequest(ctx context.Context, r domain.PoolChangeRequest) error
+}
+
+// OverlappingHold returns the first request in held that holds a block
+// overlapping prefix under the same parent, or nil. A nil parentID means a
+// top-level pool.
+func OverlappingHold(held []domain.PoolChangeRequest, prefix string, parentID *int64) *domain.PoolChangeRequest {
+	pfx, err := netip.ParsePrefix(prefix)
+	if err != nil {
+		return nil
+	}
+	for i, r := range held {
+		if !r.HoldsCIDR() || !sameParent(r.ParentID, parentID) {
+			continue
+		}
+		hp, err := netip.ParsePrefix(r.CIDR)
+		if err != nil {
+			continue
+		}
+		if cidr.PrefixesOverlap(pfx.Masked(), hp.Masked()) {
+			return &held[i]
+		}
+	}
+	return nil
+}
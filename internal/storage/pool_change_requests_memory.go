@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"cloudpam/internal/domain"
+)
+
+// MemoryPoolChangeRequestStore is an in-memory implementation of
+// PoolChangeRequestStore.
+type MemoryPoolChangeRequestStore struct {
+	mu       sync.RWMutex
+	requests map[string]domain.PoolChangeRequest
+}
+
+// NewMemoryPoolChangeRequestStore creates a new in-memory change request store.
+func NewMemoryPoolChangeRequestStore() *MemoryPoolChangeRequestStore {
+	return &MemoryPoolChangeRequestStore{requests: make(map[string]domain.PoolChangeRequest)}
+}
+
+func (s *MemoryPoolChangeRequestStore) CreatePoolChangeRequest(_ context.Context, r domain.PoolChangeRequest) error {
+	if r.ID == "" {
+		return ErrValidation
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.requests[r.ID]; exists {
+		return ErrConflict
+	}
+	if r.HoldsCIDR() {
+		held := make([]domain.PoolChangeRequest, 0, len(s.requests))
+		for _, existing := range s.requests {
+			held = append(held, existing)
+		}
+		if h := OverlappingHold(held, r.CIDR, r.ParentID); h != nil {
+			return fmt.Errorf("%s is held by change request %s (%s): %w", r.CIDR, h.ID, h.CIDR, ErrConflict)
+		}
+	}
+	s.requests[r.ID] = clonePoolChangeRequest(r)
+	return nil
+}
+
+func (s *MemoryPoolChangeRequestStore) GetPoolChangeRequest(_ context.Context, id string) (*domain.PoolChangeRequest, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r, ok := s.requests[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	out := clonePoolChangeRequest(r)
+	return &out, nil
+}
+
+func (s *MemoryPoolChangeRequestStore) ListPoolChangeRequests(_ context.Context, status domain.PoolChangeRequestStatus) ([]domain.PoolChangeRequest, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]domain.PoolChangeRequest, 0, len(s.requests))
+	for _, r := range s.requests {
+		if status != "" && r.Status != status {
+			continue
+		}
+		out = append(out, clonePoolChangeRequest(r))
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.After(out[j].CreatedAt)
+		}
+		return out[i].ID > out[j].ID
+	})
+	return out, nil
+}
+
+func (s *MemoryPoolChangeRequestStore) ReviewPoolChangeRequest(_ context.Context, r domain.PoolChangeRequest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, ok := s.requests[r.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if existing.Status != domain.PoolChangeRequestPending {
+		return fmt.Errorf("change request %s is %s: %w", r.ID, existing.Status, ErrConflict)
+	}
+	existing.Status = r.Status
+	existing.PoolID = r.PoolID
+	existing.ReviewedBy = r.ReviewedBy
+	existing.ReviewedByID = r.ReviewedByID
+	existing.ReviewedRole = r.ReviewedRole
+	existing.ReviewComment = r.ReviewComment
+	existing.ReviewedAt = r.ReviewedAt
+	s.requests[r.ID] = clonePoolChangeRequest(existing)
+	return nil
+}
+
+func clonePoolChangeRequest(r domain.PoolChangeRequest) domain.PoolChangeRequest {
+	r.PoolID = cloneInt64Ptr(r.PoolID)
+	r.ParentID = cloneInt64Ptr(r.ParentID)
+	if r.Create != nil {
+		c := *r.Create
+		c.ParentID = cloneInt64Ptr(c.ParentID)
+		c.AccountID = cloneInt64Ptr(c.AccountID)
+		c.Tags = cloneStringStringMap(c.Tags)
+		r.Create = &c
+	}
+	if r.Update != nil {
+		u := *r.Update
+		u.AccountID = cloneInt64Ptr(u.AccountID)
+		if u.Tags != nil {
+			tags := cloneStringStringMap(*u.Tags)
+			u.Tags = &tags
+		}
+		r.Update = &u
+	}
+	r.PolicyIDs = cloneStringSlice(r.PolicyIDs)
+	if r.ReviewedAt != nil {
+		t := *r.ReviewedAt
+		r.ReviewedAt = &t
+	}
+	return r
+}
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cloudpam/internal/domain"
+)
+
+func TestMemoryPoolChangeRequestStore(t *testing.T) {
+	store := NewMemoryPoolChangeRequestStore()
+	ctx := context.Background()
+	now := time.Now().UTC()
+	parent := int64(1)
+
+	create := domain.PoolChangeRequest{
+		ID: "r1", Operation: domain.PoolChangeCreate, Status: domain.PoolChangeRequestPending,
+		PoolName: "prod-vpc", CIDR: "10.0.0.0/16", ParentID: &parent,
+		Create:    &domain.CreatePool{Name: "prod-vpc", CIDR: "10.0.0.0/16", ParentID: &parent, Tags: map[string]string{"env": "prod"}},
+		PolicyIDs: []string{"prod"}, RequestedBy: "alice", RequestedRole: "operator",
+		CreatedAt: now.Add(-time.Minute),
+	}
+	if err := store.CreatePoolChangeRequest(ctx, create); err != nil {
+		t.Fatalf("CreatePoolChangeRequest: %v", err)
+	}
+	if err := store.CreatePoolChangeRequest(ctx, create); !errors.Is(err, ErrConflict) {
+		t.Fatalf("duplicate CreatePoolChangeRequest: expected ErrConflict, got %v", err)
+	}
+
+	// The pending create holds 10.0.0.0/16 under parent 1, but not elsewhere.
+	overlap := create
+	overlap.ID, overlap.CIDR = "r2", "10.0.128.0/17"
+	if err := store.CreatePoolChangeRequest(ctx, overlap); !errors.Is(err, ErrConflict) {
+		t.Fatalf("overlapping hold: expected ErrConflict, got %v", err)
+	}
+	overlap.ParentID = nil
+	if err := store.CreatePoolChangeRequest(ctx, overlap); err != nil {
+		t.Fatalf("same block at top level: %v", err)
+	}
+	pool := int64(7)
+	deprecated := domain.PoolStatusDeprecated
+	update := domain.PoolChangeRequest{
+		ID: "r3", Operation: domain.PoolChangeUpdate, Status: domain.PoolChangeRequestPending,
+		PoolID: &pool, PoolVersion: 3, PoolName: "prod-vpc",
+		Update:      &domain.UpdatePool{Status: &deprecated},
+		RequestedBy: "alice", RequestedRole: "operator", CreatedAt: now,
+	}
+	if err := store.CreatePoolChangeRequest(ctx, update); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := store.GetPoolChangeRequest(ctx, "r1")
+	if err != nil {
+		t.Fatalf("GetPoolChangeRequest: %v", err)
+	}
+	// Returned values are copies.
+	got.Create.Tags["env"] = "changed"
+	if again, _ := store.GetPoolChangeRequest(ctx, "r1"); again.Create.Tags["env"] != "prod" {
+		t.Fatalf("stored copy = %+v", again.Create)
+	}
+	if _, err := store.GetPoolChangeRequest(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetPoolChangeRequest missing: expected ErrNotFound, got %v", err)
+	}
+
+	reviewed := now
+	created := int64(9)
+	got.Status, got.PoolID = domain.PoolChangeRequestApplied, &created
+	got.ReviewedBy, got.ReviewedRole, got.ReviewedAt = "bob", "admin", &reviewed
+	if err := store.ReviewPoolChangeRequest(ctx, *got); err != nil {
+		t.Fatalf("ReviewPoolChangeRequest: %v", err)
+	}
+	if err := store.ReviewPoolChangeRequest(ctx, *got); !errors.Is(err, ErrConflict) {
+		t.Fatalf("second ReviewPoolChangeRequest: expected ErrConflict, got %v", err)
+	}
+	got.ID = "missing"
+	if err := store.ReviewPoolChangeRequest(ctx, *got); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("ReviewPoolChangeRequest missing: expected ErrNotFound, got %v", err)
+	}
+
+	// A decided request no longer holds its block.
+	overlap.ParentID = &parent
+	overlap.ID = "r4"
+	if err := store.CreatePoolChangeRequest(ctx, overlap); err != nil {
+		t.Fatalf("hold after approval: %v", err)
+	}
+
+	all, err := store.ListPoolChangeRequests(ctx, "")
+	if err != nil || len(all) != 4 {
+		t.Fatalf("ListPoolChangeRequests = %+v, %v", all, err)
+	}
+	applied, err := store.ListPoolChangeRequests(ctx, domain.PoolChangeRequestApplied)
+	if err != nil || len(applied) != 1 || *applied[0].PoolID != 9 || applied[0].ReviewedBy != "bob" {
+		t.Fatalf("ListPoolChangeRequests(applied) = %+v, %v", applied, err)
+	}
+}
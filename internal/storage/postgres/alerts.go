@@ -0,0 +1,169 @@
+//go:build postgres
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+var _ storage.AlertStore = (*Store)(nil)
+
+const alertColumns = `id, rule_id, pool_id, pool_name, pool_cidr, kind, severity, status, value, threshold, message,
+	opened_at, updated_at, acknowledged_at, acknowledged_by, resolved_at, resolved_by, notified_at`
+
+// CreateAlert stores a new alert.
+func (s *Store) CreateAlert(ctx context.Context, a domain.Alert) error {
+	_, err := s.q().Exec(ctx,
+		`INSERT INTO alerts (id, organization_id, rule_id, pool_id, pool_name, pool_cidr, kind, severity, status,
+			value, threshold, message, opened_at, updated_at, acknowledged_at, acknowledged_by,
+			resolved_at, resolved_by, notified_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)`,
+		a.ID, s.orgID, a.RuleID, a.PoolID, a.PoolName, a.PoolCIDR, string(a.Kind), string(a.Severity), string(a.Status),
+		a.Value, a.Threshold, a.Message, a.OpenedAt, a.UpdatedAt, a.AcknowledgedAt, nilStringIfEmpty(a.AcknowledgedBy),
+		a.ResolvedAt, nilStringIfEmpty(a.ResolvedBy), a.NotifiedAt,
+	)
+	return storage.WrapIfConflict(err)
+}
+
+// GetAlert returns an alert by ID.
+func (s *Store) GetAlert(ctx context.Context, id string) (*domain.Alert, error) {
+	row := s.q().QueryRow(ctx,
+		`SELECT `+alertColumns+` FROM alerts WHERE id = $1 AND organization_id = $2`,
+		id, s.orgID,
+	)
+	a, err := scanAlert(row)
+	if err == pgx.ErrNoRows {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// ListAlerts returns paginated alerts, most recently opened first.
+func (s *Store) ListAlerts(ctx context.Context, filters domain.AlertFilters) ([]domain.Alert, int, error) {
+	where := []string{"organization_id = $1"}
+	args := []any{s.orgID}
+	addArg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if filters.PoolID != 0 {
+		where = append(where, "pool_id = "+addArg(filters.PoolID))
+	}
+	switch filters.Status {
+	case "":
+	case domain.AlertStatusActive:
+		where = append(where, "status <> "+addArg(string(domain.AlertStatusResolved)))
+	default:
+		where = append(where, "status = "+addArg(filters.Status))
+	}
+	if filters.Severity != "" {
+		where = append(where, "severity = "+addArg(filters.Severity))
+	}
+	if filters.Kind != "" {
+		where = append(where, "kind = "+addArg(filters.Kind))
+	}
+	whereClause := " WHERE " + strings.Join(where, " AND ")
+
+	var total int
+	if err := s.q().QueryRow(ctx, "SELECT COUNT(*) FROM alerts"+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	page := filters.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := filters.PageSize
+	if pageSize < 1 {
+		pageSize = 50
+	}
+	limit := addArg(pageSize)
+	offset := addArg((page - 1) * pageSize)
+
+	out, err := s.queryAlerts(ctx,
+		`SELECT `+alertColumns+` FROM alerts`+whereClause+
+			` ORDER BY opened_at DESC, id DESC LIMIT `+limit+` OFFSET `+offset,
+		args...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	return out, total, nil
+}
+
+// ListActiveAlerts returns every open or acknowledged alert.
+func (s *Store) ListActiveAlerts(ctx context.Context) ([]domain.Alert, error) {
+	return s.queryAlerts(ctx,
+		`SELECT `+alertColumns+` FROM alerts
+		 WHERE organization_id = $1 AND status <> $2
+		 ORDER BY opened_at DESC, id DESC`,
+		s.orgID, string(domain.AlertStatusResolved),
+	)
+}
+
+// UpdateAlert replaces an alert's mutable fields.
+func (s *Store) UpdateAlert(ctx context.Context, a domain.Alert) error {
+	cmd, err := s.q().Exec(ctx,
+		`UPDATE alerts SET severity = $1, status = $2, value = $3, threshold = $4, message = $5, updated_at = $6,
+			acknowledged_at = $7, acknowledged_by = $8, resolved_at = $9, resolved_by = $10, notified_at = $11
+		 WHERE id = $12 AND organization_id = $13`,
+		string(a.Severity), string(a.Status), a.Value, a.Threshold, a.Message, a.UpdatedAt,
+		a.AcknowledgedAt, nilStringIfEmpty(a.AcknowledgedBy), a.ResolvedAt, nilStringIfEmpty(a.ResolvedBy), a.NotifiedAt,
+		a.ID, s.orgID,
+	)
+	if err != nil {
+		return storage.WrapIfConflict(err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return fmt.Errorf("alert %s: %w", a.ID, storage.ErrNotFound)
+	}
+	return nil
+}
+
+func (s *Store) queryAlerts(ctx context.Context, query string, args ...any) ([]domain.Alert, error) {
+	rows, err := s.q().Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []domain.Alert{}
+	for rows.Next() {
+		a, err := scanAlert(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+func scanAlert(row interface{ Scan(dest ...any) error }) (domain.Alert, error) {
+	var a domain.Alert
+	var kind, severity, status string
+	var acknowledgedBy, resolvedBy *string
+	if err := row.Scan(&a.ID, &a.RuleID, &a.PoolID, &a.PoolName, &a.PoolCIDR, &kind, &severity, &status,
+		&a.Value, &a.Threshold, &a.Message, &a.OpenedAt, &a.UpdatedAt,
+		&a.AcknowledgedAt, &acknowledgedBy, &a.ResolvedAt, &resolvedBy, &a.NotifiedAt); err != nil {
+		return a, err
+	}
+	a.Kind = domain.AlertKind(kind)
+	a.Severity = domain.AlertSeverity(severity)
+	a.Status = domain.AlertStatus(status)
+	if acknowledgedBy != nil {
+		a.AcknowledgedBy = *acknowledgedBy
+	}
+	if resolvedBy != nil {
+		a.ResolvedBy = *resolvedBy
+	}
+	return a, nil
+}
@@ -0,0 +1,55 @@
+//go:build postgres
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+var _ storage.PoolAllocator = (*Store)(nil)
+
+// AllocatePool picks and inserts a child pool in a single transaction. The
+// parent row is locked FOR UPDATE, which serializes allocators on the same
+// parent without blocking readers.
+func (s *Store) AllocatePool(ctx context.Context, parentID int64, pick storage.AllocateFunc) (domain.Pool, error) {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := fmt.Sprintf(`
+		SELECT p.%s
+		FROM pools p
+		WHERE p.seq_id = $1 AND p.organization_id = $2 AND p.deleted_at IS NULL
+		FOR UPDATE OF p`, poolColumnsWithParentAccount())
+	parent, ok, err := s.scanPool(tx.QueryRow(ctx, query, parentID, s.orgID))
+	if err != nil {
+		return domain.Pool{}, err
+	}
+	if !ok {
+		return domain.Pool{}, fmt.Errorf("parent pool not found: %w", storage.ErrNotFound)
+	}
+	children, err := s.poolChildren(ctx, tx, parentID)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+
+	in, err := pick(parent, children)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+	in.ParentID = &parentID
+	p, err := s.createPool(ctx, tx, in)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return domain.Pool{}, err
+	}
+	return p, nil
+}
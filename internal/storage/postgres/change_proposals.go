@@ -0,0 +1,165 @@
+//go:build postgres
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+var _ storage.ChangeProposalStore = (*Store)(nil)
+
+const changeProposalColumns = `id, title, source, session_id, status, tree::text, change_set::text, proposed_by,
+	proposed_by_id, proposed_role, reviewed_by, reviewed_by_id, reviewed_role, review_comment, pool_map::text,
+	created_at, reviewed_at`
+
+// CreateChangeProposal stores a new proposal.
+func (s *Store) CreateChangeProposal(ctx context.Context, p domain.ChangeProposal) error {
+	tree, err := json.Marshal(p.Tree)
+	if err != nil {
+		return err
+	}
+	changeSet, err := json.Marshal(p.ChangeSet)
+	if err != nil {
+		return err
+	}
+	poolMap, err := marshalPoolMap(p.PoolMap)
+	if err != nil {
+		return err
+	}
+	_, err = s.q().Exec(ctx,
+		`INSERT INTO change_proposals (id, organization_id, title, source, session_id, status, tree, change_set,
+		     proposed_by, proposed_by_id, proposed_role, reviewed_by, reviewed_by_id, reviewed_role, review_comment,
+		     pool_map, created_at, reviewed_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7::jsonb, $8::jsonb, $9, $10, $11, $12, $13, $14, $15, $16::jsonb, $17, $18)`,
+		p.ID, s.orgID, p.Title, string(p.Source), p.SessionID, string(p.Status), string(tree), string(changeSet),
+		p.ProposedBy, p.ProposedByID, p.ProposedRole, nilStringIfEmpty(p.ReviewedBy), nilStringIfEmpty(p.ReviewedByID),
+		nilStringIfEmpty(p.ReviewedRole), nilStringIfEmpty(p.ReviewComment), poolMap, p.CreatedAt, p.ReviewedAt,
+	)
+	return storage.WrapIfConflict(err)
+}
+
+// GetChangeProposal returns a proposal by ID.
+func (s *Store) GetChangeProposal(ctx context.Context, id string) (*domain.ChangeProposal, error) {
+	row := s.q().QueryRow(ctx,
+		`SELECT `+changeProposalColumns+` FROM change_proposals WHERE id = $1 AND organization_id = $2`,
+		id, s.orgID,
+	)
+	p, err := scanChangeProposal(row)
+	if err == pgx.ErrNoRows {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ListChangeProposals returns proposals, newest first.
+func (s *Store) ListChangeProposals(ctx context.Context, status domain.ChangeProposalStatus) ([]domain.ChangeProposal, error) {
+	query := `SELECT ` + changeProposalColumns + ` FROM change_proposals WHERE organization_id = $1`
+	args := []any{s.orgID}
+	if status != "" {
+		query += ` AND status = $2`
+		args = append(args, string(status))
+	}
+	rows, err := s.q().Query(ctx, query+` ORDER BY created_at DESC, id DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []domain.ChangeProposal{}
+	for rows.Next() {
+		p, err := scanChangeProposal(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// ReviewChangeProposal records the decision on a pending proposal.
+func (s *Store) ReviewChangeProposal(ctx context.Context, p domain.ChangeProposal) error {
+	poolMap, err := marshalPoolMap(p.PoolMap)
+	if err != nil {
+		return err
+	}
+	cmd, err := s.q().Exec(ctx,
+		`UPDATE change_proposals
+		 SET status = $1, reviewed_by = $2, reviewed_by_id = $3, reviewed_role = $4, review_comment = $5,
+		     pool_map = $6::jsonb, reviewed_at = $7
+		 WHERE id = $8 AND organization_id = $9 AND status = $10`,
+		string(p.Status), nilStringIfEmpty(p.ReviewedBy), nilStringIfEmpty(p.ReviewedByID), nilStringIfEmpty(p.ReviewedRole),
+		nilStringIfEmpty(p.ReviewComment), poolMap, p.ReviewedAt, p.ID, s.orgID, string(domain.ChangeProposalPending),
+	)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() > 0 {
+		return nil
+	}
+	var status string
+	err = s.q().QueryRow(ctx,
+		`SELECT status FROM change_proposals WHERE id = $1 AND organization_id = $2`, p.ID, s.orgID,
+	).Scan(&status)
+	if err == pgx.ErrNoRows {
+		return storage.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("change proposal %s is %s: %w", p.ID, status, storage.ErrConflict)
+}
+
+func marshalPoolMap(m map[string]int64) (string, error) {
+	if m == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(m)
+	return string(b), err
+}
+
+func scanChangeProposal(row interface{ Scan(dest ...any) error }) (domain.ChangeProposal, error) {
+	var p domain.ChangeProposal
+	var source, status, tree, changeSet, poolMap string
+	var reviewedBy, reviewedByID, reviewedRole, reviewComment *string
+	if err := row.Scan(&p.ID, &p.Title, &source, &p.SessionID, &status, &tree, &changeSet, &p.ProposedBy,
+		&p.ProposedByID, &p.ProposedRole, &reviewedBy, &reviewedByID, &reviewedRole, &reviewComment, &poolMap,
+		&p.CreatedAt, &p.ReviewedAt); err != nil {
+		return p, err
+	}
+	p.Source = domain.ChangeProposalSource(source)
+	p.Status = domain.ChangeProposalStatus(status)
+	if err := json.Unmarshal([]byte(tree), &p.Tree); err != nil {
+		return p, err
+	}
+	if err := json.Unmarshal([]byte(changeSet), &p.ChangeSet); err != nil {
+		return p, err
+	}
+	if err := json.Unmarshal([]byte(poolMap), &p.PoolMap); err != nil {
+		return p, err
+	}
+	if len(p.PoolMap) == 0 {
+		p.PoolMap = nil
+	}
+	if reviewedBy != nil {
+		p.ReviewedBy = *reviewedBy
+	}
+	if reviewedByID != nil {
+		p.ReviewedByID = *reviewedByID
+	}
+	if reviewedRole != nil {
+		p.ReviewedRole = *reviewedRole
+	}
+	if reviewComment != nil {
+		p.ReviewComment = *reviewComment
+	}
+	return p, nil
+}
@@ -0,0 +1,91 @@
+//go:build postgres
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+var _ storage.CIDROperations = (*Store)(nil)
+
+// findPools runs a CIDR predicate against the live pools of the store's
+// organization. cond may reference $1 (organization) and $2 (the prefix);
+// extra args follow from $3. Results are ordered by address and then prefix
+// length, which is the natural ordering of the cidr type.
+func (s *Store) findPools(ctx context.Context, cond string, args ...any) ([]*domain.Pool, error) {
+	query := fmt.Sprintf(`
+		SELECT p.%s
+		FROM pools p
+		WHERE p.organization_id = $1 AND p.deleted_at IS NULL AND %s
+		ORDER BY p.cidr, p.seq_id`, poolColumnsWithParentAccount(), cond)
+
+	rows, err := s.q().Query(ctx, query, append([]any{s.orgID}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	pools, err := s.scanPools(rows)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*domain.Pool, len(pools))
+	for i := range pools {
+		out[i] = &pools[i]
+	}
+	return out, nil
+}
+
+// FindOverlapping returns live pools overlapping cidr using the && operator,
+// optionally limited to one level of the hierarchy.
+func (s *Store) FindOverlapping(ctx context.Context, cidr string, parentID *int64) ([]*domain.Pool, error) {
+	q, err := storage.ParseCIDRArg(cidr)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case parentID == nil:
+		return s.findPools(ctx, `p.cidr && $2::cidr`, q.String())
+	case *parentID == 0:
+		return s.findPools(ctx, `p.cidr && $2::cidr AND p.parent_id IS NULL`, q.String())
+	default:
+		return s.findPools(ctx, `p.cidr && $2::cidr AND p.parent_id = (SELECT id FROM pools WHERE seq_id = $3 AND organization_id = $1)`, q.String(), *parentID)
+	}
+}
+
+// FindContaining returns live pools whose CIDR contains cidr (>>=).
+func (s *Store) FindContaining(ctx context.Context, cidr string) ([]*domain.Pool, error) {
+	q, err := storage.ParseCIDRArg(cidr)
+	if err != nil {
+		return nil, err
+	}
+	return s.findPools(ctx, `p.cidr >>= $2::cidr`, q.String())
+}
+
+// FindContainedBy returns live pools inside cidr (<<=).
+func (s *Store) FindContainedBy(ctx context.Context, cidr string) ([]*domain.Pool, error) {
+	q, err := storage.ParseCIDRArg(cidr)
+	if err != nil {
+		return nil, err
+	}
+	return s.findPools(ctx, `p.cidr <<= $2::cidr`, q.String())
+}
+
+// FindGaps returns the free blocks inside a pool.
+func (s *Store) FindGaps(ctx context.Context, parentID int64, prefixLen int) ([]string, error) {
+	parent, ok, err := s.GetPool(ctx, parentID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("parent pool not found: %w", storage.ErrNotFound)
+	}
+	children, err := s.poolChildren(ctx, s.q(), parentID)
+	if err != nil {
+		return nil, err
+	}
+	return storage.FreeBlocks(parent, children, prefixLen)
+}
@@ -0,0 +1,134 @@
+//go:build postgres
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v5"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+var _ storage.ComplianceRuleStore = (*Store)(nil)
+
+const complianceRuleColumns = `id, name, description, enabled, severity, enforce, match_spec::text, check_spec::text,
+	message, remediation, created_at, updated_at`
+
+// CreateComplianceRule stores a new rule.
+func (s *Store) CreateComplianceRule(ctx context.Context, rule domain.ComplianceRule) error {
+	match, check, err := marshalComplianceSpecs(rule)
+	if err != nil {
+		return err
+	}
+	_, err = s.q().Exec(ctx,
+		`INSERT INTO compliance_rules (id, organization_id, name, description, enabled, severity, enforce,
+		     match_spec, check_spec, message, remediation, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8::jsonb, $9::jsonb, $10, $11, $12, $13)`,
+		rule.ID, s.orgID, rule.Name, rule.Description, rule.Enabled, rule.Severity, rule.Enforce,
+		match, check, rule.Message, rule.Remediation, rule.CreatedAt, rule.UpdatedAt,
+	)
+	return storage.WrapIfConflict(err)
+}
+
+// GetComplianceRule returns a rule by ID.
+func (s *Store) GetComplianceRule(ctx context.Context, id string) (*domain.ComplianceRule, error) {
+	row := s.q().QueryRow(ctx,
+		`SELECT `+complianceRuleColumns+` FROM compliance_rules WHERE id = $1 AND organization_id = $2`,
+		id, s.orgID,
+	)
+	rule, err := scanComplianceRule(row)
+	if err == pgx.ErrNoRows {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// ListComplianceRules returns all rules ordered by ID.
+func (s *Store) ListComplianceRules(ctx context.Context) ([]domain.ComplianceRule, error) {
+	rows, err := s.q().Query(ctx,
+		`SELECT `+complianceRuleColumns+` FROM compliance_rules WHERE organization_id = $1 ORDER BY id`,
+		s.orgID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []domain.ComplianceRule{}
+	for rows.Next() {
+		rule, err := scanComplianceRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, rule)
+	}
+	return out, rows.Err()
+}
+
+// UpdateComplianceRule replaces a rule. CreatedAt is kept.
+func (s *Store) UpdateComplianceRule(ctx context.Context, rule domain.ComplianceRule) error {
+	match, check, err := marshalComplianceSpecs(rule)
+	if err != nil {
+		return err
+	}
+	cmd, err := s.q().Exec(ctx,
+		`UPDATE compliance_rules
+		    SET name = $1, description = $2, enabled = $3, severity = $4, enforce = $5,
+		        match_spec = $6::jsonb, check_spec = $7::jsonb, message = $8, remediation = $9, updated_at = $10
+		  WHERE id = $11 AND organization_id = $12`,
+		rule.Name, rule.Description, rule.Enabled, rule.Severity, rule.Enforce, match, check,
+		rule.Message, rule.Remediation, rule.UpdatedAt, rule.ID, s.orgID,
+	)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteComplianceRule removes a rule.
+func (s *Store) DeleteComplianceRule(ctx context.Context, id string) error {
+	cmd, err := s.q().Exec(ctx, `DELETE FROM compliance_rules WHERE id = $1 AND organization_id = $2`, id, s.orgID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+func marshalComplianceSpecs(rule domain.ComplianceRule) (string, string, error) {
+	match, err := json.Marshal(rule.Match)
+	if err != nil {
+		return "", "", err
+	}
+	check, err := json.Marshal(rule.Check)
+	if err != nil {
+		return "", "", err
+	}
+	return string(match), string(check), nil
+}
+
+func scanComplianceRule(row interface{ Scan(dest ...any) error }) (domain.ComplianceRule, error) {
+	var rule domain.ComplianceRule
+	var match, check string
+	if err := row.Scan(&rule.ID, &rule.Name, &rule.Description, &rule.Enabled, &rule.Severity, &rule.Enforce,
+		&match, &check, &rule.Message, &rule.Remediation, &rule.CreatedAt, &rule.UpdatedAt); err != nil {
+		return rule, err
+	}
+	if err := json.Unmarshal([]byte(match), &rule.Match); err != nil {
+		return rule, err
+	}
+	if err := json.Unmarshal([]byte(check), &rule.Check); err != nil {
+		return rule, err
+	}
+	return rule, nil
+}
@@ -14,7 +14,7 @@ import (
 
 // CreateConversation persists a new conversation.
 func (s *Store) CreateConversation(ctx context.Context, conv domain.Conversation) error {
-	_, err := s.pool.Exec(ctx,
+	_, err := s.q().Exec(ctx,
 		`INSERT INTO conversations (id, title, created_at, updated_at) VALUES ($1, $2, $3, $4)`,
 		conv.ID, conv.Title, conv.CreatedAt, conv.UpdatedAt,
 	)
@@ -24,7 +24,7 @@ func (s *Store) CreateConversation(ctx context.Context, conv domain.Conversation
 // GetConversation returns a conversation with its messages.
 func (s *Store) GetConversation(ctx context.Context, id string) (*domain.ConversationWithMessages, error) {
 	var conv domain.Conversation
-	err := s.pool.QueryRow(ctx,
+	err := s.q().QueryRow(ctx,
 		`SELECT id, title, created_at, updated_at FROM conversations WHERE id = $1`, id,
 	).Scan(&conv.ID, &conv.Title, &conv.CreatedAt, &conv.UpdatedAt)
 	if err != nil {
@@ -34,7 +34,7 @@ func (s *Store) GetConversation(ctx context.Context, id string) (*domain.Convers
 		return nil, err
 	}
 
-	rows, err := s.pool.Query(ctx,
+	rows, err := s.q().Query(ctx,
 		`SELECT id, conversation_id, role, content, created_at FROM conversation_messages WHERE conversation_id = $1 ORDER BY created_at ASC`, id,
 	)
 	if err != nil {
@@ -62,7 +62,7 @@ func (s *Store) GetConversation(ctx context.Context, id string) (*domain.Convers
 
 // ListConversations returns all conversations ordered by updated_at desc.
 func (s *Store) ListConversations(ctx context.Context) ([]domain.Conversation, error) {
-	rows, err := s.pool.Query(ctx,
+	rows, err := s.q().Query(ctx,
 		`SELECT id, title, created_at, updated_at FROM conversations ORDER BY updated_at DESC`,
 	)
 	if err != nil {
@@ -86,7 +86,7 @@ func (s *Store) ListConversations(ctx context.Context) ([]domain.Conversation, e
 
 // DeleteConversation removes a conversation and its messages (via CASCADE).
 func (s *Store) DeleteConversation(ctx context.Context, id string) error {
-	tag, err := s.pool.Exec(ctx, `DELETE FROM conversations WHERE id = $1`, id)
+	tag, err := s.q().Exec(ctx, `DELETE FROM conversations WHERE id = $1`, id)
 	if err != nil {
 		return err
 	}
@@ -98,7 +98,7 @@ func (s *Store) DeleteConversation(ctx context.Context, id string) error {
 
 // AddMessage appends a message to a conversation and updates its updated_at.
 func (s *Store) AddMessage(ctx context.Context, msg domain.ConversationMessage) error {
-	tx, err := s.pool.Begin(ctx)
+	tx, err := s.begin(ctx)
 	if err != nil {
 		return err
 	}
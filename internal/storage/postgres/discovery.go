@@ -53,7 +53,7 @@ func (s *Store) ListDiscoveredResources(ctx context.Context, accountID int64, fi
 
 	whereClause := strings.Join(where, " AND ")
 	var total int
-	if err := s.pool.QueryRow(ctx, "SELECT COUNT(*) FROM discovered_resources WHERE "+whereClause, args...).Scan(&total); err != nil {
+	if err := s.q().QueryRow(ctx, "SELECT COUNT(*) FROM discovered_resources WHERE "+whereClause, args...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
 
@@ -74,7 +74,7 @@ func (s *Store) ListDiscoveredResources(ctx context.Context, accountID int64, fi
 		ORDER BY discovered_at DESC
 		LIMIT $%d OFFSET $%d`, whereClause, len(queryArgs)-1, len(queryArgs))
 
-	rows, err := s.pool.Query(ctx, query, queryArgs...)
+	rows, err := s.q().Query(ctx, query, queryArgs...)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -95,7 +95,7 @@ func (s *Store) ListDiscoveredResources(ctx context.Context, accountID int64, fi
 }
 
 func (s *Store) GetDiscoveredResource(ctx context.Context, id uuid.UUID) (*domain.DiscoveredResource, error) {
-	row := s.pool.QueryRow(ctx, `SELECT id, account_id, provider, region, resource_type, resource_id, name, cidr, parent_resource_id, pool_id, status, metadata, discovered_at, last_seen_at
+	row := s.q().QueryRow(ctx, `SELECT id, account_id, provider, region, resource_type, resource_id, name, cidr, parent_resource_id, pool_id, status, metadata, discovered_at, last_seen_at
 		FROM discovered_resources
 		WHERE id = $1 AND organization_id = $2`, id, s.orgID)
 	r, err := scanPostgresDiscoveredResource(row)
@@ -120,7 +120,7 @@ func (s *Store) UpsertDiscoveredResource(ctx context.Context, res domain.Discove
 		metadataJSON = []byte("{}")
 	}
 
-	_, err := s.pool.Exec(ctx, `INSERT INTO discovered_resources
+	_, err := s.q().Exec(ctx, `INSERT INTO discovered_resources
 		(id, organization_id, account_id, provider, region, resource_type, resource_id, name, cidr, parent_resource_id, pool_id, status, metadata, discovered_at, last_seen_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13::jsonb, $14, $15)
 		ON CONFLICT (organization_id, account_id, resource_id) DO UPDATE SET
@@ -137,7 +137,7 @@ func (s *Store) UpsertDiscoveredResource(ctx context.Context, res domain.Discove
 }
 
 func (s *Store) MarkStaleResources(ctx context.Context, accountID int64, before time.Time) (int, error) {
-	tag, err := s.pool.Exec(ctx, `UPDATE discovered_resources
+	tag, err := s.q().Exec(ctx, `UPDATE discovered_resources
 		SET status = $1
 		WHERE organization_id = $2 AND account_id = $3 AND status = $4 AND last_seen_at < $5`,
 		string(domain.DiscoveryStatusStale), s.orgID, accountID, string(domain.DiscoveryStatusActive), before)
@@ -148,7 +148,7 @@ func (s *Store) MarkStaleResources(ctx context.Context, accountID int64, before
 }
 
 func (s *Store) LinkResourceToPool(ctx context.Context, resourceID uuid.UUID, poolID int64) error {
-	tag, err := s.pool.Exec(ctx, `UPDATE discovered_resources
+	tag, err := s.q().Exec(ctx, `UPDATE discovered_resources
 		SET pool_id = $1
 		WHERE id = $2 AND organization_id = $3`, poolID, resourceID, s.orgID)
 	if err != nil {
@@ -161,7 +161,7 @@ func (s *Store) LinkResourceToPool(ctx context.Context, resourceID uuid.UUID, po
 }
 
 func (s *Store) UnlinkResource(ctx context.Context, resourceID uuid.UUID) error {
-	tag, err := s.pool.Exec(ctx, `UPDATE discovered_resources
+	tag, err := s.q().Exec(ctx, `UPDATE discovered_resources
 		SET pool_id = NULL
 		WHERE id = $1 AND organization_id = $2`, resourceID, s.orgID)
 	if err != nil {
@@ -174,7 +174,7 @@ func (s *Store) UnlinkResource(ctx context.Context, resourceID uuid.UUID) error
 }
 
 func (s *Store) DeleteDiscoveredResource(ctx context.Context, id uuid.UUID) error {
-	tag, err := s.pool.Exec(ctx, `DELETE FROM discovered_resources WHERE id = $1 AND organization_id = $2`, id, s.orgID)
+	tag, err := s.q().Exec(ctx, `DELETE FROM discovered_resources WHERE id = $1 AND organization_id = $2`, id, s.orgID)
 	if err != nil {
 		return err
 	}
@@ -191,7 +191,7 @@ func (s *Store) CreateSyncJob(ctx context.Context, job domain.SyncJob) (domain.S
 	if job.CreatedAt.IsZero() {
 		job.CreatedAt = time.Now().UTC()
 	}
-	_, err := s.pool.Exec(ctx, `INSERT INTO sync_jobs
+	_, err := s.q().Exec(ctx, `INSERT INTO sync_jobs
 		(id, organization_id, account_id, status, source, agent_id, started_at, completed_at, resources_found, resources_created, resources_updated, resources_deleted, error_message, created_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`,
 		job.ID, s.orgID, job.AccountID, string(job.Status), job.Source, job.AgentID, job.StartedAt, job.CompletedAt,
@@ -203,7 +203,7 @@ func (s *Store) CreateSyncJob(ctx context.Context, job domain.SyncJob) (domain.S
 }
 
 func (s *Store) UpdateSyncJob(ctx context.Context, job domain.SyncJob) error {
-	tag, err := s.pool.Exec(ctx, `UPDATE sync_jobs SET
+	tag, err := s.q().Exec(ctx, `UPDATE sync_jobs SET
 			status = $1,
 			source = $2,
 			agent_id = $3,
@@ -228,7 +228,7 @@ func (s *Store) UpdateSyncJob(ctx context.Context, job domain.SyncJob) error {
 }
 
 func (s *Store) GetSyncJob(ctx context.Context, id uuid.UUID) (*domain.SyncJob, error) {
-	row := s.pool.QueryRow(ctx, `SELECT id, account_id, status, source, agent_id, started_at, completed_at, resources_found, resources_created, resources_updated, resources_deleted, error_message, created_at
+	row := s.q().QueryRow(ctx, `SELECT id, account_id, status, source, agent_id, started_at, completed_at, resources_found, resources_created, resources_updated, resources_deleted, error_message, created_at
 		FROM sync_jobs
 		WHERE id = $1 AND organization_id = $2`, id, s.orgID)
 	return scanPostgresSyncJob(row)
@@ -238,7 +238,7 @@ func (s *Store) ListSyncJobs(ctx context.Context, accountID int64, limit int) ([
 	if limit < 1 {
 		limit = 20
 	}
-	rows, err := s.pool.Query(ctx, `SELECT id, account_id, status, source, agent_id, started_at, completed_at, resources_found, resources_created, resources_updated, resources_deleted, error_message, created_at
+	rows, err := s.q().Query(ctx, `SELECT id, account_id, status, source, agent_id, started_at, completed_at, resources_found, resources_created, resources_updated, resources_deleted, error_message, created_at
 		FROM sync_jobs
 		WHERE organization_id = $1 AND account_id = $2
 		ORDER BY created_at DESC
@@ -263,7 +263,7 @@ func (s *Store) ListSyncJobs(ctx context.Context, accountID int64, limit int) ([
 }
 
 func (s *Store) ClaimPendingAgentSync(ctx context.Context, agentID uuid.UUID) (*domain.SyncJob, error) {
-	tx, err := s.pool.Begin(ctx)
+	tx, err := s.begin(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -314,7 +314,7 @@ func (s *Store) UpsertAgent(ctx context.Context, agent domain.DiscoveryAgent) er
 		status = string(domain.AgentApprovalApproved)
 	}
 
-	_, err := s.pool.Exec(ctx, `INSERT INTO discovery_agents
+	_, err := s.q().Exec(ctx, `INSERT INTO discovery_agents
 		(id, organization_id, name, account_id, api_key_id, version, hostname, last_seen_at, created_at, status, registered_at, approved_at, approved_by)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		ON CONFLICT (id) DO UPDATE SET
@@ -334,14 +334,14 @@ func (s *Store) UpsertAgent(ctx context.Context, agent domain.DiscoveryAgent) er
 }
 
 func (s *Store) GetAgent(ctx context.Context, id uuid.UUID) (*domain.DiscoveryAgent, error) {
-	row := s.pool.QueryRow(ctx, `SELECT id, name, account_id, api_key_id, version, hostname, last_seen_at, created_at, status, registered_at, approved_at, approved_by
+	row := s.q().QueryRow(ctx, `SELECT id, name, account_id, api_key_id, version, hostname, last_seen_at, created_at, status, registered_at, approved_at, approved_by
 		FROM discovery_agents
 		WHERE id = $1 AND organization_id = $2`, id, s.orgID)
 	return scanPostgresAgent(row)
 }
 
 func (s *Store) DeleteAgent(ctx context.Context, id uuid.UUID) error {
-	tag, err := s.pool.Exec(ctx, `DELETE FROM discovery_agents WHERE id = $1 AND organization_id = $2`, id, s.orgID)
+	tag, err := s.q().Exec(ctx, `DELETE FROM discovery_agents WHERE id = $1 AND organization_id = $2`, id, s.orgID)
 	if err != nil {
 		return err
 	}
@@ -362,7 +362,7 @@ func (s *Store) ListAgents(ctx context.Context, accountID int64) ([]domain.Disco
 	}
 	query += " ORDER BY created_at DESC"
 
-	rows, err := s.pool.Query(ctx, query, args...)
+	rows, err := s.q().Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,166 @@
+//go:build postgres
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+var _ storage.DiscoveryScheduleStore = (*Store)(nil)
+
+const discoveryScheduleColumns = `account_id, enabled, sync_interval, cron_expr, detect_drift, next_run_at,
+	last_run_at, last_status, last_error, last_sync_job_id::text, created_at, updated_at`
+
+// GetDiscoverySchedule returns the schedule for an account.
+func (s *Store) GetDiscoverySchedule(ctx context.Context, accountID int64) (*domain.DiscoverySchedule, error) {
+	row := s.q().QueryRow(ctx,
+		`SELECT `+discoveryScheduleColumns+` FROM discovery_schedules WHERE account_id = $1 AND organization_id = $2`,
+		accountID, s.orgID,
+	)
+	sc, err := scanDiscoverySchedule(row)
+	if err == pgx.ErrNoRows {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sc, nil
+}
+
+// ListDiscoverySchedules returns all schedules ordered by account ID.
+func (s *Store) ListDiscoverySchedules(ctx context.Context) ([]domain.DiscoverySchedule, error) {
+	return s.queryDiscoverySchedules(ctx,
+		`SELECT `+discoveryScheduleColumns+` FROM discovery_schedules WHERE organization_id = $1 ORDER BY account_id`,
+		s.orgID,
+	)
+}
+
+// UpsertDiscoverySchedule creates or replaces a schedule's configuration.
+func (s *Store) UpsertDiscoverySchedule(ctx context.Context, sc domain.DiscoverySchedule) error {
+	cmd, err := s.q().Exec(ctx,
+		`INSERT INTO discovery_schedules (account_id, organization_id, enabled, sync_interval, cron_expr, detect_drift,
+			next_run_at, created_at, updated_at)
+		 SELECT $1, $2, $3, $4, $5, $6, $7, $8, $9
+		 WHERE EXISTS (SELECT 1 FROM accounts WHERE seq_id = $1 AND organization_id = $2 AND deleted_at IS NULL)
+		 ON CONFLICT (account_id) DO UPDATE SET
+			enabled = EXCLUDED.enabled,
+			sync_interval = EXCLUDED.sync_interval,
+			cron_expr = EXCLUDED.cron_expr,
+			detect_drift = EXCLUDED.detect_drift,
+			next_run_at = EXCLUDED.next_run_at,
+			updated_at = EXCLUDED.updated_at`,
+		sc.AccountID, s.orgID, sc.Enabled, nilStringIfEmpty(sc.Interval), nilStringIfEmpty(sc.Cron), sc.DetectDrift,
+		sc.NextRunAt, sc.CreatedAt, sc.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return fmt.Errorf("account %d: %w", sc.AccountID, storage.ErrNotFound)
+	}
+	return nil
+}
+
+// DeleteDiscoverySchedule removes an account's schedule.
+func (s *Store) DeleteDiscoverySchedule(ctx context.Context, accountID int64) error {
+	cmd, err := s.q().Exec(ctx,
+		`DELETE FROM discovery_schedules WHERE account_id = $1 AND organization_id = $2`, accountID, s.orgID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// ListDueDiscoverySchedules returns enabled schedules due at or before now.
+func (s *Store) ListDueDiscoverySchedules(ctx context.Context, now time.Time) ([]domain.DiscoverySchedule, error) {
+	return s.queryDiscoverySchedules(ctx,
+		`SELECT `+discoveryScheduleColumns+` FROM discovery_schedules
+		 WHERE organization_id = $1 AND enabled AND next_run_at IS NOT NULL AND next_run_at <= $2
+		 ORDER BY next_run_at, account_id`,
+		s.orgID, now,
+	)
+}
+
+// ClaimDiscoverySchedule advances next_run_at from due to next if it still equals due.
+func (s *Store) ClaimDiscoverySchedule(ctx context.Context, accountID int64, due, next time.Time) (bool, error) {
+	cmd, err := s.q().Exec(ctx,
+		`UPDATE discovery_schedules SET next_run_at = $1
+		 WHERE account_id = $2 AND organization_id = $3 AND enabled AND next_run_at = $4`,
+		next, accountID, s.orgID, due,
+	)
+	if err != nil {
+		return false, err
+	}
+	return cmd.RowsAffected() == 1, nil
+}
+
+// RecordDiscoveryScheduleRun stores the outcome of a run.
+func (s *Store) RecordDiscoveryScheduleRun(ctx context.Context, accountID int64, run domain.DiscoveryScheduleRun) error {
+	cmd, err := s.q().Exec(ctx,
+		`UPDATE discovery_schedules SET last_run_at = $1, last_status = $2, last_error = $3, last_sync_job_id = $4
+		 WHERE account_id = $5 AND organization_id = $6`,
+		run.At, string(run.Status), nilStringIfEmpty(run.Error), run.SyncJobID, accountID, s.orgID,
+	)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+func (s *Store) queryDiscoverySchedules(ctx context.Context, query string, args ...any) ([]domain.DiscoverySchedule, error) {
+	rows, err := s.q().Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []domain.DiscoverySchedule{}
+	for rows.Next() {
+		sc, err := scanDiscoverySchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, sc)
+	}
+	return out, rows.Err()
+}
+
+func scanDiscoverySchedule(row interface{ Scan(dest ...any) error }) (domain.DiscoverySchedule, error) {
+	var sc domain.DiscoverySchedule
+	var interval, cron, lastStatus, lastError, lastJobID *string
+	if err := row.Scan(&sc.AccountID, &sc.Enabled, &interval, &cron, &sc.DetectDrift, &sc.NextRunAt,
+		&sc.LastRunAt, &lastStatus, &lastError, &lastJobID, &sc.CreatedAt, &sc.UpdatedAt); err != nil {
+		return sc, err
+	}
+	if interval != nil {
+		sc.Interval = *interval
+	}
+	if cron != nil {
+		sc.Cron = *cron
+	}
+	if lastStatus != nil {
+		sc.LastStatus = domain.ScheduleRunStatus(*lastStatus)
+	}
+	if lastError != nil {
+		sc.LastError = *lastError
+	}
+	if lastJobID != nil {
+		if id, err := uuid.Parse(*lastJobID); err == nil {
+			sc.LastSyncJobID = &id
+		}
+	}
+	return sc, nil
+}
@@ -34,7 +34,7 @@ func (s *Store) CreateDriftItem(ctx context.Context, item domain.DriftItem) erro
 		}
 	}
 
-	_, err := s.pool.Exec(ctx,
+	_, err := s.q().Exec(ctx,
 		`INSERT INTO drift_items (
 			id, organization_id, account_id, resource_id, pool_id, type, severity, status,
 			title, description, resource_cidr, pool_cidr, details, ignore_reason,
@@ -51,7 +51,7 @@ func (s *Store) CreateDriftItem(ctx context.Context, item domain.DriftItem) erro
 
 // GetDriftItem returns a single drift item by ID.
 func (s *Store) GetDriftItem(ctx context.Context, id string) (*domain.DriftItem, error) {
-	row := s.pool.QueryRow(ctx,
+	row := s.q().QueryRow(ctx,
 		`SELECT id, account_id, resource_id, pool_id, type, severity, status, title,
 			description, resource_cidr, pool_cidr, details::text, ignore_reason,
 			resolved_at, detected_at, updated_at
@@ -86,7 +86,7 @@ func (s *Store) ListDriftItems(ctx context.Context, filters domain.DriftFilters)
 
 	whereClause := " WHERE " + strings.Join(where, " AND ")
 	var total int
-	if err := s.pool.QueryRow(ctx, "SELECT COUNT(*) FROM drift_items"+whereClause, args...).Scan(&total); err != nil {
+	if err := s.q().QueryRow(ctx, "SELECT COUNT(*) FROM drift_items"+whereClause, args...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
 
@@ -110,7 +110,7 @@ func (s *Store) ListDriftItems(ctx context.Context, filters domain.DriftFilters)
 		 LIMIT $%d OFFSET $%d`,
 		whereClause, len(queryArgs)-1, len(queryArgs),
 	)
-	rows, err := s.pool.Query(ctx, query, queryArgs...)
+	rows, err := s.q().Query(ctx, query, queryArgs...)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -134,7 +134,7 @@ func (s *Store) UpdateDriftStatus(ctx context.Context, id string, status domain.
 	if status == domain.DriftStatusResolved {
 		resolvedAt = &now
 	}
-	tag, err := s.pool.Exec(ctx,
+	tag, err := s.q().Exec(ctx,
 		`UPDATE drift_items
 		 SET status = $1, ignore_reason = $2, resolved_at = $3, updated_at = $4
 		 WHERE id = $5 AND organization_id = $6`,
@@ -166,7 +166,7 @@ func (s *Store) UpdateDriftDetails(ctx context.Context, id string, details map[s
 	if b, err := json.Marshal(merged); err == nil {
 		detailsJSON = string(b)
 	}
-	tag, err := s.pool.Exec(ctx,
+	tag, err := s.q().Exec(ctx,
 		`UPDATE drift_items SET details = $1::jsonb, updated_at = $2 WHERE id = $3 AND organization_id = $4`,
 		detailsJSON, time.Now().UTC(), id, s.orgID,
 	)
@@ -181,7 +181,7 @@ func (s *Store) UpdateDriftDetails(ctx context.Context, id string, details map[s
 
 // DeleteOpenForAccount removes all open drift items for an account.
 func (s *Store) DeleteOpenForAccount(ctx context.Context, accountID int64) error {
-	_, err := s.pool.Exec(ctx,
+	_, err := s.q().Exec(ctx,
 		`DELETE FROM drift_items WHERE organization_id = $1 AND account_id = $2 AND status = $3`,
 		s.orgID, accountID, string(domain.DriftStatusOpen),
 	)
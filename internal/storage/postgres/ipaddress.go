@@ -0,0 +1,296 @@
+//go:build postgres
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+var _ storage.IPAddressStore = (*Store)(nil)
+
+const ipAddressColumns = `id, pool_id, host(address), hostname, mac_address, owner, status, source, description,
+	resource_id, created_at, updated_at`
+
+// CreateIPAddress stores a new record in a live pool.
+func (s *Store) CreateIPAddress(ctx context.Context, a domain.IPAddress) error {
+	return s.createIPAddress(ctx, s.q(), a)
+}
+
+func (s *Store) createIPAddress(ctx context.Context, q querier, a domain.IPAddress) error {
+	cmd, err := q.Exec(ctx,
+		`INSERT INTO ip_addresses (id, organization_id, pool_id, address, hostname, mac_address, owner, status, source,
+			description, resource_id, created_at, updated_at)
+		 SELECT $1, $2, $3, $4::inet, $5, $6, $7, $8, $9, $10, $11, $12, $13
+		 WHERE EXISTS (SELECT 1 FROM pools WHERE seq_id = $3 AND organization_id = $2 AND deleted_at IS NULL)`,
+		a.ID, s.orgID, a.PoolID, a.Address, nilStringIfEmpty(a.Hostname), nilStringIfEmpty(a.MACAddress),
+		nilStringIfEmpty(a.Owner), string(a.Status), string(a.Source), nilStringIfEmpty(a.Description),
+		a.ResourceID, a.CreatedAt, a.UpdatedAt,
+	)
+	if err != nil {
+		if strings.Contains(err.Error(), "23503") {
+			return fmt.Errorf("resource %v: %w", a.ResourceID, storage.ErrNotFound)
+		}
+		return storage.WrapIfConflict(err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return fmt.Errorf("pool %d: %w", a.PoolID, storage.ErrNotFound)
+	}
+	return nil
+}
+
+// GetIPAddress returns a record by ID.
+func (s *Store) GetIPAddress(ctx context.Context, id string) (*domain.IPAddress, error) {
+	row := s.q().QueryRow(ctx,
+		`SELECT `+ipAddressColumns+` FROM ip_addresses WHERE id = $1 AND organization_id = $2`,
+		id, s.orgID,
+	)
+	a, err := scanIPAddress(row)
+	if err == pgx.ErrNoRows {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// ListIPAddresses returns paginated records ordered by pool and address.
+func (s *Store) ListIPAddresses(ctx context.Context, filters domain.IPAddressFilters) ([]domain.IPAddress, int, error) {
+	// Records stay with soft-deleted pools but are no longer listed.
+	where := []string{"organization_id = $1",
+		"pool_id IN (SELECT seq_id FROM pools WHERE organization_id = $1 AND deleted_at IS NULL)"}
+	args := []any{s.orgID}
+	addArg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if filters.PoolID != 0 {
+		where = append(where, "pool_id = "+addArg(filters.PoolID))
+	}
+	if filters.Status != "" {
+		where = append(where, "status = "+addArg(filters.Status))
+	}
+	if filters.Source != "" {
+		where = append(where, "source = "+addArg(filters.Source))
+	}
+	if filters.Query != "" {
+		like := addArg("%" + filters.Query + "%")
+		where = append(where, "(host(address) ILIKE "+like+" OR hostname ILIKE "+like+
+			" OR mac_address ILIKE "+like+" OR owner ILIKE "+like+")")
+	}
+	whereClause := " WHERE " + strings.Join(where, " AND ")
+
+	var total int
+	if err := s.q().QueryRow(ctx, "SELECT COUNT(*) FROM ip_addresses"+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	page := filters.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := filters.PageSize
+	if pageSize < 1 {
+		pageSize = 50
+	}
+	limit := addArg(pageSize)
+	offset := addArg((page - 1) * pageSize)
+
+	rows, err := s.q().Query(ctx,
+		`SELECT `+ipAddressColumns+` FROM ip_addresses`+whereClause+
+			` ORDER BY pool_id, address LIMIT `+limit+` OFFSET `+offset,
+		args...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	out := []domain.IPAddress{}
+	for rows.Next() {
+		a, err := scanIPAddress(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		out = append(out, a)
+	}
+	return out, total, rows.Err()
+}
+
+// UpdateIPAddress replaces a record's mutable fields.
+func (s *Store) UpdateIPAddress(ctx context.Context, a domain.IPAddress) error {
+	cmd, err := s.q().Exec(ctx,
+		`UPDATE ip_addresses
+		    SET hostname = $1, mac_address = $2, owner = $3, status = $4, source = $5, description = $6,
+		        resource_id = $7, updated_at = $8
+		  WHERE id = $9 AND organization_id = $10`,
+		nilStringIfEmpty(a.Hostname), nilStringIfEmpty(a.MACAddress), nilStringIfEmpty(a.Owner), string(a.Status),
+		string(a.Source), nilStringIfEmpty(a.Description), a.ResourceID, a.UpdatedAt, a.ID, s.orgID,
+	)
+	if err != nil {
+		if strings.Contains(err.Error(), "23503") {
+			return fmt.Errorf("resource %v: %w", a.ResourceID, storage.ErrNotFound)
+		}
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteIPAddress removes a record.
+func (s *Store) DeleteIPAddress(ctx context.Context, id string) error {
+	cmd, err := s.q().Exec(ctx, `DELETE FROM ip_addresses WHERE id = $1 AND organization_id = $2`, id, s.orgID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// AllocateIPAddress picks and inserts a record in a single transaction. The
+// pool row is locked FOR UPDATE, which serializes allocators on the same pool
+// without blocking readers.
+func (s *Store) AllocateIPAddress(ctx context.Context, poolID int64, pick storage.IPAddressPickFunc) (domain.IPAddress, error) {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return domain.IPAddress{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := fmt.Sprintf(`
+		SELECT p.%s
+		FROM pools p
+		WHERE p.seq_id = $1 AND p.organization_id = $2 AND p.deleted_at IS NULL
+		FOR UPDATE OF p`, poolColumnsWithParentAccount())
+	pool, ok, err := s.scanPool(tx.QueryRow(ctx, query, poolID, s.orgID))
+	if err != nil {
+		return domain.IPAddress{}, err
+	}
+	if !ok {
+		return domain.IPAddress{}, fmt.Errorf("pool not found: %w", storage.ErrNotFound)
+	}
+	children, err := s.poolChildren(ctx, tx, poolID)
+	if err != nil {
+		return domain.IPAddress{}, err
+	}
+	existing, err := s.poolIPAddresses(ctx, tx, poolID)
+	if err != nil {
+		return domain.IPAddress{}, err
+	}
+
+	a, err := pick(pool, children, existing)
+	if err != nil {
+		return domain.IPAddress{}, err
+	}
+	a.PoolID = poolID
+	if err := s.createIPAddress(ctx, tx, a); err != nil {
+		return domain.IPAddress{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return domain.IPAddress{}, err
+	}
+	return a, nil
+}
+
+func (s *Store) poolIPAddresses(ctx context.Context, q querier, poolID int64) ([]domain.IPAddress, error) {
+	rows, err := q.Query(ctx,
+		`SELECT `+ipAddressColumns+` FROM ip_addresses WHERE pool_id = $1 AND organization_id = $2 ORDER BY address`,
+		poolID, s.orgID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []domain.IPAddress
+	for rows.Next() {
+		a, err := scanIPAddress(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// poolAddresses returns the recorded addresses of one pool for stats
+// calculation.
+func (s *Store) poolAddresses(ctx context.Context, poolID int64) ([]string, error) {
+	rows, err := s.q().Query(ctx,
+		`SELECT host(address) FROM ip_addresses WHERE organization_id = $1 AND pool_id = $2`, s.orgID, poolID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var addr string
+		if err := rows.Scan(&addr); err != nil {
+			return nil, err
+		}
+		out = append(out, addr)
+	}
+	return out, rows.Err()
+}
+
+// addressesByPool returns the recorded addresses of the given pools, keyed
+// by pool ID, for stats calculation.
+func (s *Store) addressesByPool(ctx context.Context, poolIDs []int64) (map[int64][]string, error) {
+	out := make(map[int64][]string)
+	if len(poolIDs) == 0 {
+		return out, nil
+	}
+	rows, err := s.q().Query(ctx,
+		`SELECT pool_id, host(address) FROM ip_addresses WHERE organization_id = $1 AND pool_id = ANY($2)`,
+		s.orgID, poolIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var poolID int64
+		var addr string
+		if err := rows.Scan(&poolID, &addr); err != nil {
+			return nil, err
+		}
+		out[poolID] = append(out[poolID], addr)
+	}
+	return out, rows.Err()
+}
+
+func scanIPAddress(row interface{ Scan(dest ...any) error }) (domain.IPAddress, error) {
+	var a domain.IPAddress
+	var status, source string
+	var hostname, mac, owner, description *string
+	var resourceID *uuid.UUID
+	if err := row.Scan(&a.ID, &a.PoolID, &a.Address, &hostname, &mac, &owner, &status, &source, &description,
+		&resourceID, &a.CreatedAt, &a.UpdatedAt); err != nil {
+		return a, err
+	}
+	if hostname != nil {
+		a.Hostname = *hostname
+	}
+	if mac != nil {
+		a.MACAddress = *mac
+	}
+	if owner != nil {
+		a.Owner = *owner
+	}
+	if description != nil {
+		a.Description = *description
+	}
+	a.Status = domain.IPAddressStatus(status)
+	a.Source = domain.PoolSource(source)
+	a.ResourceID = resourceID
+	return a, nil
+}
@@ -52,7 +52,7 @@ func (s *Store) ListNetworkObjects(ctx context.Context, filters domain.NetworkOb
 		q := "%" + strings.ToLower(filters.Query) + "%"
 		where = append(where, fmt.Sprintf("(LOWER(name) LIKE %s OR LOWER(cidr) LIKE %s OR LOWER(ip_address) LIKE %s OR LOWER(provider_resource_id) LIKE %s)", addArg(q), addArg(q), addArg(q), addArg(q)))
 	}
-	rows, err := s.pool.Query(ctx, `SELECT id, object_type, provider, account_id, region, name, cidr, ip_address, provider_resource_id, parent_object_id, pool_id, source_discovered_id, state, metadata, created_at, updated_at
+	rows, err := s.q().Query(ctx, `SELECT id, object_type, provider, account_id, region, name, cidr, ip_address, provider_resource_id, parent_object_id, pool_id, source_discovered_id, state, metadata, created_at, updated_at
 		FROM network_objects WHERE `+strings.Join(where, " AND ")+` ORDER BY account_id ASC, region ASC, name ASC`, args...)
 	if err != nil {
 		return nil, err
@@ -74,7 +74,7 @@ func (s *Store) ListNetworkObjects(ctx context.Context, filters domain.NetworkOb
 }
 
 func (s *Store) GetNetworkObject(ctx context.Context, id int64) (domain.NetworkObject, bool, error) {
-	row := s.pool.QueryRow(ctx, `SELECT id, object_type, provider, account_id, region, name, cidr, ip_address, provider_resource_id, parent_object_id, pool_id, source_discovered_id, state, metadata, created_at, updated_at
+	row := s.q().QueryRow(ctx, `SELECT id, object_type, provider, account_id, region, name, cidr, ip_address, provider_resource_id, parent_object_id, pool_id, source_discovered_id, state, metadata, created_at, updated_at
 		FROM network_objects WHERE id = $1 AND organization_id = $2`, id, s.orgID)
 	obj, err := scanPostgresNetworkObject(row)
 	if err != nil {
@@ -110,7 +110,7 @@ func (s *Store) CreateNetworkObject(ctx context.Context, in domain.CreateNetwork
 	}
 	now := time.Now().UTC()
 	var id int64
-	err := s.pool.QueryRow(ctx, `INSERT INTO network_objects
+	err := s.q().QueryRow(ctx, `INSERT INTO network_objects
 		(organization_id, object_type, provider, account_id, region, name, cidr, ip_address, provider_resource_id, parent_object_id, pool_id, source_discovered_id, state, metadata, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14::jsonb, $15, $16)
 		RETURNING id`,
@@ -174,7 +174,7 @@ func (s *Store) UpdateNetworkObject(ctx context.Context, id int64, update domain
 	if obj.Metadata == nil {
 		metadata = []byte("{}")
 	}
-	tag, err := s.pool.Exec(ctx, `UPDATE network_objects SET
+	tag, err := s.q().Exec(ctx, `UPDATE network_objects SET
 		object_type = $1, provider = $2, account_id = $3, region = $4, name = $5, cidr = $6, ip_address = $7, provider_resource_id = $8,
 		parent_object_id = $9, pool_id = $10, source_discovered_id = $11, state = $12, metadata = $13::jsonb, updated_at = $14
 		WHERE id = $15 AND organization_id = $16`,
@@ -227,7 +227,7 @@ func (s *Store) ListNetworkRelationships(ctx context.Context, filters domain.Net
 	if filters.ResolutionState != "" {
 		where = append(where, "resolution_state = "+addArg(filters.ResolutionState))
 	}
-	rows, err := s.pool.Query(ctx, `SELECT id, type, source_kind, source_id, target_kind, target_id, confidence, reason, evidence, resolution_state, created_at, updated_at
+	rows, err := s.q().Query(ctx, `SELECT id, type, source_kind, source_id, target_kind, target_id, confidence, reason, evidence, resolution_state, created_at, updated_at
 		FROM network_relationships WHERE `+strings.Join(where, " AND ")+` ORDER BY id ASC`, args...)
 	if err != nil {
 		return nil, err
@@ -264,7 +264,7 @@ func (s *Store) UpsertNetworkRelationship(ctx context.Context, in domain.CreateN
 	}
 	evidence, _ := json.Marshal(in.Evidence)
 	now := time.Now().UTC()
-	_, err := s.pool.Exec(ctx, `INSERT INTO network_relationships
+	_, err := s.q().Exec(ctx, `INSERT INTO network_relationships
 		(id, organization_id, type, source_kind, source_id, target_kind, target_id, confidence, reason, evidence, resolution_state, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10::jsonb, $11, $12, $13)
 		ON CONFLICT(organization_id, id) DO UPDATE SET
@@ -298,7 +298,7 @@ func (s *Store) UpdateNetworkRelationshipState(ctx context.Context, id string, s
 	if strings.TrimSpace(state) == "" {
 		return domain.NetworkRelationship{}, false, fmt.Errorf("resolution_state is required: %w", storage.ErrValidation)
 	}
-	tag, err := s.pool.Exec(ctx, `UPDATE network_relationships
+	tag, err := s.q().Exec(ctx, `UPDATE network_relationships
 		SET resolution_state = $1, reason = CASE WHEN $2 = '' THEN reason ELSE $2 END, updated_at = $3
 		WHERE id = $4 AND organization_id = $5`, state, reason, time.Now().UTC(), id, s.orgID)
 	if err != nil {
@@ -327,14 +327,14 @@ func generatedNetworkRelationshipID(in domain.CreateNetworkRelationship) string
 
 func (s *Store) validateNetworkObjectRefs(ctx context.Context, accountID int64, parentObjectID *int64, poolID *int64, sourceDiscoveredID *uuid.UUID) error {
 	var exists bool
-	if err := s.pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM accounts WHERE seq_id = $1 AND organization_id = $2 AND deleted_at IS NULL)`, accountID, s.orgID).Scan(&exists); err != nil {
+	if err := s.q().QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM accounts WHERE seq_id = $1 AND organization_id = $2 AND deleted_at IS NULL)`, accountID, s.orgID).Scan(&exists); err != nil {
 		return err
 	}
 	if !exists {
 		return fmt.Errorf("account not found: %w", storage.ErrNotFound)
 	}
 	if parentObjectID != nil {
-		if err := s.pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM network_objects WHERE id = $1 AND organization_id = $2)`, *parentObjectID, s.orgID).Scan(&exists); err != nil {
+		if err := s.q().QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM network_objects WHERE id = $1 AND organization_id = $2)`, *parentObjectID, s.orgID).Scan(&exists); err != nil {
 			return err
 		}
 		if !exists {
@@ -342,7 +342,7 @@ func (s *Store) validateNetworkObjectRefs(ctx context.Context, accountID int64,
 		}
 	}
 	if poolID != nil {
-		if err := s.pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM pools WHERE seq_id = $1 AND organization_id = $2 AND deleted_at IS NULL)`, *poolID, s.orgID).Scan(&exists); err != nil {
+		if err := s.q().QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM pools WHERE seq_id = $1 AND organization_id = $2 AND deleted_at IS NULL)`, *poolID, s.orgID).Scan(&exists); err != nil {
 			return err
 		}
 		if !exists {
@@ -350,7 +350,7 @@ func (s *Store) validateNetworkObjectRefs(ctx context.Context, accountID int64,
 		}
 	}
 	if sourceDiscoveredID != nil {
-		if err := s.pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM discovered_resources WHERE id = $1 AND organization_id = $2)`, *sourceDiscoveredID, s.orgID).Scan(&exists); err != nil {
+		if err := s.q().QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM discovered_resources WHERE id = $1 AND organization_id = $2)`, *sourceDiscoveredID, s.orgID).Scan(&exists); err != nil {
 			return err
 		}
 		if !exists {
@@ -18,14 +18,18 @@ func (s *Store) CreateProvider(ctx context.Context, p *domain.OIDCProvider) erro
 	if err != nil {
 		return err
 	}
+	roleRules, err := json.Marshal(p.RoleRules)
+	if err != nil {
+		return err
+	}
 
-	_, err = s.pool.Exec(ctx,
+	_, err = s.q().Exec(ctx,
 		`INSERT INTO oidc_providers (
 			id, name, issuer_url, client_id, client_secret_encrypted, scopes,
-			role_mapping, default_role, auto_provision, enabled, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+			role_mapping, role_rules, default_role, auto_provision, enabled, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
 		p.ID, p.Name, p.IssuerURL, p.ClientID, p.ClientSecretEncrypted,
-		p.Scopes, string(roleMapping), p.DefaultRole, p.AutoProvision,
+		p.Scopes, string(roleMapping), string(roleRules), p.DefaultRole, p.AutoProvision,
 		p.Enabled, p.CreatedAt, p.UpdatedAt,
 	)
 	if isUniqueViolation(err) {
@@ -36,9 +40,9 @@ func (s *Store) CreateProvider(ctx context.Context, p *domain.OIDCProvider) erro
 
 // GetProvider retrieves an OIDC provider by ID.
 func (s *Store) GetProvider(ctx context.Context, id string) (*domain.OIDCProvider, error) {
-	row := s.pool.QueryRow(ctx,
+	row := s.q().QueryRow(ctx,
 		`SELECT id, name, issuer_url, client_id, client_secret_encrypted, scopes,
-		        role_mapping, default_role, auto_provision, enabled, created_at, updated_at
+		        role_mapping, role_rules, default_role, auto_provision, enabled, created_at, updated_at
 		   FROM oidc_providers
 		  WHERE id = $1`,
 		id,
@@ -48,9 +52,9 @@ func (s *Store) GetProvider(ctx context.Context, id string) (*domain.OIDCProvide
 
 // GetProviderByIssuer retrieves an OIDC provider by issuer URL.
 func (s *Store) GetProviderByIssuer(ctx context.Context, issuerURL string) (*domain.OIDCProvider, error) {
-	row := s.pool.QueryRow(ctx,
+	row := s.q().QueryRow(ctx,
 		`SELECT id, name, issuer_url, client_id, client_secret_encrypted, scopes,
-		        role_mapping, default_role, auto_provision, enabled, created_at, updated_at
+		        role_mapping, role_rules, default_role, auto_provision, enabled, created_at, updated_at
 		   FROM oidc_providers
 		  WHERE issuer_url = $1`,
 		issuerURL,
@@ -60,9 +64,9 @@ func (s *Store) GetProviderByIssuer(ctx context.Context, issuerURL string) (*dom
 
 // ListProviders returns all configured OIDC providers.
 func (s *Store) ListProviders(ctx context.Context) ([]*domain.OIDCProvider, error) {
-	rows, err := s.pool.Query(ctx,
+	rows, err := s.q().Query(ctx,
 		`SELECT id, name, issuer_url, client_id, client_secret_encrypted, scopes,
-		        role_mapping, default_role, auto_provision, enabled, created_at, updated_at
+		        role_mapping, role_rules, default_role, auto_provision, enabled, created_at, updated_at
 		   FROM oidc_providers
 		  ORDER BY name`,
 	)
@@ -76,9 +80,9 @@ func (s *Store) ListProviders(ctx context.Context) ([]*domain.OIDCProvider, erro
 
 // ListEnabledProviders returns only enabled OIDC providers.
 func (s *Store) ListEnabledProviders(ctx context.Context) ([]*domain.OIDCProvider, error) {
-	rows, err := s.pool.Query(ctx,
+	rows, err := s.q().Query(ctx,
 		`SELECT id, name, issuer_url, client_id, client_secret_encrypted, scopes,
-		        role_mapping, default_role, auto_provision, enabled, created_at, updated_at
+		        role_mapping, role_rules, default_role, auto_provision, enabled, created_at, updated_at
 		   FROM oidc_providers
 		  WHERE enabled = TRUE
 		  ORDER BY name`,
@@ -97,8 +101,12 @@ func (s *Store) UpdateProvider(ctx context.Context, p *domain.OIDCProvider) erro
 	if err != nil {
 		return err
 	}
+	roleRules, err := json.Marshal(p.RoleRules)
+	if err != nil {
+		return err
+	}
 
-	cmd, err := s.pool.Exec(ctx,
+	cmd, err := s.q().Exec(ctx,
 		`UPDATE oidc_providers
 		    SET name = $1,
 		        issuer_url = $2,
@@ -106,13 +114,14 @@ func (s *Store) UpdateProvider(ctx context.Context, p *domain.OIDCProvider) erro
 		        client_secret_encrypted = $4,
 		        scopes = $5,
 		        role_mapping = $6,
-		        default_role = $7,
-		        auto_provision = $8,
-		        enabled = $9,
-		        updated_at = $10
-		  WHERE id = $11`,
+		        role_rules = $7,
+		        default_role = $8,
+		        auto_provision = $9,
+		        enabled = $10,
+		        updated_at = $11
+		  WHERE id = $12`,
 		p.Name, p.IssuerURL, p.ClientID, p.ClientSecretEncrypted,
-		p.Scopes, string(roleMapping), p.DefaultRole, p.AutoProvision,
+		p.Scopes, string(roleMapping), string(roleRules), p.DefaultRole, p.AutoProvision,
 		p.Enabled, p.UpdatedAt, p.ID,
 	)
 	if isUniqueViolation(err) {
@@ -129,7 +138,7 @@ func (s *Store) UpdateProvider(ctx context.Context, p *domain.OIDCProvider) erro
 
 // DeleteProvider removes an OIDC provider by ID.
 func (s *Store) DeleteProvider(ctx context.Context, id string) error {
-	cmd, err := s.pool.Exec(ctx, `DELETE FROM oidc_providers WHERE id = $1`, id)
+	cmd, err := s.q().Exec(ctx, `DELETE FROM oidc_providers WHERE id = $1`, id)
 	if err != nil {
 		return err
 	}
@@ -143,11 +152,11 @@ func scanProvider(row interface {
 	Scan(dest ...any) error
 }) (*domain.OIDCProvider, error) {
 	var p domain.OIDCProvider
-	var roleMappingJSON string
+	var roleMappingJSON, roleRulesJSON string
 
 	if err := row.Scan(
 		&p.ID, &p.Name, &p.IssuerURL, &p.ClientID, &p.ClientSecretEncrypted,
-		&p.Scopes, &roleMappingJSON, &p.DefaultRole, &p.AutoProvision,
+		&p.Scopes, &roleMappingJSON, &roleRulesJSON, &p.DefaultRole, &p.AutoProvision,
 		&p.Enabled, &p.CreatedAt, &p.UpdatedAt,
 	); err != nil {
 		if err == pgx.ErrNoRows {
@@ -158,6 +167,7 @@ func scanProvider(row interface {
 
 	p.RoleMapping = make(map[string]string)
 	_ = json.Unmarshal([]byte(roleMappingJSON), &p.RoleMapping)
+	_ = json.Unmarshal([]byte(roleRulesJSON), &p.RoleRules)
 	return &p, nil
 }
 
@@ -169,11 +179,11 @@ func scanProviderRows(rows interface {
 	var out []*domain.OIDCProvider
 	for rows.Next() {
 		var p domain.OIDCProvider
-		var roleMappingJSON string
+		var roleMappingJSON, roleRulesJSON string
 
 		if err := rows.Scan(
 			&p.ID, &p.Name, &p.IssuerURL, &p.ClientID, &p.ClientSecretEncrypted,
-			&p.Scopes, &roleMappingJSON, &p.DefaultRole, &p.AutoProvision,
+			&p.Scopes, &roleMappingJSON, &roleRulesJSON, &p.DefaultRole, &p.AutoProvision,
 			&p.Enabled, &p.CreatedAt, &p.UpdatedAt,
 		); err != nil {
 			return nil, err
@@ -181,6 +191,7 @@ func scanProviderRows(rows interface {
 
 		p.RoleMapping = make(map[string]string)
 		_ = json.Unmarshal([]byte(roleMappingJSON), &p.RoleMapping)
+		_ = json.Unmarshal([]byte(roleRulesJSON), &p.RoleRules)
 		out = append(out, &p)
 	}
 	if out == nil {
@@ -0,0 +1,190 @@
+//go:build postgres
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+var _ storage.PoolChangeRequestStore = (*Store)(nil)
+
+const poolChangeRequestColumns = `id, operation, status, pool_id, pool_version, pool_name, COALESCE(cidr::text, ''),
+	parent_id, payload::text, delete_cascade, policy_ids::text, requested_by, requested_by_id, requested_role,
+	reviewed_by, reviewed_by_id, reviewed_role, review_comment, created_at, reviewed_at`
+
+// poolChangePayload is the payload column: the create or update body, or
+// the CIDR a resize moves the pool to.
+type poolChangePayload struct {
+	Create *domain.CreatePool `json:"create,omitempty"`
+	Update *domain.UpdatePool `json:"update,omitempty"`
+	Resize string             `json:"resize,omitempty"`
+}
+
+// CreatePoolChangeRequest stores a new request. Creates that hold a CIDR
+// take a per-organization advisory lock first, so two overlapping holds
+// cannot both pass the check.
+func (s *Store) CreatePoolChangeRequest(ctx context.Context, r domain.PoolChangeRequest) error {
+	payload, err := json.Marshal(poolChangePayload{Create: r.Create, Update: r.Update, Resize: r.Resize})
+	if err != nil {
+		return err
+	}
+	if r.PolicyIDs == nil {
+		r.PolicyIDs = []string{}
+	}
+	policyIDs, err := json.Marshal(r.PolicyIDs)
+	if err != nil {
+		return err
+	}
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var cidr *string
+	if r.HoldsCIDR() {
+		cidr = &r.CIDR
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('cloudpam_pool_holds'), hashtext($1))`, s.orgID); err != nil {
+			return err
+		}
+		var heldID, heldCIDR string
+		err := tx.QueryRow(ctx,
+			`SELECT id, cidr::text FROM pool_change_requests
+			 WHERE organization_id = $1 AND status = $2 AND operation = $3
+			   AND parent_id IS NOT DISTINCT FROM $4 AND cidr && $5::cidr
+			 LIMIT 1`,
+			s.orgID, string(domain.PoolChangeRequestPending), string(domain.PoolChangeCreate), r.ParentID, r.CIDR,
+		).Scan(&heldID, &heldCIDR)
+		if err == nil {
+			return fmt.Errorf("%s is held by change request %s (%s): %w", r.CIDR, heldID, heldCIDR, storage.ErrConflict)
+		}
+		if err != pgx.ErrNoRows {
+			return err
+		}
+	}
+	_, err = tx.Exec(ctx,
+		`INSERT INTO pool_change_requests (id, organization_id, operation, status, pool_id, pool_version, pool_name,
+		     cidr, parent_id, payload, delete_cascade, policy_ids, requested_by, requested_by_id, requested_role,
+		     reviewed_by, reviewed_by_id, reviewed_role, review_comment, created_at, reviewed_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8::cidr, $9, $10::jsonb, $11, $12::jsonb, $13, $14, $15, $16, $17, $18,
+		     $19, $20, $21)`,
+		r.ID, s.orgID, string(r.Operation), string(r.Status), r.PoolID, r.PoolVersion, r.PoolName, cidr, r.ParentID,
+		string(payload), r.Cascade, string(policyIDs), r.RequestedBy, r.RequestedByID, r.RequestedRole,
+		nilStringIfEmpty(r.ReviewedBy), nilStringIfEmpty(r.ReviewedByID), nilStringIfEmpty(r.ReviewedRole), nilStringIfEmpty(r.ReviewComment), r.CreatedAt, r.ReviewedAt,
+	)
+	if err != nil {
+		return storage.WrapIfConflict(err)
+	}
+	return tx.Commit(ctx)
+}
+
+// GetPoolChangeRequest returns a request by ID.
+func (s *Store) GetPoolChangeRequest(ctx context.Context, id string) (*domain.PoolChangeRequest, error) {
+	row := s.q().QueryRow(ctx,
+		`SELECT `+poolChangeRequestColumns+` FROM pool_change_requests WHERE id = $1 AND organization_id = $2`,
+		id, s.orgID,
+	)
+	r, err := scanPoolChangeRequest(row)
+	if err == pgx.ErrNoRows {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// ListPoolChangeRequests returns requests, newest first.
+func (s *Store) ListPoolChangeRequests(ctx context.Context, status domain.PoolChangeRequestStatus) ([]domain.PoolChangeRequest, error) {
+	query := `SELECT ` + poolChangeRequestColumns + ` FROM pool_change_requests WHERE organization_id = $1`
+	args := []any{s.orgID}
+	if status != "" {
+		query += ` AND status = $2`
+		args = append(args, string(status))
+	}
+	rows, err := s.q().Query(ctx, query+` ORDER BY created_at DESC, id DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []domain.PoolChangeRequest{}
+	for rows.Next() {
+		r, err := scanPoolChangeRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// ReviewPoolChangeRequest records the decision on a pending request.
+func (s *Store) ReviewPoolChangeRequest(ctx context.Context, r domain.PoolChangeRequest) error {
+	cmd, err := s.q().Exec(ctx,
+		`UPDATE pool_change_requests
+		 SET status = $1, pool_id = $2, reviewed_by = $3, reviewed_by_id = $4, reviewed_role = $5, review_comment = $6,
+		     reviewed_at = $7
+		 WHERE id = $8 AND organization_id = $9 AND status = $10`,
+		string(r.Status), r.PoolID, nilStringIfEmpty(r.ReviewedBy), nilStringIfEmpty(r.ReviewedByID),
+		nilStringIfEmpty(r.ReviewedRole), nilStringIfEmpty(r.ReviewComment), r.ReviewedAt, r.ID, s.orgID,
+		string(domain.PoolChangeRequestPending),
+	)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() > 0 {
+		return nil
+	}
+	var status string
+	err = s.q().QueryRow(ctx,
+		`SELECT status FROM pool_change_requests WHERE id = $1 AND organization_id = $2`, r.ID, s.orgID,
+	).Scan(&status)
+	if err == pgx.ErrNoRows {
+		return storage.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("change request %s is %s: %w", r.ID, status, storage.ErrConflict)
+}
+
+func scanPoolChangeRequest(row interface{ Scan(dest ...any) error }) (domain.PoolChangeRequest, error) {
+	var r domain.PoolChangeRequest
+	var operation, status, payload, policyIDs string
+	var reviewedBy, reviewedByID, reviewedRole, reviewComment *string
+	if err := row.Scan(&r.ID, &operation, &status, &r.PoolID, &r.PoolVersion, &r.PoolName, &r.CIDR, &r.ParentID,
+		&payload, &r.Cascade, &policyIDs, &r.RequestedBy, &r.RequestedByID, &r.RequestedRole, &reviewedBy,
+		&reviewedByID, &reviewedRole, &reviewComment, &r.CreatedAt, &r.ReviewedAt); err != nil {
+		return r, err
+	}
+	r.Operation = domain.PoolChangeOperation(operation)
+	r.Status = domain.PoolChangeRequestStatus(status)
+	var body poolChangePayload
+	if err := json.Unmarshal([]byte(payload), &body); err != nil {
+		return r, err
+	}
+	r.Create, r.Update, r.Resize = body.Create, body.Update, body.Resize
+	if err := json.Unmarshal([]byte(policyIDs), &r.PolicyIDs); err != nil {
+		return r, err
+	}
+	if reviewedBy != nil {
+		r.ReviewedBy = *reviewedBy
+	}
+	if reviewedByID != nil {
+		r.ReviewedByID = *reviewedByID
+	}
+	if reviewedRole != nil {
+		r.ReviewedRole = *reviewedRole
+	}
+	if reviewComment != nil {
+		r.ReviewComment = *reviewComment
+	}
+	return r, nil
+}
@@ -907,6 +907,111 @@ func TestGetPoolChildren(t *testing.T) {
 	})
 }
 
+func TestAllocatePool(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	s := testDB.store
+
+	parent, _ := s.CreatePool(ctx, domain.CreatePool{Name: "parent", CIDR: "10.0.0.0/16"})
+	_, _ = s.CreatePool(ctx, domain.CreatePool{Name: "existing", CIDR: "10.0.0.0/24", ParentID: &parent.ID})
+
+	p, err := s.AllocatePool(ctx, parent.ID, func(par domain.Pool, children []domain.Pool) (domain.CreatePool, error) {
+		if par.CIDR != "10.0.0.0/16" {
+			t.Errorf("expected parent cidr 10.0.0.0/16, got %s", par.CIDR)
+		}
+		if len(children) != 1 {
+			t.Errorf("expected 1 child, got %d", len(children))
+		}
+		return domain.CreatePool{Name: "allocated", CIDR: "10.0.1.0/24", Tags: map[string]string{"ci": "true"}}, nil
+	})
+	if err != nil {
+		t.Fatalf("AllocatePool failed: %v", err)
+	}
+	if p.ParentID == nil || *p.ParentID != parent.ID {
+		t.Errorf("expected parent %d, got %v", parent.ID, p.ParentID)
+	}
+	if p.CIDR != "10.0.1.0/24" || p.Tags["ci"] != "true" {
+		t.Errorf("unexpected pool: %+v", p)
+	}
+
+	t.Run("pick error rolls back", func(t *testing.T) {
+		sentinel := errors.New("no room")
+		_, err := s.AllocatePool(ctx, parent.ID, func(domain.Pool, []domain.Pool) (domain.CreatePool, error) {
+			return domain.CreatePool{}, sentinel
+		})
+		if !errors.Is(err, sentinel) {
+			t.Fatalf("expected sentinel error, got %v", err)
+		}
+		children, _ := s.GetPoolChildren(ctx, parent.ID)
+		if len(children) != 2 {
+			t.Errorf("expected 2 children after failed allocation, got %d", len(children))
+		}
+	})
+
+	t.Run("non-existent parent returns ErrNotFound", func(t *testing.T) {
+		_, err := s.AllocatePool(ctx, 99999, func(domain.Pool, []domain.Pool) (domain.CreatePool, error) {
+			return domain.CreatePool{Name: "x", CIDR: "10.9.0.0/24"}, nil
+		})
+		if !errors.Is(err, storage.ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+}
+
+func TestCIDROperations(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	s := testDB.store
+
+	root, _ := s.CreatePool(ctx, domain.CreatePool{Name: "root", CIDR: "10.0.0.0/16"})
+	a, _ := s.CreatePool(ctx, domain.CreatePool{Name: "a", CIDR: "10.0.0.0/24", ParentID: &root.ID})
+	_, _ = s.CreatePool(ctx, domain.CreatePool{Name: "b", CIDR: "10.0.2.0/23", ParentID: &root.ID})
+	_, _ = s.CreatePool(ctx, domain.CreatePool{Name: "v6", CIDR: "2001:db8::/32"})
+
+	names := func(ps []*domain.Pool) string {
+		var out []string
+		for _, p := range ps {
+			out = append(out, p.Name)
+		}
+		return fmt.Sprint(out)
+	}
+
+	overlap, err := s.FindOverlapping(ctx, "10.0.0.0/22", nil)
+	if err != nil {
+		t.Fatalf("FindOverlapping: %v", err)
+	}
+	if got := names(overlap); got != "[root a b]" {
+		t.Errorf("FindOverlapping = %s", got)
+	}
+	top := int64(0)
+	if overlap, _ = s.FindOverlapping(ctx, "10.0.0.0/22", &top); names(overlap) != "[root]" {
+		t.Errorf("FindOverlapping(top-level) = %s", names(overlap))
+	}
+	if overlap, _ = s.FindOverlapping(ctx, "10.0.0.128/25", &root.ID); names(overlap) != "[a]" {
+		t.Errorf("FindOverlapping(children) = %s", names(overlap))
+	}
+	if containing, _ := s.FindContaining(ctx, "10.0.0.5"); names(containing) != "[root a]" {
+		t.Errorf("FindContaining = %s", names(containing))
+	}
+	if within, _ := s.FindContainedBy(ctx, "10.0.0.0/16"); names(within) != "[root a b]" {
+		t.Errorf("FindContainedBy = %s", names(within))
+	}
+	if _, err := s.FindContaining(ctx, "not-a-cidr"); !errors.Is(err, storage.ErrValidation) {
+		t.Errorf("expected ErrValidation, got %v", err)
+	}
+
+	gaps, err := s.FindGaps(ctx, root.ID, 23)
+	if err != nil {
+		t.Fatalf("FindGaps: %v", err)
+	}
+	if fmt.Sprint(gaps) != "[10.0.4.0/22 10.0.8.0/21 10.0.16.0/20 10.0.32.0/19 10.0.64.0/18 10.0.128.0/17]" {
+		t.Errorf("FindGaps = %v", gaps)
+	}
+	if _, err := s.FindGaps(ctx, a.ID+1000, 0); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
 func TestCalculatePoolUtilization(t *testing.T) {
 	resetDB(t)
 	ctx := context.Background()
@@ -2100,3 +2205,133 @@ func searchStr(s, substr string) bool {
 	}
 	return false
 }
+
+func TestQueryable(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	m := testDB.store
+	aws, _ := m.CreateAccount(ctx, domain.CreateAccount{Key: "aws:111", Name: "Prod", Provider: "aws", Environment: "prod", Regions: []string{"us-east-1"}})
+	_, _ = m.CreateAccount(ctx, domain.CreateAccount{Key: "aws:222", Name: "Dev", Provider: "aws", Environment: "dev"})
+	_, _ = m.CreateAccount(ctx, domain.CreateAccount{Key: "gcp:333", Name: "Prod GCP", Provider: "gcp", Environment: "prod"})
+
+	root, _ := m.CreatePool(ctx, domain.CreatePool{Name: "Root", CIDR: "10.0.0.0/8"})
+	mid, _ := m.CreatePool(ctx, domain.CreatePool{Name: "mid", CIDR: "10.1.0.0/16", ParentID: &root.ID, AccountID: &aws.ID})
+	_, _ = m.CreatePool(ctx, domain.CreatePool{Name: "leaf", CIDR: "10.1.2.0/24", ParentID: &mid.ID})
+	gone, _ := m.CreatePool(ctx, domain.CreatePool{Name: "gone", CIDR: "10.9.0.0/16", ParentID: &root.ID})
+	_, _ = m.CreatePool(ctx, domain.CreatePool{Name: "other-root", CIDR: "172.16.0.0/12"})
+	if _, err := m.DeletePool(ctx, gone.ID); err != nil {
+		t.Fatalf("DeletePool: %v", err)
+	}
+
+	names := func(ps []*domain.Pool) string {
+		var out []string
+		for _, p := range ps {
+			out = append(out, p.Name)
+		}
+		return fmt.Sprint(out)
+	}
+	zero := int64(0)
+	hourAgo, hourAhead := time.Now().Add(-time.Hour).Unix(), time.Now().Add(time.Hour).Unix()
+
+	cases := []struct {
+		name string
+		opts storage.PoolQueryOptions
+		want string
+	}{
+		{"all live", storage.PoolQueryOptions{}, "[Root mid leaf other-root]"},
+		{"roots", storage.PoolQueryOptions{ParentID: &zero}, "[Root other-root]"},
+		{"children", storage.PoolQueryOptions{ParentID: &root.ID}, "[mid]"},
+		{"descendants", storage.PoolQueryOptions{ParentID: &root.ID, IncludeChildren: true}, "[mid leaf]"},
+		{"account", storage.PoolQueryOptions{AccountID: &aws.ID}, "[mid]"},
+		{"unassigned", storage.PoolQueryOptions{AccountID: &zero}, "[Root leaf other-root]"},
+		{"cidr prefix", storage.PoolQueryOptions{CIDRPrefix: "10.1."}, "[mid leaf]"},
+		{"cidr contains", storage.PoolQueryOptions{CIDRContains: "10.1.2.7"}, "[Root mid leaf]"},
+		{"cidr within", storage.PoolQueryOptions{CIDRWithin: "10.1.0.0/16"}, "[mid leaf]"},
+		{"name", storage.PoolQueryOptions{NameContains: "ROOT"}, "[Root other-root]"},
+		{"order by cidr desc", storage.PoolQueryOptions{OrderBy: "cidr", OrderDesc: true}, "[other-root leaf mid Root]"},
+		{"page", storage.PoolQueryOptions{Limit: 2, Offset: 1}, "[mid leaf]"},
+		{"offset only", storage.PoolQueryOptions{Offset: 3}, "[other-root]"},
+		{"created window", storage.PoolQueryOptions{CreatedAfter: &hourAgo, CreatedBefore: &hourAhead, ParentID: &zero}, "[Root other-root]"},
+	}
+	for _, tc := range cases {
+		got, err := m.QueryPools(ctx, tc.opts)
+		if err != nil {
+			t.Fatalf("%s: QueryPools: %v", tc.name, err)
+		}
+		if names(got) != tc.want {
+			t.Errorf("%s: QueryPools = %s, want %s", tc.name, names(got), tc.want)
+		}
+	}
+
+	if n, err := m.CountPools(ctx, storage.PoolQueryOptions{CIDRWithin: "10.0.0.0/8", Limit: 1}); err != nil || n != 3 {
+		t.Errorf("CountPools = %d, %v; want 3", n, err)
+	}
+	if _, err := m.QueryPools(ctx, storage.PoolQueryOptions{OrderBy: "bogus"}); !errors.Is(err, storage.ErrValidation) {
+		t.Errorf("bad order_by: expected storage.ErrValidation, got %v", err)
+	}
+	if _, err := m.CountPools(ctx, storage.PoolQueryOptions{CIDRContains: "nope"}); !errors.Is(err, storage.ErrValidation) {
+		t.Errorf("bad cidr: expected storage.ErrValidation, got %v", err)
+	}
+
+	accts, err := m.QueryAccounts(ctx, storage.AccountQueryOptions{Environment: "prod", OrderBy: "key", OrderDesc: true})
+	if err != nil {
+		t.Fatalf("QueryAccounts: %v", err)
+	}
+	if len(accts) != 2 || accts[0].Key != "gcp:333" || accts[1].Key != "aws:111" {
+		t.Errorf("QueryAccounts(prod) = %+v", accts)
+	}
+	if got, _ := m.QueryAccounts(ctx, storage.AccountQueryOptions{Region: "us-east-1"}); len(got) != 1 || got[0].ID != aws.ID {
+		t.Errorf("QueryAccounts(region) = %+v", got)
+	}
+	if n, _ := m.CountAccounts(ctx, storage.AccountQueryOptions{KeyPrefix: "aws:"}); n != 2 {
+		t.Errorf("CountAccounts(aws:) = %d, want 2", n)
+	}
+	if _, err := m.QueryAccounts(ctx, storage.AccountQueryOptions{OrderBy: "cidr"}); !errors.Is(err, storage.ErrValidation) {
+		t.Errorf("bad order_by: expected storage.ErrValidation, got %v", err)
+	}
+}
+
+func TestVersioning(t *testing.T) {
+	resetDB(t)
+	ctx := context.Background()
+	m := testDB.store
+	p, _ := m.CreatePool(ctx, domain.CreatePool{Name: "p", CIDR: "10.0.0.0/16"})
+	if p.Version != 1 {
+		t.Fatalf("new pool version = %d, want 1", p.Version)
+	}
+	name := "renamed"
+	stale := p.Version
+	up, ok, err := m.UpdatePool(ctx, p.ID, domain.UpdatePool{Name: &name, IfVersion: &stale})
+	if err != nil || !ok || up.Version != 2 {
+		t.Fatalf("UpdatePool(IfVersion=1) = %+v, %v, %v; want version 2", up, ok, err)
+	}
+	if _, _, err := m.UpdatePool(ctx, p.ID, domain.UpdatePool{Name: &name, IfVersion: &stale}); !errors.Is(err, storage.ErrPreconditionFailed) {
+		t.Fatalf("stale IfVersion: expected ErrPreconditionFailed, got %v", err)
+	}
+	if up, _, err := m.UpdatePool(ctx, p.ID, domain.UpdatePool{Name: &name}); err != nil || up.Version != 3 {
+		t.Fatalf("unconditional UpdatePool = %+v, %v; want version 3", up, err)
+	}
+	if up, _, _ := m.UpdatePoolAccount(ctx, p.ID, nil); up.Version != 4 {
+		t.Errorf("UpdatePoolAccount version = %d, want 4", up.Version)
+	}
+
+	a, _ := m.CreateAccount(ctx, domain.CreateAccount{Key: "aws:1", Name: "a"})
+	if a.Version != 1 {
+		t.Fatalf("new account version = %d, want 1", a.Version)
+	}
+	upd := domain.Account{Name: "b", Version: 1}
+	ua, ok, err := m.UpdateAccount(ctx, a.ID, upd)
+	if err != nil || !ok || ua.Version != 2 || ua.Name != "b" {
+		t.Fatalf("UpdateAccount(Version=1) = %+v, %v, %v", ua, ok, err)
+	}
+	if _, _, err := m.UpdateAccount(ctx, a.ID, upd); !errors.Is(err, storage.ErrPreconditionFailed) {
+		t.Fatalf("stale account version: expected ErrPreconditionFailed, got %v", err)
+	}
+	upd.Version = 0
+	if ua, _, err := m.UpdateAccount(ctx, a.ID, upd); err != nil || ua.Version != 3 {
+		t.Fatalf("unconditional UpdateAccount = %+v, %v; want version 3", ua, err)
+	}
+	if _, ok, err := m.UpdateAccount(ctx, 9999, domain.Account{Name: "x", Version: 1}); ok || err != nil {
+		t.Errorf("missing account: ok=%v err=%v, want not found", ok, err)
+	}
+}
@@ -0,0 +1,240 @@
+//go:build postgres
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+var _ storage.Queryable = (*Store)(nil)
+
+// whereClause accumulates AND-ed SQL conditions with numbered placeholders.
+// $1 is always the organization id.
+type whereClause struct {
+	conds []string
+	args  []any
+}
+
+func newWhere(orgID string, alias string) *whereClause {
+	return &whereClause{
+		conds: []string{alias + `.organization_id = $1`, alias + `.deleted_at IS NULL`},
+		args:  []any{orgID},
+	}
+}
+
+// arg binds v and returns its placeholder.
+func (w *whereClause) arg(v any) string {
+	w.args = append(w.args, v)
+	return fmt.Sprintf("$%d", len(w.args))
+}
+
+func (w *whereClause) add(cond string) {
+	w.conds = append(w.conds, cond)
+}
+
+func (w *whereClause) String() string {
+	return strings.Join(w.conds, " AND ")
+}
+
+// createdRange adds the half-open [after, before) creation window.
+func (w *whereClause) createdRange(column string, after, before *int64) {
+	if after != nil {
+		w.add(column + ` >= to_timestamp(` + w.arg(*after) + `::bigint)`)
+	}
+	if before != nil {
+		w.add(column + ` < to_timestamp(` + w.arg(*before) + `::bigint)`)
+	}
+}
+
+// orderLimit renders ORDER BY, LIMIT and OFFSET, breaking ties by seq_id.
+func orderLimit(column, seq string, desc bool, limit, offset int) string {
+	dir := " ASC"
+	if desc {
+		dir = " DESC"
+	}
+	out := " ORDER BY " + column + dir
+	if column != seq {
+		out += ", " + seq + dir
+	}
+	if limit > 0 {
+		out += fmt.Sprintf(" LIMIT %d", limit)
+	}
+	if offset > 0 {
+		out += fmt.Sprintf(" OFFSET %d", offset)
+	}
+	return out
+}
+
+// poolOrderColumns maps PoolQueryOptions.OrderBy to columns. cidr sorts by
+// address and then prefix length.
+var poolOrderColumns = map[string]string{
+	"":           "p.seq_id",
+	"id":         "p.seq_id",
+	"name":       "p.name",
+	"cidr":       "p.cidr",
+	"created_at": "p.created_at",
+}
+
+func (s *Store) poolWhere(opts storage.PoolQueryOptions) *whereClause {
+	w := newWhere(s.orgID, "p")
+	if opts.ID != nil {
+		w.add(`p.seq_id = ` + w.arg(*opts.ID))
+	}
+	if opts.ParentID != nil {
+		switch {
+		case *opts.ParentID == 0:
+			w.add(`p.parent_id IS NULL`)
+		case opts.IncludeChildren:
+			w.add(`p.id IN (WITH RECURSIVE sub(id) AS (
+				SELECT id FROM pools WHERE parent_id = (SELECT id FROM pools WHERE seq_id = ` + w.arg(*opts.ParentID) + ` AND organization_id = $1)
+				UNION SELECT c.id FROM pools c JOIN sub ON c.parent_id = sub.id
+			) SELECT id FROM sub)`)
+		default:
+			w.add(`p.parent_id = (SELECT id FROM pools WHERE seq_id = ` + w.arg(*opts.ParentID) + ` AND organization_id = $1)`)
+		}
+	}
+	if opts.AccountID != nil {
+		if *opts.AccountID == 0 {
+			w.add(`p.account_id IS NULL`)
+		} else {
+			w.add(`p.account_id = (SELECT id FROM accounts WHERE seq_id = ` + w.arg(*opts.AccountID) + ` AND organization_id = $1)`)
+		}
+	}
+	if opts.CIDRPrefix != "" {
+		w.add(`starts_with(p.cidr::text, ` + w.arg(opts.CIDRPrefix) + `)`)
+	}
+	if opts.CIDRContains != "" {
+		q, _ := storage.ParseCIDRArg(opts.CIDRContains)
+		w.add(`p.cidr >>= ` + w.arg(q.String()) + `::cidr`)
+	}
+	if opts.CIDRWithin != "" {
+		q, _ := storage.ParseCIDRArg(opts.CIDRWithin)
+		w.add(`p.cidr <<= ` + w.arg(q.String()) + `::cidr`)
+	}
+	if opts.NameContains != "" {
+		w.add(`strpos(lower(p.name), ` + w.arg(strings.ToLower(opts.NameContains)) + `) > 0`)
+	}
+	w.createdRange("p.created_at", opts.CreatedAfter, opts.CreatedBefore)
+	return w
+}
+
+// QueryPools returns the page of live pools matching opts.
+func (s *Store) QueryPools(ctx context.Context, opts storage.PoolQueryOptions) ([]*domain.Pool, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	w := s.poolWhere(opts)
+	query := fmt.Sprintf(`SELECT p.%s FROM pools p WHERE %s`, poolColumnsWithParentAccount(), w) +
+		orderLimit(poolOrderColumns[opts.OrderBy], "p.seq_id", opts.OrderDesc, opts.Limit, opts.Offset)
+
+	rows, err := s.q().Query(ctx, query, w.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	pools, err := s.scanPools(rows)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*domain.Pool, len(pools))
+	for i := range pools {
+		out[i] = &pools[i]
+	}
+	return out, nil
+}
+
+// CountPools returns how many live pools match opts, ignoring Limit and Offset.
+func (s *Store) CountPools(ctx context.Context, opts storage.PoolQueryOptions) (int64, error) {
+	if err := opts.Validate(); err != nil {
+		return 0, err
+	}
+	w := s.poolWhere(opts)
+	var n int64
+	err := s.q().QueryRow(ctx, `SELECT COUNT(*) FROM pools p WHERE `+w.String(), w.args...).Scan(&n)
+	return n, err
+}
+
+var accountOrderColumns = map[string]string{
+	"":           "a.seq_id",
+	"id":         "a.seq_id",
+	"key":        "a.key",
+	"name":       "a.name",
+	"created_at": "a.created_at",
+}
+
+func (s *Store) accountWhere(opts storage.AccountQueryOptions) *whereClause {
+	w := newWhere(s.orgID, "a")
+	if opts.ID != nil {
+		w.add(`a.seq_id = ` + w.arg(*opts.ID))
+	}
+	if opts.Key != "" {
+		w.add(`a.key = ` + w.arg(opts.Key))
+	}
+	if opts.KeyPrefix != "" {
+		w.add(`starts_with(a.key, ` + w.arg(opts.KeyPrefix) + `)`)
+	}
+	for _, f := range []struct{ column, value string }{
+		{"a.provider", opts.Provider},
+		{"a.platform", opts.Platform},
+		{"a.tier", opts.Tier},
+		{"a.environment", opts.Environment},
+	} {
+		if f.value != "" {
+			w.add(f.column + ` = ` + w.arg(f.value))
+		}
+	}
+	if opts.Region != "" {
+		w.add(`a.regions @> jsonb_build_array(` + w.arg(opts.Region) + `::text)`)
+	}
+	if opts.NameContains != "" {
+		w.add(`strpos(lower(a.name), ` + w.arg(strings.ToLower(opts.NameContains)) + `) > 0`)
+	}
+	w.createdRange("a.created_at", opts.CreatedAfter, opts.CreatedBefore)
+	return w
+}
+
+// QueryAccounts returns the page of live accounts matching opts.
+func (s *Store) QueryAccounts(ctx context.Context, opts storage.AccountQueryOptions) ([]*domain.Account, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	w := s.accountWhere(opts)
+	query := `
+		SELECT a.seq_id, a.key, a.name, a.provider, a.external_id, a.description,
+			a.platform, a.tier, a.environment, a.regions, a.version, a.created_at, a.updated_at
+		FROM accounts a
+		WHERE ` + w.String() +
+		orderLimit(accountOrderColumns[opts.OrderBy], "a.seq_id", opts.OrderDesc, opts.Limit, opts.Offset)
+
+	rows, err := s.q().Query(ctx, query, w.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []*domain.Account{}
+	for rows.Next() {
+		a, err := scanAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, &a)
+	}
+	return out, rows.Err()
+}
+
+// CountAccounts returns how many live accounts match opts, ignoring Limit
+// and Offset.
+func (s *Store) CountAccounts(ctx context.Context, opts storage.AccountQueryOptions) (int64, error) {
+	if err := opts.Validate(); err != nil {
+		return 0, err
+	}
+	w := s.accountWhere(opts)
+	var n int64
+	err := s.q().QueryRow(ctx, `SELECT COUNT(*) FROM accounts a WHERE `+w.String(), w.args...).Scan(&n)
+	return n, err
+}
@@ -0,0 +1,184 @@
+//go:build postgres
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+var _ storage.ReservationStore = (*Store)(nil)
+
+const reservationColumns = `id, cidr::text, parent_id, owner, reason, status, expires_at, created_by, created_at,
+	released_by, released_at`
+
+// CreateReservation stores a new reservation. It takes a per-organization
+// advisory lock first, so two overlapping reservations cannot both pass the
+// check.
+func (s *Store) CreateReservation(ctx context.Context, r domain.Reservation) error {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('cloudpam_reservations'), hashtext($1))`, s.orgID); err != nil {
+		return err
+	}
+	var heldID, heldCIDR string
+	err = tx.QueryRow(ctx,
+		`SELECT id, cidr::text FROM reservations
+		 WHERE organization_id = $1 AND status = $2 AND expires_at > $3
+		   AND parent_id IS NOT DISTINCT FROM $4 AND cidr && $5::cidr
+		 LIMIT 1`,
+		s.orgID, string(domain.ReservationActive), r.CreatedAt, r.ParentID, r.CIDR,
+	).Scan(&heldID, &heldCIDR)
+	if err == nil {
+		return fmt.Errorf("%s is reserved by %s (%s): %w", r.CIDR, heldID, heldCIDR, storage.ErrConflict)
+	}
+	if err != pgx.ErrNoRows {
+		return err
+	}
+	_, err = tx.Exec(ctx,
+		`INSERT INTO reservations (id, organization_id, cidr, parent_id, owner, reason, status, expires_at,
+		     created_by, created_at, released_by, released_at)
+		 VALUES ($1, $2, $3::cidr, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+		r.ID, s.orgID, r.CIDR, r.ParentID, r.Owner, r.Reason, string(r.Status), r.ExpiresAt,
+		r.CreatedBy, r.CreatedAt, nilStringIfEmpty(r.ReleasedBy), r.ReleasedAt,
+	)
+	if err != nil {
+		return storage.WrapIfConflict(err)
+	}
+	return tx.Commit(ctx)
+}
+
+// GetReservation returns a reservation by ID.
+func (s *Store) GetReservation(ctx context.Context, id string) (*domain.Reservation, error) {
+	row := s.q().QueryRow(ctx,
+		`SELECT `+reservationColumns+` FROM reservations WHERE id = $1 AND organization_id = $2`,
+		id, s.orgID,
+	)
+	r, err := scanReservation(row)
+	if err == pgx.ErrNoRows {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// ListReservations returns reservations, newest first.
+func (s *Store) ListReservations(ctx context.Context, status domain.ReservationStatus) ([]domain.Reservation, error) {
+	query := `SELECT ` + reservationColumns + ` FROM reservations WHERE organization_id = $1`
+	args := []any{s.orgID}
+	if status != "" {
+		query += ` AND status = $2`
+		args = append(args, string(status))
+	}
+	rows, err := s.q().Query(ctx, query+` ORDER BY created_at DESC, id DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []domain.Reservation{}
+	for rows.Next() {
+		r, err := scanReservation(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// UpdateReservation saves the owner, reason and expiry of an active
+// reservation.
+func (s *Store) UpdateReservation(ctx context.Context, r domain.Reservation) error {
+	cmd, err := s.q().Exec(ctx,
+		`UPDATE reservations SET owner = $1, reason = $2, expires_at = $3
+		 WHERE id = $4 AND organization_id = $5 AND status = $6`,
+		r.Owner, r.Reason, r.ExpiresAt, r.ID, s.orgID, string(domain.ReservationActive),
+	)
+	if err != nil {
+		return err
+	}
+	return s.reservationChanged(ctx, cmd.RowsAffected(), r.ID)
+}
+
+// ReleaseReservation ends an active reservation.
+func (s *Store) ReleaseReservation(ctx context.Context, r domain.Reservation) error {
+	cmd, err := s.q().Exec(ctx,
+		`UPDATE reservations SET status = $1, released_by = $2, released_at = $3
+		 WHERE id = $4 AND organization_id = $5 AND status = $6`,
+		string(r.Status), nilStringIfEmpty(r.ReleasedBy), r.ReleasedAt, r.ID, s.orgID, string(domain.ReservationActive),
+	)
+	if err != nil {
+		return err
+	}
+	return s.reservationChanged(ctx, cmd.RowsAffected(), r.ID)
+}
+
+// reservationChanged turns an update of an active reservation that matched
+// no row into ErrNotFound or ErrConflict.
+func (s *Store) reservationChanged(ctx context.Context, affected int64, id string) error {
+	if affected > 0 {
+		return nil
+	}
+	var status string
+	err := s.q().QueryRow(ctx,
+		`SELECT status FROM reservations WHERE id = $1 AND organization_id = $2`, id, s.orgID,
+	).Scan(&status)
+	if err == pgx.ErrNoRows {
+		return storage.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("reservation %s is %s: %w", id, status, storage.ErrConflict)
+}
+
+// ExpireReservations marks active reservations past their expiry as
+// expired and returns them.
+func (s *Store) ExpireReservations(ctx context.Context, now time.Time) ([]domain.Reservation, error) {
+	rows, err := s.q().Query(ctx,
+		`UPDATE reservations SET status = $1, released_by = $2, released_at = $3
+		 WHERE organization_id = $4 AND status = $5 AND expires_at <= $3
+		 RETURNING `+reservationColumns,
+		string(domain.ReservationExpired), storage.ReservationSystemActor, now, s.orgID, string(domain.ReservationActive),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []domain.Reservation
+	for rows.Next() {
+		r, err := scanReservation(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+func scanReservation(row interface{ Scan(dest ...any) error }) (domain.Reservation, error) {
+	var r domain.Reservation
+	var status string
+	var releasedBy *string
+	if err := row.Scan(&r.ID, &r.CIDR, &r.ParentID, &r.Owner, &r.Reason, &status, &r.ExpiresAt, &r.CreatedBy,
+		&r.CreatedAt, &releasedBy, &r.ReleasedAt); err != nil {
+		return r, err
+	}
+	r.Status = domain.ReservationStatus(status)
+	if releasedBy != nil {
+		r.ReleasedBy = *releasedBy
+	}
+	return r, nil
+}
@@ -0,0 +1,180 @@
+//go:build postgres
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+var _ storage.PoolRestructurer = (*Store)(nil)
+
+// ResizePool checks and moves a pool to a new CIDR in a single transaction.
+// The pool and its parent are locked FOR UPDATE, which also serializes the
+// move against allocations under the same parent.
+func (s *Store) ResizePool(ctx context.Context, id int64, cidrStr string, held []netip.Prefix) (domain.Pool, error) {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	p, ok, err := s.lockPool(ctx, tx, id)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+	if !ok {
+		return domain.Pool{}, fmt.Errorf("pool not found: %w", storage.ErrNotFound)
+	}
+	parent, siblings, err := s.poolFamily(ctx, tx, p.ParentID)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+	children, err := s.poolChildren(ctx, tx, id)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+	var addresses []string
+	rows, err := tx.Query(ctx, `SELECT host(address) FROM ip_addresses WHERE organization_id = $1 AND pool_id = $2`, s.orgID, id)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+	for rows.Next() {
+		var addr string
+		if err := rows.Scan(&addr); err != nil {
+			rows.Close()
+			return domain.Pool{}, err
+		}
+		addresses = append(addresses, addr)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return domain.Pool{}, err
+	}
+	next, err := storage.CheckResize(p, cidrStr, parent, siblings, children, addresses, held)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE pools SET cidr = $3::inet
+		WHERE seq_id = $1 AND organization_id = $2`, id, s.orgID, next.String()); err != nil {
+		if isUniqueViolation(err) {
+			return domain.Pool{}, fmt.Errorf("%s is already in use: %w", next, storage.ErrConflict)
+		}
+		return domain.Pool{}, err
+	}
+	p, _, err = s.lockPool(ctx, tx, id)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return domain.Pool{}, err
+	}
+	return p, nil
+}
+
+// AggregatePools inserts the aggregate and re-parents its members in a
+// single transaction, with the members and their parent locked FOR UPDATE.
+func (s *Store) AggregatePools(ctx context.Context, ids []int64, in domain.CreatePool) (domain.Pool, error) {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	members := make([]domain.Pool, 0, len(ids))
+	for _, id := range ids {
+		p, ok, err := s.lockPool(ctx, tx, id)
+		if err != nil {
+			return domain.Pool{}, err
+		}
+		if !ok {
+			return domain.Pool{}, fmt.Errorf("pool %d not found: %w", id, storage.ErrNotFound)
+		}
+		members = append(members, p)
+	}
+	var parentID *int64
+	if len(members) > 0 {
+		parentID = members[0].ParentID
+	}
+	parent, siblings, err := s.poolFamily(ctx, tx, parentID)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+	if _, err := storage.CheckAggregate(members, in.CIDR, parent, siblings); err != nil {
+		return domain.Pool{}, err
+	}
+
+	in.ParentID = parentID
+	agg, err := s.createPool(ctx, tx, in)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+	// The path trigger only rewrites rows whose parent changes, so each
+	// member's descendants are carried one level down by hand.
+	for _, id := range ids {
+		var oldPath, newPath string
+		if err := tx.QueryRow(ctx, `SELECT path FROM pools WHERE seq_id = $1 AND organization_id = $2`, id, s.orgID).Scan(&oldPath); err != nil {
+			return domain.Pool{}, err
+		}
+		if err := tx.QueryRow(ctx, `
+			UPDATE pools SET parent_id = (SELECT id FROM pools WHERE seq_id = $3 AND organization_id = $2)
+			WHERE seq_id = $1 AND organization_id = $2
+			RETURNING path`, id, s.orgID, agg.ID).Scan(&newPath); err != nil {
+			return domain.Pool{}, err
+		}
+		if _, err := tx.Exec(ctx, `
+			UPDATE pools SET path = $3 || substr(path, $4), depth = depth + 1
+			WHERE organization_id = $1 AND path LIKE $2 || '/%'`, s.orgID, oldPath, newPath, len(oldPath)+1); err != nil {
+			return domain.Pool{}, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return domain.Pool{}, err
+	}
+	return agg, nil
+}
+
+// lockPool reads a live pool and locks its row for the rest of tx.
+func (s *Store) lockPool(ctx context.Context, q querier, id int64) (domain.Pool, bool, error) {
+	query := fmt.Sprintf(`
+		SELECT p.%s
+		FROM pools p
+		WHERE p.seq_id = $1 AND p.organization_id = $2 AND p.deleted_at IS NULL
+		FOR UPDATE OF p`, poolColumnsWithParentAccount())
+	return s.scanPool(q.QueryRow(ctx, query, id, s.orgID))
+}
+
+// poolFamily locks and returns the live pool parentID and lists the live
+// pools under it; at the top level (nil parentID) the parent is nil and the
+// siblings are the top-level pools.
+func (s *Store) poolFamily(ctx context.Context, q querier, parentID *int64) (*domain.Pool, []domain.Pool, error) {
+	if parentID != nil {
+		parent, ok, err := s.lockPool(ctx, q, *parentID)
+		if err != nil {
+			return nil, nil, err
+		}
+		siblings, err := s.poolChildren(ctx, q, *parentID)
+		if err != nil || !ok {
+			return nil, siblings, err
+		}
+		return &parent, siblings, nil
+	}
+	query := fmt.Sprintf(`
+		SELECT p.%s
+		FROM pools p
+		WHERE p.parent_id IS NULL AND p.organization_id = $1 AND p.deleted_at IS NULL
+		ORDER BY p.seq_id`, poolColumnsWithParentAccount())
+	rows, err := q.Query(ctx, query, s.orgID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	siblings, err := s.scanPools(rows)
+	return nil, siblings, err
+}
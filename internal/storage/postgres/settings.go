@@ -14,7 +14,7 @@ import (
 // GetSecuritySettings retrieves security settings from PostgreSQL.
 func (s *Store) GetSecuritySettings(ctx context.Context) (*domain.SecuritySettings, error) {
 	var raw string
-	err := s.pool.QueryRow(ctx, `SELECT value FROM settings WHERE key = 'security'`).Scan(&raw)
+	err := s.q().QueryRow(ctx, `SELECT value FROM settings WHERE key = 'security'`).Scan(&raw)
 	if err == pgx.ErrNoRows {
 		defaults := domain.DefaultSecuritySettings()
 		return &defaults, nil
@@ -38,7 +38,7 @@ func (s *Store) UpdateSecuritySettings(ctx context.Context, settings *domain.Sec
 		return err
 	}
 
-	_, err = s.pool.Exec(ctx,
+	_, err = s.q().Exec(ctx,
 		`INSERT INTO settings (key, value, updated_at)
 		 VALUES ('security', $1, NOW())
 		 ON CONFLICT (key) DO UPDATE
@@ -51,7 +51,7 @@ func (s *Store) UpdateSecuritySettings(ctx context.Context, settings *domain.Sec
 // GetNetworkSchemaPolicy retrieves the persisted merged-network schema policy.
 func (s *Store) GetNetworkSchemaPolicy(ctx context.Context) (*domain.NetworkSchemaPolicy, error) {
 	var raw string
-	err := s.pool.QueryRow(ctx, `SELECT value FROM settings WHERE key = 'network_schema_policy'`).Scan(&raw)
+	err := s.q().QueryRow(ctx, `SELECT value FROM settings WHERE key = 'network_schema_policy'`).Scan(&raw)
 	if err == pgx.ErrNoRows {
 		defaults := domain.DefaultNetworkSchemaPolicy()
 		return &defaults, nil
@@ -75,7 +75,7 @@ func (s *Store) UpdateNetworkSchemaPolicy(ctx context.Context, policy *domain.Ne
 		return err
 	}
 
-	_, err = s.pool.Exec(ctx,
+	_, err = s.q().Exec(ctx,
 		`INSERT INTO settings (key, value, updated_at)
 		 VALUES ('network_schema_policy', $1, NOW())
 		 ON CONFLICT (key) DO UPDATE
@@ -84,3 +84,113 @@ func (s *Store) UpdateNetworkSchemaPolicy(ctx context.Context, policy *domain.Ne
 	)
 	return err
 }
+
+// GetAlertSettings retrieves the capacity alert rules and notification channels.
+func (s *Store) GetAlertSettings(ctx context.Context) (*domain.AlertSettings, error) {
+	var raw string
+	err := s.q().QueryRow(ctx, `SELECT value FROM settings WHERE key = 'alerting'`).Scan(&raw)
+	if err == pgx.ErrNoRows {
+		defaults := domain.DefaultAlertSettings()
+		return &defaults, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var settings domain.AlertSettings
+	if err := json.Unmarshal([]byte(raw), &settings); err != nil {
+		return nil, err
+	}
+	return domain.NormalizeAlertSettings(&settings), nil
+}
+
+// UpdateAlertSettings saves the capacity alert rules and notification channels.
+func (s *Store) UpdateAlertSettings(ctx context.Context, settings *domain.AlertSettings) error {
+	settings = domain.NormalizeAlertSettings(settings)
+	raw, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.q().Exec(ctx,
+		`INSERT INTO settings (key, value, updated_at)
+		 VALUES ('alerting', $1, NOW())
+		 ON CONFLICT (key) DO UPDATE
+		 SET value = EXCLUDED.value, updated_at = EXCLUDED.updated_at`,
+		string(raw),
+	)
+	return err
+}
+
+// GetAuditRetentionSettings retrieves the audit log retention policy.
+func (s *Store) GetAuditRetentionSettings(ctx context.Context) (*domain.AuditRetentionSettings, error) {
+	var raw string
+	err := s.q().QueryRow(ctx, `SELECT value FROM settings WHERE key = 'audit_retention'`).Scan(&raw)
+	if err == pgx.ErrNoRows {
+		defaults := domain.DefaultAuditRetentionSettings()
+		return &defaults, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var settings domain.AuditRetentionSettings
+	if err := json.Unmarshal([]byte(raw), &settings); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// UpdateAuditRetentionSettings saves the audit log retention policy.
+func (s *Store) UpdateAuditRetentionSettings(ctx context.Context, settings *domain.AuditRetentionSettings) error {
+	raw, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.q().Exec(ctx,
+		`INSERT INTO settings (key, value, updated_at)
+		 VALUES ('audit_retention', $1, NOW())
+		 ON CONFLICT (key) DO UPDATE
+		 SET value = EXCLUDED.value, updated_at = EXCLUDED.updated_at`,
+		string(raw),
+	)
+	return err
+}
+
+// GetApprovalSettings retrieves the approval policies for pool mutations.
+func (s *Store) GetApprovalSettings(ctx context.Context) (*domain.ApprovalSettings, error) {
+	var raw string
+	err := s.q().QueryRow(ctx, `SELECT value FROM settings WHERE key = 'approvals'`).Scan(&raw)
+	if err == pgx.ErrNoRows {
+		defaults := domain.DefaultApprovalSettings()
+		return &defaults, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var settings domain.ApprovalSettings
+	if err := json.Unmarshal([]byte(raw), &settings); err != nil {
+		return nil, err
+	}
+	return domain.NormalizeApprovalSettings(&settings), nil
+}
+
+// UpdateApprovalSettings saves the approval policies for pool mutations.
+func (s *Store) UpdateApprovalSettings(ctx context.Context, settings *domain.ApprovalSettings) error {
+	settings = domain.NormalizeApprovalSettings(settings)
+	raw, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.q().Exec(ctx,
+		`INSERT INTO settings (key, value, updated_at)
+		 VALUES ('approvals', $1, NOW())
+		 ON CONFLICT (key) DO UPDATE
+		 SET value = EXCLUDED.value, updated_at = EXCLUDED.updated_at`,
+		string(raw),
+	)
+	return err
+}
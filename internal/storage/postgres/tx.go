@@ -0,0 +1,87 @@
+//go:build postgres
+
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+
+	"cloudpam/internal/storage"
+)
+
+var (
+	_ storage.TransactionalStore = (*Store)(nil)
+	_ storage.Transaction        = (*Tx)(nil)
+)
+
+// q returns the handle queries run on: the transaction when the Store is
+// scoped to one, the connection pool otherwise.
+func (s *Store) q() querier {
+	if s.tx != nil {
+		return s.tx
+	}
+	return s.pool
+}
+
+// begin starts a transaction for a multi-statement write. When the Store is
+// already scoped to a transaction pgx opens a savepoint instead, so helpers
+// like AllocatePool compose with an outer WithTx.
+func (s *Store) begin(ctx context.Context) (pgx.Tx, error) {
+	if s.tx != nil {
+		return s.tx.Begin(ctx)
+	}
+	return s.pool.Begin(ctx)
+}
+
+// Tx is a Store whose reads and writes all run inside one database
+// transaction. It also satisfies every other storage interface Store does,
+// so discovery links and drift records can join the same transaction.
+type Tx struct {
+	*Store
+	ctx context.Context
+}
+
+// BeginTx starts a transaction. Calling it on a Tx opens a savepoint.
+func (s *Store) BeginTx(ctx context.Context) (storage.Transaction, error) {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Store: &Store{pool: s.pool, orgID: s.orgID, tx: tx}, ctx: ctx}, nil
+}
+
+// WithTx runs fn in a transaction, committing if fn returns nil and rolling
+// back otherwise.
+func (s *Store) WithTx(ctx context.Context, fn func(tx storage.Transaction) error) error {
+	tx, err := s.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Commit commits the transaction.
+func (t *Tx) Commit() error {
+	return t.tx.Commit(t.ctx)
+}
+
+// Rollback aborts the transaction. It is a no-op once the transaction has
+// been committed or rolled back.
+func (t *Tx) Rollback() error {
+	// Use a fresh context so a cancelled request still releases its locks.
+	if err := t.tx.Rollback(context.Background()); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+		return err
+	}
+	return nil
+}
+
+// Close rolls the transaction back; the connection pool stays open.
+func (t *Tx) Close() error {
+	return t.Rollback()
+}
@@ -0,0 +1,88 @@
+//go:build postgres
+
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+var _ storage.UtilizationStore = (*Store)(nil)
+
+const utilizationSnapshotColumns = `id, pool_id, total_ips, used_ips, available_ips, utilization, child_count, captured_at`
+
+// RecordSnapshot stores a utilization snapshot. A second snapshot for the
+// same pool and instant replaces the first.
+func (s *Store) RecordSnapshot(ctx context.Context, snap domain.UtilizationSnapshot) error {
+	_, err := s.q().Exec(ctx,
+		`INSERT INTO utilization_snapshots (pool_id, organization_id, total_ips, used_ips, available_ips,
+			utilization, child_count, captured_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		 ON CONFLICT (pool_id, captured_at) DO UPDATE SET
+			total_ips = EXCLUDED.total_ips,
+			used_ips = EXCLUDED.used_ips,
+			available_ips = EXCLUDED.available_ips,
+			utilization = EXCLUDED.utilization,
+			child_count = EXCLUDED.child_count`,
+		snap.PoolID, s.orgID, snap.TotalIPs, snap.UsedIPs, snap.AvailableIPs,
+		snap.Utilization, snap.ChildCount, snap.CapturedAt.UTC(),
+	)
+	return err
+}
+
+// ListSnapshots returns a pool's snapshots within [from, to], oldest first.
+func (s *Store) ListSnapshots(ctx context.Context, poolID int64, from, to time.Time) ([]domain.UtilizationSnapshot, error) {
+	rows, err := s.q().Query(ctx,
+		`SELECT `+utilizationSnapshotColumns+` FROM utilization_snapshots
+		 WHERE pool_id = $1 AND organization_id = $2 AND captured_at >= $3 AND captured_at <= $4
+		 ORDER BY captured_at ASC`,
+		poolID, s.orgID, from.UTC(), to.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.UtilizationSnapshot
+	for rows.Next() {
+		snap, err := scanUtilizationSnapshot(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, snap)
+	}
+	return out, rows.Err()
+}
+
+// LatestSnapshot returns a pool's most recent snapshot, or nil if none exist.
+func (s *Store) LatestSnapshot(ctx context.Context, poolID int64) (*domain.UtilizationSnapshot, error) {
+	row := s.q().QueryRow(ctx,
+		`SELECT `+utilizationSnapshotColumns+` FROM utilization_snapshots
+		 WHERE pool_id = $1 AND organization_id = $2
+		 ORDER BY captured_at DESC LIMIT 1`,
+		poolID, s.orgID,
+	)
+	snap, err := scanUtilizationSnapshot(row)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &snap, nil
+}
+
+func scanUtilizationSnapshot(row interface{ Scan(dest ...any) error }) (domain.UtilizationSnapshot, error) {
+	var snap domain.UtilizationSnapshot
+	if err := row.Scan(&snap.ID, &snap.PoolID, &snap.TotalIPs, &snap.UsedIPs, &snap.AvailableIPs,
+		&snap.Utilization, &snap.ChildCount, &snap.CapturedAt); err != nil {
+		return snap, err
+	}
+	snap.CapturedAt = snap.CapturedAt.UTC()
+	return snap, nil
+}
@@ -0,0 +1,286 @@
+//go:build postgres
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+var _ storage.WebhookStore = (*Store)(nil)
+
+const webhookColumns = `id, name, url, secret, events::text, enabled, created_at, updated_at`
+
+const webhookDeliveryColumns = `id, webhook_id, event_id, event_type, payload, status, attempts,
+	response_status, last_error, next_attempt_at, delivered_at, created_at, updated_at`
+
+// CreateWebhook stores a new webhook.
+func (s *Store) CreateWebhook(ctx context.Context, w domain.Webhook) error {
+	events, err := json.Marshal(webhookEvents(w.Events))
+	if err != nil {
+		return err
+	}
+	_, err = s.q().Exec(ctx,
+		`INSERT INTO webhooks (id, organization_id, name, url, secret, events, enabled, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6::jsonb, $7, $8, $9)`,
+		w.ID, s.orgID, w.Name, w.URL, w.Secret, string(events), w.Enabled, w.CreatedAt, w.UpdatedAt,
+	)
+	return storage.WrapIfConflict(err)
+}
+
+// GetWebhook returns a webhook by ID, including its secret.
+func (s *Store) GetWebhook(ctx context.Context, id string) (*domain.Webhook, error) {
+	row := s.q().QueryRow(ctx,
+		`SELECT `+webhookColumns+` FROM webhooks WHERE id = $1 AND organization_id = $2`,
+		id, s.orgID,
+	)
+	w, err := scanWebhook(row)
+	if err == pgx.ErrNoRows {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// ListWebhooks returns all webhooks ordered by name.
+func (s *Store) ListWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	rows, err := s.q().Query(ctx,
+		`SELECT `+webhookColumns+` FROM webhooks WHERE organization_id = $1 ORDER BY name, id`,
+		s.orgID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []domain.Webhook{}
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, w)
+	}
+	return out, rows.Err()
+}
+
+// UpdateWebhook replaces a webhook's mutable fields.
+func (s *Store) UpdateWebhook(ctx context.Context, w domain.Webhook) error {
+	events, err := json.Marshal(webhookEvents(w.Events))
+	if err != nil {
+		return err
+	}
+	cmd, err := s.q().Exec(ctx,
+		`UPDATE webhooks
+		    SET name = $1, url = $2, secret = $3, events = $4::jsonb, enabled = $5, updated_at = $6
+		  WHERE id = $7 AND organization_id = $8`,
+		w.Name, w.URL, w.Secret, string(events), w.Enabled, w.UpdatedAt, w.ID, s.orgID,
+	)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteWebhook removes a webhook; its deliveries go with it via ON DELETE CASCADE.
+func (s *Store) DeleteWebhook(ctx context.Context, id string) error {
+	cmd, err := s.q().Exec(ctx, `DELETE FROM webhooks WHERE id = $1 AND organization_id = $2`, id, s.orgID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// CreateWebhookDelivery records a new delivery.
+func (s *Store) CreateWebhookDelivery(ctx context.Context, d domain.WebhookDelivery) error {
+	_, err := s.q().Exec(ctx,
+		`INSERT INTO webhook_deliveries (
+			id, organization_id, webhook_id, event_id, event_type, payload, status, attempts,
+			response_status, last_error, next_attempt_at, delivered_at, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`,
+		d.ID, s.orgID, d.WebhookID, d.EventID, string(d.EventType), d.Payload, string(d.Status), d.Attempts,
+		nilIntIfZero(d.ResponseStatus), nilStringIfEmpty(d.LastError), d.NextAttemptAt, d.DeliveredAt,
+		d.CreatedAt, d.UpdatedAt,
+	)
+	if err != nil && strings.Contains(err.Error(), "23503") {
+		return fmt.Errorf("webhook %s: %w", d.WebhookID, storage.ErrNotFound)
+	}
+	return storage.WrapIfConflict(err)
+}
+
+// GetWebhookDelivery returns a delivery by ID.
+func (s *Store) GetWebhookDelivery(ctx context.Context, id string) (*domain.WebhookDelivery, error) {
+	row := s.q().QueryRow(ctx,
+		`SELECT `+webhookDeliveryColumns+` FROM webhook_deliveries WHERE id = $1 AND organization_id = $2`,
+		id, s.orgID,
+	)
+	d, err := scanWebhookDelivery(row)
+	if err == pgx.ErrNoRows {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// UpdateWebhookDelivery records the outcome of a delivery attempt.
+func (s *Store) UpdateWebhookDelivery(ctx context.Context, d domain.WebhookDelivery) error {
+	cmd, err := s.q().Exec(ctx,
+		`UPDATE webhook_deliveries
+		    SET status = $1, attempts = $2, response_status = $3, last_error = $4,
+		        next_attempt_at = $5, delivered_at = $6, updated_at = $7
+		  WHERE id = $8 AND organization_id = $9`,
+		string(d.Status), d.Attempts, nilIntIfZero(d.ResponseStatus), nilStringIfEmpty(d.LastError),
+		d.NextAttemptAt, d.DeliveredAt, d.UpdatedAt, d.ID, s.orgID,
+	)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// ListWebhookDeliveries returns paginated deliveries, newest first.
+func (s *Store) ListWebhookDeliveries(ctx context.Context, filters domain.WebhookDeliveryFilters) ([]domain.WebhookDelivery, int, error) {
+	where := []string{"organization_id = $1"}
+	args := []any{s.orgID}
+	addArg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if filters.WebhookID != "" {
+		where = append(where, "webhook_id = "+addArg(filters.WebhookID))
+	}
+	if filters.Status != "" {
+		where = append(where, "status = "+addArg(filters.Status))
+	}
+	if filters.EventType != "" {
+		where = append(where, "event_type = "+addArg(filters.EventType))
+	}
+	whereClause := " WHERE " + strings.Join(where, " AND ")
+
+	var total int
+	if err := s.q().QueryRow(ctx, "SELECT COUNT(*) FROM webhook_deliveries"+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	page := filters.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := filters.PageSize
+	if pageSize < 1 {
+		pageSize = 50
+	}
+	limit := addArg(pageSize)
+	offset := addArg((page - 1) * pageSize)
+
+	rows, err := s.q().Query(ctx,
+		`SELECT `+webhookDeliveryColumns+` FROM webhook_deliveries`+whereClause+
+			` ORDER BY created_at DESC, id DESC LIMIT `+limit+` OFFSET `+offset,
+		args...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	out := []domain.WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		out = append(out, d)
+	}
+	return out, total, rows.Err()
+}
+
+// ListDueWebhookDeliveries returns pending deliveries due at or before now.
+func (s *Store) ListDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries
+		WHERE organization_id = $1 AND status = $2 AND next_attempt_at <= $3
+		ORDER BY next_attempt_at, id`
+	args := []any{s.orgID, string(domain.WebhookDeliveryPending), now}
+	if limit > 0 {
+		query += ` LIMIT $4`
+		args = append(args, limit)
+	}
+	rows, err := s.q().Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []domain.WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+// webhookEvents normalises a nil filter to an empty JSON array.
+func webhookEvents(events []domain.WebhookEventType) []domain.WebhookEventType {
+	if events == nil {
+		return []domain.WebhookEventType{}
+	}
+	return events
+}
+
+func nilIntIfZero(n int) *int {
+	if n == 0 {
+		return nil
+	}
+	return &n
+}
+
+func scanWebhook(row interface{ Scan(dest ...any) error }) (domain.Webhook, error) {
+	var w domain.Webhook
+	var eventsJSON string
+	if err := row.Scan(&w.ID, &w.Name, &w.URL, &w.Secret, &eventsJSON, &w.Enabled, &w.CreatedAt, &w.UpdatedAt); err != nil {
+		return w, err
+	}
+	w.Events = []domain.WebhookEventType{}
+	_ = json.Unmarshal([]byte(eventsJSON), &w.Events)
+	return w, nil
+}
+
+func scanWebhookDelivery(row interface{ Scan(dest ...any) error }) (domain.WebhookDelivery, error) {
+	var d domain.WebhookDelivery
+	var eventType, status string
+	var responseStatus *int
+	var lastError *string
+	if err := row.Scan(&d.ID, &d.WebhookID, &d.EventID, &eventType, &d.Payload, &status, &d.Attempts,
+		&responseStatus, &lastError, &d.NextAttemptAt, &d.DeliveredAt, &d.CreatedAt, &d.UpdatedAt); err != nil {
+		return d, err
+	}
+	d.EventType = domain.WebhookEventType(eventType)
+	d.Status = domain.WebhookDeliveryStatus(status)
+	if responseStatus != nil {
+		d.ResponseStatus = *responseStatus
+	}
+	if lastError != nil {
+		d.LastError = *lastError
+	}
+	return d, nil
+}
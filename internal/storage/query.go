@@ -0,0 +1,256 @@
+package storage
+
+import (
+	"cmp"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+
+	"cloudpam/internal/cidr"
+	"cloudpam/internal/domain"
+)
+
+// Sort fields accepted by PoolQueryOptions.OrderBy and
+// AccountQueryOptions.OrderBy. An empty OrderBy sorts by id.
+var (
+	poolOrderFields    = []string{"id", "name", "cidr", "created_at"}
+	accountOrderFields = []string{"id", "key", "name", "created_at"}
+)
+
+// Validate checks the options before a query runs. Errors wrap ErrValidation.
+func (o PoolQueryOptions) Validate() error {
+	if o.OrderBy != "" && !slices.Contains(poolOrderFields, o.OrderBy) {
+		return fmt.Errorf("order_by must be one of %s: %w", strings.Join(poolOrderFields, ", "), ErrValidation)
+	}
+	if o.Limit < 0 || o.Offset < 0 {
+		return fmt.Errorf("limit and offset must not be negative: %w", ErrValidation)
+	}
+	if o.CIDRContains != "" {
+		if _, err := ParseCIDRArg(o.CIDRContains); err != nil {
+			return fmt.Errorf("cidr_contains: %w", err)
+		}
+	}
+	if o.CIDRWithin != "" {
+		if _, err := ParseCIDRArg(o.CIDRWithin); err != nil {
+			return fmt.Errorf("cidr_within: %w", err)
+		}
+	}
+	return nil
+}
+
+// Validate checks the options before a query runs. Errors wrap ErrValidation.
+func (o AccountQueryOptions) Validate() error {
+	if o.OrderBy != "" && !slices.Contains(accountOrderFields, o.OrderBy) {
+		return fmt.Errorf("order_by must be one of %s: %w", strings.Join(accountOrderFields, ", "), ErrValidation)
+	}
+	if o.Limit < 0 || o.Offset < 0 {
+		return fmt.Errorf("limit and offset must not be negative: %w", ErrValidation)
+	}
+	return nil
+}
+
+// FilterPools applies opts to a full list of live pools, returning the
+// requested page and the number of pools that matched before paging. The
+// memory store answers Queryable with it, and callers holding a store
+// without Queryable can use it on the output of ListPools.
+func FilterPools(all []domain.Pool, opts PoolQueryOptions) ([]*domain.Pool, int64, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, 0, err
+	}
+	var contains, within netip.Prefix
+	if opts.CIDRContains != "" {
+		contains, _ = ParseCIDRArg(opts.CIDRContains)
+	}
+	if opts.CIDRWithin != "" {
+		within, _ = ParseCIDRArg(opts.CIDRWithin)
+	}
+	var under map[int64]bool
+	if opts.IncludeChildren && opts.ParentID != nil && *opts.ParentID != 0 {
+		under = descendants(all, *opts.ParentID)
+	}
+	name := strings.ToLower(opts.NameContains)
+
+	var out []*domain.Pool
+	for _, p := range all {
+		if opts.ID != nil && p.ID != *opts.ID {
+			continue
+		}
+		if under != nil {
+			if !under[p.ID] {
+				continue
+			}
+		} else if opts.ParentID != nil && !sameRef(p.ParentID, *opts.ParentID) {
+			continue
+		}
+		if opts.AccountID != nil && !sameRef(p.AccountID, *opts.AccountID) {
+			continue
+		}
+		if opts.CIDRPrefix != "" && !strings.HasPrefix(p.CIDR, opts.CIDRPrefix) {
+			continue
+		}
+		if contains.IsValid() || within.IsValid() {
+			pp, err := netip.ParsePrefix(p.CIDR)
+			if err != nil {
+				continue
+			}
+			if contains.IsValid() && !cidr.PrefixContains(pp.Masked(), contains) {
+				continue
+			}
+			if within.IsValid() && !cidr.PrefixContains(within, pp.Masked()) {
+				continue
+			}
+		}
+		if name != "" && !strings.Contains(strings.ToLower(p.Name), name) {
+			continue
+		}
+		if !inCreatedRange(p.CreatedAt, opts.CreatedAfter, opts.CreatedBefore) {
+			continue
+		}
+		cp := clonePool(p)
+		out = append(out, &cp)
+	}
+
+	slices.SortFunc(out, func(a, b *domain.Pool) int {
+		c := 0
+		switch opts.OrderBy {
+		case "name":
+			c = strings.Compare(a.Name, b.Name)
+		case "cidr":
+			c = compareCIDRs(a.CIDR, b.CIDR)
+		case "created_at":
+			c = a.CreatedAt.Compare(b.CreatedAt)
+		}
+		if c == 0 {
+			c = cmp.Compare(a.ID, b.ID)
+		}
+		if opts.OrderDesc {
+			return -c
+		}
+		return c
+	})
+	return page(out, opts.Limit, opts.Offset), int64(len(out)), nil
+}
+
+// FilterAccounts is FilterPools for accounts.
+func FilterAccounts(all []domain.Account, opts AccountQueryOptions) ([]*domain.Account, int64, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, 0, err
+	}
+	name := strings.ToLower(opts.NameContains)
+
+	var out []*domain.Account
+	for _, a := range all {
+		switch {
+		case opts.ID != nil && a.ID != *opts.ID,
+			opts.Key != "" && a.Key != opts.Key,
+			opts.KeyPrefix != "" && !strings.HasPrefix(a.Key, opts.KeyPrefix),
+			opts.Provider != "" && a.Provider != opts.Provider,
+			opts.Platform != "" && a.Platform != opts.Platform,
+			opts.Tier != "" && a.Tier != opts.Tier,
+			opts.Environment != "" && a.Environment != opts.Environment,
+			opts.Region != "" && !slices.Contains(a.Regions, opts.Region),
+			name != "" && !strings.Contains(strings.ToLower(a.Name), name),
+			!inCreatedRange(a.CreatedAt, opts.CreatedAfter, opts.CreatedBefore):
+			continue
+		}
+		ca := cloneAccount(a)
+		out = append(out, &ca)
+	}
+
+	slices.SortFunc(out, func(a, b *domain.Account) int {
+		c := 0
+		switch opts.OrderBy {
+		case "key":
+			c = strings.Compare(a.Key, b.Key)
+		case "name":
+			c = strings.Compare(a.Name, b.Name)
+		case "created_at":
+			c = a.CreatedAt.Compare(b.CreatedAt)
+		}
+		if c == 0 {
+			c = cmp.Compare(a.ID, b.ID)
+		}
+		if opts.OrderDesc {
+			return -c
+		}
+		return c
+	})
+	return page(out, opts.Limit, opts.Offset), int64(len(out)), nil
+}
+
+// sameRef reports whether ref points at id, where id 0 matches a nil ref.
+func sameRef(ref *int64, id int64) bool {
+	if id == 0 {
+		return ref == nil
+	}
+	return ref != nil && *ref == id
+}
+
+// descendants returns the ids of every pool below root.
+func descendants(all []domain.Pool, root int64) map[int64]bool {
+	children := make(map[int64][]int64)
+	for _, p := range all {
+		if p.ParentID != nil {
+			children[*p.ParentID] = append(children[*p.ParentID], p.ID)
+		}
+	}
+	out := make(map[int64]bool)
+	queue := []int64{root}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, c := range children[id] {
+			if !out[c] {
+				out[c] = true
+				queue = append(queue, c)
+			}
+		}
+	}
+	return out
+}
+
+// inCreatedRange applies the half-open [after, before) creation window.
+func inCreatedRange(t time.Time, after, before *int64) bool {
+	if after != nil && t.Before(time.Unix(*after, 0)) {
+		return false
+	}
+	if before != nil && !t.Before(time.Unix(*before, 0)) {
+		return false
+	}
+	return true
+}
+
+// compareCIDRs orders prefixes by family (IPv4 first), address and then
+// prefix length, matching PostgreSQL's ordering of the cidr type. Strings
+// that do not parse sort last, by text.
+func compareCIDRs(a, b string) int {
+	pa, errA := netip.ParsePrefix(a)
+	pb, errB := netip.ParsePrefix(b)
+	switch {
+	case errA != nil && errB != nil:
+		return strings.Compare(a, b)
+	case errA != nil:
+		return 1
+	case errB != nil:
+		return -1
+	}
+	if c := pa.Masked().Addr().Compare(pb.Masked().Addr()); c != 0 {
+		return c
+	}
+	return pa.Bits() - pb.Bits()
+}
+
+// page applies limit and offset to a sorted result. A zero limit means no
+// limit.
+func page[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return []T{}
+	}
+	items = items[offset:]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"context"
+
+	"cloudpam/internal/domain"
+)
+
+var _ Queryable = (*MemoryStore)(nil)
+
+// livePoolsLocked returns every pool that is not soft-deleted. The results
+// share tag maps with the store; FilterPools clones what it returns.
+func (m *MemoryStore) livePoolsLocked() []domain.Pool {
+	out := make([]domain.Pool, 0, len(m.pools))
+	for _, p := range m.pools {
+		if p.DeletedAt == nil {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func (m *MemoryStore) liveAccountsLocked() []domain.Account {
+	out := make([]domain.Account, 0, len(m.accounts))
+	for _, a := range m.accounts {
+		if a.DeletedAt == nil {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// QueryPools returns the page of live pools matching opts.
+func (m *MemoryStore) QueryPools(ctx context.Context, opts PoolQueryOptions) ([]*domain.Pool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out, _, err := FilterPools(m.livePoolsLocked(), opts)
+	return out, err
+}
+
+// CountPools returns how many live pools match opts, ignoring Limit and Offset.
+func (m *MemoryStore) CountPools(ctx context.Context, opts PoolQueryOptions) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, n, err := FilterPools(m.livePoolsLocked(), opts)
+	return n, err
+}
+
+// QueryAccounts returns the page of live accounts matching opts.
+func (m *MemoryStore) QueryAccounts(ctx context.Context, opts AccountQueryOptions) ([]*domain.Account, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out, _, err := FilterAccounts(m.liveAccountsLocked(), opts)
+	return out, err
+}
+
+// CountAccounts returns how many live accounts match opts, ignoring Limit
+// and Offset.
+func (m *MemoryStore) CountAccounts(ctx context.Context, opts AccountQueryOptions) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, n, err := FilterAccounts(m.liveAccountsLocked(), opts)
+	return n, err
+}
@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"cloudpam/internal/domain"
+)
+
+func TestMemoryStore_Queryable(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryStore()
+	aws, _ := m.CreateAccount(ctx, domain.CreateAccount{Key: "aws:111", Name: "Prod", Provider: "aws", Environment: "prod", Regions: []string{"us-east-1"}})
+	_, _ = m.CreateAccount(ctx, domain.CreateAccount{Key: "aws:222", Name: "Dev", Provider: "aws", Environment: "dev"})
+	_, _ = m.CreateAccount(ctx, domain.CreateAccount{Key: "gcp:333", Name: "Prod GCP", Provider: "gcp", Environment: "prod"})
+
+	root, _ := m.CreatePool(ctx, domain.CreatePool{Name: "Root", CIDR: "10.0.0.0/8"})
+	mid, _ := m.CreatePool(ctx, domain.CreatePool{Name: "mid", CIDR: "10.1.0.0/16", ParentID: &root.ID, AccountID: &aws.ID})
+	_, _ = m.CreatePool(ctx, domain.CreatePool{Name: "leaf", CIDR: "10.1.2.0/24", ParentID: &mid.ID})
+	gone, _ := m.CreatePool(ctx, domain.CreatePool{Name: "gone", CIDR: "10.9.0.0/16", ParentID: &root.ID})
+	_, _ = m.CreatePool(ctx, domain.CreatePool{Name: "other-root", CIDR: "172.16.0.0/12"})
+	if _, err := m.DeletePool(ctx, gone.ID); err != nil {
+		t.Fatalf("DeletePool: %v", err)
+	}
+
+	names := func(ps []*domain.Pool) string {
+		var out []string
+		for _, p := range ps {
+			out = append(out, p.Name)
+		}
+		return fmt.Sprint(out)
+	}
+	zero := int64(0)
+
+	cases := []struct {
+		name string
+		opts PoolQueryOptions
+		want string
+	}{
+		{"all live", PoolQueryOptions{}, "[Root mid leaf other-root]"},
+		{"roots", PoolQueryOptions{ParentID: &zero}, "[Root other-root]"},
+		{"children", PoolQueryOptions{ParentID: &root.ID}, "[mid]"},
+		{"descendants", PoolQueryOptions{ParentID: &root.ID, IncludeChildren: true}, "[mid leaf]"},
+		{"account", PoolQueryOptions{AccountID: &aws.ID}, "[mid]"},
+		{"unassigned", PoolQueryOptions{AccountID: &zero}, "[Root leaf other-root]"},
+		{"cidr prefix", PoolQueryOptions{CIDRPrefix: "10.1."}, "[mid leaf]"},
+		{"cidr contains", PoolQueryOptions{CIDRContains: "10.1.2.7"}, "[Root mid leaf]"},
+		{"cidr within", PoolQueryOptions{CIDRWithin: "10.1.0.0/16"}, "[mid leaf]"},
+		{"name", PoolQueryOptions{NameContains: "ROOT"}, "[Root other-root]"},
+		{"order by cidr desc", PoolQueryOptions{OrderBy: "cidr", OrderDesc: true}, "[other-root leaf mid Root]"},
+		{"page", PoolQueryOptions{Limit: 2, Offset: 1}, "[mid leaf]"},
+	}
+	for _, tc := range cases {
+		got, err := m.QueryPools(ctx, tc.opts)
+		if err != nil {
+			t.Fatalf("%s: QueryPools: %v", tc.name, err)
+		}
+		if names(got) != tc.want {
+			t.Errorf("%s: QueryPools = %s, want %s", tc.name, names(got), tc.want)
+		}
+	}
+
+	if n, err := m.CountPools(ctx, PoolQueryOptions{CIDRWithin: "10.0.0.0/8", Limit: 1}); err != nil || n != 3 {
+		t.Errorf("CountPools = %d, %v; want 3", n, err)
+	}
+	if _, err := m.QueryPools(ctx, PoolQueryOptions{OrderBy: "bogus"}); !errors.Is(err, ErrValidation) {
+		t.Errorf("bad order_by: expected ErrValidation, got %v", err)
+	}
+	if _, err := m.CountPools(ctx, PoolQueryOptions{CIDRContains: "nope"}); !errors.Is(err, ErrValidation) {
+		t.Errorf("bad cidr: expected ErrValidation, got %v", err)
+	}
+
+	accts, err := m.QueryAccounts(ctx, AccountQueryOptions{Environment: "prod", OrderBy: "key", OrderDesc: true})
+	if err != nil {
+		t.Fatalf("QueryAccounts: %v", err)
+	}
+	if len(accts) != 2 || accts[0].Key != "gcp:333" || accts[1].Key != "aws:111" {
+		t.Errorf("QueryAccounts(prod) = %+v", accts)
+	}
+	if got, _ := m.QueryAccounts(ctx, AccountQueryOptions{Region: "us-east-1"}); len(got) != 1 || got[0].ID != aws.ID {
+		t.Errorf("QueryAccounts(region) = %+v", got)
+	}
+	if n, _ := m.CountAccounts(ctx, AccountQueryOptions{KeyPrefix: "aws:"}); n != 2 {
+		t.Errorf("CountAccounts(aws:) = %d, want 2", n)
+	}
+	if _, err := m.QueryAccounts(ctx, AccountQueryOptions{OrderBy: "cidr"}); !errors.Is(err, ErrValidation) {
+		t.Errorf("bad order_by: expected ErrValidation, got %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"context"
+	"net/netip"
+	"time"
+
+	"cloudpam/internal/cidr"
+	"cloudpam/internal/domain"
+)
+
+// ReservationStore persists CIDR reservations.
+type ReservationStore interface {
+	// CreateReservation stores a new reservation. It fails with ErrConflict
+	// when a reservation under the same parent already holds an overlapping
+	// block at r.CreatedAt.
+	CreateReservation(ctx context.Context, r domain.Reservation) error
+
+	// GetReservation returns a reservation by ID.
+	GetReservation(ctx context.Context, id string) (*domain.Reservation, error)
+
+	// ListReservations returns reservations with the given status, or all
+	// of them when status is empty, newest first.
+	ListReservations(ctx context.Context, status domain.ReservationStatus) ([]domain.Reservation, error)
+
+	// UpdateReservation saves the owner, reason and expiry of an active
+	// reservation. It returns ErrConflict if the reservation is no longer
+	// active.
+	UpdateReservation(ctx context.Context, r domain.Reservation) error
+
+	// ReleaseReservation ends an active reservation, recording its status
+	// and the released fields. It returns ErrConflict if the reservation is
+	// no longer active.
+	ReleaseReservation(ctx context.Context, r domain.Reservation) error
+
+	// ExpireReservations marks the active reservations whose expiry is at
+	// or before now as expired, released by "system", and returns them.
+	ExpireReservations(ctx context.Context, now time.Time) ([]domain.Reservation, error)
+}
+
+// ReservationSystemActor is recorded as ReleasedBy on expired reservations.
+const ReservationSystemActor = "system"
+
+// OverlappingReservation returns the first reservation in held that holds a
+// block overlapping prefix under the same parent at now, or nil. A nil
+// parentID means a top-level pool.
+func OverlappingReservation(held []domain.Reservation, prefix string, parentID *int64, now time.Time) *domain.Reservation {
+	pfx, err := netip.ParsePrefix(prefix)
+	if err != nil {
+		return nil
+	}
+	for i, r := range held {
+		if !r.HoldsAt(now) || !sameParent(r.ParentID, parentID) {
+			continue
+		}
+		rp, err := netip.ParsePrefix(r.CIDR)
+		if err != nil {
+			continue
+		}
+		if cidr.PrefixesOverlap(pfx.Masked(), rp.Masked()) {
+			return &held[i]
+		}
+	}
+	return nil
+}
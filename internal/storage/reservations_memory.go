@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"cloudpam/internal/domain"
+)
+
+// MemoryReservationStore is an in-memory implementation of
+// ReservationStore.
+type MemoryReservationStore struct {
+	mu           sync.RWMutex
+	reservations map[string]domain.Reservation
+}
+
+// NewMemoryReservationStore creates a new in-memory reservation store.
+func NewMemoryReservationStore() *MemoryReservationStore {
+	return &MemoryReservationStore{reservations: make(map[string]domain.Reservation)}
+}
+
+func (s *MemoryReservationStore) CreateReservation(_ context.Context, r domain.Reservation) error {
+	if r.ID == "" {
+		return ErrValidation
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.reservations[r.ID]; exists {
+		return ErrConflict
+	}
+	held := make([]domain.Reservation, 0, len(s.reservations))
+	for _, existing := range s.reservations {
+		held = append(held, existing)
+	}
+	if h := OverlappingReservation(held, r.CIDR, r.ParentID, r.CreatedAt); h != nil {
+		return fmt.Errorf("%s is reserved by %s (%s): %w", r.CIDR, h.ID, h.CIDR, ErrConflict)
+	}
+	s.reservations[r.ID] = cloneReservation(r)
+	return nil
+}
+
+func (s *MemoryReservationStore) GetReservation(_ context.Context, id string) (*domain.Reservation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r, ok := s.reservations[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	out := cloneReservation(r)
+	return &out, nil
+}
+
+func (s *MemoryReservationStore) ListReservations(_ context.Context, status domain.ReservationStatus) ([]domain.Reservation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]domain.Reservation, 0, len(s.reservations))
+	for _, r := range s.reservations {
+		if status != "" && r.Status != status {
+			continue
+		}
+		out = append(out, cloneReservation(r))
+	}
+	sortReservations(out)
+	return out, nil
+}
+
+func (s *MemoryReservationStore) UpdateReservation(_ context.Context, r domain.Reservation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, err := s.activeLocked(r.ID)
+	if err != nil {
+		return err
+	}
+	existing.Owner = r.Owner
+	existing.Reason = r.Reason
+	existing.ExpiresAt = r.ExpiresAt
+	s.reservations[r.ID] = existing
+	return nil
+}
+
+func (s *MemoryReservationStore) ReleaseReservation(_ context.Context, r domain.Reservation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, err := s.activeLocked(r.ID)
+	if err != nil {
+		return err
+	}
+	existing.Status = r.Status
+	existing.ReleasedBy = r.ReleasedBy
+	existing.ReleasedAt = r.ReleasedAt
+	s.reservations[r.ID] = cloneReservation(existing)
+	return nil
+}
+
+func (s *MemoryReservationStore) ExpireReservations(_ context.Context, now time.Time) ([]domain.Reservation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []domain.Reservation
+	for id, r := range s.reservations {
+		if r.Status != domain.ReservationActive || r.ExpiresAt.After(now) {
+			continue
+		}
+		released := now
+		r.Status = domain.ReservationExpired
+		r.ReleasedBy = ReservationSystemActor
+		r.ReleasedAt = &released
+		s.reservations[id] = r
+		out = append(out, cloneReservation(r))
+	}
+	sortReservations(out)
+	return out, nil
+}
+
+// activeLocked returns the reservation with id if it is still active.
+// Callers hold s.mu.
+func (s *MemoryReservationStore) activeLocked(id string) (domain.Reservation, error) {
+	existing, ok := s.reservations[id]
+	if !ok {
+		return existing, ErrNotFound
+	}
+	if existing.Status != domain.ReservationActive {
+		return existing, fmt.Errorf("reservation %s is %s: %w", id, existing.Status, ErrConflict)
+	}
+	return existing, nil
+}
+
+func sortReservations(out []domain.Reservation) {
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.After(out[j].CreatedAt)
+		}
+		return out[i].ID > out[j].ID
+	})
+}
+
+func cloneReservation(r domain.Reservation) domain.Reservation {
+	r.ParentID = cloneInt64Ptr(r.ParentID)
+	if r.ReleasedAt != nil {
+		t := *r.ReleasedAt
+		r.ReleasedAt = &t
+	}
+	return r
+}
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cloudpam/internal/domain"
+)
+
+func TestMemoryReservationStore(t *testing.T) {
+	store := NewMemoryReservationStore()
+	ctx := context.Background()
+	now := time.Now().UTC()
+	parent := int64(1)
+
+	r := domain.Reservation{
+		ID: "r1", CIDR: "10.0.0.0/16", ParentID: &parent, Owner: "alice", Reason: "prod rollout",
+		Status: domain.ReservationActive, ExpiresAt: now.Add(time.Hour), CreatedBy: "alice", CreatedAt: now.Add(-time.Minute),
+	}
+	if err := store.CreateReservation(ctx, r); err != nil {
+		t.Fatalf("CreateReservation: %v", err)
+	}
+	if err := store.CreateReservation(ctx, r); !errors.Is(err, ErrConflict) {
+		t.Fatalf("duplicate CreateReservation: expected ErrConflict, got %v", err)
+	}
+
+	// The reservation holds 10.0.0.0/16 under parent 1, but not elsewhere.
+	overlap := r
+	overlap.ID, overlap.CIDR, overlap.CreatedAt = "r2", "10.0.128.0/17", now
+	if err := store.CreateReservation(ctx, overlap); !errors.Is(err, ErrConflict) {
+		t.Fatalf("overlapping reservation: expected ErrConflict, got %v", err)
+	}
+	overlap.ParentID = nil
+	if err := store.CreateReservation(ctx, overlap); err != nil {
+		t.Fatalf("same block at top level: %v", err)
+	}
+
+	got, err := store.GetReservation(ctx, "r1")
+	if err != nil {
+		t.Fatalf("GetReservation: %v", err)
+	}
+	// Returned values are copies.
+	*got.ParentID = 9
+	if again, _ := store.GetReservation(ctx, "r1"); *again.ParentID != 1 {
+		t.Fatal("GetReservation returned shared state")
+	}
+	if _, err := store.GetReservation(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("missing: expected ErrNotFound, got %v", err)
+	}
+
+	list, _ := store.ListReservations(ctx, "")
+	if len(list) != 2 || list[0].ID != "r2" {
+		t.Fatalf("ListReservations = %+v, want newest first", list)
+	}
+
+	r.Owner, r.ExpiresAt = "bob", now.Add(2*time.Hour)
+	if err := store.UpdateReservation(ctx, r); err != nil {
+		t.Fatalf("UpdateReservation: %v", err)
+	}
+	if got, _ := store.GetReservation(ctx, "r1"); got.Owner != "bob" || !got.ExpiresAt.Equal(r.ExpiresAt) {
+		t.Fatalf("updated = %+v", got)
+	}
+
+	// Expiry releases only the reservations past their expiry.
+	expired, err := store.ExpireReservations(ctx, now.Add(90*time.Minute))
+	if err != nil {
+		t.Fatalf("ExpireReservations: %v", err)
+	}
+	if len(expired) != 1 || expired[0].ID != "r2" || expired[0].Status != domain.ReservationExpired ||
+		expired[0].ReleasedBy != ReservationSystemActor || expired[0].ReleasedAt == nil {
+		t.Fatalf("expired = %+v", expired)
+	}
+
+	releasedAt := now
+	r.Status, r.ReleasedBy, r.ReleasedAt = domain.ReservationReleased, "bob", &releasedAt
+	if err := store.ReleaseReservation(ctx, r); err != nil {
+		t.Fatalf("ReleaseReservation: %v", err)
+	}
+	if err := store.ReleaseReservation(ctx, r); !errors.Is(err, ErrConflict) {
+		t.Fatalf("second release: expected ErrConflict, got %v", err)
+	}
+	if err := store.UpdateReservation(ctx, r); !errors.Is(err, ErrConflict) {
+		t.Fatalf("update released: expected ErrConflict, got %v", err)
+	}
+	if active, _ := store.ListReservations(ctx, domain.ReservationActive); len(active) != 0 {
+		t.Fatalf("active = %+v", active)
+	}
+
+	// Released and expired reservations no longer hold their block.
+	again := r
+	again.ID, again.Status, again.ReleasedBy, again.ReleasedAt = "r3", domain.ReservationActive, "", nil
+	if err := store.CreateReservation(ctx, again); err != nil {
+		t.Fatalf("re-reserve released block: %v", err)
+	}
+}
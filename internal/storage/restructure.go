@@ -0,0 +1,177 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+	"time"
+
+	"cloudpam/internal/cidr"
+	"cloudpam/internal/domain"
+)
+
+// PoolRestructurer changes the address space of existing pools. Each call
+// loads the affected part of the hierarchy and re-checks it before writing,
+// in one atomic step, so a change planned earlier cannot leave overlapping
+// or orphaned blocks behind.
+type PoolRestructurer interface {
+	// ResizePool moves a pool to a new CIDR. The new block must stay inside
+	// the parent, must not overlap a sibling or one of the held blocks and
+	// must still hold every child pool and recorded IP address; otherwise
+	// ErrConflict is returned and nothing changes. held lists the blocks
+	// that reservations and pending change requests keep under the pool's
+	// parent (see HeldPrefixes). It returns ErrNotFound if the pool does not
+	// exist.
+	ResizePool(ctx context.Context, id int64, cidr string, held []netip.Prefix) (domain.Pool, error)
+
+	// AggregatePools inserts a pool covering exactly the given sibling pools
+	// and moves them under it. The aggregate takes the members' parent;
+	// in.ParentID is ignored. The members must share a parent and fill
+	// in.CIDR between them, and no other sibling may overlap it; otherwise
+	// ErrConflict is returned and nothing changes. It returns ErrNotFound if
+	// a member does not exist.
+	AggregatePools(ctx context.Context, ids []int64, in domain.CreatePool) (domain.Pool, error)
+}
+
+// CheckResize validates moving pool to next and returns the parsed block.
+// parent is nil for a top-level pool; siblings are the live pools sharing
+// its parent, children its live child pools, addresses the IP addresses
+// recorded in it and held the blocks held under its parent.
+func CheckResize(pool domain.Pool, next string, parent *domain.Pool, siblings, children []domain.Pool, addresses []string, held []netip.Prefix) (netip.Prefix, error) {
+	np, err := netip.ParsePrefix(strings.TrimSpace(next))
+	if err != nil || np != np.Masked() {
+		return netip.Prefix{}, fmt.Errorf("invalid cidr %q: %w", next, ErrValidation)
+	}
+	cur, err := netip.ParsePrefix(pool.CIDR)
+	if err != nil || cur.Addr().Is4() != np.Addr().Is4() {
+		return netip.Prefix{}, fmt.Errorf("cannot move pool %d from %s to %s: %w", pool.ID, pool.CIDR, np, ErrValidation)
+	}
+	if err := checkWithinParent(np, parent); err != nil {
+		return netip.Prefix{}, err
+	}
+	for _, sib := range siblings {
+		if sib.ID == pool.ID {
+			continue
+		}
+		if sp, err := netip.ParsePrefix(sib.CIDR); err == nil && cidr.PrefixesOverlap(np, sp) {
+			return netip.Prefix{}, fmt.Errorf("%s overlaps sibling pool %d (%s): %w", np, sib.ID, sib.CIDR, ErrConflict)
+		}
+	}
+	for _, h := range held {
+		if cidr.PrefixesOverlap(np, h.Masked()) {
+			return netip.Prefix{}, fmt.Errorf("%s overlaps held block %s: %w", np, h, ErrConflict)
+		}
+	}
+	for _, ch := range children {
+		if cp, err := netip.ParsePrefix(ch.CIDR); err != nil || !cidr.PrefixContains(np, cp) {
+			return netip.Prefix{}, fmt.Errorf("%s does not contain child pool %d (%s): %w", np, ch.ID, ch.CIDR, ErrConflict)
+		}
+	}
+	for _, a := range addresses {
+		if addr, err := netip.ParseAddr(a); err == nil && !cidr.PrefixContainsAddr(np, addr) {
+			return netip.Prefix{}, fmt.Errorf("%s does not contain recorded address %s: %w", np, a, ErrConflict)
+		}
+	}
+	return np, nil
+}
+
+// HeldPrefixes returns the blocks that pending change requests and the
+// reservations active at now hold under parentID. A nil parentID means a
+// top-level pool.
+func HeldPrefixes(requests []domain.PoolChangeRequest, reservations []domain.Reservation, parentID *int64, now time.Time) []netip.Prefix {
+	var out []netip.Prefix
+	for _, r := range requests {
+		if !r.HoldsCIDR() || !sameParent(r.ParentID, parentID) {
+			continue
+		}
+		if pfx, err := netip.ParsePrefix(r.CIDR); err == nil {
+			out = append(out, pfx)
+		}
+	}
+	for _, r := range reservations {
+		if !r.HoldsAt(now) || !sameParent(r.ParentID, parentID) {
+			continue
+		}
+		if pfx, err := netip.ParsePrefix(r.CIDR); err == nil {
+			out = append(out, pfx)
+		}
+	}
+	return out
+}
+
+// CheckAggregate validates inserting an aggregate pool over members. parent
+// is the members' parent, nil at the top level; siblings are the live pools
+// under it, members included.
+func CheckAggregate(members []domain.Pool, aggregate string, parent *domain.Pool, siblings []domain.Pool) (netip.Prefix, error) {
+	ap, err := netip.ParsePrefix(strings.TrimSpace(aggregate))
+	if err != nil || ap != ap.Masked() {
+		return netip.Prefix{}, fmt.Errorf("invalid cidr %q: %w", aggregate, ErrValidation)
+	}
+	if len(members) < 2 {
+		return netip.Prefix{}, fmt.Errorf("an aggregate needs at least two pools: %w", ErrValidation)
+	}
+	if err := checkWithinParent(ap, parent); err != nil {
+		return netip.Prefix{}, err
+	}
+	if pp, err := netip.ParsePrefix(parentCIDR(parent)); err == nil && pp.Masked() == ap {
+		return netip.Prefix{}, fmt.Errorf("the pools already fill parent pool %d: %w", parent.ID, ErrConflict)
+	}
+	isMember := make(map[int64]bool, len(members))
+	var covered cidr.Uint128
+	prefixes := make([]netip.Prefix, 0, len(members))
+	for _, m := range members {
+		if !sameParent(m.ParentID, members[0].ParentID) {
+			return netip.Prefix{}, fmt.Errorf("pools %d and %d have different parents: %w", members[0].ID, m.ID, ErrConflict)
+		}
+		mp, err := netip.ParsePrefix(m.CIDR)
+		if err != nil || !cidr.PrefixContains(ap, mp) {
+			return netip.Prefix{}, fmt.Errorf("pool %d (%s) is outside %s: %w", m.ID, m.CIDR, ap, ErrConflict)
+		}
+		for _, other := range prefixes {
+			if cidr.PrefixesOverlap(other, mp) {
+				return netip.Prefix{}, fmt.Errorf("pool %d (%s) overlaps another member: %w", m.ID, m.CIDR, ErrConflict)
+			}
+		}
+		prefixes = append(prefixes, mp)
+		covered = covered.Add(cidr.AddressCount(mp))
+		isMember[m.ID] = true
+	}
+	if covered.Cmp(cidr.AddressCount(ap)) != 0 {
+		return netip.Prefix{}, fmt.Errorf("pools do not fill %s: %w", ap, ErrConflict)
+	}
+	for _, sib := range siblings {
+		if isMember[sib.ID] {
+			continue
+		}
+		if sp, err := netip.ParsePrefix(sib.CIDR); err == nil && cidr.PrefixesOverlap(ap, sp) {
+			return netip.Prefix{}, fmt.Errorf("%s overlaps sibling pool %d (%s): %w", ap, sib.ID, sib.CIDR, ErrConflict)
+		}
+	}
+	return ap, nil
+}
+
+func checkWithinParent(p netip.Prefix, parent *domain.Pool) error {
+	if parent == nil {
+		return nil
+	}
+	pp, err := netip.ParsePrefix(parent.CIDR)
+	if err != nil || !cidr.PrefixContains(pp, p) {
+		return fmt.Errorf("%s is outside parent pool %d (%s): %w", p, parent.ID, parent.CIDR, ErrConflict)
+	}
+	return nil
+}
+
+func parentCIDR(parent *domain.Pool) string {
+	if parent == nil {
+		return ""
+	}
+	return parent.CIDR
+}
+
+func sameParent(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
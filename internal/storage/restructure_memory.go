@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"time"
+
+	"cloudpam/internal/domain"
+)
+
+var _ PoolRestructurer = (*MemoryStore)(nil)
+
+// ResizePool checks and moves a pool to a new CIDR under the store's write
+// lock.
+func (m *MemoryStore) ResizePool(ctx context.Context, id int64, cidrStr string, held []netip.Prefix) (domain.Pool, error) {
+	m.lockWrite()
+	defer m.unlockWrite()
+
+	p, ok := m.pools[id]
+	if !ok || p.DeletedAt != nil {
+		return domain.Pool{}, fmt.Errorf("pool not found: %w", ErrNotFound)
+	}
+	parent := m.liveParentLocked(p.ParentID)
+	var addresses []string
+	if m.ipAddresses != nil {
+		addresses = m.ipAddresses.poolAddressesLocked(id)
+	}
+	next, err := CheckResize(p, cidrStr, parent, m.liveChildrenLocked(p.ParentID), m.liveChildrenLocked(&id), addresses, held)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+	p.CIDR = next.String()
+	p.UpdatedAt = time.Now().UTC()
+	p.Version++
+	m.pools[id] = p
+	m.cidrIdx.Store(nil)
+	return clonePool(p), nil
+}
+
+// AggregatePools checks the members, inserts the aggregate and re-parents
+// the members under the store's write lock.
+func (m *MemoryStore) AggregatePools(ctx context.Context, ids []int64, in domain.CreatePool) (domain.Pool, error) {
+	m.lockWrite()
+	defer m.unlockWrite()
+
+	members := make([]domain.Pool, 0, len(ids))
+	for _, id := range ids {
+		p, ok := m.pools[id]
+		if !ok || p.DeletedAt != nil {
+			return domain.Pool{}, fmt.Errorf("pool %d not found: %w", id, ErrNotFound)
+		}
+		members = append(members, p)
+	}
+	var parentID *int64
+	if len(members) > 0 {
+		parentID = members[0].ParentID
+	}
+	if _, err := CheckAggregate(members, in.CIDR, m.liveParentLocked(parentID), m.liveChildrenLocked(parentID)); err != nil {
+		return domain.Pool{}, err
+	}
+	in.ParentID = parentID
+	agg, err := m.createPoolLocked(in)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+	now := time.Now().UTC()
+	for _, p := range members {
+		aggID := agg.ID
+		p.ParentID = &aggID
+		p.UpdatedAt = now
+		p.Version++
+		m.pools[p.ID] = p
+	}
+	return agg, nil
+}
+
+// liveParentLocked returns the live pool with the given ID, or nil.
+func (m *MemoryStore) liveParentLocked(id *int64) *domain.Pool {
+	if id == nil {
+		return nil
+	}
+	p, ok := m.pools[*id]
+	if !ok || p.DeletedAt != nil {
+		return nil
+	}
+	return &p
+}
+
+// liveChildrenLocked returns the live pools directly under parentID, or the
+// live top-level pools when parentID is nil.
+func (m *MemoryStore) liveChildrenLocked(parentID *int64) []domain.Pool {
+	var out []domain.Pool
+	for _, p := range m.pools {
+		if p.DeletedAt == nil && sameParent(p.ParentID, parentID) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
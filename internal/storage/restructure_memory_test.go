@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+	"time"
+
+	"cloudpam/internal/domain"
+)
+
+func TestMemoryResizePool(t *testing.T) {
+	m := NewMemoryStore()
+	ips := NewMemoryIPAddressStore(m)
+	ctx := context.Background()
+
+	root, _ := m.CreatePool(ctx, domain.CreatePool{Name: "root", CIDR: "10.0.0.0/16"})
+	a, _ := m.CreatePool(ctx, domain.CreatePool{Name: "a", CIDR: "10.0.0.0/22", ParentID: &root.ID})
+	if _, err := m.CreatePool(ctx, domain.CreatePool{Name: "b", CIDR: "10.0.4.0/22", ParentID: &root.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.CreatePool(ctx, domain.CreatePool{Name: "a-1", CIDR: "10.0.1.0/24", ParentID: &a.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ips.CreateIPAddress(ctx, domain.IPAddress{ID: "ip-1", PoolID: a.ID, Address: "10.0.2.10"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		cidr string
+		want error
+	}{
+		{"10.0.0.0/21", ErrConflict},   // overlaps sibling b
+		{"10.0.1.0/24", ErrConflict},   // drops the recorded address
+		{"10.0.2.0/23", ErrConflict},   // drops child a-1
+		{"10.1.0.0/22", ErrConflict},   // outside the parent
+		{"10.0.0.1/22", ErrValidation}, // not a network address
+		{"fd00::/64", ErrValidation},   // other family
+	}
+	for _, tc := range cases {
+		if _, err := m.ResizePool(ctx, a.ID, tc.cidr, nil); !errors.Is(err, tc.want) {
+			t.Errorf("ResizePool(%s) = %v, want %v", tc.cidr, err, tc.want)
+		}
+	}
+
+	if err := ips.DeleteIPAddress(ctx, "ip-1"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := m.ResizePool(ctx, a.ID, "10.0.0.0/23", nil)
+	if err != nil {
+		t.Fatalf("ResizePool: %v", err)
+	}
+	if got.CIDR != "10.0.0.0/23" || got.Version != a.Version+1 {
+		t.Errorf("resized pool = %+v", got)
+	}
+	// The CIDR index follows the move.
+	hits, err := m.FindContaining(ctx, "10.0.3.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range hits {
+		if p.ID == a.ID {
+			t.Errorf("FindContaining still returns %s for 10.0.3.1", p.CIDR)
+		}
+	}
+	if _, err := m.ResizePool(ctx, 999, "10.0.0.0/24", nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("ResizePool missing: %v", err)
+	}
+
+	// A block held by a reservation or pending request is off limits too.
+	c, _ := m.CreatePool(ctx, domain.CreatePool{Name: "c", CIDR: "10.0.12.0/23", ParentID: &root.ID})
+	held := []netip.Prefix{netip.MustParsePrefix("10.0.14.0/24")}
+	if _, err := m.ResizePool(ctx, c.ID, "10.0.12.0/22", held); !errors.Is(err, ErrConflict) {
+		t.Errorf("ResizePool over held block = %v, want ErrConflict", err)
+	}
+	if got, _, _ := m.GetPool(ctx, c.ID); got.CIDR != "10.0.12.0/23" {
+		t.Errorf("rejected resize moved pool to %s", got.CIDR)
+	}
+}
+
+func TestHeldPrefixes(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	parent := int64(1)
+	other := int64(2)
+	requests := []domain.PoolChangeRequest{
+		{Operation: domain.PoolChangeCreate, Status: domain.PoolChangeRequestPending, CIDR: "10.0.1.0/24", ParentID: &parent},
+		{Operation: domain.PoolChangeCreate, Status: domain.PoolChangeRequestApplied, CIDR: "10.0.2.0/24", ParentID: &parent},
+		{Operation: domain.PoolChangeCreate, Status: domain.PoolChangeRequestPending, CIDR: "10.0.3.0/24", ParentID: &other},
+	}
+	reservations := []domain.Reservation{
+		{CIDR: "10.0.4.0/24", ParentID: &parent, Status: domain.ReservationActive, ExpiresAt: now.Add(time.Hour)},
+		{CIDR: "10.0.5.0/24", ParentID: &parent, Status: domain.ReservationActive, ExpiresAt: now.Add(-time.Hour)},
+		{CIDR: "10.0.6.0/24", Status: domain.ReservationActive, ExpiresAt: now.Add(time.Hour)},
+	}
+	got := HeldPrefixes(requests, reservations, &parent, now)
+	if len(got) != 2 || got[0].String() != "10.0.1.0/24" || got[1].String() != "10.0.4.0/24" {
+		t.Errorf("HeldPrefixes(parent) = %v", got)
+	}
+	if top := HeldPrefixes(requests, reservations, nil, now); len(top) != 1 || top[0].String() != "10.0.6.0/24" {
+		t.Errorf("HeldPrefixes(top level) = %v", top)
+	}
+}
+
+func TestMemoryAggregatePools(t *testing.T) {
+	m := NewMemoryStore()
+	ctx := context.Background()
+
+	root, _ := m.CreatePool(ctx, domain.CreatePool{Name: "root", CIDR: "10.0.0.0/16"})
+	a, _ := m.CreatePool(ctx, domain.CreatePool{Name: "a", CIDR: "10.0.0.0/24", ParentID: &root.ID})
+	b, _ := m.CreatePool(ctx, domain.CreatePool{Name: "b", CIDR: "10.0.1.0/24", ParentID: &root.ID})
+	c, _ := m.CreatePool(ctx, domain.CreatePool{Name: "c", CIDR: "10.0.2.0/24", ParentID: &root.ID})
+	other, _ := m.CreatePool(ctx, domain.CreatePool{Name: "other", CIDR: "192.168.0.0/24"})
+
+	cases := []struct {
+		ids  []int64
+		cidr string
+		want error
+	}{
+		{[]int64{a.ID}, "10.0.0.0/24", ErrValidation},           // one member
+		{[]int64{a.ID, b.ID}, "10.0.0.1/23", ErrValidation},     // not a network address
+		{[]int64{a.ID, 999}, "10.0.0.0/23", ErrNotFound},        // missing member
+		{[]int64{a.ID, c.ID}, "10.0.0.0/22", ErrConflict},       // leaves a hole
+		{[]int64{a.ID, b.ID, a.ID}, "10.0.0.0/23", ErrConflict}, // duplicate member
+		{[]int64{a.ID, other.ID}, "10.0.0.0/23", ErrConflict},   // different parents
+		{[]int64{a.ID, b.ID}, "10.0.0.0/23", nil},
+		{[]int64{a.ID, b.ID}, "10.0.0.0/23", ErrConflict}, // already aggregated
+	}
+	var agg domain.Pool
+	for i, tc := range cases {
+		got, err := m.AggregatePools(ctx, tc.ids, domain.CreatePool{Name: "agg", CIDR: tc.cidr, Type: domain.PoolTypeSupernet})
+		if !errors.Is(err, tc.want) {
+			t.Errorf("case %d: AggregatePools(%v, %s) = %v, want %v", i, tc.ids, tc.cidr, err, tc.want)
+		}
+		if err == nil {
+			agg = got
+		}
+	}
+	if agg.ID == 0 || agg.ParentID == nil || *agg.ParentID != root.ID {
+		t.Fatalf("aggregate = %+v", agg)
+	}
+	for _, id := range []int64{a.ID, b.ID} {
+		p, _, _ := m.GetPool(ctx, id)
+		if p.ParentID == nil || *p.ParentID != agg.ID {
+			t.Errorf("pool %d parent = %v, want %d", id, p.ParentID, agg.ID)
+		}
+	}
+	if p, _, _ := m.GetPool(ctx, c.ID); p.ParentID == nil || *p.ParentID != root.ID {
+		t.Errorf("pool c moved: %+v", p)
+	}
+}
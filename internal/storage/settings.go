@@ -12,4 +12,10 @@ type SettingsStore interface {
 	UpdateSecuritySettings(ctx context.Context, settings *domain.SecuritySettings) error
 	GetNetworkSchemaPolicy(ctx context.Context) (*domain.NetworkSchemaPolicy, error)
 	UpdateNetworkSchemaPolicy(ctx context.Context, policy *domain.NetworkSchemaPolicy) error
+	GetAlertSettings(ctx context.Context) (*domain.AlertSettings, error)
+	UpdateAlertSettings(ctx context.Context, settings *domain.AlertSettings) error
+	GetAuditRetentionSettings(ctx context.Context) (*domain.AuditRetentionSettings, error)
+	UpdateAuditRetentionSettings(ctx context.Context, settings *domain.AuditRetentionSettings) error
+	GetApprovalSettings(ctx context.Context) (*domain.ApprovalSettings, error)
+	UpdateApprovalSettings(ctx context.Context, settings *domain.ApprovalSettings) error
 }
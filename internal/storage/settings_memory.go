@@ -12,6 +12,9 @@ type MemorySettingsStore struct {
 	mu                  sync.RWMutex
 	security            *domain.SecuritySettings
 	networkSchemaPolicy *domain.NetworkSchemaPolicy
+	alerts              *domain.AlertSettings
+	auditRetention      domain.AuditRetentionSettings
+	approvals           *domain.ApprovalSettings
 }
 
 // cloneSecuritySettings deep-copies security settings so store-owned state and
@@ -37,11 +40,72 @@ func cloneSecuritySettings(in *domain.SecuritySettings) *domain.SecuritySettings
 	return &out
 }
 
+// cloneAlertSettings deep-copies alert settings, including each rule's pool
+// pointer and channel lists.
+func cloneAlertSettings(in *domain.AlertSettings) *domain.AlertSettings {
+	if in == nil {
+		return nil
+	}
+	out := domain.AlertSettings{}
+	if in.Rules != nil {
+		out.Rules = make([]domain.AlertRule, len(in.Rules))
+		for i, rule := range in.Rules {
+			if rule.PoolID != nil {
+				id := *rule.PoolID
+				rule.PoolID = &id
+			}
+			if rule.Channels != nil {
+				rule.Channels = append([]string(nil), rule.Channels...)
+			}
+			out.Rules[i] = rule
+		}
+	}
+	if in.Channels != nil {
+		out.Channels = make([]domain.NotificationChannel, len(in.Channels))
+		for i, ch := range in.Channels {
+			if ch.To != nil {
+				ch.To = append([]string(nil), ch.To...)
+			}
+			out.Channels[i] = ch
+		}
+	}
+	return &out
+}
+
+// cloneApprovalSettings deep-copies approval settings, including each
+// policy's selector lists, tags and subtree pointer.
+func cloneApprovalSettings(in *domain.ApprovalSettings) *domain.ApprovalSettings {
+	if in == nil {
+		return nil
+	}
+	out := domain.ApprovalSettings{}
+	if in.Policies != nil {
+		out.Policies = make([]domain.ApprovalPolicy, len(in.Policies))
+		for i, p := range in.Policies {
+			p.Operations = cloneSlice(p.Operations)
+			p.PoolTypes = cloneSlice(p.PoolTypes)
+			p.Statuses = cloneSlice(p.Statuses)
+			p.Tags = cloneStringStringMap(p.Tags)
+			p.SubtreePoolID = cloneInt64Ptr(p.SubtreePoolID)
+			out.Policies[i] = p
+		}
+	}
+	return &out
+}
+
 // NewMemorySettingsStore creates a new in-memory settings store with defaults.
 func NewMemorySettingsStore() *MemorySettingsStore {
 	defaults := domain.DefaultSecuritySettings()
 	policy := domain.DefaultNetworkSchemaPolicy()
-	return &MemorySettingsStore{security: cloneSecuritySettings(&defaults), networkSchemaPolicy: &policy}
+	alerts := domain.DefaultAlertSettings()
+	approvals := domain.DefaultApprovalSettings()
+	return &MemorySettingsStore{
+		security:            cloneSecuritySettings(&defaults),
+		networkSchemaPolicy: &policy,
+		alerts:              &alerts,
+		auditRetention:      domain.DefaultAuditRetentionSettings(),
+		approvals:           &approvals,
+	}
 }
 
 func (s *MemorySettingsStore) GetSecuritySettings(_ context.Context) (*domain.SecuritySettings, error) {
@@ -70,3 +134,43 @@ func (s *MemorySettingsStore) UpdateNetworkSchemaPolicy(_ context.Context, polic
 	s.networkSchemaPolicy = domain.NormalizeNetworkSchemaPolicy(policy)
 	return nil
 }
+
+func (s *MemorySettingsStore) GetAlertSettings(_ context.Context) (*domain.AlertSettings, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return domain.NormalizeAlertSettings(cloneAlertSettings(s.alerts)), nil
+}
+
+func (s *MemorySettingsStore) UpdateAlertSettings(_ context.Context, settings *domain.AlertSettings) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.alerts = domain.NormalizeAlertSettings(cloneAlertSettings(settings))
+	return nil
+}
+
+func (s *MemorySettingsStore) GetAuditRetentionSettings(_ context.Context) (*domain.AuditRetentionSettings, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	dup := s.auditRetention
+	return &dup, nil
+}
+
+func (s *MemorySettingsStore) UpdateAuditRetentionSettings(_ context.Context, settings *domain.AuditRetentionSettings) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.auditRetention = *settings
+	return nil
+}
+
+func (s *MemorySettingsStore) GetApprovalSettings(_ context.Context) (*domain.ApprovalSettings, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return domain.NormalizeApprovalSettings(cloneApprovalSettings(s.approvals)), nil
+}
+
+func (s *MemorySettingsStore) UpdateApprovalSettings(_ context.Context, settings *domain.ApprovalSettings) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.approvals = domain.NormalizeApprovalSettings(cloneApprovalSettings(settings))
+	return nil
+}
@@ -92,3 +92,35 @@ func TestMemorySettingsStoreDefaultsAreNotShared(t *testing.T) {
 		t.Error("second store shares the default scope policy map with the first")
 	}
 }
+
+func TestMemorySettingsStoreAlertSettingsIsolated(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemorySettingsStore()
+
+	got, err := store.GetAlertSettings(ctx)
+	if err != nil {
+		t.Fatalf("GetAlertSettings: %v", err)
+	}
+	if len(got.Rules) != 1 || got.Rules[0].WarningPercent != 80 || got.Rules[0].CriticalPercent != 90 {
+		t.Fatalf("default alert settings = %+v", got)
+	}
+
+	poolID := int64(7)
+	got.Rules[0].PoolID = &poolID
+	got.Rules[0].Channels = []string{"ops"}
+	got.Channels = []domain.NotificationChannel{{ID: "ops", Type: domain.NotificationChannelSMTP, To: []string{"a@example.com"}}}
+	if err := store.UpdateAlertSettings(ctx, got); err != nil {
+		t.Fatalf("UpdateAlertSettings: %v", err)
+	}
+	poolID = 99
+	got.Rules[0].Channels[0] = "changed"
+	got.Channels[0].To[0] = "intruder@example.com"
+
+	after, err := store.GetAlertSettings(ctx)
+	if err != nil {
+		t.Fatalf("GetAlertSettings: %v", err)
+	}
+	if *after.Rules[0].PoolID != 7 || after.Rules[0].Channels[0] != "ops" || after.Channels[0].To[0] != "a@example.com" {
+		t.Errorf("caller mutation reached the store: %+v", after)
+	}
+}
@@ -0,0 +1,165 @@
+//go:build sqlite
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+var _ storage.AlertStore = (*Store)(nil)
+
+const alertColumns = `id, rule_id, pool_id, pool_name, pool_cidr, kind, severity, status, value, threshold, message,
+	opened_at, updated_at, acknowledged_at, acknowledged_by, resolved_at, resolved_by, notified_at`
+
+// CreateAlert stores a new alert.
+func (s *Store) CreateAlert(ctx context.Context, a domain.Alert) error {
+	_, err := s.q().ExecContext(ctx,
+		`INSERT INTO alerts (`+alertColumns+`)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		a.ID, a.RuleID, a.PoolID, a.PoolName, a.PoolCIDR, string(a.Kind), string(a.Severity), string(a.Status),
+		a.Value, a.Threshold, a.Message,
+		a.OpenedAt.UTC().Format(time.RFC3339), a.UpdatedAt.UTC().Format(time.RFC3339),
+		formatTimePtr(a.AcknowledgedAt), nilIfEmpty(a.AcknowledgedBy),
+		formatTimePtr(a.ResolvedAt), nilIfEmpty(a.ResolvedBy), formatTimePtr(a.NotifiedAt),
+	)
+	return storage.WrapIfConflict(err)
+}
+
+// GetAlert returns an alert by ID.
+func (s *Store) GetAlert(ctx context.Context, id string) (*domain.Alert, error) {
+	row := s.q().QueryRowContext(ctx, `SELECT `+alertColumns+` FROM alerts WHERE id = ?`, id)
+	a, err := scanAlert(row)
+	if err == sql.ErrNoRows {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// ListAlerts returns paginated alerts, most recently opened first.
+func (s *Store) ListAlerts(ctx context.Context, filters domain.AlertFilters) ([]domain.Alert, int, error) {
+	var where []string
+	var args []any
+	if filters.PoolID != 0 {
+		where = append(where, "pool_id = ?")
+		args = append(args, filters.PoolID)
+	}
+	switch filters.Status {
+	case "":
+	case domain.AlertStatusActive:
+		where = append(where, "status <> ?")
+		args = append(args, string(domain.AlertStatusResolved))
+	default:
+		where = append(where, "status = ?")
+		args = append(args, filters.Status)
+	}
+	if filters.Severity != "" {
+		where = append(where, "severity = ?")
+		args = append(args, filters.Severity)
+	}
+	if filters.Kind != "" {
+		where = append(where, "kind = ?")
+		args = append(args, filters.Kind)
+	}
+	whereClause := ""
+	if len(where) > 0 {
+		whereClause = " WHERE " + strings.Join(where, " AND ")
+	}
+
+	var total int
+	if err := s.q().QueryRowContext(ctx, "SELECT COUNT(*) FROM alerts"+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	page := filters.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := filters.PageSize
+	if pageSize < 1 {
+		pageSize = 50
+	}
+	args = append(args, pageSize, (page-1)*pageSize)
+	out, err := s.queryAlerts(ctx,
+		fmt.Sprintf(`SELECT `+alertColumns+` FROM alerts%s ORDER BY opened_at DESC, id DESC LIMIT ? OFFSET ?`, whereClause),
+		args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return out, total, nil
+}
+
+// ListActiveAlerts returns every open or acknowledged alert.
+func (s *Store) ListActiveAlerts(ctx context.Context) ([]domain.Alert, error) {
+	return s.queryAlerts(ctx,
+		`SELECT `+alertColumns+` FROM alerts WHERE status <> ? ORDER BY opened_at DESC, id DESC`,
+		string(domain.AlertStatusResolved))
+}
+
+// UpdateAlert replaces an alert's mutable fields.
+func (s *Store) UpdateAlert(ctx context.Context, a domain.Alert) error {
+	res, err := s.q().ExecContext(ctx,
+		`UPDATE alerts SET severity = ?, status = ?, value = ?, threshold = ?, message = ?, updated_at = ?,
+			acknowledged_at = ?, acknowledged_by = ?, resolved_at = ?, resolved_by = ?, notified_at = ?
+		 WHERE id = ?`,
+		string(a.Severity), string(a.Status), a.Value, a.Threshold, a.Message, a.UpdatedAt.UTC().Format(time.RFC3339),
+		formatTimePtr(a.AcknowledgedAt), nilIfEmpty(a.AcknowledgedBy),
+		formatTimePtr(a.ResolvedAt), nilIfEmpty(a.ResolvedBy), formatTimePtr(a.NotifiedAt),
+		a.ID,
+	)
+	if err != nil {
+		return storage.WrapIfConflict(err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("alert %s: %w", a.ID, storage.ErrNotFound)
+	}
+	return nil
+}
+
+func (s *Store) queryAlerts(ctx context.Context, query string, args ...any) ([]domain.Alert, error) {
+	rows, err := s.q().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []domain.Alert{}
+	for rows.Next() {
+		a, err := scanAlert(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+func scanAlert(row interface{ Scan(dest ...any) error }) (domain.Alert, error) {
+	var a domain.Alert
+	var kind, severity, status, openedAt, updatedAt string
+	var acknowledgedAt, acknowledgedBy, resolvedAt, resolvedBy, notifiedAt sql.NullString
+	if err := row.Scan(&a.ID, &a.RuleID, &a.PoolID, &a.PoolName, &a.PoolCIDR, &kind, &severity, &status,
+		&a.Value, &a.Threshold, &a.Message, &openedAt, &updatedAt,
+		&acknowledgedAt, &acknowledgedBy, &resolvedAt, &resolvedBy, &notifiedAt); err != nil {
+		return a, err
+	}
+	a.Kind = domain.AlertKind(kind)
+	a.Severity = domain.AlertSeverity(severity)
+	a.Status = domain.AlertStatus(status)
+	a.OpenedAt, _ = time.Parse(time.RFC3339, openedAt)
+	a.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+	a.AcknowledgedAt = parseTimePtr(acknowledgedAt)
+	a.AcknowledgedBy = acknowledgedBy.String
+	a.ResolvedAt = parseTimePtr(resolvedAt)
+	a.ResolvedBy = resolvedBy.String
+	a.NotifiedAt = parseTimePtr(notifiedAt)
+	return a, nil
+}
@@ -0,0 +1,103 @@
+//go:build sqlite
+
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+func TestAlertStore(t *testing.T) {
+	s, err := New("file:" + filepath.Join(t.TempDir(), "alerts.db"))
+	if err != nil {
+		t.Fatalf("new sqlite store: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	pool, err := s.CreatePool(ctx, domain.CreatePool{Name: "prod", CIDR: "10.0.0.0/24"})
+	if err != nil {
+		t.Fatalf("CreatePool: %v", err)
+	}
+	alert := domain.Alert{
+		ID: "a1", RuleID: "default", PoolID: pool.ID, PoolName: pool.Name, PoolCIDR: pool.CIDR,
+		Kind: domain.AlertKindUtilization, Severity: domain.AlertSeverityWarning, Status: domain.AlertStatusOpen,
+		Value: 87.5, Threshold: 80, Message: "Pool prod is 87.5% utilized", OpenedAt: now, UpdatedAt: now,
+	}
+	if err := s.CreateAlert(ctx, alert); err != nil {
+		t.Fatalf("CreateAlert: %v", err)
+	}
+	dup := alert
+	dup.ID = "a2"
+	if err := s.CreateAlert(ctx, dup); !errors.Is(err, storage.ErrConflict) {
+		t.Fatalf("duplicate active alert err = %v, want ErrConflict", err)
+	}
+
+	acked := now.Add(time.Hour)
+	alert.Status = domain.AlertStatusAcknowledged
+	alert.AcknowledgedAt, alert.AcknowledgedBy = &acked, "alice"
+	alert.NotifiedAt = &now
+	alert.UpdatedAt = acked
+	if err := s.UpdateAlert(ctx, alert); err != nil {
+		t.Fatalf("UpdateAlert: %v", err)
+	}
+	got, err := s.GetAlert(ctx, "a1")
+	if err != nil {
+		t.Fatalf("GetAlert: %v", err)
+	}
+	if got.Status != domain.AlertStatusAcknowledged || got.AcknowledgedBy != "alice" || !got.AcknowledgedAt.Equal(acked) ||
+		got.NotifiedAt == nil || got.ResolvedAt != nil || got.Value != 87.5 || !got.OpenedAt.Equal(now) {
+		t.Fatalf("GetAlert = %+v", got)
+	}
+
+	active, err := s.ListActiveAlerts(ctx)
+	if err != nil || len(active) != 1 {
+		t.Fatalf("ListActiveAlerts = %+v, %v", active, err)
+	}
+
+	alert.Status = domain.AlertStatusResolved
+	alert.ResolvedAt, alert.ResolvedBy = &acked, "system"
+	if err := s.UpdateAlert(ctx, alert); err != nil {
+		t.Fatalf("UpdateAlert(resolve): %v", err)
+	}
+	dup.OpenedAt = now.Add(2 * time.Hour)
+	if err := s.CreateAlert(ctx, dup); err != nil {
+		t.Fatalf("CreateAlert after resolve: %v", err)
+	}
+
+	items, total, err := s.ListAlerts(ctx, domain.AlertFilters{Status: domain.AlertStatusActive})
+	if err != nil || total != 1 || items[0].ID != "a2" {
+		t.Fatalf("ListAlerts(active) = %+v, %d, %v", items, total, err)
+	}
+	items, total, err = s.ListAlerts(ctx, domain.AlertFilters{PoolID: pool.ID, Kind: string(domain.AlertKindUtilization), PageSize: 1})
+	if err != nil || total != 2 || len(items) != 1 || items[0].ID != "a2" {
+		t.Fatalf("ListAlerts(pool) = %+v, %d, %v", items, total, err)
+	}
+
+	if _, err := s.GetAlert(ctx, "missing"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("GetAlert(missing) err = %v, want ErrNotFound", err)
+	}
+	dup.ID = "missing"
+	if err := s.UpdateAlert(ctx, dup); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("UpdateAlert(missing) err = %v, want ErrNotFound", err)
+	}
+
+	// Alert rules live in the settings table.
+	settings := domain.DefaultAlertSettings()
+	settings.Rules[0].ExhaustionDays = 30
+	settings.Channels = []domain.NotificationChannel{{ID: "chat", Type: domain.NotificationChannelSlack, URL: "https://hooks.example.com/x", Enabled: true}}
+	if err := s.UpdateAlertSettings(ctx, &settings); err != nil {
+		t.Fatalf("UpdateAlertSettings: %v", err)
+	}
+	loaded, err := s.GetAlertSettings(ctx)
+	if err != nil || loaded.Rules[0].ExhaustionDays != 30 || len(loaded.Channels) != 1 || loaded.Channels[0].URL != settings.Channels[0].URL {
+		t.Fatalf("GetAlertSettings = %+v, %v", loaded, err)
+	}
+}
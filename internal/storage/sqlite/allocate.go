@@ -0,0 +1,59 @@
+//go:build sqlite
+
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+var _ storage.PoolAllocator = (*Store)(nil)
+
+// AllocatePool picks and inserts a child pool in a single transaction.
+func (s *Store) AllocatePool(ctx context.Context, parentID int64, pick storage.AllocateFunc) (domain.Pool, error) {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Touch the parent before reading its children. The write takes SQLite's
+	// reserved lock up front, so a concurrent allocator waits here on
+	// busy_timeout rather than reading the same free space.
+	res, err := tx.ExecContext(ctx, `UPDATE pools SET updated_at = updated_at WHERE id = ? AND deleted_at IS NULL`, parentID)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.Pool{}, fmt.Errorf("parent pool not found: %w", storage.ErrNotFound)
+	}
+
+	parent, ok, err := getPool(ctx, tx, parentID)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+	if !ok {
+		return domain.Pool{}, fmt.Errorf("parent pool not found: %w", storage.ErrNotFound)
+	}
+	children, err := poolChildren(ctx, tx, parentID)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+
+	in, err := pick(parent, children)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+	in.ParentID = &parentID
+	p, err := createPool(ctx, tx, in)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.Pool{}, err
+	}
+	return p, nil
+}
@@ -0,0 +1,75 @@
+//go:build sqlite
+
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+func TestAllocatePool(t *testing.T) {
+	s, err := New("file:" + filepath.Join(t.TempDir(), "alloc.db"))
+	if err != nil {
+		t.Fatalf("new sqlite store: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	ctx := context.Background()
+
+	parent, err := s.CreatePool(ctx, domain.CreatePool{Name: "root", CIDR: "10.0.0.0/16"})
+	if err != nil {
+		t.Fatalf("create parent: %v", err)
+	}
+
+	// Concurrent allocations must each see the children committed before them.
+	const n = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := s.AllocatePool(ctx, parent.ID, func(_ domain.Pool, children []domain.Pool) (domain.CreatePool, error) {
+				return domain.CreatePool{Name: fmt.Sprintf("s%d", i), CIDR: fmt.Sprintf("10.0.%d.0/24", len(children))}, nil
+			})
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("allocate: %v", err)
+		}
+	}
+	children, err := s.GetPoolChildren(ctx, parent.ID)
+	if err != nil {
+		t.Fatalf("children: %v", err)
+	}
+	if len(children) != n {
+		t.Fatalf("expected %d children, got %d", n, len(children))
+	}
+
+	// A failing pick writes nothing.
+	sentinel := errors.New("no room")
+	if _, err := s.AllocatePool(ctx, parent.ID, func(domain.Pool, []domain.Pool) (domain.CreatePool, error) {
+		return domain.CreatePool{}, sentinel
+	}); !errors.Is(err, sentinel) {
+		t.Fatalf("expected sentinel error, got %v", err)
+	}
+	if children, _ := s.GetPoolChildren(ctx, parent.ID); len(children) != n {
+		t.Fatalf("expected %d children after failed pick, got %d", n, len(children))
+	}
+
+	if _, err := s.AllocatePool(ctx, 9999, func(domain.Pool, []domain.Pool) (domain.CreatePool, error) {
+		return domain.CreatePool{Name: "x", CIDR: "10.9.0.0/24"}, nil
+	}); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+//go:build sqlite
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+var _ storage.ChangeProposalStore = (*Store)(nil)
+
+const changeProposalColumns = `id, title, source, session_id, status, tree, change_set, proposed_by, proposed_by_id,
+	proposed_role, reviewed_by, reviewed_by_id, reviewed_role, review_comment, pool_map, created_at, reviewed_at`
+
+// CreateChangeProposal stores a new proposal.
+func (s *Store) CreateChangeProposal(ctx context.Context, p domain.ChangeProposal) error {
+	tree, err := json.Marshal(p.Tree)
+	if err != nil {
+		return err
+	}
+	changeSet, err := json.Marshal(p.ChangeSet)
+	if err != nil {
+		return err
+	}
+	poolMap, err := marshalPoolMap(p.PoolMap)
+	if err != nil {
+		return err
+	}
+	_, err = s.q().ExecContext(ctx,
+		`INSERT INTO change_proposals (`+changeProposalColumns+`)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		p.ID, p.Title, string(p.Source), p.SessionID, string(p.Status), string(tree), string(changeSet),
+		p.ProposedBy, p.ProposedByID, p.ProposedRole, nilIfEmpty(p.ReviewedBy), nilIfEmpty(p.ReviewedByID),
+		nilIfEmpty(p.ReviewedRole), nilIfEmpty(p.ReviewComment), poolMap, p.CreatedAt.UTC().Format(time.RFC3339),
+		formatTimePtr(p.ReviewedAt),
+	)
+	return storage.WrapIfConflict(err)
+}
+
+// GetChangeProposal returns a proposal by ID.
+func (s *Store) GetChangeProposal(ctx context.Context, id string) (*domain.ChangeProposal, error) {
+	row := s.q().QueryRowContext(ctx, `SELECT `+changeProposalColumns+` FROM change_proposals WHERE id = ?`, id)
+	p, err := scanChangeProposal(row)
+	if err == sql.ErrNoRows {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ListChangeProposals returns proposals, newest first.
+func (s *Store) ListChangeProposals(ctx context.Context, status domain.ChangeProposalStatus) ([]domain.ChangeProposal, error) {
+	query := `SELECT ` + changeProposalColumns + ` FROM change_proposals`
+	var args []any
+	if status != "" {
+		query += ` WHERE status = ?`
+		args = append(args, string(status))
+	}
+	rows, err := s.q().QueryContext(ctx, query+` ORDER BY created_at DESC, id DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []domain.ChangeProposal{}
+	for rows.Next() {
+		p, err := scanChangeProposal(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// ReviewChangeProposal records the decision on a pending proposal.
+func (s *Store) ReviewChangeProposal(ctx context.Context, p domain.ChangeProposal) error {
+	poolMap, err := marshalPoolMap(p.PoolMap)
+	if err != nil {
+		return err
+	}
+	res, err := s.q().ExecContext(ctx,
+		`UPDATE change_proposals
+		 SET status = ?, reviewed_by = ?, reviewed_by_id = ?, reviewed_role = ?, review_comment = ?, pool_map = ?,
+		     reviewed_at = ?
+		 WHERE id = ? AND status = ?`,
+		string(p.Status), nilIfEmpty(p.ReviewedBy), nilIfEmpty(p.ReviewedByID), nilIfEmpty(p.ReviewedRole),
+		nilIfEmpty(p.ReviewComment), poolMap, formatTimePtr(p.ReviewedAt), p.ID, string(domain.ChangeProposalPending),
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		return nil
+	}
+	var status string
+	err = s.q().QueryRowContext(ctx, `SELECT status FROM change_proposals WHERE id = ?`, p.ID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return storage.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("change proposal %s is %s: %w", p.ID, status, storage.ErrConflict)
+}
+
+func marshalPoolMap(m map[string]int64) (string, error) {
+	if m == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(m)
+	return string(b), err
+}
+
+func scanChangeProposal(row interface{ Scan(dest ...any) error }) (domain.ChangeProposal, error) {
+	var p domain.ChangeProposal
+	var source, status, tree, changeSet, poolMap, createdAt string
+	var reviewedBy, reviewedByID, reviewedRole, reviewComment, reviewedAt sql.NullString
+	if err := row.Scan(&p.ID, &p.Title, &source, &p.SessionID, &status, &tree, &changeSet, &p.ProposedBy,
+		&p.ProposedByID, &p.ProposedRole, &reviewedBy, &reviewedByID, &reviewedRole, &reviewComment, &poolMap,
+		&createdAt, &reviewedAt); err != nil {
+		return p, err
+	}
+	p.Source = domain.ChangeProposalSource(source)
+	p.Status = domain.ChangeProposalStatus(status)
+	if err := json.Unmarshal([]byte(tree), &p.Tree); err != nil {
+		return p, err
+	}
+	if err := json.Unmarshal([]byte(changeSet), &p.ChangeSet); err != nil {
+		return p, err
+	}
+	if err := json.Unmarshal([]byte(poolMap), &p.PoolMap); err != nil {
+		return p, err
+	}
+	if len(p.PoolMap) == 0 {
+		p.PoolMap = nil
+	}
+	p.ReviewedBy = reviewedBy.String
+	p.ReviewedByID = reviewedByID.String
+	p.ReviewedRole = reviewedRole.String
+	p.ReviewComment = reviewComment.String
+	p.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	p.ReviewedAt = parseTimePtr(reviewedAt)
+	return p, nil
+}
@@ -0,0 +1,79 @@
+//go:build sqlite
+
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+func TestChangeProposalStore(t *testing.T) {
+	s, err := New("file:" + filepath.Join(t.TempDir(), "proposals.db"))
+	if err != nil {
+		t.Fatalf("new sqlite store: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	p := domain.ChangeProposal{
+		ID: "p1", Title: "Prod VPCs", Source: domain.ChangeProposalSourceAIPlan, SessionID: "s1",
+		Status: domain.ChangeProposalPending, ProposedBy: "alice", ProposedByID: "user:u-alice", ProposedRole: "operator",
+		Tree: domain.PoolTree{
+			Pools:  []domain.PoolSpec{{Ref: "root", Name: "Root", CIDR: "10.0.0.0/16", Type: "supernet"}},
+			Status: domain.PoolStatusPlanned, Tags: map[string]string{"source": "ai_planner"},
+		},
+		ChangeSet: domain.ChangeSet{
+			Create:     []domain.PlannedPool{{Ref: "root", Name: "Root", CIDR: "10.0.0.0/16", Type: domain.PoolTypeSupernet, Depth: 1}},
+			Skip:       []domain.SkippedPool{},
+			Violations: []domain.PlannedViolation{{Ref: "root", RuleID: "TAGS-001", Severity: "error"}},
+			Warnings:   []string{},
+			Applicable: true,
+		},
+		CreatedAt: now,
+	}
+	if err := s.CreateChangeProposal(ctx, p); err != nil {
+		t.Fatalf("CreateChangeProposal: %v", err)
+	}
+	if err := s.CreateChangeProposal(ctx, p); !errors.Is(err, storage.ErrConflict) {
+		t.Fatalf("duplicate CreateChangeProposal: expected ErrConflict, got %v", err)
+	}
+	got, err := s.GetChangeProposal(ctx, "p1")
+	if err != nil {
+		t.Fatalf("GetChangeProposal: %v", err)
+	}
+	if got.SessionID != "s1" || got.ProposedByID != "user:u-alice" || got.Tree.Tags["source"] != "ai_planner" || len(got.ChangeSet.Violations) != 1 ||
+		got.ChangeSet.Create[0].Depth != 1 || got.ReviewedAt != nil || !got.CreatedAt.Equal(now) {
+		t.Fatalf("GetChangeProposal = %+v", got)
+	}
+
+	reviewed := now.Add(time.Hour)
+	got.Status = domain.ChangeProposalApplied
+	got.ReviewedBy, got.ReviewedRole, got.ReviewComment, got.ReviewedAt = "bob", "admin", "lgtm", &reviewed
+	got.ReviewedByID = "user:u-bob"
+	got.PoolMap = map[string]int64{"root": 7}
+	if err := s.ReviewChangeProposal(ctx, *got); err != nil {
+		t.Fatalf("ReviewChangeProposal: %v", err)
+	}
+	if err := s.ReviewChangeProposal(ctx, *got); !errors.Is(err, storage.ErrConflict) {
+		t.Fatalf("second ReviewChangeProposal: expected ErrConflict, got %v", err)
+	}
+	got.ID = "missing"
+	if err := s.ReviewChangeProposal(ctx, *got); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("ReviewChangeProposal missing: expected ErrNotFound, got %v", err)
+	}
+
+	list, err := s.ListChangeProposals(ctx, domain.ChangeProposalApplied)
+	if err != nil || len(list) != 1 || list[0].PoolMap["root"] != 7 || list[0].ReviewComment != "lgtm" || list[0].ReviewedByID != "user:u-bob" || !list[0].ReviewedAt.Equal(reviewed) {
+		t.Fatalf("ListChangeProposals(applied) = %+v, %v", list, err)
+	}
+	if pending, err := s.ListChangeProposals(ctx, domain.ChangeProposalPending); err != nil || len(pending) != 0 {
+		t.Fatalf("ListChangeProposals(pending) = %+v, %v", pending, err)
+	}
+}
@@ -0,0 +1,183 @@
+//go:build sqlite
+
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+	"sync"
+
+	"cloudpam/internal/cidr"
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+var _ storage.CIDROperations = (*Store)(nil)
+
+// cidrIndexCache holds an interval tree over every pool row together with
+// the fingerprint it was built from. Pool ids come from AUTOINCREMENT and
+// rows are only ever soft-deleted, so the row count and highest id change
+// whenever a pool is added; soft-deleted pools are filtered when results are
+// loaded. Every row update bumps its version, so the version sum catches a
+// pool moved to a new CIDR.
+type cidrIndexCache struct {
+	mu       sync.Mutex
+	count    int64
+	maxID    int64
+	versions int64
+	idx      *cidr.Index
+}
+
+// poolIndex returns an interval tree over the pools visible to s. Stores
+// scoped to a transaction build a private tree so uncommitted rows never
+// reach the shared cache.
+func (s *Store) poolIndex(ctx context.Context) (*cidr.Index, error) {
+	var count, maxID, versions int64
+	if err := s.q().QueryRowContext(ctx, `SELECT COUNT(1), COALESCE(MAX(id), 0), COALESCE(SUM(version), 0) FROM pools`).Scan(&count, &maxID, &versions); err != nil {
+		return nil, err
+	}
+	c := s.cidrIdx
+	if s.tx == nil && c != nil {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.idx != nil && c.count == count && c.maxID == maxID && c.versions == versions {
+			return c.idx, nil
+		}
+	}
+	rows, err := s.q().QueryContext(ctx, `SELECT id, cidr FROM pools`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	entries := make([]cidr.IndexEntry, 0, count)
+	for rows.Next() {
+		var id int64
+		var cidrStr string
+		if err := rows.Scan(&id, &cidrStr); err != nil {
+			return nil, err
+		}
+		if p, err := netip.ParsePrefix(cidrStr); err == nil {
+			entries = append(entries, cidr.IndexEntry{Prefix: p, ID: id})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	idx := cidr.NewIndex(entries)
+	if s.tx == nil && c != nil {
+		c.idx, c.count, c.maxID, c.versions = idx, count, maxID, versions
+	}
+	return idx, nil
+}
+
+// loadPoolsBatch bounds the ids bound into one IN list, well under SQLite's
+// host parameter limit.
+const loadPoolsBatch = 500
+
+// loadPools fetches the live pools behind index hits, keeping hit order.
+// where, if set, further filters the rows.
+func (s *Store) loadPools(ctx context.Context, hits []cidr.IndexEntry, where string, args ...any) ([]*domain.Pool, error) {
+	byID := make(map[int64]domain.Pool, len(hits))
+	for start := 0; start < len(hits); start += loadPoolsBatch {
+		batch := hits[start:min(start+loadPoolsBatch, len(hits))]
+		q := `SELECT ` + poolSelectColumns + ` FROM pools WHERE deleted_at IS NULL AND id IN (?` + strings.Repeat(`,?`, len(batch)-1) + `)`
+		if where != "" {
+			q += ` AND ` + where
+		}
+		qargs := make([]any, 0, len(batch)+len(args))
+		for _, h := range batch {
+			qargs = append(qargs, h.ID)
+		}
+		if err := s.scanPoolsInto(ctx, byID, q, append(qargs, args...)...); err != nil {
+			return nil, err
+		}
+	}
+	out := []*domain.Pool{}
+	for _, h := range hits {
+		if p, ok := byID[h.ID]; ok {
+			out = append(out, &p)
+		}
+	}
+	return out, nil
+}
+
+func (s *Store) scanPoolsInto(ctx context.Context, dst map[int64]domain.Pool, q string, args ...any) error {
+	rows, err := s.q().QueryContext(ctx, q, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		p, err := scanPool(rows)
+		if err != nil {
+			return err
+		}
+		dst[p.ID] = p
+	}
+	return rows.Err()
+}
+
+// FindOverlapping returns live pools overlapping cidr, optionally limited to
+// one level of the hierarchy.
+func (s *Store) FindOverlapping(ctx context.Context, cidr string, parentID *int64) ([]*domain.Pool, error) {
+	q, err := storage.ParseCIDRArg(cidr)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := s.poolIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case parentID == nil:
+		return s.loadPools(ctx, idx.Overlapping(q), "")
+	case *parentID == 0:
+		return s.loadPools(ctx, idx.Overlapping(q), "parent_id IS NULL")
+	default:
+		return s.loadPools(ctx, idx.Overlapping(q), "parent_id = ?", *parentID)
+	}
+}
+
+// FindContaining returns live pools whose CIDR contains cidr.
+func (s *Store) FindContaining(ctx context.Context, cidr string) ([]*domain.Pool, error) {
+	q, err := storage.ParseCIDRArg(cidr)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := s.poolIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.loadPools(ctx, idx.Containing(q), "")
+}
+
+// FindContainedBy returns live pools inside cidr.
+func (s *Store) FindContainedBy(ctx context.Context, cidr string) ([]*domain.Pool, error) {
+	q, err := storage.ParseCIDRArg(cidr)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := s.poolIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.loadPools(ctx, idx.ContainedBy(q), "")
+}
+
+// FindGaps returns the free blocks inside a pool.
+func (s *Store) FindGaps(ctx context.Context, parentID int64, prefixLen int) ([]string, error) {
+	parent, ok, err := s.GetPool(ctx, parentID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("parent pool not found: %w", storage.ErrNotFound)
+	}
+	children, err := s.GetPoolChildren(ctx, parentID)
+	if err != nil {
+		return nil, err
+	}
+	return storage.FreeBlocks(parent, children, prefixLen)
+}
@@ -0,0 +1,98 @@
+//go:build sqlite
+
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+func TestCIDROperations(t *testing.T) {
+	s, err := New("file:" + filepath.Join(t.TempDir(), "cidr.db"))
+	if err != nil {
+		t.Fatalf("new sqlite store: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	ctx := context.Background()
+
+	root, _ := s.CreatePool(ctx, domain.CreatePool{Name: "root", CIDR: "10.0.0.0/16"})
+	a, _ := s.CreatePool(ctx, domain.CreatePool{Name: "a", CIDR: "10.0.0.0/24", ParentID: &root.ID})
+	_, _ = s.CreatePool(ctx, domain.CreatePool{Name: "b", CIDR: "10.0.2.0/23", ParentID: &root.ID})
+	_, _ = s.CreatePool(ctx, domain.CreatePool{Name: "v6", CIDR: "2001:db8::/32"})
+
+	names := func(ps []*domain.Pool) string {
+		var out []string
+		for _, p := range ps {
+			out = append(out, p.Name)
+		}
+		return fmt.Sprint(out)
+	}
+
+	overlap, err := s.FindOverlapping(ctx, "10.0.0.0/22", nil)
+	if err != nil {
+		t.Fatalf("FindOverlapping: %v", err)
+	}
+	if got := names(overlap); got != "[root a b]" {
+		t.Errorf("FindOverlapping = %s", got)
+	}
+	top := int64(0)
+	if overlap, _ = s.FindOverlapping(ctx, "10.0.0.0/22", &top); names(overlap) != "[root]" {
+		t.Errorf("FindOverlapping(top-level) = %s", names(overlap))
+	}
+	if overlap, _ = s.FindOverlapping(ctx, "10.0.0.128/25", &root.ID); names(overlap) != "[a]" {
+		t.Errorf("FindOverlapping(children) = %s", names(overlap))
+	}
+	if containing, _ := s.FindContaining(ctx, "10.0.0.5"); names(containing) != "[root a]" {
+		t.Errorf("FindContaining = %s", names(containing))
+	}
+	if within, _ := s.FindContainedBy(ctx, "2001:db8::/16"); names(within) != "[v6]" {
+		t.Errorf("FindContainedBy = %s", names(within))
+	}
+	if _, err := s.FindContaining(ctx, "not-a-cidr"); !errors.Is(err, storage.ErrValidation) {
+		t.Errorf("expected ErrValidation, got %v", err)
+	}
+
+	gaps, err := s.FindGaps(ctx, root.ID, 23)
+	if err != nil {
+		t.Fatalf("FindGaps: %v", err)
+	}
+	if fmt.Sprint(gaps) != "[10.0.4.0/22 10.0.8.0/21 10.0.16.0/20 10.0.32.0/19 10.0.64.0/18 10.0.128.0/17]" {
+		t.Errorf("FindGaps = %v", gaps)
+	}
+	if _, err := s.FindGaps(ctx, a.ID+1000, 0); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	// The cached index picks up new pools and drops deleted ones.
+	c, _ := s.CreatePool(ctx, domain.CreatePool{Name: "c", CIDR: "10.0.1.0/24", ParentID: &root.ID})
+	if within, _ := s.FindContainedBy(ctx, "10.0.0.0/22"); names(within) != "[a c b]" {
+		t.Errorf("FindContainedBy after insert = %s", names(within))
+	}
+	if _, err := s.DeletePool(ctx, c.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if within, _ := s.FindContainedBy(ctx, "10.0.0.0/22"); names(within) != "[a b]" {
+		t.Errorf("FindContainedBy after delete = %s", names(within))
+	}
+
+	// A transaction sees its own pools without leaking them into the cache.
+	_ = s.WithTx(ctx, func(tx storage.Transaction) error {
+		if _, err := tx.CreatePool(ctx, domain.CreatePool{Name: "tx", CIDR: "10.0.4.0/24", ParentID: &root.ID}); err != nil {
+			t.Fatalf("create in tx: %v", err)
+		}
+		got, _ := tx.(storage.CIDROperations).FindContaining(ctx, "10.0.4.1")
+		if names(got) != "[root tx]" {
+			t.Errorf("FindContaining in tx = %s", names(got))
+		}
+		return errors.New("rollback")
+	})
+	if got, _ := s.FindContaining(ctx, "10.0.4.1"); names(got) != "[root]" {
+		t.Errorf("FindContaining after rollback = %s", names(got))
+	}
+}
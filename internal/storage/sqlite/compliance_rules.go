@@ -0,0 +1,134 @@
+//go:build sqlite
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+var _ storage.ComplianceRuleStore = (*Store)(nil)
+
+const complianceRuleColumns = `id, name, description, enabled, severity, enforce, match_spec, check_spec,
+	message, remediation, created_at, updated_at`
+
+// CreateComplianceRule stores a new rule.
+func (s *Store) CreateComplianceRule(ctx context.Context, rule domain.ComplianceRule) error {
+	match, check, err := marshalComplianceSpecs(rule)
+	if err != nil {
+		return err
+	}
+	_, err = s.q().ExecContext(ctx,
+		`INSERT INTO compliance_rules (`+complianceRuleColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		rule.ID, rule.Name, rule.Description, boolToInt(rule.Enabled), rule.Severity, boolToInt(rule.Enforce),
+		match, check, rule.Message, rule.Remediation,
+		rule.CreatedAt.UTC().Format(time.RFC3339), rule.UpdatedAt.UTC().Format(time.RFC3339),
+	)
+	return storage.WrapIfConflict(err)
+}
+
+// GetComplianceRule returns a rule by ID.
+func (s *Store) GetComplianceRule(ctx context.Context, id string) (*domain.ComplianceRule, error) {
+	row := s.q().QueryRowContext(ctx, `SELECT `+complianceRuleColumns+` FROM compliance_rules WHERE id = ?`, id)
+	rule, err := scanComplianceRule(row)
+	if err == sql.ErrNoRows {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// ListComplianceRules returns all rules ordered by ID.
+func (s *Store) ListComplianceRules(ctx context.Context) ([]domain.ComplianceRule, error) {
+	rows, err := s.q().QueryContext(ctx, `SELECT `+complianceRuleColumns+` FROM compliance_rules ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []domain.ComplianceRule{}
+	for rows.Next() {
+		rule, err := scanComplianceRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, rule)
+	}
+	return out, rows.Err()
+}
+
+// UpdateComplianceRule replaces a rule. CreatedAt is kept.
+func (s *Store) UpdateComplianceRule(ctx context.Context, rule domain.ComplianceRule) error {
+	match, check, err := marshalComplianceSpecs(rule)
+	if err != nil {
+		return err
+	}
+	res, err := s.q().ExecContext(ctx,
+		`UPDATE compliance_rules
+		 SET name = ?, description = ?, enabled = ?, severity = ?, enforce = ?, match_spec = ?, check_spec = ?,
+		     message = ?, remediation = ?, updated_at = ?
+		 WHERE id = ?`,
+		rule.Name, rule.Description, boolToInt(rule.Enabled), rule.Severity, boolToInt(rule.Enforce), match, check,
+		rule.Message, rule.Remediation, rule.UpdatedAt.UTC().Format(time.RFC3339), rule.ID,
+	)
+	if err != nil {
+		return err
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteComplianceRule removes a rule.
+func (s *Store) DeleteComplianceRule(ctx context.Context, id string) error {
+	res, err := s.q().ExecContext(ctx, `DELETE FROM compliance_rules WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+func marshalComplianceSpecs(rule domain.ComplianceRule) (string, string, error) {
+	match, err := json.Marshal(rule.Match)
+	if err != nil {
+		return "", "", err
+	}
+	check, err := json.Marshal(rule.Check)
+	if err != nil {
+		return "", "", err
+	}
+	return string(match), string(check), nil
+}
+
+func scanComplianceRule(row interface{ Scan(dest ...any) error }) (domain.ComplianceRule, error) {
+	var rule domain.ComplianceRule
+	var enabled, enforce int
+	var match, check, createdAt, updatedAt string
+	if err := row.Scan(&rule.ID, &rule.Name, &rule.Description, &enabled, &rule.Severity, &enforce, &match, &check,
+		&rule.Message, &rule.Remediation, &createdAt, &updatedAt); err != nil {
+		return rule, err
+	}
+	rule.Enabled = enabled == 1
+	rule.Enforce = enforce == 1
+	if err := json.Unmarshal([]byte(match), &rule.Match); err != nil {
+		return rule, err
+	}
+	if err := json.Unmarshal([]byte(check), &rule.Check); err != nil {
+		return rule, err
+	}
+	rule.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	rule.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+	return rule, nil
+}
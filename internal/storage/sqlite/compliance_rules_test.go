@@ -0,0 +1,67 @@
+//go:build sqlite
+
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+func TestComplianceRuleStore(t *testing.T) {
+	s, err := New("file:" + filepath.Join(t.TempDir(), "compliance.db"))
+	if err != nil {
+		t.Fatalf("new sqlite store: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	rule := domain.ComplianceRule{
+		ID: "DOCKER-001", Name: "No Docker bridge ranges", Enabled: true, Severity: "error", Enforce: true,
+		Match:       domain.ComplianceMatch{PoolTypes: []domain.PoolType{domain.PoolTypeSubnet}, IPVersion: 4},
+		Check:       domain.ComplianceCheck{Type: domain.ComplianceCheckForbiddenRanges, Ranges: []string{"172.17.0.0/16"}},
+		Remediation: "Pick a range outside 172.17.0.0/16",
+		CreatedAt:   now, UpdatedAt: now,
+	}
+	if err := s.CreateComplianceRule(ctx, rule); err != nil {
+		t.Fatalf("CreateComplianceRule: %v", err)
+	}
+	if err := s.CreateComplianceRule(ctx, rule); !errors.Is(err, storage.ErrConflict) {
+		t.Fatalf("duplicate CreateComplianceRule: expected ErrConflict, got %v", err)
+	}
+	got, err := s.GetComplianceRule(ctx, "DOCKER-001")
+	if err != nil {
+		t.Fatalf("GetComplianceRule: %v", err)
+	}
+	if !got.Enabled || !got.Enforce || got.Check.Ranges[0] != "172.17.0.0/16" || got.Match.IPVersion != 4 ||
+		got.Match.PoolTypes[0] != domain.PoolTypeSubnet || !got.CreatedAt.Equal(now) {
+		t.Fatalf("GetComplianceRule = %+v", got)
+	}
+
+	rule.Enforce = false
+	rule.Check = domain.ComplianceCheck{Type: domain.ComplianceCheckMaxDepth, MaxDepth: 4}
+	rule.UpdatedAt = now.Add(time.Hour)
+	if err := s.UpdateComplianceRule(ctx, rule); err != nil {
+		t.Fatalf("UpdateComplianceRule: %v", err)
+	}
+	list, err := s.ListComplianceRules(ctx)
+	if err != nil || len(list) != 1 || list[0].Enforce || list[0].Check.MaxDepth != 4 || !list[0].UpdatedAt.Equal(rule.UpdatedAt) {
+		t.Fatalf("ListComplianceRules = %+v, %v", list, err)
+	}
+	if err := s.UpdateComplianceRule(ctx, domain.ComplianceRule{ID: "missing"}); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("UpdateComplianceRule missing: expected ErrNotFound, got %v", err)
+	}
+
+	if err := s.DeleteComplianceRule(ctx, "DOCKER-001"); err != nil {
+		t.Fatalf("DeleteComplianceRule: %v", err)
+	}
+	if _, err := s.GetComplianceRule(ctx, "DOCKER-001"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("GetComplianceRule after delete: expected ErrNotFound, got %v", err)
+	}
+}
@@ -13,7 +13,7 @@ import (
 
 // CreateConversation persists a new conversation.
 func (s *Store) CreateConversation(ctx context.Context, conv domain.Conversation) error {
-	_, err := s.db.ExecContext(ctx,
+	_, err := s.q().ExecContext(ctx,
 		`INSERT INTO conversations (id, title, created_at, updated_at) VALUES (?, ?, ?, ?)`,
 		conv.ID, conv.Title,
 		conv.CreatedAt.Format(time.RFC3339), conv.UpdatedAt.Format(time.RFC3339),
@@ -25,7 +25,7 @@ func (s *Store) CreateConversation(ctx context.Context, conv domain.Conversation
 func (s *Store) GetConversation(ctx context.Context, id string) (*domain.ConversationWithMessages, error) {
 	var conv domain.Conversation
 	var createdAt, updatedAt string
-	err := s.db.QueryRowContext(ctx,
+	err := s.q().QueryRowContext(ctx,
 		`SELECT id, title, created_at, updated_at FROM conversations WHERE id = ?`, id,
 	).Scan(&conv.ID, &conv.Title, &createdAt, &updatedAt)
 	if err != nil {
@@ -37,7 +37,7 @@ func (s *Store) GetConversation(ctx context.Context, id string) (*domain.Convers
 	conv.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
 	conv.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
 
-	rows, err := s.db.QueryContext(ctx,
+	rows, err := s.q().QueryContext(ctx,
 		`SELECT id, conversation_id, role, content, created_at FROM conversation_messages WHERE conversation_id = ? ORDER BY created_at ASC`, id,
 	)
 	if err != nil {
@@ -67,7 +67,7 @@ func (s *Store) GetConversation(ctx context.Context, id string) (*domain.Convers
 
 // ListConversations returns all conversations ordered by updated_at desc.
 func (s *Store) ListConversations(ctx context.Context) ([]domain.Conversation, error) {
-	rows, err := s.db.QueryContext(ctx,
+	rows, err := s.q().QueryContext(ctx,
 		`SELECT id, title, created_at, updated_at FROM conversations ORDER BY updated_at DESC`,
 	)
 	if err != nil {
@@ -94,7 +94,7 @@ func (s *Store) ListConversations(ctx context.Context) ([]domain.Conversation, e
 
 // DeleteConversation removes a conversation and its messages (via CASCADE).
 func (s *Store) DeleteConversation(ctx context.Context, id string) error {
-	res, err := s.db.ExecContext(ctx, `DELETE FROM conversations WHERE id = ?`, id)
+	res, err := s.q().ExecContext(ctx, `DELETE FROM conversations WHERE id = ?`, id)
 	if err != nil {
 		return err
 	}
@@ -107,7 +107,7 @@ func (s *Store) DeleteConversation(ctx context.Context, id string) error {
 
 // AddMessage appends a message to a conversation and updates its updated_at.
 func (s *Store) AddMessage(ctx context.Context, msg domain.ConversationMessage) error {
-	tx, err := s.db.BeginTx(ctx, nil)
+	tx, err := s.begin(ctx)
 	if err != nil {
 		return err
 	}
@@ -55,7 +55,7 @@ func (s *Store) ListDiscoveredResources(ctx context.Context, accountID int64, fi
 	// Count total
 	var total int
 	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM discovered_resources WHERE %s", whereClause)
-	if err := s.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+	if err := s.q().QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
 
@@ -76,7 +76,7 @@ func (s *Store) ListDiscoveredResources(ctx context.Context, accountID int64, fi
 	)
 	args = append(args, pageSize, offset)
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.q().QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -98,7 +98,7 @@ func (s *Store) ListDiscoveredResources(ctx context.Context, accountID int64, fi
 
 // GetDiscoveredResource returns a single discovered resource by UUID.
 func (s *Store) GetDiscoveredResource(ctx context.Context, id uuid.UUID) (*domain.DiscoveredResource, error) {
-	row := s.db.QueryRowContext(ctx,
+	row := s.q().QueryRowContext(ctx,
 		"SELECT id, account_id, provider, region, resource_type, resource_id, name, cidr, parent_resource_id, pool_id, status, metadata, discovered_at, last_seen_at FROM discovered_resources WHERE id = ?",
 		id.String(),
 	)
@@ -149,7 +149,7 @@ func (s *Store) UpsertDiscoveredResource(ctx context.Context, res domain.Discove
 		parentResID = res.ParentResourceID
 	}
 
-	_, err := s.db.ExecContext(ctx,
+	_, err := s.q().ExecContext(ctx,
 		`INSERT INTO discovered_resources (id, account_id, provider, region, resource_type, resource_id, name, cidr, parent_resource_id, pool_id, status, metadata, discovered_at, last_seen_at)
 		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		 ON CONFLICT(account_id, resource_id) DO UPDATE SET
@@ -169,7 +169,7 @@ func (s *Store) UpsertDiscoveredResource(ctx context.Context, res domain.Discove
 
 // MarkStaleResources marks active resources not seen since the given time as stale.
 func (s *Store) MarkStaleResources(ctx context.Context, accountID int64, before time.Time) (int, error) {
-	res, err := s.db.ExecContext(ctx,
+	res, err := s.q().ExecContext(ctx,
 		"UPDATE discovered_resources SET status = ? WHERE account_id = ? AND status = ? AND last_seen_at < ?",
 		string(domain.DiscoveryStatusStale), accountID, string(domain.DiscoveryStatusActive), before.Format(time.RFC3339),
 	)
@@ -182,7 +182,7 @@ func (s *Store) MarkStaleResources(ctx context.Context, accountID int64, before
 
 // LinkResourceToPool links a discovered resource to a managed pool.
 func (s *Store) LinkResourceToPool(ctx context.Context, resourceID uuid.UUID, poolID int64) error {
-	res, err := s.db.ExecContext(ctx,
+	res, err := s.q().ExecContext(ctx,
 		"UPDATE discovered_resources SET pool_id = ? WHERE id = ?",
 		poolID, resourceID.String(),
 	)
@@ -198,7 +198,7 @@ func (s *Store) LinkResourceToPool(ctx context.Context, resourceID uuid.UUID, po
 
 // UnlinkResource removes the pool link from a discovered resource.
 func (s *Store) UnlinkResource(ctx context.Context, resourceID uuid.UUID) error {
-	res, err := s.db.ExecContext(ctx,
+	res, err := s.q().ExecContext(ctx,
 		"UPDATE discovered_resources SET pool_id = NULL WHERE id = ?",
 		resourceID.String(),
 	)
@@ -214,7 +214,7 @@ func (s *Store) UnlinkResource(ctx context.Context, resourceID uuid.UUID) error
 
 // DeleteDiscoveredResource deletes a discovered resource by ID.
 func (s *Store) DeleteDiscoveredResource(ctx context.Context, id uuid.UUID) error {
-	res, err := s.db.ExecContext(ctx,
+	res, err := s.q().ExecContext(ctx,
 		"DELETE FROM discovered_resources WHERE id = ?",
 		id.String(),
 	)
@@ -253,7 +253,7 @@ func (s *Store) CreateSyncJob(ctx context.Context, job domain.SyncJob) (domain.S
 		agentID = &s
 	}
 
-	_, err := s.db.ExecContext(ctx,
+	_, err := s.q().ExecContext(ctx,
 		`INSERT INTO sync_jobs (id, account_id, status, source, agent_id, started_at, completed_at, resources_found, resources_created, resources_updated, resources_deleted, error_message, created_at)
 		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		job.ID.String(), job.AccountID, string(job.Status), job.Source, agentID, startedAt, completedAt,
@@ -284,7 +284,7 @@ func (s *Store) UpdateSyncJob(ctx context.Context, job domain.SyncJob) error {
 		agentID = &s
 	}
 
-	res, err := s.db.ExecContext(ctx,
+	res, err := s.q().ExecContext(ctx,
 		`UPDATE sync_jobs SET status = ?, source = ?, agent_id = ?, started_at = ?, completed_at = ?, resources_found = ?, resources_created = ?, resources_updated = ?, resources_deleted = ?, error_message = ? WHERE id = ?`,
 		string(job.Status), job.Source, agentID, startedAt, completedAt,
 		job.ResourcesFound, job.ResourcesCreated, job.ResourcesUpdated, job.ResourcesDeleted,
@@ -302,7 +302,7 @@ func (s *Store) UpdateSyncJob(ctx context.Context, job domain.SyncJob) error {
 
 // GetSyncJob returns a sync job by UUID.
 func (s *Store) GetSyncJob(ctx context.Context, id uuid.UUID) (*domain.SyncJob, error) {
-	row := s.db.QueryRowContext(ctx,
+	row := s.q().QueryRowContext(ctx,
 		"SELECT id, account_id, status, source, agent_id, started_at, completed_at, resources_found, resources_created, resources_updated, resources_deleted, error_message, created_at FROM sync_jobs WHERE id = ?",
 		id.String(),
 	)
@@ -314,7 +314,7 @@ func (s *Store) ListSyncJobs(ctx context.Context, accountID int64, limit int) ([
 	if limit < 1 {
 		limit = 20
 	}
-	rows, err := s.db.QueryContext(ctx,
+	rows, err := s.q().QueryContext(ctx,
 		"SELECT id, account_id, status, source, agent_id, started_at, completed_at, resources_found, resources_created, resources_updated, resources_deleted, error_message, created_at FROM sync_jobs WHERE account_id = ? ORDER BY created_at DESC LIMIT ?",
 		accountID, limit,
 	)
@@ -355,7 +355,7 @@ func (s *Store) ListSyncJobs(ctx context.Context, accountID int64, limit int) ([
 
 // ClaimPendingAgentSync atomically claims the oldest pending sync job assigned to an agent.
 func (s *Store) ClaimPendingAgentSync(ctx context.Context, agentID uuid.UUID) (*domain.SyncJob, error) {
-	tx, err := s.db.BeginTx(ctx, nil)
+	tx, err := s.begin(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -453,7 +453,7 @@ func (s *Store) UpsertAgent(ctx context.Context, agent domain.DiscoveryAgent) er
 		agent.CreatedAt = time.Now().UTC()
 	}
 
-	_, err := s.db.ExecContext(ctx,
+	_, err := s.q().ExecContext(ctx,
 		`INSERT INTO discovery_agents (id, name, account_id, api_key_id, version, hostname, last_seen_at, created_at)
 		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 		 ON CONFLICT(id) DO UPDATE SET
@@ -472,7 +472,7 @@ func (s *Store) UpsertAgent(ctx context.Context, agent domain.DiscoveryAgent) er
 
 // GetAgent returns a discovery agent by ID.
 func (s *Store) GetAgent(ctx context.Context, id uuid.UUID) (*domain.DiscoveryAgent, error) {
-	row := s.db.QueryRowContext(ctx,
+	row := s.q().QueryRowContext(ctx,
 		"SELECT id, name, account_id, api_key_id, version, hostname, last_seen_at, created_at FROM discovery_agents WHERE id = ?",
 		id.String(),
 	)
@@ -493,7 +493,7 @@ func (s *Store) GetAgent(ctx context.Context, id uuid.UUID) (*domain.DiscoveryAg
 }
 
 func (s *Store) DeleteAgent(ctx context.Context, id uuid.UUID) error {
-	res, err := s.db.ExecContext(ctx, "DELETE FROM discovery_agents WHERE id = ?", id.String())
+	res, err := s.q().ExecContext(ctx, "DELETE FROM discovery_agents WHERE id = ?", id.String())
 	if err != nil {
 		return err
 	}
@@ -516,7 +516,7 @@ func (s *Store) ListAgents(ctx context.Context, accountID int64) ([]domain.Disco
 
 	query += " ORDER BY created_at DESC"
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.q().QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
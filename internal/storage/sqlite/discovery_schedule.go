@@ -0,0 +1,166 @@
+//go:build sqlite
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+var _ storage.DiscoveryScheduleStore = (*Store)(nil)
+
+const discoveryScheduleColumns = `account_id, enabled, sync_interval, cron_expr, detect_drift, next_run_at,
+	last_run_at, last_status, last_error, last_sync_job_id, created_at, updated_at`
+
+// GetDiscoverySchedule returns the schedule for an account.
+func (s *Store) GetDiscoverySchedule(ctx context.Context, accountID int64) (*domain.DiscoverySchedule, error) {
+	row := s.q().QueryRowContext(ctx,
+		`SELECT `+discoveryScheduleColumns+` FROM discovery_schedules WHERE account_id = ?`, accountID)
+	sc, err := scanDiscoverySchedule(row)
+	if err == sql.ErrNoRows {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sc, nil
+}
+
+// ListDiscoverySchedules returns all schedules ordered by account ID.
+func (s *Store) ListDiscoverySchedules(ctx context.Context) ([]domain.DiscoverySchedule, error) {
+	return s.queryDiscoverySchedules(ctx,
+		`SELECT `+discoveryScheduleColumns+` FROM discovery_schedules ORDER BY account_id`)
+}
+
+// UpsertDiscoverySchedule creates or replaces a schedule's configuration.
+func (s *Store) UpsertDiscoverySchedule(ctx context.Context, sc domain.DiscoverySchedule) error {
+	res, err := s.q().ExecContext(ctx,
+		`INSERT INTO discovery_schedules (account_id, enabled, sync_interval, cron_expr, detect_drift, next_run_at, created_at, updated_at)
+		 SELECT ?, ?, ?, ?, ?, ?, ?, ?
+		 WHERE EXISTS (SELECT 1 FROM accounts WHERE id = ? AND deleted_at IS NULL)
+		 ON CONFLICT(account_id) DO UPDATE SET
+			enabled = excluded.enabled,
+			sync_interval = excluded.sync_interval,
+			cron_expr = excluded.cron_expr,
+			detect_drift = excluded.detect_drift,
+			next_run_at = excluded.next_run_at,
+			updated_at = excluded.updated_at`,
+		sc.AccountID, boolToInt(sc.Enabled), nilIfEmpty(sc.Interval), nilIfEmpty(sc.Cron), boolToInt(sc.DetectDrift),
+		formatTimePtr(sc.NextRunAt), sc.CreatedAt.UTC().Format(time.RFC3339), sc.UpdatedAt.UTC().Format(time.RFC3339),
+		sc.AccountID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("account %d: %w", sc.AccountID, storage.ErrNotFound)
+	}
+	return nil
+}
+
+// DeleteDiscoverySchedule removes an account's schedule.
+func (s *Store) DeleteDiscoverySchedule(ctx context.Context, accountID int64) error {
+	res, err := s.q().ExecContext(ctx, `DELETE FROM discovery_schedules WHERE account_id = ?`, accountID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// ListDueDiscoverySchedules returns enabled schedules due at or before now.
+func (s *Store) ListDueDiscoverySchedules(ctx context.Context, now time.Time) ([]domain.DiscoverySchedule, error) {
+	return s.queryDiscoverySchedules(ctx,
+		`SELECT `+discoveryScheduleColumns+` FROM discovery_schedules
+		 WHERE enabled = 1 AND next_run_at IS NOT NULL AND next_run_at <= ?
+		 ORDER BY next_run_at, account_id`,
+		now.UTC().Format(time.RFC3339),
+	)
+}
+
+// ClaimDiscoverySchedule advances next_run_at from due to next if it still equals due.
+func (s *Store) ClaimDiscoverySchedule(ctx context.Context, accountID int64, due, next time.Time) (bool, error) {
+	res, err := s.q().ExecContext(ctx,
+		`UPDATE discovery_schedules SET next_run_at = ?
+		 WHERE account_id = ? AND enabled = 1 AND next_run_at = ?`,
+		next.UTC().Format(time.RFC3339), accountID, due.UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n == 1, nil
+}
+
+// RecordDiscoveryScheduleRun stores the outcome of a run.
+func (s *Store) RecordDiscoveryScheduleRun(ctx context.Context, accountID int64, run domain.DiscoveryScheduleRun) error {
+	var jobID any
+	if run.SyncJobID != nil {
+		jobID = run.SyncJobID.String()
+	}
+	res, err := s.q().ExecContext(ctx,
+		`UPDATE discovery_schedules SET last_run_at = ?, last_status = ?, last_error = ?, last_sync_job_id = ?
+		 WHERE account_id = ?`,
+		run.At.UTC().Format(time.RFC3339), string(run.Status), nilIfEmpty(run.Error), jobID, accountID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+func (s *Store) queryDiscoverySchedules(ctx context.Context, query string, args ...any) ([]domain.DiscoverySchedule, error) {
+	rows, err := s.q().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []domain.DiscoverySchedule{}
+	for rows.Next() {
+		sc, err := scanDiscoverySchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, sc)
+	}
+	return out, rows.Err()
+}
+
+func scanDiscoverySchedule(row interface{ Scan(dest ...any) error }) (domain.DiscoverySchedule, error) {
+	var sc domain.DiscoverySchedule
+	var enabled, detectDrift int
+	var interval, cron, nextRunAt, lastRunAt, lastStatus, lastError, lastJobID sql.NullString
+	var createdAt, updatedAt string
+	if err := row.Scan(&sc.AccountID, &enabled, &interval, &cron, &detectDrift, &nextRunAt,
+		&lastRunAt, &lastStatus, &lastError, &lastJobID, &createdAt, &updatedAt); err != nil {
+		return sc, err
+	}
+	sc.Enabled = enabled == 1
+	sc.DetectDrift = detectDrift == 1
+	sc.Interval = interval.String
+	sc.Cron = cron.String
+	sc.NextRunAt = parseTimePtr(nextRunAt)
+	sc.LastRunAt = parseTimePtr(lastRunAt)
+	sc.LastStatus = domain.ScheduleRunStatus(lastStatus.String)
+	sc.LastError = lastError.String
+	if lastJobID.Valid {
+		if id, err := uuid.Parse(lastJobID.String); err == nil {
+			sc.LastSyncJobID = &id
+		}
+	}
+	sc.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	sc.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+	return sc, nil
+}
@@ -0,0 +1,89 @@
+//go:build sqlite
+
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+func TestDiscoveryScheduleStore(t *testing.T) {
+	s, err := New("file:" + filepath.Join(t.TempDir(), "schedules.db"))
+	if err != nil {
+		t.Fatalf("new sqlite store: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	acct, err := s.CreateAccount(ctx, domain.CreateAccount{Key: "aws:111", Name: "Prod", Provider: "aws"})
+	if err != nil {
+		t.Fatalf("CreateAccount: %v", err)
+	}
+
+	next := now.Add(-time.Minute)
+	sc := domain.DiscoverySchedule{
+		AccountID: acct.ID, Enabled: true, Cron: "0 * * * *", DetectDrift: true,
+		NextRunAt: &next, CreatedAt: now, UpdatedAt: now,
+	}
+	if err := s.UpsertDiscoverySchedule(ctx, sc); err != nil {
+		t.Fatalf("UpsertDiscoverySchedule: %v", err)
+	}
+	orphan := sc
+	orphan.AccountID = 999
+	if err := s.UpsertDiscoverySchedule(ctx, orphan); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("schedule for missing account: expected ErrNotFound, got %v", err)
+	}
+
+	due, err := s.ListDueDiscoverySchedules(ctx, now)
+	if err != nil || len(due) != 1 || due[0].Cron != "0 * * * *" || !due[0].DetectDrift {
+		t.Fatalf("ListDueDiscoverySchedules = %+v, %v", due, err)
+	}
+	if ok, err := s.ClaimDiscoverySchedule(ctx, acct.ID, *due[0].NextRunAt, now.Add(time.Hour)); err != nil || !ok {
+		t.Fatalf("first claim = %v, %v", ok, err)
+	}
+	if ok, err := s.ClaimDiscoverySchedule(ctx, acct.ID, *due[0].NextRunAt, now.Add(time.Hour)); err != nil || ok {
+		t.Fatalf("second claim = %v, %v; want false", ok, err)
+	}
+
+	jobID := uuid.New()
+	if err := s.RecordDiscoveryScheduleRun(ctx, acct.ID, domain.DiscoveryScheduleRun{
+		At: now, Status: domain.ScheduleRunFailed, Error: "boom", SyncJobID: &jobID,
+	}); err != nil {
+		t.Fatalf("RecordDiscoveryScheduleRun: %v", err)
+	}
+
+	// Reconfiguring keeps the last run.
+	sc.Cron, sc.Interval, sc.Enabled = "", "6h", false
+	if err := s.UpsertDiscoverySchedule(ctx, sc); err != nil {
+		t.Fatalf("second UpsertDiscoverySchedule: %v", err)
+	}
+	got, err := s.GetDiscoverySchedule(ctx, acct.ID)
+	if err != nil {
+		t.Fatalf("GetDiscoverySchedule: %v", err)
+	}
+	if got.Interval != "6h" || got.Cron != "" || got.Enabled {
+		t.Fatalf("config not replaced: %+v", got)
+	}
+	if got.LastStatus != domain.ScheduleRunFailed || got.LastError != "boom" || got.LastSyncJobID == nil || *got.LastSyncJobID != jobID {
+		t.Fatalf("last run lost: %+v", got)
+	}
+	if list, err := s.ListDiscoverySchedules(ctx); err != nil || len(list) != 1 {
+		t.Fatalf("ListDiscoverySchedules = %+v, %v", list, err)
+	}
+
+	if err := s.DeleteDiscoverySchedule(ctx, acct.ID); err != nil {
+		t.Fatalf("DeleteDiscoverySchedule: %v", err)
+	}
+	if _, err := s.GetDiscoverySchedule(ctx, acct.ID); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after delete, got %v", err)
+	}
+}
@@ -31,7 +31,7 @@ func (s *Store) CreateDriftItem(ctx context.Context, item domain.DriftItem) erro
 		resourceID = &s
 	}
 
-	_, err := s.db.ExecContext(ctx,
+	_, err := s.q().ExecContext(ctx,
 		`INSERT INTO drift_items (id, account_id, resource_id, pool_id, type, severity, status, title, description, resource_cidr, pool_cidr, details, ignore_reason, resolved_at, detected_at, updated_at)
 		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		item.ID, item.AccountID, resourceID, item.PoolID,
@@ -45,7 +45,7 @@ func (s *Store) CreateDriftItem(ctx context.Context, item domain.DriftItem) erro
 
 // GetDriftItem returns a single drift item by ID.
 func (s *Store) GetDriftItem(ctx context.Context, id string) (*domain.DriftItem, error) {
-	row := s.db.QueryRowContext(ctx,
+	row := s.q().QueryRowContext(ctx,
 		`SELECT id, account_id, resource_id, pool_id, type, severity, status, title, description, resource_cidr, pool_cidr, details, ignore_reason, resolved_at, detected_at, updated_at
 		 FROM drift_items WHERE id = ?`, id,
 	)
@@ -80,7 +80,7 @@ func (s *Store) ListDriftItems(ctx context.Context, filters domain.DriftFilters)
 	}
 
 	var total int
-	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM drift_items"+whereClause, args...).Scan(&total); err != nil {
+	if err := s.q().QueryRowContext(ctx, "SELECT COUNT(*) FROM drift_items"+whereClause, args...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
 
@@ -100,7 +100,7 @@ func (s *Store) ListDriftItems(ctx context.Context, filters domain.DriftFilters)
 	)
 	args = append(args, pageSize, offset)
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.q().QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -127,7 +127,7 @@ func (s *Store) UpdateDriftStatus(ctx context.Context, id string, status domain.
 	if status == domain.DriftStatusResolved {
 		resolvedAt = &now
 	}
-	res, err := s.db.ExecContext(ctx,
+	res, err := s.q().ExecContext(ctx,
 		`UPDATE drift_items SET status = ?, ignore_reason = ?, resolved_at = ?, updated_at = ? WHERE id = ?`,
 		string(status), nilIfEmpty(ignoreReason), resolvedAt, now, id,
 	)
@@ -159,7 +159,7 @@ func (s *Store) UpdateDriftDetails(ctx context.Context, id string, details map[s
 		detailsJSON = string(b)
 	}
 	now := time.Now().UTC().Format(time.RFC3339)
-	res, err := s.db.ExecContext(ctx,
+	res, err := s.q().ExecContext(ctx,
 		`UPDATE drift_items SET details = ?, updated_at = ? WHERE id = ?`,
 		detailsJSON, now, id,
 	)
@@ -175,7 +175,7 @@ func (s *Store) UpdateDriftDetails(ctx context.Context, id string, details map[s
 
 // DeleteOpenForAccount removes all open drift items for an account.
 func (s *Store) DeleteOpenForAccount(ctx context.Context, accountID int64) error {
-	_, err := s.db.ExecContext(ctx,
+	_, err := s.q().ExecContext(ctx,
 		`DELETE FROM drift_items WHERE account_id = ? AND status = ?`,
 		accountID, string(domain.DriftStatusOpen),
 	)
@@ -0,0 +1,279 @@
+//go:build sqlite
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+var _ storage.IPAddressStore = (*Store)(nil)
+
+const ipAddressColumns = `id, pool_id, address, hostname, mac_address, owner, status, source, description,
+	resource_id, created_at, updated_at`
+
+// CreateIPAddress stores a new record in a live pool.
+func (s *Store) CreateIPAddress(ctx context.Context, a domain.IPAddress) error {
+	return createIPAddress(ctx, s.q(), a)
+}
+
+func createIPAddress(ctx context.Context, q dbtx, a domain.IPAddress) error {
+	var resourceID any
+	if a.ResourceID != nil {
+		resourceID = a.ResourceID.String()
+	}
+	res, err := q.ExecContext(ctx,
+		`INSERT INTO ip_addresses (id, pool_id, address, address_key, hostname, mac_address, owner, status, source,
+			description, resource_id, created_at, updated_at)
+		 SELECT ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+		 WHERE EXISTS (SELECT 1 FROM pools WHERE id = ? AND deleted_at IS NULL)`,
+		a.ID, a.PoolID, a.Address, addressKey(a.Address), nilIfEmpty(a.Hostname), nilIfEmpty(a.MACAddress),
+		nilIfEmpty(a.Owner), string(a.Status), string(a.Source), nilIfEmpty(a.Description), resourceID,
+		a.CreatedAt.UTC().Format(time.RFC3339), a.UpdatedAt.UTC().Format(time.RFC3339), a.PoolID,
+	)
+	if err != nil {
+		if strings.Contains(err.Error(), "FOREIGN KEY") {
+			return fmt.Errorf("resource %v: %w", a.ResourceID, storage.ErrNotFound)
+		}
+		return storage.WrapIfConflict(err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("pool %d: %w", a.PoolID, storage.ErrNotFound)
+	}
+	return nil
+}
+
+// GetIPAddress returns a record by ID.
+func (s *Store) GetIPAddress(ctx context.Context, id string) (*domain.IPAddress, error) {
+	row := s.q().QueryRowContext(ctx, `SELECT `+ipAddressColumns+` FROM ip_addresses WHERE id = ?`, id)
+	a, err := scanIPAddress(row)
+	if err == sql.ErrNoRows {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// ListIPAddresses returns paginated records ordered by pool and address.
+func (s *Store) ListIPAddresses(ctx context.Context, filters domain.IPAddressFilters) ([]domain.IPAddress, int, error) {
+	// Records stay with soft-deleted pools but are no longer listed.
+	where := []string{"pool_id IN (SELECT id FROM pools WHERE deleted_at IS NULL)"}
+	var args []any
+	if filters.PoolID != 0 {
+		where = append(where, "pool_id = ?")
+		args = append(args, filters.PoolID)
+	}
+	if filters.Status != "" {
+		where = append(where, "status = ?")
+		args = append(args, filters.Status)
+	}
+	if filters.Source != "" {
+		where = append(where, "source = ?")
+		args = append(args, filters.Source)
+	}
+	if filters.Query != "" {
+		where = append(where, "(address LIKE ? OR LOWER(hostname) LIKE ? OR LOWER(mac_address) LIKE ? OR LOWER(owner) LIKE ?)")
+		like := "%" + strings.ToLower(filters.Query) + "%"
+		args = append(args, like, like, like, like)
+	}
+	whereClause := " WHERE " + strings.Join(where, " AND ")
+
+	var total int
+	if err := s.q().QueryRowContext(ctx, "SELECT COUNT(*) FROM ip_addresses"+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	page := filters.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := filters.PageSize
+	if pageSize < 1 {
+		pageSize = 50
+	}
+	args = append(args, pageSize, (page-1)*pageSize)
+
+	rows, err := s.q().QueryContext(ctx,
+		`SELECT `+ipAddressColumns+` FROM ip_addresses`+whereClause+` ORDER BY pool_id, address_key LIMIT ? OFFSET ?`,
+		args...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	out := []domain.IPAddress{}
+	for rows.Next() {
+		a, err := scanIPAddress(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		out = append(out, a)
+	}
+	return out, total, rows.Err()
+}
+
+// UpdateIPAddress replaces a record's mutable fields.
+func (s *Store) UpdateIPAddress(ctx context.Context, a domain.IPAddress) error {
+	var resourceID any
+	if a.ResourceID != nil {
+		resourceID = a.ResourceID.String()
+	}
+	res, err := s.q().ExecContext(ctx,
+		`UPDATE ip_addresses
+		 SET hostname = ?, mac_address = ?, owner = ?, status = ?, source = ?, description = ?, resource_id = ?, updated_at = ?
+		 WHERE id = ?`,
+		nilIfEmpty(a.Hostname), nilIfEmpty(a.MACAddress), nilIfEmpty(a.Owner), string(a.Status), string(a.Source),
+		nilIfEmpty(a.Description), resourceID, a.UpdatedAt.UTC().Format(time.RFC3339), a.ID,
+	)
+	if err != nil {
+		if strings.Contains(err.Error(), "FOREIGN KEY") {
+			return fmt.Errorf("resource %v: %w", a.ResourceID, storage.ErrNotFound)
+		}
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteIPAddress removes a record.
+func (s *Store) DeleteIPAddress(ctx context.Context, id string) error {
+	res, err := s.q().ExecContext(ctx, `DELETE FROM ip_addresses WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// AllocateIPAddress picks and inserts a record in a single transaction.
+func (s *Store) AllocateIPAddress(ctx context.Context, poolID int64, pick storage.IPAddressPickFunc) (domain.IPAddress, error) {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return domain.IPAddress{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Touch the pool first so concurrent allocators queue on SQLite's
+	// reserved lock instead of reading the same free addresses.
+	res, err := tx.ExecContext(ctx, `UPDATE pools SET updated_at = updated_at WHERE id = ? AND deleted_at IS NULL`, poolID)
+	if err != nil {
+		return domain.IPAddress{}, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.IPAddress{}, fmt.Errorf("pool not found: %w", storage.ErrNotFound)
+	}
+
+	pool, ok, err := getPool(ctx, tx, poolID)
+	if err != nil {
+		return domain.IPAddress{}, err
+	}
+	if !ok {
+		return domain.IPAddress{}, fmt.Errorf("pool not found: %w", storage.ErrNotFound)
+	}
+	children, err := poolChildren(ctx, tx, poolID)
+	if err != nil {
+		return domain.IPAddress{}, err
+	}
+	existing, err := poolIPAddresses(ctx, tx, poolID)
+	if err != nil {
+		return domain.IPAddress{}, err
+	}
+
+	a, err := pick(pool, children, existing)
+	if err != nil {
+		return domain.IPAddress{}, err
+	}
+	a.PoolID = poolID
+	if err := createIPAddress(ctx, tx, a); err != nil {
+		return domain.IPAddress{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.IPAddress{}, err
+	}
+	return a, nil
+}
+
+func poolIPAddresses(ctx context.Context, q dbtx, poolID int64) ([]domain.IPAddress, error) {
+	rows, err := q.QueryContext(ctx, `SELECT `+ipAddressColumns+` FROM ip_addresses WHERE pool_id = ? ORDER BY address_key`, poolID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []domain.IPAddress
+	for rows.Next() {
+		a, err := scanIPAddress(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+func poolAddressList(ctx context.Context, q dbtx, poolID int64) ([]string, error) {
+	rows, err := q.QueryContext(ctx, `SELECT address FROM ip_addresses WHERE pool_id = ?`, poolID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var addr string
+		if err := rows.Scan(&addr); err != nil {
+			return nil, err
+		}
+		out = append(out, addr)
+	}
+	return out, rows.Err()
+}
+
+// addressKey returns the 16-byte form of addr in hex, which sorts
+// numerically. IPv4 addresses use their IPv4-mapped form.
+func addressKey(addr string) string {
+	a, err := netip.ParseAddr(addr)
+	if err != nil {
+		return addr
+	}
+	b := a.As16()
+	return hex.EncodeToString(b[:])
+}
+
+func scanIPAddress(row interface{ Scan(dest ...any) error }) (domain.IPAddress, error) {
+	var a domain.IPAddress
+	var status, source, createdAt, updatedAt string
+	var hostname, mac, owner, description, resourceID sql.NullString
+	if err := row.Scan(&a.ID, &a.PoolID, &a.Address, &hostname, &mac, &owner, &status, &source, &description,
+		&resourceID, &createdAt, &updatedAt); err != nil {
+		return a, err
+	}
+	a.Hostname = hostname.String
+	a.MACAddress = mac.String
+	a.Owner = owner.String
+	a.Description = description.String
+	a.Status = domain.IPAddressStatus(status)
+	a.Source = domain.PoolSource(source)
+	if resourceID.Valid {
+		if id, err := uuid.Parse(resourceID.String); err == nil {
+			a.ResourceID = &id
+		}
+	}
+	a.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	a.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+	return a, nil
+}
@@ -0,0 +1,99 @@
+//go:build sqlite
+
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+func TestIPAddressStore(t *testing.T) {
+	s, err := New("file:" + filepath.Join(t.TempDir(), "ipaddress.db"))
+	if err != nil {
+		t.Fatalf("new sqlite store: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	pool, err := s.CreatePool(ctx, domain.CreatePool{Name: "apps", CIDR: "10.0.0.0/24", Type: domain.PoolTypeSubnet})
+	if err != nil {
+		t.Fatalf("CreatePool: %v", err)
+	}
+	record := func(id, addr string) domain.IPAddress {
+		return domain.IPAddress{
+			ID: id, PoolID: pool.ID, Address: addr, Status: domain.IPAddressStatusAssigned,
+			Source: domain.PoolSourceManual, CreatedAt: now, UpdatedAt: now,
+		}
+	}
+
+	// 10.0.0.9 must sort before 10.0.0.10 even though it is larger as text.
+	for _, a := range []domain.IPAddress{record("a1", "10.0.0.10"), record("a2", "10.0.0.9")} {
+		if err := s.CreateIPAddress(ctx, a); err != nil {
+			t.Fatalf("CreateIPAddress %s: %v", a.Address, err)
+		}
+	}
+	if err := s.CreateIPAddress(ctx, record("a3", "10.0.0.10")); !errors.Is(err, storage.ErrConflict) {
+		t.Fatalf("duplicate address: got %v, want ErrConflict", err)
+	}
+	missing := record("a4", "10.0.0.11")
+	missing.PoolID = 999
+	if err := s.CreateIPAddress(ctx, missing); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("missing pool: got %v, want ErrNotFound", err)
+	}
+
+	items, total, err := s.ListIPAddresses(ctx, domain.IPAddressFilters{PoolID: pool.ID})
+	if err != nil || total != 2 || items[0].Address != "10.0.0.9" || items[1].Address != "10.0.0.10" {
+		t.Fatalf("ListIPAddresses = %+v, %d, %v", items, total, err)
+	}
+
+	got, err := s.GetIPAddress(ctx, "a1")
+	if err != nil || !got.CreatedAt.Equal(now) {
+		t.Fatalf("GetIPAddress = %+v, %v", got, err)
+	}
+	got.Hostname = "web-1"
+	got.Status = domain.IPAddressStatusReserved
+	if err := s.UpdateIPAddress(ctx, *got); err != nil {
+		t.Fatalf("UpdateIPAddress: %v", err)
+	}
+	items, _, err = s.ListIPAddresses(ctx, domain.IPAddressFilters{Query: "WEB", Status: "reserved"})
+	if err != nil || len(items) != 1 || items[0].ID != "a1" {
+		t.Fatalf("filtered list = %+v, %v", items, err)
+	}
+
+	stats, err := s.CalculatePoolUtilization(ctx, pool.ID)
+	if err != nil || stats.UsedIPs != 2 {
+		t.Fatalf("CalculatePoolUtilization = %+v, %v", stats, err)
+	}
+
+	allocated, err := s.AllocateIPAddress(ctx, pool.ID, func(p domain.Pool, children []domain.Pool, existing []domain.IPAddress) (domain.IPAddress, error) {
+		if p.ID != pool.ID || len(existing) != 2 {
+			t.Errorf("pick got pool %d with %d records", p.ID, len(existing))
+		}
+		return record("a5", "10.0.0.1"), nil
+	})
+	if err != nil || allocated.PoolID != pool.ID {
+		t.Fatalf("AllocateIPAddress = %+v, %v", allocated, err)
+	}
+
+	if err := s.DeleteIPAddress(ctx, "a1"); err != nil {
+		t.Fatalf("DeleteIPAddress: %v", err)
+	}
+	if err := s.DeleteIPAddress(ctx, "a1"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("second delete: got %v, want ErrNotFound", err)
+	}
+
+	// Deleting the pool removes its records.
+	if _, err := s.DeletePoolCascade(ctx, pool.ID); err != nil {
+		t.Fatalf("DeletePoolCascade: %v", err)
+	}
+	if _, total, err := s.ListIPAddresses(ctx, domain.IPAddressFilters{}); err != nil || total != 0 {
+		t.Fatalf("records after pool delete = %d, %v", total, err)
+	}
+}
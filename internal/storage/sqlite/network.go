@@ -56,7 +56,7 @@ func (s *Store) ListNetworkObjects(ctx context.Context, filters domain.NetworkOb
 		where = append(where, "(LOWER(name) LIKE ? OR LOWER(cidr) LIKE ? OR LOWER(ip_address) LIKE ? OR LOWER(provider_resource_id) LIKE ?)")
 		args = append(args, q, q, q, q)
 	}
-	rows, err := s.db.QueryContext(ctx, `SELECT id, object_type, provider, account_id, region, name, cidr, ip_address, provider_resource_id, parent_object_id, pool_id, source_discovered_id, state, metadata, created_at, updated_at
+	rows, err := s.q().QueryContext(ctx, `SELECT id, object_type, provider, account_id, region, name, cidr, ip_address, provider_resource_id, parent_object_id, pool_id, source_discovered_id, state, metadata, created_at, updated_at
 		FROM network_objects WHERE `+strings.Join(where, " AND ")+` ORDER BY account_id ASC, region ASC, name ASC`, args...)
 	if err != nil {
 		return nil, err
@@ -78,7 +78,7 @@ func (s *Store) ListNetworkObjects(ctx context.Context, filters domain.NetworkOb
 }
 
 func (s *Store) GetNetworkObject(ctx context.Context, id int64) (domain.NetworkObject, bool, error) {
-	row := s.db.QueryRowContext(ctx, `SELECT id, object_type, provider, account_id, region, name, cidr, ip_address, provider_resource_id, parent_object_id, pool_id, source_discovered_id, state, metadata, created_at, updated_at
+	row := s.q().QueryRowContext(ctx, `SELECT id, object_type, provider, account_id, region, name, cidr, ip_address, provider_resource_id, parent_object_id, pool_id, source_discovered_id, state, metadata, created_at, updated_at
 		FROM network_objects WHERE id = ?`, id)
 	obj, err := scanNetworkObject(row)
 	if err != nil {
@@ -117,7 +117,7 @@ func (s *Store) CreateNetworkObject(ctx context.Context, in domain.CreateNetwork
 		discoveredID = &id
 	}
 	now := time.Now().UTC().Format(time.RFC3339)
-	res, err := s.db.ExecContext(ctx, `INSERT INTO network_objects
+	res, err := s.q().ExecContext(ctx, `INSERT INTO network_objects
 		(object_type, provider, account_id, region, name, cidr, ip_address, provider_resource_id, parent_object_id, pool_id, source_discovered_id, state, metadata, created_at, updated_at)
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		string(objectType), in.Provider, in.AccountID, in.Region, in.Name, in.CIDR, in.IPAddress, in.ProviderResourceID,
@@ -189,7 +189,7 @@ func (s *Store) UpdateNetworkObject(ctx context.Context, id int64, update domain
 		discoveredID = &id
 	}
 	now := time.Now().UTC().Format(time.RFC3339)
-	_, err = s.db.ExecContext(ctx, `UPDATE network_objects SET
+	_, err = s.q().ExecContext(ctx, `UPDATE network_objects SET
 		object_type = ?, provider = ?, account_id = ?, region = ?, name = ?, cidr = ?, ip_address = ?, provider_resource_id = ?,
 		parent_object_id = ?, pool_id = ?, source_discovered_id = ?, state = ?, metadata = ?, updated_at = ?
 		WHERE id = ?`,
@@ -241,7 +241,7 @@ func (s *Store) ListNetworkRelationships(ctx context.Context, filters domain.Net
 		where = append(where, "resolution_state = ?")
 		args = append(args, filters.ResolutionState)
 	}
-	rows, err := s.db.QueryContext(ctx, `SELECT id, type, source_kind, source_id, target_kind, target_id, confidence, reason, evidence, resolution_state, created_at, updated_at
+	rows, err := s.q().QueryContext(ctx, `SELECT id, type, source_kind, source_id, target_kind, target_id, confidence, reason, evidence, resolution_state, created_at, updated_at
 		FROM network_relationships WHERE `+strings.Join(where, " AND ")+` ORDER BY id ASC`, args...)
 	if err != nil {
 		return nil, err
@@ -278,7 +278,7 @@ func (s *Store) UpsertNetworkRelationship(ctx context.Context, in domain.CreateN
 	}
 	evidence, _ := json.Marshal(in.Evidence)
 	now := time.Now().UTC().Format(time.RFC3339)
-	_, err := s.db.ExecContext(ctx, `INSERT INTO network_relationships
+	_, err := s.q().ExecContext(ctx, `INSERT INTO network_relationships
 		(id, type, source_kind, source_id, target_kind, target_id, confidence, reason, evidence, resolution_state, created_at, updated_at)
 		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
@@ -313,7 +313,7 @@ func (s *Store) UpdateNetworkRelationshipState(ctx context.Context, id string, s
 		return domain.NetworkRelationship{}, false, fmt.Errorf("resolution_state is required: %w", storage.ErrValidation)
 	}
 	now := time.Now().UTC().Format(time.RFC3339)
-	res, err := s.db.ExecContext(ctx, `UPDATE network_relationships SET resolution_state = ?, reason = CASE WHEN ? = '' THEN reason ELSE ? END, updated_at = ? WHERE id = ?`, state, reason, reason, now, id)
+	res, err := s.q().ExecContext(ctx, `UPDATE network_relationships SET resolution_state = ?, reason = CASE WHEN ? = '' THEN reason ELSE ? END, updated_at = ? WHERE id = ?`, state, reason, reason, now, id)
 	if err != nil {
 		return domain.NetworkRelationship{}, false, err
 	}
@@ -19,12 +19,16 @@ func (s *Store) CreateProvider(ctx context.Context, p *domain.OIDCProvider) erro
 	if err != nil {
 		return err
 	}
+	roleRules, err := json.Marshal(p.RoleRules)
+	if err != nil {
+		return err
+	}
 
-	_, err = s.db.ExecContext(ctx,
-		`INSERT INTO oidc_providers (id, name, issuer_url, client_id, client_secret_encrypted, scopes, role_mapping, default_role, auto_provision, enabled, created_at, updated_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+	_, err = s.q().ExecContext(ctx,
+		`INSERT INTO oidc_providers (id, name, issuer_url, client_id, client_secret_encrypted, scopes, role_mapping, role_rules, default_role, auto_provision, enabled, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		p.ID, p.Name, p.IssuerURL, p.ClientID, p.ClientSecretEncrypted,
-		p.Scopes, string(roleMapping), p.DefaultRole,
+		p.Scopes, string(roleMapping), string(roleRules), p.DefaultRole,
 		boolToInt(p.AutoProvision), boolToInt(p.Enabled),
 		p.CreatedAt.Format(time.RFC3339), p.UpdatedAt.Format(time.RFC3339),
 	)
@@ -40,8 +44,8 @@ func (s *Store) CreateProvider(ctx context.Context, p *domain.OIDCProvider) erro
 
 // GetProvider retrieves an OIDC provider by ID.
 func (s *Store) GetProvider(ctx context.Context, id string) (*domain.OIDCProvider, error) {
-	row := s.db.QueryRowContext(ctx,
-		`SELECT id, name, issuer_url, client_id, client_secret_encrypted, scopes, role_mapping, default_role, auto_provision, enabled, created_at, updated_at
+	row := s.q().QueryRowContext(ctx,
+		`SELECT id, name, issuer_url, client_id, client_secret_encrypted, scopes, role_mapping, role_rules, default_role, auto_provision, enabled, created_at, updated_at
 		 FROM oidc_providers WHERE id = ?`, id,
 	)
 	return scanProvider(row)
@@ -49,8 +53,8 @@ func (s *Store) GetProvider(ctx context.Context, id string) (*domain.OIDCProvide
 
 // GetProviderByIssuer retrieves an OIDC provider by issuer URL.
 func (s *Store) GetProviderByIssuer(ctx context.Context, issuerURL string) (*domain.OIDCProvider, error) {
-	row := s.db.QueryRowContext(ctx,
-		`SELECT id, name, issuer_url, client_id, client_secret_encrypted, scopes, role_mapping, default_role, auto_provision, enabled, created_at, updated_at
+	row := s.q().QueryRowContext(ctx,
+		`SELECT id, name, issuer_url, client_id, client_secret_encrypted, scopes, role_mapping, role_rules, default_role, auto_provision, enabled, created_at, updated_at
 		 FROM oidc_providers WHERE issuer_url = ?`, issuerURL,
 	)
 	return scanProvider(row)
@@ -58,8 +62,8 @@ func (s *Store) GetProviderByIssuer(ctx context.Context, issuerURL string) (*dom
 
 // ListProviders returns all configured OIDC providers.
 func (s *Store) ListProviders(ctx context.Context) ([]*domain.OIDCProvider, error) {
-	rows, err := s.db.QueryContext(ctx,
-		`SELECT id, name, issuer_url, client_id, client_secret_encrypted, scopes, role_mapping, default_role, auto_provision, enabled, created_at, updated_at
+	rows, err := s.q().QueryContext(ctx,
+		`SELECT id, name, issuer_url, client_id, client_secret_encrypted, scopes, role_mapping, role_rules, default_role, auto_provision, enabled, created_at, updated_at
 		 FROM oidc_providers ORDER BY name`,
 	)
 	if err != nil {
@@ -72,8 +76,8 @@ func (s *Store) ListProviders(ctx context.Context) ([]*domain.OIDCProvider, erro
 
 // ListEnabledProviders returns only enabled OIDC providers.
 func (s *Store) ListEnabledProviders(ctx context.Context) ([]*domain.OIDCProvider, error) {
-	rows, err := s.db.QueryContext(ctx,
-		`SELECT id, name, issuer_url, client_id, client_secret_encrypted, scopes, role_mapping, default_role, auto_provision, enabled, created_at, updated_at
+	rows, err := s.q().QueryContext(ctx,
+		`SELECT id, name, issuer_url, client_id, client_secret_encrypted, scopes, role_mapping, role_rules, default_role, auto_provision, enabled, created_at, updated_at
 		 FROM oidc_providers WHERE enabled = 1 ORDER BY name`,
 	)
 	if err != nil {
@@ -90,12 +94,16 @@ func (s *Store) UpdateProvider(ctx context.Context, p *domain.OIDCProvider) erro
 	if err != nil {
 		return err
 	}
+	roleRules, err := json.Marshal(p.RoleRules)
+	if err != nil {
+		return err
+	}
 
-	res, err := s.db.ExecContext(ctx,
-		`UPDATE oidc_providers SET name = ?, issuer_url = ?, client_id = ?, client_secret_encrypted = ?, scopes = ?, role_mapping = ?, default_role = ?, auto_provision = ?, enabled = ?, updated_at = ?
+	res, err := s.q().ExecContext(ctx,
+		`UPDATE oidc_providers SET name = ?, issuer_url = ?, client_id = ?, client_secret_encrypted = ?, scopes = ?, role_mapping = ?, role_rules = ?, default_role = ?, auto_provision = ?, enabled = ?, updated_at = ?
 		 WHERE id = ?`,
 		p.Name, p.IssuerURL, p.ClientID, p.ClientSecretEncrypted,
-		p.Scopes, string(roleMapping), p.DefaultRole,
+		p.Scopes, string(roleMapping), string(roleRules), p.DefaultRole,
 		boolToInt(p.AutoProvision), boolToInt(p.Enabled),
 		p.UpdatedAt.Format(time.RFC3339), p.ID,
 	)
@@ -115,7 +123,7 @@ func (s *Store) UpdateProvider(ctx context.Context, p *domain.OIDCProvider) erro
 
 // DeleteProvider removes an OIDC provider by ID.
 func (s *Store) DeleteProvider(ctx context.Context, id string) error {
-	res, err := s.db.ExecContext(ctx, `DELETE FROM oidc_providers WHERE id = ?`, id)
+	res, err := s.q().ExecContext(ctx, `DELETE FROM oidc_providers WHERE id = ?`, id)
 	if err != nil {
 		return err
 	}
@@ -129,11 +137,11 @@ func (s *Store) DeleteProvider(ctx context.Context, id string) error {
 // scanProvider scans a single row into an OIDCProvider.
 func scanProvider(row *sql.Row) (*domain.OIDCProvider, error) {
 	var p domain.OIDCProvider
-	var roleMappingJSON, createdAt, updatedAt string
+	var roleMappingJSON, roleRulesJSON, createdAt, updatedAt string
 	var autoProvision, enabled int
 
 	if err := row.Scan(&p.ID, &p.Name, &p.IssuerURL, &p.ClientID, &p.ClientSecretEncrypted,
-		&p.Scopes, &roleMappingJSON, &p.DefaultRole,
+		&p.Scopes, &roleMappingJSON, &roleRulesJSON, &p.DefaultRole,
 		&autoProvision, &enabled, &createdAt, &updatedAt); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, storage.ErrNotFound
@@ -148,6 +156,7 @@ func scanProvider(row *sql.Row) (*domain.OIDCProvider, error) {
 
 	p.RoleMapping = make(map[string]string)
 	_ = json.Unmarshal([]byte(roleMappingJSON), &p.RoleMapping)
+	_ = json.Unmarshal([]byte(roleRulesJSON), &p.RoleRules)
 
 	return &p, nil
 }
@@ -157,11 +166,11 @@ func scanProviderRows(rows *sql.Rows) ([]*domain.OIDCProvider, error) {
 	var out []*domain.OIDCProvider
 	for rows.Next() {
 		var p domain.OIDCProvider
-		var roleMappingJSON, createdAt, updatedAt string
+		var roleMappingJSON, roleRulesJSON, createdAt, updatedAt string
 		var autoProvision, enabled int
 
 		if err := rows.Scan(&p.ID, &p.Name, &p.IssuerURL, &p.ClientID, &p.ClientSecretEncrypted,
-			&p.Scopes, &roleMappingJSON, &p.DefaultRole,
+			&p.Scopes, &roleMappingJSON, &roleRulesJSON, &p.DefaultRole,
 			&autoProvision, &enabled, &createdAt, &updatedAt); err != nil {
 			return nil, err
 		}
@@ -173,6 +182,7 @@ func scanProviderRows(rows *sql.Rows) ([]*domain.OIDCProvider, error) {
 
 		p.RoleMapping = make(map[string]string)
 		_ = json.Unmarshal([]byte(roleMappingJSON), &p.RoleMapping)
+		_ = json.Unmarshal([]byte(roleRulesJSON), &p.RoleRules)
 
 		out = append(out, &p)
 	}
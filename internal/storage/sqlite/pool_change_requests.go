@@ -0,0 +1,178 @@
+//go:build sqlite
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+var _ storage.PoolChangeRequestStore = (*Store)(nil)
+
+const poolChangeRequestColumns = `id, operation, status, pool_id, pool_version, pool_name, cidr, parent_id, payload,
+	delete_cascade, policy_ids, requested_by, requested_by_id, requested_role, reviewed_by, reviewed_by_id,
+	reviewed_role, review_comment, created_at, reviewed_at`
+
+// poolChangePayload is the payload column: the create or update body, or
+// the CIDR a resize moves the pool to.
+type poolChangePayload struct {
+	Create *domain.CreatePool `json:"create,omitempty"`
+	Update *domain.UpdatePool `json:"update,omitempty"`
+	Resize string             `json:"resize,omitempty"`
+}
+
+// CreatePoolChangeRequest stores a new request. The hold check and the
+// insert share a transaction, so two overlapping creates cannot both land.
+func (s *Store) CreatePoolChangeRequest(ctx context.Context, r domain.PoolChangeRequest) error {
+	payload, err := json.Marshal(poolChangePayload{Create: r.Create, Update: r.Update, Resize: r.Resize})
+	if err != nil {
+		return err
+	}
+	if r.PolicyIDs == nil {
+		r.PolicyIDs = []string{}
+	}
+	policyIDs, err := json.Marshal(r.PolicyIDs)
+	if err != nil {
+		return err
+	}
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if r.HoldsCIDR() {
+		held, err := s.listPoolChangeRequests(ctx, tx, domain.PoolChangeRequestPending)
+		if err != nil {
+			return err
+		}
+		if h := storage.OverlappingHold(held, r.CIDR, r.ParentID); h != nil {
+			return fmt.Errorf("%s is held by change request %s (%s): %w", r.CIDR, h.ID, h.CIDR, storage.ErrConflict)
+		}
+	}
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO pool_change_requests (`+poolChangeRequestColumns+`)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		r.ID, string(r.Operation), string(r.Status), r.PoolID, r.PoolVersion, r.PoolName, nilIfEmpty(r.CIDR),
+		r.ParentID, string(payload), boolToInt(r.Cascade), string(policyIDs), r.RequestedBy, r.RequestedByID,
+		r.RequestedRole, nilIfEmpty(r.ReviewedBy), nilIfEmpty(r.ReviewedByID), nilIfEmpty(r.ReviewedRole),
+		nilIfEmpty(r.ReviewComment),
+		r.CreatedAt.UTC().Format(time.RFC3339), formatTimePtr(r.ReviewedAt),
+	)
+	if err != nil {
+		return storage.WrapIfConflict(err)
+	}
+	return tx.Commit()
+}
+
+// GetPoolChangeRequest returns a request by ID.
+func (s *Store) GetPoolChangeRequest(ctx context.Context, id string) (*domain.PoolChangeRequest, error) {
+	row := s.q().QueryRowContext(ctx, `SELECT `+poolChangeRequestColumns+` FROM pool_change_requests WHERE id = ?`, id)
+	r, err := scanPoolChangeRequest(row)
+	if err == sql.ErrNoRows {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// ListPoolChangeRequests returns requests, newest first.
+func (s *Store) ListPoolChangeRequests(ctx context.Context, status domain.PoolChangeRequestStatus) ([]domain.PoolChangeRequest, error) {
+	return s.listPoolChangeRequests(ctx, s.q(), status)
+}
+
+func (s *Store) listPoolChangeRequests(ctx context.Context, q dbtx, status domain.PoolChangeRequestStatus) ([]domain.PoolChangeRequest, error) {
+	query := `SELECT ` + poolChangeRequestColumns + ` FROM pool_change_requests`
+	var args []any
+	if status != "" {
+		query += ` WHERE status = ?`
+		args = append(args, string(status))
+	}
+	rows, err := q.QueryContext(ctx, query+` ORDER BY created_at DESC, id DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []domain.PoolChangeRequest{}
+	for rows.Next() {
+		r, err := scanPoolChangeRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// ReviewPoolChangeRequest records the decision on a pending request.
+func (s *Store) ReviewPoolChangeRequest(ctx context.Context, r domain.PoolChangeRequest) error {
+	res, err := s.q().ExecContext(ctx,
+		`UPDATE pool_change_requests
+		 SET status = ?, pool_id = ?, reviewed_by = ?, reviewed_by_id = ?, reviewed_role = ?, review_comment = ?,
+		     reviewed_at = ?
+		 WHERE id = ? AND status = ?`,
+		string(r.Status), r.PoolID, nilIfEmpty(r.ReviewedBy), nilIfEmpty(r.ReviewedByID), nilIfEmpty(r.ReviewedRole),
+		nilIfEmpty(r.ReviewComment), formatTimePtr(r.ReviewedAt), r.ID, string(domain.PoolChangeRequestPending),
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		return nil
+	}
+	var status string
+	err = s.q().QueryRowContext(ctx, `SELECT status FROM pool_change_requests WHERE id = ?`, r.ID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return storage.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("change request %s is %s: %w", r.ID, status, storage.ErrConflict)
+}
+
+func scanPoolChangeRequest(row interface{ Scan(dest ...any) error }) (domain.PoolChangeRequest, error) {
+	var r domain.PoolChangeRequest
+	var operation, status, payload, policyIDs, createdAt string
+	var poolID, parentID sql.NullInt64
+	var cascade int
+	var cidr, reviewedBy, reviewedByID, reviewedRole, reviewComment, reviewedAt sql.NullString
+	if err := row.Scan(&r.ID, &operation, &status, &poolID, &r.PoolVersion, &r.PoolName, &cidr, &parentID, &payload,
+		&cascade, &policyIDs, &r.RequestedBy, &r.RequestedByID, &r.RequestedRole, &reviewedBy, &reviewedByID,
+		&reviewedRole, &reviewComment, &createdAt, &reviewedAt); err != nil {
+		return r, err
+	}
+	r.Operation = domain.PoolChangeOperation(operation)
+	r.Status = domain.PoolChangeRequestStatus(status)
+	if poolID.Valid {
+		r.PoolID = &poolID.Int64
+	}
+	if parentID.Valid {
+		r.ParentID = &parentID.Int64
+	}
+	r.CIDR = cidr.String
+	var body poolChangePayload
+	if err := json.Unmarshal([]byte(payload), &body); err != nil {
+		return r, err
+	}
+	r.Create, r.Update, r.Resize = body.Create, body.Update, body.Resize
+	r.Cascade = cascade != 0
+	if err := json.Unmarshal([]byte(policyIDs), &r.PolicyIDs); err != nil {
+		return r, err
+	}
+	r.ReviewedBy = reviewedBy.String
+	r.ReviewedByID = reviewedByID.String
+	r.ReviewedRole = reviewedRole.String
+	r.ReviewComment = reviewComment.String
+	r.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	r.ReviewedAt = parseTimePtr(reviewedAt)
+	return r, nil
+}
@@ -0,0 +1,93 @@
+//go:build sqlite
+
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+func TestPoolChangeRequestStore(t *testing.T) {
+	s, err := New("file:" + filepath.Join(t.TempDir(), "change_requests.db"))
+	if err != nil {
+		t.Fatalf("new sqlite store: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	parent := int64(3)
+
+	r := domain.PoolChangeRequest{
+		ID: "r1", Operation: domain.PoolChangeCreate, Status: domain.PoolChangeRequestPending,
+		PoolName: "prod-vpc", CIDR: "10.1.0.0/16", ParentID: &parent,
+		Create: &domain.CreatePool{
+			Name: "prod-vpc", CIDR: "10.1.0.0/16", ParentID: &parent,
+			Type: domain.PoolTypeVPC, Tags: map[string]string{"env": "prod"},
+		},
+		PolicyIDs: []string{"prod-vpcs"}, RequestedBy: "alice", RequestedByID: "user:u-alice", RequestedRole: "operator", CreatedAt: now,
+	}
+	if err := s.CreatePoolChangeRequest(ctx, r); err != nil {
+		t.Fatalf("CreatePoolChangeRequest: %v", err)
+	}
+	held := r
+	held.ID, held.CIDR = "r2", "10.1.4.0/24"
+	if err := s.CreatePoolChangeRequest(ctx, held); !errors.Is(err, storage.ErrConflict) {
+		t.Fatalf("overlapping hold: expected ErrConflict, got %v", err)
+	}
+
+	got, err := s.GetPoolChangeRequest(ctx, "r1")
+	if err != nil {
+		t.Fatalf("GetPoolChangeRequest: %v", err)
+	}
+	if got.Create == nil || got.Create.Type != domain.PoolTypeVPC || got.Create.Tags["env"] != "prod" ||
+		*got.ParentID != 3 || got.PoolID != nil || len(got.PolicyIDs) != 1 || got.ReviewedAt != nil || !got.CreatedAt.Equal(now) {
+		t.Fatalf("GetPoolChangeRequest = %+v", got)
+	}
+
+	reviewed := now.Add(time.Hour)
+	created := int64(12)
+	got.Status, got.PoolID = domain.PoolChangeRequestApplied, &created
+	got.ReviewedBy, got.ReviewedRole, got.ReviewComment, got.ReviewedAt = "bob", "admin", "lgtm", &reviewed
+	got.ReviewedByID = "user:u-bob"
+	if err := s.ReviewPoolChangeRequest(ctx, *got); err != nil {
+		t.Fatalf("ReviewPoolChangeRequest: %v", err)
+	}
+	if err := s.ReviewPoolChangeRequest(ctx, *got); !errors.Is(err, storage.ErrConflict) {
+		t.Fatalf("second ReviewPoolChangeRequest: expected ErrConflict, got %v", err)
+	}
+	got.ID = "missing"
+	if err := s.ReviewPoolChangeRequest(ctx, *got); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("ReviewPoolChangeRequest missing: expected ErrNotFound, got %v", err)
+	}
+
+	// Once decided, the block is free for another request.
+	if err := s.CreatePoolChangeRequest(ctx, held); err != nil {
+		t.Fatalf("hold after approval: %v", err)
+	}
+	list, err := s.ListPoolChangeRequests(ctx, domain.PoolChangeRequestApplied)
+	if err != nil || len(list) != 1 || *list[0].PoolID != 12 || list[0].ReviewComment != "lgtm" ||
+		list[0].RequestedByID != "user:u-alice" || list[0].ReviewedByID != "user:u-bob" || !list[0].ReviewedAt.Equal(reviewed) {
+		t.Fatalf("ListPoolChangeRequests(applied) = %+v, %v", list, err)
+	}
+	if pending, err := s.ListPoolChangeRequests(ctx, domain.PoolChangeRequestPending); err != nil || len(pending) != 1 || pending[0].ID != "r2" {
+		t.Fatalf("ListPoolChangeRequests(pending) = %+v, %v", pending, err)
+	}
+
+	resize := domain.PoolChangeRequest{
+		ID: "r3", Operation: domain.PoolChangeUpdate, Status: domain.PoolChangeRequestPending,
+		PoolID: &created, PoolVersion: 2, PoolName: "prod-vpc", Resize: "10.1.0.0/15",
+		PolicyIDs: []string{"prod-vpcs"}, RequestedBy: "alice", RequestedByID: "user:u-alice", RequestedRole: "operator", CreatedAt: now,
+	}
+	if err := s.CreatePoolChangeRequest(ctx, resize); err != nil {
+		t.Fatalf("CreatePoolChangeRequest resize: %v", err)
+	}
+	if got, err := s.GetPoolChangeRequest(ctx, "r3"); err != nil || got.Resize != "10.1.0.0/15" || got.Update != nil {
+		t.Fatalf("GetPoolChangeRequest resize = %+v, %v", got, err)
+	}
+}
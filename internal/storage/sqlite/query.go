@@ -0,0 +1,265 @@
+//go:build sqlite
+
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloudpam/internal/cidr"
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+var _ storage.Queryable = (*Store)(nil)
+
+// whereClause accumulates AND-ed SQL conditions and their arguments.
+type whereClause struct {
+	conds []string
+	args  []any
+}
+
+func (w *whereClause) add(cond string, args ...any) {
+	w.conds = append(w.conds, cond)
+	w.args = append(w.args, args...)
+}
+
+func (w *whereClause) String() string {
+	return strings.Join(w.conds, " AND ")
+}
+
+// refCond filters a nullable id column, where id 0 matches NULL.
+func (w *whereClause) refCond(column string, id int64) {
+	if id == 0 {
+		w.add(column + ` IS NULL`)
+		return
+	}
+	w.add(column+` = ?`, id)
+}
+
+// createdRange adds the half-open [after, before) creation window. Timestamps
+// are stored as UTC RFC 3339 text, which sorts chronologically.
+func (w *whereClause) createdRange(after, before *int64) {
+	if after != nil {
+		w.add(`created_at >= ?`, time.Unix(*after, 0).UTC().Format(time.RFC3339))
+	}
+	if before != nil {
+		w.add(`created_at < ?`, time.Unix(*before, 0).UTC().Format(time.RFC3339))
+	}
+}
+
+// limitClause renders LIMIT/OFFSET; SQLite needs a LIMIT before OFFSET, and
+// -1 means no limit.
+func limitClause(limit, offset int) string {
+	if limit == 0 && offset == 0 {
+		return ""
+	}
+	if limit == 0 {
+		limit = -1
+	}
+	return " LIMIT " + strconv.Itoa(limit) + " OFFSET " + strconv.Itoa(offset)
+}
+
+// poolWhere translates opts into conditions. CIDR containment is answered by
+// the interval tree and passed in as a JSON array of ids.
+func (s *Store) poolWhere(ctx context.Context, opts storage.PoolQueryOptions) (*whereClause, error) {
+	w := &whereClause{}
+	w.add(`deleted_at IS NULL`)
+	if opts.ID != nil {
+		w.add(`id = ?`, *opts.ID)
+	}
+	if opts.ParentID != nil {
+		if opts.IncludeChildren && *opts.ParentID != 0 {
+			w.add(`id IN (WITH RECURSIVE sub(id) AS (
+				SELECT id FROM pools WHERE parent_id = ?
+				UNION SELECT p.id FROM pools p JOIN sub ON p.parent_id = sub.id
+			) SELECT id FROM sub)`, *opts.ParentID)
+		} else {
+			w.refCond(`parent_id`, *opts.ParentID)
+		}
+	}
+	if opts.AccountID != nil {
+		w.refCond(`account_id`, *opts.AccountID)
+	}
+	if opts.CIDRPrefix != "" {
+		w.add(`substr(cidr, 1, length(?)) = ?`, opts.CIDRPrefix, opts.CIDRPrefix)
+	}
+	if opts.CIDRContains != "" || opts.CIDRWithin != "" {
+		idx, err := s.poolIndex(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if opts.CIDRContains != "" {
+			q, _ := storage.ParseCIDRArg(opts.CIDRContains)
+			w.add(`id IN (SELECT value FROM json_each(?))`, idsJSON(idx.Containing(q)))
+		}
+		if opts.CIDRWithin != "" {
+			q, _ := storage.ParseCIDRArg(opts.CIDRWithin)
+			w.add(`id IN (SELECT value FROM json_each(?))`, idsJSON(idx.ContainedBy(q)))
+		}
+	}
+	if opts.NameContains != "" {
+		w.add(`instr(lower(name), ?) > 0`, strings.ToLower(opts.NameContains))
+	}
+	w.createdRange(opts.CreatedAfter, opts.CreatedBefore)
+	return w, nil
+}
+
+// QueryPools returns the page of live pools matching opts.
+func (s *Store) QueryPools(ctx context.Context, opts storage.PoolQueryOptions) ([]*domain.Pool, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	w, err := s.poolWhere(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	q := `SELECT ` + poolSelectColumns + ` FROM pools WHERE ` + w.String()
+	if opts.OrderBy == "cidr" {
+		// CIDR text does not sort by address, so order and page in Go.
+		pools, err := s.queryPoolRows(ctx, q, w.args...)
+		if err != nil {
+			return nil, err
+		}
+		out, _, err := storage.FilterPools(pools, storage.PoolQueryOptions{
+			OrderBy: "cidr", OrderDesc: opts.OrderDesc, Limit: opts.Limit, Offset: opts.Offset,
+		})
+		return out, err
+	}
+	q += orderClause(opts.OrderBy, opts.OrderDesc) + limitClause(opts.Limit, opts.Offset)
+	pools, err := s.queryPoolRows(ctx, q, w.args...)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*domain.Pool, len(pools))
+	for i := range pools {
+		out[i] = &pools[i]
+	}
+	return out, nil
+}
+
+// CountPools returns how many live pools match opts, ignoring Limit and Offset.
+func (s *Store) CountPools(ctx context.Context, opts storage.PoolQueryOptions) (int64, error) {
+	if err := opts.Validate(); err != nil {
+		return 0, err
+	}
+	w, err := s.poolWhere(ctx, opts)
+	if err != nil {
+		return 0, err
+	}
+	var n int64
+	err = s.q().QueryRowContext(ctx, `SELECT COUNT(1) FROM pools WHERE `+w.String(), w.args...).Scan(&n)
+	return n, err
+}
+
+func (s *Store) queryPoolRows(ctx context.Context, q string, args ...any) ([]domain.Pool, error) {
+	rows, err := s.q().QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []domain.Pool
+	for rows.Next() {
+		p, err := scanPool(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+func accountWhere(opts storage.AccountQueryOptions) *whereClause {
+	w := &whereClause{}
+	w.add(`deleted_at IS NULL`)
+	if opts.ID != nil {
+		w.add(`id = ?`, *opts.ID)
+	}
+	if opts.Key != "" {
+		w.add(`key = ?`, opts.Key)
+	}
+	if opts.KeyPrefix != "" {
+		w.add(`substr(key, 1, length(?)) = ?`, opts.KeyPrefix, opts.KeyPrefix)
+	}
+	for _, f := range []struct{ column, value string }{
+		{"provider", opts.Provider},
+		{"platform", opts.Platform},
+		{"tier", opts.Tier},
+		{"environment", opts.Environment},
+	} {
+		if f.value != "" {
+			w.add(f.column+` = ?`, f.value)
+		}
+	}
+	if opts.Region != "" {
+		w.add(`EXISTS (SELECT 1 FROM json_each(CASE WHEN json_valid(regions) THEN regions ELSE '[]' END) WHERE value = ?)`, opts.Region)
+	}
+	if opts.NameContains != "" {
+		w.add(`instr(lower(name), ?) > 0`, strings.ToLower(opts.NameContains))
+	}
+	w.createdRange(opts.CreatedAfter, opts.CreatedBefore)
+	return w
+}
+
+// QueryAccounts returns the page of live accounts matching opts.
+func (s *Store) QueryAccounts(ctx context.Context, opts storage.AccountQueryOptions) ([]*domain.Account, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	w := accountWhere(opts)
+	q := `SELECT ` + accountSelectColumns + ` FROM accounts WHERE ` + w.String() +
+		orderClause(opts.OrderBy, opts.OrderDesc) + limitClause(opts.Limit, opts.Offset)
+	rows, err := s.q().QueryContext(ctx, q, w.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []*domain.Account{}
+	for rows.Next() {
+		a, err := scanAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, &a)
+	}
+	return out, rows.Err()
+}
+
+// CountAccounts returns how many live accounts match opts, ignoring Limit
+// and Offset.
+func (s *Store) CountAccounts(ctx context.Context, opts storage.AccountQueryOptions) (int64, error) {
+	if err := opts.Validate(); err != nil {
+		return 0, err
+	}
+	w := accountWhere(opts)
+	var n int64
+	err := s.q().QueryRowContext(ctx, `SELECT COUNT(1) FROM accounts WHERE `+w.String(), w.args...).Scan(&n)
+	return n, err
+}
+
+// orderClause renders ORDER BY for a field already checked by Validate,
+// breaking ties by id.
+func orderClause(field string, desc bool) string {
+	dir := " ASC"
+	if desc {
+		dir = " DESC"
+	}
+	if field == "" || field == "id" {
+		return " ORDER BY id" + dir
+	}
+	return " ORDER BY " + field + dir + ", id" + dir
+}
+
+// idsJSON encodes index hits as a JSON array for json_each, which keeps the
+// statement to one parameter however many pools match.
+func idsJSON(hits []cidr.IndexEntry) string {
+	ids := make([]int64, len(hits))
+	for i, h := range hits {
+		ids[i] = h.ID
+	}
+	b, _ := json.Marshal(ids)
+	return string(b)
+}
@@ -0,0 +1,103 @@
+//go:build sqlite
+
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+func TestQueryable(t *testing.T) {
+	m, err := New("file:" + filepath.Join(t.TempDir(), "query.db"))
+	if err != nil {
+		t.Fatalf("new sqlite store: %v", err)
+	}
+	t.Cleanup(func() { _ = m.Close() })
+	ctx := context.Background()
+	aws, _ := m.CreateAccount(ctx, domain.CreateAccount{Key: "aws:111", Name: "Prod", Provider: "aws", Environment: "prod", Regions: []string{"us-east-1"}})
+	_, _ = m.CreateAccount(ctx, domain.CreateAccount{Key: "aws:222", Name: "Dev", Provider: "aws", Environment: "dev"})
+	_, _ = m.CreateAccount(ctx, domain.CreateAccount{Key: "gcp:333", Name: "Prod GCP", Provider: "gcp", Environment: "prod"})
+
+	root, _ := m.CreatePool(ctx, domain.CreatePool{Name: "Root", CIDR: "10.0.0.0/8"})
+	mid, _ := m.CreatePool(ctx, domain.CreatePool{Name: "mid", CIDR: "10.1.0.0/16", ParentID: &root.ID, AccountID: &aws.ID})
+	_, _ = m.CreatePool(ctx, domain.CreatePool{Name: "leaf", CIDR: "10.1.2.0/24", ParentID: &mid.ID})
+	gone, _ := m.CreatePool(ctx, domain.CreatePool{Name: "gone", CIDR: "10.9.0.0/16", ParentID: &root.ID})
+	_, _ = m.CreatePool(ctx, domain.CreatePool{Name: "other-root", CIDR: "172.16.0.0/12"})
+	if _, err := m.DeletePool(ctx, gone.ID); err != nil {
+		t.Fatalf("DeletePool: %v", err)
+	}
+
+	names := func(ps []*domain.Pool) string {
+		var out []string
+		for _, p := range ps {
+			out = append(out, p.Name)
+		}
+		return fmt.Sprint(out)
+	}
+	zero := int64(0)
+	hourAgo, hourAhead := time.Now().Add(-time.Hour).Unix(), time.Now().Add(time.Hour).Unix()
+
+	cases := []struct {
+		name string
+		opts storage.PoolQueryOptions
+		want string
+	}{
+		{"all live", storage.PoolQueryOptions{}, "[Root mid leaf other-root]"},
+		{"roots", storage.PoolQueryOptions{ParentID: &zero}, "[Root other-root]"},
+		{"children", storage.PoolQueryOptions{ParentID: &root.ID}, "[mid]"},
+		{"descendants", storage.PoolQueryOptions{ParentID: &root.ID, IncludeChildren: true}, "[mid leaf]"},
+		{"account", storage.PoolQueryOptions{AccountID: &aws.ID}, "[mid]"},
+		{"unassigned", storage.PoolQueryOptions{AccountID: &zero}, "[Root leaf other-root]"},
+		{"cidr prefix", storage.PoolQueryOptions{CIDRPrefix: "10.1."}, "[mid leaf]"},
+		{"cidr contains", storage.PoolQueryOptions{CIDRContains: "10.1.2.7"}, "[Root mid leaf]"},
+		{"cidr within", storage.PoolQueryOptions{CIDRWithin: "10.1.0.0/16"}, "[mid leaf]"},
+		{"name", storage.PoolQueryOptions{NameContains: "ROOT"}, "[Root other-root]"},
+		{"order by cidr desc", storage.PoolQueryOptions{OrderBy: "cidr", OrderDesc: true}, "[other-root leaf mid Root]"},
+		{"page", storage.PoolQueryOptions{Limit: 2, Offset: 1}, "[mid leaf]"},
+		{"offset only", storage.PoolQueryOptions{Offset: 3}, "[other-root]"},
+		{"created window", storage.PoolQueryOptions{CreatedAfter: &hourAgo, CreatedBefore: &hourAhead, ParentID: &zero}, "[Root other-root]"},
+	}
+	for _, tc := range cases {
+		got, err := m.QueryPools(ctx, tc.opts)
+		if err != nil {
+			t.Fatalf("%s: QueryPools: %v", tc.name, err)
+		}
+		if names(got) != tc.want {
+			t.Errorf("%s: QueryPools = %s, want %s", tc.name, names(got), tc.want)
+		}
+	}
+
+	if n, err := m.CountPools(ctx, storage.PoolQueryOptions{CIDRWithin: "10.0.0.0/8", Limit: 1}); err != nil || n != 3 {
+		t.Errorf("CountPools = %d, %v; want 3", n, err)
+	}
+	if _, err := m.QueryPools(ctx, storage.PoolQueryOptions{OrderBy: "bogus"}); !errors.Is(err, storage.ErrValidation) {
+		t.Errorf("bad order_by: expected storage.ErrValidation, got %v", err)
+	}
+	if _, err := m.CountPools(ctx, storage.PoolQueryOptions{CIDRContains: "nope"}); !errors.Is(err, storage.ErrValidation) {
+		t.Errorf("bad cidr: expected storage.ErrValidation, got %v", err)
+	}
+
+	accts, err := m.QueryAccounts(ctx, storage.AccountQueryOptions{Environment: "prod", OrderBy: "key", OrderDesc: true})
+	if err != nil {
+		t.Fatalf("QueryAccounts: %v", err)
+	}
+	if len(accts) != 2 || accts[0].Key != "gcp:333" || accts[1].Key != "aws:111" {
+		t.Errorf("QueryAccounts(prod) = %+v", accts)
+	}
+	if got, _ := m.QueryAccounts(ctx, storage.AccountQueryOptions{Region: "us-east-1"}); len(got) != 1 || got[0].ID != aws.ID {
+		t.Errorf("QueryAccounts(region) = %+v", got)
+	}
+	if n, _ := m.CountAccounts(ctx, storage.AccountQueryOptions{KeyPrefix: "aws:"}); n != 2 {
+		t.Errorf("CountAccounts(aws:) = %d, want 2", n)
+	}
+	if _, err := m.QueryAccounts(ctx, storage.AccountQueryOptions{OrderBy: "cidr"}); !errors.Is(err, storage.ErrValidation) {
+		t.Errorf("bad order_by: expected storage.ErrValidation, got %v", err)
+	}
+}
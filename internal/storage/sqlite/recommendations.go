@@ -23,7 +23,7 @@ func (s *Store) CreateRecommendation(ctx context.Context, rec domain.Recommendat
 		}
 	}
 
-	_, err := s.db.ExecContext(ctx,
+	_, err := s.q().ExecContext(ctx,
 		`INSERT INTO recommendations (id, pool_id, type, status, priority, title, description, suggested_cidr, rule_id, score, metadata, dismiss_reason, applied_pool_id, created_at, updated_at)
 		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		rec.ID, rec.PoolID, string(rec.Type), string(rec.Status), string(rec.Priority),
@@ -36,7 +36,7 @@ func (s *Store) CreateRecommendation(ctx context.Context, rec domain.Recommendat
 
 // GetRecommendation returns a single recommendation by ID.
 func (s *Store) GetRecommendation(ctx context.Context, id string) (*domain.Recommendation, error) {
-	row := s.db.QueryRowContext(ctx,
+	row := s.q().QueryRowContext(ctx,
 		`SELECT id, pool_id, type, status, priority, title, description, suggested_cidr, rule_id, score, metadata, dismiss_reason, applied_pool_id, created_at, updated_at
 		 FROM recommendations WHERE id = ?`, id,
 	)
@@ -72,7 +72,7 @@ func (s *Store) ListRecommendations(ctx context.Context, filters domain.Recommen
 
 	// Count
 	var total int
-	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM recommendations"+whereClause, args...).Scan(&total); err != nil {
+	if err := s.q().QueryRowContext(ctx, "SELECT COUNT(*) FROM recommendations"+whereClause, args...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
 
@@ -93,7 +93,7 @@ func (s *Store) ListRecommendations(ctx context.Context, filters domain.Recommen
 	)
 	args = append(args, pageSize, offset)
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.q().QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -116,7 +116,7 @@ func (s *Store) ListRecommendations(ctx context.Context, filters domain.Recommen
 // UpdateRecommendationStatus updates a recommendation's status and optional fields.
 func (s *Store) UpdateRecommendationStatus(ctx context.Context, id string, status domain.RecommendationStatus, dismissReason string, appliedPoolID *int64) error {
 	now := time.Now().UTC().Format(time.RFC3339)
-	res, err := s.db.ExecContext(ctx,
+	res, err := s.q().ExecContext(ctx,
 		`UPDATE recommendations SET status = ?, dismiss_reason = ?, applied_pool_id = ?, updated_at = ? WHERE id = ?`,
 		string(status), nilIfEmpty(dismissReason), appliedPoolID, now, id,
 	)
@@ -132,7 +132,7 @@ func (s *Store) UpdateRecommendationStatus(ctx context.Context, id string, statu
 
 // DeletePendingForPool removes all pending recommendations for a pool.
 func (s *Store) DeletePendingForPool(ctx context.Context, poolID int64) error {
-	_, err := s.db.ExecContext(ctx,
+	_, err := s.q().ExecContext(ctx,
 		`DELETE FROM recommendations WHERE pool_id = ? AND status = ?`,
 		poolID, string(domain.RecommendationStatusPending),
 	)
@@ -0,0 +1,199 @@
+//go:build sqlite
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+var _ storage.ReservationStore = (*Store)(nil)
+
+const reservationColumns = `id, cidr, parent_id, owner, reason, status, expires_at, created_by, created_at,
+	released_by, released_at`
+
+// CreateReservation stores a new reservation. The overlap check and the
+// insert share a transaction, so two overlapping reservations cannot both
+// land.
+func (s *Store) CreateReservation(ctx context.Context, r domain.Reservation) error {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	held, err := s.listReservations(ctx, tx, domain.ReservationActive)
+	if err != nil {
+		return err
+	}
+	if h := storage.OverlappingReservation(held, r.CIDR, r.ParentID, r.CreatedAt); h != nil {
+		return fmt.Errorf("%s is reserved by %s (%s): %w", r.CIDR, h.ID, h.CIDR, storage.ErrConflict)
+	}
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO reservations (`+reservationColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		r.ID, r.CIDR, r.ParentID, r.Owner, r.Reason, string(r.Status), r.ExpiresAt.UTC().Format(time.RFC3339),
+		r.CreatedBy, r.CreatedAt.UTC().Format(time.RFC3339), nilIfEmpty(r.ReleasedBy), formatTimePtr(r.ReleasedAt),
+	)
+	if err != nil {
+		return storage.WrapIfConflict(err)
+	}
+	return tx.Commit()
+}
+
+// GetReservation returns a reservation by ID.
+func (s *Store) GetReservation(ctx context.Context, id string) (*domain.Reservation, error) {
+	row := s.q().QueryRowContext(ctx, `SELECT `+reservationColumns+` FROM reservations WHERE id = ?`, id)
+	r, err := scanReservation(row)
+	if err == sql.ErrNoRows {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// ListReservations returns reservations, newest first.
+func (s *Store) ListReservations(ctx context.Context, status domain.ReservationStatus) ([]domain.Reservation, error) {
+	return s.listReservations(ctx, s.q(), status)
+}
+
+func (s *Store) listReservations(ctx context.Context, q dbtx, status domain.ReservationStatus) ([]domain.Reservation, error) {
+	query := `SELECT ` + reservationColumns + ` FROM reservations`
+	var args []any
+	if status != "" {
+		query += ` WHERE status = ?`
+		args = append(args, string(status))
+	}
+	rows, err := q.QueryContext(ctx, query+` ORDER BY created_at DESC, id DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []domain.Reservation{}
+	for rows.Next() {
+		r, err := scanReservation(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// UpdateReservation saves the owner, reason and expiry of an active
+// reservation.
+func (s *Store) UpdateReservation(ctx context.Context, r domain.Reservation) error {
+	res, err := s.q().ExecContext(ctx,
+		`UPDATE reservations SET owner = ?, reason = ?, expires_at = ? WHERE id = ? AND status = ?`,
+		r.Owner, r.Reason, r.ExpiresAt.UTC().Format(time.RFC3339), r.ID, string(domain.ReservationActive),
+	)
+	if err != nil {
+		return err
+	}
+	return s.reservationChanged(ctx, res, r.ID)
+}
+
+// ReleaseReservation ends an active reservation.
+func (s *Store) ReleaseReservation(ctx context.Context, r domain.Reservation) error {
+	res, err := s.q().ExecContext(ctx,
+		`UPDATE reservations SET status = ?, released_by = ?, released_at = ? WHERE id = ? AND status = ?`,
+		string(r.Status), nilIfEmpty(r.ReleasedBy), formatTimePtr(r.ReleasedAt), r.ID, string(domain.ReservationActive),
+	)
+	if err != nil {
+		return err
+	}
+	return s.reservationChanged(ctx, res, r.ID)
+}
+
+// reservationChanged turns an update of an active reservation that matched
+// no row into ErrNotFound or ErrConflict.
+func (s *Store) reservationChanged(ctx context.Context, res sql.Result, id string) error {
+	if n, _ := res.RowsAffected(); n > 0 {
+		return nil
+	}
+	var status string
+	err := s.q().QueryRowContext(ctx, `SELECT status FROM reservations WHERE id = ?`, id).Scan(&status)
+	if err == sql.ErrNoRows {
+		return storage.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("reservation %s is %s: %w", id, status, storage.ErrConflict)
+}
+
+// ExpireReservations marks active reservations past their expiry as
+// expired and returns them.
+func (s *Store) ExpireReservations(ctx context.Context, now time.Time) ([]domain.Reservation, error) {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	cutoff := now.UTC().Format(time.RFC3339)
+	rows, err := tx.QueryContext(ctx,
+		`SELECT `+reservationColumns+` FROM reservations WHERE status = ? AND expires_at <= ? ORDER BY created_at DESC, id DESC`,
+		string(domain.ReservationActive), cutoff,
+	)
+	if err != nil {
+		return nil, err
+	}
+	var out []domain.Reservation
+	for rows.Next() {
+		r, err := scanReservation(rows)
+		if err != nil {
+			_ = rows.Close()
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE reservations SET status = ?, released_by = ?, released_at = ? WHERE status = ? AND expires_at <= ?`,
+		string(domain.ReservationExpired), storage.ReservationSystemActor, cutoff, string(domain.ReservationActive), cutoff,
+	); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	released := now.UTC().Truncate(time.Second)
+	for i := range out {
+		out[i].Status = domain.ReservationExpired
+		out[i].ReleasedBy = storage.ReservationSystemActor
+		out[i].ReleasedAt = &released
+	}
+	return out, nil
+}
+
+func scanReservation(row interface{ Scan(dest ...any) error }) (domain.Reservation, error) {
+	var r domain.Reservation
+	var status, expiresAt, createdAt string
+	var parentID sql.NullInt64
+	var releasedBy, releasedAt sql.NullString
+	if err := row.Scan(&r.ID, &r.CIDR, &parentID, &r.Owner, &r.Reason, &status, &expiresAt, &r.CreatedBy, &createdAt,
+		&releasedBy, &releasedAt); err != nil {
+		return r, err
+	}
+	if parentID.Valid {
+		r.ParentID = &parentID.Int64
+	}
+	r.Status = domain.ReservationStatus(status)
+	r.ExpiresAt, _ = time.Parse(time.RFC3339, expiresAt)
+	r.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	r.ReleasedBy = releasedBy.String
+	r.ReleasedAt = parseTimePtr(releasedAt)
+	return r, nil
+}
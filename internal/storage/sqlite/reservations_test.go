@@ -0,0 +1,91 @@
+//go:build sqlite
+
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+func TestReservationStore(t *testing.T) {
+	s, err := New("file:" + filepath.Join(t.TempDir(), "reservations.db"))
+	if err != nil {
+		t.Fatalf("new sqlite store: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	parent := int64(3)
+
+	r := domain.Reservation{
+		ID: "r1", CIDR: "10.1.0.0/16", ParentID: &parent, Owner: "alice", Reason: "prod rollout",
+		Status: domain.ReservationActive, ExpiresAt: now.Add(time.Hour), CreatedBy: "alice", CreatedAt: now,
+	}
+	if err := s.CreateReservation(ctx, r); err != nil {
+		t.Fatalf("CreateReservation: %v", err)
+	}
+	held := r
+	held.ID, held.CIDR = "r2", "10.1.4.0/24"
+	if err := s.CreateReservation(ctx, held); !errors.Is(err, storage.ErrConflict) {
+		t.Fatalf("overlapping reservation: expected ErrConflict, got %v", err)
+	}
+	// Past its expiry the reservation no longer holds the block.
+	held.CreatedAt = now.Add(2 * time.Hour)
+	held.ExpiresAt = now.Add(3 * time.Hour)
+	if err := s.CreateReservation(ctx, held); err != nil {
+		t.Fatalf("reserve after expiry: %v", err)
+	}
+
+	got, err := s.GetReservation(ctx, "r1")
+	if err != nil {
+		t.Fatalf("GetReservation: %v", err)
+	}
+	if got.CIDR != r.CIDR || got.ParentID == nil || *got.ParentID != 3 || got.Owner != "alice" ||
+		!got.ExpiresAt.Equal(r.ExpiresAt) || !got.CreatedAt.Equal(now) || got.ReleasedAt != nil {
+		t.Fatalf("round trip = %+v", got)
+	}
+	if _, err := s.GetReservation(ctx, "missing"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("missing: expected ErrNotFound, got %v", err)
+	}
+
+	got.Reason = "extended"
+	got.ExpiresAt = now.Add(90 * time.Minute)
+	if err := s.UpdateReservation(ctx, *got); err != nil {
+		t.Fatalf("UpdateReservation: %v", err)
+	}
+
+	expired, err := s.ExpireReservations(ctx, now.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("ExpireReservations: %v", err)
+	}
+	if len(expired) != 1 || expired[0].ID != "r1" || expired[0].Reason != "extended" ||
+		expired[0].Status != domain.ReservationExpired || expired[0].ReleasedBy != storage.ReservationSystemActor {
+		t.Fatalf("expired = %+v", expired)
+	}
+	if err := s.UpdateReservation(ctx, *got); !errors.Is(err, storage.ErrConflict) {
+		t.Fatalf("update expired: expected ErrConflict, got %v", err)
+	}
+
+	releasedAt := now.Add(2 * time.Hour)
+	held.Status, held.ReleasedBy, held.ReleasedAt = domain.ReservationReleased, "bob", &releasedAt
+	if err := s.ReleaseReservation(ctx, held); err != nil {
+		t.Fatalf("ReleaseReservation: %v", err)
+	}
+	if err := s.ReleaseReservation(ctx, domain.Reservation{ID: "missing"}); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("release missing: expected ErrNotFound, got %v", err)
+	}
+
+	list, err := s.ListReservations(ctx, "")
+	if err != nil || len(list) != 2 || list[0].ID != "r2" || list[0].ReleasedBy != "bob" {
+		t.Fatalf("ListReservations = %+v, %v", list, err)
+	}
+	if active, _ := s.ListReservations(ctx, domain.ReservationActive); len(active) != 0 {
+		t.Fatalf("active = %+v", active)
+	}
+}
@@ -0,0 +1,151 @@
+//go:build sqlite
+
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"time"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+var _ storage.PoolRestructurer = (*Store)(nil)
+
+// ResizePool checks and moves a pool to a new CIDR in a single transaction.
+func (s *Store) ResizePool(ctx context.Context, id int64, cidrStr string, held []netip.Prefix) (domain.Pool, error) {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Write first to take SQLite's reserved lock, as AllocatePool does, so
+	// the checks below see the hierarchy the update lands in.
+	res, err := tx.ExecContext(ctx, `UPDATE pools SET updated_at = updated_at WHERE id = ? AND deleted_at IS NULL`, id)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.Pool{}, fmt.Errorf("pool not found: %w", storage.ErrNotFound)
+	}
+	p, _, err := getPool(ctx, tx, id)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+	parent, siblings, err := poolFamily(ctx, tx, p.ParentID)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+	children, err := poolChildren(ctx, tx, id)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+	addresses, err := poolAddressList(ctx, tx, id)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+	next, err := storage.CheckResize(p, cidrStr, parent, siblings, children, addresses, held)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	if _, err := tx.ExecContext(ctx, `UPDATE pools SET cidr=?, updated_at=?, version=version+1 WHERE id=?`, next.String(), now, id); err != nil {
+		return domain.Pool{}, storage.WrapIfConflict(err)
+	}
+	p, _, err = getPool(ctx, tx, id)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.Pool{}, err
+	}
+	return p, nil
+}
+
+// AggregatePools inserts the aggregate and re-parents its members in a
+// single transaction.
+func (s *Store) AggregatePools(ctx context.Context, ids []int64, in domain.CreatePool) (domain.Pool, error) {
+	tx, err := s.begin(ctx)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	members := make([]domain.Pool, 0, len(ids))
+	for _, id := range ids {
+		res, err := tx.ExecContext(ctx, `UPDATE pools SET updated_at = updated_at WHERE id = ? AND deleted_at IS NULL`, id)
+		if err != nil {
+			return domain.Pool{}, err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return domain.Pool{}, fmt.Errorf("pool %d not found: %w", id, storage.ErrNotFound)
+		}
+		p, _, err := getPool(ctx, tx, id)
+		if err != nil {
+			return domain.Pool{}, err
+		}
+		members = append(members, p)
+	}
+	var parentID *int64
+	if len(members) > 0 {
+		parentID = members[0].ParentID
+	}
+	parent, siblings, err := poolFamily(ctx, tx, parentID)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+	if _, err := storage.CheckAggregate(members, in.CIDR, parent, siblings); err != nil {
+		return domain.Pool{}, err
+	}
+
+	in.ParentID = parentID
+	agg, err := createPool(ctx, tx, in)
+	if err != nil {
+		return domain.Pool{}, err
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, p := range members {
+		if _, err := tx.ExecContext(ctx, `UPDATE pools SET parent_id=?, updated_at=?, version=version+1 WHERE id=?`, agg.ID, now, p.ID); err != nil {
+			return domain.Pool{}, storage.WrapIfConflict(err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return domain.Pool{}, err
+	}
+	return agg, nil
+}
+
+// poolFamily returns the live pool parentID and the live pools under it; at
+// the top level (nil parentID) the parent is nil and the siblings are the
+// top-level pools.
+func poolFamily(ctx context.Context, q dbtx, parentID *int64) (*domain.Pool, []domain.Pool, error) {
+	if parentID != nil {
+		parent, ok, err := getPool(ctx, q, *parentID)
+		if err != nil {
+			return nil, nil, err
+		}
+		siblings, err := poolChildren(ctx, q, *parentID)
+		if err != nil || !ok {
+			return nil, siblings, err
+		}
+		return &parent, siblings, nil
+	}
+	rows, err := q.QueryContext(ctx, `SELECT `+poolSelectColumns+` FROM pools WHERE parent_id IS NULL AND deleted_at IS NULL ORDER BY id ASC`)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	var siblings []domain.Pool
+	for rows.Next() {
+		p, err := scanPool(rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		siblings = append(siblings, p)
+	}
+	return nil, siblings, rows.Err()
+}
@@ -0,0 +1,101 @@
+//go:build sqlite
+
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+func TestResizePool(t *testing.T) {
+	s, err := New("file:" + filepath.Join(t.TempDir(), "resize.db"))
+	if err != nil {
+		t.Fatalf("new sqlite store: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	root, _ := s.CreatePool(ctx, domain.CreatePool{Name: "root", CIDR: "10.0.0.0/16"})
+	a, _ := s.CreatePool(ctx, domain.CreatePool{Name: "a", CIDR: "10.0.0.0/22", ParentID: &root.ID})
+	_, _ = s.CreatePool(ctx, domain.CreatePool{Name: "b", CIDR: "10.0.4.0/22", ParentID: &root.ID})
+	_, _ = s.CreatePool(ctx, domain.CreatePool{Name: "a-1", CIDR: "10.0.1.0/24", ParentID: &a.ID})
+	if err := s.CreateIPAddress(ctx, domain.IPAddress{
+		ID: "ip-1", PoolID: a.ID, Address: "10.0.2.10", Status: domain.IPAddressStatusAssigned,
+		Source: domain.PoolSourceManual, CreatedAt: now, UpdatedAt: now,
+	}); err != nil {
+		t.Fatalf("CreateIPAddress: %v", err)
+	}
+
+	// Prime the CIDR index so the move has to invalidate it.
+	if hits, err := s.FindContaining(ctx, "10.0.3.1"); err != nil || len(hits) != 2 {
+		t.Fatalf("FindContaining before = %d pools, %v", len(hits), err)
+	}
+	for _, next := range []string{"10.0.0.0/21", "10.0.2.0/23", "10.0.1.0/24", "10.1.0.0/22"} {
+		if _, err := s.ResizePool(ctx, a.ID, next, nil); !errors.Is(err, storage.ErrConflict) {
+			t.Errorf("ResizePool(%s) = %v, want ErrConflict", next, err)
+		}
+	}
+	if err := s.DeleteIPAddress(ctx, "ip-1"); err != nil {
+		t.Fatalf("DeleteIPAddress: %v", err)
+	}
+	got, err := s.ResizePool(ctx, a.ID, "10.0.0.0/23", nil)
+	if err != nil {
+		t.Fatalf("ResizePool: %v", err)
+	}
+	if got.CIDR != "10.0.0.0/23" || got.Version != a.Version+1 {
+		t.Errorf("resized pool = %+v", got)
+	}
+	if hits, err := s.FindContaining(ctx, "10.0.3.1"); err != nil || len(hits) != 1 {
+		t.Errorf("FindContaining after = %d pools, %v", len(hits), err)
+	}
+	if _, err := s.ResizePool(ctx, 999, "10.0.0.0/24", nil); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("ResizePool missing = %v", err)
+	}
+	c, _ := s.CreatePool(ctx, domain.CreatePool{Name: "c", CIDR: "10.0.12.0/23", ParentID: &root.ID})
+	held := []netip.Prefix{netip.MustParsePrefix("10.0.14.0/24")}
+	if _, err := s.ResizePool(ctx, c.ID, "10.0.12.0/22", held); !errors.Is(err, storage.ErrConflict) {
+		t.Errorf("ResizePool over held block = %v, want ErrConflict", err)
+	}
+}
+
+func TestAggregatePools(t *testing.T) {
+	s, err := New("file:" + filepath.Join(t.TempDir(), "aggregate.db"))
+	if err != nil {
+		t.Fatalf("new sqlite store: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	ctx := context.Background()
+
+	root, _ := s.CreatePool(ctx, domain.CreatePool{Name: "root", CIDR: "10.0.0.0/16"})
+	a, _ := s.CreatePool(ctx, domain.CreatePool{Name: "a", CIDR: "10.0.0.0/24", ParentID: &root.ID})
+	b, _ := s.CreatePool(ctx, domain.CreatePool{Name: "b", CIDR: "10.0.1.0/24", ParentID: &root.ID})
+	c, _ := s.CreatePool(ctx, domain.CreatePool{Name: "c", CIDR: "10.0.2.0/24", ParentID: &root.ID})
+
+	in := domain.CreatePool{Name: "agg", CIDR: "10.0.0.0/22", Type: domain.PoolTypeSupernet}
+	if _, err := s.AggregatePools(ctx, []int64{a.ID, c.ID}, in); !errors.Is(err, storage.ErrConflict) {
+		t.Errorf("AggregatePools with a hole = %v, want ErrConflict", err)
+	}
+	in.CIDR = "10.0.0.0/23"
+	agg, err := s.AggregatePools(ctx, []int64{a.ID, b.ID}, in)
+	if err != nil {
+		t.Fatalf("AggregatePools: %v", err)
+	}
+	if agg.ParentID == nil || *agg.ParentID != root.ID {
+		t.Errorf("aggregate parent = %v, want %d", agg.ParentID, root.ID)
+	}
+	children, err := s.GetPoolChildren(ctx, agg.ID)
+	if err != nil || len(children) != 2 {
+		t.Fatalf("aggregate children = %d, %v", len(children), err)
+	}
+	if _, err := s.AggregatePools(ctx, []int64{a.ID, b.ID}, in); !errors.Is(err, storage.ErrConflict) {
+		t.Errorf("second AggregatePools = %v, want ErrConflict", err)
+	}
+}
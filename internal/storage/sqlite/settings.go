@@ -13,7 +13,7 @@ import (
 // GetSecuritySettings retrieves security settings from the database.
 func (s *Store) GetSecuritySettings(ctx context.Context) (*domain.SecuritySettings, error) {
 	var raw string
-	err := s.db.QueryRowContext(ctx, `SELECT value FROM settings WHERE key = 'security'`).Scan(&raw)
+	err := s.q().QueryRowContext(ctx, `SELECT value FROM settings WHERE key = 'security'`).Scan(&raw)
 	if err == sql.ErrNoRows {
 		defaults := domain.DefaultSecuritySettings()
 		return &defaults, nil
@@ -35,7 +35,7 @@ func (s *Store) UpdateSecuritySettings(ctx context.Context, settings *domain.Sec
 	if err != nil {
 		return err
 	}
-	_, err = s.db.ExecContext(ctx,
+	_, err = s.q().ExecContext(ctx,
 		`INSERT INTO settings (key, value, updated_at) VALUES ('security', ?, datetime('now'))
 		 ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at`,
 		string(raw))
@@ -45,7 +45,7 @@ func (s *Store) UpdateSecuritySettings(ctx context.Context, settings *domain.Sec
 // GetNetworkSchemaPolicy retrieves the persisted merged-network schema policy.
 func (s *Store) GetNetworkSchemaPolicy(ctx context.Context) (*domain.NetworkSchemaPolicy, error) {
 	var raw string
-	err := s.db.QueryRowContext(ctx, `SELECT value FROM settings WHERE key = 'network_schema_policy'`).Scan(&raw)
+	err := s.q().QueryRowContext(ctx, `SELECT value FROM settings WHERE key = 'network_schema_policy'`).Scan(&raw)
 	if err == sql.ErrNoRows {
 		defaults := domain.DefaultNetworkSchemaPolicy()
 		return &defaults, nil
@@ -67,9 +67,104 @@ func (s *Store) UpdateNetworkSchemaPolicy(ctx context.Context, policy *domain.Ne
 	if err != nil {
 		return err
 	}
-	_, err = s.db.ExecContext(ctx,
+	_, err = s.q().ExecContext(ctx,
 		`INSERT INTO settings (key, value, updated_at) VALUES ('network_schema_policy', ?, datetime('now'))
 		 ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at`,
 		string(raw))
 	return err
 }
+
+// GetAlertSettings retrieves the capacity alert rules and notification channels.
+func (s *Store) GetAlertSettings(ctx context.Context) (*domain.AlertSettings, error) {
+	var raw string
+	err := s.q().QueryRowContext(ctx, `SELECT value FROM settings WHERE key = 'alerting'`).Scan(&raw)
+	if err == sql.ErrNoRows {
+		defaults := domain.DefaultAlertSettings()
+		return &defaults, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var settings domain.AlertSettings
+	if err := json.Unmarshal([]byte(raw), &settings); err != nil {
+		return nil, err
+	}
+	return domain.NormalizeAlertSettings(&settings), nil
+}
+
+// UpdateAlertSettings saves the capacity alert rules and notification channels.
+func (s *Store) UpdateAlertSettings(ctx context.Context, settings *domain.AlertSettings) error {
+	settings = domain.NormalizeAlertSettings(settings)
+	raw, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	_, err = s.q().ExecContext(ctx,
+		`INSERT INTO settings (key, value, updated_at) VALUES ('alerting', ?, datetime('now'))
+		 ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at`,
+		string(raw))
+	return err
+}
+
+// GetAuditRetentionSettings retrieves the audit log retention policy.
+func (s *Store) GetAuditRetentionSettings(ctx context.Context) (*domain.AuditRetentionSettings, error) {
+	var raw string
+	err := s.q().QueryRowContext(ctx, `SELECT value FROM settings WHERE key = 'audit_retention'`).Scan(&raw)
+	if err == sql.ErrNoRows {
+		defaults := domain.DefaultAuditRetentionSettings()
+		return &defaults, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var settings domain.AuditRetentionSettings
+	if err := json.Unmarshal([]byte(raw), &settings); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// UpdateAuditRetentionSettings saves the audit log retention policy.
+func (s *Store) UpdateAuditRetentionSettings(ctx context.Context, settings *domain.AuditRetentionSettings) error {
+	raw, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	_, err = s.q().ExecContext(ctx,
+		`INSERT INTO settings (key, value, updated_at) VALUES ('audit_retention', ?, datetime('now'))
+		 ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at`,
+		string(raw))
+	return err
+}
+
+// GetApprovalSettings retrieves the approval policies for pool mutations.
+func (s *Store) GetApprovalSettings(ctx context.Context) (*domain.ApprovalSettings, error) {
+	var raw string
+	err := s.q().QueryRowContext(ctx, `SELECT value FROM settings WHERE key = 'approvals'`).Scan(&raw)
+	if err == sql.ErrNoRows {
+		defaults := domain.DefaultApprovalSettings()
+		return &defaults, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var settings domain.ApprovalSettings
+	if err := json.Unmarshal([]byte(raw), &settings); err != nil {
+		return nil, err
+	}
+	return domain.NormalizeApprovalSettings(&settings), nil
+}
+
+// UpdateApprovalSettings saves the approval policies for pool mutations.
+func (s *Store) UpdateApprovalSettings(ctx context.Context, settings *domain.ApprovalSettings) error {
+	settings = domain.NormalizeApprovalSettings(settings)
+	raw, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	_, err = s.q().ExecContext(ctx,
+		`INSERT INTO settings (key, value, updated_at) VALUES ('approvals', ?, datetime('now'))
+		 ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at`,
+		string(raw))
+	return err
+}
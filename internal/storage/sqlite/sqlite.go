@@ -25,6 +25,20 @@ import (
 
 type Store struct {
 	db *sql.DB
+	// tx is set when the Store is scoped to a transaction (see BeginTx);
+	// every query then runs on it instead of db.
+	tx *sql.Tx
+	// cidrIdx caches the interval tree behind CIDROperations; it is nil on
+	// transaction-scoped stores (see cidr_ops.go).
+	cidrIdx *cidrIndexCache
+}
+
+// dbtx is satisfied by both *sql.DB and *sql.Tx, so pool helpers can run
+// either directly or inside a transaction.
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
 }
 
 // Connection pool defaults for SQLite.
@@ -64,7 +78,7 @@ func New(dsn string) (*Store, error) {
 	var _schemaVersion, _minSupported int
 	var _appVersion, _appliedAt string
 	_ = db.QueryRow(`SELECT schema_version, min_supported_schema, app_version, applied_at FROM schema_info WHERE id=1`).Scan(&_schemaVersion, &_minSupported, &_appVersion, &_appliedAt)
-	return &Store{db: db}, nil
+	return &Store{db: db, cidrIdx: &cidrIndexCache{}}, nil
 }
 
 // envIntOrDefault reads an integer from the named environment variable,
@@ -111,64 +125,83 @@ func (s *Store) Close() error {
 }
 
 func (s *Store) ListPools(ctx context.Context) ([]domain.Pool, error) {
-	rows, err := s.db.QueryContext(ctx, `SELECT id, name, cidr, parent_id, account_id, type, status, source, description, tags, created_at, updated_at FROM pools WHERE deleted_at IS NULL ORDER BY id ASC`)
+	rows, err := s.q().QueryContext(ctx, `SELECT `+poolSelectColumns+` FROM pools WHERE deleted_at IS NULL ORDER BY id ASC`)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 	var out []domain.Pool
 	for rows.Next() {
-		var p domain.Pool
-		var createdAt, updatedAt sql.NullString
-		var parent, account sql.NullInt64
-		var poolType, poolStatus, poolSource, description, tagsJSON sql.NullString
-		if err := rows.Scan(&p.ID, &p.Name, &p.CIDR, &parent, &account, &poolType, &poolStatus, &poolSource, &description, &tagsJSON, &createdAt, &updatedAt); err != nil {
+		p, err := scanPool(rows)
+		if err != nil {
 			return nil, err
 		}
-		if parent.Valid {
-			p.ParentID = &parent.Int64
-		}
-		if account.Valid {
-			p.AccountID = &account.Int64
-		}
-		// Set new fields with defaults
-		p.Type = domain.PoolTypeSubnet
-		if poolType.Valid && poolType.String != "" {
-			p.Type = domain.PoolType(poolType.String)
-		}
-		p.Status = domain.PoolStatusActive
-		if poolStatus.Valid && poolStatus.String != "" {
-			p.Status = domain.PoolStatus(poolStatus.String)
-		}
-		p.Source = domain.PoolSourceManual
-		if poolSource.Valid && poolSource.String != "" {
-			p.Source = domain.PoolSource(poolSource.String)
-		}
-		if description.Valid {
-			p.Description = description.String
-		}
-		if tagsJSON.Valid && tagsJSON.String != "" && tagsJSON.String != "{}" {
-			var tags map[string]string
-			if err := json.Unmarshal([]byte(tagsJSON.String), &tags); err == nil {
-				p.Tags = tags
-			}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// poolSelectColumns are the columns scanPool expects, in order.
+const poolSelectColumns = `id, name, cidr, parent_id, account_id, type, status, source, description, tags, version, created_at, updated_at`
+
+// scanPool scans one row of poolSelectColumns, applying the defaults for
+// columns added after the initial schema.
+func scanPool(row scanner) (domain.Pool, error) {
+	var p domain.Pool
+	var createdAt, updatedAt sql.NullString
+	var parent, account sql.NullInt64
+	var poolType, poolStatus, poolSource, description, tagsJSON sql.NullString
+	if err := row.Scan(&p.ID, &p.Name, &p.CIDR, &parent, &account, &poolType, &poolStatus, &poolSource, &description, &tagsJSON, &p.Version, &createdAt, &updatedAt); err != nil {
+		return domain.Pool{}, err
+	}
+	if parent.Valid {
+		p.ParentID = &parent.Int64
+	}
+	if account.Valid {
+		p.AccountID = &account.Int64
+	}
+	// Set new fields with defaults
+	p.Type = domain.PoolTypeSubnet
+	if poolType.Valid && poolType.String != "" {
+		p.Type = domain.PoolType(poolType.String)
+	}
+	p.Status = domain.PoolStatusActive
+	if poolStatus.Valid && poolStatus.String != "" {
+		p.Status = domain.PoolStatus(poolStatus.String)
+	}
+	p.Source = domain.PoolSourceManual
+	if poolSource.Valid && poolSource.String != "" {
+		p.Source = domain.PoolSource(poolSource.String)
+	}
+	if description.Valid {
+		p.Description = description.String
+	}
+	if tagsJSON.Valid && tagsJSON.String != "" && tagsJSON.String != "{}" {
+		var tags map[string]string
+		if err := json.Unmarshal([]byte(tagsJSON.String), &tags); err == nil {
+			p.Tags = tags
 		}
-		if createdAt.Valid {
-			if t, e := time.Parse(time.RFC3339, createdAt.String); e == nil {
-				p.CreatedAt = t
-			}
+	}
+	if createdAt.Valid {
+		if t, e := time.Parse(time.RFC3339, createdAt.String); e == nil {
+			p.CreatedAt = t
 		}
-		if updatedAt.Valid {
-			if t, e := time.Parse(time.RFC3339, updatedAt.String); e == nil {
-				p.UpdatedAt = t
-			}
+	}
+	if updatedAt.Valid {
+		if t, e := time.Parse(time.RFC3339, updatedAt.String); e == nil {
+			p.UpdatedAt = t
 		}
-		out = append(out, p)
 	}
-	return out, rows.Err()
+	return p, nil
 }
 
 func (s *Store) CreatePool(ctx context.Context, in domain.CreatePool) (domain.Pool, error) {
+	return createPool(ctx, s.q(), in)
+}
+
+// createPool inserts a pool using q, which may be the store's handle or an
+// open transaction.
+func createPool(ctx context.Context, q dbtx, in domain.CreatePool) (domain.Pool, error) {
 	if in.Name == "" || in.CIDR == "" {
 		return domain.Pool{}, fmt.Errorf("name and cidr required: %w", storage.ErrValidation)
 	}
@@ -199,7 +232,7 @@ func (s *Store) CreatePool(ctx context.Context, in domain.CreatePool) (domain.Po
 	}
 
 	nowStr := now.Format(time.RFC3339)
-	res, err := s.db.ExecContext(ctx, `INSERT INTO pools(name, cidr, parent_id, account_id, type, status, source, description, tags, created_at, updated_at) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+	res, err := q.ExecContext(ctx, `INSERT INTO pools(name, cidr, parent_id, account_id, type, status, source, description, tags, created_at, updated_at) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		in.Name, in.CIDR, in.ParentID, in.AccountID, string(poolType), string(poolStatus), string(poolSource), in.Description, tagsJSON, nowStr, nowStr)
 	if err != nil {
 		return domain.Pool{}, storage.WrapIfConflict(err)
@@ -229,60 +262,24 @@ func (s *Store) CreatePool(ctx context.Context, in domain.CreatePool) (domain.Po
 		Source:      poolSource,
 		Description: in.Description,
 		Tags:        tags,
+		Version:     1,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}, nil
 }
 
 func (s *Store) GetPool(ctx context.Context, id int64) (domain.Pool, bool, error) {
-	var p domain.Pool
-	var createdAt, updatedAt sql.NullString
-	var parent, account sql.NullInt64
-	var poolType, poolStatus, poolSource, description, tagsJSON sql.NullString
-	row := s.db.QueryRowContext(ctx, `SELECT id, name, cidr, parent_id, account_id, type, status, source, description, tags, created_at, updated_at FROM pools WHERE id=? AND deleted_at IS NULL`, id)
-	if err := row.Scan(&p.ID, &p.Name, &p.CIDR, &parent, &account, &poolType, &poolStatus, &poolSource, &description, &tagsJSON, &createdAt, &updatedAt); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return domain.Pool{}, false, nil
-		}
-		return domain.Pool{}, false, err
-	}
-	if parent.Valid {
-		p.ParentID = &parent.Int64
-	}
-	if account.Valid {
-		p.AccountID = &account.Int64
-	}
-	// Set new fields with defaults
-	p.Type = domain.PoolTypeSubnet
-	if poolType.Valid && poolType.String != "" {
-		p.Type = domain.PoolType(poolType.String)
-	}
-	p.Status = domain.PoolStatusActive
-	if poolStatus.Valid && poolStatus.String != "" {
-		p.Status = domain.PoolStatus(poolStatus.String)
-	}
-	p.Source = domain.PoolSourceManual
-	if poolSource.Valid && poolSource.String != "" {
-		p.Source = domain.PoolSource(poolSource.String)
-	}
-	if description.Valid {
-		p.Description = description.String
-	}
-	if tagsJSON.Valid && tagsJSON.String != "" && tagsJSON.String != "{}" {
-		var tags map[string]string
-		if err := json.Unmarshal([]byte(tagsJSON.String), &tags); err == nil {
-			p.Tags = tags
-		}
-	}
-	if createdAt.Valid {
-		if t, e := time.Parse(time.RFC3339, createdAt.String); e == nil {
-			p.CreatedAt = t
-		}
+	return getPool(ctx, s.q(), id)
+}
+
+// getPool reads a live pool by ID using q.
+func getPool(ctx context.Context, q dbtx, id int64) (domain.Pool, bool, error) {
+	p, err := scanPool(q.QueryRowContext(ctx, `SELECT `+poolSelectColumns+` FROM pools WHERE id=? AND deleted_at IS NULL`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Pool{}, false, nil
 	}
-	if updatedAt.Valid {
-		if t, e := time.Parse(time.RFC3339, updatedAt.String); e == nil {
-			p.UpdatedAt = t
-		}
+	if err != nil {
+		return domain.Pool{}, false, err
 	}
 	return p, true, nil
 }
@@ -290,7 +287,7 @@ func (s *Store) GetPool(ctx context.Context, id int64) (domain.Pool, bool, error
 func (s *Store) UpdatePoolAccount(ctx context.Context, id int64, accountID *int64) (domain.Pool, bool, error) {
 	// Update and then fetch
 	now := time.Now().UTC().Format(time.RFC3339)
-	if _, err := s.db.ExecContext(ctx, `UPDATE pools SET account_id=?, updated_at=? WHERE id=?`, accountID, now, id); err != nil {
+	if _, err := s.q().ExecContext(ctx, `UPDATE pools SET account_id=?, updated_at=?, version=version+1 WHERE id=?`, accountID, now, id); err != nil {
 		return domain.Pool{}, false, err
 	}
 	return s.GetPool(ctx, id)
@@ -308,7 +305,7 @@ func (s *Store) UpdatePoolMeta(ctx context.Context, id int64, name *string, acco
 	// Always set accountID (caller controls whether to clear or set)
 	p.AccountID = accountID
 	now := time.Now().UTC().Format(time.RFC3339)
-	if _, err := s.db.ExecContext(ctx, `UPDATE pools SET name=?, account_id=?, updated_at=? WHERE id=?`, p.Name, p.AccountID, now, id); err != nil {
+	if _, err := s.q().ExecContext(ctx, `UPDATE pools SET name=?, account_id=?, updated_at=?, version=version+1 WHERE id=?`, p.Name, p.AccountID, now, id); err != nil {
 		return domain.Pool{}, false, err
 	}
 	return s.GetPool(ctx, id)
@@ -321,6 +318,9 @@ func (s *Store) UpdatePool(ctx context.Context, id int64, update domain.UpdatePo
 	if err != nil || !ok {
 		return domain.Pool{}, ok, err
 	}
+	if update.IfVersion != nil && *update.IfVersion != p.Version {
+		return domain.Pool{}, false, fmt.Errorf("pool %d is at version %d: %w", id, p.Version, storage.ErrPreconditionFailed)
+	}
 	if update.Name != nil {
 		p.Name = *update.Name
 	}
@@ -356,25 +356,31 @@ func (s *Store) UpdatePool(ctx context.Context, id int64, update domain.UpdatePo
 		tagsJSON = "{}"
 	}
 
+	// For a conditional update the version guard makes the read-modify-write
+	// atomic: a concurrent writer moves the version and this matches no row.
 	now := time.Now().UTC().Format(time.RFC3339)
-	if _, err := s.db.ExecContext(ctx, `UPDATE pools SET name=?, account_id=?, type=?, status=?, description=?, tags=?, updated_at=? WHERE id=?`,
-		p.Name, p.AccountID, string(p.Type), string(p.Status), p.Description, tagsJSON, now, id); err != nil {
+	res, err := s.q().ExecContext(ctx, `UPDATE pools SET name=?, account_id=?, type=?, status=?, description=?, tags=?, updated_at=?, version=version+1 WHERE id=? AND (? IS NULL OR version=?)`,
+		p.Name, p.AccountID, string(p.Type), string(p.Status), p.Description, tagsJSON, now, id, update.IfVersion, update.IfVersion)
+	if err != nil {
 		return domain.Pool{}, false, err
 	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.Pool{}, false, fmt.Errorf("pool %d changed concurrently: %w", id, storage.ErrPreconditionFailed)
+	}
 	return s.GetPool(ctx, id)
 }
 
 func (s *Store) DeletePool(ctx context.Context, id int64) (bool, error) {
 	// check children (only non-deleted)
 	var cnt int
-	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(1) FROM pools WHERE parent_id=? AND deleted_at IS NULL`, id).Scan(&cnt); err != nil {
+	if err := s.q().QueryRowContext(ctx, `SELECT COUNT(1) FROM pools WHERE parent_id=? AND deleted_at IS NULL`, id).Scan(&cnt); err != nil {
 		return false, err
 	}
 	if cnt > 0 {
 		return false, fmt.Errorf("pool has child pools: %w", storage.ErrConflict)
 	}
 	now := time.Now().UTC().Format(time.RFC3339)
-	res, err := s.db.ExecContext(ctx, `UPDATE pools SET deleted_at=?, updated_at=? WHERE id=? AND deleted_at IS NULL`, now, now, id)
+	res, err := s.q().ExecContext(ctx, `UPDATE pools SET deleted_at=?, updated_at=?, version=version+1 WHERE id=? AND deleted_at IS NULL`, now, now, id)
 	if err != nil {
 		return false, err
 	}
@@ -414,7 +420,7 @@ func (s *Store) DeletePoolCascade(ctx context.Context, id int64) (bool, error) {
 	}
 	now := time.Now().UTC().Format(time.RFC3339)
 	for _, pid := range order {
-		_, err := s.db.ExecContext(ctx, `UPDATE pools SET deleted_at=?, updated_at=? WHERE id=? AND deleted_at IS NULL`, now, now, pid)
+		_, err := s.q().ExecContext(ctx, `UPDATE pools SET deleted_at=?, updated_at=?, version=version+1 WHERE id=? AND deleted_at IS NULL`, now, now, pid)
 		if err != nil {
 			return false, err
 		}
@@ -424,89 +430,33 @@ func (s *Store) DeletePoolCascade(ctx context.Context, id int64) (bool, error) {
 
 // Accounts
 func (s *Store) ListAccounts(ctx context.Context) ([]domain.Account, error) {
-	rows, err := s.db.QueryContext(ctx, `SELECT id, key, name, provider, external_id, description, platform, tier, environment, regions, created_at, updated_at FROM accounts WHERE deleted_at IS NULL ORDER BY id ASC`)
+	rows, err := s.q().QueryContext(ctx, `SELECT `+accountSelectColumns+` FROM accounts WHERE deleted_at IS NULL ORDER BY id ASC`)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 	var out []domain.Account
 	for rows.Next() {
-		var a domain.Account
-		var ts string
-		var provider, extid, desc, platform, tier, env sql.NullString
-		var regions, updatedAt sql.NullString
-		if err := rows.Scan(&a.ID, &a.Key, &a.Name, &provider, &extid, &desc, &platform, &tier, &env, &regions, &ts, &updatedAt); err != nil {
+		a, err := scanAccount(rows)
+		if err != nil {
 			return nil, err
 		}
-		if provider.Valid {
-			a.Provider = provider.String
-		}
-		if extid.Valid {
-			a.ExternalID = extid.String
-		}
-		if desc.Valid {
-			a.Description = desc.String
-		}
-		if platform.Valid {
-			a.Platform = platform.String
-		}
-		if tier.Valid {
-			a.Tier = tier.String
-		}
-		if env.Valid {
-			a.Environment = env.String
-		}
-		if regions.Valid && regions.String != "" {
-			var arr []string
-			if err := json.Unmarshal([]byte(regions.String), &arr); err == nil {
-				a.Regions = arr
-			}
-		}
-		if t, e := time.Parse(time.RFC3339, ts); e == nil {
-			a.CreatedAt = t
-		}
-		if updatedAt.Valid {
-			if t, e := time.Parse(time.RFC3339, updatedAt.String); e == nil {
-				a.UpdatedAt = t
-			}
-		}
 		out = append(out, a)
 	}
 	return out, rows.Err()
 }
 
-func (s *Store) CreateAccount(ctx context.Context, in domain.CreateAccount) (domain.Account, error) {
-	if in.Key == "" || in.Name == "" {
-		return domain.Account{}, fmt.Errorf("key and name required: %w", storage.ErrValidation)
-	}
-	var regions string
-	if len(in.Regions) > 0 {
-		if b, e := json.Marshal(in.Regions); e == nil {
-			regions = string(b)
-		}
-	}
-	res, err := s.db.ExecContext(ctx, `INSERT INTO accounts(key, name, provider, external_id, description, platform, tier, environment, regions, created_at) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, in.Key, in.Name, in.Provider, in.ExternalID, in.Description, in.Platform, in.Tier, in.Environment, regions, time.Now().UTC().Format(time.RFC3339))
-	if err != nil {
-		return domain.Account{}, storage.WrapIfConflict(err)
-	}
-	id, err := res.LastInsertId()
-	if err != nil {
-		return domain.Account{}, err
-	}
-	return domain.Account{ID: id, Key: in.Key, Name: in.Name, Provider: in.Provider, ExternalID: in.ExternalID, Description: in.Description, Platform: in.Platform, Tier: in.Tier, Environment: in.Environment, Regions: append([]string(nil), in.Regions...), CreatedAt: time.Now().UTC()}, nil
-}
+// accountSelectColumns are the columns scanAccount expects, in order.
+const accountSelectColumns = `id, key, name, provider, external_id, description, platform, tier, environment, regions, version, created_at, updated_at`
 
-func (s *Store) GetAccount(ctx context.Context, id int64) (domain.Account, bool, error) {
-	row := s.db.QueryRowContext(ctx, `SELECT id, key, name, provider, external_id, description, platform, tier, environment, regions, created_at, updated_at FROM accounts WHERE id=? AND deleted_at IS NULL`, id)
+// scanAccount scans one row of accountSelectColumns.
+func scanAccount(row scanner) (domain.Account, error) {
 	var a domain.Account
 	var ts string
 	var provider, extid, desc, platform, tier, env sql.NullString
 	var regions, updatedAt sql.NullString
-	if err := row.Scan(&a.ID, &a.Key, &a.Name, &provider, &extid, &desc, &platform, &tier, &env, &regions, &ts, &updatedAt); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return domain.Account{}, false, nil
-		}
-		return domain.Account{}, false, err
+	if err := row.Scan(&a.ID, &a.Key, &a.Name, &provider, &extid, &desc, &platform, &tier, &env, &regions, &a.Version, &ts, &updatedAt); err != nil {
+		return domain.Account{}, err
 	}
 	if provider.Valid {
 		a.Provider = provider.String
@@ -540,95 +490,59 @@ func (s *Store) GetAccount(ctx context.Context, id int64) (domain.Account, bool,
 			a.UpdatedAt = t
 		}
 	}
-	return a, true, nil
+	return a, nil
 }
 
-func (s *Store) GetAccountByKey(ctx context.Context, key string) (*domain.Account, error) {
-	row := s.db.QueryRowContext(ctx, `SELECT id, key, name, provider, external_id, description, platform, tier, environment, regions, created_at, updated_at FROM accounts WHERE key=? AND deleted_at IS NULL`, key)
-	var a domain.Account
-	var ts string
-	var provider, extid, desc, platform, tier, env sql.NullString
-	var regions, updatedAt sql.NullString
-	if err := row.Scan(&a.ID, &a.Key, &a.Name, &provider, &extid, &desc, &platform, &tier, &env, &regions, &ts, &updatedAt); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, storage.ErrNotFound
-		}
-		return nil, err
-	}
-	if provider.Valid {
-		a.Provider = provider.String
-	}
-	if extid.Valid {
-		a.ExternalID = extid.String
-	}
-	if desc.Valid {
-		a.Description = desc.String
-	}
-	if platform.Valid {
-		a.Platform = platform.String
-	}
-	if tier.Valid {
-		a.Tier = tier.String
-	}
-	if env.Valid {
-		a.Environment = env.String
+func (s *Store) CreateAccount(ctx context.Context, in domain.CreateAccount) (domain.Account, error) {
+	if in.Key == "" || in.Name == "" {
+		return domain.Account{}, fmt.Errorf("key and name required: %w", storage.ErrValidation)
 	}
-	if regions.Valid && regions.String != "" {
-		var arr []string
-		if err := json.Unmarshal([]byte(regions.String), &arr); err == nil {
-			a.Regions = arr
+	var regions string
+	if len(in.Regions) > 0 {
+		if b, e := json.Marshal(in.Regions); e == nil {
+			regions = string(b)
 		}
 	}
-	if t, e := time.Parse(time.RFC3339, ts); e == nil {
-		a.CreatedAt = t
+	res, err := s.q().ExecContext(ctx, `INSERT INTO accounts(key, name, provider, external_id, description, platform, tier, environment, regions, created_at) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, in.Key, in.Name, in.Provider, in.ExternalID, in.Description, in.Platform, in.Tier, in.Environment, regions, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return domain.Account{}, storage.WrapIfConflict(err)
 	}
-	if updatedAt.Valid {
-		if t, e := time.Parse(time.RFC3339, updatedAt.String); e == nil {
-			a.UpdatedAt = t
-		}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return domain.Account{}, err
 	}
-	return &a, nil
+	return domain.Account{ID: id, Key: in.Key, Name: in.Name, Provider: in.Provider, ExternalID: in.ExternalID, Description: in.Description, Platform: in.Platform, Tier: in.Tier, Environment: in.Environment, Regions: append([]string(nil), in.Regions...), Version: 1, CreatedAt: time.Now().UTC()}, nil
 }
 
-func (s *Store) UpdateAccount(ctx context.Context, id int64, update domain.Account) (domain.Account, bool, error) {
-	// Fetch current (non-deleted)
-	rows, err := s.db.QueryContext(ctx, `SELECT id, key, name, provider, external_id, description, platform, tier, environment, regions, created_at, updated_at FROM accounts WHERE id=? AND deleted_at IS NULL`, id)
-	if err != nil {
-		return domain.Account{}, false, err
-	}
-	defer rows.Close()
-	if !rows.Next() {
+func (s *Store) GetAccount(ctx context.Context, id int64) (domain.Account, bool, error) {
+	a, err := scanAccount(s.q().QueryRowContext(ctx, `SELECT `+accountSelectColumns+` FROM accounts WHERE id=? AND deleted_at IS NULL`, id))
+	if errors.Is(err, sql.ErrNoRows) {
 		return domain.Account{}, false, nil
 	}
-	var a domain.Account
-	var ts string
-	var provider, extid, desc, platform, tier, env sql.NullString
-	var regions, updatedAt sql.NullString
-	if err := rows.Scan(&a.ID, &a.Key, &a.Name, &provider, &extid, &desc, &platform, &tier, &env, &regions, &ts, &updatedAt); err != nil {
+	if err != nil {
 		return domain.Account{}, false, err
 	}
-	if provider.Valid {
-		a.Provider = provider.String
-	}
-	if extid.Valid {
-		a.ExternalID = extid.String
-	}
-	if desc.Valid {
-		a.Description = desc.String
-	}
-	if platform.Valid {
-		a.Platform = platform.String
+	return a, true, nil
+}
+
+func (s *Store) GetAccountByKey(ctx context.Context, key string) (*domain.Account, error) {
+	a, err := scanAccount(s.q().QueryRowContext(ctx, `SELECT `+accountSelectColumns+` FROM accounts WHERE key=? AND deleted_at IS NULL`, key))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, storage.ErrNotFound
 	}
-	if tier.Valid {
-		a.Tier = tier.String
+	if err != nil {
+		return nil, err
 	}
-	if env.Valid {
-		a.Environment = env.String
+	return &a, nil
+}
+
+func (s *Store) UpdateAccount(ctx context.Context, id int64, update domain.Account) (domain.Account, bool, error) {
+	a, ok, err := s.GetAccount(ctx, id)
+	if err != nil || !ok {
+		return domain.Account{}, ok, err
 	}
-	if regions.Valid && regions.String != "" {
-		var arr []string
-		_ = json.Unmarshal([]byte(regions.String), &arr)
-		a.Regions = arr
+	if update.Version != 0 && update.Version != a.Version {
+		return domain.Account{}, false, fmt.Errorf("account %d is at version %d: %w", id, a.Version, storage.ErrPreconditionFailed)
 	}
 	// Apply update
 	if update.Name != "" {
@@ -653,22 +567,26 @@ func (s *Store) UpdateAccount(ctx context.Context, id int64, update domain.Accou
 			regionsOut = &s
 		}
 	}
-	if _, err := s.db.ExecContext(ctx, `UPDATE accounts SET name=?, provider=?, external_id=?, description=?, platform=?, tier=?, environment=?, regions=?, updated_at=? WHERE id=?`, a.Name, a.Provider, a.ExternalID, a.Description, a.Platform, a.Tier, a.Environment, regionsOut, now.Format(time.RFC3339), id); err != nil {
+	res, err := s.q().ExecContext(ctx, `UPDATE accounts SET name=?, provider=?, external_id=?, description=?, platform=?, tier=?, environment=?, regions=?, updated_at=?, version=version+1 WHERE id=? AND (?=0 OR version=?)`, a.Name, a.Provider, a.ExternalID, a.Description, a.Platform, a.Tier, a.Environment, regionsOut, now.Format(time.RFC3339), id, update.Version, update.Version)
+	if err != nil {
 		return domain.Account{}, false, err
 	}
-	return a, true, nil
+	if n, _ := res.RowsAffected(); n == 0 {
+		return domain.Account{}, false, fmt.Errorf("account %d changed concurrently: %w", id, storage.ErrPreconditionFailed)
+	}
+	return s.GetAccount(ctx, id)
 }
 
 func (s *Store) DeleteAccount(ctx context.Context, id int64) (bool, error) {
 	var cnt int
-	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(1) FROM pools WHERE account_id=? AND deleted_at IS NULL`, id).Scan(&cnt); err != nil {
+	if err := s.q().QueryRowContext(ctx, `SELECT COUNT(1) FROM pools WHERE account_id=? AND deleted_at IS NULL`, id).Scan(&cnt); err != nil {
 		return false, err
 	}
 	if cnt > 0 {
 		return false, fmt.Errorf("account in use by pools: %w", storage.ErrConflict)
 	}
 	now := time.Now().UTC().Format(time.RFC3339)
-	res, err := s.db.ExecContext(ctx, `UPDATE accounts SET deleted_at=?, updated_at=? WHERE id=? AND deleted_at IS NULL`, now, now, id)
+	res, err := s.q().ExecContext(ctx, `UPDATE accounts SET deleted_at=?, updated_at=?, version=version+1 WHERE id=? AND deleted_at IS NULL`, now, now, id)
 	if err != nil {
 		return false, err
 	}
@@ -684,7 +602,7 @@ func (s *Store) DeleteAccountCascade(ctx context.Context, id int64) (bool, error
 	}
 	// Check account exists (non-deleted)
 	var accCnt int
-	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(1) FROM accounts WHERE id=? AND deleted_at IS NULL`, id).Scan(&accCnt); err != nil {
+	if err := s.q().QueryRowContext(ctx, `SELECT COUNT(1) FROM accounts WHERE id=? AND deleted_at IS NULL`, id).Scan(&accCnt); err != nil {
 		return false, err
 	}
 	if accCnt == 0 {
@@ -722,12 +640,12 @@ func (s *Store) DeleteAccountCascade(ctx context.Context, id int64) (bool, error
 	// Soft-delete pools
 	now := time.Now().UTC().Format(time.RFC3339)
 	for pid := range toDel {
-		if _, err := s.db.ExecContext(ctx, `UPDATE pools SET deleted_at=?, updated_at=? WHERE id=? AND deleted_at IS NULL`, now, now, pid); err != nil {
+		if _, err := s.q().ExecContext(ctx, `UPDATE pools SET deleted_at=?, updated_at=?, version=version+1 WHERE id=? AND deleted_at IS NULL`, now, now, pid); err != nil {
 			return false, err
 		}
 	}
 	// Soft-delete account
-	if _, err := s.db.ExecContext(ctx, `UPDATE accounts SET deleted_at=?, updated_at=? WHERE id=? AND deleted_at IS NULL`, now, now, id); err != nil {
+	if _, err := s.q().ExecContext(ctx, `UPDATE accounts SET deleted_at=?, updated_at=?, version=version+1 WHERE id=? AND deleted_at IS NULL`, now, now, id); err != nil {
 		return false, err
 	}
 	return true, nil
@@ -831,8 +749,12 @@ func (s *Store) GetPoolChildren(ctx context.Context, parentID int64) ([]domain.P
 	if !ok {
 		return nil, fmt.Errorf("parent pool not found: %w", storage.ErrNotFound)
 	}
+	return poolChildren(ctx, s.q(), parentID)
+}
 
-	rows, err := s.db.QueryContext(ctx, `SELECT id, name, cidr, parent_id, account_id, type, status, source, description, tags, created_at, updated_at FROM pools WHERE parent_id=? AND deleted_at IS NULL ORDER BY id ASC`, parentID)
+// poolChildren lists the live direct children of parentID using q.
+func poolChildren(ctx context.Context, q dbtx, parentID int64) ([]domain.Pool, error) {
+	rows, err := q.QueryContext(ctx, `SELECT `+poolSelectColumns+` FROM pools WHERE parent_id=? AND deleted_at IS NULL ORDER BY id ASC`, parentID)
 	if err != nil {
 		return nil, err
 	}
@@ -840,50 +762,10 @@ func (s *Store) GetPoolChildren(ctx context.Context, parentID int64) ([]domain.P
 
 	var children []domain.Pool
 	for rows.Next() {
-		var p domain.Pool
-		var createdAt, updatedAt sql.NullString
-		var parent, account sql.NullInt64
-		var poolType, poolStatus, poolSource, description, tagsJSON sql.NullString
-		if err := rows.Scan(&p.ID, &p.Name, &p.CIDR, &parent, &account, &poolType, &poolStatus, &poolSource, &description, &tagsJSON, &createdAt, &updatedAt); err != nil {
+		p, err := scanPool(rows)
+		if err != nil {
 			return nil, err
 		}
-		if parent.Valid {
-			p.ParentID = &parent.Int64
-		}
-		if account.Valid {
-			p.AccountID = &account.Int64
-		}
-		p.Type = domain.PoolTypeSubnet
-		if poolType.Valid && poolType.String != "" {
-			p.Type = domain.PoolType(poolType.String)
-		}
-		p.Status = domain.PoolStatusActive
-		if poolStatus.Valid && poolStatus.String != "" {
-			p.Status = domain.PoolStatus(poolStatus.String)
-		}
-		p.Source = domain.PoolSourceManual
-		if poolSource.Valid && poolSource.String != "" {
-			p.Source = domain.PoolSource(poolSource.String)
-		}
-		if description.Valid {
-			p.Description = description.String
-		}
-		if tagsJSON.Valid && tagsJSON.String != "" && tagsJSON.String != "{}" {
-			var tags map[string]string
-			if err := json.Unmarshal([]byte(tagsJSON.String), &tags); err == nil {
-				p.Tags = tags
-			}
-		}
-		if createdAt.Valid {
-			if t, e := time.Parse(time.RFC3339, createdAt.String); e == nil {
-				p.CreatedAt = t
-			}
-		}
-		if updatedAt.Valid {
-			if t, e := time.Parse(time.RFC3339, updatedAt.String); e == nil {
-				p.UpdatedAt = t
-			}
-		}
 		children = append(children, p)
 	}
 	return children, rows.Err()
@@ -909,43 +791,22 @@ func (s *Store) calculatePoolStats(ctx context.Context, p domain.Pool) (*domain.
 		return &domain.PoolStats{}, nil
 	}
 
-	var totalIPs int64
-	if prefix.Addr().Is4() {
-		totalIPs = int64(1) << (32 - prefix.Bits())
-	} else {
-		// For IPv6, cap at max int64 for practical purposes
-		bits := 128 - prefix.Bits()
-		if bits >= 63 {
-			totalIPs = 1<<63 - 1 // max int64
-		} else {
-			totalIPs = int64(1) << bits
-		}
-	}
-
 	// Get direct children (non-deleted)
-	rows, err := s.db.QueryContext(ctx, `SELECT cidr FROM pools WHERE parent_id=? AND deleted_at IS NULL`, p.ID)
+	rows, err := s.q().QueryContext(ctx, `SELECT cidr FROM pools WHERE parent_id=? AND deleted_at IS NULL`, p.ID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
 	var directChildren int
-	var usedIPs int64
+	var childCIDRs []string
 	for rows.Next() {
 		var childCIDR string
 		if err := rows.Scan(&childCIDR); err != nil {
 			return nil, err
 		}
 		directChildren++
-
-		// Calculate used IPs from child's CIDR
-		childPrefix, err := netip.ParsePrefix(childCIDR)
-		if err != nil {
-			continue
-		}
-		if childPrefix.Addr().Is4() {
-			usedIPs += int64(1) << (32 - childPrefix.Bits())
-		}
+		childCIDRs = append(childCIDRs, childCIDR)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
@@ -956,7 +817,7 @@ func (s *Store) calculatePoolStats(ctx context.Context, p domain.Pool) (*domain.
 	var countDescendants func(parentID int64) (int, error)
 	countDescendants = func(parentID int64) (int, error) {
 		var count int
-		childRows, err := s.db.QueryContext(ctx, `SELECT id FROM pools WHERE parent_id=? AND deleted_at IS NULL`, parentID)
+		childRows, err := s.q().QueryContext(ctx, `SELECT id FROM pools WHERE parent_id=? AND deleted_at IS NULL`, parentID)
 		if err != nil {
 			return 0, err
 		}
@@ -981,16 +842,18 @@ func (s *Store) calculatePoolStats(ctx context.Context, p domain.Pool) (*domain.
 		return nil, err
 	}
 
-	// Calculate utilization percentage
-	var utilization float64
-	if totalIPs > 0 {
-		utilization = float64(usedIPs) / float64(totalIPs) * 100
+	addresses, err := poolAddressList(ctx, s.q(), p.ID)
+	if err != nil {
+		return nil, err
 	}
+	childCIDRs = append(childCIDRs, storage.HostCIDRs(childCIDRs, addresses)...)
+
+	totalIPs, usedIPs, availableIPs, utilization := storage.AddressUsage(prefix, childCIDRs)
 
 	return &domain.PoolStats{
 		TotalIPs:       totalIPs,
 		UsedIPs:        usedIPs,
-		AvailableIPs:   totalIPs - usedIPs,
+		AvailableIPs:   availableIPs,
 		Utilization:    utilization,
 		ChildCount:     totalChildCount,
 		DirectChildren: directChildren,
@@ -1040,16 +903,34 @@ func (s *Store) Search(ctx context.Context, req domain.SearchRequest) (domain.Se
 	var items []domain.SearchResultItem
 
 	// Search pools — use SQL LIKE for text search, then filter CIDR in Go
+	// against the candidates the interval tree allows.
 	if searchPools {
+		var candidates map[int64]bool
+		if hasCIDRContains || hasCIDRWithin {
+			idx, err := s.poolIndex(ctx)
+			if err != nil {
+				return domain.SearchResponse{}, err
+			}
+			var hits []cidr.IndexEntry
+			if hasCIDRContains {
+				hits = idx.Containing(cidrContains)
+			} else {
+				hits = idx.ContainedBy(cidrWithin)
+			}
+			candidates = make(map[int64]bool, len(hits))
+			for _, h := range hits {
+				candidates[h.ID] = true
+			}
+		}
 		var poolRows *sql.Rows
 		var err error
 		if query != "" {
 			like := "%" + query + "%"
-			poolRows, err = s.db.QueryContext(ctx,
+			poolRows, err = s.q().QueryContext(ctx,
 				`SELECT id, name, cidr, parent_id, account_id, type, status, source, description FROM pools WHERE deleted_at IS NULL AND (name LIKE ? OR cidr LIKE ? OR description LIKE ?) ORDER BY id`,
 				like, like, like)
 		} else {
-			poolRows, err = s.db.QueryContext(ctx,
+			poolRows, err = s.q().QueryContext(ctx,
 				`SELECT id, name, cidr, parent_id, account_id, type, status, source, description FROM pools WHERE deleted_at IS NULL ORDER BY id`)
 		}
 		if err != nil {
@@ -1067,7 +948,10 @@ func (s *Store) Search(ctx context.Context, req domain.SearchRequest) (domain.Se
 			}
 
 			// Apply CIDR filters in Go
-			if hasCIDRContains || hasCIDRWithin {
+			if candidates != nil {
+				if !candidates[id] {
+					continue
+				}
 				poolPrefix, err := netip.ParsePrefix(cidrStr)
 				if err != nil {
 					continue
@@ -1116,11 +1000,11 @@ func (s *Store) Search(ctx context.Context, req domain.SearchRequest) (domain.Se
 		var err error
 		if query != "" {
 			like := "%" + query + "%"
-			accRows, err = s.db.QueryContext(ctx,
+			accRows, err = s.q().QueryContext(ctx,
 				`SELECT id, key, name, provider, description FROM accounts WHERE deleted_at IS NULL AND (name LIKE ? OR key LIKE ? OR description LIKE ?) ORDER BY id`,
 				like, like, like)
 		} else {
-			accRows, err = s.db.QueryContext(ctx,
+			accRows, err = s.q().QueryContext(ctx,
 				`SELECT id, key, name, provider, description FROM accounts WHERE deleted_at IS NULL ORDER BY id`)
 		}
 		if err != nil {
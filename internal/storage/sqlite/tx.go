@@ -0,0 +1,142 @@
+//go:build sqlite
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"cloudpam/internal/storage"
+)
+
+var (
+	_ storage.TransactionalStore = (*Store)(nil)
+	_ storage.Transaction        = (*Tx)(nil)
+)
+
+// txScope is a transaction or a savepoint inside one.
+type txScope interface {
+	dbtx
+	Commit() error
+	Rollback() error
+}
+
+// savepointSeq keeps savepoint names unique within a connection.
+var savepointSeq atomic.Uint64
+
+// savepoint is a nested transaction expressed as an SQLite SAVEPOINT on the
+// enclosing *sql.Tx.
+type savepoint struct {
+	*sql.Tx
+	ctx  context.Context
+	name string
+	done bool
+}
+
+func newSavepoint(ctx context.Context, tx *sql.Tx) (*savepoint, error) {
+	name := fmt.Sprintf("sp_%d", savepointSeq.Add(1))
+	if _, err := tx.ExecContext(ctx, `SAVEPOINT `+name); err != nil {
+		return nil, err
+	}
+	return &savepoint{Tx: tx, ctx: ctx, name: name}, nil
+}
+
+func (sp *savepoint) Commit() error {
+	if sp.done {
+		return sql.ErrTxDone
+	}
+	sp.done = true
+	_, err := sp.Tx.ExecContext(sp.ctx, `RELEASE SAVEPOINT `+sp.name)
+	return err
+}
+
+func (sp *savepoint) Rollback() error {
+	if sp.done {
+		return sql.ErrTxDone
+	}
+	sp.done = true
+	if _, err := sp.Tx.ExecContext(sp.ctx, `ROLLBACK TO SAVEPOINT `+sp.name); err != nil {
+		return err
+	}
+	_, err := sp.Tx.ExecContext(sp.ctx, `RELEASE SAVEPOINT `+sp.name)
+	return err
+}
+
+// q returns the handle queries run on: the transaction when the Store is
+// scoped to one, the database otherwise.
+func (s *Store) q() dbtx {
+	if s.tx != nil {
+		return s.tx
+	}
+	return s.db
+}
+
+// begin starts a transaction for a multi-statement write. When the Store is
+// already scoped to a transaction it opens a savepoint instead, so helpers
+// like AllocatePool compose with an outer WithTx.
+func (s *Store) begin(ctx context.Context) (txScope, error) {
+	if s.tx != nil {
+		return newSavepoint(ctx, s.tx)
+	}
+	return s.db.BeginTx(ctx, nil)
+}
+
+// Tx is a Store whose reads and writes all run inside one database
+// transaction. It also satisfies every other storage interface Store does,
+// so discovery links and drift records can join the same transaction.
+type Tx struct {
+	*Store
+	scope txScope
+}
+
+// BeginTx starts a transaction. Calling it on a Tx opens a savepoint.
+func (s *Store) BeginTx(ctx context.Context) (storage.Transaction, error) {
+	if s.tx != nil {
+		sp, err := newSavepoint(ctx, s.tx)
+		if err != nil {
+			return nil, err
+		}
+		return &Tx{Store: s, scope: sp}, nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Store: &Store{db: s.db, tx: tx}, scope: tx}, nil
+}
+
+// WithTx runs fn in a transaction, committing if fn returns nil and rolling
+// back otherwise.
+func (s *Store) WithTx(ctx context.Context, fn func(tx storage.Transaction) error) error {
+	tx, err := s.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Commit commits the transaction.
+func (t *Tx) Commit() error {
+	return t.scope.Commit()
+}
+
+// Rollback aborts the transaction. It is a no-op once the transaction has
+// been committed or rolled back.
+func (t *Tx) Rollback() error {
+	if err := t.scope.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+		return err
+	}
+	return nil
+}
+
+// Close rolls the transaction back; the underlying database stays open.
+func (t *Tx) Close() error {
+	return t.Rollback()
+}
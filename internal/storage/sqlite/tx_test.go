@@ -0,0 +1,95 @@
+//go:build sqlite
+
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+func TestWithTx(t *testing.T) {
+	s, err := New("file:" + filepath.Join(t.TempDir(), "tx.db"))
+	if err != nil {
+		t.Fatalf("new sqlite store: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	ctx := context.Background()
+
+	root, err := s.CreatePool(ctx, domain.CreatePool{Name: "root", CIDR: "10.0.0.0/16"})
+	if err != nil {
+		t.Fatalf("create root: %v", err)
+	}
+
+	// A failing transaction leaves nothing behind, including pools created
+	// through AllocatePool, which joins the outer transaction.
+	boom := errors.New("boom")
+	err = s.WithTx(ctx, func(tx storage.Transaction) error {
+		if _, err := tx.CreatePool(ctx, domain.CreatePool{Name: "a", CIDR: "10.0.0.0/24", ParentID: &root.ID}); err != nil {
+			return err
+		}
+		alloc, ok := tx.(storage.PoolAllocator)
+		if !ok {
+			t.Fatal("transaction should support AllocatePool")
+		}
+		if _, err := alloc.AllocatePool(ctx, root.ID, func(_ domain.Pool, children []domain.Pool) (domain.CreatePool, error) {
+			if len(children) != 1 {
+				t.Errorf("allocator should see the uncommitted child, got %d", len(children))
+			}
+			return domain.CreatePool{Name: "b", CIDR: "10.0.1.0/24"}, nil
+		}); err != nil {
+			return err
+		}
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("WithTx error = %v, want %v", err, boom)
+	}
+	children, err := s.GetPoolChildren(ctx, root.ID)
+	if err != nil {
+		t.Fatalf("children: %v", err)
+	}
+	if len(children) != 0 {
+		t.Fatalf("rolled-back transaction left %d children", len(children))
+	}
+
+	// A nested transaction rolls back to its savepoint only.
+	err = s.WithTx(ctx, func(tx storage.Transaction) error {
+		if _, err := tx.CreatePool(ctx, domain.CreatePool{Name: "kept", CIDR: "10.0.2.0/24", ParentID: &root.ID}); err != nil {
+			return err
+		}
+		nested := tx.(storage.TransactionalStore)
+		_ = nested.WithTx(ctx, func(sp storage.Transaction) error {
+			if _, err := sp.CreatePool(ctx, domain.CreatePool{Name: "dropped", CIDR: "10.0.3.0/24", ParentID: &root.ID}); err != nil {
+				return err
+			}
+			return boom
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithTx: %v", err)
+	}
+	children, err = s.GetPoolChildren(ctx, root.ID)
+	if err != nil {
+		t.Fatalf("children: %v", err)
+	}
+	if len(children) != 1 || children[0].Name != "kept" {
+		t.Fatalf("expected only the outer pool to commit, got %+v", children)
+	}
+
+	tx, err := s.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("rollback after commit should be a no-op, got %v", err)
+	}
+}
@@ -13,7 +13,7 @@ import (
 var _ storage.UtilizationStore = (*Store)(nil)
 
 func (s *Store) RecordSnapshot(ctx context.Context, snap domain.UtilizationSnapshot) error {
-	_, err := s.db.ExecContext(ctx, `
+	_, err := s.q().ExecContext(ctx, `
 		INSERT INTO utilization_snapshots (pool_id, total_ips, used_ips, available_ips, utilization, child_count, captured_at)
 		VALUES (?, ?, ?, ?, ?, ?, ?)`,
 		snap.PoolID, snap.TotalIPs, snap.UsedIPs, snap.AvailableIPs,
@@ -22,7 +22,7 @@ func (s *Store) RecordSnapshot(ctx context.Context, snap domain.UtilizationSnaps
 }
 
 func (s *Store) ListSnapshots(ctx context.Context, poolID int64, from, to time.Time) ([]domain.UtilizationSnapshot, error) {
-	rows, err := s.db.QueryContext(ctx, `
+	rows, err := s.q().QueryContext(ctx, `
 		SELECT id, pool_id, total_ips, used_ips, available_ips, utilization, child_count, captured_at
 		FROM utilization_snapshots
 		WHERE pool_id = ? AND captured_at >= ? AND captured_at <= ?
@@ -50,7 +50,7 @@ func (s *Store) ListSnapshots(ctx context.Context, poolID int64, from, to time.T
 }
 
 func (s *Store) LatestSnapshot(ctx context.Context, poolID int64) (*domain.UtilizationSnapshot, error) {
-	row := s.db.QueryRowContext(ctx, `
+	row := s.q().QueryRowContext(ctx, `
 		SELECT id, pool_id, total_ips, used_ips, available_ips, utilization, child_count, captured_at
 		FROM utilization_snapshots
 		WHERE pool_id = ?
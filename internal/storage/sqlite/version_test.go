@@ -0,0 +1,61 @@
+//go:build sqlite
+
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+func TestVersioning(t *testing.T) {
+	m, err := New("file:" + filepath.Join(t.TempDir(), "version.db"))
+	if err != nil {
+		t.Fatalf("new sqlite store: %v", err)
+	}
+	t.Cleanup(func() { _ = m.Close() })
+	ctx := context.Background()
+	p, _ := m.CreatePool(ctx, domain.CreatePool{Name: "p", CIDR: "10.0.0.0/16"})
+	if p.Version != 1 {
+		t.Fatalf("new pool version = %d, want 1", p.Version)
+	}
+	name := "renamed"
+	stale := p.Version
+	up, ok, err := m.UpdatePool(ctx, p.ID, domain.UpdatePool{Name: &name, IfVersion: &stale})
+	if err != nil || !ok || up.Version != 2 {
+		t.Fatalf("UpdatePool(IfVersion=1) = %+v, %v, %v; want version 2", up, ok, err)
+	}
+	if _, _, err := m.UpdatePool(ctx, p.ID, domain.UpdatePool{Name: &name, IfVersion: &stale}); !errors.Is(err, storage.ErrPreconditionFailed) {
+		t.Fatalf("stale IfVersion: expected ErrPreconditionFailed, got %v", err)
+	}
+	if up, _, err := m.UpdatePool(ctx, p.ID, domain.UpdatePool{Name: &name}); err != nil || up.Version != 3 {
+		t.Fatalf("unconditional UpdatePool = %+v, %v; want version 3", up, err)
+	}
+	if up, _, _ := m.UpdatePoolAccount(ctx, p.ID, nil); up.Version != 4 {
+		t.Errorf("UpdatePoolAccount version = %d, want 4", up.Version)
+	}
+
+	a, _ := m.CreateAccount(ctx, domain.CreateAccount{Key: "aws:1", Name: "a"})
+	if a.Version != 1 {
+		t.Fatalf("new account version = %d, want 1", a.Version)
+	}
+	upd := domain.Account{Name: "b", Version: 1}
+	ua, ok, err := m.UpdateAccount(ctx, a.ID, upd)
+	if err != nil || !ok || ua.Version != 2 || ua.Name != "b" {
+		t.Fatalf("UpdateAccount(Version=1) = %+v, %v, %v", ua, ok, err)
+	}
+	if _, _, err := m.UpdateAccount(ctx, a.ID, upd); !errors.Is(err, storage.ErrPreconditionFailed) {
+		t.Fatalf("stale account version: expected ErrPreconditionFailed, got %v", err)
+	}
+	upd.Version = 0
+	if ua, _, err := m.UpdateAccount(ctx, a.ID, upd); err != nil || ua.Version != 3 {
+		t.Fatalf("unconditional UpdateAccount = %+v, %v; want version 3", ua, err)
+	}
+	if _, ok, err := m.UpdateAccount(ctx, 9999, domain.Account{Name: "x", Version: 1}); ok || err != nil {
+		t.Errorf("missing account: ok=%v err=%v, want not found", ok, err)
+	}
+}
@@ -0,0 +1,296 @@
+//go:build sqlite
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+var _ storage.WebhookStore = (*Store)(nil)
+
+const webhookColumns = `id, name, url, secret, events, enabled, created_at, updated_at`
+
+const webhookDeliveryColumns = `id, webhook_id, event_id, event_type, payload, status, attempts,
+	response_status, last_error, next_attempt_at, delivered_at, created_at, updated_at`
+
+// CreateWebhook stores a new webhook.
+func (s *Store) CreateWebhook(ctx context.Context, w domain.Webhook) error {
+	events, err := json.Marshal(webhookEvents(w.Events))
+	if err != nil {
+		return err
+	}
+	_, err = s.q().ExecContext(ctx,
+		`INSERT INTO webhooks (`+webhookColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		w.ID, w.Name, w.URL, w.Secret, string(events), boolToInt(w.Enabled),
+		w.CreatedAt.UTC().Format(time.RFC3339), w.UpdatedAt.UTC().Format(time.RFC3339),
+	)
+	return storage.WrapIfConflict(err)
+}
+
+// GetWebhook returns a webhook by ID, including its secret.
+func (s *Store) GetWebhook(ctx context.Context, id string) (*domain.Webhook, error) {
+	row := s.q().QueryRowContext(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE id = ?`, id)
+	w, err := scanWebhook(row)
+	if err == sql.ErrNoRows {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// ListWebhooks returns all webhooks ordered by name.
+func (s *Store) ListWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	rows, err := s.q().QueryContext(ctx, `SELECT `+webhookColumns+` FROM webhooks ORDER BY name, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []domain.Webhook{}
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, w)
+	}
+	return out, rows.Err()
+}
+
+// UpdateWebhook replaces a webhook's mutable fields.
+func (s *Store) UpdateWebhook(ctx context.Context, w domain.Webhook) error {
+	events, err := json.Marshal(webhookEvents(w.Events))
+	if err != nil {
+		return err
+	}
+	res, err := s.q().ExecContext(ctx,
+		`UPDATE webhooks SET name = ?, url = ?, secret = ?, events = ?, enabled = ?, updated_at = ? WHERE id = ?`,
+		w.Name, w.URL, w.Secret, string(events), boolToInt(w.Enabled),
+		w.UpdatedAt.UTC().Format(time.RFC3339), w.ID,
+	)
+	if err != nil {
+		return err
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteWebhook removes a webhook; its deliveries go with it via ON DELETE CASCADE.
+func (s *Store) DeleteWebhook(ctx context.Context, id string) error {
+	res, err := s.q().ExecContext(ctx, `DELETE FROM webhooks WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// CreateWebhookDelivery records a new delivery.
+func (s *Store) CreateWebhookDelivery(ctx context.Context, d domain.WebhookDelivery) error {
+	_, err := s.q().ExecContext(ctx,
+		`INSERT INTO webhook_deliveries (`+webhookDeliveryColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		d.ID, d.WebhookID, d.EventID, string(d.EventType), d.Payload, string(d.Status), d.Attempts,
+		nilIfZero(d.ResponseStatus), nilIfEmpty(d.LastError), formatTimePtr(d.NextAttemptAt), formatTimePtr(d.DeliveredAt),
+		d.CreatedAt.UTC().Format(time.RFC3339), d.UpdatedAt.UTC().Format(time.RFC3339),
+	)
+	if err != nil && strings.Contains(err.Error(), "FOREIGN KEY") {
+		return fmt.Errorf("webhook %s: %w", d.WebhookID, storage.ErrNotFound)
+	}
+	return storage.WrapIfConflict(err)
+}
+
+// GetWebhookDelivery returns a delivery by ID.
+func (s *Store) GetWebhookDelivery(ctx context.Context, id string) (*domain.WebhookDelivery, error) {
+	row := s.q().QueryRowContext(ctx, `SELECT `+webhookDeliveryColumns+` FROM webhook_deliveries WHERE id = ?`, id)
+	d, err := scanWebhookDelivery(row)
+	if err == sql.ErrNoRows {
+		return nil, storage.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// UpdateWebhookDelivery records the outcome of a delivery attempt.
+func (s *Store) UpdateWebhookDelivery(ctx context.Context, d domain.WebhookDelivery) error {
+	res, err := s.q().ExecContext(ctx,
+		`UPDATE webhook_deliveries
+		 SET status = ?, attempts = ?, response_status = ?, last_error = ?, next_attempt_at = ?, delivered_at = ?, updated_at = ?
+		 WHERE id = ?`,
+		string(d.Status), d.Attempts, nilIfZero(d.ResponseStatus), nilIfEmpty(d.LastError),
+		formatTimePtr(d.NextAttemptAt), formatTimePtr(d.DeliveredAt), d.UpdatedAt.UTC().Format(time.RFC3339), d.ID,
+	)
+	if err != nil {
+		return err
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// ListWebhookDeliveries returns paginated deliveries, newest first.
+func (s *Store) ListWebhookDeliveries(ctx context.Context, filters domain.WebhookDeliveryFilters) ([]domain.WebhookDelivery, int, error) {
+	var where []string
+	var args []any
+	if filters.WebhookID != "" {
+		where = append(where, "webhook_id = ?")
+		args = append(args, filters.WebhookID)
+	}
+	if filters.Status != "" {
+		where = append(where, "status = ?")
+		args = append(args, filters.Status)
+	}
+	if filters.EventType != "" {
+		where = append(where, "event_type = ?")
+		args = append(args, filters.EventType)
+	}
+	whereClause := ""
+	if len(where) > 0 {
+		whereClause = " WHERE " + strings.Join(where, " AND ")
+	}
+
+	var total int
+	if err := s.q().QueryRowContext(ctx, "SELECT COUNT(*) FROM webhook_deliveries"+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	page := filters.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := filters.PageSize
+	if pageSize < 1 {
+		pageSize = 50
+	}
+	args = append(args, pageSize, (page-1)*pageSize)
+
+	rows, err := s.q().QueryContext(ctx,
+		`SELECT `+webhookDeliveryColumns+` FROM webhook_deliveries`+whereClause+` ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?`,
+		args...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	out := []domain.WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		out = append(out, d)
+	}
+	return out, total, rows.Err()
+}
+
+// ListDueWebhookDeliveries returns pending deliveries due at or before now.
+func (s *Store) ListDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries
+		WHERE status = ? AND next_attempt_at IS NOT NULL AND next_attempt_at <= ?
+		ORDER BY next_attempt_at, id`
+	args := []any{string(domain.WebhookDeliveryPending), now.UTC().Format(time.RFC3339)}
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+	rows, err := s.q().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []domain.WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+// webhookEvents normalises a nil filter to an empty JSON array.
+func webhookEvents(events []domain.WebhookEventType) []domain.WebhookEventType {
+	if events == nil {
+		return []domain.WebhookEventType{}
+	}
+	return events
+}
+
+func scanWebhook(row interface{ Scan(dest ...any) error }) (domain.Webhook, error) {
+	var w domain.Webhook
+	var eventsJSON, createdAt, updatedAt string
+	var enabled int
+	if err := row.Scan(&w.ID, &w.Name, &w.URL, &w.Secret, &eventsJSON, &enabled, &createdAt, &updatedAt); err != nil {
+		return w, err
+	}
+	w.Enabled = enabled == 1
+	w.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	w.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+	w.Events = []domain.WebhookEventType{}
+	_ = json.Unmarshal([]byte(eventsJSON), &w.Events)
+	return w, nil
+}
+
+func scanWebhookDelivery(row interface{ Scan(dest ...any) error }) (domain.WebhookDelivery, error) {
+	var d domain.WebhookDelivery
+	var eventType, status, createdAt, updatedAt string
+	var responseStatus sql.NullInt64
+	var lastError, nextAttemptAt, deliveredAt sql.NullString
+	if err := row.Scan(&d.ID, &d.WebhookID, &d.EventID, &eventType, &d.Payload, &status, &d.Attempts,
+		&responseStatus, &lastError, &nextAttemptAt, &deliveredAt, &createdAt, &updatedAt); err != nil {
+		return d, err
+	}
+	d.EventType = domain.WebhookEventType(eventType)
+	d.Status = domain.WebhookDeliveryStatus(status)
+	d.ResponseStatus = int(responseStatus.Int64)
+	d.LastError = lastError.String
+	d.NextAttemptAt = parseTimePtr(nextAttemptAt)
+	d.DeliveredAt = parseTimePtr(deliveredAt)
+	d.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	d.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt)
+	return d, nil
+}
+
+func nilIfZero(n int) any {
+	if n == 0 {
+		return nil
+	}
+	return n
+}
+
+func formatTimePtr(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func parseTimePtr(v sql.NullString) *time.Time {
+	if !v.Valid || v.String == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, v.String)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
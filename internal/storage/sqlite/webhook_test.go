@@ -0,0 +1,99 @@
+//go:build sqlite
+
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+func TestWebhookStore(t *testing.T) {
+	s, err := New("file:" + filepath.Join(t.TempDir(), "webhook.db"))
+	if err != nil {
+		t.Fatalf("new sqlite store: %v", err)
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	hook := domain.Webhook{
+		ID: "w1", Name: "ops", URL: "https://hooks.example.com/ipam", Secret: "s3cret",
+		Events:  []domain.WebhookEventType{domain.WebhookEventPoolCreated, domain.WebhookEventDriftDetected},
+		Enabled: true, CreatedAt: now, UpdatedAt: now,
+	}
+	if err := s.CreateWebhook(ctx, hook); err != nil {
+		t.Fatalf("CreateWebhook: %v", err)
+	}
+	got, err := s.GetWebhook(ctx, "w1")
+	if err != nil {
+		t.Fatalf("GetWebhook: %v", err)
+	}
+	if got.Secret != "s3cret" || !got.Enabled || len(got.Events) != 2 || !got.CreatedAt.Equal(now) {
+		t.Fatalf("GetWebhook = %+v", got)
+	}
+
+	hook.Events = nil
+	hook.Enabled = false
+	if err := s.UpdateWebhook(ctx, hook); err != nil {
+		t.Fatalf("UpdateWebhook: %v", err)
+	}
+	list, err := s.ListWebhooks(ctx)
+	if err != nil || len(list) != 1 || list[0].Enabled || list[0].Events == nil || len(list[0].Events) != 0 {
+		t.Fatalf("ListWebhooks = %+v, %v", list, err)
+	}
+
+	next := now
+	d := domain.WebhookDelivery{
+		ID: "d1", WebhookID: "w1", EventID: "e1", EventType: domain.WebhookEventPoolCreated,
+		Payload: `{"type":"pool.created"}`, Status: domain.WebhookDeliveryPending,
+		NextAttemptAt: &next, CreatedAt: now, UpdatedAt: now,
+	}
+	if err := s.CreateWebhookDelivery(ctx, d); err != nil {
+		t.Fatalf("CreateWebhookDelivery: %v", err)
+	}
+	orphan := d
+	orphan.ID, orphan.WebhookID = "d2", "missing"
+	if err := s.CreateWebhookDelivery(ctx, orphan); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("delivery for unknown webhook: expected ErrNotFound, got %v", err)
+	}
+
+	due, err := s.ListDueWebhookDeliveries(ctx, now.Add(-time.Second), 10)
+	if err != nil || len(due) != 0 {
+		t.Fatalf("nothing should be due yet: %v, %v", due, err)
+	}
+	due, err = s.ListDueWebhookDeliveries(ctx, now, 10)
+	if err != nil || len(due) != 1 || due[0].ID != "d1" {
+		t.Fatalf("ListDueWebhookDeliveries = %v, %v", due, err)
+	}
+
+	delivered := now.Add(time.Minute)
+	d.Status = domain.WebhookDeliverySucceeded
+	d.Attempts = 2
+	d.ResponseStatus = 204
+	d.NextAttemptAt = nil
+	d.DeliveredAt = &delivered
+	d.UpdatedAt = delivered
+	if err := s.UpdateWebhookDelivery(ctx, d); err != nil {
+		t.Fatalf("UpdateWebhookDelivery: %v", err)
+	}
+	items, total, err := s.ListWebhookDeliveries(ctx, domain.WebhookDeliveryFilters{WebhookID: "w1", Status: string(domain.WebhookDeliverySucceeded)})
+	if err != nil || total != 1 || len(items) != 1 {
+		t.Fatalf("ListWebhookDeliveries = %v (total %d), %v", items, total, err)
+	}
+	if gd := items[0]; gd.ResponseStatus != 204 || gd.Attempts != 2 || gd.NextAttemptAt != nil || gd.DeliveredAt == nil || !gd.DeliveredAt.Equal(delivered) {
+		t.Fatalf("delivery round-trip = %+v", gd)
+	}
+
+	if err := s.DeleteWebhook(ctx, "w1"); err != nil {
+		t.Fatalf("DeleteWebhook: %v", err)
+	}
+	if _, err := s.GetWebhookDelivery(ctx, "d1"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("deliveries should cascade with the webhook, got %v", err)
+	}
+}
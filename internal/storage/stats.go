@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"net/netip"
+
+	"cloudpam/internal/cidr"
+)
+
+// AddressUsage computes the address totals shared by every store's pool
+// stats. Sums are taken in 128-bit space so IPv6 pools are counted exactly
+// and only saturate (at math.MaxInt64) when narrowed for domain.PoolStats.
+// Children of the other address family, or with unparsable CIDRs, are
+// ignored.
+func AddressUsage(parent netip.Prefix, childCIDRs []string) (total, used, available int64, utilization float64) {
+	totalN := cidr.AddressCount(parent)
+	var usedN cidr.Uint128
+	for _, c := range childCIDRs {
+		cp, err := netip.ParsePrefix(c)
+		if err != nil || cp.Addr().Is4() != parent.Addr().Is4() {
+			continue
+		}
+		usedN = usedN.Add(cidr.AddressCount(cp))
+	}
+	if !totalN.IsZero() {
+		utilization = usedN.Float64() / totalN.Float64() * 100
+	}
+	var availN cidr.Uint128
+	if usedN.Cmp(totalN) < 0 {
+		availN = totalN.Sub(usedN)
+	}
+	return totalN.Int64(), usedN.Int64(), availN.Int64(), utilization
+}
+
+// HostCIDRs returns host prefixes (/32 or /128) for the addresses not already
+// covered by one of childCIDRs. Appending them to the child CIDRs lets
+// AddressUsage count recorded IP addresses without counting an address twice
+// when it also sits inside a child pool. Unparsable entries are skipped.
+func HostCIDRs(childCIDRs, addresses []string) []string {
+	if len(addresses) == 0 {
+		return nil
+	}
+	children := make([]netip.Prefix, 0, len(childCIDRs))
+	for _, c := range childCIDRs {
+		if cp, err := netip.ParsePrefix(c); err == nil {
+			children = append(children, cp.Masked())
+		}
+	}
+	var out []string
+	for _, a := range addresses {
+		addr, err := netip.ParseAddr(a)
+		if err != nil {
+			continue
+		}
+		covered := false
+		for _, cp := range children {
+			if cidr.PrefixContainsAddr(cp, addr) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			out = append(out, netip.PrefixFrom(addr, addr.BitLen()).String())
+		}
+	}
+	return out
+}
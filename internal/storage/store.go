@@ -6,6 +6,7 @@ import (
 	"net/netip"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"cloudpam/internal/cidr"
@@ -45,16 +46,34 @@ type Store interface {
 
 // MemoryStore is an in-memory implementation for quick start and tests.
 type MemoryStore struct {
+	*memoryState
+	// inTx marks a transaction's view of the store, whose writes do not wait
+	// for the transaction lock (see tx_memory.go).
+	inTx bool
+}
+
+// memoryState is the state shared by a MemoryStore and its transaction views.
+type memoryState struct {
 	mu    sync.RWMutex
 	pools map[int64]domain.Pool
 	next  int64
 	// accounts
 	accounts    map[int64]domain.Account
 	nextAccount int64
+	// txMu serializes memory transactions; participants are the stores that
+	// share mu and are snapshotted with it (see tx_memory.go).
+	txMu         sync.Mutex
+	participants []memoryTxParticipant
+	// cidrIdx caches the interval tree behind CIDROperations. It is built
+	// lazily and dropped whenever a pool is added (see cidr_ops_memory.go).
+	cidrIdx atomic.Pointer[cidr.Index]
+	// ipAddresses, when attached, contributes recorded host addresses to
+	// pool stats (see ipaddress_memory.go).
+	ipAddresses *MemoryIPAddressStore
 }
 
 func NewMemoryStore() *MemoryStore {
-	return &MemoryStore{pools: make(map[int64]domain.Pool), next: 1, accounts: make(map[int64]domain.Account), nextAccount: 1}
+	return &MemoryStore{memoryState: &memoryState{pools: make(map[int64]domain.Pool), next: 1, accounts: make(map[int64]domain.Account), nextAccount: 1}}
 }
 
 func (m *MemoryStore) ListPools(ctx context.Context) ([]domain.Pool, error) {
@@ -71,11 +90,16 @@ func (m *MemoryStore) ListPools(ctx context.Context) ([]domain.Pool, error) {
 }
 
 func (m *MemoryStore) CreatePool(ctx context.Context, in domain.CreatePool) (domain.Pool, error) {
+	m.lockWrite()
+	defer m.unlockWrite()
+	return m.createPoolLocked(in)
+}
+
+// createPoolLocked inserts a pool (must be called with the write lock held).
+func (m *MemoryStore) createPoolLocked(in domain.CreatePool) (domain.Pool, error) {
 	if in.Name == "" || in.CIDR == "" {
 		return domain.Pool{}, fmt.Errorf("name and cidr required: %w", ErrValidation)
 	}
-	m.mu.Lock()
-	defer m.mu.Unlock()
 	id := m.next
 	m.next++
 	now := time.Now().UTC()
@@ -114,10 +138,12 @@ func (m *MemoryStore) CreatePool(ctx context.Context, in domain.CreatePool) (dom
 		Source:      poolSource,
 		Description: in.Description,
 		Tags:        tags,
+		Version:     1,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
 	m.pools[id] = p
+	m.cidrIdx.Store(nil)
 	return clonePool(p), nil
 }
 
@@ -132,20 +158,21 @@ func (m *MemoryStore) GetPool(ctx context.Context, id int64) (domain.Pool, bool,
 }
 
 func (m *MemoryStore) UpdatePoolAccount(ctx context.Context, id int64, accountID *int64) (domain.Pool, bool, error) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	m.lockWrite()
+	defer m.unlockWrite()
 	p, ok := m.pools[id]
 	if !ok {
 		return domain.Pool{}, false, nil
 	}
 	p.AccountID = accountID
+	p.Version++
 	m.pools[id] = p
 	return clonePool(p), true, nil
 }
 
 func (m *MemoryStore) UpdatePoolMeta(ctx context.Context, id int64, name *string, accountID *int64) (domain.Pool, bool, error) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	m.lockWrite()
+	defer m.unlockWrite()
 	p, ok := m.pools[id]
 	if !ok {
 		return domain.Pool{}, false, nil
@@ -156,18 +183,22 @@ func (m *MemoryStore) UpdatePoolMeta(ctx context.Context, id int64, name *string
 	// Always set accountID (caller controls whether to clear or set)
 	p.AccountID = accountID
 	p.UpdatedAt = time.Now().UTC()
+	p.Version++
 	m.pools[id] = p
 	return clonePool(p), true, nil
 }
 
 // UpdatePool updates pool metadata with support for new fields.
 func (m *MemoryStore) UpdatePool(ctx context.Context, id int64, update domain.UpdatePool) (domain.Pool, bool, error) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	m.lockWrite()
+	defer m.unlockWrite()
 	p, ok := m.pools[id]
 	if !ok {
 		return domain.Pool{}, false, nil
 	}
+	if update.IfVersion != nil && *update.IfVersion != p.Version {
+		return domain.Pool{}, false, fmt.Errorf("pool %d is at version %d: %w", id, p.Version, ErrPreconditionFailed)
+	}
 	if update.Name != nil {
 		p.Name = *update.Name
 	}
@@ -194,13 +225,14 @@ func (m *MemoryStore) UpdatePool(ctx context.Context, id int64, update domain.Up
 		}
 	}
 	p.UpdatedAt = time.Now().UTC()
+	p.Version++
 	m.pools[id] = p
 	return clonePool(p), true, nil
 }
 
 func (m *MemoryStore) DeletePool(ctx context.Context, id int64) (bool, error) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	m.lockWrite()
+	defer m.unlockWrite()
 	p, ok := m.pools[id]
 	if !ok || p.DeletedAt != nil {
 		return false, nil
@@ -213,13 +245,14 @@ func (m *MemoryStore) DeletePool(ctx context.Context, id int64) (bool, error) {
 	now := time.Now().UTC()
 	p.DeletedAt = &now
 	p.UpdatedAt = now
+	p.Version++
 	m.pools[id] = p
 	return true, nil
 }
 
 func (m *MemoryStore) DeletePoolCascade(ctx context.Context, id int64) (bool, error) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	m.lockWrite()
+	defer m.unlockWrite()
 	p, ok := m.pools[id]
 	if !ok || p.DeletedAt != nil {
 		return false, nil
@@ -248,6 +281,7 @@ func (m *MemoryStore) DeletePoolCascade(ctx context.Context, id int64) (bool, er
 		pp := m.pools[pid]
 		pp.DeletedAt = &now
 		pp.UpdatedAt = now
+		pp.Version++
 		m.pools[pid] = pp
 	}
 	return true, nil
@@ -271,8 +305,8 @@ func (m *MemoryStore) CreateAccount(ctx context.Context, in domain.CreateAccount
 	if in.Key == "" || in.Name == "" {
 		return domain.Account{}, fmt.Errorf("key and name required: %w", ErrValidation)
 	}
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	m.lockWrite()
+	defer m.unlockWrite()
 	id := m.nextAccount
 	m.nextAccount++
 	now := time.Now().UTC()
@@ -287,6 +321,7 @@ func (m *MemoryStore) CreateAccount(ctx context.Context, in domain.CreateAccount
 		Tier:        in.Tier,
 		Environment: in.Environment,
 		Regions:     append([]string(nil), in.Regions...),
+		Version:     1,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
@@ -295,12 +330,15 @@ func (m *MemoryStore) CreateAccount(ctx context.Context, in domain.CreateAccount
 }
 
 func (m *MemoryStore) UpdateAccount(ctx context.Context, id int64, update domain.Account) (domain.Account, bool, error) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	m.lockWrite()
+	defer m.unlockWrite()
 	a, ok := m.accounts[id]
 	if !ok {
 		return domain.Account{}, false, nil
 	}
+	if update.Version != 0 && update.Version != a.Version {
+		return domain.Account{}, false, fmt.Errorf("account %d is at version %d: %w", id, a.Version, ErrPreconditionFailed)
+	}
 	if update.Name != "" {
 		a.Name = update.Name
 	}
@@ -315,13 +353,14 @@ func (m *MemoryStore) UpdateAccount(ctx context.Context, id int64, update domain
 		a.Regions = append([]string(nil), update.Regions...)
 	}
 	a.UpdatedAt = time.Now().UTC()
+	a.Version++
 	m.accounts[id] = a
 	return cloneAccount(a), true, nil
 }
 
 func (m *MemoryStore) DeleteAccount(ctx context.Context, id int64) (bool, error) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	m.lockWrite()
+	defer m.unlockWrite()
 	a, ok := m.accounts[id]
 	if !ok || a.DeletedAt != nil {
 		return false, nil
@@ -334,13 +373,14 @@ func (m *MemoryStore) DeleteAccount(ctx context.Context, id int64) (bool, error)
 	now := time.Now().UTC()
 	a.DeletedAt = &now
 	a.UpdatedAt = now
+	a.Version++
 	m.accounts[id] = a
 	return true, nil
 }
 
 func (m *MemoryStore) DeleteAccountCascade(ctx context.Context, id int64) (bool, error) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	m.lockWrite()
+	defer m.unlockWrite()
 	a, ok := m.accounts[id]
 	if !ok || a.DeletedAt != nil {
 		return false, nil
@@ -378,10 +418,12 @@ func (m *MemoryStore) DeleteAccountCascade(ctx context.Context, id int64) (bool,
 		pp := m.pools[pid]
 		pp.DeletedAt = &now
 		pp.UpdatedAt = now
+		pp.Version++
 		m.pools[pid] = pp
 	}
 	a.DeletedAt = &now
 	a.UpdatedAt = now
+	a.Version++
 	m.accounts[id] = a
 	return true, nil
 }
@@ -519,22 +561,9 @@ func (m *MemoryStore) calculatePoolStatsLocked(p domain.Pool) domain.PoolStats {
 		return domain.PoolStats{}
 	}
 
-	var totalIPs int64
-	if prefix.Addr().Is4() {
-		totalIPs = int64(1) << (32 - prefix.Bits())
-	} else {
-		// For IPv6, cap at max int64 for practical purposes
-		bits := 128 - prefix.Bits()
-		if bits >= 63 {
-			totalIPs = int64(1) << 62 // Cap to avoid overflow
-		} else {
-			totalIPs = int64(1) << bits
-		}
-	}
-
 	// Count direct children and calculate used IPs
 	var directChildren int
-	var usedIPs int64
+	var childCIDRs []string
 	var totalChildCount int
 
 	// Recursive function to count all descendants (skip soft-deleted)
@@ -559,30 +588,20 @@ func (m *MemoryStore) calculatePoolStatsLocked(p domain.Pool) domain.PoolStats {
 		}
 		if child.ParentID != nil && *child.ParentID == p.ID {
 			directChildren++
-
-			// Calculate used IPs from child's CIDR
-			childPrefix, err := netip.ParsePrefix(child.CIDR)
-			if err != nil {
-				continue
-			}
-			if childPrefix.Addr().Is4() {
-				usedIPs += int64(1) << (32 - childPrefix.Bits())
-			}
+			childCIDRs = append(childCIDRs, child.CIDR)
 		}
 	}
 
 	totalChildCount = countDescendants(p.ID)
-
-	// Calculate utilization percentage
-	var utilization float64
-	if totalIPs > 0 {
-		utilization = float64(usedIPs) / float64(totalIPs) * 100
+	if m.ipAddresses != nil {
+		childCIDRs = append(childCIDRs, HostCIDRs(childCIDRs, m.ipAddresses.poolAddressesLocked(p.ID))...)
 	}
+	totalIPs, usedIPs, availableIPs, utilization := AddressUsage(prefix, childCIDRs)
 
 	return domain.PoolStats{
 		TotalIPs:       totalIPs,
 		UsedIPs:        usedIPs,
-		AvailableIPs:   totalIPs - usedIPs,
+		AvailableIPs:   availableIPs,
 		Utilization:    utilization,
 		ChildCount:     totalChildCount,
 		DirectChildren: directChildren,
@@ -635,7 +654,7 @@ func (m *MemoryStore) Search(ctx context.Context, req domain.SearchRequest) (dom
 
 	// Search pools (skip soft-deleted)
 	if searchPools {
-		for _, p := range m.pools {
+		for _, p := range m.searchCandidatesLocked(hasCIDRContains, cidrContains, hasCIDRWithin, cidrWithin) {
 			if p.DeletedAt != nil {
 				continue
 			}
@@ -707,6 +726,32 @@ func (m *MemoryStore) Search(ctx context.Context, req domain.SearchRequest) (dom
 	}, nil
 }
 
+// searchCandidatesLocked narrows a search to the pools a CIDR filter can
+// match using the interval tree, or returns every pool when there is none.
+// matchPool still applies every filter to the candidates.
+func (m *MemoryStore) searchCandidatesLocked(hasCIDRContains bool, cidrContains netip.Prefix, hasCIDRWithin bool, cidrWithin netip.Prefix) []domain.Pool {
+	var hits []cidr.IndexEntry
+	switch {
+	case hasCIDRContains:
+		hits = m.cidrIndexLocked().Containing(cidrContains)
+	case hasCIDRWithin:
+		hits = m.cidrIndexLocked().ContainedBy(cidrWithin)
+	default:
+		out := make([]domain.Pool, 0, len(m.pools))
+		for _, p := range m.pools {
+			out = append(out, p)
+		}
+		return out
+	}
+	out := make([]domain.Pool, 0, len(hits))
+	for _, h := range hits {
+		if p, ok := m.pools[h.ID]; ok {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
 func (m *MemoryStore) matchPool(p domain.Pool, query string, hasCIDRContains bool, cidrContains netip.Prefix, hasCIDRWithin bool, cidrWithin netip.Prefix) bool {
 	// CIDR containment: find pools whose CIDR contains the given IP/prefix
 	if hasCIDRContains {
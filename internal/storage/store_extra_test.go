@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"errors"
+	"math"
 	"testing"
 
 	"cloudpam/internal/domain"
@@ -69,9 +70,9 @@ func TestMemoryStoreExtra_SearchInvalidCIDRFilters(t *testing.T) {
 		req  domain.SearchRequest
 	}{
 		{"unparsable cidr_contains", domain.SearchRequest{CIDRContains: "not-a-cidr"}},
-		{"ipv6 cidr_contains", domain.SearchRequest{CIDRContains: "2001:db8::/32"}},
+		{"zoned ipv6 cidr_contains", domain.SearchRequest{CIDRContains: "fe80::1%eth0"}},
 		{"unparsable cidr_within", domain.SearchRequest{CIDRWithin: "10.0.0.0/99"}},
-		{"ipv6 cidr_within", domain.SearchRequest{CIDRWithin: "::1"}},
+		{"ipv4-mapped cidr_within", domain.SearchRequest{CIDRWithin: "::ffff:10.0.0.0/104"}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -478,7 +479,7 @@ func TestMemoryStoreExtra_PoolStatsEdgeCases(t *testing.T) {
 		}
 	})
 
-	t.Run("ipv6 pool sizes are capped", func(t *testing.T) {
+	t.Run("ipv6 pool sizes saturate", func(t *testing.T) {
 		s := NewMemoryStore()
 		huge, err := s.CreatePool(ctx, domain.CreatePool{Name: "v6-huge", CIDR: "2001:db8::/32"})
 		if err != nil {
@@ -488,8 +489,11 @@ func TestMemoryStoreExtra_PoolStatsEdgeCases(t *testing.T) {
 		if err != nil {
 			t.Fatalf("CalculatePoolUtilization: %v", err)
 		}
-		if stats.TotalIPs != int64(1)<<62 {
-			t.Errorf("TotalIPs = %d, want the 1<<62 cap", stats.TotalIPs)
+		if stats.TotalIPs != math.MaxInt64 {
+			t.Errorf("TotalIPs = %d, want math.MaxInt64", stats.TotalIPs)
+		}
+		if stats.Utilization != 0 {
+			t.Errorf("Utilization = %v, want 0 for an empty pool", stats.Utilization)
 		}
 
 		small, err := s.CreatePool(ctx, domain.CreatePool{Name: "v6-small", CIDR: "2001:db8::/100"})
@@ -538,4 +542,66 @@ func TestMemoryStoreExtra_PoolStatsEdgeCases(t *testing.T) {
 			t.Errorf("AvailableIPs = %d, want %d", stats.AvailableIPs, 65536-256)
 		}
 	})
+
+	t.Run("ipv6 children count toward utilization", func(t *testing.T) {
+		s := NewMemoryStore()
+		parent, err := s.CreatePool(ctx, domain.CreatePool{Name: "v6-parent", CIDR: "2001:db8::/62"})
+		if err != nil {
+			t.Fatalf("CreatePool: %v", err)
+		}
+		if _, err := s.CreatePool(ctx, domain.CreatePool{Name: "v6-child", CIDR: "2001:db8::/64", ParentID: &parent.ID}); err != nil {
+			t.Fatalf("CreatePool(child): %v", err)
+		}
+		stats, err := s.CalculatePoolUtilization(ctx, parent.ID)
+		if err != nil {
+			t.Fatalf("CalculatePoolUtilization: %v", err)
+		}
+		// A /64 is 2^64 addresses, which saturates int64, but the ratio is
+		// computed before narrowing.
+		if stats.Utilization != 25 {
+			t.Errorf("Utilization = %v, want 25", stats.Utilization)
+		}
+		if stats.UsedIPs != math.MaxInt64 {
+			t.Errorf("UsedIPs = %d, want math.MaxInt64", stats.UsedIPs)
+		}
+	})
+}
+
+func TestMemoryStoreExtra_SearchIPv6(t *testing.T) {
+	ctx := context.Background()
+	s := seedSearchStoreExtra(t)
+	v6, err := s.CreatePool(ctx, domain.CreatePool{Name: "V6 Supernet", CIDR: "2001:db8::/32"})
+	if err != nil {
+		t.Fatalf("CreatePool(v6): %v", err)
+	}
+	if _, err := s.CreatePool(ctx, domain.CreatePool{Name: "V6 VPC", CIDR: "2001:db8:1::/48", ParentID: &v6.ID}); err != nil {
+		t.Fatalf("CreatePool(v6 vpc): %v", err)
+	}
+
+	tests := []struct {
+		name string
+		req  domain.SearchRequest
+		want []string
+	}{
+		{"contains bare address", domain.SearchRequest{CIDRContains: "2001:db8:1::5", Types: []string{"pool"}}, []string{"pool:V6 Supernet", "pool:V6 VPC"}},
+		{"within /32", domain.SearchRequest{CIDRWithin: "2001:db8::/32"}, []string{"pool:V6 Supernet", "pool:V6 VPC"}},
+		{"ipv4 filter ignores ipv6 pools", domain.SearchRequest{CIDRWithin: "0.0.0.0/0"}, []string{"pool:Prod Supernet", "pool:Prod VPC", "pool:Dev VPC"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := s.Search(ctx, tt.req)
+			if err != nil {
+				t.Fatalf("Search: %v", err)
+			}
+			got := searchNamesExtra(resp.Items)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for _, w := range tt.want {
+				if !got[w] {
+					t.Errorf("missing %s in %v", w, got)
+				}
+			}
+		})
+	}
 }
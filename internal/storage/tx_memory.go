@@ -0,0 +1,243 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"maps"
+
+	"cloudpam/internal/domain"
+)
+
+var (
+	_ TransactionalStore = (*MemoryStore)(nil)
+	_ Transaction        = (*memoryTx)(nil)
+	_ TxBinder           = (*memoryTx)(nil)
+)
+
+// errMemoryTxDone is returned by Commit on a finished memory transaction.
+var errMemoryTxDone = errors.New("transaction already committed or rolled back")
+
+// memoryTxParticipant is implemented by in-memory stores that share a
+// MemoryStore's lock, so a rollback restores their state along with pools
+// and accounts.
+type memoryTxParticipant interface {
+	// snapshotLocked copies the store's state and returns a func that puts it
+	// back. Both run with the shared write lock held.
+	snapshotLocked() (restore func())
+	// bind returns a copy of the store that writes through view, or false if
+	// the store belongs to a different MemoryStore.
+	bind(view *MemoryStore) (any, bool)
+}
+
+// join registers p to be snapshotted by memory transactions.
+func (m *MemoryStore) join(p memoryTxParticipant) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.participants = append(m.participants, p)
+}
+
+// lockWrite takes the write lock. Writes outside a transaction first wait for
+// the running one to finish, so its rollback cannot undo them.
+func (m *MemoryStore) lockWrite() {
+	if !m.inTx {
+		m.txMu.Lock()
+	}
+	m.mu.Lock()
+}
+
+func (m *MemoryStore) unlockWrite() {
+	m.mu.Unlock()
+	if !m.inTx {
+		m.txMu.Unlock()
+	}
+}
+
+// memoryTx is a MemoryStore transaction. Its writes go straight to the shared
+// maps through a view of the store, so the transaction reads its own writes
+// and other readers see them too; Rollback restores the state captured at
+// BeginTx. Transactions are serialized against each other and against plain
+// writes, which block until the transaction ends. Companion stores join the
+// transaction through Bind; writing through the plain store instead would
+// deadlock.
+type memoryTx struct {
+	*MemoryStore
+	restore []func()
+	release func()
+	done    bool
+}
+
+// BeginTx snapshots the store and every participant sharing its lock.
+func (m *MemoryStore) BeginTx(_ context.Context) (Transaction, error) {
+	m.txMu.Lock()
+	return m.beginLocked(m.txMu.Unlock), nil
+}
+
+func (m *MemoryStore) beginLocked(release func()) *memoryTx {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	restore := []func(){m.snapshotLocked()}
+	for _, p := range m.participants {
+		restore = append(restore, p.snapshotLocked())
+	}
+	view := &MemoryStore{memoryState: m.memoryState, inTx: true}
+	return &memoryTx{MemoryStore: view, restore: restore, release: release}
+}
+
+// WithTx runs fn in a transaction, committing if fn returns nil and rolling
+// back otherwise.
+func (m *MemoryStore) WithTx(ctx context.Context, fn func(tx Transaction) error) error {
+	tx, err := m.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	return runMemoryTx(tx, fn)
+}
+
+func runMemoryTx(tx Transaction, fn func(tx Transaction) error) error {
+	defer func() { _ = tx.Rollback() }()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (m *MemoryStore) snapshotLocked() func() {
+	pools := copyMap(m.pools, clonePool)
+	accounts := copyMap(m.accounts, cloneAccount)
+	// IDs are not reused after a rollback, matching database sequences.
+	return func() {
+		m.pools = pools
+		m.accounts = accounts
+		m.cidrIdx.Store(nil)
+	}
+}
+
+// Bind returns the transaction's view of a companion store that shares the
+// memory store's lock, such as a MemoryDiscoveryStore, or nil for any other
+// store.
+func (t *memoryTx) Bind(store any) any {
+	if p, ok := store.(memoryTxParticipant); ok {
+		if v, ok := p.bind(t.MemoryStore); ok {
+			return v
+		}
+	}
+	return nil
+}
+
+// BeginTx on a transaction takes a nested snapshot, like a savepoint.
+func (t *memoryTx) BeginTx(_ context.Context) (Transaction, error) {
+	return t.beginLocked(nil), nil
+}
+
+// WithTx runs fn in a nested transaction.
+func (t *memoryTx) WithTx(ctx context.Context, fn func(tx Transaction) error) error {
+	tx, err := t.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	return runMemoryTx(tx, fn)
+}
+
+func (t *memoryTx) Commit() error {
+	if t.done {
+		return errMemoryTxDone
+	}
+	t.finish()
+	return nil
+}
+
+func (t *memoryTx) Rollback() error {
+	if t.done {
+		return nil
+	}
+	t.mu.Lock()
+	for _, restore := range t.restore {
+		restore()
+	}
+	t.mu.Unlock()
+	t.finish()
+	return nil
+}
+
+func (t *memoryTx) finish() {
+	t.done = true
+	if t.release != nil {
+		t.release()
+	}
+}
+
+// Close rolls the transaction back.
+func (t *memoryTx) Close() error {
+	return t.Rollback()
+}
+
+func copyMap[K comparable, V any](in map[K]V, clone func(V) V) map[K]V {
+	out := make(map[K]V, len(in))
+	for k, v := range in {
+		out[k] = clone(v)
+	}
+	return out
+}
+
+// restoreMap puts a snapshot back in place, so views of the store keep
+// sharing the live map.
+func restoreMap[K comparable, V any](dst, snapshot map[K]V) {
+	clear(dst)
+	maps.Copy(dst, snapshot)
+}
+
+func identity[V any](v V) V { return v }
+
+func (m *MemoryDiscoveryStore) snapshotLocked() func() {
+	resources := copyMap(m.resources, cloneDiscoveredResource)
+	syncJobs := copyMap(m.syncJobs, identity[domain.SyncJob])
+	agents := copyMap(m.agents, identity[domain.DiscoveryAgent])
+	return func() {
+		restoreMap(m.resources, resources)
+		restoreMap(m.syncJobs, syncJobs)
+		restoreMap(m.agents, agents)
+	}
+}
+
+func (m *MemoryDiscoveryStore) bind(view *MemoryStore) (any, bool) {
+	v := *m
+	v.store = view
+	return &v, m.store.memoryState == view.memoryState
+}
+
+func (m *MemoryDriftStore) snapshotLocked() func() {
+	drifts := copyMap(m.drifts, cloneDriftItem)
+	return func() { restoreMap(m.drifts, drifts) }
+}
+
+func (m *MemoryDriftStore) bind(view *MemoryStore) (any, bool) {
+	v := *m
+	v.store = view
+	return &v, m.store.memoryState == view.memoryState
+}
+
+func (m *MemoryNetworkStore) snapshotLocked() func() {
+	objects := copyMap(m.objects, cloneNetworkObject)
+	relationships := copyMap(m.relationships, cloneNetworkRelationship)
+	return func() {
+		restoreMap(m.objects, objects)
+		restoreMap(m.relationships, relationships)
+	}
+}
+
+func (m *MemoryNetworkStore) bind(view *MemoryStore) (any, bool) {
+	v := *m
+	v.store = view
+	return &v, m.store.memoryState == view.memoryState
+}
+
+func (m *MemoryIPAddressStore) snapshotLocked() func() {
+	addresses := copyMap(m.addresses, cloneIPAddress)
+	return func() { restoreMap(m.addresses, addresses) }
+}
+
+func (m *MemoryIPAddressStore) bind(view *MemoryStore) (any, bool) {
+	v := *m
+	v.store = view
+	return &v, m.store.memoryState == view.memoryState
+}
@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"cloudpam/internal/domain"
+)
+
+func TestMemoryStore_WithTxRollsBackPoolsAndLinks(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryStore()
+	ds := NewMemoryDiscoveryStore(m)
+	keep, err := m.CreatePool(ctx, domain.CreatePool{Name: "keep", CIDR: "10.0.0.0/16"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resID := uuid.New()
+	if err := ds.UpsertDiscoveredResource(ctx, domain.DiscoveredResource{ID: resID, AccountID: 1, ResourceType: domain.ResourceTypeVPC, ResourceID: "vpc-1", CIDR: "10.1.0.0/16", Status: domain.DiscoveryStatusActive, DiscoveredAt: time.Now(), LastSeenAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	boom := errors.New("boom")
+	err = m.WithTx(ctx, func(tx Transaction) error {
+		p, err := tx.CreatePool(ctx, domain.CreatePool{Name: "new", CIDR: "10.1.0.0/16"})
+		if err != nil {
+			return err
+		}
+		txds, ok := tx.(TxBinder).Bind(ds).(*MemoryDiscoveryStore)
+		if !ok {
+			t.Fatal("memory transaction should bind the discovery store")
+		}
+		if err := txds.LinkResourceToPool(ctx, resID, p.ID); err != nil {
+			return err
+		}
+		renamed := "renamed"
+		if _, _, err := tx.UpdatePoolMeta(ctx, keep.ID, &renamed, nil); err != nil {
+			return err
+		}
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("WithTx error = %v, want %v", err, boom)
+	}
+
+	pools, _ := m.ListPools(ctx)
+	if len(pools) != 1 || pools[0].Name != "keep" {
+		t.Fatalf("rollback should restore pools, got %+v", pools)
+	}
+	res, err := ds.GetDiscoveredResource(ctx, resID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.PoolID != nil {
+		t.Fatalf("rollback should restore the discovery link, got pool %d", *res.PoolID)
+	}
+
+	// IDs handed out inside the rolled-back transaction are not reused.
+	next, _ := m.CreatePool(ctx, domain.CreatePool{Name: "after", CIDR: "10.2.0.0/16"})
+	if next.ID <= keep.ID+1 {
+		t.Fatalf("expected id past the rolled-back pool, got %d", next.ID)
+	}
+}
+
+func TestMemoryStore_TxCommitAndNested(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryStore()
+
+	tx, err := m.BeginTx(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.CreatePool(ctx, domain.CreatePool{Name: "outer", CIDR: "10.0.0.0/16"}); err != nil {
+		t.Fatal(err)
+	}
+	// A nested transaction behaves like a savepoint.
+	inner, ok := tx.(TransactionalStore)
+	if !ok {
+		t.Fatal("memory transaction should support nested transactions")
+	}
+	_ = inner.WithTx(ctx, func(sp Transaction) error {
+		_, _ = sp.CreatePool(ctx, domain.CreatePool{Name: "inner", CIDR: "10.1.0.0/16"})
+		return errors.New("undo inner")
+	})
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("rollback after commit should be a no-op, got %v", err)
+	}
+	if err := tx.Commit(); err == nil {
+		t.Fatal("second commit should fail")
+	}
+
+	pools, _ := m.ListPools(ctx)
+	if len(pools) != 1 || pools[0].Name != "outer" {
+		t.Fatalf("expected only the outer pool, got %+v", pools)
+	}
+
+	// The transaction lock is released, so another one can start.
+	if err := m.WithTx(ctx, func(Transaction) error { return nil }); err != nil {
+		t.Fatalf("second transaction: %v", err)
+	}
+}
+
+func TestMemoryStore_RollbackKeepsConcurrentWrites(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemoryStore()
+	ds := NewMemoryDiscoveryStore(m)
+	tx, err := m.BeginTx(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tx.(TxBinder).Bind(NewMemoryDiscoveryStore(NewMemoryStore())); got != nil {
+		t.Fatalf("Bind of another store's companion = %T, want nil", got)
+	}
+	if _, err := tx.CreatePool(ctx, domain.CreatePool{Name: "tx", CIDR: "10.0.0.0/16"}); err != nil {
+		t.Fatal(err)
+	}
+	// Plain writes wait for the transaction instead of being undone by it.
+	resID := uuid.New()
+	done := make(chan error, 2)
+	go func() {
+		_, err := m.CreatePool(ctx, domain.CreatePool{Name: "plain", CIDR: "10.1.0.0/16"})
+		done <- err
+	}()
+	go func() {
+		done <- ds.UpsertDiscoveredResource(ctx, domain.DiscoveredResource{ID: resID, AccountID: 1, ResourceType: domain.ResourceTypeVPC, ResourceID: "vpc-1", CIDR: "10.1.0.0/16", Status: domain.DiscoveryStatusActive, DiscoveredAt: time.Now(), LastSeenAt: time.Now()})
+	}()
+	select {
+	case err := <-done:
+		t.Fatalf("plain write finished during the transaction: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if err := <-done; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pools, _ := m.ListPools(ctx)
+	if len(pools) != 1 || pools[0].Name != "plain" {
+		t.Fatalf("expected only the plain pool after rollback, got %+v", pools)
+	}
+	if _, err := ds.GetDiscoveredResource(ctx, resID); err != nil {
+		t.Fatalf("plain discovery write should survive the rollback: %v", err)
+	}
+}
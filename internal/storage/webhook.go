@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"cloudpam/internal/domain"
+)
+
+// WebhookStore persists webhook endpoints and their delivery log.
+type WebhookStore interface {
+	// CreateWebhook stores a new webhook.
+	CreateWebhook(ctx context.Context, w domain.Webhook) error
+
+	// GetWebhook returns a webhook by ID, including its secret.
+	GetWebhook(ctx context.Context, id string) (*domain.Webhook, error)
+
+	// ListWebhooks returns all webhooks ordered by name.
+	ListWebhooks(ctx context.Context) ([]domain.Webhook, error)
+
+	// UpdateWebhook replaces a webhook's mutable fields.
+	UpdateWebhook(ctx context.Context, w domain.Webhook) error
+
+	// DeleteWebhook removes a webhook and its delivery log.
+	DeleteWebhook(ctx context.Context, id string) error
+
+	// CreateWebhookDelivery records a new delivery.
+	CreateWebhookDelivery(ctx context.Context, d domain.WebhookDelivery) error
+
+	// GetWebhookDelivery returns a delivery by ID.
+	GetWebhookDelivery(ctx context.Context, id string) (*domain.WebhookDelivery, error)
+
+	// UpdateWebhookDelivery records the outcome of a delivery attempt.
+	UpdateWebhookDelivery(ctx context.Context, d domain.WebhookDelivery) error
+
+	// ListWebhookDeliveries returns paginated deliveries, newest first.
+	ListWebhookDeliveries(ctx context.Context, filters domain.WebhookDeliveryFilters) ([]domain.WebhookDelivery, int, error)
+
+	// ListDueWebhookDeliveries returns up to limit pending deliveries whose
+	// next attempt is at or before now, oldest first.
+	ListDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]domain.WebhookDelivery, error)
+}
@@ -0,0 +1,219 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"cloudpam/internal/domain"
+)
+
+// MemoryWebhookStore is an in-memory implementation of WebhookStore.
+type MemoryWebhookStore struct {
+	mu         sync.RWMutex
+	webhooks   map[string]domain.Webhook
+	deliveries map[string]domain.WebhookDelivery
+}
+
+// NewMemoryWebhookStore creates a new in-memory webhook store.
+func NewMemoryWebhookStore() *MemoryWebhookStore {
+	return &MemoryWebhookStore{
+		webhooks:   make(map[string]domain.Webhook),
+		deliveries: make(map[string]domain.WebhookDelivery),
+	}
+}
+
+func (s *MemoryWebhookStore) CreateWebhook(_ context.Context, w domain.Webhook) error {
+	if w.ID == "" {
+		return ErrValidation
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.webhooks[w.ID]; exists {
+		return ErrConflict
+	}
+	s.webhooks[w.ID] = cloneWebhook(w)
+	return nil
+}
+
+func (s *MemoryWebhookStore) GetWebhook(_ context.Context, id string) (*domain.Webhook, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	w, ok := s.webhooks[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	out := cloneWebhook(w)
+	return &out, nil
+}
+
+func (s *MemoryWebhookStore) ListWebhooks(_ context.Context) ([]domain.Webhook, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]domain.Webhook, 0, len(s.webhooks))
+	for _, w := range s.webhooks {
+		out = append(out, cloneWebhook(w))
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Name != out[j].Name {
+			return out[i].Name < out[j].Name
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+func (s *MemoryWebhookStore) UpdateWebhook(_ context.Context, w domain.Webhook) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, ok := s.webhooks[w.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	w.CreatedAt = existing.CreatedAt
+	s.webhooks[w.ID] = cloneWebhook(w)
+	return nil
+}
+
+func (s *MemoryWebhookStore) DeleteWebhook(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.webhooks[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.webhooks, id)
+	for did, d := range s.deliveries {
+		if d.WebhookID == id {
+			delete(s.deliveries, did)
+		}
+	}
+	return nil
+}
+
+func (s *MemoryWebhookStore) CreateWebhookDelivery(_ context.Context, d domain.WebhookDelivery) error {
+	if d.ID == "" {
+		return ErrValidation
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.webhooks[d.WebhookID]; !ok {
+		return ErrNotFound
+	}
+	if _, exists := s.deliveries[d.ID]; exists {
+		return ErrConflict
+	}
+	s.deliveries[d.ID] = cloneWebhookDelivery(d)
+	return nil
+}
+
+func (s *MemoryWebhookStore) GetWebhookDelivery(_ context.Context, id string) (*domain.WebhookDelivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	d, ok := s.deliveries[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	out := cloneWebhookDelivery(d)
+	return &out, nil
+}
+
+func (s *MemoryWebhookStore) UpdateWebhookDelivery(_ context.Context, d domain.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.deliveries[d.ID]; !ok {
+		return ErrNotFound
+	}
+	s.deliveries[d.ID] = cloneWebhookDelivery(d)
+	return nil
+}
+
+func (s *MemoryWebhookStore) ListWebhookDeliveries(_ context.Context, filters domain.WebhookDeliveryFilters) ([]domain.WebhookDelivery, int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var filtered []domain.WebhookDelivery
+	for _, d := range s.deliveries {
+		if filters.WebhookID != "" && d.WebhookID != filters.WebhookID {
+			continue
+		}
+		if filters.Status != "" && string(d.Status) != filters.Status {
+			continue
+		}
+		if filters.EventType != "" && string(d.EventType) != filters.EventType {
+			continue
+		}
+		filtered = append(filtered, d)
+	}
+	sort.Slice(filtered, func(i, j int) bool {
+		if !filtered[i].CreatedAt.Equal(filtered[j].CreatedAt) {
+			return filtered[i].CreatedAt.After(filtered[j].CreatedAt)
+		}
+		return filtered[i].ID > filtered[j].ID
+	})
+
+	total := len(filtered)
+	page := filters.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := filters.PageSize
+	if pageSize < 1 {
+		pageSize = 50
+	}
+	start := (page - 1) * pageSize
+	if start > total {
+		return []domain.WebhookDelivery{}, total, nil
+	}
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+	out := make([]domain.WebhookDelivery, 0, end-start)
+	for _, d := range filtered[start:end] {
+		out = append(out, cloneWebhookDelivery(d))
+	}
+	return out, total, nil
+}
+
+func (s *MemoryWebhookStore) ListDueWebhookDeliveries(_ context.Context, now time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var due []domain.WebhookDelivery
+	for _, d := range s.deliveries {
+		if d.Status != domain.WebhookDeliveryPending || d.NextAttemptAt == nil || d.NextAttemptAt.After(now) {
+			continue
+		}
+		due = append(due, cloneWebhookDelivery(d))
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].NextAttemptAt.Equal(*due[j].NextAttemptAt) {
+			return due[i].NextAttemptAt.Before(*due[j].NextAttemptAt)
+		}
+		return due[i].ID < due[j].ID
+	})
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+func cloneWebhook(w domain.Webhook) domain.Webhook {
+	if w.Events != nil {
+		w.Events = append([]domain.WebhookEventType(nil), w.Events...)
+	}
+	return w
+}
+
+func cloneWebhookDelivery(d domain.WebhookDelivery) domain.WebhookDelivery {
+	if d.NextAttemptAt != nil {
+		t := *d.NextAttemptAt
+		d.NextAttemptAt = &t
+	}
+	if d.DeliveredAt != nil {
+		t := *d.DeliveredAt
+		d.DeliveredAt = &t
+	}
+	return d
+}
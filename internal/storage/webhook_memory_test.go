@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cloudpam/internal/domain"
+)
+
+func newTestWebhookDelivery(id, webhookID string, status domain.WebhookDeliveryStatus, created time.Time) domain.WebhookDelivery {
+	next := created
+	return domain.WebhookDelivery{
+		ID:            id,
+		WebhookID:     webhookID,
+		EventID:       "evt-" + id,
+		EventType:     domain.WebhookEventPoolCreated,
+		Payload:       `{}`,
+		Status:        status,
+		NextAttemptAt: &next,
+		CreatedAt:     created,
+		UpdatedAt:     created,
+	}
+}
+
+func TestWebhookMemoryStore_CRUD(t *testing.T) {
+	store := NewMemoryWebhookStore()
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	hook := domain.Webhook{
+		ID: "w1", Name: "ops", URL: "https://hooks.example.com/ipam", Secret: "s3cret",
+		Events: []domain.WebhookEventType{domain.WebhookEventPoolCreated}, Enabled: true,
+		CreatedAt: now, UpdatedAt: now,
+	}
+	if err := store.CreateWebhook(ctx, hook); err != nil {
+		t.Fatalf("CreateWebhook: %v", err)
+	}
+	if err := store.CreateWebhook(ctx, hook); !errors.Is(err, ErrConflict) {
+		t.Fatalf("duplicate CreateWebhook: expected ErrConflict, got %v", err)
+	}
+
+	got, err := store.GetWebhook(ctx, "w1")
+	if err != nil {
+		t.Fatalf("GetWebhook: %v", err)
+	}
+	if got.Secret != "s3cret" || len(got.Events) != 1 {
+		t.Fatalf("GetWebhook = %+v", got)
+	}
+	// Returned values are copies.
+	got.Events[0] = domain.WebhookEventPoolDeleted
+	if again, _ := store.GetWebhook(ctx, "w1"); again.Events[0] != domain.WebhookEventPoolCreated {
+		t.Fatal("mutating a returned webhook changed the stored copy")
+	}
+
+	hook.Name = "ops-renamed"
+	hook.Enabled = false
+	if err := store.UpdateWebhook(ctx, hook); err != nil {
+		t.Fatalf("UpdateWebhook: %v", err)
+	}
+	list, err := store.ListWebhooks(ctx)
+	if err != nil || len(list) != 1 || list[0].Name != "ops-renamed" || list[0].Enabled {
+		t.Fatalf("ListWebhooks = %+v, %v", list, err)
+	}
+	if err := store.UpdateWebhook(ctx, domain.Webhook{ID: "missing"}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("UpdateWebhook(missing): expected ErrNotFound, got %v", err)
+	}
+
+	if err := store.CreateWebhookDelivery(ctx, newTestWebhookDelivery("d1", "w1", domain.WebhookDeliveryPending, now)); err != nil {
+		t.Fatalf("CreateWebhookDelivery: %v", err)
+	}
+	if err := store.CreateWebhookDelivery(ctx, newTestWebhookDelivery("d2", "missing", domain.WebhookDeliveryPending, now)); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("delivery for unknown webhook: expected ErrNotFound, got %v", err)
+	}
+
+	if err := store.DeleteWebhook(ctx, "w1"); err != nil {
+		t.Fatalf("DeleteWebhook: %v", err)
+	}
+	if _, err := store.GetWebhookDelivery(ctx, "d1"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("delivery should be deleted with its webhook, got %v", err)
+	}
+	if err := store.DeleteWebhook(ctx, "w1"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("second DeleteWebhook: expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestWebhookMemoryStore_Deliveries(t *testing.T) {
+	store := NewMemoryWebhookStore()
+	ctx := context.Background()
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, id := range []string{"w1", "w2"} {
+		if err := store.CreateWebhook(ctx, domain.Webhook{ID: id, Name: id, URL: "https://example.com", Enabled: true}); err != nil {
+			t.Fatalf("CreateWebhook(%s): %v", id, err)
+		}
+	}
+	deliveries := []domain.WebhookDelivery{
+		newTestWebhookDelivery("d1", "w1", domain.WebhookDeliveryPending, base),
+		newTestWebhookDelivery("d2", "w1", domain.WebhookDeliverySucceeded, base.Add(time.Minute)),
+		newTestWebhookDelivery("d3", "w1", domain.WebhookDeliveryPending, base.Add(2*time.Minute)),
+		newTestWebhookDelivery("d4", "w2", domain.WebhookDeliveryPending, base.Add(3*time.Minute)),
+	}
+	for _, d := range deliveries {
+		if err := store.CreateWebhookDelivery(ctx, d); err != nil {
+			t.Fatalf("CreateWebhookDelivery(%s): %v", d.ID, err)
+		}
+	}
+
+	items, total, err := store.ListWebhookDeliveries(ctx, domain.WebhookDeliveryFilters{WebhookID: "w1", PageSize: 2})
+	if err != nil {
+		t.Fatalf("ListWebhookDeliveries: %v", err)
+	}
+	if total != 3 || len(items) != 2 || items[0].ID != "d3" || items[1].ID != "d2" {
+		t.Fatalf("page 1 = %d items (total %d), first %v", len(items), total, items)
+	}
+	items, _, _ = store.ListWebhookDeliveries(ctx, domain.WebhookDeliveryFilters{WebhookID: "w1", PageSize: 2, Page: 2})
+	if len(items) != 1 || items[0].ID != "d1" {
+		t.Fatalf("page 2 = %v", items)
+	}
+	_, total, _ = store.ListWebhookDeliveries(ctx, domain.WebhookDeliveryFilters{Status: string(domain.WebhookDeliveryPending)})
+	if total != 3 {
+		t.Fatalf("pending total = %d, want 3", total)
+	}
+
+	// Only pending deliveries due by now are returned, oldest first.
+	due, err := store.ListDueWebhookDeliveries(ctx, base.Add(2*time.Minute), 0)
+	if err != nil {
+		t.Fatalf("ListDueWebhookDeliveries: %v", err)
+	}
+	if len(due) != 2 || due[0].ID != "d1" || due[1].ID != "d3" {
+		t.Fatalf("due = %v", due)
+	}
+	if due, _ := store.ListDueWebhookDeliveries(ctx, base.Add(time.Hour), 1); len(due) != 1 {
+		t.Fatalf("limit 1 returned %d deliveries", len(due))
+	}
+
+	d := due[0]
+	d.Status = domain.WebhookDeliveryFailed
+	d.Attempts = 3
+	d.LastError = "boom"
+	d.NextAttemptAt = nil
+	if err := store.UpdateWebhookDelivery(ctx, d); err != nil {
+		t.Fatalf("UpdateWebhookDelivery: %v", err)
+	}
+	got, err := store.GetWebhookDelivery(ctx, d.ID)
+	if err != nil || got.Status != domain.WebhookDeliveryFailed || got.Attempts != 3 || got.NextAttemptAt != nil {
+		t.Fatalf("GetWebhookDelivery = %+v, %v", got, err)
+	}
+}
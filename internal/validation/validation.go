@@ -18,7 +18,7 @@ var (
 	ErrInvalidFormat    = errors.New("invalid format")
 	ErrReservedRange    = errors.New("cidr uses reserved address range")
 	ErrInvalidPrefix    = errors.New("invalid prefix length")
-	ErrIPv6NotSupported = errors.New("ipv6 not supported")
+	ErrIPv6NotSupported = errors.New("ipv6 not supported") // Deprecated: no longer returned; IPv6 is validated like IPv4.
 	ErrNotCanonical     = errors.New("cidr is not in canonical form")
 	ErrControlCharacter = errors.New("value contains control characters")
 	ErrInvalidProvider  = errors.New("invalid provider")
@@ -31,6 +31,8 @@ const (
 	MaxAccountKeyLength = 100 // Updated to 100 as per requirements
 	MinPrefixLength     = 8
 	MaxPrefixLength     = 30 // Reduced to /30 for typical use cases
+	MinIPv6PrefixLength = 16
+	MaxIPv6PrefixLength = 64 // Smallest standard IPv6 subnet
 )
 
 // Reserved IPv4 ranges that should not be used for allocation.
@@ -44,6 +46,15 @@ var reservedIPv4Ranges = []netip.Prefix{
 	netip.MustParsePrefix("255.255.255.255/32"), // Broadcast
 }
 
+// Reserved IPv6 ranges that should not be used for allocation.
+var reservedIPv6Ranges = []netip.Prefix{
+	netip.MustParsePrefix("::/128"),        // Unspecified (RFC 4291)
+	netip.MustParsePrefix("::1/128"),       // Loopback (RFC 4291)
+	netip.MustParsePrefix("::ffff:0:0/96"), // IPv4-mapped (RFC 4291)
+	netip.MustParsePrefix("fe80::/10"),     // Link-local (RFC 4291)
+	netip.MustParsePrefix("ff00::/8"),      // Multicast (RFC 4291)
+}
+
 // Account key patterns for each provider.
 var (
 	// AWS: 12 digits
@@ -119,19 +130,45 @@ type CIDROptions struct {
 	AllowReserved bool
 	// AllowNonCanonical permits CIDRs that are not in canonical form (e.g., 10.0.0.1/24)
 	AllowNonCanonical bool
-	// MinPrefix overrides the minimum prefix length (default: MinPrefixLength)
+	// MinPrefix overrides the minimum IPv4 prefix length (default: MinPrefixLength)
 	MinPrefix int
-	// MaxPrefix overrides the maximum prefix length (default: MaxPrefixLength)
+	// MaxPrefix overrides the maximum IPv4 prefix length (default: MaxPrefixLength)
 	MaxPrefix int
+	// MinIPv6Prefix overrides the minimum IPv6 prefix length (default: MinIPv6PrefixLength)
+	MinIPv6Prefix int
+	// MaxIPv6Prefix overrides the maximum IPv6 prefix length (default: MaxIPv6PrefixLength)
+	MaxIPv6Prefix int
+}
+
+// PrefixBounds returns the allowed prefix length range for an address
+// family, applying any overrides from opts.
+func PrefixBounds(is4 bool, opts CIDROptions) (minPrefix, maxPrefix int) {
+	if is4 {
+		minPrefix, maxPrefix = MinPrefixLength, MaxPrefixLength
+		if opts.MinPrefix > 0 {
+			minPrefix = opts.MinPrefix
+		}
+		if opts.MaxPrefix > 0 {
+			maxPrefix = opts.MaxPrefix
+		}
+		return minPrefix, maxPrefix
+	}
+	minPrefix, maxPrefix = MinIPv6PrefixLength, MaxIPv6PrefixLength
+	if opts.MinIPv6Prefix > 0 {
+		minPrefix = opts.MinIPv6Prefix
+	}
+	if opts.MaxIPv6Prefix > 0 {
+		maxPrefix = opts.MaxIPv6Prefix
+	}
+	return minPrefix, maxPrefix
 }
 
 // ValidateCIDR validates a CIDR string for use in CloudPAM.
 // It checks for:
-// - Valid CIDR format (a.b.c.d/x)
-// - IPv4 only (IPv6 not supported)
+// - Valid CIDR format (a.b.c.d/x or an IPv6 prefix such as 2001:db8::/32)
 // - Not in reserved ranges (loopback, multicast, etc.) unless allowed
 // - Canonical form (network address matches CIDR) unless allowed
-// - Reasonable prefix length (between MinPrefixLength and MaxPrefixLength)
+// - Reasonable prefix length (see PrefixBounds for the per-family defaults)
 func ValidateCIDR(cidr string) error {
 	return ValidateCIDRWithOptions(cidr, CIDROptions{})
 }
@@ -154,11 +191,6 @@ func ValidateCIDRWithOptions(cidr string, opts CIDROptions) error {
 		return &CIDRError{CIDR: cidr, Reason: "invalid cidr notation", Err: ErrInvalidFormat}
 	}
 
-	// IPv4 only
-	if !pfx.Addr().Is4() {
-		return &CIDRError{CIDR: cidr, Reason: "only ipv4 is supported", Err: ErrIPv6NotSupported}
-	}
-
 	// Check canonical form: the address should be the network address
 	// (e.g., 10.0.0.1/24 should be 10.0.0.0/24)
 	if !opts.AllowNonCanonical {
@@ -174,7 +206,11 @@ func ValidateCIDRWithOptions(cidr string, opts CIDROptions) error {
 
 	// Check for reserved ranges (security concern takes priority)
 	if !opts.AllowReserved {
-		for _, reserved := range reservedIPv4Ranges {
+		reservedRanges := reservedIPv4Ranges
+		if !pfx.Addr().Is4() {
+			reservedRanges = reservedIPv6Ranges
+		}
+		for _, reserved := range reservedRanges {
 			if prefixOverlaps(pfx, reserved) {
 				return &CIDRError{
 					CIDR:   cidr,
@@ -186,14 +222,7 @@ func ValidateCIDRWithOptions(cidr string, opts CIDROptions) error {
 	}
 
 	// Determine prefix bounds
-	minPrefix := MinPrefixLength
-	if opts.MinPrefix > 0 {
-		minPrefix = opts.MinPrefix
-	}
-	maxPrefix := MaxPrefixLength
-	if opts.MaxPrefix > 0 {
-		maxPrefix = opts.MaxPrefix
-	}
+	minPrefix, maxPrefix := PrefixBounds(pfx.Addr().Is4(), opts)
 
 	// Check prefix length bounds
 	bits := pfx.Bits()
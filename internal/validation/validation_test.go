@@ -28,9 +28,18 @@ func TestValidateCIDR(t *testing.T) {
 		{name: "invalid prefix", cidr: "10.0.0.0/33", wantErr: ErrInvalidFormat},
 		{name: "garbage", cidr: "not-a-cidr", wantErr: ErrInvalidFormat},
 
-		// IPv6 not supported
-		{name: "ipv6", cidr: "2001:db8::/32", wantErr: ErrIPv6NotSupported},
-		{name: "ipv6 loopback", cidr: "::1/128", wantErr: ErrIPv6NotSupported},
+		// IPv6
+		{name: "ipv6 /32", cidr: "2001:db8::/32", wantErr: nil},
+		{name: "ipv6 /64", cidr: "2001:db8:0:1::/64", wantErr: nil},
+		{name: "ipv6 unique local", cidr: "fd00:1234::/48", wantErr: nil},
+		{name: "ipv6 loopback", cidr: "::1/128", wantErr: ErrReservedRange},
+		{name: "ipv6 link-local", cidr: "fe80::/64", wantErr: ErrReservedRange},
+		{name: "ipv6 multicast", cidr: "ff02::/16", wantErr: ErrReservedRange},
+		{name: "ipv6 mapped ipv4", cidr: "::ffff:10.0.0.0/104", wantErr: ErrReservedRange},
+		{name: "ipv6 prefix too small /12", cidr: "2000::/12", wantErr: ErrInvalidPrefix},
+		{name: "ipv6 prefix too large /80", cidr: "2001:db8::/80", wantErr: ErrInvalidPrefix},
+		{name: "ipv6 non-canonical", cidr: "2001:db8::1/64", wantErr: ErrNotCanonical},
+		{name: "ipv6 zone", cidr: "fe80::1%eth0/64", wantErr: ErrInvalidFormat},
 
 		// Reserved ranges
 		{name: "loopback /8", cidr: "127.0.0.0/8", wantErr: ErrReservedRange},
@@ -121,6 +130,18 @@ func TestValidateCIDRWithOptions(t *testing.T) {
 			opts:    CIDROptions{MinPrefix: 8},
 			wantErr: ErrInvalidPrefix,
 		},
+		{
+			name:    "custom ipv6 max prefix /127",
+			cidr:    "2001:db8::/127",
+			opts:    CIDROptions{MaxIPv6Prefix: 127},
+			wantErr: nil,
+		},
+		{
+			name:    "ipv4 bounds do not apply to ipv6",
+			cidr:    "2001:db8::/64",
+			opts:    CIDROptions{MinPrefix: 8, MaxPrefix: 30},
+			wantErr: nil,
+		},
 		// Combined options
 		{
 			name:    "all options enabled",
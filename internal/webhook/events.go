@@ -0,0 +1,134 @@
+package webhook
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"cloudpam/internal/audit"
+	"cloudpam/internal/domain"
+)
+
+// MaxEventItems caps the items embedded in drift and recommendation events.
+// Receivers that need the full set can page through the API.
+const MaxEventItems = 100
+
+// PoolEventData is the payload of the pool.* events.
+type PoolEventData struct {
+	PoolID       int64          `json:"pool_id"`
+	Name         string         `json:"name,omitempty"`
+	Actor        string         `json:"actor"`
+	ActorType    string         `json:"actor_type"`
+	AuditEventID string         `json:"audit_event_id,omitempty"`
+	RequestID    string         `json:"request_id,omitempty"`
+	Changes      *audit.Changes `json:"changes,omitempty"`
+}
+
+// DriftDetectedData is the payload of drift.detected, published after a
+// detection run that found at least one item.
+type DriftDetectedData struct {
+	Total     int                 `json:"total"`
+	Summary   domain.DriftSummary `json:"summary"`
+	Items     []domain.DriftItem  `json:"items"`
+	Truncated bool                `json:"truncated,omitempty"`
+}
+
+// NewDriftDetectedData builds a drift.detected payload from a detection run.
+func NewDriftDetectedData(resp *domain.RunDriftDetectionResponse) DriftDetectedData {
+	items, truncated := capItems(resp.Items)
+	return DriftDetectedData{Total: resp.Total, Summary: resp.Summary, Items: items, Truncated: truncated}
+}
+
+// RecommendationsGeneratedData is the payload of recommendation.generated,
+// published after a generation run that produced at least one item.
+type RecommendationsGeneratedData struct {
+	Total     int                     `json:"total"`
+	Items     []domain.Recommendation `json:"items"`
+	Truncated bool                    `json:"truncated,omitempty"`
+}
+
+// NewRecommendationsGeneratedData builds a recommendation.generated payload.
+func NewRecommendationsGeneratedData(resp *domain.GenerateRecommendationsResponse) RecommendationsGeneratedData {
+	items, truncated := capItems(resp.Items)
+	return RecommendationsGeneratedData{Total: resp.Total, Items: items, Truncated: truncated}
+}
+
+// AgentOfflineData is the payload of agent.offline, published when an
+// approved discovery agent stops sending heartbeats.
+type AgentOfflineData struct {
+	AgentID    uuid.UUID `json:"agent_id"`
+	Name       string    `json:"name"`
+	AccountID  int64     `json:"account_id"`
+	Hostname   string    `json:"hostname,omitempty"`
+	Version    string    `json:"version,omitempty"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+// ReservationExpiredData is the payload of reservation.expired, published
+// when the expiry job releases a reservation past its expiry.
+type ReservationExpiredData struct {
+	ReservationID string    `json:"reservation_id"`
+	CIDR          string    `json:"cidr"`
+	ParentID      *int64    `json:"parent_id,omitempty"`
+	Owner         string    `json:"owner"`
+	Reason        string    `json:"reason,omitempty"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+// PingData is the payload of the ping event sent by the test endpoint.
+type PingData struct {
+	WebhookID string `json:"webhook_id"`
+	Message   string `json:"message"`
+}
+
+func capItems[T any](items []T) ([]T, bool) {
+	if len(items) > MaxEventItems {
+		return items[:MaxEventItems], true
+	}
+	return items, false
+}
+
+// AuditSink returns an audit.Sink that publishes successful pool writes as
+// pool.* events. Wrap the audit logger with audit.NewForwardingAuditLogger
+// to attach it.
+func (d *Dispatcher) AuditSink() audit.Sink {
+	return AuditSink{Publisher: d}
+}
+
+// AuditSink maps audit events onto webhook events.
+type AuditSink struct {
+	Publisher Publisher
+}
+
+// Send publishes the pool event matching an audit event, if any.
+func (s AuditSink) Send(ctx context.Context, event *audit.AuditEvent) error {
+	if s.Publisher == nil || event == nil || event.StatusCode >= 400 || event.ResourceType != audit.ResourcePool {
+		return nil
+	}
+	var eventType domain.WebhookEventType
+	switch event.Action {
+	case audit.ActionCreate:
+		eventType = domain.WebhookEventPoolCreated
+	case audit.ActionUpdate:
+		eventType = domain.WebhookEventPoolUpdated
+	case audit.ActionDelete:
+		eventType = domain.WebhookEventPoolDeleted
+	case audit.ActionAllocate:
+		eventType = domain.WebhookEventPoolAllocated
+	default:
+		return nil
+	}
+	poolID, _ := strconv.ParseInt(event.ResourceID, 10, 64)
+	s.Publisher.Publish(ctx, eventType, PoolEventData{
+		PoolID:       poolID,
+		Name:         event.ResourceName,
+		Actor:        event.Actor,
+		ActorType:    event.ActorType,
+		AuditEventID: event.ID,
+		RequestID:    event.RequestID,
+		Changes:      event.Changes,
+	})
+	return nil
+}
@@ -0,0 +1,44 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+)
+
+// Headers set on every delivery.
+const (
+	HeaderEvent     = "X-CloudPAM-Event"
+	HeaderDelivery  = "X-CloudPAM-Delivery"
+	HeaderTimestamp = "X-CloudPAM-Timestamp"
+	HeaderSignature = "X-CloudPAM-Signature"
+)
+
+// Sign returns the X-CloudPAM-Signature value for a body sent at timestamp
+// (Unix seconds): "sha256=" followed by the hex HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with the webhook secret. Binding the timestamp
+// lets receivers reject replays.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature matches body and timestamp, comparing in
+// constant time.
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// GenerateSecret returns a random signing secret.
+func GenerateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,364 @@
+// Package webhook delivers IPAM lifecycle events to admin-registered HTTP
+// endpoints.
+//
+// Publishing an event records one pending delivery per subscribed, enabled
+// webhook and hands it to a worker pool. Each attempt is an HMAC-signed POST;
+// failures are retried with exponential backoff until MaxAttempts, and every
+// outcome is written back to the delivery log. Because pending deliveries
+// live in the store, retries survive a restart.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+// Publisher accepts lifecycle events for delivery. Publishing is best effort:
+// failures are logged by the implementation and never surface to callers.
+type Publisher interface {
+	Publish(ctx context.Context, eventType domain.WebhookEventType, data any)
+}
+
+// Config tunes delivery. Zero values take the defaults noted on each field.
+type Config struct {
+	Workers        int           // concurrent deliveries; default 4
+	MaxAttempts    int           // attempts before a delivery is marked failed; default 8
+	InitialBackoff time.Duration // delay before the first retry, doubling per attempt; default 30s
+	MaxBackoff     time.Duration // cap on the retry delay; default 1h
+	Timeout        time.Duration // per-attempt HTTP timeout; default 10s
+	PollInterval   time.Duration // how often due retries are picked up; default 15s
+	Client         *http.Client  // default: a client that does not follow redirects
+	Logger         *slog.Logger
+}
+
+// Dispatcher publishes events into the delivery log and delivers them.
+type Dispatcher struct {
+	store  storage.WebhookStore
+	cfg    Config
+	client *http.Client
+	logger *slog.Logger
+	now    func() time.Time
+	queue  chan string
+
+	mu       sync.Mutex
+	inflight map[string]bool
+}
+
+var _ Publisher = (*Dispatcher)(nil)
+
+// NewDispatcher creates a Dispatcher. Call Run to start delivering.
+func NewDispatcher(store storage.WebhookStore, cfg Config) *Dispatcher {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 4
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 8
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = 30 * time.Second
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = time.Hour
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 15 * time.Second
+	}
+	client := cfg.Client
+	if client == nil {
+		client = &http.Client{
+			// A redirect is reported as a failed delivery rather than
+			// followed, so a signed payload only reaches the configured URL.
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		}
+	}
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+	return &Dispatcher{
+		store:    store,
+		cfg:      cfg,
+		client:   client,
+		logger:   logger,
+		now:      func() time.Time { return time.Now().UTC() },
+		queue:    make(chan string, 256),
+		inflight: make(map[string]bool),
+	}
+}
+
+// Publish records a pending delivery of the event for every enabled webhook
+// subscribed to eventType.
+func (d *Dispatcher) Publish(ctx context.Context, eventType domain.WebhookEventType, data any) {
+	hooks, err := d.store.ListWebhooks(ctx)
+	if err != nil {
+		d.logger.WarnContext(ctx, "webhook publish: list webhooks failed", "event_type", eventType, "error", err)
+		return
+	}
+	var event *domain.WebhookEvent
+	var payload []byte
+	for _, hook := range hooks {
+		if !hook.Enabled || !hook.Subscribes(eventType) {
+			continue
+		}
+		if event == nil {
+			if event, payload, err = d.newEvent(eventType, data); err != nil {
+				d.logger.WarnContext(ctx, "webhook publish: encode event failed", "event_type", eventType, "error", err)
+				return
+			}
+		}
+		if _, err := d.createDelivery(ctx, hook, event, payload); err != nil {
+			d.logger.WarnContext(ctx, "webhook publish: record delivery failed",
+				"webhook_id", hook.ID, "event_type", eventType, "error", err)
+		}
+	}
+}
+
+// Send queues an event for a single webhook, ignoring its event filter. It
+// backs the test endpoint.
+func (d *Dispatcher) Send(ctx context.Context, hook domain.Webhook, eventType domain.WebhookEventType, data any) (*domain.WebhookDelivery, error) {
+	event, payload, err := d.newEvent(eventType, data)
+	if err != nil {
+		return nil, err
+	}
+	return d.createDelivery(ctx, hook, event, payload)
+}
+
+func (d *Dispatcher) newEvent(eventType domain.WebhookEventType, data any) (*domain.WebhookEvent, []byte, error) {
+	event := &domain.WebhookEvent{
+		ID:         uuid.New().String(),
+		Type:       eventType,
+		OccurredAt: d.now(),
+		Data:       data,
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, nil, err
+	}
+	return event, payload, nil
+}
+
+func (d *Dispatcher) createDelivery(ctx context.Context, hook domain.Webhook, event *domain.WebhookEvent, payload []byte) (*domain.WebhookDelivery, error) {
+	now := d.now()
+	delivery := domain.WebhookDelivery{
+		ID:            uuid.New().String(),
+		WebhookID:     hook.ID,
+		EventID:       event.ID,
+		EventType:     event.Type,
+		Payload:       string(payload),
+		Status:        domain.WebhookDeliveryPending,
+		NextAttemptAt: &now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := d.store.CreateWebhookDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+	d.enqueue(delivery.ID)
+	return &delivery, nil
+}
+
+// enqueue hands a delivery to the workers. When the queue is full the
+// delivery stays pending in the store and the poller picks it up.
+func (d *Dispatcher) enqueue(id string) {
+	select {
+	case d.queue <- id:
+	default:
+	}
+}
+
+// Run delivers queued events and polls for due retries until ctx is done.
+// It also resumes deliveries left pending by a previous process.
+func (d *Dispatcher) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < d.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case id := <-d.queue:
+					d.attempt(ctx, id)
+				}
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+	d.poll(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case <-ticker.C:
+			d.poll(ctx)
+		}
+	}
+}
+
+func (d *Dispatcher) poll(ctx context.Context) {
+	due, err := d.store.ListDueWebhookDeliveries(ctx, d.now(), cap(d.queue))
+	if err != nil {
+		if ctx.Err() == nil {
+			d.logger.WarnContext(ctx, "webhook poll failed", "error", err)
+		}
+		return
+	}
+	for _, dl := range due {
+		if !d.isInflight(dl.ID) {
+			d.enqueue(dl.ID)
+		}
+	}
+}
+
+func (d *Dispatcher) claim(id string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.inflight[id] {
+		return false
+	}
+	d.inflight[id] = true
+	return true
+}
+
+func (d *Dispatcher) release(id string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.inflight, id)
+}
+
+func (d *Dispatcher) isInflight(id string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.inflight[id]
+}
+
+// attempt makes one delivery attempt if the delivery is still pending and
+// due, then records the outcome. The same ID can be queued more than once;
+// the claim and the due check make the extra copies no-ops.
+func (d *Dispatcher) attempt(ctx context.Context, id string) {
+	if !d.claim(id) {
+		return
+	}
+	defer d.release(id)
+
+	dl, err := d.store.GetWebhookDelivery(ctx, id)
+	if err != nil {
+		if !errors.Is(err, storage.ErrNotFound) && ctx.Err() == nil {
+			d.logger.WarnContext(ctx, "webhook delivery lookup failed", "delivery_id", id, "error", err)
+		}
+		return
+	}
+	now := d.now()
+	if dl.Status != domain.WebhookDeliveryPending || dl.NextAttemptAt == nil || dl.NextAttemptAt.After(now) {
+		return
+	}
+	hook, err := d.store.GetWebhook(ctx, dl.WebhookID)
+	if err != nil {
+		if !errors.Is(err, storage.ErrNotFound) && ctx.Err() == nil {
+			d.logger.WarnContext(ctx, "webhook lookup failed", "webhook_id", dl.WebhookID, "error", err)
+		}
+		return
+	}
+
+	var status int
+	if hook.Enabled {
+		status, err = d.deliver(ctx, *hook, *dl)
+		if ctx.Err() != nil {
+			// Shutting down: leave the delivery pending for the next run.
+			return
+		}
+	} else {
+		err = errors.New("webhook is disabled")
+	}
+
+	now = d.now()
+	dl.Attempts++
+	dl.ResponseStatus = status
+	dl.UpdatedAt = now
+	switch {
+	case err == nil:
+		dl.Status = domain.WebhookDeliverySucceeded
+		dl.LastError = ""
+		dl.NextAttemptAt = nil
+		dl.DeliveredAt = &now
+	case !hook.Enabled || dl.Attempts >= d.cfg.MaxAttempts:
+		dl.Status = domain.WebhookDeliveryFailed
+		dl.LastError = err.Error()
+		dl.NextAttemptAt = nil
+	default:
+		next := now.Add(d.backoff(dl.Attempts))
+		dl.LastError = err.Error()
+		dl.NextAttemptAt = &next
+	}
+	if err := d.store.UpdateWebhookDelivery(ctx, *dl); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		d.logger.WarnContext(ctx, "webhook delivery update failed", "delivery_id", dl.ID, "error", err)
+	}
+	if dl.Status == domain.WebhookDeliveryFailed {
+		d.logger.WarnContext(ctx, "webhook delivery failed",
+			"delivery_id", dl.ID, "webhook_id", hook.ID, "event_type", dl.EventType,
+			"attempts", dl.Attempts, "error", dl.LastError)
+	}
+}
+
+// backoff returns the delay after the given number of failed attempts.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.cfg.InitialBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= d.cfg.MaxBackoff {
+			return d.cfg.MaxBackoff
+		}
+	}
+	return delay
+}
+
+// deliver POSTs the stored payload. Any 2xx response is a success.
+func (d *Dispatcher) deliver(ctx context.Context, hook domain.Webhook, dl domain.WebhookDelivery) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.cfg.Timeout)
+	defer cancel()
+
+	body := []byte(dl.Payload)
+	ts := d.now().Unix()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "CloudPAM-Webhook/1.0")
+	req.Header.Set(HeaderEvent, string(dl.EventType))
+	req.Header.Set(HeaderDelivery, dl.ID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(HeaderSignature, Sign(hook.Secret, ts, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint returned %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
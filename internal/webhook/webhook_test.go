@@ -0,0 +1,293 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"cloudpam/internal/audit"
+	"cloudpam/internal/domain"
+	"cloudpam/internal/storage"
+)
+
+type fixedClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fixedClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fixedClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestDispatcher(t *testing.T, cfg Config) (*Dispatcher, *storage.MemoryWebhookStore, *fixedClock) {
+	t.Helper()
+	store := storage.NewMemoryWebhookStore()
+	d := NewDispatcher(store, cfg)
+	clock := &fixedClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	d.now = clock.Now
+	return d, store, clock
+}
+
+func addHook(t *testing.T, store storage.WebhookStore, id, url string, enabled bool, events ...domain.WebhookEventType) {
+	t.Helper()
+	if err := store.CreateWebhook(context.Background(), domain.Webhook{
+		ID: id, Name: id, URL: url, Secret: "secret-" + id, Events: events, Enabled: enabled,
+	}); err != nil {
+		t.Fatalf("CreateWebhook(%s): %v", id, err)
+	}
+}
+
+func deliveries(t *testing.T, store storage.WebhookStore, webhookID string) []domain.WebhookDelivery {
+	t.Helper()
+	items, _, err := store.ListWebhookDeliveries(context.Background(), domain.WebhookDeliveryFilters{WebhookID: webhookID})
+	if err != nil {
+		t.Fatalf("ListWebhookDeliveries: %v", err)
+	}
+	return items
+}
+
+func TestPublish_FiltersBySubscriptionAndEnabled(t *testing.T) {
+	d, store, _ := newTestDispatcher(t, Config{})
+	addHook(t, store, "all", "https://example.com/all", true)
+	addHook(t, store, "pools", "https://example.com/pools", true, domain.WebhookEventPoolCreated)
+	addHook(t, store, "drift", "https://example.com/drift", true, domain.WebhookEventDriftDetected)
+	addHook(t, store, "off", "https://example.com/off", false)
+
+	d.Publish(context.Background(), domain.WebhookEventPoolCreated, PoolEventData{PoolID: 7})
+
+	want := map[string]int{"all": 1, "pools": 1, "drift": 0, "off": 0}
+	for id, n := range want {
+		if got := len(deliveries(t, store, id)); got != n {
+			t.Errorf("webhook %s: %d deliveries, want %d", id, got, n)
+		}
+	}
+	// One event fans out to every subscriber with the same event ID.
+	a, p := deliveries(t, store, "all")[0], deliveries(t, store, "pools")[0]
+	if a.EventID != p.EventID || a.Status != domain.WebhookDeliveryPending {
+		t.Fatalf("deliveries = %+v / %+v", a, p)
+	}
+}
+
+func TestAttempt_SignsAndRecordsSuccess(t *testing.T) {
+	type received struct {
+		header http.Header
+		body   []byte
+	}
+	got := make(chan received, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got <- received{header: r.Header.Clone(), body: body}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	d, store, clock := newTestDispatcher(t, Config{})
+	addHook(t, store, "w1", srv.URL, true)
+	d.Publish(context.Background(), domain.WebhookEventPoolDeleted, PoolEventData{PoolID: 42, Name: "prod"})
+	dl := deliveries(t, store, "w1")[0]
+
+	d.attempt(context.Background(), dl.ID)
+
+	r := <-got
+	if r.header.Get(HeaderEvent) != "pool.deleted" || r.header.Get(HeaderDelivery) != dl.ID {
+		t.Fatalf("headers = %v", r.header)
+	}
+	ts, err := strconv.ParseInt(r.header.Get(HeaderTimestamp), 10, 64)
+	if err != nil || ts != clock.Now().Unix() {
+		t.Fatalf("timestamp header = %q", r.header.Get(HeaderTimestamp))
+	}
+	if !Verify("secret-w1", ts, r.body, r.header.Get(HeaderSignature)) {
+		t.Fatal("signature does not verify")
+	}
+	if Verify("wrong", ts, r.body, r.header.Get(HeaderSignature)) {
+		t.Fatal("signature verified with the wrong secret")
+	}
+	var event struct {
+		Type domain.WebhookEventType `json:"type"`
+		Data PoolEventData           `json:"data"`
+	}
+	if err := json.Unmarshal(r.body, &event); err != nil || event.Type != domain.WebhookEventPoolDeleted || event.Data.PoolID != 42 {
+		t.Fatalf("body = %s (%v)", r.body, err)
+	}
+
+	after, _ := store.GetWebhookDelivery(context.Background(), dl.ID)
+	if after.Status != domain.WebhookDeliverySucceeded || after.Attempts != 1 || after.ResponseStatus != 204 ||
+		after.DeliveredAt == nil || after.NextAttemptAt != nil {
+		t.Fatalf("delivery after success = %+v", after)
+	}
+}
+
+func TestAttempt_RetriesWithBackoffThenFails(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, "nope", http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	d, store, clock := newTestDispatcher(t, Config{MaxAttempts: 3, InitialBackoff: time.Minute})
+	addHook(t, store, "w1", srv.URL, true)
+	d.Publish(context.Background(), domain.WebhookEventPoolCreated, PoolEventData{PoolID: 1})
+	id := deliveries(t, store, "w1")[0].ID
+	ctx := context.Background()
+
+	d.attempt(ctx, id)
+	dl, _ := store.GetWebhookDelivery(ctx, id)
+	if dl.Status != domain.WebhookDeliveryPending || dl.Attempts != 1 || dl.ResponseStatus != 502 || dl.LastError == "" {
+		t.Fatalf("after first failure = %+v", dl)
+	}
+	if want := clock.Now().Add(time.Minute); dl.NextAttemptAt == nil || !dl.NextAttemptAt.Equal(want) {
+		t.Fatalf("next attempt = %v, want %v", dl.NextAttemptAt, want)
+	}
+
+	// Not yet due: the attempt is a no-op.
+	d.attempt(ctx, id)
+	if calls != 1 {
+		t.Fatalf("attempt before backoff elapsed made a request (calls=%d)", calls)
+	}
+
+	clock.Advance(time.Minute)
+	d.attempt(ctx, id)
+	dl, _ = store.GetWebhookDelivery(ctx, id)
+	if want := clock.Now().Add(2 * time.Minute); dl.Attempts != 2 || !dl.NextAttemptAt.Equal(want) {
+		t.Fatalf("after second failure = %+v, want next attempt %v", dl, want)
+	}
+
+	clock.Advance(2 * time.Minute)
+	d.attempt(ctx, id)
+	dl, _ = store.GetWebhookDelivery(ctx, id)
+	if dl.Status != domain.WebhookDeliveryFailed || dl.Attempts != 3 || dl.NextAttemptAt != nil {
+		t.Fatalf("after final failure = %+v", dl)
+	}
+	if due, _ := store.ListDueWebhookDeliveries(ctx, clock.Now().Add(time.Hour), 0); len(due) != 0 {
+		t.Fatalf("failed delivery is still due: %v", due)
+	}
+}
+
+func TestAttempt_DisabledWebhookFails(t *testing.T) {
+	d, store, _ := newTestDispatcher(t, Config{})
+	addHook(t, store, "w1", "http://127.0.0.1:0/unused", true)
+	d.Publish(context.Background(), domain.WebhookEventPoolCreated, PoolEventData{PoolID: 1})
+	hook, _ := store.GetWebhook(context.Background(), "w1")
+	hook.Enabled = false
+	_ = store.UpdateWebhook(context.Background(), *hook)
+
+	id := deliveries(t, store, "w1")[0].ID
+	d.attempt(context.Background(), id)
+	dl, _ := store.GetWebhookDelivery(context.Background(), id)
+	if dl.Status != domain.WebhookDeliveryFailed || dl.LastError != "webhook is disabled" {
+		t.Fatalf("delivery = %+v", dl)
+	}
+}
+
+func TestRun_DeliversQueuedEvents(t *testing.T) {
+	hits := make(chan struct{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits <- struct{}{}
+	}))
+	defer srv.Close()
+
+	store := storage.NewMemoryWebhookStore()
+	addHook(t, store, "w1", srv.URL, true)
+	d := NewDispatcher(store, Config{Workers: 1})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		d.Run(ctx)
+		close(done)
+	}()
+
+	dl, err := d.Send(ctx, domain.Webhook{ID: "w1"}, domain.WebhookEventPing, PingData{WebhookID: "w1"})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	select {
+	case <-hits:
+	case <-time.After(5 * time.Second):
+		t.Fatal("delivery was not attempted")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got, _ := store.GetWebhookDelivery(context.Background(), dl.ID)
+		if got.Status == domain.WebhookDeliverySucceeded {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("delivery status = %s", got.Status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+}
+
+func TestBackoff(t *testing.T) {
+	d := NewDispatcher(storage.NewMemoryWebhookStore(), Config{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second})
+	for attempts, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 10: 5 * time.Second} {
+		if got := d.backoff(attempts); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
+
+type recordingPublisher struct {
+	events []domain.WebhookEventType
+	data   []any
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, t domain.WebhookEventType, data any) {
+	p.events = append(p.events, t)
+	p.data = append(p.data, data)
+}
+
+func TestAuditSink_MapsPoolEvents(t *testing.T) {
+	pub := &recordingPublisher{}
+	sink := AuditSink{Publisher: pub}
+	ctx := context.Background()
+
+	events := []*audit.AuditEvent{
+		{Action: audit.ActionCreate, ResourceType: audit.ResourcePool, ResourceID: "1", StatusCode: 201},
+		{Action: audit.ActionUpdate, ResourceType: audit.ResourcePool, ResourceID: "1", StatusCode: 200},
+		{Action: audit.ActionAllocate, ResourceType: audit.ResourcePool, ResourceID: "2", StatusCode: 201},
+		{Action: audit.ActionDelete, ResourceType: audit.ResourcePool, ResourceID: "1", StatusCode: 204},
+		// Ignored: failed writes, other resources, other actions.
+		{Action: audit.ActionCreate, ResourceType: audit.ResourcePool, ResourceID: "3", StatusCode: 409},
+		{Action: audit.ActionCreate, ResourceType: audit.ResourceAccount, ResourceID: "4", StatusCode: 201},
+		{Action: audit.ActionRead, ResourceType: audit.ResourcePool, ResourceID: "1", StatusCode: 200},
+	}
+	for _, e := range events {
+		if err := sink.Send(ctx, e); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+	}
+
+	want := []domain.WebhookEventType{
+		domain.WebhookEventPoolCreated, domain.WebhookEventPoolUpdated,
+		domain.WebhookEventPoolAllocated, domain.WebhookEventPoolDeleted,
+	}
+	if len(pub.events) != len(want) {
+		t.Fatalf("published %v, want %v", pub.events, want)
+	}
+	for i := range want {
+		if pub.events[i] != want[i] {
+			t.Errorf("event %d = %s, want %s", i, pub.events[i], want[i])
+		}
+	}
+	if data := pub.data[2].(PoolEventData); data.PoolID != 2 {
+		t.Errorf("allocate payload pool_id = %d, want 2", data.PoolID)
+	}
+}
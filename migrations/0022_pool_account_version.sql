@@ -0,0 +1,5 @@
+-- Row versions for optimistic concurrency. Every write to a pool or account
+-- increments version; the API exposes it as the ETag and checks If-Match
+-- against it.
+ALTER TABLE pools ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE accounts ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
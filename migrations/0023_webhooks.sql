@@ -0,0 +1,45 @@
+-- Outbound webhooks: admin-registered endpoints that receive HMAC-signed
+-- IPAM lifecycle events, plus a delivery log that also drives retries.
+CREATE TABLE IF NOT EXISTS webhooks (
+    id         TEXT PRIMARY KEY,
+    name       TEXT NOT NULL,
+    url        TEXT NOT NULL,
+    secret     TEXT NOT NULL,
+    events     TEXT NOT NULL DEFAULT '[]',
+    enabled    INTEGER NOT NULL DEFAULT 1,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id              TEXT PRIMARY KEY,
+    webhook_id      TEXT NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    event_id        TEXT NOT NULL,
+    event_type      TEXT NOT NULL,
+    payload         TEXT NOT NULL,
+    status          TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending','succeeded','failed')),
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    response_status INTEGER,
+    last_error      TEXT,
+    next_attempt_at TEXT,
+    delivered_at    TEXT,
+    created_at      TEXT NOT NULL,
+    updated_at      TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due     ON webhook_deliveries(status, next_attempt_at);
+
+INSERT INTO permissions (id, name, description, category) VALUES
+    ('webhooks:create', 'Create webhooks', 'Register outbound webhook endpoints', 'Integrations'),
+    ('webhooks:read', 'Read webhooks', 'View webhook configuration and delivery logs', 'Integrations'),
+    ('webhooks:update', 'Update webhooks', 'Edit webhook endpoints, event filters, secrets, and send test events', 'Integrations'),
+    ('webhooks:delete', 'Delete webhooks', 'Remove webhook endpoints and their delivery logs', 'Integrations'),
+    ('webhooks:list', 'List webhooks', 'Browse webhook endpoints', 'Integrations')
+ON CONFLICT(id) DO UPDATE SET
+    name = excluded.name,
+    description = excluded.description,
+    category = excluded.category;
+
+INSERT OR IGNORE INTO role_permissions (role_id, permission_id)
+SELECT 10, id FROM permissions WHERE id LIKE 'webhooks:%';
@@ -0,0 +1,22 @@
+-- Individual host addresses recorded inside subnet pools. address_key is the
+-- 16-byte form of the address in hex so rows sort numerically; resource_id
+-- links a record to the discovered network interface holding the address.
+CREATE TABLE IF NOT EXISTS ip_addresses (
+    id          TEXT PRIMARY KEY,
+    pool_id     INTEGER NOT NULL REFERENCES pools(id) ON DELETE CASCADE,
+    address     TEXT NOT NULL,
+    address_key TEXT NOT NULL,
+    hostname    TEXT,
+    mac_address TEXT,
+    owner       TEXT,
+    status      TEXT NOT NULL DEFAULT 'assigned' CHECK (status IN ('reserved','assigned','dhcp')),
+    source      TEXT NOT NULL DEFAULT 'manual',
+    description TEXT,
+    resource_id TEXT REFERENCES discovered_resources(id) ON DELETE SET NULL,
+    created_at  TEXT NOT NULL,
+    updated_at  TEXT NOT NULL,
+    UNIQUE (pool_id, address)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ip_addresses_pool     ON ip_addresses(pool_id, address_key);
+CREATE INDEX IF NOT EXISTS idx_ip_addresses_resource ON ip_addresses(resource_id);
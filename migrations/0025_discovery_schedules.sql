@@ -0,0 +1,19 @@
+-- Per-account discovery schedules driving the server-side scheduler. Exactly
+-- one of sync_interval or cron_expr is set; next_run_at is claimed with a
+-- compare-and-set so replicas sharing a database never fire the same run.
+CREATE TABLE IF NOT EXISTS discovery_schedules (
+    account_id       INTEGER PRIMARY KEY REFERENCES accounts(id) ON DELETE CASCADE,
+    enabled          INTEGER NOT NULL DEFAULT 1,
+    sync_interval    TEXT,
+    cron_expr        TEXT,
+    detect_drift     INTEGER NOT NULL DEFAULT 1,
+    next_run_at      TEXT,
+    last_run_at      TEXT,
+    last_status      TEXT,
+    last_error       TEXT,
+    last_sync_job_id TEXT,
+    created_at       TEXT NOT NULL,
+    updated_at       TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_discovery_schedules_due ON discovery_schedules(enabled, next_run_at);
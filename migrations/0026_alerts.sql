@@ -0,0 +1,28 @@
+-- Capacity alerts raised when a pool crosses a utilization threshold or is
+-- forecast to exhaust. Rules and notification channels live in the settings
+-- table under the 'alerting' key; the partial unique index keeps at most one
+-- active alert per rule, pool and kind.
+CREATE TABLE IF NOT EXISTS alerts (
+    id              TEXT PRIMARY KEY,
+    rule_id         TEXT NOT NULL,
+    pool_id         INTEGER NOT NULL REFERENCES pools(id) ON DELETE CASCADE,
+    pool_name       TEXT NOT NULL,
+    pool_cidr       TEXT NOT NULL,
+    kind            TEXT NOT NULL CHECK (kind IN ('utilization','exhaustion')),
+    severity        TEXT NOT NULL CHECK (severity IN ('warning','critical')),
+    status          TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open','acknowledged','resolved')),
+    value           REAL NOT NULL,
+    threshold       REAL NOT NULL,
+    message         TEXT NOT NULL,
+    opened_at       TEXT NOT NULL,
+    updated_at      TEXT NOT NULL,
+    acknowledged_at TEXT,
+    acknowledged_by TEXT,
+    resolved_at     TEXT,
+    resolved_by     TEXT,
+    notified_at     TEXT
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_alerts_active ON alerts(rule_id, pool_id, kind) WHERE status <> 'resolved';
+CREATE INDEX IF NOT EXISTS idx_alerts_status_opened ON alerts(status, opened_at DESC);
+CREATE INDEX IF NOT EXISTS idx_alerts_pool ON alerts(pool_id);
@@ -0,0 +1,17 @@
+-- User-defined compliance rules evaluated by the compliance check next to
+-- the built-in checks. match_spec and check_spec hold the rule's JSON match
+-- and check documents; enforce rejects pool creation that violates the rule.
+CREATE TABLE IF NOT EXISTS compliance_rules (
+    id          TEXT PRIMARY KEY,
+    name        TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    enabled     INTEGER NOT NULL DEFAULT 1,
+    severity    TEXT NOT NULL CHECK (severity IN ('error','warning','info')),
+    enforce     INTEGER NOT NULL DEFAULT 0,
+    match_spec  TEXT NOT NULL DEFAULT '{}',
+    check_spec  TEXT NOT NULL,
+    message     TEXT NOT NULL DEFAULT '',
+    remediation TEXT NOT NULL DEFAULT '',
+    created_at  TEXT NOT NULL,
+    updated_at  TEXT NOT NULL
+);
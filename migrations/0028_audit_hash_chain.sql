@@ -0,0 +1,26 @@
+-- Tamper-evident hash chain over the audit log. seq orders events in the
+-- chain; hash covers each event's canonical serialization together with
+-- prev_hash, the hash of the event before it. Events recorded before this
+-- migration are chained by the audit logger on first use.
+ALTER TABLE audit_logs ADD COLUMN seq INTEGER;
+ALTER TABLE audit_logs ADD COLUMN prev_hash TEXT;
+ALTER TABLE audit_logs ADD COLUMN hash TEXT;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_logs_seq ON audit_logs(seq);
+
+-- Links of events removed by retention, kept while a retained event follows
+-- them so the chain still verifies across the gap.
+CREATE TABLE IF NOT EXISTS audit_chain_links (
+    seq       INTEGER PRIMARY KEY,
+    prev_hash TEXT NOT NULL,
+    hash      TEXT NOT NULL,
+    timestamp TEXT NOT NULL
+);
+
+-- Chain heads signed with the server's checkpoint key.
+CREATE TABLE IF NOT EXISTS audit_checkpoints (
+    seq        INTEGER PRIMARY KEY,
+    hash       TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    key_id     TEXT NOT NULL,
+    signature  TEXT NOT NULL
+);
@@ -0,0 +1 @@
+ALTER TABLE oidc_providers ADD COLUMN role_rules TEXT NOT NULL DEFAULT '[]';
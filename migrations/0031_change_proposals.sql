@@ -0,0 +1,24 @@
+-- Change proposals: pool trees from the schema planner or an AI plan that
+-- wait for a second reviewer before they are applied. tree holds the
+-- request, change_set the dry run taken when it was proposed.
+CREATE TABLE IF NOT EXISTS change_proposals (
+    id             TEXT PRIMARY KEY,
+    title          TEXT NOT NULL,
+    source         TEXT NOT NULL CHECK (source IN ('schema','ai_plan')),
+    session_id     TEXT NOT NULL DEFAULT '',
+    status         TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending','applied','rejected')),
+    tree           TEXT NOT NULL,
+    change_set     TEXT NOT NULL,
+    proposed_by    TEXT NOT NULL,
+    proposed_by_id TEXT NOT NULL DEFAULT '',
+    proposed_role  TEXT NOT NULL DEFAULT '',
+    reviewed_by    TEXT,
+    reviewed_by_id TEXT,
+    reviewed_role  TEXT,
+    review_comment TEXT,
+    pool_map       TEXT NOT NULL DEFAULT '{}',
+    created_at     TEXT NOT NULL,
+    reviewed_at    TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_change_proposals_status_created ON change_proposals(status, created_at DESC);
@@ -0,0 +1,49 @@
+-- Pool change requests: pool creates, updates and deletes held back by an
+-- approval policy until a second person approves them. payload holds the
+-- create or update body. A pending create holds cidr under parent_id.
+CREATE TABLE IF NOT EXISTS pool_change_requests (
+    id              TEXT PRIMARY KEY,
+    operation       TEXT NOT NULL CHECK (operation IN ('create','update','delete')),
+    status          TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending','applied','rejected')),
+    pool_id         INTEGER,
+    pool_version    INTEGER NOT NULL DEFAULT 0,
+    pool_name       TEXT NOT NULL,
+    cidr            TEXT,
+    parent_id       INTEGER,
+    payload         TEXT NOT NULL DEFAULT '{}',
+    delete_cascade  INTEGER NOT NULL DEFAULT 0,
+    policy_ids      TEXT NOT NULL DEFAULT '[]',
+    requested_by    TEXT NOT NULL,
+    requested_by_id TEXT NOT NULL DEFAULT '',
+    requested_role  TEXT NOT NULL DEFAULT '',
+    reviewed_by     TEXT,
+    reviewed_by_id  TEXT,
+    reviewed_role   TEXT,
+    review_comment  TEXT,
+    created_at      TEXT NOT NULL,
+    reviewed_at     TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_pool_change_requests_status_created ON pool_change_requests(status, created_at DESC);
+
+INSERT INTO permissions (id, name, description, category) VALUES
+    ('change_requests:read', 'Read change requests', 'View pool change requests awaiting approval', 'IPAM'),
+    ('change_requests:list', 'List change requests', 'Browse pool change requests', 'IPAM'),
+    ('change_requests:approve', 'Approve change requests', 'Approve or reject pool changes requested by someone else', 'IPAM')
+ON CONFLICT(id) DO UPDATE SET
+    name = excluded.name,
+    description = excluded.description,
+    category = excluded.category;
+
+INSERT OR IGNORE INTO role_permissions (role_id, permission_id)
+SELECT 10, id FROM permissions WHERE id LIKE 'change_requests:%';
+
+-- Operators, viewers and auditors may see requests; approving is admin-only.
+INSERT OR IGNORE INTO role_permissions (role_id, permission_id)
+SELECT 20, id FROM permissions WHERE id IN ('change_requests:read', 'change_requests:list');
+
+INSERT OR IGNORE INTO role_permissions (role_id, permission_id)
+SELECT 30, id FROM permissions WHERE id IN ('change_requests:read', 'change_requests:list');
+
+INSERT OR IGNORE INTO role_permissions (role_id, permission_id)
+SELECT 40, id FROM permissions WHERE id IN ('change_requests:read', 'change_requests:list');
@@ -0,0 +1,19 @@
+-- CIDR reservations: soft holds on a block under a parent pool, kept for an
+-- owner until released or expired. An active reservation holds cidr under
+-- parent_id until expires_at.
+CREATE TABLE IF NOT EXISTS reservations (
+    id          TEXT PRIMARY KEY,
+    cidr        TEXT NOT NULL,
+    parent_id   INTEGER,
+    owner       TEXT NOT NULL,
+    reason      TEXT NOT NULL DEFAULT '',
+    status      TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active','released','expired')),
+    expires_at  TEXT NOT NULL,
+    created_by  TEXT NOT NULL,
+    created_at  TEXT NOT NULL,
+    released_by TEXT,
+    released_at TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_reservations_status_expires ON reservations(status, expires_at);
+CREATE INDEX IF NOT EXISTS idx_reservations_created ON reservations(created_at DESC);
@@ -0,0 +1,6 @@
+-- Store pool prefixes as cidr rather than inet so the column rejects host
+-- bits and CIDROperations can use the cidr operators (&&, >>=, <<=).
+-- network() masks any host bits left by earlier inserts. The GiST inet_ops
+-- and unique indexes cover both types and are rebuilt by the ALTER.
+ALTER TABLE pools
+    ALTER COLUMN cidr TYPE CIDR USING network(cidr);
@@ -0,0 +1,21 @@
+-- Row versions for optimistic concurrency. The API exposes version as the
+-- ETag and checks If-Match against it. Like updated_at, it is maintained by
+-- a trigger so every UPDATE, including soft deletes, moves it forward.
+CREATE OR REPLACE FUNCTION bump_version()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.version = OLD.version + 1;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE pools ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE accounts ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+
+CREATE TRIGGER bump_pools_version
+    BEFORE UPDATE ON pools
+    FOR EACH ROW EXECUTE FUNCTION bump_version();
+
+CREATE TRIGGER bump_accounts_version
+    BEFORE UPDATE ON accounts
+    FOR EACH ROW EXECUTE FUNCTION bump_version();
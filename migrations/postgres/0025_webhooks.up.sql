@@ -0,0 +1,54 @@
+-- CloudPAM PostgreSQL Webhook Schema
+-- Migration 0025: outbound webhook endpoints, their delivery log, and the
+-- webhooks:* permissions for the admin role.
+
+CREATE TABLE IF NOT EXISTS webhooks (
+    id              TEXT PRIMARY KEY,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name            TEXT NOT NULL,
+    url             TEXT NOT NULL,
+    secret          TEXT NOT NULL,
+    events          JSONB NOT NULL DEFAULT '[]',
+    enabled         BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at      TIMESTAMPTZ NOT NULL,
+    updated_at      TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhooks_org ON webhooks(organization_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id              TEXT PRIMARY KEY,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    webhook_id      TEXT NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    event_id        TEXT NOT NULL,
+    event_type      VARCHAR(64) NOT NULL,
+    payload         TEXT NOT NULL,
+    status          VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending','succeeded','failed')),
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    response_status INTEGER,
+    last_error      TEXT,
+    next_attempt_at TIMESTAMPTZ,
+    delivered_at    TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL,
+    updated_at      TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(organization_id, next_attempt_at)
+    WHERE status = 'pending';
+
+INSERT INTO permissions (id, name, description, category) VALUES
+    ('webhooks:create', 'Create webhooks', 'Register outbound webhook endpoints', 'Integrations'),
+    ('webhooks:read', 'Read webhooks', 'View webhook configuration and delivery logs', 'Integrations'),
+    ('webhooks:update', 'Update webhooks', 'Edit webhook endpoints, event filters, secrets, and send test events', 'Integrations'),
+    ('webhooks:delete', 'Delete webhooks', 'Remove webhook endpoints and their delivery logs', 'Integrations'),
+    ('webhooks:list', 'List webhooks', 'Browse webhook endpoints', 'Integrations')
+ON CONFLICT (id) DO UPDATE SET
+    name = EXCLUDED.name,
+    description = EXCLUDED.description,
+    category = EXCLUDED.category;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT '00000000-0000-0000-0000-000000000010'::uuid, id FROM permissions
+WHERE id LIKE 'webhooks:%'
+ON CONFLICT DO NOTHING;
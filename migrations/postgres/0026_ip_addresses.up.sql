@@ -0,0 +1,23 @@
+-- CloudPAM PostgreSQL IP Address Schema
+-- Migration 0026: individual host addresses recorded inside subnet pools,
+-- optionally linked to the discovered network interface holding them.
+
+CREATE TABLE IF NOT EXISTS ip_addresses (
+    id              TEXT PRIMARY KEY,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    pool_id         BIGINT NOT NULL REFERENCES pools(seq_id) ON DELETE CASCADE,
+    address         INET NOT NULL,
+    hostname        TEXT,
+    mac_address     TEXT,
+    owner           TEXT,
+    status          VARCHAR(20) NOT NULL DEFAULT 'assigned' CHECK (status IN ('reserved','assigned','dhcp')),
+    source          VARCHAR(20) NOT NULL DEFAULT 'manual',
+    description     TEXT,
+    resource_id     UUID REFERENCES discovered_resources(id) ON DELETE SET NULL,
+    created_at      TIMESTAMPTZ NOT NULL,
+    updated_at      TIMESTAMPTZ NOT NULL,
+    UNIQUE (pool_id, address)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ip_addresses_org_pool ON ip_addresses(organization_id, pool_id, address);
+CREATE INDEX IF NOT EXISTS idx_ip_addresses_resource ON ip_addresses(resource_id);
@@ -0,0 +1,23 @@
+-- CloudPAM PostgreSQL Discovery Schedule Schema
+-- Migration 0027: per-account discovery schedules driving the server-side
+-- scheduler. next_run_at is claimed with a compare-and-set so replicas never
+-- fire the same run.
+
+CREATE TABLE IF NOT EXISTS discovery_schedules (
+    account_id       BIGINT PRIMARY KEY REFERENCES accounts(seq_id) ON DELETE CASCADE,
+    organization_id  UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    enabled          BOOLEAN NOT NULL DEFAULT TRUE,
+    sync_interval    TEXT,
+    cron_expr        TEXT,
+    detect_drift     BOOLEAN NOT NULL DEFAULT TRUE,
+    next_run_at      TIMESTAMPTZ,
+    last_run_at      TIMESTAMPTZ,
+    last_status      VARCHAR(20),
+    last_error       TEXT,
+    last_sync_job_id UUID,
+    created_at       TIMESTAMPTZ NOT NULL,
+    updated_at       TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_discovery_schedules_due ON discovery_schedules(organization_id, next_run_at)
+    WHERE enabled;
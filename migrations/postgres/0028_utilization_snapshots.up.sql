@@ -0,0 +1,18 @@
+-- CloudPAM PostgreSQL Utilization Snapshot Schema
+-- Migration 0028: periodic pool utilization snapshots backing the history
+-- and exhaustion forecast endpoints.
+
+CREATE TABLE IF NOT EXISTS utilization_snapshots (
+    id              BIGSERIAL PRIMARY KEY,
+    pool_id         BIGINT NOT NULL REFERENCES pools(seq_id) ON DELETE CASCADE,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    total_ips       BIGINT NOT NULL,
+    used_ips        BIGINT NOT NULL,
+    available_ips   BIGINT NOT NULL,
+    utilization     DOUBLE PRECISION NOT NULL,
+    child_count     INTEGER NOT NULL DEFAULT 0,
+    captured_at     TIMESTAMPTZ NOT NULL,
+    UNIQUE(pool_id, captured_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_utilization_snapshots_org_captured ON utilization_snapshots(organization_id, captured_at);
@@ -0,0 +1,30 @@
+-- CloudPAM PostgreSQL Capacity Alert Schema
+-- Migration 0029: capacity alerts raised by the threshold rules stored in
+-- the 'alerting' settings document. At most one active alert exists per
+-- rule, pool and kind.
+
+CREATE TABLE IF NOT EXISTS alerts (
+    id              TEXT PRIMARY KEY,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    rule_id         TEXT NOT NULL,
+    pool_id         BIGINT NOT NULL REFERENCES pools(seq_id) ON DELETE CASCADE,
+    pool_name       TEXT NOT NULL,
+    pool_cidr       TEXT NOT NULL,
+    kind            VARCHAR(20) NOT NULL CHECK (kind IN ('utilization','exhaustion')),
+    severity        VARCHAR(20) NOT NULL CHECK (severity IN ('warning','critical')),
+    status          VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open','acknowledged','resolved')),
+    value           DOUBLE PRECISION NOT NULL,
+    threshold       DOUBLE PRECISION NOT NULL,
+    message         TEXT NOT NULL,
+    opened_at       TIMESTAMPTZ NOT NULL,
+    updated_at      TIMESTAMPTZ NOT NULL,
+    acknowledged_at TIMESTAMPTZ,
+    acknowledged_by TEXT,
+    resolved_at     TIMESTAMPTZ,
+    resolved_by     TEXT,
+    notified_at     TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_alerts_active ON alerts(organization_id, rule_id, pool_id, kind)
+    WHERE status <> 'resolved';
+CREATE INDEX IF NOT EXISTS idx_alerts_org_status_opened ON alerts(organization_id, status, opened_at DESC);
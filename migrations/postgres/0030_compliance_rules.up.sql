@@ -0,0 +1,21 @@
+-- CloudPAM PostgreSQL Compliance Rule Schema
+-- Migration 0030: user-defined compliance rules evaluated next to the
+-- built-in checks. Rule IDs are chosen by users, so they are unique per
+-- organization.
+
+CREATE TABLE IF NOT EXISTS compliance_rules (
+    id              TEXT NOT NULL,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name            TEXT NOT NULL,
+    description     TEXT NOT NULL DEFAULT '',
+    enabled         BOOLEAN NOT NULL DEFAULT TRUE,
+    severity        VARCHAR(20) NOT NULL CHECK (severity IN ('error','warning','info')),
+    enforce         BOOLEAN NOT NULL DEFAULT FALSE,
+    match_spec      JSONB NOT NULL DEFAULT '{}',
+    check_spec      JSONB NOT NULL,
+    message         TEXT NOT NULL DEFAULT '',
+    remediation     TEXT NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ NOT NULL,
+    updated_at      TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (organization_id, id)
+);
@@ -0,0 +1,36 @@
+-- CloudPAM PostgreSQL Audit Hash Chain Schema
+-- Migration 0031: tamper-evident hash chain over audit_events. seq orders an
+-- organization's events in its chain; hash covers each event's canonical
+-- serialization together with prev_hash, the hash of the event before it.
+-- Events recorded before this migration are chained by the audit logger on
+-- first use.
+
+ALTER TABLE audit_events
+    ADD COLUMN IF NOT EXISTS seq BIGINT,
+    ADD COLUMN IF NOT EXISTS prev_hash TEXT,
+    ADD COLUMN IF NOT EXISTS hash TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_events_org_seq
+    ON audit_events (organization_id, seq);
+
+-- Links of events removed by retention, kept while a retained event follows
+-- them so the chain still verifies across the gap.
+CREATE TABLE IF NOT EXISTS audit_chain_links (
+    organization_id UUID NOT NULL,
+    seq             BIGINT NOT NULL,
+    prev_hash       TEXT NOT NULL,
+    hash            TEXT NOT NULL,
+    timestamp       TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (organization_id, seq)
+);
+
+-- Chain heads signed with the server's checkpoint key.
+CREATE TABLE IF NOT EXISTS audit_checkpoints (
+    organization_id UUID NOT NULL,
+    seq             BIGINT NOT NULL,
+    hash            TEXT NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL,
+    key_id          TEXT NOT NULL,
+    signature       TEXT NOT NULL,
+    PRIMARY KEY (organization_id, seq)
+);
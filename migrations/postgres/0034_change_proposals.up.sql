@@ -0,0 +1,28 @@
+-- CloudPAM PostgreSQL Change Proposal Schema
+-- Migration 0034: pool trees from the schema planner or an AI plan that
+-- wait for a second reviewer before they are applied. tree holds the
+-- request, change_set the dry run taken when it was proposed.
+
+CREATE TABLE IF NOT EXISTS change_proposals (
+    id              TEXT PRIMARY KEY,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    title           TEXT NOT NULL,
+    source          VARCHAR(20) NOT NULL CHECK (source IN ('schema','ai_plan')),
+    session_id      TEXT NOT NULL DEFAULT '',
+    status          VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending','applied','rejected')),
+    tree            JSONB NOT NULL,
+    change_set      JSONB NOT NULL,
+    proposed_by     TEXT NOT NULL,
+    proposed_by_id  TEXT NOT NULL DEFAULT '',
+    proposed_role   TEXT NOT NULL DEFAULT '',
+    reviewed_by     TEXT,
+    reviewed_by_id  TEXT,
+    reviewed_role   TEXT,
+    review_comment  TEXT,
+    pool_map        JSONB NOT NULL DEFAULT '{}',
+    created_at      TIMESTAMPTZ NOT NULL,
+    reviewed_at     TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_change_proposals_org_status_created
+    ON change_proposals(organization_id, status, created_at DESC);
@@ -0,0 +1,61 @@
+-- CloudPAM PostgreSQL Pool Change Request Schema
+-- Migration 0035: pool creates, updates and deletes held back by an approval
+-- policy until a second person approves them. payload holds the create or
+-- update body. A pending create holds cidr under parent_id.
+
+CREATE TABLE IF NOT EXISTS pool_change_requests (
+    id              TEXT PRIMARY KEY,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    operation       VARCHAR(20) NOT NULL CHECK (operation IN ('create','update','delete')),
+    status          VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending','applied','rejected')),
+    pool_id         BIGINT,
+    pool_version    BIGINT NOT NULL DEFAULT 0,
+    pool_name       TEXT NOT NULL,
+    cidr            CIDR,
+    parent_id       BIGINT,
+    payload         JSONB NOT NULL DEFAULT '{}',
+    delete_cascade  BOOLEAN NOT NULL DEFAULT FALSE,
+    policy_ids      JSONB NOT NULL DEFAULT '[]',
+    requested_by    TEXT NOT NULL,
+    requested_by_id TEXT NOT NULL DEFAULT '',
+    requested_role  TEXT NOT NULL DEFAULT '',
+    reviewed_by     TEXT,
+    reviewed_by_id  TEXT,
+    reviewed_role   TEXT,
+    review_comment  TEXT,
+    created_at      TIMESTAMPTZ NOT NULL,
+    reviewed_at     TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_pool_change_requests_org_status_created
+    ON pool_change_requests(organization_id, status, created_at DESC);
+
+INSERT INTO permissions (id, name, description, category) VALUES
+    ('change_requests:read', 'Read change requests', 'View pool change requests awaiting approval', 'IPAM'),
+    ('change_requests:list', 'List change requests', 'Browse pool change requests', 'IPAM'),
+    ('change_requests:approve', 'Approve change requests', 'Approve or reject pool changes requested by someone else', 'IPAM')
+ON CONFLICT (id) DO UPDATE SET
+    name = EXCLUDED.name,
+    description = EXCLUDED.description,
+    category = EXCLUDED.category;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT '00000000-0000-0000-0000-000000000010'::uuid, id FROM permissions
+WHERE id LIKE 'change_requests:%'
+ON CONFLICT DO NOTHING;
+
+-- Operators, viewers and auditors may see requests; approving is admin-only.
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT '00000000-0000-0000-0000-000000000020'::uuid, id FROM permissions
+WHERE id IN ('change_requests:read', 'change_requests:list')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT '00000000-0000-0000-0000-000000000030'::uuid, id FROM permissions
+WHERE id IN ('change_requests:read', 'change_requests:list')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT '00000000-0000-0000-0000-000000000040'::uuid, id FROM permissions
+WHERE id IN ('change_requests:read', 'change_requests:list')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,24 @@
+-- CloudPAM PostgreSQL Reservation Schema
+-- Migration 0036: soft holds on a block under a parent pool, kept for an
+-- owner until released or expired. An active reservation holds cidr under
+-- parent_id until expires_at.
+
+CREATE TABLE IF NOT EXISTS reservations (
+    id              TEXT PRIMARY KEY,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    cidr            CIDR NOT NULL,
+    parent_id       BIGINT,
+    owner           TEXT NOT NULL,
+    reason          TEXT NOT NULL DEFAULT '',
+    status          VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active','released','expired')),
+    expires_at      TIMESTAMPTZ NOT NULL,
+    created_by      TEXT NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL,
+    released_by     TEXT,
+    released_at     TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_reservations_org_status_expires
+    ON reservations(organization_id, status, expires_at);
+CREATE INDEX IF NOT EXISTS idx_reservations_org_created
+    ON reservations(organization_id, created_at DESC);
@@ -57,14 +57,29 @@ func (e *APIError) Error() string {
 	return fmt.Sprintf("%s %s: %d %s: %s", e.Method, e.Path, e.StatusCode, http.StatusText(e.StatusCode), msg)
 }
 
-// Is lets errors.Is(err, ErrNotFound) succeed for 404 responses.
+// ErrPreconditionFailed is returned (wrapped in an *APIError) when the API
+// answers 412 because the object changed since the version sent in If-Match.
+var ErrPreconditionFailed = errors.New("precondition failed")
+
+// Is lets errors.Is(err, ErrNotFound) succeed for 404 responses and
+// errors.Is(err, ErrPreconditionFailed) for 412 responses.
 func (e *APIError) Is(target error) bool {
-	return target == ErrNotFound && e.StatusCode == http.StatusNotFound
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrPreconditionFailed:
+		return e.StatusCode == http.StatusPreconditionFailed
+	}
+	return false
 }
 
 // IsNotFound reports whether err represents a 404 from the CloudPAM API.
 func IsNotFound(err error) bool { return errors.Is(err, ErrNotFound) }
 
+// IsPreconditionFailed reports whether err represents a 412 from the CloudPAM
+// API, i.e. a lost update.
+func IsPreconditionFailed(err error) bool { return errors.Is(err, ErrPreconditionFailed) }
+
 // Client talks to a CloudPAM server.
 type Client struct {
 	baseURL   *url.URL
@@ -139,6 +154,11 @@ func (c *Client) BaseURL() string { return c.baseURL.String() }
 // do performs an API call. body, when non-nil, is JSON encoded. out, when
 // non-nil, receives the decoded JSON response.
 func (c *Client) do(ctx context.Context, method, path string, query url.Values, body any, out any) error {
+	return c.doWithHeader(ctx, method, path, query, nil, body, out)
+}
+
+// doWithHeader is do with extra request headers, such as If-Match.
+func (c *Client) doWithHeader(ctx context.Context, method, path string, query url.Values, header http.Header, body any, out any) error {
 	var reader io.Reader
 	if body != nil {
 		buf, err := json.Marshal(body)
@@ -164,6 +184,11 @@ func (c *Client) do(ctx context.Context, method, path string, query url.Values,
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
+	for k, vs := range header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
 
 	resp, err := c.httpc.Do(req)
 	if err != nil {
@@ -20,8 +20,11 @@ type Pool struct {
 	Source      string            `json:"source,omitempty"`
 	Description string            `json:"description,omitempty"`
 	Tags        map[string]string `json:"tags,omitempty"`
-	CreatedAt   string            `json:"created_at,omitempty"`
-	UpdatedAt   string            `json:"updated_at,omitempty"`
+	// Version increases on every server-side write. Pass it back as
+	// PoolUpdate.IfVersion to detect concurrent changes.
+	Version   int64  `json:"version,omitempty"`
+	CreatedAt string `json:"created_at,omitempty"`
+	UpdatedAt string `json:"updated_at,omitempty"`
 }
 
 // PoolCreate mirrors domain.CreatePool. Nil ParentID/AccountID are omitted so
@@ -50,6 +53,9 @@ type PoolCreate struct {
 // domain.UpdatePool uses server-side — cannot express the first state, so the
 // request body is assembled as a map instead. Set SetAccountID to include the
 // key; leave AccountID nil at the same time to send an explicit null.
+//
+// A non-zero IfVersion is sent as If-Match; the server then answers 412 if the
+// pool has been written since that version was read.
 type PoolUpdate struct {
 	Name        *string
 	Type        *string
@@ -59,6 +65,8 @@ type PoolUpdate struct {
 
 	SetAccountID bool
 	AccountID    *int64
+
+	IfVersion int64
 }
 
 // body renders the PATCH payload. Only explicitly-set fields are included.
@@ -116,9 +124,15 @@ func (c *Client) CreatePool(ctx context.Context, in PoolCreate) (*Pool, error) {
 
 // UpdatePool patches a pool. Note that cidr, parent_id and source are immutable
 // server-side; the provider marks those attributes as requiring replacement.
+// A stale IfVersion yields an error satisfying errors.Is(err,
+// ErrPreconditionFailed).
 func (c *Client) UpdatePool(ctx context.Context, id int64, in PoolUpdate) (*Pool, error) {
+	var header http.Header
+	if in.IfVersion != 0 {
+		header = http.Header{"If-Match": []string{`"` + strconv.FormatInt(in.IfVersion, 10) + `"`}}
+	}
 	var out Pool
-	if err := c.do(ctx, http.MethodPatch, poolPath(id), nil, in.body(), &out); err != nil {
+	if err := c.doWithHeader(ctx, http.MethodPatch, poolPath(id), nil, header, in.body(), &out); err != nil {
 		return nil, err
 	}
 	return &out, nil
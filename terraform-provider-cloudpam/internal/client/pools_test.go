@@ -315,6 +315,36 @@ func TestUpdatePoolEmptyBodyIsEmptyObject(t *testing.T) {
 	}
 }
 
+func TestUpdatePoolIfVersion(t *testing.T) {
+	var ifMatch []string
+	c, _ := newTestClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ifMatch = append(ifMatch, r.Header.Get("If-Match"))
+		if r.Header.Get("If-Match") == `"4"` {
+			writeRaw(t, w, http.StatusPreconditionFailed, `{"error":"pool 3 is at version 5: precondition failed"}`)
+			return
+		}
+		writeJSON(t, w, http.StatusOK, Pool{ID: 3, Version: 6})
+	}))
+
+	_, err := c.UpdatePool(context.Background(), 3, PoolUpdate{Name: strPtr("x"), IfVersion: 4})
+	if !IsPreconditionFailed(err) {
+		t.Fatalf("stale version: err = %v, want ErrPreconditionFailed", err)
+	}
+	if IsNotFound(err) {
+		t.Errorf("412 must not satisfy IsNotFound")
+	}
+	p, err := c.UpdatePool(context.Background(), 3, PoolUpdate{Name: strPtr("x"), IfVersion: 5})
+	if err != nil || p.Version != 6 {
+		t.Fatalf("UpdatePool() = %+v, %v", p, err)
+	}
+	if _, err := c.UpdatePool(context.Background(), 3, PoolUpdate{Name: strPtr("x")}); err != nil {
+		t.Fatalf("unconditional UpdatePool() error = %v", err)
+	}
+	if want := []string{`"4"`, `"5"`, ""}; !reflect.DeepEqual(ifMatch, want) {
+		t.Errorf("If-Match headers = %q, want %q", ifMatch, want)
+	}
+}
+
 func TestDeletePool(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -45,6 +45,7 @@ type poolResourceModel struct {
 	Source       types.String `tfsdk:"source"`
 	Description  types.String `tfsdk:"description"`
 	Tags         types.Map    `tfsdk:"tags"`
+	Version      types.Int64  `tfsdk:"version"`
 	CreatedAt    types.String `tfsdk:"created_at"`
 	UpdatedAt    types.String `tfsdk:"updated_at"`
 	ForceDestroy types.Bool   `tfsdk:"force_destroy"`
@@ -118,6 +119,10 @@ func (r *poolResource) Schema(_ context.Context, _ resource.SchemaRequest, resp
 				Default:             mapdefault.StaticValue(types.MapValueMust(types.StringType, map[string]attr.Value{})),
 				MarkdownDescription: "Key/value tags stored with the pool.",
 			},
+			"version": schema.Int64Attribute{
+				Computed:            true,
+				MarkdownDescription: "Server-side version of the pool, incremented on every write. Updates send it as `If-Match` so a change made outside Terraform since the last refresh fails instead of being overwritten.",
+			},
 			"created_at": schema.StringAttribute{
 				Computed:            true,
 				MarkdownDescription: "RFC 3339 creation timestamp.",
@@ -242,6 +247,9 @@ func (r *poolResource) Update(ctx context.Context, req resource.UpdateRequest, r
 		// assignment: the server keeps the current value when the key is absent.
 		SetAccountID: true,
 		AccountID:    plan.AccountID.ValueInt64Pointer(),
+		// State written before the server reported versions holds null, which
+		// becomes 0 and sends an unconditional update.
+		IfVersion: state.Version.ValueInt64(),
 	}
 	if v := stringOrEmpty(plan.Type); v != "" {
 		update.Type = &v
@@ -259,6 +267,13 @@ func (r *poolResource) Update(ctx context.Context, req resource.UpdateRequest, r
 			)
 			return
 		}
+		if client.IsPreconditionFailed(err) {
+			resp.Diagnostics.AddError(
+				"CloudPAM pool was modified outside of Terraform",
+				fmt.Sprintf("Pool %d changed since it was last read, so the update was rejected to avoid overwriting it. Run `terraform apply -refresh-only` and re-plan.", state.ID.ValueInt64()),
+			)
+			return
+		}
 		resp.Diagnostics.AddError("Unable to update CloudPAM pool", err.Error())
 		return
 	}
@@ -319,6 +334,7 @@ func applyPoolToModel(p *client.Pool, m *poolResourceModel) diag.Diagnostics {
 	m.Source = types.StringValue(p.Source)
 	m.Description = types.StringValue(p.Description)
 	m.Tags = tags
+	m.Version = types.Int64Value(p.Version)
 	m.CreatedAt = types.StringValue(p.CreatedAt)
 	m.UpdatedAt = types.StringValue(p.UpdatedAt)
 	if m.ForceDestroy.IsNull() || m.ForceDestroy.IsUnknown() {
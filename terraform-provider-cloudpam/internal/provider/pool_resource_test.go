@@ -137,6 +137,16 @@ func TestApplyPoolToModelPreservesForceDestroy(t *testing.T) {
 	}
 }
 
+func TestApplyPoolToModelCopiesVersion(t *testing.T) {
+	var m poolResourceModel
+	if diags := applyPoolToModel(&client.Pool{ID: 1, Version: 4}, &m); diags.HasError() {
+		t.Fatalf("diagnostics: %v", diags)
+	}
+	if !m.Version.Equal(types.Int64Value(4)) {
+		t.Fatalf("Version = %v, want 4 so the next update sends If-Match", m.Version)
+	}
+}
+
 func TestMatchesInt64Filter(t *testing.T) {
 	tests := []struct {
 		name   string
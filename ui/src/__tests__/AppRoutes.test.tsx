@@ -59,6 +59,8 @@ function authState(overrides: Partial<AuthContextValue> = {}): AuthContextValue
     needsSetup: false,
     authChecked: true,
     loginWithPassword: async () => {},
+    completeMFALogin: async () => {},
+    enrollMFALogin: async () => ({ secret: '', otpauth_uri: '', recovery_codes: [] }),
     logout: () => {},
     ...overrides,
   }
@@ -27,10 +27,14 @@ vi.mock('react-router-dom', async () => {
 })
 
 const loginWithPassword = vi.fn()
+const completeMFALogin = vi.fn()
+const enrollMFALogin = vi.fn()
 
 function auth(overrides: Partial<AuthContextValue> = {}) {
   return {
     loginWithPassword,
+    completeMFALogin,
+    enrollMFALogin,
     isAuthenticated: false,
     needsSetup: false,
     authChecked: true,
@@ -227,4 +231,55 @@ describe('LoginPage sign-in modes', () => {
     fireEvent.click(screen.getByRole('button', { name: 'Hide password' }))
     expect(passwordInput.type).toBe('password')
   })
+
+  it('asks for an authentication code when the account has MFA', async () => {
+    loginWithPassword.mockResolvedValue({ mfa_required: true, mfa_enrollment_required: false, mfa_token: 'tok', expires_at: '' })
+    completeMFALogin.mockResolvedValue(undefined)
+
+    renderPage()
+    await signIn('admin', 'correct-horse-battery')
+
+    const codeInput = await screen.findByLabelText('Authentication code')
+    expect(mockNavigate).not.toHaveBeenCalled()
+    expect(enrollMFALogin).not.toHaveBeenCalled()
+
+    fireEvent.change(codeInput, { target: { value: '123456' } })
+    fireEvent.click(screen.getByRole('button', { name: 'Verify' }))
+
+    await waitFor(() => {
+      expect(completeMFALogin).toHaveBeenCalledWith('tok', { code: '123456' })
+    })
+    await waitFor(() => {
+      expect(mockNavigate).toHaveBeenCalledWith('/')
+    })
+  })
+
+  it('accepts a recovery code instead of an authenticator code', async () => {
+    loginWithPassword.mockResolvedValue({ mfa_required: true, mfa_enrollment_required: false, mfa_token: 'tok', expires_at: '' })
+    completeMFALogin.mockResolvedValue(undefined)
+
+    renderPage()
+    await signIn('admin', 'correct-horse-battery')
+
+    fireEvent.click(await screen.findByRole('button', { name: 'Use a recovery code' }))
+    fireEvent.change(screen.getByLabelText('Recovery code'), { target: { value: 'abcd-efgh' } })
+    fireEvent.click(screen.getByRole('button', { name: 'Verify' }))
+
+    await waitFor(() => {
+      expect(completeMFALogin).toHaveBeenCalledWith('tok', { recovery_code: 'abcd-efgh' })
+    })
+  })
+
+  it('shows the new secret and recovery codes when enrollment is required', async () => {
+    loginWithPassword.mockResolvedValue({ mfa_required: true, mfa_enrollment_required: true, mfa_token: 'tok', expires_at: '' })
+    enrollMFALogin.mockResolvedValue({ secret: 'JBSWY3DPEHPK3PXP', otpauth_uri: 'otpauth://totp/CloudPAM:admin?secret=JBSWY3DPEHPK3PXP', recovery_codes: ['aaaa-bbbb', 'cccc-dddd'] })
+
+    renderPage()
+    await signIn('admin', 'correct-horse-battery')
+
+    expect(await screen.findByText('JBSWY3DPEHPK3PXP')).toBeTruthy()
+    expect(enrollMFALogin).toHaveBeenCalledWith('tok')
+    expect(screen.getByText('aaaa-bbbb')).toBeTruthy()
+    expect(screen.queryByRole('button', { name: 'Use a recovery code' })).toBeNull()
+  })
 })
@@ -73,6 +73,8 @@ function authValue(permissions: string[], role = 'operator'): AuthContextValue {
     needsSetup: false,
     authChecked: true,
     loginWithPassword: async () => {},
+    completeMFALogin: async () => {},
+    enrollMFALogin: async () => ({ secret: '', otpauth_uri: '', recovery_codes: [] }),
     logout: () => {},
   }
 }
@@ -18,6 +18,7 @@ export interface SchemaConflict {
   existing_pool_name: string
   existing_cidr: string
   overlap_type: string
+  reservation_id?: string
 }
 
 export interface SchemaCheckResponse {
@@ -340,13 +341,28 @@ export interface LoginResponse {
   permissions?: string[]
 }
 
+// Returned by /api/v1/auth/login in place of a session when the user must
+// enter, or first enroll, a TOTP code.
+export interface MFAChallenge {
+  mfa_required: true
+  mfa_enrollment_required: boolean
+  mfa_token: string
+  expires_at: string
+}
+
+export interface MFAEnrollment {
+  secret: string
+  otpauth_uri: string
+  recovery_codes: string[]
+}
+
 export interface MeResponse {
   auth_type: 'session' | 'api_key'
   role: string
   user?: UserInfo
   key_id?: string
   key_name?: string
-  auth_provider?: 'local' | 'oidc'
+  auth_provider?: 'local' | 'oidc' | 'scim'
   session_expires_at?: string
   permissions?: string[]
 }
@@ -751,7 +767,7 @@ export interface NetworkConflictActionResponse {
 
 // --- Recommendation types ---
 
-export type RecommendationType = 'allocation' | 'compliance'
+export type RecommendationType = 'allocation' | 'compliance' | 'consolidation' | 'resize' | 'reclaim'
 export type RecommendationStatus = 'pending' | 'applied' | 'dismissed'
 export type RecommendationPriority = 'high' | 'medium' | 'low'
 
@@ -769,10 +785,19 @@ export interface Recommendation {
   metadata?: Record<string, string>
   dismiss_reason?: string
   applied_pool_id?: number | null
+  changes?: RecommendationChange[]
   created_at: string
   updated_at: string
 }
 
+export interface RecommendationChange {
+  action: 'create' | 'update' | 'delete'
+  pool_id: number
+  name: string
+  before?: Record<string, unknown>
+  after?: Record<string, unknown>
+}
+
 export interface RecommendationsListResponse {
   items: Recommendation[]
   total: number
@@ -1,5 +1,5 @@
 import { createContext, useContext, useState, useEffect, useCallback } from 'react'
-import type { HealthResponse, UserInfo, LoginResponse, MeResponse } from '../api/types'
+import type { HealthResponse, UserInfo, LoginResponse, MeResponse, MFAChallenge, MFAEnrollment } from '../api/types'
 
 // Non-sensitive display metadata persisted in localStorage for UX continuity.
 const AUTH_NAME_KEY = 'cloudpam_key_name'
@@ -21,7 +21,10 @@ export interface AuthContextValue {
   localAuthEnabled: boolean
   needsSetup: boolean
   authChecked: boolean
-  loginWithPassword: (username: string, password: string) => Promise<void>
+  // Resolves to a challenge when the account needs a second factor.
+  loginWithPassword: (username: string, password: string) => Promise<MFAChallenge | void>
+  completeMFALogin: (mfaToken: string, code: { code?: string; recovery_code?: string }) => Promise<void>
+  enrollMFALogin: (mfaToken: string) => Promise<MFAEnrollment>
   logout: () => void
 }
 
@@ -38,6 +41,8 @@ export const AuthContext = createContext<AuthContextValue>({
   needsSetup: false,
   authChecked: false,
   loginWithPassword: async () => {},
+  completeMFALogin: async () => {},
+  enrollMFALogin: async () => ({ secret: '', otpauth_uri: '', recovery_codes: [] }),
   logout: () => {},
 })
 
@@ -130,6 +135,18 @@ export function useAuthState(): AuthContextValue {
     return () => window.removeEventListener('auth:logout', handleLogout)
   }, [])
 
+  const applyLogin = useCallback((data: LoginResponse) => {
+    setIsAuthenticated(true)
+    setCurrentUser(data.user)
+    setRole(data.user.role)
+    setPermissions(data.permissions ?? [])
+    setKeyName(data.user.display_name || data.user.username)
+    setAuthType('session')
+
+    localStorage.setItem(AUTH_NAME_KEY, data.user.display_name || data.user.username)
+    localStorage.setItem(AUTH_TYPE_KEY, 'session')
+  }, [])
+
   const loginWithPassword = useCallback(async (username: string, password: string) => {
     const res = await fetch('/api/v1/auth/login', {
       method: 'POST',
@@ -143,17 +160,43 @@ export function useAuthState(): AuthContextValue {
       throw new Error(body.error || 'Login failed')
     }
 
-    const data: LoginResponse = await res.json()
+    const data: LoginResponse | MFAChallenge = await res.json()
+    if ('mfa_required' in data && data.mfa_required) {
+      return data
+    }
+    applyLogin(data as LoginResponse)
+  }, [applyLogin])
 
-    setIsAuthenticated(true)
-    setCurrentUser(data.user)
-    setRole(data.user.role)
-    setPermissions(data.permissions ?? [])
-    setKeyName(data.user.display_name || data.user.username)
-    setAuthType('session')
+  const completeMFALogin = useCallback(async (mfaToken: string, code: { code?: string; recovery_code?: string }) => {
+    const res = await fetch('/api/v1/auth/login/mfa', {
+      method: 'POST',
+      credentials: 'same-origin',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ mfa_token: mfaToken, ...code }),
+    })
 
-    localStorage.setItem(AUTH_NAME_KEY, data.user.display_name || data.user.username)
-    localStorage.setItem(AUTH_TYPE_KEY, 'session')
+    if (!res.ok) {
+      const body = await res.json().catch(() => ({ error: 'Verification failed' }))
+      throw new Error(body.error || 'Verification failed')
+    }
+
+    applyLogin(await res.json())
+  }, [applyLogin])
+
+  const enrollMFALogin = useCallback(async (mfaToken: string): Promise<MFAEnrollment> => {
+    const res = await fetch('/api/v1/auth/login/mfa/enroll', {
+      method: 'POST',
+      credentials: 'same-origin',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ mfa_token: mfaToken }),
+    })
+
+    if (!res.ok) {
+      const body = await res.json().catch(() => ({ error: 'Enrollment failed' }))
+      throw new Error(body.error || 'Enrollment failed')
+    }
+
+    return res.json()
   }, [])
 
   const logout = useCallback(async () => {
@@ -192,6 +235,8 @@ export function useAuthState(): AuthContextValue {
     needsSetup,
     authChecked,
     loginWithPassword,
+    completeMFALogin,
+    enrollMFALogin,
     logout,
   }
 }
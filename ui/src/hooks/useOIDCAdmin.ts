@@ -1,6 +1,16 @@
 import { useState, useEffect, useCallback } from 'react'
 import { get, post, patch, del } from '../api/client'
 
+// A role rule assigns `role` when the ID token claim at `claim` (a dot
+// path such as "realm_access.roles") contains `value`. Lower priorities are
+// tried first.
+export interface OIDCRoleRule {
+  claim: string
+  value: string
+  role: string
+  priority: number
+}
+
 export interface OIDCProvider {
   id: string
   name: string
@@ -9,6 +19,7 @@ export interface OIDCProvider {
   client_secret?: string
   scopes: string
   role_mapping: Record<string, string>
+  role_rules: OIDCRoleRule[] | null
   default_role: string
   auto_provision: boolean
   enabled: boolean
@@ -23,6 +34,7 @@ export interface OIDCProviderCreate {
   client_secret: string
   scopes?: string
   role_mapping?: Record<string, string>
+  role_rules?: OIDCRoleRule[]
   default_role?: string
   auto_provision?: boolean
   enabled?: boolean
@@ -35,6 +47,7 @@ export interface OIDCProviderUpdate {
   client_secret?: string
   scopes?: string
   role_mapping?: Record<string, string>
+  role_rules?: OIDCRoleRule[]
   default_role?: string
   auto_provision?: boolean
   enabled?: boolean
@@ -7,7 +7,10 @@ const DEBOUNCE_MS = 300
 
 // Detect if input looks like a CIDR or IP address
 function isCIDROrIP(s: string): boolean {
-  return /^\d{1,3}\.\d{1,3}(\.\d{1,3}(\.\d{1,3})?)?(\/\d{1,2})?$/.test(s.trim())
+  const t = s.trim()
+  if (/^\d{1,3}\.\d{1,3}(\.\d{1,3}(\.\d{1,3})?)?(\/\d{1,2})?$/.test(t)) return true
+  // IPv6: hex groups with at least two colons, e.g. 2001:db8::1 or 2001:db8::/32
+  return /^[0-9a-fA-F:]*:[0-9a-fA-F]*:[0-9a-fA-F:]*(\/\d{1,3})?$/.test(t)
 }
 
 export function useSearch() {
@@ -3,6 +3,7 @@ import type { MeResponse } from '../api/types'
 
 const POLL_INTERVAL_MS = 60_000 // 60 seconds
 const LIFETIME_THRESHOLD = 0.2 // trigger refresh when 20% of lifetime remains
+const ROLE_SYNC_INTERVAL_MS = 15 * 60_000 // re-check IdP roles every 15 minutes
 
 export interface OIDCRefreshMessage {
   type: 'oidc-refresh'
@@ -44,9 +45,15 @@ export function parseRefreshMessage(
  * re-authentication with the IdP. On success the session cookie is refreshed
  * automatically. On failure a toast-style event is dispatched so the user
  * knows they need to log in again.
+ *
+ * Every 15 minutes it also calls the refresh endpoint without waiting for
+ * expiry. When the server holds a refresh token it renews the session there
+ * and re-evaluates the user's role against current IdP claims; otherwise the
+ * check is skipped until the session nears expiry.
  */
 export function useSessionRefresh() {
   const refreshAttemptedRef = useRef(false)
+  const lastRoleSyncRef = useRef(Date.now())
   const iframeRef = useRef<HTMLIFrameElement | null>(null)
 
   useEffect(() => {
@@ -98,8 +105,8 @@ export function useSessionRefresh() {
 
         const me: MeResponse = await res.json()
 
-        // Only act on OIDC sessions.
-        if (me.auth_type !== 'session' || me.auth_provider !== 'oidc') return
+        // Only act on SSO sessions (OIDC, including SCIM-provisioned users).
+        if (me.auth_type !== 'session' || (me.auth_provider !== 'oidc' && me.auth_provider !== 'scim')) return
         if (!me.session_expires_at) return
 
         const expiresAt = new Date(me.session_expires_at).getTime()
@@ -116,17 +123,20 @@ export function useSessionRefresh() {
         const totalDuration = 24 * 60 * 60 * 1000 // 24 hours in ms
         const threshold = totalDuration * LIFETIME_THRESHOLD
 
-        if (remaining > threshold) {
+        const expiring = remaining <= threshold
+        const roleSyncDue = now - lastRoleSyncRef.current >= ROLE_SYNC_INTERVAL_MS
+        if (!expiring) {
           // Not yet in the danger zone.
           refreshAttemptedRef.current = false
-          return
+          if (!roleSyncDue) return
+        } else {
+          // In the last 20% of lifetime — attempt silent refresh.
+          if (refreshAttemptedRef.current) return
+          refreshAttemptedRef.current = true
         }
+        lastRoleSyncRef.current = now
 
-        // In the last 20% of lifetime — attempt silent refresh.
-        if (refreshAttemptedRef.current) return
-        refreshAttemptedRef.current = true
-
-        // Get the redirect URL from the backend.
+        // Ask the backend to renew the session, or for a silent re-auth URL.
         const csrfMatch = document.cookie.match(/(?:^|;\s*)csrf_token=([^;]+)/)
         const csrfToken = csrfMatch ? csrfMatch[1] : ''
 
@@ -144,9 +154,18 @@ export function useSessionRefresh() {
           return
         }
 
-        const { redirect_url } = (await refreshRes.json()) as {
-          redirect_url: string
+        const { redirect_url, refreshed } = (await refreshRes.json()) as {
+          redirect_url?: string
+          refreshed?: boolean
+        }
+
+        // Renewed server-side with a refresh token; nothing else to do.
+        if (refreshed) {
+          refreshAttemptedRef.current = false
+          return
         }
+        // Without a refresh token, only fall back to the iframe near expiry.
+        if (!expiring || !redirect_url) return
 
         // Create hidden iframe for silent re-auth.
         cleanup()
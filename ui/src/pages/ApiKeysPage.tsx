@@ -8,16 +8,20 @@ import type { ApiKeyCreateResponse } from '../api/types'
 // Must match backend validScopes in auth_handlers.go createAPIKey
 const SCOPE_OPTIONS = [
   'pools:read', 'pools:write',
+  'change_requests:read', 'change_requests:approve',
   'accounts:read', 'accounts:write',
   'keys:read', 'keys:write',
   'discovery:read', 'discovery:write',
   'audit:read',
+  'scim:provision',
   '*',
 ]
 
 const SCOPE_LABELS: Record<string, string> = {
   'pools:read': 'Pools Read',
   'pools:write': 'Pools Write',
+  'change_requests:read': 'Change Requests Read',
+  'change_requests:approve': 'Change Requests Approve',
   'accounts:read': 'Accounts Read',
   'accounts:write': 'Accounts Write',
   'keys:read': 'Keys Read',
@@ -25,6 +29,7 @@ const SCOPE_LABELS: Record<string, string> = {
   'discovery:read': 'Discovery Read',
   'discovery:write': 'Discovery Write',
   'audit:read': 'Audit Read',
+  'scim:provision': 'SCIM Provisioning',
   '*': 'Admin (all)',
 }
 
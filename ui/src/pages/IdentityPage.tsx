@@ -6,7 +6,7 @@ import { useSecuritySettings } from '../hooks/useSettings'
 import { useOIDCAdmin } from '../hooks/useOIDCAdmin'
 import { permissionID, useRoles } from '../hooks/useRoles'
 import { useAuth } from '../hooks/useAuth'
-import type { OIDCProvider, OIDCProviderCreate, OIDCProviderUpdate } from '../hooks/useOIDCAdmin'
+import type { OIDCProvider, OIDCProviderCreate, OIDCProviderUpdate, OIDCRoleRule } from '../hooks/useOIDCAdmin'
 import UsersAdminPanel from '../components/UsersAdminPanel'
 
 // Each tab is gated on the permission the API enforces for the data it renders:
@@ -42,9 +42,16 @@ function ProviderFormModal({ provider, onSave, onClose }: ProviderFormProps) {
   const [roleMappingEntries, setRoleMappingEntries] = useState<Array<{ group: string; role: string }>>(
     provider?.role_mapping ? Object.entries(provider.role_mapping).map(([group, role]) => ({ group, role })) : []
   )
+  const [roleRules, setRoleRules] = useState<OIDCRoleRule[]>(provider?.role_rules ?? [])
+  const { roles } = useRoles()
+  const roleNames = roles.length > 0 ? roles.map(role => role.name) : ['admin', 'operator', 'viewer', 'auditor']
   const [saving, setSaving] = useState(false)
   const [error, setError] = useState<string | null>(null)
 
+  function updateRule(index: number, changes: Partial<OIDCRoleRule>) {
+    setRoleRules(prev => prev.map((rule, idx) => idx === index ? { ...rule, ...changes } : rule))
+  }
+
   function updateMapping(index: number, field: 'group' | 'role', value: string) {
     setRoleMappingEntries(prev => prev.map((entry, idx) => idx === index ? { ...entry, [field]: value } : entry))
   }
@@ -62,6 +69,9 @@ function ProviderFormModal({ provider, onSave, onClose }: ProviderFormProps) {
         roleMapping[entry.group.trim()] = entry.role
       }
     }
+    const rules = roleRules
+      .filter(rule => rule.claim.trim() && rule.value.trim())
+      .map(rule => ({ ...rule, claim: rule.claim.trim(), value: rule.value.trim() }))
 
     setSaving(true)
     try {
@@ -75,6 +85,7 @@ function ProviderFormModal({ provider, onSave, onClose }: ProviderFormProps) {
           auto_provision: autoProvision,
           enabled,
           role_mapping: roleMapping,
+          role_rules: rules,
         }
         if (clientSecret.trim()) updates.client_secret = clientSecret.trim()
         await onSave(updates)
@@ -89,6 +100,7 @@ function ProviderFormModal({ provider, onSave, onClose }: ProviderFormProps) {
           auto_provision: autoProvision,
           enabled,
           role_mapping: roleMapping,
+          role_rules: rules,
         })
       }
       onClose()
@@ -138,10 +150,7 @@ function ProviderFormModal({ provider, onSave, onClose }: ProviderFormProps) {
             <label className="text-sm">
               <span className="block mb-1 text-gray-700 dark:text-gray-300">Default Role</span>
               <select value={defaultRole} onChange={e => setDefaultRole(e.target.value)} className="w-full px-3 py-2 border rounded-lg text-sm dark:bg-gray-700 dark:border-gray-600 dark:text-white">
-                <option value="admin">Admin</option>
-                <option value="operator">Operator</option>
-                <option value="viewer">Viewer</option>
-                <option value="auditor">Auditor</option>
+                {roleNames.map(role => <option key={role} value={role}>{roleLabel(role)}</option>)}
               </select>
             </label>
           </div>
@@ -178,6 +187,55 @@ function ProviderFormModal({ provider, onSave, onClose }: ProviderFormProps) {
             ))}
           </div>
 
+          <div className="space-y-2">
+            <div className="flex items-center justify-between">
+              <h3 className="text-sm font-medium text-gray-900 dark:text-white">Role Rules</h3>
+              <button
+                type="button"
+                onClick={() => setRoleRules(prev => [...prev, { claim: 'groups', value: '', role: 'viewer', priority: (prev.length + 1) * 10 }])}
+                className="text-sm text-blue-600 dark:text-blue-400"
+              >
+                Add rule
+              </button>
+            </div>
+            <p className="text-xs text-gray-500 dark:text-gray-400">
+              Rules can match any claim path and assign custom roles. The lowest priority that matches wins and takes precedence over the mapping above. Roles are re-evaluated at every sign-in and session refresh.
+            </p>
+            {roleRules.map((rule, idx) => (
+              <div key={idx} className="flex items-center gap-2">
+                <input
+                  type="number"
+                  value={rule.priority}
+                  onChange={e => updateRule(idx, { priority: Number(e.target.value) })}
+                  aria-label="Priority"
+                  className="w-20 px-3 py-2 border rounded-lg text-sm dark:bg-gray-700 dark:border-gray-600 dark:text-white"
+                />
+                <input
+                  value={rule.claim}
+                  onChange={e => updateRule(idx, { claim: e.target.value })}
+                  placeholder="Claim path, e.g. realm_access.roles"
+                  className="flex-1 px-3 py-2 border rounded-lg text-sm dark:bg-gray-700 dark:border-gray-600 dark:text-white"
+                />
+                <input
+                  value={rule.value}
+                  onChange={e => updateRule(idx, { value: e.target.value })}
+                  placeholder="Value"
+                  className="flex-1 px-3 py-2 border rounded-lg text-sm dark:bg-gray-700 dark:border-gray-600 dark:text-white"
+                />
+                <select
+                  value={rule.role}
+                  onChange={e => updateRule(idx, { role: e.target.value })}
+                  className="px-3 py-2 border rounded-lg text-sm dark:bg-gray-700 dark:border-gray-600 dark:text-white"
+                >
+                  {roleNames.map(role => <option key={role} value={role}>{roleLabel(role)}</option>)}
+                </select>
+                <button type="button" onClick={() => setRoleRules(prev => prev.filter((_, ruleIdx) => ruleIdx !== idx))} className="p-2 text-red-600">
+                  <Trash2 className="w-4 h-4" />
+                </button>
+              </div>
+            ))}
+          </div>
+
           <div className="flex items-center gap-6 text-sm text-gray-700 dark:text-gray-300">
             <label className="flex items-center gap-2">
               <input type="checkbox" checked={autoProvision} onChange={e => setAutoProvision(e.target.checked)} />
@@ -3,9 +3,10 @@ import { useNavigate } from 'react-router-dom'
 import { Eye, EyeOff, Server, User } from 'lucide-react'
 import { useAuth } from '../hooks/useAuth'
 import { useOIDCProviders } from '../hooks/useOIDCProviders'
+import type { MFAChallenge, MFAEnrollment } from '../api/types'
 
 export default function LoginPage() {
-  const { loginWithPassword, isAuthenticated, needsSetup, authChecked, localAuthEnabled } = useAuth()
+  const { loginWithPassword, completeMFALogin, enrollMFALogin, isAuthenticated, needsSetup, authChecked, localAuthEnabled } = useAuth()
   const { providers, loading: providersLoading } = useOIDCProviders()
   const navigate = useNavigate()
 
@@ -14,6 +15,10 @@ export default function LoginPage() {
   const [showPassword, setShowPassword] = useState(false)
   const [error, setError] = useState('')
   const [loading, setLoading] = useState(false)
+  const [challenge, setChallenge] = useState<MFAChallenge | null>(null)
+  const [enrollment, setEnrollment] = useState<MFAEnrollment | null>(null)
+  const [code, setCode] = useState('')
+  const [useRecoveryCode, setUseRecoveryCode] = useState(false)
 
   // Redirect to setup if fresh install, or home if already authenticated
   useEffect(() => {
@@ -30,7 +35,15 @@ export default function LoginPage() {
     setLoading(true)
     setError('')
     try {
-      await loginWithPassword(username.trim(), password)
+      const mfa = await loginWithPassword(username.trim(), password)
+      if (mfa) {
+        setChallenge(mfa)
+        setPassword('')
+        if (mfa.mfa_enrollment_required) {
+          setEnrollment(await enrollMFALogin(mfa.mfa_token))
+        }
+        return
+      }
       navigate('/')
     } catch (err) {
       setError(err instanceof Error ? err.message : 'Login failed')
@@ -39,6 +52,30 @@ export default function LoginPage() {
     }
   }
 
+  async function handleMFASubmit(e: React.FormEvent) {
+    e.preventDefault()
+    if (!challenge || !code.trim()) return
+    setLoading(true)
+    setError('')
+    try {
+      await completeMFALogin(challenge.mfa_token, useRecoveryCode ? { recovery_code: code.trim() } : { code: code.trim() })
+      navigate('/')
+    } catch (err) {
+      setError(err instanceof Error ? err.message : 'Verification failed')
+      setCode('')
+    } finally {
+      setLoading(false)
+    }
+  }
+
+  function restartLogin() {
+    setChallenge(null)
+    setEnrollment(null)
+    setCode('')
+    setUseRecoveryCode(false)
+    setError('')
+  }
+
   // Don't render until we know the auth configuration
   if (!authChecked) {
     return (
@@ -60,7 +97,63 @@ export default function LoginPage() {
         </div>
 
         <div className="bg-white dark:bg-gray-800 rounded-xl shadow-lg p-6">
-          {localAuthEnabled && (
+          {challenge && (
+            <form onSubmit={handleMFASubmit}>
+              {enrollment && (
+                <div className="mb-4 text-sm text-gray-700 dark:text-gray-300 space-y-2">
+                  <p>Your account requires two-factor authentication. Add this key to your authenticator app:</p>
+                  <code className="block break-all bg-gray-100 dark:bg-gray-700 px-2 py-1 rounded">{enrollment.secret}</code>
+                  <a href={enrollment.otpauth_uri} className="text-blue-600 hover:underline text-xs">Open in authenticator app</a>
+                  <p>Save these recovery codes somewhere safe. Each can be used once if you lose your device:</p>
+                  <ul className="grid grid-cols-2 gap-1 font-mono text-xs" aria-label="Recovery codes">
+                    {enrollment.recovery_codes.map(rc => <li key={rc}>{rc}</li>)}
+                  </ul>
+                </div>
+              )}
+
+              <label htmlFor="mfa-code" className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1.5">
+                {useRecoveryCode ? 'Recovery code' : 'Authentication code'}
+              </label>
+              <input
+                id="mfa-code"
+                type="text"
+                value={code}
+                onChange={e => setCode(e.target.value)}
+                placeholder={useRecoveryCode ? 'xxxx-xxxx' : '123456'}
+                className="w-full px-3 py-2 border rounded-lg text-sm dark:bg-gray-700 dark:border-gray-600 dark:text-white focus:ring-2 focus:ring-blue-500 focus:border-blue-500"
+                autoFocus
+                autoComplete="one-time-code"
+                inputMode={useRecoveryCode ? 'text' : 'numeric'}
+              />
+
+              {error && (
+                <div className="mt-3 text-sm text-red-600 dark:text-red-400 bg-red-50 dark:bg-red-900/20 px-3 py-2 rounded">
+                  {error}
+                </div>
+              )}
+
+              <button
+                type="submit"
+                disabled={loading || !code.trim()}
+                className="w-full mt-4 px-4 py-2 bg-blue-600 text-white rounded-lg text-sm font-medium hover:bg-blue-700 disabled:opacity-50 disabled:cursor-not-allowed"
+              >
+                {loading ? 'Verifying...' : 'Verify'}
+              </button>
+
+              <div className="mt-3 flex justify-between text-xs">
+                {!enrollment ? (
+                  <button type="button" onClick={() => { setUseRecoveryCode(!useRecoveryCode); setCode('') }} className="text-blue-600 hover:underline">
+                    {useRecoveryCode ? 'Use authenticator code' : 'Use a recovery code'}
+                  </button>
+                ) : <span />}
+                <button type="button" onClick={restartLogin} className="text-gray-500 hover:underline">
+                  Start over
+                </button>
+              </div>
+            </form>
+          )}
+
+          {localAuthEnabled && !challenge && (
             <form onSubmit={handleSubmit}>
               <label className="block text-sm font-medium text-gray-700 dark:text-gray-300 mb-1.5">
                 Username
@@ -118,7 +211,7 @@ export default function LoginPage() {
             </form>
           )}
 
-          {!providersLoading && providers.length > 0 && (
+          {!challenge && !providersLoading && providers.length > 0 && (
             <>
               {localAuthEnabled && (
                 <div className="relative my-5">
@@ -23,15 +23,26 @@ function validatePoolName(name: string): string | undefined {
   return name.trim() ? undefined : 'Name is required'
 }
 
-function validateIPv4CIDR(cidr: string): string | undefined {
+function validateCIDR(cidr: string): string | undefined {
   const trimmed = cidr.trim()
   if (!trimmed) return 'CIDR is required'
 
   const parts = trimmed.split('/')
-  if (parts.length !== 2) return 'Enter an IPv4 CIDR such as 10.0.0.0/16'
+  if (parts.length !== 2) return 'Enter a CIDR such as 10.0.0.0/16 or 2001:db8::/48'
 
   const [addr, prefixText] = parts
   const prefix = Number(prefixText)
+  if (addr.includes(':')) {
+    // IPv6: check the shape here and leave reserved ranges and canonical
+    // form to the server, which reports them precisely.
+    if (!/^[0-9a-fA-F:]+$/.test(addr) || (addr.match(/::/g) ?? []).length > 1) {
+      return 'Enter an IPv6 CIDR such as 2001:db8::/48'
+    }
+    if (!Number.isInteger(prefix) || prefix < 16 || prefix > 64) {
+      return 'IPv6 prefix length must be between 16 and 64'
+    }
+    return undefined
+  }
   if (!Number.isInteger(prefix) || prefix < 8 || prefix > 32) {
     return 'Prefix length must be between 8 and 32'
   }
@@ -50,7 +61,7 @@ function validateIPv4CIDR(cidr: string): string | undefined {
 function validateCreatePool(form: CreatePoolRequest): PoolFormErrors {
   const errors: PoolFormErrors = {}
   const nameError = validatePoolName(form.name)
-  const cidrError = validateIPv4CIDR(form.cidr)
+  const cidrError = validateCIDR(form.cidr)
   if (nameError) errors.name = nameError
   if (cidrError) errors.cidr = cidrError
   return errors
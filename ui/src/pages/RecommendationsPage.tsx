@@ -92,6 +92,9 @@ export default function RecommendationsPage() {
           <option value="">All Types</option>
           <option value="allocation">Allocation</option>
           <option value="compliance">Compliance</option>
+          <option value="consolidation">Consolidation</option>
+          <option value="resize">Resize</option>
+          <option value="reclaim">Reclaim</option>
         </select>
         <select
           value={statusFilter}
@@ -427,7 +430,11 @@ function ApplyModal({
   onApply: (name?: string, accountId?: number) => Promise<void>
 }) {
   const [name, setName] = useState(
-    rec.type === 'allocation' ? `Allocation ${rec.suggested_cidr}` : '',
+    rec.type === 'allocation'
+      ? `Allocation ${rec.suggested_cidr}`
+      : rec.type === 'consolidation'
+        ? `Aggregate ${rec.suggested_cidr}`
+        : '',
   )
   const [submitting, setSubmitting] = useState(false)
 
@@ -440,7 +447,7 @@ function ApplyModal({
         <p className="text-sm text-gray-600 dark:text-gray-400 mb-4">
           {rec.title}
         </p>
-        {rec.type === 'allocation' && rec.suggested_cidr && (
+        {(rec.type === 'allocation' || rec.type === 'consolidation') && rec.suggested_cidr && (
           <div className="space-y-3 mb-4">
             <div className="bg-blue-50 dark:bg-blue-900/30 rounded p-3 text-sm">
               <span className="font-medium">CIDR:</span> {rec.suggested_cidr}
@@ -463,6 +470,16 @@ function ApplyModal({
             Marking this as applied acknowledges the compliance issue. The underlying fix should be applied manually.
           </div>
         )}
+        {rec.type === 'resize' && (
+          <div className="bg-yellow-50 dark:bg-yellow-900/30 rounded p-3 text-sm mb-4">
+            Moves the pool from {rec.metadata?.current_cidr} to {rec.suggested_cidr}. The change is rejected if the pool or its neighbours have changed since this was generated.
+          </div>
+        )}
+        {rec.type === 'reclaim' && (
+          <div className="bg-red-50 dark:bg-red-900/30 rounded p-3 text-sm mb-4">
+            Deletes this pool and returns its block to the parent.
+          </div>
+        )}
         <div className="flex justify-end gap-2">
           <button
             onClick={onClose}
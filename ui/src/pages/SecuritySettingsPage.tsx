@@ -8,10 +8,12 @@ import { useAuth } from '../hooks/useAuth'
 
 const API_KEY_SCOPE_OPTIONS = [
   'pools:read', 'pools:write',
+  'change_requests:read', 'change_requests:approve',
   'accounts:read', 'accounts:write',
   'keys:read', 'keys:write',
   'discovery:read', 'discovery:write',
   'audit:read',
+  'scim:provision',
   '*',
 ]
 
@@ -24,9 +26,9 @@ const API_KEY_ROLE_LABELS: Record<string, string> = {
 
 const API_KEY_SCOPE_OPTIONS_BY_ROLE: Record<string, string[]> = {
   admin: API_KEY_SCOPE_OPTIONS,
-  operator: ['pools:read', 'pools:write', 'accounts:read', 'accounts:write', 'discovery:read', 'discovery:write'],
-  viewer: ['pools:read', 'accounts:read', 'discovery:read'],
-  auditor: ['audit:read'],
+  operator: ['pools:read', 'pools:write', 'change_requests:read', 'accounts:read', 'accounts:write', 'discovery:read', 'discovery:write'],
+  viewer: ['pools:read', 'change_requests:read', 'accounts:read', 'discovery:read'],
+  auditor: ['audit:read', 'change_requests:read'],
 }
 
 export default function SecuritySettingsPage() {
@@ -62,12 +62,12 @@ export default function PreviewStep({ schema, conflicts, conflictsLoading, confl
             <div>
               <h3 className="font-medium text-red-800 dark:text-red-300">Conflicts Detected</h3>
               <p className="text-sm text-red-600 dark:text-red-400 mt-1">
-                {conflicts.length} allocation(s) overlap with existing pools in CloudPAM.
+                {conflicts.length} allocation(s) overlap with existing pools or reservations in CloudPAM.
               </p>
               <ul className="mt-2 space-y-1">
                 {conflicts.map((c, i) => (
                   <li key={i} className="text-sm text-red-700 dark:text-red-300">
-                    &bull; <code className="font-mono">{c.planned_cidr}</code> overlaps with existing pool &ldquo;{c.existing_pool_name}&rdquo; ({c.existing_cidr})
+                    &bull; <code className="font-mono">{c.planned_cidr}</code> overlaps with {c.reservation_id ? 'a block' : 'existing pool'} &ldquo;{c.existing_pool_name}&rdquo; ({c.existing_cidr})
                   </li>
                 ))}
               </ul>